@@ -59,6 +59,10 @@ func (m *AssetModule) Init(ctx context.Context, config *plugin.ModuleConfig) err
 
 // Start 启动模块
 func (m *AssetModule) Start() error {
+	// 基础模块检查生命周期状态，拒绝未初始化或重复启动
+	if err := m.BaseModule.Start(); err != nil {
+		return err
+	}
 	m.Logger.Info("启动资产管理模块")
 
 	// 检查是否启用自动上报
@@ -73,6 +77,10 @@ func (m *AssetModule) Start() error {
 
 // Stop 停止模块
 func (m *AssetModule) Stop() error {
+	// 基础模块检查生命周期状态，拒绝停止未启动的模块
+	if err := m.BaseModule.Stop(); err != nil {
+		return err
+	}
 	m.Logger.Info("停止资产管理模块")
 	// 在实际应用中，这里应该停止所有后台协程
 	return nil
@@ -65,6 +65,10 @@ func (m *AuditModule) Init(ctx context.Context, config *plugin.ModuleConfig) err
 
 // Start 启动模块
 func (m *AuditModule) Start() error {
+	// 基础模块检查生命周期状态，拒绝未初始化或重复启动
+	if err := m.BaseModule.Start(); err != nil {
+		return err
+	}
 	m.Logger.Info("启动安全审计模块")
 
 	// 确保审计日志记录器已初始化
@@ -80,6 +84,7 @@ func (m *AuditModule) Start() error {
 		auditLogger, err := NewAuditLogger(m.Logger, m.Config)
 		if err != nil {
 			m.Logger.Error("创建审计日志记录器失败", "error", err)
+			m.BaseModule.Stop()
 			return fmt.Errorf("创建审计日志记录器失败: %w", err)
 		}
 		m.auditLogger = auditLogger
@@ -102,6 +107,10 @@ func (m *AuditModule) Start() error {
 
 // Stop 停止模块
 func (m *AuditModule) Stop() error {
+	// 基础模块检查生命周期状态，拒绝停止未启动的模块
+	if err := m.BaseModule.Stop(); err != nil {
+		return err
+	}
 	m.Logger.Info("停止安全审计模块")
 
 	// 确保审计日志记录器已初始化
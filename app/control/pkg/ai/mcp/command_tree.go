@@ -0,0 +1,15 @@
+package mcp
+
+import "os/exec"
+
+// RunCommandTree 运行命令并等待其结束
+// 命令的上下文取消或超时时终止命令创建的整个进程树，而不只是直接启动的子进程，
+// 例如 sh -c "sleep 100" 中由 shell 启动的 sleep 进程
+func RunCommandTree(cmd *exec.Cmd) error {
+	release, err := startCommandTree(cmd)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return cmd.Wait()
+}
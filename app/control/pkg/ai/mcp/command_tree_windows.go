@@ -0,0 +1,42 @@
+//go:build windows
+
+package mcp
+
+import (
+	"os/exec"
+	"sync/atomic"
+
+	"golang.org/x/sys/windows"
+)
+
+// startCommandTree 启动命令并将其加入作业对象，取消时终止作业中的所有进程
+// 命令进程启动后才能加入作业，在此之前创建的子进程不受控制；加入失败时取消只终止命令进程
+func startCommandTree(cmd *exec.Cmd) (func(), error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var assigned atomic.Bool
+	cmd.Cancel = func() error {
+		if assigned.Load() {
+			return windows.TerminateJobObject(job, 1)
+		}
+		return cmd.Process.Kill()
+	}
+
+	if err := cmd.Start(); err != nil {
+		windows.CloseHandle(job)
+		return nil, err
+	}
+
+	process, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(cmd.Process.Pid))
+	if err == nil {
+		if windows.AssignProcessToJobObject(job, process) == nil {
+			assigned.Store(true)
+		}
+		windows.CloseHandle(process)
+	}
+
+	return func() { windows.CloseHandle(job) }, nil
+}
@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// DefaultTerminationGracePeriod 发送终止信号后等待进程自行退出的默认时间
+const DefaultTerminationGracePeriod = 5 * time.Second
+
+// terminationPollInterval 等待进程退出时检查进程状态的间隔
+const terminationPollInterval = 50 * time.Millisecond
+
+// killWaitTimeout 强制终止后等待进程消失的最长时间
+const killWaitTimeout = 2 * time.Second
+
+// TerminationStep 进程终止在哪一步完成
+type TerminationStep string
+
+const (
+	// TerminationStepAlreadyExited 进程在发送信号前已经退出
+	TerminationStepAlreadyExited TerminationStep = "already_exited"
+	// TerminationStepGraceful 进程在宽限期内响应终止信号（SIGTERM 或 Windows 关闭请求）退出
+	TerminationStepGraceful TerminationStep = "graceful"
+	// TerminationStepForced 进程被强制终止（SIGKILL 或 TerminateProcess）
+	TerminationStepForced TerminationStep = "forced"
+)
+
+// TerminableProcess 可终止的进程
+type TerminableProcess interface {
+	// Terminate 请求进程退出，Unix 上发送 SIGTERM，Windows 上发送关闭请求
+	Terminate() error
+	// Kill 强制终止进程
+	Kill() error
+	// IsRunning 检查进程是否仍在运行
+	IsRunning() (bool, error)
+}
+
+// TerminationResult 进程终止结果
+type TerminationResult struct {
+	Step      TerminationStep `json:"step"`
+	Escalated bool            `json:"escalated"` // 宽限期内未退出，升级为强制终止
+	Duration  time.Duration   `json:"duration"`
+}
+
+// TerminateProcess 先请求进程退出，宽限期内未退出时再强制终止
+// grace 不大于0时直接强制终止
+func TerminateProcess(ctx context.Context, proc TerminableProcess, grace time.Duration) (*TerminationResult, error) {
+	start := time.Now()
+	result := &TerminationResult{}
+	defer func() { result.Duration = time.Since(start) }()
+
+	if running, err := proc.IsRunning(); err == nil && !running {
+		result.Step = TerminationStepAlreadyExited
+		return result, nil
+	}
+
+	if grace > 0 {
+		if err := proc.Terminate(); err != nil {
+			// 进程可能恰好退出
+			if running, runErr := proc.IsRunning(); runErr == nil && !running {
+				result.Step = TerminationStepGraceful
+				return result, nil
+			}
+			return result, fmt.Errorf("发送终止信号失败: %w", err)
+		}
+
+		exited, err := waitForExit(ctx, proc, grace)
+		if err != nil {
+			return result, err
+		}
+		if exited {
+			result.Step = TerminationStepGraceful
+			return result, nil
+		}
+		result.Escalated = true
+	}
+
+	if err := proc.Kill(); err != nil {
+		if running, runErr := proc.IsRunning(); runErr == nil && !running {
+			result.Step = TerminationStepForced
+			return result, nil
+		}
+		return result, fmt.Errorf("强制终止进程失败: %w", err)
+	}
+
+	exited, err := waitForExit(ctx, proc, killWaitTimeout)
+	if err != nil {
+		return result, err
+	}
+	if !exited {
+		return result, fmt.Errorf("强制终止后进程仍在运行")
+	}
+	result.Step = TerminationStepForced
+	return result, nil
+}
+
+// waitForExit 在超时时间内等待进程退出，返回进程是否已退出
+func waitForExit(ctx context.Context, proc TerminableProcess, timeout time.Duration) (bool, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(terminationPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if running, err := proc.IsRunning(); err == nil && !running {
+			return true, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-timer.C:
+			running, err := proc.IsRunning()
+			return err == nil && !running, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// systemProcess 基于操作系统进程的可终止进程
+type systemProcess struct {
+	pid  int
+	proc *process.Process
+}
+
+// OpenSystemProcess 打开指定PID的操作系统进程
+func OpenSystemProcess(pid int) (TerminableProcess, error) {
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return nil, err
+	}
+	return &systemProcess{pid: pid, proc: proc}, nil
+}
+
+// Kill 强制终止进程
+func (p *systemProcess) Kill() error {
+	return p.proc.Kill()
+}
+
+// IsRunning 检查进程是否仍在运行，僵尸进程视为已退出
+func (p *systemProcess) IsRunning() (bool, error) {
+	running, err := p.proc.IsRunning()
+	if err != nil || !running {
+		return running, err
+	}
+
+	if status, err := p.proc.Status(); err == nil {
+		for _, s := range status {
+			if strings.EqualFold(s, process.Zombie) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
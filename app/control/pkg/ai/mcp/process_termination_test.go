@@ -0,0 +1,190 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockProcess 模拟进程，可配置是否响应终止信号
+type mockProcess struct {
+	mu         sync.Mutex
+	running    bool
+	exitOnTerm bool
+	terminated int
+	killed     int
+}
+
+func newMockProcess(exitOnTerm bool) *mockProcess {
+	return &mockProcess{running: true, exitOnTerm: exitOnTerm}
+}
+
+func (p *mockProcess) Terminate() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.terminated++
+	if p.exitOnTerm {
+		p.running = false
+	}
+	return nil
+}
+
+func (p *mockProcess) Kill() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.killed++
+	p.running = false
+	return nil
+}
+
+func (p *mockProcess) IsRunning() (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.running, nil
+}
+
+func (p *mockProcess) counts() (int, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.terminated, p.killed
+}
+
+func TestTerminateProcess_ExitsOnTerm(t *testing.T) {
+	proc := newMockProcess(true)
+
+	result, err := TerminateProcess(context.Background(), proc, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, TerminationStepGraceful, result.Step)
+	assert.False(t, result.Escalated)
+
+	terminated, killed := proc.counts()
+	assert.Equal(t, 1, terminated)
+	assert.Equal(t, 0, killed, "响应终止信号的进程不应被强制终止")
+}
+
+func TestTerminateProcess_EscalatesWhenTermIgnored(t *testing.T) {
+	proc := newMockProcess(false)
+	grace := 200 * time.Millisecond
+
+	start := time.Now()
+	result, err := TerminateProcess(context.Background(), proc, grace)
+	require.NoError(t, err)
+	assert.Equal(t, TerminationStepForced, result.Step)
+	assert.True(t, result.Escalated)
+	assert.GreaterOrEqual(t, time.Since(start), grace, "应该等待宽限期后再强制终止")
+
+	terminated, killed := proc.counts()
+	assert.Equal(t, 1, terminated)
+	assert.Equal(t, 1, killed)
+}
+
+func TestTerminateProcess_ForceSkipsGracePeriod(t *testing.T) {
+	proc := newMockProcess(true)
+
+	result, err := TerminateProcess(context.Background(), proc, 0)
+	require.NoError(t, err)
+	assert.Equal(t, TerminationStepForced, result.Step)
+	assert.False(t, result.Escalated)
+
+	terminated, killed := proc.counts()
+	assert.Equal(t, 0, terminated)
+	assert.Equal(t, 1, killed)
+}
+
+func TestTerminateProcess_AlreadyExited(t *testing.T) {
+	proc := newMockProcess(true)
+	proc.running = false
+
+	result, err := TerminateProcess(context.Background(), proc, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, TerminationStepAlreadyExited, result.Step)
+
+	terminated, killed := proc.counts()
+	assert.Zero(t, terminated)
+	assert.Zero(t, killed)
+}
+
+func TestTerminateProcess_ContextCancelled(t *testing.T) {
+	proc := newMockProcess(false)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := TerminateProcess(ctx, proc, 10*time.Second)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	_, killed := proc.counts()
+	assert.Zero(t, killed, "取消后不应升级为强制终止")
+}
+
+func TestProcessKillTool_GracePeriodParameter(t *testing.T) {
+	ignoring := newMockProcess(false)
+	tool := &ProcessKillTool{
+		openProcess: func(pid int) (TerminableProcess, error) { return ignoring, nil },
+	}
+	assert.Contains(t, tool.GetParameters(), "grace_period")
+
+	raw, err := tool.Execute(context.Background(), map[string]interface{}{
+		"pid":          float64(1234),
+		"grace_period": 0.1,
+	})
+	require.NoError(t, err)
+	result := raw.(*ProcessKillToolResult)
+	assert.True(t, result.Success)
+	assert.Equal(t, string(TerminationStepForced), result.Step)
+	assert.True(t, result.Escalated)
+
+	graceful := newMockProcess(true)
+	tool.openProcess = func(pid int) (TerminableProcess, error) { return graceful, nil }
+	raw, err = tool.Execute(context.Background(), map[string]interface{}{"pid": 1234})
+	require.NoError(t, err)
+	result = raw.(*ProcessKillToolResult)
+	assert.True(t, result.Success)
+	assert.Equal(t, string(TerminationStepGraceful), result.Step)
+	assert.False(t, result.Escalated)
+
+	_, err = tool.Execute(context.Background(), map[string]interface{}{"pid": 1234, "grace_period": -1})
+	assert.Error(t, err)
+}
+
+func TestTerminateProcess_SystemProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("依赖 POSIX 信号")
+	}
+
+	tests := []struct {
+		name      string
+		script    string
+		step      TerminationStep
+		escalated bool
+	}{
+		{"响应SIGTERM", "sleep 30", TerminationStepGraceful, false},
+		{"忽略SIGTERM", "trap '' TERM; while true; do sleep 0.05; done", TerminationStepForced, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("sh", "-c", tt.script)
+			require.NoError(t, cmd.Start())
+			t.Cleanup(func() {
+				_ = cmd.Process.Kill()
+				_ = cmd.Wait()
+			})
+			// 等待 shell 设置好信号处理
+			time.Sleep(100 * time.Millisecond)
+
+			proc, err := OpenSystemProcess(cmd.Process.Pid)
+			require.NoError(t, err)
+
+			result, err := TerminateProcess(context.Background(), proc, 300*time.Millisecond)
+			require.NoError(t, err)
+			assert.Equal(t, tt.step, result.Step)
+			assert.Equal(t, tt.escalated, result.Escalated)
+		})
+	}
+}
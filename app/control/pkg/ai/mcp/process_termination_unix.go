@@ -0,0 +1,8 @@
+//go:build !windows
+
+package mcp
+
+// Terminate 向进程发送 SIGTERM
+func (p *systemProcess) Terminate() error {
+	return p.proc.Terminate()
+}
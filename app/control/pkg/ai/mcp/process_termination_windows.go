@@ -0,0 +1,13 @@
+//go:build windows
+
+package mcp
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// Terminate 通过不带 /F 的 taskkill 向进程发送关闭请求，进程可以自行清理后退出
+func (p *systemProcess) Terminate() error {
+	return exec.Command("taskkill", "/PID", strconv.Itoa(p.pid)).Run()
+}
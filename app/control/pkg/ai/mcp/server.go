@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
@@ -14,13 +15,18 @@ import (
 
 // ServerConfig 定义了 MCP Server 的配置
 type ServerConfig struct {
-	Addr           string        // 监听地址，默认为 :8080
-	ReadTimeout    time.Duration // 读取超时，默认为 10 秒
-	WriteTimeout   time.Duration // 写入超时，默认为 10 秒
-	MaxHeaderBytes int           // 最大头部字节数，默认为 1MB
-	APIKey         string        // API 密钥，用于认证
+	Addr           string                   // 监听地址，默认为 :8080
+	ReadTimeout    time.Duration            // 读取超时，默认为 10 秒
+	WriteTimeout   time.Duration            // 写入超时，默认为 10 秒
+	MaxHeaderBytes int                      // 最大头部字节数，默认为 1MB
+	APIKey         string                   // API 密钥，用于认证
+	ToolTimeout    time.Duration            // 工具执行超时，默认为 30 秒
+	ToolTimeouts   map[string]time.Duration // 按工具名称覆盖执行超时
 }
 
+// ErrToolTimeout 工具执行超时
+var ErrToolTimeout = errors.New("工具执行超时")
+
 // Server 实现了 MCP Server
 type Server struct {
 	config     *ServerConfig
@@ -50,6 +56,9 @@ func NewServer(config *ServerConfig, logger logging.Logger) (*Server, error) {
 	if config.MaxHeaderBytes == 0 {
 		config.MaxHeaderBytes = 1 << 20 // 1MB
 	}
+	if config.ToolTimeout == 0 {
+		config.ToolTimeout = 30 * time.Second
+	}
 
 	// 创建路由器
 	router := mux.NewRouter()
@@ -130,6 +139,54 @@ func (s *Server) ListTools() []ToolInfo {
 	return tools
 }
 
+// ToolTimeout 返回工具的执行超时
+func (s *Server) ToolTimeout(name string) time.Duration {
+	if timeout, ok := s.config.ToolTimeouts[name]; ok && timeout > 0 {
+		return timeout
+	}
+	return s.config.ToolTimeout
+}
+
+// ExecuteTool 执行工具，超过工具的执行超时后取消其上下文并返回 ErrToolTimeout
+// 不响应上下文取消的工具会在后台继续运行直到返回，但不再占用请求
+func (s *Server) ExecuteTool(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
+	s.mu.RLock()
+	tool, exists := s.tools[name]
+	s.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("工具 %s 不存在", name)
+	}
+
+	timeout := s.ToolTimeout(name)
+	toolCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type toolResult struct {
+		result interface{}
+		err    error
+	}
+	done := make(chan toolResult, 1)
+	go func() {
+		result, err := tool.Execute(toolCtx, params)
+		done <- toolResult{result: result, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil && errors.Is(toolCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("%w: %s 超过 %s", ErrToolTimeout, name, timeout)
+		}
+		return res.result, res.err
+	case <-toolCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		s.logger.Warn("工具执行超时，已取消", "tool", name, "timeout", timeout)
+		return nil, fmt.Errorf("%w: %s 超过 %s", ErrToolTimeout, name, timeout)
+	}
+}
+
 // Start 启动服务器
 func (s *Server) Start() error {
 	s.logger.Info("启动 MCP Server", "addr", s.config.Addr)
@@ -210,7 +267,7 @@ func (s *Server) handleExecuteTool(w http.ResponseWriter, r *http.Request) {
 	name := vars["name"]
 
 	s.mu.RLock()
-	tool, exists := s.tools[name]
+	_, exists := s.tools[name]
 	s.mu.RUnlock()
 
 	if !exists {
@@ -226,12 +283,13 @@ func (s *Server) handleExecuteTool(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 创建上下文，包含超时
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
-	defer cancel()
-
-	// 执行工具
-	result, err := tool.Execute(ctx, params)
+	// 执行工具，超时后取消
+	result, err := s.ExecuteTool(r.Context(), name, params)
+	if errors.Is(err, ErrToolTimeout) {
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+		s.logger.Error("执行工具超时", "tool", name, "error", err)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		s.logger.Error("执行工具失败", "tool", name, "error", err)
@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, config *ServerConfig) *Server {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	server, err := NewServer(config, logger)
+	require.NoError(t, err)
+	return server
+}
+
+// ignoringTool 忽略上下文取消的工具
+func ignoringTool(release <-chan struct{}) Tool {
+	return NewTool("ignore_ctx", "忽略取消", nil, func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		<-release
+		return "done", nil
+	})
+}
+
+// respectingTool 响应上下文取消的工具
+func respectingTool(cancelled chan<- struct{}) Tool {
+	return NewTool("respect_ctx", "响应取消", nil, func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			close(cancelled)
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Second):
+			return "done", nil
+		}
+	})
+}
+
+func TestServer_ToolTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	cancelled := make(chan struct{})
+
+	server := newTestServer(t, &ServerConfig{ToolTimeout: 100 * time.Millisecond})
+	require.NoError(t, server.RegisterTool(ignoringTool(release)))
+	require.NoError(t, server.RegisterTool(respectingTool(cancelled)))
+
+	for _, name := range []string{"ignore_ctx", "respect_ctx"} {
+		start := time.Now()
+		_, err := server.ExecuteTool(context.Background(), name, map[string]interface{}{})
+		assert.ErrorIs(t, err, ErrToolTimeout, name)
+		assert.Less(t, time.Since(start), time.Second, "%s 应在超时后立即返回", name)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("超时后应取消工具的上下文")
+	}
+}
+
+func TestServer_PerToolTimeout(t *testing.T) {
+	server := newTestServer(t, &ServerConfig{
+		ToolTimeout:  50 * time.Millisecond,
+		ToolTimeouts: map[string]time.Duration{"slow": time.Second},
+	})
+	assert.Equal(t, time.Second, server.ToolTimeout("slow"))
+	assert.Equal(t, 50*time.Millisecond, server.ToolTimeout("other"))
+	assert.Equal(t, 30*time.Second, newTestServer(t, nil).ToolTimeout("any"), "默认超时为30秒")
+
+	require.NoError(t, server.RegisterTool(NewTool("slow", "慢工具", nil, func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+			return "done", nil
+		}
+	})))
+
+	result, err := server.ExecuteTool(context.Background(), "slow", map[string]interface{}{})
+	require.NoError(t, err, "覆盖的超时应长于默认超时")
+	assert.Equal(t, "done", result)
+
+	// 工具自身的错误不应被当作超时
+	require.NoError(t, server.RegisterTool(NewTool("failing", "失败", nil, func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("失败")
+	})))
+	_, err = server.ExecuteTool(context.Background(), "failing", map[string]interface{}{})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrToolTimeout)
+}
+
+func TestServer_ExecuteToolTimeoutHTTP(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	server := newTestServer(t, &ServerConfig{ToolTimeout: 50 * time.Millisecond})
+	require.NoError(t, server.RegisterTool(ignoringTool(release)))
+
+	req := httptest.NewRequest(http.MethodPost, "/tools/ignore_ctx/execute", bytes.NewBufferString("{}"))
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrToolTimeout.Error())
+}
+
+// processRunning 检查是否存在命令行完全一致的进程
+func processRunning(args ...string) bool {
+	cmdlines, _ := filepath.Glob("/proc/[0-9]*/cmdline")
+	want := strings.Join(args, "\x00") + "\x00"
+	for _, path := range cmdlines {
+		data, err := os.ReadFile(path)
+		if err == nil && string(data) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestServer_TimeoutKillsCommandProcess(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("通过 /proc 检查进程，仅在 Linux 上运行")
+	}
+	sleepArg := "31.337"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+	executor := NewCommandExecuteToolExecutor(logger)
+
+	server := newTestServer(t, &ServerConfig{ToolTimeout: 300 * time.Millisecond})
+	require.NoError(t, server.RegisterTool(NewTool("run_command", "执行命令", nil, executor.Execute)))
+
+	started := make(chan struct{})
+	go func() {
+		for !processRunning("sleep", sleepArg) {
+			select {
+			case <-time.After(10 * time.Millisecond):
+			case <-ctx.Done():
+				return
+			}
+		}
+		close(started)
+	}()
+
+	_, err = server.ExecuteTool(context.Background(), "run_command", map[string]interface{}{
+		"command": "sleep",
+		"args":    []interface{}{sleepArg},
+		"timeout": float64(60),
+	})
+	assert.ErrorIs(t, err, ErrToolTimeout)
+
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("命令进程未启动")
+	}
+
+	deadline := time.Now().Add(CommandWaitDelay + time.Second)
+	for processRunning("sleep", sleepArg) {
+		if time.Now().After(deadline) {
+			t.Fatal("超时后命令进程应被终止")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestCommandExecuteToolExecutor_CancelKillsProcessTree(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("通过 /proc 检查进程，仅在 Linux 上运行")
+	}
+	sleepArg := "100.271"
+
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+	executor := NewCommandExecuteToolExecutor(logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for !processRunning("sleep", sleepArg) {
+			select {
+			case <-time.After(10 * time.Millisecond):
+			case <-ctx.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+
+	// 末尾的 true 使 shell 以子进程方式运行 sleep，而不是直接 exec
+	result, err := executor.Execute(ctx, map[string]interface{}{
+		"command": "sh",
+		"args":    []interface{}{"-c", "sleep " + sleepArg + "; true"},
+		"timeout": float64(60),
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, result)
+
+	deadline := time.Now().Add(time.Second)
+	for processRunning("sleep", sleepArg) {
+		if time.Now().After(deadline) {
+			t.Fatal("取消后 shell 启动的子进程应被终止")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
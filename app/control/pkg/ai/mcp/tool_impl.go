@@ -7,7 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
-	"syscall"
+	"time"
 
 	"github.com/lomehong/kennel/pkg/logging"
 )
@@ -15,6 +15,9 @@ import (
 // ProcessKillTool 是进程终止工具
 type ProcessKillTool struct {
 	Logger logging.Logger
+
+	// openProcess 打开要终止的进程，为nil时使用操作系统进程
+	openProcess func(pid int) (TerminableProcess, error)
 }
 
 // GetName 返回工具名称
@@ -37,8 +40,14 @@ func (t *ProcessKillTool) GetParameters() map[string]Parameter {
 		},
 		"force": {
 			Type:        "boolean",
-			Description: "是否强制终止",
+			Description: "是否跳过宽限期直接强制终止",
+			Required:    false,
+		},
+		"grace_period": {
+			Type:        "integer",
+			Description: "发送终止信号后等待进程自行退出的时间（秒），超时后强制终止",
 			Required:    false,
+			Default:     int(DefaultTerminationGracePeriod / time.Second),
 		},
 	}
 }
@@ -67,14 +76,27 @@ func (t *ProcessKillTool) Execute(ctx context.Context, params map[string]interfa
 		return nil, fmt.Errorf("无效的进程ID类型")
 	}
 
-	// 获取是否强制终止
-	force := false
-	if forceValue, ok := params["force"].(bool); ok {
-		force = forceValue
+	// 获取宽限期，强制终止时不等待
+	grace := DefaultTerminationGracePeriod
+	switch v := params["grace_period"].(type) {
+	case int:
+		grace = time.Duration(v) * time.Second
+	case float64:
+		grace = time.Duration(v * float64(time.Second))
+	}
+	if grace < 0 {
+		return nil, fmt.Errorf("无效的宽限期: %v", params["grace_period"])
+	}
+	if force, ok := params["force"].(bool); ok && force {
+		grace = 0
 	}
 
 	// 获取进程
-	process, err := os.FindProcess(pid)
+	openProcess := t.openProcess
+	if openProcess == nil {
+		openProcess = OpenSystemProcess
+	}
+	process, err := openProcess(pid)
 	if err != nil {
 		return &ProcessKillToolResult{
 			Success: false,
@@ -82,23 +104,23 @@ func (t *ProcessKillTool) Execute(ctx context.Context, params map[string]interfa
 		}, nil
 	}
 
-	// 终止进程
-	var signal syscall.Signal
-	if force {
-		signal = syscall.SIGKILL
-	} else {
-		signal = syscall.SIGTERM
-	}
-
-	if err := process.Signal(signal); err != nil {
+	// 先请求进程退出，宽限期后仍在运行时强制终止
+	result, err := TerminateProcess(ctx, process, grace)
+	if err != nil {
 		return &ProcessKillToolResult{
-			Success: false,
-			Error:   fmt.Sprintf("终止进程失败: %v", err),
+			Success:   false,
+			Escalated: result.Escalated,
+			Error:     fmt.Sprintf("终止进程失败: %v", err),
 		}, nil
 	}
 
+	if t.Logger != nil {
+		t.Logger.Info("进程已终止", "pid", pid, "step", result.Step, "escalated", result.Escalated, "duration", result.Duration)
+	}
 	return &ProcessKillToolResult{
-		Success: true,
+		Success:   true,
+		Step:      string(result.Step),
+		Escalated: result.Escalated,
 	}, nil
 }
 
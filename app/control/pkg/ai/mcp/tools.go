@@ -16,10 +16,16 @@ import (
 	"github.com/lomehong/kennel/pkg/logging"
 )
 
+// CommandWaitDelay 命令被取消后等待输出管道关闭的最长时间
+// 子进程继承了输出管道时，超过该时间后不再等待，避免取消后仍被阻塞
+const CommandWaitDelay = 2 * time.Second
+
 // ProcessKillToolResult 是进程终止工具的结果
 type ProcessKillToolResult struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error,omitempty"`
+	Success   bool   `json:"success"`
+	Step      string `json:"step,omitempty"` // 终止完成的步骤：already_exited、graceful 或 forced
+	Escalated bool   `json:"escalated"`      // 宽限期内未退出，升级为强制终止
+	Error     string `json:"error,omitempty"`
 }
 
 // CommandExecuteToolResult 是命令执行工具的结果
@@ -202,8 +208,9 @@ func (e *CommandExecuteToolExecutor) Execute(ctx context.Context, params map[str
 	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
-	// 创建命令
+	// 创建命令，上下文取消或超时后终止进程树
 	cmd := exec.CommandContext(execCtx, command, args...)
+	cmd.WaitDelay = CommandWaitDelay
 	if workDir != "" {
 		cmd.Dir = workDir
 	}
@@ -214,8 +221,12 @@ func (e *CommandExecuteToolExecutor) Execute(ctx context.Context, params map[str
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	// 执行命令
-	err := cmd.Run()
+	// 执行命令，取消时终止命令创建的整个进程树
+	err := RunCommandTree(cmd)
+	if ctxErr := execCtx.Err(); ctxErr != nil {
+		e.logger.Warn("命令执行被取消，进程已终止", "command", command, "error", ctxErr)
+		return nil, fmt.Errorf("命令 %s 执行被取消，进程已终止: %w", command, ctxErr)
+	}
 
 	// 检查结果
 	exitCode := 0
@@ -240,8 +240,9 @@ func (t *CommandExecTool) Execute(ctx context.Context, params map[string]interfa
 	execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
-	// 创建命令
+	// 创建命令，上下文取消或超时后终止进程树
 	cmd := exec.CommandContext(execCtx, command, args...)
+	cmd.WaitDelay = mcp.CommandWaitDelay
 
 	// 捕获输出
 	var stdout, stderr bytes.Buffer
@@ -251,8 +252,12 @@ func (t *CommandExecTool) Execute(ctx context.Context, params map[string]interfa
 	// 记录开始时间
 	startTime := time.Now()
 
-	// 执行命令
-	err := cmd.Run()
+	// 执行命令，取消时终止命令创建的整个进程树
+	err := mcp.RunCommandTree(cmd)
+	if ctxErr := execCtx.Err(); ctxErr != nil {
+		t.logger.Warn("命令执行被取消，进程已终止", "command", command, "error", ctxErr)
+		return nil, fmt.Errorf("命令 %s 执行被取消，进程已终止: %w", command, ctxErr)
+	}
 
 	// 计算执行时间
 	duration := time.Since(startTime).Milliseconds()
@@ -337,10 +342,16 @@ func (t *ProcessKillTool) GetParameters() map[string]mcp.Parameter {
 		},
 		"force": {
 			Type:        "boolean",
-			Description: "是否强制终止进程",
+			Description: "是否跳过宽限期直接强制终止进程",
 			Required:    false,
 			Default:     false,
 		},
+		"grace_period": {
+			Type:        "number",
+			Description: "发送终止信号后等待进程自行退出的时间（秒），超时后强制终止",
+			Required:    false,
+			Default:     mcp.DefaultTerminationGracePeriod.Seconds(),
+		},
 	}
 }
 
@@ -363,7 +374,19 @@ func (t *ProcessKillTool) Execute(ctx context.Context, params map[string]interfa
 		force = forceParam
 	}
 
-	t.logger.Info("终止进程", "pid", pid, "force", force)
+	// 解析宽限期，强制终止时不等待
+	grace := mcp.DefaultTerminationGracePeriod
+	if graceParam, ok := params["grace_period"].(float64); ok {
+		if graceParam < 0 {
+			return nil, fmt.Errorf("无效的宽限期: %v", graceParam)
+		}
+		grace = time.Duration(graceParam * float64(time.Second))
+	}
+	if force {
+		grace = 0
+	}
+
+	t.logger.Info("终止进程", "pid", pid, "force", force, "grace_period", grace)
 
 	// 获取进程
 	p, err := process.NewProcess(int32(pid))
@@ -385,22 +408,22 @@ func (t *ProcessKillTool) Execute(ctx context.Context, params map[string]interfa
 		}
 	}
 
-	// 终止进程
-	var killErr error
-	if force {
-		killErr = p.Kill()
-	} else {
-		killErr = p.Terminate()
+	// 先请求进程退出，宽限期后仍在运行时强制终止
+	proc, err := mcp.OpenSystemProcess(pid)
+	if err != nil {
+		return nil, fmt.Errorf("获取进程失败: %w", err)
 	}
-
-	if killErr != nil {
-		return nil, fmt.Errorf("终止进程失败: %w", killErr)
+	termination, err := mcp.TerminateProcess(ctx, proc, grace)
+	if err != nil {
+		return nil, fmt.Errorf("终止进程失败: %w", err)
 	}
 
 	// 创建结果
 	result := map[string]interface{}{
-		"status":  "success",
-		"message": fmt.Sprintf("进程 %d (%s) 已终止", pid, name),
+		"status":    "success",
+		"message":   fmt.Sprintf("进程 %d (%s) 已终止", pid, name),
+		"step":      string(termination.Step),
+		"escalated": termination.Escalated,
 	}
 
 	return result, nil
@@ -63,6 +63,10 @@ func (m *ControlModule) Init(ctx context.Context, config *plugin.ModuleConfig) e
 
 // Start 启动模块
 func (m *ControlModule) Start() error {
+	// 基础模块检查生命周期状态，拒绝未初始化或重复启动
+	if err := m.BaseModule.Start(); err != nil {
+		return err
+	}
 	m.Logger.Info("启动终端管控模块")
 
 	// 初始化AI管理器
@@ -76,6 +80,10 @@ func (m *ControlModule) Start() error {
 
 // Stop 停止模块
 func (m *ControlModule) Stop() error {
+	// 基础模块检查生命周期状态，拒绝停止未启动的模块
+	if err := m.BaseModule.Stop(); err != nil {
+		return err
+	}
 	m.Logger.Info("停止终端管控模块")
 	return nil
 }
@@ -260,6 +268,11 @@ func (m *ControlModule) HandleEvent(ctx context.Context, event *plugin.Event) er
 		m.Logger.Info("系统关闭")
 		return nil
 
+	case "config.changed":
+		// 主程序配置变更事件
+		m.Logger.Info("主程序配置已变更", "changed_keys", event.Data["changed_keys"])
+		return nil
+
 	case "process.monitor":
 		// 进程监控事件
 		m.Logger.Info("进程监控")
@@ -70,6 +70,10 @@ func (m *DeviceModule) Init(ctx context.Context, config *plugin.ModuleConfig) er
 
 // Start 启动模块
 func (m *DeviceModule) Start() error {
+	// 基础模块检查生命周期状态，拒绝未初始化或重复启动
+	if err := m.BaseModule.Start(); err != nil {
+		return err
+	}
 	m.Logger.Info("启动设备管理模块")
 
 	// 确保网络管理器和USB管理器已初始化
@@ -103,6 +107,10 @@ func (m *DeviceModule) Start() error {
 
 // Stop 停止模块
 func (m *DeviceModule) Stop() error {
+	// 基础模块检查生命周期状态，拒绝停止未启动的模块
+	if err := m.BaseModule.Stop(); err != nil {
+		return err
+	}
 	m.Logger.Info("停止设备管理模块")
 
 	// 停止设备监控
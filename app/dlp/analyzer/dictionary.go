@@ -0,0 +1,471 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultDictionaryReloadInterval 默认的词典文件变化检查间隔
+const DefaultDictionaryReloadInterval = 30 * time.Second
+
+// DictionaryConfig 外部关键词词典配置
+// 词典文件每行一个词条，# 开头的行为注释，[分类] 行声明其后词条的分类，例如：
+//
+//	# 项目代号
+//	[project]
+//	北极星
+//	Project Aurora
+//
+//	[customer]
+//	ACME Corp
+type DictionaryConfig struct {
+	ID            string    `yaml:"id" json:"id"`                         // 词典ID，默认使用文件名
+	Path          string    `yaml:"path" json:"path"`                     // 词典文件路径
+	Type          string    `yaml:"type" json:"type"`                     // 发现类型，默认 keyword
+	Category      string    `yaml:"category" json:"category"`             // 未声明分类的词条使用的分类
+	RiskLevel     RiskLevel `yaml:"risk_level" json:"risk_level"`         // 风险级别
+	Confidence    float64   `yaml:"confidence" json:"confidence"`         // 命中置信度
+	CaseSensitive bool      `yaml:"case_sensitive" json:"case_sensitive"` // 是否区分大小写
+	WholeWord     bool      `yaml:"whole_word" json:"whole_word"`         // 是否只匹配完整单词
+}
+
+// DictionaryInfo 已加载词典的信息
+type DictionaryInfo struct {
+	ID         string         `json:"id"`
+	Path       string         `json:"path"`
+	Terms      int            `json:"terms"`
+	Categories map[string]int `json:"categories"`
+	Hash       string         `json:"hash"`
+	LoadedAt   time.Time      `json:"loaded_at"`
+	LastError  string         `json:"last_error,omitempty"`
+}
+
+// keywordDictionary 从文件加载的关键词词典
+type keywordDictionary struct {
+	config   DictionaryConfig
+	matchers []*dictionaryMatcher
+	info     DictionaryInfo
+}
+
+// dictionaryMatcher 词典中一个分类的匹配器
+type dictionaryMatcher struct {
+	rule    *KeywordRule
+	pattern *regexp.Regexp
+	terms   map[string]string // 归一化词条 -> 原始词条
+}
+
+// normalizeDictionaryConfig 补全词典配置的默认值
+func normalizeDictionaryConfig(config DictionaryConfig) (DictionaryConfig, error) {
+	if config.Path == "" {
+		return config, fmt.Errorf("词典文件路径不能为空")
+	}
+	if config.ID == "" {
+		config.ID = strings.TrimSuffix(filepath.Base(config.Path), filepath.Ext(config.Path))
+	}
+	if config.Type == "" {
+		config.Type = "keyword"
+	}
+	if config.Category == "" {
+		config.Category = "dictionary"
+	}
+	if config.Confidence <= 0 {
+		config.Confidence = 0.8
+	}
+	return config, nil
+}
+
+// loadKeywordDictionary 读取并编译词典文件
+func loadKeywordDictionary(config DictionaryConfig) (*keywordDictionary, error) {
+	data, err := os.ReadFile(config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("读取词典文件失败 %s: %w", config.Path, err)
+	}
+	return compileKeywordDictionary(config, data)
+}
+
+// ValidateDictionary 检查词典配置和内容能否加载，返回补全默认值后的配置
+func ValidateDictionary(config DictionaryConfig, data []byte) (DictionaryConfig, error) {
+	normalized, err := normalizeDictionaryConfig(config)
+	if err != nil {
+		return config, err
+	}
+	if _, err := compileKeywordDictionary(normalized, data); err != nil {
+		return normalized, err
+	}
+	return normalized, nil
+}
+
+// compileKeywordDictionary 编译词典内容
+func compileKeywordDictionary(config DictionaryConfig, data []byte) (*keywordDictionary, error) {
+	sum := sha256.Sum256(data)
+	dict := &keywordDictionary{
+		config: config,
+		info: DictionaryInfo{
+			ID:         config.ID,
+			Path:       config.Path,
+			Categories: make(map[string]int),
+			Hash:       hex.EncodeToString(sum[:]),
+			LoadedAt:   time.Now(),
+		},
+	}
+
+	categories, order, err := parseDictionary(data, config.Category)
+	if err != nil {
+		return nil, fmt.Errorf("解析词典文件失败 %s: %w", config.Path, err)
+	}
+
+	for _, category := range order {
+		matcher, err := newDictionaryMatcher(config, category, categories[category])
+		if err != nil {
+			return nil, fmt.Errorf("编译词典 %s 分类 %s 失败: %w", config.ID, category, err)
+		}
+		dict.matchers = append(dict.matchers, matcher)
+		dict.info.Categories[category] = len(matcher.terms)
+		dict.info.Terms += len(matcher.terms)
+	}
+
+	return dict, nil
+}
+
+// parseDictionary 解析词典内容，返回各分类的词条和分类出现顺序
+func parseDictionary(data []byte, defaultCategory string) (map[string][]string, []string, error) {
+	categories := make(map[string][]string)
+	var order []string
+	category := defaultCategory
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			category = strings.TrimSpace(line[1 : len(line)-1])
+			if category == "" {
+				return nil, nil, fmt.Errorf("第%d行: 分类名称不能为空", lineNo)
+			}
+			continue
+		}
+
+		if _, exists := categories[category]; !exists {
+			order = append(order, category)
+		}
+		categories[category] = append(categories[category], line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return categories, order, nil
+}
+
+// newDictionaryMatcher 将一个分类的词条编译为单个正则表达式，较长的词条优先匹配
+func newDictionaryMatcher(config DictionaryConfig, category string, terms []string) (*dictionaryMatcher, error) {
+	matcher := &dictionaryMatcher{
+		rule: &KeywordRule{
+			ID:            fmt.Sprintf("dictionary_%s_%s", config.ID, category),
+			Name:          fmt.Sprintf("词典 %s (%s)", config.ID, category),
+			Description:   fmt.Sprintf("外部词典 %s 中的 %s 分类词条", config.Path, category),
+			Type:          config.Type,
+			Category:      category,
+			RiskLevel:     config.RiskLevel,
+			Confidence:    config.Confidence,
+			CaseSensitive: config.CaseSensitive,
+			WholeWord:     config.WholeWord,
+			Enabled:       true,
+			Metadata: map[string]interface{}{
+				"dictionary": config.ID,
+			},
+		},
+		terms: make(map[string]string, len(terms)),
+	}
+
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		key := matcher.normalize(term)
+		if _, exists := matcher.terms[key]; exists {
+			continue
+		}
+		matcher.terms[key] = term
+		matcher.rule.Keywords = append(matcher.rule.Keywords, term)
+		quoted = append(quoted, regexp.QuoteMeta(term))
+	}
+	sort.SliceStable(quoted, func(i, j int) bool {
+		return len(quoted[i]) > len(quoted[j])
+	})
+
+	expr := "(?:" + strings.Join(quoted, "|") + ")"
+	if !config.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	matcher.pattern = pattern
+	return matcher, nil
+}
+
+// normalize 返回用于查找原始词条的键
+func (m *dictionaryMatcher) normalize(term string) string {
+	if m.rule.CaseSensitive {
+		return term
+	}
+	return strings.ToLower(term)
+}
+
+// match 返回文本中命中的词条及其在文本中的形式，每个词条只返回一次
+func (m *dictionaryMatcher) match(text string) []dictionaryHit {
+	var hits []dictionaryHit
+	seen := make(map[string]bool)
+
+	for _, loc := range m.pattern.FindAllStringIndex(text, -1) {
+		if m.rule.WholeWord && !isWholeWord(text, loc[0], loc[1]) {
+			continue
+		}
+		matched := text[loc[0]:loc[1]]
+		term, ok := m.terms[m.normalize(matched)]
+		if !ok || seen[term] {
+			continue
+		}
+		seen[term] = true
+		hits = append(hits, dictionaryHit{term: term, matched: matched, start: loc[0], end: loc[1]})
+	}
+	return hits
+}
+
+// dictionaryHit 词典命中
+type dictionaryHit struct {
+	term    string // 词典中的原始词条
+	matched string // 文本中命中的内容
+	start   int    // 首次命中的字节偏移
+	end     int
+}
+
+// isWholeWord 检查 text[start:end] 是否为完整单词
+// 只在词条边缘是字母或数字时要求相邻字符不是字母或数字，中文等不以空格分词的文字不受限制
+func isWholeWord(text string, start, end int) bool {
+	if start > 0 {
+		first, _ := utf8.DecodeRuneInString(text[start:])
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		if isWordRune(first) && isWordRune(before) {
+			return false
+		}
+	}
+	if end < len(text) {
+		last, _ := utf8.DecodeLastRuneInString(text[:end])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(last) && isWordRune(after) {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordRune 是否为以空格分词的文字中的单词字符
+func isWordRune(r rune) bool {
+	if r == '_' {
+		return true
+	}
+	if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) {
+		return false
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// fileHash 计算文件内容哈希，用于判断词典是否变化
+func fileHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SetDictionaries 设置外部词典并立即加载
+// 加载失败的词典记录错误，在后续重新加载时重试
+func (ta *TextAnalyzer) SetDictionaries(configs []DictionaryConfig) error {
+	var errs []error
+	dictionaries := make([]*keywordDictionary, 0, len(configs))
+	for _, config := range configs {
+		normalized, err := normalizeDictionaryConfig(config)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		dict, err := loadKeywordDictionary(normalized)
+		if err != nil {
+			errs = append(errs, err)
+			dict = &keywordDictionary{
+				config: normalized,
+				info:   DictionaryInfo{ID: normalized.ID, Path: normalized.Path, LastError: err.Error()},
+			}
+		} else {
+			ta.logger.Info("加载关键词词典", "id", dict.info.ID, "path", dict.info.Path, "terms", dict.info.Terms)
+		}
+		dictionaries = append(dictionaries, dict)
+	}
+
+	ta.mu.Lock()
+	ta.dictionaries = dictionaries
+	ta.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// ReloadDictionaries 检查词典文件，内容变化的词典重新加载
+// 重新加载失败时保留原有词条，返回重新加载的词典数量
+func (ta *TextAnalyzer) ReloadDictionaries() (int, error) {
+	ta.mu.RLock()
+	current := make([]*keywordDictionary, len(ta.dictionaries))
+	copy(current, ta.dictionaries)
+	ta.mu.RUnlock()
+
+	var errs []error
+	reloaded := make(map[string]*keywordDictionary)
+	for _, dict := range current {
+		hash, err := fileHash(dict.config.Path)
+		if err == nil && hash == dict.info.Hash {
+			continue
+		}
+
+		updated, loadErr := loadKeywordDictionary(dict.config)
+		if loadErr != nil {
+			errs = append(errs, loadErr)
+			// 同一错误只记录一次
+			if loadErr.Error() != dict.info.LastError {
+				ta.logger.Warn("重新加载关键词词典失败，保留原有词条", "id", dict.config.ID, "error", loadErr)
+			}
+			failed := *dict
+			failed.info.LastError = loadErr.Error()
+			reloaded[dict.config.ID] = &failed
+			continue
+		}
+		ta.logger.Info("关键词词典已更新", "id", updated.info.ID, "terms", updated.info.Terms)
+		reloaded[dict.config.ID] = updated
+	}
+
+	if len(reloaded) == 0 {
+		return 0, nil
+	}
+
+	// 替换为新的切片，分析中的请求继续使用原有词典
+	count := 0
+	ta.mu.Lock()
+	dictionaries := make([]*keywordDictionary, len(ta.dictionaries))
+	for i, dict := range ta.dictionaries {
+		dictionaries[i] = dict
+		if updated, ok := reloaded[dict.config.ID]; ok {
+			dictionaries[i] = updated
+			if updated.info.LastError == "" {
+				count++
+			}
+		}
+	}
+	ta.dictionaries = dictionaries
+	ta.mu.Unlock()
+
+	return count, errors.Join(errs...)
+}
+
+// DictionaryConfigs 获取已设置词典的配置，包括加载失败的词典
+func (ta *TextAnalyzer) DictionaryConfigs() []DictionaryConfig {
+	ta.mu.RLock()
+	defer ta.mu.RUnlock()
+
+	configs := make([]DictionaryConfig, 0, len(ta.dictionaries))
+	for _, dict := range ta.dictionaries {
+		configs = append(configs, dict.config)
+	}
+	return configs
+}
+
+// GetDictionaries 获取已加载词典的信息
+func (ta *TextAnalyzer) GetDictionaries() []DictionaryInfo {
+	ta.mu.RLock()
+	defer ta.mu.RUnlock()
+
+	infos := make([]DictionaryInfo, 0, len(ta.dictionaries))
+	for _, dict := range ta.dictionaries {
+		info := dict.info
+		info.Categories = make(map[string]int, len(dict.info.Categories))
+		for category, count := range dict.info.Categories {
+			info.Categories[category] = count
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// watchDictionaries 定期检查词典文件变化，直到 stop 关闭
+func (ta *TextAnalyzer) watchDictionaries(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := ta.ReloadDictionaries(); err != nil {
+				ta.logger.Debug("检查关键词词典失败", "error", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// analyzeWithDictionaries 使用外部词典分析
+func (ta *TextAnalyzer) analyzeWithDictionaries(text string) []*SensitiveDataInfo {
+	ta.mu.RLock()
+	dictionaries := ta.dictionaries
+	ta.mu.RUnlock()
+
+	results := make([]*SensitiveDataInfo, 0)
+	for _, dict := range dictionaries {
+		for _, matcher := range dict.matchers {
+			rule := matcher.rule
+			if rule.Confidence < ta.config.MinConfidence {
+				continue
+			}
+			for _, hit := range matcher.match(text) {
+				results = append(results, &SensitiveDataInfo{
+					Type:        rule.Type,
+					Value:       hit.term,
+					MaskedValue: ta.maskValue(rule.Type, hit.term),
+					Position:    newPosition(hit.start, hit.end),
+					Confidence:  rule.Confidence,
+					Context:     ta.extractContext(text, hit.matched),
+					Metadata: map[string]interface{}{
+						"rule_id":    rule.ID,
+						"rule_name":  rule.Name,
+						"category":   rule.Category,
+						"keyword":    hit.term,
+						"dictionary": dict.config.ID,
+						"detector":   FindingSourceKeyword,
+					},
+				})
+			}
+		}
+	}
+	return results
+}
@@ -0,0 +1,134 @@
+package analyzer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDictionary = `# 项目代号
+Project Aurora
+[customer]
+ACME Corp
+北极星客户
+# 重复词条只保留一次
+acme corp
+`
+
+// newDictionaryTestAnalyzer 创建加载了测试词典的文本分析器，关闭默认关键词规则以免干扰
+func newDictionaryTestAnalyzer(t *testing.T, dictionary DictionaryConfig) *TextAnalyzer {
+	ta := NewTextAnalyzer(newTestLogger(t)).(*TextAnalyzer)
+	config := DefaultAnalyzerConfig()
+	config.EnableRegexRules = false
+	config.Dictionaries = []DictionaryConfig{dictionary}
+	require.NoError(t, ta.Initialize(config))
+	require.NoError(t, ta.UpdateRules([]*KeywordRule{}))
+	t.Cleanup(func() { ta.Cleanup() })
+	return ta
+}
+
+func writeDictionary(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// dictionaryHits 分析文本，返回按词条排序的命中词条和分类
+func dictionaryHits(t *testing.T, ta *TextAnalyzer, text string) map[string]string {
+	result, err := ta.Analyze(context.Background(), &parser.ParsedData{Body: []byte(text)})
+	require.NoError(t, err)
+
+	hits := make(map[string]string)
+	for _, data := range result.SensitiveData {
+		if data.Metadata["dictionary"] == nil {
+			continue
+		}
+		hits[data.Value] = data.Metadata["category"].(string)
+	}
+	return hits
+}
+
+func TestTextAnalyzer_DictionaryMatching(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.txt")
+	writeDictionary(t, path, testDictionary)
+
+	ta := newDictionaryTestAnalyzer(t, DictionaryConfig{Path: path, Category: "project", WholeWord: true})
+
+	infos := ta.GetDictionaries()
+	require.Len(t, infos, 1)
+	assert.Equal(t, "projects", infos[0].ID)
+	assert.Equal(t, 3, infos[0].Terms)
+	assert.Equal(t, map[string]int{"project": 1, "customer": 2}, infos[0].Categories)
+
+	// 默认不区分大小写，中文词条不要求单词边界
+	hits := dictionaryHits(t, ta, "上线计划：project aurora 交付给 acme CORP 和北极星客户团队")
+	assert.Equal(t, map[string]string{
+		"Project Aurora": "project",
+		"ACME Corp":      "customer",
+		"北极星客户":          "customer",
+	}, hits)
+
+	// 完整单词匹配时，单词的一部分不算命中
+	hits = dictionaryHits(t, ta, "see ACME Corporation and Project Auroras")
+	assert.Empty(t, hits)
+}
+
+func TestTextAnalyzer_DictionaryCaseSensitive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codes.txt")
+	writeDictionary(t, path, "ZEUS\n")
+
+	ta := newDictionaryTestAnalyzer(t, DictionaryConfig{Path: path, CaseSensitive: true})
+
+	assert.Empty(t, dictionaryHits(t, ta, "zeus release notes"))
+	// 未要求完整单词时匹配单词的一部分
+	assert.Equal(t, map[string]string{"ZEUS": "dictionary"}, dictionaryHits(t, ta, "ZEUSX release notes"))
+}
+
+func TestTextAnalyzer_DictionaryReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "projects.txt")
+	writeDictionary(t, path, testDictionary)
+	ta := newDictionaryTestAnalyzer(t, DictionaryConfig{Path: path, Category: "project"})
+
+	// 文件未变化时不重新加载
+	count, err := ta.ReloadDictionaries()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	writeDictionary(t, path, "[project]\nProject Borealis\n")
+	count, err = ta.ReloadDictionaries()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	hits := dictionaryHits(t, ta, "Project Aurora 已被 Project Borealis 取代")
+	assert.Equal(t, map[string]string{"Project Borealis": "project"}, hits)
+
+	// 文件被删除时保留原有词条并记录错误
+	require.NoError(t, os.Remove(path))
+	_, err = ta.ReloadDictionaries()
+	assert.Error(t, err)
+	assert.Contains(t, dictionaryHits(t, ta, "Project Borealis"), "Project Borealis")
+	infos := ta.GetDictionaries()
+	require.Len(t, infos, 1)
+	assert.NotEmpty(t, infos[0].LastError)
+}
+
+func TestParseDictionary(t *testing.T) {
+	categories, order, err := parseDictionary([]byte("\ufeffalpha\n\n[b]\nbeta\n[a]\ngamma\n"), "default")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default", "b", "a"}, order)
+	assert.Equal(t, []string{"alpha"}, categories["default"])
+
+	keys := make([]string, 0, len(categories))
+	for key := range categories {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	assert.Equal(t, []string{"a", "b", "default"}, keys)
+
+	_, _, err = parseDictionary([]byte("[ ]\nterm\n"), "default")
+	assert.Error(t, err)
+}
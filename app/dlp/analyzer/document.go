@@ -0,0 +1,194 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DocumentFormat 结构化文档格式
+type DocumentFormat string
+
+const (
+	DocumentFormatUnknown DocumentFormat = ""
+	DocumentFormatPDF     DocumentFormat = "pdf"
+	DocumentFormatDOCX    DocumentFormat = "docx"
+	DocumentFormatXLSX    DocumentFormat = "xlsx"
+	DocumentFormatPPTX    DocumentFormat = "pptx"
+
+	// DocumentFormatEncryptedOffice 密码保护的 Office 文档，保存在 OLE 复合文档中，无法区分具体格式
+	DocumentFormatEncryptedOffice DocumentFormat = "encrypted_office"
+)
+
+var (
+	// ErrDocumentEncrypted 文档已加密，无法提取内容
+	ErrDocumentEncrypted = errors.New("文档已加密")
+
+	// ErrDocumentCorrupt 文档结构损坏
+	ErrDocumentCorrupt = errors.New("文档已损坏")
+
+	// ErrDocumentTooLarge 文档超过提取大小限制
+	ErrDocumentTooLarge = errors.New("文档超过大小限制")
+)
+
+// DocumentConfig 文档文本提取配置
+type DocumentConfig struct {
+	Enabled       bool  `yaml:"enabled" json:"enabled"`
+	MaxSize       int64 `yaml:"max_size" json:"max_size"`             // 参与提取的最大文档字节数，同时限制OOXML解压后的总字节数
+	MaxPages      int   `yaml:"max_pages" json:"max_pages"`           // 最多提取的页数（PDF页、幻灯片或工作表）
+	MaxTextSize   int   `yaml:"max_text_size" json:"max_text_size"`   // 提取文本的最大字节数，超出部分截断
+	ExtractImages bool  `yaml:"extract_images" json:"extract_images"` // 提取内嵌图像交给OCR
+	MaxImages     int   `yaml:"max_images" json:"max_images"`         // 最多提取的内嵌图像数量
+}
+
+// DefaultDocumentConfig 返回默认文档文本提取配置
+func DefaultDocumentConfig() DocumentConfig {
+	return DocumentConfig{
+		Enabled:       true,
+		MaxSize:       20 * 1024 * 1024, // 20MB
+		MaxPages:      100,
+		MaxTextSize:   5 * 1024 * 1024, // 5MB
+		ExtractImages: true,
+		MaxImages:     10,
+	}
+}
+
+// ExtractedDocument 从文档中提取的内容
+type ExtractedDocument struct {
+	Format    DocumentFormat
+	Text      string
+	Pages     int      // 文档总页数（PDF页、幻灯片或工作表），无法确定时为0
+	Truncated bool     // 因页数或文本大小限制被截断
+	Images    [][]byte // 内嵌图像，仅在启用 ExtractImages 时提取
+}
+
+// DetectDocumentFormat 根据文件头和文档结构识别文档格式
+// OOXML 文档是包含特定部件的ZIP包，按部件名区分 docx、xlsx 和 pptx
+func DetectDocumentFormat(data []byte) DocumentFormat {
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return DocumentFormatPDF
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return DocumentFormatUnknown
+		}
+		for _, file := range reader.File {
+			switch {
+			case file.Name == "word/document.xml":
+				return DocumentFormatDOCX
+			case file.Name == "xl/workbook.xml":
+				return DocumentFormatXLSX
+			case file.Name == "ppt/presentation.xml":
+				return DocumentFormatPPTX
+			}
+		}
+		return DocumentFormatUnknown
+	case isEncryptedOOXML(data):
+		return DocumentFormatEncryptedOffice
+	default:
+		return DocumentFormatUnknown
+	}
+}
+
+// ExtractDocument 从文档中提取文本，可选提取内嵌图像
+// 加密文档返回 ErrDocumentEncrypted，结构损坏返回 ErrDocumentCorrupt
+func ExtractDocument(data []byte, config DocumentConfig) (doc *ExtractedDocument, err error) {
+	if config.MaxSize > 0 && int64(len(data)) > config.MaxSize {
+		return nil, fmt.Errorf("%w: %d > %d", ErrDocumentTooLarge, len(data), config.MaxSize)
+	}
+
+	// 畸形文档可能触发解析器的越界访问，按损坏处理
+	defer func() {
+		if r := recover(); r != nil {
+			doc = nil
+			err = fmt.Errorf("%w: %v", ErrDocumentCorrupt, r)
+		}
+	}()
+
+	switch format := DetectDocumentFormat(data); format {
+	case DocumentFormatEncryptedOffice:
+		return nil, fmt.Errorf("%w: Office 文档使用了密码保护", ErrDocumentEncrypted)
+	case DocumentFormatPDF:
+		return extractPDF(data, config)
+	case DocumentFormatDOCX, DocumentFormatXLSX, DocumentFormatPPTX:
+		return extractOOXML(data, format, config)
+	default:
+		return nil, fmt.Errorf("不支持的文档格式")
+	}
+}
+
+// isEncryptedOOXML 密码保护的 OOXML 文档是包含 EncryptedPackage 流的 OLE 复合文档
+func isEncryptedOOXML(data []byte) bool {
+	if !bytes.HasPrefix(data, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}) {
+		return false
+	}
+	return bytes.Contains(data, utf16LE("EncryptedPackage"))
+}
+
+// utf16LE 将ASCII字符串编码为UTF-16LE，用于在OLE目录中查找流名称
+func utf16LE(s string) []byte {
+	encoded := make([]byte, 0, len(s)*2)
+	for i := 0; i < len(s); i++ {
+		encoded = append(encoded, s[i], 0)
+	}
+	return encoded
+}
+
+// textCollector 按大小限制收集提取的文本
+type textCollector struct {
+	builder   strings.Builder
+	limit     int
+	truncated bool
+}
+
+// write 写入文本，超出限制时截断并返回 false
+func (c *textCollector) write(s string) bool {
+	if c.truncated {
+		return false
+	}
+	if c.limit > 0 && c.builder.Len()+len(s) > c.limit {
+		remaining := c.limit - c.builder.Len()
+		// 不在多字节字符中间截断
+		for remaining > 0 && remaining < len(s) && !isRuneStart(s[remaining]) {
+			remaining--
+		}
+		c.builder.WriteString(s[:remaining])
+		c.truncated = true
+		return false
+	}
+	c.builder.WriteString(s)
+	return true
+}
+
+// newline 在文本末尾没有换行时写入换行
+func (c *textCollector) newline() {
+	if last, ok := c.lastByte(); ok && last != '\n' {
+		c.write("\n")
+	}
+}
+
+// space 在文本末尾没有空白时写入空格
+func (c *textCollector) space() {
+	if last, ok := c.lastByte(); ok && last != ' ' && last != '\n' && last != '\t' {
+		c.write(" ")
+	}
+}
+
+func (c *textCollector) lastByte() (byte, bool) {
+	text := c.builder.String()
+	if len(text) == 0 {
+		return 0, false
+	}
+	return text[len(text)-1], true
+}
+
+func (c *textCollector) String() string {
+	return strings.TrimSpace(c.builder.String())
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,368 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// errDocumentBudget 解压数据超过文档大小限制
+var errDocumentBudget = errors.New("解压数据超过文档大小限制")
+
+// ooxmlPackage OOXML 文档包
+type ooxmlPackage struct {
+	files  map[string]*zip.File
+	budget int64 // 剩余可解压字节数，小于0表示不限制
+	config DocumentConfig
+}
+
+// extractOOXML 从 docx、xlsx 或 pptx 中提取文本
+func extractOOXML(data []byte, format DocumentFormat, config DocumentConfig) (*ExtractedDocument, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDocumentCorrupt, err)
+	}
+
+	pkg := &ooxmlPackage{
+		files:  make(map[string]*zip.File, len(reader.File)),
+		budget: -1,
+		config: config,
+	}
+	if config.MaxSize > 0 {
+		pkg.budget = config.MaxSize
+	}
+	for _, file := range reader.File {
+		pkg.files[file.Name] = file
+	}
+
+	doc := &ExtractedDocument{Format: format}
+	out := &textCollector{limit: config.MaxTextSize}
+	switch format {
+	case DocumentFormatDOCX:
+		err = pkg.extractDOCX(doc, out)
+	case DocumentFormatXLSX:
+		err = pkg.extractXLSX(doc, out)
+	case DocumentFormatPPTX:
+		err = pkg.extractPPTX(doc, out)
+	}
+	switch {
+	case errors.Is(err, errDocumentBudget):
+		doc.Truncated = true
+	case err != nil:
+		return nil, err
+	}
+
+	if config.ExtractImages {
+		doc.Images = pkg.images(config.MaxImages)
+	}
+	doc.Text = out.String()
+	doc.Truncated = doc.Truncated || out.truncated
+	return doc, nil
+}
+
+// extractDOCX 提取正文、页眉页脚、脚注尾注和批注
+func (p *ooxmlPackage) extractDOCX(doc *ExtractedDocument, out *textCollector) error {
+	if err := p.extractText("word/document.xml", out, true); err != nil {
+		return err
+	}
+	for _, name := range p.partNames("word/", "header", "footer", "footnotes", "endnotes", "comments") {
+		out.newline()
+		if err := p.extractText(name, out, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractPPTX 按幻灯片顺序提取文本，幻灯片数量受页数限制
+func (p *ooxmlPackage) extractPPTX(doc *ExtractedDocument, out *textCollector) error {
+	slides := p.partNames("ppt/slides/", "slide")
+	doc.Pages = len(slides)
+	for i, name := range slides {
+		if p.config.MaxPages > 0 && i >= p.config.MaxPages {
+			doc.Truncated = true
+			break
+		}
+		out.newline()
+		if err := p.extractText(name, out, i == 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractXLSX 按工作表顺序提取单元格，单元格以制表符分隔，行以换行分隔，工作表数量受页数限制
+func (p *ooxmlPackage) extractXLSX(doc *ExtractedDocument, out *textCollector) error {
+	sharedStrings, err := p.sharedStrings()
+	if err != nil {
+		return err
+	}
+
+	sheets := p.partNames("xl/worksheets/", "sheet")
+	doc.Pages = len(sheets)
+	for i, name := range sheets {
+		if p.config.MaxPages > 0 && i >= p.config.MaxPages {
+			doc.Truncated = true
+			break
+		}
+		out.newline()
+		if err := p.extractSheet(name, sharedStrings, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sharedStrings 读取共享字符串表
+func (p *ooxmlPackage) sharedStrings() ([]string, error) {
+	var strs []string
+	var current strings.Builder
+	inText := false
+	err := p.decode("xl/sharedStrings.xml", false, func(token xml.Token) bool {
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				current.Reset()
+			case "t":
+				inText = true
+			case "rPh": // 注音文字不属于单元格内容
+				inText = false
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				strs = append(strs, current.String())
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				current.Write(t)
+			}
+		}
+		return true
+	})
+	return strs, err
+}
+
+// extractSheet 提取工作表中的单元格值
+func (p *ooxmlPackage) extractSheet(name string, sharedStrings []string, out *textCollector) error {
+	var cellType string
+	var value strings.Builder
+	inValue := false
+	firstCell := true
+	return p.decode(name, false, func(token xml.Token) bool {
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				firstCell = true
+			case "c":
+				cellType = ""
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "t" {
+						cellType = attr.Value
+					}
+				}
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				text := value.String()
+				if cellType == "s" {
+					index, err := strconv.Atoi(strings.TrimSpace(text))
+					if err != nil || index < 0 || index >= len(sharedStrings) {
+						return true
+					}
+					text = sharedStrings[index]
+				}
+				if text == "" {
+					return true
+				}
+				if !firstCell {
+					out.write("\t")
+				}
+				firstCell = false
+				return out.write(text)
+			case "row":
+				out.newline()
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+		return true
+	})
+}
+
+// extractText 提取 WordprocessingML 或 DrawingML 部件中的文本
+// 段落结束时换行，制表符和换行元素转换为对应字符
+func (p *ooxmlPackage) extractText(name string, out *textCollector, required bool) error {
+	inText := false
+	return p.decode(name, required, func(token xml.Token) bool {
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				return out.write("\t")
+			case "br", "cr":
+				return out.write("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				out.newline()
+			}
+		case xml.CharData:
+			if inText {
+				return out.write(string(t))
+			}
+		}
+		return true
+	})
+}
+
+// decode 逐个读取部件中的XML标记，handle 返回 false 时停止
+// 部件不存在时，required 为 true 返回损坏错误，否则忽略
+func (p *ooxmlPackage) decode(name string, required bool, handle func(xml.Token) bool) error {
+	file, ok := p.files[name]
+	if !ok {
+		if required {
+			return fmt.Errorf("%w: 缺少部件 %s", ErrDocumentCorrupt, name)
+		}
+		return nil
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: 打开部件 %s 失败: %v", ErrDocumentCorrupt, name, err)
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(&budgetReader{reader: rc, budget: &p.budget})
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if errors.Is(err, errDocumentBudget) {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("%w: 解析部件 %s 失败: %v", ErrDocumentCorrupt, name, err)
+		}
+		if !handle(token) {
+			return nil
+		}
+	}
+}
+
+// partNames 返回目录下以指定前缀命名的XML部件，按名称中的序号排序
+func (p *ooxmlPackage) partNames(dir string, prefixes ...string) []string {
+	var names []string
+	for name := range p.files {
+		if path.Dir(name)+"/" != dir || path.Ext(name) != ".xml" {
+			continue
+		}
+		base := path.Base(name)
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(base, prefix) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ni, nj := partNumber(names[i]), partNumber(names[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// partNumber 返回部件名称中的序号，如 slide12.xml 返回 12
+func partNumber(name string) int {
+	base := strings.TrimSuffix(path.Base(name), ".xml")
+	end := len(base)
+	start := end
+	for start > 0 && base[start-1] >= '0' && base[start-1] <= '9' {
+		start--
+	}
+	n, err := strconv.Atoi(base[start:end])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// images 提取 media 目录中可识别的内嵌图像
+func (p *ooxmlPackage) images(limit int) [][]byte {
+	var names []string
+	for name := range p.files {
+		if strings.Contains(name, "/media/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var images [][]byte
+	for _, name := range names {
+		if limit > 0 && len(images) >= limit {
+			break
+		}
+		rc, err := p.files[name].Open()
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(&budgetReader{reader: rc, budget: &p.budget})
+		rc.Close()
+		if err != nil {
+			break
+		}
+		if DetectImageType(data).IsImage() {
+			images = append(images, data)
+		}
+	}
+	return images
+}
+
+// budgetReader 按剩余预算读取数据，防止压缩炸弹
+type budgetReader struct {
+	reader io.Reader
+	budget *int64
+}
+
+func (r *budgetReader) Read(b []byte) (int, error) {
+	if *r.budget < 0 {
+		return r.reader.Read(b)
+	}
+	if *r.budget == 0 {
+		return 0, errDocumentBudget
+	}
+	if int64(len(b)) > *r.budget {
+		b = b[:*r.budget]
+	}
+	n, err := r.reader.Read(b)
+	*r.budget -= int64(n)
+	return n, err
+}
@@ -0,0 +1,1021 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PDF 文本提取只解析文本提取需要的结构：对象表、对象流、页面树、内容流和字体的 ToUnicode 映射。
+// 不支持的编码（如无 ToUnicode 的复合字体）按单字节编码解码，提取结果可能不完整
+
+var (
+	pdfObjectHeaderPattern = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	pdfRefPattern          = regexp.MustCompile(`(\d+)\s+\d+\s+R\b`)
+	pdfLengthPattern       = regexp.MustCompile(`/Length\s+(\d+)(\s+\d+\s+R)?`)
+	pdfFilterPattern       = regexp.MustCompile(`/Filter\s*(\[[^\]]*\]|/[^\s/<>\[\]()]+)`)
+	pdfNamePattern         = regexp.MustCompile(`/([^\s/<>\[\]()]+)`)
+	pdfTypePagePattern     = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfTypeCatalogPattern  = regexp.MustCompile(`/Type\s*/Catalog\b`)
+	pdfTypeObjStmPattern   = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	pdfTypeXRefPattern     = regexp.MustCompile(`/Type\s*/XRef\b`)
+	pdfSubtypeImagePattern = regexp.MustCompile(`/Subtype\s*/Image\b`)
+	pdfFontEntryPattern    = regexp.MustCompile(`/([^\s/<>\[\]()]+)\s+(\d+)\s+\d+\s+R\b`)
+	pdfBfcharPattern       = regexp.MustCompile(`(?s)beginbfchar(.*?)endbfchar`)
+	pdfBfrangePattern      = regexp.MustCompile(`(?s)beginbfrange(.*?)endbfrange`)
+	pdfBfcharEntryPattern  = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]*)>`)
+	pdfBfrangeEntryPattern = regexp.MustCompile(`<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>\s*(<[0-9A-Fa-f]*>|\[[^\]]*\])`)
+	pdfHexPattern          = regexp.MustCompile(`<([0-9A-Fa-f]*)>`)
+)
+
+// pdfObject PDF间接对象
+type pdfObject struct {
+	dict      []byte // 对象的值，流对象为流之前的字典
+	raw       []byte // 未解码的流数据
+	hasStream bool
+}
+
+// pdfDocument 解析后的PDF对象表
+type pdfDocument struct {
+	data    []byte
+	objects map[int]*pdfObject
+	budget  int64 // 剩余可解码字节数，小于0表示不限制
+	cmaps   map[int]*pdfCMap
+}
+
+// pdfPage PDF页面
+type pdfPage struct {
+	contents  []int  // 内容流对象编号
+	resources []byte // 资源字典，可能继承自父节点
+}
+
+// extractPDF 从PDF中提取文本
+func extractPDF(data []byte, config DocumentConfig) (*ExtractedDocument, error) {
+	budget := int64(-1)
+	if config.MaxSize > 0 {
+		budget = config.MaxSize
+	}
+	doc, err := parsePDF(data, budget)
+	if err != nil {
+		return nil, err
+	}
+	if doc.encrypted() {
+		return nil, fmt.Errorf("%w: PDF 使用了加密", ErrDocumentEncrypted)
+	}
+
+	result := &ExtractedDocument{Format: DocumentFormatPDF}
+	out := &textCollector{limit: config.MaxTextSize}
+
+	pages := doc.pages()
+	result.Pages = len(pages)
+	if len(pages) == 0 {
+		// 找不到页面树时，提取所有包含文本块的内容流
+		doc.extractLooseContent(out)
+	}
+	for i, page := range pages {
+		if config.MaxPages > 0 && i >= config.MaxPages {
+			result.Truncated = true
+			break
+		}
+		out.newline()
+		if !doc.extractPage(page, out) {
+			break
+		}
+	}
+
+	if config.ExtractImages {
+		result.Images = doc.images(config.MaxImages)
+	}
+	result.Text = out.String()
+	result.Truncated = result.Truncated || out.truncated || doc.budget == 0
+	return result, nil
+}
+
+// parsePDF 扫描文件中的间接对象，并展开对象流中的对象
+// 增量更新中后出现的同号对象覆盖先出现的；budget 限制解码流的总字节数，小于0表示不限制
+func parsePDF(data []byte, budget int64) (*pdfDocument, error) {
+	doc := &pdfDocument{
+		data:    data,
+		objects: make(map[int]*pdfObject),
+		budget:  budget,
+		cmaps:   make(map[int]*pdfCMap),
+	}
+
+	pos := 0
+	for _, match := range pdfObjectHeaderPattern.FindAllSubmatchIndex(data, -1) {
+		if match[0] < pos {
+			continue // 位于上一个对象的流数据中
+		}
+		num, err := strconv.Atoi(string(data[match[2]:match[3]]))
+		if err != nil {
+			continue
+		}
+		obj, end := parsePDFObject(data, match[1])
+		doc.objects[num] = obj
+		pos = end
+	}
+	if len(doc.objects) == 0 {
+		return nil, fmt.Errorf("%w: 未找到PDF对象", ErrDocumentCorrupt)
+	}
+
+	doc.expandObjectStreams()
+	return doc, nil
+}
+
+// parsePDFObject 解析从 start 开始的对象内容，返回对象和对象结束位置
+func parsePDFObject(data []byte, start int) (*pdfObject, int) {
+	endobj := indexFrom(data, start, "endobj")
+	streamStart := indexFrom(data, start, "stream")
+	if streamStart < 0 || (endobj >= 0 && streamStart > endobj) {
+		if endobj < 0 {
+			return &pdfObject{dict: data[start:]}, len(data)
+		}
+		return &pdfObject{dict: data[start:endobj]}, endobj + len("endobj")
+	}
+
+	obj := &pdfObject{dict: data[start:streamStart], hasStream: true}
+
+	// 流数据从 stream 关键字后的换行开始
+	begin := streamStart + len("stream")
+	if begin < len(data) && data[begin] == '\r' {
+		begin++
+	}
+	if begin < len(data) && data[begin] == '\n' {
+		begin++
+	}
+
+	// 优先使用直接给出的长度，长度为间接引用或与数据不符时查找 endstream
+	end := -1
+	if match := pdfLengthPattern.FindSubmatch(obj.dict); match != nil && len(match[2]) == 0 {
+		if length, err := strconv.Atoi(string(match[1])); err == nil && begin+length <= len(data) {
+			rest := bytes.TrimLeft(data[begin+length:], "\r\n \t")
+			if bytes.HasPrefix(rest, []byte("endstream")) {
+				end = begin + length
+			}
+		}
+	}
+	if end < 0 {
+		end = indexFrom(data, begin, "endstream")
+		if end < 0 {
+			obj.raw = data[begin:]
+			return obj, len(data)
+		}
+	}
+	obj.raw = data[begin:end]
+
+	next := indexFrom(data, end, "endobj")
+	if next < 0 {
+		return obj, len(data)
+	}
+	return obj, next + len("endobj")
+}
+
+// expandObjectStreams 展开对象流（PDF 1.5）中压缩保存的对象
+func (doc *pdfDocument) expandObjectStreams() {
+	for _, stream := range doc.objects {
+		if !stream.hasStream || !pdfTypeObjStmPattern.Match(stream.dict) {
+			continue
+		}
+		decoded, err := doc.decodeStream(stream)
+		if err != nil {
+			continue
+		}
+		first, ok := pdfIntValue(stream.dict, "First")
+		if !ok || first > len(decoded) {
+			continue
+		}
+
+		header := strings.Fields(string(decoded[:first]))
+		for i := 0; i+1 < len(header); i += 2 {
+			num, err1 := strconv.Atoi(header[i])
+			offset, err2 := strconv.Atoi(header[i+1])
+			if err1 != nil || err2 != nil || first+offset > len(decoded) {
+				continue
+			}
+			end := len(decoded)
+			if i+3 < len(header) {
+				if next, err := strconv.Atoi(header[i+3]); err == nil && first+next <= end && next >= offset {
+					end = first + next
+				}
+			}
+			if _, exists := doc.objects[num]; !exists {
+				doc.objects[num] = &pdfObject{dict: decoded[first+offset : end]}
+			}
+		}
+	}
+}
+
+// encrypted 检查文件尾或交叉引用流字典中是否声明了加密
+func (doc *pdfDocument) encrypted() bool {
+	for offset := 0; ; {
+		index := indexFrom(doc.data, offset, "trailer")
+		if index < 0 {
+			break
+		}
+		end := index + 4096
+		if end > len(doc.data) {
+			end = len(doc.data)
+		}
+		trailer := doc.data[index:end]
+		if startxref := bytes.Index(trailer, []byte("startxref")); startxref >= 0 {
+			trailer = trailer[:startxref]
+		}
+		if bytes.Contains(trailer, []byte("/Encrypt")) {
+			return true
+		}
+		offset = index + len("trailer")
+	}
+
+	for _, obj := range doc.objects {
+		if pdfTypeXRefPattern.Match(obj.dict) && bytes.Contains(obj.dict, []byte("/Encrypt")) {
+			return true
+		}
+	}
+	return false
+}
+
+// pages 按页面树顺序返回页面
+func (doc *pdfDocument) pages() []pdfPage {
+	var pages []pdfPage
+	visited := make(map[int]bool)
+	for _, obj := range doc.sortedObjects() {
+		if !pdfTypeCatalogPattern.Match(obj.dict) {
+			continue
+		}
+		if root, ok := pdfRef(pdfDictValue(obj.dict, "Pages")); ok {
+			doc.walkPages(root, nil, visited, &pages)
+		}
+		if len(pages) > 0 {
+			break
+		}
+	}
+	return pages
+}
+
+// walkPages 遍历页面树，页面继承父节点的资源字典
+func (doc *pdfDocument) walkPages(num int, inherited []byte, visited map[int]bool, pages *[]pdfPage) {
+	if visited[num] {
+		return
+	}
+	visited[num] = true
+
+	obj, ok := doc.objects[num]
+	if !ok {
+		return
+	}
+	resources := pdfDictValue(obj.dict, "Resources")
+	if resources == nil {
+		resources = inherited
+	}
+
+	if pdfTypePagePattern.Match(obj.dict) {
+		*pages = append(*pages, pdfPage{
+			contents:  doc.refs(pdfDictValue(obj.dict, "Contents")),
+			resources: resources,
+		})
+		return
+	}
+	for _, kid := range doc.refs(pdfDictValue(obj.dict, "Kids")) {
+		doc.walkPages(kid, resources, visited, pages)
+	}
+}
+
+// extractPage 提取页面文本，文本达到大小限制时返回 false
+func (doc *pdfDocument) extractPage(page pdfPage, out *textCollector) bool {
+	extractor := &pdfTextExtractor{out: out, fonts: doc.fonts(page.resources)}
+	for _, num := range page.contents {
+		obj, ok := doc.objects[num]
+		if !ok || !obj.hasStream {
+			continue
+		}
+		content, err := doc.decodeStream(obj)
+		if err != nil {
+			continue
+		}
+		if !extractor.run(content) {
+			return false
+		}
+		out.space()
+	}
+	return true
+}
+
+// extractLooseContent 提取所有包含文本块的流，用于无法解析页面树的文档
+func (doc *pdfDocument) extractLooseContent(out *textCollector) {
+	extractor := &pdfTextExtractor{out: out}
+	for _, obj := range doc.sortedObjects() {
+		if !obj.hasStream || pdfSubtypeImagePattern.Match(obj.dict) || pdfTypeObjStmPattern.Match(obj.dict) || pdfTypeXRefPattern.Match(obj.dict) {
+			continue
+		}
+		content, err := doc.decodeStream(obj)
+		if err != nil || !bytes.Contains(content, []byte("BT")) {
+			continue
+		}
+		out.newline()
+		if !extractor.run(content) {
+			return
+		}
+	}
+}
+
+// fonts 读取资源字典中字体的 ToUnicode 映射
+func (doc *pdfDocument) fonts(resources []byte) map[string]*pdfCMap {
+	fonts := make(map[string]*pdfCMap)
+	fontDict := doc.resolve(pdfDictValue(doc.resolve(resources), "Font"))
+	for _, match := range pdfFontEntryPattern.FindAllSubmatch(fontDict, -1) {
+		num, err := strconv.Atoi(string(match[2]))
+		if err != nil {
+			continue
+		}
+		if cmap := doc.toUnicode(num); cmap != nil {
+			fonts[string(match[1])] = cmap
+		}
+	}
+	return fonts
+}
+
+// toUnicode 读取字体对象的 ToUnicode 映射
+func (doc *pdfDocument) toUnicode(fontNum int) *pdfCMap {
+	if cmap, ok := doc.cmaps[fontNum]; ok {
+		return cmap
+	}
+	doc.cmaps[fontNum] = nil
+
+	font, ok := doc.objects[fontNum]
+	if !ok {
+		return nil
+	}
+	ref, ok := pdfRef(pdfDictValue(font.dict, "ToUnicode"))
+	if !ok {
+		return nil
+	}
+	stream, ok := doc.objects[ref]
+	if !ok || !stream.hasStream {
+		return nil
+	}
+	data, err := doc.decodeStream(stream)
+	if err != nil {
+		return nil
+	}
+	cmap := parsePDFCMap(data)
+	doc.cmaps[fontNum] = cmap
+	return cmap
+}
+
+// images 提取 DCT 编码（JPEG）的图像，其他编码的图像需要还原像素数据，不提取
+func (doc *pdfDocument) images(limit int) [][]byte {
+	var images [][]byte
+	for _, obj := range doc.sortedObjects() {
+		if limit > 0 && len(images) >= limit {
+			break
+		}
+		if !obj.hasStream || !pdfSubtypeImagePattern.Match(obj.dict) {
+			continue
+		}
+		if filters := pdfFilters(obj.dict); len(filters) == 1 && (filters[0] == "DCTDecode" || filters[0] == "DCT") {
+			images = append(images, obj.raw)
+		}
+	}
+	return images
+}
+
+// decodeStream 按过滤器解码流数据，仅支持 FlateDecode
+func (doc *pdfDocument) decodeStream(obj *pdfObject) ([]byte, error) {
+	data := obj.raw
+	for _, filter := range pdfFilters(obj.dict) {
+		switch filter {
+		case "FlateDecode", "Fl":
+			reader, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			decoded, err := io.ReadAll(&budgetReader{reader: reader, budget: &doc.budget})
+			reader.Close()
+			// 校验和错误时保留已解码的数据
+			if err != nil && len(decoded) == 0 {
+				return nil, err
+			}
+			data = decoded
+		default:
+			return nil, fmt.Errorf("不支持的过滤器: %s", filter)
+		}
+	}
+	return data, nil
+}
+
+// resolve 将间接引用解析为对象的值
+func (doc *pdfDocument) resolve(value []byte) []byte {
+	if num, ok := pdfRef(value); ok {
+		if obj, exists := doc.objects[num]; exists {
+			return obj.dict
+		}
+		return nil
+	}
+	return value
+}
+
+// refs 返回引用或引用数组中的对象编号，引用指向数组对象时展开该数组
+func (doc *pdfDocument) refs(value []byte) []int {
+	value = bytes.TrimSpace(value)
+	if num, ok := pdfRef(value); ok {
+		if obj, exists := doc.objects[num]; exists && !obj.hasStream && bytes.HasPrefix(bytes.TrimSpace(obj.dict), []byte("[")) {
+			value = obj.dict
+		} else {
+			return []int{num}
+		}
+	}
+
+	var nums []int
+	for _, match := range pdfRefPattern.FindAllSubmatch(value, -1) {
+		if num, err := strconv.Atoi(string(match[1])); err == nil {
+			nums = append(nums, num)
+		}
+	}
+	return nums
+}
+
+// sortedObjects 按对象编号顺序返回对象
+func (doc *pdfDocument) sortedObjects() []*pdfObject {
+	nums := make([]int, 0, len(doc.objects))
+	for num := range doc.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+
+	objects := make([]*pdfObject, len(nums))
+	for i, num := range nums {
+		objects[i] = doc.objects[num]
+	}
+	return objects
+}
+
+// pdfFilters 返回流字典中的过滤器名称
+func pdfFilters(dict []byte) []string {
+	match := pdfFilterPattern.FindSubmatch(dict)
+	if match == nil {
+		return nil
+	}
+	var filters []string
+	for _, name := range pdfNamePattern.FindAllSubmatch(match[1], -1) {
+		filters = append(filters, string(name[1]))
+	}
+	return filters
+}
+
+// pdfRef 解析形如 "12 0 R" 的间接引用
+func pdfRef(value []byte) (int, bool) {
+	value = bytes.TrimSpace(value)
+	match := pdfRefPattern.FindSubmatchIndex(value)
+	if match == nil || match[0] != 0 || match[1] != len(value) {
+		return 0, false
+	}
+	num, err := strconv.Atoi(string(value[match[2]:match[3]]))
+	return num, err == nil
+}
+
+// pdfIntValue 读取字典中的整数值
+func pdfIntValue(dict []byte, key string) (int, bool) {
+	n, err := strconv.Atoi(string(bytes.TrimSpace(pdfDictValue(dict, key))))
+	return n, err == nil
+}
+
+// pdfDictValue 返回字典中键对应的原始值：字典、数组、间接引用或单个标记
+func pdfDictValue(dict []byte, key string) []byte {
+	name := []byte("/" + key)
+	for offset := 0; offset < len(dict); {
+		index := bytes.Index(dict[offset:], name)
+		if index < 0 {
+			return nil
+		}
+		start := offset + index + len(name)
+		offset = start
+		if start < len(dict) && isPDFRegular(dict[start]) {
+			continue // 只是前缀相同的其他键
+		}
+
+		for start < len(dict) && isPDFWhitespace(dict[start]) {
+			start++
+		}
+		if start >= len(dict) {
+			return nil
+		}
+		rest := dict[start:]
+		switch {
+		case bytes.HasPrefix(rest, []byte("<<")):
+			return rest[:pdfBalancedEnd(rest, "<<", ">>")]
+		case rest[0] == '[':
+			return rest[:pdfBalancedEnd(rest, "[", "]")]
+		}
+		if match := pdfRefPattern.FindIndex(rest); match != nil && match[0] == 0 {
+			return rest[:match[1]]
+		}
+		end := 1
+		for end < len(rest) && isPDFRegular(rest[end]) {
+			end++
+		}
+		return rest[:end]
+	}
+	return nil
+}
+
+// pdfBalancedEnd 返回与开头分隔符配对的结束分隔符之后的位置
+func pdfBalancedEnd(data []byte, open, close string) int {
+	depth := 0
+	for i := 0; i < len(data); {
+		switch {
+		case data[i] == '(':
+			i = skipPDFLiteralString(data, i)
+			continue
+		case bytes.HasPrefix(data[i:], []byte(open)):
+			depth++
+			i += len(open)
+			continue
+		case bytes.HasPrefix(data[i:], []byte(close)):
+			depth--
+			i += len(close)
+			if depth == 0 {
+				return i
+			}
+			continue
+		}
+		i++
+	}
+	return len(data)
+}
+
+// skipPDFLiteralString 跳过从 start 开始的字面字符串，返回结束后的位置
+func skipPDFLiteralString(data []byte, start int) int {
+	depth := 0
+	for i := start; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(data)
+}
+
+func indexFrom(data []byte, from int, sep string) int {
+	if from >= len(data) {
+		return -1
+	}
+	index := bytes.Index(data[from:], []byte(sep))
+	if index < 0 {
+		return -1
+	}
+	return from + index
+}
+
+func isPDFWhitespace(b byte) bool {
+	switch b {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isPDFDelimiter(b byte) bool {
+	switch b {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func isPDFRegular(b byte) bool {
+	return !isPDFWhitespace(b) && !isPDFDelimiter(b)
+}
+
+// pdfCMap ToUnicode 映射，按编码字节数分别保存
+type pdfCMap struct {
+	codes map[int]map[uint32]string
+}
+
+// parsePDFCMap 解析 ToUnicode CMap 中的 bfchar 和 bfrange
+func parsePDFCMap(data []byte) *pdfCMap {
+	cmap := &pdfCMap{codes: make(map[int]map[uint32]string)}
+
+	for _, section := range pdfBfcharPattern.FindAllSubmatch(data, -1) {
+		for _, entry := range pdfBfcharEntryPattern.FindAllSubmatch(section[1], -1) {
+			src, err := hex.DecodeString(string(entry[1]))
+			if err != nil || len(src) == 0 || len(src) > 4 {
+				continue
+			}
+			cmap.set(src, decodeUTF16BE(decodeHexString(entry[2])))
+		}
+	}
+
+	for _, section := range pdfBfrangePattern.FindAllSubmatch(data, -1) {
+		for _, entry := range pdfBfrangeEntryPattern.FindAllSubmatch(section[1], -1) {
+			lo, err1 := hex.DecodeString(string(entry[1]))
+			hi, err2 := hex.DecodeString(string(entry[2]))
+			if err1 != nil || err2 != nil || len(lo) == 0 || len(lo) > 4 || len(lo) != len(hi) {
+				continue
+			}
+			start, end := bytesToUint(lo), bytesToUint(hi)
+			if end < start || end-start > 0xFFFF {
+				continue
+			}
+
+			if entry[3][0] == '[' {
+				// 数组形式为区间内每个编码单独给出目标字符
+				for i, dst := range pdfHexPattern.FindAllSubmatch(entry[3], -1) {
+					if start+uint32(i) > end {
+						break
+					}
+					cmap.setCode(len(lo), start+uint32(i), decodeUTF16BE(decodeHexString(dst[1])))
+				}
+				continue
+			}
+
+			// 目标字符串的最后一个UTF-16单元随编码递增
+			units := utf16Units(decodeHexString(entry[3][1 : len(entry[3])-1]))
+			if len(units) == 0 {
+				continue
+			}
+			for code := start; code <= end; code++ {
+				shifted := append([]uint16(nil), units...)
+				shifted[len(shifted)-1] += uint16(code - start)
+				cmap.setCode(len(lo), code, string(utf16.Decode(shifted)))
+			}
+		}
+	}
+	return cmap
+}
+
+func (c *pdfCMap) set(src []byte, dst string) {
+	c.setCode(len(src), bytesToUint(src), dst)
+}
+
+func (c *pdfCMap) setCode(length int, code uint32, dst string) {
+	if c.codes[length] == nil {
+		c.codes[length] = make(map[uint32]string)
+	}
+	c.codes[length][code] = dst
+}
+
+// decode 按映射解码字符串，优先匹配较短的编码，无法映射的字节被跳过
+func (c *pdfCMap) decode(data []byte) string {
+	lengths := make([]int, 0, len(c.codes))
+	for length := range c.codes {
+		lengths = append(lengths, length)
+	}
+	sort.Ints(lengths)
+	if len(lengths) == 0 {
+		return decodePDFString(data)
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(data); {
+		matched := false
+		for _, length := range lengths {
+			if i+length > len(data) {
+				break
+			}
+			if dst, ok := c.codes[length][bytesToUint(data[i:i+length])]; ok {
+				b.WriteString(dst)
+				i += length
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i += lengths[0]
+		}
+	}
+	return b.String()
+}
+
+// decodePDFString 解码没有 ToUnicode 映射的字符串：带BOM时按UTF-16BE，否则按单字节编码
+func decodePDFString(data []byte) string {
+	if bytes.HasPrefix(data, []byte{0xFE, 0xFF}) {
+		return decodeUTF16BE(data[2:])
+	}
+	runes := make([]rune, 0, len(data))
+	for _, b := range data {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' {
+			continue
+		}
+		runes = append(runes, rune(b))
+	}
+	return string(runes)
+}
+
+func decodeUTF16BE(data []byte) string {
+	return string(utf16.Decode(utf16Units(data)))
+}
+
+func utf16Units(data []byte) []uint16 {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+	}
+	return units
+}
+
+// decodeHexString 解码十六进制字符串，忽略空白，奇数位时末尾补0
+func decodeHexString(data []byte) []byte {
+	digits := make([]byte, 0, len(data)+1)
+	for _, b := range data {
+		if !isPDFWhitespace(b) {
+			digits = append(digits, b)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	decoded := make([]byte, hex.DecodedLen(len(digits)))
+	n, _ := hex.Decode(decoded, digits)
+	return decoded[:n]
+}
+
+func bytesToUint(data []byte) uint32 {
+	var v uint32
+	for _, b := range data {
+		v = v<<8 | uint32(b)
+	}
+	return v
+}
+
+// pdfTokenKind 内容流标记类型
+type pdfTokenKind int
+
+const (
+	pdfTokenOperator pdfTokenKind = iota
+	pdfTokenString
+	pdfTokenNumber
+	pdfTokenName
+	pdfTokenArray
+	pdfTokenArrayEnd
+	pdfTokenOther
+)
+
+// pdfToken 内容流标记
+type pdfToken struct {
+	kind   pdfTokenKind
+	value  []byte // 字符串内容、名称或操作符
+	number float64
+	array  []pdfToken
+}
+
+// pdfLexer 内容流词法分析器
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+// next 读取下一个标记，数组作为一个标记返回
+func (l *pdfLexer) next() (pdfToken, bool) {
+	l.skipWhitespace()
+	if l.pos >= len(l.data) {
+		return pdfToken{}, false
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return pdfToken{kind: pdfTokenString, value: l.literalString()}, true
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return pdfToken{kind: pdfTokenOther}, true
+	case c == '<':
+		end := indexFrom(l.data, l.pos, ">")
+		if end < 0 {
+			end = len(l.data)
+		}
+		value := decodeHexString(l.data[l.pos+1 : end])
+		l.pos = end + 1
+		return pdfToken{kind: pdfTokenString, value: value}, true
+	case c == '>':
+		l.pos++
+		if l.pos < len(l.data) && l.data[l.pos] == '>' {
+			l.pos++
+		}
+		return pdfToken{kind: pdfTokenOther}, true
+	case c == '[':
+		l.pos++
+		var array []pdfToken
+		for {
+			token, ok := l.next()
+			if !ok || token.kind == pdfTokenArrayEnd {
+				break
+			}
+			array = append(array, token)
+		}
+		return pdfToken{kind: pdfTokenArray, array: array}, true
+	case c == ']':
+		l.pos++
+		return pdfToken{kind: pdfTokenArrayEnd}, true
+	case c == '/':
+		start := l.pos + 1
+		l.pos = start
+		for l.pos < len(l.data) && isPDFRegular(l.data[l.pos]) {
+			l.pos++
+		}
+		return pdfToken{kind: pdfTokenName, value: l.data[start:l.pos]}, true
+	case isPDFDelimiter(c):
+		l.pos++
+		return pdfToken{kind: pdfTokenOther}, true
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && isPDFRegular(l.data[l.pos]) {
+		l.pos++
+	}
+	word := l.data[start:l.pos]
+	if c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9') {
+		if number, err := strconv.ParseFloat(string(word), 64); err == nil {
+			return pdfToken{kind: pdfTokenNumber, number: number}, true
+		}
+	}
+	return pdfToken{kind: pdfTokenOperator, value: word}, true
+}
+
+func (l *pdfLexer) skipWhitespace() {
+	for l.pos < len(l.data) {
+		switch {
+		case isPDFWhitespace(l.data[l.pos]):
+			l.pos++
+		case l.data[l.pos] == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// literalString 读取字面字符串并处理转义
+func (l *pdfLexer) literalString() []byte {
+	var value []byte
+	depth := 1
+	l.pos++
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '\\':
+			if l.pos >= len(l.data) {
+				return value
+			}
+			escaped := l.data[l.pos]
+			l.pos++
+			switch escaped {
+			case 'n':
+				value = append(value, '\n')
+			case 'r':
+				value = append(value, '\r')
+			case 't':
+				value = append(value, '\t')
+			case 'b':
+				value = append(value, '\b')
+			case 'f':
+				value = append(value, '\f')
+			case '\r':
+				// 续行
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+				// 续行
+			default:
+				if escaped >= '0' && escaped <= '7' {
+					code := int(escaped - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						code = code*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					value = append(value, byte(code))
+				} else {
+					value = append(value, escaped)
+				}
+			}
+		case '(':
+			depth++
+			value = append(value, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return value
+			}
+			value = append(value, c)
+		default:
+			value = append(value, c)
+		}
+	}
+	return value
+}
+
+// skipInlineImage 跳过内联图像数据，数据以空白后的 EI 结束
+func (l *pdfLexer) skipInlineImage() {
+	for l.pos+2 <= len(l.data) {
+		if isPDFWhitespace(l.data[l.pos]) && l.pos+3 <= len(l.data) && l.data[l.pos+1] == 'E' && l.data[l.pos+2] == 'I' &&
+			(l.pos+3 == len(l.data) || !isPDFRegular(l.data[l.pos+3])) {
+			l.pos += 3
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.data)
+}
+
+// pdfTextExtractor 执行内容流中的文本操作符，输出文本
+type pdfTextExtractor struct {
+	out   *textCollector
+	fonts map[string]*pdfCMap
+	font  *pdfCMap
+	lastY float64
+	hasY  bool
+}
+
+// run 处理内容流，文本达到大小限制时返回 false
+func (e *pdfTextExtractor) run(content []byte) bool {
+	lexer := &pdfLexer{data: content}
+	var operands []pdfToken
+	for {
+		token, ok := lexer.next()
+		if !ok {
+			return true
+		}
+		if token.kind != pdfTokenOperator {
+			operands = append(operands, token)
+			continue
+		}
+
+		switch string(token.value) {
+		case "Tf":
+			if len(operands) >= 2 && operands[len(operands)-2].kind == pdfTokenName {
+				e.font = e.fonts[string(operands[len(operands)-2].value)]
+			}
+		case "Tj":
+			e.showLast(operands)
+		case "'", "\"":
+			e.out.newline()
+			e.showLast(operands)
+		case "TJ":
+			if len(operands) > 0 && operands[len(operands)-1].kind == pdfTokenArray {
+				for _, item := range operands[len(operands)-1].array {
+					switch {
+					case item.kind == pdfTokenString:
+						e.show(item.value)
+					case item.kind == pdfTokenNumber && item.number < -200:
+						// 较大的字距调整通常表示单词间隔
+						e.out.space()
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 && operands[len(operands)-1].number != 0 {
+				e.out.newline()
+			}
+		case "T*":
+			e.out.newline()
+		case "Tm":
+			if len(operands) >= 6 {
+				y := operands[len(operands)-1].number
+				if e.hasY && y != e.lastY {
+					e.out.newline()
+				}
+				e.lastY, e.hasY = y, true
+			}
+		case "ET":
+			e.out.space()
+		case "ID":
+			lexer.skipInlineImage()
+		}
+		operands = operands[:0]
+
+		if e.out.truncated {
+			return false
+		}
+	}
+}
+
+func (e *pdfTextExtractor) showLast(operands []pdfToken) {
+	if len(operands) > 0 && operands[len(operands)-1].kind == pdfTokenString {
+		e.show(operands[len(operands)-1].value)
+	}
+}
+
+func (e *pdfTextExtractor) show(data []byte) {
+	if e.font != nil {
+		e.out.write(e.font.decode(data))
+		return
+	}
+	e.out.write(decodePDFString(data))
+}
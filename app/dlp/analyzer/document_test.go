@@ -0,0 +1,303 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIDCard = "110101199003074514"
+
+// buildZip 按给定顺序写入部件，构造OOXML文档包
+func buildZip(t *testing.T, parts ...[2]string) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, part := range parts {
+		w, err := writer.Create(part[0])
+		require.NoError(t, err)
+		_, err = w.Write([]byte(part[1]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+// docxBytes 构造包含指定段落的docx，extra 为附加部件
+func docxBytes(t *testing.T, paragraphs []string, extra ...[2]string) []byte {
+	var body strings.Builder
+	for _, paragraph := range paragraphs {
+		fmt.Fprintf(&body, `<w:p><w:r><w:t xml:space="preserve">%s</w:t></w:r></w:p>`, paragraph)
+	}
+	parts := [][2]string{
+		{"[Content_Types].xml", `<?xml version="1.0"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"/>`},
+		{"word/document.xml", `<?xml version="1.0"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+			body.String() + `</w:body></w:document>`},
+	}
+	return buildZip(t, append(parts, extra...)...)
+}
+
+// testPDF 构造测试用PDF
+type testPDF struct {
+	objects []string
+}
+
+// add 添加对象，返回对象编号
+func (p *testPDF) add(body string) int {
+	p.objects = append(p.objects, body)
+	return len(p.objects)
+}
+
+// addStream 添加流对象，compress 为 true 时使用 FlateDecode
+func (p *testPDF) addStream(dict string, data string, compress bool) int {
+	content := []byte(data)
+	if compress {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		w.Write(content)
+		w.Close()
+		content = buf.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+	return p.add(fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(content), content))
+}
+
+func (p *testPDF) bytes(trailer string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	for i, body := range p.objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R %s >>\nstartxref\n0\n%%%%EOF\n", len(p.objects)+1, trailer)
+	return buf.Bytes()
+}
+
+// samplePDF 构造两页PDF：第一页为压缩的西文文本，第二页使用带 ToUnicode 映射的复合字体输出中文
+func samplePDF(t *testing.T, trailer string) []byte {
+	pdf := &testPDF{}
+	pdf.add("<< /Type /Catalog /Pages 2 0 R >>")
+	pdf.add("<< /Type /Pages /Kids [3 0 R 5 0 R] /Count 2 /Resources << /Font << /F1 7 0 R /F2 8 0 R >> >> >>")
+	pdf.add("<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>")
+	pdf.addStream("", "BT /F1 12 Tf 72 720 Td (Employee \\(HR\\) report) Tj 0 -14 Td [(ID card: ) -250 ("+testIDCard+")] TJ ET", true)
+	pdf.add("<< /Type /Page /Parent 2 0 R /Contents [6 0 R] >>")
+	pdf.addStream("", "BT /F2 12 Tf 72 720 Td <00010002> Tj ET", false)
+	pdf.add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	pdf.add("<< /Type /Font /Subtype /Type0 /BaseFont /SimSun /Encoding /Identity-H /ToUnicode 9 0 R >>")
+	pdf.addStream("", "/CIDInit /ProcSet findresource begin\nbegincmap\n1 begincodespacerange <0000> <FFFF> endcodespacerange\n"+
+		"1 beginbfchar <0001> <673A> endbfchar\n1 beginbfrange <0002> <0002> <5BC6> endbfrange\nendcmap", true)
+	return pdf.bytes(trailer)
+}
+
+func TestDetectDocumentFormat(t *testing.T) {
+	assert.Equal(t, DocumentFormatPDF, DetectDocumentFormat(samplePDF(t, "")))
+	assert.Equal(t, DocumentFormatDOCX, DetectDocumentFormat(docxBytes(t, []string{"正文"})))
+	assert.Equal(t, DocumentFormatXLSX, DetectDocumentFormat(buildZip(t, [2]string{"xl/workbook.xml", "<workbook/>"})))
+	assert.Equal(t, DocumentFormatPPTX, DetectDocumentFormat(buildZip(t, [2]string{"ppt/presentation.xml", "<presentation/>"})))
+	assert.Equal(t, DocumentFormatEncryptedOffice, DetectDocumentFormat(encryptedOfficeBytes()))
+	assert.Equal(t, DocumentFormatUnknown, DetectDocumentFormat(buildZip(t, [2]string{"readme.txt", "普通压缩包"})))
+	assert.Equal(t, DocumentFormatUnknown, DetectDocumentFormat([]byte("plain text")))
+}
+
+func TestExtractDocument_DOCX(t *testing.T) {
+	data := docxBytes(t, []string{"员工信息", "身份证号：" + testIDCard},
+		[2]string{"word/footer1.xml", `<w:ftr xmlns:w="w"><w:p><w:r><w:t>机密</w:t></w:r></w:p></w:ftr>`},
+		[2]string{"word/media/image1.png", string(pngBytes(t))},
+		[2]string{"word/media/notes.bin", "不是图像"},
+	)
+
+	doc, err := ExtractDocument(data, DefaultDocumentConfig())
+	require.NoError(t, err)
+	assert.Equal(t, DocumentFormatDOCX, doc.Format)
+	assert.Equal(t, "员工信息\n身份证号："+testIDCard+"\n机密", doc.Text)
+	assert.False(t, doc.Truncated)
+	require.Len(t, doc.Images, 1, "只提取可识别的图像")
+	assert.True(t, DetectImageType(doc.Images[0]).IsImage())
+
+	config := DefaultDocumentConfig()
+	config.ExtractImages = false
+	doc, err = ExtractDocument(data, config)
+	require.NoError(t, err)
+	assert.Empty(t, doc.Images)
+}
+
+func TestExtractDocument_XLSX(t *testing.T) {
+	data := buildZip(t,
+		[2]string{"xl/workbook.xml", "<workbook/>"},
+		[2]string{"xl/sharedStrings.xml", `<sst><si><t>姓名</t></si><si><r><t>手机</t></r><r><t>号</t></r></si><si><t>张三</t></si></sst>`},
+		[2]string{"xl/worksheets/sheet2.xml", `<worksheet><sheetData><row><c t="inlineStr"><is><t>第二张表</t></is></c></row></sheetData></worksheet>`},
+		[2]string{"xl/worksheets/sheet1.xml", `<worksheet><sheetData>` +
+			`<row><c t="s"><v>0</v></c><c t="s"><v>1</v></c></row>` +
+			`<row><c t="s"><v>2</v></c><c><v>13800138000</v></c><c t="s"><v>99</v></c></row>` +
+			`</sheetData></worksheet>`},
+	)
+
+	doc, err := ExtractDocument(data, DefaultDocumentConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "姓名\t手机号\n张三\t13800138000\n第二张表", doc.Text, "工作表按序号排序，越界的共享字符串被忽略")
+	assert.Equal(t, 2, doc.Pages)
+
+	config := DefaultDocumentConfig()
+	config.MaxPages = 1
+	doc, err = ExtractDocument(data, config)
+	require.NoError(t, err)
+	assert.NotContains(t, doc.Text, "第二张表")
+	assert.True(t, doc.Truncated)
+}
+
+func TestExtractDocument_PPTX(t *testing.T) {
+	slide := func(text string) string {
+		return `<p:sld xmlns:a="a" xmlns:p="p"><p:cSld><p:spTree><p:sp><p:txBody><a:p><a:r><a:t>` + text + `</a:t></a:r></a:p></p:txBody></p:sp></p:spTree></p:cSld></p:sld>`
+	}
+	data := buildZip(t,
+		[2]string{"ppt/presentation.xml", "<presentation/>"},
+		[2]string{"ppt/slides/slide10.xml", slide("第十页")},
+		[2]string{"ppt/slides/slide2.xml", slide("第二页")},
+		[2]string{"ppt/slides/_rels/slide2.xml.rels", "<Relationships/>"},
+	)
+
+	doc, err := ExtractDocument(data, DefaultDocumentConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "第二页\n第十页", doc.Text)
+	assert.Equal(t, 2, doc.Pages)
+}
+
+func TestExtractDocument_PDF(t *testing.T) {
+	data := samplePDF(t, "")
+
+	doc, err := ExtractDocument(data, DefaultDocumentConfig())
+	require.NoError(t, err)
+	assert.Equal(t, DocumentFormatPDF, doc.Format)
+	assert.Equal(t, 2, doc.Pages)
+	assert.Contains(t, doc.Text, "Employee (HR) report\nID card: "+testIDCard)
+	assert.Contains(t, doc.Text, "机密", "应通过 ToUnicode 映射解码复合字体")
+	assert.False(t, doc.Truncated)
+
+	config := DefaultDocumentConfig()
+	config.MaxPages = 1
+	doc, err = ExtractDocument(data, config)
+	require.NoError(t, err)
+	assert.Contains(t, doc.Text, testIDCard)
+	assert.NotContains(t, doc.Text, "机密")
+	assert.True(t, doc.Truncated)
+}
+
+func TestExtractDocument_Limits(t *testing.T) {
+	data := docxBytes(t, []string{"身份证号：" + testIDCard})
+
+	config := DefaultDocumentConfig()
+	config.MaxSize = int64(len(data) - 1)
+	_, err := ExtractDocument(data, config)
+	assert.ErrorIs(t, err, ErrDocumentTooLarge)
+
+	config = DefaultDocumentConfig()
+	config.MaxTextSize = len("身份证号") + 1
+	doc, err := ExtractDocument(data, config)
+	require.NoError(t, err)
+	assert.Equal(t, "身份证号", doc.Text, "不在多字节字符中间截断")
+	assert.True(t, doc.Truncated)
+
+	// 解压后远大于原文件的文档按大小限制截断，而不是耗尽内存
+	bomb := docxBytes(t, []string{strings.Repeat("a", 4<<20)})
+	config = DefaultDocumentConfig()
+	config.MaxSize = int64(len(bomb) + 1024)
+	doc, err = ExtractDocument(bomb, config)
+	require.NoError(t, err)
+	assert.True(t, doc.Truncated)
+	assert.Less(t, len(doc.Text), 4<<20)
+}
+
+func TestExtractDocument_EncryptedAndCorrupt(t *testing.T) {
+	_, err := ExtractDocument(samplePDF(t, "/Encrypt 10 0 R"), DefaultDocumentConfig())
+	assert.ErrorIs(t, err, ErrDocumentEncrypted)
+
+	_, err = ExtractDocument(encryptedOfficeBytes(), DefaultDocumentConfig())
+	assert.ErrorIs(t, err, ErrDocumentEncrypted)
+
+	_, err = ExtractDocument([]byte("%PDF-1.4\n这不是PDF"), DefaultDocumentConfig())
+	assert.ErrorIs(t, err, ErrDocumentCorrupt)
+
+	corrupt := buildZip(t, [2]string{"word/document.xml", "<w:document><w:body><w:p>未闭合"})
+	_, err = ExtractDocument(corrupt, DefaultDocumentConfig())
+	assert.ErrorIs(t, err, ErrDocumentCorrupt)
+
+	// 截断的PDF不应导致panic
+	pdf := samplePDF(t, "")
+	for _, n := range []int{20, len(pdf) / 3, len(pdf) / 2, len(pdf) - 30} {
+		assert.NotPanics(t, func() { ExtractDocument(pdf[:n], DefaultDocumentConfig()) })
+	}
+}
+
+func TestTextAnalyzer_DocumentContent(t *testing.T) {
+	ta := NewTextAnalyzer(newTestLogger(t)).(*TextAnalyzer)
+	require.NoError(t, ta.Initialize(DefaultAnalyzerConfig()))
+	t.Cleanup(func() { ta.Cleanup() })
+
+	tests := []struct {
+		name   string
+		format DocumentFormat
+		data   []byte
+	}{
+		{"docx", DocumentFormatDOCX, docxBytes(t, []string{"员工信息", "身份证号：" + testIDCard})},
+		{"pdf", DocumentFormatPDF, samplePDF(t, "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ta.Analyze(context.Background(), &parser.ParsedData{Protocol: "file", Body: tt.data})
+			require.NoError(t, err)
+			assert.Equal(t, string(tt.format), result.Metadata["document_format"])
+			assert.Contains(t, findingValues(result, "id_card"), testIDCard)
+		})
+	}
+}
+
+func TestTextAnalyzer_DocumentImagesOCR(t *testing.T) {
+	ta := NewTextAnalyzer(newTestLogger(t)).(*TextAnalyzer)
+	require.NoError(t, ta.Initialize(DefaultAnalyzerConfig()))
+	t.Cleanup(func() { ta.Cleanup() })
+	ta.ocrEngine = &fakeOCREngine{formats: []string{"image/png"}, text: "扫描件 " + testIDCard}
+	ta.ocrEnabled = true
+
+	data := docxBytes(t, []string{"见附件扫描件"}, [2]string{"word/media/image1.png", string(pngBytes(t))})
+	result, err := ta.Analyze(context.Background(), &parser.ParsedData{Protocol: "file", Body: data})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Metadata["document_ocr_images"])
+	assert.Contains(t, findingValues(result, "id_card"), testIDCard)
+}
+
+func TestTextAnalyzer_EncryptedDocument(t *testing.T) {
+	ta := NewTextAnalyzer(newTestLogger(t)).(*TextAnalyzer)
+	require.NoError(t, ta.Initialize(DefaultAnalyzerConfig()))
+	t.Cleanup(func() { ta.Cleanup() })
+
+	result, err := ta.Analyze(context.Background(), &parser.ParsedData{Protocol: "file", Body: samplePDF(t, "/Encrypt 10 0 R")})
+	require.NoError(t, err)
+	assert.Contains(t, result.Categories, "encrypted")
+	assert.Equal(t, true, result.Metadata["document_encrypted"])
+	assert.Equal(t, "pdf", result.Metadata["document_format"])
+	assert.Empty(t, result.SensitiveData)
+}
+
+// encryptedOfficeBytes 构造包含 EncryptedPackage 流名称的OLE复合文档头
+func encryptedOfficeBytes() []byte {
+	data := []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+	data = append(data, make([]byte, 504)...)
+	return append(data, utf16LE("EncryptedPackage")...)
+}
+
+// findingValues 返回指定类型的发现值
+func findingValues(result *AnalysisResult, findingType string) []string {
+	var values []string
+	for _, data := range result.SensitiveData {
+		if data.Type == findingType {
+			values = append(values, data.Value)
+		}
+	}
+	return values
+}
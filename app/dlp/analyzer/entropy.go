@@ -0,0 +1,188 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+)
+
+// FindingTypeHighEntropy 高熵载荷发现类型，通常为加密或压缩后外发的数据
+const FindingTypeHighEntropy = "high_entropy"
+
+// EntropyConfig 熵检测配置
+type EntropyConfig struct {
+	Enabled             bool     `yaml:"enabled" json:"enabled"`
+	Threshold           float64  `yaml:"threshold" json:"threshold"`                       // 香农熵阈值（比特/字节，0-8）
+	MinSize             int      `yaml:"min_size" json:"min_size"`                         // 参与检测的最小载荷字节数
+	TrustedDestinations []string `yaml:"trusted_destinations" json:"trusted_destinations"` // 可信目的地：主机名（支持 *.example.com）、IP或CIDR
+}
+
+// DefaultEntropyConfig 返回默认熵检测配置
+// 随机或加密数据的熵接近8，gzip等压缩数据通常在7.5以上，自然语言文本在4-5之间
+func DefaultEntropyConfig() EntropyConfig {
+	return EntropyConfig{
+		Enabled:   true,
+		Threshold: 7.2,
+		MinSize:   1024,
+	}
+}
+
+// ShannonEntropy 计算数据的香农熵，单位为比特/字节
+func ShannonEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+
+	var entropy float64
+	total := float64(len(data))
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / total
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// EntropyDetector 熵检测阶段
+// 对发往不可信目的地的高熵载荷给出发现，参与风险评分
+type EntropyDetector struct {
+	config   EntropyConfig
+	networks []*net.IPNet
+	hosts    []string
+}
+
+// NewEntropyDetector 创建熵检测阶段
+func NewEntropyDetector(config EntropyConfig) *EntropyDetector {
+	detector := &EntropyDetector{config: config}
+	for _, destination := range config.TrustedDestinations {
+		destination = strings.ToLower(strings.TrimSpace(destination))
+		if destination == "" {
+			continue
+		}
+		if _, network, err := net.ParseCIDR(destination); err == nil {
+			detector.networks = append(detector.networks, network)
+			continue
+		}
+		detector.hosts = append(detector.hosts, destination)
+	}
+	return detector
+}
+
+// IsHighEntropy 判断载荷是否为高熵数据，小于最小长度的载荷不参与判断
+func (d *EntropyDetector) IsHighEntropy(data []byte) (bool, float64) {
+	if len(data) < d.config.MinSize {
+		return false, 0
+	}
+	entropy := ShannonEntropy(data)
+	return entropy >= d.config.Threshold, entropy
+}
+
+// Inspect 检测载荷熵，发往不可信目的地的高熵载荷作为发现加入分析结果
+func (d *EntropyDetector) Inspect(data *parser.ParsedData, result *AnalysisResult) {
+	if !d.config.Enabled || data == nil || result == nil {
+		return
+	}
+
+	destination := destinationOf(data)
+	if destination == "" || d.isTrusted(destination) {
+		return
+	}
+
+	high, entropy := d.IsHighEntropy(data.Body)
+	if !high {
+		return
+	}
+
+	// 熵越接近8置信度越高
+	confidence := 0.6
+	if d.config.Threshold < 8 {
+		confidence += 0.4 * (entropy - d.config.Threshold) / (8 - d.config.Threshold)
+	}
+
+	result.SensitiveData = append(result.SensitiveData, &SensitiveDataInfo{
+		Type:        FindingTypeHighEntropy,
+		Value:       fmt.Sprintf("%.2f", entropy),
+		MaskedValue: fmt.Sprintf("%.2f", entropy),
+		Confidence:  clampUnit(confidence),
+		Context:     fmt.Sprintf("%d字节高熵载荷发往 %s", len(data.Body), destination),
+		Metadata: map[string]interface{}{
+			"detector":    FindingSourceEntropy,
+			"entropy":     entropy,
+			"size":        len(data.Body),
+			"destination": destination,
+		},
+	})
+	result.Tags = append(result.Tags, FindingTypeHighEntropy)
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	result.Metadata["entropy"] = entropy
+}
+
+// isTrusted 检查目的地是否可信
+func (d *EntropyDetector) isTrusted(destination string) bool {
+	if ip := net.ParseIP(destination); ip != nil {
+		for _, network := range d.networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+	}
+
+	for _, host := range d.hosts {
+		if suffix, ok := strings.CutPrefix(host, "*."); ok {
+			if strings.HasSuffix(destination, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if destination == host {
+			return true
+		}
+	}
+	return false
+}
+
+// destinationOf 获取网络数据的目的地主机，文件和剪贴板等非网络数据返回空
+func destinationOf(data *parser.ParsedData) string {
+	switch data.Protocol {
+	case "", "file", "clipboard":
+		return ""
+	}
+
+	host := ""
+	if data.URL != "" {
+		if parsed, err := url.Parse(data.URL); err == nil {
+			host = parsed.Hostname()
+		}
+	}
+	if host == "" {
+		host = data.Headers["Host"]
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+	}
+	if host == "" {
+		host, _ = data.Metadata["dest_ip"].(string)
+	}
+	if host == "" {
+		for _, session := range data.Sessions {
+			if session != nil && session.DestIP != "" {
+				host = session.DestIP
+				break
+			}
+		}
+	}
+	return strings.ToLower(host)
+}
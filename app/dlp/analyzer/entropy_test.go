@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"fmt"
+	mathrand "math/rand"
+	"strings"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleText 生成自然语言风格的文本
+func sampleText(size int) []byte {
+	words := []string{"the", "quarterly", "report", "shows", "revenue", "growth", "in", "our", "regional",
+		"offices", "and", "customers", "expect", "delivery", "before", "next", "month", "with", "updated", "pricing"}
+	rng := mathrand.New(mathrand.NewSource(1))
+
+	var builder strings.Builder
+	for builder.Len() < size {
+		builder.WriteString(words[rng.Intn(len(words))])
+		builder.WriteString(" ")
+		if rng.Intn(12) == 0 {
+			builder.WriteString(fmt.Sprintf("订单%d。\n", rng.Intn(100000)))
+		}
+	}
+	return []byte(builder.String())
+}
+
+// randomBytes 生成加密数据一样的随机字节
+func randomBytes(t *testing.T, size int) []byte {
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	return data
+}
+
+// gzipBytes 压缩数据
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestShannonEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, ShannonEntropy(nil))
+	assert.Equal(t, 0.0, ShannonEntropy(bytes.Repeat([]byte{'a'}, 100)))
+	assert.InDelta(t, 1.0, ShannonEntropy([]byte("abababab")), 1e-9)
+
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	assert.InDelta(t, 8.0, ShannonEntropy(all), 1e-9)
+}
+
+func TestEntropyDetector_Classification(t *testing.T) {
+	detector := NewEntropyDetector(DefaultEntropyConfig())
+
+	tests := []struct {
+		name string
+		data []byte
+		high bool
+	}{
+		{"随机数据", randomBytes(t, 64*1024), true},
+		{"普通文本", sampleText(64 * 1024), false},
+		{"gzip流", gzipBytes(t, sampleText(256*1024)), true},
+		{"小于最小长度的随机数据", randomBytes(t, 512), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			high, entropy := detector.IsHighEntropy(tt.data)
+			assert.Equal(t, tt.high, high, "熵: %.3f", entropy)
+		})
+	}
+
+	// 提高阈值可以放过压缩流量
+	strict := DefaultEntropyConfig()
+	strict.Threshold = 7.999
+	high, _ := NewEntropyDetector(strict).IsHighEntropy(gzipBytes(t, sampleText(256*1024)))
+	assert.False(t, high)
+}
+
+func TestEntropyDetector_TrustedDestinations(t *testing.T) {
+	config := DefaultEntropyConfig()
+	config.TrustedDestinations = []string{"*.backup.example.com", "10.0.0.0/8", "Updates.Example.org"}
+	detector := NewEntropyDetector(config)
+
+	tests := []struct {
+		name    string
+		data    *parser.ParsedData
+		flagged bool
+	}{
+		{"不可信主机", &parser.ParsedData{Protocol: "https", URL: "https://paste.example.net/upload"}, true},
+		{"通配可信主机", &parser.ParsedData{Protocol: "https", URL: "https://s3.backup.example.com/put"}, false},
+		{"可信主机忽略大小写", &parser.ParsedData{Protocol: "http", Headers: map[string]string{"Host": "updates.example.org:8080"}}, false},
+		{"可信网段", &parser.ParsedData{Protocol: "tcp", Metadata: map[string]interface{}{"dest_ip": "10.1.2.3"}}, false},
+		{"不可信IP", &parser.ParsedData{Protocol: "tcp", Sessions: []*parser.SessionInfo{{DestIP: "203.0.113.5"}}}, true},
+		{"文件不是外发", &parser.ParsedData{Protocol: "file", URL: "C:\\data\\archive.bin"}, false},
+	}
+	body := randomBytes(t, 4096)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.data.Body = body
+			result := &AnalysisResult{}
+			detector.Inspect(tt.data, result)
+			if !tt.flagged {
+				assert.Empty(t, result.SensitiveData)
+				return
+			}
+			require.Len(t, result.SensitiveData, 1)
+			finding := result.SensitiveData[0]
+			assert.Equal(t, FindingTypeHighEntropy, finding.Type)
+			assert.Equal(t, FindingSourceEntropy, finding.Metadata["detector"])
+			assert.Greater(t, finding.Confidence, 0.9)
+			assert.Contains(t, result.Tags, FindingTypeHighEntropy)
+		})
+	}
+}
+
+func TestAnalysisManager_EntropyContributesToRisk(t *testing.T) {
+	logger := newTestLogger(t)
+	config := DefaultAnalyzerConfig()
+
+	textAnalyzer := NewTextAnalyzer(logger)
+	require.NoError(t, textAnalyzer.Initialize(config))
+	manager := NewAnalysisManager(logger, config)
+	require.NoError(t, manager.RegisterAnalyzer(textAnalyzer))
+
+	result, err := manager.AnalyzeContent(context.Background(), &parser.ParsedData{
+		Protocol:    "https",
+		ContentType: "application/octet-stream",
+		URL:         "https://paste.example.net/upload",
+		Body:        randomBytes(t, 8192),
+		Metadata:    make(map[string]interface{}),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.RiskBreakdown)
+
+	var contribution *RiskContribution
+	for i := range result.RiskBreakdown.Contributions {
+		if result.RiskBreakdown.Contributions[i].Type == FindingTypeHighEntropy {
+			contribution = &result.RiskBreakdown.Contributions[i]
+		}
+	}
+	require.NotNil(t, contribution, "高熵载荷应该参与风险评分")
+	assert.Equal(t, FindingSourceEntropy, contribution.Source)
+	assert.Greater(t, contribution.Score, 0.0)
+	assert.GreaterOrEqual(t, result.RiskLevel, RiskLevelHigh)
+
+	// 关闭熵检测后不再给出发现
+	config.Entropy.Enabled = false
+	disabled := NewAnalysisManager(logger, config)
+	require.NoError(t, disabled.RegisterAnalyzer(textAnalyzer))
+	result, err = disabled.AnalyzeContent(context.Background(), &parser.ParsedData{
+		Protocol:    "https",
+		ContentType: "application/octet-stream",
+		URL:         "https://paste.example.net/upload",
+		Body:        randomBytes(t, 8192),
+		Metadata:    make(map[string]interface{}),
+	})
+	require.NoError(t, err)
+	for _, data := range result.SensitiveData {
+		assert.NotEqual(t, FindingTypeHighEntropy, data.Type)
+	}
+}
@@ -0,0 +1,421 @@
+package analyzer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+)
+
+// FindingTypeEDM 精确数据匹配发现类型，内容命中了登记的敏感记录
+const FindingTypeEDM = "edm_match"
+
+// maxFingerprintFindings 单次分析最多报告的命中记录数
+const maxFingerprintFindings = 10
+
+// FingerprintSourceConfig 指纹记录文件配置
+// 文件每行一条记录，字段以分隔符分隔（CSV格式），# 开头的行为注释，例如：
+//
+//	# 客户名单
+//	Alice Zhang,alice@example.com,138-0000-1234,310101199001011234
+type FingerprintSourceConfig struct {
+	ID         string `yaml:"id" json:"id"`                   // 记录来源ID，默认使用文件名
+	Path       string `yaml:"path" json:"path"`               // 记录文件路径
+	Delimiter  string `yaml:"delimiter" json:"delimiter"`     // 字段分隔符，默认逗号
+	SkipHeader bool   `yaml:"skip_header" json:"skip_header"` // 是否跳过首行表头
+}
+
+// FingerprintConfig 精确数据匹配（EDM）配置
+// 登记的记录只以带密钥的哈希形式保存在内存中，不保留原始数据
+type FingerprintConfig struct {
+	Enabled        bool                      `yaml:"enabled" json:"enabled"`
+	MinMatches     int                       `yaml:"min_matches" json:"min_matches"`           // 同一记录至少命中的词元数
+	MinTokenLength int                       `yaml:"min_token_length" json:"min_token_length"` // 参与匹配的最短词元字符数
+	Sources        []FingerprintSourceConfig `yaml:"sources" json:"sources"`
+}
+
+// DefaultFingerprintConfig 返回默认精确数据匹配配置
+func DefaultFingerprintConfig() FingerprintConfig {
+	return FingerprintConfig{
+		Enabled:        true,
+		MinMatches:     3,
+		MinTokenLength: 3,
+	}
+}
+
+// FingerprintSourceInfo 已加载记录来源的信息
+type FingerprintSourceInfo struct {
+	ID       string    `json:"id"`
+	Path     string    `json:"path,omitempty"`
+	Records  int       `json:"records"`
+	Tokens   int       `json:"tokens"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// FingerprintMatch 内容命中的一条登记记录
+type FingerprintMatch struct {
+	Source  string `json:"source"`  // 记录来源ID
+	Record  int    `json:"record"`  // 记录在来源中的序号（不含表头），从1开始
+	Matched int    `json:"matched"` // 命中的词元数
+	Tokens  int    `json:"tokens"`  // 记录的词元总数
+}
+
+// fingerprintRecord 登记记录，只保存来源和词元数
+type fingerprintRecord struct {
+	source string
+	number int
+	tokens int
+}
+
+// FingerprintStore 敏感记录指纹库
+// 记录被切分为词元，每个词元以进程内随机密钥的HMAC-SHA256截断值建立索引，
+// 原始数据不会保存，也无法从索引还原
+type FingerprintStore struct {
+	key            []byte
+	minTokenLength int
+	index          map[uint64][]uint32 // 词元哈希 -> 记录编号
+	records        []fingerprintRecord
+	sources        map[string]*FingerprintSourceInfo
+	mu             sync.RWMutex
+}
+
+// NewFingerprintStore 创建指纹库，短于 minTokenLength 个字符的词元不参与索引和匹配
+func NewFingerprintStore(minTokenLength int) *FingerprintStore {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("生成指纹密钥失败: %v", err))
+	}
+	if minTokenLength <= 0 {
+		minTokenLength = DefaultFingerprintConfig().MinTokenLength
+	}
+	return &FingerprintStore{
+		key:            key,
+		minTokenLength: minTokenLength,
+		index:          make(map[uint64][]uint32),
+		sources:        make(map[string]*FingerprintSourceInfo),
+	}
+}
+
+// AddRecord 登记一条记录，返回记录被切分出的词元数，没有可用词元时不登记
+func (s *FingerprintStore) AddRecord(source string, fields ...string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := s.sources[source]
+	if info == nil {
+		info = &FingerprintSourceInfo{ID: source, LoadedAt: time.Now()}
+		s.sources[source] = info
+	}
+	return s.addRecordLocked(info, info.Records+1, fields)
+}
+
+// addRecordLocked 登记来源中序号为 number 的记录，调用方需持有写锁
+func (s *FingerprintStore) addRecordLocked(info *FingerprintSourceInfo, number int, fields []string) int {
+	hashes := make(map[uint64]bool)
+	for _, field := range fields {
+		for _, token := range fingerprintTokens(field, s.minTokenLength) {
+			hashes[s.hash(token)] = true
+		}
+	}
+	if len(hashes) == 0 {
+		return 0
+	}
+
+	info.Records++
+	id := uint32(len(s.records))
+	s.records = append(s.records, fingerprintRecord{source: info.ID, number: number, tokens: len(hashes)})
+	for hash := range hashes {
+		s.index[hash] = append(s.index[hash], id)
+	}
+	info.Tokens += len(hashes)
+	return len(hashes)
+}
+
+// LoadSource 从文件加载记录，替换同一来源之前登记的记录
+func (s *FingerprintStore) LoadSource(config FingerprintSourceConfig) (FingerprintSourceInfo, error) {
+	if config.Path == "" {
+		return FingerprintSourceInfo{}, fmt.Errorf("指纹记录文件路径不能为空")
+	}
+	if config.ID == "" {
+		config.ID = strings.TrimSuffix(filepath.Base(config.Path), filepath.Ext(config.Path))
+	}
+
+	file, err := os.Open(config.Path)
+	if err != nil {
+		return FingerprintSourceInfo{}, fmt.Errorf("打开指纹记录文件失败 %s: %w", config.Path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	switch config.Delimiter {
+	case "", ",":
+	case `\t`, "\t", "tab":
+		reader.Comma = '\t'
+	default:
+		delimiter, size := utf8.DecodeRuneInString(config.Delimiter)
+		if size != len(config.Delimiter) {
+			return FingerprintSourceInfo{}, fmt.Errorf("字段分隔符必须是单个字符: %q", config.Delimiter)
+		}
+		reader.Comma = delimiter
+	}
+
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return FingerprintSourceInfo{}, fmt.Errorf("解析指纹记录文件失败 %s: %w", config.Path, err)
+		}
+		rows = append(rows, row)
+	}
+	if config.SkipHeader && len(rows) > 0 {
+		rows = rows[1:]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeSourceLocked(config.ID)
+	info := &FingerprintSourceInfo{ID: config.ID, Path: config.Path, LoadedAt: time.Now()}
+	s.sources[config.ID] = info
+	for i, row := range rows {
+		s.addRecordLocked(info, i+1, row)
+	}
+	return *info, nil
+}
+
+// removeSourceLocked 删除来源的全部记录，调用方需持有写锁
+func (s *FingerprintStore) removeSourceLocked(source string) {
+	if _, ok := s.sources[source]; !ok {
+		return
+	}
+	delete(s.sources, source)
+
+	for hash, ids := range s.index {
+		kept := ids[:0]
+		for _, id := range ids {
+			if s.records[id].source != source {
+				kept = append(kept, id)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.index, hash)
+		} else {
+			s.index[hash] = kept
+		}
+	}
+	for i := range s.records {
+		if s.records[i].source == source {
+			s.records[i] = fingerprintRecord{}
+		}
+	}
+}
+
+// Match 返回内容中至少命中 minMatches 个词元的记录，按命中数降序排列
+func (s *FingerprintStore) Match(text string, minMatches int) []FingerprintMatch {
+	if minMatches <= 0 {
+		minMatches = 1
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.index) == 0 {
+		return nil
+	}
+
+	seen := make(map[uint64]bool)
+	counts := make(map[uint32]int)
+	for _, token := range fingerprintTokens(text, s.minTokenLength) {
+		hash := s.hash(token)
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		for _, id := range s.index[hash] {
+			counts[id]++
+		}
+	}
+
+	var matches []FingerprintMatch
+	for id, count := range counts {
+		if count < minMatches {
+			continue
+		}
+		record := s.records[id]
+		matches = append(matches, FingerprintMatch{
+			Source:  record.source,
+			Record:  record.number,
+			Matched: count,
+			Tokens:  record.tokens,
+		})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Matched != matches[j].Matched {
+			return matches[i].Matched > matches[j].Matched
+		}
+		if matches[i].Source != matches[j].Source {
+			return matches[i].Source < matches[j].Source
+		}
+		return matches[i].Record < matches[j].Record
+	})
+	return matches
+}
+
+// Sources 返回已加载的记录来源，按ID排序
+func (s *FingerprintStore) Sources() []FingerprintSourceInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sources := make([]FingerprintSourceInfo, 0, len(s.sources))
+	for _, info := range s.sources {
+		sources = append(sources, *info)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].ID < sources[j].ID
+	})
+	return sources
+}
+
+// Len 返回已登记的记录数
+func (s *FingerprintStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	total := 0
+	for _, info := range s.sources {
+		total += info.Records
+	}
+	return total
+}
+
+// hash 计算词元的带密钥哈希
+func (s *FingerprintStore) hash(token string) uint64 {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(token))
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
+
+// fingerprintTokens 将文本切分为归一化的词元
+// 词元由字母、数字和 @ . _ + - 组成，统一为小写；纯数字词元去掉分隔符，
+// 使 138-0000-1234 与 13800001234 视为同一词元
+func fingerprintTokens(text string, minLength int) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("@._+-", r)
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.Trim(field, "@._+-")
+		if digits := strings.Map(func(r rune) rune {
+			if r == '-' || r == '.' || r == '+' {
+				return -1
+			}
+			return r
+		}, field); digits != "" && strings.IndexFunc(digits, func(r rune) bool { return !unicode.IsDigit(r) }) < 0 {
+			field = digits
+		}
+		if utf8.RuneCountInString(field) >= minLength {
+			tokens = append(tokens, field)
+		}
+	}
+	return tokens
+}
+
+// FingerprintDetector 精确数据匹配阶段
+// 内容中同一登记记录的命中词元数达到阈值时给出发现，参与风险评分
+type FingerprintDetector struct {
+	config FingerprintConfig
+	store  *FingerprintStore
+}
+
+// NewFingerprintDetector 创建精确数据匹配阶段并加载配置的记录文件
+// 部分文件加载失败时返回已加载其余文件的检测阶段和汇总的错误
+func NewFingerprintDetector(config FingerprintConfig) (*FingerprintDetector, error) {
+	if config.MinMatches <= 0 {
+		config.MinMatches = DefaultFingerprintConfig().MinMatches
+	}
+	detector := &FingerprintDetector{
+		config: config,
+		store:  NewFingerprintStore(config.MinTokenLength),
+	}
+	if !config.Enabled {
+		return detector, nil
+	}
+
+	var errs []error
+	for _, source := range config.Sources {
+		if _, err := detector.store.LoadSource(source); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return detector, errors.Join(errs...)
+}
+
+// Store 返回检测阶段使用的指纹库
+func (d *FingerprintDetector) Store() *FingerprintStore {
+	return d.store
+}
+
+// Inspect 将内容与登记记录比对，命中的记录作为发现加入分析结果
+// 发现中只包含记录来源和序号，不包含原始数据
+func (d *FingerprintDetector) Inspect(data *parser.ParsedData, result *AnalysisResult) {
+	if !d.config.Enabled || data == nil || result == nil || len(data.Body) == 0 {
+		return
+	}
+
+	matches := d.store.Match(string(data.Body), d.config.MinMatches)
+	if len(matches) == 0 {
+		return
+	}
+	total := len(matches)
+	if len(matches) > maxFingerprintFindings {
+		matches = matches[:maxFingerprintFindings]
+	}
+
+	for _, match := range matches {
+		// 命中的词元占记录的比例越高置信度越高
+		confidence := 0.7 + 0.3*float64(match.Matched)/float64(match.Tokens)
+		reference := fmt.Sprintf("%s#%d", match.Source, match.Record)
+		result.SensitiveData = append(result.SensitiveData, &SensitiveDataInfo{
+			Type:        FindingTypeEDM,
+			Value:       reference,
+			MaskedValue: reference,
+			Confidence:  clampUnit(confidence),
+			Context:     fmt.Sprintf("命中指纹库 %s 第%d条记录的%d/%d个词元", match.Source, match.Record, match.Matched, match.Tokens),
+			Metadata: map[string]interface{}{
+				"detector":       FindingSourceFingerprint,
+				"source":         match.Source,
+				"record":         match.Record,
+				"matched_tokens": match.Matched,
+				"record_tokens":  match.Tokens,
+			},
+		})
+	}
+	result.Tags = append(result.Tags, FindingTypeEDM)
+	if result.Metadata == nil {
+		result.Metadata = make(map[string]interface{})
+	}
+	result.Metadata["edm_matches"] = total
+}
@@ -0,0 +1,145 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const customerRecords = `# 客户名单
+name,email,phone,id_card
+Alice Zhang,alice.zhang@example.com,138-0000-1234,310101199001011234
+Bob Li,bob.li@example.org,139-1111-5678,110101198502023456
+`
+
+func writeCustomerRecords(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "customers.csv")
+	require.NoError(t, os.WriteFile(path, []byte(customerRecords), 0644))
+	return path
+}
+
+func TestFingerprintTokens(t *testing.T) {
+	assert.Equal(t,
+		[]string{"alice", "zhang", "alice.zhang@example.com", "tel", "13800001234"},
+		fingerprintTokens("Alice Zhang, <alice.zhang@example.com>. Tel: 138-0000-1234", 3))
+	// 短于最小长度的词元被忽略
+	assert.Equal(t, []string{"13800001234"}, fingerprintTokens("Li 13800001234", 3))
+}
+
+func TestFingerprintStore_LoadAndMatch(t *testing.T) {
+	store := NewFingerprintStore(3)
+	info, err := store.LoadSource(FingerprintSourceConfig{Path: writeCustomerRecords(t), SkipHeader: true})
+	require.NoError(t, err)
+	assert.Equal(t, "customers", info.ID)
+	assert.Equal(t, 2, info.Records)
+	assert.Equal(t, 2, store.Len())
+
+	// 命中同一记录的多个字段，电话号码分隔符不同也能匹配
+	matches := store.Match("请联系 alice.zhang@example.com，电话 13800001234，身份证 310101199001011234", 3)
+	require.Len(t, matches, 1)
+	assert.Equal(t, FingerprintMatch{Source: "customers", Record: 1, Matched: 3, Tokens: 5}, matches[0])
+
+	// 命中数不足
+	assert.Empty(t, store.Match("alice.zhang@example.com 13800001234", 3))
+	// 不同记录各命中一部分字段不构成匹配
+	assert.Empty(t, store.Match("alice.zhang@example.com 13800001234 bob.li@example.org 13911115678", 3))
+
+	// 重新加载同一来源时替换旧记录
+	path := filepath.Join(t.TempDir(), "customers.csv")
+	require.NoError(t, os.WriteFile(path, []byte("Carol Wang\tcarol@example.com\t13700000000\n"), 0644))
+	info, err = store.LoadSource(FingerprintSourceConfig{ID: "customers", Path: path, Delimiter: "tab"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, info.Records)
+	assert.Empty(t, store.Match("alice.zhang@example.com 13800001234 310101199001011234", 3))
+	assert.Len(t, store.Match("Carol Wang <carol@example.com>", 3), 1)
+}
+
+func TestFingerprintStore_DoesNotKeepRawData(t *testing.T) {
+	store := NewFingerprintStore(3)
+	store.AddRecord("manual", "alice.zhang@example.com", "13800001234", "310101199001011234")
+
+	// 指纹库中只有哈希和记录编号
+	dump := fmt.Sprintf("%v %v %v", store.index, store.records, store.Sources())
+	assert.NotContains(t, dump, "alice")
+	assert.NotContains(t, dump, "13800001234")
+
+	// 相同内容在不同指纹库中的哈希不同
+	assert.NotEqual(t, store.hash("13800001234"), NewFingerprintStore(3).hash("13800001234"))
+}
+
+func TestFingerprintDetector_Inspect(t *testing.T) {
+	config := DefaultFingerprintConfig()
+	config.Sources = []FingerprintSourceConfig{{ID: "crm", Path: writeCustomerRecords(t), SkipHeader: true}}
+	detector, err := NewFingerprintDetector(config)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		body    string
+		flagged bool
+	}{
+		{name: "包含客户记录", body: "导出客户: Bob Li, bob.li@example.org, 139.1111.5678", flagged: true},
+		{name: "只提到客户姓名", body: "今天和 Bob Li 开会", flagged: false},
+		{name: "无关内容", body: "the quarterly report shows revenue growth in regional offices", flagged: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &AnalysisResult{}
+			detector.Inspect(&parser.ParsedData{Protocol: "https", Body: []byte(tt.body)}, result)
+			if !tt.flagged {
+				assert.Empty(t, result.SensitiveData)
+				return
+			}
+
+			require.Len(t, result.SensitiveData, 1)
+			finding := result.SensitiveData[0]
+			assert.Equal(t, FindingTypeEDM, finding.Type)
+			assert.Equal(t, "crm#2", finding.Value)
+			assert.Equal(t, FindingSourceFingerprint, finding.Metadata["detector"])
+			assert.NotContains(t, finding.Context, "bob.li@example.org")
+			assert.Contains(t, result.Tags, FindingTypeEDM)
+		})
+	}
+
+	// 加载失败的文件返回错误，其余文件照常加载
+	config.Sources = append(config.Sources, FingerprintSourceConfig{Path: filepath.Join(t.TempDir(), "missing.csv")})
+	detector, err = NewFingerprintDetector(config)
+	assert.Error(t, err)
+	assert.Equal(t, 2, detector.Store().Len())
+}
+
+func TestAnalysisManager_FingerprintContributesToRisk(t *testing.T) {
+	logger := newTestLogger(t)
+	config := DefaultAnalyzerConfig()
+	config.Fingerprint.Sources = []FingerprintSourceConfig{{ID: "crm", Path: writeCustomerRecords(t), SkipHeader: true}}
+
+	textAnalyzer := NewTextAnalyzer(logger)
+	require.NoError(t, textAnalyzer.Initialize(config))
+	manager := NewAnalysisManager(logger, config)
+	require.NoError(t, manager.RegisterAnalyzer(textAnalyzer))
+
+	result, err := manager.AnalyzeContent(context.Background(), &parser.ParsedData{
+		Protocol:    "https",
+		ContentType: "text/plain",
+		Body:        []byte("name: Alice Zhang; mail: alice.zhang@example.com"),
+		Metadata:    make(map[string]interface{}),
+	})
+	require.NoError(t, err)
+
+	var contribution *RiskContribution
+	for i := range result.RiskBreakdown.Contributions {
+		if result.RiskBreakdown.Contributions[i].Type == FindingTypeEDM {
+			contribution = &result.RiskBreakdown.Contributions[i]
+		}
+	}
+	require.NotNil(t, contribution, "精确数据匹配应该参与风险评分")
+	assert.Equal(t, FindingSourceFingerprint, contribution.Source)
+	assert.GreaterOrEqual(t, result.RiskLevel, RiskLevelHigh)
+}
@@ -0,0 +1,200 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	"golang.org/x/image/webp"
+)
+
+// ImageFormat 通过文件头识别的图像格式
+type ImageFormat string
+
+const (
+	ImageFormatUnknown ImageFormat = ""
+	ImageFormatPNG     ImageFormat = "png"
+	ImageFormatJPEG    ImageFormat = "jpeg"
+	ImageFormatGIF     ImageFormat = "gif"
+	ImageFormatBMP     ImageFormat = "bmp"
+	ImageFormatTIFF    ImageFormat = "tiff"
+	ImageFormatWebP    ImageFormat = "webp"
+)
+
+// OCR跳过原因，记录在OCR统计中
+const (
+	OCRSkipNotImage          = "not_image"          // 内容不是图像
+	OCRSkipUnsupportedFormat = "unsupported_format" // OCR引擎不支持该格式
+	OCRSkipAnimated          = "animated"           // 多帧图像无法提取首帧
+	OCRSkipDecodeFailed      = "decode_failed"      // 图像解码失败
+)
+
+// ImageTypeInfo 图像类型检测结果
+type ImageTypeInfo struct {
+	Format     ImageFormat // 图像格式，无法识别时为空
+	MimeType   string      // MIME类型
+	MultiFrame bool        // 是否为动画或多页图像
+}
+
+// IsImage 是否识别为图像
+func (i ImageTypeInfo) IsImage() bool {
+	return i.Format != ImageFormatUnknown
+}
+
+// DetectImageType 根据文件头识别图像格式，并判断是否为动画或多页图像
+func DetectImageType(data []byte) ImageTypeInfo {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return ImageTypeInfo{Format: ImageFormatPNG, MimeType: "image/png", MultiFrame: isAnimatedPNG(data)}
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return ImageTypeInfo{Format: ImageFormatJPEG, MimeType: "image/jpeg"}
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return ImageTypeInfo{Format: ImageFormatGIF, MimeType: "image/gif", MultiFrame: isAnimatedGIF(data)}
+	case len(data) >= 14 && bytes.HasPrefix(data, []byte("BM")):
+		return ImageTypeInfo{Format: ImageFormatBMP, MimeType: "image/bmp"}
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		return ImageTypeInfo{Format: ImageFormatTIFF, MimeType: "image/tiff", MultiFrame: isMultiPageTIFF(data)}
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && string(data[8:12]) == "WEBP":
+		return ImageTypeInfo{Format: ImageFormatWebP, MimeType: "image/webp", MultiFrame: isAnimatedWebP(data)}
+	default:
+		return ImageTypeInfo{}
+	}
+}
+
+// decodeFirstFrame 解码图像，动画或多页图像只解码第一帧
+// PNG、GIF和TIFF的解码器本身只返回第一帧；动画WebP无法解码，返回跳过原因
+func decodeFirstFrame(data []byte, info ImageTypeInfo) (image.Image, string, error) {
+	reader := bytes.NewReader(data)
+
+	var img image.Image
+	var err error
+	switch info.Format {
+	case ImageFormatPNG:
+		img, err = png.Decode(reader)
+	case ImageFormatJPEG:
+		img, err = jpeg.Decode(reader)
+	case ImageFormatGIF:
+		img, err = gif.Decode(reader)
+	case ImageFormatBMP:
+		img, err = bmp.Decode(reader)
+	case ImageFormatTIFF:
+		img, err = tiff.Decode(reader)
+	case ImageFormatWebP:
+		if info.MultiFrame {
+			return nil, OCRSkipAnimated, fmt.Errorf("不支持解码动画WebP")
+		}
+		img, err = webp.Decode(reader)
+	default:
+		return nil, OCRSkipNotImage, fmt.Errorf("不是图像")
+	}
+
+	if err != nil {
+		return nil, OCRSkipDecodeFailed, fmt.Errorf("解码%s图像失败: %w", info.Format, err)
+	}
+	return img, "", nil
+}
+
+// isAnimatedPNG 在图像数据之前存在 acTL 块时为APNG
+func isAnimatedPNG(data []byte) bool {
+	offset := 8
+	for offset+8 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		chunkType := string(data[offset+4 : offset+8])
+		switch chunkType {
+		case "acTL":
+			return true
+		case "IDAT", "IEND":
+			return false
+		}
+		offset += 12 + length
+	}
+	return false
+}
+
+// isAnimatedGIF 遍历数据块统计图像描述符，存在多帧时为动画
+func isAnimatedGIF(data []byte) bool {
+	if len(data) < 13 {
+		return false
+	}
+
+	offset := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		offset += 3 << (int(flags&0x07) + 1) // 全局颜色表
+	}
+
+	frames := 0
+	for offset < len(data) {
+		switch data[offset] {
+		case 0x2C: // 图像描述符
+			frames++
+			if frames > 1 {
+				return true
+			}
+			if offset+10 > len(data) {
+				return false
+			}
+			flags := data[offset+9]
+			offset += 10
+			if flags&0x80 != 0 {
+				offset += 3 << (int(flags&0x07) + 1) // 局部颜色表
+			}
+			offset++ // LZW最小码长
+			offset = skipGIFSubBlocks(data, offset)
+		case 0x21: // 扩展块
+			offset = skipGIFSubBlocks(data, offset+2)
+		default: // 结束符或无效数据
+			return false
+		}
+	}
+	return false
+}
+
+// skipGIFSubBlocks 跳过以0长度块结尾的数据子块
+func skipGIFSubBlocks(data []byte, offset int) int {
+	for offset < len(data) {
+		size := int(data[offset])
+		offset++
+		if size == 0 {
+			return offset
+		}
+		offset += size
+	}
+	return len(data)
+}
+
+// isMultiPageTIFF 第一个IFD之后还有IFD时为多页TIFF
+func isMultiPageTIFF(data []byte) bool {
+	if len(data) < 8 {
+		return false
+	}
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if data[0] == 'M' {
+		order = binary.BigEndian
+	}
+
+	ifd := int64(order.Uint32(data[4:8]))
+	if ifd+2 > int64(len(data)) {
+		return false
+	}
+	entries := int64(order.Uint16(data[ifd : ifd+2]))
+	next := ifd + 2 + entries*12
+	if next+4 > int64(len(data)) {
+		return false
+	}
+	return order.Uint32(data[next:next+4]) != 0
+}
+
+// isAnimatedWebP VP8X扩展头设置了动画标志时为动画WebP
+func isAnimatedWebP(data []byte) bool {
+	if len(data) < 21 || string(data[12:16]) != "VP8X" {
+		return false
+	}
+	return data[20]&0x02 != 0
+}
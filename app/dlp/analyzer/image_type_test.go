@@ -0,0 +1,189 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+// fakeOCREngine 记录调用的OCR引擎
+type fakeOCREngine struct {
+	formats []string
+	text    string
+	images  []image.Image
+}
+
+func (f *fakeOCREngine) ExtractText(ctx context.Context, img image.Image) (string, error) {
+	f.images = append(f.images, img)
+	return f.text, nil
+}
+
+func (f *fakeOCREngine) ExtractTextFromBytes(ctx context.Context, data []byte) (string, error) {
+	return "", errors.New("不应调用")
+}
+
+func (f *fakeOCREngine) GetSupportedFormats() []string                  { return f.formats }
+func (f *fakeOCREngine) Initialize(config map[string]interface{}) error { return nil }
+func (f *fakeOCREngine) Cleanup() error                                 { return nil }
+
+func testImage(width, height int) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, width, height), color.Palette{color.White, color.Black})
+	img.SetColorIndex(0, 0, 1)
+	return img
+}
+
+func encodeImage(t *testing.T, encode func(*bytes.Buffer, image.Image) error) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, encode(&buf, testImage(4, 4)))
+	return buf.Bytes()
+}
+
+func pngBytes(t *testing.T) []byte {
+	return encodeImage(t, func(b *bytes.Buffer, img image.Image) error { return png.Encode(b, img) })
+}
+
+func animatedGIFBytes(t *testing.T, frames int) []byte {
+	anim := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		anim.Image = append(anim.Image, testImage(4, 4))
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, anim))
+	return buf.Bytes()
+}
+
+// apngBytes 在IHDR之后插入acTL块
+func apngBytes(t *testing.T) []byte {
+	data := pngBytes(t)
+	ihdrEnd := 8 + 12 + 13
+	acTL := make([]byte, 20)
+	binary.BigEndian.PutUint32(acTL[0:4], 8)
+	copy(acTL[4:8], "acTL")
+	binary.BigEndian.PutUint32(acTL[8:12], 2)
+	return append(append(append([]byte{}, data[:ihdrEnd]...), acTL...), data[ihdrEnd:]...)
+}
+
+// multiPageTIFFBytes 将第一个IFD的下一IFD偏移指向自身
+func multiPageTIFFBytes(t *testing.T) []byte {
+	data := encodeImage(t, func(b *bytes.Buffer, img image.Image) error { return tiff.Encode(b, img, nil) })
+	ifd := binary.LittleEndian.Uint32(data[4:8])
+	entries := binary.LittleEndian.Uint16(data[ifd : ifd+2])
+	next := ifd + 2 + uint32(entries)*12
+	binary.LittleEndian.PutUint32(data[next:next+4], ifd)
+	return data
+}
+
+// webpVP8XBytes 构造VP8X扩展头
+func webpVP8XBytes(flags byte) []byte {
+	data := []byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00")
+	return append(data, flags, 0, 0, 0, 3, 0, 0, 3, 0, 0)
+}
+
+func TestDetectImageType(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		format     ImageFormat
+		mimeType   string
+		multiFrame bool
+	}{
+		{"png", pngBytes(t), ImageFormatPNG, "image/png", false},
+		{"apng", apngBytes(t), ImageFormatPNG, "image/png", true},
+		{"jpeg", encodeImage(t, func(b *bytes.Buffer, img image.Image) error { return jpeg.Encode(b, img, nil) }), ImageFormatJPEG, "image/jpeg", false},
+		{"gif", animatedGIFBytes(t, 1), ImageFormatGIF, "image/gif", false},
+		{"animated gif", animatedGIFBytes(t, 2), ImageFormatGIF, "image/gif", true},
+		{"bmp", encodeImage(t, func(b *bytes.Buffer, img image.Image) error { return bmp.Encode(b, img) }), ImageFormatBMP, "image/bmp", false},
+		{"tiff", encodeImage(t, func(b *bytes.Buffer, img image.Image) error { return tiff.Encode(b, img, nil) }), ImageFormatTIFF, "image/tiff", false},
+		{"multi-page tiff", multiPageTIFFBytes(t), ImageFormatTIFF, "image/tiff", true},
+		{"big-endian tiff", []byte("MM\x00*\x00\x00\x00\x08"), ImageFormatTIFF, "image/tiff", false},
+		{"webp", webpVP8XBytes(0), ImageFormatWebP, "image/webp", false},
+		{"animated webp", webpVP8XBytes(0x02), ImageFormatWebP, "image/webp", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := DetectImageType(tt.data)
+			assert.True(t, info.IsImage())
+			assert.Equal(t, tt.format, info.Format)
+			assert.Equal(t, tt.mimeType, info.MimeType)
+			assert.Equal(t, tt.multiFrame, info.MultiFrame)
+		})
+	}
+
+	for _, data := range [][]byte{nil, []byte("%PDF-1.7"), []byte("hello world"), []byte("BM")} {
+		assert.False(t, DetectImageType(data).IsImage(), "%q 不应识别为图像", data)
+	}
+}
+
+func TestDecodeFirstFrame(t *testing.T) {
+	img, reason, err := decodeFirstFrame(animatedGIFBytes(t, 3), DetectImageType(animatedGIFBytes(t, 3)))
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+	assert.Equal(t, image.Rect(0, 0, 4, 4), img.Bounds())
+
+	img, _, err = decodeFirstFrame(multiPageTIFFBytes(t), DetectImageType(multiPageTIFFBytes(t)))
+	require.NoError(t, err)
+	assert.NotNil(t, img)
+
+	animated := webpVP8XBytes(0x02)
+	_, reason, err = decodeFirstFrame(animated, DetectImageType(animated))
+	assert.Error(t, err)
+	assert.Equal(t, OCRSkipAnimated, reason)
+
+	truncated := pngBytes(t)[:40]
+	_, reason, err = decodeFirstFrame(truncated, DetectImageType(truncated))
+	assert.Error(t, err)
+	assert.Equal(t, OCRSkipDecodeFailed, reason)
+}
+
+func TestTextAnalyzer_OCRImageDetection(t *testing.T) {
+	engine := &fakeOCREngine{
+		formats: []string{"image/png", "image/gif"},
+		text:    "身份证号 110101199003071234",
+	}
+	ta := NewTextAnalyzer(newTestLogger(t)).(*TextAnalyzer)
+	ta.ocrEngine = engine
+	ta.ocrEnabled = true
+	ctx := context.Background()
+
+	text, err := ta.extractTextWithOCR(ctx, &parser.ParsedData{Body: pngBytes(t), ContentType: "image/png"})
+	require.NoError(t, err)
+	assert.Equal(t, engine.text, text)
+
+	// 动画GIF只识别第一帧
+	_, err = ta.extractTextWithOCR(ctx, &parser.ParsedData{Body: animatedGIFBytes(t, 2)})
+	require.NoError(t, err)
+	require.Len(t, engine.images, 2)
+	assert.Equal(t, image.Rect(0, 0, 4, 4), engine.images[1].Bounds())
+
+	// 引擎不支持的格式和非图像内容被跳过
+	bmpData := encodeImage(t, func(b *bytes.Buffer, img image.Image) error { return bmp.Encode(b, img) })
+	_, err = ta.extractTextWithOCR(ctx, &parser.ParsedData{Body: bmpData})
+	var skipped *ocrSkipError
+	require.ErrorAs(t, err, &skipped)
+	assert.Equal(t, OCRSkipUnsupportedFormat, skipped.reason)
+
+	_, err = ta.extractTextWithOCR(ctx, &parser.ParsedData{Body: []byte("plain text"), ContentType: "image/png"})
+	require.ErrorAs(t, err, &skipped)
+	assert.Equal(t, OCRSkipNotImage, skipped.reason)
+	assert.Len(t, engine.images, 2, "跳过的内容不应交给OCR引擎")
+
+	stats := ta.GetOCRStats()
+	assert.Equal(t, uint64(2), stats.Processed)
+	assert.Equal(t, map[string]uint64{"png": 1, "gif": 1, "bmp": 1, "unknown": 1}, stats.DetectedTypes)
+	assert.Equal(t, map[string]uint64{OCRSkipUnsupportedFormat: 1, OCRSkipNotImage: 1}, stats.SkipReasons)
+}
@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/lomehong/kennel/app/dlp/parser"
@@ -22,6 +23,7 @@ type AnalysisResult struct {
 	Metadata        map[string]interface{} `json:"metadata"`
 	ProcessingTime  time.Duration          `json:"processing_time"`
 	AnalyzerResults map[string]interface{} `json:"analyzer_results"`
+	RiskBreakdown   *RiskBreakdown         `json:"risk_breakdown,omitempty"`
 }
 
 // SensitiveDataInfo 敏感数据信息
@@ -36,11 +38,25 @@ type SensitiveDataInfo struct {
 }
 
 // Position 位置信息
+// Start、End 为敏感数据在分析文本中的字节偏移（左闭右开），Line、Column 从1开始，Column 按字符计数。
+// 正则命中的每个匹配各有位置，关键词和词典发现为首次出现的位置，熵和指纹等整体发现没有位置
 type Position struct {
 	Start  int `json:"start"`
 	End    int `json:"end"`
 	Line   int `json:"line"`
 	Column int `json:"column"`
+
+	// 字节长度和字符（rune）偏移，执行器按字符脱敏时使用
+	Length     int `json:"length"`
+	CharStart  int `json:"char_start"`
+	CharLength int `json:"char_length"`
+
+	// Basis 偏移所基于的文本，只有 PositionBasisBody 的偏移可以直接用于 ParsedData.Body
+	Basis string `json:"basis"`
+	// Decoded 分析文本不是线上传输的原始字节（HTTP分块、内容编码、文档提取或OCR），偏移不能映射回原始数据包
+	Decoded bool `json:"decoded,omitempty"`
+	// Encoding 主体经过的传输编码和内容编码，如 "chunked, gzip"
+	Encoding string `json:"encoding,omitempty"`
 }
 
 // RiskLevel 风险级别
@@ -69,22 +85,41 @@ func (r RiskLevel) String() string {
 	}
 }
 
+// ParseRiskLevel 根据字符串解析风险级别
+func ParseRiskLevel(name string) (RiskLevel, bool) {
+	for level := RiskLevelLow; level <= RiskLevelCritical; level++ {
+		if level.String() == strings.ToLower(name) {
+			return level, true
+		}
+	}
+	return RiskLevelLow, false
+}
+
 // AnalyzerConfig 分析器配置
 type AnalyzerConfig struct {
-	MaxContentSize   int64             `yaml:"max_content_size" json:"max_content_size"`
-	Timeout          time.Duration     `yaml:"timeout" json:"timeout"`
-	EnableMLAnalysis bool              `yaml:"enable_ml_analysis" json:"enable_ml_analysis"`
-	MLModelPath      string            `yaml:"ml_model_path" json:"ml_model_path"`
-	EnableRegexRules bool              `yaml:"enable_regex_rules" json:"enable_regex_rules"`
-	RegexRulesPath   string            `yaml:"regex_rules_path" json:"regex_rules_path"`
-	EnableKeywords   bool              `yaml:"enable_keywords" json:"enable_keywords"`
-	KeywordsPath     string            `yaml:"keywords_path" json:"keywords_path"`
-	MinConfidence    float64           `yaml:"min_confidence" json:"min_confidence"`
-	MaxConcurrency   int               `yaml:"max_concurrency" json:"max_concurrency"`
-	CacheSize        int               `yaml:"cache_size" json:"cache_size"`
-	CacheTTL         time.Duration     `yaml:"cache_ttl" json:"cache_ttl"`
-	CustomRules      map[string]string `yaml:"custom_rules" json:"custom_rules"`
-	Logger           logging.Logger    `yaml:"-" json:"-"`
+	MaxContentSize           int64              `yaml:"max_content_size" json:"max_content_size"`
+	Timeout                  time.Duration      `yaml:"timeout" json:"timeout"`
+	EnableMLAnalysis         bool               `yaml:"enable_ml_analysis" json:"enable_ml_analysis"`
+	MLModelPath              string             `yaml:"ml_model_path" json:"ml_model_path"`
+	EnableRegexRules         bool               `yaml:"enable_regex_rules" json:"enable_regex_rules"`
+	RegexRulesPath           string             `yaml:"regex_rules_path" json:"regex_rules_path"`
+	EnableKeywords           bool               `yaml:"enable_keywords" json:"enable_keywords"`
+	KeywordsPath             string             `yaml:"keywords_path" json:"keywords_path"`
+	Dictionaries             []DictionaryConfig `yaml:"dictionaries" json:"dictionaries"`                             // 外部关键词词典
+	DictionaryReloadInterval time.Duration      `yaml:"dictionary_reload_interval" json:"dictionary_reload_interval"` // 词典文件变化检查间隔
+	MinConfidence            float64            `yaml:"min_confidence" json:"min_confidence"`
+	MaxConcurrency           int                `yaml:"max_concurrency" json:"max_concurrency"`
+	CacheSize                int                `yaml:"cache_size" json:"cache_size"`
+	CacheTTL                 time.Duration      `yaml:"cache_ttl" json:"cache_ttl"`
+	CustomRules              map[string]string  `yaml:"custom_rules" json:"custom_rules"`
+	RiskScoring              RiskScoringConfig  `yaml:"risk_scoring" json:"risk_scoring"`
+	Entropy                  EntropyConfig      `yaml:"entropy" json:"entropy"`         // 高熵载荷检测
+	Documents                DocumentConfig     `yaml:"documents" json:"documents"`     // PDF、Office 文档文本提取
+	Fingerprint              FingerprintConfig  `yaml:"fingerprint" json:"fingerprint"` // 精确数据匹配（EDM）
+	Logger                   logging.Logger     `yaml:"-" json:"-"`
+
+	// Masking 按敏感数据类型生成 MaskedValue 的脱敏规则
+	Masking MaskingConfig `yaml:"masking" json:"masking"`
 }
 
 // DefaultAnalyzerConfig 返回默认分析器配置
@@ -100,6 +135,13 @@ func DefaultAnalyzerConfig() AnalyzerConfig {
 		CacheSize:        10000,
 		CacheTTL:         1 * time.Hour,
 		CustomRules:      make(map[string]string),
+		RiskScoring:      DefaultRiskScoringConfig(),
+		Entropy:          DefaultEntropyConfig(),
+		Documents:        DefaultDocumentConfig(),
+		Fingerprint:      DefaultFingerprintConfig(),
+		Masking:          DefaultMaskingConfig(),
+
+		DictionaryReloadInterval: DefaultDictionaryReloadInterval,
 	}
 }
 
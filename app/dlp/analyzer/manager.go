@@ -18,17 +18,28 @@ type AnalysisManagerImpl struct {
 	logger       logging.Logger
 	stats        ManagerStats
 	cacheManager CacheManager
+	riskScorer   *RiskScorer
+	entropy      *EntropyDetector
+	fingerprints *FingerprintDetector
 	running      int32
 	mu           sync.RWMutex
 }
 
 // NewAnalysisManager 创建分析管理器
 func NewAnalysisManager(logger logging.Logger, config AnalyzerConfig) AnalysisManager {
+	fingerprints, err := NewFingerprintDetector(config.Fingerprint)
+	if err != nil && logger != nil {
+		logger.Warn("加载指纹记录失败", "error", err)
+	}
+
 	return &AnalysisManagerImpl{
 		analyzers:    make(map[string]ContentAnalyzer),
 		config:       config,
 		logger:       logger,
 		cacheManager: NewCacheManager(config.CacheSize, config.CacheTTL),
+		riskScorer:   NewRiskScorer(config.RiskScoring),
+		entropy:      NewEntropyDetector(config.Entropy),
+		fingerprints: fingerprints,
 		stats: ManagerStats{
 			AnalyzerStats: make(map[string]AnalyzerStats),
 			StartTime:     time.Now(),
@@ -101,6 +112,18 @@ func (am *AnalysisManagerImpl) AnalyzeContent(ctx context.Context, data *parser.
 		return nil, fmt.Errorf("内容分析失败: %w", err)
 	}
 
+	// 检测发往不可信目的地的高熵载荷（加密或压缩后外发）
+	am.mu.RLock()
+	entropy := am.entropy
+	am.mu.RUnlock()
+	entropy.Inspect(data, result)
+
+	// 比对登记的敏感记录（精确数据匹配）
+	am.fingerprints.Inspect(data, result)
+
+	// 按统一的评分模型聚合各检测器的发现
+	am.riskScorer.Apply(result)
+
 	// 更新统计信息
 	atomic.AddUint64(&am.stats.ProcessedRequests, 1)
 	processingTime := time.Since(startTime)
@@ -112,6 +135,7 @@ func (am *AnalysisManagerImpl) AnalyzeContent(ctx context.Context, data *parser.
 	am.logger.Debug("内容分析完成",
 		"content_type", data.ContentType,
 		"risk_level", result.RiskLevel.String(),
+		"risk_score", result.RiskBreakdown.Score,
 		"sensitive_count", len(result.SensitiveData),
 		"processing_time", processingTime)
 
@@ -213,6 +237,29 @@ func (am *AnalysisManagerImpl) UpdateRules(analyzerName string, rules interface{
 	return fmt.Errorf("未找到分析器: %s", analyzerName)
 }
 
+// TrustedDestinations 获取熵检测的可信目的地
+func (am *AnalysisManagerImpl) TrustedDestinations() []string {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+	return append([]string(nil), am.config.Entropy.TrustedDestinations...)
+}
+
+// SetTrustedDestinations 替换熵检测的可信目的地，之后的分析立即生效
+func (am *AnalysisManagerImpl) SetTrustedDestinations(destinations []string) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	am.config.Entropy.TrustedDestinations = append([]string(nil), destinations...)
+	am.entropy = NewEntropyDetector(am.config.Entropy)
+	am.cacheManager.Clear()
+	am.logger.Info("更新可信目的地", "count", len(destinations))
+}
+
+// Fingerprints 获取精确数据匹配使用的指纹库
+func (am *AnalysisManagerImpl) Fingerprints() *FingerprintStore {
+	return am.fingerprints.Store()
+}
+
 // generateCacheKey 生成缓存键
 func (am *AnalysisManagerImpl) generateCacheKey(data *parser.ParsedData) string {
 	// 简化的缓存键生成，实际应该使用更复杂的哈希算法
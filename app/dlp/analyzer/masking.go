@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultMaskChar 默认掩码字符
+const DefaultMaskChar = "*"
+
+// maskRunLength 掩码部分固定的字符数，不暴露原始值的长度
+const maskRunLength = 4
+
+// MaskingRule 一种敏感数据类型的脱敏规则
+// 保留前后缀后隐藏的字符少于4个时整体掩码
+type MaskingRule struct {
+	KeepPrefix int  `yaml:"keep_prefix" json:"keep_prefix"` // 保留的前缀字符数
+	KeepSuffix int  `yaml:"keep_suffix" json:"keep_suffix"` // 保留的后缀字符数
+	Reveal     bool `yaml:"reveal" json:"reveal"`           // 原样输出，用于指纹引用、熵值等本身不敏感的发现
+}
+
+// MaskingConfig 日志、告警和审计中敏感数据的脱敏配置
+type MaskingConfig struct {
+	Enabled  bool                   `yaml:"enabled" json:"enabled"`     // 关闭时输出原始值
+	MaskChar string                 `yaml:"mask_char" json:"mask_char"` // 掩码字符
+	Default  MaskingRule            `yaml:"default" json:"default"`     // 未单独配置的类型使用的规则
+	Rules    map[string]MaskingRule `yaml:"rules" json:"rules"`         // 按敏感数据类型配置的规则
+}
+
+// DefaultMaskingConfig 返回默认脱敏配置
+func DefaultMaskingConfig() MaskingConfig {
+	return MaskingConfig{
+		Enabled:  true,
+		MaskChar: DefaultMaskChar,
+		Default:  MaskingRule{KeepSuffix: 4},
+		Rules: map[string]MaskingRule{
+			"credit_card":          {KeepSuffix: 4},
+			"phone":                {KeepPrefix: 3, KeepSuffix: 4},
+			"id_card":              {KeepPrefix: 3, KeepSuffix: 4},
+			"email":                {KeepPrefix: 2},
+			"password":             {},
+			"secret":               {},
+			"api_key":              {},
+			FindingTypeEDM:         {Reveal: true},
+			FindingTypeHighEntropy: {Reveal: true},
+		},
+	}
+}
+
+// MaskedFinding 脱敏后的敏感数据发现，只包含类型和部分预览
+type MaskedFinding struct {
+	Type       string  `json:"type"`
+	Preview    string  `json:"preview"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Masker 按敏感数据类型脱敏，创建后只读，可并发使用
+type Masker struct {
+	config MaskingConfig
+}
+
+// NewMasker 创建脱敏器
+func NewMasker(config MaskingConfig) *Masker {
+	if config.MaskChar == "" {
+		config.MaskChar = DefaultMaskChar
+	}
+	return &Masker{config: config}
+}
+
+// Rule 返回指定类型的脱敏规则
+func (m *Masker) Rule(dataType string) MaskingRule {
+	if rule, ok := m.config.Rules[dataType]; ok {
+		return rule
+	}
+	return m.config.Default
+}
+
+// Mask 按类型的规则掩码敏感值，不受 Enabled 影响，用于生成 MaskedValue
+func (m *Masker) Mask(dataType, value string) string {
+	rule := m.Rule(dataType)
+	if rule.Reveal {
+		return value
+	}
+
+	runes := []rune(value)
+	mask := strings.Repeat(m.config.MaskChar, maskRunLength)
+	prefix, suffix := max(rule.KeepPrefix, 0), max(rule.KeepSuffix, 0)
+	if prefix+suffix == 0 || prefix+suffix+maskRunLength > len(runes) {
+		return mask
+	}
+	return string(runes[:prefix]) + mask + string(runes[len(runes)-suffix:])
+}
+
+// Preview 返回输出到日志、告警和审计中的值，关闭脱敏时为原始值
+func (m *Masker) Preview(dataType, value string) string {
+	if !m.config.Enabled {
+		return value
+	}
+	return m.Mask(dataType, value)
+}
+
+// Findings 返回分析结果中全部发现的脱敏预览
+func (m *Masker) Findings(results ...*AnalysisResult) []MaskedFinding {
+	findings := make([]MaskedFinding, 0)
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, item := range result.SensitiveData {
+			if item == nil {
+				continue
+			}
+			findings = append(findings, MaskedFinding{
+				Type:       item.Type,
+				Preview:    m.Preview(item.Type, item.Value),
+				Confidence: item.Confidence,
+			})
+		}
+	}
+	return findings
+}
+
+// Redact 将文本中出现的发现原始值替换为脱敏预览，关闭脱敏时原样返回
+func (m *Masker) Redact(text string, results ...*AnalysisResult) string {
+	if !m.config.Enabled || text == "" {
+		return text
+	}
+
+	type replacement struct{ value, preview string }
+	replacements := make([]replacement, 0)
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		for _, item := range result.SensitiveData {
+			if item == nil || item.Value == "" || m.Rule(item.Type).Reveal {
+				continue
+			}
+			replacements = append(replacements, replacement{item.Value, m.Mask(item.Type, item.Value)})
+		}
+	}
+	// 先替换较长的值，避免较短的值是其子串时留下部分原文
+	sort.SliceStable(replacements, func(i, j int) bool {
+		return len(replacements[i].value) > len(replacements[j].value)
+	})
+
+	pairs := make([]string, 0, len(replacements)*2)
+	for _, r := range replacements {
+		pairs = append(pairs, r.value, r.preview)
+	}
+	if len(pairs) == 0 {
+		return text
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasker_Mask(t *testing.T) {
+	masker := NewMasker(DefaultMaskingConfig())
+
+	tests := []struct {
+		dataType string
+		value    string
+		want     string
+	}{
+		{"credit_card", "4111111111111111", "****1111"},
+		{"phone", "13812345678", "138****5678"},
+		{"id_card", "110101199003071234", "110****1234"},
+		{"email", "alice@example.com", "al****"},
+		{"password", "hunter2", "****"},
+		{"api_key", "sk_live_1234567890", "****"},
+		{"unknown", "abcdefgh", "****efgh"},
+		{"unknown", "1234567", "****"}, // 隐藏的字符不足4个时整体掩码
+		{"unknown", "机密项目代号", "****"},
+		{FindingTypeEDM, "customers#42", "customers#42"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, masker.Mask(tt.dataType, tt.value), "%s %s", tt.dataType, tt.value)
+		assert.Equal(t, tt.want, masker.Preview(tt.dataType, tt.value))
+	}
+
+	disabled := DefaultMaskingConfig()
+	disabled.Enabled = false
+	masker = NewMasker(disabled)
+	assert.Equal(t, "4111111111111111", masker.Preview("credit_card", "4111111111111111"), "关闭脱敏时预览为原始值")
+	assert.Equal(t, "****1111", masker.Mask("credit_card", "4111111111111111"), "Mask 不受开关影响")
+}
+
+func TestMasker_Redact(t *testing.T) {
+	masker := NewMasker(DefaultMaskingConfig())
+	result := &AnalysisResult{SensitiveData: []*SensitiveDataInfo{
+		{Type: "phone", Value: "13812345678"},
+		{Type: "unknown", Value: "1381234567"}, // 是前一个值的子串
+		{Type: FindingTypeEDM, Value: "customers#42"},
+	}}
+
+	text := "call 13812345678 or 1381234567, ref customers#42"
+	assert.Equal(t, "call 138****5678 or ****4567, ref customers#42", masker.Redact(text, result))
+
+	disabled := DefaultMaskingConfig()
+	disabled.Enabled = false
+	assert.Equal(t, text, NewMasker(disabled).Redact(text, result))
+}
+
+func TestTextAnalyzer_MaskedValuePerType(t *testing.T) {
+	ta := NewTextAnalyzer(newTestLogger(t)).(*TextAnalyzer)
+	config := DefaultAnalyzerConfig()
+	config.Masking.Rules["credit_card"] = MaskingRule{KeepPrefix: 4, KeepSuffix: 4}
+	require.NoError(t, ta.Initialize(config))
+	t.Cleanup(func() { ta.Cleanup() })
+
+	result, err := ta.Analyze(context.Background(), &parser.ParsedData{
+		Body: []byte("手机 13812345678，信用卡 4111111111111111，邮箱 alice@example.com"),
+	})
+	require.NoError(t, err)
+
+	masked := make(map[string]string)
+	for _, data := range result.SensitiveData {
+		masked[data.Type] = data.MaskedValue
+	}
+	assert.Equal(t, "138****5678", masked["phone"])
+	assert.Equal(t, "4111****1111", masked["credit_card"])
+	assert.Equal(t, "al****", masked["email"])
+}
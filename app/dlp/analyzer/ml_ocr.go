@@ -252,7 +252,7 @@ func (t *TesseractOCR) ExtractTextFromBytes(ctx context.Context, data []byte) (s
 
 // GetSupportedFormats 获取支持的图像格式
 func (t *TesseractOCR) GetSupportedFormats() []string {
-	return []string{"image/jpeg", "image/png", "image/tiff", "image/bmp"}
+	return []string{"image/jpeg", "image/png", "image/tiff", "image/bmp", "image/gif", "image/webp"}
 }
 
 // preprocessImage 预处理图像以提高OCR准确率
@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+)
+
+// 发现位置所基于的文本
+const (
+	// PositionBasisBody 偏移指向 ParsedData.Body，执行器可以直接按偏移生成脱敏副本
+	PositionBasisBody = "body"
+	// PositionBasisDocument 偏移指向从PDF、Office文档中提取的文本
+	PositionBasisDocument = "document"
+	// PositionBasisOCR 偏移指向OCR识别出的文本
+	PositionBasisOCR = "ocr"
+	// PositionBasisMetadata 偏移指向由URL、头部和元数据拼接的文本
+	PositionBasisMetadata = "metadata"
+)
+
+// newPosition 创建只包含字节偏移的位置，其余字段由 resolvePositions 补全
+func newPosition(start, end int) *Position {
+	return &Position{Start: start, End: end, Length: end - start}
+}
+
+// positionIndex 分析文本的行索引，用于把字节偏移换算为行列和字符偏移
+type positionIndex struct {
+	text       string
+	lineStarts []int // 每行第一个字节的偏移
+}
+
+// newPositionIndex 创建文本的行索引
+func newPositionIndex(text string) *positionIndex {
+	index := &positionIndex{text: text, lineStarts: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			index.lineStarts = append(index.lineStarts, i+1)
+		}
+	}
+	return index
+}
+
+// resolve 根据字节偏移补全行列和字符偏移，超出文本范围的位置不处理
+func (idx *positionIndex) resolve(position *Position) bool {
+	if position.Start < 0 || position.End < position.Start || position.End > len(idx.text) {
+		return false
+	}
+
+	line := sort.Search(len(idx.lineStarts), func(i int) bool {
+		return idx.lineStarts[i] > position.Start
+	})
+	lineStart := idx.lineStarts[line-1]
+
+	position.Length = position.End - position.Start
+	position.Line = line
+	position.Column = utf8.RuneCountInString(idx.text[lineStart:position.Start]) + 1
+	position.CharStart = utf8.RuneCountInString(idx.text[:position.Start])
+	position.CharLength = utf8.RuneCountInString(idx.text[position.Start:position.End])
+	return true
+}
+
+// resolvePositions 为带有字节偏移的发现补全行列、字符偏移和偏移所基于的文本
+// 偏移无效的位置被移除，避免执行器按错误的范围脱敏
+func resolvePositions(text string, findings []*SensitiveDataInfo, basis string, data *parser.ParsedData) {
+	var index *positionIndex
+	decoded, encoding := basis != PositionBasisBody, ""
+	if basis == PositionBasisBody {
+		decoded, encoding = bodyEncoding(data)
+	}
+
+	for _, finding := range findings {
+		if finding == nil || finding.Position == nil {
+			continue
+		}
+		if index == nil {
+			index = newPositionIndex(text)
+		}
+		if !index.resolve(finding.Position) {
+			finding.Position = nil
+			continue
+		}
+		finding.Position.Basis = basis
+		finding.Position.Decoded = decoded
+		finding.Position.Encoding = encoding
+	}
+}
+
+// bodyEncoding 报告 ParsedData.Body 是否经过解析器解码以及经过的编码
+// 解码后的主体与线上传输的字节不同，偏移只能用于 ParsedData.Body，不能映射回原始数据包
+func bodyEncoding(data *parser.ParsedData) (bool, string) {
+	if data == nil || data.Metadata == nil {
+		return false, ""
+	}
+
+	encodings := make([]string, 0, 2)
+	if transfer, ok := data.Metadata["transfer_encoding"].(string); ok && transfer != "" {
+		encodings = append(encodings, transfer)
+	}
+	if decoded, _ := data.Metadata["body_decoded"].(bool); decoded {
+		if content, ok := data.Metadata["content_encoding"].(string); ok && content != "" {
+			encodings = append(encodings, content)
+		}
+	}
+	return len(encodings) > 0, strings.Join(encodings, ", ")
+}
+
+// RedactSpans 按发现的位置生成脱敏副本，位置内的每个字符替换为 maskChar
+// 只处理位置基于 ParsedData.Body 的发现，content 应为分析时的 Body；重叠的范围合并处理。
+// 返回脱敏后的副本和被脱敏的范围数
+func RedactSpans(content []byte, findings []*SensitiveDataInfo, maskChar rune) ([]byte, int) {
+	type span struct{ start, end int }
+	spans := make([]span, 0, len(findings))
+	for _, finding := range findings {
+		if finding == nil || finding.Position == nil || finding.Position.Basis != PositionBasisBody {
+			continue
+		}
+		start, end := finding.Position.Start, finding.Position.End
+		if start < 0 || end <= start || end > len(content) {
+			continue
+		}
+		spans = append(spans, span{start, end})
+	}
+	if len(spans) == 0 {
+		return append([]byte(nil), content...), 0
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.start <= last.end {
+			if s.end > last.end {
+				last.end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	mask := string(maskChar)
+	var redacted strings.Builder
+	redacted.Grow(len(content))
+	offset := 0
+	for _, s := range merged {
+		redacted.Write(content[offset:s.start])
+		redacted.WriteString(strings.Repeat(mask, utf8.RuneCount(content[s.start:s.end])))
+		offset = s.end
+	}
+	redacted.Write(content[offset:])
+	return []byte(redacted.String()), len(merged)
+}
@@ -0,0 +1,197 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const positionTestText = "客户资料\n手机: 13812345678, 邮箱: zhang.san@example.com\n身份证 110101199001011234 Password 已更新\n"
+
+func newPositionTestAnalyzer(t *testing.T) *TextAnalyzer {
+	ta := NewTextAnalyzer(newTestLogger(t)).(*TextAnalyzer)
+	config := DefaultAnalyzerConfig()
+	config.MinConfidence = 0.5
+	require.NoError(t, ta.Initialize(config))
+	t.Cleanup(func() { ta.Cleanup() })
+	return ta
+}
+
+// assertFindingPositions 校验每个发现的字节偏移和字符偏移都指向发现的内容
+func assertFindingPositions(t *testing.T, content string, findings []*SensitiveDataInfo) {
+	runes := []rune(content)
+	for _, finding := range findings {
+		require.NotNil(t, finding.Position, "发现 %s 缺少位置", finding.Type)
+		position := finding.Position
+
+		matched := content[position.Start:position.End]
+		assert.True(t, strings.EqualFold(finding.Value, matched), "偏移应指向 %q，实际为 %q", finding.Value, matched)
+		assert.Equal(t, position.End-position.Start, position.Length)
+		assert.Equal(t, matched, string(runes[position.CharStart:position.CharStart+position.CharLength]))
+
+		lineStart := strings.LastIndex(content[:position.Start], "\n") + 1
+		assert.Equal(t, strings.Count(content[:position.Start], "\n")+1, position.Line)
+		assert.Equal(t, len([]rune(content[lineStart:position.Start]))+1, position.Column)
+	}
+}
+
+// maskSubstrings 将文本中的子串按字符替换为 *，作为脱敏结果的期望值
+func maskSubstrings(content string, values ...string) string {
+	for _, value := range values {
+		content = strings.ReplaceAll(content, value, strings.Repeat("*", len([]rune(value))))
+	}
+	return content
+}
+
+func TestTextAnalyzer_FindingPositions(t *testing.T) {
+	ta := newPositionTestAnalyzer(t)
+
+	result, err := ta.Analyze(context.Background(), &parser.ParsedData{Body: []byte(positionTestText)})
+	require.NoError(t, err)
+
+	types := make(map[string]bool)
+	for _, finding := range result.SensitiveData {
+		types[finding.Type] = true
+		assert.Equal(t, PositionBasisBody, finding.Position.Basis)
+		assert.False(t, finding.Position.Decoded, "未经解码的主体不应标记为已解码")
+	}
+	for _, expected := range []string{"phone", "email", "id_card", "password"} {
+		assert.True(t, types[expected], "应检测到 %s", expected)
+	}
+	assertFindingPositions(t, positionTestText, result.SensitiveData)
+
+	// 邮箱位于第2行，前面有中文字符，字节偏移和字符偏移不同
+	for _, finding := range result.SensitiveData {
+		if finding.Type != "email" {
+			continue
+		}
+		assert.Equal(t, 2, finding.Position.Line)
+		assert.Equal(t, 22, finding.Position.Column)
+		assert.Equal(t, 26, finding.Position.CharStart)
+		assert.Equal(t, strings.Index(positionTestText, "zhang.san"), finding.Position.Start)
+	}
+
+	redacted, spans := RedactSpans([]byte(positionTestText), result.SensitiveData, '*')
+	assert.Equal(t, maskSubstrings(positionTestText,
+		"13812345678", "zhang.san@example.com", "110101199001011234", "Password"), string(redacted))
+	assert.Equal(t, 4, spans, "手机号规则在身份证号内的匹配应与身份证号合并")
+	assert.Equal(t, positionTestText[:5], string(redacted[:5]), "不应修改原始内容以外的部分")
+}
+
+func TestTextAnalyzer_FindingPositionsAfterHTTPDecoding(t *testing.T) {
+	ta := newPositionTestAnalyzer(t)
+
+	plain := "name=张三&phone=13912345678&note=confidential"
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write([]byte(plain))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	// 按7字节分块编码压缩后的主体
+	var chunked bytes.Buffer
+	for body := compressed.Bytes(); len(body) > 0; {
+		n := min(7, len(body))
+		fmt.Fprintf(&chunked, "%x\r\n%s\r\n", n, body[:n])
+		body = body[n:]
+	}
+	chunked.WriteString("0\r\n\r\n")
+	payload := append([]byte("POST /upload HTTP/1.1\r\nHost: files.example.com\r\n"+
+		"Transfer-Encoding: chunked\r\nContent-Encoding: gzip\r\n\r\n"), chunked.Bytes()...)
+
+	httpParser := parser.NewHTTPParser(newTestLogger(t))
+	require.NoError(t, httpParser.Initialize(parser.DefaultParserConfig()))
+	data, err := httpParser.Parse(&interceptor.PacketInfo{
+		SourceIP:   net.ParseIP("192.168.1.10"),
+		DestIP:     net.ParseIP("203.0.113.5"),
+		SourcePort: 50000,
+		DestPort:   80,
+		Payload:    payload,
+		Size:       len(payload),
+	})
+	require.NoError(t, err)
+	require.Equal(t, plain, string(data.Body))
+
+	result, err := ta.Analyze(context.Background(), data)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.SensitiveData)
+
+	// 偏移指向解码后的主体，并标记经过的编码
+	assertFindingPositions(t, plain, result.SensitiveData)
+	for _, finding := range result.SensitiveData {
+		assert.Equal(t, PositionBasisBody, finding.Position.Basis)
+		assert.True(t, finding.Position.Decoded)
+		assert.Equal(t, "chunked, gzip", finding.Position.Encoding)
+	}
+
+	redacted, _ := RedactSpans(data.Body, result.SensitiveData, '#')
+	assert.Equal(t, "name=张三&phone=###########&note=############", string(redacted))
+}
+
+func TestTextAnalyzer_MetadataFindingsNotRedactable(t *testing.T) {
+	ta := newPositionTestAnalyzer(t)
+
+	data := &parser.ParsedData{URL: "https://example.com/reset?mail=li.si@example.com"}
+	result, err := ta.Analyze(context.Background(), data)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.SensitiveData)
+
+	for _, finding := range result.SensitiveData {
+		assert.Equal(t, PositionBasisMetadata, finding.Position.Basis)
+		assert.True(t, finding.Position.Decoded, "元数据文本不是原始主体")
+	}
+
+	// 位置不基于主体的发现不参与主体脱敏
+	redacted, spans := RedactSpans([]byte("li.si@example.com"), result.SensitiveData, '*')
+	assert.Equal(t, 0, spans)
+	assert.Equal(t, "li.si@example.com", string(redacted))
+}
+
+func TestRedactSpans(t *testing.T) {
+	content := []byte("卡号 6222 0212 3456 7890 结束")
+	finding := func(start, end int) *SensitiveDataInfo {
+		return &SensitiveDataInfo{Position: &Position{Start: start, End: end, Basis: PositionBasisBody}}
+	}
+
+	start := bytes.Index(content, []byte("6222"))
+	redacted, spans := RedactSpans(content, []*SensitiveDataInfo{
+		finding(start, start+9),
+		finding(start+5, start+19), // 与前一个范围重叠
+		finding(start, start+100),  // 超出内容范围
+		{Type: "high_entropy"},     // 没有位置
+	}, '*')
+
+	assert.Equal(t, 1, spans)
+	assert.Equal(t, "卡号 "+strings.Repeat("*", 19)+" 结束", string(redacted))
+	assert.Equal(t, "卡号 6222 0212 3456 7890 结束", string(content), "不应修改传入的内容")
+
+	// 中文按字符掩码
+	redacted, _ = RedactSpans(content, []*SensitiveDataInfo{finding(0, len("卡号"))}, '*')
+	assert.Equal(t, "** 6222 0212 3456 7890 结束", string(redacted))
+}
+
+func TestCompileKeywordRule(t *testing.T) {
+	rule := compileKeywordRule(&KeywordRule{
+		Keywords:  []string{"Secret", "a.b"},
+		WholeWord: true,
+	})
+	require.Len(t, rule.matchers, 2)
+	require.NotNil(t, rule.matchers[0].pattern, "不区分大小写的关键词应在加载时编译")
+
+	assert.Equal(t, []int{4, 10}, rule.matchers[0].locate("top SECRET data"))
+	assert.Nil(t, rule.matchers[0].locate("topsecret"))
+	assert.Nil(t, rule.matchers[1].locate("axb"), "关键词中的正则元字符应按字面匹配")
+
+	exact := compileKeywordRule(&KeywordRule{Keywords: []string{"机密"}, CaseSensitive: true})
+	assert.Nil(t, exact.matchers[0].pattern)
+	assert.Equal(t, []int{6, 12}, exact.matchers[0].locate("绝密机密"))
+}
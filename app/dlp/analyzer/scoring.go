@@ -0,0 +1,200 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+)
+
+// 发现来源
+const (
+	FindingSourceRegex    = "regex"    // 正则规则
+	FindingSourceKeyword  = "keyword"  // 关键词规则
+	FindingSourceOCR      = "ocr"      // OCR提取文本中的发现
+	FindingSourceML       = "ml"       // 机器学习预测
+	FindingSourceAnalyzer = "analyzer" // 分析器自身给出的基础评分
+	FindingSourceEntropy  = "entropy"  // 熵检测阶段给出的高熵载荷
+
+	FindingSourceFingerprint = "fingerprint" // 精确数据匹配阶段命中的登记记录
+)
+
+// RiskScoringConfig 风险评分配置
+type RiskScoringConfig struct {
+	TypeWeights   map[string]float64 `yaml:"type_weights" json:"type_weights"`
+	DefaultWeight float64            `yaml:"default_weight" json:"default_weight"`
+}
+
+// DefaultRiskScoringConfig 返回默认风险评分配置
+func DefaultRiskScoringConfig() RiskScoringConfig {
+	return RiskScoringConfig{
+		TypeWeights: map[string]float64{
+			"id_card":           1.0,
+			"credit_card":       1.0,
+			"bank_card":         1.0,
+			"password":          0.8,
+			"ml_prediction":     0.6,
+			"high_entropy":      0.7,
+			"edm_match":         1.0,
+			"analyzer_baseline": 1.0,
+			"phone":             0.5,
+			"secret":            0.5,
+			"email":             0.4,
+		},
+		DefaultWeight: 0.3,
+	}
+}
+
+// RiskFinding 参与评分的单个发现
+type RiskFinding struct {
+	Type       string  `json:"type"`
+	Source     string  `json:"source"`
+	RuleID     string  `json:"rule_id,omitempty"`
+	Confidence float64 `json:"confidence"`
+}
+
+// RiskContribution 单个发现对风险评分的贡献
+type RiskContribution struct {
+	RiskFinding
+	Weight float64 `json:"weight"` // 类型权重
+	Raw    float64 `json:"raw"`    // 权重×置信度，0-1
+	Score  float64 `json:"score"`  // 在总分中所占的分值，所有贡献之和等于总分
+}
+
+// RiskBreakdown 风险评分明细
+type RiskBreakdown struct {
+	Score         float64            `json:"score"` // 归一化风险评分，0-100
+	Contributions []RiskContribution `json:"contributions"`
+}
+
+// RiskScorer 置信度加权的风险评分器
+// 每个发现的强度为 类型权重×置信度，总分按 noisy-OR 合并：
+// 100×(1-∏(1-强度))，发现越多越接近100但不会超过；
+// 总分按各发现强度的比例分摊，得到可解释的贡献明细
+type RiskScorer struct {
+	config RiskScoringConfig
+}
+
+// NewRiskScorer 创建风险评分器
+func NewRiskScorer(config RiskScoringConfig) *RiskScorer {
+	if config.TypeWeights == nil {
+		config.TypeWeights = make(map[string]float64)
+	}
+	return &RiskScorer{config: config}
+}
+
+// Weight 获取发现类型的权重
+func (rs *RiskScorer) Weight(findingType string) float64 {
+	if weight, ok := rs.config.TypeWeights[findingType]; ok {
+		return clampUnit(weight)
+	}
+	return clampUnit(rs.config.DefaultWeight)
+}
+
+// Aggregate 聚合发现为风险评分明细，贡献按分值降序排列
+func (rs *RiskScorer) Aggregate(findings []RiskFinding) *RiskBreakdown {
+	breakdown := &RiskBreakdown{
+		Contributions: make([]RiskContribution, 0, len(findings)),
+	}
+
+	var rawTotal float64
+	remaining := 1.0
+	for _, finding := range findings {
+		finding.Confidence = clampUnit(finding.Confidence)
+		weight := rs.Weight(finding.Type)
+		raw := weight * finding.Confidence
+
+		breakdown.Contributions = append(breakdown.Contributions, RiskContribution{
+			RiskFinding: finding,
+			Weight:      weight,
+			Raw:         raw,
+		})
+		rawTotal += raw
+		remaining *= 1 - raw
+	}
+
+	if rawTotal == 0 {
+		return breakdown
+	}
+
+	breakdown.Score = 100 * (1 - remaining)
+	for i := range breakdown.Contributions {
+		breakdown.Contributions[i].Score = breakdown.Score * breakdown.Contributions[i].Raw / rawTotal
+	}
+
+	sort.SliceStable(breakdown.Contributions, func(i, j int) bool {
+		return breakdown.Contributions[i].Score > breakdown.Contributions[j].Score
+	})
+	return breakdown
+}
+
+// Apply 根据分析结果中的发现计算评分，更新结果的风险评分、风险级别和评分明细
+func (rs *RiskScorer) Apply(result *AnalysisResult) {
+	breakdown := rs.Aggregate(collectRiskFindings(result))
+
+	result.RiskBreakdown = breakdown
+	result.RiskScore = breakdown.Score / 100
+	result.RiskLevel = RiskLevelFromScore(breakdown.Score)
+}
+
+// RiskLevelFromScore 根据0-100的风险评分确定风险级别
+func RiskLevelFromScore(score float64) RiskLevel {
+	switch {
+	case score >= 80:
+		return RiskLevelCritical
+	case score >= 60:
+		return RiskLevelHigh
+	case score >= 40:
+		return RiskLevelMedium
+	default:
+		return RiskLevelLow
+	}
+}
+
+// collectRiskFindings 从分析结果中收集参与评分的发现
+func collectRiskFindings(result *AnalysisResult) []RiskFinding {
+	findings := make([]RiskFinding, 0, len(result.SensitiveData)+1)
+	for _, data := range result.SensitiveData {
+		if data == nil {
+			continue
+		}
+
+		source := FindingSourceRegex
+		if detector, ok := data.Metadata["detector"].(string); ok && detector != "" {
+			source = detector
+		}
+		if ocr, ok := data.Metadata["ocr"].(bool); ok && ocr {
+			source = FindingSourceOCR
+		}
+		ruleID, _ := data.Metadata["rule_id"].(string)
+
+		findings = append(findings, RiskFinding{
+			Type:       data.Type,
+			Source:     source,
+			RuleID:     ruleID,
+			Confidence: data.Confidence,
+		})
+	}
+
+	if prediction, ok := result.AnalyzerResults["ml_prediction"].(*MLPrediction); ok && prediction != nil && prediction.IsSensitive {
+		findings = append(findings, RiskFinding{
+			Type:       "ml_prediction",
+			Source:     FindingSourceML,
+			Confidence: prediction.Confidence,
+		})
+	}
+
+	// 没有任何发现时保留分析器给出的基础评分（如加密内容的元数据评估）
+	if len(findings) == 0 && result.RiskScore > 0 {
+		findings = append(findings, RiskFinding{
+			Type:       "analyzer_baseline",
+			Source:     FindingSourceAnalyzer,
+			Confidence: result.RiskScore,
+		})
+	}
+
+	return findings
+}
+
+// clampUnit 将数值限制在0-1之间
+func clampUnit(value float64) float64 {
+	return math.Max(0, math.Min(1, value))
+}
@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) logging.Logger {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+	return logger
+}
+
+// assertBreakdownSums 校验贡献之和等于总分
+func assertBreakdownSums(t *testing.T, breakdown *RiskBreakdown) {
+	var sum float64
+	for _, contribution := range breakdown.Contributions {
+		sum += contribution.Score
+	}
+	assert.InDelta(t, breakdown.Score, sum, 1e-9, "贡献之和应等于总分")
+}
+
+func TestRiskScorer_Aggregate(t *testing.T) {
+	scorer := NewRiskScorer(RiskScoringConfig{
+		TypeWeights: map[string]float64{
+			"id_card": 1.0,
+			"email":   0.5,
+			"phone":   0.5,
+		},
+		DefaultWeight: 0.2,
+	})
+
+	tests := []struct {
+		name     string
+		findings []RiskFinding
+		score    float64
+		level    RiskLevel
+	}{
+		{
+			name:  "无发现",
+			score: 0,
+			level: RiskLevelLow,
+		},
+		{
+			name:     "单个高权重发现",
+			findings: []RiskFinding{{Type: "id_card", Source: FindingSourceRegex, Confidence: 0.9}},
+			score:    90,
+			level:    RiskLevelCritical,
+		},
+		{
+			// 1-(1-0.4)(1-0.4) = 0.64
+			name: "两个中等发现",
+			findings: []RiskFinding{
+				{Type: "email", Source: FindingSourceRegex, Confidence: 0.8},
+				{Type: "phone", Source: FindingSourceKeyword, Confidence: 0.8},
+			},
+			score: 64,
+			level: RiskLevelHigh,
+		},
+		{
+			// 1-(1-0.5)(1-0.1)(1-0.12) = 0.604
+			name: "混合来源与默认权重",
+			findings: []RiskFinding{
+				{Type: "id_card", Source: FindingSourceOCR, Confidence: 0.5},
+				{Type: "email", Source: FindingSourceRegex, Confidence: 0.2},
+				{Type: "ml_prediction", Source: FindingSourceML, Confidence: 0.6},
+			},
+			score: 60.4,
+			level: RiskLevelHigh,
+		},
+		{
+			// 置信度超出范围时截断到1
+			name: "置信度截断",
+			findings: []RiskFinding{
+				{Type: "email", Source: FindingSourceRegex, Confidence: 1.5},
+			},
+			score: 50,
+			level: RiskLevelMedium,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown := scorer.Aggregate(tt.findings)
+			assert.InDelta(t, tt.score, breakdown.Score, 1e-9)
+			assert.Equal(t, tt.level, RiskLevelFromScore(breakdown.Score))
+			assert.Len(t, breakdown.Contributions, len(tt.findings))
+			assertBreakdownSums(t, breakdown)
+		})
+	}
+}
+
+func TestRiskScorer_ContributionsProportional(t *testing.T) {
+	scorer := NewRiskScorer(DefaultRiskScoringConfig())
+
+	breakdown := scorer.Aggregate([]RiskFinding{
+		{Type: "email", Source: FindingSourceRegex, RuleID: "email", Confidence: 0.8},
+		{Type: "id_card", Source: FindingSourceRegex, RuleID: "id_card_cn", Confidence: 0.95},
+	})
+
+	require.Len(t, breakdown.Contributions, 2)
+	// 贡献按分值降序排列
+	first, second := breakdown.Contributions[0], breakdown.Contributions[1]
+	assert.Equal(t, "id_card_cn", first.RuleID)
+	assert.Equal(t, 1.0, first.Weight)
+	assert.InDelta(t, 0.95, first.Raw, 1e-9)
+	assert.Equal(t, "email", second.RuleID)
+	assert.InDelta(t, 0.32, second.Raw, 1e-9)
+	assert.InDelta(t, first.Raw/second.Raw, first.Score/second.Score, 1e-9)
+
+	// 1-(1-0.95)(1-0.32) = 0.966
+	assert.InDelta(t, 96.6, breakdown.Score, 1e-9)
+	assertBreakdownSums(t, breakdown)
+}
+
+func TestAnalysisManager_AppliesRiskScoring(t *testing.T) {
+	logger := newTestLogger(t)
+	config := DefaultAnalyzerConfig()
+
+	textAnalyzer := NewTextAnalyzer(logger)
+	require.NoError(t, textAnalyzer.Initialize(config))
+
+	manager := NewAnalysisManager(logger, config)
+	require.NoError(t, manager.RegisterAnalyzer(textAnalyzer))
+
+	result, err := manager.AnalyzeContent(context.Background(), &parser.ParsedData{
+		ContentType: "text/plain",
+		Body:        []byte("身份证: 110101199003077777 邮箱 alice@example.com"),
+		Metadata:    make(map[string]interface{}),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.RiskBreakdown)
+	require.NotEmpty(t, result.RiskBreakdown.Contributions)
+
+	sources := make(map[string]bool)
+	for _, contribution := range result.RiskBreakdown.Contributions {
+		sources[contribution.Type] = true
+		assert.Equal(t, FindingSourceRegex, contribution.Source)
+	}
+	assert.True(t, sources["id_card"])
+	assert.True(t, sources["email"])
+
+	assertBreakdownSums(t, result.RiskBreakdown)
+	assert.InDelta(t, result.RiskBreakdown.Score/100, result.RiskScore, 1e-9)
+	assert.Equal(t, RiskLevelFromScore(result.RiskBreakdown.Score), result.RiskLevel)
+	assert.Equal(t, RiskLevelCritical, result.RiskLevel)
+}
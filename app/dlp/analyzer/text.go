@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -18,12 +19,13 @@ type TextAnalyzer struct {
 	config       AnalyzerConfig
 	logger       logging.Logger
 	regexRules   []*RegexRule
-	keywordRules []*KeywordRule
+	keywordRules []*compiledKeywordRule
 	stats        AnalyzerStats
 
 	// OCR 支持
 	ocrEnabled bool
 	ocrEngine  OCREngine
+	ocrStats   ocrStats
 
 	// 机器学习支持
 	mlEnabled bool
@@ -32,6 +34,13 @@ type TextAnalyzer struct {
 	// 文件类型检测
 	fileDetector FileTypeDetector
 
+	// 外部关键词词典
+	dictionaries        []*keywordDictionary
+	stopDictionaryWatch chan struct{}
+
+	// 生成 MaskedValue 的脱敏器
+	masker *Masker
+
 	// 并发控制
 	mu sync.RWMutex
 }
@@ -41,7 +50,7 @@ func NewTextAnalyzer(logger logging.Logger) ContentAnalyzer {
 	return &TextAnalyzer{
 		logger:       logger,
 		regexRules:   make([]*RegexRule, 0),
-		keywordRules: make([]*KeywordRule, 0),
+		keywordRules: make([]*compiledKeywordRule, 0),
 		stats: AnalyzerStats{
 			StartTime: time.Now(),
 		},
@@ -65,10 +74,14 @@ func (ta *TextAnalyzer) GetAnalyzerInfo() AnalyzerInfo {
 			"application/json",
 			"application/xml",
 			"text/csv",
+			"application/pdf",
+			"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			"application/vnd.openxmlformats-officedocument.presentationml.presentation",
 		},
 		Author:       "DLP Team",
 		License:      "MIT",
-		Capabilities: []string{"regex", "keywords", "patterns"},
+		Capabilities: []string{"regex", "keywords", "patterns", "documents"},
 	}
 }
 
@@ -100,20 +113,34 @@ func (ta *TextAnalyzer) Analyze(ctx context.Context, data *parser.ParsedData) (*
 		isEncrypted = true
 	}
 
-	// 提取文本内容
-	text := string(data.Body)
-	if text == "" {
+	// 提取文本内容，PDF和Office文档先提取其中的文本
+	text, documentMetadata := ta.extractDocumentText(ctx, data)
+	basis := PositionBasisBody
+	if documentMetadata["document_pages"] != nil {
+		basis = PositionBasisDocument
+	}
+	if documentMetadata["document_encrypted"] == true {
+		isEncrypted = true
+	}
+	if text == "" && !isEncrypted {
 		// 尝试从其他字段提取文本
 		text = ta.extractTextFromData(data)
+		basis = PositionBasisMetadata
 	}
 
-	// 如果仍然没有文本，尝试OCR提取
-	if text == "" && ta.ocrEnabled {
+	// 图像内容或没有文本时尝试OCR提取
+	ocrUsed := false
+	if ta.ocrEnabled && (text == "" || isImageContent(data)) {
 		ocrText, err := ta.extractTextWithOCR(ctx, data)
-		if err != nil {
+		var skipped *ocrSkipError
+		if errors.As(err, &skipped) {
+			ta.logger.Debug("跳过OCR", "reason", skipped.reason, "error", skipped.err)
+		} else if err != nil {
 			ta.logger.Warn("OCR文本提取失败", "error", err)
-		} else {
+		} else if ocrText != "" {
 			text = ocrText
+			basis = PositionBasisOCR
+			ocrUsed = true
 		}
 	}
 
@@ -125,10 +152,15 @@ func (ta *TextAnalyzer) Analyze(ctx context.Context, data *parser.ParsedData) (*
 
 		// 从元数据中提取可分析的信息
 		text = ta.extractTextFromData(data)
+		basis = PositionBasisMetadata
 
 		// 如果仍然没有可分析的文本，创建一个基于元数据的分析结果
 		if text == "" {
-			return ta.createEncryptedContentResult(data), nil
+			result := ta.createEncryptedContentResult(data)
+			for key, value := range documentMetadata {
+				result.Metadata[key] = value
+			}
+			return result, nil
 		}
 	}
 
@@ -162,6 +194,21 @@ func (ta *TextAnalyzer) Analyze(ctx context.Context, data *parser.ParsedData) (*
 	if ta.config.EnableKeywords {
 		keywordResults := ta.analyzeWithKeywords(text)
 		result.SensitiveData = append(result.SensitiveData, keywordResults...)
+		result.SensitiveData = append(result.SensitiveData, ta.analyzeWithDictionaries(text)...)
+	}
+
+	// 补全发现的行列和字符偏移，并标记偏移所基于的文本
+	resolvePositions(text, result.SensitiveData, basis, data)
+
+	for key, value := range documentMetadata {
+		result.Metadata[key] = value
+	}
+
+	// 标记从OCR文本中得到的发现
+	if ocrUsed {
+		for _, sensitiveData := range result.SensitiveData {
+			sensitiveData.Metadata["ocr"] = true
+		}
 	}
 
 	// 执行机器学习分析
@@ -208,6 +255,9 @@ func (ta *TextAnalyzer) GetSupportedTypes() []string {
 // Initialize 初始化分析器
 func (ta *TextAnalyzer) Initialize(config AnalyzerConfig) error {
 	ta.config = config
+	ta.mu.Lock()
+	ta.masker = NewMasker(config.Masking)
+	ta.mu.Unlock()
 	ta.logger.Info("初始化文本分析器",
 		"max_content_size", config.MaxContentSize,
 		"enable_regex", config.EnableRegexRules,
@@ -218,6 +268,20 @@ func (ta *TextAnalyzer) Initialize(config AnalyzerConfig) error {
 		return fmt.Errorf("加载默认规则失败: %w", err)
 	}
 
+	// 加载外部词典，并定期检查文件变化
+	if len(config.Dictionaries) > 0 {
+		if err := ta.SetDictionaries(config.Dictionaries); err != nil {
+			ta.logger.Warn("加载关键词词典失败，将在文件变化后重试", "error", err)
+		}
+
+		interval := config.DictionaryReloadInterval
+		if interval <= 0 {
+			interval = DefaultDictionaryReloadInterval
+		}
+		ta.stopDictionaryWatch = make(chan struct{})
+		go ta.watchDictionaries(interval, ta.stopDictionaryWatch)
+	}
+
 	return nil
 }
 
@@ -226,6 +290,14 @@ func (ta *TextAnalyzer) Cleanup() error {
 	ta.logger.Info("清理文本分析器资源")
 	ta.regexRules = nil
 	ta.keywordRules = nil
+
+	if ta.stopDictionaryWatch != nil {
+		close(ta.stopDictionaryWatch)
+		ta.stopDictionaryWatch = nil
+	}
+	ta.mu.Lock()
+	ta.dictionaries = nil
+	ta.mu.Unlock()
 	return nil
 }
 
@@ -236,7 +308,7 @@ func (ta *TextAnalyzer) UpdateRules(rules interface{}) error {
 		ta.regexRules = r
 		ta.logger.Info("更新正则表达式规则", "count", len(r))
 	case []*KeywordRule:
-		ta.keywordRules = r
+		ta.keywordRules = compileKeywordRules(r)
 		ta.logger.Info("更新关键词规则", "count", len(r))
 	default:
 		return fmt.Errorf("不支持的规则类型: %T", rules)
@@ -276,6 +348,56 @@ func (ta *TextAnalyzer) extractTextFromData(data *parser.ParsedData) string {
 	return text.String()
 }
 
+// extractDocumentText 提取待分析的文本，PDF和Office文档提取其中的文本，启用OCR时识别内嵌图像
+// 加密文档在元数据中标记 document_encrypted；文档损坏或超过大小限制时退回按原始内容分析
+func (ta *TextAnalyzer) extractDocumentText(ctx context.Context, data *parser.ParsedData) (string, map[string]interface{}) {
+	if !ta.config.Documents.Enabled {
+		return string(data.Body), nil
+	}
+	format := DetectDocumentFormat(data.Body)
+	if format == DocumentFormatUnknown {
+		return string(data.Body), nil
+	}
+
+	metadata := map[string]interface{}{"document_format": string(format)}
+	doc, err := ExtractDocument(data.Body, ta.config.Documents)
+	switch {
+	case errors.Is(err, ErrDocumentEncrypted):
+		ta.logger.Info("文档已加密，无法检查内容", "format", format, "url", data.URL)
+		metadata["document_encrypted"] = true
+		return "", metadata
+	case err != nil:
+		ta.logger.Warn("提取文档文本失败，按原始内容分析", "format", format, "url", data.URL, "error", err)
+		metadata["document_error"] = err.Error()
+		return string(data.Body), metadata
+	}
+
+	text := doc.Text
+	metadata["document_pages"] = doc.Pages
+	metadata["document_truncated"] = doc.Truncated
+
+	if ta.ocrEnabled && len(doc.Images) > 0 {
+		recognized := 0
+		for _, img := range doc.Images {
+			ocrText, err := ta.extractTextWithOCR(ctx, &parser.ParsedData{Body: img})
+			if err != nil || ocrText == "" {
+				continue
+			}
+			text += "\n" + ocrText
+			recognized++
+		}
+		metadata["document_ocr_images"] = recognized
+	}
+
+	ta.logger.Debug("文档文本提取完成",
+		"format", format,
+		"pages", doc.Pages,
+		"images", len(doc.Images),
+		"text_length", len(text),
+		"truncated", doc.Truncated)
+	return text, metadata
+}
+
 // createEncryptedContentResult 为加密内容创建分析结果
 func (ta *TextAnalyzer) createEncryptedContentResult(data *parser.ParsedData) *AnalysisResult {
 	result := &AnalysisResult{
@@ -344,21 +466,23 @@ func (ta *TextAnalyzer) analyzeWithRegex(text string) []*SensitiveDataInfo {
 			continue
 		}
 
-		matches := regex.FindAllStringSubmatch(text, -1)
+		matches := regex.FindAllStringIndex(text, -1)
 		for _, match := range matches {
 			if len(match) > 0 {
-				value := match[0]
+				value := text[match[0]:match[1]]
 				if rule.Confidence >= ta.config.MinConfidence {
 					sensitiveData := &SensitiveDataInfo{
 						Type:        rule.Type,
 						Value:       value,
-						MaskedValue: ta.maskValue(value),
+						MaskedValue: ta.maskValue(rule.Type, value),
+						Position:    newPosition(match[0], match[1]),
 						Confidence:  rule.Confidence,
 						Context:     ta.extractContext(text, value),
 						Metadata: map[string]interface{}{
 							"rule_id":   rule.ID,
 							"rule_name": rule.Name,
 							"category":  rule.Category,
+							"detector":  FindingSourceRegex,
 						},
 					}
 					results = append(results, sensitiveData)
@@ -379,34 +503,15 @@ func (ta *TextAnalyzer) analyzeWithKeywords(text string) []*SensitiveDataInfo {
 			continue
 		}
 
-		searchText := text
-		if !rule.CaseSensitive {
-			searchText = strings.ToLower(text)
-		}
-
-		for _, keyword := range rule.Keywords {
-			searchKeyword := keyword
-			if !rule.CaseSensitive {
-				searchKeyword = strings.ToLower(keyword)
-			}
-
-			var found bool
-			if rule.WholeWord {
-				// 使用正则表达式进行全词匹配
-				pattern := fmt.Sprintf(`\b%s\b`, regexp.QuoteMeta(searchKeyword))
-				regex, err := regexp.Compile(pattern)
-				if err == nil {
-					found = regex.MatchString(searchText)
-				}
-			} else {
-				found = strings.Contains(searchText, searchKeyword)
-			}
-
-			if found && rule.Confidence >= ta.config.MinConfidence {
+		for _, matcher := range rule.matchers {
+			keyword := matcher.keyword
+			loc := matcher.locate(text)
+			if loc != nil && rule.Confidence >= ta.config.MinConfidence {
 				sensitiveData := &SensitiveDataInfo{
 					Type:        rule.Type,
 					Value:       keyword,
-					MaskedValue: ta.maskValue(keyword),
+					MaskedValue: ta.maskValue(rule.Type, keyword),
+					Position:    newPosition(loc[0], loc[1]),
 					Confidence:  rule.Confidence,
 					Context:     ta.extractContext(text, keyword),
 					Metadata: map[string]interface{}{
@@ -414,6 +519,7 @@ func (ta *TextAnalyzer) analyzeWithKeywords(text string) []*SensitiveDataInfo {
 						"rule_name": rule.Name,
 						"category":  rule.Category,
 						"keyword":   keyword,
+						"detector":  FindingSourceKeyword,
 					},
 				}
 				results = append(results, sensitiveData)
@@ -424,6 +530,65 @@ func (ta *TextAnalyzer) analyzeWithKeywords(text string) []*SensitiveDataInfo {
 	return results
 }
 
+// compiledKeywordRule 关键词规则及其预先编译的匹配器，规则加载时编译一次，分析时复用
+type compiledKeywordRule struct {
+	*KeywordRule
+	matchers []keywordMatcher
+}
+
+// keywordMatcher 单个关键词的匹配器
+// 区分大小写且不要求全词匹配时直接查找子串，pattern 为 nil
+type keywordMatcher struct {
+	keyword string
+	pattern *regexp.Regexp
+}
+
+// compileKeywordRules 编译关键词规则
+func compileKeywordRules(rules []*KeywordRule) []*compiledKeywordRule {
+	compiled := make([]*compiledKeywordRule, 0, len(rules))
+	for _, rule := range rules {
+		compiled = append(compiled, compileKeywordRule(rule))
+	}
+	return compiled
+}
+
+// compileKeywordRule 为规则中的每个关键词编译匹配器
+// 不区分大小写时在原文上按正则匹配，保证偏移指向原文而不是转换大小写后的文本
+func compileKeywordRule(rule *KeywordRule) *compiledKeywordRule {
+	compiled := &compiledKeywordRule{
+		KeywordRule: rule,
+		matchers:    make([]keywordMatcher, 0, len(rule.Keywords)),
+	}
+	for _, keyword := range rule.Keywords {
+		matcher := keywordMatcher{keyword: keyword}
+		if !rule.CaseSensitive || rule.WholeWord {
+			pattern := regexp.QuoteMeta(keyword)
+			if rule.WholeWord {
+				// 使用正则表达式进行全词匹配
+				pattern = `\b` + pattern + `\b`
+			}
+			if !rule.CaseSensitive {
+				pattern = "(?i)" + pattern
+			}
+			matcher.pattern = regexp.MustCompile(pattern)
+		}
+		compiled.matchers = append(compiled.matchers, matcher)
+	}
+	return compiled
+}
+
+// locate 返回关键词在文本中首次出现的字节范围，未出现时返回 nil
+func (m keywordMatcher) locate(text string) []int {
+	if m.pattern != nil {
+		return m.pattern.FindStringIndex(text)
+	}
+	index := strings.Index(text, m.keyword)
+	if index < 0 {
+		return nil
+	}
+	return []int{index, index + len(m.keyword)}
+}
+
 // calculateRiskScore 计算风险评分
 func (ta *TextAnalyzer) calculateRiskScore(result *AnalysisResult) {
 	if len(result.SensitiveData) == 0 {
@@ -480,14 +645,16 @@ func (ta *TextAnalyzer) calculateRiskScore(result *AnalysisResult) {
 	}
 }
 
-// maskValue 掩码敏感值
-func (ta *TextAnalyzer) maskValue(value string) string {
-	if len(value) <= 4 {
-		return strings.Repeat("*", len(value))
+// maskValue 按敏感数据类型的脱敏规则掩码敏感值
+func (ta *TextAnalyzer) maskValue(dataType, value string) string {
+	ta.mu.RLock()
+	masker := ta.masker
+	ta.mu.RUnlock()
+	if masker == nil {
+		// 未初始化时使用默认规则
+		masker = NewMasker(DefaultMaskingConfig())
 	}
-
-	// 保留前2位和后2位，中间用*替换
-	return value[:2] + strings.Repeat("*", len(value)-4) + value[len(value)-2:]
+	return masker.Mask(dataType, value)
 }
 
 // extractContext 提取上下文
@@ -568,7 +735,7 @@ func (ta *TextAnalyzer) loadDefaultRules() error {
 	}
 
 	// 加载默认关键词规则
-	ta.keywordRules = []*KeywordRule{
+	ta.keywordRules = compileKeywordRules([]*KeywordRule{
 		{
 			ID:            "password_keywords",
 			Name:          "密码关键词",
@@ -595,7 +762,7 @@ func (ta *TextAnalyzer) loadDefaultRules() error {
 			WholeWord:     true,
 			Enabled:       true,
 		},
-	}
+	})
 
 	ta.logger.Info("加载默认规则",
 		"regex_rules", len(ta.regexRules),
@@ -605,27 +772,34 @@ func (ta *TextAnalyzer) loadDefaultRules() error {
 }
 
 // extractTextWithOCR 使用OCR提取文本
+// 先根据文件头识别图像格式，非图像和引擎不支持的格式直接跳过，动画或多页图像只识别第一帧
 func (ta *TextAnalyzer) extractTextWithOCR(ctx context.Context, data *parser.ParsedData) (string, error) {
-	// 检测文件类型
-	fileInfo, err := ta.fileDetector.DetectType(data.Body)
-	if err != nil {
-		return "", fmt.Errorf("文件类型检测失败: %w", err)
+	imageType := DetectImageType(data.Body)
+	ta.ocrStats.recordDetected(imageType.Format)
+
+	if !imageType.IsImage() {
+		return "", ta.skipOCR(OCRSkipNotImage, fmt.Errorf("不是图像文件: %s", data.ContentType))
+	}
+	if !ta.ocrSupports(imageType.MimeType) {
+		return "", ta.skipOCR(OCRSkipUnsupportedFormat, fmt.Errorf("OCR引擎不支持 %s", imageType.MimeType))
 	}
 
-	// 只对图像文件进行OCR
-	if !fileInfo.IsImage {
-		return "", fmt.Errorf("不是图像文件，无法进行OCR: %s", fileInfo.MimeType)
+	img, reason, err := decodeFirstFrame(data.Body, imageType)
+	if err != nil {
+		return "", ta.skipOCR(reason, err)
 	}
 
 	ta.logger.Debug("开始OCR文本提取",
-		"mime_type", fileInfo.MimeType,
+		"image_type", imageType.Format,
+		"multi_frame", imageType.MultiFrame,
 		"size", len(data.Body))
 
 	// 使用OCR引擎提取文本
-	text, err := ta.ocrEngine.ExtractTextFromBytes(ctx, data.Body)
+	text, err := ta.ocrEngine.ExtractText(ctx, img)
 	if err != nil {
 		return "", fmt.Errorf("OCR提取失败: %w", err)
 	}
+	ta.ocrStats.recordProcessed()
 
 	ta.logger.Debug("OCR文本提取完成",
 		"extracted_length", len(text))
@@ -633,6 +807,107 @@ func (ta *TextAnalyzer) extractTextWithOCR(ctx context.Context, data *parser.Par
 	return text, nil
 }
 
+// ocrSupports 检查OCR引擎是否支持指定的图像类型
+func (ta *TextAnalyzer) ocrSupports(mimeType string) bool {
+	for _, format := range ta.ocrEngine.GetSupportedFormats() {
+		if format == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+// skipOCR 记录跳过原因并返回跳过错误
+func (ta *TextAnalyzer) skipOCR(reason string, err error) error {
+	ta.ocrStats.recordSkipped(reason)
+	return &ocrSkipError{reason: reason, err: err}
+}
+
+// isImageContent 内容类型或文件头表明内容为图像
+func isImageContent(data *parser.ParsedData) bool {
+	return strings.HasPrefix(strings.ToLower(data.ContentType), "image/") || DetectImageType(data.Body).IsImage()
+}
+
+// ocrSkipError 内容不适合OCR，不属于OCR失败
+type ocrSkipError struct {
+	reason string
+	err    error
+}
+
+func (e *ocrSkipError) Error() string {
+	return fmt.Sprintf("跳过OCR (%s): %v", e.reason, e.err)
+}
+
+func (e *ocrSkipError) Unwrap() error {
+	return e.err
+}
+
+// OCRStats OCR统计信息
+type OCRStats struct {
+	Processed     uint64            `json:"processed"`      // 完成OCR的图像数量
+	DetectedTypes map[string]uint64 `json:"detected_types"` // 按检测到的图像格式计数，非图像记为 unknown
+	SkipReasons   map[string]uint64 `json:"skip_reasons"`   // 按跳过原因计数
+}
+
+// ocrStats OCR统计
+type ocrStats struct {
+	mu        sync.Mutex
+	processed uint64
+	detected  map[string]uint64
+	skipped   map[string]uint64
+}
+
+// recordDetected 记录检测到的图像格式
+func (s *ocrStats) recordDetected(format ImageFormat) {
+	name := string(format)
+	if format == ImageFormatUnknown {
+		name = "unknown"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.detected == nil {
+		s.detected = make(map[string]uint64)
+	}
+	s.detected[name]++
+}
+
+// recordSkipped 记录跳过原因
+func (s *ocrStats) recordSkipped(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.skipped == nil {
+		s.skipped = make(map[string]uint64)
+	}
+	s.skipped[reason]++
+}
+
+// recordProcessed 记录完成的OCR
+func (s *ocrStats) recordProcessed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processed++
+}
+
+// snapshot 返回统计快照
+func (s *ocrStats) snapshot() OCRStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := OCRStats{
+		Processed:     s.processed,
+		DetectedTypes: make(map[string]uint64, len(s.detected)),
+		SkipReasons:   make(map[string]uint64, len(s.skipped)),
+	}
+	for name, count := range s.detected {
+		stats.DetectedTypes[name] = count
+	}
+	for reason, count := range s.skipped {
+		stats.SkipReasons[reason] = count
+	}
+	return stats
+}
+
 // analyzeWithML 使用机器学习分析
 func (ta *TextAnalyzer) analyzeWithML(ctx context.Context, text string) (*MLPrediction, error) {
 	ta.logger.Debug("开始ML分析", "text_length", len(text))
@@ -715,6 +990,11 @@ func (ta *TextAnalyzer) GetMLModelInfo() *MLModelInfo {
 	return ta.mlModel.GetModelInfo()
 }
 
+// GetOCRStats 获取OCR统计信息
+func (ta *TextAnalyzer) GetOCRStats() OCRStats {
+	return ta.ocrStats.snapshot()
+}
+
 // GetOCRSupportedFormats 获取OCR支持的格式
 func (ta *TextAnalyzer) GetOCRSupportedFormats() []string {
 	if ta.ocrEngine == nil {
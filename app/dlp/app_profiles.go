@@ -0,0 +1,211 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// DefaultApplicationProfileName 未匹配任何应用配置时使用的默认配置名称
+const DefaultApplicationProfileName = "default"
+
+// ApplicationProfilesConfig 应用策略配置
+// 不同应用发送的数据按各自配置的规则子集和风险阈值评估，未匹配的应用使用 Default
+type ApplicationProfilesConfig struct {
+	Default  engine.PolicyProfile `yaml:"default" json:"default"`
+	Profiles []ApplicationProfile `yaml:"profiles" json:"profiles"`
+}
+
+// ApplicationProfile 按进程路径或可执行文件哈希匹配的应用策略配置
+type ApplicationProfile struct {
+	engine.PolicyProfile `yaml:",inline"`
+
+	// Paths 进程路径的通配符模式，不区分大小写；不含路径分隔符的模式只匹配文件名
+	Paths []string `yaml:"paths" json:"paths,omitempty"`
+	// Hashes 可执行文件的 SHA-256 摘要（十六进制）
+	Hashes []string `yaml:"hashes" json:"hashes,omitempty"`
+}
+
+// configured 检查是否配置了应用策略，未配置时评估全部规则并使用默认风险阈值
+func (c ApplicationProfilesConfig) configured() bool {
+	d := c.Default
+	return len(c.Profiles) > 0 || len(d.Rules) > 0 || d.AlertRiskLevel != "" || d.BlockRiskLevel != ""
+}
+
+// parseApplicationProfileSettings 解析应用策略配置
+func parseApplicationProfileSettings(settings map[string]interface{}, config *ApplicationProfilesConfig) error {
+	config.Default = parsePolicyProfile(sdk.GetConfigMap(settings, "default"), DefaultApplicationProfileName)
+	if err := config.Default.Validate(); err != nil {
+		return err
+	}
+
+	for i, item := range sdk.GetConfigSlice(settings, "profiles") {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		profile := ApplicationProfile{
+			PolicyProfile: parsePolicyProfile(entry, fmt.Sprintf("profile_%d", i+1)),
+			Paths:         sdk.GetConfigStringSlice(entry, "paths"),
+			Hashes:        sdk.GetConfigStringSlice(entry, "hashes"),
+		}
+		if len(profile.Paths) == 0 && len(profile.Hashes) == 0 {
+			return fmt.Errorf("应用配置 %s 未指定进程路径或哈希", profile.Name)
+		}
+		if err := profile.Validate(); err != nil {
+			return err
+		}
+		config.Profiles = append(config.Profiles, profile)
+	}
+	return nil
+}
+
+// parsePolicyProfile 解析策略配置的规则子集和风险阈值
+func parsePolicyProfile(settings map[string]interface{}, name string) engine.PolicyProfile {
+	return engine.PolicyProfile{
+		Name:           sdk.GetConfigString(settings, "name", name),
+		Rules:          sdk.GetConfigStringSlice(settings, "rules"),
+		AlertRiskLevel: sdk.GetConfigString(settings, "alert_risk_level", ""),
+		BlockRiskLevel: sdk.GetConfigString(settings, "block_risk_level", ""),
+	}
+}
+
+// executableHash 按路径缓存的可执行文件摘要，文件大小或修改时间变化后重新计算
+type executableHash struct {
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+// applicationProfiles 按数据包的进程信息选择应用策略配置
+type applicationProfiles struct {
+	config ApplicationProfilesConfig
+
+	mu     sync.Mutex
+	hashes map[string]executableHash
+}
+
+// newApplicationProfiles 创建应用策略配置选择器
+func newApplicationProfiles(config ApplicationProfilesConfig) *applicationProfiles {
+	if config.Default.Name == "" {
+		config.Default.Name = DefaultApplicationProfileName
+	}
+	profiles := make([]ApplicationProfile, len(config.Profiles))
+	for i, profile := range config.Profiles {
+		hashes := make([]string, len(profile.Hashes))
+		for j, hash := range profile.Hashes {
+			hashes[j] = strings.ToLower(hash)
+		}
+		profile.Hashes = hashes
+		profiles[i] = profile
+	}
+	config.Profiles = profiles
+	return &applicationProfiles{
+		config: config,
+		hashes: make(map[string]executableHash),
+	}
+}
+
+// match 返回进程对应的策略配置，按配置顺序取第一个匹配的应用，未匹配时返回默认配置
+func (a *applicationProfiles) match(process *interceptor.ProcessInfo) *engine.PolicyProfile {
+	if a == nil {
+		return nil
+	}
+	if process != nil {
+		for i := range a.config.Profiles {
+			profile := &a.config.Profiles[i]
+			if profile.matchesPath(process) || a.matchesHash(profile, process.ExecutePath) {
+				return &profile.PolicyProfile
+			}
+		}
+	}
+	return &a.config.Default
+}
+
+// matchesPath 检查进程路径或进程名是否匹配应用的路径模式
+func (p *ApplicationProfile) matchesPath(process *interceptor.ProcessInfo) bool {
+	executePath := normalizeProcessPath(process.ExecutePath)
+	name := strings.ToLower(process.ProcessName)
+	if name == "" && executePath != "" {
+		name = path.Base(executePath)
+	}
+
+	for _, pattern := range p.Paths {
+		pattern = normalizeProcessPath(pattern)
+		target := name
+		if strings.Contains(pattern, "/") {
+			target = executePath
+		}
+		if target == "" {
+			continue
+		}
+		if matched, _ := path.Match(pattern, target); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesHash 检查可执行文件的摘要是否在应用的哈希列表中
+func (a *applicationProfiles) matchesHash(profile *ApplicationProfile, executePath string) bool {
+	if len(profile.Hashes) == 0 || executePath == "" {
+		return false
+	}
+	sum, err := a.executableHash(executePath)
+	if err != nil {
+		return false
+	}
+	for _, hash := range profile.Hashes {
+		if hash == sum {
+			return true
+		}
+	}
+	return false
+}
+
+// executableHash 计算可执行文件的 SHA-256 摘要，文件未变化时使用缓存
+func (a *applicationProfiles) executableHash(executePath string) (string, error) {
+	info, err := os.Stat(executePath)
+	if err != nil {
+		return "", err
+	}
+
+	a.mu.Lock()
+	cached, ok := a.hashes[executePath]
+	a.mu.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.sum, nil
+	}
+
+	file, err := os.Open(executePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(hasher.Sum(nil))
+
+	a.mu.Lock()
+	a.hashes[executePath] = executableHash{size: info.Size(), modTime: info.ModTime(), sum: sum}
+	a.mu.Unlock()
+	return sum, nil
+}
+
+// normalizeProcessPath 统一路径分隔符和大小写
+func normalizeProcessPath(p string) string {
+	return strings.ToLower(strings.ReplaceAll(p, `\`, "/"))
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPolicyEngine 记录每个数据包的策略决策
+type recordingPolicyEngine struct {
+	engine.PolicyEngine
+
+	mu        sync.Mutex
+	decisions map[string]*engine.PolicyDecision
+}
+
+// EvaluatePolicy 评估策略并按数据包ID记录决策
+func (r *recordingPolicyEngine) EvaluatePolicy(ctx context.Context, decisionContext *engine.DecisionContext) (*engine.PolicyDecision, error) {
+	decision, err := r.PolicyEngine.EvaluatePolicy(ctx, decisionContext)
+	if err == nil && decisionContext.PacketInfo != nil {
+		r.mu.Lock()
+		r.decisions[decisionContext.PacketInfo.ID] = decision
+		r.mu.Unlock()
+	}
+	return decision, err
+}
+
+// writeExecutable 写入测试用的可执行文件并返回路径和 SHA-256 摘要
+func writeExecutable(t *testing.T, name, content string) (string, string) {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0755))
+	sum := sha256.Sum256([]byte(content))
+	return path, hex.EncodeToString(sum[:])
+}
+
+func TestParseApplicationProfileSettings(t *testing.T) {
+	var config ApplicationProfilesConfig
+	require.NoError(t, parseApplicationProfileSettings(map[string]interface{}{}, &config))
+	assert.False(t, config.configured())
+	assert.Equal(t, DefaultApplicationProfileName, config.Default.Name)
+
+	err := parseApplicationProfileSettings(map[string]interface{}{
+		"default": map[string]interface{}{"alert_risk_level": "medium"},
+		"profiles": []interface{}{
+			map[string]interface{}{
+				"name":  "browser",
+				"paths": []interface{}{"/usr/bin/*", "firefox.exe"},
+			},
+			map[string]interface{}{
+				"hashes":           []interface{}{"ABCDEF"},
+				"rules":            []interface{}{"audit_all"},
+				"block_risk_level": "none",
+			},
+		},
+	}, &config)
+	require.NoError(t, err)
+	assert.True(t, config.configured())
+	assert.Equal(t, "medium", config.Default.AlertRiskLevel)
+	require.Len(t, config.Profiles, 2)
+	assert.Equal(t, "browser", config.Profiles[0].Name)
+	assert.Equal(t, []string{"/usr/bin/*", "firefox.exe"}, config.Profiles[0].Paths)
+	assert.Equal(t, "profile_2", config.Profiles[1].Name)
+	assert.Equal(t, []string{"audit_all"}, config.Profiles[1].Rules)
+	assert.Equal(t, engine.RiskLevelNone, config.Profiles[1].BlockRiskLevel)
+
+	// 未指定匹配条件或风险级别无效时返回错误
+	assert.Error(t, parseApplicationProfileSettings(map[string]interface{}{
+		"profiles": []interface{}{map[string]interface{}{"name": "empty"}},
+	}, &ApplicationProfilesConfig{}))
+	assert.Error(t, parseApplicationProfileSettings(map[string]interface{}{
+		"default": map[string]interface{}{"block_risk_level": "severe"},
+	}, &ApplicationProfilesConfig{}))
+}
+
+func TestApplicationProfiles_Match(t *testing.T) {
+	backupPath, backupHash := writeExecutable(t, "backup-agent", "backup agent binary")
+	profiles := newApplicationProfiles(ApplicationProfilesConfig{
+		Profiles: []ApplicationProfile{
+			{PolicyProfile: engine.PolicyProfile{Name: "browser"}, Paths: []string{`C:\Program Files\*\chrome.exe`, "firefox.exe"}},
+			{PolicyProfile: engine.PolicyProfile{Name: "backup"}, Hashes: []string{strings.ToUpper(backupHash)}},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		process *interceptor.ProcessInfo
+		profile string
+	}{
+		{name: "完整路径匹配", process: &interceptor.ProcessInfo{ExecutePath: `c:\program files\Google\Chrome.exe`}, profile: "browser"},
+		{name: "进程名匹配", process: &interceptor.ProcessInfo{ProcessName: "Firefox.exe", ExecutePath: "/usr/lib/firefox/firefox.exe"}, profile: "browser"},
+		{name: "路径不在模式范围内", process: &interceptor.ProcessInfo{ExecutePath: `D:\tools\chrome.exe`}, profile: DefaultApplicationProfileName},
+		{name: "可执行文件哈希匹配", process: &interceptor.ProcessInfo{ProcessName: "renamed.exe", ExecutePath: backupPath}, profile: "backup"},
+		{name: "文件不存在", process: &interceptor.ProcessInfo{ExecutePath: filepath.Join(t.TempDir(), "missing")}, profile: DefaultApplicationProfileName},
+		{name: "没有进程信息", profile: DefaultApplicationProfileName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.profile, profiles.match(tt.process).Name)
+		})
+	}
+
+	// 可执行文件变化后重新计算哈希
+	require.NoError(t, os.WriteFile(backupPath, []byte("replaced binary"), 0755))
+	require.NoError(t, os.Chtimes(backupPath, time.Now(), time.Now().Add(time.Minute)))
+	assert.Equal(t, DefaultApplicationProfileName, profiles.match(&interceptor.ProcessInfo{ExecutePath: backupPath}).Name)
+
+	// 未配置应用策略时不选择配置
+	var disabled *applicationProfiles
+	assert.Nil(t, disabled.match(&interceptor.ProcessInfo{ProcessName: "chrome.exe"}))
+}
+
+func TestProcessTask_ApplicationProfiles(t *testing.T) {
+	module := newRunningTestModule(t)
+	recorder := &recordingPolicyEngine{PolicyEngine: module.policyEngine, decisions: make(map[string]*engine.PolicyDecision)}
+	module.policyEngine = recorder
+	require.NoError(t, recorder.AddRule(&engine.PolicyRule{
+		ID:         "block_sensitive_upload",
+		Name:       "阻断敏感数据上传",
+		Type:       "security",
+		Priority:   80,
+		Enabled:    true,
+		Conditions: []*engine.RuleCondition{{Field: "findings.count", Operator: "greater_than", Value: 0}},
+		Actions:    []*engine.RuleAction{{Type: engine.PolicyActionBlock}},
+	}))
+
+	backupPath, backupHash := writeExecutable(t, "backup-agent", "backup agent binary")
+	module.appProfiles = newApplicationProfiles(ApplicationProfilesConfig{
+		Profiles: []ApplicationProfile{
+			{PolicyProfile: engine.PolicyProfile{Name: "browser"}, Paths: []string{"chrome.exe"}},
+			{
+				// 备份工具只审计
+				PolicyProfile: engine.PolicyProfile{
+					Name:  "backup",
+					Rules: []string{"audit_all"},
+				},
+				Hashes: []string{backupHash},
+			},
+		},
+	})
+
+	sensitive := "客户信息：身份证号 110101199003077777，银行卡 6222021234567890128，手机 13812345678"
+	request := fmt.Sprintf("POST /upload HTTP/1.1\r\nHost: example.com\r\nContent-Type: text/plain\r\n"+
+		"Content-Length: %d\r\n\r\n%s", len(sensitive), sensitive)
+
+	tests := []struct {
+		id      string
+		process *interceptor.ProcessInfo
+		profile string
+		action  engine.PolicyAction
+		rules   []string
+	}{
+		{
+			id:      "browser-1",
+			process: &interceptor.ProcessInfo{PID: 100, ProcessName: "chrome.exe", ExecutePath: "/opt/google/chrome/chrome.exe"},
+			profile: "browser",
+			action:  engine.PolicyActionBlock,
+			rules:   []string{"block_sensitive_upload", "audit_all"},
+		},
+		{
+			id:      "backup-1",
+			process: &interceptor.ProcessInfo{PID: 200, ProcessName: "backup-agent", ExecutePath: backupPath},
+			profile: "backup",
+			action:  engine.PolicyActionAudit,
+			rules:   []string{"audit_all"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			err := module.processTask(&ProcessingTask{
+				ID:      tt.id,
+				Context: context.Background(),
+				Packet: &interceptor.PacketInfo{
+					ID:          tt.id,
+					Timestamp:   time.Now(),
+					Direction:   interceptor.PacketDirectionOutbound,
+					Protocol:    interceptor.ProtocolTCP,
+					SourceIP:    net.ParseIP("192.168.1.10"),
+					DestIP:      net.ParseIP("203.0.113.5"),
+					SourcePort:  50000,
+					DestPort:    80,
+					Payload:     []byte(request),
+					Size:        len(request),
+					Metadata:    make(map[string]interface{}),
+					ProcessInfo: tt.process,
+				},
+			})
+			require.NoError(t, err)
+
+			recorder.mu.Lock()
+			decision := recorder.decisions[tt.id]
+			recorder.mu.Unlock()
+			require.NotNil(t, decision)
+
+			assert.Equal(t, tt.profile, decision.Metadata["profile"])
+			assert.Equal(t, tt.action, decision.Action)
+			matched := make([]string, 0, len(decision.MatchedRules))
+			for _, rule := range decision.MatchedRules {
+				matched = append(matched, rule.RuleID)
+			}
+			assert.Equal(t, tt.rules, matched)
+		})
+	}
+}
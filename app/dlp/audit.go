@@ -0,0 +1,22 @@
+package main
+
+import (
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/executor"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// parseAuditWriterSettings 解析执行器配置中的审计日志批量写入配置，flush_interval 单位为毫秒
+func parseAuditWriterSettings(settings map[string]interface{}, config *executor.ExecutorConfig) {
+	writer := sdk.GetConfigMap(settings, "audit_writer")
+	if len(writer) == 0 {
+		return
+	}
+
+	audit := &config.AuditWriter
+	audit.Path = sdk.GetConfigString(writer, "path", audit.Path)
+	audit.BatchSize = sdk.GetConfigInt(writer, "batch_size", audit.BatchSize)
+	audit.FlushInterval = time.Duration(sdk.GetConfigInt(writer, "flush_interval", int(audit.FlushInterval/time.Millisecond))) * time.Millisecond
+	audit.QueueSize = sdk.GetConfigInt(writer, "queue_size", audit.QueueSize)
+}
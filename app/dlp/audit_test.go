@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/executor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAuditWriterSettings(t *testing.T) {
+	config := executor.DefaultExecutorConfig()
+	parseAuditWriterSettings(map[string]interface{}{
+		"audit_writer": map[string]interface{}{
+			"path":           "/var/log/dlp/audit.log",
+			"batch_size":     500,
+			"flush_interval": 250,
+		},
+	}, &config)
+
+	assert.Equal(t, executor.AuditWriterConfig{
+		Path:          "/var/log/dlp/audit.log",
+		BatchSize:     500,
+		FlushInterval: 250 * time.Millisecond,
+		QueueSize:     executor.DefaultAuditWriterConfig().QueueSize,
+	}, config.AuditWriter)
+
+	// 未配置时保持默认值
+	config = executor.DefaultExecutorConfig()
+	parseAuditWriterSettings(map[string]interface{}{}, &config)
+	assert.Equal(t, executor.DefaultAuditWriterConfig(), config.AuditWriter)
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/app/dlp/engine"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+const (
+	// BundleFormat 规则包格式标识
+	BundleFormat = "kennel-dlp-bundle"
+	// BundleFormatVersion 当前规则包格式版本，格式不兼容时递增
+	BundleFormatVersion = 1
+
+	// defaultBundleDictionaryDir 导入的词典文件默认保存目录
+	defaultBundleDictionaryDir = "dictionaries"
+)
+
+var (
+	// ErrBundleChecksum 规则包内容与校验和不一致
+	ErrBundleChecksum = errors.New("规则包校验和不匹配")
+	// ErrBundleIncompatible 规则包格式或版本不兼容
+	ErrBundleIncompatible = errors.New("规则包不兼容")
+)
+
+// ConfigBundle 规则包，包含版本信息、校验和和序列化的规则内容
+type ConfigBundle struct {
+	Format   string          `json:"format"`
+	Version  int             `json:"version"`
+	Checksum string          `json:"checksum"` // sha256:<hex>，按紧凑格式的 content 计算
+	Content  json.RawMessage `json:"content"`
+}
+
+// BundleContent 规则包内容
+type BundleContent struct {
+	ModuleVersion string               `json:"module_version"`
+	CreatedAt     time.Time            `json:"created_at"`
+	Rules         []*DLPRule           `json:"rules"`
+	Policies      []*engine.PolicyRule `json:"policies"`
+	Allowlists    BundleAllowlists     `json:"allowlists"`
+	Dictionaries  []BundleDictionary   `json:"dictionaries"`
+}
+
+// BundleAllowlists 规则包中的白名单
+type BundleAllowlists struct {
+	TrustedDestinations []string `json:"trusted_destinations"` // 熵检测的可信目的地
+}
+
+// BundleDictionary 规则包中的词典，包含配置和文件内容
+type BundleDictionary struct {
+	Config  analyzer.DictionaryConfig `json:"config"`
+	Content []byte                    `json:"content"`
+}
+
+// BundleSummary 规则包内容摘要
+type BundleSummary struct {
+	Version       int       `json:"version"`
+	ModuleVersion string    `json:"module_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Rules         int       `json:"rules"`
+	Policies      int       `json:"policies"`
+	Allowlists    int       `json:"allowlists"`
+	Dictionaries  int       `json:"dictionaries"`
+	Applied       bool      `json:"applied"`
+}
+
+// trustedDestinationStore 支持读取和替换可信目的地的分析管理器
+type trustedDestinationStore interface {
+	TrustedDestinations() []string
+	SetTrustedDestinations(destinations []string)
+}
+
+// bundleChecksum 计算规则包内容的校验和
+func bundleChecksum(content []byte) (string, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, content); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(compact.Bytes())
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// textAnalyzer 获取已注册的文本分析器
+func (m *DLPModule) textAnalyzer() (*analyzer.TextAnalyzer, bool) {
+	if m.analysisManager == nil {
+		return nil, false
+	}
+	contentAnalyzer, ok := m.analysisManager.GetAnalyzer("text/plain")
+	if !ok {
+		return nil, false
+	}
+	ta, ok := contentAnalyzer.(*analyzer.TextAnalyzer)
+	return ta, ok
+}
+
+// ExportBundle 将规则、策略、白名单和词典导出为带版本和校验和的规则包
+func (m *DLPModule) ExportBundle() ([]byte, error) {
+	content := BundleContent{
+		ModuleVersion: m.Version(),
+		CreatedAt:     time.Now().UTC(),
+		Rules:         []*DLPRule{},
+		Policies:      []*engine.PolicyRule{},
+		Dictionaries:  []BundleDictionary{},
+	}
+
+	if m.ruleManager != nil {
+		content.Rules = m.ruleManager.GetRules()
+		sort.Slice(content.Rules, func(i, j int) bool { return content.Rules[i].ID < content.Rules[j].ID })
+	}
+	if m.policyEngine != nil {
+		content.Policies = m.policyEngine.GetRules()
+		sort.Slice(content.Policies, func(i, j int) bool { return content.Policies[i].ID < content.Policies[j].ID })
+	}
+	if store, ok := m.analysisManager.(trustedDestinationStore); ok {
+		content.Allowlists.TrustedDestinations = store.TrustedDestinations()
+	}
+	if ta, ok := m.textAnalyzer(); ok {
+		for _, config := range ta.DictionaryConfigs() {
+			data, err := os.ReadFile(config.Path)
+			if err != nil {
+				return nil, fmt.Errorf("读取词典文件失败 %s: %w", config.Path, err)
+			}
+			content.Dictionaries = append(content.Dictionaries, BundleDictionary{Config: config, Content: data})
+		}
+	}
+
+	encoded, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("序列化规则包内容失败: %w", err)
+	}
+	checksum, err := bundleChecksum(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("计算规则包校验和失败: %w", err)
+	}
+
+	return json.MarshalIndent(ConfigBundle{
+		Format:   BundleFormat,
+		Version:  BundleFormatVersion,
+		Checksum: checksum,
+		Content:  encoded,
+	}, "", "  ")
+}
+
+// ValidateBundle 校验规则包的格式、版本、校验和及其中的规则，不修改任何状态
+func (m *DLPModule) ValidateBundle(data []byte) (*BundleContent, error) {
+	var bundle ConfigBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("解析规则包失败: %w", err)
+	}
+	if bundle.Format != BundleFormat {
+		return nil, fmt.Errorf("%w: 未知格式 %q", ErrBundleIncompatible, bundle.Format)
+	}
+	if bundle.Version != BundleFormatVersion {
+		return nil, fmt.Errorf("%w: 版本 %d，当前支持版本 %d", ErrBundleIncompatible, bundle.Version, BundleFormatVersion)
+	}
+	if len(bundle.Content) == 0 {
+		return nil, fmt.Errorf("规则包内容为空")
+	}
+
+	checksum, err := bundleChecksum(bundle.Content)
+	if err != nil {
+		return nil, fmt.Errorf("解析规则包内容失败: %w", err)
+	}
+	if checksum != bundle.Checksum {
+		return nil, fmt.Errorf("%w: 期望 %s，实际 %s", ErrBundleChecksum, bundle.Checksum, checksum)
+	}
+
+	var content BundleContent
+	if err := json.Unmarshal(bundle.Content, &content); err != nil {
+		return nil, fmt.Errorf("解析规则包内容失败: %w", err)
+	}
+	if err := validateBundleRules(content.Rules); err != nil {
+		return nil, err
+	}
+	engineConfig := engine.DefaultPolicyEngineConfig()
+	if m.dlpConfig != nil {
+		engineConfig = m.dlpConfig.EngineConfig
+	}
+	if err := engine.ValidateRules(engineConfig, content.Policies); err != nil {
+		return nil, fmt.Errorf("策略验证失败: %w", err)
+	}
+	for i, dictionary := range content.Dictionaries {
+		normalized, err := analyzer.ValidateDictionary(dictionary.Config, dictionary.Content)
+		if err != nil {
+			return nil, fmt.Errorf("词典验证失败 [%s]: %w", dictionary.Config.ID, err)
+		}
+		content.Dictionaries[i].Config = normalized
+	}
+	if err := validateBundleDictionaryNames(content.Dictionaries); err != nil {
+		return nil, err
+	}
+	return &content, nil
+}
+
+// validateBundleRules 验证规则包中的检测规则
+func validateBundleRules(rules []*DLPRule) error {
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule == nil {
+			return fmt.Errorf("规则不能为空")
+		}
+		if rule.ID == "" || rule.Pattern == "" {
+			return fmt.Errorf("规则缺少必要字段: ID=%s, Pattern=%s", rule.ID, rule.Pattern)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("规则ID重复: %s", rule.ID)
+		}
+		seen[rule.ID] = true
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("规则 %s 编译正则表达式失败: %w", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+// validateBundleDictionaryNames 检查词典ID和导入后的文件名不重复
+func validateBundleDictionaryNames(dictionaries []BundleDictionary) error {
+	ids := make(map[string]bool, len(dictionaries))
+	files := make(map[string]bool, len(dictionaries))
+	for _, dictionary := range dictionaries {
+		if ids[dictionary.Config.ID] {
+			return fmt.Errorf("词典ID重复: %s", dictionary.Config.ID)
+		}
+		ids[dictionary.Config.ID] = true
+
+		name := bundleDictionaryFile(dictionary.Config)
+		if files[name] {
+			return fmt.Errorf("词典文件名重复: %s", name)
+		}
+		files[name] = true
+	}
+	return nil
+}
+
+// bundleDictionaryFile 返回导入词典使用的文件名，只保留原路径的文件名部分
+func bundleDictionaryFile(config analyzer.DictionaryConfig) string {
+	name := filepath.Base(filepath.FromSlash(strings.ReplaceAll(config.Path, "\\", "/")))
+	if name == "." || name == string(filepath.Separator) || name == ".." {
+		name = config.ID + ".txt"
+	}
+	return name
+}
+
+// ImportBundle 校验并应用规则包
+// 校验失败时不修改任何规则；校验通过后替换检测规则、策略规则、可信目的地和词典，
+// 词典文件写入 bundle_dictionary_dir 配置的目录
+func (m *DLPModule) ImportBundle(data []byte) error {
+	content, err := m.ValidateBundle(data)
+	if err != nil {
+		return err
+	}
+	return m.applyBundle(content)
+}
+
+// applyBundle 应用已校验的规则包内容
+func (m *DLPModule) applyBundle(content *BundleContent) error {
+	ta, hasTextAnalyzer := m.textAnalyzer()
+	if len(content.Dictionaries) > 0 && !hasTextAnalyzer {
+		return fmt.Errorf("文本分析器未初始化，无法导入词典")
+	}
+	if m.ruleManager == nil {
+		m.ruleManager = NewRuleManager(m.Logger)
+	}
+
+	// 先写入词典文件，失败时不修改内存中的规则
+	dictionaries := make([]analyzer.DictionaryConfig, 0, len(content.Dictionaries))
+	if len(content.Dictionaries) > 0 {
+		dir := sdk.GetConfigString(m.Config, "bundle_dictionary_dir", defaultBundleDictionaryDir)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建词典目录失败: %w", err)
+		}
+		for _, dictionary := range content.Dictionaries {
+			config := dictionary.Config
+			config.Path = filepath.Join(dir, bundleDictionaryFile(config))
+			if err := os.WriteFile(config.Path, dictionary.Content, 0644); err != nil {
+				return fmt.Errorf("写入词典文件失败 %s: %w", config.Path, err)
+			}
+			dictionaries = append(dictionaries, config)
+		}
+	}
+
+	if err := m.ruleManager.ReplaceRules(content.Rules); err != nil {
+		return err
+	}
+	if m.policyEngine != nil {
+		if err := m.policyEngine.LoadRules(content.Policies); err != nil {
+			return fmt.Errorf("加载策略规则失败: %w", err)
+		}
+	}
+	if store, ok := m.analysisManager.(trustedDestinationStore); ok {
+		store.SetTrustedDestinations(content.Allowlists.TrustedDestinations)
+	}
+	if hasTextAnalyzer {
+		if err := ta.SetDictionaries(dictionaries); err != nil {
+			return fmt.Errorf("加载词典失败: %w", err)
+		}
+	}
+
+	m.Logger.Info("导入规则包",
+		"rules", len(content.Rules),
+		"policies", len(content.Policies),
+		"trusted_destinations", len(content.Allowlists.TrustedDestinations),
+		"dictionaries", len(content.Dictionaries))
+	return nil
+}
+
+// importBundle 处理规则包导入请求，dry_run 为 true 时只校验不应用
+func (m *DLPModule) importBundle(params map[string]interface{}) (*BundleSummary, error) {
+	data := sdk.GetConfigString(params, "bundle", "")
+	if data == "" {
+		return nil, sdk.InvalidParamError("规则包不能为空")
+	}
+
+	content, err := m.ValidateBundle([]byte(data))
+	if err != nil {
+		return nil, sdk.WrapError(sdk.ErrorCodeInvalidParam, err)
+	}
+	summary := &BundleSummary{
+		Version:       BundleFormatVersion,
+		ModuleVersion: content.ModuleVersion,
+		CreatedAt:     content.CreatedAt,
+		Rules:         len(content.Rules),
+		Policies:      len(content.Policies),
+		Allowlists:    len(content.Allowlists.TrustedDestinations),
+		Dictionaries:  len(content.Dictionaries),
+	}
+	if sdk.GetConfigBool(params, "dry_run", false) {
+		return summary, nil
+	}
+
+	if err := m.applyBundle(content); err != nil {
+		return nil, err
+	}
+	summary.Applied = true
+	return summary, nil
+}
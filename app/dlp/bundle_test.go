@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBundleTestModule 创建加载了词典和可信目的地的DLP模块
+func newBundleTestModule(t *testing.T) *DLPModule {
+	module := newRunningTestModule(t)
+	module.Config["bundle_dictionary_dir"] = filepath.Join(t.TempDir(), "imported")
+
+	path := filepath.Join(t.TempDir(), "projects.txt")
+	require.NoError(t, os.WriteFile(path, []byte("Project Aurora\n[customer]\nACME Corp\n"), 0644))
+
+	ta, ok := module.textAnalyzer()
+	require.True(t, ok)
+	require.NoError(t, ta.SetDictionaries([]analyzer.DictionaryConfig{{ID: "projects", Path: path, Category: "project"}}))
+
+	module.analysisManager.(trustedDestinationStore).SetTrustedDestinations([]string{"*.backup.example.com"})
+	return module
+}
+
+// rewriteBundle 修改规则包内容并重新计算校验和
+func rewriteBundle(t *testing.T, data []byte, modify func(content *BundleContent)) []byte {
+	var bundle ConfigBundle
+	require.NoError(t, json.Unmarshal(data, &bundle))
+	var content BundleContent
+	require.NoError(t, json.Unmarshal(bundle.Content, &content))
+	modify(&content)
+
+	encoded, err := json.Marshal(content)
+	require.NoError(t, err)
+	bundle.Content = encoded
+	bundle.Checksum, err = bundleChecksum(encoded)
+	require.NoError(t, err)
+
+	data, err = json.Marshal(bundle)
+	require.NoError(t, err)
+	return data
+}
+
+func TestBundle_RoundTrip(t *testing.T) {
+	source := newBundleTestModule(t)
+	require.NoError(t, source.ruleManager.AddRule(&DLPRule{ID: "project_code", Name: "项目代号", Pattern: `PRJ-\d{4}`, Action: "alert", Enabled: true}))
+
+	data, err := source.ExportBundle()
+	require.NoError(t, err)
+
+	target := newRunningTestModule(t)
+	dir := filepath.Join(t.TempDir(), "imported")
+	target.Config["bundle_dictionary_dir"] = dir
+	require.NoError(t, target.ImportBundle(data))
+
+	// 检测规则、策略规则、可信目的地和词典与导出方一致
+	rule, ok := target.ruleManager.GetRule("project_code")
+	require.True(t, ok)
+	assert.True(t, rule.regex.MatchString("PRJ-2024"))
+	assert.Len(t, target.ruleManager.GetRules(), len(source.ruleManager.GetRules()))
+	assert.ElementsMatch(t, policyIDs(source.policyEngine.GetRules()), policyIDs(target.policyEngine.GetRules()))
+	assert.Equal(t, []string{"*.backup.example.com"}, target.analysisManager.(trustedDestinationStore).TrustedDestinations())
+
+	ta, ok := target.textAnalyzer()
+	require.True(t, ok)
+	configs := ta.DictionaryConfigs()
+	require.Len(t, configs, 1)
+	assert.Equal(t, "projects", configs[0].ID)
+	assert.Equal(t, filepath.Join(dir, "projects.txt"), configs[0].Path)
+	dictionaries := ta.GetDictionaries()
+	require.Len(t, dictionaries, 1)
+	assert.Empty(t, dictionaries[0].LastError)
+	assert.Equal(t, 2, dictionaries[0].Terms)
+
+	// 再次导出的内容与原规则包一致
+	again, err := target.ExportBundle()
+	require.NoError(t, err)
+	first, err := source.ValidateBundle(data)
+	require.NoError(t, err)
+	second, err := target.ValidateBundle(again)
+	require.NoError(t, err)
+	assert.Equal(t, len(first.Rules), len(second.Rules))
+	assert.Equal(t, first.Dictionaries[0].Content, second.Dictionaries[0].Content)
+}
+
+func TestBundle_RejectsTampered(t *testing.T) {
+	module := newBundleTestModule(t)
+	data, err := module.ExportBundle()
+	require.NoError(t, err)
+
+	var bundle map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &bundle))
+	content := bundle["content"].(map[string]interface{})
+	content["rules"] = []interface{}{map[string]interface{}{"id": "evil", "pattern": ".*", "action": "allow"}}
+	tampered, err := json.Marshal(bundle)
+	require.NoError(t, err)
+
+	before := len(module.ruleManager.GetRules())
+	err = module.ImportBundle(tampered)
+	assert.ErrorIs(t, err, ErrBundleChecksum)
+	assert.Len(t, module.ruleManager.GetRules(), before)
+	_, exists := module.ruleManager.GetRule("evil")
+	assert.False(t, exists)
+}
+
+func TestBundle_RejectsIncompatible(t *testing.T) {
+	module := newBundleTestModule(t)
+	data, err := module.ExportBundle()
+	require.NoError(t, err)
+
+	for _, modify := range []func(bundle map[string]interface{}){
+		func(bundle map[string]interface{}) { bundle["version"] = BundleFormatVersion + 1 },
+		func(bundle map[string]interface{}) { bundle["format"] = "other-bundle" },
+	} {
+		var bundle map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &bundle))
+		modify(bundle)
+		incompatible, err := json.Marshal(bundle)
+		require.NoError(t, err)
+
+		_, err = module.ValidateBundle(incompatible)
+		assert.ErrorIs(t, err, ErrBundleIncompatible)
+		assert.ErrorIs(t, module.ImportBundle(incompatible), ErrBundleIncompatible)
+	}
+
+	_, err = module.ValidateBundle([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestBundle_InvalidContentNotApplied(t *testing.T) {
+	module := newBundleTestModule(t)
+	data, err := module.ExportBundle()
+	require.NoError(t, err)
+	rulesBefore := len(module.ruleManager.GetRules())
+	policiesBefore := len(module.policyEngine.GetRules())
+
+	cases := map[string]func(content *BundleContent){
+		"无效正则": func(content *BundleContent) {
+			content.Rules = append(content.Rules, &DLPRule{ID: "broken", Pattern: "(unclosed"})
+		},
+		"重复规则": func(content *BundleContent) {
+			content.Rules = append(content.Rules, content.Rules[0])
+		},
+		"无效策略": func(content *BundleContent) {
+			content.Policies = append(content.Policies, &engine.PolicyRule{ID: "no_conditions", Name: "无条件"})
+		},
+		"词典缺少路径": func(content *BundleContent) {
+			content.Dictionaries[0].Config.Path = ""
+		},
+	}
+	for name, modify := range cases {
+		t.Run(name, func(t *testing.T) {
+			// 校验和有效但内容无效，校验失败且不修改任何规则
+			invalid := rewriteBundle(t, data, func(content *BundleContent) {
+				content.Rules = append(content.Rules, &DLPRule{ID: "added", Pattern: "x"})
+				modify(content)
+			})
+			assert.Error(t, module.ImportBundle(invalid))
+			_, exists := module.ruleManager.GetRule("added")
+			assert.False(t, exists)
+			assert.Len(t, module.ruleManager.GetRules(), rulesBefore)
+			assert.Len(t, module.policyEngine.GetRules(), policiesBefore)
+		})
+	}
+}
+
+func TestBundle_DryRun(t *testing.T) {
+	source := newBundleTestModule(t)
+	require.NoError(t, source.ruleManager.AddRule(&DLPRule{ID: "project_code", Name: "项目代号", Pattern: `PRJ-\d{4}`}))
+	data, err := source.ExportBundle()
+	require.NoError(t, err)
+
+	target := newRunningTestModule(t)
+	dir := filepath.Join(t.TempDir(), "imported")
+	target.Config["bundle_dictionary_dir"] = dir
+
+	resp, err := target.HandleRequest(context.Background(), &plugin.Request{
+		ID:     "req-1",
+		Action: "import_bundle",
+		Params: map[string]interface{}{"bundle": string(data), "dry_run": true},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	assert.Equal(t, false, resp.Data["applied"])
+	assert.Equal(t, float64(len(source.ruleManager.GetRules())), resp.Data["rules"])
+	assert.Equal(t, float64(1), resp.Data["dictionaries"])
+
+	// 试运行不修改规则，也不写入词典文件
+	_, exists := target.ruleManager.GetRule("project_code")
+	assert.False(t, exists)
+	assert.NoDirExists(t, dir)
+
+	resp, err = target.HandleRequest(context.Background(), &plugin.Request{
+		ID:     "req-2",
+		Action: "import_bundle",
+		Params: map[string]interface{}{"bundle": string(data)},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	assert.Equal(t, true, resp.Data["applied"])
+	_, exists = target.ruleManager.GetRule("project_code")
+	assert.True(t, exists)
+	assert.FileExists(t, filepath.Join(dir, "projects.txt"))
+
+	// 无效规则包返回参数错误
+	resp, err = target.HandleRequest(context.Background(), &plugin.Request{
+		ID:     "req-3",
+		Action: "import_bundle",
+		Params: map[string]interface{}{"bundle": "{}", "dry_run": true},
+	})
+	require.NoError(t, err)
+	assert.False(t, resp.Success)
+}
+
+// policyIDs 返回策略规则ID列表
+func policyIDs(rules []*engine.PolicyRule) []string {
+	ids := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ids = append(ids, rule.ID)
+	}
+	return ids
+}
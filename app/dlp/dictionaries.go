@@ -0,0 +1,66 @@
+package main
+
+import (
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// parseAnalyzerSettings 解析分析器配置中的外部词典、熵检测、文档提取和精确数据匹配设置
+// dictionary_reload_interval 以秒为单位
+func parseAnalyzerSettings(settings map[string]interface{}, config *analyzer.AnalyzerConfig) {
+	for _, item := range sdk.GetConfigSlice(settings, "dictionaries") {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		dictionary := analyzer.DictionaryConfig{
+			ID:            sdk.GetConfigString(entry, "id", ""),
+			Path:          sdk.GetConfigString(entry, "path", ""),
+			Type:          sdk.GetConfigString(entry, "type", ""),
+			Category:      sdk.GetConfigString(entry, "category", ""),
+			Confidence:    getConfigFloat(entry, "confidence", 0),
+			CaseSensitive: sdk.GetConfigBool(entry, "case_sensitive", false),
+			WholeWord:     sdk.GetConfigBool(entry, "whole_word", false),
+		}
+		if level, ok := analyzer.ParseRiskLevel(sdk.GetConfigString(entry, "risk_level", "")); ok {
+			dictionary.RiskLevel = level
+		}
+		config.Dictionaries = append(config.Dictionaries, dictionary)
+	}
+
+	interval := sdk.GetConfigInt(settings, "dictionary_reload_interval", int(config.DictionaryReloadInterval/time.Second))
+	config.DictionaryReloadInterval = time.Duration(interval) * time.Second
+
+	entropy := sdk.GetConfigMap(settings, "entropy")
+	config.Entropy.Enabled = sdk.GetConfigBool(entropy, "enabled", config.Entropy.Enabled)
+	config.Entropy.Threshold = getConfigFloat(entropy, "threshold", config.Entropy.Threshold)
+	config.Entropy.MinSize = sdk.GetConfigInt(entropy, "min_size", config.Entropy.MinSize)
+	config.Entropy.TrustedDestinations = append(config.Entropy.TrustedDestinations, sdk.GetConfigStringSlice(entropy, "trusted_destinations")...)
+
+	documents := sdk.GetConfigMap(settings, "documents")
+	config.Documents.Enabled = sdk.GetConfigBool(documents, "enabled", config.Documents.Enabled)
+	config.Documents.MaxSize = int64(sdk.GetConfigInt(documents, "max_size", int(config.Documents.MaxSize)))
+	config.Documents.MaxPages = sdk.GetConfigInt(documents, "max_pages", config.Documents.MaxPages)
+	config.Documents.MaxTextSize = sdk.GetConfigInt(documents, "max_text_size", config.Documents.MaxTextSize)
+	config.Documents.ExtractImages = sdk.GetConfigBool(documents, "extract_images", config.Documents.ExtractImages)
+	config.Documents.MaxImages = sdk.GetConfigInt(documents, "max_images", config.Documents.MaxImages)
+
+	fingerprint := sdk.GetConfigMap(settings, "fingerprint")
+	config.Fingerprint.Enabled = sdk.GetConfigBool(fingerprint, "enabled", config.Fingerprint.Enabled)
+	config.Fingerprint.MinMatches = sdk.GetConfigInt(fingerprint, "min_matches", config.Fingerprint.MinMatches)
+	config.Fingerprint.MinTokenLength = sdk.GetConfigInt(fingerprint, "min_token_length", config.Fingerprint.MinTokenLength)
+	for _, item := range sdk.GetConfigSlice(fingerprint, "sources") {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		config.Fingerprint.Sources = append(config.Fingerprint.Sources, analyzer.FingerprintSourceConfig{
+			ID:         sdk.GetConfigString(entry, "id", ""),
+			Path:       sdk.GetConfigString(entry, "path", ""),
+			Delimiter:  sdk.GetConfigString(entry, "delimiter", ""),
+			SkipHeader: sdk.GetConfigBool(entry, "skip_header", false),
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAnalyzerSettings_Entropy(t *testing.T) {
+	config := analyzer.DefaultAnalyzerConfig()
+	parseAnalyzerSettings(map[string]interface{}{
+		"entropy": map[string]interface{}{
+			"threshold":            7.6,
+			"min_size":             4096,
+			"trusted_destinations": []interface{}{"*.backup.example.com", "10.0.0.0/8"},
+		},
+	}, &config)
+
+	assert.True(t, config.Entropy.Enabled)
+	assert.Equal(t, 7.6, config.Entropy.Threshold)
+	assert.Equal(t, 4096, config.Entropy.MinSize)
+	assert.Equal(t, []string{"*.backup.example.com", "10.0.0.0/8"}, config.Entropy.TrustedDestinations)
+
+	// 未配置熵检测时保留默认值
+	config = analyzer.DefaultAnalyzerConfig()
+	parseAnalyzerSettings(map[string]interface{}{}, &config)
+	assert.Equal(t, analyzer.DefaultEntropyConfig(), config.Entropy)
+}
+
+func TestParseAnalyzerSettings_Fingerprint(t *testing.T) {
+	config := analyzer.DefaultAnalyzerConfig()
+	parseAnalyzerSettings(map[string]interface{}{
+		"fingerprint": map[string]interface{}{
+			"min_matches": 4,
+			"sources": []interface{}{
+				map[string]interface{}{"id": "crm", "path": "edm/customers.csv", "skip_header": true},
+				map[string]interface{}{"path": "edm/employees.tsv", "delimiter": "tab"},
+			},
+		},
+	}, &config)
+
+	assert.True(t, config.Fingerprint.Enabled)
+	assert.Equal(t, 4, config.Fingerprint.MinMatches)
+	assert.Equal(t, analyzer.DefaultFingerprintConfig().MinTokenLength, config.Fingerprint.MinTokenLength)
+	assert.Equal(t, []analyzer.FingerprintSourceConfig{
+		{ID: "crm", Path: "edm/customers.csv", SkipHeader: true},
+		{Path: "edm/employees.tsv", Delimiter: "tab"},
+	}, config.Fingerprint.Sources)
+}
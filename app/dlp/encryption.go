@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/lomehong/kennel/app/dlp/executor"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// parseEncryptionSettings 解析执行器配置中的加密配置，密钥来源优先级为 key > key_env > key_path
+func parseEncryptionSettings(settings map[string]interface{}, config *executor.ExecutorConfig) {
+	encryption := sdk.GetConfigMap(settings, "encryption")
+	if len(encryption) == 0 {
+		return
+	}
+
+	config.Encryption.Algorithm = sdk.GetConfigString(encryption, "algorithm", config.Encryption.Algorithm)
+	config.Encryption.Key = sdk.GetConfigString(encryption, "key", config.Encryption.Key)
+	config.Encryption.KeyEnv = sdk.GetConfigString(encryption, "key_env", config.Encryption.KeyEnv)
+	config.Encryption.KeyPath = sdk.GetConfigString(encryption, "key_path", config.Encryption.KeyPath)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/executor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEncryptionSettings(t *testing.T) {
+	config := executor.DefaultExecutorConfig()
+	parseEncryptionSettings(map[string]interface{}{
+		"encryption": map[string]interface{}{
+			"algorithm": "AES-128-GCM",
+			"key_env":   "DLP_ENCRYPTION_KEY",
+			"key_path":  "/etc/kennel/dlp.key",
+		},
+	}, &config)
+
+	assert.Equal(t, executor.EncryptionConfig{
+		Algorithm: "AES-128-GCM",
+		KeyEnv:    "DLP_ENCRYPTION_KEY",
+		KeyPath:   "/etc/kennel/dlp.key",
+	}, config.Encryption)
+
+	// 未配置加密时保持默认值
+	config = executor.DefaultExecutorConfig()
+	parseEncryptionSettings(map[string]interface{}{}, &config)
+	assert.Equal(t, executor.EncryptionConfig{}, config.Encryption)
+}
@@ -31,27 +31,32 @@ type AuditConfig struct {
 	Compress   bool   `yaml:"compress" json:"compress"`
 }
 
-// NewAuditLogger 创建审计日志记录器
+// DefaultAuditLogPath 默认的策略决策审计日志路径
+const DefaultAuditLogPath = "logs/dlp_audit.log"
+
+// NewAuditLogger 创建审计日志记录器，日志写入默认路径
 func NewAuditLogger(logger logging.Logger) AuditLogger {
+	return NewAuditLoggerWithPath(logger, DefaultAuditLogPath)
+}
+
+// NewAuditLoggerWithPath 创建写入指定路径的审计日志记录器
+func NewAuditLoggerWithPath(logger logging.Logger, logPath string) AuditLogger {
+	if logPath == "" {
+		logPath = DefaultAuditLogPath
+	}
 	config := AuditConfig{
-		LogPath:    "logs/dlp_audit.log",
+		LogPath:    logPath,
 		MaxSize:    100 * 1024 * 1024, // 100MB
 		MaxAge:     30,                // 30天
 		MaxBackups: 10,
 		Compress:   true,
 	}
 
-	auditLogger := &AuditLoggerImpl{
+	// 日志文件在第一次写入时创建，未启用审计时不会生成空文件
+	return &AuditLoggerImpl{
 		logger: logger,
 		config: config,
 	}
-
-	// 初始化日志文件
-	if err := auditLogger.initLogFile(); err != nil {
-		logger.Error("初始化审计日志文件失败", "error", err)
-	}
-
-	return auditLogger
 }
 
 // LogDecision 记录决策
@@ -75,6 +80,9 @@ func (al *AuditLoggerImpl) LogDecision(decision *PolicyDecision) error {
 			"reason":          decision.Reason,
 		},
 	}
+	if decision.Explanation != nil {
+		auditLog.Details["explanation"] = decision.Explanation
+	}
 
 	// 从上下文中提取详细信息（增强版本）
 	if decision.Context != nil {
@@ -822,7 +830,9 @@ func (al *AuditLoggerImpl) initLogFile() error {
 // writeLog 写入日志
 func (al *AuditLoggerImpl) writeLog(auditLog *AuditLog) error {
 	if al.logFile == nil {
-		return fmt.Errorf("日志文件未初始化")
+		if err := al.initLogFile(); err != nil {
+			return err
+		}
 	}
 
 	// 序列化为JSON
@@ -0,0 +1,25 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogger_CreatesFileOnFirstWrite(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "logs", "dlp_audit.log")
+	auditLogger := NewAuditLoggerWithPath(newTestLogger(t), logPath)
+	defer auditLogger.(*AuditLoggerImpl).Close()
+
+	// 未写入任何记录时不生成日志文件
+	_, err := os.Stat(logPath)
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, auditLogger.LogEngineEvent("started", nil))
+	content, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "started")
+}
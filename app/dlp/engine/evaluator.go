@@ -3,10 +3,10 @@ package engine
 import (
 	"fmt"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/lomehong/kennel/app/dlp/interceptor"
 	"github.com/lomehong/kennel/pkg/logging"
 )
 
@@ -17,10 +17,15 @@ type RuleEvaluatorImpl struct {
 }
 
 // NewRuleEvaluator 创建规则评估器
-func NewRuleEvaluator(logger logging.Logger) RuleEvaluator {
+func NewRuleEvaluator(logger logging.Logger, regexCache *RegexCache) RuleEvaluator {
+	return newRuleEvaluator(logger, regexCache, GeoFenceConfig{})
+}
+
+// newRuleEvaluator 创建使用指定地理围栏配置的规则评估器
+func newRuleEvaluator(logger logging.Logger, regexCache *RegexCache, geoFence GeoFenceConfig) RuleEvaluator {
 	return &RuleEvaluatorImpl{
 		logger:             logger,
-		conditionEvaluator: NewConditionEvaluator(logger),
+		conditionEvaluator: newConditionEvaluator(logger, regexCache, geoFence),
 	}
 }
 
@@ -93,18 +98,36 @@ func (re *RuleEvaluatorImpl) CanEvaluate(ruleType string) bool {
 
 // ConditionEvaluatorImpl 条件评估器实现
 type ConditionEvaluatorImpl struct {
-	logger logging.Logger
+	logger     logging.Logger
+	regexCache *RegexCache
+	geoFence   GeoFenceConfig
+}
+
+// NewConditionEvaluator 创建条件评估器，regexCache为nil时使用默认限制创建
+func NewConditionEvaluator(logger logging.Logger, regexCache *RegexCache) ConditionEvaluator {
+	return newConditionEvaluator(logger, regexCache, GeoFenceConfig{})
 }
 
-// NewConditionEvaluator 创建条件评估器
-func NewConditionEvaluator(logger logging.Logger) ConditionEvaluator {
+// newConditionEvaluator 创建使用指定地理围栏配置的条件评估器
+func newConditionEvaluator(logger logging.Logger, regexCache *RegexCache, geoFence GeoFenceConfig) *ConditionEvaluatorImpl {
+	if regexCache == nil {
+		regexCache = NewRegexCache(DefaultRegexConfig())
+	}
+
 	return &ConditionEvaluatorImpl{
-		logger: logger,
+		logger:     logger,
+		regexCache: regexCache,
+		geoFence:   geoFence,
 	}
 }
 
 // EvaluateCondition 评估条件
 func (ce *ConditionEvaluatorImpl) EvaluateCondition(condition *RuleCondition, context *DecisionContext) (bool, error) {
+	// 地理围栏条件在国家未知时按配置放行或阻断，不作为评估失败处理
+	if isGeoFenceOperator(condition.Operator) {
+		return ce.evaluateGeoFence(condition, context), nil
+	}
+
 	// 获取字段值
 	fieldValue, err := ce.getFieldValue(condition.Field, context)
 	if err != nil {
@@ -126,6 +149,7 @@ func (ce *ConditionEvaluatorImpl) GetSupportedOperators() []string {
 		"in", "not_in",
 		"regex", "not_regex",
 		"exists", "not_exists",
+		"country_in", "country_not_in",
 	}
 }
 
@@ -142,8 +166,10 @@ func (ce *ConditionEvaluatorImpl) GetSupportedFields() []string {
 		"parsed_data.content_type",
 		"parsed_data.url",
 		"parsed_data.method",
+		"parsed_data.metadata.<key>",
 		"analysis_result.risk_level",
 		"analysis_result.risk_score",
+		"analysis_result.score",
 		"analysis_result.confidence",
 		"user_info.id",
 		"user_info.role",
@@ -152,6 +178,24 @@ func (ce *ConditionEvaluatorImpl) GetSupportedFields() []string {
 		"device_info.trust_level",
 		"environment.location",
 		"environment.working_hours",
+		"traffic_quota.exceeded",
+		"traffic_quota.bytes",
+		"traffic_quota.limit",
+		"traffic_quota.process_name",
+		"destination_geo.country",
+		"destination_geo.ip",
+		"destination_geo.network",
+		"flow.state",
+		"flow.bytes",
+		"flow.bytes_out",
+		"flow.bytes_in",
+		"flow.packets",
+		"flow.duration",
+		"findings.types",
+		"findings.count",
+		"findings.risk_level",
+		"findings.risk_score",
+		"findings.type.<name>",
 	}
 }
 
@@ -173,6 +217,9 @@ func (ce *ConditionEvaluatorImpl) getFieldValue(field string, context *DecisionC
 		if context.ParsedData == nil {
 			return nil, fmt.Errorf("解析数据为空")
 		}
+		if parts[1] == "metadata" && len(parts) > 2 {
+			return context.ParsedData.Metadata[strings.Join(parts[2:], ".")], nil
+		}
 		return ce.getParsedDataField(parts[1], context.ParsedData)
 
 	case "analysis_result":
@@ -199,6 +246,27 @@ func (ce *ConditionEvaluatorImpl) getFieldValue(field string, context *DecisionC
 		}
 		return ce.getEnvironmentField(parts[1], context.Environment)
 
+	case "traffic_quota":
+		if context.TrafficQuota == nil {
+			return nil, fmt.Errorf("流量配额信息为空")
+		}
+		return ce.getTrafficQuotaField(parts[1], context.TrafficQuota)
+
+	case "destination_geo":
+		if context.DestinationGeo == nil {
+			return nil, fmt.Errorf("目的地址地理位置信息为空")
+		}
+		return ce.getGeoField(parts[1], context.DestinationGeo)
+
+	case "flow":
+		if context.Flow == nil {
+			return nil, fmt.Errorf("流信息为空")
+		}
+		return ce.getFlowField(parts[1], context.Flow)
+
+	case "findings":
+		return ce.getFindingsField(parts[1:], context.FindingSummary())
+
 	default:
 		return nil, fmt.Errorf("不支持的字段前缀: %s", parts[0])
 	}
@@ -212,7 +280,7 @@ func (ce *ConditionEvaluatorImpl) getPacketInfoField(field string, packetInfo in
 		v = v.Elem()
 	}
 
-	fieldValue := v.FieldByName(strings.Title(field))
+	fieldValue := v.FieldByName(structFieldName(field))
 	if !fieldValue.IsValid() {
 		return nil, fmt.Errorf("字段不存在: %s", field)
 	}
@@ -227,7 +295,7 @@ func (ce *ConditionEvaluatorImpl) getParsedDataField(field string, parsedData in
 		v = v.Elem()
 	}
 
-	fieldValue := v.FieldByName(strings.Title(field))
+	fieldValue := v.FieldByName(structFieldName(field))
 	if !fieldValue.IsValid() {
 		return nil, fmt.Errorf("字段不存在: %s", field)
 	}
@@ -250,8 +318,21 @@ func (ce *ConditionEvaluatorImpl) getAnalysisResultField(field string, analysisR
 		}
 		// 转换为字符串
 		return fieldValue.String(), nil
+	case "risk_score":
+		fieldValue := v.FieldByName("RiskScore")
+		if !fieldValue.IsValid() {
+			return nil, fmt.Errorf("字段不存在: %s", field)
+		}
+		return fieldValue.Interface(), nil
+	case "score":
+		// 归一化风险评分（0-100）
+		fieldValue := v.FieldByName("RiskBreakdown")
+		if !fieldValue.IsValid() || fieldValue.IsNil() {
+			return nil, fmt.Errorf("字段不存在: %s", field)
+		}
+		return fieldValue.Elem().FieldByName("Score").Interface(), nil
 	default:
-		fieldValue := v.FieldByName(strings.Title(field))
+		fieldValue := v.FieldByName(structFieldName(field))
 		if !fieldValue.IsValid() {
 			return nil, fmt.Errorf("字段不存在: %s", field)
 		}
@@ -259,6 +340,21 @@ func (ce *ConditionEvaluatorImpl) getAnalysisResultField(field string, analysisR
 	}
 }
 
+// structFieldName 将蛇形字段名转换为结构体字段名，例如 dest_ip 转换为 DestIP
+func structFieldName(field string) string {
+	var name strings.Builder
+	for _, part := range strings.Split(field, "_") {
+		switch part {
+		case "id", "ip", "url":
+			name.WriteString(strings.ToUpper(part))
+		case "":
+		default:
+			name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return name.String()
+}
+
 // getUserInfoField 获取用户信息字段
 func (ce *ConditionEvaluatorImpl) getUserInfoField(field string, userInfo *UserInfo) (interface{}, error) {
 	switch field {
@@ -303,6 +399,124 @@ func (ce *ConditionEvaluatorImpl) getEnvironmentField(field string, environment
 	}
 }
 
+// getTrafficQuotaField 获取流量配额字段
+func (ce *ConditionEvaluatorImpl) getTrafficQuotaField(field string, quota *interceptor.QuotaStatus) (interface{}, error) {
+	switch field {
+	case "exceeded":
+		return quota.Exceeded, nil
+	case "bytes":
+		return quota.Bytes, nil
+	case "limit":
+		return quota.Limit, nil
+	case "process_name":
+		return quota.ProcessName, nil
+	default:
+		return nil, fmt.Errorf("不支持的流量配额字段: %s", field)
+	}
+}
+
+// getFlowField 获取流字段，duration 单位为秒
+func (ce *ConditionEvaluatorImpl) getFlowField(field string, flow *interceptor.Flow) (interface{}, error) {
+	switch field {
+	case "state":
+		return string(flow.State), nil
+	case "bytes":
+		return int64(flow.Bytes()), nil
+	case "bytes_out":
+		return int64(flow.BytesOut), nil
+	case "bytes_in":
+		return int64(flow.BytesIn), nil
+	case "packets":
+		return int64(flow.Packets()), nil
+	case "duration":
+		return flow.Duration().Seconds(), nil
+	default:
+		return nil, fmt.Errorf("不支持的流字段: %s", field)
+	}
+}
+
+// getFindingsField 获取全部分析结果的汇总字段
+// findings.type.<name> 返回该类型敏感数据的数量，未发现时为0
+func (ce *ConditionEvaluatorImpl) getFindingsField(path []string, summary FindingSummary) (interface{}, error) {
+	switch path[0] {
+	case "types":
+		return summary.Types, nil
+	case "count":
+		return summary.Count, nil
+	case "risk_level":
+		return summary.RiskLevel.String(), nil
+	case "risk_score":
+		return summary.RiskScore, nil
+	case "type":
+		if len(path) < 2 {
+			return nil, fmt.Errorf("缺少敏感数据类型: findings.type")
+		}
+		return summary.Counts[strings.Join(path[1:], ".")], nil
+	default:
+		return nil, fmt.Errorf("不支持的分析结果汇总字段: %s", path[0])
+	}
+}
+
+// getGeoField 获取地理位置字段
+func (ce *ConditionEvaluatorImpl) getGeoField(field string, geo *interceptor.GeoInfo) (interface{}, error) {
+	switch field {
+	case "country":
+		return geo.Country, nil
+	case "ip":
+		return geo.IP, nil
+	case "network":
+		return geo.Network, nil
+	default:
+		return nil, fmt.Errorf("不支持的地理位置字段: %s", field)
+	}
+}
+
+// isGeoFenceOperator 检查是否为地理围栏操作符
+func isGeoFenceOperator(operator string) bool {
+	return operator == "country_in" || operator == "country_not_in"
+}
+
+// evaluateGeoFence 评估地理围栏条件，国家代码不区分大小写
+// 字段缺失或为空时国家未知，FailClosed 时条件成立（视为违反围栏），否则不成立
+func (ce *ConditionEvaluatorImpl) evaluateGeoFence(condition *RuleCondition, context *DecisionContext) bool {
+	fieldValue, err := ce.getFieldValue(condition.Field, context)
+	country := ""
+	if err == nil && fieldValue != nil {
+		country = strings.ToUpper(strings.TrimSpace(fmt.Sprintf("%v", fieldValue)))
+	}
+	if country == "" {
+		return ce.geoFence.FailClosed
+	}
+
+	listed := countryListed(country, condition.Value)
+	if condition.Operator == "country_in" {
+		return listed
+	}
+	return !listed
+}
+
+// countryListed 检查国家代码是否在列表中，列表可以是切片或逗号分隔的字符串
+func countryListed(country string, list interface{}) bool {
+	var items []string
+	switch v := list.(type) {
+	case []string:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+	default:
+		items = strings.Split(fmt.Sprintf("%v", list), ",")
+	}
+
+	for _, item := range items {
+		if strings.EqualFold(strings.TrimSpace(item), country) {
+			return true
+		}
+	}
+	return false
+}
+
 // compareValues 比较值
 func (ce *ConditionEvaluatorImpl) compareValues(fieldValue interface{}, operator string, expectedValue interface{}) (bool, error) {
 	switch operator {
@@ -331,6 +545,10 @@ func (ce *ConditionEvaluatorImpl) compareValues(fieldValue interface{}, operator
 	case "not_regex":
 		matched, err := ce.regex(fieldValue, expectedValue)
 		return !matched, err
+	case "in":
+		return ce.in(fieldValue, expectedValue), nil
+	case "not_in":
+		return !ce.in(fieldValue, expectedValue), nil
 	case "exists":
 		return fieldValue != nil, nil
 	case "not_exists":
@@ -345,13 +563,41 @@ func (ce *ConditionEvaluatorImpl) equals(a, b interface{}) bool {
 	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
 }
 
-// contains 包含比较
+// contains 包含比较，字段为列表时检查是否包含该元素
 func (ce *ConditionEvaluatorImpl) contains(fieldValue, expectedValue interface{}) bool {
+	if items, ok := fieldValue.([]string); ok {
+		return ce.in(expectedValue, items)
+	}
+
 	fieldStr := fmt.Sprintf("%v", fieldValue)
 	expectedStr := fmt.Sprintf("%v", expectedValue)
 	return strings.Contains(fieldStr, expectedStr)
 }
 
+// in 检查字段值是否在期望的列表中，列表可以是切片或逗号分隔的字符串
+func (ce *ConditionEvaluatorImpl) in(fieldValue, expectedValue interface{}) bool {
+	fieldStr := fmt.Sprintf("%v", fieldValue)
+
+	var items []string
+	switch v := expectedValue.(type) {
+	case []string:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+	default:
+		items = strings.Split(fmt.Sprintf("%v", expectedValue), ",")
+	}
+
+	for _, item := range items {
+		if strings.TrimSpace(item) == fieldStr {
+			return true
+		}
+	}
+	return false
+}
+
 // startsWith 开始于比较
 func (ce *ConditionEvaluatorImpl) startsWith(fieldValue, expectedValue interface{}) bool {
 	fieldStr := fmt.Sprintf("%v", fieldValue)
@@ -422,13 +668,8 @@ func (ce *ConditionEvaluatorImpl) lessEqual(fieldValue, expectedValue interface{
 func (ce *ConditionEvaluatorImpl) regex(fieldValue, expectedValue interface{}) (bool, error) {
 	fieldStr := fmt.Sprintf("%v", fieldValue)
 	pattern := fmt.Sprintf("%v", expectedValue)
-	
-	regex, err := regexp.Compile(pattern)
-	if err != nil {
-		return false, fmt.Errorf("正则表达式编译失败: %w", err)
-	}
-	
-	return regex.MatchString(fieldStr), nil
+
+	return ce.regexCache.MatchString(pattern, fieldStr)
 }
 
 // toFloat64 转换为float64
@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionEvaluator_ParsedDataMetadata(t *testing.T) {
+	evaluator := NewConditionEvaluator(newTestLogger(t), nil)
+	context := &DecisionContext{
+		ParsedData: &parser.ParsedData{
+			Protocol: "https",
+			Metadata: map[string]any{
+				"server_cert_trusted":    false,
+				"server_cert_subject_cn": "upload.example.com",
+			},
+		},
+	}
+
+	matched, err := evaluator.EvaluateCondition(&RuleCondition{
+		Field: "parsed_data.metadata.server_cert_trusted", Operator: "equals", Value: false,
+	}, context)
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	matched, err = evaluator.EvaluateCondition(&RuleCondition{
+		Field: "parsed_data.metadata.server_cert_subject_cn", Operator: "ends_with", Value: ".example.com",
+	}, context)
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	// 不存在的元数据视为空值
+	matched, err = evaluator.EvaluateCondition(&RuleCondition{
+		Field: "parsed_data.metadata.server_cert_fingerprint", Operator: "exists",
+	}, context)
+	require.NoError(t, err)
+	assert.False(t, matched)
+}
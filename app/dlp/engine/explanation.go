@@ -0,0 +1,237 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// DecisionExplanation 决策解释，说明每个匹配规则的依据和最终动作的理由，用于合规审计报告
+type DecisionExplanation struct {
+	Action       string             `json:"action"`
+	Rationale    string             `json:"rationale"`               // 最终动作的理由
+	DecidingRule string             `json:"deciding_rule,omitempty"` // 决定动作的规则
+	DefaultUsed  bool               `json:"default_used"`            // 无规则匹配，使用默认动作
+	RiskOverride bool               `json:"risk_override"`           // 动作因风险级别被提升
+	EarlyStop    bool               `json:"early_stop"`              // 高优先级规则已决定阻断，未评估剩余规则
+	RiskLevel    string             `json:"risk_level"`
+	RiskScore    float64            `json:"risk_score"` // 0-100
+	Rules        []*RuleExplanation `json:"rules"`
+}
+
+// RuleExplanation 单个匹配规则的解释
+type RuleExplanation struct {
+	RuleID           string                  `json:"rule_id"`
+	RuleName         string                  `json:"rule_name"`
+	Priority         int                     `json:"priority"`
+	Action           string                  `json:"action"`
+	Deciding         bool                    `json:"deciding"`
+	Conditions       []*ConditionExplanation `json:"conditions"`
+	Findings         []*FindingExplanation   `json:"findings"`
+	RiskContribution float64                 `json:"risk_contribution"` // 满足规则的发现在风险评分（0-100）中所占的分值
+}
+
+// ConditionExplanation 规则条件及其实际取值
+type ConditionExplanation struct {
+	Field    string      `json:"field"`
+	Operator string      `json:"operator"`
+	Expected interface{} `json:"expected"`
+	Actual   interface{} `json:"actual"`
+}
+
+// FindingExplanation 满足规则的敏感数据发现，只记录脱敏后的值
+type FindingExplanation struct {
+	Type        string  `json:"type"`
+	MaskedValue string  `json:"masked_value,omitempty"`
+	Confidence  float64 `json:"confidence"`
+	ResultID    string  `json:"result_id,omitempty"` // 发现所属的分析结果
+}
+
+// decisionExplainer 根据规则条件和决策上下文生成决策解释
+type decisionExplainer struct {
+	conditions *ConditionEvaluatorImpl
+}
+
+// newDecisionExplainer 创建决策解释生成器
+func newDecisionExplainer(logger logging.Logger, regexCache *RegexCache) *decisionExplainer {
+	return &decisionExplainer{
+		conditions: NewConditionEvaluator(logger, regexCache).(*ConditionEvaluatorImpl),
+	}
+}
+
+// explain 生成决策解释
+// rules 为匹配规则对应的策略规则，preFinalAction 为根据风险级别调整前的动作
+func (e *decisionExplainer) explain(decision *PolicyDecision, rules map[string]*PolicyRule, decidingRule *MatchedRule, preFinalAction PolicyAction, earlyStop bool) *DecisionExplanation {
+	explanation := &DecisionExplanation{
+		Action:      decision.Action.String(),
+		DefaultUsed: len(decision.MatchedRules) == 0,
+		EarlyStop:   earlyStop,
+		RiskLevel:   decision.RiskLevel.String(),
+		RiskScore:   decision.RiskScore * 100,
+		Rules:       make([]*RuleExplanation, 0, len(decision.MatchedRules)),
+	}
+	explanation.RiskOverride = !explanation.DefaultUsed && decision.Action != preFinalAction
+	if decidingRule != nil {
+		explanation.DecidingRule = decidingRule.RuleID
+	}
+
+	context := decision.Context
+	if context == nil {
+		context = &DecisionContext{}
+	}
+	for _, matched := range decision.MatchedRules {
+		rule, ok := rules[matched.RuleID]
+		if !ok {
+			continue
+		}
+		explanation.Rules = append(explanation.Rules, e.explainRule(rule, matched, matched == decidingRule, context))
+	}
+
+	explanation.Rationale = e.rationale(decision, explanation, decidingRule, preFinalAction)
+	return explanation
+}
+
+// explainRule 解释单个匹配规则：条件的实际取值、满足条件的发现及其风险贡献
+func (e *decisionExplainer) explainRule(rule *PolicyRule, matched *MatchedRule, deciding bool, context *DecisionContext) *RuleExplanation {
+	explanation := &RuleExplanation{
+		RuleID:     rule.ID,
+		RuleName:   rule.Name,
+		Priority:   rule.Priority,
+		Action:     matched.Action.String(),
+		Deciding:   deciding,
+		Conditions: make([]*ConditionExplanation, 0, len(rule.Conditions)),
+		Findings:   make([]*FindingExplanation, 0),
+	}
+
+	scope := newFindingScope()
+	for _, condition := range rule.Conditions {
+		actual, err := e.conditions.getFieldValue(condition.Field, context)
+		if err != nil {
+			actual = nil
+		}
+		explanation.Conditions = append(explanation.Conditions, &ConditionExplanation{
+			Field:    condition.Field,
+			Operator: condition.Operator,
+			Expected: condition.Value,
+			Actual:   actual,
+		})
+		scope.add(condition)
+	}
+
+	for _, result := range scope.results(context) {
+		var contribution float64
+		for _, item := range result.SensitiveData {
+			if item == nil || !scope.includes(context, result, item.Type) {
+				continue
+			}
+			explanation.Findings = append(explanation.Findings, &FindingExplanation{
+				Type:        item.Type,
+				MaskedValue: item.MaskedValue,
+				Confidence:  item.Confidence,
+				ResultID:    result.ID,
+			})
+		}
+		if result.RiskBreakdown != nil {
+			for _, c := range result.RiskBreakdown.Contributions {
+				if scope.includes(context, result, c.Type) {
+					contribution += c.Score
+				}
+			}
+		}
+		// 决策风险取各分析结果中的最高值，规则的贡献同样取最高的一个结果
+		if contribution > explanation.RiskContribution {
+			explanation.RiskContribution = contribution
+		}
+	}
+	return explanation
+}
+
+// rationale 生成最终动作的理由
+func (e *decisionExplainer) rationale(decision *PolicyDecision, explanation *DecisionExplanation, decidingRule *MatchedRule, preFinalAction PolicyAction) string {
+	if explanation.DefaultUsed {
+		return fmt.Sprintf("无匹配规则，使用默认动作 %s", decision.Action.String())
+	}
+
+	var parts []string
+	if decidingRule != nil {
+		parts = append(parts, fmt.Sprintf("规则 %s (优先级 %d) 给出 %d 个匹配规则中最严格的动作 %s",
+			decidingRule.RuleName, decidingRule.Priority, len(decision.MatchedRules), preFinalAction.String()))
+	} else {
+		parts = append(parts, fmt.Sprintf("%d 个匹配规则均未指定动作，使用默认动作 %s", len(decision.MatchedRules), preFinalAction.String()))
+	}
+	if explanation.EarlyStop {
+		parts = append(parts, "高优先级规则已决定阻断，未评估剩余规则")
+	}
+	if explanation.RiskOverride {
+		parts = append(parts, fmt.Sprintf("%s，动作由 %s 提升为 %s", decision.Reason, preFinalAction.String(), decision.Action.String()))
+	}
+	return strings.Join(parts, "；")
+}
+
+// findingScope 规则条件涉及的发现范围
+type findingScope struct {
+	primary bool            // 条件涉及主分析结果的全部发现
+	all     bool            // 条件涉及全部分析结果的全部发现
+	types   map[string]bool // 条件涉及的敏感数据类型
+}
+
+func newFindingScope() *findingScope {
+	return &findingScope{types: make(map[string]bool)}
+}
+
+// add 根据条件字段确定涉及的发现
+// findings.type.<name> 和 findings.types 的比较值涉及对应类型，其余 findings.* 和 analysis_result.* 涉及全部发现
+func (s *findingScope) add(condition *RuleCondition) {
+	parts := strings.Split(condition.Field, ".")
+	switch parts[0] {
+	case "analysis_result":
+		s.primary = true
+	case "findings":
+		switch {
+		case len(parts) > 2 && parts[1] == "type":
+			s.types[strings.Join(parts[2:], ".")] = true
+		case len(parts) == 2 && parts[1] == "types":
+			for _, name := range conditionValues(condition.Value) {
+				s.types[name] = true
+			}
+		default:
+			s.all = true
+		}
+	}
+}
+
+// includes 检查分析结果中的某类发现是否在范围内
+func (s *findingScope) includes(context *DecisionContext, result *analyzer.AnalysisResult, findingType string) bool {
+	return s.all || s.types[findingType] || (s.primary && result == context.AnalysisResult)
+}
+
+// results 返回范围涉及的分析结果
+func (s *findingScope) results(context *DecisionContext) []*analyzer.AnalysisResult {
+	if !s.all && len(s.types) == 0 {
+		if s.primary && context.AnalysisResult != nil {
+			return []*analyzer.AnalysisResult{context.AnalysisResult}
+		}
+		return nil
+	}
+	return context.AnalysisResults()
+}
+
+// conditionValues 将条件的比较值转换为字符串列表
+func conditionValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	default:
+		return nil
+	}
+}
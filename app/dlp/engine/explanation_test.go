@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExplainingTestEngine(t *testing.T, rules ...*PolicyRule) PolicyEngine {
+	config := DefaultPolicyEngineConfig()
+	config.EnableAudit = false
+	config.ExplainDecisions = true
+	policyEngine := NewPolicyEngine(newTestLogger(t), config)
+	require.NoError(t, policyEngine.LoadRules(rules))
+	return policyEngine
+}
+
+// newScoredFinding 创建带评分明细的分析结果
+func newScoredFinding(id string, items ...*analyzer.SensitiveDataInfo) *analyzer.AnalysisResult {
+	result := &analyzer.AnalysisResult{ID: id, SensitiveData: items}
+	analyzer.NewRiskScorer(analyzer.DefaultRiskScoringConfig()).Apply(result)
+	return result
+}
+
+func sensitiveItem(dataType, value, masked string, confidence float64) *analyzer.SensitiveDataInfo {
+	return &analyzer.SensitiveDataInfo{Type: dataType, Value: value, MaskedValue: masked, Confidence: confidence}
+}
+
+// newExplanationTestContext 主分析结果包含邮箱（评分40），其他结果包含两个信用卡号（评分99）和电话（评分50）
+func newExplanationTestContext() *DecisionContext {
+	return &DecisionContext{
+		PacketInfo: &interceptor.PacketInfo{
+			Direction: interceptor.PacketDirectionOutbound,
+			Protocol:  interceptor.ProtocolTCP,
+			DestIP:    net.ParseIP("203.0.113.5"),
+			DestPort:  443,
+		},
+		AnalysisResult: newScoredFinding("body", sensitiveItem("email", "alice@example.com", "a***@example.com", 1.0)),
+		Findings: []*analyzer.AnalysisResult{
+			newScoredFinding("attachment",
+				sensitiveItem("credit_card", "4111111111111111", "************1111", 0.9),
+				sensitiveItem("credit_card", "5500000000000004", "************0004", 0.9),
+			),
+			newScoredFinding("ocr", sensitiveItem("phone", "13800138000", "138****8000", 1.0)),
+		},
+	}
+}
+
+func ruleExplanation(t *testing.T, explanation *DecisionExplanation, ruleID string) *RuleExplanation {
+	for _, rule := range explanation.Rules {
+		if rule.RuleID == ruleID {
+			return rule
+		}
+	}
+	require.Failf(t, "解释中缺少规则", "rule_id=%s", ruleID)
+	return nil
+}
+
+func TestEvaluatePolicy_Explanation(t *testing.T) {
+	policyEngine := newExplainingTestEngine(t,
+		cardToExternalRule(),
+		findingRule("alert_email", 80, PolicyActionAlert,
+			&RuleCondition{Field: "findings.type.email", Operator: "greater_than", Value: 0},
+		),
+		findingRule("audit_https", 10, PolicyActionAudit,
+			&RuleCondition{Field: "packet_info.dest_port", Operator: "equals", Value: 443},
+		),
+	)
+
+	decision, err := policyEngine.EvaluatePolicy(context.Background(), newExplanationTestContext())
+	require.NoError(t, err)
+	require.NotNil(t, decision.Explanation)
+
+	explanation := decision.Explanation
+	assert.Equal(t, "block", explanation.Action)
+	assert.Equal(t, "block_card_external", explanation.DecidingRule)
+	assert.False(t, explanation.DefaultUsed)
+	assert.False(t, explanation.RiskOverride)
+	assert.InDelta(t, 99, explanation.RiskScore, 0.001)
+	assert.Contains(t, explanation.Rationale, "block_card_external")
+	assert.Contains(t, explanation.Rationale, "3 个匹配规则")
+
+	// 规则按评估顺序（优先级降序）列出
+	require.Len(t, explanation.Rules, 3)
+	assert.Equal(t, []string{"alert_email", "block_card_external", "audit_https"},
+		[]string{explanation.Rules[0].RuleID, explanation.Rules[1].RuleID, explanation.Rules[2].RuleID})
+
+	card := ruleExplanation(t, explanation, "block_card_external")
+	assert.True(t, card.Deciding)
+	assert.Equal(t, "block", card.Action)
+	assert.Equal(t, 60, card.Priority)
+	require.Len(t, card.Conditions, 2)
+	assert.Equal(t, "findings.types", card.Conditions[0].Field)
+	assert.Equal(t, []string{"email", "credit_card", "phone"}, card.Conditions[0].Actual)
+	assert.Equal(t, "credit_card", card.Conditions[0].Expected)
+	assert.Equal(t, net.ParseIP("203.0.113.5"), card.Conditions[1].Actual)
+	assert.Equal(t, []*FindingExplanation{
+		{Type: "credit_card", MaskedValue: "************1111", Confidence: 0.9, ResultID: "attachment"},
+		{Type: "credit_card", MaskedValue: "************0004", Confidence: 0.9, ResultID: "attachment"},
+	}, card.Findings)
+	assert.InDelta(t, 99, card.RiskContribution, 0.001, "两个信用卡号占满了附件的评分")
+
+	email := ruleExplanation(t, explanation, "alert_email")
+	assert.False(t, email.Deciding)
+	assert.Equal(t, "alert", email.Action)
+	assert.Equal(t, 1, email.Conditions[0].Actual)
+	require.Len(t, email.Findings, 1)
+	assert.Equal(t, "email", email.Findings[0].Type)
+	assert.Equal(t, "body", email.Findings[0].ResultID)
+	assert.InDelta(t, 40, email.RiskContribution, 0.001)
+
+	https := ruleExplanation(t, explanation, "audit_https")
+	assert.Empty(t, https.Findings, "与发现无关的条件不应列出发现")
+	assert.Zero(t, https.RiskContribution)
+
+	// 可序列化为JSON，且不包含敏感数据原文
+	data, err := json.Marshal(decision)
+	require.NoError(t, err)
+	var report map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &report))
+	exported, ok := report["explanation"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "block_card_external", exported["deciding_rule"])
+	assert.Len(t, exported["rules"], 3)
+
+	explanationJSON, err := json.Marshal(explanation)
+	require.NoError(t, err)
+	assert.NotContains(t, string(explanationJSON), "4111111111111111")
+	assert.Contains(t, string(explanationJSON), "************1111")
+}
+
+func TestEvaluatePolicy_ExplanationRationale(t *testing.T) {
+	// 无规则匹配时使用默认动作
+	policyEngine := newExplainingTestEngine(t,
+		findingRule("alert_ssn", 50, PolicyActionAlert,
+			&RuleCondition{Field: "findings.type.ssn", Operator: "greater_than", Value: 0},
+		),
+	)
+	decision, err := policyEngine.EvaluatePolicy(context.Background(), newExplanationTestContext())
+	require.NoError(t, err)
+	assert.True(t, decision.Explanation.DefaultUsed)
+	assert.Empty(t, decision.Explanation.Rules)
+	assert.Contains(t, decision.Explanation.Rationale, "无匹配规则")
+
+	// 允许规则匹配但风险级别为关键，动作被提升为阻断
+	policyEngine = newExplainingTestEngine(t,
+		findingRule("allow_internal", 50, PolicyActionAllow,
+			&RuleCondition{Field: "findings.count", Operator: "greater_than", Value: 0},
+		),
+	)
+	decision, err = policyEngine.EvaluatePolicy(context.Background(), newExplanationTestContext())
+	require.NoError(t, err)
+	assert.Equal(t, PolicyActionBlock, decision.Action)
+	explanation := decision.Explanation
+	assert.True(t, explanation.RiskOverride)
+	assert.Contains(t, explanation.Rationale, "动作由 allow 提升为 block")
+	allow := ruleExplanation(t, explanation, "allow_internal")
+	assert.Len(t, allow.Findings, 4, "findings.count 涉及全部发现")
+	assert.InDelta(t, 99, allow.RiskContribution, 0.001, "风险贡献取最高的分析结果")
+
+	// 高优先级规则已决定阻断时提前结束
+	policyEngine = newExplainingTestEngine(t,
+		findingRule("block_critical", 95, PolicyActionBlock,
+			&RuleCondition{Field: "findings.risk_level", Operator: "equals", Value: "critical"},
+		),
+		findingRule("audit_https", 10, PolicyActionAudit,
+			&RuleCondition{Field: "packet_info.dest_port", Operator: "equals", Value: 443},
+		),
+	)
+	decision, err = policyEngine.EvaluatePolicy(context.Background(), newExplanationTestContext())
+	require.NoError(t, err)
+	assert.True(t, decision.Explanation.EarlyStop)
+	assert.Len(t, decision.Explanation.Rules, 1)
+	assert.Contains(t, decision.Explanation.Rationale, "未评估剩余规则")
+}
+
+func TestEvaluatePolicy_ExplanationDisabled(t *testing.T) {
+	policyEngine := newFindingsTestEngine(t, cardToExternalRule())
+
+	decision, err := policyEngine.EvaluatePolicy(context.Background(), newExplanationTestContext())
+	require.NoError(t, err)
+	assert.Equal(t, PolicyActionBlock, decision.Action)
+	assert.Nil(t, decision.Explanation)
+
+	data, err := json.Marshal(decision)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), `"explanation"`)
+}
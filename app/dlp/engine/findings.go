@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+)
+
+// actionRestrictiveness 动作的严格程度，多条规则冲突时取最严格的动作
+var actionRestrictiveness = map[PolicyAction]int{
+	PolicyActionAllow:      0,
+	PolicyActionAudit:      1,
+	PolicyActionAlert:      2,
+	PolicyActionEncrypt:    3,
+	PolicyActionRedirect:   4,
+	PolicyActionQuarantine: 5,
+	PolicyActionBlock:      6,
+}
+
+// MoreRestrictive 返回两个动作中更严格的一个
+// 严格程度从低到高：allow、audit、alert、encrypt、redirect、quarantine、block
+func MoreRestrictive(a, b PolicyAction) PolicyAction {
+	if actionRestrictiveness[b] > actionRestrictiveness[a] {
+		return b
+	}
+	return a
+}
+
+// FindingSummary 多个分析结果的汇总
+type FindingSummary struct {
+	Types      []string           `json:"types"`      // 敏感数据类型，按首次出现顺序去重
+	Counts     map[string]int     `json:"counts"`     // 各类型的敏感数据数量
+	Count      int                `json:"count"`      // 敏感数据总数
+	RiskLevel  analyzer.RiskLevel `json:"risk_level"` // 最高风险级别
+	RiskScore  float64            `json:"risk_score"` // 最高风险评分
+	Confidence float64            `json:"confidence"` // 最高置信度
+}
+
+// AnalysisResults 返回参与决策的全部分析结果
+// AnalysisResult 在前，随后是 Findings 中的其他结果
+func (c *DecisionContext) AnalysisResults() []*analyzer.AnalysisResult {
+	results := make([]*analyzer.AnalysisResult, 0, len(c.Findings)+1)
+	if c.AnalysisResult != nil {
+		results = append(results, c.AnalysisResult)
+	}
+	for _, finding := range c.Findings {
+		if finding == nil || finding == c.AnalysisResult {
+			continue
+		}
+		results = append(results, finding)
+	}
+	return results
+}
+
+// FindingSummary 汇总全部分析结果
+func (c *DecisionContext) FindingSummary() FindingSummary {
+	summary := FindingSummary{
+		Types:  make([]string, 0),
+		Counts: make(map[string]int),
+	}
+
+	for _, result := range c.AnalysisResults() {
+		if result.RiskLevel > summary.RiskLevel {
+			summary.RiskLevel = result.RiskLevel
+		}
+		if result.RiskScore > summary.RiskScore {
+			summary.RiskScore = result.RiskScore
+		}
+		if result.Confidence > summary.Confidence {
+			summary.Confidence = result.Confidence
+		}
+
+		for _, item := range result.SensitiveData {
+			if item == nil {
+				continue
+			}
+			if _, seen := summary.Counts[item.Type]; !seen {
+				summary.Types = append(summary.Types, item.Type)
+			}
+			summary.Counts[item.Type]++
+			summary.Count++
+		}
+	}
+
+	return summary
+}
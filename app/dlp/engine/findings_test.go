@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFindingsTestEngine(t *testing.T, rules ...*PolicyRule) PolicyEngine {
+	config := DefaultPolicyEngineConfig()
+	config.EnableAudit = false
+	policyEngine := NewPolicyEngine(newTestLogger(t), config)
+	require.NoError(t, policyEngine.LoadRules(rules))
+	return policyEngine
+}
+
+func newFinding(level analyzer.RiskLevel, score float64, sensitiveTypes ...string) *analyzer.AnalysisResult {
+	result := &analyzer.AnalysisResult{RiskLevel: level, RiskScore: score}
+	for _, sensitiveType := range sensitiveTypes {
+		result.SensitiveData = append(result.SensitiveData, &analyzer.SensitiveDataInfo{Type: sensitiveType})
+	}
+	return result
+}
+
+func newFindingsTestContext(destIP string) *DecisionContext {
+	return &DecisionContext{
+		PacketInfo: &interceptor.PacketInfo{
+			Direction: interceptor.PacketDirectionOutbound,
+			Protocol:  interceptor.ProtocolTCP,
+			DestIP:    net.ParseIP(destIP),
+			DestPort:  443,
+		},
+		AnalysisResult: newFinding(analyzer.RiskLevelLow, 0.2, "email"),
+		Findings: []*analyzer.AnalysisResult{
+			newFinding(analyzer.RiskLevelHigh, 0.8, "credit_card", "credit_card"),
+			newFinding(analyzer.RiskLevelMedium, 0.5, "phone"),
+		},
+	}
+}
+
+func findingRule(id string, priority int, action PolicyAction, conditions ...*RuleCondition) *PolicyRule {
+	return &PolicyRule{
+		ID:         id,
+		Name:       id,
+		Type:       "security",
+		Priority:   priority,
+		Enabled:    true,
+		Conditions: conditions,
+		Actions:    []*RuleAction{{Type: action}},
+	}
+}
+
+// cardToExternalRule 信用卡号发往白名单之外的目的地址时阻断
+func cardToExternalRule() *PolicyRule {
+	return findingRule("block_card_external", 60, PolicyActionBlock,
+		&RuleCondition{Field: "findings.types", Operator: "contains", Value: "credit_card"},
+		&RuleCondition{Field: "packet_info.dest_ip", Operator: "not_in", Value: []interface{}{"10.0.0.5", "10.0.0.6"}},
+	)
+}
+
+func TestFindingSummary_CombinesResults(t *testing.T) {
+	summary := newFindingsTestContext("203.0.113.5").FindingSummary()
+
+	assert.Equal(t, []string{"email", "credit_card", "phone"}, summary.Types)
+	assert.Equal(t, map[string]int{"email": 1, "credit_card": 2, "phone": 1}, summary.Counts)
+	assert.Equal(t, 4, summary.Count)
+	assert.Equal(t, analyzer.RiskLevelHigh, summary.RiskLevel)
+	assert.Equal(t, 0.8, summary.RiskScore)
+
+	// 同一分析结果同时出现在 AnalysisResult 和 Findings 中时只计算一次
+	ctx := &DecisionContext{AnalysisResult: newFinding(analyzer.RiskLevelLow, 0.1, "email")}
+	ctx.Findings = []*analyzer.AnalysisResult{ctx.AnalysisResult, nil}
+	assert.Len(t, ctx.AnalysisResults(), 1)
+	assert.Equal(t, 1, ctx.FindingSummary().Count)
+}
+
+func TestEvaluatePolicy_MultipleFindings(t *testing.T) {
+	policyEngine := newFindingsTestEngine(t,
+		cardToExternalRule(),
+		findingRule("alert_email", 80, PolicyActionAlert,
+			&RuleCondition{Field: "findings.type.email", Operator: "greater_than", Value: 0},
+		),
+		findingRule("audit_all", 10, PolicyActionAudit,
+			&RuleCondition{Field: "findings.count", Operator: "greater_than", Value: 0},
+		),
+	)
+
+	// 信用卡号发往外部地址：低优先级的阻断规则比高优先级的告警规则更严格
+	decision, err := policyEngine.EvaluatePolicy(context.Background(), newFindingsTestContext("203.0.113.5"))
+	require.NoError(t, err)
+	assert.Equal(t, PolicyActionBlock, decision.Action)
+	assert.Equal(t, "block_card_external", decision.Metadata["deciding_rule"])
+	assert.Len(t, decision.MatchedRules, 3)
+	assert.Equal(t, analyzer.RiskLevelHigh, decision.RiskLevel, "风险级别应取全部分析结果中的最高值")
+	assert.Equal(t, 0.8, decision.RiskScore)
+	assert.Contains(t, decision.Reason, "block_card_external")
+
+	// 目的地址在白名单中：阻断规则不匹配，告警优先于审计
+	decision, err = policyEngine.EvaluatePolicy(context.Background(), newFindingsTestContext("10.0.0.5"))
+	require.NoError(t, err)
+	assert.Equal(t, PolicyActionAlert, decision.Action)
+	assert.Equal(t, "alert_email", decision.Metadata["deciding_rule"])
+	assert.Len(t, decision.MatchedRules, 2)
+}
+
+func TestEvaluatePolicy_ConflictResolution(t *testing.T) {
+	// 允许规则的优先级更高，也不能覆盖更严格的动作
+	policyEngine := newFindingsTestEngine(t,
+		findingRule("allow_phone", 95, PolicyActionAllow,
+			&RuleCondition{Field: "findings.types", Operator: "contains", Value: "phone"},
+		),
+		findingRule("quarantine_card", 50, PolicyActionQuarantine,
+			&RuleCondition{Field: "findings.type.credit_card", Operator: "greater_equal", Value: 2},
+		),
+		findingRule("encrypt_high_risk", 70, PolicyActionEncrypt,
+			&RuleCondition{Field: "findings.risk_level", Operator: "in", Value: "high,critical"},
+		),
+	)
+
+	decision, err := policyEngine.EvaluatePolicy(context.Background(), newFindingsTestContext("203.0.113.5"))
+	require.NoError(t, err)
+	assert.Equal(t, PolicyActionQuarantine, decision.Action)
+	assert.Equal(t, "quarantine_card", decision.Metadata["deciding_rule"])
+	assert.Len(t, decision.MatchedRules, 3)
+
+	// 严格程度相同时以优先级更高的规则为准
+	policyEngine = newFindingsTestEngine(t,
+		findingRule("block_low", 20, PolicyActionBlock,
+			&RuleCondition{Field: "findings.types", Operator: "contains", Value: "email"},
+		),
+		cardToExternalRule(),
+	)
+	decision, err = policyEngine.EvaluatePolicy(context.Background(), newFindingsTestContext("203.0.113.5"))
+	require.NoError(t, err)
+	assert.Equal(t, PolicyActionBlock, decision.Action)
+	assert.Equal(t, "block_card_external", decision.Metadata["deciding_rule"])
+	assert.Equal(t, "匹配 2 个规则", decision.Reason, "动作一致时不属于冲突")
+}
+
+func TestMoreRestrictive(t *testing.T) {
+	order := []PolicyAction{
+		PolicyActionAllow, PolicyActionAudit, PolicyActionAlert, PolicyActionEncrypt,
+		PolicyActionRedirect, PolicyActionQuarantine, PolicyActionBlock,
+	}
+	for i := 1; i < len(order); i++ {
+		assert.Equal(t, order[i], MoreRestrictive(order[i-1], order[i]))
+		assert.Equal(t, order[i], MoreRestrictive(order[i], order[i-1]))
+	}
+}
@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluatePolicy_FlowConditions(t *testing.T) {
+	config := DefaultPolicyEngineConfig()
+	config.EnableAudit = false
+	config.DefaultAction = PolicyActionAllow
+	policyEngine := NewPolicyEngine(newTestLogger(t), config)
+	require.NoError(t, policyEngine.LoadRules([]*PolicyRule{
+		findingRule("large_flow_upload", 80, PolicyActionAlert,
+			&RuleCondition{Field: "flow.state", Operator: "equals", Value: "established"},
+			&RuleCondition{Field: "flow.bytes_out", Operator: "greater_than", Value: 1000000},
+		),
+	}))
+
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name   string
+		flow   *interceptor.Flow
+		action PolicyAction
+	}{
+		{"小流量", &interceptor.Flow{State: interceptor.FlowStateEstablished, BytesOut: 2048}, PolicyActionAllow},
+		{"大流量上传", &interceptor.Flow{State: interceptor.FlowStateEstablished, BytesOut: 5000000, StartTime: start, LastSeen: start.Add(time.Minute)}, PolicyActionAlert},
+		{"已关闭的流", &interceptor.Flow{State: interceptor.FlowStateClosing, BytesOut: 5000000}, PolicyActionAllow},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decisionContext := newGeoFenceTestContext(nil)
+			decisionContext.Flow = tc.flow
+			decision, err := policyEngine.EvaluatePolicy(context.Background(), decisionContext)
+			require.NoError(t, err)
+			assert.Equal(t, tc.action, decision.Action)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// geoFenceRule 敏感内容发往允许国家之外的目的地时阻断
+func geoFenceRule() *PolicyRule {
+	return findingRule("geo_fence_sensitive", 90, PolicyActionBlock,
+		&RuleCondition{Field: "findings.count", Operator: "greater_than", Value: 0},
+		&RuleCondition{Field: "destination_geo.country", Operator: "country_not_in", Value: []interface{}{"CN", "sg"}},
+	)
+}
+
+func newGeoFenceTestEngine(t *testing.T, failClosed bool) PolicyEngine {
+	config := DefaultPolicyEngineConfig()
+	config.EnableAudit = false
+	config.DefaultAction = PolicyActionAllow
+	config.GeoFence.FailClosed = failClosed
+	policyEngine := NewPolicyEngine(newTestLogger(t), config)
+	require.NoError(t, policyEngine.LoadRules([]*PolicyRule{geoFenceRule()}))
+	return policyEngine
+}
+
+// newGeoFenceTestContext 包含信用卡号的出站数据，geo 为富化步骤解析出的目的地地理位置
+func newGeoFenceTestContext(geo *interceptor.GeoInfo) *DecisionContext {
+	return &DecisionContext{
+		PacketInfo: &interceptor.PacketInfo{
+			Direction: interceptor.PacketDirectionOutbound,
+			Protocol:  interceptor.ProtocolTCP,
+			DestIP:    net.ParseIP("203.0.113.5"),
+			DestPort:  443,
+		},
+		AnalysisResult: newScoredFinding("body", sensitiveItem("credit_card", "4111111111111111", "************1111", 0.9)),
+		DestinationGeo: geo,
+	}
+}
+
+func TestEvaluatePolicy_GeoFence(t *testing.T) {
+	policyEngine := newGeoFenceTestEngine(t, false)
+
+	cases := []struct {
+		name    string
+		geo     *interceptor.GeoInfo
+		blocked bool
+	}{
+		{"允许的国家", &interceptor.GeoInfo{IP: "203.0.113.5", Country: "CN"}, false},
+		{"允许的国家不区分大小写", &interceptor.GeoInfo{IP: "203.0.113.5", Country: "SG"}, false},
+		{"不允许的国家", &interceptor.GeoInfo{IP: "203.0.113.5", Country: "US"}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			decision, err := policyEngine.EvaluatePolicy(context.Background(), newGeoFenceTestContext(tc.geo))
+			require.NoError(t, err)
+			if tc.blocked {
+				assert.Equal(t, PolicyActionBlock, decision.Action)
+				require.Len(t, decision.MatchedRules, 1)
+				assert.Equal(t, "geo_fence_sensitive", decision.MatchedRules[0].RuleID)
+			} else {
+				assert.Equal(t, PolicyActionAllow, decision.Action)
+				assert.Empty(t, decision.MatchedRules)
+			}
+		})
+	}
+
+	// 不含敏感内容时即使发往不允许的国家也放行
+	clean := newGeoFenceTestContext(&interceptor.GeoInfo{Country: "US"})
+	clean.AnalysisResult = &analyzer.AnalysisResult{ID: "body"}
+	decision, err := policyEngine.EvaluatePolicy(context.Background(), clean)
+	require.NoError(t, err)
+	assert.Equal(t, PolicyActionAllow, decision.Action)
+}
+
+func TestEvaluatePolicy_GeoFenceUnknownCountry(t *testing.T) {
+	unknown := map[string]*interceptor.GeoInfo{
+		"未富化":   nil,
+		"国家为空": {IP: "203.0.113.5"},
+	}
+
+	for name, geo := range unknown {
+		t.Run(name, func(t *testing.T) {
+			// 默认放行
+			decision, err := newGeoFenceTestEngine(t, false).EvaluatePolicy(context.Background(), newGeoFenceTestContext(geo))
+			require.NoError(t, err)
+			assert.Equal(t, PolicyActionAllow, decision.Action)
+
+			// fail_closed 时视为违反地理围栏
+			decision, err = newGeoFenceTestEngine(t, true).EvaluatePolicy(context.Background(), newGeoFenceTestContext(geo))
+			require.NoError(t, err)
+			assert.Equal(t, PolicyActionBlock, decision.Action)
+		})
+	}
+}
+
+func TestConditionEvaluator_CountryIn(t *testing.T) {
+	evaluator := newConditionEvaluator(newTestLogger(t), nil, GeoFenceConfig{FailClosed: true})
+	condition := &RuleCondition{Field: "destination_geo.country", Operator: "country_in", Value: "RU, KP"}
+
+	matched, err := evaluator.EvaluateCondition(condition, &DecisionContext{DestinationGeo: &interceptor.GeoInfo{Country: "kp"}})
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	matched, err = evaluator.EvaluateCondition(condition, &DecisionContext{DestinationGeo: &interceptor.GeoInfo{Country: "DE"}})
+	require.NoError(t, err)
+	assert.False(t, matched)
+
+	// 禁止国家列表在国家未知且 fail_closed 时同样成立
+	matched, err = evaluator.EvaluateCondition(condition, &DecisionContext{})
+	require.NoError(t, err)
+	assert.True(t, matched)
+
+	assert.Contains(t, evaluator.GetSupportedOperators(), "country_not_in")
+}
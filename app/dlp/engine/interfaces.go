@@ -23,6 +23,8 @@ type PolicyDecision struct {
 	Metadata       map[string]interface{} `json:"metadata"`
 	ProcessingTime time.Duration          `json:"processing_time"`
 	Context        *DecisionContext       `json:"context"`
+	Explanation    *DecisionExplanation   `json:"explanation,omitempty"` // 启用 ExplainDecisions 时生成
+	SampledOut     bool                   `json:"sampled_out,omitempty"` // 低风险决策未被抽中，跳过审计日志和动作执行
 }
 
 // PolicyAction 策略动作
@@ -60,6 +62,16 @@ func (pa PolicyAction) String() string {
 	}
 }
 
+// ParsePolicyAction 根据字符串解析策略动作
+func ParsePolicyAction(name string) (PolicyAction, bool) {
+	for action := PolicyActionAllow; action <= PolicyActionRedirect; action++ {
+		if action.String() == name {
+			return action, true
+		}
+	}
+	return PolicyActionAllow, false
+}
+
 // MatchedRule 匹配的规则
 type MatchedRule struct {
 	RuleID      string                 `json:"rule_id"`
@@ -74,13 +86,18 @@ type MatchedRule struct {
 
 // DecisionContext 决策上下文
 type DecisionContext struct {
-	PacketInfo     *interceptor.PacketInfo  `json:"packet_info"`
-	ParsedData     *parser.ParsedData       `json:"parsed_data"`
-	AnalysisResult *analyzer.AnalysisResult `json:"analysis_result"`
-	UserInfo       *UserInfo                `json:"user_info"`
-	DeviceInfo     *DeviceInfo              `json:"device_info"`
-	SessionInfo    *SessionInfo             `json:"session_info"`
-	Environment    *Environment             `json:"environment"`
+	PacketInfo     *interceptor.PacketInfo    `json:"packet_info"`
+	ParsedData     *parser.ParsedData         `json:"parsed_data"`
+	AnalysisResult *analyzer.AnalysisResult   `json:"analysis_result"`
+	Findings       []*analyzer.AnalysisResult `json:"findings,omitempty"` // 流水线各阶段产生的其他分析结果，与 AnalysisResult 一并参与决策
+	UserInfo       *UserInfo                  `json:"user_info"`
+	DeviceInfo     *DeviceInfo                `json:"device_info"`
+	SessionInfo    *SessionInfo               `json:"session_info"`
+	Environment    *Environment               `json:"environment"`
+	TrafficQuota   *interceptor.QuotaStatus   `json:"traffic_quota,omitempty"`   // 发送进程的流量配额状态
+	DestinationGeo *interceptor.GeoInfo       `json:"destination_geo,omitempty"` // 目的地址的地理位置，由富化步骤解析
+	Flow           *interceptor.Flow          `json:"flow,omitempty"`            // 数据包所属的流，启用流跟踪时设置
+	Profile        *PolicyProfile             `json:"profile,omitempty"`         // 发送数据的应用对应的策略配置，为空时评估全部规则
 }
 
 // UserInfo 用户信息
@@ -166,12 +183,35 @@ type PolicyEngineConfig struct {
 	CacheTTL       time.Duration  `yaml:"cache_ttl" json:"cache_ttl"`
 	EnableAudit    bool           `yaml:"enable_audit" json:"enable_audit"`
 	AuditLevel     string         `yaml:"audit_level" json:"audit_level"`
+	AuditLogPath   string         `yaml:"audit_log_path" json:"audit_log_path"`
 	DefaultAction  PolicyAction   `yaml:"default_action" json:"default_action"`
 	RulesPath      string         `yaml:"rules_path" json:"rules_path"`
 	EnableMLEngine bool           `yaml:"enable_ml_engine" json:"enable_ml_engine"`
 	MLModelPath    string         `yaml:"ml_model_path" json:"ml_model_path"`
 	MaxConcurrency int            `yaml:"max_concurrency" json:"max_concurrency"`
+	Regex          RegexConfig    `yaml:"regex" json:"regex"`
+	Backend        string         `yaml:"backend" json:"backend"`
+	OPA            OPAConfig      `yaml:"opa" json:"opa"`
 	Logger         logging.Logger `yaml:"-" json:"-"`
+
+	// ExplainDecisions 为内置后端的决策生成解释，列出匹配规则、满足规则的发现和风险贡献，供合规审计使用
+	ExplainDecisions bool `yaml:"explain_decisions" json:"explain_decisions"`
+
+	// RuleTemplates 规则模板，TemplateRules 中的实例在引擎启动时展开为具体规则
+	RuleTemplates []*RuleTemplate         `yaml:"rule_templates" json:"rule_templates"`
+	TemplateRules []*RuleTemplateInstance `yaml:"template_rules" json:"template_rules"`
+
+	// GeoFence 地理围栏条件（country_in、country_not_in）在目的地国家未知时的处理方式
+	GeoFence GeoFenceConfig `yaml:"geo_fence" json:"geo_fence"`
+
+	// Sampling 低风险决策抽样，减少审计和动作执行的负载
+	Sampling DecisionSamplingConfig `yaml:"sampling" json:"sampling"`
+}
+
+// GeoFenceConfig 地理围栏配置
+type GeoFenceConfig struct {
+	// FailClosed 为 true 时国家未知的目的地视为违反地理围栏，条件成立；默认放行，条件不成立
+	FailClosed bool `yaml:"fail_closed" json:"fail_closed"`
 }
 
 // DefaultPolicyEngineConfig 返回默认策略引擎配置
@@ -184,9 +224,13 @@ func DefaultPolicyEngineConfig() PolicyEngineConfig {
 		CacheTTL:       1 * time.Hour,
 		EnableAudit:    true,
 		AuditLevel:     "info",
+		AuditLogPath:   DefaultAuditLogPath,
 		DefaultAction:  PolicyActionAudit,
 		EnableMLEngine: false,
 		MaxConcurrency: 100,
+		Regex:          DefaultRegexConfig(),
+		Backend:        PolicyBackendBuiltin,
+		OPA:            DefaultOPAConfig(),
 	}
 }
 
@@ -236,9 +280,14 @@ type EngineStats struct {
 	FailedDecisions  uint64            `json:"failed_decisions"`
 	AverageTime      time.Duration     `json:"average_time"`
 	RuleStats        map[string]uint64 `json:"rule_stats"`
+	RegexStats       RegexCacheStats   `json:"regex_stats"`
 	LastError        error             `json:"last_error,omitempty"`
 	StartTime        time.Time         `json:"start_time"`
 	Uptime           time.Duration     `json:"uptime"`
+
+	// 低风险决策抽样统计
+	SampledDecisions    uint64 `json:"sampled_decisions"`     // 参与抽样的低风险决策数
+	SampledOutDecisions uint64 `json:"sampled_out_decisions"` // 未被抽中、跳过审计日志和动作执行的决策数
 }
 
 // RuleEvaluator 规则评估器接口
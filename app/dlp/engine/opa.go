@@ -0,0 +1,568 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// 策略引擎后端
+const (
+	PolicyBackendBuiltin = "builtin" // 内置规则引擎
+	PolicyBackendOPA     = "opa"     // 委托 OPA 服务通过 Rego 策略决策
+)
+
+// ErrOPARulesUnsupported OPA 后端的规则由 Rego 策略定义，不支持逐条管理
+var ErrOPARulesUnsupported = errors.New("OPA后端不支持规则管理，请修改Rego策略")
+
+// OPAConfig OPA 后端配置
+// 通过 OPA REST API 进行决策，Rego 策略可由 OPA 服务自行加载，
+// 也可以通过 Policy/PolicyFile 配置由引擎上传
+type OPAConfig struct {
+	URL        string        `yaml:"url" json:"url"`
+	PolicyPath string        `yaml:"policy_path" json:"policy_path"`
+	PolicyID   string        `yaml:"policy_id" json:"policy_id"`
+	Policy     string        `yaml:"policy" json:"policy"`
+	PolicyFile string        `yaml:"policy_file" json:"policy_file"`
+	Timeout    time.Duration `yaml:"timeout" json:"timeout"`
+}
+
+// DefaultOPAConfig 返回默认 OPA 配置
+func DefaultOPAConfig() OPAConfig {
+	return OPAConfig{
+		URL:        "http://127.0.0.1:8181",
+		PolicyPath: "dlp/decision",
+		PolicyID:   "kennel_dlp",
+		Timeout:    2 * time.Second,
+	}
+}
+
+// opaResponse OPA 数据查询响应
+type opaResponse struct {
+	Result *json.RawMessage `json:"result"`
+}
+
+// opaResult Rego 策略返回的决策对象
+type opaResult struct {
+	Action       string                 `json:"action"`
+	Reason       string                 `json:"reason"`
+	RiskScore    *float64               `json:"risk_score"`
+	Confidence   *float64               `json:"confidence"`
+	MatchedRules []json.RawMessage      `json:"matched_rules"`
+	Metadata     map[string]interface{} `json:"metadata"`
+}
+
+// opaMatchedRule Rego 策略返回的匹配规则
+type opaMatchedRule struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Action   string `json:"action"`
+	Priority int    `json:"priority"`
+}
+
+// OPAPolicyEngine 委托 OPA 进行决策的策略引擎
+type OPAPolicyEngine struct {
+	config        PolicyEngineConfig
+	logger        logging.Logger
+	client        *http.Client
+	policyHash    string
+	policyUploads uint64
+	sampler       *decisionSampler
+	stats         EngineStats
+	running       int32
+	mu            sync.RWMutex
+}
+
+// NewOPAPolicyEngine 创建 OPA 策略引擎
+func NewOPAPolicyEngine(logger logging.Logger, config PolicyEngineConfig) *OPAPolicyEngine {
+	return &OPAPolicyEngine{
+		config:  config,
+		logger:  logger,
+		client:  &http.Client{Timeout: config.OPA.Timeout},
+		sampler: newDecisionSampler(config.Sampling),
+		stats: EngineStats{
+			RuleStats: make(map[string]uint64),
+			StartTime: time.Now(),
+		},
+	}
+}
+
+// EvaluatePolicy 调用 OPA 评估策略
+func (oe *OPAPolicyEngine) EvaluatePolicy(ctx context.Context, context *DecisionContext) (*PolicyDecision, error) {
+	startTime := time.Now()
+	atomic.AddUint64(&oe.stats.TotalDecisions, 1)
+
+	decision := &PolicyDecision{
+		ID:           fmt.Sprintf("decision_%d", time.Now().UnixNano()),
+		Timestamp:    time.Now(),
+		Action:       oe.config.DefaultAction,
+		MatchedRules: make([]*MatchedRule, 0),
+		Metadata:     map[string]interface{}{"backend": PolicyBackendOPA},
+		Context:      context,
+	}
+	if context != nil {
+		findings := context.FindingSummary()
+		decision.RiskLevel = findings.RiskLevel
+		decision.RiskScore = findings.RiskScore
+	}
+
+	result, err := oe.query(ctx, buildOPAInput(context))
+	if err == nil {
+		err = oe.applyResult(decision, result)
+	}
+	if err != nil {
+		atomic.AddUint64(&oe.stats.FailedDecisions, 1)
+		oe.mu.Lock()
+		oe.stats.LastError = err
+		oe.mu.Unlock()
+		return nil, err
+	}
+
+	decision.ProcessingTime = time.Since(startTime)
+	oe.updateStats(decision)
+	oe.sampler.apply(decision, &oe.stats)
+
+	oe.logger.Debug("OPA策略评估完成",
+		"decision_id", decision.ID,
+		"action", decision.Action.String(),
+		"matched_rules", len(decision.MatchedRules),
+		"processing_time", decision.ProcessingTime)
+
+	return decision, nil
+}
+
+// query 查询 OPA 决策文档，策略未定义决策时返回 nil
+func (oe *OPAPolicyEngine) query(ctx context.Context, input map[string]interface{}) (json.RawMessage, error) {
+	body, err := json.Marshal(map[string]interface{}{"input": input})
+	if err != nil {
+		return nil, fmt.Errorf("序列化OPA输入失败: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/data/%s", strings.TrimRight(oe.config.OPA.URL, "/"), strings.Trim(oe.config.OPA.PolicyPath, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建OPA请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := oe.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OPA请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取OPA响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OPA返回错误状态 %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var response opaResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("解析OPA响应失败: %w", err)
+	}
+	if response.Result == nil {
+		return nil, nil
+	}
+	return *response.Result, nil
+}
+
+// applyResult 将 Rego 决策映射为策略决策
+// 支持三种结果：布尔值（true 放行，false 阻断）、动作名称字符串、决策对象
+func (oe *OPAPolicyEngine) applyResult(decision *PolicyDecision, raw json.RawMessage) error {
+	if raw == nil {
+		decision.Reason = "OPA策略未定义决策，使用默认动作"
+		return nil
+	}
+
+	var allowed bool
+	if err := json.Unmarshal(raw, &allowed); err == nil {
+		if allowed {
+			decision.Action = PolicyActionAllow
+			decision.Reason = "OPA策略允许"
+		} else {
+			decision.Action = PolicyActionBlock
+			decision.Reason = "OPA策略拒绝"
+		}
+		return nil
+	}
+
+	var result opaResult
+	var actionName string
+	if err := json.Unmarshal(raw, &actionName); err == nil {
+		result.Action = actionName
+	} else if err := json.Unmarshal(raw, &result); err != nil {
+		return fmt.Errorf("无法识别的OPA决策结果: %s", string(raw))
+	}
+
+	if result.Action != "" {
+		action, ok := ParsePolicyAction(result.Action)
+		if !ok {
+			return fmt.Errorf("OPA返回未知动作: %s", result.Action)
+		}
+		decision.Action = action
+	}
+	if result.RiskScore != nil {
+		decision.RiskScore = *result.RiskScore
+	}
+	if result.Confidence != nil {
+		decision.Confidence = *result.Confidence
+	}
+	for k, v := range result.Metadata {
+		decision.Metadata[k] = v
+	}
+
+	for _, item := range result.MatchedRules {
+		matchedRule, err := parseOPAMatchedRule(item, decision.Action)
+		if err != nil {
+			return err
+		}
+		decision.MatchedRules = append(decision.MatchedRules, matchedRule)
+
+		oe.mu.Lock()
+		oe.stats.RuleStats[matchedRule.RuleID]++
+		oe.mu.Unlock()
+	}
+
+	decision.Reason = result.Reason
+	if decision.Reason == "" {
+		decision.Reason = fmt.Sprintf("OPA策略决策: %s", decision.Action.String())
+	}
+	return nil
+}
+
+// parseOPAMatchedRule 解析匹配规则，支持规则ID字符串或规则对象
+func parseOPAMatchedRule(raw json.RawMessage, defaultAction PolicyAction) (*MatchedRule, error) {
+	var rule opaMatchedRule
+	if err := json.Unmarshal(raw, &rule.ID); err != nil {
+		if err := json.Unmarshal(raw, &rule); err != nil {
+			return nil, fmt.Errorf("无法识别的OPA匹配规则: %s", string(raw))
+		}
+	}
+
+	action := defaultAction
+	if rule.Action != "" {
+		parsed, ok := ParsePolicyAction(rule.Action)
+		if !ok {
+			return nil, fmt.Errorf("OPA规则 %s 返回未知动作: %s", rule.ID, rule.Action)
+		}
+		action = parsed
+	}
+
+	name := rule.Name
+	if name == "" {
+		name = rule.ID
+	}
+
+	return &MatchedRule{
+		RuleID:     rule.ID,
+		RuleName:   name,
+		RuleType:   PolicyBackendOPA,
+		Priority:   rule.Priority,
+		Action:     action,
+		Confidence: 1.0,
+	}, nil
+}
+
+// buildOPAInput 构造 OPA 输入文档
+// 只传递决策所需的元数据，不包含载荷和敏感数据原文
+func buildOPAInput(context *DecisionContext) map[string]interface{} {
+	input := make(map[string]interface{})
+	if context == nil {
+		return input
+	}
+
+	if packet := context.PacketInfo; packet != nil {
+		direction := "outbound"
+		if packet.Direction == interceptor.PacketDirectionInbound {
+			direction = "inbound"
+		}
+		packetInput := map[string]interface{}{
+			"id":          packet.ID,
+			"direction":   direction,
+			"protocol":    int(packet.Protocol),
+			"source_ip":   packet.SourceIP.String(),
+			"dest_ip":     packet.DestIP.String(),
+			"source_port": packet.SourcePort,
+			"dest_port":   packet.DestPort,
+			"size":        packet.Size,
+		}
+		if packet.ProcessInfo != nil {
+			packetInput["process_name"] = packet.ProcessInfo.ProcessName
+			packetInput["process_path"] = packet.ProcessInfo.ExecutePath
+			packetInput["user"] = packet.ProcessInfo.User
+		}
+		input["packet"] = packetInput
+	}
+
+	if parsed := context.ParsedData; parsed != nil {
+		input["parsed_data"] = map[string]interface{}{
+			"protocol":     parsed.Protocol,
+			"url":          parsed.URL,
+			"method":       parsed.Method,
+			"content_type": parsed.ContentType,
+			"status_code":  parsed.StatusCode,
+			"headers":      parsed.Headers,
+			"body_size":    len(parsed.Body),
+		}
+	}
+
+	if analysis := context.AnalysisResult; analysis != nil {
+		types := make([]string, 0, len(analysis.SensitiveData))
+		seen := make(map[string]bool)
+		for _, item := range analysis.SensitiveData {
+			if item == nil || seen[item.Type] {
+				continue
+			}
+			seen[item.Type] = true
+			types = append(types, item.Type)
+		}
+		analysisInput := map[string]interface{}{
+			"risk_level":      analysis.RiskLevel.String(),
+			"risk_score":      analysis.RiskScore,
+			"confidence":      analysis.Confidence,
+			"categories":      analysis.Categories,
+			"tags":            analysis.Tags,
+			"sensitive_types": types,
+			"sensitive_count": len(analysis.SensitiveData),
+		}
+		if analysis.RiskBreakdown != nil {
+			analysisInput["score"] = analysis.RiskBreakdown.Score
+		}
+		input["analysis"] = analysisInput
+	}
+
+	if len(context.Findings) > 0 {
+		findings := context.FindingSummary()
+		input["findings"] = map[string]interface{}{
+			"types":      findings.Types,
+			"counts":     findings.Counts,
+			"count":      findings.Count,
+			"risk_level": findings.RiskLevel.String(),
+			"risk_score": findings.RiskScore,
+		}
+	}
+
+	if context.UserInfo != nil {
+		input["user"] = context.UserInfo
+	}
+	if context.DeviceInfo != nil {
+		input["device"] = context.DeviceInfo
+	}
+	if context.SessionInfo != nil {
+		input["session"] = context.SessionInfo
+	}
+	if context.Environment != nil {
+		input["environment"] = context.Environment
+	}
+	if context.TrafficQuota != nil {
+		input["traffic_quota"] = context.TrafficQuota
+	}
+	if context.DestinationGeo != nil {
+		input["destination_geo"] = context.DestinationGeo
+	}
+	if context.Flow != nil {
+		input["flow"] = context.Flow
+	}
+	return input
+}
+
+// ReloadPolicy 重新读取 Rego 策略，内容变化时上传到 OPA
+// 策略按内容哈希缓存，未变化时不会重复上传和编译
+func (oe *OPAPolicyEngine) ReloadPolicy(ctx context.Context) error {
+	source, err := oe.policySource()
+	if err != nil {
+		return err
+	}
+	if source == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(source))
+	hash := hex.EncodeToString(sum[:])
+
+	oe.mu.Lock()
+	defer oe.mu.Unlock()
+
+	if hash == oe.policyHash {
+		return nil
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/policies/%s", strings.TrimRight(oe.config.OPA.URL, "/"), oe.config.OPA.PolicyID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, strings.NewReader(source))
+	if err != nil {
+		return fmt.Errorf("创建OPA策略上传请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain")
+
+	resp, err := oe.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("上传OPA策略失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("OPA策略编译失败 %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	oe.policyHash = hash
+	oe.policyUploads++
+	oe.logger.Info("已上传OPA策略", "policy_id", oe.config.OPA.PolicyID, "hash", hash[:12])
+	return nil
+}
+
+// policySource 获取配置的 Rego 策略源码，未配置时返回空字符串
+func (oe *OPAPolicyEngine) policySource() (string, error) {
+	if oe.config.OPA.Policy != "" {
+		return oe.config.OPA.Policy, nil
+	}
+	if oe.config.OPA.PolicyFile == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(oe.config.OPA.PolicyFile)
+	if err != nil {
+		return "", fmt.Errorf("读取Rego策略文件失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// LoadRules OPA 后端不支持
+func (oe *OPAPolicyEngine) LoadRules(rules []*PolicyRule) error {
+	return ErrOPARulesUnsupported
+}
+
+// AddRule OPA 后端不支持
+func (oe *OPAPolicyEngine) AddRule(rule *PolicyRule) error {
+	return ErrOPARulesUnsupported
+}
+
+// RemoveRule OPA 后端不支持
+func (oe *OPAPolicyEngine) RemoveRule(ruleID string) error {
+	return ErrOPARulesUnsupported
+}
+
+// UpdateRule OPA 后端不支持
+func (oe *OPAPolicyEngine) UpdateRule(rule *PolicyRule) error {
+	return ErrOPARulesUnsupported
+}
+
+// GetRule OPA 后端没有本地规则
+func (oe *OPAPolicyEngine) GetRule(ruleID string) (*PolicyRule, bool) {
+	return nil, false
+}
+
+// GetRules OPA 后端没有本地规则
+func (oe *OPAPolicyEngine) GetRules() []*PolicyRule {
+	return []*PolicyRule{}
+}
+
+// GetStats 获取统计信息
+func (oe *OPAPolicyEngine) GetStats() EngineStats {
+	oe.mu.RLock()
+	defer oe.mu.RUnlock()
+
+	stats := oe.stats
+	stats.RuleStats = make(map[string]uint64, len(oe.stats.RuleStats))
+	for id, count := range oe.stats.RuleStats {
+		stats.RuleStats[id] = count
+	}
+	stats.Uptime = time.Since(oe.stats.StartTime)
+	return stats
+}
+
+// PolicyUploads 获取 Rego 策略上传次数
+func (oe *OPAPolicyEngine) PolicyUploads() uint64 {
+	oe.mu.RLock()
+	defer oe.mu.RUnlock()
+
+	return oe.policyUploads
+}
+
+// Start 启动引擎并上传配置的 Rego 策略
+func (oe *OPAPolicyEngine) Start() error {
+	if oe.config.OPA.URL == "" {
+		return fmt.Errorf("未配置OPA服务地址")
+	}
+	if oe.config.OPA.PolicyPath == "" {
+		return fmt.Errorf("未配置OPA决策路径")
+	}
+	if !atomic.CompareAndSwapInt32(&oe.running, 0, 1) {
+		return fmt.Errorf("策略引擎已在运行")
+	}
+
+	oe.logger.Info("启动OPA策略引擎", "url", oe.config.OPA.URL, "policy_path", oe.config.OPA.PolicyPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), oe.config.Timeout)
+	defer cancel()
+	if err := oe.ReloadPolicy(ctx); err != nil {
+		atomic.StoreInt32(&oe.running, 0)
+		return err
+	}
+
+	oe.logger.Info("OPA策略引擎已启动")
+	return nil
+}
+
+// Stop 停止引擎
+func (oe *OPAPolicyEngine) Stop() error {
+	if !atomic.CompareAndSwapInt32(&oe.running, 1, 0) {
+		return fmt.Errorf("策略引擎未在运行")
+	}
+
+	oe.logger.Info("OPA策略引擎已停止")
+	return nil
+}
+
+// HealthCheck 检查 OPA 服务是否可用
+func (oe *OPAPolicyEngine) HealthCheck() error {
+	if atomic.LoadInt32(&oe.running) == 0 {
+		return fmt.Errorf("策略引擎未运行")
+	}
+
+	resp, err := oe.client.Get(strings.TrimRight(oe.config.OPA.URL, "/") + "/health")
+	if err != nil {
+		return fmt.Errorf("OPA服务不可用: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("OPA服务不健康: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// updateStats 更新统计信息
+func (oe *OPAPolicyEngine) updateStats(decision *PolicyDecision) {
+	switch decision.Action {
+	case PolicyActionAllow:
+		atomic.AddUint64(&oe.stats.AllowedDecisions, 1)
+	case PolicyActionBlock:
+		atomic.AddUint64(&oe.stats.BlockedDecisions, 1)
+	case PolicyActionAlert:
+		atomic.AddUint64(&oe.stats.AlertDecisions, 1)
+	case PolicyActionAudit:
+		atomic.AddUint64(&oe.stats.AuditDecisions, 1)
+	}
+
+	oe.mu.Lock()
+	oe.stats.AverageTime = (oe.stats.AverageTime + decision.ProcessingTime) / 2
+	oe.mu.Unlock()
+}
@@ -0,0 +1,236 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRegoPolicy 测试用 Rego 策略：外发身份证号时阻断，否则放行
+const testRegoPolicy = `package dlp
+
+import rego.v1
+
+default decision := {"action": "allow", "reason": "未发现敏感数据"}
+
+decision := {
+	"action": "block",
+	"reason": "外发身份证号",
+	"risk_score": 0.9,
+	"matched_rules": [{"id": "block_id_card", "name": "阻断身份证外发"}],
+} if {
+	input.packet.direction == "outbound"
+	"id_card" in input.analysis.sensitive_types
+}
+`
+
+// fakeOPAServer 模拟 OPA REST API，按 testRegoPolicy 的逻辑返回决策
+type fakeOPAServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	policies map[string]string
+	uploads  int
+	inputs   []map[string]interface{}
+}
+
+func newFakeOPAServer(t *testing.T) *fakeOPAServer {
+	fake := &fakeOPAServer{policies: make(map[string]string)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("/v1/policies/", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fake.mu.Lock()
+		fake.policies[r.URL.Path[len("/v1/policies/"):]] = string(body)
+		fake.uploads++
+		fake.mu.Unlock()
+		w.Write([]byte("{}"))
+	})
+	mux.HandleFunc("/v1/data/dlp/decision", func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Input map[string]interface{} `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		fake.mu.Lock()
+		fake.inputs = append(fake.inputs, request.Input)
+		loaded := fake.policies["kennel_dlp"] == testRegoPolicy
+		fake.mu.Unlock()
+		if !loaded {
+			// 策略未加载时决策文档未定义
+			w.Write([]byte("{}"))
+			return
+		}
+
+		result := map[string]interface{}{"action": "allow", "reason": "未发现敏感数据"}
+		packet, _ := request.Input["packet"].(map[string]interface{})
+		analysis, _ := request.Input["analysis"].(map[string]interface{})
+		types, _ := analysis["sensitive_types"].([]interface{})
+		for _, item := range types {
+			if item == "id_card" && packet["direction"] == "outbound" {
+				result = map[string]interface{}{
+					"action":        "block",
+					"reason":        "外发身份证号",
+					"risk_score":    0.9,
+					"matched_rules": []interface{}{map[string]interface{}{"id": "block_id_card", "name": "阻断身份证外发"}},
+				}
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+	})
+
+	fake.Server = httptest.NewServer(mux)
+	t.Cleanup(fake.Close)
+	return fake
+}
+
+func newOPATestEngine(t *testing.T, url string) *OPAPolicyEngine {
+	config := DefaultPolicyEngineConfig()
+	config.Backend = PolicyBackendOPA
+	config.OPA.URL = url
+	config.OPA.Policy = testRegoPolicy
+
+	policyEngine, ok := NewPolicyEngine(newTestLogger(t), config).(*OPAPolicyEngine)
+	require.True(t, ok, "backend为opa时应创建OPA策略引擎")
+	return policyEngine
+}
+
+func newOPATestContext(direction interceptor.PacketDirection, sensitiveTypes ...string) *DecisionContext {
+	analysis := &analyzer.AnalysisResult{RiskLevel: analyzer.RiskLevelLow}
+	for _, sensitiveType := range sensitiveTypes {
+		analysis.SensitiveData = append(analysis.SensitiveData, &analyzer.SensitiveDataInfo{
+			Type:  sensitiveType,
+			Value: "11010119900307777X",
+		})
+	}
+
+	return &DecisionContext{
+		PacketInfo: &interceptor.PacketInfo{
+			ID:        "pkt_1",
+			Direction: direction,
+			Protocol:  interceptor.ProtocolTCP,
+			SourceIP:  net.ParseIP("192.168.1.10"),
+			DestIP:    net.ParseIP("203.0.113.5"),
+			DestPort:  443,
+			Payload:   []byte("id=11010119900307777X"),
+		},
+		AnalysisResult: analysis,
+	}
+}
+
+func TestOPAPolicyEngine_BlockAndAllow(t *testing.T) {
+	fake := newFakeOPAServer(t)
+	policyEngine := newOPATestEngine(t, fake.URL)
+	require.NoError(t, policyEngine.Start())
+	defer policyEngine.Stop()
+	require.NoError(t, policyEngine.HealthCheck())
+
+	decision, err := policyEngine.EvaluatePolicy(context.Background(), newOPATestContext(interceptor.PacketDirectionOutbound, "id_card"))
+	require.NoError(t, err)
+	assert.Equal(t, PolicyActionBlock, decision.Action)
+	assert.Equal(t, "外发身份证号", decision.Reason)
+	assert.Equal(t, 0.9, decision.RiskScore)
+	require.Len(t, decision.MatchedRules, 1)
+	assert.Equal(t, "block_id_card", decision.MatchedRules[0].RuleID)
+	assert.Equal(t, PolicyActionBlock, decision.MatchedRules[0].Action)
+
+	decision, err = policyEngine.EvaluatePolicy(context.Background(), newOPATestContext(interceptor.PacketDirectionInbound, "id_card"))
+	require.NoError(t, err)
+	assert.Equal(t, PolicyActionAllow, decision.Action)
+	assert.Empty(t, decision.MatchedRules)
+
+	stats := policyEngine.GetStats()
+	assert.Equal(t, uint64(2), stats.TotalDecisions)
+	assert.Equal(t, uint64(1), stats.BlockedDecisions)
+	assert.Equal(t, uint64(1), stats.AllowedDecisions)
+	assert.Equal(t, uint64(1), stats.RuleStats["block_id_card"])
+
+	// 输入文档不应包含载荷和敏感数据原文
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	raw, err := json.Marshal(fake.inputs)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "11010119900307777X")
+}
+
+func TestOPAPolicyEngine_CachesPolicyUpload(t *testing.T) {
+	fake := newFakeOPAServer(t)
+	policyEngine := newOPATestEngine(t, fake.URL)
+
+	require.NoError(t, policyEngine.Start())
+	require.NoError(t, policyEngine.Stop())
+	require.NoError(t, policyEngine.Start())
+	require.NoError(t, policyEngine.ReloadPolicy(context.Background()))
+	defer policyEngine.Stop()
+
+	// 策略内容未变化，只上传编译一次
+	assert.Equal(t, uint64(1), policyEngine.PolicyUploads())
+	fake.mu.Lock()
+	assert.Equal(t, 1, fake.uploads)
+	fake.mu.Unlock()
+
+	policyEngine.config.OPA.Policy = testRegoPolicy + "\n# v2\n"
+	require.NoError(t, policyEngine.ReloadPolicy(context.Background()))
+	assert.Equal(t, uint64(2), policyEngine.PolicyUploads())
+}
+
+func TestOPAPolicyEngine_ResultMapping(t *testing.T) {
+	policyEngine := NewOPAPolicyEngine(newTestLogger(t), DefaultPolicyEngineConfig())
+
+	tests := []struct {
+		name   string
+		result string
+		action PolicyAction
+	}{
+		{"布尔允许", `true`, PolicyActionAllow},
+		{"布尔拒绝", `false`, PolicyActionBlock},
+		{"动作名称", `"alert"`, PolicyActionAlert},
+		{"决策对象", `{"action": "quarantine", "matched_rules": ["r1"]}`, PolicyActionQuarantine},
+		{"未定义", ``, PolicyActionAudit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := &PolicyDecision{Action: PolicyActionAudit, Metadata: make(map[string]interface{})}
+			var raw json.RawMessage
+			if tt.result != "" {
+				raw = json.RawMessage(tt.result)
+			}
+			require.NoError(t, policyEngine.applyResult(decision, raw))
+			assert.Equal(t, tt.action, decision.Action)
+		})
+	}
+
+	decision := &PolicyDecision{Metadata: make(map[string]interface{})}
+	assert.Error(t, policyEngine.applyResult(decision, json.RawMessage(`{"action": "explode"}`)))
+}
+
+func TestOPAPolicyEngine_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code": "internal_error"}`, http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	config := DefaultPolicyEngineConfig()
+	config.OPA.URL = server.URL
+	policyEngine := NewOPAPolicyEngine(newTestLogger(t), config)
+
+	_, err := policyEngine.EvaluatePolicy(context.Background(), newOPATestContext(interceptor.PacketDirectionOutbound))
+	assert.Error(t, err)
+	assert.Equal(t, uint64(1), policyEngine.GetStats().FailedDecisions)
+}
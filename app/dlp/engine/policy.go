@@ -8,7 +8,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/lomehong/kennel/app/dlp/analyzer"
 	"github.com/lomehong/kennel/pkg/logging"
 )
 
@@ -18,21 +17,39 @@ type PolicyEngineImpl struct {
 	logger        logging.Logger
 	rules         map[string]*PolicyRule
 	ruleEvaluator RuleEvaluator
+	regexCache    *RegexCache
 	auditLogger   AuditLogger
 	mlEngine      MLEngine
+	explainer     *decisionExplainer
+	sampler       *decisionSampler
 	stats         EngineStats
 	running       int32
 	mu            sync.RWMutex
 }
 
 // NewPolicyEngine 创建策略引擎
+// Backend 为 opa 时返回委托 OPA 服务进行决策的引擎
 func NewPolicyEngine(logger logging.Logger, config PolicyEngineConfig) PolicyEngine {
+	if config.Backend == PolicyBackendOPA {
+		return NewOPAPolicyEngine(logger, config)
+	}
+
+	regexCache := NewRegexCache(config.Regex)
+
+	var explainer *decisionExplainer
+	if config.ExplainDecisions {
+		explainer = newDecisionExplainer(logger, regexCache)
+	}
+
 	return &PolicyEngineImpl{
 		config:        config,
 		logger:        logger,
 		rules:         make(map[string]*PolicyRule),
-		ruleEvaluator: NewRuleEvaluator(logger),
-		auditLogger:   NewAuditLogger(logger),
+		ruleEvaluator: newRuleEvaluator(logger, regexCache, config.GeoFence),
+		regexCache:    regexCache,
+		auditLogger:   NewAuditLoggerWithPath(logger, config.AuditLogPath),
+		explainer:     explainer,
+		sampler:       newDecisionSampler(config.Sampling),
 		stats: EngineStats{
 			RuleStats: make(map[string]uint64),
 			StartTime: time.Now(),
@@ -45,13 +62,14 @@ func (pe *PolicyEngineImpl) EvaluatePolicy(ctx context.Context, context *Decisio
 	startTime := time.Now()
 	atomic.AddUint64(&pe.stats.TotalDecisions, 1)
 
-	// 创建决策结果
+	// 创建决策结果，风险取全部分析结果中的最高值
+	findings := context.FindingSummary()
 	decision := &PolicyDecision{
 		ID:           fmt.Sprintf("decision_%d", time.Now().UnixNano()),
 		Timestamp:    time.Now(),
 		Action:       pe.config.DefaultAction,
-		RiskLevel:    context.AnalysisResult.RiskLevel,
-		RiskScore:    context.AnalysisResult.RiskScore,
+		RiskLevel:    findings.RiskLevel,
+		RiskScore:    findings.RiskScore,
 		Confidence:   0.0,
 		MatchedRules: make([]*MatchedRule, 0),
 		Metadata:     make(map[string]interface{}),
@@ -61,9 +79,27 @@ func (pe *PolicyEngineImpl) EvaluatePolicy(ctx context.Context, context *Decisio
 	// 获取排序后的规则列表
 	rules := pe.getSortedRules()
 
+	// 应用策略配置决定参与评估的规则和风险升级阈值
+	var profile *PolicyProfile
+	if context != nil && context.Profile != nil {
+		profile = context.Profile
+		decision.Metadata["profile"] = profile.Name
+	}
+
+	// 多条规则匹配时取最严格的动作，严格程度相同时以优先级更高（先匹配）的规则为准
+	var decidingRule *MatchedRule
+
+	// 生成决策解释时需要匹配规则的条件
+	var explainedRules map[string]*PolicyRule
+	earlyStop := false
+	if pe.explainer != nil {
+		explainedRules = make(map[string]*PolicyRule)
+	}
+
 	// 评估规则
 	for _, rule := range rules {
-		if !rule.Enabled {
+		// 跳过禁用的规则和应用策略配置之外的规则
+		if !rule.Enabled || !profile.allowsRule(rule.ID) {
 			continue
 		}
 
@@ -97,10 +133,16 @@ func (pe *PolicyEngineImpl) EvaluatePolicy(ctx context.Context, context *Decisio
 			// 确定动作
 			if len(result.Actions) > 0 {
 				matchedRule.Action = result.Actions[0].Type
-				decision.Action = result.Actions[0].Type
+				if decidingRule == nil || MoreRestrictive(decidingRule.Action, matchedRule.Action) != decidingRule.Action {
+					decidingRule = matchedRule
+					decision.Action = matchedRule.Action
+				}
 			}
 
 			decision.MatchedRules = append(decision.MatchedRules, matchedRule)
+			if explainedRules != nil {
+				explainedRules[rule.ID] = rule
+			}
 
 			// 更新置信度
 			if result.Confidence > decision.Confidence {
@@ -112,14 +154,26 @@ func (pe *PolicyEngineImpl) EvaluatePolicy(ctx context.Context, context *Decisio
 			pe.stats.RuleStats[rule.ID]++
 			pe.mu.Unlock()
 
-			// 如果是高优先级规则且置信度高，可以提前结束评估
-			if rule.Priority >= 90 && result.Confidence >= 0.9 {
+			// 高优先级、高置信度的规则已决定阻断时，后续规则无法给出更严格的动作，提前结束评估
+			if rule.Priority >= 90 && result.Confidence >= 0.9 && decision.Action == PolicyActionBlock {
 				decision.Reason = fmt.Sprintf("高优先级规则匹配: %s", rule.Name)
+				earlyStop = true
 				break
 			}
 		}
 	}
 
+	if decidingRule != nil {
+		decision.Metadata["deciding_rule"] = decidingRule.RuleID
+		if len(findings.Types) > 0 {
+			decision.Metadata["finding_types"] = findings.Types
+		}
+		if decision.Reason == "" && hasConflictingActions(decision.MatchedRules) {
+			decision.Reason = fmt.Sprintf("%d 个规则动作冲突，采用最严格的动作 %s (规则: %s)",
+				len(decision.MatchedRules), decision.Action.String(), decidingRule.RuleName)
+		}
+	}
+
 	// 使用机器学习引擎进行风险预测
 	if pe.config.EnableMLEngine && pe.mlEngine != nil && pe.mlEngine.IsReady() {
 		mlRisk, err := pe.mlEngine.PredictRisk(context)
@@ -133,15 +187,23 @@ func (pe *PolicyEngineImpl) EvaluatePolicy(ctx context.Context, context *Decisio
 	}
 
 	// 最终决策逻辑
-	pe.finalizeDecision(decision)
+	preFinalAction := decision.Action
+	pe.finalizeDecision(decision, profile)
+
+	if pe.explainer != nil {
+		decision.Explanation = pe.explainer.explain(decision, explainedRules, decidingRule, preFinalAction, earlyStop)
+	}
 
 	// 更新统计信息
 	processingTime := time.Since(startTime)
 	decision.ProcessingTime = processingTime
 	pe.updateStats(decision)
 
+	// 低风险决策抽样，未被抽中的决策不记录审计日志
+	pe.sampler.apply(decision, &pe.stats)
+
 	// 记录审计日志
-	if pe.config.EnableAudit && pe.auditLogger != nil {
+	if pe.config.EnableAudit && pe.auditLogger != nil && !decision.SampledOut {
 		if err := pe.auditLogger.LogDecision(decision); err != nil {
 			pe.logger.Error("记录审计日志失败", "error", err)
 		}
@@ -152,6 +214,7 @@ func (pe *PolicyEngineImpl) EvaluatePolicy(ctx context.Context, context *Decisio
 		"action", decision.Action.String(),
 		"risk_score", decision.RiskScore,
 		"matched_rules", len(decision.MatchedRules),
+		"sampled_out", decision.SampledOut,
 		"processing_time", processingTime)
 
 	return decision, nil
@@ -275,6 +338,7 @@ func (pe *PolicyEngineImpl) GetStats() EngineStats {
 
 	stats := pe.stats
 	stats.Uptime = time.Since(pe.stats.StartTime)
+	stats.RegexStats = pe.regexCache.GetStats()
 	return stats
 }
 
@@ -299,6 +363,11 @@ func (pe *PolicyEngineImpl) Start() error {
 		return fmt.Errorf("加载默认规则失败: %w", err)
 	}
 
+	// 展开并加载模板规则
+	if err := pe.loadTemplateRules(); err != nil {
+		return fmt.Errorf("加载模板规则失败: %w", err)
+	}
+
 	pe.logger.Info("策略引擎已启动")
 	return nil
 }
@@ -356,7 +425,7 @@ func (pe *PolicyEngineImpl) getSortedRules() []*PolicyRule {
 }
 
 // finalizeDecision 最终决策
-func (pe *PolicyEngineImpl) finalizeDecision(decision *PolicyDecision) {
+func (pe *PolicyEngineImpl) finalizeDecision(decision *PolicyDecision, profile *PolicyProfile) {
 	// 如果没有匹配的规则，使用默认动作
 	if len(decision.MatchedRules) == 0 {
 		decision.Action = pe.config.DefaultAction
@@ -364,17 +433,16 @@ func (pe *PolicyEngineImpl) finalizeDecision(decision *PolicyDecision) {
 		return
 	}
 
-	// 根据风险级别调整动作
-	switch decision.RiskLevel {
-	case analyzer.RiskLevelCritical:
-		if decision.Action == PolicyActionAllow {
+	// 根据风险级别调整动作，阈值由应用策略配置决定
+	if decision.Action == PolicyActionAllow {
+		alertLevel, alertOK, blockLevel, blockOK := profile.escalationLevels()
+		switch {
+		case blockOK && decision.RiskLevel >= blockLevel:
 			decision.Action = PolicyActionBlock
-			decision.Reason = "关键风险级别，强制阻断"
-		}
-	case analyzer.RiskLevelHigh:
-		if decision.Action == PolicyActionAllow {
+			decision.Reason = fmt.Sprintf("风险级别 %s 达到阻断阈值，强制阻断", decision.RiskLevel.String())
+		case alertOK && decision.RiskLevel >= alertLevel:
 			decision.Action = PolicyActionAlert
-			decision.Reason = "高风险级别，发出告警"
+			decision.Reason = fmt.Sprintf("风险级别 %s 达到告警阈值，发出告警", decision.RiskLevel.String())
 		}
 	}
 
@@ -384,6 +452,16 @@ func (pe *PolicyEngineImpl) finalizeDecision(decision *PolicyDecision) {
 	}
 }
 
+// hasConflictingActions 检查匹配的规则是否给出了不同的动作
+func hasConflictingActions(matched []*MatchedRule) bool {
+	for _, rule := range matched[1:] {
+		if rule.Action != matched[0].Action {
+			return true
+		}
+	}
+	return false
+}
+
 // updateStats 更新统计信息
 func (pe *PolicyEngineImpl) updateStats(decision *PolicyDecision) {
 	switch decision.Action {
@@ -403,6 +481,34 @@ func (pe *PolicyEngineImpl) updateStats(decision *PolicyDecision) {
 
 // validateRule 验证规则
 func (pe *PolicyEngineImpl) validateRule(rule *PolicyRule) error {
+	return validatePolicyRule(rule, pe.regexCache)
+}
+
+// ValidateRules 按引擎配置验证一组规则而不加载，用于导入配置前的检查
+func ValidateRules(config PolicyEngineConfig, rules []*PolicyRule) error {
+	if config.MaxRules > 0 && len(rules) > config.MaxRules {
+		return fmt.Errorf("规则数量超过限制: %d > %d", len(rules), config.MaxRules)
+	}
+
+	regexCache := NewRegexCache(config.Regex)
+	seen := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		if rule == nil {
+			return fmt.Errorf("规则不能为空")
+		}
+		if err := validatePolicyRule(rule, regexCache); err != nil {
+			return fmt.Errorf("规则验证失败 [%s]: %w", rule.ID, err)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("规则ID重复: %s", rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+	return nil
+}
+
+// validatePolicyRule 验证规则字段并预编译正则条件
+func validatePolicyRule(rule *PolicyRule, regexCache *RegexCache) error {
 	if rule.ID == "" {
 		return fmt.Errorf("规则ID不能为空")
 	}
@@ -423,6 +529,16 @@ func (pe *PolicyEngineImpl) validateRule(rule *PolicyRule) error {
 		return fmt.Errorf("规则必须包含至少一个动作")
 	}
 
+	// 加载时预编译正则条件，拒绝无效或过于复杂的模式
+	for _, condition := range rule.Conditions {
+		if condition.Operator != "regex" && condition.Operator != "not_regex" {
+			continue
+		}
+		if _, err := regexCache.Compile(fmt.Sprintf("%v", condition.Value)); err != nil {
+			return fmt.Errorf("条件 %s 的正则表达式无效: %w", condition.Field, err)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+)
+
+// RiskLevelNone 风险升级阈值取该值时不升级决策
+const RiskLevelNone = "none"
+
+// 未配置风险升级阈值时使用的默认值
+const (
+	DefaultAlertRiskLevel = analyzer.RiskLevelHigh
+	DefaultBlockRiskLevel = analyzer.RiskLevelCritical
+)
+
+// PolicyProfile 策略配置，按发送数据的应用选择参与评估的规则和风险升级阈值
+// 仅内置后端使用，OPA 后端忽略该配置
+type PolicyProfile struct {
+	Name  string   `yaml:"name" json:"name"`
+	Rules []string `yaml:"rules" json:"rules,omitempty"` // 参与评估的规则ID，为空时评估全部规则
+
+	// 匹配规则后动作仍为放行时，风险达到阈值的决策升级为告警或阻断
+	// 为空时使用默认阈值（high 告警、critical 阻断），none 表示不升级
+	AlertRiskLevel string `yaml:"alert_risk_level" json:"alert_risk_level,omitempty"`
+	BlockRiskLevel string `yaml:"block_risk_level" json:"block_risk_level,omitempty"`
+}
+
+// Validate 校验风险升级阈值
+func (p *PolicyProfile) Validate() error {
+	for _, level := range []string{p.AlertRiskLevel, p.BlockRiskLevel} {
+		if _, _, err := parseEscalationLevel(level, analyzer.RiskLevelLow); err != nil {
+			return fmt.Errorf("策略配置 %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// allowsRule 检查规则是否参与评估，未设置配置时评估全部规则
+func (p *PolicyProfile) allowsRule(ruleID string) bool {
+	if p == nil || len(p.Rules) == 0 {
+		return true
+	}
+	for _, id := range p.Rules {
+		if id == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
+// escalationLevels 返回放行决策升级为告警和阻断的风险阈值，不升级时对应的 ok 为 false
+func (p *PolicyProfile) escalationLevels() (alert analyzer.RiskLevel, alertOK bool, block analyzer.RiskLevel, blockOK bool) {
+	var alertLevel, blockLevel string
+	if p != nil {
+		alertLevel, blockLevel = p.AlertRiskLevel, p.BlockRiskLevel
+	}
+	alert, alertOK, _ = parseEscalationLevel(alertLevel, DefaultAlertRiskLevel)
+	block, blockOK, _ = parseEscalationLevel(blockLevel, DefaultBlockRiskLevel)
+	return alert, alertOK, block, blockOK
+}
+
+// parseEscalationLevel 解析风险升级阈值，为空时使用默认值
+func parseEscalationLevel(value string, fallback analyzer.RiskLevel) (analyzer.RiskLevel, bool, error) {
+	switch value {
+	case "":
+		return fallback, true, nil
+	case RiskLevelNone:
+		return fallback, false, nil
+	}
+	level, ok := analyzer.ParseRiskLevel(value)
+	if !ok {
+		return fallback, false, fmt.Errorf("无效的风险级别: %s", value)
+	}
+	return level, true, nil
+}
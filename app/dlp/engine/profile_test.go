@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyProfile_SelectsRulesAndThresholds(t *testing.T) {
+	policyEngine := newFindingsTestEngine(t,
+		cardToExternalRule(),
+		findingRule("allow_cards", 50, PolicyActionAllow,
+			&RuleCondition{Field: "findings.types", Operator: "contains", Value: "credit_card"}),
+	)
+
+	tests := []struct {
+		name    string
+		profile *PolicyProfile
+		action  PolicyAction
+		rules   []string
+	}{
+		{name: "未设置配置时评估全部规则", action: PolicyActionBlock, rules: []string{"block_card_external", "allow_cards"}},
+		{name: "空规则列表评估全部规则", profile: &PolicyProfile{Name: "browser"}, action: PolicyActionBlock, rules: []string{"block_card_external", "allow_cards"}},
+		{
+			// 放行规则匹配后，高风险按默认阈值升级为告警
+			name:    "只评估放行规则",
+			profile: &PolicyProfile{Name: "backup", Rules: []string{"allow_cards"}},
+			action:  PolicyActionAlert,
+			rules:   []string{"allow_cards"},
+		},
+		{
+			name:    "降低阻断阈值",
+			profile: &PolicyProfile{Name: "strict", Rules: []string{"allow_cards"}, BlockRiskLevel: "high"},
+			action:  PolicyActionBlock,
+			rules:   []string{"allow_cards"},
+		},
+		{
+			name:    "不升级决策",
+			profile: &PolicyProfile{Name: "trusted", Rules: []string{"allow_cards"}, AlertRiskLevel: RiskLevelNone},
+			action:  PolicyActionAllow,
+			rules:   []string{"allow_cards"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newFindingsTestContext("203.0.113.5")
+			ctx.Profile = tt.profile
+
+			decision, err := policyEngine.EvaluatePolicy(context.Background(), ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.action, decision.Action)
+
+			matched := make([]string, 0, len(decision.MatchedRules))
+			for _, rule := range decision.MatchedRules {
+				matched = append(matched, rule.RuleID)
+			}
+			assert.Equal(t, tt.rules, matched)
+
+			if tt.profile != nil {
+				assert.Equal(t, tt.profile.Name, decision.Metadata["profile"])
+			} else {
+				assert.NotContains(t, decision.Metadata, "profile")
+			}
+		})
+	}
+}
+
+func TestPolicyProfile_Validate(t *testing.T) {
+	assert.NoError(t, (&PolicyProfile{Name: "default"}).Validate())
+	assert.NoError(t, (&PolicyProfile{AlertRiskLevel: "medium", BlockRiskLevel: RiskLevelNone}).Validate())
+	assert.Error(t, (&PolicyProfile{AlertRiskLevel: "severe"}).Validate())
+
+	alert, alertOK, block, blockOK := (*PolicyProfile)(nil).escalationLevels()
+	assert.True(t, alertOK)
+	assert.True(t, blockOK)
+	assert.Equal(t, analyzer.RiskLevelHigh, alert)
+	assert.Equal(t, analyzer.RiskLevelCritical, block)
+}
@@ -0,0 +1,186 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRegexTimeout 正则匹配超过时间预算
+var ErrRegexTimeout = errors.New("正则表达式匹配超时")
+
+// regexDirectMatchSize 小于该长度的输入直接在当前协程匹配，避免额外的协程开销
+const regexDirectMatchSize = 4096
+
+// RegexConfig 正则表达式安全限制配置
+type RegexConfig struct {
+	MaxPatternLength int           `yaml:"max_pattern_length" json:"max_pattern_length"`
+	MaxComplexity    int           `yaml:"max_complexity" json:"max_complexity"`
+	MaxInputSize     int           `yaml:"max_input_size" json:"max_input_size"`
+	MatchTimeout     time.Duration `yaml:"match_timeout" json:"match_timeout"`
+}
+
+// DefaultRegexConfig 返回默认正则表达式安全限制配置
+func DefaultRegexConfig() RegexConfig {
+	return RegexConfig{
+		MaxPatternLength: 1024,
+		MaxComplexity:    2000,
+		MaxInputSize:     1024 * 1024, // 1MB
+		MatchTimeout:     100 * time.Millisecond,
+	}
+}
+
+// RegexCacheStats 正则缓存统计信息
+type RegexCacheStats struct {
+	Patterns        int    `json:"patterns"`
+	Compilations    uint64 `json:"compilations"`
+	CacheHits       uint64 `json:"cache_hits"`
+	Rejected        uint64 `json:"rejected"`
+	Timeouts        uint64 `json:"timeouts"`
+	TruncatedInputs uint64 `json:"truncated_inputs"`
+}
+
+// RegexCache 正则表达式编译缓存
+// Go的regexp基于RE2，不会发生灾难性回溯，但匹配耗时仍与输入大小和模式复杂度成正比，
+// 因此在编译时限制模式长度和复杂度，在匹配时限制输入大小和时间预算
+type RegexCache struct {
+	config   RegexConfig
+	patterns map[string]*regexp.Regexp
+	mu       sync.RWMutex
+
+	compilations    uint64
+	cacheHits       uint64
+	rejected        uint64
+	timeouts        uint64
+	truncatedInputs uint64
+}
+
+// NewRegexCache 创建正则表达式编译缓存
+func NewRegexCache(config RegexConfig) *RegexCache {
+	defaults := DefaultRegexConfig()
+	if config.MaxPatternLength <= 0 {
+		config.MaxPatternLength = defaults.MaxPatternLength
+	}
+	if config.MaxComplexity <= 0 {
+		config.MaxComplexity = defaults.MaxComplexity
+	}
+	if config.MaxInputSize <= 0 {
+		config.MaxInputSize = defaults.MaxInputSize
+	}
+
+	return &RegexCache{
+		config:   config,
+		patterns: make(map[string]*regexp.Regexp),
+	}
+}
+
+// Compile 编译正则表达式，已编译的模式直接从缓存返回
+func (rc *RegexCache) Compile(pattern string) (*regexp.Regexp, error) {
+	rc.mu.RLock()
+	re, exists := rc.patterns[pattern]
+	rc.mu.RUnlock()
+	if exists {
+		atomic.AddUint64(&rc.cacheHits, 1)
+		return re, nil
+	}
+
+	if err := rc.validatePattern(pattern); err != nil {
+		atomic.AddUint64(&rc.rejected, 1)
+		return nil, err
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		atomic.AddUint64(&rc.rejected, 1)
+		return nil, fmt.Errorf("正则表达式编译失败: %w", err)
+	}
+	atomic.AddUint64(&rc.compilations, 1)
+
+	rc.mu.Lock()
+	if cached, exists := rc.patterns[pattern]; exists {
+		re = cached
+	} else {
+		rc.patterns[pattern] = re
+	}
+	rc.mu.Unlock()
+
+	return re, nil
+}
+
+// MatchString 在时间预算内匹配输入，超出MaxInputSize的部分不参与匹配
+func (rc *RegexCache) MatchString(pattern, input string) (bool, error) {
+	re, err := rc.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	if len(input) > rc.config.MaxInputSize {
+		input = input[:rc.config.MaxInputSize]
+		atomic.AddUint64(&rc.truncatedInputs, 1)
+	}
+
+	if rc.config.MatchTimeout <= 0 || len(input) <= regexDirectMatchSize {
+		return re.MatchString(input), nil
+	}
+
+	// RE2匹配无法中途取消，超时后匹配协程会在有限时间内（受输入大小约束）自行结束
+	result := make(chan bool, 1)
+	go func() {
+		result <- re.MatchString(input)
+	}()
+
+	timer := time.NewTimer(rc.config.MatchTimeout)
+	defer timer.Stop()
+
+	select {
+	case matched := <-result:
+		return matched, nil
+	case <-timer.C:
+		atomic.AddUint64(&rc.timeouts, 1)
+		return false, fmt.Errorf("%w: 预算 %v", ErrRegexTimeout, rc.config.MatchTimeout)
+	}
+}
+
+// GetStats 获取统计信息
+func (rc *RegexCache) GetStats() RegexCacheStats {
+	rc.mu.RLock()
+	patterns := len(rc.patterns)
+	rc.mu.RUnlock()
+
+	return RegexCacheStats{
+		Patterns:        patterns,
+		Compilations:    atomic.LoadUint64(&rc.compilations),
+		CacheHits:       atomic.LoadUint64(&rc.cacheHits),
+		Rejected:        atomic.LoadUint64(&rc.rejected),
+		Timeouts:        atomic.LoadUint64(&rc.timeouts),
+		TruncatedInputs: atomic.LoadUint64(&rc.truncatedInputs),
+	}
+}
+
+// validatePattern 检查模式长度和复杂度
+// 复杂度以编译后的RE2程序指令数衡量，大量嵌套的重复（如 (a{1,100}){1,100}）会展开成巨大的程序
+func (rc *RegexCache) validatePattern(pattern string) error {
+	if len(pattern) > rc.config.MaxPatternLength {
+		return fmt.Errorf("正则表达式长度超过限制: %d > %d", len(pattern), rc.config.MaxPatternLength)
+	}
+
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return fmt.Errorf("正则表达式解析失败: %w", err)
+	}
+
+	prog, err := syntax.Compile(parsed.Simplify())
+	if err != nil {
+		return fmt.Errorf("正则表达式编译失败: %w", err)
+	}
+
+	if len(prog.Inst) > rc.config.MaxComplexity {
+		return fmt.Errorf("正则表达式过于复杂: %d > %d", len(prog.Inst), rc.config.MaxComplexity)
+	}
+
+	return nil
+}
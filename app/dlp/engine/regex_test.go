@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) logging.Logger {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+	return logger
+}
+
+func TestRegexCache_CachesCompiledPatterns(t *testing.T) {
+	cache := NewRegexCache(DefaultRegexConfig())
+
+	first, err := cache.Compile(`\d{17}[\dXx]`)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		matched, err := cache.MatchString(`\d{17}[\dXx]`, "身份证: 11010119900307777X")
+		require.NoError(t, err)
+		assert.True(t, matched)
+	}
+
+	second, err := cache.Compile(`\d{17}[\dXx]`)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	stats := cache.GetStats()
+	assert.Equal(t, uint64(1), stats.Compilations)
+	assert.Equal(t, uint64(11), stats.CacheHits)
+	assert.Equal(t, 1, stats.Patterns)
+}
+
+func TestRegexCache_RejectsUnsafePatterns(t *testing.T) {
+	config := DefaultRegexConfig()
+	config.MaxPatternLength = 64
+	config.MaxComplexity = 500
+	cache := NewRegexCache(config)
+
+	_, err := cache.Compile(strings.Repeat("a", 65))
+	assert.Error(t, err)
+
+	_, err = cache.Compile(`((a{1,50}){1,50})`)
+	assert.Error(t, err)
+
+	_, err = cache.Compile(`(unclosed`)
+	assert.Error(t, err)
+
+	assert.Equal(t, uint64(3), cache.GetStats().Rejected)
+	assert.Equal(t, 0, cache.GetStats().Patterns)
+}
+
+func TestRegexCache_MatchTimeBudget(t *testing.T) {
+	config := DefaultRegexConfig()
+	config.MaxInputSize = 16 * 1024 * 1024
+	config.MatchTimeout = 5 * time.Millisecond
+	cache := NewRegexCache(config)
+
+	// 大输入 + 无法使用字面量前缀优化的复杂模式
+	input := strings.Repeat("ab cd ", 2*1024*1024)
+	pattern := `(\w+\s?){3,}[0-9]{18}`
+
+	start := time.Now()
+	matched, err := cache.MatchString(pattern, input)
+	elapsed := time.Since(start)
+
+	assert.False(t, matched)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrRegexTimeout))
+	assert.Less(t, elapsed, 500*time.Millisecond)
+	assert.Equal(t, uint64(1), cache.GetStats().Timeouts)
+}
+
+func TestRegexCache_TruncatesLargeInput(t *testing.T) {
+	config := DefaultRegexConfig()
+	config.MaxInputSize = 1024
+	config.MatchTimeout = 0
+	cache := NewRegexCache(config)
+
+	input := strings.Repeat("x", 2048) + "secret"
+	matched, err := cache.MatchString(`secret`, input)
+	require.NoError(t, err)
+	assert.False(t, matched)
+	assert.Equal(t, uint64(1), cache.GetStats().TruncatedInputs)
+}
+
+func TestPolicyEngine_RejectsInvalidRegexRule(t *testing.T) {
+	engine := NewPolicyEngine(newTestLogger(t), DefaultPolicyEngineConfig())
+
+	rule := &PolicyRule{
+		ID:       "bad_regex",
+		Name:     "无效正则",
+		Priority: 50,
+		Enabled:  true,
+		Conditions: []*RuleCondition{
+			{Field: "parsed_data.url", Operator: "regex", Value: `([a-z]+`},
+		},
+		Actions: []*RuleAction{{Type: PolicyActionAlert}},
+	}
+
+	assert.Error(t, engine.AddRule(rule))
+
+	rule.Conditions[0].Value = `^https?://[a-z.]+/upload`
+	require.NoError(t, engine.AddRule(rule))
+	assert.Equal(t, uint64(1), engine.GetStats().RegexStats.Compilations)
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"sync/atomic"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+)
+
+// DecisionSamplingConfig 低风险决策抽样配置
+// 审计和放行等不拦截数据的低风险决策数量大、价值低，抽样后只完整处理其中一部分，
+// 未被抽中的决策不写入审计日志、不执行动作，只计入统计；medium 及以上风险的决策和拦截类动作始终完整处理
+type DecisionSamplingConfig struct {
+	// LowRate 每 LowRate 个低风险决策完整处理1个，0 或 1 表示不抽样
+	LowRate int `yaml:"low_rate" json:"low_rate"`
+}
+
+// decisionSampler 按到达顺序对低风险决策计数抽样，每 rate 个中的第一个被完整处理
+type decisionSampler struct {
+	rate    uint64
+	counter uint64
+}
+
+// newDecisionSampler 创建决策抽样器，未启用抽样时返回 nil
+func newDecisionSampler(config DecisionSamplingConfig) *decisionSampler {
+	if config.LowRate <= 1 {
+		return nil
+	}
+	return &decisionSampler{rate: uint64(config.LowRate)}
+}
+
+// sampleable 检查决策是否参与抽样：风险级别为 low 且动作为审计或放行
+func sampleable(decision *PolicyDecision) bool {
+	if decision.RiskLevel != analyzer.RiskLevelLow {
+		return false
+	}
+	return decision.Action == PolicyActionAudit || decision.Action == PolicyActionAllow
+}
+
+// apply 对参与抽样的决策计数，未被抽中的决策标记为 SampledOut
+func (s *decisionSampler) apply(decision *PolicyDecision, stats *EngineStats) {
+	if s == nil || !sampleable(decision) {
+		return
+	}
+
+	atomic.AddUint64(&stats.SampledDecisions, 1)
+	if n := atomic.AddUint64(&s.counter, 1); (n-1)%s.rate != 0 {
+		decision.SampledOut = true
+		atomic.AddUint64(&stats.SampledOutDecisions, 1)
+	}
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSamplingTestEngine(t *testing.T, lowRate int, rules ...*PolicyRule) PolicyEngine {
+	config := DefaultPolicyEngineConfig()
+	config.EnableAudit = false
+	config.Sampling.LowRate = lowRate
+	policyEngine := NewPolicyEngine(newTestLogger(t), config)
+	require.NoError(t, policyEngine.LoadRules(rules))
+	return policyEngine
+}
+
+// newSamplingTestContext 只包含一个指定风险级别发现的决策上下文
+func newSamplingTestContext(level analyzer.RiskLevel, sensitiveTypes ...string) *DecisionContext {
+	ctx := newFindingsTestContext("203.0.113.5")
+	ctx.AnalysisResult = newFinding(level, 0.1, sensitiveTypes...)
+	ctx.Findings = nil
+	return ctx
+}
+
+// evaluateSampled 评估 n 个决策，返回被完整处理的决策序号
+func evaluateSampled(t *testing.T, policyEngine PolicyEngine, n int, newContext func() *DecisionContext) []int {
+	kept := make([]int, 0)
+	for i := 0; i < n; i++ {
+		decision, err := policyEngine.EvaluatePolicy(context.Background(), newContext())
+		require.NoError(t, err)
+		if !decision.SampledOut {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
+
+func TestDecisionSampling_LowRiskSampledAtRate(t *testing.T) {
+	policyEngine := newSamplingTestEngine(t, 10)
+
+	// 无匹配规则时使用默认审计动作
+	kept := evaluateSampled(t, policyEngine, 100, func() *DecisionContext {
+		return newSamplingTestContext(analyzer.RiskLevelLow, "email")
+	})
+	assert.Equal(t, []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}, kept)
+
+	stats := policyEngine.GetStats()
+	assert.Equal(t, uint64(100), stats.TotalDecisions)
+	assert.Equal(t, uint64(100), stats.AuditDecisions)
+	assert.Equal(t, uint64(100), stats.SampledDecisions)
+	assert.Equal(t, uint64(90), stats.SampledOutDecisions)
+}
+
+func TestDecisionSampling_NeverDropsHighValueDecisions(t *testing.T) {
+	policyEngine := newSamplingTestEngine(t, 10, cardToExternalRule())
+
+	for _, level := range []analyzer.RiskLevel{analyzer.RiskLevelMedium, analyzer.RiskLevelHigh, analyzer.RiskLevelCritical} {
+		kept := evaluateSampled(t, policyEngine, 50, func() *DecisionContext {
+			return newSamplingTestContext(level, "email")
+		})
+		assert.Len(t, kept, 50, "%s 风险的决策不应被抽样丢弃", level)
+	}
+
+	// 低风险但需要阻断的决策同样完整处理
+	kept := evaluateSampled(t, policyEngine, 50, func() *DecisionContext {
+		return newSamplingTestContext(analyzer.RiskLevelLow, "credit_card")
+	})
+	assert.Len(t, kept, 50, "阻断决策不应被抽样丢弃")
+
+	stats := policyEngine.GetStats()
+	assert.Equal(t, uint64(50), stats.BlockedDecisions)
+	assert.Zero(t, stats.SampledDecisions)
+	assert.Zero(t, stats.SampledOutDecisions)
+}
+
+func TestDecisionSampling_Disabled(t *testing.T) {
+	for _, rate := range []int{0, 1} {
+		policyEngine := newSamplingTestEngine(t, rate)
+		kept := evaluateSampled(t, policyEngine, 20, func() *DecisionContext {
+			return newSamplingTestContext(analyzer.RiskLevelLow, "email")
+		})
+		assert.Len(t, kept, 20)
+		assert.Zero(t, policyEngine.GetStats().SampledDecisions)
+	}
+}
@@ -0,0 +1,263 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// templateVariablePattern 模板变量引用，形如 ${port}
+var templateVariablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// RuleTemplate 规则模板
+// Rule 中的字符串（ID、名称、条件字段和值、动作参数等）可以引用 ${变量}，加载时按实例参数展开为具体规则。
+// 字符串恰好为单个变量引用时保留参数的原始类型，例如 ${port} 展开为整数
+type RuleTemplate struct {
+	ID          string                 `yaml:"id" json:"id"`
+	Description string                 `yaml:"description" json:"description"`
+	Variables   []string               `yaml:"variables" json:"variables"`
+	Defaults    map[string]interface{} `yaml:"defaults" json:"defaults"`
+	Rule        PolicyRule             `yaml:"rule" json:"rule"`
+}
+
+// RuleTemplateInstance 规则模板实例
+type RuleTemplateInstance struct {
+	Template string                 `yaml:"template" json:"template"`
+	Params   map[string]interface{} `yaml:"params" json:"params"`
+}
+
+// Validate 检查模板引用的变量均已声明
+func (t *RuleTemplate) Validate() error {
+	if t.ID == "" {
+		return fmt.Errorf("规则模板ID不能为空")
+	}
+
+	declared := make(map[string]bool, len(t.Variables))
+	for _, name := range t.Variables {
+		declared[name] = true
+	}
+	for name := range t.Defaults {
+		if !declared[name] {
+			return fmt.Errorf("规则模板 %s 的默认值引用了未声明的变量: %s", t.ID, name)
+		}
+	}
+
+	var undeclared []string
+	for _, name := range t.references() {
+		if !declared[name] {
+			undeclared = append(undeclared, name)
+		}
+	}
+	if len(undeclared) > 0 {
+		return fmt.Errorf("规则模板 %s 引用了未声明的变量: %s", t.ID, strings.Join(undeclared, ", "))
+	}
+	return nil
+}
+
+// Instantiate 使用参数展开模板，缺少参数或提供了未声明的参数时返回错误
+func (t *RuleTemplate) Instantiate(params map[string]interface{}) (*PolicyRule, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{}, len(t.Variables))
+	for name, value := range t.Defaults {
+		values[name] = value
+	}
+	declared := make(map[string]bool, len(t.Variables))
+	for _, name := range t.Variables {
+		declared[name] = true
+	}
+
+	var unknown []string
+	for name, value := range params {
+		if !declared[name] {
+			unknown = append(unknown, name)
+			continue
+		}
+		values[name] = value
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("规则模板 %s 没有变量: %s", t.ID, strings.Join(unknown, ", "))
+	}
+
+	var missing []string
+	for _, name := range t.Variables {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("规则模板 %s 缺少变量: %s", t.ID, strings.Join(missing, ", "))
+	}
+
+	rule := &PolicyRule{
+		ID:          expandTemplateString(t.Rule.ID, values),
+		Name:        expandTemplateString(t.Rule.Name, values),
+		Description: expandTemplateString(t.Rule.Description, values),
+		Type:        expandTemplateString(t.Rule.Type, values),
+		Priority:    t.Rule.Priority,
+		Enabled:     t.Rule.Enabled,
+		Version:     t.Rule.Version,
+		Metadata:    map[string]interface{}{"template": t.ID},
+	}
+	for key, value := range t.Rule.Metadata {
+		rule.Metadata[key] = expandTemplateValue(value, values)
+	}
+	for _, condition := range t.Rule.Conditions {
+		rule.Conditions = append(rule.Conditions, &RuleCondition{
+			Field:    expandTemplateString(condition.Field, values),
+			Operator: expandTemplateString(condition.Operator, values),
+			Value:    expandTemplateValue(condition.Value, values),
+			Type:     expandTemplateString(condition.Type, values),
+		})
+	}
+	for _, action := range t.Rule.Actions {
+		expanded := &RuleAction{Type: action.Type}
+		if action.Parameters != nil {
+			expanded.Parameters = expandTemplateValue(action.Parameters, values).(map[string]interface{})
+		}
+		rule.Actions = append(rule.Actions, expanded)
+	}
+
+	return rule, nil
+}
+
+// references 返回模板规则中引用的全部变量，按名称排序
+func (t *RuleTemplate) references() []string {
+	seen := make(map[string]bool)
+	collect := func(s string) {
+		for _, match := range templateVariablePattern.FindAllStringSubmatch(s, -1) {
+			seen[match[1]] = true
+		}
+	}
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case string:
+			collect(v)
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+
+	collect(t.Rule.ID)
+	collect(t.Rule.Name)
+	collect(t.Rule.Description)
+	collect(t.Rule.Type)
+	walk(t.Rule.Metadata)
+	for _, condition := range t.Rule.Conditions {
+		collect(condition.Field)
+		collect(condition.Operator)
+		collect(condition.Type)
+		walk(condition.Value)
+	}
+	for _, action := range t.Rule.Actions {
+		walk(action.Parameters)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// expandTemplateString 替换字符串中的变量引用
+func expandTemplateString(s string, values map[string]interface{}) string {
+	return templateVariablePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		return fmt.Sprint(values[ref[2:len(ref)-1]])
+	})
+}
+
+// expandTemplateValue 递归替换值中的变量引用，单个变量引用保留参数的原始类型
+func expandTemplateValue(value interface{}, values map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if match := templateVariablePattern.FindStringSubmatch(v); match != nil && match[0] == v {
+			return values[match[1]]
+		}
+		return expandTemplateString(v, values)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = expandTemplateValue(item, values)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = expandTemplateValue(item, values)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// ExpandRuleTemplates 将模板实例展开为具体规则，展开后的规则ID不能重复
+func ExpandRuleTemplates(templates []*RuleTemplate, instances []*RuleTemplateInstance) ([]*PolicyRule, error) {
+	byID := make(map[string]*RuleTemplate, len(templates))
+	for _, template := range templates {
+		if err := template.Validate(); err != nil {
+			return nil, err
+		}
+		if _, exists := byID[template.ID]; exists {
+			return nil, fmt.Errorf("规则模板ID重复: %s", template.ID)
+		}
+		byID[template.ID] = template
+	}
+
+	rules := make([]*PolicyRule, 0, len(instances))
+	ids := make(map[string]bool, len(instances))
+	for i, instance := range instances {
+		template, ok := byID[instance.Template]
+		if !ok {
+			return nil, fmt.Errorf("第 %d 个模板规则引用了不存在的模板: %s", i+1, instance.Template)
+		}
+		rule, err := template.Instantiate(instance.Params)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 个模板规则展开失败: %w", i+1, err)
+		}
+		if ids[rule.ID] {
+			return nil, fmt.Errorf("模板 %s 展开的规则ID重复: %s", template.ID, rule.ID)
+		}
+		ids[rule.ID] = true
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// loadTemplateRules 展开配置中的模板规则并加入引擎
+func (pe *PolicyEngineImpl) loadTemplateRules() error {
+	if len(pe.config.TemplateRules) == 0 {
+		return nil
+	}
+
+	rules, err := ExpandRuleTemplates(pe.config.RuleTemplates, pe.config.TemplateRules)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, rule := range rules {
+		rule.CreatedAt = now
+		rule.UpdatedAt = now
+		if err := pe.AddRule(rule); err != nil {
+			return fmt.Errorf("添加模板规则 %s 失败: %w", rule.ID, err)
+		}
+	}
+
+	pe.logger.Info("加载模板规则", "templates", len(pe.config.RuleTemplates), "rules", len(rules))
+	return nil
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockProtocolPortTemplate 阻断指定协议访问指定端口的规则模板
+func blockProtocolPortTemplate() *RuleTemplate {
+	return &RuleTemplate{
+		ID:        "block_protocol_port",
+		Variables: []string{"protocol", "port", "priority_tag"},
+		Defaults:  map[string]interface{}{"priority_tag": "standard"},
+		Rule: PolicyRule{
+			ID:       "block_${protocol}_${port}",
+			Name:     "阻断${protocol}访问端口${port}",
+			Type:     "security",
+			Priority: 80,
+			Enabled:  true,
+			Version:  "1.0",
+			Metadata: map[string]interface{}{"tag": "${priority_tag}"},
+			Conditions: []*RuleCondition{
+				{Field: "parsed_data.protocol", Operator: "equals", Value: "${protocol}", Type: "string"},
+				{Field: "packet_info.dest_port", Operator: "equals", Value: "${port}", Type: "number"},
+			},
+			Actions: []*RuleAction{
+				{Type: PolicyActionBlock, Parameters: map[string]interface{}{"reason": "禁止${protocol}访问端口${port}"}},
+			},
+		},
+	}
+}
+
+// handWrittenBlockRule 手写的等价规则
+func handWrittenBlockRule(protocol string, port int, tag string) *PolicyRule {
+	return &PolicyRule{
+		ID:       "block_" + protocol + "_" + strconv.Itoa(port),
+		Name:     "阻断" + protocol + "访问端口" + strconv.Itoa(port),
+		Type:     "security",
+		Priority: 80,
+		Enabled:  true,
+		Version:  "1.0",
+		Metadata: map[string]interface{}{"template": "block_protocol_port", "tag": tag},
+		Conditions: []*RuleCondition{
+			{Field: "parsed_data.protocol", Operator: "equals", Value: protocol, Type: "string"},
+			{Field: "packet_info.dest_port", Operator: "equals", Value: port, Type: "number"},
+		},
+		Actions: []*RuleAction{
+			{Type: PolicyActionBlock, Parameters: map[string]interface{}{"reason": "禁止" + protocol + "访问端口" + strconv.Itoa(port)}},
+		},
+	}
+}
+
+func TestExpandRuleTemplates_MatchesHandWrittenRules(t *testing.T) {
+	rules, err := ExpandRuleTemplates([]*RuleTemplate{blockProtocolPortTemplate()}, []*RuleTemplateInstance{
+		{Template: "block_protocol_port", Params: map[string]interface{}{"protocol": "ftp", "port": 21}},
+		{Template: "block_protocol_port", Params: map[string]interface{}{"protocol": "smtp", "port": 25, "priority_tag": "mail"}},
+		{Template: "block_protocol_port", Params: map[string]interface{}{"protocol": "http", "port": 8080}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*PolicyRule{
+		handWrittenBlockRule("ftp", 21, "standard"),
+		handWrittenBlockRule("smtp", 25, "mail"),
+		handWrittenBlockRule("http", 8080, "standard"),
+	}, rules)
+}
+
+func TestExpandRuleTemplates_ValidatesVariables(t *testing.T) {
+	templates := []*RuleTemplate{blockProtocolPortTemplate()}
+
+	_, err := ExpandRuleTemplates(templates, []*RuleTemplateInstance{
+		{Template: "block_protocol_port", Params: map[string]interface{}{"protocol": "ftp"}},
+	})
+	assert.ErrorContains(t, err, "缺少变量: port")
+
+	_, err = ExpandRuleTemplates(templates, []*RuleTemplateInstance{
+		{Template: "block_protocol_port", Params: map[string]interface{}{"protocol": "ftp", "port": 21, "prot": "x"}},
+	})
+	assert.ErrorContains(t, err, "没有变量: prot")
+
+	_, err = ExpandRuleTemplates(templates, []*RuleTemplateInstance{
+		{Template: "missing", Params: map[string]interface{}{}},
+	})
+	assert.ErrorContains(t, err, "不存在的模板: missing")
+
+	// 同一参数展开两次得到重复的规则ID
+	params := map[string]interface{}{"protocol": "ftp", "port": 21}
+	_, err = ExpandRuleTemplates(templates, []*RuleTemplateInstance{
+		{Template: "block_protocol_port", Params: params},
+		{Template: "block_protocol_port", Params: params},
+	})
+	assert.ErrorContains(t, err, "规则ID重复: block_ftp_21")
+
+	// 模板引用未声明的变量
+	broken := blockProtocolPortTemplate()
+	broken.Variables = []string{"protocol", "priority_tag"}
+	assert.ErrorContains(t, broken.Validate(), "未声明的变量: port")
+}
+
+func TestPolicyEngine_LoadsTemplateRules(t *testing.T) {
+	config := DefaultPolicyEngineConfig()
+	config.EnableAudit = false
+	config.RuleTemplates = []*RuleTemplate{blockProtocolPortTemplate()}
+	config.TemplateRules = []*RuleTemplateInstance{
+		{Template: "block_protocol_port", Params: map[string]interface{}{"protocol": "ftp", "port": 21}},
+	}
+
+	policyEngine := NewPolicyEngine(newTestLogger(t), config)
+	require.NoError(t, policyEngine.Start())
+	t.Cleanup(func() { policyEngine.Stop() })
+
+	rule, ok := policyEngine.GetRule("block_ftp_21")
+	require.True(t, ok)
+	assert.False(t, rule.CreatedAt.IsZero())
+
+	decision, err := policyEngine.EvaluatePolicy(context.Background(), &DecisionContext{
+		PacketInfo: &interceptor.PacketInfo{Protocol: interceptor.ProtocolTCP, DestPort: 21},
+		ParsedData: &parser.ParsedData{Protocol: "ftp"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, PolicyActionBlock, decision.Action)
+
+	// 模板变量缺失时引擎启动失败
+	config.TemplateRules = []*RuleTemplateInstance{{Template: "block_protocol_port", Params: map[string]interface{}{}}}
+	assert.Error(t, NewPolicyEngine(newTestLogger(t), config).Start())
+}
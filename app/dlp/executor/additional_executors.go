@@ -67,6 +67,11 @@ func (re *RedirectExecutorImpl) ExecuteAction(ctx context.Context, decision *eng
 		re.logger.Info("重定向规则创建成功", "rule_id", redirectRule.ID)
 	}
 
+	setRemediation(result, re.config.RemediationTemplates, engine.PolicyActionRedirect.String(), decision, map[string]string{
+		"original_dest": redirectRule.OriginalDest,
+		"new_dest":      redirectRule.NewDest,
+		"redirect_rule": redirectRule.ID,
+	})
 	result.ProcessingTime = time.Since(startTime)
 	re.updateAverageTime(result.ProcessingTime)
 
@@ -164,6 +169,7 @@ func (ae *AllowExecutorImpl) ExecuteAction(ctx context.Context, decision *engine
 	atomic.AddUint64(&ae.stats.SuccessfulExecutions, 1)
 	ae.logger.Debug("允许操作执行", "decision_id", decision.ID)
 
+	setRemediation(result, ae.config.RemediationTemplates, engine.PolicyActionAllow.String(), decision, nil)
 	result.ProcessingTime = time.Since(startTime)
 	ae.updateAverageTime(result.ProcessingTime)
 
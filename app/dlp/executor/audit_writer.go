@@ -0,0 +1,225 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// DefaultAuditLogPath 默认审计日志文件路径
+const DefaultAuditLogPath = "app/dlp/logs/dlp_audit.log"
+
+// AuditWriterConfig 审计日志批量写入配置
+type AuditWriterConfig struct {
+	Path          string        `yaml:"path" json:"path"`                     // 审计日志文件路径
+	BatchSize     int           `yaml:"batch_size" json:"batch_size"`         // 缓冲的记录数达到该值时立即写入
+	FlushInterval time.Duration `yaml:"flush_interval" json:"flush_interval"` // 记录在缓冲区中的最长停留时间
+	QueueSize     int           `yaml:"queue_size" json:"queue_size"`         // 等待写入的记录队列长度，队列满时写入方阻塞
+}
+
+// DefaultAuditWriterConfig 返回默认审计日志批量写入配置
+func DefaultAuditWriterConfig() AuditWriterConfig {
+	return AuditWriterConfig{
+		Path:          DefaultAuditLogPath,
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		QueueSize:     10000,
+	}
+}
+
+// withDefaults 用默认值补全未配置的字段
+func (c AuditWriterConfig) withDefaults() AuditWriterConfig {
+	defaults := DefaultAuditWriterConfig()
+	if c.Path == "" {
+		c.Path = defaults.Path
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaults.BatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = defaults.FlushInterval
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaults.QueueSize
+	}
+	return c
+}
+
+// AuditSink 审计日志的写入目标，每批记录写入后调用 Sync 落盘
+type AuditSink interface {
+	io.Writer
+	Sync() error
+	Close() error
+}
+
+// OpenAuditFile 以追加方式打开审计日志文件，目录不存在时创建
+func OpenAuditFile(path string) (AuditSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开审计日志文件失败: %w", err)
+	}
+	return file, nil
+}
+
+// AuditWriter 异步批量写入审计记录
+// 记录序列化为JSON行后进入队列，由后台协程攒批写入，记录数达到 BatchSize 或
+// 距上次写入超过 FlushInterval 时写入并落盘；Close 写完队列中的全部记录后关闭文件
+type AuditWriter struct {
+	config  AuditWriterConfig
+	sink    AuditSink
+	logger  logging.Logger
+	records chan []byte
+	flushes chan chan error
+	done    chan struct{}
+
+	mu       sync.RWMutex
+	closed   bool
+	closeErr error
+
+	buf     bytes.Buffer
+	pending int
+}
+
+// NewAuditWriter 创建审计日志写入器并启动后台写入协程
+func NewAuditWriter(config AuditWriterConfig, sink AuditSink, logger logging.Logger) *AuditWriter {
+	config = config.withDefaults()
+	w := &AuditWriter{
+		config:  config,
+		sink:    sink,
+		logger:  logger,
+		records: make(chan []byte, config.QueueSize),
+		flushes: make(chan chan error),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write 将审计记录序列化为一行JSON放入写入队列，队列满时阻塞
+func (w *AuditWriter) Write(record map[string]interface{}) error {
+	jsonData, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("序列化审计事件失败: %w", err)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return fmt.Errorf("审计日志写入器已关闭")
+	}
+	w.records <- append(jsonData, '\n')
+	return nil
+}
+
+// Flush 立即写入调用前已进入队列的全部记录并落盘
+func (w *AuditWriter) Flush() error {
+	reply := make(chan error, 1)
+
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return fmt.Errorf("审计日志写入器已关闭")
+	}
+	w.flushes <- reply
+	w.mu.RUnlock()
+
+	return <-reply
+}
+
+// Close 写入队列中剩余的记录后关闭写入目标，可重复调用
+func (w *AuditWriter) Close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.records)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+	return w.closeErr
+}
+
+// run 后台写入协程
+func (w *AuditWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case line, ok := <-w.records:
+			if !ok {
+				err := w.flush()
+				if closeErr := w.sink.Close(); err == nil && closeErr != nil {
+					err = fmt.Errorf("关闭审计日志文件失败: %w", closeErr)
+				}
+				w.closeErr = err
+				return
+			}
+			w.add(line)
+			if w.pending >= w.config.BatchSize {
+				w.flushAndLog()
+			}
+		case <-ticker.C:
+			w.flushAndLog()
+		case reply := <-w.flushes:
+			// 先取出 Flush 调用前已入队的记录
+			for drained := false; !drained; {
+				select {
+				case line, ok := <-w.records:
+					if !ok {
+						drained = true
+						break
+					}
+					w.add(line)
+				default:
+					drained = true
+				}
+			}
+			reply <- w.flush()
+		}
+	}
+}
+
+// add 将一行记录加入缓冲区
+func (w *AuditWriter) add(line []byte) {
+	w.buf.Write(line)
+	w.pending++
+}
+
+// flushAndLog 写入缓冲区并记录失败，失败的记录保留在缓冲区中等待下次写入
+func (w *AuditWriter) flushAndLog() {
+	if err := w.flush(); err != nil && w.logger != nil {
+		w.logger.Error("写入审计日志失败", "pending", w.pending, "error", err)
+	}
+}
+
+// flush 将缓冲区写入目标并落盘
+func (w *AuditWriter) flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	n, err := w.sink.Write(w.buf.Bytes())
+	if n > 0 {
+		w.pending -= bytes.Count(w.buf.Next(n), []byte{'\n'})
+	}
+	if err != nil {
+		return fmt.Errorf("写入审计日志失败: %w", err)
+	}
+	if err := w.sink.Sync(); err != nil {
+		return fmt.Errorf("刷新审计日志文件失败: %w", err)
+	}
+	return nil
+}
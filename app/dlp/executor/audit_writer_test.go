@@ -0,0 +1,219 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingSink 记录写入内容以及写入和落盘次数
+type countingSink struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	writes   int
+	syncs    int
+	closed   bool
+	failNext int // 接下来失败的写入次数
+}
+
+func (s *countingSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failNext > 0 {
+		s.failNext--
+		return 0, errors.New("磁盘已满")
+	}
+	s.writes++
+	return s.buf.Write(p)
+}
+
+func (s *countingSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.syncs++
+	return nil
+}
+
+func (s *countingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *countingSink) stats() (writes, syncs int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writes, s.syncs
+}
+
+func (s *countingSink) lines(t *testing.T) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return decodeAuditLines(t, s.buf.Bytes())
+}
+
+func decodeAuditLines(t *testing.T, data []byte) []map[string]interface{} {
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record), "每行应为一条JSON记录")
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func newTestAuditLogger(t *testing.T) logging.Logger {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+	return logger
+}
+
+func TestAuditWriter_BatchesRecords(t *testing.T) {
+	sink := &countingSink{}
+	w := NewAuditWriter(AuditWriterConfig{BatchSize: 100, FlushInterval: time.Hour}, sink, newTestAuditLogger(t))
+
+	const total = 1000
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < total/4; i++ {
+				assert.NoError(t, w.Write(map[string]interface{}{"id": fmt.Sprintf("%d-%d", g, i), "type": "policy_decision"}))
+			}
+		}(g)
+	}
+	wg.Wait()
+	require.NoError(t, w.Close())
+
+	records := sink.lines(t)
+	require.Len(t, records, total)
+	ids := make(map[string]bool, total)
+	for _, record := range records {
+		ids[record["id"].(string)] = true
+	}
+	assert.Len(t, ids, total, "每条记录应恰好写入一次")
+
+	writes, syncs := sink.stats()
+	assert.Equal(t, total/100, writes, "记录应按批写入")
+	assert.Equal(t, writes, syncs, "每批写入后应落盘")
+	assert.True(t, sink.closed)
+
+	assert.Error(t, w.Write(map[string]interface{}{"id": "late"}), "关闭后不应接受新记录")
+	assert.NoError(t, w.Close(), "重复关闭应无副作用")
+}
+
+func TestAuditWriter_FlushesOnInterval(t *testing.T) {
+	sink := &countingSink{}
+	w := NewAuditWriter(AuditWriterConfig{BatchSize: 100, FlushInterval: 20 * time.Millisecond}, sink, newTestAuditLogger(t))
+	defer w.Close()
+
+	require.NoError(t, w.Write(map[string]interface{}{"id": "a"}))
+	require.NoError(t, w.Write(map[string]interface{}{"id": "b"}))
+
+	require.Eventually(t, func() bool {
+		writes, _ := sink.stats()
+		return writes == 1
+	}, time.Second, 5*time.Millisecond, "未达到批量大小的记录应在间隔到达后写入")
+	assert.Len(t, sink.lines(t), 2)
+}
+
+func TestAuditWriter_Flush(t *testing.T) {
+	sink := &countingSink{}
+	w := NewAuditWriter(AuditWriterConfig{BatchSize: 100, FlushInterval: time.Hour}, sink, newTestAuditLogger(t))
+	defer w.Close()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, w.Write(map[string]interface{}{"id": i}))
+	}
+	require.NoError(t, w.Flush())
+	assert.Len(t, sink.lines(t), 10, "Flush 返回时之前写入的记录应已落盘")
+	writes, syncs := sink.stats()
+	assert.Equal(t, 1, writes)
+	assert.Equal(t, 1, syncs)
+}
+
+func TestAuditWriter_RetriesFailedBatch(t *testing.T) {
+	sink := &countingSink{failNext: 1}
+	w := NewAuditWriter(AuditWriterConfig{BatchSize: 5, FlushInterval: time.Hour}, sink, newTestAuditLogger(t))
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, w.Write(map[string]interface{}{"id": i}))
+	}
+	require.NoError(t, w.Close())
+
+	assert.Len(t, sink.lines(t), 5, "写入失败的记录应在下次写入时重试")
+}
+
+func TestAuditExecutor_PersistsAllEventsOnCleanup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "dlp_audit.log")
+	ae := NewAuditExecutor(newTestAuditLogger(t)).(*AuditExecutorImpl)
+	config := DefaultExecutorConfig()
+	config.AuditWriter = AuditWriterConfig{Path: path, BatchSize: 64, FlushInterval: time.Hour}
+	require.NoError(t, ae.Initialize(config))
+
+	const total = 500
+	for i := 0; i < total; i++ {
+		require.NoError(t, ae.RecordAuditEvent(&AuditEvent{
+			ID:        fmt.Sprintf("evt_%d", i),
+			Timestamp: time.Now(),
+			EventType: "quarantine_release",
+			Action:    "release",
+			Result:    "success",
+		}))
+	}
+	require.NoError(t, ae.Cleanup())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records := decodeAuditLines(t, data)
+	require.Len(t, records, total, "停止后所有审计记录都应已写入文件")
+	assert.Equal(t, "evt_0", records[0]["id"])
+	assert.Equal(t, "quarantine_release", records[0]["type"])
+	assert.Equal(t, fmt.Sprintf("evt_%d", total-1), records[total-1]["id"])
+}
+
+func TestAuditExecutor_ConcurrentRecordAuditEvent(t *testing.T) {
+	ae := NewAuditExecutor(newTestAuditLogger(t)).(*AuditExecutorImpl)
+	config := DefaultExecutorConfig()
+	config.AuditWriter = AuditWriterConfig{Path: filepath.Join(t.TempDir(), "dlp_audit.log")}
+	require.NoError(t, ae.Initialize(config))
+	defer ae.Cleanup()
+
+	const workers, perWorker = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				assert.NoError(t, ae.RecordAuditEvent(&AuditEvent{
+					ID:        fmt.Sprintf("evt_%d_%d", w, i),
+					Timestamp: time.Now(),
+					EventType: "quarantine_release",
+					Action:    "release",
+					Result:    "success",
+				}))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	ae.eventsMu.Lock()
+	defer ae.eventsMu.Unlock()
+	assert.Len(t, ae.events, workers*perWorker)
+}
@@ -2,8 +2,7 @@ package executor
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/json"
@@ -12,7 +11,6 @@ import (
 	"net/http"
 	"net/smtp"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -20,10 +18,15 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/lomehong/kennel/app/dlp/analyzer"
 	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
 	"github.com/lomehong/kennel/pkg/logging"
 )
 
+// MetadataAlreadyApplied 执行结果元数据键，表示等效动作已生效，本次未重复执行
+const MetadataAlreadyApplied = "already_applied"
+
 // BlockExecutorImpl 阻断执行器实现
 type BlockExecutorImpl struct {
 	logger             logging.Logger
@@ -34,21 +37,30 @@ type BlockExecutorImpl struct {
 	// 网络阻断相关
 	firewallRules []FirewallRule
 	blockedIPs    map[string]time.Time
+	applyRule     func(rule *FirewallRule) error // 下发防火墙规则，默认调用系统防火墙
+	runner        CommandRunner                  // 执行防火墙命令
+	goos          string                         // 决定使用的防火墙工具
+	capability    EnforcementCapability          // 初始化时检测的阻断执行能力
 	mu            sync.RWMutex
 }
 
 // NewBlockExecutor 创建阻断执行器
 func NewBlockExecutor(logger logging.Logger) ActionExecutor {
-	return &BlockExecutorImpl{
+	be := &BlockExecutorImpl{
 		logger:             logger,
 		blockedConnections: make([]BlockedConnection, 0),
 		firewallRules:      make([]FirewallRule, 0),
 		blockedIPs:         make(map[string]time.Time),
+		runner:             execCommandRunner{},
+		goos:               runtime.GOOS,
+		capability:         EnforcementCapability{Mode: EnforcementModeBlock},
 		stats: ExecutorStats{
 			ActionStats: make(map[string]uint64),
 			StartTime:   time.Now(),
 		},
 	}
+	be.applyRule = be.blockConnection
+	return be
 }
 
 // ExecuteAction 执行动作
@@ -65,15 +77,28 @@ func (be *BlockExecutorImpl) ExecuteAction(ctx context.Context, decision *engine
 	}
 
 	// 执行阻断逻辑
+	remediationVars := make(map[string]string)
 	if decision.Context != nil && decision.Context.PacketInfo != nil {
 		packet := decision.Context.PacketInfo
+		rule := newBlockRule(result.ID, packet, decision.Reason)
+		remediationVars["firewall_rule"] = rule.Name
 
-		// 执行真实的网络阻断
-		if err := be.blockConnection(packet); err != nil {
+		// 执行真实的网络阻断，等效规则已生效时不重复下发
+		existing, err := be.ensureRule(&rule)
+		if err != nil {
 			result.Error = fmt.Errorf("阻断连接失败: %w", err)
-			atomic.AddUint64(&be.stats.FailedExecutions, 1)
-			be.stats.LastError = result.Error
+			be.recordFailure(result.Error)
 			be.logger.Error("阻断连接失败", "error", err)
+		} else if existing != nil {
+			result.Success = true
+			result.Metadata[MetadataAlreadyApplied] = true
+			result.Metadata["firewall_rule"] = *existing
+			result.AffectedData = *existing
+
+			atomic.AddUint64(&be.stats.SuccessfulExecutions, 1)
+			be.logger.Debug("阻断规则已生效，跳过",
+				"dest_ip", existing.DestIP,
+				"rule_id", existing.ID)
 		} else {
 			// 记录被阻断的连接
 			blockedConn := BlockedConnection{
@@ -92,7 +117,9 @@ func (be *BlockExecutorImpl) ExecuteAction(ctx context.Context, decision *engine
 			be.mu.Unlock()
 
 			result.Success = true
+			result.Metadata[MetadataAlreadyApplied] = false
 			result.Metadata["blocked_connection"] = blockedConn
+			result.Metadata["firewall_rule"] = rule
 			result.AffectedData = blockedConn
 
 			atomic.AddUint64(&be.stats.SuccessfulExecutions, 1)
@@ -103,10 +130,10 @@ func (be *BlockExecutorImpl) ExecuteAction(ctx context.Context, decision *engine
 		}
 	} else {
 		result.Error = fmt.Errorf("缺少数据包信息")
-		atomic.AddUint64(&be.stats.FailedExecutions, 1)
-		be.stats.LastError = result.Error
+		be.recordFailure(result.Error)
 	}
 
+	setRemediation(result, be.config.RemediationTemplates, engine.PolicyActionBlock.String(), decision, remediationVars)
 	result.ProcessingTime = time.Since(startTime)
 	be.updateAverageTime(result.ProcessingTime)
 
@@ -123,13 +150,33 @@ func (be *BlockExecutorImpl) CanExecute(actionType engine.PolicyAction) bool {
 	return actionType == engine.PolicyActionBlock
 }
 
-// Initialize 初始化执行器
+// Initialize 初始化执行器，检测一次防火墙工具是否可用
 func (be *BlockExecutorImpl) Initialize(config ExecutorConfig) error {
 	be.config = config
 	be.logger.Info("初始化阻断执行器")
+
+	capability := detectEnforcement(be.goos, be.runner)
+	be.mu.Lock()
+	be.capability = capability
+	be.mu.Unlock()
+
+	if capability.CanBlock() {
+		be.logger.Info("防火墙工具可用，阻断动作将下发防火墙规则", "tool", capability.Tool)
+	} else {
+		be.logger.Warn("防火墙工具不可用，阻断动作将降级为告警和审计",
+			"tool", capability.Tool,
+			"reason", capability.Reason)
+	}
 	return nil
 }
 
+// GetEnforcementCapability 获取阻断执行能力
+func (be *BlockExecutorImpl) GetEnforcementCapability() EnforcementCapability {
+	be.mu.RLock()
+	defer be.mu.RUnlock()
+	return be.capability
+}
+
 // Cleanup 清理资源
 func (be *BlockExecutorImpl) Cleanup() error {
 	be.logger.Info("清理阻断执行器资源")
@@ -138,16 +185,105 @@ func (be *BlockExecutorImpl) Cleanup() error {
 
 // GetStats 获取统计信息
 func (be *BlockExecutorImpl) GetStats() ExecutorStats {
-	stats := be.stats
-	stats.Uptime = time.Since(be.stats.StartTime)
-	return stats
+	be.mu.RLock()
+	defer be.mu.RUnlock()
+
+	// 计数器通过原子操作更新，不能随结构体一起复制
+	return ExecutorStats{
+		TotalExecutions:      atomic.LoadUint64(&be.stats.TotalExecutions),
+		SuccessfulExecutions: atomic.LoadUint64(&be.stats.SuccessfulExecutions),
+		FailedExecutions:     atomic.LoadUint64(&be.stats.FailedExecutions),
+		AverageTime:          be.stats.AverageTime,
+		ActionStats:          be.stats.ActionStats,
+		LastError:            be.stats.LastError,
+		StartTime:            be.stats.StartTime,
+		Uptime:               time.Since(be.stats.StartTime),
+	}
 }
 
-// updateAverageTime 更新平均处理时间
+// updateAverageTime 更新平均处理时间，并发执行的动作共用统计信息，需要持有锁
 func (be *BlockExecutorImpl) updateAverageTime(duration time.Duration) {
+	be.mu.Lock()
+	defer be.mu.Unlock()
 	be.stats.AverageTime = (be.stats.AverageTime + duration) / 2
 }
 
+// recordFailure 记录失败的执行
+func (be *BlockExecutorImpl) recordFailure(err error) {
+	atomic.AddUint64(&be.stats.FailedExecutions, 1)
+	be.mu.Lock()
+	be.stats.LastError = err
+	be.mu.Unlock()
+}
+
+// GetFirewallRules 获取已下发的防火墙规则
+func (be *BlockExecutorImpl) GetFirewallRules() []FirewallRule {
+	be.mu.RLock()
+	defer be.mu.RUnlock()
+
+	rules := make([]FirewallRule, len(be.firewallRules))
+	copy(rules, be.firewallRules)
+	return rules
+}
+
+// newBlockRule 根据数据包创建出站阻断规则
+func newBlockRule(id string, packet *interceptor.PacketInfo, reason string) FirewallRule {
+	destIP := ""
+	if packet.DestIP != nil {
+		destIP = packet.DestIP.String()
+	}
+	return FirewallRule{
+		ID:        id,
+		Name:      "DLP_Block_" + destIP,
+		Action:    "block",
+		Protocol:  "all",
+		DestIP:    destIP,
+		Direction: "outbound",
+		Enabled:   true,
+		CreatedAt: time.Now(),
+		Reason:    reason,
+		Metadata:  make(map[string]interface{}),
+	}
+}
+
+// ensureRule 下发防火墙规则，存在等效的有效规则时返回该规则且不重复下发
+// 检查和下发在同一把锁内完成，并发的重复决策只会下发一条规则
+func (be *BlockExecutorImpl) ensureRule(rule *FirewallRule) (*FirewallRule, error) {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	now := time.Now()
+	for i := range be.firewallRules {
+		existing := &be.firewallRules[i]
+		if existing.isActive(now) && existing.equivalent(rule) {
+			copied := *existing
+			return &copied, nil
+		}
+	}
+
+	if err := be.applyRule(rule); err != nil {
+		return nil, err
+	}
+	be.firewallRules = append(be.firewallRules, *rule)
+	return nil, nil
+}
+
+// isActive 规则是否启用且未过期
+func (r *FirewallRule) isActive(now time.Time) bool {
+	return r.Enabled && (r.ExpiresAt == nil || now.Before(*r.ExpiresAt))
+}
+
+// equivalent 两条规则是否产生相同的过滤效果
+func (r *FirewallRule) equivalent(other *FirewallRule) bool {
+	return r.Action == other.Action &&
+		r.Direction == other.Direction &&
+		r.Protocol == other.Protocol &&
+		r.SourceIP == other.SourceIP &&
+		r.DestIP == other.DestIP &&
+		r.SourcePort == other.SourcePort &&
+		r.DestPort == other.DestPort
+}
+
 // AlertExecutorImpl 告警执行器实现
 type AlertExecutorImpl struct {
 	logger   logging.Logger
@@ -158,14 +294,20 @@ type AlertExecutorImpl struct {
 	// 告警配置
 	emailConfig   *EmailConfig
 	webhookConfig *WebhookConfig
+	notifications *NotificationRenderer
 	mu            sync.RWMutex
+
+	// 告警中敏感数据的脱敏器
+	masker *analyzer.Masker
 }
 
 // NewAlertExecutor 创建告警执行器
 func NewAlertExecutor(logger logging.Logger) ActionExecutor {
 	return &AlertExecutorImpl{
-		logger:   logger,
-		channels: []string{"email", "sms", "webhook"},
+		logger:        logger,
+		channels:      []string{NotificationChannelEmail, NotificationChannelSMS, NotificationChannelWebhook},
+		notifications: NewNotificationRenderer(DefaultNotificationConfig(), logger),
+		masker:        analyzer.NewMasker(analyzer.DefaultMaskingConfig()),
 		stats: ExecutorStats{
 			ActionStats: make(map[string]uint64),
 			StartTime:   time.Now(),
@@ -187,42 +329,72 @@ func (ae *AlertExecutorImpl) ExecuteAction(ctx context.Context, decision *engine
 	}
 
 	// 创建告警
-	alert := &Alert{
-		ID:        result.ID,
-		Title:     "DLP安全告警",
-		Message:   fmt.Sprintf("检测到%s级别的安全风险: %s", decision.RiskLevel.String(), decision.Reason),
-		Level:     ae.mapRiskLevelToAlertLevel(decision.RiskLevel),
-		Source:    "DLP",
-		Timestamp: time.Now(),
-		Tags:      []string{"dlp", "security", decision.RiskLevel.String()},
-		Metadata: map[string]interface{}{
-			"decision_id": decision.ID,
-			"risk_score":  decision.RiskScore,
-			"confidence":  decision.Confidence,
-		},
-		Recipients: []string{"admin@example.com"},
-		Channels:   []string{"email"},
-	}
+	alert := ae.newAlert(ctx, result.ID, decision)
 
 	// 发送告警
 	if err := ae.sendAlert(alert); err != nil {
 		result.Error = err
 		atomic.AddUint64(&ae.stats.FailedExecutions, 1)
 		ae.stats.LastError = err
+		setRemediation(result, ae.config.RemediationTemplates, engine.PolicyActionAlert.String(), decision, nil)
 	} else {
 		result.Success = true
 		result.Metadata["alert"] = alert
 		result.AffectedData = alert
+		result.Remediation = alert.Remediation
 		atomic.AddUint64(&ae.stats.SuccessfulExecutions, 1)
 		ae.logger.Info("发送告警成功", "alert_id", alert.ID, "level", alert.Level.String())
 	}
-
 	result.ProcessingTime = time.Since(startTime)
 	ae.updateAverageTime(result.ProcessingTime)
 
 	return result, nil
 }
 
+// newAlert 根据决策创建告警，处置建议优先使用上下文中主动作的建议
+func (ae *AlertExecutorImpl) newAlert(ctx context.Context, id string, decision *engine.PolicyDecision) *Alert {
+	remediation := remediationFromContext(ctx)
+	if remediation == "" {
+		remediation = renderRemediation(ae.config.RemediationTemplates, engine.PolicyActionAlert.String(), remediationVariables(decision))
+	}
+
+	vars := notificationVariables(decision)
+	vars["remediation"] = remediation
+	// URL、处置建议等变量可能带有发现的原始值
+	for key, value := range vars {
+		vars[key] = redactDecisionText(ae.masker, decision, value)
+	}
+	remediation = vars["remediation"]
+	findings := decisionFindings(ae.masker, decision)
+	if len(findings) > 0 {
+		vars["findings"] = formatFindings(findings)
+	}
+	title, message := ae.notifications.Render(NotificationChannelAlert, vars)
+
+	alert := &Alert{
+		ID:        id,
+		Title:     title,
+		Message:   message,
+		Level:     ae.mapRiskLevelToAlertLevel(decision.RiskLevel),
+		Source:    "DLP",
+		Timestamp: time.Now(),
+		Tags:      []string{"dlp", "security", decision.RiskLevel.String()},
+		Metadata: map[string]interface{}{
+			"decision_id": decision.ID,
+			"risk_score":  decision.RiskScore,
+			"confidence":  decision.Confidence,
+		},
+		Recipients:  []string{"admin@example.com"},
+		Channels:    []string{NotificationChannelEmail},
+		Remediation: remediation,
+		variables:   vars,
+	}
+	if len(findings) > 0 {
+		alert.Metadata["findings"] = findings
+	}
+	return alert
+}
+
 // GetSupportedActions 获取支持的动作类型
 func (ae *AlertExecutorImpl) GetSupportedActions() []engine.PolicyAction {
 	return []engine.PolicyAction{engine.PolicyActionAlert}
@@ -236,6 +408,8 @@ func (ae *AlertExecutorImpl) CanExecute(actionType engine.PolicyAction) bool {
 // Initialize 初始化执行器
 func (ae *AlertExecutorImpl) Initialize(config ExecutorConfig) error {
 	ae.config = config
+	ae.notifications = NewNotificationRenderer(config.Notification, ae.logger)
+	ae.masker = analyzer.NewMasker(config.Masking)
 	ae.logger.Info("初始化告警执行器")
 	return nil
 }
@@ -285,15 +459,15 @@ func (ae *AlertExecutorImpl) sendAlert(alert *Alert) error {
 	// 根据不同的通道发送告警
 	for _, channel := range alert.Channels {
 		switch channel {
-		case "email":
+		case NotificationChannelEmail:
 			if err := ae.sendEmailAlert(alert); err != nil {
 				errors = append(errors, fmt.Errorf("邮件告警发送失败: %w", err))
 			}
-		case "webhook":
+		case NotificationChannelWebhook:
 			if err := ae.sendWebhookAlert(alert); err != nil {
 				errors = append(errors, fmt.Errorf("Webhook告警发送失败: %w", err))
 			}
-		case "sms":
+		case NotificationChannelSMS:
 			if err := ae.sendSMSAlert(alert); err != nil {
 				errors = append(errors, fmt.Errorf("短信告警发送失败: %w", err))
 			}
@@ -316,8 +490,7 @@ func (ae *AlertExecutorImpl) sendEmailAlert(alert *Alert) error {
 	}
 
 	// 构建邮件内容
-	subject := fmt.Sprintf("[DLP告警] %s - %s", alert.Level.String(), alert.Title)
-	body := ae.buildEmailBody(alert)
+	subject, body := ae.notifications.Render(NotificationChannelEmail, alertVariables(alert))
 
 	// 连接SMTP服务器
 	addr := fmt.Sprintf("%s:%d", ae.emailConfig.SMTPServer, ae.emailConfig.SMTPPort)
@@ -345,16 +518,19 @@ func (ae *AlertExecutorImpl) sendWebhookAlert(alert *Alert) error {
 		return fmt.Errorf("Webhook配置未设置")
 	}
 
-	// 构建Webhook负载
+	// 构建Webhook负载，text 为按模板渲染的可读内容
+	_, text := ae.notifications.Render(NotificationChannelWebhook, alertVariables(alert))
 	payload := map[string]interface{}{
-		"alert_id":  alert.ID,
-		"title":     alert.Title,
-		"message":   alert.Message,
-		"level":     alert.Level.String(),
-		"source":    alert.Source,
-		"timestamp": alert.Timestamp.Format(time.RFC3339),
-		"tags":      alert.Tags,
-		"metadata":  alert.Metadata,
+		"alert_id":    alert.ID,
+		"title":       alert.Title,
+		"message":     alert.Message,
+		"level":       alert.Level.String(),
+		"source":      alert.Source,
+		"timestamp":   alert.Timestamp.Format(time.RFC3339),
+		"tags":        alert.Tags,
+		"metadata":    alert.Metadata,
+		"remediation": alert.Remediation,
+		"text":        text,
 	}
 
 	// 序列化为JSON
@@ -398,37 +574,11 @@ func (ae *AlertExecutorImpl) sendWebhookAlert(alert *Alert) error {
 func (ae *AlertExecutorImpl) sendSMSAlert(alert *Alert) error {
 	// 这里可以集成短信服务提供商的API
 	// 例如：阿里云短信、腾讯云短信、Twilio等
-	ae.logger.Info("短信告警发送（模拟）", "alert_id", alert.ID, "title", alert.Title)
+	_, content := ae.notifications.Render(NotificationChannelSMS, alertVariables(alert))
+	ae.logger.Info("短信告警发送（模拟）", "alert_id", alert.ID, "content", content)
 	return nil
 }
 
-// buildEmailBody 构建邮件正文
-func (ae *AlertExecutorImpl) buildEmailBody(alert *Alert) string {
-	var body strings.Builder
-
-	body.WriteString(fmt.Sprintf("告警标题: %s\n", alert.Title))
-	body.WriteString(fmt.Sprintf("告警级别: %s\n", alert.Level.String()))
-	body.WriteString(fmt.Sprintf("告警时间: %s\n", alert.Timestamp.Format("2006-01-02 15:04:05")))
-	body.WriteString(fmt.Sprintf("告警来源: %s\n", alert.Source))
-	body.WriteString(fmt.Sprintf("告警消息: %s\n\n", alert.Message))
-
-	if len(alert.Tags) > 0 {
-		body.WriteString(fmt.Sprintf("标签: %s\n", strings.Join(alert.Tags, ", ")))
-	}
-
-	if len(alert.Metadata) > 0 {
-		body.WriteString("详细信息:\n")
-		for key, value := range alert.Metadata {
-			body.WriteString(fmt.Sprintf("  %s: %v\n", key, value))
-		}
-	}
-
-	body.WriteString("\n---\n")
-	body.WriteString("此邮件由DLP系统自动发送，请勿回复。")
-
-	return body.String()
-}
-
 // buildEmailMessage 构建邮件消息
 func (ae *AlertExecutorImpl) buildEmailMessage(from string, to []string, subject, body string) string {
 	var message strings.Builder
@@ -462,9 +612,19 @@ type AuditExecutorImpl struct {
 	logger           logging.Logger
 	config           ExecutorConfig
 	stats            ExecutorStats
-	events           []AuditEvent
 	processCollector *ProcessInfoCollector
 	networkExtractor *NetworkInfoExtractor
+
+	// 已记录的审计事件，策略决策和 RecordAuditEvent 可能并发追加
+	events   []AuditEvent
+	eventsMu sync.Mutex
+
+	// 审计日志批量写入器，首次写入时创建，Cleanup 时写完剩余记录并关闭
+	writer   *AuditWriter
+	writerMu sync.Mutex
+
+	// 审计记录中敏感数据的脱敏器
+	masker *analyzer.Masker
 }
 
 // NewAuditExecutor 创建审计执行器
@@ -474,6 +634,7 @@ func NewAuditExecutor(logger logging.Logger) ActionExecutor {
 		events:           make([]AuditEvent, 0),
 		processCollector: NewProcessInfoCollector(logger),
 		networkExtractor: NewNetworkInfoExtractor(logger),
+		masker:           analyzer.NewMasker(analyzer.DefaultMaskingConfig()),
 		stats: ExecutorStats{
 			ActionStats: make(map[string]uint64),
 			StartTime:   time.Now(),
@@ -559,8 +720,10 @@ func (ae *AuditExecutorImpl) ExecuteAction(ctx context.Context, decision *engine
 		SourcePort:  networkInfo.SourcePort,
 		DestPort:    networkInfo.DestPort,
 		DestDomain:  networkInfo.DestDomain,
-		RequestURL:  networkInfo.RequestURL,
-		RequestData: networkInfo.RequestData,
+		RequestURL:  redactDecisionText(ae.masker, decision, networkInfo.RequestURL),
+		RequestData: redactDecisionText(ae.masker, decision, networkInfo.RequestData),
+
+		Findings: decisionFindings(ae.masker, decision),
 
 		Details: map[string]interface{}{
 			"decision_id":     decision.ID,
@@ -571,6 +734,15 @@ func (ae *AuditExecutorImpl) ExecuteAction(ctx context.Context, decision *engine
 		Metadata: make(map[string]interface{}),
 	}
 
+	// 处置建议优先使用主动作的建议
+	event.Remediation = remediationFromContext(ctx)
+	if event.Remediation == "" {
+		vars := remediationVariables(decision)
+		vars["event_id"] = event.ID
+		event.Remediation = renderRemediation(ae.config.RemediationTemplates, engine.PolicyActionAudit.String(), vars)
+	}
+	event.Remediation = redactDecisionText(ae.masker, decision, event.Remediation)
+
 	// 从上下文中提取信息
 	if decision.Context != nil {
 		if decision.Context.UserInfo != nil {
@@ -599,6 +771,7 @@ func (ae *AuditExecutorImpl) ExecuteAction(ctx context.Context, decision *engine
 		ae.logger.Debug("记录审计事件成功", "event_id", event.ID)
 	}
 
+	setRemediation(result, ae.config.RemediationTemplates, engine.PolicyActionAudit.String(), decision, map[string]string{"event_id": event.ID})
 	result.ProcessingTime = time.Since(startTime)
 	ae.updateAverageTime(result.ProcessingTime)
 
@@ -618,6 +791,7 @@ func (ae *AuditExecutorImpl) CanExecute(actionType engine.PolicyAction) bool {
 // Initialize 初始化执行器
 func (ae *AuditExecutorImpl) Initialize(config ExecutorConfig) error {
 	ae.config = config
+	ae.masker = analyzer.NewMasker(config.Masking)
 	ae.logger.Info("初始化审计执行器")
 	return nil
 }
@@ -625,6 +799,17 @@ func (ae *AuditExecutorImpl) Initialize(config ExecutorConfig) error {
 // Cleanup 清理资源
 func (ae *AuditExecutorImpl) Cleanup() error {
 	ae.logger.Info("清理审计执行器资源")
+
+	ae.writerMu.Lock()
+	writer := ae.writer
+	ae.writer = nil
+	ae.writerMu.Unlock()
+
+	if writer != nil {
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf("关闭审计日志失败: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -643,7 +828,7 @@ func (ae *AuditExecutorImpl) updateAverageTime(duration time.Duration) {
 // logAuditEvent 记录审计事件
 func (ae *AuditExecutorImpl) logAuditEvent(event *AuditEvent) error {
 	// 简化的审计事件记录实现
-	ae.events = append(ae.events, *event)
+	ae.appendEvent(event)
 
 	// 构建日志字段
 	logFields := []interface{}{
@@ -693,6 +878,9 @@ func (ae *AuditExecutorImpl) logAuditEvent(event *AuditEvent) error {
 	if event.RequestData != "" {
 		logFields = append(logFields, "request_data", event.RequestData)
 	}
+	if len(event.Findings) > 0 {
+		logFields = append(logFields, "findings", formatFindings(event.Findings))
+	}
 
 	// 记录审计事件日志
 	ae.logger.Info("审计事件", logFields...)
@@ -706,17 +894,38 @@ func (ae *AuditExecutorImpl) logAuditEvent(event *AuditEvent) error {
 	return nil
 }
 
-// writeAuditEventToFile 将审计事件写入文件
-func (ae *AuditExecutorImpl) writeAuditEventToFile(event *AuditEvent) error {
-	// 构建审计日志文件路径
-	logDir := "app/dlp/logs"
-	logFile := filepath.Join(logDir, "dlp_audit.log")
+// RecordAuditEvent 记录策略决策之外的审计事件，如隔离文件的恢复
+func (ae *AuditExecutorImpl) RecordAuditEvent(event *AuditEvent) error {
+	ae.appendEvent(event)
+	ae.logger.Info("审计事件",
+		"event_type", event.EventType,
+		"action", event.Action,
+		"user_id", event.UserID,
+		"result", event.Result,
+		"reason", event.Reason,
+	)
 
-	// 确保日志目录存在
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("创建日志目录失败: %w", err)
-	}
+	return ae.writeAuditRecord(map[string]interface{}{
+		"id":        event.ID,
+		"timestamp": event.Timestamp.Format(time.RFC3339),
+		"type":      event.EventType,
+		"action":    event.Action,
+		"user_id":   event.UserID,
+		"device_id": event.DeviceID,
+		"result":    event.Result,
+		"details":   event.Details,
+	})
+}
 
+// appendEvent 保存审计事件
+func (ae *AuditExecutorImpl) appendEvent(event *AuditEvent) {
+	ae.eventsMu.Lock()
+	defer ae.eventsMu.Unlock()
+	ae.events = append(ae.events, *event)
+}
+
+// writeAuditEventToFile 将审计事件写入文件
+func (ae *AuditExecutorImpl) writeAuditEventToFile(event *AuditEvent) error {
 	// 构建完整的审计事件JSON
 	auditRecord := map[string]interface{}{
 		"id":        event.ID,
@@ -774,6 +983,12 @@ func (ae *AuditExecutorImpl) writeAuditEventToFile(event *AuditEvent) error {
 	if event.RequestData != "" {
 		auditRecord["request_data"] = event.RequestData
 	}
+	if event.Remediation != "" {
+		auditRecord["remediation"] = event.Remediation
+	}
+	if len(event.Findings) > 0 {
+		auditRecord["findings"] = event.Findings
+	}
 
 	// 添加进程信息
 	if event.ProcessInfo != nil {
@@ -784,30 +999,32 @@ func (ae *AuditExecutorImpl) writeAuditEventToFile(event *AuditEvent) error {
 		auditRecord["process_user"] = event.ProcessInfo.UserName
 	}
 
-	// 序列化为JSON
-	jsonData, err := json.Marshal(auditRecord)
-	if err != nil {
-		return fmt.Errorf("序列化审计事件失败: %w", err)
-	}
+	return ae.writeAuditRecord(auditRecord)
+}
 
-	// 写入文件
-	file, err := os.OpenFile(logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+// writeAuditRecord 将审计记录以JSON行追加到审计日志文件，由批量写入器异步落盘
+func (ae *AuditExecutorImpl) writeAuditRecord(auditRecord map[string]interface{}) error {
+	writer, err := ae.auditWriter()
 	if err != nil {
-		return fmt.Errorf("打开审计日志文件失败: %w", err)
+		return err
 	}
-	defer file.Close()
+	return writer.Write(auditRecord)
+}
 
-	// 写入JSON数据和换行符
-	if _, err := file.Write(append(jsonData, '\n')); err != nil {
-		return fmt.Errorf("写入审计日志失败: %w", err)
-	}
+// auditWriter 获取审计日志写入器，未创建时打开审计日志文件
+func (ae *AuditExecutorImpl) auditWriter() (*AuditWriter, error) {
+	ae.writerMu.Lock()
+	defer ae.writerMu.Unlock()
 
-	// 刷新缓冲区
-	if err := file.Sync(); err != nil {
-		return fmt.Errorf("刷新审计日志文件失败: %w", err)
+	if ae.writer == nil {
+		config := ae.config.AuditWriter.withDefaults()
+		sink, err := OpenAuditFile(config.Path)
+		if err != nil {
+			return nil, err
+		}
+		ae.writer = NewAuditWriter(config, sink, ae.logger)
 	}
-
-	return nil
+	return ae.writer, nil
 }
 
 // EncryptExecutorImpl 加密执行器实现
@@ -818,6 +1035,7 @@ type EncryptExecutorImpl struct {
 
 	// 加密配置
 	encryptConfig *EncryptionConfig
+	keyProvider   KeyProvider
 	mu            sync.RWMutex
 }
 
@@ -832,7 +1050,7 @@ func NewEncryptExecutor(logger logging.Logger) ActionExecutor {
 	}
 }
 
-// ExecuteAction 执行动作
+// ExecuteAction 执行动作，使用当前密钥加密决策中的敏感数据
 func (ee *EncryptExecutorImpl) ExecuteAction(ctx context.Context, decision *engine.PolicyDecision) (*ExecutionResult, error) {
 	startTime := time.Now()
 	atomic.AddUint64(&ee.stats.TotalExecutions, 1)
@@ -841,23 +1059,61 @@ func (ee *EncryptExecutorImpl) ExecuteAction(ctx context.Context, decision *engi
 		ID:        fmt.Sprintf("encrypt_%d", time.Now().UnixNano()),
 		Timestamp: time.Now(),
 		Action:    engine.PolicyActionEncrypt,
-		Success:   true, // 简化实现，总是成功
+		Success:   false,
 		Metadata:  make(map[string]interface{}),
 	}
 
-	// 简化的加密实现
-	result.Metadata["encryption_algorithm"] = "AES-256"
-	result.Metadata["encryption_status"] = "completed"
+	algorithm := ee.getEncryptionAlgorithm()
+	result.Metadata["encryption_algorithm"] = algorithm
 
-	atomic.AddUint64(&ee.stats.SuccessfulExecutions, 1)
-	ee.logger.Info("数据加密完成", "decision_id", decision.ID)
+	data := encryptTarget(decision)
+	var ciphertext []byte
+	var err error
+	if data == nil {
+		err = fmt.Errorf("缺少待加密的数据")
+	} else {
+		ciphertext, err = ee.EncryptData(data, algorithm)
+	}
 
+	if err != nil {
+		result.Error = fmt.Errorf("加密数据失败: %w", err)
+		result.Metadata["encryption_status"] = "failed"
+		ee.recordFailure(result.Error)
+		ee.logger.Error("数据加密失败", "decision_id", decision.ID, "error", err)
+	} else {
+		keyID, _, _, _ := parseCiphertextHeader(ciphertext)
+		result.Success = true
+		result.Metadata["encryption_status"] = "completed"
+		result.Metadata["key_id"] = keyID
+		result.Metadata["original_size"] = len(data)
+		result.Metadata["encrypted_size"] = len(ciphertext)
+		result.AffectedData = ciphertext
+
+		atomic.AddUint64(&ee.stats.SuccessfulExecutions, 1)
+		ee.logger.Info("数据加密完成", "decision_id", decision.ID, "key_id", keyID)
+	}
+
+	setRemediation(result, ee.config.RemediationTemplates, engine.PolicyActionEncrypt.String(), decision, map[string]string{"algorithm": algorithm})
 	result.ProcessingTime = time.Since(startTime)
 	ee.updateAverageTime(result.ProcessingTime)
 
 	return result, nil
 }
 
+// encryptTarget 获取待加密的数据，优先使用解析后的正文，否则使用原始数据包负载
+func encryptTarget(decision *engine.PolicyDecision) []byte {
+	if decision.Context == nil {
+		return nil
+	}
+	if decision.Context.ParsedData != nil && len(decision.Context.ParsedData.Body) > 0 {
+		return decision.Context.ParsedData.Body
+	}
+	if decision.Context.PacketInfo != nil && len(decision.Context.PacketInfo.Payload) > 0 {
+		return decision.Context.PacketInfo.Payload
+	}
+	return nil
+}
+
 // GetSupportedActions 获取支持的动作类型
 func (ee *EncryptExecutorImpl) GetSupportedActions() []engine.PolicyAction {
 	return []engine.PolicyAction{engine.PolicyActionEncrypt}
@@ -868,9 +1124,12 @@ func (ee *EncryptExecutorImpl) CanExecute(actionType engine.PolicyAction) bool {
 	return actionType == engine.PolicyActionEncrypt
 }
 
-// Initialize 初始化执行器
+// Initialize 初始化执行器，根据配置的密钥来源创建密钥提供者
 func (ee *EncryptExecutorImpl) Initialize(config ExecutorConfig) error {
 	ee.config = config
+	if err := ee.SetEncryptionConfig(&config.Encryption); err != nil {
+		return fmt.Errorf("加载加密密钥失败: %w", err)
+	}
 	ee.logger.Info("初始化加密执行器")
 	return nil
 }
@@ -883,22 +1142,49 @@ func (ee *EncryptExecutorImpl) Cleanup() error {
 
 // GetStats 获取统计信息
 func (ee *EncryptExecutorImpl) GetStats() ExecutorStats {
-	stats := ee.stats
-	stats.Uptime = time.Since(ee.stats.StartTime)
-	return stats
+	ee.mu.RLock()
+	defer ee.mu.RUnlock()
+
+	// 计数器通过原子操作更新，不能随结构体一起复制
+	return ExecutorStats{
+		TotalExecutions:      atomic.LoadUint64(&ee.stats.TotalExecutions),
+		SuccessfulExecutions: atomic.LoadUint64(&ee.stats.SuccessfulExecutions),
+		FailedExecutions:     atomic.LoadUint64(&ee.stats.FailedExecutions),
+		AverageTime:          ee.stats.AverageTime,
+		ActionStats:          ee.stats.ActionStats,
+		LastError:            ee.stats.LastError,
+		StartTime:            ee.stats.StartTime,
+		Uptime:               time.Since(ee.stats.StartTime),
+	}
 }
 
-// updateAverageTime 更新平均处理时间
+// updateAverageTime 更新平均处理时间，并发执行的动作共用统计信息，需要持有锁
 func (ee *EncryptExecutorImpl) updateAverageTime(duration time.Duration) {
+	ee.mu.Lock()
+	defer ee.mu.Unlock()
 	ee.stats.AverageTime = (ee.stats.AverageTime + duration) / 2
 }
 
+// recordFailure 记录失败的执行
+func (ee *EncryptExecutorImpl) recordFailure(err error) {
+	atomic.AddUint64(&ee.stats.FailedExecutions, 1)
+	ee.mu.Lock()
+	ee.stats.LastError = err
+	ee.mu.Unlock()
+}
+
 // QuarantineExecutorImpl 隔离执行器实现
 type QuarantineExecutorImpl struct {
 	logger           logging.Logger
 	config           ExecutorConfig
 	stats            ExecutorStats
 	quarantinedFiles []QuarantinedFile
+	mu               sync.RWMutex
+
+	// quarantineDir 隔离目录，为空时只记录隔离信息，不移动文件
+	quarantineDir string
+	approvers     map[string]ed25519.PublicKey
+	auditRecorder AuditRecorder
 }
 
 // NewQuarantineExecutor 创建隔离执行器
@@ -906,6 +1192,7 @@ func NewQuarantineExecutor(logger logging.Logger) ActionExecutor {
 	return &QuarantineExecutorImpl{
 		logger:           logger,
 		quarantinedFiles: make([]QuarantinedFile, 0),
+		approvers:        make(map[string]ed25519.PublicKey),
 		stats: ExecutorStats{
 			ActionStats: make(map[string]uint64),
 			StartTime:   time.Now(),
@@ -926,10 +1213,26 @@ func (qe *QuarantineExecutorImpl) ExecuteAction(ctx context.Context, decision *e
 		Metadata:  make(map[string]interface{}),
 	}
 
+	// 同一文件已隔离时不重复执行
+	originalPath := quarantineTarget(decision)
+	if existing := qe.findQuarantined(originalPath); existing != nil {
+		result.Success = true
+		result.Metadata[MetadataAlreadyApplied] = true
+		result.Metadata["quarantined_file"] = *existing
+		result.AffectedData = *existing
+		atomic.AddUint64(&qe.stats.SuccessfulExecutions, 1)
+		qe.logger.Debug("文件已隔离，跳过", "file_id", existing.ID, "original_path", originalPath)
+
+		setRemediation(result, qe.config.RemediationTemplates, engine.PolicyActionQuarantine.String(), decision, quarantineRemediationVariables(existing))
+		result.ProcessingTime = time.Since(startTime)
+		qe.updateAverageTime(result.ProcessingTime)
+		return result, nil
+	}
+
 	// 创建隔离文件记录
 	quarantinedFile := QuarantinedFile{
 		ID:             result.ID,
-		OriginalPath:   "unknown", // 实际实现需要从上下文获取
+		OriginalPath:   originalPath,
 		QuarantinePath: fmt.Sprintf("/quarantine/%s", result.ID),
 		Reason:         decision.Reason,
 		Timestamp:      time.Now(),
@@ -941,16 +1244,17 @@ func (qe *QuarantineExecutorImpl) ExecuteAction(ctx context.Context, decision *e
 	// 执行隔离操作
 	if err := qe.quarantineFile(&quarantinedFile); err != nil {
 		result.Error = err
-		atomic.AddUint64(&qe.stats.FailedExecutions, 1)
-		qe.stats.LastError = err
+		qe.recordFailure(err)
 	} else {
 		result.Success = true
+		result.Metadata[MetadataAlreadyApplied] = false
 		result.Metadata["quarantined_file"] = quarantinedFile
 		result.AffectedData = quarantinedFile
 		atomic.AddUint64(&qe.stats.SuccessfulExecutions, 1)
 		qe.logger.Info("文件隔离成功", "file_id", quarantinedFile.ID)
 	}
 
+	setRemediation(result, qe.config.RemediationTemplates, engine.PolicyActionQuarantine.String(), decision, quarantineRemediationVariables(&quarantinedFile))
 	result.ProcessingTime = time.Since(startTime)
 	qe.updateAverageTime(result.ProcessingTime)
 
@@ -970,6 +1274,9 @@ func (qe *QuarantineExecutorImpl) CanExecute(actionType engine.PolicyAction) boo
 // Initialize 初始化执行器
 func (qe *QuarantineExecutorImpl) Initialize(config ExecutorConfig) error {
 	qe.config = config
+	if err := qe.SetQuarantineConfig(&config.Quarantine); err != nil {
+		return err
+	}
 	qe.logger.Info("初始化隔离执行器")
 	return nil
 }
@@ -982,25 +1289,104 @@ func (qe *QuarantineExecutorImpl) Cleanup() error {
 
 // GetStats 获取统计信息
 func (qe *QuarantineExecutorImpl) GetStats() ExecutorStats {
-	stats := qe.stats
-	stats.Uptime = time.Since(qe.stats.StartTime)
-	return stats
+	qe.mu.RLock()
+	defer qe.mu.RUnlock()
+
+	// 计数器通过原子操作更新，不能随结构体一起复制
+	return ExecutorStats{
+		TotalExecutions:      atomic.LoadUint64(&qe.stats.TotalExecutions),
+		SuccessfulExecutions: atomic.LoadUint64(&qe.stats.SuccessfulExecutions),
+		FailedExecutions:     atomic.LoadUint64(&qe.stats.FailedExecutions),
+		AverageTime:          qe.stats.AverageTime,
+		ActionStats:          qe.stats.ActionStats,
+		LastError:            qe.stats.LastError,
+		StartTime:            qe.stats.StartTime,
+		Uptime:               time.Since(qe.stats.StartTime),
+	}
 }
 
-// updateAverageTime 更新平均处理时间
+// updateAverageTime 更新平均处理时间，并发执行的动作共用统计信息，需要持有锁
 func (qe *QuarantineExecutorImpl) updateAverageTime(duration time.Duration) {
+	qe.mu.Lock()
+	defer qe.mu.Unlock()
 	qe.stats.AverageTime = (qe.stats.AverageTime + duration) / 2
 }
 
+// recordFailure 记录失败的执行
+func (qe *QuarantineExecutorImpl) recordFailure(err error) {
+	atomic.AddUint64(&qe.stats.FailedExecutions, 1)
+	qe.mu.Lock()
+	qe.stats.LastError = err
+	qe.mu.Unlock()
+}
+
+// GetQuarantinedFiles 获取隔离的文件
+func (qe *QuarantineExecutorImpl) GetQuarantinedFiles() []QuarantinedFile {
+	qe.mu.RLock()
+	defer qe.mu.RUnlock()
+
+	files := make([]QuarantinedFile, len(qe.quarantinedFiles))
+	copy(files, qe.quarantinedFiles)
+	return files
+}
+
+// quarantineRemediationVariables 隔离文件的修复建议模板变量
+func quarantineRemediationVariables(file *QuarantinedFile) map[string]string {
+	return map[string]string{
+		"original_path":   file.OriginalPath,
+		"quarantine_path": file.QuarantinePath,
+	}
+}
+
+// quarantineTarget 从决策上下文获取待隔离的文件路径，无法确定时返回 unknown
+func quarantineTarget(decision *engine.PolicyDecision) string {
+	if decision.Context != nil && decision.Context.ParsedData != nil {
+		parsed := decision.Context.ParsedData
+		if path, ok := parsed.Metadata["file_path"].(string); ok && path != "" {
+			return path
+		}
+		if parsed.Protocol == "file" && parsed.URL != "" {
+			return parsed.URL
+		}
+	}
+	return "unknown"
+}
+
+// findQuarantined 查找已隔离的同一文件，路径未知时无法判断，返回nil
+func (qe *QuarantineExecutorImpl) findQuarantined(originalPath string) *QuarantinedFile {
+	if originalPath == "unknown" {
+		return nil
+	}
+
+	qe.mu.RLock()
+	defer qe.mu.RUnlock()
+	for i := range qe.quarantinedFiles {
+		if qe.quarantinedFiles[i].OriginalPath == originalPath {
+			file := qe.quarantinedFiles[i]
+			return &file
+		}
+	}
+	return nil
+}
+
 // quarantineFile 隔离文件
+// 配置了隔离目录且文件存在时将文件移动到隔离目录并记录哈希，否则只记录隔离信息
 func (qe *QuarantineExecutorImpl) quarantineFile(file *QuarantinedFile) error {
-	// 简化的文件隔离实现
-	qe.quarantinedFiles = append(qe.quarantinedFiles, *file)
+	qe.mu.RLock()
+	quarantineDir := qe.quarantineDir
+	qe.mu.RUnlock()
+
+	if quarantineDir != "" {
+		if info, err := os.Stat(file.OriginalPath); err == nil && info.Mode().IsRegular() {
+			if err := qe.quarantineFileReal(file); err != nil {
+				return err
+			}
+		}
+	}
 
-	// 实际实现需要：
-	// 1. 移动文件到隔离目录
-	// 2. 设置适当的权限
-	// 3. 记录隔离信息
+	qe.mu.Lock()
+	qe.quarantinedFiles = append(qe.quarantinedFiles, *file)
+	qe.mu.Unlock()
 
 	qe.logger.Info("隔离文件",
 		"original_path", file.OriginalPath,
@@ -1010,38 +1396,38 @@ func (qe *QuarantineExecutorImpl) quarantineFile(file *QuarantinedFile) error {
 	return nil
 }
 
-// blockConnection 阻断网络连接
-func (be *BlockExecutorImpl) blockConnection(packet interface{}) error {
+// blockConnection 按规则阻断网络连接
+func (be *BlockExecutorImpl) blockConnection(rule *FirewallRule) error {
+	if rule.DestIP == "" {
+		return fmt.Errorf("阻断规则缺少目标IP")
+	}
+
 	// 这里需要根据操作系统实现真实的网络阻断
-	switch runtime.GOOS {
+	switch be.goos {
 	case "windows":
-		return be.blockConnectionWindows(packet)
+		return be.blockConnectionWindows(rule)
 	case "linux":
-		return be.blockConnectionLinux(packet)
+		return be.blockConnectionLinux(rule)
 	case "darwin":
-		return be.blockConnectionDarwin(packet)
+		return be.blockConnectionDarwin(rule)
 	default:
-		be.logger.Warn("不支持的操作系统，使用模拟阻断", "os", runtime.GOOS)
-		return be.blockConnectionMock(packet)
+		be.logger.Warn("不支持的操作系统，使用模拟阻断", "os", be.goos)
+		return be.blockConnectionMock(rule)
 	}
 }
 
 // blockConnectionWindows Windows平台网络阻断
-func (be *BlockExecutorImpl) blockConnectionWindows(packet interface{}) error {
+func (be *BlockExecutorImpl) blockConnectionWindows(rule *FirewallRule) error {
 	// 在Windows上使用netsh命令或Windows防火墙API
 	// 这里使用netsh命令作为示例
-
-	// 提取IP地址（这里需要根据实际的packet类型进行类型断言）
-	destIP := "0.0.0.0" // 从packet中提取目标IP
+	destIP := rule.DestIP
 
 	// 使用netsh命令添加防火墙规则
-	cmd := exec.Command("netsh", "advfirewall", "firewall", "add", "rule",
-		"name=DLP_Block_"+destIP,
+	output, err := be.runner.CombinedOutput("netsh", "advfirewall", "firewall", "add", "rule",
+		"name="+rule.Name,
 		"dir=out",
 		"action=block",
 		"remoteip="+destIP)
-
-	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("执行netsh命令失败: %w, 输出: %s", err, string(output))
 	}
@@ -1051,14 +1437,12 @@ func (be *BlockExecutorImpl) blockConnectionWindows(packet interface{}) error {
 }
 
 // blockConnectionLinux Linux平台网络阻断
-func (be *BlockExecutorImpl) blockConnectionLinux(packet interface{}) error {
+func (be *BlockExecutorImpl) blockConnectionLinux(rule *FirewallRule) error {
 	// 在Linux上使用iptables
-	destIP := "0.0.0.0" // 从packet中提取目标IP
+	destIP := rule.DestIP
 
 	// 使用iptables命令阻断连接
-	cmd := exec.Command("iptables", "-A", "OUTPUT", "-d", destIP, "-j", "DROP")
-
-	output, err := cmd.CombinedOutput()
+	output, err := be.runner.CombinedOutput("iptables", "-A", "OUTPUT", "-d", destIP, "-j", "DROP")
 	if err != nil {
 		return fmt.Errorf("执行iptables命令失败: %w, 输出: %s", err, string(output))
 	}
@@ -1068,23 +1452,21 @@ func (be *BlockExecutorImpl) blockConnectionLinux(packet interface{}) error {
 }
 
 // blockConnectionDarwin macOS平台网络阻断
-func (be *BlockExecutorImpl) blockConnectionDarwin(packet interface{}) error {
+func (be *BlockExecutorImpl) blockConnectionDarwin(rule *FirewallRule) error {
 	// 在macOS上使用pfctl
-	destIP := "0.0.0.0" // 从packet中提取目标IP
+	destIP := rule.DestIP
 
 	// 创建临时规则文件
 	ruleFile := "/tmp/dlp_block_" + fmt.Sprintf("%d", time.Now().Unix()) + ".conf"
-	rule := fmt.Sprintf("block out quick to %s\n", destIP)
+	pfRule := fmt.Sprintf("block out quick to %s\n", destIP)
 
-	if err := os.WriteFile(ruleFile, []byte(rule), 0644); err != nil {
+	if err := os.WriteFile(ruleFile, []byte(pfRule), 0644); err != nil {
 		return fmt.Errorf("创建规则文件失败: %w", err)
 	}
 	defer os.Remove(ruleFile)
 
 	// 使用pfctl加载规则
-	cmd := exec.Command("pfctl", "-f", ruleFile)
-
-	output, err := cmd.CombinedOutput()
+	output, err := be.runner.CombinedOutput("pfctl", "-f", ruleFile)
 	if err != nil {
 		return fmt.Errorf("执行pfctl命令失败: %w, 输出: %s", err, string(output))
 	}
@@ -1094,121 +1476,184 @@ func (be *BlockExecutorImpl) blockConnectionDarwin(packet interface{}) error {
 }
 
 // blockConnectionMock 模拟网络阻断
-func (be *BlockExecutorImpl) blockConnectionMock(packet interface{}) error {
-	be.logger.Info("模拟网络连接阻断", "packet", packet)
+func (be *BlockExecutorImpl) blockConnectionMock(rule *FirewallRule) error {
+	be.logger.Info("模拟网络连接阻断", "dest_ip", rule.DestIP)
 	return nil
 }
 
-// encryptData 加密数据
-func (ee *EncryptExecutorImpl) encryptData(data []byte) ([]byte, error) {
-	if ee.encryptConfig == nil {
-		// 使用默认AES-256加密
-		return ee.encryptWithAES256(data)
-	}
-
-	switch ee.encryptConfig.Algorithm {
-	case "AES-256":
-		return ee.encryptWithAES256(data)
-	case "AES-128":
-		return ee.encryptWithAES128(data)
-	default:
-		return nil, fmt.Errorf("不支持的加密算法: %s", ee.encryptConfig.Algorithm)
-	}
-}
+// EncryptData 使用当前密钥加密数据，密文头部包含密钥ID
+func (ee *EncryptExecutorImpl) EncryptData(data []byte, algorithm string) ([]byte, error) {
+	ee.mu.RLock()
+	provider := ee.keyProvider
+	ee.mu.RUnlock()
 
-// encryptWithAES256 使用AES-256加密
-func (ee *EncryptExecutorImpl) encryptWithAES256(data []byte) ([]byte, error) {
-	// 生成32字节的密钥（AES-256）
-	key := make([]byte, 32)
-	if _, err := rand.Read(key); err != nil {
-		return nil, fmt.Errorf("生成密钥失败: %w", err)
+	if provider == nil {
+		return nil, ErrNoActiveKey
 	}
 
-	// 创建AES cipher
-	block, err := aes.NewCipher(key)
+	keyID, key, err := provider.CurrentKey()
 	if err != nil {
-		return nil, fmt.Errorf("创建AES cipher失败: %w", err)
+		return nil, fmt.Errorf("获取加密密钥失败: %w", err)
 	}
 
-	// 使用GCM模式
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("创建GCM失败: %w", err)
+	if err := checkAlgorithmKey(algorithm, key); err != nil {
+		return nil, err
 	}
 
-	// 生成随机nonce
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
-		return nil, fmt.Errorf("生成nonce失败: %w", err)
+	ciphertext, err := sealWithKey(keyID, key, data)
+	if err != nil {
+		return nil, err
 	}
 
-	// 加密数据
-	ciphertext := gcm.Seal(nonce, nonce, data, nil)
-
-	ee.logger.Debug("AES-256加密完成",
+	ee.logger.Debug("数据加密完成",
+		"algorithm", algorithm,
+		"key_id", keyID,
 		"original_size", len(data),
 		"encrypted_size", len(ciphertext))
 
 	return ciphertext, nil
 }
 
-// encryptWithAES128 使用AES-128加密
-func (ee *EncryptExecutorImpl) encryptWithAES128(data []byte) ([]byte, error) {
-	// 生成16字节的密钥（AES-128）
-	key := make([]byte, 16)
-	if _, err := rand.Read(key); err != nil {
-		return nil, fmt.Errorf("生成密钥失败: %w", err)
+// DecryptData 根据密文头部中的密钥ID选择密钥解密数据
+func (ee *EncryptExecutorImpl) DecryptData(encryptedData []byte, algorithm string) ([]byte, error) {
+	ee.mu.RLock()
+	provider := ee.keyProvider
+	ee.mu.RUnlock()
+
+	if provider == nil {
+		return nil, ErrNoActiveKey
 	}
 
-	// 创建AES cipher
-	block, err := aes.NewCipher(key)
+	keyID, header, rest, err := parseCiphertextHeader(encryptedData)
 	if err != nil {
-		return nil, fmt.Errorf("创建AES cipher失败: %w", err)
+		return nil, err
 	}
 
-	// 使用GCM模式
-	gcm, err := cipher.NewGCM(block)
+	key, err := provider.GetKey(keyID)
 	if err != nil {
-		return nil, fmt.Errorf("创建GCM失败: %w", err)
+		return nil, err
+	}
+	if err := checkAlgorithmKey(algorithm, key); err != nil {
+		return nil, err
 	}
 
-	// 生成随机nonce
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
-		return nil, fmt.Errorf("生成nonce失败: %w", err)
+	plaintext, err := openWithKey(key, header, rest)
+	if err != nil {
+		return nil, err
 	}
 
-	// 加密数据
-	ciphertext := gcm.Seal(nonce, nonce, data, nil)
+	ee.logger.Debug("数据解密完成", "key_id", keyID, "size", len(plaintext))
+	return plaintext, nil
+}
 
-	ee.logger.Debug("AES-128加密完成",
-		"original_size", len(data),
-		"encrypted_size", len(ciphertext))
+// Encrypt 使用配置的算法加密数据
+func (ee *EncryptExecutorImpl) Encrypt(data []byte) ([]byte, error) {
+	return ee.EncryptData(data, ee.getEncryptionAlgorithm())
+}
 
-	return ciphertext, nil
+// Decrypt 解密由Encrypt生成的数据
+func (ee *EncryptExecutorImpl) Decrypt(encryptedData []byte) ([]byte, error) {
+	return ee.DecryptData(encryptedData, ee.getEncryptionAlgorithm())
+}
+
+// GetSupportedAlgorithms 获取支持的加密算法
+func (ee *EncryptExecutorImpl) GetSupportedAlgorithms() []string {
+	return []string{"AES-256", "AES-256-GCM", "AES-128", "AES-128-GCM"}
+}
+
+// GenerateKey 生成密钥
+func (ee *EncryptExecutorImpl) GenerateKey(algorithm string, keySize int) ([]byte, error) {
+	if keySize <= 0 {
+		size, err := algorithmKeySize(algorithm)
+		if err != nil {
+			return nil, err
+		}
+		keySize = size
+	}
+
+	key := make([]byte, keySize)
+	if err := validateAESKey(key); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("生成密钥失败: %w", err)
+	}
+	return key, nil
+}
+
+// SetKeyProvider 设置密钥提供者
+func (ee *EncryptExecutorImpl) SetKeyProvider(provider KeyProvider) {
+	ee.mu.Lock()
+	defer ee.mu.Unlock()
+	ee.keyProvider = provider
+}
+
+// algorithmKeySize 获取算法对应的密钥长度
+func algorithmKeySize(algorithm string) (int, error) {
+	switch algorithm {
+	case "", "AES-256", "AES-256-GCM":
+		return 32, nil
+	case "AES-128", "AES-128-GCM":
+		return 16, nil
+	default:
+		return 0, fmt.Errorf("不支持的加密算法: %s", algorithm)
+	}
+}
+
+// checkAlgorithmKey 检查密钥长度是否与算法匹配
+func checkAlgorithmKey(algorithm string, key []byte) error {
+	size, err := algorithmKeySize(algorithm)
+	if err != nil {
+		return err
+	}
+	if len(key) != size {
+		return fmt.Errorf("密钥长度 %d 与加密算法 %s 不匹配", len(key), algorithm)
+	}
+	return nil
 }
 
 // getEncryptionAlgorithm 获取加密算法
 func (ee *EncryptExecutorImpl) getEncryptionAlgorithm() string {
-	if ee.encryptConfig == nil {
+	ee.mu.RLock()
+	defer ee.mu.RUnlock()
+
+	if ee.encryptConfig == nil || ee.encryptConfig.Algorithm == "" {
 		return "AES-256-GCM"
 	}
 	return ee.encryptConfig.Algorithm
 }
 
-// SetEncryptionConfig 设置加密配置
-func (ee *EncryptExecutorImpl) SetEncryptionConfig(config *EncryptionConfig) {
+// SetEncryptionConfig 设置加密配置，配置了密钥来源时同时创建密钥提供者
+func (ee *EncryptExecutorImpl) SetEncryptionConfig(config *EncryptionConfig) error {
+	var provider KeyProvider
+	if config != nil && (config.Key != "" || config.KeyEnv != "" || config.KeyPath != "") {
+		p, err := NewKeyProviderFromConfig(config)
+		if err != nil {
+			return err
+		}
+		provider = p
+	}
+
 	ee.mu.Lock()
 	defer ee.mu.Unlock()
 	ee.encryptConfig = config
+	if provider != nil {
+		ee.keyProvider = provider
+	}
+	return nil
 }
 
 // quarantineFileReal 真实的文件隔离实现
 func (qe *QuarantineExecutorImpl) quarantineFileReal(file *QuarantinedFile) error {
 	// 创建隔离目录
-	quarantineDir := "/var/quarantine/dlp" // Linux/macOS
-	if runtime.GOOS == "windows" {
-		quarantineDir = "C:\\ProgramData\\DLP\\Quarantine"
+	qe.mu.RLock()
+	quarantineDir := qe.quarantineDir
+	qe.mu.RUnlock()
+	if quarantineDir == "" {
+		quarantineDir = "/var/quarantine/dlp" // Linux/macOS
+		if runtime.GOOS == "windows" {
+			quarantineDir = "C:\\ProgramData\\DLP\\Quarantine"
+		}
 	}
 
 	if err := os.MkdirAll(quarantineDir, 0755); err != nil {
@@ -1218,6 +1663,11 @@ func (qe *QuarantineExecutorImpl) quarantineFileReal(file *QuarantinedFile) erro
 	// 构建隔离文件路径
 	quarantinePath := filepath.Join(quarantineDir, file.ID)
 
+	// 记录原始权限，恢复时还原
+	if info, err := os.Stat(file.OriginalPath); err == nil {
+		file.Metadata["original_mode"] = uint32(info.Mode().Perm())
+	}
+
 	// 移动文件到隔离目录
 	if err := os.Rename(file.OriginalPath, quarantinePath); err != nil {
 		// 如果移动失败，尝试复制然后删除
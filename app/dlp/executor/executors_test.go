@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBlockExecutor 创建阻断执行器，规则下发只计数不调用系统防火墙
+func newTestBlockExecutor(t *testing.T) (*BlockExecutorImpl, *[]FirewallRule) {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	be := NewBlockExecutor(logger).(*BlockExecutorImpl)
+	var mu sync.Mutex
+	applied := make([]FirewallRule, 0)
+	be.applyRule = func(rule *FirewallRule) error {
+		mu.Lock()
+		defer mu.Unlock()
+		applied = append(applied, *rule)
+		return nil
+	}
+	return be, &applied
+}
+
+func newBlockDecision(destIP string, destPort uint16) *engine.PolicyDecision {
+	return &engine.PolicyDecision{
+		Action: engine.PolicyActionBlock,
+		Reason: "检测到敏感数据外发",
+		Context: &engine.DecisionContext{
+			PacketInfo: &interceptor.PacketInfo{
+				SourceIP:   net.ParseIP("192.168.1.10"),
+				DestIP:     net.ParseIP(destIP),
+				SourcePort: 50000,
+				DestPort:   destPort,
+				Protocol:   interceptor.ProtocolTCP,
+			},
+		},
+	}
+}
+
+func TestBlockExecutor_RepeatedBlockIsIdempotent(t *testing.T) {
+	be, applied := newTestBlockExecutor(t)
+
+	first, err := be.ExecuteAction(context.Background(), newBlockDecision("203.0.113.5", 443))
+	require.NoError(t, err)
+	require.True(t, first.Success)
+	assert.Equal(t, false, first.Metadata[MetadataAlreadyApplied])
+
+	// 同一目标的重复决策不再下发规则
+	second, err := be.ExecuteAction(context.Background(), newBlockDecision("203.0.113.5", 8443))
+	require.NoError(t, err)
+	require.True(t, second.Success)
+	assert.Equal(t, true, second.Metadata[MetadataAlreadyApplied])
+
+	require.Len(t, *applied, 1)
+	assert.Equal(t, "203.0.113.5", (*applied)[0].DestIP)
+	assert.Equal(t, "DLP_Block_203.0.113.5", (*applied)[0].Name)
+
+	rules := be.GetFirewallRules()
+	require.Len(t, rules, 1)
+	assert.Equal(t, first.ID, rules[0].ID)
+	assert.Equal(t, rules[0], second.AffectedData)
+
+	// 不同目标仍然下发新规则
+	third, err := be.ExecuteAction(context.Background(), newBlockDecision("198.51.100.7", 443))
+	require.NoError(t, err)
+	assert.Equal(t, false, third.Metadata[MetadataAlreadyApplied])
+	assert.Len(t, *applied, 2)
+}
+
+func TestBlockExecutor_ConcurrentBlocksCreateOneRule(t *testing.T) {
+	be, applied := newTestBlockExecutor(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := be.ExecuteAction(context.Background(), newBlockDecision("203.0.113.5", 443))
+			assert.NoError(t, err)
+			assert.True(t, result.Success)
+			// 执行期间并发读取统计信息
+			be.GetStats()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, *applied, 1)
+	assert.Len(t, be.GetFirewallRules(), 1)
+	stats := be.GetStats()
+	assert.Equal(t, uint64(16), stats.TotalExecutions)
+	assert.Equal(t, uint64(16), stats.SuccessfulExecutions)
+}
+
+func TestQuarantineExecutor_RepeatedQuarantineIsIdempotent(t *testing.T) {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+	qe := NewQuarantineExecutor(logger).(*QuarantineExecutorImpl)
+
+	decision := &engine.PolicyDecision{
+		Action: engine.PolicyActionQuarantine,
+		Reason: "文件包含身份证号",
+		Context: &engine.DecisionContext{
+			ParsedData: &parser.ParsedData{Protocol: "file", URL: "C:\\Users\\alice\\report.xlsx"},
+		},
+	}
+
+	first, err := qe.ExecuteAction(context.Background(), decision)
+	require.NoError(t, err)
+	require.True(t, first.Success)
+	assert.Equal(t, false, first.Metadata[MetadataAlreadyApplied])
+
+	second, err := qe.ExecuteAction(context.Background(), decision)
+	require.NoError(t, err)
+	require.True(t, second.Success)
+	assert.Equal(t, true, second.Metadata[MetadataAlreadyApplied])
+
+	files := qe.GetQuarantinedFiles()
+	require.Len(t, files, 1)
+	assert.Equal(t, "C:\\Users\\alice\\report.xlsx", files[0].OriginalPath)
+	assert.Equal(t, first.ID, files[0].ID)
+}
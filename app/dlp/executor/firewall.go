@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// EnforcementMode 阻断动作的实际执行方式
+type EnforcementMode string
+
+// 预定义阻断执行方式
+const (
+	EnforcementModeBlock     EnforcementMode = "block"      // 下发防火墙规则阻断连接
+	EnforcementModeAlertOnly EnforcementMode = "alert_only" // 防火墙不可用，阻断降级为告警和审计
+)
+
+// EnforcementCapability 阻断执行能力
+type EnforcementCapability struct {
+	Mode      EnforcementMode `json:"mode"`
+	Tool      string          `json:"tool,omitempty"`   // 使用的防火墙工具
+	Reason    string          `json:"reason,omitempty"` // 降级原因
+	CheckedAt time.Time       `json:"checked_at,omitempty"`
+}
+
+// CanBlock 是否能够下发防火墙规则阻断连接
+func (c EnforcementCapability) CanBlock() bool {
+	return c.Mode == EnforcementModeBlock
+}
+
+// EnforcementReporter 报告阻断执行能力的执行器
+type EnforcementReporter interface {
+	// GetEnforcementCapability 获取阻断执行能力
+	GetEnforcementCapability() EnforcementCapability
+}
+
+// CommandRunner 执行系统命令
+type CommandRunner interface {
+	// LookPath 查找命令的可执行文件路径
+	LookPath(name string) (string, error)
+
+	// CombinedOutput 执行命令并返回合并的标准输出和标准错误
+	CombinedOutput(name string, args ...string) ([]byte, error)
+}
+
+// execCommandRunner 使用os/exec执行系统命令
+type execCommandRunner struct{}
+
+// LookPath 查找命令的可执行文件路径
+func (execCommandRunner) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+// CombinedOutput 执行命令并返回合并的标准输出和标准错误
+func (execCommandRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).CombinedOutput()
+}
+
+// firewallProbes 各平台的防火墙工具及用于检查权限的只读命令
+var firewallProbes = map[string]struct {
+	tool string
+	args []string
+}{
+	"windows": {tool: "netsh", args: []string{"advfirewall", "show", "currentprofile"}},
+	"linux":   {tool: "iptables", args: []string{"-L", "OUTPUT", "-n"}},
+	"darwin":  {tool: "pfctl", args: []string{"-s", "info"}},
+}
+
+// detectEnforcement 检测当前平台能否下发防火墙规则
+// 防火墙工具不存在或只读命令执行失败（通常是权限不足）时返回降级的执行能力
+func detectEnforcement(goos string, runner CommandRunner) EnforcementCapability {
+	capability := EnforcementCapability{
+		Mode:      EnforcementModeAlertOnly,
+		CheckedAt: time.Now(),
+	}
+
+	probe, ok := firewallProbes[goos]
+	if !ok {
+		capability.Reason = fmt.Sprintf("不支持的操作系统: %s", goos)
+		return capability
+	}
+	capability.Tool = probe.tool
+
+	if _, err := runner.LookPath(probe.tool); err != nil {
+		capability.Reason = fmt.Sprintf("未找到防火墙工具 %s: %v", probe.tool, err)
+		return capability
+	}
+
+	if output, err := runner.CombinedOutput(probe.tool, probe.args...); err != nil {
+		capability.Reason = fmt.Sprintf("防火墙工具 %s 不可用（可能缺少权限）: %v, 输出: %s",
+			probe.tool, err, strings.TrimSpace(string(output)))
+		return capability
+	}
+
+	capability.Mode = EnforcementModeBlock
+	return capability
+}
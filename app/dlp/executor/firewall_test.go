@@ -0,0 +1,160 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCommandRunner 模拟系统命令，记录调用次数
+type fakeCommandRunner struct {
+	mu       sync.Mutex
+	missing  bool // 防火墙工具不存在
+	denied   bool // 执行命令时权限不足
+	lookups  int
+	commands [][]string
+}
+
+func (r *fakeCommandRunner) LookPath(name string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups++
+	if r.missing {
+		return "", errors.New("executable file not found in $PATH")
+	}
+	return "/usr/sbin/" + name, nil
+}
+
+func (r *fakeCommandRunner) CombinedOutput(name string, args ...string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, append([]string{name}, args...))
+	if r.denied {
+		return []byte("Permission denied (you must be root)"), errors.New("exit status 4")
+	}
+	return nil, nil
+}
+
+// recordingExecutor 记录执行次数的测试执行器
+type recordingExecutor struct {
+	action engine.PolicyAction
+	mu     sync.Mutex
+	count  int
+}
+
+func (e *recordingExecutor) ExecuteAction(ctx context.Context, decision *engine.PolicyDecision) (*ExecutionResult, error) {
+	e.mu.Lock()
+	e.count++
+	e.mu.Unlock()
+	return &ExecutionResult{Action: e.action, Success: true, Metadata: map[string]interface{}{}}, nil
+}
+
+func (e *recordingExecutor) GetSupportedActions() []engine.PolicyAction {
+	return []engine.PolicyAction{e.action}
+}
+
+func (e *recordingExecutor) CanExecute(actionType engine.PolicyAction) bool {
+	return actionType == e.action
+}
+
+func (e *recordingExecutor) Initialize(config ExecutorConfig) error { return nil }
+
+func (e *recordingExecutor) Cleanup() error { return nil }
+
+func (e *recordingExecutor) GetStats() ExecutorStats {
+	return ExecutorStats{ActionStats: map[string]uint64{}}
+}
+
+func (e *recordingExecutor) executions() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.count
+}
+
+// newEnforcementTestManager 创建注册了阻断、告警和审计执行器的管理器，阻断执行器使用模拟的系统命令
+func newEnforcementTestManager(t *testing.T, runner CommandRunner) (*ExecutionManagerImpl, *[]FirewallRule, *recordingExecutor, *recordingExecutor) {
+	be, applied := newTestBlockExecutor(t)
+	be.goos = "linux"
+	be.runner = runner
+
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+	em := NewExecutionManager(logger, DefaultExecutorConfig()).(*ExecutionManagerImpl)
+
+	alert := &recordingExecutor{action: engine.PolicyActionAlert}
+	audit := &recordingExecutor{action: engine.PolicyActionAudit}
+	require.NoError(t, em.RegisterExecutor(engine.PolicyActionBlock, be))
+	require.NoError(t, em.RegisterExecutor(engine.PolicyActionAlert, alert))
+	require.NoError(t, em.RegisterExecutor(engine.PolicyActionAudit, audit))
+	return em, applied, alert, audit
+}
+
+func TestDetectEnforcement(t *testing.T) {
+	capability := detectEnforcement("linux", &fakeCommandRunner{})
+	assert.True(t, capability.CanBlock())
+	assert.Equal(t, "iptables", capability.Tool)
+
+	capability = detectEnforcement("windows", &fakeCommandRunner{missing: true})
+	assert.Equal(t, EnforcementModeAlertOnly, capability.Mode)
+	assert.Equal(t, "netsh", capability.Tool)
+	assert.Contains(t, capability.Reason, "未找到防火墙工具")
+
+	capability = detectEnforcement("darwin", &fakeCommandRunner{denied: true})
+	assert.False(t, capability.CanBlock())
+	assert.Contains(t, capability.Reason, "Permission denied")
+
+	capability = detectEnforcement("plan9", &fakeCommandRunner{})
+	assert.False(t, capability.CanBlock())
+}
+
+func TestExecutionManager_DegradesBlockWhenFirewallMissing(t *testing.T) {
+	runner := &fakeCommandRunner{missing: true}
+	em, applied, alert, audit := newEnforcementTestManager(t, runner)
+
+	capability := em.GetEnforcementCapability()
+	assert.Equal(t, EnforcementModeAlertOnly, capability.Mode)
+	assert.Equal(t, "iptables", capability.Tool)
+
+	for i := 0; i < 3; i++ {
+		result, err := em.ExecuteDecision(context.Background(), newBlockDecision("203.0.113.5", 443))
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, engine.PolicyActionBlock, result.Action)
+		assert.Equal(t, true, result.Metadata["degraded"])
+		assert.Equal(t, string(EnforcementModeAlertOnly), result.Metadata["enforcement_mode"])
+		assert.Contains(t, result.Metadata, "alert_result")
+		assert.Contains(t, result.Metadata, "audit_result")
+	}
+
+	// 告警和审计照常执行，不再逐包下发规则
+	assert.Equal(t, 3, alert.executions())
+	assert.Equal(t, 3, audit.executions())
+	assert.Empty(t, *applied)
+	assert.Equal(t, uint64(3), em.GetStats().DegradedBlocks)
+
+	// 只在初始化时检测一次
+	assert.Equal(t, 1, runner.lookups)
+	assert.Empty(t, runner.commands)
+}
+
+func TestExecutionManager_BlocksWhenFirewallAvailable(t *testing.T) {
+	runner := &fakeCommandRunner{}
+	em, applied, alert, audit := newEnforcementTestManager(t, runner)
+	assert.True(t, em.GetEnforcementCapability().CanBlock())
+
+	result, err := em.ExecuteDecision(context.Background(), newBlockDecision("203.0.113.5", 443))
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.NotContains(t, result.Metadata, "degraded")
+
+	assert.Len(t, *applied, 1)
+	assert.Equal(t, 0, alert.executions())
+	assert.Equal(t, 0, audit.executions())
+	assert.Equal(t, uint64(0), em.GetStats().DegradedBlocks)
+}
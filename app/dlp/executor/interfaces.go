@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/lomehong/kennel/app/dlp/analyzer"
 	"github.com/lomehong/kennel/app/dlp/engine"
 	"github.com/lomehong/kennel/app/dlp/interceptor"
 	"github.com/lomehong/kennel/pkg/logging"
@@ -19,6 +20,7 @@ type ExecutionResult struct {
 	ProcessingTime time.Duration          `json:"processing_time"`
 	Metadata       map[string]interface{} `json:"metadata"`
 	AffectedData   interface{}            `json:"affected_data,omitempty"`
+	Remediation    string                 `json:"remediation,omitempty"` // 面向响应人员的处置建议
 }
 
 // ExecutorConfig 执行器配置
@@ -33,6 +35,24 @@ type ExecutorConfig struct {
 	EnableMetrics   bool           `yaml:"enable_metrics" json:"enable_metrics"`
 	MetricsInterval time.Duration  `yaml:"metrics_interval" json:"metrics_interval"`
 	Logger          logging.Logger `yaml:"-" json:"-"`
+
+	// RemediationTemplates 修复建议模板，键为动作名称、degraded_block 或 failed
+	RemediationTemplates map[string]string `yaml:"remediation_templates" json:"remediation_templates"`
+
+	// Quarantine 隔离配置
+	Quarantine QuarantineConfig `yaml:"quarantine" json:"quarantine"`
+
+	// Encryption 加密动作的算法和密钥来源配置
+	Encryption EncryptionConfig `yaml:"encryption" json:"encryption"`
+
+	// Notification 告警和通知模板配置
+	Notification NotificationConfig `yaml:"notification" json:"notification"`
+
+	// AuditWriter 审计日志批量写入配置
+	AuditWriter AuditWriterConfig `yaml:"audit_writer" json:"audit_writer"`
+
+	// Masking 告警和审计中敏感数据的脱敏配置
+	Masking analyzer.MaskingConfig `yaml:"masking" json:"masking"`
 }
 
 // DefaultExecutorConfig 返回默认执行器配置
@@ -47,6 +67,11 @@ func DefaultExecutorConfig() ExecutorConfig {
 		BufferSize:      1000,
 		EnableMetrics:   true,
 		MetricsInterval: 1 * time.Minute,
+
+		RemediationTemplates: DefaultRemediationTemplates(),
+		Notification:         DefaultNotificationConfig(),
+		AuditWriter:          DefaultAuditWriterConfig(),
+		Masking:              analyzer.DefaultMaskingConfig(),
 	}
 }
 
@@ -108,6 +133,9 @@ type ExecutionManager interface {
 
 	// HealthCheck 健康检查
 	HealthCheck() error
+
+	// GetEnforcementCapability 获取阻断动作的实际执行能力
+	GetEnforcementCapability() EnforcementCapability
 }
 
 // ManagerStats 管理器统计信息
@@ -115,6 +143,7 @@ type ManagerStats struct {
 	TotalRequests      uint64                   `json:"total_requests"`
 	ProcessedRequests  uint64                   `json:"processed_requests"`
 	FailedRequests     uint64                   `json:"failed_requests"`
+	DegradedBlocks     uint64                   `json:"degraded_blocks"` // 防火墙不可用时降级为告警和审计的阻断决策数
 	AverageTime        time.Duration            `json:"average_time"`
 	ExecutorStats      map[string]ExecutorStats `json:"executor_stats"`
 	ActionDistribution map[string]uint64        `json:"action_distribution"`
@@ -175,6 +204,10 @@ type Alert struct {
 	Metadata   map[string]interface{} `json:"metadata"`
 	Recipients []string               `json:"recipients"`
 	Channels   []string               `json:"channels"`
+
+	Remediation string `json:"remediation,omitempty"` // 处置建议
+
+	variables map[string]string // 创建告警时提取的决策变量，用于渲染通知模板
 }
 
 // AlertLevel 告警级别
@@ -243,6 +276,10 @@ type AuditEvent struct {
 	// 进程信息
 	ProcessInfo *ProcessInfo `json:"process_info,omitempty"`
 
+	Remediation string `json:"remediation,omitempty"` // 处置建议
+
+	Findings []analyzer.MaskedFinding `json:"findings,omitempty"` // 脱敏后的敏感数据发现
+
 	Details  map[string]interface{} `json:"details"`
 	Metadata map[string]interface{} `json:"metadata"`
 }
@@ -285,8 +322,8 @@ type QuarantineExecutor interface {
 	// QuarantineFile 隔离文件
 	QuarantineFile(filePath string, reason string) error
 
-	// RestoreFile 恢复文件
-	RestoreFile(quarantineID string) error
+	// ReleaseFile 凭审批恢复隔离的文件
+	ReleaseFile(approval ReleaseApproval) (*QuarantinedFile, error)
 
 	// GetQuarantinedFiles 获取隔离的文件
 	GetQuarantinedFiles() []QuarantinedFile
@@ -448,6 +485,8 @@ type EncryptionConfig struct {
 	KeySize    int    `json:"key_size"`
 	Mode       string `json:"mode"` // CBC, GCM, etc.
 	KeyPath    string `json:"key_path"`
+	Key        string `json:"key"`     // 十六进制或base64编码的静态密钥
+	KeyEnv     string `json:"key_env"` // 保存密钥的环境变量名
 	CertPath   string `json:"cert_path"`
 	Passphrase string `json:"passphrase"`
 }
@@ -459,4 +498,7 @@ type QuarantineConfig struct {
 	RetentionPeriod  time.Duration `json:"retention_period"`
 	CompressionLevel int           `json:"compression_level"`
 	EncryptFiles     bool          `json:"encrypt_files"`
+
+	// ApproverKeys 可审批恢复隔离文件的操作员公钥，键为密钥标识，值为 Base64 编码的 Ed25519 公钥
+	ApproverKeys map[string]string `json:"approver_keys"`
 }
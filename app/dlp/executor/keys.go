@@ -0,0 +1,354 @@
+package executor
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 密文头部格式: magic(4) | version(1) | keyID长度(1) | keyID | nonce | 密文
+var ciphertextMagic = []byte("DLPE")
+
+const (
+	ciphertextVersion = 1
+	maxKeyIDLength    = 255
+)
+
+var (
+	// ErrKeyNotFound 未找到指定ID的密钥
+	ErrKeyNotFound = errors.New("未找到密钥")
+	// ErrNoActiveKey 没有可用于加密的当前密钥
+	ErrNoActiveKey = errors.New("没有可用的加密密钥")
+	// ErrInvalidCiphertext 密文格式无效
+	ErrInvalidCiphertext = errors.New("无效的密文格式")
+)
+
+// KeyProvider 加密密钥提供者接口
+type KeyProvider interface {
+	// CurrentKey 返回用于加密的当前密钥及其ID
+	CurrentKey() (string, []byte, error)
+
+	// GetKey 根据密钥ID返回解密用的密钥
+	GetKey(keyID string) ([]byte, error)
+}
+
+// StaticKeyProvider 静态密钥提供者，密钥来自配置或文件
+type StaticKeyProvider struct {
+	keyID string
+	key   []byte
+}
+
+// NewStaticKeyProvider 创建静态密钥提供者，密钥ID由密钥指纹生成
+func NewStaticKeyProvider(key []byte) (*StaticKeyProvider, error) {
+	if err := validateAESKey(key); err != nil {
+		return nil, err
+	}
+
+	return &StaticKeyProvider{
+		keyID: keyFingerprint(key),
+		key:   append([]byte(nil), key...),
+	}, nil
+}
+
+// NewFileKeyProvider 从文件加载静态密钥，文件内容可以是原始字节、十六进制或base64编码
+func NewFileKeyProvider(path string) (*StaticKeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+
+	key, err := decodeKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析密钥文件失败: %w", err)
+	}
+
+	return NewStaticKeyProvider(key)
+}
+
+// NewEnvKeyProvider 从环境变量加载静态密钥，值必须是十六进制或base64编码
+func NewEnvKeyProvider(envVar string) (*StaticKeyProvider, error) {
+	value, exists := os.LookupEnv(envVar)
+	if !exists || value == "" {
+		return nil, fmt.Errorf("环境变量未设置: %s", envVar)
+	}
+
+	key, err := decodeEncodedKey(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("解析环境变量 %s 中的密钥失败: %w", envVar, err)
+	}
+
+	return NewStaticKeyProvider(key)
+}
+
+// CurrentKey 返回当前密钥
+func (sp *StaticKeyProvider) CurrentKey() (string, []byte, error) {
+	return sp.keyID, sp.key, nil
+}
+
+// GetKey 根据密钥ID返回密钥
+func (sp *StaticKeyProvider) GetKey(keyID string) ([]byte, error) {
+	if keyID != sp.keyID {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	return sp.key, nil
+}
+
+// KeyRing 可轮换的密钥环
+// 新数据始终使用当前密钥加密，旧密钥保留在密钥环中用于解密历史数据
+type KeyRing struct {
+	keys     map[string][]byte
+	activeID string
+	mu       sync.RWMutex
+}
+
+// NewKeyRing 创建密钥环
+func NewKeyRing() *KeyRing {
+	return &KeyRing{
+		keys: make(map[string][]byte),
+	}
+}
+
+// AddKey 添加密钥，不改变当前密钥；密钥环为空时该密钥成为当前密钥
+func (kr *KeyRing) AddKey(keyID string, key []byte) error {
+	if keyID == "" || len(keyID) > maxKeyIDLength {
+		return fmt.Errorf("密钥ID长度无效: %d", len(keyID))
+	}
+	if err := validateAESKey(key); err != nil {
+		return err
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if _, exists := kr.keys[keyID]; exists {
+		return fmt.Errorf("密钥ID已存在: %s", keyID)
+	}
+
+	kr.keys[keyID] = append([]byte(nil), key...)
+	if kr.activeID == "" {
+		kr.activeID = keyID
+	}
+	return nil
+}
+
+// Rotate 添加新密钥并将其设为当前密钥
+func (kr *KeyRing) Rotate(keyID string, key []byte) error {
+	if err := kr.AddKey(keyID, key); err != nil {
+		return err
+	}
+	return kr.SetActive(keyID)
+}
+
+// SetActive 设置当前密钥
+func (kr *KeyRing) SetActive(keyID string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if _, exists := kr.keys[keyID]; !exists {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	kr.activeID = keyID
+	return nil
+}
+
+// RemoveKey 移除密钥，当前密钥不能移除
+func (kr *KeyRing) RemoveKey(keyID string) error {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	if keyID == kr.activeID {
+		return fmt.Errorf("不能移除当前密钥: %s", keyID)
+	}
+	if _, exists := kr.keys[keyID]; !exists {
+		return fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	delete(kr.keys, keyID)
+	return nil
+}
+
+// KeyIDs 返回密钥环中的所有密钥ID
+func (kr *KeyRing) KeyIDs() []string {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	ids := make([]string, 0, len(kr.keys))
+	for id := range kr.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// CurrentKey 返回当前密钥
+func (kr *KeyRing) CurrentKey() (string, []byte, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	if kr.activeID == "" {
+		return "", nil, ErrNoActiveKey
+	}
+	return kr.activeID, kr.keys[kr.activeID], nil
+}
+
+// GetKey 根据密钥ID返回密钥
+func (kr *KeyRing) GetKey(keyID string) ([]byte, error) {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	key, exists := kr.keys[keyID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+	return key, nil
+}
+
+// NewKeyProviderFromConfig 根据加密配置创建密钥提供者
+// 优先级: 配置中的密钥 > 环境变量 > 密钥文件
+func NewKeyProviderFromConfig(config *EncryptionConfig) (KeyProvider, error) {
+	if config == nil {
+		return nil, ErrNoActiveKey
+	}
+
+	switch {
+	case config.Key != "":
+		key, err := decodeEncodedKey(config.Key)
+		if err != nil {
+			return nil, fmt.Errorf("解析配置中的密钥失败: %w", err)
+		}
+		return NewStaticKeyProvider(key)
+	case config.KeyEnv != "":
+		return NewEnvKeyProvider(config.KeyEnv)
+	case config.KeyPath != "":
+		return NewFileKeyProvider(config.KeyPath)
+	default:
+		return nil, ErrNoActiveKey
+	}
+}
+
+// sealWithKey 使用AES-GCM加密数据，并在密文头部写入密钥ID
+func sealWithKey(keyID string, key, data []byte) ([]byte, error) {
+	if keyID == "" || len(keyID) > maxKeyIDLength {
+		return nil, fmt.Errorf("密钥ID长度无效: %d", len(keyID))
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("生成nonce失败: %w", err)
+	}
+
+	header := make([]byte, 0, len(ciphertextMagic)+2+len(keyID))
+	header = append(header, ciphertextMagic...)
+	header = append(header, ciphertextVersion, byte(len(keyID)))
+	header = append(header, keyID...)
+
+	// 头部作为附加认证数据，防止密钥ID被篡改
+	out := append(header, nonce...)
+	return gcm.Seal(out, nonce, data, header), nil
+}
+
+// parseCiphertextHeader 解析密文头部，返回密钥ID、头部和剩余数据
+func parseCiphertextHeader(data []byte) (string, []byte, []byte, error) {
+	prefix := len(ciphertextMagic) + 2
+	if len(data) < prefix || !bytes.Equal(data[:len(ciphertextMagic)], ciphertextMagic) {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+	if data[len(ciphertextMagic)] != ciphertextVersion {
+		return "", nil, nil, fmt.Errorf("%w: 不支持的版本 %d", ErrInvalidCiphertext, data[len(ciphertextMagic)])
+	}
+
+	idLen := int(data[len(ciphertextMagic)+1])
+	if idLen == 0 || len(data) < prefix+idLen {
+		return "", nil, nil, ErrInvalidCiphertext
+	}
+
+	headerEnd := prefix + idLen
+	return string(data[prefix:headerEnd]), data[:headerEnd], data[headerEnd:], nil
+}
+
+// openWithKey 使用密钥解密去掉头部后的数据，头部作为附加认证数据校验
+func openWithKey(key, header, rest []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, ciphertext := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, fmt.Errorf("解密失败: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// newGCM 创建AES-GCM实例
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("创建AES cipher失败: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("创建GCM失败: %w", err)
+	}
+
+	return gcm, nil
+}
+
+// validateAESKey 检查密钥长度，只接受支持的算法（AES-128、AES-256）使用的长度
+func validateAESKey(key []byte) error {
+	switch len(key) {
+	case 16, 32:
+		return nil
+	default:
+		return fmt.Errorf("无效的AES密钥长度: %d", len(key))
+	}
+}
+
+// keyFingerprint 根据密钥生成密钥ID
+func keyFingerprint(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// decodeKey 解析密钥文件内容，优先按编码文本解析，否则视为原始字节
+func decodeKey(data []byte) ([]byte, error) {
+	if key, err := decodeEncodedKey(strings.TrimSpace(string(data))); err == nil {
+		return key, nil
+	}
+	if err := validateAESKey(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// decodeEncodedKey 解析十六进制或base64编码的密钥
+func decodeEncodedKey(value string) ([]byte, error) {
+	if key, err := hex.DecodeString(value); err == nil && validateAESKey(key) == nil {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(value); err == nil && validateAESKey(key) == nil {
+		return key, nil
+	}
+	return nil, errors.New("密钥必须是16或32字节的十六进制或base64编码")
+}
@@ -0,0 +1,216 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// EncryptExecutorImpl 必须满足 EncryptExecutor 接口
+var _ EncryptExecutor = (*EncryptExecutorImpl)(nil)
+
+func newTestEncryptExecutor(t *testing.T, provider KeyProvider) *EncryptExecutorImpl {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	ee := NewEncryptExecutor(logger).(*EncryptExecutorImpl)
+	if provider != nil {
+		ee.SetKeyProvider(provider)
+	}
+	return ee
+}
+
+func testKey(fill byte, size int) []byte {
+	return bytes.Repeat([]byte{fill}, size)
+}
+
+func TestEncryptExecutor_RoundTripWithProviders(t *testing.T) {
+	plaintext := []byte("身份证号: 11010119900307777X")
+
+	keyFile := filepath.Join(t.TempDir(), "dlp.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(hex.EncodeToString(testKey(0x11, 32))+"\n"), 0600))
+
+	t.Setenv("DLP_TEST_ENCRYPTION_KEY", base64.StdEncoding.EncodeToString(testKey(0x22, 32)))
+
+	staticProvider, err := NewStaticKeyProvider(testKey(0x33, 32))
+	require.NoError(t, err)
+	fileProvider, err := NewFileKeyProvider(keyFile)
+	require.NoError(t, err)
+	envProvider, err := NewEnvKeyProvider("DLP_TEST_ENCRYPTION_KEY")
+	require.NoError(t, err)
+	keyRing := NewKeyRing()
+	require.NoError(t, keyRing.AddKey("2025-01", testKey(0x44, 32)))
+
+	providers := map[string]KeyProvider{
+		"static":  staticProvider,
+		"file":    fileProvider,
+		"env":     envProvider,
+		"keyring": keyRing,
+	}
+
+	for name, provider := range providers {
+		t.Run(name, func(t *testing.T) {
+			ee := newTestEncryptExecutor(t, provider)
+
+			ciphertext, err := ee.Encrypt(plaintext)
+			require.NoError(t, err)
+			assert.False(t, bytes.Contains(ciphertext, plaintext))
+
+			decrypted, err := ee.Decrypt(ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, plaintext, decrypted)
+		})
+	}
+}
+
+func TestEncryptExecutor_KeyRingSelectsKeyByID(t *testing.T) {
+	keyRing := NewKeyRing()
+	require.NoError(t, keyRing.AddKey("k1", testKey(0x01, 32)))
+	ee := newTestEncryptExecutor(t, keyRing)
+
+	oldCiphertext, err := ee.Encrypt([]byte("旧数据"))
+	require.NoError(t, err)
+
+	require.NoError(t, keyRing.Rotate("k2", testKey(0x02, 32)))
+	newCiphertext, err := ee.Encrypt([]byte("新数据"))
+	require.NoError(t, err)
+
+	oldID, _, _, err := parseCiphertextHeader(oldCiphertext)
+	require.NoError(t, err)
+	newID, _, _, err := parseCiphertextHeader(newCiphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", oldID)
+	assert.Equal(t, "k2", newID)
+
+	// 轮换后仍可用旧密钥解密历史数据
+	decrypted, err := ee.Decrypt(oldCiphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("旧数据"), decrypted)
+
+	decrypted, err = ee.Decrypt(newCiphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("新数据"), decrypted)
+
+	// 移除旧密钥后无法再解密旧数据
+	require.NoError(t, keyRing.RemoveKey("k1"))
+	_, err = ee.Decrypt(oldCiphertext)
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+}
+
+func TestEncryptExecutor_RejectsTamperedOrUnknownCiphertext(t *testing.T) {
+	provider, err := NewStaticKeyProvider(testKey(0x55, 32))
+	require.NoError(t, err)
+	ee := newTestEncryptExecutor(t, provider)
+
+	ciphertext, err := ee.Encrypt([]byte("敏感数据"))
+	require.NoError(t, err)
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, err = ee.Decrypt(tampered)
+	assert.Error(t, err)
+
+	_, err = ee.Decrypt([]byte("not encrypted"))
+	assert.True(t, errors.Is(err, ErrInvalidCiphertext))
+
+	other, err := NewStaticKeyProvider(testKey(0x66, 32))
+	require.NoError(t, err)
+	_, err = newTestEncryptExecutor(t, other).Decrypt(ciphertext)
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+}
+
+func TestEncryptExecutor_ConfigAndAlgorithm(t *testing.T) {
+	ee := newTestEncryptExecutor(t, nil)
+
+	_, err := ee.Encrypt([]byte("data"))
+	assert.True(t, errors.Is(err, ErrNoActiveKey), "未配置密钥时不应使用随机密钥加密")
+
+	require.NoError(t, ee.SetEncryptionConfig(&EncryptionConfig{
+		Algorithm: "AES-128",
+		Key:       hex.EncodeToString(testKey(0x77, 16)),
+	}))
+
+	ciphertext, err := ee.Encrypt([]byte("data"))
+	require.NoError(t, err)
+	decrypted, err := ee.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), decrypted)
+
+	_, err = ee.EncryptData([]byte("data"), "AES-256")
+	assert.Error(t, err, "密钥长度与算法不匹配")
+
+	assert.Error(t, ee.SetEncryptionConfig(&EncryptionConfig{KeyEnv: "DLP_TEST_MISSING_KEY"}))
+}
+
+func TestEncryptExecutor_ExecuteActionEncryptsData(t *testing.T) {
+	body := []byte("客户名单: 张三 110101199003077777")
+	decision := &engine.PolicyDecision{
+		ID:     "decision-1",
+		Action: engine.PolicyActionEncrypt,
+		Context: &engine.DecisionContext{
+			ParsedData: &parser.ParsedData{Protocol: "http", Body: body},
+		},
+	}
+
+	// 未配置密钥时加密失败，不能报告成功
+	ee := newTestEncryptExecutor(t, nil)
+	require.NoError(t, ee.Initialize(DefaultExecutorConfig()))
+	result, err := ee.ExecuteAction(context.Background(), decision)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.True(t, errors.Is(result.Error, ErrNoActiveKey))
+	assert.Equal(t, uint64(1), ee.GetStats().FailedExecutions)
+
+	// 通过执行器配置加载密钥
+	config := DefaultExecutorConfig()
+	config.Encryption = EncryptionConfig{Algorithm: "AES-256-GCM", Key: hex.EncodeToString(testKey(0x88, 32))}
+	require.NoError(t, ee.Initialize(config))
+
+	result, err = ee.ExecuteAction(context.Background(), decision)
+	require.NoError(t, err)
+	require.True(t, result.Success)
+	assert.Equal(t, "completed", result.Metadata["encryption_status"])
+	assert.Equal(t, keyFingerprint(testKey(0x88, 32)), result.Metadata["key_id"])
+
+	ciphertext, ok := result.AffectedData.([]byte)
+	require.True(t, ok)
+	assert.False(t, bytes.Contains(ciphertext, body))
+	decrypted, err := ee.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, body, decrypted)
+
+	// 没有可加密的数据
+	result, err = ee.ExecuteAction(context.Background(), &engine.PolicyDecision{Action: engine.PolicyActionEncrypt})
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+
+	// 配置的密钥无效时初始化失败
+	config.Encryption = EncryptionConfig{KeyEnv: "DLP_TEST_MISSING_KEY"}
+	assert.Error(t, NewEncryptExecutor(ee.logger).Initialize(config))
+}
+
+func TestValidateAESKey_RejectsUnsupportedLengths(t *testing.T) {
+	for _, size := range []int{16, 32} {
+		assert.NoError(t, validateAESKey(testKey(0x01, size)))
+	}
+
+	// 支持的算法中没有 AES-192，24 字节的密钥在加密时必然失败，加载时直接拒绝
+	for _, size := range []int{0, 8, 24, 48} {
+		assert.Error(t, validateAESKey(testKey(0x01, size)), "size %d", size)
+	}
+	_, err := NewStaticKeyProvider(testKey(0x01, 24))
+	assert.Error(t, err)
+	_, err = decodeEncodedKey(hex.EncodeToString(testKey(0x01, 24)))
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package executor
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,6 +20,7 @@ type ExecutionManagerImpl struct {
 	stats               ManagerStats
 	metricsCollector    MetricsCollector
 	notificationService NotificationService
+	notifications       *NotificationRenderer
 	running             int32
 	mu                  sync.RWMutex
 }
@@ -31,6 +33,7 @@ func NewExecutionManager(logger logging.Logger, config ExecutorConfig) Execution
 		logger:              logger,
 		metricsCollector:    NewMetricsCollector(),
 		notificationService: NewNotificationService(logger),
+		notifications:       NewNotificationRenderer(config.Notification, logger),
 		stats: ManagerStats{
 			ExecutorStats:      make(map[string]ExecutorStats),
 			ActionDistribution: make(map[string]uint64),
@@ -82,8 +85,14 @@ func (em *ExecutionManagerImpl) ExecuteDecision(ctx context.Context, decision *e
 		return nil, em.stats.LastError
 	}
 
-	// 执行动作
-	result, err := em.executeWithRetry(ctx, executor, decision)
+	// 执行动作，防火墙不可用时阻断降级为告警和审计
+	var result *ExecutionResult
+	var err error
+	if decision.Action == engine.PolicyActionBlock && !em.GetEnforcementCapability().CanBlock() {
+		result, err = em.executeDegradedBlock(ctx, decision)
+	} else {
+		result, err = em.executeWithRetry(ctx, executor, decision)
+	}
 	if err != nil {
 		atomic.AddUint64(&em.stats.FailedRequests, 1)
 		em.stats.LastError = err
@@ -207,6 +216,75 @@ func (em *ExecutionManagerImpl) HealthCheck() error {
 	return nil
 }
 
+// GetEnforcementCapability 获取阻断动作的实际执行能力
+func (em *ExecutionManagerImpl) GetEnforcementCapability() EnforcementCapability {
+	executor, exists := em.GetExecutor(engine.PolicyActionBlock)
+	if !exists {
+		return EnforcementCapability{Mode: EnforcementModeAlertOnly, Reason: "未注册阻断执行器"}
+	}
+	if reporter, ok := executor.(EnforcementReporter); ok {
+		return reporter.GetEnforcementCapability()
+	}
+	return EnforcementCapability{Mode: EnforcementModeBlock}
+}
+
+// executeDegradedBlock 防火墙不可用时以告警和审计代替阻断
+// 告警和审计都执行成功时结果视为成功，结果元数据记录降级原因和各动作的执行结果
+func (em *ExecutionManagerImpl) executeDegradedBlock(ctx context.Context, decision *engine.PolicyDecision) (*ExecutionResult, error) {
+	startTime := time.Now()
+	atomic.AddUint64(&em.stats.DegradedBlocks, 1)
+	capability := em.GetEnforcementCapability()
+
+	result := &ExecutionResult{
+		ID:        fmt.Sprintf("degraded_block_%d", time.Now().UnixNano()),
+		Timestamp: time.Now(),
+		Action:    engine.PolicyActionBlock,
+		Success:   true,
+		Metadata: map[string]interface{}{
+			"degraded":         true,
+			"enforcement_mode": string(capability.Mode),
+			"degrade_reason":   capability.Reason,
+		},
+	}
+	vars := remediationVariables(decision)
+	vars["degrade_reason"] = capability.Reason
+	result.Remediation = renderRemediation(em.config.RemediationTemplates, RemediationKeyDegradedBlock, vars)
+
+	// 告警和审计携带降级阻断的处置建议
+	ctx = withRemediation(ctx, result.Remediation)
+
+	var errs []string
+	for _, action := range []engine.PolicyAction{engine.PolicyActionAlert, engine.PolicyActionAudit} {
+		executor, exists := em.GetExecutor(action)
+		if !exists {
+			errs = append(errs, fmt.Sprintf("未找到执行器: %s", action.String()))
+			continue
+		}
+
+		actionResult, err := em.executeWithRetry(ctx, executor, decision)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", action.String(), err))
+			continue
+		}
+		result.Metadata[action.String()+"_result"] = actionResult
+		if !actionResult.Success {
+			errs = append(errs, fmt.Sprintf("%s: %v", action.String(), actionResult.Error))
+		}
+	}
+
+	if len(errs) > 0 {
+		result.Success = false
+		result.Error = fmt.Errorf("降级执行失败: %s", strings.Join(errs, "; "))
+	}
+	result.ProcessingTime = time.Since(startTime)
+
+	em.logger.Debug("防火墙不可用，阻断决策已降级为告警和审计",
+		"decision_id", decision.ID,
+		"reason", capability.Reason)
+
+	return result, nil
+}
+
 // executeWithRetry 带重试的执行
 func (em *ExecutionManagerImpl) executeWithRetry(ctx context.Context, executor ActionExecutor, decision *engine.PolicyDecision) (*ExecutionResult, error) {
 	retryPolicy := DefaultRetryPolicy()
@@ -294,18 +372,20 @@ func (em *ExecutionManagerImpl) shouldSendNotification(decision *engine.PolicyDe
 
 // sendNotification 发送通知
 func (em *ExecutionManagerImpl) sendNotification(decision *engine.PolicyDecision, result *ExecutionResult) {
+	title, message := em.buildNotification(decision, result)
 	notification := &Notification{
 		ID:         fmt.Sprintf("notif_%d", time.Now().UnixNano()),
-		Title:      fmt.Sprintf("DLP动作执行: %s", decision.Action.String()),
-		Message:    em.buildNotificationMessage(decision, result),
+		Title:      title,
+		Message:    message,
 		Level:      em.getNotificationLevel(decision),
-		Channel:    "default",
+		Channel:    NotificationChannelDefault,
 		Recipients: []string{"admin@example.com"},
 		Metadata: map[string]interface{}{
 			"decision_id": decision.ID,
 			"action":      decision.Action.String(),
 			"risk_level":  decision.RiskLevel.String(),
 			"success":     result.Success,
+			"remediation": result.Remediation,
 		},
 		Timestamp: time.Now(),
 	}
@@ -315,18 +395,17 @@ func (em *ExecutionManagerImpl) sendNotification(decision *engine.PolicyDecision
 	}
 }
 
-// buildNotificationMessage 构建通知消息
-func (em *ExecutionManagerImpl) buildNotificationMessage(decision *engine.PolicyDecision, result *ExecutionResult) string {
-	if result.Success {
-		return fmt.Sprintf("成功执行%s动作，风险级别: %s，匹配规则: %d个",
-			decision.Action.String(),
-			decision.RiskLevel.String(),
-			len(decision.MatchedRules))
-	} else {
-		return fmt.Sprintf("执行%s动作失败: %v",
-			decision.Action.String(),
-			result.Error)
-	}
+// buildNotification 按通知模板构建通知标题和消息，附带处置建议
+func (em *ExecutionManagerImpl) buildNotification(decision *engine.PolicyDecision, result *ExecutionResult) (string, string) {
+	vars := notificationVariables(decision)
+	vars["result_id"] = result.ID
+	vars["success"] = fmt.Sprintf("%t", result.Success)
+	vars["level"] = em.getNotificationLevel(decision).String()
+	vars["remediation"] = result.Remediation
+	if result.Error != nil {
+		vars["error"] = result.Error.Error()
+	}
+	return em.notifications.Render(NotificationChannelDefault, vars)
 }
 
 // getNotificationLevel 获取通知级别
@@ -375,8 +454,9 @@ func (em *ExecutionManagerImpl) registerDefaultExecutors() error {
 		return fmt.Errorf("注册加密执行器失败: %w", err)
 	}
 
-	// 注册隔离执行器
+	// 注册隔离执行器，恢复隔离文件的结果写入审计
 	quarantineExecutor := NewQuarantineExecutor(em.logger)
+	quarantineExecutor.(*QuarantineExecutorImpl).SetAuditRecorder(auditExecutor.(*AuditExecutorImpl))
 	if err := em.RegisterExecutor(engine.PolicyActionQuarantine, quarantineExecutor); err != nil {
 		return fmt.Errorf("注册隔离执行器失败: %w", err)
 	}
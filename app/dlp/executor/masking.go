@@ -0,0 +1,33 @@
+package executor
+
+import (
+	"strings"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/app/dlp/engine"
+)
+
+// decisionFindings 返回决策全部分析结果中发现的脱敏预览
+func decisionFindings(masker *analyzer.Masker, decision *engine.PolicyDecision) []analyzer.MaskedFinding {
+	if decision.Context == nil {
+		return nil
+	}
+	return masker.Findings(decision.Context.AnalysisResults()...)
+}
+
+// redactDecisionText 将文本中出现的决策发现原始值替换为脱敏预览
+func redactDecisionText(masker *analyzer.Masker, decision *engine.PolicyDecision, text string) string {
+	if decision.Context == nil {
+		return text
+	}
+	return masker.Redact(text, decision.Context.AnalysisResults()...)
+}
+
+// formatFindings 将脱敏预览格式化为“类型: 预览”列表，用于日志和通知模板
+func formatFindings(findings []analyzer.MaskedFinding) string {
+	parts := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		parts = append(parts, finding.Type+": "+finding.Preview)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// maskingCases 各类型敏感数据的原始值和按默认规则脱敏后的预览
+var maskingCases = []struct {
+	dataType string
+	value    string
+	preview  string
+}{
+	{"credit_card", "4111111111111111", "****1111"},
+	{"phone", "13812345678", "138****5678"},
+	{"id_card", "110101199003071234", "110****1234"},
+	{"email", "alice@example.com", "al****"},
+	{"password", "hunter2hunter2", "****"},
+	{"bank_account", "6222020200112233", "****2233"}, // 未单独配置的类型使用默认规则
+	{analyzer.FindingTypeEDM, "customers#42", "customers#42"},
+}
+
+// newMaskingDecision 创建包含全部测试类型敏感数据的决策，请求数据中带有原始值
+func newMaskingDecision() *engine.PolicyDecision {
+	decision := newNotificationDecision()
+	items := make([]*analyzer.SensitiveDataInfo, 0, len(maskingCases))
+	for _, c := range maskingCases {
+		items = append(items, &analyzer.SensitiveDataInfo{Type: c.dataType, Value: c.value, Confidence: 0.9})
+	}
+	decision.Context.AnalysisResult = &analyzer.AnalysisResult{ID: "analysis_1", SensitiveData: items}
+	decision.Context.ParsedData = &parser.ParsedData{
+		Protocol:    "http",
+		Method:      "POST",
+		URL:         "https://example.com/upload?phone=13812345678",
+		ContentType: "text/plain",
+		Body:        []byte("card 4111111111111111, id 110101199003071234, mail alice@example.com"),
+	}
+	decision.Context.PacketInfo.ProcessInfo = &interceptor.ProcessInfo{PID: 4242, ProcessName: "curl"}
+	return decision
+}
+
+// assertMaskedFindings 检查每种类型的发现都按规则脱敏
+func assertMaskedFindings(t *testing.T, findings []analyzer.MaskedFinding) {
+	t.Helper()
+	require.Len(t, findings, len(maskingCases))
+	for i, c := range maskingCases {
+		assert.Equal(t, c.dataType, findings[i].Type)
+		assert.Equal(t, c.preview, findings[i].Preview, "%s 应按规则脱敏", c.dataType)
+	}
+}
+
+// assertNoRawValues 检查输出中不包含需要脱敏的原始值
+func assertNoRawValues(t *testing.T, output string) {
+	t.Helper()
+	for _, c := range maskingCases {
+		if c.value != c.preview {
+			assert.NotContains(t, output, c.value, "%s 的原始值不应出现在输出中", c.dataType)
+		}
+	}
+}
+
+func TestAuditExecutor_MasksFindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dlp_audit.log")
+	ae := NewAuditExecutor(newTestAuditLogger(t)).(*AuditExecutorImpl)
+	config := DefaultExecutorConfig()
+	config.AuditWriter = AuditWriterConfig{Path: path, FlushInterval: time.Hour}
+	require.NoError(t, ae.Initialize(config))
+
+	result, err := ae.ExecuteAction(context.Background(), newMaskingDecision())
+	require.NoError(t, err)
+	require.True(t, result.Success)
+	event := result.AffectedData.(*AuditEvent)
+	assertMaskedFindings(t, event.Findings)
+	assert.Equal(t, "card ****1111, id 110****1234, mail al****", event.RequestData, "请求数据中的原始值应替换为预览")
+	assert.Equal(t, "https://example.com/upload?phone=138****5678", event.RequestURL)
+	require.NoError(t, ae.Cleanup())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records := decodeAuditLines(t, data)
+	require.Len(t, records, 1)
+
+	findings, ok := records[0]["findings"].([]interface{})
+	require.True(t, ok, "审计记录应包含脱敏后的发现")
+	require.Len(t, findings, len(maskingCases))
+	for i, c := range maskingCases {
+		finding := findings[i].(map[string]interface{})
+		assert.Equal(t, c.dataType, finding["type"])
+		assert.Equal(t, c.preview, finding["preview"], "%s 应按规则脱敏", c.dataType)
+	}
+	assertNoRawValues(t, string(data))
+}
+
+func TestAlertExecutor_MasksFindings(t *testing.T) {
+	ae := NewAlertExecutor(newTestLogger(t)).(*AlertExecutorImpl)
+	require.NoError(t, ae.Initialize(DefaultExecutorConfig()))
+
+	alert := ae.newAlert(context.Background(), "alert_1", newMaskingDecision())
+	findings, ok := alert.Metadata["findings"].([]analyzer.MaskedFinding)
+	require.True(t, ok, "告警应包含脱敏后的发现")
+	assertMaskedFindings(t, findings)
+
+	_, body := ae.notifications.Render(NotificationChannelEmail, alertVariables(alert))
+	assert.Contains(t, body, "credit_card: ****1111")
+	assert.Contains(t, body, "phone: 138****5678")
+	assertNoRawValues(t, body)
+}
+
+func TestMasking_CustomRulesAndDisabled(t *testing.T) {
+	config := DefaultExecutorConfig()
+	config.Masking.MaskChar = "#"
+	config.Masking.Rules = map[string]analyzer.MaskingRule{"credit_card": {KeepPrefix: 4, KeepSuffix: 4}}
+
+	ae := NewAlertExecutor(newTestLogger(t)).(*AlertExecutorImpl)
+	require.NoError(t, ae.Initialize(config))
+	findings := ae.newAlert(context.Background(), "alert_1", newMaskingDecision()).Metadata["findings"].([]analyzer.MaskedFinding)
+	assert.Equal(t, "4111####1111", findings[0].Preview)
+	assert.Equal(t, "####5678", findings[1].Preview, "未配置的类型应使用默认规则")
+
+	// 关闭脱敏后输出原始值
+	config.Masking.Enabled = false
+	require.NoError(t, ae.Initialize(config))
+	findings = ae.newAlert(context.Background(), "alert_2", newMaskingDecision()).Metadata["findings"].([]analyzer.MaskedFinding)
+	for i, c := range maskingCases {
+		assert.Equal(t, c.value, findings[i].Preview)
+	}
+}
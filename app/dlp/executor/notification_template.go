@@ -0,0 +1,374 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// 通知渠道，每个渠道在每种语言下有一个默认模板
+const (
+	// NotificationChannelAlert 告警本身的标题和消息
+	NotificationChannelAlert = "alert"
+	// NotificationChannelEmail 邮件告警的主题和正文
+	NotificationChannelEmail = "email"
+	// NotificationChannelWebhook Webhook告警负载中的 text 字段
+	NotificationChannelWebhook = "webhook"
+	// NotificationChannelSMS 短信告警内容
+	NotificationChannelSMS = "sms"
+	// NotificationChannelDefault 执行管理器发送的动作执行通知
+	NotificationChannelDefault = "default"
+)
+
+// DefaultNotificationLocale 默认通知语言
+const DefaultNotificationLocale = "zh-CN"
+
+// NotificationTemplate 通知模板，主题和正文使用 Go text/template 语法
+// 模板变量为字符串，通过 {{.变量名}} 引用，不存在的变量展开为空字符串
+type NotificationTemplate struct {
+	Subject string `yaml:"subject" json:"subject"`
+	Body    string `yaml:"body" json:"body"`
+}
+
+// NotificationConfig 通知模板配置
+type NotificationConfig struct {
+	// Locale 通知语言，如 zh-CN、en-US
+	Locale string `yaml:"locale" json:"locale"`
+
+	// Templates 自定义模板，键为语言和渠道；未配置的模板使用默认模板
+	Templates map[string]map[string]NotificationTemplate `yaml:"templates" json:"templates"`
+}
+
+// DefaultNotificationConfig 返回默认通知模板配置
+func DefaultNotificationConfig() NotificationConfig {
+	return NotificationConfig{Locale: DefaultNotificationLocale}
+}
+
+// DefaultNotificationTemplates 返回默认通知模板，键为语言和渠道
+func DefaultNotificationTemplates() map[string]map[string]NotificationTemplate {
+	return map[string]map[string]NotificationTemplate{
+		"zh-CN": {
+			NotificationChannelAlert: {
+				Subject: "DLP安全告警",
+				Body:    "检测到{{.risk_level}}级别的安全风险: {{.reason}}",
+			},
+			NotificationChannelEmail: {
+				Subject: "[DLP告警] {{.level}} - {{.title}}",
+				Body: `告警标题: {{.title}}
+告警级别: {{.level}}
+告警时间: {{.timestamp}}
+告警来源: {{.source}}
+告警消息: {{.message}}
+{{if .remediation}}处置建议: {{.remediation}}
+{{end}}
+{{if .tags}}标签: {{.tags}}
+{{end}}详细信息:
+  决策ID: {{.decision_id}}
+  风险分数: {{.risk_score}}
+  置信度: {{.confidence}}
+{{if .rule_ids}}  命中规则: {{.rule_ids}}
+{{end}}{{if .destination}}  目标地址: {{.destination}}
+{{end}}{{if .process_name}}  进程: {{.process_name}} ({{.process_id}})
+{{end}}{{if .username}}  用户: {{.username}}
+{{end}}{{if .findings}}  敏感数据: {{.findings}}
+{{end}}
+---
+此邮件由DLP系统自动发送，请勿回复。`,
+			},
+			NotificationChannelWebhook: {
+				Subject: "{{.title}}",
+				Body:    "[{{.level}}] {{.message}}{{if .remediation}}。处置建议: {{.remediation}}{{end}}",
+			},
+			NotificationChannelSMS: {
+				Body: "【DLP告警】{{.level}}: {{.message}}",
+			},
+			NotificationChannelDefault: {
+				Subject: "DLP动作执行: {{.action}}",
+				Body:    "{{if eq .success \"true\"}}成功执行{{.action}}动作，风险级别: {{.risk_level}}，匹配规则: {{.rule_count}}个{{else}}执行{{.action}}动作失败: {{.error}}{{end}}{{if .remediation}}。处置建议: {{.remediation}}{{end}}",
+			},
+		},
+		"en-US": {
+			NotificationChannelAlert: {
+				Subject: "DLP Security Alert",
+				Body:    "Detected a {{.risk_level}} risk: {{.reason}}",
+			},
+			NotificationChannelEmail: {
+				Subject: "[DLP Alert] {{.level}} - {{.title}}",
+				Body: `Title: {{.title}}
+Level: {{.level}}
+Time: {{.timestamp}}
+Source: {{.source}}
+Message: {{.message}}
+{{if .remediation}}Remediation: {{.remediation}}
+{{end}}
+{{if .tags}}Tags: {{.tags}}
+{{end}}Details:
+  Decision ID: {{.decision_id}}
+  Risk score: {{.risk_score}}
+  Confidence: {{.confidence}}
+{{if .rule_ids}}  Matched rules: {{.rule_ids}}
+{{end}}{{if .destination}}  Destination: {{.destination}}
+{{end}}{{if .process_name}}  Process: {{.process_name}} ({{.process_id}})
+{{end}}{{if .username}}  User: {{.username}}
+{{end}}{{if .findings}}  Sensitive data: {{.findings}}
+{{end}}
+---
+This message was sent automatically by the DLP system. Please do not reply.`,
+			},
+			NotificationChannelWebhook: {
+				Subject: "{{.title}}",
+				Body:    "[{{.level}}] {{.message}}{{if .remediation}}. Remediation: {{.remediation}}{{end}}",
+			},
+			NotificationChannelSMS: {
+				Body: "[DLP Alert] {{.level}}: {{.message}}",
+			},
+			NotificationChannelDefault: {
+				Subject: "DLP action executed: {{.action}}",
+				Body:    "{{if eq .success \"true\"}}Executed {{.action}} action, risk level: {{.risk_level}}, matched rules: {{.rule_count}}{{else}}Failed to execute {{.action}} action: {{.error}}{{end}}{{if .remediation}}. Remediation: {{.remediation}}{{end}}",
+			},
+		},
+	}
+}
+
+// notificationFuncs 模板中可用的函数
+var notificationFuncs = template.FuncMap{
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// parsedNotificationTemplate 解析后的通知模板
+type parsedNotificationTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// NotificationRenderer 通知模板渲染器
+type NotificationRenderer struct {
+	locale    string
+	templates map[string]map[string]*parsedNotificationTemplate // 语言 -> 渠道 -> 模板
+	defaults  map[string]map[string]*parsedNotificationTemplate
+	logger    logging.Logger
+}
+
+// NewNotificationRenderer 创建通知模板渲染器
+// 自定义模板解析失败时记录警告并使用默认模板
+func NewNotificationRenderer(config NotificationConfig, logger logging.Logger) *NotificationRenderer {
+	locale := config.Locale
+	if locale == "" {
+		locale = DefaultNotificationLocale
+	}
+
+	r := &NotificationRenderer{
+		locale:    locale,
+		templates: make(map[string]map[string]*parsedNotificationTemplate),
+		defaults:  make(map[string]map[string]*parsedNotificationTemplate),
+		logger:    logger,
+	}
+
+	for loc, channels := range DefaultNotificationTemplates() {
+		r.defaults[loc] = make(map[string]*parsedNotificationTemplate)
+		for channel, tmpl := range channels {
+			parsed, err := parseNotificationTemplate(loc+"."+channel, tmpl)
+			if err != nil {
+				panic(fmt.Sprintf("默认通知模板无效: %v", err))
+			}
+			r.defaults[loc][channel] = parsed
+		}
+	}
+
+	for loc, channels := range config.Templates {
+		for channel, tmpl := range channels {
+			parsed, err := parseNotificationTemplate(loc+"."+channel, tmpl)
+			if err != nil {
+				if logger != nil {
+					logger.Warn("通知模板无效，使用默认模板", "locale", loc, "channel", channel, "error", err)
+				}
+				continue
+			}
+			if r.templates[loc] == nil {
+				r.templates[loc] = make(map[string]*parsedNotificationTemplate)
+			}
+			r.templates[loc][channel] = parsed
+		}
+	}
+
+	return r
+}
+
+// parseNotificationTemplate 解析通知模板，不存在的变量展开为空字符串
+func parseNotificationTemplate(name string, tmpl NotificationTemplate) (*parsedNotificationTemplate, error) {
+	subject, err := template.New(name + ".subject").Funcs(notificationFuncs).Option("missingkey=zero").Parse(tmpl.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("解析主题模板失败: %w", err)
+	}
+	body, err := template.New(name + ".body").Funcs(notificationFuncs).Option("missingkey=zero").Parse(tmpl.Body)
+	if err != nil {
+		return nil, fmt.Errorf("解析正文模板失败: %w", err)
+	}
+	return &parsedNotificationTemplate{subject: subject, body: body}, nil
+}
+
+// Locale 返回渲染器使用的语言
+func (r *NotificationRenderer) Locale() string {
+	return r.locale
+}
+
+// Render 使用配置语言渲染指定渠道的通知
+// 按语言回退链依次查找自定义模板和默认模板，模板渲染失败时使用下一个候选模板
+func (r *NotificationRenderer) Render(channel string, vars map[string]string) (string, string) {
+	for _, candidate := range r.candidates(channel) {
+		subject, body, err := candidate.render(vars)
+		if err == nil {
+			return subject, body
+		}
+		if r.logger != nil {
+			r.logger.Warn("渲染通知模板失败", "locale", r.locale, "channel", channel, "error", err)
+		}
+	}
+	return "", ""
+}
+
+// candidates 返回渠道可用的模板，按优先级排列
+func (r *NotificationRenderer) candidates(channel string) []*parsedNotificationTemplate {
+	var candidates []*parsedNotificationTemplate
+	for _, loc := range r.localeChain() {
+		if tmpl := r.templates[loc][channel]; tmpl != nil {
+			candidates = append(candidates, tmpl)
+		}
+		if tmpl := r.defaults[loc][channel]; tmpl != nil {
+			candidates = append(candidates, tmpl)
+		}
+	}
+	return candidates
+}
+
+// localeChain 返回语言回退链，如 en-GB -> en -> 同语言的其他地区 -> zh-CN
+func (r *NotificationRenderer) localeChain() []string {
+	chain := []string{r.locale}
+	language := strings.SplitN(strings.ReplaceAll(r.locale, "_", "-"), "-", 2)[0]
+	if language != r.locale {
+		chain = append(chain, language)
+	}
+
+	// 同语言的其他地区，按名称排序保证结果稳定
+	var regional []string
+	for _, set := range []map[string]map[string]*parsedNotificationTemplate{r.templates, r.defaults} {
+		for loc := range set {
+			if loc != r.locale && strings.HasPrefix(loc, language+"-") {
+				regional = append(regional, loc)
+			}
+		}
+	}
+	sort.Strings(regional)
+	chain = append(chain, regional...)
+
+	return append(chain, DefaultNotificationLocale)
+}
+
+// render 渲染主题和正文
+func (t *parsedNotificationTemplate) render(vars map[string]string) (string, string, error) {
+	if vars == nil {
+		vars = map[string]string{}
+	}
+
+	var subject, body strings.Builder
+	if err := t.subject.Execute(&subject, vars); err != nil {
+		return "", "", err
+	}
+	if err := t.body.Execute(&body, vars); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
+
+// notificationVariables 提取决策中的通知模板变量，包含决策和审计相关字段
+func notificationVariables(decision *engine.PolicyDecision) map[string]string {
+	vars := remediationVariables(decision)
+	if !decision.Timestamp.IsZero() {
+		vars["decision_time"] = formatNotificationTime(decision.Timestamp)
+	}
+	vars["risk_score"] = fmt.Sprintf("%.2f", decision.RiskScore)
+	vars["confidence"] = fmt.Sprintf("%.2f", decision.Confidence)
+	vars["rule_count"] = fmt.Sprintf("%d", len(decision.MatchedRules))
+
+	ruleNames := make([]string, 0, len(decision.MatchedRules))
+	for _, rule := range decision.MatchedRules {
+		ruleNames = append(ruleNames, rule.RuleName)
+	}
+	vars["rule_names"] = strings.Join(ruleNames, ", ")
+
+	if decision.Context == nil {
+		return vars
+	}
+	if packet := decision.Context.PacketInfo; packet != nil {
+		if packet.SourceIP != nil {
+			vars["source_port"] = fmt.Sprintf("%d", packet.SourcePort)
+		}
+		if process := packet.ProcessInfo; process != nil {
+			vars["process_id"] = fmt.Sprintf("%d", process.PID)
+			vars["process_path"] = process.ExecutePath
+			vars["process_user"] = process.User
+		}
+	}
+	if parsed := decision.Context.ParsedData; parsed != nil {
+		vars["protocol"] = parsed.Protocol
+		vars["request_url"] = parsed.URL
+		vars["request_method"] = parsed.Method
+	}
+	if user := decision.Context.UserInfo; user != nil {
+		vars["user_id"] = user.ID
+		vars["username"] = user.Username
+		vars["user_email"] = user.Email
+		vars["department"] = user.Department
+	}
+	if device := decision.Context.DeviceInfo; device != nil {
+		vars["device_id"] = device.ID
+		vars["device_name"] = device.Name
+	}
+	if analysis := decision.Context.AnalysisResult; analysis != nil {
+		types := make([]string, 0, len(analysis.SensitiveData))
+		seen := make(map[string]bool)
+		for _, data := range analysis.SensitiveData {
+			if data != nil && !seen[data.Type] {
+				seen[data.Type] = true
+				types = append(types, data.Type)
+			}
+		}
+		vars["sensitive_types"] = strings.Join(types, ", ")
+		vars["sensitive_count"] = fmt.Sprintf("%d", len(analysis.SensitiveData))
+	}
+	return vars
+}
+
+// alertVariables 在决策变量基础上添加告警字段
+func alertVariables(alert *Alert) map[string]string {
+	vars := make(map[string]string, len(alert.variables)+8)
+	for key, value := range alert.variables {
+		vars[key] = value
+	}
+	vars["alert_id"] = alert.ID
+	vars["title"] = alert.Title
+	vars["message"] = alert.Message
+	vars["level"] = alert.Level.String()
+	vars["source"] = alert.Source
+	vars["timestamp"] = formatNotificationTime(alert.Timestamp)
+	vars["tags"] = strings.Join(alert.Tags, ", ")
+	vars["remediation"] = alert.Remediation
+	return vars
+}
+
+// formatNotificationTime 格式化通知中的时间
+func formatNotificationTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,172 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNotificationDecision 创建包含用户、进程和敏感数据的告警决策
+func newNotificationDecision() *engine.PolicyDecision {
+	decision := newBlockDecision("203.0.113.5", 443)
+	decision.ID = "decision_1"
+	decision.Action = engine.PolicyActionAlert
+	decision.RiskLevel = analyzer.RiskLevelHigh
+	decision.RiskScore = 0.85
+	decision.MatchedRules = []*engine.MatchedRule{{RuleID: "rule_cc", RuleName: "信用卡号外发"}}
+	decision.Context.PacketInfo.ProcessInfo = &interceptor.ProcessInfo{PID: 4242, ProcessName: "curl"}
+	decision.Context.UserInfo = &engine.UserInfo{ID: "u1", Username: "alice"}
+	decision.Context.AnalysisResult = &analyzer.AnalysisResult{SensitiveData: []*analyzer.SensitiveDataInfo{
+		{Type: "credit_card"}, {Type: "credit_card"},
+	}}
+	return decision
+}
+
+func TestNotificationRenderer_DefaultTemplatesPerLocale(t *testing.T) {
+	logger := newTestLogger(t)
+	decision := newNotificationDecision()
+
+	tests := []struct {
+		locale   string
+		title    string
+		subject  string
+		contains []string
+	}{
+		{
+			locale:   "zh-CN",
+			title:    "DLP安全告警",
+			subject:  "[DLP告警] error - DLP安全告警",
+			contains: []string{"告警消息: 检测到high级别的安全风险: 检测到敏感数据外发", "命中规则: rule_cc", "进程: curl (4242)", "用户: alice", "请勿回复"},
+		},
+		{
+			locale:   "en-US",
+			title:    "DLP Security Alert",
+			subject:  "[DLP Alert] error - DLP Security Alert",
+			contains: []string{"Message: Detected a high risk: 检测到敏感数据外发", "Matched rules: rule_cc", "Process: curl (4242)", "User: alice", "Please do not reply"},
+		},
+		{
+			// 未内置的地区回退到同语言模板
+			locale:   "en-GB",
+			title:    "DLP Security Alert",
+			subject:  "[DLP Alert] error - DLP Security Alert",
+			contains: []string{"Destination: 203.0.113.5:443"},
+		},
+		{
+			// 未内置的语言回退到默认语言
+			locale:   "fr-FR",
+			title:    "DLP安全告警",
+			subject:  "[DLP告警] error - DLP安全告警",
+			contains: []string{"目标地址: 203.0.113.5:443"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			ae := NewAlertExecutor(logger).(*AlertExecutorImpl)
+			config := DefaultExecutorConfig()
+			config.Notification.Locale = tt.locale
+			require.NoError(t, ae.Initialize(config))
+
+			alert := ae.newAlert(context.Background(), "alert_1", decision)
+			assert.Equal(t, tt.title, alert.Title)
+
+			subject, body := ae.notifications.Render(NotificationChannelEmail, alertVariables(alert))
+			assert.Equal(t, tt.subject, subject)
+			for _, text := range tt.contains {
+				assert.Contains(t, body, text)
+			}
+			assert.NotContains(t, body, "<no value>")
+		})
+	}
+}
+
+func TestNotificationRenderer_CustomTemplates(t *testing.T) {
+	renderer := NewNotificationRenderer(NotificationConfig{
+		Locale: "en-US",
+		Templates: map[string]map[string]NotificationTemplate{
+			"en-US": {
+				NotificationChannelSMS: {Body: "{{upper .level}} {{.process_name}}{{.undefined_variable}} -> {{default \"unknown\" .dest_ip}}"},
+				// 对字符串变量取字段会在执行时出错，回退到默认模板
+				NotificationChannelWebhook: {Body: "{{.message.text}}"},
+				// 语法错误的模板在创建时被忽略
+				NotificationChannelEmail: {Subject: "{{.title", Body: "broken"},
+			},
+			"zh-CN": {
+				NotificationChannelSMS: {Body: "中文短信 {{.level}}"},
+			},
+		},
+	}, newTestLogger(t))
+	assert.Equal(t, "en-US", renderer.Locale())
+
+	// 缺失的变量展开为空字符串
+	_, body := renderer.Render(NotificationChannelSMS, map[string]string{"level": "critical", "process_name": "curl"})
+	assert.Equal(t, "CRITICAL curl -> unknown", body)
+
+	_, body = renderer.Render(NotificationChannelWebhook, map[string]string{"level": "warning", "message": "发现敏感数据"})
+	assert.Equal(t, "[warning] 发现敏感数据", body)
+
+	subject, _ := renderer.Render(NotificationChannelEmail, map[string]string{"level": "info", "title": "Test"})
+	assert.Equal(t, "[DLP Alert] info - Test", subject)
+
+	// 没有任何变量时也能渲染
+	subject, body = renderer.Render(NotificationChannelDefault, nil)
+	assert.Equal(t, "DLP action executed: ", subject)
+	assert.Equal(t, "Failed to execute  action: ", body)
+
+	// 未知渠道没有模板
+	subject, body = renderer.Render("pager", nil)
+	assert.Empty(t, subject)
+	assert.Empty(t, body)
+}
+
+func TestAlertExecutor_WebhookTextUsesTemplate(t *testing.T) {
+	payloads := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+			payloads <- payload
+		}
+	}))
+	defer server.Close()
+
+	ae := NewAlertExecutor(newTestLogger(t)).(*AlertExecutorImpl)
+	config := DefaultExecutorConfig()
+	config.Notification = NotificationConfig{
+		Locale: "en-US",
+		Templates: map[string]map[string]NotificationTemplate{
+			"en-US": {NotificationChannelWebhook: {Body: "{{.alert_id}} {{.sensitive_types}} x{{.sensitive_count}} by {{.username}}"}},
+		},
+	}
+	require.NoError(t, ae.Initialize(config))
+	ae.SetWebhookConfig(&WebhookConfig{URL: server.URL, Method: http.MethodPost, Timeout: time.Second})
+
+	alert := ae.newAlert(context.Background(), "alert_1", newNotificationDecision())
+	require.NoError(t, ae.sendWebhookAlert(alert))
+	payload := <-payloads
+	assert.Equal(t, "alert_1 credit_card x2 by alice", payload["text"])
+	assert.Equal(t, "Detected a high risk: 检测到敏感数据外发", payload["message"])
+}
+
+func TestExecutionManager_NotificationTemplates(t *testing.T) {
+	config := DefaultExecutorConfig()
+	config.Notification.Locale = "en-US"
+	em := NewExecutionManager(newTestLogger(t), config).(*ExecutionManagerImpl)
+	decision := newNotificationDecision()
+
+	title, message := em.buildNotification(decision, &ExecutionResult{Success: true})
+	assert.Equal(t, "DLP action executed: alert", title)
+	assert.Equal(t, "Executed alert action, risk level: high, matched rules: 1", message)
+
+	_, message = em.buildNotification(decision, &ExecutionResult{Error: errors.New("smtp unavailable"), Remediation: "Check SMTP"})
+	assert.Equal(t, "Failed to execute alert action: smtp unavailable. Remediation: Check SMTP", message)
+}
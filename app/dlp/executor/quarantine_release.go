@@ -0,0 +1,258 @@
+package executor
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrQuarantinedFileNotFound 隔离记录不存在
+	ErrQuarantinedFileNotFound = errors.New("隔离记录不存在")
+
+	// ErrReleaseNotApproved 恢复请求未经有效审批
+	ErrReleaseNotApproved = errors.New("恢复隔离文件未经审批")
+
+	// ErrReleaseApprovalExpired 审批已过期
+	ErrReleaseApprovalExpired = errors.New("恢复审批已过期")
+
+	// ErrQuarantineHashMismatch 隔离文件内容与隔离记录不一致
+	ErrQuarantineHashMismatch = errors.New("隔离文件哈希与隔离记录不一致")
+)
+
+// AuditRecorder 审计记录器，AuditExecutorImpl 实现了该接口
+type AuditRecorder interface {
+	// RecordAuditEvent 记录审计事件
+	RecordAuditEvent(event *AuditEvent) error
+}
+
+// ReleaseApproval 恢复隔离文件的审批令牌，由操作员使用 Ed25519 私钥签名
+// 签名覆盖隔离ID、文件哈希、操作员、密钥标识和过期时间，审批只对签发时的文件内容有效
+type ReleaseApproval struct {
+	QuarantineID string    `json:"quarantine_id"`
+	FileHash     string    `json:"file_hash"`
+	Operator     string    `json:"operator"`
+	KeyID        string    `json:"key_id"`
+	ExpiresAt    time.Time `json:"expires_at"`
+
+	// Signature 对审批内容的签名（Base64）
+	Signature string `json:"signature"`
+}
+
+// digest 返回审批内容的签名摘要
+func (a ReleaseApproval) digest() []byte {
+	payload := strings.Join([]string{
+		"kennel-quarantine-release",
+		a.QuarantineID,
+		strings.ToLower(a.FileHash),
+		a.Operator,
+		a.KeyID,
+		strconv.FormatInt(a.ExpiresAt.Unix(), 10),
+	}, "\n")
+	sum := sha256.Sum256([]byte(payload))
+	return sum[:]
+}
+
+// SignReleaseApproval 使用操作员私钥签名审批，供审批工具生成审批令牌
+func SignReleaseApproval(approval ReleaseApproval, key ed25519.PrivateKey) ReleaseApproval {
+	approval.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, approval.digest()))
+	return approval
+}
+
+// SetQuarantineConfig 设置隔离配置，同时加载审批公钥
+func (qe *QuarantineExecutorImpl) SetQuarantineConfig(config *QuarantineConfig) error {
+	approvers := make(map[string]ed25519.PublicKey, len(config.ApproverKeys))
+	for keyID, value := range config.ApproverKeys {
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("解析审批公钥 %s 失败: %w", keyID, err)
+		}
+		if len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("审批公钥 %s 长度无效: %d", keyID, len(key))
+		}
+		approvers[keyID] = ed25519.PublicKey(key)
+	}
+
+	qe.mu.Lock()
+	defer qe.mu.Unlock()
+	qe.quarantineDir = config.QuarantineDir
+	for keyID, key := range approvers {
+		qe.approvers[keyID] = key
+	}
+	return nil
+}
+
+// AddReleaseApprover 添加可审批恢复隔离文件的操作员公钥
+func (qe *QuarantineExecutorImpl) AddReleaseApprover(keyID string, key ed25519.PublicKey) error {
+	if keyID == "" {
+		return fmt.Errorf("密钥标识不能为空")
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("审批公钥 %s 长度无效: %d", keyID, len(key))
+	}
+
+	qe.mu.Lock()
+	defer qe.mu.Unlock()
+	qe.approvers[keyID] = key
+	return nil
+}
+
+// SetAuditRecorder 设置审计记录器，恢复隔离文件的结果写入审计
+func (qe *QuarantineExecutorImpl) SetAuditRecorder(recorder AuditRecorder) {
+	qe.mu.Lock()
+	defer qe.mu.Unlock()
+	qe.auditRecorder = recorder
+}
+
+// ReleaseFile 凭审批恢复隔离的文件
+// 校验审批签名与有效期，并确认隔离文件的哈希仍与隔离记录一致后，将文件移回原位置并删除隔离记录。
+// 无论成功与否都记录审计
+func (qe *QuarantineExecutorImpl) ReleaseFile(approval ReleaseApproval) (*QuarantinedFile, error) {
+	file, err := qe.releaseFile(approval, time.Now())
+	qe.auditRelease(approval, file, err)
+	if err != nil {
+		qe.logger.Warn("拒绝恢复隔离文件",
+			"quarantine_id", approval.QuarantineID,
+			"operator", approval.Operator,
+			"error", err)
+		return nil, err
+	}
+
+	qe.logger.Info("隔离文件已恢复",
+		"quarantine_id", file.ID,
+		"original_path", file.OriginalPath,
+		"operator", approval.Operator)
+	return file, nil
+}
+
+func (qe *QuarantineExecutorImpl) releaseFile(approval ReleaseApproval, now time.Time) (*QuarantinedFile, error) {
+	qe.mu.Lock()
+	defer qe.mu.Unlock()
+
+	index := -1
+	for i := range qe.quarantinedFiles {
+		if qe.quarantinedFiles[i].ID == approval.QuarantineID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrQuarantinedFileNotFound, approval.QuarantineID)
+	}
+	file := qe.quarantinedFiles[index]
+
+	if err := qe.verifyReleaseApproval(approval, file, now); err != nil {
+		return &file, err
+	}
+
+	// 确认隔离期间文件未被替换或篡改
+	if file.Hash == "" {
+		return &file, fmt.Errorf("%w: 隔离记录缺少文件哈希，无法校验", ErrQuarantineHashMismatch)
+	}
+	currentHash, err := qe.calculateFileHash(file.QuarantinePath)
+	if err != nil {
+		return &file, fmt.Errorf("读取隔离文件失败: %w", err)
+	}
+	if !strings.EqualFold(currentHash, file.Hash) {
+		return &file, fmt.Errorf("%w: 记录 %s, 实际 %s", ErrQuarantineHashMismatch, file.Hash, currentHash)
+	}
+
+	if err := qe.restoreFile(&file); err != nil {
+		return &file, err
+	}
+
+	qe.quarantinedFiles = append(qe.quarantinedFiles[:index], qe.quarantinedFiles[index+1:]...)
+	return &file, nil
+}
+
+// verifyReleaseApproval 校验审批签名、有效期以及审批与隔离记录是否对应
+// 调用方需持有锁
+func (qe *QuarantineExecutorImpl) verifyReleaseApproval(approval ReleaseApproval, file QuarantinedFile, now time.Time) error {
+	if approval.Signature == "" {
+		return fmt.Errorf("%w: 缺少审批签名", ErrReleaseNotApproved)
+	}
+	key, ok := qe.approvers[approval.KeyID]
+	if !ok {
+		return fmt.Errorf("%w: 审批密钥不受信任 %s", ErrReleaseNotApproved, approval.KeyID)
+	}
+	signature, err := base64.StdEncoding.DecodeString(approval.Signature)
+	if err != nil || !ed25519.Verify(key, approval.digest(), signature) {
+		return fmt.Errorf("%w: 审批签名无效", ErrReleaseNotApproved)
+	}
+	if !now.Before(approval.ExpiresAt) {
+		return fmt.Errorf("%w: %s", ErrReleaseApprovalExpired, approval.ExpiresAt.Format(time.RFC3339))
+	}
+	if !strings.EqualFold(approval.FileHash, file.Hash) {
+		return fmt.Errorf("%w: 审批的文件哈希与隔离记录不一致", ErrReleaseNotApproved)
+	}
+	return nil
+}
+
+// restoreFile 将隔离文件移回原位置，原位置已存在文件时拒绝覆盖
+func (qe *QuarantineExecutorImpl) restoreFile(file *QuarantinedFile) error {
+	if _, err := os.Lstat(file.OriginalPath); err == nil {
+		return fmt.Errorf("原位置已存在文件，拒绝覆盖: %s", file.OriginalPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(file.OriginalPath), 0755); err != nil {
+		return fmt.Errorf("创建原目录失败: %w", err)
+	}
+
+	if err := os.Rename(file.QuarantinePath, file.OriginalPath); err != nil {
+		if err := qe.copyAndDeleteFile(file.QuarantinePath, file.OriginalPath); err != nil {
+			return fmt.Errorf("恢复文件失败: %w", err)
+		}
+	}
+
+	// 还原隔离前的权限
+	mode := os.FileMode(0644)
+	if original, ok := file.Metadata["original_mode"].(uint32); ok {
+		mode = os.FileMode(original)
+	}
+	if err := os.Chmod(file.OriginalPath, mode); err != nil {
+		qe.logger.Warn("还原文件权限失败", "path", file.OriginalPath, "error", err)
+	}
+	return nil
+}
+
+// auditRelease 记录恢复隔离文件的审计事件
+func (qe *QuarantineExecutorImpl) auditRelease(approval ReleaseApproval, file *QuarantinedFile, releaseErr error) {
+	qe.mu.RLock()
+	recorder := qe.auditRecorder
+	qe.mu.RUnlock()
+	if recorder == nil {
+		return
+	}
+
+	event := &AuditEvent{
+		ID:        fmt.Sprintf("quarantine_release_%d", time.Now().UnixNano()),
+		Timestamp: time.Now(),
+		EventType: "quarantine_release",
+		Action:    "release",
+		UserID:    approval.Operator,
+		Result:    "released",
+		Details: map[string]interface{}{
+			"quarantine_id": approval.QuarantineID,
+			"key_id":        approval.KeyID,
+		},
+	}
+	if file != nil {
+		event.Details["original_path"] = file.OriginalPath
+		event.Details["quarantine_path"] = file.QuarantinePath
+		event.Details["file_hash"] = file.Hash
+	}
+	if releaseErr != nil {
+		event.Result = "denied"
+		event.Reason = releaseErr.Error()
+	}
+
+	if err := recorder.RecordAuditEvent(event); err != nil {
+		qe.logger.Error("记录隔离文件恢复审计失败", "quarantine_id", approval.QuarantineID, "error", err)
+	}
+}
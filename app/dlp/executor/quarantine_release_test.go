@@ -0,0 +1,211 @@
+package executor
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditRecorder 记录审计事件的测试记录器
+type recordingAuditRecorder struct {
+	mu     sync.Mutex
+	events []AuditEvent
+}
+
+func (r *recordingAuditRecorder) RecordAuditEvent(event *AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *recordingAuditRecorder) last(t *testing.T) AuditEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	require.NotEmpty(t, r.events)
+	return r.events[len(r.events)-1]
+}
+
+type quarantineReleaseFixture struct {
+	qe           *QuarantineExecutorImpl
+	recorder     *recordingAuditRecorder
+	operatorKey  ed25519.PrivateKey
+	originalPath string
+	file         QuarantinedFile
+}
+
+// newQuarantineReleaseFixture 隔离一个真实文件，并信任操作员 alice 的审批公钥
+func newQuarantineReleaseFixture(t *testing.T) *quarantineReleaseFixture {
+	dir := t.TempDir()
+	originalPath := filepath.Join(dir, "docs", "report.xlsx")
+	require.NoError(t, os.MkdirAll(filepath.Dir(originalPath), 0755))
+	require.NoError(t, os.WriteFile(originalPath, []byte("身份证号 110101199003074514"), 0640))
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	config := DefaultExecutorConfig()
+	config.Quarantine = QuarantineConfig{
+		QuarantineDir: filepath.Join(dir, "quarantine"),
+		ApproverKeys:  map[string]string{"alice": base64.StdEncoding.EncodeToString(publicKey)},
+	}
+	qe := NewQuarantineExecutor(newTestLogger(t)).(*QuarantineExecutorImpl)
+	require.NoError(t, qe.Initialize(config))
+	recorder := &recordingAuditRecorder{}
+	qe.SetAuditRecorder(recorder)
+
+	result, err := qe.ExecuteAction(context.Background(), &engine.PolicyDecision{
+		Action: engine.PolicyActionQuarantine,
+		Reason: "文件包含身份证号",
+		Context: &engine.DecisionContext{
+			ParsedData: &parser.ParsedData{Protocol: "file", URL: originalPath},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, result.Success)
+
+	files := qe.GetQuarantinedFiles()
+	require.Len(t, files, 1)
+	require.NoFileExists(t, originalPath)
+	require.FileExists(t, files[0].QuarantinePath)
+	require.NotEmpty(t, files[0].Hash)
+
+	return &quarantineReleaseFixture{
+		qe:           qe,
+		recorder:     recorder,
+		operatorKey:  privateKey,
+		originalPath: originalPath,
+		file:         files[0],
+	}
+}
+
+func (f *quarantineReleaseFixture) approval() ReleaseApproval {
+	return SignReleaseApproval(ReleaseApproval{
+		QuarantineID: f.file.ID,
+		FileHash:     f.file.Hash,
+		Operator:     "alice",
+		KeyID:        "alice",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}, f.operatorKey)
+}
+
+func TestQuarantineExecutor_ReleaseApproved(t *testing.T) {
+	f := newQuarantineReleaseFixture(t)
+
+	released, err := f.qe.ReleaseFile(f.approval())
+	require.NoError(t, err)
+	assert.Equal(t, f.file.ID, released.ID)
+
+	content, err := os.ReadFile(f.originalPath)
+	require.NoError(t, err)
+	assert.Equal(t, "身份证号 110101199003074514", string(content))
+	assert.NoFileExists(t, f.file.QuarantinePath)
+	if info, err := os.Stat(f.originalPath); assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0640), info.Mode().Perm(), "应还原隔离前的权限")
+	}
+	assert.Empty(t, f.qe.GetQuarantinedFiles(), "恢复后应删除隔离记录")
+
+	event := f.recorder.last(t)
+	assert.Equal(t, "quarantine_release", event.EventType)
+	assert.Equal(t, "released", event.Result)
+	assert.Equal(t, "alice", event.UserID)
+	assert.Equal(t, f.file.ID, event.Details["quarantine_id"])
+	assert.Equal(t, f.originalPath, event.Details["original_path"])
+
+	// 同一审批不能重复使用
+	_, err = f.qe.ReleaseFile(f.approval())
+	assert.ErrorIs(t, err, ErrQuarantinedFileNotFound)
+}
+
+func TestQuarantineExecutor_ReleaseRefusesHashMismatch(t *testing.T) {
+	f := newQuarantineReleaseFixture(t)
+
+	// 隔离期间文件被替换
+	require.NoError(t, os.Chmod(f.file.QuarantinePath, 0644))
+	require.NoError(t, os.WriteFile(f.file.QuarantinePath, []byte("被替换的内容"), 0644))
+
+	_, err := f.qe.ReleaseFile(f.approval())
+	require.ErrorIs(t, err, ErrQuarantineHashMismatch)
+
+	assert.NoFileExists(t, f.originalPath)
+	assert.FileExists(t, f.file.QuarantinePath)
+	assert.Len(t, f.qe.GetQuarantinedFiles(), 1, "拒绝恢复时应保留隔离记录")
+
+	event := f.recorder.last(t)
+	assert.Equal(t, "denied", event.Result)
+	assert.Contains(t, event.Reason, "哈希")
+}
+
+func TestQuarantineExecutor_ReleaseRefusesUnapproved(t *testing.T) {
+	f := newQuarantineReleaseFixture(t)
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	unsigned := f.approval()
+	unsigned.Signature = ""
+
+	tampered := f.approval()
+	tampered.Operator = "mallory"
+
+	wrongKey := SignReleaseApproval(f.approval(), otherKey)
+
+	untrusted := f.approval()
+	untrusted.KeyID = "mallory"
+	untrusted = SignReleaseApproval(untrusted, otherKey)
+
+	otherContent := f.approval()
+	otherContent.FileHash = "0000000000000000000000000000000000000000000000000000000000000000"
+	otherContent = SignReleaseApproval(otherContent, f.operatorKey)
+
+	expired := f.approval()
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	expired = SignReleaseApproval(expired, f.operatorKey)
+
+	tests := []struct {
+		name     string
+		approval ReleaseApproval
+		err      error
+	}{
+		{"缺少签名", unsigned, ErrReleaseNotApproved},
+		{"审批内容被篡改", tampered, ErrReleaseNotApproved},
+		{"签名密钥与标识不符", wrongKey, ErrReleaseNotApproved},
+		{"审批密钥不受信任", untrusted, ErrReleaseNotApproved},
+		{"审批的文件内容不同", otherContent, ErrReleaseNotApproved},
+		{"审批已过期", expired, ErrReleaseApprovalExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.qe.ReleaseFile(tt.approval)
+			require.ErrorIs(t, err, tt.err)
+
+			assert.NoFileExists(t, f.originalPath)
+			assert.Len(t, f.qe.GetQuarantinedFiles(), 1)
+			assert.Equal(t, "denied", f.recorder.last(t).Result)
+		})
+	}
+
+	// 审批有效时仍可恢复
+	_, err = f.qe.ReleaseFile(f.approval())
+	assert.NoError(t, err)
+}
+
+func TestQuarantineExecutor_SetQuarantineConfigRejectsInvalidKeys(t *testing.T) {
+	qe := NewQuarantineExecutor(newTestLogger(t)).(*QuarantineExecutorImpl)
+
+	err := qe.SetQuarantineConfig(&QuarantineConfig{ApproverKeys: map[string]string{"alice": "不是Base64"}})
+	assert.Error(t, err)
+
+	err = qe.SetQuarantineConfig(&QuarantineConfig{ApproverKeys: map[string]string{"alice": base64.StdEncoding.EncodeToString([]byte("short"))}})
+	assert.ErrorContains(t, err, "长度无效")
+}
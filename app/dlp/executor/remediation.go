@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+)
+
+const (
+	// RemediationKeyDegradedBlock 防火墙不可用、阻断降级为告警和审计时使用的修复建议模板键
+	RemediationKeyDegradedBlock = "degraded_block"
+
+	// RemediationKeyFailed 动作执行失败时使用的修复建议模板键
+	RemediationKeyFailed = "failed"
+)
+
+// remediationVariablePattern 修复建议模板变量引用，形如 ${dest_ip}
+var remediationVariablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// DefaultRemediationTemplates 返回默认修复建议模板
+// 键为动作名称（block、alert 等）或 degraded_block、failed，模板中的 ${变量} 在执行时展开
+func DefaultRemediationTemplates() map[string]string {
+	return map[string]string{
+		"block":                     "已阻断到 ${destination} 的连接（命中规则: ${rule_ids}）。如确认为正常业务，请将 ${dest_ip} 加入策略白名单，并删除防火墙规则 ${firewall_rule}",
+		"alert":                     "已发送${risk_level}级别告警: ${reason}。请核实进程 ${process_name} 向 ${destination} 的数据传输是否符合业务需要",
+		"audit":                     "已记录审计事件 ${event_id}。如需追溯，请在审计日志中按事件ID检索完整上下文",
+		"encrypt":                   "数据已使用 ${algorithm} 加密。接收方需要通过密钥管理服务获取密钥后解密",
+		"quarantine":                "文件 ${original_path} 已隔离到 ${quarantine_path}。确认无风险后可从隔离区恢复，并将该文件加入策略白名单",
+		"redirect":                  "到 ${original_dest} 的流量已重定向到 ${new_dest}。如需恢复直连，请删除重定向规则 ${redirect_rule}",
+		RemediationKeyDegradedBlock: "防火墙不可用（${degrade_reason}），到 ${destination} 的连接未被阻断，已改为告警和审计。请恢复防火墙后手动阻断 ${dest_ip}",
+		RemediationKeyFailed:        "${action}动作执行失败: ${error}。请检查执行器日志，并手动处置到 ${destination} 的数据传输",
+	}
+}
+
+// renderRemediation 展开修复建议模板
+// 配置中没有对应模板时使用默认模板，模板为空表示无需处置；未知变量展开为"未知"
+func renderRemediation(templates map[string]string, key string, vars map[string]string) string {
+	template, ok := templates[key]
+	if !ok {
+		template = DefaultRemediationTemplates()[key]
+	}
+	if template == "" {
+		return ""
+	}
+
+	return remediationVariablePattern.ReplaceAllStringFunc(template, func(ref string) string {
+		name := remediationVariablePattern.FindStringSubmatch(ref)[1]
+		if value := vars[name]; value != "" {
+			return value
+		}
+		return "未知"
+	})
+}
+
+// remediationVariables 提取决策中的通用模板变量
+func remediationVariables(decision *engine.PolicyDecision) map[string]string {
+	vars := map[string]string{
+		"decision_id": decision.ID,
+		"action":      decision.Action.String(),
+		"reason":      decision.Reason,
+		"risk_level":  decision.RiskLevel.String(),
+	}
+
+	ruleIDs := make([]string, 0, len(decision.MatchedRules))
+	for _, rule := range decision.MatchedRules {
+		ruleIDs = append(ruleIDs, rule.RuleID)
+	}
+	vars["rule_ids"] = strings.Join(ruleIDs, ", ")
+
+	if decision.Context == nil {
+		return vars
+	}
+	if packet := decision.Context.PacketInfo; packet != nil {
+		if packet.SourceIP != nil {
+			vars["source_ip"] = packet.SourceIP.String()
+		}
+		if packet.DestIP != nil {
+			vars["dest_ip"] = packet.DestIP.String()
+			vars["dest_port"] = fmt.Sprintf("%d", packet.DestPort)
+			vars["destination"] = fmt.Sprintf("%s:%d", packet.DestIP.String(), packet.DestPort)
+		}
+		if packet.ProcessInfo != nil {
+			vars["process_name"] = packet.ProcessInfo.ProcessName
+		}
+	}
+	if parsed := decision.Context.ParsedData; parsed != nil && parsed.URL != "" {
+		vars["destination"] = parsed.URL
+	}
+	return vars
+}
+
+// setRemediation 根据执行结果填充修复建议，执行失败时使用 failed 模板
+func setRemediation(result *ExecutionResult, templates map[string]string, key string, decision *engine.PolicyDecision, extra map[string]string) {
+	vars := remediationVariables(decision)
+	for name, value := range extra {
+		vars[name] = value
+	}
+	if !result.Success {
+		key = RemediationKeyFailed
+		if result.Error != nil {
+			vars["error"] = result.Error.Error()
+		}
+	}
+	result.Remediation = renderRemediation(templates, key, vars)
+}
+
+// remediationContextKey 上下文中主动作修复建议的键
+type remediationContextKey struct{}
+
+// withRemediation 在上下文中携带主动作的修复建议，告警和审计执行器优先使用该建议
+func withRemediation(ctx context.Context, remediation string) context.Context {
+	return context.WithValue(ctx, remediationContextKey{}, remediation)
+}
+
+// remediationFromContext 获取上下文中携带的修复建议
+func remediationFromContext(ctx context.Context) string {
+	remediation, _ := ctx.Value(remediationContextKey{}).(string)
+	return remediation
+}
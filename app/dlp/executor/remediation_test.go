@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) logging.Logger {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+	return logger
+}
+
+func TestExecutors_PopulateRemediation(t *testing.T) {
+	// 审计执行器写入相对路径的审计日志
+	t.Chdir(t.TempDir())
+	logger := newTestLogger(t)
+
+	fileDecision := &engine.PolicyDecision{
+		Action: engine.PolicyActionQuarantine,
+		Reason: "文件包含身份证号",
+		Context: &engine.DecisionContext{
+			ParsedData: &parser.ParsedData{Protocol: "file", URL: "C:\\Users\\alice\\report.xlsx"},
+		},
+	}
+
+	be, _ := newTestBlockExecutor(t)
+	encryptProvider, err := NewStaticKeyProvider(testKey(0x10, 32))
+	require.NoError(t, err)
+	encryptDecision := newBlockDecision("203.0.113.5", 443)
+	encryptDecision.Context.PacketInfo.Payload = []byte("身份证号: 110101199003077777")
+	tests := []struct {
+		name     string
+		executor ActionExecutor
+		decision *engine.PolicyDecision
+		contains []string
+	}{
+		{"block", be, newBlockDecision("203.0.113.5", 443), []string{"已阻断到 203.0.113.5:443", "DLP_Block_203.0.113.5"}},
+		{"audit", NewAuditExecutor(logger), newBlockDecision("203.0.113.5", 443), []string{"已记录审计事件 audit_"}},
+		{"encrypt", newTestEncryptExecutor(t, encryptProvider), encryptDecision, []string{"AES-256"}},
+		{"quarantine", NewQuarantineExecutor(logger), fileDecision, []string{"C:\\Users\\alice\\report.xlsx", "/quarantine/quarantine_"}},
+		{"redirect", NewRedirectExecutor(logger), newBlockDecision("203.0.113.5", 443), []string{"safe.example.com", "redirect_"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.executor.ExecuteAction(context.Background(), tt.decision)
+			require.NoError(t, err)
+			require.True(t, result.Success)
+			for _, text := range tt.contains {
+				assert.Contains(t, result.Remediation, text)
+			}
+			assert.NotContains(t, result.Remediation, "${")
+		})
+	}
+
+	// 放行无需处置
+	allowed, err := NewAllowExecutor(logger).ExecuteAction(context.Background(), &engine.PolicyDecision{Action: engine.PolicyActionAllow})
+	require.NoError(t, err)
+	assert.Empty(t, allowed.Remediation)
+
+	// 执行失败时给出失败处置建议
+	failed, err := be.ExecuteAction(context.Background(), &engine.PolicyDecision{Action: engine.PolicyActionBlock})
+	require.NoError(t, err)
+	require.False(t, failed.Success)
+	assert.Equal(t, "block动作执行失败: 缺少数据包信息。请检查执行器日志，并手动处置到 未知 的数据传输", failed.Remediation)
+}
+
+func TestRemediationTemplatesConfigurable(t *testing.T) {
+	be, _ := newTestBlockExecutor(t)
+	config := DefaultExecutorConfig()
+	config.RemediationTemplates = map[string]string{
+		"block": "请联系SOC解除对 ${dest_ip} 的阻断（规则 ${firewall_rule}，工单 ${ticket}）",
+	}
+	require.NoError(t, be.Initialize(config))
+
+	result, err := be.ExecuteAction(context.Background(), newBlockDecision("198.51.100.7", 443))
+	require.NoError(t, err)
+	assert.Equal(t, "请联系SOC解除对 198.51.100.7 的阻断（规则 DLP_Block_198.51.100.7，工单 未知）", result.Remediation)
+}
+
+func TestAlertExecutor_RemediationInAlertPayload(t *testing.T) {
+	payloads := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err == nil {
+			payloads <- payload
+		}
+	}))
+	defer server.Close()
+
+	ae := NewAlertExecutor(newTestLogger(t)).(*AlertExecutorImpl)
+	ae.SetWebhookConfig(&WebhookConfig{URL: server.URL, Method: http.MethodPost, Timeout: time.Second})
+
+	decision := newBlockDecision("203.0.113.5", 443)
+	decision.Action = engine.PolicyActionAlert
+	alert := ae.newAlert(context.Background(), "alert_1", decision)
+	assert.Contains(t, alert.Remediation, "检测到敏感数据外发")
+	assert.Contains(t, alert.Remediation, "203.0.113.5:443")
+	_, body := ae.notifications.Render(NotificationChannelEmail, alertVariables(alert))
+	assert.Contains(t, body, "处置建议: "+alert.Remediation)
+
+	require.NoError(t, ae.sendWebhookAlert(alert))
+	payload := <-payloads
+	assert.Equal(t, alert.Remediation, payload["remediation"])
+
+	// 主动作的处置建议优先于告警本身的建议
+	degraded := ae.newAlert(withRemediation(context.Background(), "请手动阻断 203.0.113.5"), "alert_2", decision)
+	assert.Equal(t, "请手动阻断 203.0.113.5", degraded.Remediation)
+
+	// 告警发送失败
+	result, err := ae.ExecuteAction(context.Background(), decision)
+	require.NoError(t, err)
+	require.False(t, result.Success)
+	assert.Contains(t, result.Remediation, "alert动作执行失败")
+}
+
+func TestExecutionManager_DegradedBlockRemediation(t *testing.T) {
+	em, _, _, _ := newEnforcementTestManager(t, &fakeCommandRunner{missing: true})
+
+	decision := newBlockDecision("203.0.113.5", 443)
+	result, err := em.ExecuteDecision(context.Background(), decision)
+	require.NoError(t, err)
+	assert.Contains(t, result.Remediation, "防火墙不可用（未找到防火墙工具")
+	assert.Contains(t, result.Remediation, "请恢复防火墙后手动阻断 203.0.113.5")
+	_, message := em.buildNotification(decision, result)
+	assert.Contains(t, message, "处置建议: "+result.Remediation)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// DefaultFileMonitorDebounce 同一文件连续变更时，最后一次变更后等待多久再扫描
+const DefaultFileMonitorDebounce = 500 * time.Millisecond
+
+// FileMonitor 基于文件系统事件的文件监控器
+// 使用 fsnotify（Linux 上为 inotify，Windows 上为 ReadDirectoryChangesW），
+// 只在文件创建、修改和重命名时触发扫描，同一文件的连续变更合并为一次扫描
+type FileMonitor struct {
+	logger   logging.Logger
+	watcher  *fsnotify.Watcher
+	patterns []string
+	debounce time.Duration
+	scan     func(path string)
+
+	pending map[string]*pendingScan
+	stopped bool
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+}
+
+// pendingScan 等待去抖时间到期的扫描
+type pendingScan struct {
+	timer *time.Timer
+}
+
+// NewFileMonitor 创建文件监控器，patterns 为监控的文件类型（扩展名或通配符），为空时监控所有文件
+func NewFileMonitor(logger logging.Logger, patterns []string, debounce time.Duration, scan func(path string)) (*FileMonitor, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("创建文件监视器失败: %w", err)
+	}
+	if debounce <= 0 {
+		debounce = DefaultFileMonitorDebounce
+	}
+
+	fm := &FileMonitor{
+		logger:   logger,
+		watcher:  watcher,
+		patterns: patterns,
+		debounce: debounce,
+		scan:     scan,
+		pending:  make(map[string]*pendingScan),
+	}
+
+	fm.wg.Add(1)
+	go fm.run()
+	return fm, nil
+}
+
+// Watch 监控目录及其子目录
+func (fm *FileMonitor) Watch(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			fm.logger.Warn("访问目录失败", "path", path, "error", err)
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if err := fm.watcher.Add(path); err != nil {
+			return fmt.Errorf("监控目录失败 %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+// Stop 停止监控，取消尚未触发的扫描
+func (fm *FileMonitor) Stop() error {
+	fm.mu.Lock()
+	if fm.stopped {
+		fm.mu.Unlock()
+		return nil
+	}
+	fm.stopped = true
+	for path, scan := range fm.pending {
+		scan.timer.Stop()
+		delete(fm.pending, path)
+	}
+	fm.mu.Unlock()
+
+	err := fm.watcher.Close()
+	fm.wg.Wait()
+	return err
+}
+
+// run 处理文件系统事件
+func (fm *FileMonitor) run() {
+	defer fm.wg.Done()
+
+	for {
+		select {
+		case event, ok := <-fm.watcher.Events:
+			if !ok {
+				return
+			}
+			fm.handleEvent(event)
+		case err, ok := <-fm.watcher.Errors:
+			if !ok {
+				return
+			}
+			fm.logger.Error("文件监视器错误", "error", err)
+		}
+	}
+}
+
+// handleEvent 处理单个文件系统事件，删除和权限变更不触发扫描
+func (fm *FileMonitor) handleEvent(event fsnotify.Event) {
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) && !event.Has(fsnotify.Rename) {
+		return
+	}
+
+	// 新建的子目录加入监控
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := fm.Watch(event.Name); err != nil {
+				fm.logger.Warn("监控新建目录失败", "path", event.Name, "error", err)
+			}
+			return
+		}
+	}
+
+	if !matchesFileType(fm.patterns, event.Name) {
+		return
+	}
+	fm.schedule(event.Name)
+}
+
+// schedule 安排扫描文件，去抖时间内的再次变更会推迟扫描
+func (fm *FileMonitor) schedule(path string) {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.stopped {
+		return
+	}
+	if scan, ok := fm.pending[path]; ok && scan.timer.Stop() {
+		scan.timer.Reset(fm.debounce)
+		return
+	}
+
+	// 已到期但尚未执行的定时器会发现自己已被替换而放弃扫描
+	scan := &pendingScan{}
+	scan.timer = time.AfterFunc(fm.debounce, func() { fm.fire(path, scan) })
+	fm.pending[path] = scan
+}
+
+// fire 去抖时间到期后扫描文件，重命名后已不存在的文件跳过
+func (fm *FileMonitor) fire(path string, scan *pendingScan) {
+	fm.mu.Lock()
+	if fm.stopped || fm.pending[path] != scan {
+		fm.mu.Unlock()
+		return
+	}
+	delete(fm.pending, path)
+	fm.mu.Unlock()
+
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return
+	}
+	fm.scan(path)
+}
+
+// matchesFileType 检查文件是否属于监控的文件类型，不区分大小写
+// 以点开头且不含通配符的类型按扩展名匹配（如 .txt），其余按文件名通配符匹配（如 *.doc?）
+func matchesFileType(patterns []string, path string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	name := strings.ToLower(filepath.Base(path))
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if strings.HasPrefix(pattern, ".") && !strings.ContainsAny(pattern, "*?[") {
+			if strings.HasSuffix(name, pattern) {
+				return true
+			}
+			continue
+		}
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// windowsEnvPattern 匹配 Windows 风格的环境变量引用，如 %USERNAME%
+var windowsEnvPattern = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_]*)%`)
+
+// expandMonitoredPath 展开监控目录中的环境变量，支持 $VAR、${VAR} 和 %VAR%
+func expandMonitoredPath(path string) string {
+	path = windowsEnvPattern.ReplaceAllStringFunc(path, func(ref string) string {
+		if value, ok := os.LookupEnv(strings.Trim(ref, "%")); ok {
+			return value
+		}
+		return ref
+	})
+	return os.ExpandEnv(path)
+}
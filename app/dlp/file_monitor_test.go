@@ -0,0 +1,171 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scanRecorder 记录文件监控触发的扫描
+type scanRecorder struct {
+	mu    sync.Mutex
+	scans map[string]int
+}
+
+func newScanRecorder() *scanRecorder {
+	return &scanRecorder{scans: make(map[string]int)}
+}
+
+func (r *scanRecorder) scan(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scans[filepath.Base(path)]++
+}
+
+func (r *scanRecorder) count(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.scans[name]
+}
+
+func (r *scanRecorder) total() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	total := 0
+	for _, n := range r.scans {
+		total += n
+	}
+	return total
+}
+
+// newTestFileMonitor 创建监控临时目录的文件监控器
+func newTestFileMonitor(t *testing.T, patterns []string, debounce time.Duration) (*FileMonitor, *scanRecorder, string) {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	recorder := newScanRecorder()
+	monitor, err := NewFileMonitor(logger, patterns, debounce, recorder.scan)
+	require.NoError(t, err)
+	t.Cleanup(func() { monitor.Stop() })
+	require.NoError(t, monitor.Watch(dir))
+	return monitor, recorder, dir
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestFileMonitor_ScansOnlyMatchingTypes(t *testing.T) {
+	_, recorder, dir := newTestFileMonitor(t, []string{".txt", "report_*.csv"}, 50*time.Millisecond)
+
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "4111-1111-1111-1111")
+	writeTestFile(t, filepath.Join(dir, "NOTES2.TXT"), "大写扩展名")
+	writeTestFile(t, filepath.Join(dir, "report_q1.csv"), "a,b")
+	writeTestFile(t, filepath.Join(dir, "data.csv"), "a,b")
+	writeTestFile(t, filepath.Join(dir, "image.png"), "png")
+
+	assert.Eventually(t, func() bool { return recorder.total() == 3 }, 2*time.Second, 10*time.Millisecond)
+	// 留出时间确认不匹配的文件不会被扫描
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 1, recorder.count("notes.txt"))
+	assert.Equal(t, 1, recorder.count("NOTES2.TXT"))
+	assert.Equal(t, 1, recorder.count("report_q1.csv"))
+	assert.Zero(t, recorder.count("data.csv"))
+	assert.Zero(t, recorder.count("image.png"))
+}
+
+func TestFileMonitor_DebouncesRapidChanges(t *testing.T) {
+	_, recorder, dir := newTestFileMonitor(t, []string{".txt"}, 200*time.Millisecond)
+
+	path := filepath.Join(dir, "draft.txt")
+	for i := 0; i < 10; i++ {
+		writeTestFile(t, path, "第"+string(rune('0'+i))+"次修改")
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	assert.Eventually(t, func() bool { return recorder.count("draft.txt") == 1 }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(400 * time.Millisecond)
+	assert.Equal(t, 1, recorder.count("draft.txt"), "连续修改应该合并为一次扫描")
+
+	// 去抖时间过后的修改再次触发扫描
+	writeTestFile(t, path, "再次修改")
+	assert.Eventually(t, func() bool { return recorder.count("draft.txt") == 2 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestFileMonitor_RenameAndSubdirectories(t *testing.T) {
+	_, recorder, dir := newTestFileMonitor(t, []string{".txt"}, 50*time.Millisecond)
+
+	// 重命名为监控类型时扫描新文件
+	tmp := filepath.Join(dir, "upload.tmp")
+	writeTestFile(t, tmp, "临时文件")
+	require.NoError(t, os.Rename(tmp, filepath.Join(dir, "upload.txt")))
+	assert.Eventually(t, func() bool { return recorder.count("upload.txt") == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	// 新建的子目录自动加入监控
+	sub := filepath.Join(dir, "sub")
+	require.NoError(t, os.Mkdir(sub, 0755))
+	assert.Eventually(t, func() bool {
+		writeTestFile(t, filepath.Join(sub, "nested.txt"), "子目录文件")
+		return recorder.count("nested.txt") > 0
+	}, 2*time.Second, 100*time.Millisecond)
+	assert.Zero(t, recorder.count("upload.tmp"))
+}
+
+func TestFileMonitor_StopCancelsPendingScans(t *testing.T) {
+	monitor, recorder, dir := newTestFileMonitor(t, []string{".txt"}, 300*time.Millisecond)
+
+	writeTestFile(t, filepath.Join(dir, "late.txt"), "内容")
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, monitor.Stop())
+
+	time.Sleep(400 * time.Millisecond)
+	assert.Zero(t, recorder.total())
+	assert.NoError(t, monitor.Stop(), "重复停止应该安全")
+}
+
+func TestMatchesFileType(t *testing.T) {
+	patterns := []string{".txt", ".DOCX", "report_*.csv"}
+
+	assert.True(t, matchesFileType(patterns, "/data/a.txt"))
+	assert.True(t, matchesFileType(patterns, "/data/A.docx"))
+	assert.True(t, matchesFileType(patterns, "/data/report_2024.csv"))
+	assert.False(t, matchesFileType(patterns, "/data/summary.csv"))
+	assert.False(t, matchesFileType(patterns, "/data/a.txt.bak"))
+	assert.True(t, matchesFileType(nil, "/data/anything.bin"))
+}
+
+func TestScanner_MonitorFiles(t *testing.T) {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	scanner := NewScanner(logger, NewRuleManager(logger), NewAlertManager(), map[string]interface{}{
+		"monitor_files":         true,
+		"monitored_directories": []interface{}{dir, filepath.Join(dir, "missing")},
+		"monitored_file_types":  []interface{}{".txt"},
+	})
+
+	require.NoError(t, scanner.MonitorFiles())
+	assert.NotNil(t, scanner.fileMonitor)
+	require.NoError(t, scanner.StopMonitoring())
+	assert.Nil(t, scanner.fileMonitor)
+
+	// 所有目录都无法监控时返回错误
+	scanner.config["monitored_directories"] = []interface{}{filepath.Join(dir, "missing")}
+	assert.Error(t, scanner.MonitorFiles())
+}
+
+func TestExpandMonitoredPath(t *testing.T) {
+	t.Setenv("KENNEL_TEST_USER", "alice")
+
+	assert.Equal(t, "/home/alice/docs", expandMonitoredPath("/home/%KENNEL_TEST_USER%/docs"))
+	assert.Equal(t, "/home/alice/docs", expandMonitoredPath("/home/$KENNEL_TEST_USER/docs"))
+	assert.Equal(t, "/home/%KENNEL_UNSET_VAR%/docs", expandMonitoredPath("/home/%KENNEL_UNSET_VAR%/docs"))
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// 敏感数据统计的默认配置
+const (
+	DefaultFindingStatsResolution       = time.Minute
+	DefaultFindingStatsRetention        = time.Hour
+	DefaultFindingStatsRollupResolution = time.Hour
+	DefaultFindingStatsRollupRetention  = 7 * 24 * time.Hour
+	DefaultFindingStatsMaxKeys          = 500
+)
+
+// findingStatsOther 单个时间桶内的目的地址或进程超出上限后归并到的取值
+const findingStatsOther = "other"
+
+// 敏感数据统计支持的分组维度
+const (
+	FindingStatsByType        = "type"
+	FindingStatsByDestination = "destination"
+	FindingStatsByProcess     = "process"
+)
+
+// FindingStatsConfig 敏感数据统计配置
+// 近期数据按 Resolution 分桶保留 Retention，更早的数据汇总为 RollupResolution 的桶保留 RollupRetention
+type FindingStatsConfig struct {
+	Enabled          bool          `yaml:"enabled" json:"enabled"`
+	Resolution       time.Duration `yaml:"resolution" json:"resolution"`
+	Retention        time.Duration `yaml:"retention" json:"retention"`
+	RollupResolution time.Duration `yaml:"rollup_resolution" json:"rollup_resolution"`
+	RollupRetention  time.Duration `yaml:"rollup_retention" json:"rollup_retention"`
+	MaxKeys          int           `yaml:"max_keys" json:"max_keys"` // 单个时间桶内的最大统计项数
+}
+
+// DefaultFindingStatsConfig 返回默认敏感数据统计配置
+func DefaultFindingStatsConfig() FindingStatsConfig {
+	return FindingStatsConfig{
+		Enabled:          true,
+		Resolution:       DefaultFindingStatsResolution,
+		Retention:        DefaultFindingStatsRetention,
+		RollupResolution: DefaultFindingStatsRollupResolution,
+		RollupRetention:  DefaultFindingStatsRollupRetention,
+		MaxKeys:          DefaultFindingStatsMaxKeys,
+	}
+}
+
+// parseFindingStatsSettings 解析敏感数据统计配置，时间单位为秒
+func parseFindingStatsSettings(settings map[string]interface{}, config *FindingStatsConfig) {
+	config.Enabled = sdk.GetConfigBool(settings, "enabled", config.Enabled)
+	config.Resolution = time.Duration(sdk.GetConfigInt(settings, "resolution", int(config.Resolution/time.Second))) * time.Second
+	config.Retention = time.Duration(sdk.GetConfigInt(settings, "retention", int(config.Retention/time.Second))) * time.Second
+	config.RollupResolution = time.Duration(sdk.GetConfigInt(settings, "rollup_resolution", int(config.RollupResolution/time.Second))) * time.Second
+	config.RollupRetention = time.Duration(sdk.GetConfigInt(settings, "rollup_retention", int(config.RollupRetention/time.Second))) * time.Second
+	config.MaxKeys = sdk.GetConfigInt(settings, "max_keys", config.MaxKeys)
+}
+
+// FindingStatsQuery 敏感数据统计查询条件
+type FindingStatsQuery struct {
+	Since       time.Time     `json:"since"`
+	Until       time.Time     `json:"until"`
+	Window      time.Duration `json:"window"`      // 聚合窗口，不足统计分辨率时使用统计分辨率
+	GroupBy     []string      `json:"group_by"`    // 分组维度：type、destination、process，默认按类型分组
+	Type        string        `json:"type"`        // 只统计指定类型
+	Destination string        `json:"destination"` // 只统计指定目的地址
+	Process     string        `json:"process"`     // 只统计指定进程
+}
+
+// FindingStatsCount 一个分组的敏感数据数量，未参与分组的维度为空
+type FindingStatsCount struct {
+	Type        string `json:"type,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Process     string `json:"process,omitempty"`
+	Count       uint64 `json:"count"`
+}
+
+// FindingStatsWindow 一个聚合窗口内的统计
+type FindingStatsWindow struct {
+	Start  time.Time           `json:"start"`
+	Counts []FindingStatsCount `json:"counts"` // 按数量从多到少排列
+	Total  uint64              `json:"total"`
+}
+
+// FindingStatsResult 敏感数据统计查询结果
+type FindingStatsResult struct {
+	Since   time.Time            `json:"since"`
+	Until   time.Time            `json:"until"`
+	Window  time.Duration        `json:"window"`
+	GroupBy []string             `json:"group_by"`
+	Windows []FindingStatsWindow `json:"windows"` // 按时间排列，只包含有数据的窗口
+	Totals  []FindingStatsCount  `json:"totals"`  // 整个查询范围内的汇总
+	Total   uint64               `json:"total"`
+}
+
+// findingKey 统计项
+type findingKey struct {
+	Type        string
+	Destination string
+	Process     string
+}
+
+// findingBucket 一个时间桶内的统计
+type findingBucket struct {
+	start  time.Time
+	counts map[findingKey]uint64
+}
+
+// findingStats 按敏感数据类型、目的地址和进程统计检测结果
+// 统计项数超出上限时，目的地址和进程归并为 other，超出保留时间的时间桶被汇总或删除，内存占用有上限
+type findingStats struct {
+	mu      sync.Mutex
+	config  FindingStatsConfig
+	buckets map[int64]*findingBucket // 近期的细粒度时间桶，按起始时间索引
+	rollups map[int64]*findingBucket // 汇总后的粗粒度时间桶
+}
+
+// newFindingStats 创建敏感数据统计，无效的配置项使用默认值
+func newFindingStats(config FindingStatsConfig) *findingStats {
+	defaults := DefaultFindingStatsConfig()
+	if config.Resolution <= 0 {
+		config.Resolution = defaults.Resolution
+	}
+	if config.Retention < config.Resolution {
+		config.Retention = config.Resolution
+	}
+	if config.RollupResolution < config.Resolution {
+		config.RollupResolution = config.Resolution
+	}
+	if config.RollupRetention < config.RollupResolution {
+		config.RollupRetention = config.RollupResolution
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = defaults.MaxKeys
+	}
+
+	return &findingStats{
+		config:  config,
+		buckets: make(map[int64]*findingBucket),
+		rollups: make(map[int64]*findingBucket),
+	}
+}
+
+// recordDecision 记录一次策略决策中检测到的敏感数据
+func (fs *findingStats) recordDecision(at time.Time, ctx *engine.DecisionContext) {
+	if fs == nil || ctx == nil {
+		return
+	}
+	summary := ctx.FindingSummary()
+	if summary.Count == 0 {
+		return
+	}
+
+	destination, process := "", ""
+	if packet := ctx.PacketInfo; packet != nil {
+		if packet.DestIP != nil {
+			destination = packet.DestIP.String()
+		}
+		if packet.ProcessInfo != nil {
+			process = packet.ProcessInfo.ProcessName
+		}
+	} else if ctx.ParsedData != nil {
+		// 文件和剪贴板数据以数据来源作为目的地址
+		destination = ctx.ParsedData.Protocol
+	}
+	fs.record(at, summary.Counts, destination, process)
+}
+
+// record 记录指定时间检测到的各类型敏感数据数量
+func (fs *findingStats) record(at time.Time, counts map[string]int, destination, process string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.compact(at)
+	bucket := fs.bucket(fs.buckets, at.Truncate(fs.config.Resolution))
+
+	for dataType, count := range counts {
+		if count <= 0 {
+			continue
+		}
+		bucket.add(findingKey{Type: dataType, Destination: destination, Process: process}, uint64(count), fs.config.MaxKeys)
+	}
+}
+
+// fineCutoff 返回细粒度时间桶的保留起点
+func (fs *findingStats) fineCutoff(now time.Time) time.Time {
+	return now.Add(-fs.config.Retention).Truncate(fs.config.Resolution)
+}
+
+// bucket 获取或创建指定起始时间的时间桶
+func (fs *findingStats) bucket(buckets map[int64]*findingBucket, start time.Time) *findingBucket {
+	bucket, exists := buckets[start.UnixNano()]
+	if !exists {
+		bucket = &findingBucket{start: start, counts: make(map[findingKey]uint64)}
+		buckets[start.UnixNano()] = bucket
+	}
+	return bucket
+}
+
+// compact 将超出细粒度保留时间的时间桶汇总为粗粒度时间桶，删除超出汇总保留时间的时间桶
+func (fs *findingStats) compact(now time.Time) {
+	fineCutoff := fs.fineCutoff(now)
+	for key, bucket := range fs.buckets {
+		if !bucket.start.Before(fineCutoff) {
+			continue
+		}
+		rollup := fs.bucket(fs.rollups, bucket.start.Truncate(fs.config.RollupResolution))
+		for item, count := range bucket.counts {
+			rollup.add(item, count, fs.config.MaxKeys)
+		}
+		delete(fs.buckets, key)
+	}
+
+	rollupCutoff := now.Add(-fs.config.RollupRetention).Truncate(fs.config.RollupResolution)
+	for key, bucket := range fs.rollups {
+		if bucket.start.Before(rollupCutoff) {
+			delete(fs.rollups, key)
+		}
+	}
+}
+
+// add 累加统计项，统计项数已达上限时将目的地址和进程归并为 other
+func (b *findingBucket) add(key findingKey, count uint64, maxKeys int) {
+	if _, exists := b.counts[key]; !exists && len(b.counts) >= maxKeys {
+		key.Destination = findingStatsOther
+		key.Process = findingStatsOther
+	}
+	b.counts[key] += count
+}
+
+// query 按条件查询统计结果
+func (fs *findingStats) query(q FindingStatsQuery, now time.Time) (*FindingStatsResult, error) {
+	groupBy := q.GroupBy
+	if len(groupBy) == 0 {
+		groupBy = []string{FindingStatsByType}
+	}
+	group := make(map[string]bool, len(groupBy))
+	for _, field := range groupBy {
+		switch field {
+		case FindingStatsByType, FindingStatsByDestination, FindingStatsByProcess:
+			group[field] = true
+		default:
+			return nil, fmt.Errorf("不支持的分组维度: %s", field)
+		}
+	}
+
+	if q.Until.IsZero() {
+		q.Until = now
+	}
+	if q.Since.IsZero() {
+		q.Since = q.Until.Add(-fs.config.Retention)
+	}
+	if !q.Since.Before(q.Until) {
+		return nil, fmt.Errorf("查询起始时间必须早于结束时间")
+	}
+	if q.Window < fs.config.Resolution {
+		q.Window = fs.config.Resolution
+	}
+
+	fs.mu.Lock()
+	fs.compact(now)
+	windows := make(map[int64]map[findingKey]uint64)
+	for _, buckets := range []map[int64]*findingBucket{fs.rollups, fs.buckets} {
+		for _, bucket := range buckets {
+			if bucket.start.Before(q.Since) || !bucket.start.Before(q.Until) {
+				continue
+			}
+			start := bucket.start.Truncate(q.Window).UnixNano()
+			for key, count := range bucket.counts {
+				if !q.matches(key) {
+					continue
+				}
+				if windows[start] == nil {
+					windows[start] = make(map[findingKey]uint64)
+				}
+				windows[start][key.project(group)] += count
+			}
+		}
+	}
+	fs.mu.Unlock()
+
+	result := &FindingStatsResult{
+		Since:   q.Since,
+		Until:   q.Until,
+		Window:  q.Window,
+		GroupBy: groupBy,
+		Windows: make([]FindingStatsWindow, 0, len(windows)),
+	}
+	totals := make(map[findingKey]uint64)
+	for start, counts := range windows {
+		window := FindingStatsWindow{Start: time.Unix(0, start).In(q.Until.Location()), Counts: sortedFindingCounts(counts)}
+		for key, count := range counts {
+			window.Total += count
+			totals[key] += count
+		}
+		result.Total += window.Total
+		result.Windows = append(result.Windows, window)
+	}
+	sort.Slice(result.Windows, func(i, j int) bool {
+		return result.Windows[i].Start.Before(result.Windows[j].Start)
+	})
+	result.Totals = sortedFindingCounts(totals)
+	return result, nil
+}
+
+// matches 检查统计项是否满足过滤条件
+func (q FindingStatsQuery) matches(key findingKey) bool {
+	return (q.Type == "" || key.Type == q.Type) &&
+		(q.Destination == "" || key.Destination == q.Destination) &&
+		(q.Process == "" || key.Process == q.Process)
+}
+
+// project 只保留参与分组的维度
+func (k findingKey) project(group map[string]bool) findingKey {
+	var projected findingKey
+	if group[FindingStatsByType] {
+		projected.Type = k.Type
+	}
+	if group[FindingStatsByDestination] {
+		projected.Destination = k.Destination
+	}
+	if group[FindingStatsByProcess] {
+		projected.Process = k.Process
+	}
+	return projected
+}
+
+// sortedFindingCounts 将统计项按数量从多到少排列，数量相同时按维度排序
+func sortedFindingCounts(counts map[findingKey]uint64) []FindingStatsCount {
+	result := make([]FindingStatsCount, 0, len(counts))
+	for key, count := range counts {
+		result = append(result, FindingStatsCount{
+			Type:        key.Type,
+			Destination: key.Destination,
+			Process:     key.Process,
+			Count:       count,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Destination != b.Destination {
+			return a.Destination < b.Destination
+		}
+		return a.Process < b.Process
+	})
+	return result
+}
+
+// parseFindingStatsQuery 解析请求参数中的查询条件
+// since、until 为 RFC3339 时间，window 单位为秒，group_by 为维度列表或逗号分隔的字符串
+func parseFindingStatsQuery(params map[string]interface{}) (FindingStatsQuery, error) {
+	query := FindingStatsQuery{
+		Window:      time.Duration(sdk.GetConfigInt(params, "window", 0)) * time.Second,
+		Type:        sdk.GetConfigString(params, "type", ""),
+		Destination: sdk.GetConfigString(params, "destination", ""),
+		Process:     sdk.GetConfigString(params, "process", ""),
+	}
+
+	for key, target := range map[string]*time.Time{"since": &query.Since, "until": &query.Until} {
+		value := sdk.GetConfigString(params, key, "")
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return query, sdk.InvalidParamError("%s 必须是RFC3339时间: %v", key, err)
+		}
+		*target = parsed
+	}
+
+	if groupBy, ok := params["group_by"].(string); ok {
+		for _, field := range strings.Split(groupBy, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				query.GroupBy = append(query.GroupBy, field)
+			}
+		}
+	} else {
+		query.GroupBy = sdk.GetConfigStringSlice(params, "group_by")
+	}
+	return query, nil
+}
+
+// getFindingStats 查询敏感数据统计，供仪表盘使用
+func (m *DLPModule) getFindingStats(params map[string]interface{}) (*FindingStatsResult, error) {
+	if m.findingStats == nil {
+		return nil, fmt.Errorf("敏感数据统计未启用")
+	}
+	query, err := parseFindingStatsQuery(params)
+	if err != nil {
+		return nil, err
+	}
+	result, err := m.findingStats.query(query, time.Now())
+	if err != nil {
+		return nil, sdk.InvalidParamError("%v", err)
+	}
+	return result, nil
+}
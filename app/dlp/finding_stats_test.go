@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var findingStatsBase = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// windowCounts 将查询结果转换为 窗口起始时间 -> 类型 -> 数量
+func windowCounts(result *FindingStatsResult) map[time.Time]map[string]uint64 {
+	counts := make(map[time.Time]map[string]uint64)
+	for _, window := range result.Windows {
+		counts[window.Start] = make(map[string]uint64)
+		for _, count := range window.Counts {
+			counts[window.Start][count.Type] += count.Count
+		}
+	}
+	return counts
+}
+
+func TestFindingStats_CountsByTypeAndWindow(t *testing.T) {
+	stats := newFindingStats(DefaultFindingStatsConfig())
+
+	stats.record(findingStatsBase.Add(10*time.Second), map[string]int{"credit_card": 2, "ssn": 1}, "203.0.113.5", "chrome.exe")
+	stats.record(findingStatsBase.Add(50*time.Second), map[string]int{"credit_card": 1}, "198.51.100.7", "outlook.exe")
+	stats.record(findingStatsBase.Add(3*time.Minute), map[string]int{"ssn": 4}, "203.0.113.5", "chrome.exe")
+	stats.record(findingStatsBase.Add(7*time.Minute), map[string]int{"credit_card": 5, "email": 0}, "203.0.113.5", "curl")
+
+	now := findingStatsBase.Add(10 * time.Minute)
+	result, err := stats.query(FindingStatsQuery{Since: findingStatsBase, Until: now}, now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, result.Window)
+	assert.Equal(t, map[time.Time]map[string]uint64{
+		findingStatsBase:                      {"credit_card": 3, "ssn": 1},
+		findingStatsBase.Add(3 * time.Minute): {"ssn": 4},
+		findingStatsBase.Add(7 * time.Minute): {"credit_card": 5},
+	}, windowCounts(result))
+	assert.Equal(t, []FindingStatsCount{
+		{Type: "credit_card", Count: 8},
+		{Type: "ssn", Count: 5},
+	}, result.Totals)
+	assert.Equal(t, uint64(13), result.Total)
+
+	// 5分钟窗口
+	result, err = stats.query(FindingStatsQuery{Since: findingStatsBase, Until: now, Window: 5 * time.Minute}, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[time.Time]map[string]uint64{
+		findingStatsBase:                      {"credit_card": 3, "ssn": 5},
+		findingStatsBase.Add(5 * time.Minute): {"credit_card": 5},
+	}, windowCounts(result))
+
+	// 查询范围只包含部分窗口
+	result, err = stats.query(FindingStatsQuery{Since: findingStatsBase.Add(time.Minute), Until: now}, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), result.Total)
+}
+
+func TestFindingStats_GroupByAndFilter(t *testing.T) {
+	stats := newFindingStats(DefaultFindingStatsConfig())
+	stats.record(findingStatsBase, map[string]int{"credit_card": 2}, "203.0.113.5", "chrome.exe")
+	stats.record(findingStatsBase, map[string]int{"credit_card": 1, "ssn": 3}, "198.51.100.7", "chrome.exe")
+	stats.record(findingStatsBase, map[string]int{"ssn": 1}, "203.0.113.5", "curl")
+
+	now := findingStatsBase.Add(time.Minute)
+	result, err := stats.query(FindingStatsQuery{Until: now, GroupBy: []string{FindingStatsByDestination}}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []FindingStatsCount{
+		{Destination: "198.51.100.7", Count: 4},
+		{Destination: "203.0.113.5", Count: 3},
+	}, result.Totals)
+
+	result, err = stats.query(FindingStatsQuery{
+		Until:   now,
+		GroupBy: []string{FindingStatsByType, FindingStatsByProcess},
+		Type:    "ssn",
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []FindingStatsCount{
+		{Type: "ssn", Process: "chrome.exe", Count: 3},
+		{Type: "ssn", Process: "curl", Count: 1},
+	}, result.Totals)
+
+	_, err = stats.query(FindingStatsQuery{Until: now, GroupBy: []string{"user"}}, now)
+	assert.Error(t, err)
+	_, err = stats.query(FindingStatsQuery{Since: now, Until: findingStatsBase}, now)
+	assert.Error(t, err)
+}
+
+func TestFindingStats_RollupAndRetention(t *testing.T) {
+	stats := newFindingStats(FindingStatsConfig{
+		Resolution:       time.Minute,
+		Retention:        10 * time.Minute,
+		RollupResolution: time.Hour,
+		RollupRetention:  24 * time.Hour,
+		MaxKeys:          100,
+	})
+
+	// 每分钟一条，持续3小时
+	for i := 0; i < 180; i++ {
+		stats.record(findingStatsBase.Add(time.Duration(i)*time.Minute), map[string]int{"credit_card": 1}, "203.0.113.5", "chrome.exe")
+	}
+
+	now := findingStatsBase.Add(3 * time.Hour)
+	stats.mu.Lock()
+	stats.compact(now)
+	assert.LessOrEqual(t, len(stats.buckets), 11)
+	assert.Len(t, stats.rollups, 3)
+	stats.mu.Unlock()
+
+	// 汇总后总数不变，早期数据按小时聚合
+	result, err := stats.query(FindingStatsQuery{Since: findingStatsBase, Until: now, Window: time.Hour}, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(180), result.Total)
+	assert.Equal(t, map[time.Time]map[string]uint64{
+		findingStatsBase:                    {"credit_card": 60},
+		findingStatsBase.Add(time.Hour):     {"credit_card": 60},
+		findingStatsBase.Add(2 * time.Hour): {"credit_card": 60},
+	}, windowCounts(result))
+
+	// 超出汇总保留时间的数据被删除
+	later := findingStatsBase.Add(26 * time.Hour)
+	result, err = stats.query(FindingStatsQuery{Since: findingStatsBase, Until: later, Window: time.Hour}, later)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(60), result.Total)
+}
+
+func TestFindingStats_MaxKeysCollapsesToOther(t *testing.T) {
+	stats := newFindingStats(FindingStatsConfig{MaxKeys: 3})
+	for i := 0; i < 10; i++ {
+		stats.record(findingStatsBase, map[string]int{"credit_card": 1}, fmt.Sprintf("203.0.113.%d", i), "chrome.exe")
+	}
+
+	stats.mu.Lock()
+	for _, bucket := range stats.buckets {
+		assert.Len(t, bucket.counts, 4)
+	}
+	stats.mu.Unlock()
+
+	now := findingStatsBase.Add(time.Minute)
+	result, err := stats.query(FindingStatsQuery{Until: now, GroupBy: []string{FindingStatsByDestination}}, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), result.Total)
+	assert.Equal(t, FindingStatsCount{Destination: findingStatsOther, Count: 7}, result.Totals[0])
+}
+
+func TestFindingStats_RecordDecision(t *testing.T) {
+	stats := newFindingStats(DefaultFindingStatsConfig())
+
+	stats.recordDecision(findingStatsBase, &engine.DecisionContext{
+		PacketInfo: &interceptor.PacketInfo{
+			DestIP:      net.ParseIP("203.0.113.5"),
+			ProcessInfo: &interceptor.ProcessInfo{ProcessName: "chrome.exe"},
+		},
+		AnalysisResult: &analyzer.AnalysisResult{SensitiveData: []*analyzer.SensitiveDataInfo{
+			{Type: "credit_card"}, {Type: "credit_card"}, {Type: "ssn"},
+		}},
+	})
+	stats.recordDecision(findingStatsBase, &engine.DecisionContext{
+		ParsedData: &parser.ParsedData{Protocol: "clipboard"},
+		AnalysisResult: &analyzer.AnalysisResult{SensitiveData: []*analyzer.SensitiveDataInfo{
+			{Type: "ssn"},
+		}},
+	})
+	// 没有敏感数据时不记录
+	stats.recordDecision(findingStatsBase, &engine.DecisionContext{AnalysisResult: &analyzer.AnalysisResult{}})
+
+	now := findingStatsBase.Add(time.Minute)
+	result, err := stats.query(FindingStatsQuery{
+		Until:   now,
+		GroupBy: []string{FindingStatsByType, FindingStatsByDestination, FindingStatsByProcess},
+	}, now)
+	require.NoError(t, err)
+	assert.Equal(t, []FindingStatsCount{
+		{Type: "credit_card", Destination: "203.0.113.5", Process: "chrome.exe", Count: 2},
+		{Type: "ssn", Destination: "203.0.113.5", Process: "chrome.exe", Count: 1},
+		{Type: "ssn", Destination: "clipboard", Count: 1},
+	}, result.Totals)
+
+	// 未启用时忽略
+	var disabled *findingStats
+	disabled.recordDecision(findingStatsBase, &engine.DecisionContext{})
+}
+
+func TestParseFindingStatsQuery(t *testing.T) {
+	query, err := parseFindingStatsQuery(map[string]interface{}{
+		"since":    "2024-03-01T10:00:00Z",
+		"window":   300,
+		"group_by": "type, destination",
+		"type":     "ssn",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, findingStatsBase, query.Since)
+	assert.True(t, query.Until.IsZero())
+	assert.Equal(t, 5*time.Minute, query.Window)
+	assert.Equal(t, []string{"type", "destination"}, query.GroupBy)
+	assert.Equal(t, "ssn", query.Type)
+
+	query, err = parseFindingStatsQuery(map[string]interface{}{"group_by": []interface{}{"process"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"process"}, query.GroupBy)
+
+	_, err = parseFindingStatsQuery(map[string]interface{}{"until": "yesterday"})
+	assert.Error(t, err)
+}
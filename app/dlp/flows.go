@@ -0,0 +1,55 @@
+package main
+
+import (
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// parseFlowTrackerSettings 解析流跟踪配置，超时时间单位为秒
+func parseFlowTrackerSettings(settings map[string]interface{}, config *interceptor.FlowTrackerConfig) {
+	config.Enabled = sdk.GetConfigBool(settings, "enabled", config.Enabled)
+	config.IdleTimeout = time.Duration(sdk.GetConfigInt(settings, "idle_timeout", int(config.IdleTimeout/time.Second))) * time.Second
+	config.ClosingTimeout = time.Duration(sdk.GetConfigInt(settings, "closing_timeout", int(config.ClosingTimeout/time.Second))) * time.Second
+	config.MaxFlows = sdk.GetConfigInt(settings, "max_flows", config.MaxFlows)
+}
+
+// handleFlowEvent 记录流的开始和结束，流结束时输出汇总供审计
+func (m *DLPModule) handleFlowEvent(event interceptor.FlowEvent) {
+	flow := event.Flow
+	process := ""
+	if flow.ProcessInfo != nil {
+		process = flow.ProcessInfo.ProcessName
+	}
+
+	switch event.Type {
+	case interceptor.FlowEventStart:
+		m.Logger.Debug("网络流开始", "flow_id", flow.ID, "flow", flow.Key.String(), "process", process)
+	case interceptor.FlowEventEnd:
+		m.Logger.Info("网络流结束",
+			"flow_id", flow.ID,
+			"flow", flow.Key.String(),
+			"process", process,
+			"reason", flow.EndReason,
+			"bytes_out", flow.BytesOut,
+			"bytes_in", flow.BytesIn,
+			"packets", flow.Packets(),
+			"duration", flow.Duration())
+	}
+}
+
+// flowExpiryLoop 定期结束空闲的流，没有新数据包时空闲的流也能及时发出结束事件
+func (m *DLPModule) flowExpiryLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.flowTracker.Expire()
+		case <-m.stopCh:
+			return
+		}
+	}
+}
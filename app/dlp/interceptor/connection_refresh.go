@@ -0,0 +1,217 @@
+package interceptor
+
+import (
+	"sync"
+	"time"
+)
+
+// ConnectionRefreshConfig 连接表自适应刷新配置
+type ConnectionRefreshConfig struct {
+	MinInterval  time.Duration `yaml:"min_interval" json:"min_interval"`     // 未命中率高时定期刷新间隔的下限
+	MaxInterval  time.Duration `yaml:"max_interval" json:"max_interval"`     // 未命中率低时定期刷新间隔的上限
+	MissCooldown time.Duration `yaml:"miss_cooldown" json:"miss_cooldown"`   // 距上次刷新不足该时长时，查找未命中不再触发刷新
+	HighMissRate float64       `yaml:"high_miss_rate" json:"high_miss_rate"` // 统计周期内未命中率高于该值时刷新间隔减半
+	LowMissRate  float64       `yaml:"low_miss_rate" json:"low_miss_rate"`   // 统计周期内未命中率低于该值时刷新间隔加倍
+	MinSamples   int64         `yaml:"min_samples" json:"min_samples"`       // 统计周期内查找次数少于该值时不调整间隔
+}
+
+// DefaultConnectionRefreshConfig 返回默认连接表刷新配置
+func DefaultConnectionRefreshConfig() ConnectionRefreshConfig {
+	return ConnectionRefreshConfig{
+		MinInterval:  time.Second,
+		MaxInterval:  30 * time.Second,
+		MissCooldown: 200 * time.Millisecond,
+		HighMissRate: 0.2,
+		LowMissRate:  0.02,
+		MinSamples:   20,
+	}
+}
+
+// ConnectionRefreshStats 连接表刷新统计
+type ConnectionRefreshStats struct {
+	Refreshes          int64         `json:"refreshes"`           // 实际执行的刷新次数
+	MissRefreshes      int64         `json:"miss_refreshes"`      // 由查找未命中触发的刷新次数
+	PeriodicRefreshes  int64         `json:"periodic_refreshes"`  // 定期刷新次数
+	CoalescedRefreshes int64         `json:"coalesced_refreshes"` // 合并到进行中刷新的请求数
+	SkippedRefreshes   int64         `json:"skipped_refreshes"`   // 刚刷新过而跳过的未命中刷新请求数
+	FailedRefreshes    int64         `json:"failed_refreshes"`    // 失败的刷新次数
+	Lookups            int64         `json:"lookups"`             // 进程查找总次数
+	Misses             int64         `json:"misses"`              // 进程查找未命中总次数
+	MissRate           float64       `json:"miss_rate"`           // 最近一个统计周期的未命中率
+	Interval           time.Duration `json:"interval"`            // 当前定期刷新间隔
+}
+
+// refreshCall 进行中的刷新，并发请求等待同一次刷新的结果
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// ConnectionRefresher 连接表刷新调度器
+// 并发的刷新请求合并为一次刷新；定期刷新间隔按查找未命中率在上下限之间自适应调整
+type ConnectionRefresher struct {
+	config  ConnectionRefreshConfig
+	refresh func() error
+	now     func() time.Time
+
+	mu            sync.Mutex
+	inflight      *refreshCall
+	lastRefresh   time.Time // 最近一次刷新开始的时间
+	interval      time.Duration
+	windowLookups int64 // 当前统计周期的查找次数
+	windowMisses  int64 // 当前统计周期的未命中次数
+	stats         ConnectionRefreshStats
+}
+
+// NewConnectionRefresher 创建连接表刷新调度器，refresh 执行实际的连接表刷新
+func NewConnectionRefresher(config ConnectionRefreshConfig, refresh func() error) *ConnectionRefresher {
+	defaults := DefaultConnectionRefreshConfig()
+	if config.MinInterval <= 0 {
+		config.MinInterval = defaults.MinInterval
+	}
+	if config.MaxInterval < config.MinInterval {
+		config.MaxInterval = config.MinInterval
+	}
+	if config.MissCooldown < 0 {
+		config.MissCooldown = 0
+	}
+	if config.HighMissRate <= 0 {
+		config.HighMissRate = defaults.HighMissRate
+	}
+	if config.LowMissRate < 0 || config.LowMissRate > config.HighMissRate {
+		config.LowMissRate = defaults.LowMissRate
+	}
+
+	return &ConnectionRefresher{
+		config:   config,
+		refresh:  refresh,
+		now:      time.Now,
+		interval: config.MinInterval,
+	}
+}
+
+// SetInterval 设置定期刷新的初始间隔，限制在配置的上下限之间
+func (r *ConnectionRefresher) SetInterval(interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interval = r.clamp(interval)
+}
+
+// Interval 返回当前定期刷新间隔
+func (r *ConnectionRefresher) Interval() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.interval
+}
+
+// RecordHit 记录一次命中连接表的进程查找
+func (r *ConnectionRefresher) RecordHit() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.Lookups++
+	r.windowLookups++
+}
+
+// RefreshOnMiss 记录一次未命中的进程查找并按需刷新连接表
+// 已有刷新进行中时等待其完成而不重复刷新；距上次刷新不足 MissCooldown 时直接返回
+func (r *ConnectionRefresher) RefreshOnMiss() error {
+	r.mu.Lock()
+	r.stats.Lookups++
+	r.stats.Misses++
+	r.windowLookups++
+	r.windowMisses++
+
+	if call := r.inflight; call != nil {
+		r.stats.CoalescedRefreshes++
+		r.mu.Unlock()
+		<-call.done
+		return call.err
+	}
+	if !r.lastRefresh.IsZero() && r.now().Sub(r.lastRefresh) < r.config.MissCooldown {
+		r.stats.SkippedRefreshes++
+		r.mu.Unlock()
+		return nil
+	}
+	r.stats.MissRefreshes++
+	return r.runLocked()
+}
+
+// RefreshPeriodic 执行一次定期刷新，返回按最近统计周期的未命中率调整后的下次刷新间隔
+func (r *ConnectionRefresher) RefreshPeriodic() (time.Duration, error) {
+	r.mu.Lock()
+	r.adaptLocked()
+	interval := r.interval
+
+	if call := r.inflight; call != nil {
+		r.stats.CoalescedRefreshes++
+		r.mu.Unlock()
+		<-call.done
+		return interval, call.err
+	}
+	r.stats.PeriodicRefreshes++
+	return interval, r.runLocked()
+}
+
+// runLocked 执行刷新，调用时需持有锁，执行刷新前释放锁
+func (r *ConnectionRefresher) runLocked() error {
+	call := &refreshCall{done: make(chan struct{})}
+	r.inflight = call
+	r.lastRefresh = r.now()
+	r.mu.Unlock()
+
+	call.err = r.refresh()
+
+	r.mu.Lock()
+	r.inflight = nil
+	r.stats.Refreshes++
+	if call.err != nil {
+		r.stats.FailedRefreshes++
+	}
+	r.mu.Unlock()
+
+	close(call.done)
+	return call.err
+}
+
+// adaptLocked 按当前统计周期的未命中率调整刷新间隔并开始新的统计周期，调用方需持有锁
+func (r *ConnectionRefresher) adaptLocked() {
+	if r.windowLookups == 0 {
+		return
+	}
+
+	rate := float64(r.windowMisses) / float64(r.windowLookups)
+	r.stats.MissRate = rate
+	if r.windowLookups < r.config.MinSamples {
+		return
+	}
+
+	switch {
+	case rate > r.config.HighMissRate:
+		r.interval = r.clamp(r.interval / 2)
+	case rate < r.config.LowMissRate:
+		r.interval = r.clamp(r.interval * 2)
+	}
+	r.windowLookups = 0
+	r.windowMisses = 0
+}
+
+// clamp 将间隔限制在配置的上下限之间
+func (r *ConnectionRefresher) clamp(interval time.Duration) time.Duration {
+	if interval < r.config.MinInterval {
+		return r.config.MinInterval
+	}
+	if interval > r.config.MaxInterval {
+		return r.config.MaxInterval
+	}
+	return interval
+}
+
+// Stats 返回刷新统计
+func (r *ConnectionRefresher) Stats() ConnectionRefreshStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := r.stats
+	stats.Interval = r.interval
+	return stats
+}
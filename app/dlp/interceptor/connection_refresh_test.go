@@ -0,0 +1,164 @@
+package interceptor
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRefresher 创建使用可控时钟的刷新调度器
+func newTestRefresher(config ConnectionRefreshConfig, refresh func() error) (*ConnectionRefresher, *time.Time) {
+	refresher := NewConnectionRefresher(config, refresh)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	refresher.now = func() time.Time { return now }
+	return refresher, &now
+}
+
+// recordLookups 记录 total 次查找，其中 misses 次未命中
+func recordLookups(r *ConnectionRefresher, total, misses int) {
+	for i := 0; i < total; i++ {
+		if i < misses {
+			r.RefreshOnMiss()
+		} else {
+			r.RecordHit()
+		}
+	}
+}
+
+func TestConnectionRefresherSingleFlight(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	refresher, _ := newTestRefresher(DefaultConnectionRefreshConfig(), func() error {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return errors.New("刷新失败")
+	})
+
+	const workers = 20
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+
+	// 第一个未命中开始刷新后，其余并发未命中等待同一次刷新
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		errs <- refresher.RefreshOnMiss()
+	}()
+	<-started
+	for i := 1; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- refresher.RefreshOnMiss()
+		}()
+	}
+
+	require.Eventually(t, func() bool {
+		return refresher.Stats().CoalescedRefreshes == workers-1
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	assert.Equal(t, int32(1), calls.Load())
+	for err := range errs {
+		assert.EqualError(t, err, "刷新失败", "等待的请求应得到同一次刷新的结果")
+	}
+
+	stats := refresher.Stats()
+	assert.Equal(t, int64(1), stats.Refreshes)
+	assert.Equal(t, int64(1), stats.MissRefreshes)
+	assert.Equal(t, int64(1), stats.FailedRefreshes)
+	assert.Equal(t, int64(workers), stats.Misses)
+}
+
+func TestConnectionRefresherMissCooldown(t *testing.T) {
+	calls := 0
+	refresher, now := newTestRefresher(DefaultConnectionRefreshConfig(), func() error {
+		calls++
+		return nil
+	})
+
+	require.NoError(t, refresher.RefreshOnMiss())
+	require.NoError(t, refresher.RefreshOnMiss())
+	assert.Equal(t, 1, calls, "刚刷新过时未命中不应再次刷新")
+
+	*now = now.Add(DefaultConnectionRefreshConfig().MissCooldown)
+	require.NoError(t, refresher.RefreshOnMiss())
+	assert.Equal(t, 2, calls)
+
+	stats := refresher.Stats()
+	assert.Equal(t, int64(1), stats.SkippedRefreshes)
+	assert.Equal(t, int64(3), stats.Lookups)
+}
+
+func TestConnectionRefresherAdaptiveInterval(t *testing.T) {
+	config := DefaultConnectionRefreshConfig()
+	config.MinInterval = time.Second
+	config.MaxInterval = 16 * time.Second
+	config.MinSamples = 10
+	config.MissCooldown = time.Hour // 只统计未命中，不触发刷新
+
+	refresher, _ := newTestRefresher(config, func() error { return nil })
+	refresher.SetInterval(4 * time.Second)
+
+	// 未命中率高时缩短间隔，直到下限
+	recordLookups(refresher, 10, 5)
+	interval, err := refresher.RefreshPeriodic()
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, interval)
+	assert.InDelta(t, 0.5, refresher.Stats().MissRate, 0.001)
+
+	recordLookups(refresher, 10, 5)
+	interval, _ = refresher.RefreshPeriodic()
+	assert.Equal(t, time.Second, interval)
+
+	recordLookups(refresher, 10, 5)
+	interval, _ = refresher.RefreshPeriodic()
+	assert.Equal(t, time.Second, interval, "间隔不应低于下限")
+
+	// 未命中率居中时保持不变
+	recordLookups(refresher, 10, 1)
+	interval, _ = refresher.RefreshPeriodic()
+	assert.Equal(t, time.Second, interval)
+
+	// 未命中率低时延长间隔，直到上限
+	for _, want := range []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 16 * time.Second} {
+		recordLookups(refresher, 100, 0)
+		interval, _ = refresher.RefreshPeriodic()
+		assert.Equal(t, want, interval)
+	}
+	assert.Equal(t, 0.0, refresher.Stats().MissRate)
+
+	// 样本不足时不调整，样本累积到下一周期
+	recordLookups(refresher, 5, 5)
+	interval, _ = refresher.RefreshPeriodic()
+	assert.Equal(t, 16*time.Second, interval)
+	recordLookups(refresher, 5, 5)
+	interval, _ = refresher.RefreshPeriodic()
+	assert.Equal(t, 8*time.Second, interval)
+
+	stats := refresher.Stats()
+	assert.Equal(t, int64(11), stats.PeriodicRefreshes)
+	assert.Equal(t, stats.PeriodicRefreshes+stats.MissRefreshes, stats.Refreshes)
+	assert.Equal(t, int64(1), stats.MissRefreshes, "冷却期内的未命中不应触发刷新")
+	assert.Equal(t, 8*time.Second, stats.Interval)
+}
+
+func TestConnectionRefresherSetIntervalClamped(t *testing.T) {
+	refresher, _ := newTestRefresher(DefaultConnectionRefreshConfig(), func() error { return nil })
+
+	refresher.SetInterval(time.Millisecond)
+	assert.Equal(t, DefaultConnectionRefreshConfig().MinInterval, refresher.Interval())
+
+	refresher.SetInterval(time.Hour)
+	assert.Equal(t, DefaultConnectionRefreshConfig().MaxInterval, refresher.Interval())
+}
@@ -0,0 +1,293 @@
+package interceptor
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// 过滤器切换的默认超时
+const (
+	// defaultHandoverTimeout 等待新句柄收到第一个数据包的最长时间，超时后仍然切换，避免流量稀少的过滤器阻塞切换
+	defaultHandoverTimeout = 2 * time.Second
+	// defaultDrainTimeout 旧句柄停止排队后等待接收协程取完已排队数据包的最长时间
+	defaultDrainTimeout = time.Second
+	// maxOverlapFingerprints 新旧句柄重叠期间记录的数据包指纹上限
+	maxOverlapFingerprints = 65536
+)
+
+// divertHandle 已打开的抓包句柄，由 WinDivert 等抓包后端实现
+type divertHandle interface {
+	// Recv 接收一个数据包，数据包被应用层过滤时返回 nil；句柄关闭或排空后返回错误
+	Recv(buffer []byte) (*PacketInfo, error)
+
+	// Shutdown 停止向句柄排队新的数据包，已排队的数据包仍可接收
+	Shutdown() error
+
+	// Close 关闭句柄
+	Close() error
+}
+
+// divertOpener 按过滤器打开抓包句柄
+type divertOpener func(filter string) (divertHandle, error)
+
+// captureSession 一个抓包句柄及其接收协程
+type captureSession struct {
+	filter string
+	handle divertHandle
+
+	receiving   chan struct{} // 收到第一个数据包后关闭
+	retiring    chan struct{} // 被新句柄替换后关闭，接收协程取完已排队的数据包后退出
+	receiveOnce sync.Once
+	retireOnce  sync.Once
+	workers     sync.WaitGroup
+}
+
+// newCaptureSession 创建抓包会话
+func newCaptureSession(filter string, handle divertHandle) *captureSession {
+	return &captureSession{
+		filter:    filter,
+		handle:    handle,
+		receiving: make(chan struct{}),
+		retiring:  make(chan struct{}),
+	}
+}
+
+// Filter 返回会话使用的过滤器
+func (s *captureSession) Filter() string {
+	return s.filter
+}
+
+// Go 启动会话的接收协程，会话退役时等待其退出
+func (s *captureSession) Go(worker func()) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		worker()
+	}()
+}
+
+// MarkReceiving 标记会话已收到数据包
+func (s *captureSession) MarkReceiving() {
+	s.receiveOnce.Do(func() { close(s.receiving) })
+}
+
+// Retiring 报告会话是否已被新句柄替换，此时接收失败表示句柄已排空，接收协程应退出
+func (s *captureSession) Retiring() bool {
+	select {
+	case <-s.retiring:
+		return true
+	default:
+		return false
+	}
+}
+
+// waitWorkers 等待接收协程退出，超时返回 false
+func (s *captureSession) waitWorkers(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// filterHandover 在不停止抓包的情况下切换过滤器
+// 先用新过滤器打开句柄并启动接收，新句柄收到数据包后再排空并关闭旧句柄；
+// 新旧句柄同时接收期间，两个句柄都收到的数据包只交付一次
+type filterHandover struct {
+	open   divertOpener
+	start  func(session *captureSession) // 启动会话的接收协程
+	logger logging.Logger
+
+	handoverTimeout time.Duration
+	drainTimeout    time.Duration
+
+	swapMu  sync.Mutex // 串行化过滤器切换和停止
+	mu      sync.RWMutex
+	current *captureSession
+	dedup   overlapDeduper
+}
+
+// newFilterHandover 创建过滤器切换器
+func newFilterHandover(open divertOpener, start func(session *captureSession), logger logging.Logger) *filterHandover {
+	return &filterHandover{
+		open:            open,
+		start:           start,
+		logger:          logger,
+		handoverTimeout: defaultHandoverTimeout,
+		drainTimeout:    defaultDrainTimeout,
+	}
+}
+
+// Activate 使用已打开的句柄开始抓包
+func (h *filterHandover) Activate(filter string, handle divertHandle) *captureSession {
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+
+	session := newCaptureSession(filter, handle)
+	h.start(session)
+
+	h.mu.Lock()
+	h.current = session
+	h.mu.Unlock()
+	return session
+}
+
+// Current 返回当前抓包会话，未启动时返回 nil
+func (h *filterHandover) Current() *captureSession {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.current
+}
+
+// Swap 切换到新的过滤器，打开新句柄失败时保留原句柄
+func (h *filterHandover) Swap(filter string) error {
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+
+	old := h.Current()
+	if old == nil {
+		return fmt.Errorf("抓包未启动")
+	}
+
+	handle, err := h.open(filter)
+	if err != nil {
+		return fmt.Errorf("使用新过滤器打开句柄失败: %w", err)
+	}
+
+	next := newCaptureSession(filter, handle)
+	h.dedup.begin()
+	h.start(next)
+
+	select {
+	case <-next.receiving:
+	case <-time.After(h.handoverTimeout):
+		h.logger.Warn("新过滤器在切换超时内未收到数据包，直接关闭旧句柄",
+			"filter", filter, "timeout", h.handoverTimeout)
+	}
+
+	h.mu.Lock()
+	h.current = next
+	h.mu.Unlock()
+
+	h.retire(old)
+	duplicates := h.dedup.end()
+
+	h.logger.Info("过滤器切换完成",
+		"old_filter", old.filter,
+		"new_filter", filter,
+		"duplicates_dropped", duplicates)
+	return nil
+}
+
+// Duplicate 报告数据包是否已由切换中的另一个句柄交付，重复的数据包应丢弃
+func (h *filterHandover) Duplicate(session *captureSession, packet *PacketInfo) bool {
+	return h.dedup.duplicate(session, packet)
+}
+
+// Stop 关闭当前句柄并等待接收协程退出
+func (h *filterHandover) Stop() error {
+	h.swapMu.Lock()
+	defer h.swapMu.Unlock()
+
+	h.mu.Lock()
+	session := h.current
+	h.current = nil
+	h.mu.Unlock()
+
+	if session == nil {
+		return nil
+	}
+	session.retireOnce.Do(func() { close(session.retiring) })
+	err := session.handle.Close()
+	session.workers.Wait()
+	return err
+}
+
+// retire 排空并关闭被替换的会话
+func (h *filterHandover) retire(session *captureSession) {
+	session.retireOnce.Do(func() { close(session.retiring) })
+
+	if err := session.handle.Shutdown(); err != nil {
+		h.logger.Debug("旧句柄不支持排空，直接关闭", "filter", session.filter, "error", err)
+	} else if !session.waitWorkers(h.drainTimeout) {
+		h.logger.Warn("旧句柄排空超时，直接关闭", "filter", session.filter, "timeout", h.drainTimeout)
+	}
+
+	if err := session.handle.Close(); err != nil {
+		h.logger.Warn("关闭旧句柄失败", "filter", session.filter, "error", err)
+	}
+	session.workers.Wait()
+}
+
+// overlapDeduper 新旧句柄重叠期间的重复数据包检测
+// 按方向和原始数据包内容计算指纹，一个句柄已交付的数据包再由另一个句柄收到时视为重复
+type overlapDeduper struct {
+	active     atomic.Bool
+	mu         sync.Mutex
+	seen       map[uint64]*captureSession
+	duplicates int
+}
+
+// begin 开始记录指纹
+func (d *overlapDeduper) begin() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.seen = make(map[uint64]*captureSession)
+	d.duplicates = 0
+	d.active.Store(true)
+}
+
+// end 停止记录并返回丢弃的重复数据包数
+func (d *overlapDeduper) end() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.active.Store(false)
+	d.seen = nil
+	return d.duplicates
+}
+
+// duplicate 报告数据包是否已由另一个会话交付
+func (d *overlapDeduper) duplicate(session *captureSession, packet *PacketInfo) bool {
+	if !d.active.Load() || packet == nil {
+		return false
+	}
+
+	fingerprint := packetFingerprint(packet)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.seen == nil {
+		return false
+	}
+	if owner, ok := d.seen[fingerprint]; ok && owner != session {
+		delete(d.seen, fingerprint)
+		d.duplicates++
+		return true
+	}
+	if len(d.seen) < maxOverlapFingerprints {
+		d.seen[fingerprint] = session
+	}
+	return false
+}
+
+// packetFingerprint 计算数据包指纹
+func packetFingerprint(packet *PacketInfo) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte{byte(packet.Direction)})
+	hash.Write(packet.Payload)
+	return hash.Sum64()
+}
@@ -0,0 +1,270 @@
+package interceptor
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockDivertHandle 模拟的抓包句柄，测试通过 deliver 向句柄排队数据包
+type mockDivertHandle struct {
+	filter   string
+	queue    chan *PacketInfo
+	shutdown chan struct{}
+	closed   chan struct{}
+
+	shutdownOnce sync.Once
+	closeOnce    sync.Once
+	closeCalls   atomic.Int32
+	noShutdown   bool // 模拟不支持排空的旧版本驱动
+}
+
+func newMockDivertHandle(filter string) *mockDivertHandle {
+	return &mockDivertHandle{
+		filter:   filter,
+		queue:    make(chan *PacketInfo, 64),
+		shutdown: make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+}
+
+// deliver 模拟驱动按过滤器向句柄排队数据包，句柄停止接收后丢弃
+func (h *mockDivertHandle) deliver(packet *PacketInfo) {
+	select {
+	case <-h.shutdown:
+	case <-h.closed:
+	default:
+		h.queue <- packet
+	}
+}
+
+func (h *mockDivertHandle) Recv(buffer []byte) (*PacketInfo, error) {
+	select {
+	case <-h.closed:
+		return nil, errors.New("句柄已关闭")
+	case <-h.shutdown:
+		select {
+		case packet := <-h.queue:
+			return packet, nil
+		default:
+			return nil, errors.New("没有更多数据")
+		}
+	case packet := <-h.queue:
+		return packet, nil
+	}
+}
+
+func (h *mockDivertHandle) Shutdown() error {
+	if h.noShutdown {
+		return errors.New("不支持 WinDivertShutdown")
+	}
+	h.shutdownOnce.Do(func() { close(h.shutdown) })
+	return nil
+}
+
+func (h *mockDivertHandle) Close() error {
+	h.closeCalls.Add(1)
+	h.closeOnce.Do(func() { close(h.closed) })
+	return nil
+}
+
+func (h *mockDivertHandle) isClosed() bool {
+	select {
+	case <-h.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// mockDivertLayer 模拟的 WinDivert 层，依次发出按过滤器打开的句柄
+type mockDivertLayer struct {
+	opened chan *mockDivertHandle
+}
+
+func newMockDivertLayer() *mockDivertLayer {
+	return &mockDivertLayer{opened: make(chan *mockDivertHandle, 8)}
+}
+
+func (l *mockDivertLayer) open(filter string) (divertHandle, error) {
+	if filter == "invalid filter" {
+		return nil, errors.New("过滤器语法错误")
+	}
+	handle := newMockDivertHandle(filter)
+	l.opened <- handle
+	return handle, nil
+}
+
+// handoverHarness 使用模拟 WinDivert 层的过滤器切换器，接收协程与 WinDivert 拦截器的处理方式相同
+type handoverHarness struct {
+	handover  *filterHandover
+	layer     *mockDivertLayer
+	delivered chan *PacketInfo
+}
+
+func newHandoverHarness(t *testing.T) *handoverHarness {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	h := &handoverHarness{
+		layer:     newMockDivertLayer(),
+		delivered: make(chan *PacketInfo, 64),
+	}
+	h.handover = newFilterHandover(h.layer.open, func(session *captureSession) {
+		for i := 0; i < 2; i++ {
+			session.Go(func() {
+				for {
+					packet, err := session.handle.Recv(nil)
+					if err != nil {
+						if session.Retiring() {
+							return
+						}
+						continue
+					}
+					session.MarkReceiving()
+					if h.handover.Duplicate(session, packet) {
+						continue
+					}
+					h.delivered <- packet
+				}
+			})
+		}
+	}, logger)
+	t.Cleanup(func() { h.handover.Stop() })
+	return h
+}
+
+// activate 用模拟句柄开始抓包
+func (h *handoverHarness) activate(filter string) *mockDivertHandle {
+	handle := newMockDivertHandle(filter)
+	h.handover.Activate(filter, handle)
+	return handle
+}
+
+// collect 收集交付的数据包ID，直到在等待时间内没有新数据包
+func (h *handoverHarness) collect(wait time.Duration) []string {
+	ids := make([]string, 0)
+	for {
+		select {
+		case packet := <-h.delivered:
+			ids = append(ids, packet.ID)
+		case <-time.After(wait):
+			return ids
+		}
+	}
+}
+
+func newHandoverPacket(id string) *PacketInfo {
+	return &PacketInfo{ID: id, Direction: PacketDirectionOutbound, Payload: []byte("packet:" + id)}
+}
+
+func TestFilterHandover_SwapAppliesNewFilterAndClosesOldHandle(t *testing.T) {
+	h := newHandoverHarness(t)
+	oldHandle := h.activate("tcp.DstPort == 80")
+
+	oldHandle.deliver(newHandoverPacket("before"))
+	assert.Equal(t, []string{"before"}, h.collect(50*time.Millisecond))
+
+	swapped := make(chan error, 1)
+	go func() { swapped <- h.handover.Swap("tcp.DstPort == 443") }()
+
+	newHandle := <-h.layer.opened
+	assert.Equal(t, "tcp.DstPort == 443", newHandle.filter)
+
+	// 新句柄收到数据包之前旧句柄保持接收
+	oldHandle.deliver(newHandoverPacket("overlap"))
+	select {
+	case err := <-swapped:
+		t.Fatalf("新句柄收到数据包之前不应完成切换: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.False(t, oldHandle.isClosed(), "新句柄收到数据包之前旧句柄不应关闭")
+
+	// 重叠期间两个句柄都收到的数据包只交付一次
+	newHandle.deliver(newHandoverPacket("overlap"))
+	newHandle.deliver(newHandoverPacket("after"))
+
+	require.NoError(t, <-swapped)
+	assert.True(t, oldHandle.isClosed(), "切换完成后旧句柄应已关闭")
+	assert.Equal(t, int32(1), oldHandle.closeCalls.Load())
+	assert.Equal(t, "tcp.DstPort == 443", h.handover.Current().Filter())
+
+	newHandle.deliver(newHandoverPacket("later"))
+	assert.ElementsMatch(t, []string{"overlap", "after", "later"}, h.collect(50*time.Millisecond))
+}
+
+func TestFilterHandover_OldHandleStopsReceivingAfterSwap(t *testing.T) {
+	h := newHandoverHarness(t)
+	oldHandle := h.activate("tcp")
+
+	swapped := make(chan error, 1)
+	go func() { swapped <- h.handover.Swap("udp") }()
+	newHandle := <-h.layer.opened
+
+	newHandle.deliver(newHandoverPacket("new"))
+	require.NoError(t, <-swapped)
+
+	// 旧句柄排空后不再接收新数据包
+	oldHandle.deliver(newHandoverPacket("stale"))
+	assert.Equal(t, []string{"new"}, h.collect(50*time.Millisecond))
+	assert.Equal(t, "udp", h.handover.Current().Filter())
+}
+
+func TestFilterHandover_TimeoutWithoutTraffic(t *testing.T) {
+	h := newHandoverHarness(t)
+	h.handover.handoverTimeout = 20 * time.Millisecond
+	oldHandle := h.activate("tcp")
+	oldHandle.noShutdown = true
+
+	start := time.Now()
+	require.NoError(t, h.handover.Swap("udp"))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.True(t, oldHandle.isClosed(), "新句柄没有流量时超时后仍应关闭旧句柄")
+	assert.Equal(t, "udp", h.handover.Current().Filter())
+}
+
+func TestFilterHandover_InvalidFilterKeepsOldHandle(t *testing.T) {
+	h := newHandoverHarness(t)
+	oldHandle := h.activate("tcp")
+
+	err := h.handover.Swap("invalid filter")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "过滤器语法错误")
+	assert.False(t, oldHandle.isClosed(), "打开新句柄失败时应保留旧句柄")
+	assert.Equal(t, "tcp", h.handover.Current().Filter())
+
+	oldHandle.deliver(newHandoverPacket("still"))
+	assert.Equal(t, []string{"still"}, h.collect(50*time.Millisecond))
+}
+
+func TestFilterHandover_StopClosesCurrentHandle(t *testing.T) {
+	h := newHandoverHarness(t)
+	handle := h.activate("tcp")
+
+	require.NoError(t, h.handover.Stop())
+	assert.True(t, handle.isClosed())
+	assert.Nil(t, h.handover.Current())
+	assert.Error(t, h.handover.Swap("udp"), "停止后不能切换过滤器")
+}
+
+func TestOverlapDeduper(t *testing.T) {
+	var d overlapDeduper
+	oldSession, newSession := &captureSession{}, &captureSession{}
+	packet := newHandoverPacket("p")
+
+	assert.False(t, d.duplicate(newSession, packet), "未切换时不检测重复")
+
+	d.begin()
+	assert.False(t, d.duplicate(oldSession, packet))
+	assert.False(t, d.duplicate(oldSession, packet), "同一个句柄重复收到的数据包不视为重复")
+	assert.True(t, d.duplicate(newSession, packet))
+	assert.False(t, d.duplicate(newSession, &PacketInfo{Direction: PacketDirectionInbound, Payload: packet.Payload}), "方向不同的数据包不是重复")
+	assert.Equal(t, 1, d.end())
+	assert.False(t, d.duplicate(newSession, packet))
+}
@@ -0,0 +1,380 @@
+package interceptor
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// 数据包元数据中与流相关的键
+const (
+	MetadataTCPFlags = "tcp_flags" // TCP标志位（uint8），由拦截器解析TCP头部时设置
+	MetadataFlow     = "flow"      // 数据包所属流在处理该数据包后的快照（*Flow）
+)
+
+// TCP标志位
+const (
+	TCPFlagFIN uint8 = 0x01
+	TCPFlagSYN uint8 = 0x02
+	TCPFlagRST uint8 = 0x04
+	TCPFlagACK uint8 = 0x10
+)
+
+// FlowState 流状态
+type FlowState string
+
+const (
+	FlowStateNew         FlowState = "new"         // 只看到发起方的数据包
+	FlowStateEstablished FlowState = "established" // 双方都有数据包，或从连接中途开始跟踪
+	FlowStateClosing     FlowState = "closing"     // 已有一方发送FIN
+	FlowStateClosed      FlowState = "closed"      // 双方都已发送FIN、收到RST、空闲超时或被淘汰
+)
+
+// FlowEventType 流生命周期事件类型
+type FlowEventType string
+
+const (
+	FlowEventStart FlowEventType = "flow_start"
+	FlowEventEnd   FlowEventType = "flow_end"
+)
+
+// 流结束原因
+const (
+	FlowEndFIN     = "fin"
+	FlowEndRST     = "rst"
+	FlowEndIdle    = "idle"
+	FlowEndEvicted = "evicted"
+)
+
+// FlowTrackerConfig 流跟踪配置
+type FlowTrackerConfig struct {
+	Enabled        bool          `yaml:"enabled" json:"enabled"`
+	IdleTimeout    time.Duration `yaml:"idle_timeout" json:"idle_timeout"`       // 没有数据包的流在该时间后结束
+	ClosingTimeout time.Duration `yaml:"closing_timeout" json:"closing_timeout"` // 只有一方发送FIN的流在该时间后结束
+	MaxFlows       int           `yaml:"max_flows" json:"max_flows"`             // 最多跟踪的流数量，超出时淘汰最久未活动的流
+}
+
+// DefaultFlowTrackerConfig 返回默认流跟踪配置
+func DefaultFlowTrackerConfig() FlowTrackerConfig {
+	return FlowTrackerConfig{
+		Enabled:        false,
+		IdleTimeout:    2 * time.Minute,
+		ClosingTimeout: 10 * time.Second,
+		MaxFlows:       10000,
+	}
+}
+
+// FlowKey 流的四元组和传输层协议，客户端为发起连接的一方
+type FlowKey struct {
+	Protocol   Protocol `json:"protocol"`
+	ClientIP   string   `json:"client_ip"`
+	ClientPort uint16   `json:"client_port"`
+	ServerIP   string   `json:"server_ip"`
+	ServerPort uint16   `json:"server_port"`
+}
+
+// String 返回可读的流标识
+func (k FlowKey) String() string {
+	return fmt.Sprintf("%d %s -> %s", k.Protocol,
+		net.JoinHostPort(k.ClientIP, fmt.Sprint(k.ClientPort)),
+		net.JoinHostPort(k.ServerIP, fmt.Sprint(k.ServerPort)))
+}
+
+// reverse 返回反方向的流键
+func (k FlowKey) reverse() FlowKey {
+	return FlowKey{
+		Protocol:   k.Protocol,
+		ClientIP:   k.ServerIP,
+		ClientPort: k.ServerPort,
+		ServerIP:   k.ClientIP,
+		ServerPort: k.ClientPort,
+	}
+}
+
+// Flow 一条网络流，字节数按数据包大小统计，out 为客户端发往服务端的方向
+type Flow struct {
+	ID          string       `json:"id"`
+	Key         FlowKey      `json:"key"`
+	State       FlowState    `json:"state"`
+	ProcessInfo *ProcessInfo `json:"process_info,omitempty"`
+	StartTime   time.Time    `json:"start_time"`
+	LastSeen    time.Time    `json:"last_seen"`
+	EndTime     time.Time    `json:"end_time,omitempty"`
+	EndReason   string       `json:"end_reason,omitempty"`
+	BytesOut    uint64       `json:"bytes_out"`
+	BytesIn     uint64       `json:"bytes_in"`
+	PacketsOut  uint64       `json:"packets_out"`
+	PacketsIn   uint64       `json:"packets_in"`
+
+	finOut bool
+	finIn  bool
+}
+
+// Bytes 返回双向字节总数
+func (f *Flow) Bytes() uint64 {
+	return f.BytesOut + f.BytesIn
+}
+
+// Packets 返回双向数据包总数
+func (f *Flow) Packets() uint64 {
+	return f.PacketsOut + f.PacketsIn
+}
+
+// Duration 返回流的持续时间，未结束的流计算到最后一个数据包
+func (f *Flow) Duration() time.Duration {
+	if !f.EndTime.IsZero() {
+		return f.EndTime.Sub(f.StartTime)
+	}
+	return f.LastSeen.Sub(f.StartTime)
+}
+
+// FlowEvent 流生命周期事件，Flow 为事件发生时的快照
+type FlowEvent struct {
+	Type FlowEventType `json:"type"`
+	Flow Flow          `json:"flow"`
+}
+
+// FlowEventHandler 流生命周期事件处理函数，在调用 Track 或 Expire 的协程中同步执行，应尽快返回
+type FlowEventHandler func(event FlowEvent)
+
+// FlowTracker 按四元组将数据包归并为流，跟踪连接状态和字节数，并在流开始和结束时发出事件
+type FlowTracker struct {
+	config    FlowTrackerConfig
+	flows     map[FlowKey]*Flow
+	handlers  []FlowEventHandler
+	nextID    uint64
+	lastSweep time.Time
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+// NewFlowTracker 创建流跟踪器，无效的配置项使用默认值
+func NewFlowTracker(config FlowTrackerConfig) *FlowTracker {
+	defaults := DefaultFlowTrackerConfig()
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaults.IdleTimeout
+	}
+	if config.ClosingTimeout <= 0 {
+		config.ClosingTimeout = defaults.ClosingTimeout
+	}
+	if config.MaxFlows <= 0 {
+		config.MaxFlows = defaults.MaxFlows
+	}
+
+	return &FlowTracker{
+		config:    config,
+		flows:     make(map[FlowKey]*Flow),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// OnEvent 注册流生命周期事件处理函数
+func (t *FlowTracker) OnEvent(handler FlowEventHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers = append(t.handlers, handler)
+}
+
+// Track 将数据包计入所属的流，返回处理该数据包后的流快照，并写入数据包元数据的 MetadataFlow
+// 第一个数据包的源地址视为客户端；TCP流根据 MetadataTCPFlags 中的标志位转换状态
+func (t *FlowTracker) Track(packet *PacketInfo) *Flow {
+	if packet == nil || packet.SourceIP == nil || packet.DestIP == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	now := t.now()
+
+	var events []FlowEvent
+	if now.Sub(t.lastSweep) >= t.config.ClosingTimeout {
+		events = t.expire(now, events)
+		t.lastSweep = now
+	}
+
+	key := FlowKey{
+		Protocol:   packet.Protocol,
+		ClientIP:   packet.SourceIP.String(),
+		ClientPort: packet.SourcePort,
+		ServerIP:   packet.DestIP.String(),
+		ServerPort: packet.DestPort,
+	}
+	outbound := true
+	flow, exists := t.flows[key]
+	if !exists {
+		if flow, exists = t.flows[key.reverse()]; exists {
+			key, outbound = key.reverse(), false
+		}
+	}
+
+	flags := packetTCPFlags(packet)
+	if !exists && packet.Protocol == ProtocolTCP && flags == TCPFlagACK && len(packet.Payload) == 0 {
+		// 连接关闭后的最后一个ACK不创建新流
+		t.mu.Unlock()
+		return nil
+	}
+	if !exists {
+		if len(t.flows) >= t.config.MaxFlows {
+			events = t.evictOldest(now, events)
+		}
+		t.nextID++
+		flow = &Flow{
+			ID:          fmt.Sprintf("flow_%d", t.nextID),
+			Key:         key,
+			State:       FlowStateNew,
+			ProcessInfo: packet.ProcessInfo,
+			StartTime:   now,
+		}
+		// 没有SYN的TCP数据包说明连接在跟踪前已建立
+		if packet.Protocol == ProtocolTCP && flags&TCPFlagSYN == 0 {
+			flow.State = FlowStateEstablished
+		}
+		t.flows[key] = flow
+		events = append(events, FlowEvent{Type: FlowEventStart, Flow: *flow})
+	}
+
+	flow.LastSeen = now
+	if flow.ProcessInfo == nil {
+		flow.ProcessInfo = packet.ProcessInfo
+	}
+	if outbound {
+		flow.BytesOut += uint64(packet.Size)
+		flow.PacketsOut++
+	} else {
+		flow.BytesIn += uint64(packet.Size)
+		flow.PacketsIn++
+		if flow.State == FlowStateNew {
+			flow.State = FlowStateEstablished
+		}
+	}
+
+	if packet.Protocol == ProtocolTCP {
+		switch {
+		case flags&TCPFlagRST != 0:
+			events = t.close(flow, now, FlowEndRST, events)
+		case flags&TCPFlagFIN != 0:
+			if outbound {
+				flow.finOut = true
+			} else {
+				flow.finIn = true
+			}
+			flow.State = FlowStateClosing
+			if flow.finOut && flow.finIn {
+				events = t.close(flow, now, FlowEndFIN, events)
+			}
+		}
+	}
+
+	snapshot := *flow
+	handlers := t.handlers
+	t.mu.Unlock()
+
+	if packet.Metadata == nil {
+		packet.Metadata = make(map[string]interface{})
+	}
+	packet.Metadata[MetadataFlow] = &snapshot
+
+	dispatchFlowEvents(handlers, events)
+	return &snapshot
+}
+
+// Expire 结束空闲超时的流，返回结束的流数量
+// 没有新数据包时空闲的流不会自动结束，调用方应定期调用
+func (t *FlowTracker) Expire() int {
+	t.mu.Lock()
+	now := t.now()
+	events := t.expire(now, nil)
+	t.lastSweep = now
+	handlers := t.handlers
+	t.mu.Unlock()
+
+	dispatchFlowEvents(handlers, events)
+	return len(events)
+}
+
+// Flows 返回当前跟踪的流快照
+func (t *FlowTracker) Flows() []Flow {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	flows := make([]Flow, 0, len(t.flows))
+	for _, flow := range t.flows {
+		flows = append(flows, *flow)
+	}
+	return flows
+}
+
+// Len 返回当前跟踪的流数量
+func (t *FlowTracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.flows)
+}
+
+// expire 结束超时的流，调用方需持有锁
+func (t *FlowTracker) expire(now time.Time, events []FlowEvent) []FlowEvent {
+	for _, flow := range t.flows {
+		timeout := t.config.IdleTimeout
+		if flow.State == FlowStateClosing {
+			timeout = t.config.ClosingTimeout
+		}
+		if now.Sub(flow.LastSeen) >= timeout {
+			events = t.close(flow, now, FlowEndIdle, events)
+		}
+	}
+	return events
+}
+
+// evictOldest 淘汰最久未活动的流，调用方需持有锁
+func (t *FlowTracker) evictOldest(now time.Time, events []FlowEvent) []FlowEvent {
+	var oldest *Flow
+	for _, flow := range t.flows {
+		if oldest == nil || flow.LastSeen.Before(oldest.LastSeen) {
+			oldest = flow
+		}
+	}
+	if oldest == nil {
+		return events
+	}
+	return t.close(oldest, now, FlowEndEvicted, events)
+}
+
+// close 结束流并停止跟踪，调用方需持有锁
+func (t *FlowTracker) close(flow *Flow, now time.Time, reason string, events []FlowEvent) []FlowEvent {
+	flow.State = FlowStateClosed
+	flow.EndTime = now
+	flow.EndReason = reason
+	delete(t.flows, flow.Key)
+	return append(events, FlowEvent{Type: FlowEventEnd, Flow: *flow})
+}
+
+// dispatchFlowEvents 依次调用事件处理函数
+func dispatchFlowEvents(handlers []FlowEventHandler, events []FlowEvent) {
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+// packetTCPFlags 读取数据包元数据中的TCP标志位
+func packetTCPFlags(packet *PacketInfo) uint8 {
+	switch flags := packet.Metadata[MetadataTCPFlags].(type) {
+	case uint8:
+		return flags
+	case int:
+		return uint8(flags)
+	default:
+		return 0
+	}
+}
+
+// PacketFlow 返回流跟踪器写入数据包元数据的流快照，未跟踪时返回nil
+func PacketFlow(packet *PacketInfo) *Flow {
+	if packet == nil {
+		return nil
+	}
+	flow, _ := packet.Metadata[MetadataFlow].(*Flow)
+	return flow
+}
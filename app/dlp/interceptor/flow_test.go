@@ -0,0 +1,169 @@
+package interceptor
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlowTestTracker 创建使用可控时钟的流跟踪器，并记录全部事件
+func newFlowTestTracker(config FlowTrackerConfig) (*FlowTracker, *time.Time, *[]FlowEvent) {
+	tracker := NewFlowTracker(config)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	tracker.lastSweep = now
+
+	events := &[]FlowEvent{}
+	tracker.OnEvent(func(event FlowEvent) {
+		*events = append(*events, event)
+	})
+	return tracker, &now, events
+}
+
+// tcpPacket 构造客户端 10.0.0.5:50000 与服务端 203.0.113.10:443 之间的TCP数据包
+func tcpPacket(outbound bool, flags uint8, size int) *PacketInfo {
+	client, server := net.ParseIP("10.0.0.5"), net.ParseIP("203.0.113.10")
+	packet := &PacketInfo{
+		Protocol: ProtocolTCP,
+		Size:     size,
+		Payload:  make([]byte, size),
+		Metadata: map[string]interface{}{MetadataTCPFlags: flags},
+	}
+	if outbound {
+		packet.Direction = PacketDirectionOutbound
+		packet.SourceIP, packet.SourcePort = client, 50000
+		packet.DestIP, packet.DestPort = server, 443
+		packet.ProcessInfo = &ProcessInfo{PID: 42, ProcessName: "curl"}
+	} else {
+		packet.Direction = PacketDirectionInbound
+		packet.SourceIP, packet.SourcePort = server, 443
+		packet.DestIP, packet.DestPort = client, 50000
+	}
+	return packet
+}
+
+func TestFlowTracker_TCPLifecycle(t *testing.T) {
+	tracker, now, events := newFlowTestTracker(DefaultFlowTrackerConfig())
+	step := func(outbound bool, flags uint8, size int) *Flow {
+		*now = now.Add(100 * time.Millisecond)
+		return tracker.Track(tcpPacket(outbound, flags, size))
+	}
+
+	flow := step(true, TCPFlagSYN, 0)
+	require.NotNil(t, flow)
+	assert.Equal(t, FlowStateNew, flow.State)
+	require.Len(t, *events, 1)
+	assert.Equal(t, FlowEventStart, (*events)[0].Type)
+	assert.Equal(t, FlowKey{Protocol: ProtocolTCP, ClientIP: "10.0.0.5", ClientPort: 50000, ServerIP: "203.0.113.10", ServerPort: 443}, (*events)[0].Flow.Key)
+
+	assert.Equal(t, FlowStateEstablished, step(false, TCPFlagSYN|TCPFlagACK, 0).State)
+	assert.Equal(t, FlowStateEstablished, step(true, TCPFlagACK, 0).State)
+	step(true, TCPFlagACK, 1200)
+	step(false, TCPFlagACK, 300)
+	step(true, TCPFlagACK, 800)
+
+	flow = step(true, TCPFlagFIN|TCPFlagACK, 0)
+	assert.Equal(t, FlowStateClosing, flow.State)
+	assert.Len(t, *events, 1)
+
+	flow = step(false, TCPFlagFIN|TCPFlagACK, 0)
+	assert.Equal(t, FlowStateClosed, flow.State)
+	assert.Equal(t, FlowEndFIN, flow.EndReason)
+
+	// 最后一个ACK不产生新流
+	assert.Nil(t, step(true, TCPFlagACK, 0))
+	assert.Equal(t, 0, tracker.Len())
+
+	require.Len(t, *events, 2)
+	end := (*events)[1]
+	assert.Equal(t, FlowEventEnd, end.Type)
+	assert.Equal(t, flow.ID, end.Flow.ID)
+	assert.Equal(t, uint64(2000), end.Flow.BytesOut)
+	assert.Equal(t, uint64(300), end.Flow.BytesIn)
+	assert.Equal(t, uint64(2300), end.Flow.Bytes())
+	assert.Equal(t, uint64(5), end.Flow.PacketsOut)
+	assert.Equal(t, uint64(3), end.Flow.PacketsIn)
+	assert.Equal(t, 700*time.Millisecond, end.Flow.Duration())
+	assert.Equal(t, "curl", end.Flow.ProcessInfo.ProcessName)
+}
+
+func TestFlowTracker_RSTClosesFlow(t *testing.T) {
+	tracker, _, events := newFlowTestTracker(DefaultFlowTrackerConfig())
+
+	tracker.Track(tcpPacket(true, TCPFlagSYN, 0))
+	flow := tracker.Track(tcpPacket(false, TCPFlagRST, 0))
+	assert.Equal(t, FlowStateClosed, flow.State)
+	assert.Equal(t, FlowEndRST, flow.EndReason)
+	require.Len(t, *events, 2)
+	assert.Equal(t, FlowEventEnd, (*events)[1].Type)
+}
+
+func TestFlowTracker_MidStreamAndMetadata(t *testing.T) {
+	tracker, _, _ := newFlowTestTracker(DefaultFlowTrackerConfig())
+
+	packet := tcpPacket(true, TCPFlagACK|0x08, 500)
+	flow := tracker.Track(packet)
+	require.NotNil(t, flow)
+	assert.Equal(t, FlowStateEstablished, flow.State)
+	assert.Same(t, flow, PacketFlow(packet))
+	assert.Nil(t, PacketFlow(tcpPacket(true, TCPFlagACK, 0)))
+}
+
+func TestFlowTracker_IdleExpiry(t *testing.T) {
+	tracker, now, events := newFlowTestTracker(FlowTrackerConfig{IdleTimeout: time.Minute, ClosingTimeout: 5 * time.Second})
+
+	tracker.Track(tcpPacket(true, TCPFlagSYN, 0))
+	tracker.Track(tcpPacket(false, TCPFlagSYN|TCPFlagACK, 0))
+
+	*now = now.Add(30 * time.Second)
+	assert.Equal(t, 0, tracker.Expire())
+
+	*now = now.Add(31 * time.Second)
+	assert.Equal(t, 1, tracker.Expire())
+	assert.Equal(t, 0, tracker.Len())
+	require.Len(t, *events, 2)
+	assert.Equal(t, FlowEndIdle, (*events)[1].Flow.EndReason)
+
+	// 半关闭的流使用较短的超时
+	tracker.Track(tcpPacket(true, TCPFlagACK, 100))
+	tracker.Track(tcpPacket(true, TCPFlagFIN|TCPFlagACK, 0))
+	*now = now.Add(6 * time.Second)
+	assert.Equal(t, 1, tracker.Expire())
+}
+
+func TestFlowTracker_MaxFlowsEvictsOldest(t *testing.T) {
+	tracker, now, events := newFlowTestTracker(FlowTrackerConfig{MaxFlows: 2})
+
+	for port := uint16(1); port <= 3; port++ {
+		*now = now.Add(time.Millisecond)
+		packet := tcpPacket(true, TCPFlagSYN, 0)
+		packet.SourcePort = port
+		tracker.Track(packet)
+	}
+
+	assert.Equal(t, 2, tracker.Len())
+	var evicted []FlowEvent
+	for _, event := range *events {
+		if event.Type == FlowEventEnd {
+			evicted = append(evicted, event)
+		}
+	}
+	require.Len(t, evicted, 1)
+	assert.Equal(t, uint16(1), evicted[0].Flow.Key.ClientPort)
+	assert.Equal(t, FlowEndEvicted, evicted[0].Flow.EndReason)
+}
+
+func TestFlowTracker_UDP(t *testing.T) {
+	tracker, _, _ := newFlowTestTracker(DefaultFlowTrackerConfig())
+
+	query := &PacketInfo{Protocol: ProtocolUDP, SourceIP: net.ParseIP("10.0.0.5"), SourcePort: 53000, DestIP: net.ParseIP("198.51.100.53"), DestPort: 53, Size: 60}
+	reply := &PacketInfo{Protocol: ProtocolUDP, SourceIP: net.ParseIP("198.51.100.53"), SourcePort: 53, DestIP: net.ParseIP("10.0.0.5"), DestPort: 53000, Size: 120}
+
+	assert.Equal(t, FlowStateNew, tracker.Track(query).State)
+	flow := tracker.Track(reply)
+	assert.Equal(t, FlowStateEstablished, flow.State)
+	assert.Equal(t, uint64(180), flow.Bytes())
+}
@@ -0,0 +1,90 @@
+package interceptor
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// GeoIPConfig 目的地址地理位置富化配置
+type GeoIPConfig struct {
+	Enabled  bool                `yaml:"enabled" json:"enabled"`
+	Networks map[string][]string `yaml:"networks" json:"networks"` // 国家代码(ISO 3166-1 alpha-2)到网段列表的映射
+}
+
+// DefaultGeoIPConfig 返回默认地理位置富化配置
+func DefaultGeoIPConfig() GeoIPConfig {
+	return GeoIPConfig{
+		Enabled:  false,
+		Networks: make(map[string][]string),
+	}
+}
+
+// GeoInfo 地址的地理位置信息
+type GeoInfo struct {
+	IP      string `json:"ip"`
+	Country string `json:"country"` // 大写的国家代码
+	Network string `json:"network"` // 命中的网段
+}
+
+// geoNetwork 网段与所属国家
+type geoNetwork struct {
+	network *net.IPNet
+	country string
+	ones    int
+}
+
+// GeoResolver 按网段将IP地址解析为国家，多个网段包含同一地址时使用前缀最长的网段
+type GeoResolver struct {
+	networks []geoNetwork
+}
+
+// NewGeoResolver 创建地理位置解析器，国家代码或网段无效时返回错误
+func NewGeoResolver(config GeoIPConfig) (*GeoResolver, error) {
+	resolver := &GeoResolver{}
+	for country, cidrs := range config.Networks {
+		code := strings.ToUpper(strings.TrimSpace(country))
+		if len(code) != 2 {
+			return nil, fmt.Errorf("无效的国家代码: %q", country)
+		}
+		for _, cidr := range cidrs {
+			_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+			if err != nil {
+				return nil, fmt.Errorf("国家 %s 的网段无效: %w", code, err)
+			}
+			ones, _ := network.Mask.Size()
+			resolver.networks = append(resolver.networks, geoNetwork{network: network, country: code, ones: ones})
+		}
+	}
+
+	sort.SliceStable(resolver.networks, func(i, j int) bool {
+		return resolver.networks[i].ones > resolver.networks[j].ones
+	})
+	return resolver, nil
+}
+
+// Lookup 解析IP地址所属国家，未知时返回nil
+func (r *GeoResolver) Lookup(ip net.IP) *GeoInfo {
+	if r == nil || ip == nil {
+		return nil
+	}
+	for _, entry := range r.networks {
+		if entry.network.Contains(ip) {
+			return &GeoInfo{
+				IP:      ip.String(),
+				Country: entry.country,
+				Network: entry.network.String(),
+			}
+		}
+	}
+	return nil
+}
+
+// Enrich 解析数据包目的地址的地理位置，未知时返回nil
+func (r *GeoResolver) Enrich(packet *PacketInfo) *GeoInfo {
+	if packet == nil {
+		return nil
+	}
+	return r.Lookup(packet.DestIP)
+}
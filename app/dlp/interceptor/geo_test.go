@@ -0,0 +1,45 @@
+package interceptor
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeoResolver_Lookup(t *testing.T) {
+	resolver, err := NewGeoResolver(GeoIPConfig{
+		Enabled: true,
+		Networks: map[string][]string{
+			"us": {"203.0.113.0/24"},
+			"CN": {"198.51.100.0/24", "2001:db8::/32"},
+			"SG": {"203.0.113.128/25"},
+		},
+	})
+	require.NoError(t, err)
+
+	geo := resolver.Lookup(net.ParseIP("198.51.100.7"))
+	require.NotNil(t, geo)
+	assert.Equal(t, "CN", geo.Country)
+	assert.Equal(t, "198.51.100.7", geo.IP)
+	assert.Equal(t, "198.51.100.0/24", geo.Network)
+
+	// 多个网段包含同一地址时使用前缀最长的网段，国家代码统一为大写
+	assert.Equal(t, "SG", resolver.Lookup(net.ParseIP("203.0.113.200")).Country)
+	assert.Equal(t, "US", resolver.Lookup(net.ParseIP("203.0.113.5")).Country)
+	assert.Equal(t, "CN", resolver.Lookup(net.ParseIP("2001:db8::1")).Country)
+
+	assert.Nil(t, resolver.Lookup(net.ParseIP("192.0.2.1")))
+	assert.Nil(t, resolver.Lookup(nil))
+	assert.Nil(t, resolver.Enrich(nil))
+	assert.Equal(t, "CN", resolver.Enrich(&PacketInfo{DestIP: net.ParseIP("198.51.100.9")}).Country)
+}
+
+func TestNewGeoResolver_InvalidConfig(t *testing.T) {
+	_, err := NewGeoResolver(GeoIPConfig{Networks: map[string][]string{"CHN": {"198.51.100.0/24"}}})
+	assert.Error(t, err)
+
+	_, err = NewGeoResolver(GeoIPConfig{Networks: map[string][]string{"CN": {"198.51.100.0"}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+package interceptor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+const (
+	// HistogramOtherBucket 未进入前N名的流量汇总桶
+	HistogramOtherBucket = "other"
+
+	// DefaultHistogramTopN 默认保留的直方图桶数量
+	DefaultHistogramTopN = 10
+
+	// histogramTrackFactor 实际跟踪的键数量是前N名的倍数，用于在内存有限的前提下提高前N名的准确性
+	histogramTrackFactor = 4
+)
+
+// HistogramBucket 直方图桶
+type HistogramBucket struct {
+	Key     string `json:"key"`
+	Packets uint64 `json:"packets"`
+	Bytes   uint64 `json:"bytes"`
+}
+
+// histogramCounter 按键统计数据包数和字节数，跟踪的键数量有上限
+// 键数量达到上限时，字节数最少的键被合并到 other 桶并让出位置（Space-Saving 策略），
+// 所有桶的总和始终等于记录的总流量
+type histogramCounter struct {
+	topN     int
+	capacity int
+	buckets  map[string]*HistogramBucket
+	other    HistogramBucket
+}
+
+// newHistogramCounter 创建直方图计数器
+func newHistogramCounter(topN int) *histogramCounter {
+	return &histogramCounter{
+		topN:     topN,
+		capacity: topN * histogramTrackFactor,
+		buckets:  make(map[string]*HistogramBucket),
+		other:    HistogramBucket{Key: HistogramOtherBucket},
+	}
+}
+
+// add 记录一个数据包
+func (h *histogramCounter) add(key string, size uint64) {
+	bucket, ok := h.buckets[key]
+	if !ok {
+		if len(h.buckets) >= h.capacity {
+			h.evictSmallest()
+		}
+		bucket = &HistogramBucket{Key: key}
+		h.buckets[key] = bucket
+	}
+	bucket.Packets++
+	bucket.Bytes += size
+}
+
+// evictSmallest 将字节数最少的键合并到 other 桶
+func (h *histogramCounter) evictSmallest() {
+	var smallest *HistogramBucket
+	for _, bucket := range h.buckets {
+		if smallest == nil || bucket.Bytes < smallest.Bytes ||
+			(bucket.Bytes == smallest.Bytes && bucket.Packets < smallest.Packets) {
+			smallest = bucket
+		}
+	}
+	if smallest == nil {
+		return
+	}
+	h.other.Packets += smallest.Packets
+	h.other.Bytes += smallest.Bytes
+	delete(h.buckets, smallest.Key)
+}
+
+// snapshot 返回按字节数降序排列的前N个桶，其余流量汇总到 other 桶
+func (h *histogramCounter) snapshot() []HistogramBucket {
+	buckets := make([]HistogramBucket, 0, len(h.buckets))
+	for _, bucket := range h.buckets {
+		buckets = append(buckets, *bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Bytes != buckets[j].Bytes {
+			return buckets[i].Bytes > buckets[j].Bytes
+		}
+		if buckets[i].Packets != buckets[j].Packets {
+			return buckets[i].Packets > buckets[j].Packets
+		}
+		return buckets[i].Key < buckets[j].Key
+	})
+
+	other := h.other
+	if len(buckets) > h.topN {
+		for _, bucket := range buckets[h.topN:] {
+			other.Packets += bucket.Packets
+			other.Bytes += bucket.Bytes
+		}
+		buckets = buckets[:h.topN]
+	}
+	if other.Packets > 0 {
+		buckets = append(buckets, other)
+	}
+	return buckets
+}
+
+// TrafficHistogram 按协议和目标端口统计捕获的流量
+type TrafficHistogram struct {
+	protocols *histogramCounter
+	ports     *histogramCounter
+	mu        sync.Mutex
+}
+
+// NewTrafficHistogram 创建流量直方图，topN 为每个维度保留的桶数量，其余流量汇总到 other 桶
+func NewTrafficHistogram(topN int) *TrafficHistogram {
+	if topN <= 0 {
+		topN = DefaultHistogramTopN
+	}
+	return &TrafficHistogram{
+		protocols: newHistogramCounter(topN),
+		ports:     newHistogramCounter(topN),
+	}
+}
+
+// Record 记录一个数据包，没有目标端口的数据包只计入协议直方图
+func (h *TrafficHistogram) Record(packet *PacketInfo) {
+	if h == nil || packet == nil {
+		return
+	}
+
+	size := uint64(packet.Size)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.protocols.add(protocolName(packet.Protocol), size)
+	if packet.DestPort != 0 {
+		h.ports.add(strconv.Itoa(int(packet.DestPort)), size)
+	}
+}
+
+// Fill 将直方图快照写入统计信息
+func (h *TrafficHistogram) Fill(stats *InterceptorStats) {
+	if h == nil || stats == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats.ProtocolHistogram = h.protocols.snapshot()
+	stats.PortHistogram = h.ports.snapshot()
+}
+
+// protocolName 返回协议名称
+func protocolName(protocol Protocol) string {
+	switch protocol {
+	case ProtocolTCP:
+		return "tcp"
+	case ProtocolUDP:
+		return "udp"
+	default:
+		return fmt.Sprintf("ip-%d", int(protocol))
+	}
+}
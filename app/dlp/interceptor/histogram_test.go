@@ -0,0 +1,145 @@
+package interceptor
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHistogramTestPacket(protocol Protocol, port uint16, size int) *PacketInfo {
+	return &PacketInfo{
+		Direction: PacketDirectionOutbound,
+		Protocol:  protocol,
+		DestPort:  port,
+		Size:      size,
+	}
+}
+
+// bucketByKey 按键查找直方图桶
+func bucketByKey(buckets []HistogramBucket, key string) (HistogramBucket, bool) {
+	for _, bucket := range buckets {
+		if bucket.Key == key {
+			return bucket, true
+		}
+	}
+	return HistogramBucket{}, false
+}
+
+// sumBuckets 汇总直方图的数据包数和字节数
+func sumBuckets(buckets []HistogramBucket) (uint64, uint64) {
+	var packets, bytes uint64
+	for _, bucket := range buckets {
+		packets += bucket.Packets
+		bytes += bucket.Bytes
+	}
+	return packets, bytes
+}
+
+func TestTrafficHistogram_Distribution(t *testing.T) {
+	histogram := NewTrafficHistogram(10)
+
+	for i := 0; i < 30; i++ {
+		histogram.Record(newHistogramTestPacket(ProtocolTCP, 443, 1000))
+	}
+	for i := 0; i < 10; i++ {
+		histogram.Record(newHistogramTestPacket(ProtocolTCP, 80, 500))
+	}
+	for i := 0; i < 5; i++ {
+		histogram.Record(newHistogramTestPacket(ProtocolUDP, 53, 100))
+	}
+	histogram.Record(newHistogramTestPacket(Protocol(1), 0, 64))
+
+	var stats InterceptorStats
+	histogram.Fill(&stats)
+
+	// 协议按字节数降序排列
+	require.Len(t, stats.ProtocolHistogram, 3)
+	assert.Equal(t, HistogramBucket{Key: "tcp", Packets: 40, Bytes: 35000}, stats.ProtocolHistogram[0])
+	assert.Equal(t, HistogramBucket{Key: "udp", Packets: 5, Bytes: 500}, stats.ProtocolHistogram[1])
+	assert.Equal(t, HistogramBucket{Key: "ip-1", Packets: 1, Bytes: 64}, stats.ProtocolHistogram[2])
+
+	// 没有目标端口的数据包不计入端口直方图
+	require.Len(t, stats.PortHistogram, 3)
+	assert.Equal(t, HistogramBucket{Key: "443", Packets: 30, Bytes: 30000}, stats.PortHistogram[0])
+	assert.Equal(t, HistogramBucket{Key: "80", Packets: 10, Bytes: 5000}, stats.PortHistogram[1])
+	assert.Equal(t, HistogramBucket{Key: "53", Packets: 5, Bytes: 500}, stats.PortHistogram[2])
+	_, hasOther := bucketByKey(stats.PortHistogram, HistogramOtherBucket)
+	assert.False(t, hasOther)
+}
+
+func TestTrafficHistogram_TopNBound(t *testing.T) {
+	const topN = 3
+	histogram := NewTrafficHistogram(topN)
+
+	// 三个热点端口
+	for i := 0; i < 100; i++ {
+		histogram.Record(newHistogramTestPacket(ProtocolTCP, 443, 1500))
+		histogram.Record(newHistogramTestPacket(ProtocolTCP, 8443, 1200))
+		histogram.Record(newHistogramTestPacket(ProtocolTCP, 3306, 1000))
+	}
+	// 大量只出现一次的高位端口，远超跟踪上限
+	for port := 40000; port < 41000; port++ {
+		histogram.Record(newHistogramTestPacket(ProtocolUDP, uint16(port), 100))
+	}
+
+	// 跟踪的键数量有上限
+	assert.LessOrEqual(t, len(histogram.ports.buckets), topN*histogramTrackFactor)
+
+	var stats InterceptorStats
+	histogram.Fill(&stats)
+
+	require.Len(t, stats.PortHistogram, topN+1)
+	for i, port := range []string{"443", "8443", "3306"} {
+		assert.Equal(t, port, stats.PortHistogram[i].Key)
+		assert.Equal(t, uint64(100), stats.PortHistogram[i].Packets)
+	}
+
+	other := stats.PortHistogram[topN]
+	assert.Equal(t, HistogramOtherBucket, other.Key)
+	assert.Equal(t, uint64(1000), other.Packets)
+	assert.Equal(t, uint64(100000), other.Bytes)
+
+	// 所有桶的总和等于记录的总流量
+	packets, bytes := sumBuckets(stats.PortHistogram)
+	assert.Equal(t, uint64(1300), packets)
+	assert.Equal(t, uint64(100*(1500+1200+1000)+1000*100), bytes)
+
+	packets, bytes = sumBuckets(stats.ProtocolHistogram)
+	assert.Equal(t, uint64(1300), packets)
+	assert.Equal(t, uint64(100*(1500+1200+1000)+1000*100), bytes)
+}
+
+func TestTrafficHistogram_LateHeavyKeyReachesTopN(t *testing.T) {
+	histogram := NewTrafficHistogram(2)
+
+	// 先用只出现一次的端口填满跟踪表
+	for port := 1000; port < 1100; port++ {
+		histogram.Record(newHistogramTestPacket(ProtocolTCP, uint16(port), 10))
+	}
+	// 之后出现的热点端口仍能进入前N名
+	for i := 0; i < 50; i++ {
+		histogram.Record(newHistogramTestPacket(ProtocolTCP, 9000, 1000))
+	}
+
+	var stats InterceptorStats
+	histogram.Fill(&stats)
+
+	require.NotEmpty(t, stats.PortHistogram)
+	assert.Equal(t, strconv.Itoa(9000), stats.PortHistogram[0].Key)
+	assert.Equal(t, uint64(50), stats.PortHistogram[0].Packets)
+	assert.LessOrEqual(t, len(stats.PortHistogram), 3)
+}
+
+func TestTrafficHistogram_DefaultTopN(t *testing.T) {
+	histogram := NewTrafficHistogram(0)
+	assert.Equal(t, DefaultHistogramTopN, histogram.ports.topN)
+
+	// nil 直方图不记录也不填充
+	var nilHistogram *TrafficHistogram
+	nilHistogram.Record(newHistogramTestPacket(ProtocolTCP, 443, 100))
+	var stats InterceptorStats
+	nilHistogram.Fill(&stats)
+	assert.Nil(t, stats.PortHistogram)
+}
@@ -59,6 +59,10 @@ type InterceptorStats struct {
 	LastError        error         `json:"last_error,omitempty"`
 	StartTime        time.Time     `json:"start_time"`
 	Uptime           time.Duration `json:"uptime"`
+
+	// 按协议和目标端口的流量分布，只保留前N个桶，其余汇总到 other 桶
+	ProtocolHistogram []HistogramBucket `json:"protocol_histogram,omitempty"`
+	PortHistogram     []HistogramBucket `json:"port_histogram,omitempty"`
 }
 
 // InterceptorMode 拦截器模式
@@ -75,21 +79,27 @@ const (
 
 // InterceptorConfig 拦截器配置
 type InterceptorConfig struct {
-	Filter       string          `yaml:"filter" json:"filter"`
-	BufferSize   int             `yaml:"buffer_size" json:"buffer_size"`
-	ChannelSize  int             `yaml:"channel_size" json:"channel_size"`
-	Priority     int16           `yaml:"priority" json:"priority"`
-	Flags        uint64          `yaml:"flags" json:"flags"`
-	QueueLen     uint64          `yaml:"queue_len" json:"queue_len"`
-	QueueTime    uint64          `yaml:"queue_time" json:"queue_time"`
-	WorkerCount  int             `yaml:"worker_count" json:"worker_count"`
-	CacheSize    int             `yaml:"cache_size" json:"cache_size"`
-	Interface    string          `yaml:"interface" json:"interface"`
-	BypassCIDR   string          `yaml:"bypass_cidr" json:"bypass_cidr"`
-	ProxyPort    int             `yaml:"proxy_port" json:"proxy_port"`
-	Mode         InterceptorMode `yaml:"mode" json:"mode"`                   // 拦截器模式
-	AutoReinject bool            `yaml:"auto_reinject" json:"auto_reinject"` // 自动重新注入
-	Logger       logging.Logger  `yaml:"-" json:"-"`
+	Filter       string             `yaml:"filter" json:"filter"`
+	BufferSize   int                `yaml:"buffer_size" json:"buffer_size"`
+	ChannelSize  int                `yaml:"channel_size" json:"channel_size"`
+	Priority     int16              `yaml:"priority" json:"priority"`
+	Flags        uint64             `yaml:"flags" json:"flags"`
+	QueueLen     uint64             `yaml:"queue_len" json:"queue_len"`
+	QueueTime    uint64             `yaml:"queue_time" json:"queue_time"`
+	WorkerCount  int                `yaml:"worker_count" json:"worker_count"`
+	CacheSize    int                `yaml:"cache_size" json:"cache_size"`
+	Interface    string             `yaml:"interface" json:"interface"`
+	BypassCIDR   string             `yaml:"bypass_cidr" json:"bypass_cidr"`
+	ProxyPort    int                `yaml:"proxy_port" json:"proxy_port"`
+	Mode         InterceptorMode    `yaml:"mode" json:"mode"`                   // 拦截器模式
+	AutoReinject bool               `yaml:"auto_reinject" json:"auto_reinject"` // 自动重新注入
+	Pcap         PcapConfig         `yaml:"pcap" json:"pcap"`                   // 调试用pcap导出
+	Quota        TrafficQuotaConfig `yaml:"quota" json:"quota"`                 // 按进程的出站流量配额
+	Geo          GeoIPConfig        `yaml:"geo" json:"geo"`                     // 目的地址地理位置富化
+	Flow         FlowTrackerConfig  `yaml:"flow" json:"flow"`                   // 按四元组的流跟踪
+	RateLimiter  RateLimiterConfig  `yaml:"rate_limiter" json:"rate_limiter"`   // 自适应流量限制
+	StatsTopN    int                `yaml:"stats_top_n" json:"stats_top_n"`     // 流量统计直方图每个维度保留的桶数量
+	Logger       logging.Logger     `yaml:"-" json:"-"`
 }
 
 // DefaultInterceptorConfig 返回默认拦截器配置（性能优化版本）
@@ -116,6 +126,12 @@ func DefaultInterceptorConfig() InterceptorConfig {
 		ProxyPort:    8080,
 		Mode:         ModeMonitorOnly, // 默认使用监控模式
 		AutoReinject: true,            // 自动重新注入数据包
+		Pcap:         DefaultPcapConfig(),
+		Quota:        DefaultTrafficQuotaConfig(),
+		Geo:          DefaultGeoIPConfig(),
+		Flow:         DefaultFlowTrackerConfig(),
+		RateLimiter:  DefaultRateLimiterConfig(),
+		StatsTopN:    DefaultHistogramTopN,
 	}
 }
 
@@ -146,6 +162,18 @@ type TrafficInterceptor interface {
 	HealthCheck() error
 }
 
+// RateLimitController 支持在运行时调整流量限制的拦截器
+type RateLimitController interface {
+	// GetLimiterState 获取流量限制器的当前参数和运行状态
+	GetLimiterState() (LimiterState, error)
+
+	// SetLimiterParams 更新流量限制参数，无需重启拦截器
+	SetLimiterParams(params RateLimiterConfig) error
+
+	// SetLimiterEventHandler 设置流量限制开始和解除时的事件处理函数
+	SetLimiterEventHandler(handler LimiterEventHandler)
+}
+
 // InterceptorFactory 拦截器工厂接口
 type InterceptorFactory interface {
 	// CreateInterceptor 创建拦截器
@@ -0,0 +1,433 @@
+package interceptor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// pcap文件格式常量
+const (
+	pcapMagic        = 0xa1b2c3d4
+	pcapVersionMajor = 2
+	pcapVersionMinor = 4
+	pcapLinkTypeRaw  = 101 // LINKTYPE_RAW，记录直接以IP头开始
+	pcapSnapLen      = 65535
+
+	pcapGlobalHeaderSize = 24
+	pcapRecordHeaderSize = 16
+
+	// pcapFilePattern 导出文件名模式，文件名中的时间和序号保证按名称排序即为创建顺序
+	pcapFilePattern = "dlp_*.pcap"
+)
+
+// PcapConfig 调试用pcap导出配置
+type PcapConfig struct {
+	Enabled        bool   `yaml:"enabled" json:"enabled"`
+	Dir            string `yaml:"dir" json:"dir"`                           // 输出目录
+	MaxFileSize    int64  `yaml:"max_file_size" json:"max_file_size"`       // 单个文件最大字节数，超出后轮转
+	MaxFiles       int    `yaml:"max_files" json:"max_files"`               // 最多保留的文件数量
+	FlowBufferSize int    `yaml:"flow_buffer_size" json:"flow_buffer_size"` // 每个流缓存的前序数据包数量
+	MaxFlows       int    `yaml:"max_flows" json:"max_flows"`               // 最多跟踪的流数量
+	Redact         bool   `yaml:"redact" json:"redact"`                     // 是否抹除载荷内容
+}
+
+// DefaultPcapConfig 返回默认pcap导出配置
+func DefaultPcapConfig() PcapConfig {
+	return PcapConfig{
+		Enabled:        false,
+		Dir:            "data/dlp/pcap",
+		MaxFileSize:    10 * 1024 * 1024,
+		MaxFiles:       5,
+		FlowBufferSize: 16,
+		MaxFlows:       1024,
+		Redact:         true,
+	}
+}
+
+// flowBuffer 单个流的数据包环形缓冲区
+type flowBuffer struct {
+	packets  []*PacketInfo
+	next     int
+	full     bool
+	lastSeen time.Time
+}
+
+// add 添加数据包，缓冲区满时覆盖最早的数据包
+func (b *flowBuffer) add(packet *PacketInfo) {
+	b.packets[b.next] = packet
+	b.next = (b.next + 1) % len(b.packets)
+	if b.next == 0 {
+		b.full = true
+	}
+	b.lastSeen = time.Now()
+}
+
+// drain 按时间顺序取出并清空缓冲的数据包
+func (b *flowBuffer) drain() []*PacketInfo {
+	var result []*PacketInfo
+	if b.full {
+		result = append(result, b.packets[b.next:]...)
+	}
+	result = append(result, b.packets[:b.next]...)
+
+	for i := range b.packets {
+		b.packets[i] = nil
+	}
+	b.next = 0
+	b.full = false
+	return result
+}
+
+// PcapWriter 将触发非放行决策的数据包导出为pcap文件，便于离线分析
+// 所有数据包先进入所属流的环形缓冲区，决策为非放行时连同前序数据包一起写入文件
+type PcapWriter struct {
+	config PcapConfig
+	logger logging.Logger
+
+	flows map[string]*flowBuffer
+
+	file     *os.File
+	fileSize int64
+	fileSeq  int
+	files    []string
+
+	packetsWritten uint64
+	mu             sync.Mutex
+}
+
+// NewPcapWriter 创建pcap导出器
+func NewPcapWriter(config PcapConfig, logger logging.Logger) (*PcapWriter, error) {
+	if config.Dir == "" {
+		return nil, fmt.Errorf("pcap输出目录不能为空")
+	}
+	defaults := DefaultPcapConfig()
+	if config.MaxFileSize <= pcapGlobalHeaderSize {
+		config.MaxFileSize = defaults.MaxFileSize
+	}
+	if config.MaxFiles <= 0 {
+		config.MaxFiles = defaults.MaxFiles
+	}
+	if config.FlowBufferSize <= 0 {
+		config.FlowBufferSize = defaults.FlowBufferSize
+	}
+	if config.MaxFlows <= 0 {
+		config.MaxFlows = defaults.MaxFlows
+	}
+
+	if err := os.MkdirAll(config.Dir, 0750); err != nil {
+		return nil, fmt.Errorf("创建pcap输出目录失败: %w", err)
+	}
+
+	// 之前运行留下的文件同样计入保留数量
+	files, err := filepath.Glob(filepath.Join(config.Dir, pcapFilePattern))
+	if err != nil {
+		return nil, fmt.Errorf("扫描pcap输出目录失败: %w", err)
+	}
+	sort.Strings(files)
+
+	w := &PcapWriter{
+		config: config,
+		logger: logger,
+		flows:  make(map[string]*flowBuffer),
+		files:  files,
+	}
+	w.pruneFiles()
+	return w, nil
+}
+
+// Record 将数据包加入所属流的缓冲区
+func (w *PcapWriter) Record(packet *PacketInfo) {
+	if packet == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.bufferFor(packet).add(packet)
+}
+
+// Capture 写出触发非放行决策的数据包及其所在流缓冲的前序数据包
+// 数据包若已通过 Record 缓冲不会重复写入
+func (w *PcapWriter) Capture(packet *PacketInfo) error {
+	if packet == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	buffer := w.bufferFor(packet)
+	packets := buffer.drain()
+
+	recorded := false
+	for _, p := range packets {
+		if p == packet {
+			recorded = true
+			break
+		}
+	}
+	if !recorded {
+		packets = append(packets, packet)
+	}
+
+	for _, p := range packets {
+		if err := w.writePacket(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Files 返回当前保留的pcap文件路径，按创建顺序排列
+func (w *PcapWriter) Files() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]string(nil), w.files...)
+}
+
+// PacketsWritten 返回已写入的数据包数量
+func (w *PcapWriter) PacketsWritten() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.packetsWritten
+}
+
+// Close 关闭当前pcap文件
+func (w *PcapWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flows = make(map[string]*flowBuffer)
+	return w.closeFile()
+}
+
+// bufferFor 获取数据包所属流的缓冲区，调用方需持有锁
+func (w *PcapWriter) bufferFor(packet *PacketInfo) *flowBuffer {
+	key := pcapFlowKey(packet)
+	buffer, exists := w.flows[key]
+	if exists {
+		return buffer
+	}
+
+	if len(w.flows) >= w.config.MaxFlows {
+		w.evictOldestFlow()
+	}
+
+	buffer = &flowBuffer{
+		packets:  make([]*PacketInfo, w.config.FlowBufferSize),
+		lastSeen: time.Now(),
+	}
+	w.flows[key] = buffer
+	return buffer
+}
+
+// evictOldestFlow 淘汰最久未活动的流，调用方需持有锁
+func (w *PcapWriter) evictOldestFlow() {
+	var oldestKey string
+	var oldest time.Time
+	for key, buffer := range w.flows {
+		if oldestKey == "" || buffer.lastSeen.Before(oldest) {
+			oldestKey = key
+			oldest = buffer.lastSeen
+		}
+	}
+	delete(w.flows, oldestKey)
+}
+
+// writePacket 写入单个数据包记录，必要时轮转文件，调用方需持有锁
+func (w *PcapWriter) writePacket(packet *PacketInfo) error {
+	frame := w.buildFrame(packet)
+	if frame == nil {
+		w.logger.Debug("跳过无法编码的数据包", "packet_id", packet.ID)
+		return nil
+	}
+
+	captured := frame
+	if len(captured) > pcapSnapLen {
+		captured = captured[:pcapSnapLen]
+	}
+	recordSize := int64(pcapRecordHeaderSize + len(captured))
+
+	if w.file != nil && w.fileSize+recordSize > w.config.MaxFileSize {
+		if err := w.closeFile(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		if err := w.openFile(); err != nil {
+			return err
+		}
+	}
+
+	timestamp := packet.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	record := make([]byte, pcapRecordHeaderSize, recordSize)
+	binary.LittleEndian.PutUint32(record[0:4], uint32(timestamp.Unix()))
+	binary.LittleEndian.PutUint32(record[4:8], uint32(timestamp.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(record[8:12], uint32(len(captured)))
+	binary.LittleEndian.PutUint32(record[12:16], uint32(len(frame)))
+	record = append(record, captured...)
+
+	if _, err := w.file.Write(record); err != nil {
+		return fmt.Errorf("写入pcap记录失败: %w", err)
+	}
+	w.fileSize += recordSize
+	w.packetsWritten++
+	return nil
+}
+
+// openFile 创建新的pcap文件并写入全局头，超出保留数量时删除最早的文件
+func (w *PcapWriter) openFile() error {
+	w.fileSeq++
+	name := fmt.Sprintf("dlp_%s_%03d.pcap", time.Now().Format("20060102_150405"), w.fileSeq)
+	path := filepath.Join(w.config.Dir, name)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return fmt.Errorf("创建pcap文件失败: %w", err)
+	}
+
+	header := make([]byte, pcapGlobalHeaderSize)
+	binary.LittleEndian.PutUint32(header[0:4], pcapMagic)
+	binary.LittleEndian.PutUint16(header[4:6], pcapVersionMajor)
+	binary.LittleEndian.PutUint16(header[6:8], pcapVersionMinor)
+	binary.LittleEndian.PutUint32(header[16:20], pcapSnapLen)
+	binary.LittleEndian.PutUint32(header[20:24], pcapLinkTypeRaw)
+
+	if _, err := file.Write(header); err != nil {
+		file.Close()
+		return fmt.Errorf("写入pcap文件头失败: %w", err)
+	}
+
+	w.file = file
+	w.fileSize = pcapGlobalHeaderSize
+
+	// 同一秒内重启可能覆盖之前运行留下的同名文件
+	for i, existing := range w.files {
+		if existing == path {
+			w.files = append(w.files[:i], w.files[i+1:]...)
+			break
+		}
+	}
+	w.files = append(w.files, path)
+	w.pruneFiles()
+
+	w.logger.Info("创建pcap文件", "path", path)
+	return nil
+}
+
+// pruneFiles 删除超出保留数量的最早文件，调用方需持有锁
+func (w *PcapWriter) pruneFiles() {
+	for len(w.files) > w.config.MaxFiles {
+		if err := os.Remove(w.files[0]); err != nil && !os.IsNotExist(err) {
+			w.logger.Warn("删除过期pcap文件失败", "path", w.files[0], "error", err)
+		}
+		w.files = w.files[1:]
+	}
+}
+
+// closeFile 关闭当前pcap文件，调用方需持有锁
+func (w *PcapWriter) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	w.fileSize = 0
+	if err != nil {
+		return fmt.Errorf("关闭pcap文件失败: %w", err)
+	}
+	return nil
+}
+
+// buildFrame 根据数据包信息合成IP和传输层头部
+// 拦截器只保留了应用层载荷，这里重建的头部仅用于离线分析工具识别流
+func (w *PcapWriter) buildFrame(packet *PacketInfo) []byte {
+	payload := packet.Payload
+	if w.config.Redact {
+		payload = make([]byte, len(packet.Payload))
+	}
+
+	var transport []byte
+	switch packet.Protocol {
+	case ProtocolUDP:
+		transport = make([]byte, 8, 8+len(payload))
+		binary.BigEndian.PutUint16(transport[0:2], packet.SourcePort)
+		binary.BigEndian.PutUint16(transport[2:4], packet.DestPort)
+		binary.BigEndian.PutUint16(transport[4:6], uint16(8+len(payload)))
+	default:
+		transport = make([]byte, 20, 20+len(payload))
+		binary.BigEndian.PutUint16(transport[0:2], packet.SourcePort)
+		binary.BigEndian.PutUint16(transport[2:4], packet.DestPort)
+		transport[12] = 5 << 4 // 数据偏移
+		transport[13] = 0x18   // PSH|ACK
+		binary.BigEndian.PutUint16(transport[14:16], 65535)
+	}
+	transport = append(transport, payload...)
+
+	protocol := byte(packet.Protocol)
+	if protocol == 0 {
+		protocol = byte(ProtocolTCP)
+	}
+
+	if src, dst := packet.SourceIP.To4(), packet.DestIP.To4(); src != nil && dst != nil {
+		header := make([]byte, 20, 20+len(transport))
+		header[0] = 0x45
+		binary.BigEndian.PutUint16(header[2:4], uint16(20+len(transport)))
+		header[8] = 64
+		header[9] = protocol
+		copy(header[12:16], src)
+		copy(header[16:20], dst)
+		binary.BigEndian.PutUint16(header[10:12], ipv4Checksum(header))
+		return append(header, transport...)
+	}
+
+	src, dst := packet.SourceIP.To16(), packet.DestIP.To16()
+	if src == nil || dst == nil {
+		return nil
+	}
+	header := make([]byte, 40, 40+len(transport))
+	header[0] = 0x60
+	binary.BigEndian.PutUint16(header[4:6], uint16(len(transport)))
+	header[6] = protocol
+	header[7] = 64
+	copy(header[8:24], src)
+	copy(header[24:40], dst)
+	return append(header, transport...)
+}
+
+// ipv4Checksum 计算IPv4头部校验和
+func ipv4Checksum(header []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(header); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(header[i : i+2]))
+	}
+	for sum > 0xffff {
+		sum = (sum >> 16) + (sum & 0xffff)
+	}
+	return ^uint16(sum)
+}
+
+// pcapFlowKey 生成与方向无关的流标识
+func pcapFlowKey(packet *PacketInfo) string {
+	a := net.JoinHostPort(packet.SourceIP.String(), fmt.Sprint(packet.SourcePort))
+	b := net.JoinHostPort(packet.DestIP.String(), fmt.Sprint(packet.DestPort))
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%d|%s|%s", packet.Protocol, a, b)
+}
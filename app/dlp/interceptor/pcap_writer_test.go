@@ -0,0 +1,173 @@
+package interceptor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPcapTestWriter(t *testing.T, config PcapConfig) *PcapWriter {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	config.Dir = t.TempDir()
+	writer, err := NewPcapWriter(config, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { writer.Close() })
+	return writer
+}
+
+func newPcapTestPacket(srcPort uint16, payload string) *PacketInfo {
+	return &PacketInfo{
+		ID:         fmt.Sprintf("pkt_%d_%s", srcPort, payload),
+		Timestamp:  time.Now(),
+		Direction:  PacketDirectionOutbound,
+		Protocol:   ProtocolTCP,
+		SourceIP:   net.ParseIP("192.168.1.10"),
+		DestIP:     net.ParseIP("203.0.113.5"),
+		SourcePort: srcPort,
+		DestPort:   443,
+		Payload:    []byte(payload),
+		Size:       len(payload),
+	}
+}
+
+// readPcapRecords 校验pcap全局头并返回各记录的数据
+func readPcapRecords(t *testing.T, path string) [][]byte {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(data), pcapGlobalHeaderSize)
+
+	assert.Equal(t, uint32(pcapMagic), binary.LittleEndian.Uint32(data[0:4]))
+	assert.Equal(t, uint16(2), binary.LittleEndian.Uint16(data[4:6]))
+	assert.Equal(t, uint16(4), binary.LittleEndian.Uint16(data[6:8]))
+	assert.Equal(t, uint32(pcapLinkTypeRaw), binary.LittleEndian.Uint32(data[20:24]))
+
+	var records [][]byte
+	offset := pcapGlobalHeaderSize
+	for offset < len(data) {
+		require.LessOrEqual(t, offset+pcapRecordHeaderSize, len(data), "记录头不完整")
+		inclLen := int(binary.LittleEndian.Uint32(data[offset+8 : offset+12]))
+		origLen := int(binary.LittleEndian.Uint32(data[offset+12 : offset+16]))
+		assert.LessOrEqual(t, inclLen, origLen)
+
+		offset += pcapRecordHeaderSize
+		require.LessOrEqual(t, offset+inclLen, len(data), "记录数据不完整")
+		records = append(records, data[offset:offset+inclLen])
+		offset += inclLen
+	}
+	return records
+}
+
+func TestPcapWriter_CapturesFlowHistory(t *testing.T) {
+	config := DefaultPcapConfig()
+	config.FlowBufferSize = 3
+	config.Redact = false
+	writer := newPcapTestWriter(t, config)
+
+	// 同一个流的5个数据包，缓冲区只保留最近3个
+	var packets []*PacketInfo
+	for i := 0; i < 5; i++ {
+		packet := newPcapTestPacket(50000, fmt.Sprintf("chunk-%d", i))
+		packets = append(packets, packet)
+		writer.Record(packet)
+	}
+	// 其他流的数据包不应被导出
+	writer.Record(newPcapTestPacket(50001, "other"))
+
+	require.NoError(t, writer.Capture(packets[4]))
+	assert.Equal(t, uint64(3), writer.PacketsWritten())
+
+	files := writer.Files()
+	require.Len(t, files, 1)
+	require.NoError(t, writer.Close())
+
+	records := readPcapRecords(t, files[0])
+	require.Len(t, records, 3)
+
+	for i, record := range records {
+		assert.Equal(t, byte(0x45), record[0], "应为IPv4头")
+		assert.Equal(t, byte(ProtocolTCP), record[9])
+		assert.Equal(t, uint16(len(record)), binary.BigEndian.Uint16(record[2:4]))
+		assert.Equal(t, uint16(0), ipv4Checksum(record[:20]), "IPv4校验和无效")
+		assert.Equal(t, uint16(50000), binary.BigEndian.Uint16(record[20:22]))
+		assert.Equal(t, fmt.Sprintf("chunk-%d", i+2), string(record[40:]))
+	}
+}
+
+func TestPcapWriter_RedactAndRotate(t *testing.T) {
+	config := DefaultPcapConfig()
+	config.FlowBufferSize = 4
+	config.MaxFileSize = pcapGlobalHeaderSize + 2*(pcapRecordHeaderSize+40+64)
+	config.MaxFiles = 2
+	writer := newPcapTestWriter(t, config)
+
+	secret := "card=4111111111111111"
+	for i := 0; i < 6; i++ {
+		packet := newPcapTestPacket(uint16(50000+i), fmt.Sprintf("%-64s", secret))
+		require.NoError(t, writer.Capture(packet))
+	}
+	assert.Equal(t, uint64(6), writer.PacketsWritten())
+
+	// 每个文件容纳2个数据包，共轮转3个文件，只保留最近2个
+	files := writer.Files()
+	require.Len(t, files, 2)
+	require.NoError(t, writer.Close())
+
+	dirEntries, err := os.ReadDir(writer.config.Dir)
+	require.NoError(t, err)
+	assert.Len(t, dirEntries, 2)
+
+	total := 0
+	for _, path := range files {
+		records := readPcapRecords(t, path)
+		total += len(records)
+		for _, record := range records {
+			assert.Len(t, record, 40+64)
+			assert.NotContains(t, string(record), "4111111111111111")
+		}
+	}
+	assert.Equal(t, 4, total)
+}
+
+func TestPcapWriter_RotationCountsExistingFiles(t *testing.T) {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	// 之前运行留下的文件以及与导出无关的文件
+	dir := t.TempDir()
+	previous := []string{
+		"dlp_20240101_080000_001.pcap",
+		"dlp_20240101_080000_002.pcap",
+		"dlp_20240102_090000_001.pcap",
+	}
+	for _, name := range append(previous, "notes.txt") {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0640))
+	}
+
+	config := DefaultPcapConfig()
+	config.Dir = dir
+	config.MaxFiles = 2
+	writer, err := NewPcapWriter(config, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() { writer.Close() })
+
+	// 启动时即按保留数量清理最早的文件
+	assert.Equal(t, []string{filepath.Join(dir, previous[1]), filepath.Join(dir, previous[2])}, writer.Files())
+	assert.NoFileExists(t, filepath.Join(dir, previous[0]))
+
+	require.NoError(t, writer.Capture(newPcapTestPacket(50000, "payload")))
+	files := writer.Files()
+	require.Len(t, files, 2)
+	assert.Equal(t, filepath.Join(dir, previous[2]), files[0])
+	assert.NoFileExists(t, filepath.Join(dir, previous[1]))
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+}
@@ -150,6 +150,9 @@ type ProcessTracker struct {
 	// 权限提升状态
 	privilegesEnabled bool
 
+	// 连接表刷新调度，合并并发的未命中刷新并自适应调整定期刷新间隔
+	refresher *ConnectionRefresher
+
 	// 监控状态
 	monitoringActive bool
 	stopMonitoring   chan bool
@@ -173,6 +176,7 @@ func NewProcessTracker(logger logging.Logger) *ProcessTracker {
 		processSnapshot: make(map[uint32]string),
 		stopMonitoring:  make(chan bool, 1),
 	}
+	pt.refresher = NewConnectionRefresher(DefaultConnectionRefreshConfig(), pt.UpdateConnectionTables)
 
 	// 加载Windows API
 	pt.loadWindowsAPIs()
@@ -446,13 +450,14 @@ func (pt *ProcessTracker) GetProcessByConnection(protocol Protocol, localIP net.
 	// 首先尝试从缓存查找
 	pid := pt.findProcessInCache(protocol, localIP, localPort)
 	if pid != 0 {
+		pt.refresher.RecordHit()
 		pt.logger.Debug("从缓存找到进程", "protocol", protocol, "ip", localIP.String(), "port", localPort, "pid", pid)
 		return pid
 	}
 
-	// 如果缓存中没有，强制更新连接表
+	// 如果缓存中没有，刷新连接表；并发的未命中只触发一次刷新，刚刷新过时不再刷新
 	pt.logger.Debug("缓存未命中，更新连接表", "protocol", protocol, "ip", localIP.String(), "port", localPort)
-	if err := pt.UpdateConnectionTables(); err != nil {
+	if err := pt.refresher.RefreshOnMiss(); err != nil {
 		pt.logger.Error("更新连接表失败", "error", err)
 		return 0
 	}
@@ -585,6 +590,7 @@ func (pt *ProcessTracker) GetProcessByConnectionEx(protocol Protocol, localIP ne
 	// 首先尝试四元组精确匹配
 	pid := pt.findProcessByQuadruple(protocol, localIP, localPort, remoteIP, remotePort)
 	if pid != 0 {
+		pt.refresher.RecordHit()
 		return pid
 	}
 
@@ -1229,6 +1235,7 @@ func (pt *ProcessTracker) getProcessIntegrityLevel(handle uintptr) string {
 }
 
 // StartPeriodicUpdate 启动定期更新（增强版本）
+// interval 为初始刷新间隔，之后按进程查找的未命中率在刷新配置的上下限之间自适应调整
 func (pt *ProcessTracker) StartPeriodicUpdate(interval time.Duration) {
 	pt.mu.Lock()
 	if pt.monitoringActive {
@@ -1239,7 +1246,8 @@ func (pt *ProcessTracker) StartPeriodicUpdate(interval time.Duration) {
 	pt.monitoringActive = true
 	pt.mu.Unlock()
 
-	pt.logger.Info("启动连接表定期监控", "interval", interval)
+	pt.refresher.SetInterval(interval)
+	pt.logger.Info("启动连接表定期监控", "interval", pt.refresher.Interval())
 
 	go func() {
 		defer func() {
@@ -1249,11 +1257,10 @@ func (pt *ProcessTracker) StartPeriodicUpdate(interval time.Duration) {
 			pt.logger.Info("连接表监控已停止")
 		}()
 
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
+		currentInterval := pt.refresher.Interval()
+		timer := time.NewTimer(currentInterval)
+		defer timer.Stop()
 
-		// 自适应更新间隔
-		adaptiveInterval := interval
 		consecutiveFailures := 0
 		maxFailures := 3
 
@@ -1262,44 +1269,47 @@ func (pt *ProcessTracker) StartPeriodicUpdate(interval time.Duration) {
 			case <-pt.stopMonitoring:
 				pt.logger.Info("收到停止监控信号")
 				return
-			case <-ticker.C:
+			case <-timer.C:
 				updateStart := time.Now()
 
-				if err := pt.UpdateConnectionTables(); err != nil {
+				nextInterval, err := pt.refresher.RefreshPeriodic()
+				if nextInterval != currentInterval {
+					pt.logger.Debug("按未命中率调整更新间隔",
+						"old_interval", currentInterval,
+						"new_interval", nextInterval,
+						"miss_rate", pt.refresher.Stats().MissRate)
+				}
+				currentInterval = nextInterval
+
+				if err != nil {
 					consecutiveFailures++
 					pt.logger.Error("定期更新连接表失败",
 						"error", err,
 						"consecutive_failures", consecutiveFailures)
 
-					// 自适应调整：失败时增加更新间隔
+					// 连续失败时增加更新间隔，最大60秒
 					if consecutiveFailures >= maxFailures {
-						newInterval := adaptiveInterval * 2
-						if newInterval <= 60*time.Second { // 最大60秒
-							adaptiveInterval = newInterval
-							ticker.Reset(adaptiveInterval)
-							pt.logger.Warn("由于连续失败，调整更新间隔",
-								"new_interval", adaptiveInterval)
-						}
-					}
-				} else {
-					// 成功时重置失败计数和间隔
-					if consecutiveFailures > 0 {
-						consecutiveFailures = 0
-						if adaptiveInterval != interval {
-							adaptiveInterval = interval
-							ticker.Reset(adaptiveInterval)
-							pt.logger.Info("恢复正常更新间隔", "interval", interval)
+						nextInterval *= 2
+						if nextInterval > 60*time.Second {
+							nextInterval = 60 * time.Second
 						}
+						pt.logger.Warn("由于连续失败，调整更新间隔",
+							"new_interval", nextInterval)
 					}
+				} else if consecutiveFailures > 0 {
+					consecutiveFailures = 0
+					pt.logger.Info("恢复正常更新间隔", "interval", nextInterval)
 				}
 
 				// 性能监控
 				updateDuration := time.Since(updateStart)
-				if updateDuration > interval/2 {
+				if updateDuration > nextInterval/2 {
 					pt.logger.Warn("连接表更新耗时过长",
 						"duration", updateDuration,
-						"interval", interval)
+						"interval", nextInterval)
 				}
+
+				timer.Reset(nextInterval)
 			}
 		}
 	}()
@@ -1349,6 +1359,17 @@ func (pt *ProcessTracker) GetMonitoringStats() map[string]interface{} {
 		stats["success_rate"] = float64(pt.updateStats.successUpdates) / float64(pt.updateStats.totalUpdates)
 	}
 
+	refresh := pt.refresher.Stats()
+	stats["refresh_count"] = refresh.Refreshes
+	stats["miss_refreshes"] = refresh.MissRefreshes
+	stats["periodic_refreshes"] = refresh.PeriodicRefreshes
+	stats["coalesced_refreshes"] = refresh.CoalescedRefreshes
+	stats["skipped_refreshes"] = refresh.SkippedRefreshes
+	stats["lookups"] = refresh.Lookups
+	stats["lookup_misses"] = refresh.Misses
+	stats["miss_rate"] = refresh.MissRate
+	stats["refresh_interval"] = refresh.Interval
+
 	return stats
 }
 
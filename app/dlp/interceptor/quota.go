@@ -0,0 +1,162 @@
+package interceptor
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetadataQuotaExceeded 数据包元数据中标记进程超出流量配额的键
+const MetadataQuotaExceeded = "quota_exceeded"
+
+// TrafficQuotaConfig 按进程的出站流量配额配置
+type TrafficQuotaConfig struct {
+	Enabled       bool             `yaml:"enabled" json:"enabled"`
+	Window        time.Duration    `yaml:"window" json:"window"`                 // 统计窗口，窗口结束后计数清零
+	DefaultLimit  int64            `yaml:"default_limit" json:"default_limit"`   // 每个进程在一个窗口内允许上传的字节数，0表示不限制
+	ProcessLimits map[string]int64 `yaml:"process_limits" json:"process_limits"` // 按进程名覆盖配额（不区分大小写）
+}
+
+// DefaultTrafficQuotaConfig 返回默认流量配额配置
+func DefaultTrafficQuotaConfig() TrafficQuotaConfig {
+	return TrafficQuotaConfig{
+		Enabled:       false,
+		Window:        time.Minute,
+		DefaultLimit:  100 * 1024 * 1024,
+		ProcessLimits: make(map[string]int64),
+	}
+}
+
+// QuotaStatus 进程在当前窗口内的配额使用情况
+type QuotaStatus struct {
+	PID         int       `json:"pid"`
+	ProcessName string    `json:"process_name"`
+	Bytes       int64     `json:"bytes"`
+	Limit       int64     `json:"limit"`
+	Exceeded    bool      `json:"exceeded"`
+	WindowStart time.Time `json:"window_start"`
+}
+
+// quotaCounter 单个进程的窗口计数
+type quotaCounter struct {
+	bytes       int64
+	windowStart time.Time
+}
+
+// TrafficQuota 按进程统计出站字节数，超出配额时标记数据包
+type TrafficQuota struct {
+	config    TrafficQuotaConfig
+	counters  map[string]*quotaCounter
+	lastSweep time.Time
+	now       func() time.Time
+	mu        sync.Mutex
+}
+
+// NewTrafficQuota 创建流量配额跟踪器
+func NewTrafficQuota(config TrafficQuotaConfig) *TrafficQuota {
+	if config.Window <= 0 {
+		config.Window = DefaultTrafficQuotaConfig().Window
+	}
+
+	limits := make(map[string]int64, len(config.ProcessLimits))
+	for name, limit := range config.ProcessLimits {
+		limits[strings.ToLower(name)] = limit
+	}
+	config.ProcessLimits = limits
+
+	return &TrafficQuota{
+		config:    config,
+		counters:  make(map[string]*quotaCounter),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Record 记录出站数据包并返回所属进程的配额状态
+// 无进程信息的数据包和入站数据包不计入配额，返回nil；
+// 超出配额时在数据包元数据中设置 MetadataQuotaExceeded
+func (q *TrafficQuota) Record(packet *PacketInfo) *QuotaStatus {
+	if packet == nil || packet.ProcessInfo == nil || packet.Direction != PacketDirectionOutbound {
+		return nil
+	}
+
+	process := packet.ProcessInfo
+	limit := q.limitFor(process.ProcessName)
+	if limit <= 0 {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	q.sweep(now)
+
+	key := quotaKey(process)
+	counter, exists := q.counters[key]
+	if !exists || now.Sub(counter.windowStart) >= q.config.Window {
+		counter = &quotaCounter{windowStart: now}
+		q.counters[key] = counter
+	}
+	counter.bytes += int64(packet.Size)
+
+	status := &QuotaStatus{
+		PID:         process.PID,
+		ProcessName: process.ProcessName,
+		Bytes:       counter.bytes,
+		Limit:       limit,
+		Exceeded:    counter.bytes > limit,
+		WindowStart: counter.windowStart,
+	}
+
+	if status.Exceeded {
+		if packet.Metadata == nil {
+			packet.Metadata = make(map[string]interface{})
+		}
+		packet.Metadata[MetadataQuotaExceeded] = true
+	}
+	return status
+}
+
+// Usage 返回进程在当前窗口内已上传的字节数
+func (q *TrafficQuota) Usage(process *ProcessInfo) int64 {
+	if process == nil {
+		return 0
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	counter, exists := q.counters[quotaKey(process)]
+	if !exists || q.now().Sub(counter.windowStart) >= q.config.Window {
+		return 0
+	}
+	return counter.bytes
+}
+
+// limitFor 返回进程的配额
+func (q *TrafficQuota) limitFor(processName string) int64 {
+	if limit, exists := q.config.ProcessLimits[strings.ToLower(processName)]; exists {
+		return limit
+	}
+	return q.config.DefaultLimit
+}
+
+// sweep 每个窗口清理一次已过期的计数，避免已退出的进程占用内存
+func (q *TrafficQuota) sweep(now time.Time) {
+	if now.Sub(q.lastSweep) < q.config.Window {
+		return
+	}
+	for key, counter := range q.counters {
+		if now.Sub(counter.windowStart) >= q.config.Window {
+			delete(q.counters, key)
+		}
+	}
+	q.lastSweep = now
+}
+
+// quotaKey 进程计数键，同名的不同进程分别统计
+func quotaKey(process *ProcessInfo) string {
+	return strings.ToLower(process.ProcessName) + ":" + strconv.Itoa(process.PID)
+}
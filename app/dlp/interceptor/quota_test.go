@@ -0,0 +1,121 @@
+package interceptor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newQuotaTestTracker 创建使用可控时钟的配额跟踪器
+func newQuotaTestTracker(config TrafficQuotaConfig) (*TrafficQuota, *time.Time) {
+	quota := NewTrafficQuota(config)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	quota.now = func() time.Time { return now }
+	quota.lastSweep = now
+	return quota, &now
+}
+
+func newQuotaTestPacket(pid int, name string, size int) *PacketInfo {
+	return &PacketInfo{
+		Direction:   PacketDirectionOutbound,
+		Size:        size,
+		ProcessInfo: &ProcessInfo{PID: pid, ProcessName: name},
+	}
+}
+
+func TestTrafficQuota_UnderQuota(t *testing.T) {
+	quota, _ := newQuotaTestTracker(TrafficQuotaConfig{Window: time.Minute, DefaultLimit: 1000})
+
+	var status *QuotaStatus
+	for i := 0; i < 10; i++ {
+		packet := newQuotaTestPacket(100, "chrome.exe", 100)
+		status = quota.Record(packet)
+		require.NotNil(t, status)
+		assert.False(t, status.Exceeded)
+		assert.NotContains(t, packet.Metadata, MetadataQuotaExceeded)
+	}
+
+	// 恰好用完配额不算超出
+	assert.Equal(t, int64(1000), status.Bytes)
+	assert.Equal(t, int64(1000), status.Limit)
+	assert.Equal(t, "chrome.exe", status.ProcessName)
+}
+
+func TestTrafficQuota_ExceedQuota(t *testing.T) {
+	quota, _ := newQuotaTestTracker(TrafficQuotaConfig{Window: time.Minute, DefaultLimit: 1000})
+
+	status := quota.Record(newQuotaTestPacket(200, "rclone.exe", 900))
+	require.NotNil(t, status)
+	assert.False(t, status.Exceeded)
+
+	packet := newQuotaTestPacket(200, "rclone.exe", 200)
+	status = quota.Record(packet)
+	require.NotNil(t, status)
+	assert.True(t, status.Exceeded)
+	assert.Equal(t, int64(1100), status.Bytes)
+	assert.Equal(t, true, packet.Metadata[MetadataQuotaExceeded])
+
+	// 其他进程不受影响
+	other := newQuotaTestPacket(300, "rclone.exe", 100)
+	status = quota.Record(other)
+	assert.False(t, status.Exceeded)
+	assert.NotContains(t, other.Metadata, MetadataQuotaExceeded)
+}
+
+func TestTrafficQuota_WindowReset(t *testing.T) {
+	quota, now := newQuotaTestTracker(TrafficQuotaConfig{Window: time.Minute, DefaultLimit: 1000})
+
+	status := quota.Record(newQuotaTestPacket(200, "rclone.exe", 1500))
+	assert.True(t, status.Exceeded)
+	assert.Equal(t, int64(1500), quota.Usage(&ProcessInfo{PID: 200, ProcessName: "rclone.exe"}))
+
+	// 窗口结束后计数清零
+	*now = now.Add(time.Minute)
+	assert.Equal(t, int64(0), quota.Usage(&ProcessInfo{PID: 200, ProcessName: "rclone.exe"}))
+
+	status = quota.Record(newQuotaTestPacket(200, "rclone.exe", 500))
+	assert.False(t, status.Exceeded)
+	assert.Equal(t, int64(500), status.Bytes)
+	assert.Equal(t, *now, status.WindowStart)
+}
+
+func TestTrafficQuota_ProcessLimitsAndSkips(t *testing.T) {
+	quota, _ := newQuotaTestTracker(TrafficQuotaConfig{
+		Window:       time.Minute,
+		DefaultLimit: 1000,
+		ProcessLimits: map[string]int64{
+			"Backup.exe": 0,
+			"curl.exe":   50,
+		},
+	})
+
+	// 配额为0的进程不限制
+	assert.Nil(t, quota.Record(newQuotaTestPacket(1, "backup.exe", 5000)))
+
+	// 按进程名覆盖配额，不区分大小写
+	status := quota.Record(newQuotaTestPacket(2, "CURL.EXE", 60))
+	require.NotNil(t, status)
+	assert.True(t, status.Exceeded)
+	assert.Equal(t, int64(50), status.Limit)
+
+	// 入站数据包和无进程信息的数据包不计入
+	inbound := newQuotaTestPacket(3, "chrome.exe", 5000)
+	inbound.Direction = PacketDirectionInbound
+	assert.Nil(t, quota.Record(inbound))
+	assert.Nil(t, quota.Record(&PacketInfo{Direction: PacketDirectionOutbound, Size: 5000}))
+}
+
+func TestTrafficQuota_SweepExpiredCounters(t *testing.T) {
+	quota, now := newQuotaTestTracker(TrafficQuotaConfig{Window: time.Minute, DefaultLimit: 1000})
+
+	for pid := 1; pid <= 5; pid++ {
+		quota.Record(newQuotaTestPacket(pid, "app.exe", 10))
+	}
+	assert.Len(t, quota.counters, 5)
+
+	*now = now.Add(2 * time.Minute)
+	quota.Record(newQuotaTestPacket(9, "app.exe", 10))
+	assert.Len(t, quota.counters, 1)
+}
@@ -1,29 +1,121 @@
 package interceptor
 
 import (
+	"fmt"
 	"sync"
 	"time"
 
 	"github.com/lomehong/kennel/pkg/logging"
 )
 
+// limiterDisengageDelay 连续该时长没有丢弃数据包后，视为流量限制解除
+const limiterDisengageDelay = time.Second
+
+// RateLimiterConfig 流量限制器配置
+type RateLimiterConfig struct {
+	MaxPacketsPerSecond int64         `yaml:"max_packets_per_second" json:"max_packets_per_second"` // 每秒最大数据包数
+	MaxBytesPerSecond   int64         `yaml:"max_bytes_per_second" json:"max_bytes_per_second"`     // 每秒最大字节数
+	BurstSize           int64         `yaml:"burst_size" json:"burst_size"`                         // 突发数据包数
+	CPUThreshold        float64       `yaml:"cpu_threshold" json:"cpu_threshold"`                   // CPU使用率阈值（百分比），超过后按比例降低限制
+	MemoryThreshold     float64       `yaml:"memory_threshold" json:"memory_threshold"`             // 内存使用率阈值（百分比）
+	CheckInterval       time.Duration `yaml:"check_interval" json:"check_interval"`                 // 资源检查间隔
+}
+
+// DefaultRateLimiterConfig 返回默认流量限制器配置
+func DefaultRateLimiterConfig() RateLimiterConfig {
+	return RateLimiterConfig{
+		MaxPacketsPerSecond: 1000,     // 每秒最大1000个数据包
+		MaxBytesPerSecond:   10485760, // 每秒最大10MB
+		BurstSize:           100,      // 突发大小100
+		CPUThreshold:        80.0,     // CPU阈值80%
+		MemoryThreshold:     80.0,     // 内存阈值80%
+		CheckInterval:       time.Minute,
+	}
+}
+
+// Validate 验证流量限制器配置
+func (c RateLimiterConfig) Validate() error {
+	if c.MaxPacketsPerSecond <= 0 {
+		return fmt.Errorf("每秒最大数据包数必须大于0")
+	}
+	if c.MaxBytesPerSecond <= 0 {
+		return fmt.Errorf("每秒最大字节数必须大于0")
+	}
+	if c.BurstSize <= 0 {
+		return fmt.Errorf("突发大小必须大于0")
+	}
+	if c.CPUThreshold <= 0 || c.CPUThreshold > 100 {
+		return fmt.Errorf("CPU阈值必须在(0, 100]范围内")
+	}
+	if c.MemoryThreshold <= 0 || c.MemoryThreshold > 100 {
+		return fmt.Errorf("内存阈值必须在(0, 100]范围内")
+	}
+	if c.CheckInterval <= 0 {
+		return fmt.Errorf("资源检查间隔必须大于0")
+	}
+	return nil
+}
+
+// LimiterEventType 流量限制器事件类型
+type LimiterEventType string
+
+const (
+	LimiterEventEngaged    LimiterEventType = "engaged"    // 开始限制流量
+	LimiterEventDisengaged LimiterEventType = "disengaged" // 解除流量限制
+)
+
+// 流量限制原因
+const (
+	LimiterReasonRateExceeded     = "rate_exceeded"     // 流量超出限制，开始丢弃数据包
+	LimiterReasonResourcePressure = "resource_pressure" // 系统资源紧张，自适应降低限制
+)
+
+// LimiterEvent 流量限制器状态变化事件
+type LimiterEvent struct {
+	Type      LimiterEventType `json:"type"`
+	Reason    string           `json:"reason"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// LimiterEventHandler 流量限制器事件处理函数
+type LimiterEventHandler func(event LimiterEvent)
+
+// LimiterState 流量限制器运行状态
+type LimiterState struct {
+	Params                    RateLimiterConfig `json:"params"`                       // 配置的限制参数
+	EffectivePacketsPerSecond int64             `json:"effective_packets_per_second"` // 自适应调整后的每秒最大数据包数
+	EffectiveBytesPerSecond   int64             `json:"effective_bytes_per_second"`   // 自适应调整后的每秒最大字节数
+	EffectiveBurstSize        int64             `json:"effective_burst_size"`         // 自适应调整后的突发大小
+	AdjustmentFactor          float64           `json:"adjustment_factor"`            // 当前调整因子
+	Engaged                   bool              `json:"engaged"`                      // 是否正在丢弃数据包
+	Throttled                 bool              `json:"throttled"`                    // 是否因资源紧张降低了限制
+	PacketsDropped            uint64            `json:"packets_dropped"`
+	BytesDropped              uint64            `json:"bytes_dropped"`
+}
+
 // RateLimiter 流量限制器
 type RateLimiter struct {
 	maxPacketsPerSecond int64
 	maxBytesPerSecond   int64
 	burstSize           int64
-	
+
 	// 令牌桶
 	packetTokens int64
 	byteTokens   int64
-	
+
 	// 时间跟踪
 	lastRefill time.Time
-	
+	lastDrop   time.Time
+	now        func() time.Time
+
 	// 统计信息
 	packetsDropped uint64
 	bytesDropped   uint64
-	
+
+	// 是否正在丢弃数据包
+	engaged      bool
+	eventHandler LimiterEventHandler
+
 	mu     sync.Mutex
 	logger logging.Logger
 }
@@ -37,58 +129,95 @@ func NewRateLimiter(maxPacketsPerSecond, maxBytesPerSecond, burstSize int64, log
 		packetTokens:        burstSize,
 		byteTokens:          maxBytesPerSecond,
 		lastRefill:          time.Now(),
+		now:                 time.Now,
 		logger:              logger,
 	}
 }
 
+// SetEventHandler 设置限制开始和解除时的事件处理函数
+func (rl *RateLimiter) SetEventHandler(handler LimiterEventHandler) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.eventHandler = handler
+}
+
 // AllowPacket 检查是否允许处理数据包
 func (rl *RateLimiter) AllowPacket(packetSize int64) bool {
 	rl.mu.Lock()
-	defer rl.mu.Unlock()
-	
+
 	// 补充令牌
-	rl.refillTokens()
-	
+	now := rl.now()
+	rl.refillTokens(now)
+
 	// 检查是否有足够的令牌
-	if rl.packetTokens > 0 && rl.byteTokens >= packetSize {
+	allowed := rl.packetTokens > 0 && rl.byteTokens >= packetSize
+	var event *LimiterEvent
+	if allowed {
 		rl.packetTokens--
 		rl.byteTokens -= packetSize
-		return true
+
+		if rl.engaged && now.Sub(rl.lastDrop) >= limiterDisengageDelay {
+			rl.engaged = false
+			event = &LimiterEvent{Type: LimiterEventDisengaged, Reason: LimiterReasonRateExceeded, Timestamp: now}
+		}
+	} else {
+		// 记录丢弃统计
+		rl.packetsDropped++
+		rl.bytesDropped += uint64(packetSize)
+		rl.lastDrop = now
+
+		if !rl.engaged {
+			rl.engaged = true
+			event = &LimiterEvent{Type: LimiterEventEngaged, Reason: LimiterReasonRateExceeded, Timestamp: now}
+		}
+	}
+	handler := rl.eventHandler
+	rl.mu.Unlock()
+
+	if event != nil {
+		rl.emit(handler, *event)
+	}
+	return allowed
+}
+
+// emit 记录并分发事件，调用方不能持有锁
+func (rl *RateLimiter) emit(handler LimiterEventHandler, event LimiterEvent) {
+	if event.Type == LimiterEventEngaged {
+		rl.logger.Warn("流量限制生效", "reason", event.Reason)
+	} else {
+		rl.logger.Info("流量限制解除", "reason", event.Reason)
+	}
+	if handler != nil {
+		handler(event)
 	}
-	
-	// 记录丢弃统计
-	rl.packetsDropped++
-	rl.bytesDropped += uint64(packetSize)
-	
-	return false
 }
 
 // refillTokens 补充令牌
-func (rl *RateLimiter) refillTokens() {
-	now := time.Now()
+func (rl *RateLimiter) refillTokens(now time.Time) {
 	elapsed := now.Sub(rl.lastRefill)
-	
+
 	if elapsed < time.Millisecond*100 { // 最小补充间隔
 		return
 	}
-	
+
 	// 计算应该补充的令牌数
 	seconds := elapsed.Seconds()
-	
+
 	// 补充数据包令牌
 	packetTokensToAdd := int64(seconds * float64(rl.maxPacketsPerSecond))
 	rl.packetTokens += packetTokensToAdd
 	if rl.packetTokens > rl.burstSize {
 		rl.packetTokens = rl.burstSize
 	}
-	
+
 	// 补充字节令牌
 	byteTokensToAdd := int64(seconds * float64(rl.maxBytesPerSecond))
 	rl.byteTokens += byteTokensToAdd
 	if rl.byteTokens > rl.maxBytesPerSecond {
 		rl.byteTokens = rl.maxBytesPerSecond
 	}
-	
+
 	rl.lastRefill = now
 }
 
@@ -96,7 +225,7 @@ func (rl *RateLimiter) refillTokens() {
 func (rl *RateLimiter) GetStats() (packetsDropped, bytesDropped uint64) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
-	
+
 	return rl.packetsDropped, rl.bytesDropped
 }
 
@@ -104,7 +233,7 @@ func (rl *RateLimiter) GetStats() (packetsDropped, bytesDropped uint64) {
 func (rl *RateLimiter) Reset() {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
-	
+
 	rl.packetsDropped = 0
 	rl.bytesDropped = 0
 }
@@ -113,11 +242,11 @@ func (rl *RateLimiter) Reset() {
 func (rl *RateLimiter) UpdateLimits(maxPacketsPerSecond, maxBytesPerSecond, burstSize int64) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
-	
+
 	rl.maxPacketsPerSecond = maxPacketsPerSecond
 	rl.maxBytesPerSecond = maxBytesPerSecond
 	rl.burstSize = burstSize
-	
+
 	// 调整当前令牌数
 	if rl.packetTokens > burstSize {
 		rl.packetTokens = burstSize
@@ -125,38 +254,49 @@ func (rl *RateLimiter) UpdateLimits(maxPacketsPerSecond, maxBytesPerSecond, burs
 	if rl.byteTokens > maxBytesPerSecond {
 		rl.byteTokens = maxBytesPerSecond
 	}
-	
+
 	rl.logger.Info("更新流量限制参数",
 		"max_packets_per_second", maxPacketsPerSecond,
 		"max_bytes_per_second", maxBytesPerSecond,
 		"burst_size", burstSize)
 }
 
+// fillTokens 将令牌桶补满，使新的限制参数立即生效
+func (rl *RateLimiter) fillTokens() {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.packetTokens = rl.burstSize
+	rl.byteTokens = rl.maxBytesPerSecond
+	rl.lastRefill = rl.now()
+}
+
 // AdaptiveLimiter 自适应流量限制器
 type AdaptiveLimiter struct {
 	*RateLimiter
-	
+
 	// 自适应参数
 	cpuThreshold    float64
 	memoryThreshold float64
 	checkInterval   time.Duration
-	
+
 	// 原始限制值
 	originalPacketsPerSecond int64
 	originalBytesPerSecond   int64
 	originalBurstSize        int64
-	
+
 	// 当前调整因子
 	adjustmentFactor float64
-	
+
 	lastCheck time.Time
+	adaptMu   sync.Mutex
 	logger    logging.Logger
 }
 
 // NewAdaptiveLimiter 创建自适应流量限制器
-func NewAdaptiveLimiter(maxPacketsPerSecond, maxBytesPerSecond, burstSize int64, 
+func NewAdaptiveLimiter(maxPacketsPerSecond, maxBytesPerSecond, burstSize int64,
 	cpuThreshold, memoryThreshold float64, checkInterval time.Duration, logger logging.Logger) *AdaptiveLimiter {
-	
+
 	return &AdaptiveLimiter{
 		RateLimiter:              NewRateLimiter(maxPacketsPerSecond, maxBytesPerSecond, burstSize, logger),
 		cpuThreshold:             cpuThreshold,
@@ -171,52 +311,151 @@ func NewAdaptiveLimiter(maxPacketsPerSecond, maxBytesPerSecond, burstSize int64,
 	}
 }
 
+// NewAdaptiveLimiterFromConfig 根据配置创建自适应流量限制器，配置无效时使用默认配置
+func NewAdaptiveLimiterFromConfig(config RateLimiterConfig, logger logging.Logger) *AdaptiveLimiter {
+	if err := config.Validate(); err != nil {
+		logger.Warn("流量限制器配置无效，使用默认配置", "error", err)
+		config = DefaultRateLimiterConfig()
+	}
+
+	return NewAdaptiveLimiter(
+		config.MaxPacketsPerSecond,
+		config.MaxBytesPerSecond,
+		config.BurstSize,
+		config.CPUThreshold,
+		config.MemoryThreshold,
+		config.CheckInterval,
+		logger,
+	)
+}
+
+// GetLimiterState 获取流量限制器的当前参数和运行状态
+func (al *AdaptiveLimiter) GetLimiterState() LimiterState {
+	al.adaptMu.Lock()
+	state := LimiterState{
+		Params: RateLimiterConfig{
+			MaxPacketsPerSecond: al.originalPacketsPerSecond,
+			MaxBytesPerSecond:   al.originalBytesPerSecond,
+			BurstSize:           al.originalBurstSize,
+			CPUThreshold:        al.cpuThreshold,
+			MemoryThreshold:     al.memoryThreshold,
+			CheckInterval:       al.checkInterval,
+		},
+		AdjustmentFactor: al.adjustmentFactor,
+		Throttled:        al.adjustmentFactor < 1.0,
+	}
+	al.adaptMu.Unlock()
+
+	al.mu.Lock()
+	state.EffectivePacketsPerSecond = al.maxPacketsPerSecond
+	state.EffectiveBytesPerSecond = al.maxBytesPerSecond
+	state.EffectiveBurstSize = al.burstSize
+	state.Engaged = al.engaged
+	state.PacketsDropped = al.packetsDropped
+	state.BytesDropped = al.bytesDropped
+	al.mu.Unlock()
+
+	return state
+}
+
+// SetLimiterParams 在运行时更新限制参数，无需重启拦截器
+// 当前的自适应调整因子继续作用于新参数
+func (al *AdaptiveLimiter) SetLimiterParams(params RateLimiterConfig) error {
+	if err := params.Validate(); err != nil {
+		return fmt.Errorf("无效的流量限制参数: %w", err)
+	}
+
+	al.adaptMu.Lock()
+	al.cpuThreshold = params.CPUThreshold
+	al.memoryThreshold = params.MemoryThreshold
+	al.checkInterval = params.CheckInterval
+	al.originalPacketsPerSecond = params.MaxPacketsPerSecond
+	al.originalBytesPerSecond = params.MaxBytesPerSecond
+	al.originalBurstSize = params.BurstSize
+	factor := al.adjustmentFactor
+	al.adaptMu.Unlock()
+
+	al.UpdateLimits(scaleLimit(params.MaxPacketsPerSecond, factor), scaleLimit(params.MaxBytesPerSecond, factor), scaleLimit(params.BurstSize, factor))
+	al.fillTokens()
+	return nil
+}
+
 // CheckAndAdjust 检查系统资源并调整限制
 func (al *AdaptiveLimiter) CheckAndAdjust(cpuUsage, memoryUsage float64) {
+	al.adaptMu.Lock()
+
 	now := time.Now()
 	if now.Sub(al.lastCheck) < al.checkInterval {
+		al.adaptMu.Unlock()
 		return
 	}
-	
+
 	al.lastCheck = now
-	
+
 	// 计算新的调整因子
 	newFactor := 1.0
-	
+
 	if cpuUsage > al.cpuThreshold {
 		// CPU使用率过高，降低限制
 		newFactor *= (al.cpuThreshold / cpuUsage)
 	}
-	
+
 	if memoryUsage > al.memoryThreshold {
 		// 内存使用率过高，降低限制
 		newFactor *= (al.memoryThreshold / memoryUsage)
 	}
-	
+
 	// 限制调整范围
 	if newFactor < 0.1 {
 		newFactor = 0.1 // 最低10%
 	} else if newFactor > 1.0 {
 		newFactor = 1.0 // 最高100%
 	}
-	
-	// 如果调整因子有显著变化，更新限制
-	if abs(newFactor-al.adjustmentFactor) > 0.1 {
-		al.adjustmentFactor = newFactor
-		
-		newPacketsPerSecond := int64(float64(al.originalPacketsPerSecond) * newFactor)
-		newBytesPerSecond := int64(float64(al.originalBytesPerSecond) * newFactor)
-		newBurstSize := int64(float64(al.originalBurstSize) * newFactor)
-		
-		al.UpdateLimits(newPacketsPerSecond, newBytesPerSecond, newBurstSize)
-		
-		al.logger.Info("自适应调整流量限制",
-			"cpu_usage", cpuUsage,
-			"memory_usage", memoryUsage,
-			"adjustment_factor", newFactor,
-			"new_packets_per_second", newPacketsPerSecond,
-			"new_bytes_per_second", newBytesPerSecond)
+
+	// 如果调整因子有显著变化，或资源恢复正常，更新限制
+	oldFactor := al.adjustmentFactor
+	if abs(newFactor-oldFactor) <= 0.1 && !(newFactor == 1.0 && oldFactor < 1.0) {
+		al.adaptMu.Unlock()
+		return
+	}
+	al.adjustmentFactor = newFactor
+
+	newPacketsPerSecond := scaleLimit(al.originalPacketsPerSecond, newFactor)
+	newBytesPerSecond := scaleLimit(al.originalBytesPerSecond, newFactor)
+	newBurstSize := scaleLimit(al.originalBurstSize, newFactor)
+	al.adaptMu.Unlock()
+
+	al.UpdateLimits(newPacketsPerSecond, newBytesPerSecond, newBurstSize)
+
+	al.logger.Info("自适应调整流量限制",
+		"cpu_usage", cpuUsage,
+		"memory_usage", memoryUsage,
+		"adjustment_factor", newFactor,
+		"new_packets_per_second", newPacketsPerSecond,
+		"new_bytes_per_second", newBytesPerSecond)
+
+	// 资源紧张开始降低限制或恢复正常时发出事件
+	var event *LimiterEvent
+	if oldFactor >= 1.0 && newFactor < 1.0 {
+		event = &LimiterEvent{Type: LimiterEventEngaged, Reason: LimiterReasonResourcePressure, Timestamp: now}
+	} else if oldFactor < 1.0 && newFactor >= 1.0 {
+		event = &LimiterEvent{Type: LimiterEventDisengaged, Reason: LimiterReasonResourcePressure, Timestamp: now}
+	}
+	if event != nil {
+		al.mu.Lock()
+		handler := al.eventHandler
+		al.mu.Unlock()
+		al.emit(handler, *event)
+	}
+}
+
+// scaleLimit 按调整因子缩放限制值，结果至少为1
+func scaleLimit(limit int64, factor float64) int64 {
+	scaled := int64(float64(limit) * factor)
+	if scaled < 1 {
+		return 1
 	}
+	return scaled
 }
 
 func abs(x float64) float64 {
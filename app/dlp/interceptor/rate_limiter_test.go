@@ -0,0 +1,140 @@
+package interceptor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLimiterTestLimiter 创建使用可控时钟的流量限制器，并收集其发出的事件
+func newLimiterTestLimiter(t *testing.T, config RateLimiterConfig) (*AdaptiveLimiter, *time.Time, *[]LimiterEvent) {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	limiter := NewAdaptiveLimiterFromConfig(config, logger)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	limiter.lastRefill = now
+
+	var events []LimiterEvent
+	limiter.SetEventHandler(func(event LimiterEvent) {
+		events = append(events, event)
+	})
+	return limiter, &now, &events
+}
+
+// allowN 连续提交 n 个数据包，返回被允许的数量
+func allowN(limiter *AdaptiveLimiter, n int, size int64) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		if limiter.AllowPacket(size) {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func testLimiterConfig() RateLimiterConfig {
+	config := DefaultRateLimiterConfig()
+	config.MaxPacketsPerSecond = 10
+	config.BurstSize = 5
+	return config
+}
+
+func TestRateLimiter_DropsWhenRateExceeded(t *testing.T) {
+	limiter, now, events := newLimiterTestLimiter(t, testLimiterConfig())
+
+	assert.Equal(t, 5, allowN(limiter, 8, 100), "超出突发大小的数据包应被丢弃")
+
+	state := limiter.GetLimiterState()
+	assert.True(t, state.Engaged)
+	assert.Equal(t, uint64(3), state.PacketsDropped)
+	assert.Equal(t, uint64(300), state.BytesDropped)
+	require.Len(t, *events, 1, "持续丢弃只发出一次生效事件")
+	assert.Equal(t, LimiterEventEngaged, (*events)[0].Type)
+	assert.Equal(t, LimiterReasonRateExceeded, (*events)[0].Reason)
+
+	// 补充令牌后恢复放行，距上次丢弃超过解除延迟时发出解除事件
+	*now = now.Add(limiterDisengageDelay)
+	assert.Equal(t, 5, allowN(limiter, 5, 100))
+	assert.False(t, limiter.GetLimiterState().Engaged)
+	require.Len(t, *events, 2)
+	assert.Equal(t, LimiterEventDisengaged, (*events)[1].Type)
+}
+
+func TestRateLimiter_ByteLimit(t *testing.T) {
+	config := testLimiterConfig()
+	config.MaxBytesPerSecond = 1000
+	limiter, _, _ := newLimiterTestLimiter(t, config)
+
+	assert.Equal(t, 2, allowN(limiter, 5, 400), "字节令牌不足时应丢弃数据包")
+	_, bytesDropped := limiter.GetStats()
+	assert.Equal(t, uint64(1200), bytesDropped)
+}
+
+func TestAdaptiveLimiter_SetLimiterParams(t *testing.T) {
+	limiter, _, _ := newLimiterTestLimiter(t, testLimiterConfig())
+	assert.Equal(t, 5, allowN(limiter, 20, 100))
+
+	params := testLimiterConfig()
+	params.BurstSize = 50
+	params.MaxPacketsPerSecond = 500
+	params.CPUThreshold = 90
+	require.NoError(t, limiter.SetLimiterParams(params))
+
+	// 新参数立即生效，无需等待令牌补充
+	assert.Equal(t, 50, allowN(limiter, 60, 100))
+
+	state := limiter.GetLimiterState()
+	assert.Equal(t, params, state.Params)
+	assert.Equal(t, int64(500), state.EffectivePacketsPerSecond)
+	assert.Equal(t, int64(50), state.EffectiveBurstSize)
+	assert.Equal(t, 1.0, state.AdjustmentFactor)
+
+	// 无效参数被拒绝且不影响当前参数
+	invalid := params
+	invalid.BurstSize = 0
+	assert.Error(t, limiter.SetLimiterParams(invalid))
+	invalid = params
+	invalid.MemoryThreshold = 150
+	assert.Error(t, limiter.SetLimiterParams(invalid))
+	assert.Equal(t, params, limiter.GetLimiterState().Params)
+}
+
+func TestAdaptiveLimiter_ResourcePressure(t *testing.T) {
+	config := testLimiterConfig()
+	config.MaxPacketsPerSecond = 1000
+	config.BurstSize = 100
+	config.CheckInterval = time.Nanosecond
+	limiter, _, events := newLimiterTestLimiter(t, config)
+
+	// CPU使用率为阈值的两倍，限制降为一半
+	time.Sleep(time.Millisecond)
+	limiter.CheckAndAdjust(160, 10)
+	state := limiter.GetLimiterState()
+	assert.True(t, state.Throttled)
+	assert.Equal(t, 0.5, state.AdjustmentFactor)
+	assert.Equal(t, int64(500), state.EffectivePacketsPerSecond)
+	assert.Equal(t, int64(50), state.EffectiveBurstSize)
+	require.Len(t, *events, 1)
+	assert.Equal(t, LimiterEventEngaged, (*events)[0].Type)
+	assert.Equal(t, LimiterReasonResourcePressure, (*events)[0].Reason)
+
+	// 调整期间更新参数时，调整因子继续作用于新参数
+	params := config
+	params.MaxPacketsPerSecond = 2000
+	require.NoError(t, limiter.SetLimiterParams(params))
+	assert.Equal(t, int64(1000), limiter.GetLimiterState().EffectivePacketsPerSecond)
+
+	// 资源恢复正常后恢复原始限制
+	time.Sleep(time.Millisecond)
+	limiter.CheckAndAdjust(10, 10)
+	state = limiter.GetLimiterState()
+	assert.False(t, state.Throttled)
+	assert.Equal(t, int64(2000), state.EffectivePacketsPerSecond)
+	require.Len(t, *events, 2)
+	assert.Equal(t, LimiterEventDisengaged, (*events)[1].Type)
+}
@@ -3,6 +3,7 @@
 package interceptor
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
@@ -28,6 +29,7 @@ const (
 	WINDIVERT_FLAG_SEND_ONLY        = 8
 	WINDIVERT_FLAG_NO_INSTALL       = 16
 	WINDIVERT_FLAG_FRAGMENTS        = 32
+	WINDIVERT_SHUTDOWN_RECV         = 1
 )
 
 // Windows错误代码常量
@@ -37,6 +39,9 @@ const (
 	ERROR_NO_MORE_ITEMS  = 259
 )
 
+// errWinDivertHandleInvalid WinDivert句柄已失效
+var errWinDivertHandleInvalid = errors.New("WinDivert句柄失效")
+
 // WinDivert 地址结构
 type WinDivertAddress struct {
 	Timestamp   int64
@@ -98,13 +103,18 @@ type WinDivertInterceptorImpl struct {
 	mu             sync.RWMutex
 
 	// 性能优化组件
-	rateLimiter *AdaptiveLimiter
+	rateLimiter    *AdaptiveLimiter
+	limiterHandler LimiterEventHandler
 
 	// 性能监控
 	performanceMonitor *PerformanceMonitor
 
+	// 按协议和目标端口的流量直方图
+	histogram *TrafficHistogram
+
 	// WinDivert 相关
-	handle        syscall.Handle
+	handover      *filterHandover // 当前句柄及过滤器热切换
+	openFlag      uintptr         // 打开句柄使用的标志，切换过滤器时沿用
 	windivertDLL  *syscall.LazyDLL
 	driverManager *WinDivertDriverManager
 	installer     *WinDivertInstaller
@@ -117,6 +127,7 @@ type WinDivertInterceptorImpl struct {
 	winDivertRecv              *syscall.LazyProc
 	winDivertSend              *syscall.LazyProc
 	winDivertClose             *syscall.LazyProc
+	winDivertShutdown          *syscall.LazyProc
 	winDivertHelperParsePacket *syscall.LazyProc
 }
 
@@ -125,7 +136,6 @@ func NewWinDivertInterceptor(logger logging.Logger) TrafficInterceptor {
 	interceptor := &WinDivertInterceptorImpl{
 		logger:         logger,
 		stopCh:         make(chan struct{}),
-		handle:         syscall.InvalidHandle,
 		processTracker: NewProcessTracker(logger),
 		driverManager:  NewWinDivertDriverManager(logger),
 		installer:      NewWinDivertInstaller(logger),
@@ -157,6 +167,7 @@ func (w *WinDivertInterceptorImpl) loadWinDivertDLL() {
 	w.winDivertRecv = w.windivertDLL.NewProc("WinDivertRecv")
 	w.winDivertSend = w.windivertDLL.NewProc("WinDivertSend")
 	w.winDivertClose = w.windivertDLL.NewProc("WinDivertClose")
+	w.winDivertShutdown = w.windivertDLL.NewProc("WinDivertShutdown")
 	w.winDivertHelperParsePacket = w.windivertDLL.NewProc("WinDivertHelperParsePacket")
 }
 
@@ -172,18 +183,14 @@ func (w *WinDivertInterceptorImpl) Initialize(config InterceptorConfig) error {
 	w.stats.StartTime = time.Now()
 
 	// 初始化自适应流量限制器
-	w.rateLimiter = NewAdaptiveLimiter(
-		1000,        // 每秒最大1000个数据包
-		10485760,    // 每秒最大10MB
-		100,         // 突发大小100
-		80.0,        // CPU阈值80%
-		80.0,        // 内存阈值80%
-		time.Minute, // 检查间隔1分钟
-		w.logger,
-	)
+	w.rateLimiter = NewAdaptiveLimiterFromConfig(config.RateLimiter, w.logger)
+	if w.limiterHandler != nil {
+		w.rateLimiter.SetEventHandler(w.limiterHandler)
+	}
 
 	// 初始化性能监控器
 	w.performanceMonitor = NewPerformanceMonitor(w.logger)
+	w.histogram = NewTrafficHistogram(config.StatsTopN)
 
 	w.logger.Info("初始化WinDivert拦截器",
 		"filter", config.Filter,
@@ -281,6 +288,7 @@ func (w *WinDivertInterceptorImpl) Start() error {
 		w.winDivertRecv = w.windivertDLL.NewProc("WinDivertRecv")
 		w.winDivertSend = w.windivertDLL.NewProc("WinDivertSend")
 		w.winDivertClose = w.windivertDLL.NewProc("WinDivertClose")
+		w.winDivertShutdown = w.windivertDLL.NewProc("WinDivertShutdown")
 		w.winDivertHelperParsePacket = w.windivertDLL.NewProc("WinDivertHelperParsePacket")
 	}
 
@@ -306,7 +314,7 @@ func (w *WinDivertInterceptorImpl) Start() error {
 		return err
 	}
 
-	w.handle = syscall.Handle(handle)
+	w.handover = newFilterHandover(w.openFilterHandle, w.startReceivers, w.logger)
 
 	// 启动增强进程管理器（优先）或传统进程跟踪器（备选）
 	if w.enhancedProcessManager != nil {
@@ -330,9 +338,7 @@ func (w *WinDivertInterceptorImpl) Start() error {
 	}
 
 	// 启动数据包接收协程
-	for i := 0; i < w.config.WorkerCount; i++ {
-		go w.packetReceiver(i)
-	}
+	w.handover.Activate(filter, &winDivertHandle{w: w, handle: syscall.Handle(handle)})
 
 	// 启动重新注入协程（如果启用自动重新注入）
 	if w.config.AutoReinject {
@@ -340,7 +346,7 @@ func (w *WinDivertInterceptorImpl) Start() error {
 	}
 
 	w.logger.Info("WinDivert流量拦截已启动",
-		"handle", w.handle,
+		"handle", w.currentHandle(),
 		"mode", w.config.Mode,
 		"auto_reinject", w.config.AutoReinject)
 	return nil
@@ -366,10 +372,11 @@ func (w *WinDivertInterceptorImpl) Stop() error {
 		w.processTracker.StopPeriodicUpdate()
 	}
 
-	// 关闭WinDivert句柄
-	if w.handle != syscall.InvalidHandle {
-		w.winDivertClose.Call(uintptr(w.handle))
-		w.handle = syscall.InvalidHandle
+	// 关闭WinDivert句柄并等待接收协程退出
+	if w.handover != nil {
+		if err := w.handover.Stop(); err != nil {
+			w.logger.Warn("关闭WinDivert句柄失败", "error", err)
+		}
 	}
 
 	// 关闭数据包通道
@@ -382,18 +389,20 @@ func (w *WinDivertInterceptorImpl) Stop() error {
 }
 
 // SetFilter 设置过滤规则
+// 运行中时用新过滤器打开句柄并开始接收，新句柄收到数据包后再排空并关闭旧句柄，
+// 切换期间不中断抓包；新过滤器无效时返回错误并保留原句柄
 func (w *WinDivertInterceptorImpl) SetFilter(filter string) error {
-	w.mu.Lock()
-	defer w.mu.Unlock()
+	if atomic.LoadInt32(&w.running) == 1 && w.handover != nil {
+		if err := w.handover.Swap(filter); err != nil {
+			return fmt.Errorf("切换WinDivert过滤规则失败: %w", err)
+		}
+	}
 
+	w.mu.Lock()
 	w.config.Filter = filter
-	w.logger.Info("设置WinDivert过滤规则", "filter", filter)
-
-	// 如果正在运行，需要重启以应用新过滤器
-	if atomic.LoadInt32(&w.running) == 1 {
-		w.logger.Warn("过滤器更改需要重启拦截器")
-	}
+	w.mu.Unlock()
 
+	w.logger.Info("设置WinDivert过滤规则", "filter", filter)
 	return nil
 }
 
@@ -404,7 +413,8 @@ func (w *WinDivertInterceptorImpl) GetPacketChannel() <-chan *PacketInfo {
 
 // Reinject 重新注入数据包
 func (w *WinDivertInterceptorImpl) Reinject(packet *PacketInfo) error {
-	if w.handle == syscall.InvalidHandle {
+	handle := w.currentHandle()
+	if handle == syscall.InvalidHandle {
 		return fmt.Errorf("WinDivert句柄无效")
 	}
 
@@ -441,7 +451,7 @@ func (w *WinDivertInterceptorImpl) Reinject(packet *PacketInfo) error {
 
 	var written uint32
 	ret, _, errno := w.winDivertSend.Call(
-		uintptr(w.handle),
+		uintptr(handle),
 		uintptr(unsafe.Pointer(&packet.Payload[0])),
 		uintptr(len(packet.Payload)),
 		uintptr(unsafe.Pointer(&written)),
@@ -464,24 +474,62 @@ func (w *WinDivertInterceptorImpl) GetStats() InterceptorStats {
 
 	stats := w.stats
 	stats.Uptime = time.Since(w.stats.StartTime)
+	w.histogram.Fill(&stats)
 	return stats
 }
 
+// GetLimiterState 获取流量限制器的当前参数和运行状态
+func (w *WinDivertInterceptorImpl) GetLimiterState() (LimiterState, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.rateLimiter == nil {
+		return LimiterState{}, fmt.Errorf("流量限制器未初始化")
+	}
+	return w.rateLimiter.GetLimiterState(), nil
+}
+
+// SetLimiterParams 更新流量限制参数，无需重启拦截器
+func (w *WinDivertInterceptorImpl) SetLimiterParams(params RateLimiterConfig) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.rateLimiter == nil {
+		return fmt.Errorf("流量限制器未初始化")
+	}
+	if err := w.rateLimiter.SetLimiterParams(params); err != nil {
+		return err
+	}
+	w.config.RateLimiter = params
+	return nil
+}
+
+// SetLimiterEventHandler 设置流量限制开始和解除时的事件处理函数
+func (w *WinDivertInterceptorImpl) SetLimiterEventHandler(handler LimiterEventHandler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.limiterHandler = handler
+	if w.rateLimiter != nil {
+		w.rateLimiter.SetEventHandler(handler)
+	}
+}
+
 // HealthCheck 健康检查
 func (w *WinDivertInterceptorImpl) HealthCheck() error {
 	if atomic.LoadInt32(&w.running) == 0 {
 		return fmt.Errorf("拦截器未运行")
 	}
 
-	if w.handle == syscall.InvalidHandle {
+	if w.currentHandle() == syscall.InvalidHandle {
 		return fmt.Errorf("WinDivert句柄无效")
 	}
 
 	return nil
 }
 
-// packetReceiver 数据包接收协程（性能优化版本）
-func (w *WinDivertInterceptorImpl) packetReceiver(workerID int) {
+// packetReceiver 数据包接收协程（性能优化版本），从会话的句柄接收数据包
+func (w *WinDivertInterceptorImpl) packetReceiver(session *captureSession, workerID int) {
 	w.logger.Debug("启动数据包接收协程", "worker_id", workerID)
 	defer w.logger.Debug("数据包接收协程退出", "worker_id", workerID)
 
@@ -509,8 +557,21 @@ func (w *WinDivertInterceptorImpl) packetReceiver(workerID int) {
 			return
 		default:
 			// 接收数据包
-			packet, err := w.receivePacket(buffer)
+			packet, err := session.handle.Recv(buffer)
 			if err != nil {
+				// 过滤器已切换，旧句柄排空后退出
+				if session.Retiring() {
+					if len(packets) > 0 {
+						w.processBatch(packets, workerID)
+					}
+					return
+				}
+
+				if errors.Is(err, errWinDivertHandleInvalid) {
+					w.logger.Error("WinDivert句柄已失效，需要重新初始化")
+					atomic.StoreInt32(&w.running, 0) // 标记为停止状态
+				}
+
 				if atomic.LoadInt32(&w.running) == 1 {
 					errorCount++
 
@@ -546,6 +607,13 @@ func (w *WinDivertInterceptorImpl) packetReceiver(workerID int) {
 			adaptiveDelay = time.Microsecond * 100
 
 			if packet != nil {
+				session.MarkReceiving()
+
+				// 切换过滤器期间新旧句柄都收到的数据包只处理一次
+				if w.handover.Duplicate(session, packet) {
+					continue
+				}
+
 				// 应用流量限制
 				if w.rateLimiter != nil && !w.rateLimiter.AllowPacket(int64(packet.Size)) {
 					// 数据包被流量限制器丢弃
@@ -555,6 +623,7 @@ func (w *WinDivertInterceptorImpl) packetReceiver(workerID int) {
 
 				atomic.AddUint64(&w.stats.PacketsProcessed, 1)
 				atomic.AddUint64(&w.stats.BytesProcessed, uint64(packet.Size))
+				w.histogram.Record(packet)
 				processedCount++
 
 				// 添加到批处理队列
@@ -689,10 +758,10 @@ func (w *WinDivertInterceptorImpl) reinjectWorker() {
 	}
 }
 
-// receivePacket 接收单个数据包
-func (w *WinDivertInterceptorImpl) receivePacket(buffer []byte) (*PacketInfo, error) {
+// receivePacket 从指定句柄接收单个数据包
+func (w *WinDivertInterceptorImpl) receivePacket(handle syscall.Handle, buffer []byte) (*PacketInfo, error) {
 	// 检查句柄是否有效
-	if w.handle == syscall.InvalidHandle {
+	if handle == syscall.InvalidHandle {
 		return nil, fmt.Errorf("WinDivert句柄无效")
 	}
 
@@ -705,7 +774,7 @@ func (w *WinDivertInterceptorImpl) receivePacket(buffer []byte) (*PacketInfo, er
 	addr := &WinDivertAddress{}
 
 	ret, _, errno := w.winDivertRecv.Call(
-		uintptr(w.handle),
+		uintptr(handle),
 		uintptr(unsafe.Pointer(&buffer[0])),
 		uintptr(len(buffer)),
 		uintptr(unsafe.Pointer(&received)),
@@ -717,19 +786,17 @@ func (w *WinDivertInterceptorImpl) receivePacket(buffer []byte) (*PacketInfo, er
 		if sysErr, ok := errno.(syscall.Errno); ok {
 			switch sysErr {
 			case ERROR_INVALID_HANDLE:
-				w.logger.Error("WinDivert句柄已失效，需要重新初始化")
-				atomic.StoreInt32(&w.running, 0) // 标记为停止状态
-				return nil, fmt.Errorf("WinDivert句柄失效")
+				return nil, errWinDivertHandleInvalid
 			case ERROR_ACCESS_DENIED:
 				w.logger.Error("WinDivert访问被拒绝，请检查管理员权限")
 				return nil, fmt.Errorf("访问被拒绝，需要管理员权限")
 			default:
 				// 对于其他错误，记录详细信息
-				w.logger.Debug("WinDivert接收数据包失败", "errno", sysErr, "handle", w.handle)
+				w.logger.Debug("WinDivert接收数据包失败", "errno", sysErr, "handle", handle)
 				return nil, fmt.Errorf("接收数据包失败: %v (错误代码: %d)", sysErr, sysErr)
 			}
 		} else {
-			w.logger.Debug("WinDivert接收数据包失败", "errno", errno, "handle", w.handle)
+			w.logger.Debug("WinDivert接收数据包失败", "errno", errno, "handle", handle)
 			return nil, fmt.Errorf("接收数据包失败: %v", errno)
 		}
 	}
@@ -1020,6 +1087,7 @@ func (w *WinDivertInterceptorImpl) getProcessInfo(packet *PacketInfo) *ProcessIn
 	}
 
 	// 策略2：如果四元组匹配失败，使用本地连接匹配
+	// 未命中时进程跟踪器会刷新连接表后重试，并发未命中合并为一次刷新，不再额外强制刷新
 	if pid == 0 {
 		pid = w.processTracker.GetProcessByConnection(packet.Protocol, localIP, localPort)
 		if pid != 0 {
@@ -1027,23 +1095,6 @@ func (w *WinDivertInterceptorImpl) getProcessInfo(packet *PacketInfo) *ProcessIn
 		}
 	}
 
-	// 策略3：如果仍然失败，尝试强制更新连接表后再次查找
-	if pid == 0 {
-		w.logger.Debug("首次查找失败，强制更新连接表后重试",
-			"protocol", packet.Protocol,
-			"local", fmt.Sprintf("%s:%d", localIP, localPort))
-
-		if err := w.processTracker.UpdateConnectionTables(); err != nil {
-			w.logger.Error("强制更新连接表失败", "error", err)
-		} else {
-			// 重新尝试查找
-			pid = w.processTracker.GetProcessByConnection(packet.Protocol, localIP, localPort)
-			if pid != 0 {
-				matchStrategy = "更新后匹配"
-			}
-		}
-	}
-
 	if pid == 0 {
 		w.logger.Debug("传统进程跟踪器所有策略都未找到对应的进程",
 			"direction", packet.Direction,
@@ -1194,6 +1245,7 @@ func (w *WinDivertInterceptorImpl) openWinDivertHandleWithRetry(originalFilter s
 			)
 
 			if ret != uintptr(syscall.InvalidHandle) {
+				w.openFlag = config.flag
 				w.logger.Info("WinDivert句柄打开成功",
 					"filter", config.filter,
 					"flag", config.flag,
@@ -1244,6 +1296,77 @@ func (w *WinDivertInterceptorImpl) openWinDivertHandleWithRetry(originalFilter s
 	return 0, fmt.Errorf("打开WinDivert句柄失败: %s，已重试%d次", errorMsg, maxRetries)
 }
 
+// openFilterHandle 使用指定过滤器和当前句柄的标志打开WinDivert句柄，用于切换过滤器
+func (w *WinDivertInterceptorImpl) openFilterHandle(filter string) (divertHandle, error) {
+	filterPtr, err := syscall.BytePtrFromString(filter)
+	if err != nil {
+		return nil, fmt.Errorf("转换过滤器字符串失败: %w", err)
+	}
+
+	ret, _, errno := w.winDivertOpen.Call(
+		uintptr(unsafe.Pointer(filterPtr)),
+		uintptr(WINDIVERT_LAYER_NETWORK),
+		uintptr(0), // priority (INT16)
+		w.openFlag, // flags (UINT64)
+	)
+	if ret == uintptr(syscall.InvalidHandle) {
+		return nil, fmt.Errorf("打开WinDivert句柄失败: %v", errno)
+	}
+
+	w.logger.Info("WinDivert句柄打开成功", "filter", filter, "flag", w.openFlag, "handle", ret)
+	return &winDivertHandle{w: w, handle: syscall.Handle(ret)}, nil
+}
+
+// startReceivers 为抓包会话启动数据包接收协程
+func (w *WinDivertInterceptorImpl) startReceivers(session *captureSession) {
+	for i := 0; i < w.config.WorkerCount; i++ {
+		workerID := i
+		session.Go(func() { w.packetReceiver(session, workerID) })
+	}
+}
+
+// currentHandle 返回当前抓包会话的WinDivert句柄
+func (w *WinDivertInterceptorImpl) currentHandle() syscall.Handle {
+	if w.handover == nil {
+		return syscall.InvalidHandle
+	}
+	session := w.handover.Current()
+	if session == nil {
+		return syscall.InvalidHandle
+	}
+	return session.handle.(*winDivertHandle).handle
+}
+
+// winDivertHandle WinDivert句柄
+type winDivertHandle struct {
+	w      *WinDivertInterceptorImpl
+	handle syscall.Handle
+}
+
+// Recv 接收一个数据包
+func (h *winDivertHandle) Recv(buffer []byte) (*PacketInfo, error) {
+	return h.w.receivePacket(h.handle, buffer)
+}
+
+// Shutdown 停止向句柄排队新的数据包，已排队的数据包接收完后 Recv 返回错误
+func (h *winDivertHandle) Shutdown() error {
+	if err := h.w.winDivertShutdown.Find(); err != nil {
+		return err
+	}
+	if ret, _, errno := h.w.winDivertShutdown.Call(uintptr(h.handle), WINDIVERT_SHUTDOWN_RECV); ret == 0 {
+		return fmt.Errorf("停止WinDivert句柄接收失败: %v", errno)
+	}
+	return nil
+}
+
+// Close 关闭句柄
+func (h *winDivertHandle) Close() error {
+	if ret, _, errno := h.w.winDivertClose.Call(uintptr(h.handle)); ret == 0 {
+		return fmt.Errorf("关闭WinDivert句柄失败: %v", errno)
+	}
+	return nil
+}
+
 // checkWinDivertDriver 检查WinDivert驱动状态
 func (w *WinDivertInterceptorImpl) checkWinDivertDriver() error {
 	w.logger.Debug("检查WinDivert驱动状态")
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// histogramInterceptor 返回带流量直方图统计的测试拦截器
+type histogramInterceptor struct {
+	interceptor.TrafficInterceptor
+	histogram *interceptor.TrafficHistogram
+}
+
+func (h *histogramInterceptor) GetStats() interceptor.InterceptorStats {
+	stats := interceptor.InterceptorStats{PacketsProcessed: 3, BytesProcessed: 2100}
+	h.histogram.Fill(&stats)
+	return stats
+}
+
+func TestDLPMetrics_InterceptorHistogram(t *testing.T) {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	module := NewDLPModule(logger)
+	module.interceptorManager = interceptor.NewInterceptorManager(logger)
+
+	traffic := &histogramInterceptor{histogram: interceptor.NewTrafficHistogram(1)}
+	traffic.histogram.Record(&interceptor.PacketInfo{Protocol: interceptor.ProtocolTCP, DestPort: 443, Size: 1500})
+	traffic.histogram.Record(&interceptor.PacketInfo{Protocol: interceptor.ProtocolTCP, DestPort: 80, Size: 500})
+	traffic.histogram.Record(&interceptor.PacketInfo{Protocol: interceptor.ProtocolUDP, DestPort: 53, Size: 100})
+	require.NoError(t, module.interceptorManager.RegisterInterceptor("traffic", traffic))
+
+	interceptors, ok := module.dlpMetrics()["interceptors"].(map[string]interface{})
+	require.True(t, ok)
+	trafficMetrics, ok := interceptors["traffic"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, uint64(3), trafficMetrics["packets_processed"])
+
+	ports, ok := trafficMetrics["port_histogram"].([]interceptor.HistogramBucket)
+	require.True(t, ok)
+	assert.Equal(t, []interceptor.HistogramBucket{
+		{Key: "443", Packets: 1, Bytes: 1500},
+		{Key: interceptor.HistogramOtherBucket, Packets: 2, Bytes: 600},
+	}, ports)
+
+	protocols, ok := trafficMetrics["protocol_histogram"].([]interceptor.HistogramBucket)
+	require.True(t, ok)
+	assert.Equal(t, []interceptor.HistogramBucket{
+		{Key: "tcp", Packets: 2, Bytes: 2000},
+		{Key: interceptor.HistogramOtherBucket, Packets: 1, Bytes: 100},
+	}, protocols)
+}
@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/lomehong/kennel/pkg/core/config"
 	"github.com/lomehong/kennel/pkg/core/plugin"
 	"github.com/lomehong/kennel/pkg/logging"
 	"gopkg.in/yaml.v2"
@@ -145,30 +146,13 @@ func main() {
 		dlpConfig = make(map[string]interface{})
 	}
 
-	// 合并DLP配置和默认配置
-	defaultDLPConfig := map[string]interface{}{
-		"log_level":         "info",
-		"monitor_clipboard": true,
-		"monitor_files":     true,
-		"monitor_network":   true,
-		"monitored_directories": []string{
-			"data/dlp/monitored",
-		},
-		"monitored_file_types": []string{
-			"*.txt", "*.doc", "*.docx", "*.xls", "*.xlsx", "*.pdf",
-		},
-		"network_protocols": []string{
-			"http", "https", "ftp", "smtp",
-		},
-	}
-
-	// 创建配置，使用DLP配置段
-	config := &plugin.ModuleConfig{
-		Settings: mergeConfigs(dlpConfig, defaultDLPConfig),
+	// 创建配置，使用DLP配置段，未配置的键使用登记的默认值
+	moduleConfig := &plugin.ModuleConfig{
+		Settings: mergeConfigs(dlpConfig, config.RegisteredDefaults().Defaults("plugins.dlp")),
 	}
 
 	// 初始化模块
-	if err := module.Init(context.Background(), config); err != nil {
+	if err := module.Init(context.Background(), moduleConfig); err != nil {
 		fmt.Fprintf(os.Stderr, "初始化模块失败: %v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// parseMaskingSettings 解析敏感数据脱敏配置，rules 中配置的类型覆盖该类型的默认规则
+func parseMaskingSettings(settings map[string]interface{}, config *analyzer.MaskingConfig) {
+	config.Enabled = sdk.GetConfigBool(settings, "enabled", config.Enabled)
+	config.MaskChar = sdk.GetConfigString(settings, "mask_char", config.MaskChar)
+	config.Default = parseMaskingRule(sdk.GetConfigMap(settings, "default"), config.Default)
+
+	rules := sdk.GetConfigMap(settings, "rules")
+	if len(rules) == 0 {
+		return
+	}
+	merged := make(map[string]analyzer.MaskingRule, len(config.Rules)+len(rules))
+	for dataType, rule := range config.Rules {
+		merged[dataType] = rule
+	}
+	for dataType := range rules {
+		merged[dataType] = parseMaskingRule(sdk.GetConfigMap(rules, dataType), analyzer.MaskingRule{})
+	}
+	config.Rules = merged
+}
+
+// parseMaskingRule 解析单个类型的脱敏规则
+func parseMaskingRule(settings map[string]interface{}, rule analyzer.MaskingRule) analyzer.MaskingRule {
+	rule.KeepPrefix = sdk.GetConfigInt(settings, "keep_prefix", rule.KeepPrefix)
+	rule.KeepSuffix = sdk.GetConfigInt(settings, "keep_suffix", rule.KeepSuffix)
+	rule.Reveal = sdk.GetConfigBool(settings, "reveal", rule.Reveal)
+	return rule
+}
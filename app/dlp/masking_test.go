@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/analyzer"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaskingSettings(t *testing.T) {
+	config := analyzer.DefaultMaskingConfig()
+	parseMaskingSettings(map[string]interface{}{
+		"mask_char": "#",
+		"default":   map[string]interface{}{"keep_suffix": 2},
+		"rules": map[string]interface{}{
+			"credit_card": map[string]interface{}{"keep_prefix": 6, "keep_suffix": 4},
+			"employee_id": map[string]interface{}{"reveal": true},
+			"phone":       map[string]interface{}{},
+		},
+	}, &config)
+
+	assert.True(t, config.Enabled)
+	assert.Equal(t, "#", config.MaskChar)
+	assert.Equal(t, analyzer.MaskingRule{KeepSuffix: 2}, config.Default)
+	assert.Equal(t, analyzer.MaskingRule{KeepPrefix: 6, KeepSuffix: 4}, config.Rules["credit_card"])
+	assert.Equal(t, analyzer.MaskingRule{Reveal: true}, config.Rules["employee_id"])
+	assert.Equal(t, analyzer.MaskingRule{}, config.Rules["phone"], "配置的类型覆盖默认规则")
+	assert.Equal(t, analyzer.DefaultMaskingConfig().Rules["id_card"], config.Rules["id_card"], "未配置的类型保留默认规则")
+
+	// 未配置脱敏时保留默认值
+	config = analyzer.DefaultMaskingConfig()
+	parseMaskingSettings(map[string]interface{}{}, &config)
+	assert.Equal(t, analyzer.DefaultMaskingConfig(), config)
+}
+
+func TestScanner_MasksAlertContent(t *testing.T) {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	rules := NewRuleManager(logger)
+	require.NoError(t, rules.AddRule(&DLPRule{ID: "credit_card", Name: "信用卡号检测", Pattern: `\b(?:\d{4}[-\s]?){3}\d{4}\b`, Action: "block", Enabled: true}))
+	require.NoError(t, rules.AddRule(&DLPRule{ID: "phone", Name: "手机号检测", Pattern: `1[3-9]\d{9}`, Action: "audit", Enabled: true}))
+
+	content := "卡号 4111111111111111 手机 13812345678"
+	alerts := NewScanner(logger, rules, NewAlertManager(), map[string]interface{}{}).ScanContent(content, "clipboard", "clipboard")
+	previews := make(map[string]string)
+	for _, alert := range alerts {
+		previews[alert.RuleID] = alert.Content
+	}
+	assert.Equal(t, map[string]string{"credit_card": "****1111", "phone": "138****5678"}, previews)
+
+	// 关闭脱敏后警报中为原始值
+	alerts = NewScanner(logger, rules, NewAlertManager(), map[string]interface{}{
+		"masking": map[string]interface{}{"enabled": false},
+	}).ScanContent(content, "clipboard", "clipboard")
+	previews = make(map[string]string)
+	for _, alert := range alerts {
+		previews[alert.RuleID] = alert.Content
+	}
+	assert.Equal(t, map[string]string{"credit_card": "4111111111111111", "phone": "13812345678"}, previews)
+}
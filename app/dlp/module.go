@@ -80,6 +80,16 @@ type DLPModule struct {
 	analysisManager    analyzer.AnalysisManager
 	policyEngine       engine.PolicyEngine
 	executionManager   executor.ExecutionManager
+	pcapWriter         *interceptor.PcapWriter
+	trafficQuota       *interceptor.TrafficQuota
+	geoResolver        *interceptor.GeoResolver
+	flowTracker        *interceptor.FlowTracker
+	findingStats       *findingStats
+	appProfiles        *applicationProfiles
+	processingMetrics  *processingMetrics
+	overflowMetrics    *overflowMetrics
+	limiterEvents      *limiterEventLog
+	pauseState         *pauseState
 
 	// 配置和状态
 	dlpConfig    *DLPConfig
@@ -104,6 +114,11 @@ type DLPConfig struct {
 	ExecutorConfig            executor.ExecutorConfig       `yaml:"executor_config" json:"executor_config"`
 	MaxConcurrency            int                           `yaml:"max_concurrency" json:"max_concurrency"`
 	BufferSize                int                           `yaml:"buffer_size" json:"buffer_size"`
+	OverflowPolicy            OverflowPolicy                `yaml:"overflow_policy" json:"overflow_policy"`
+	OverflowTimeout           time.Duration                 `yaml:"overflow_timeout" json:"overflow_timeout"`
+	FindingStats              FindingStatsConfig            `yaml:"finding_stats" json:"finding_stats"`
+	ApplicationProfiles       ApplicationProfilesConfig     `yaml:"application_profiles" json:"application_profiles"`
+	Masking                   analyzer.MaskingConfig        `yaml:"masking" json:"masking"`
 
 	// OCR和ML相关配置
 	OCRConfig            map[string]interface{} `yaml:"ocr_config" json:"ocr_config"`
@@ -146,6 +161,11 @@ func NewDLPModule(logger logging.Logger) *DLPModule {
 		monitorCancel: cancel,
 		processingCh:  make(chan *ProcessingTask, 200), // 减少处理通道大小
 		stopCh:        make(chan struct{}),
+
+		processingMetrics: newProcessingMetrics(),
+		overflowMetrics:   newOverflowMetrics(),
+		limiterEvents:     newLimiterEventLog(),
+		pauseState:        newPauseState(),
 	}
 
 	// 设置日志记录器
@@ -153,6 +173,9 @@ func NewDLPModule(logger logging.Logger) *DLPModule {
 		module.Logger = logger
 	}
 
+	// 注册DLP特定指标
+	base.SetMetricsProvider(module.dlpMetrics)
+
 	return module
 }
 
@@ -212,6 +235,23 @@ func (m *DLPModule) parseDLPConfig(config *plugin.ModuleConfig) error {
 		NetworkProtocols:          getStringSlice("network_protocols", []string{"http", "https", "ftp", "smtp"}),
 		MaxConcurrency:            sdk.GetConfigInt(config.Settings, "max_concurrency", 4), // 减少并发数
 		BufferSize:                sdk.GetConfigInt(config.Settings, "buffer_size", 500),   // 减少缓冲区大小
+		OverflowTimeout:           time.Duration(sdk.GetConfigInt(config.Settings, "overflow_timeout", int(DefaultOverflowTimeout/time.Millisecond))) * time.Millisecond,
+	}
+
+	overflowPolicy, err := ParseOverflowPolicy(sdk.GetConfigString(config.Settings, "overflow_policy", string(OverflowDropNewest)))
+	if err != nil {
+		return err
+	}
+	m.dlpConfig.OverflowPolicy = overflowPolicy
+
+	m.dlpConfig.FindingStats = DefaultFindingStatsConfig()
+	parseFindingStatsSettings(sdk.GetConfigMap(config.Settings, "finding_stats"), &m.dlpConfig.FindingStats)
+
+	m.dlpConfig.Masking = analyzer.DefaultMaskingConfig()
+	parseMaskingSettings(sdk.GetConfigMap(config.Settings, "masking"), &m.dlpConfig.Masking)
+
+	if err := parseApplicationProfileSettings(sdk.GetConfigMap(config.Settings, "application_profiles"), &m.dlpConfig.ApplicationProfiles); err != nil {
+		return fmt.Errorf("解析应用策略配置失败: %w", err)
 	}
 
 	// 创建增强日志记录器用于子组件
@@ -225,18 +265,101 @@ func (m *DLPModule) parseDLPConfig(config *plugin.ModuleConfig) error {
 	// 设置子组件配置
 	m.dlpConfig.InterceptorConfig = interceptor.DefaultInterceptorConfig()
 	m.dlpConfig.InterceptorConfig.Logger = enhancedLogger.Named("interceptor")
+	if interceptorSettings, ok := config.Settings["interceptor_config"].(map[string]interface{}); ok {
+		pcapSettings := sdk.GetConfigMap(interceptorSettings, "pcap")
+		pcap := &m.dlpConfig.InterceptorConfig.Pcap
+		pcap.Enabled = sdk.GetConfigBool(pcapSettings, "enabled", pcap.Enabled)
+		pcap.Dir = sdk.GetConfigString(pcapSettings, "dir", pcap.Dir)
+		pcap.MaxFileSize = int64(sdk.GetConfigInt(pcapSettings, "max_file_size", int(pcap.MaxFileSize)))
+		pcap.MaxFiles = sdk.GetConfigInt(pcapSettings, "max_files", pcap.MaxFiles)
+		pcap.FlowBufferSize = sdk.GetConfigInt(pcapSettings, "flow_buffer_size", pcap.FlowBufferSize)
+		pcap.MaxFlows = sdk.GetConfigInt(pcapSettings, "max_flows", pcap.MaxFlows)
+		pcap.Redact = sdk.GetConfigBool(pcapSettings, "redact", pcap.Redact)
+
+		quotaSettings := sdk.GetConfigMap(interceptorSettings, "quota")
+		quota := &m.dlpConfig.InterceptorConfig.Quota
+		quota.Enabled = sdk.GetConfigBool(quotaSettings, "enabled", quota.Enabled)
+		quota.Window = time.Duration(sdk.GetConfigInt(quotaSettings, "window", int(quota.Window/time.Second))) * time.Second
+		quota.DefaultLimit = int64(sdk.GetConfigInt(quotaSettings, "default_limit", int(quota.DefaultLimit)))
+		processLimits := sdk.GetConfigMap(quotaSettings, "process_limits")
+		for name := range processLimits {
+			quota.ProcessLimits[name] = int64(sdk.GetConfigInt(processLimits, name, 0))
+		}
+
+		geoSettings := sdk.GetConfigMap(interceptorSettings, "geo")
+		geo := &m.dlpConfig.InterceptorConfig.Geo
+		geo.Enabled = sdk.GetConfigBool(geoSettings, "enabled", geo.Enabled)
+		geoNetworks := sdk.GetConfigMap(geoSettings, "networks")
+		for country := range geoNetworks {
+			geo.Networks[country] = sdk.GetConfigStringSlice(geoNetworks, country)
+		}
+
+		parseFlowTrackerSettings(sdk.GetConfigMap(interceptorSettings, "flow"), &m.dlpConfig.InterceptorConfig.Flow)
+		parseRateLimiterSettings(sdk.GetConfigMap(interceptorSettings, "rate_limiter"), &m.dlpConfig.InterceptorConfig.RateLimiter)
+		m.dlpConfig.InterceptorConfig.StatsTopN = sdk.GetConfigInt(interceptorSettings, "stats_top_n", m.dlpConfig.InterceptorConfig.StatsTopN)
+	}
 
 	m.dlpConfig.ParserConfig = parser.DefaultParserConfig()
 	m.dlpConfig.ParserConfig.Logger = enhancedLogger.Named("parser")
+	if parserSettings, ok := config.Settings["parser_config"].(map[string]interface{}); ok {
+		m.dlpConfig.ParserConfig.MaxAttachmentSize = int64(sdk.GetConfigInt(parserSettings, "max_attachment_size", int(m.dlpConfig.ParserConfig.MaxAttachmentSize)))
+		m.dlpConfig.ParserConfig.MaxDecompressedSize = int64(sdk.GetConfigInt(parserSettings, "max_decompressed_size", int(m.dlpConfig.ParserConfig.MaxDecompressedSize)))
+		m.dlpConfig.ParserConfig.MaxSMBWriteSize = int64(sdk.GetConfigInt(parserSettings, "max_smb_write_size", int(m.dlpConfig.ParserConfig.MaxSMBWriteSize)))
+		if portSettings := sdk.GetConfigMap(parserSettings, "port_overrides"); len(portSettings) > 0 {
+			portOverrides, err := parser.ParsePortOverrides(portSettings)
+			if err != nil {
+				return fmt.Errorf("解析端口映射失败: %w", err)
+			}
+			m.dlpConfig.ParserConfig.PortOverrides = portOverrides
+		}
+		if parsersSettings := sdk.GetConfigMap(parserSettings, "parsers"); len(parsersSettings) > 0 {
+			parsers, err := parser.ParseParserSettings(parsersSettings)
+			if err != nil {
+				return fmt.Errorf("解析解析器配置失败: %w", err)
+			}
+			m.dlpConfig.ParserConfig.Parsers = parsers
+		}
+	}
 
 	m.dlpConfig.AnalyzerConfig = analyzer.DefaultAnalyzerConfig()
 	m.dlpConfig.AnalyzerConfig.Logger = enhancedLogger.Named("analyzer")
+	m.dlpConfig.AnalyzerConfig.Masking = m.dlpConfig.Masking
+	if analyzerSettings, ok := config.Settings["analyzer_config"].(map[string]interface{}); ok {
+		parseAnalyzerSettings(analyzerSettings, &m.dlpConfig.AnalyzerConfig)
+	}
 
 	m.dlpConfig.EngineConfig = engine.DefaultPolicyEngineConfig()
 	m.dlpConfig.EngineConfig.Logger = enhancedLogger.Named("engine")
+	if engineSettings, ok := config.Settings["engine_config"].(map[string]interface{}); ok {
+		engineConfig := &m.dlpConfig.EngineConfig
+		engineConfig.Backend = sdk.GetConfigString(engineSettings, "backend", engineConfig.Backend)
+		engineConfig.ExplainDecisions = sdk.GetConfigBool(engineSettings, "explain_decisions", engineConfig.ExplainDecisions)
+		geoFenceSettings := sdk.GetConfigMap(engineSettings, "geo_fence")
+		engineConfig.GeoFence.FailClosed = sdk.GetConfigBool(geoFenceSettings, "fail_closed", engineConfig.GeoFence.FailClosed)
+		samplingSettings := sdk.GetConfigMap(engineSettings, "sampling")
+		engineConfig.Sampling.LowRate = sdk.GetConfigInt(samplingSettings, "low_rate", engineConfig.Sampling.LowRate)
+		opaSettings := sdk.GetConfigMap(engineSettings, "opa")
+		opa := &engineConfig.OPA
+		opa.URL = sdk.GetConfigString(opaSettings, "url", opa.URL)
+		opa.PolicyPath = sdk.GetConfigString(opaSettings, "policy_path", opa.PolicyPath)
+		opa.PolicyID = sdk.GetConfigString(opaSettings, "policy_id", opa.PolicyID)
+		opa.PolicyFile = sdk.GetConfigString(opaSettings, "policy_file", opa.PolicyFile)
+		opa.Timeout = time.Duration(sdk.GetConfigInt(opaSettings, "timeout", int(opa.Timeout/time.Millisecond))) * time.Millisecond
+		if err := parseRuleTemplateSettings(engineSettings, engineConfig); err != nil {
+			return fmt.Errorf("解析规则模板失败: %w", err)
+		}
+	}
 
 	m.dlpConfig.ExecutorConfig = executor.DefaultExecutorConfig()
 	m.dlpConfig.ExecutorConfig.Logger = enhancedLogger.Named("executor")
+	m.dlpConfig.ExecutorConfig.Masking = m.dlpConfig.Masking
+	if executorSettings, ok := config.Settings["executor_config"].(map[string]interface{}); ok {
+		parseRemediationSettings(executorSettings, &m.dlpConfig.ExecutorConfig)
+		parseQuarantineSettings(executorSettings, &m.dlpConfig.ExecutorConfig)
+		parseEncryptionSettings(executorSettings, &m.dlpConfig.ExecutorConfig)
+		parseNotificationSettings(executorSettings, &m.dlpConfig.ExecutorConfig)
+		parseAuditWriterSettings(executorSettings, &m.dlpConfig.ExecutorConfig)
+	}
 
 	// 解析OCR和ML配置
 	if err := m.parseOCRAndMLConfig(config); err != nil {
@@ -361,6 +484,7 @@ func (m *DLPModule) parseOCRAndMLConfig(config *plugin.ModuleConfig) error {
 	// 从主配置文件中读取审计配置
 	if auditConfig, ok := config.Settings["audit"].(map[string]interface{}); ok {
 		m.dlpConfig.AuditConfig = auditConfig
+		m.dlpConfig.EngineConfig.AuditLogPath = sdk.GetConfigString(auditConfig, "log_path", m.dlpConfig.EngineConfig.AuditLogPath)
 		m.Logger.Info("已加载审计配置")
 	} else {
 		m.Logger.Info("未找到审计配置，使用默认设置")
@@ -394,6 +518,46 @@ func (m *DLPModule) initializeCoreComponents() error {
 	// 创建执行管理器
 	m.executionManager = executor.NewExecutionManager(m.dlpConfig.ExecutorConfig.Logger, m.dlpConfig.ExecutorConfig)
 
+	// 创建调试用pcap导出器
+	if m.dlpConfig.InterceptorConfig.Pcap.Enabled {
+		pcapWriter, err := interceptor.NewPcapWriter(m.dlpConfig.InterceptorConfig.Pcap, logger.Named("pcap"))
+		if err != nil {
+			m.Logger.Warn("创建pcap导出器失败", "error", err)
+		} else {
+			m.pcapWriter = pcapWriter
+		}
+	}
+
+	// 创建按进程的流量配额跟踪器
+	if m.dlpConfig.InterceptorConfig.Quota.Enabled {
+		m.trafficQuota = interceptor.NewTrafficQuota(m.dlpConfig.InterceptorConfig.Quota)
+	}
+
+	// 创建目的地址地理位置解析器，供地理围栏条件使用
+	if m.dlpConfig.InterceptorConfig.Geo.Enabled {
+		geoResolver, err := interceptor.NewGeoResolver(m.dlpConfig.InterceptorConfig.Geo)
+		if err != nil {
+			return fmt.Errorf("创建地理位置解析器失败: %w", err)
+		}
+		m.geoResolver = geoResolver
+	}
+
+	// 创建应用策略配置选择器，按发送数据的进程选择规则子集和风险阈值
+	if m.dlpConfig.ApplicationProfiles.configured() {
+		m.appProfiles = newApplicationProfiles(m.dlpConfig.ApplicationProfiles)
+	}
+
+	// 创建流跟踪器，将数据包归并为流并记录流的开始和结束
+	if m.dlpConfig.InterceptorConfig.Flow.Enabled {
+		m.flowTracker = interceptor.NewFlowTracker(m.dlpConfig.InterceptorConfig.Flow)
+		m.flowTracker.OnEvent(m.handleFlowEvent)
+	}
+
+	// 创建敏感数据统计，供仪表盘查询趋势
+	if m.dlpConfig.FindingStats.Enabled {
+		m.findingStats = newFindingStats(m.dlpConfig.FindingStats)
+	}
+
 	// 注册协议解析器
 	if err := m.registerProtocolParsers(); err != nil {
 		return fmt.Errorf("注册协议解析器失败: %w", err)
@@ -539,10 +703,16 @@ func (m *DLPModule) initializeLegacyComponents() error {
 
 // Start 启动模块
 func (m *DLPModule) Start() error {
+	// 基础模块检查生命周期状态，拒绝未初始化或重复启动
+	if err := m.BaseModule.Start(); err != nil {
+		return err
+	}
 	m.Logger.Info("启动数据防泄漏模块v2.0")
 
 	// 启动核心组件
 	if err := m.startCoreComponents(); err != nil {
+		// 回到停止状态，允许修复后重新启动
+		m.BaseModule.Stop()
 		return fmt.Errorf("启动核心组件失败: %w", err)
 	}
 
@@ -554,6 +724,7 @@ func (m *DLPModule) Start() error {
 
 	// 启动数据处理流水线
 	if err := m.startProcessingPipeline(); err != nil {
+		m.BaseModule.Stop()
 		return fmt.Errorf("启动处理流水线失败: %w", err)
 	}
 
@@ -607,6 +778,9 @@ func (m *DLPModule) startCoreComponents() error {
 			if err := trafficInterceptor.Initialize(m.dlpConfig.InterceptorConfig); err != nil {
 				m.Logger.Warn("初始化流量拦截器失败", "error", err)
 			} else {
+				if controller, ok := trafficInterceptor.(interceptor.RateLimitController); ok {
+					controller.SetLimiterEventHandler(m.handleLimiterEvent)
+				}
 				if err := m.interceptorManager.RegisterInterceptor("traffic", trafficInterceptor); err != nil {
 					m.Logger.Warn("注册流量拦截器失败，网络监控功能将被禁用", "error", err)
 				} else {
@@ -688,6 +862,10 @@ func (m *DLPModule) startProcessingPipeline() error {
 		if m.dlpConfig.EnableNetworkMonitoring {
 			go m.packetListener()
 		}
+
+		if m.flowTracker != nil {
+			go m.flowExpiryLoop(m.dlpConfig.InterceptorConfig.Flow.ClosingTimeout)
+		}
 	}
 
 	m.Logger.Info("数据处理流水线启动完成")
@@ -702,6 +880,11 @@ func (m *DLPModule) processingWorker(workerID int) {
 	for {
 		select {
 		case task := <-m.processingCh:
+			// 暂停前已入队的任务同样跳过
+			if m.IsPaused() {
+				m.pauseState.skip(DataTypeNetworkPacket)
+				continue
+			}
 			if err := m.processTask(task); err != nil {
 				m.Logger.Error("处理任务失败", "task_id", task.ID, "error", err)
 			}
@@ -735,6 +918,12 @@ func (m *DLPModule) packetListener() {
 	for {
 		select {
 		case packet := <-packetCh:
+			// 暂停期间继续取出数据包，避免拦截器通道阻塞，但不做检测
+			if m.IsPaused() {
+				m.pauseState.skip(DataTypeNetworkPacket)
+				continue
+			}
+
 			// 创建处理任务
 			task := &ProcessingTask{
 				ID:        fmt.Sprintf("task_%d", time.Now().UnixNano()),
@@ -743,14 +932,8 @@ func (m *DLPModule) packetListener() {
 				Context:   context.Background(),
 			}
 
-			// 发送到处理通道
-			select {
-			case m.processingCh <- task:
-			case <-m.stopCh:
-				return
-			default:
-				m.Logger.Warn("处理通道已满，丢弃任务", "task_id", task.ID)
-			}
+			// 按溢出策略发送到处理通道
+			m.enqueueTask(task)
 		case <-m.stopCh:
 			return
 		}
@@ -765,6 +948,13 @@ func (m *DLPModule) processTask(task *ProcessingTask) error {
 		return fmt.Errorf("核心组件未初始化")
 	}
 
+	if m.pcapWriter != nil {
+		m.pcapWriter.Record(task.Packet)
+	}
+	if m.flowTracker != nil {
+		m.flowTracker.Track(task.Packet)
+	}
+
 	// 1. 协议解析
 	parsedData, err := m.protocolManager.ParsePacket(task.Packet)
 	if err != nil {
@@ -774,30 +964,12 @@ func (m *DLPModule) processTask(task *ProcessingTask) error {
 		return fmt.Errorf("协议解析失败: %w", err)
 	}
 
-	// 2. 内容分析
-	analysisResult, err := m.analysisManager.AnalyzeContent(task.Context, parsedData)
-	if err != nil {
-		return fmt.Errorf("内容分析失败: %w", err)
-	}
-
-	// 3. 策略决策
-	decisionContext := &engine.DecisionContext{
-		PacketInfo:     task.Packet,
-		ParsedData:     parsedData,
-		AnalysisResult: analysisResult,
-		// 其他上下文信息可以在这里添加
-	}
-
-	decision, err := m.policyEngine.EvaluatePolicy(task.Context, decisionContext)
-	if err != nil {
-		return fmt.Errorf("策略评估失败: %w", err)
-	}
-
-	// 4. 动作执行
-	_, err = m.executionManager.ExecuteDecision(task.Context, decision)
+	// 2. 内容分析、策略决策和动作执行
+	result, err := m.runPipeline(task.Context, task.Packet, parsedData)
 	if err != nil {
-		return fmt.Errorf("动作执行失败: %w", err)
+		return err
 	}
+	decision := result.Decision
 
 	m.Logger.Debug("任务处理完成",
 		"task_id", task.ID,
@@ -809,6 +981,10 @@ func (m *DLPModule) processTask(task *ProcessingTask) error {
 
 // Stop 停止模块
 func (m *DLPModule) Stop() error {
+	// 基础模块检查生命周期状态，拒绝停止未启动的模块
+	if err := m.BaseModule.Stop(); err != nil {
+		return err
+	}
 	m.Logger.Info("停止数据防泄漏模块v2.0")
 
 	// 设置停止标志
@@ -816,6 +992,9 @@ func (m *DLPModule) Stop() error {
 	m.running = false
 	m.mu.Unlock()
 
+	// 停止后暂停状态不再有意义，下次启动时正常处理
+	m.Resume()
+
 	// 发送停止信号
 	close(m.stopCh)
 
@@ -844,6 +1023,13 @@ func (m *DLPModule) stopCoreComponents() error {
 		}
 	}
 
+	// 关闭pcap导出器
+	if m.pcapWriter != nil {
+		if err := m.pcapWriter.Close(); err != nil {
+			m.Logger.Error("关闭pcap导出器失败", "error", err)
+		}
+	}
+
 	// 停止执行管理器
 	if m.executionManager != nil {
 		if err := m.executionManager.Stop(); err != nil {
@@ -953,26 +1139,12 @@ func (m *DLPModule) HandleRequest(ctx context.Context, req *plugin.Request) (*pl
 
 		// 检查必要字段
 		if rule.ID == "" || rule.Pattern == "" {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "invalid_param",
-					Message: "规则ID和模式不能为空",
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, sdk.InvalidParamError("规则ID和模式不能为空")), nil
 		}
 
 		// 添加规则
 		if err := m.ruleManager.AddRule(rule); err != nil {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "add_error",
-					Message: err.Error(),
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, err), nil
 		}
 
 		return &plugin.Response{
@@ -996,26 +1168,12 @@ func (m *DLPModule) HandleRequest(ctx context.Context, req *plugin.Request) (*pl
 
 		// 检查必要字段
 		if rule.ID == "" || rule.Pattern == "" {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "invalid_param",
-					Message: "规则ID和模式不能为空",
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, sdk.InvalidParamError("规则ID和模式不能为空")), nil
 		}
 
 		// 更新规则
 		if err := m.ruleManager.UpdateRule(rule); err != nil {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "update_error",
-					Message: err.Error(),
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, err), nil
 		}
 
 		return &plugin.Response{
@@ -1030,26 +1188,12 @@ func (m *DLPModule) HandleRequest(ctx context.Context, req *plugin.Request) (*pl
 		// 删除规则
 		id := sdk.GetConfigString(req.Params, "id", "")
 		if id == "" {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "invalid_param",
-					Message: "规则ID不能为空",
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, sdk.InvalidParamError("规则ID不能为空")), nil
 		}
 
 		// 删除规则
 		if err := m.ruleManager.DeleteRule(id); err != nil {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "delete_error",
-					Message: err.Error(),
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, err), nil
 		}
 
 		return &plugin.Response{
@@ -1064,27 +1208,13 @@ func (m *DLPModule) HandleRequest(ctx context.Context, req *plugin.Request) (*pl
 		// 扫描文件
 		path := sdk.GetConfigString(req.Params, "path", "")
 		if path == "" {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "invalid_param",
-					Message: "文件路径不能为空",
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, sdk.InvalidParamError("文件路径不能为空")), nil
 		}
 
 		// 扫描文件
 		alerts, err := m.scanner.ScanFile(path)
 		if err != nil {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "scan_error",
-					Message: err.Error(),
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, err), nil
 		}
 
 		return &plugin.Response{
@@ -1100,27 +1230,13 @@ func (m *DLPModule) HandleRequest(ctx context.Context, req *plugin.Request) (*pl
 		// 扫描目录
 		dir := sdk.GetConfigString(req.Params, "directory", "")
 		if dir == "" {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "invalid_param",
-					Message: "目录路径不能为空",
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, sdk.InvalidParamError("目录路径不能为空")), nil
 		}
 
 		// 扫描目录
 		alerts, err := m.scanner.ScanDirectory(dir)
 		if err != nil {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "scan_error",
-					Message: err.Error(),
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, err), nil
 		}
 
 		return &plugin.Response{
@@ -1136,14 +1252,7 @@ func (m *DLPModule) HandleRequest(ctx context.Context, req *plugin.Request) (*pl
 		// 扫描剪贴板
 		alerts, err := m.scanner.ScanClipboard()
 		if err != nil {
-			return &plugin.Response{
-				ID:      req.ID,
-				Success: false,
-				Error: &plugin.ErrorInfo{
-					Code:    "scan_error",
-					Message: err.Error(),
-				},
-			}, nil
+			return sdk.ErrorResponse(req.ID, err), nil
 		}
 
 		return &plugin.Response{
@@ -1167,6 +1276,117 @@ func (m *DLPModule) HandleRequest(ctx context.Context, req *plugin.Request) (*pl
 			},
 		}, nil
 
+	case "get_finding_stats":
+		// 按类型、目的地址和进程查询敏感数据统计
+		stats, err := m.getFindingStats(req.Params)
+		if err != nil {
+			return sdk.ErrorResponse(req.ID, err), nil
+		}
+		return sdk.NewTypedResponse(req.ID, stats), nil
+
+	case "get_limiter_state":
+		// 获取网络流量限制器状态
+		data, err := m.getLimiterState()
+		if err != nil {
+			return sdk.ErrorResponse(req.ID, err), nil
+		}
+		return &plugin.Response{
+			ID:      req.ID,
+			Success: true,
+			Data:    data,
+		}, nil
+
+	case "set_limiter_params":
+		// 运行时调整网络流量限制参数
+		state, err := m.setLimiterParams(req.Params)
+		if err != nil {
+			return sdk.ErrorResponse(req.ID, err), nil
+		}
+		return &plugin.Response{
+			ID:      req.ID,
+			Success: true,
+			Data: map[string]interface{}{
+				"state": state,
+			},
+		}, nil
+
+	case "pause":
+		// 暂停DLP处理
+		if err := m.Pause(); err != nil {
+			return sdk.ErrorResponse(req.ID, err), nil
+		}
+		return &plugin.Response{
+			ID:      req.ID,
+			Success: true,
+			Data: map[string]interface{}{
+				"pause": m.pauseState.snapshot(time.Now()),
+			},
+		}, nil
+
+	case "resume":
+		// 恢复DLP处理
+		if err := m.Resume(); err != nil {
+			return sdk.ErrorResponse(req.ID, err), nil
+		}
+		return &plugin.Response{
+			ID:      req.ID,
+			Success: true,
+			Data: map[string]interface{}{
+				"pause": m.pauseState.snapshot(time.Now()),
+			},
+		}, nil
+
+	case "list_quarantined":
+		// 列出隔离的文件
+		files, err := m.listQuarantined()
+		if err != nil {
+			return sdk.ErrorResponse(req.ID, err), nil
+		}
+		return &plugin.Response{
+			ID:      req.ID,
+			Success: true,
+			Data: map[string]interface{}{
+				"files": files,
+				"count": len(files),
+			},
+		}, nil
+
+	case "release_quarantined":
+		// 凭审批令牌恢复隔离的文件
+		file, err := m.releaseQuarantined(req.Params)
+		if err != nil {
+			return sdk.ErrorResponse(req.ID, err), nil
+		}
+		return &plugin.Response{
+			ID:      req.ID,
+			Success: true,
+			Data: map[string]interface{}{
+				"file": file,
+			},
+		}, nil
+
+	case "export_bundle":
+		// 导出规则包
+		data, err := m.ExportBundle()
+		if err != nil {
+			return sdk.ErrorResponse(req.ID, err), nil
+		}
+		return &plugin.Response{
+			ID:      req.ID,
+			Success: true,
+			Data: map[string]interface{}{
+				"bundle": string(data),
+			},
+		}, nil
+
+	case "import_bundle":
+		// 校验并导入规则包，dry_run 时只校验
+		summary, err := m.importBundle(req.Params)
+		if err != nil {
+			return sdk.ErrorResponse(req.ID, err), nil
+		}
+		return sdk.NewTypedResponse(req.ID, summary), nil
+
 	case "clear_alerts":
 		// 清除警报
 		m.alertManager.ClearAlerts()
@@ -1180,14 +1400,7 @@ func (m *DLPModule) HandleRequest(ctx context.Context, req *plugin.Request) (*pl
 		}, nil
 
 	default:
-		return &plugin.Response{
-			ID:      req.ID,
-			Success: false,
-			Error: &plugin.ErrorInfo{
-				Code:    "unknown_action",
-				Message: fmt.Sprintf("不支持的操作: %s", req.Action),
-			},
-		}, nil
+		return sdk.ErrorResponse(req.ID, sdk.UnknownActionError(req.Action)), nil
 	}
 }
 
@@ -1229,6 +1442,14 @@ func (m *DLPModule) HandleEvent(ctx context.Context, event *plugin.Event) error
 		m.Logger.Info("系统关闭")
 		return nil
 
+	case "config.changed":
+		// 主程序配置变更事件
+		return m.handleConfigChangedEvent(newPluginEvent(event))
+
+	case "security.alert":
+		// 主程序转发的安全告警事件
+		return m.handleSecurityAlertEvent(newPluginEvent(event))
+
 	case "dlp.scan_request":
 		// 扫描请求
 		m.Logger.Info("收到扫描请求")
@@ -1246,73 +1467,45 @@ func (m *DLPModule) HandleEvent(ctx context.Context, event *plugin.Event) error
 
 // registerProtocolParsers 注册所有协议解析器
 func (m *DLPModule) registerProtocolParsers() error {
-	logger := m.dlpConfig.ParserConfig.Logger
-
-	// HTTP 解析器（只处理明文HTTP）
-	httpParser := parser.NewHTTPParser(logger)
-	if err := m.protocolManager.RegisterParser(httpParser); err != nil {
-		return fmt.Errorf("注册HTTP解析器失败: %w", err)
-	}
-	logger.Info("注册HTTP解析器成功", "protocols", httpParser.GetSupportedProtocols())
-
-	// HTTPS 解析器（处理TLS/SSL加密的HTTP）
-	httpsParser := parser.NewHTTPSParser(logger, m.dlpConfig.ParserConfig.TLSConfig)
-	if err := m.protocolManager.RegisterParser(httpsParser); err != nil {
-		return fmt.Errorf("注册HTTPS解析器失败: %w", err)
-	}
-	logger.Info("注册HTTPS解析器成功", "protocols", httpsParser.GetSupportedProtocols())
-
-	// FTP 解析器
-	ftpParser := parser.NewFTPParser(logger)
-	if err := m.protocolManager.RegisterParser(ftpParser); err != nil {
-		return fmt.Errorf("注册FTP解析器失败: %w", err)
-	}
-	logger.Info("注册FTP解析器成功", "protocols", ftpParser.GetSupportedProtocols())
-
-	// SMTP 解析器
-	smtpParser := parser.NewSMTPParser(logger)
-	if err := m.protocolManager.RegisterParser(smtpParser); err != nil {
-		return fmt.Errorf("注册SMTP解析器失败: %w", err)
-	}
-	logger.Info("注册SMTP解析器成功", "protocols", smtpParser.GetSupportedProtocols())
-
-	// MySQL 解析器
-	mysqlParser := parser.NewMySQLParser(logger)
-	if err := m.protocolManager.RegisterParser(mysqlParser); err != nil {
-		return fmt.Errorf("注册MySQL解析器失败: %w", err)
-	}
-	logger.Info("注册MySQL解析器成功", "protocols", mysqlParser.GetSupportedProtocols())
-
-	// PostgreSQL 解析器
-	postgresqlParser := parser.NewPostgreSQLParser(logger)
-	if err := m.protocolManager.RegisterParser(postgresqlParser); err != nil {
-		return fmt.Errorf("注册PostgreSQL解析器失败: %w", err)
-	}
-	logger.Info("注册PostgreSQL解析器成功", "protocols", postgresqlParser.GetSupportedProtocols())
-
-	// SMB 解析器
-	smbParser := parser.NewSMBParser(logger)
-	if err := m.protocolManager.RegisterParser(smbParser); err != nil {
-		return fmt.Errorf("注册SMB解析器失败: %w", err)
-	}
-	logger.Info("注册SMB解析器成功", "protocols", smbParser.GetSupportedProtocols())
-
-	// WebSocket 解析器 - 暂时注释掉，等待接口修复
-	// websocketParser := parser.NewWebSocketParser(logger)
-	// if err := m.protocolManager.RegisterParser(websocketParser); err != nil {
-	//	return fmt.Errorf("注册WebSocket解析器失败: %w", err)
-	// }
-	// logger.Info("注册WebSocket解析器成功", "protocols", websocketParser.GetSupportedProtocols())
-
-	// 添加默认解析器用于未知协议
-	defaultParser := parser.NewDefaultParser(logger)
-	if err := m.protocolManager.RegisterParser(defaultParser); err != nil {
-		return fmt.Errorf("注册默认解析器失败: %w", err)
+	config := m.dlpConfig.ParserConfig
+	logger := config.Logger
+
+	// HTTP 解析器只处理明文HTTP，HTTPS 解析器处理TLS/SSL加密的HTTP，默认解析器兜底未知协议
+	// WebSocket 解析器暂未注册，等待接口修复
+	parsers := []struct {
+		name   string
+		create func() parser.ProtocolParser
+	}{
+		{"HTTP", func() parser.ProtocolParser { return parser.NewHTTPParser(logger) }},
+		{"HTTPS", func() parser.ProtocolParser { return parser.NewHTTPSParser(logger, config.TLSConfig) }},
+		{"FTP", func() parser.ProtocolParser { return parser.NewFTPParser(logger) }},
+		{"SMTP", func() parser.ProtocolParser { return parser.NewSMTPParser(logger) }},
+		{"MySQL", func() parser.ProtocolParser { return parser.NewMySQLParser(logger) }},
+		{"PostgreSQL", func() parser.ProtocolParser { return parser.NewPostgreSQLParser(logger) }},
+		{"Redis", func() parser.ProtocolParser { return parser.NewRedisParser(logger) }},
+		{"LDAP", func() parser.ProtocolParser { return parser.NewLDAPParser(logger) }},
+		{"AMQP", func() parser.ProtocolParser { return parser.NewAMQPParser(logger) }},
+		{"SMB", func() parser.ProtocolParser { return parser.NewSMBParser(logger) }},
+		{"默认", func() parser.ProtocolParser { return parser.NewDefaultParser(logger) }},
+	}
+
+	var registered []string
+	for _, item := range parsers {
+		protocolParser := item.create()
+		protocols := protocolParser.GetSupportedProtocols()
+		if !config.ParserEnabled(protocols...) {
+			logger.Info("解析器已在配置中禁用", "parser", item.name, "protocols", protocols)
+			continue
+		}
+		if err := m.protocolManager.RegisterParser(protocolParser); err != nil {
+			return fmt.Errorf("注册%s解析器失败: %w", item.name, err)
+		}
+		logger.Info(fmt.Sprintf("注册%s解析器成功", item.name), "protocols", protocols, "priority", config.ParserPriority(protocols...))
+		registered = append(registered, protocols...)
 	}
-	logger.Info("注册默认解析器成功", "protocols", defaultParser.GetSupportedProtocols())
 
-	logger.Info("协议解析器注册完成", "count", 8)
-	logger.Info("支持的协议", "protocols", []string{"http", "https", "tls", "ftp", "smtp", "mysql", "postgresql", "postgres", "pgsql", "smb", "smb2", "smb3", "cifs", "unknown", "default"})
+	logger.Info("协议解析器注册完成", "count", len(registered))
+	logger.Info("支持的协议", "protocols", registered)
 	return nil
 }
 
@@ -1537,13 +1730,25 @@ func (m *DLPModule) ProcessData(data *DataContext) (*ProcessResult, error) {
 		return result, fmt.Errorf("DLP模块未运行")
 	}
 
+	if m.IsPaused() {
+		m.pauseState.skip(data.Type)
+		result.Error = ErrModulePaused.Error()
+		return result, ErrModulePaused
+	}
+
+	if m.protocolManager == nil || m.analysisManager == nil ||
+		m.policyEngine == nil || m.executionManager == nil {
+		result.Error = "核心组件未初始化"
+		return result, fmt.Errorf("核心组件未初始化")
+	}
+
 	// 根据数据类型进行处理
 	switch data.Type {
-	case "network_packet":
+	case DataTypeNetworkPacket:
 		return m.processNetworkData(data, result)
-	case "file_content":
+	case DataTypeFileContent:
 		return m.processFileData(data, result)
-	case "clipboard_content":
+	case DataTypeClipboardContent:
 		return m.processClipboardData(data, result)
 	default:
 		result.Error = fmt.Sprintf("不支持的数据类型: %s", data.Type)
@@ -1551,8 +1756,8 @@ func (m *DLPModule) ProcessData(data *DataContext) (*ProcessResult, error) {
 	}
 }
 
-// GetMetrics 获取插件指标
-func (m *DLPModule) GetMetrics() map[string]interface{} {
+// dlpMetrics 获取DLP特定指标，通过SetMetricsProvider合并到BaseModule的通用指标中
+func (m *DLPModule) dlpMetrics() map[string]interface{} {
 	metrics := make(map[string]interface{})
 
 	m.mu.RLock()
@@ -1562,7 +1767,8 @@ func (m *DLPModule) GetMetrics() map[string]interface{} {
 	metrics["name"] = m.Name()
 	metrics["version"] = m.Version()
 	metrics["running"] = m.running
-	metrics["uptime"] = time.Since(time.Now()).String() // 简化实现
+	metrics["paused"] = m.IsPaused()
+	metrics["pause"] = m.pauseState.snapshot(time.Now())
 
 	// 处理通道指标
 	if m.processingCh != nil {
@@ -1570,16 +1776,31 @@ func (m *DLPModule) GetMetrics() map[string]interface{} {
 		metrics["processing_channel_capacity"] = cap(m.processingCh)
 		metrics["processing_channel_usage"] = float64(len(m.processingCh)) / float64(cap(m.processingCh))
 	}
+	if m.overflowMetrics != nil {
+		metrics["processing_channel_overflows"] = m.overflowMetrics.snapshot()
+	}
 
 	// 配置指标
 	if m.dlpConfig != nil {
 		metrics["max_concurrency"] = m.dlpConfig.MaxConcurrency
 		metrics["buffer_size"] = m.dlpConfig.BufferSize
+		metrics["overflow_policy"] = string(m.dlpConfig.OverflowPolicy)
 		metrics["network_monitoring_enabled"] = m.dlpConfig.EnableNetworkMonitoring
 		metrics["file_monitoring_enabled"] = m.dlpConfig.EnableFileMonitoring
 		metrics["clipboard_monitoring_enabled"] = m.dlpConfig.EnableClipboardMonitoring
 	}
 
+	// 阻断执行能力指标
+	if m.executionManager != nil {
+		metrics["enforcement"] = m.executionManager.GetEnforcementCapability()
+		metrics["degraded_blocks"] = m.executionManager.GetStats().DegradedBlocks
+	}
+
+	// 拦截器流量指标
+	if m.interceptorManager != nil {
+		metrics["interceptors"] = interceptorMetrics(m.interceptorManager.GetStats())
+	}
+
 	// 组件状态指标
 	componentStatus := make(map[string]bool)
 	componentStatus["interceptor_manager"] = m.interceptorManager != nil
@@ -1596,6 +1817,32 @@ func (m *DLPModule) GetMetrics() map[string]interface{} {
 	legacyStatus["scanner"] = m.scanner != nil
 	metrics["legacy_components"] = legacyStatus
 
+	// 当前跟踪的流数量
+	if m.flowTracker != nil {
+		metrics["active_flows"] = m.flowTracker.Len()
+	}
+
+	// 按数据类型统计的处理指标
+	if m.processingMetrics != nil {
+		metrics["process_data"] = m.processingMetrics.snapshot()
+	}
+
+	return metrics
+}
+
+// interceptorMetrics 将拦截器统计信息转换为指标，包括按协议和目标端口的流量分布
+func interceptorMetrics(stats map[string]interceptor.InterceptorStats) map[string]interface{} {
+	metrics := make(map[string]interface{}, len(stats))
+	for name, s := range stats {
+		metrics[name] = map[string]interface{}{
+			"packets_processed":  s.PacketsProcessed,
+			"packets_dropped":    s.PacketsDropped,
+			"bytes_processed":    s.BytesProcessed,
+			"error_count":        s.ErrorCount,
+			"protocol_histogram": s.ProtocolHistogram,
+			"port_histogram":     s.PortHistogram,
+		}
+	}
 	return metrics
 }
 
@@ -1702,45 +1949,6 @@ func (m *DLPModule) checkExecutionManagerHealth() error {
 	return nil
 }
 
-// processNetworkData 处理网络数据
-func (m *DLPModule) processNetworkData(data *DataContext, result *ProcessResult) (*ProcessResult, error) {
-	m.Logger.Debug("处理网络数据", "data_id", data.ID)
-
-	// 简化实现：标记为成功处理
-	result.Success = true
-	result.Data["type"] = "network_packet"
-	result.Data["processed"] = true
-	result.Actions = append(result.Actions, "analyzed", "logged")
-
-	return result, nil
-}
-
-// processFileData 处理文件数据
-func (m *DLPModule) processFileData(data *DataContext, result *ProcessResult) (*ProcessResult, error) {
-	m.Logger.Debug("处理文件数据", "data_id", data.ID)
-
-	// 简化实现：标记为成功处理
-	result.Success = true
-	result.Data["type"] = "file_content"
-	result.Data["processed"] = true
-	result.Actions = append(result.Actions, "scanned", "logged")
-
-	return result, nil
-}
-
-// processClipboardData 处理剪贴板数据
-func (m *DLPModule) processClipboardData(data *DataContext, result *ProcessResult) (*ProcessResult, error) {
-	m.Logger.Debug("处理剪贴板数据", "data_id", data.ID)
-
-	// 简化实现：标记为成功处理
-	result.Success = true
-	result.Data["type"] = "clipboard_content"
-	result.Data["processed"] = true
-	result.Actions = append(result.Actions, "monitored", "logged")
-
-	return result, nil
-}
-
 // convertPluginConfigToDLPConfig 将插件配置转换为DLP配置
 func (m *DLPModule) convertPluginConfigToDLPConfig(config PluginConfig, dlpConfig *DLPConfig) error {
 	// 简化实现：从插件配置中提取DLP相关配置
@@ -1763,9 +1971,20 @@ func (m *DLPModule) reconfigureComponents() error {
 	return nil
 }
 
+// newPluginEvent 将事件总线转发的事件转换为插件事件
+func newPluginEvent(event *plugin.Event) *PluginEvent {
+	return &PluginEvent{
+		ID:        event.ID,
+		Type:      event.Type,
+		Timestamp: time.UnixMilli(event.Timestamp),
+		Source:    event.Source,
+		Data:      event.Data,
+	}
+}
+
 // 事件处理方法
 func (m *DLPModule) handleConfigChangedEvent(event *PluginEvent) error {
-	m.Logger.Info("处理配置变更事件", "event_id", event.ID)
+	m.Logger.Info("处理配置变更事件", "event_id", event.ID, "changed_keys", event.Data["changed_keys"])
 	// 简化实现：记录事件
 	return nil
 }
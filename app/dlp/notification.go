@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/lomehong/kennel/app/dlp/executor"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// parseNotificationSettings 解析执行器配置中的通知模板配置
+// templates 的键为语言和渠道，值包含 subject 和 body；未配置的模板使用默认模板
+func parseNotificationSettings(settings map[string]interface{}, config *executor.ExecutorConfig) {
+	notification := sdk.GetConfigMap(settings, "notification")
+	if len(notification) == 0 {
+		return
+	}
+
+	config.Notification.Locale = sdk.GetConfigString(notification, "locale", config.Notification.Locale)
+
+	locales := sdk.GetConfigMap(notification, "templates")
+	if len(locales) == 0 {
+		return
+	}
+	config.Notification.Templates = make(map[string]map[string]executor.NotificationTemplate, len(locales))
+	for locale := range locales {
+		channels := sdk.GetConfigMap(locales, locale)
+		if len(channels) == 0 {
+			continue
+		}
+		templates := make(map[string]executor.NotificationTemplate, len(channels))
+		for channel := range channels {
+			template := sdk.GetConfigMap(channels, channel)
+			templates[channel] = executor.NotificationTemplate{
+				Subject: sdk.GetConfigString(template, "subject", ""),
+				Body:    sdk.GetConfigString(template, "body", ""),
+			}
+		}
+		config.Notification.Templates[locale] = templates
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/executor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNotificationSettings(t *testing.T) {
+	config := executor.DefaultExecutorConfig()
+	parseNotificationSettings(map[string]interface{}{
+		"notification": map[string]interface{}{
+			"locale": "en-US",
+			"templates": map[string]interface{}{
+				"en-US": map[string]interface{}{
+					"email": map[string]interface{}{
+						"subject": "[{{.level}}] {{.title}}",
+						"body":    "{{.message}}",
+					},
+				},
+			},
+		},
+	}, &config)
+
+	assert.Equal(t, "en-US", config.Notification.Locale)
+	assert.Equal(t, map[string]map[string]executor.NotificationTemplate{
+		"en-US": {"email": {Subject: "[{{.level}}] {{.title}}", Body: "{{.message}}"}},
+	}, config.Notification.Templates)
+
+	// 未配置时保留默认值
+	config = executor.DefaultExecutorConfig()
+	parseNotificationSettings(map[string]interface{}{}, &config)
+	assert.Equal(t, executor.DefaultNotificationConfig(), config.Notification)
+}
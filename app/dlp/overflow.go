@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy 处理通道已满时的溢出策略
+type OverflowPolicy string
+
+const (
+	// OverflowDropNewest 丢弃新任务（默认）
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest 丢弃通道中最早的任务，为新任务腾出空间
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+	// OverflowBlock 阻塞等待通道空闲，超时后丢弃新任务
+	OverflowBlock OverflowPolicy = "block"
+)
+
+// DefaultOverflowTimeout 阻塞策略的默认等待时间
+const DefaultOverflowTimeout = 100 * time.Millisecond
+
+// ParseOverflowPolicy 解析溢出策略名称
+func ParseOverflowPolicy(name string) (OverflowPolicy, error) {
+	switch policy := OverflowPolicy(name); policy {
+	case OverflowDropNewest, OverflowDropOldest, OverflowBlock:
+		return policy, nil
+	case "":
+		return OverflowDropNewest, nil
+	default:
+		return "", fmt.Errorf("未知的溢出策略: %s", name)
+	}
+}
+
+// overflowStats 单个溢出策略的统计
+type overflowStats struct {
+	Overflows uint64 // 通道已满的次数
+	Dropped   uint64 // 因溢出丢弃的任务数
+}
+
+// overflowMetrics 按溢出策略统计的处理通道溢出指标
+type overflowMetrics struct {
+	stats map[OverflowPolicy]*overflowStats
+	mu    sync.Mutex
+}
+
+// newOverflowMetrics 创建溢出指标
+func newOverflowMetrics() *overflowMetrics {
+	return &overflowMetrics{
+		stats: make(map[OverflowPolicy]*overflowStats),
+	}
+}
+
+// record 记录一次溢出，dropped 为本次丢弃的任务数
+func (om *overflowMetrics) record(policy OverflowPolicy, dropped uint64) {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	stats, exists := om.stats[policy]
+	if !exists {
+		stats = &overflowStats{}
+		om.stats[policy] = stats
+	}
+	stats.Overflows++
+	stats.Dropped += dropped
+}
+
+// get 返回指定策略的统计
+func (om *overflowMetrics) get(policy OverflowPolicy) overflowStats {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	if stats, exists := om.stats[policy]; exists {
+		return *stats
+	}
+	return overflowStats{}
+}
+
+// snapshot 返回指标快照
+func (om *overflowMetrics) snapshot() map[string]interface{} {
+	om.mu.Lock()
+	defer om.mu.Unlock()
+
+	result := make(map[string]interface{}, len(om.stats))
+	for policy, stats := range om.stats {
+		result[string(policy)] = map[string]interface{}{
+			"overflows": stats.Overflows,
+			"dropped":   stats.Dropped,
+		}
+	}
+	return result
+}
+
+// enqueueTask 按配置的溢出策略将任务放入处理通道
+// 返回任务是否已进入通道；模块停止时返回 false
+func (m *DLPModule) enqueueTask(task *ProcessingTask) bool {
+	select {
+	case m.processingCh <- task:
+		return true
+	case <-m.stopCh:
+		return false
+	default:
+	}
+
+	policy, timeout := OverflowDropNewest, DefaultOverflowTimeout
+	if m.dlpConfig != nil {
+		policy, timeout = m.dlpConfig.OverflowPolicy, m.dlpConfig.OverflowTimeout
+	}
+
+	switch policy {
+	case OverflowDropOldest:
+		var dropped uint64
+		for {
+			select {
+			case m.processingCh <- task:
+				m.overflowMetrics.record(policy, dropped)
+				return true
+			case <-m.stopCh:
+				m.overflowMetrics.record(policy, dropped)
+				return false
+			default:
+			}
+
+			// 通道仍然已满，丢弃最早的任务后重试
+			select {
+			case oldest := <-m.processingCh:
+				dropped++
+				m.Logger.Warn("处理通道已满，丢弃最早的任务", "task_id", oldest.ID)
+			default:
+			}
+		}
+
+	case OverflowBlock:
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case m.processingCh <- task:
+			m.overflowMetrics.record(policy, 0)
+			return true
+		case <-m.stopCh:
+			m.overflowMetrics.record(policy, 0)
+			return false
+		case <-timer.C:
+			m.overflowMetrics.record(policy, 1)
+			m.Logger.Warn("处理通道已满，等待超时后丢弃任务", "task_id", task.ID, "timeout", timeout)
+			return false
+		}
+
+	default:
+		m.overflowMetrics.record(OverflowDropNewest, 1)
+		m.Logger.Warn("处理通道已满，丢弃任务", "task_id", task.ID)
+		return false
+	}
+}
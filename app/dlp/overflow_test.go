@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOverflowTestModule 创建处理通道容量为 size 且没有工作协程的模块
+func newOverflowTestModule(t *testing.T, policy OverflowPolicy, size int) *DLPModule {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	module := NewDLPModule(logger)
+	module.processingCh = make(chan *ProcessingTask, size)
+	module.dlpConfig = &DLPConfig{OverflowPolicy: policy, OverflowTimeout: 50 * time.Millisecond}
+	return module
+}
+
+// fillChannel 填满处理通道
+func fillChannel(t *testing.T, module *DLPModule) {
+	for i := 0; i < cap(module.processingCh); i++ {
+		require.True(t, module.enqueueTask(&ProcessingTask{ID: fmt.Sprintf("task_%d", i)}))
+	}
+}
+
+// drainIDs 取出通道中全部任务的ID
+func drainIDs(module *DLPModule) []string {
+	var ids []string
+	for len(module.processingCh) > 0 {
+		ids = append(ids, (<-module.processingCh).ID)
+	}
+	return ids
+}
+
+func TestEnqueueTask_DropNewest(t *testing.T) {
+	module := newOverflowTestModule(t, OverflowDropNewest, 3)
+	fillChannel(t, module)
+
+	assert.False(t, module.enqueueTask(&ProcessingTask{ID: "new_1"}))
+	assert.False(t, module.enqueueTask(&ProcessingTask{ID: "new_2"}))
+
+	assert.Equal(t, []string{"task_0", "task_1", "task_2"}, drainIDs(module))
+	assert.Equal(t, overflowStats{Overflows: 2, Dropped: 2}, module.overflowMetrics.get(OverflowDropNewest))
+}
+
+func TestEnqueueTask_DropOldest(t *testing.T) {
+	module := newOverflowTestModule(t, OverflowDropOldest, 3)
+	fillChannel(t, module)
+
+	assert.True(t, module.enqueueTask(&ProcessingTask{ID: "new_1"}))
+	assert.True(t, module.enqueueTask(&ProcessingTask{ID: "new_2"}))
+
+	assert.Equal(t, []string{"task_2", "new_1", "new_2"}, drainIDs(module))
+	assert.Equal(t, overflowStats{Overflows: 2, Dropped: 2}, module.overflowMetrics.get(OverflowDropOldest))
+	assert.Equal(t, overflowStats{}, module.overflowMetrics.get(OverflowDropNewest))
+}
+
+func TestEnqueueTask_BlockWithTimeout(t *testing.T) {
+	module := newOverflowTestModule(t, OverflowBlock, 2)
+	fillChannel(t, module)
+
+	// 超时前没有空闲位置：等待超时后丢弃新任务
+	start := time.Now()
+	assert.False(t, module.enqueueTask(&ProcessingTask{ID: "timeout"}))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// 等待期间通道出现空闲位置：新任务进入通道
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		<-module.processingCh
+	}()
+	assert.True(t, module.enqueueTask(&ProcessingTask{ID: "waited"}))
+
+	assert.Equal(t, []string{"task_1", "waited"}, drainIDs(module))
+	assert.Equal(t, overflowStats{Overflows: 2, Dropped: 1}, module.overflowMetrics.get(OverflowBlock))
+
+	metrics := module.dlpMetrics()
+	assert.Equal(t, "block", metrics["overflow_policy"])
+	assert.Equal(t, map[string]interface{}{
+		"block": map[string]interface{}{"overflows": uint64(2), "dropped": uint64(1)},
+	}, metrics["processing_channel_overflows"])
+}
+
+func TestEnqueueTask_BlockStopsWithModule(t *testing.T) {
+	module := newOverflowTestModule(t, OverflowBlock, 1)
+	module.dlpConfig.OverflowTimeout = time.Minute
+	fillChannel(t, module)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(module.stopCh)
+	}()
+
+	start := time.Now()
+	assert.False(t, module.enqueueTask(&ProcessingTask{ID: "stopped"}))
+	assert.Less(t, time.Since(start), time.Second, "模块停止时应立即返回")
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	for _, name := range []string{"drop_newest", "drop_oldest", "block"} {
+		policy, err := ParseOverflowPolicy(name)
+		require.NoError(t, err)
+		assert.Equal(t, OverflowPolicy(name), policy)
+	}
+
+	policy, err := ParseOverflowPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, OverflowDropNewest, policy)
+
+	_, err = ParseOverflowPolicy("drop_random")
+	assert.Error(t, err)
+}
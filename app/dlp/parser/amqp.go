@@ -0,0 +1,686 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// AMQP默认端口
+const AMQPDefaultPort = 5672
+
+// AMQP 0-9-1 帧类型
+const (
+	AMQPFrameMethod    = 1
+	AMQPFrameHeader    = 2
+	AMQPFrameBody      = 3
+	AMQPFrameHeartbeat = 8
+)
+
+// AMQP 0-9-1 方法类
+const (
+	AMQPClassConnection = 10
+	AMQPClassChannel    = 20
+	AMQPClassExchange   = 40
+	AMQPClassQueue      = 50
+	AMQPClassBasic      = 60
+	AMQPClassConfirm    = 85
+	AMQPClassTx         = 90
+)
+
+const (
+	// amqpFrameEnd 帧结束标记
+	amqpFrameEnd = 0xCE
+	// amqpFrameHeaderSize 帧头长度：类型(1) 通道(2) 负载长度(4)
+	amqpFrameHeaderSize = 7
+	// amqpMaxFrameSize 单个帧允许的最大声明长度
+	amqpMaxFrameSize = 16 * 1024 * 1024
+)
+
+// amqpProtocolHeader AMQP协议头前缀，完整的协议头为 "AMQP" 0 0 9 1
+var amqpProtocolHeader = []byte("AMQP\x00")
+
+// amqpClasses 方法类名称
+var amqpClasses = map[uint16]string{
+	AMQPClassConnection: "connection",
+	AMQPClassChannel:    "channel",
+	AMQPClassExchange:   "exchange",
+	AMQPClassQueue:      "queue",
+	AMQPClassBasic:      "basic",
+	AMQPClassConfirm:    "confirm",
+	AMQPClassTx:         "tx",
+}
+
+// amqpMethods 各方法类的方法名称
+var amqpMethods = map[uint16]map[uint16]string{
+	AMQPClassConnection: {
+		10: "start", 11: "start-ok", 20: "secure", 21: "secure-ok", 30: "tune", 31: "tune-ok",
+		40: "open", 41: "open-ok", 50: "close", 51: "close-ok", 60: "blocked", 61: "unblocked",
+	},
+	AMQPClassChannel: {
+		10: "open", 11: "open-ok", 20: "flow", 21: "flow-ok", 40: "close", 41: "close-ok",
+	},
+	AMQPClassExchange: {
+		10: "declare", 11: "declare-ok", 20: "delete", 21: "delete-ok",
+		30: "bind", 31: "bind-ok", 40: "unbind", 51: "unbind-ok",
+	},
+	AMQPClassQueue: {
+		10: "declare", 11: "declare-ok", 20: "bind", 21: "bind-ok", 30: "purge", 31: "purge-ok",
+		40: "delete", 41: "delete-ok", 50: "unbind", 51: "unbind-ok",
+	},
+	AMQPClassBasic: {
+		10: "qos", 11: "qos-ok", 20: "consume", 21: "consume-ok", 30: "cancel", 31: "cancel-ok",
+		40: "publish", 50: "return", 60: "deliver", 70: "get", 71: "get-ok", 72: "get-empty",
+		80: "ack", 90: "reject", 100: "recover-async", 110: "recover", 111: "recover-ok", 120: "nack",
+	},
+	AMQPClassConfirm: {
+		10: "select", 11: "select-ok",
+	},
+	AMQPClassTx: {
+		10: "select", 11: "select-ok", 20: "commit", 21: "commit-ok", 30: "rollback", 31: "rollback-ok",
+	},
+}
+
+// AMQPFrame AMQP帧
+type AMQPFrame struct {
+	Type     byte
+	Channel  uint16
+	Size     int    // 声明的负载长度
+	Payload  []byte // 负载，帧被截断时只包含部分内容
+	Truncate bool
+}
+
+// AMQPMethod AMQP方法帧的解码结果
+type AMQPMethod struct {
+	Channel      uint16
+	ClassID      uint16
+	MethodID     uint16
+	Name         string
+	Exchange     string
+	RoutingKey   string
+	Queue        string
+	ConsumerTag  string
+	VirtualHost  string
+	Mechanisms   string // connection.start 中服务端支持的认证机制
+	Mechanism    string // connection.start-ok 中客户端选择的认证机制
+	AuthResponse []byte // 认证凭据，只用于标记，不会输出
+	ReplyText    string
+}
+
+// carriesContent 检查方法后是否跟随消息内容（内容头帧和内容体帧）
+func (m *AMQPMethod) carriesContent() bool {
+	if m.ClassID != AMQPClassBasic {
+		return false
+	}
+	switch m.MethodID {
+	case 40, 50, 60, 71: // publish、return、deliver、get-ok
+		return true
+	}
+	return false
+}
+
+// AMQPContentHeader AMQP内容头帧
+type AMQPContentHeader struct {
+	ClassID         uint16
+	BodySize        uint64
+	ContentType     string
+	ContentEncoding string
+}
+
+// AMQPParser AMQP 0-9-1 协议解析器
+type AMQPParser struct {
+	logger       logging.Logger
+	maxValueSize int
+	timeout      time.Duration
+}
+
+// NewAMQPParser 创建AMQP解析器
+func NewAMQPParser(logger logging.Logger) *AMQPParser {
+	parser := &AMQPParser{
+		logger:       logger,
+		maxValueSize: 1024 * 1024, // 1MB
+		timeout:      30 * time.Second,
+	}
+
+	parser.logger.Info("初始化AMQP解析器",
+		"max_value_size", parser.maxValueSize,
+		"timeout", parser.timeout)
+
+	return parser
+}
+
+// GetParserInfo 获取解析器信息
+func (p *AMQPParser) GetParserInfo() ParserInfo {
+	return ParserInfo{
+		Name:               "AMQP Parser",
+		Version:            "1.0.0",
+		Description:        "AMQP 0-9-1协议解析器，解析方法帧、内容头帧和内容体帧",
+		SupportedProtocols: []string{"amqp"},
+		Author:             "DLP Team",
+		License:            "MIT",
+	}
+}
+
+// GetSupportedProtocols 获取支持的协议
+func (p *AMQPParser) GetSupportedProtocols() []string {
+	return []string{"amqp"}
+}
+
+// CanParse 检查是否可以解析数据
+// 识别 "AMQP\x00" 协议头，或帧类型、帧结束标记和方法类都合法的帧
+func (p *AMQPParser) CanParse(packet *interceptor.PacketInfo) bool {
+	data := packet.Payload
+	if bytes.HasPrefix(data, amqpProtocolHeader) {
+		return len(data) >= 8
+	}
+	if len(data) < amqpFrameHeaderSize+1 {
+		return false
+	}
+
+	frame, _, err := p.readFrame(data, 0)
+	if err != nil {
+		return false
+	}
+	// 截断的帧没有结束标记可供校验，只在AMQP端口上识别
+	if frame.Truncate && !p.isAMQPPort(packet) {
+		return false
+	}
+	if frame.Type == AMQPFrameMethod {
+		_, err := p.decodeMethod(frame)
+		return err == nil
+	}
+	return true
+}
+
+// Initialize 初始化解析器
+func (p *AMQPParser) Initialize(config ParserConfig) error {
+	if config.MaxBodySize > 0 {
+		p.maxValueSize = int(config.MaxBodySize)
+	}
+	if config.Timeout > 0 {
+		p.timeout = config.Timeout
+	}
+	p.logger.Info("初始化AMQP解析器", "config", config)
+	return nil
+}
+
+// Parse 解析AMQP数据包
+func (p *AMQPParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error) {
+	startTime := time.Now()
+	defer func() {
+		duration := time.Since(startTime)
+		if duration > p.timeout {
+			p.logger.Warn("AMQP解析超时", "duration", duration)
+		}
+	}()
+
+	data := packet.Payload
+	if len(data) == 0 {
+		return nil, fmt.Errorf("数据包为空")
+	}
+
+	result := &ParsedData{
+		Protocol:    "amqp",
+		ContentType: "application/amqp",
+		Headers:     make(map[string]string),
+		Metadata:    make(map[string]interface{}),
+	}
+
+	// 客户端建立连接时先发送协议头
+	pos := 0
+	if bytes.HasPrefix(data, amqpProtocolHeader) {
+		if len(data) < 8 {
+			return nil, fmt.Errorf("AMQP协议头不完整")
+		}
+		result.Metadata["amqp_protocol_header"] = true
+		result.Metadata["amqp_version"] = fmt.Sprintf("%d-%d-%d", data[5], data[6], data[7])
+		pos = 8
+	}
+
+	frames, err := p.readFrames(data, pos)
+	if err != nil {
+		return nil, fmt.Errorf("解析AMQP帧失败: %w", err)
+	}
+	result.Metadata["amqp_frame_count"] = len(frames)
+
+	var (
+		methods   []*AMQPMethod
+		primary   *AMQPMethod
+		header    *AMQPContentHeader
+		body      bytes.Buffer
+		bodyBytes int
+		truncated bool
+	)
+	for _, frame := range frames {
+		truncated = truncated || frame.Truncate
+
+		switch frame.Type {
+		case AMQPFrameMethod:
+			method, err := p.decodeMethod(frame)
+			if err != nil {
+				return nil, fmt.Errorf("解析AMQP方法失败: %w", err)
+			}
+			methods = append(methods, method)
+			if primary == nil || (!primary.carriesContent() && method.carriesContent()) {
+				primary = method
+			}
+		case AMQPFrameHeader:
+			contentHeader, err := p.decodeContentHeader(frame)
+			if err != nil {
+				if !frame.Truncate {
+					return nil, fmt.Errorf("解析AMQP内容头失败: %w", err)
+				}
+				continue
+			}
+			if header == nil {
+				header = contentHeader
+			}
+		case AMQPFrameBody:
+			bodyBytes += len(frame.Payload)
+			if body.Len()+len(frame.Payload) <= p.maxValueSize {
+				body.Write(frame.Payload)
+			}
+		}
+	}
+
+	result.Body = body.Bytes()
+	result.Metadata["amqp_truncated"] = truncated
+	result.Metadata["amqp_body_bytes"] = bodyBytes
+
+	names := make([]string, 0, len(methods))
+	for _, method := range methods {
+		names = append(names, method.Name)
+
+		// 认证凭据（如 PLAIN 机制的用户名和密码）只标记不输出
+		if method.ClassID == AMQPClassConnection && method.MethodID == 11 {
+			result.Metadata["amqp_auth_mechanism"] = method.Mechanism
+			if len(method.AuthResponse) > 0 {
+				result.Headers["Authorization"] = "***REDACTED***"
+				result.Metadata["password_provided"] = true
+			}
+		}
+	}
+	result.Metadata["amqp_methods"] = names
+
+	if primary != nil {
+		result.Method = primary.Name
+		result.Headers["Method"] = primary.Name
+		result.Metadata["amqp_channel"] = primary.Channel
+		result.Metadata["amqp_class_id"] = primary.ClassID
+		result.Metadata["amqp_method_id"] = primary.MethodID
+		result.Metadata["amqp_method"] = primary.Name
+
+		if primary.carriesContent() || primary.Exchange != "" {
+			result.Metadata["amqp_exchange"] = primary.Exchange
+			result.Headers["Exchange"] = primary.Exchange
+		}
+		if primary.carriesContent() || primary.RoutingKey != "" {
+			result.Metadata["amqp_routing_key"] = primary.RoutingKey
+			result.Headers["Routing-Key"] = primary.RoutingKey
+		}
+		if primary.Queue != "" {
+			result.Metadata["amqp_queue"] = primary.Queue
+		}
+		if primary.ConsumerTag != "" {
+			result.Metadata["amqp_consumer_tag"] = primary.ConsumerTag
+		}
+		if primary.VirtualHost != "" {
+			result.Metadata["amqp_virtual_host"] = primary.VirtualHost
+		}
+		if primary.Mechanisms != "" {
+			result.Metadata["amqp_mechanisms"] = primary.Mechanisms
+		}
+		if primary.ReplyText != "" {
+			result.Metadata["amqp_reply_text"] = primary.ReplyText
+		}
+	}
+
+	if header != nil {
+		result.Metadata["amqp_body_size"] = int64(header.BodySize)
+		if header.ContentType != "" {
+			result.Metadata["amqp_content_type"] = header.ContentType
+			result.Headers["Content-Type"] = header.ContentType
+		}
+		if header.ContentEncoding != "" {
+			result.Metadata["amqp_content_encoding"] = header.ContentEncoding
+		}
+	}
+
+	p.logger.Debug("AMQP帧解析",
+		"method", result.Method,
+		"frames", len(frames),
+		"body_bytes", bodyBytes)
+
+	return result, nil
+}
+
+// Cleanup 清理资源
+func (p *AMQPParser) Cleanup() error {
+	p.logger.Info("清理AMQP解析器资源")
+	return nil
+}
+
+// isAMQPPort 检查数据包是否使用AMQP默认端口
+func (p *AMQPParser) isAMQPPort(packet *interceptor.PacketInfo) bool {
+	return packet.DestPort == AMQPDefaultPort || packet.SourcePort == AMQPDefaultPort
+}
+
+// readFrames 从pos开始读取数据包中的所有帧
+func (p *AMQPParser) readFrames(data []byte, pos int) ([]*AMQPFrame, error) {
+	var frames []*AMQPFrame
+
+	for pos < len(data) {
+		frame, next, err := p.readFrame(data, pos)
+		if err != nil {
+			if len(frames) > 0 {
+				// 后续帧不完整时保留已解析的帧
+				break
+			}
+			return nil, err
+		}
+		frames = append(frames, frame)
+		pos = next
+	}
+
+	return frames, nil
+}
+
+// readFrame 读取pos处的一个帧，返回帧和下一个帧的位置
+// 负载超出数据包范围时按截断处理，Size保留声明的长度
+func (p *AMQPParser) readFrame(data []byte, pos int) (*AMQPFrame, int, error) {
+	if len(data)-pos < amqpFrameHeaderSize {
+		return nil, 0, fmt.Errorf("帧头不完整")
+	}
+
+	frame := &AMQPFrame{
+		Type:    data[pos],
+		Channel: binary.BigEndian.Uint16(data[pos+1:]),
+	}
+	switch frame.Type {
+	case AMQPFrameMethod, AMQPFrameHeader, AMQPFrameBody, AMQPFrameHeartbeat:
+	default:
+		return nil, 0, fmt.Errorf("未知的帧类型: %d", frame.Type)
+	}
+
+	size := binary.BigEndian.Uint32(data[pos+3:])
+	if size > amqpMaxFrameSize {
+		return nil, 0, fmt.Errorf("帧长度过大: %d", size)
+	}
+	frame.Size = int(size)
+	if frame.Type == AMQPFrameHeartbeat && (frame.Size != 0 || frame.Channel != 0) {
+		return nil, 0, fmt.Errorf("无效的心跳帧")
+	}
+
+	start := pos + amqpFrameHeaderSize
+	end := start + frame.Size
+	if end >= len(data) {
+		// 数据包在帧负载或结束标记处被截断
+		if end > len(data) {
+			end = len(data)
+		}
+		frame.Payload = data[start:end]
+		frame.Truncate = true
+		return frame, len(data), nil
+	}
+	if data[end] != amqpFrameEnd {
+		return nil, 0, fmt.Errorf("缺少帧结束标记")
+	}
+
+	frame.Payload = data[start:end]
+	return frame, end + 1, nil
+}
+
+// decodeMethod 解码方法帧，截断帧的参数不完整时只保留方法类和方法ID
+func (p *AMQPParser) decodeMethod(frame *AMQPFrame) (*AMQPMethod, error) {
+	r := &amqpReader{data: frame.Payload}
+	classID, err := r.short()
+	if err != nil {
+		return nil, err
+	}
+	methodID, err := r.short()
+	if err != nil {
+		return nil, err
+	}
+
+	className, ok := amqpClasses[classID]
+	if !ok {
+		return nil, fmt.Errorf("未知的方法类: %d", classID)
+	}
+	method := &AMQPMethod{Channel: frame.Channel, ClassID: classID, MethodID: methodID}
+	if name, ok := amqpMethods[classID][methodID]; ok {
+		method.Name = className + "." + name
+	} else {
+		method.Name = fmt.Sprintf("%s.%d", className, methodID)
+	}
+
+	if err := p.decodeMethodArgs(r, method); err != nil && !frame.Truncate {
+		return nil, fmt.Errorf("%s参数无效: %w", method.Name, err)
+	}
+	return method, nil
+}
+
+// decodeMethodArgs 解码与数据流向相关的方法参数
+func (p *AMQPParser) decodeMethodArgs(r *amqpReader, m *AMQPMethod) error {
+	var err error
+	switch m.ClassID {
+	case AMQPClassConnection:
+		switch m.MethodID {
+		case 10: // start: version-major version-minor server-properties mechanisms locales
+			if err = r.skip(2); err == nil {
+				if err = r.skipTable(); err == nil {
+					m.Mechanisms, err = r.longString()
+				}
+			}
+		case 11: // start-ok: client-properties mechanism response locale
+			if err = r.skipTable(); err == nil {
+				if m.Mechanism, err = r.shortString(); err == nil {
+					var response string
+					response, err = r.longString()
+					m.AuthResponse = []byte(response)
+				}
+			}
+		case 40: // open: virtual-host
+			m.VirtualHost, err = r.shortString()
+		case 50: // close: reply-code reply-text
+			if err = r.skip(2); err == nil {
+				m.ReplyText, err = r.shortString()
+			}
+		}
+
+	case AMQPClassExchange:
+		switch m.MethodID {
+		case 10, 20: // declare、delete: reserved exchange
+			if err = r.skip(2); err == nil {
+				m.Exchange, err = r.shortString()
+			}
+		case 30, 40: // bind、unbind: reserved destination source routing-key
+			if err = r.skip(2); err == nil {
+				if _, err = r.shortString(); err == nil {
+					if m.Exchange, err = r.shortString(); err == nil {
+						m.RoutingKey, err = r.shortString()
+					}
+				}
+			}
+		}
+
+	case AMQPClassQueue:
+		switch m.MethodID {
+		case 10, 30, 40: // declare、purge、delete: reserved queue
+			if err = r.skip(2); err == nil {
+				m.Queue, err = r.shortString()
+			}
+		case 11: // declare-ok: queue
+			m.Queue, err = r.shortString()
+		case 20, 50: // bind、unbind: reserved queue exchange routing-key
+			if err = r.skip(2); err == nil {
+				if m.Queue, err = r.shortString(); err == nil {
+					if m.Exchange, err = r.shortString(); err == nil {
+						m.RoutingKey, err = r.shortString()
+					}
+				}
+			}
+		}
+
+	case AMQPClassBasic:
+		switch m.MethodID {
+		case 20: // consume: reserved queue consumer-tag
+			if err = r.skip(2); err == nil {
+				if m.Queue, err = r.shortString(); err == nil {
+					m.ConsumerTag, err = r.shortString()
+				}
+			}
+		case 40: // publish: reserved exchange routing-key
+			if err = r.skip(2); err == nil {
+				if m.Exchange, err = r.shortString(); err == nil {
+					m.RoutingKey, err = r.shortString()
+				}
+			}
+		case 50: // return: reply-code reply-text exchange routing-key
+			if err = r.skip(2); err == nil {
+				if m.ReplyText, err = r.shortString(); err == nil {
+					if m.Exchange, err = r.shortString(); err == nil {
+						m.RoutingKey, err = r.shortString()
+					}
+				}
+			}
+		case 60: // deliver: consumer-tag delivery-tag redelivered exchange routing-key
+			if m.ConsumerTag, err = r.shortString(); err == nil {
+				if err = r.skip(9); err == nil {
+					if m.Exchange, err = r.shortString(); err == nil {
+						m.RoutingKey, err = r.shortString()
+					}
+				}
+			}
+		case 70: // get: reserved queue
+			if err = r.skip(2); err == nil {
+				m.Queue, err = r.shortString()
+			}
+		case 71: // get-ok: delivery-tag redelivered exchange routing-key
+			if err = r.skip(9); err == nil {
+				if m.Exchange, err = r.shortString(); err == nil {
+					m.RoutingKey, err = r.shortString()
+				}
+			}
+		}
+	}
+	return err
+}
+
+// decodeContentHeader 解码内容头帧：class-id weight body-size property-flags properties
+func (p *AMQPParser) decodeContentHeader(frame *AMQPFrame) (*AMQPContentHeader, error) {
+	r := &amqpReader{data: frame.Payload}
+	classID, err := r.short()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.skip(2); err != nil {
+		return nil, err
+	}
+	bodySize, err := r.longLong()
+	if err != nil {
+		return nil, err
+	}
+	flags, err := r.short()
+	if err != nil {
+		return nil, err
+	}
+
+	header := &AMQPContentHeader{ClassID: classID, BodySize: bodySize}
+	// 属性按标志位从高到低排列，content-type 和 content-encoding 位于最前，属性不完整时忽略
+	if flags&0x8000 != 0 {
+		if header.ContentType, err = r.shortString(); err != nil {
+			return header, nil
+		}
+	}
+	if flags&0x4000 != 0 {
+		header.ContentEncoding, _ = r.shortString()
+	}
+	return header, nil
+}
+
+// amqpReader 按AMQP数据类型读取方法参数
+type amqpReader struct {
+	data []byte
+	pos  int
+}
+
+// skip 跳过n个字节
+func (r *amqpReader) skip(n int) error {
+	if len(r.data)-r.pos < n {
+		return fmt.Errorf("数据不完整")
+	}
+	r.pos += n
+	return nil
+}
+
+// short 读取16位无符号整数
+func (r *amqpReader) short() (uint16, error) {
+	if len(r.data)-r.pos < 2 {
+		return 0, fmt.Errorf("数据不完整")
+	}
+	v := binary.BigEndian.Uint16(r.data[r.pos:])
+	r.pos += 2
+	return v, nil
+}
+
+// longLong 读取64位无符号整数
+func (r *amqpReader) longLong() (uint64, error) {
+	if len(r.data)-r.pos < 8 {
+		return 0, fmt.Errorf("数据不完整")
+	}
+	v := binary.BigEndian.Uint64(r.data[r.pos:])
+	r.pos += 8
+	return v, nil
+}
+
+// shortString 读取以1字节长度开头的短字符串
+func (r *amqpReader) shortString() (string, error) {
+	if r.pos >= len(r.data) {
+		return "", fmt.Errorf("数据不完整")
+	}
+	n := int(r.data[r.pos])
+	if len(r.data)-r.pos-1 < n {
+		return "", fmt.Errorf("短字符串超出数据范围")
+	}
+	s := string(r.data[r.pos+1 : r.pos+1+n])
+	r.pos += 1 + n
+	return s, nil
+}
+
+// longString 读取以4字节长度开头的长字符串
+func (r *amqpReader) longString() (string, error) {
+	n, err := r.longLength()
+	if err != nil {
+		return "", err
+	}
+	s := string(r.data[r.pos : r.pos+n])
+	r.pos += n
+	return s, nil
+}
+
+// skipTable 跳过字段表，字段表以4字节长度开头
+func (r *amqpReader) skipTable() error {
+	n, err := r.longLength()
+	if err != nil {
+		return err
+	}
+	r.pos += n
+	return nil
+}
+
+// longLength 读取4字节长度并校验其后的数据足够
+func (r *amqpReader) longLength() (int, error) {
+	if len(r.data)-r.pos < 4 {
+		return 0, fmt.Errorf("数据不完整")
+	}
+	n := binary.BigEndian.Uint32(r.data[r.pos:])
+	r.pos += 4
+	if uint64(n) > uint64(len(r.data)-r.pos) {
+		return 0, fmt.Errorf("长度超出数据范围: %d", n)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,154 @@
+package parser
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// amqpFrame 编码一个AMQP帧
+func amqpFrame(frameType byte, channel uint16, payload []byte) []byte {
+	out := []byte{frameType, byte(channel >> 8), byte(channel)}
+	out = binary.BigEndian.AppendUint32(out, uint32(len(payload)))
+	out = append(out, payload...)
+	return append(out, amqpFrameEnd)
+}
+
+// amqpMethodPayload 编码方法帧负载
+func amqpMethodPayload(classID, methodID uint16, args ...[]byte) []byte {
+	out := binary.BigEndian.AppendUint16(nil, classID)
+	out = binary.BigEndian.AppendUint16(out, methodID)
+	for _, arg := range args {
+		out = append(out, arg...)
+	}
+	return out
+}
+
+func amqpShortString(s string) []byte {
+	return append([]byte{byte(len(s))}, s...)
+}
+
+func amqpLongString(s string) []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(s))), s...)
+}
+
+func newAMQPPacket(payload []byte, srcPort, dstPort uint16) *interceptor.PacketInfo {
+	return &interceptor.PacketInfo{
+		SourceIP:   net.ParseIP("192.168.1.10"),
+		DestIP:     net.ParseIP("10.0.0.5"),
+		SourcePort: srcPort,
+		DestPort:   dstPort,
+		Payload:    payload,
+		Size:       len(payload),
+	}
+}
+
+func TestAMQPParser_ConnectionStart(t *testing.T) {
+	p := NewAMQPParser(newTestLogger(t))
+
+	// 客户端发送协议头
+	header := newAMQPPacket([]byte("AMQP\x00\x00\x09\x01"), 50000, 5672)
+	require.True(t, p.CanParse(header))
+	result, err := p.Parse(header)
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Metadata["amqp_protocol_header"])
+	assert.Equal(t, "0-9-1", result.Metadata["amqp_version"])
+	assert.Equal(t, 0, result.Metadata["amqp_frame_count"])
+
+	// 服务端响应 connection.start
+	serverProperties := amqpLongString("\x07productS\x00\x00\x00\x08RabbitMQ")
+	start := amqpFrame(AMQPFrameMethod, 0, amqpMethodPayload(AMQPClassConnection, 10,
+		[]byte{0, 9}, serverProperties, amqpLongString("PLAIN AMQPLAIN"), amqpLongString("en_US")))
+	packet := newAMQPPacket(start, 5672, 50000)
+	require.True(t, p.CanParse(packet))
+
+	result, err = p.Parse(packet)
+	require.NoError(t, err)
+	assert.Equal(t, "amqp", result.Protocol)
+	assert.Equal(t, "connection.start", result.Method)
+	assert.Equal(t, uint16(AMQPClassConnection), result.Metadata["amqp_class_id"])
+	assert.Equal(t, uint16(10), result.Metadata["amqp_method_id"])
+	assert.Equal(t, "PLAIN AMQPLAIN", result.Metadata["amqp_mechanisms"])
+
+	// 客户端认证凭据不进入检测内容
+	startOK := amqpFrame(AMQPFrameMethod, 0, amqpMethodPayload(AMQPClassConnection, 11,
+		amqpLongString(""), amqpShortString("PLAIN"), amqpLongString("\x00guest\x00secret"), amqpShortString("en_US")))
+	result, err = p.Parse(newAMQPPacket(startOK, 50000, 5672))
+	require.NoError(t, err)
+	assert.Equal(t, "PLAIN", result.Metadata["amqp_auth_mechanism"])
+	assert.Equal(t, true, result.Metadata["password_provided"])
+	assert.Equal(t, "***REDACTED***", result.Headers["Authorization"])
+	assert.NotContains(t, string(result.Body), "secret")
+}
+
+func TestAMQPParser_BasicPublish(t *testing.T) {
+	p := NewAMQPParser(newTestLogger(t))
+
+	message := `{"customer":"张三","card":"4111111111111111"}`
+	publish := amqpFrame(AMQPFrameMethod, 1, amqpMethodPayload(AMQPClassBasic, 40,
+		[]byte{0, 0}, amqpShortString("orders"), amqpShortString("payment.created"), []byte{0}))
+
+	contentHeader := binary.BigEndian.AppendUint16(nil, AMQPClassBasic)
+	contentHeader = append(contentHeader, 0, 0)
+	contentHeader = binary.BigEndian.AppendUint64(contentHeader, uint64(len(message)))
+	contentHeader = append(contentHeader, 0x80, 0x00)
+	contentHeader = append(contentHeader, amqpShortString("application/json")...)
+
+	frames := append(publish, amqpFrame(AMQPFrameHeader, 1, contentHeader)...)
+	frames = append(frames, amqpFrame(AMQPFrameBody, 1, []byte(message))...)
+
+	packet := newAMQPPacket(frames, 50000, 15672) // 非默认端口也应通过帧结构识别
+	require.True(t, p.CanParse(packet))
+
+	result, err := p.Parse(packet)
+	require.NoError(t, err)
+	assert.Equal(t, "basic.publish", result.Method)
+	assert.Equal(t, uint16(AMQPClassBasic), result.Metadata["amqp_class_id"])
+	assert.Equal(t, uint16(40), result.Metadata["amqp_method_id"])
+	assert.Equal(t, uint16(1), result.Metadata["amqp_channel"])
+	assert.Equal(t, "orders", result.Metadata["amqp_exchange"])
+	assert.Equal(t, "payment.created", result.Metadata["amqp_routing_key"])
+	assert.Equal(t, int64(len(message)), result.Metadata["amqp_body_size"])
+	assert.Equal(t, len(message), result.Metadata["amqp_body_bytes"])
+	assert.Equal(t, "application/json", result.Metadata["amqp_content_type"])
+	assert.Equal(t, []string{"basic.publish"}, result.Metadata["amqp_methods"])
+	assert.Equal(t, 3, result.Metadata["amqp_frame_count"])
+	assert.Equal(t, false, result.Metadata["amqp_truncated"])
+	assert.Equal(t, message, string(result.Body))
+
+	// 内容体帧被截断时保留声明的大小，单独的截断帧只在AMQP端口上识别
+	truncated := frames[:len(frames)-10]
+	bodyFrame := truncated[len(publish)+amqpFrameHeaderSize+len(contentHeader)+1:]
+	assert.False(t, p.CanParse(newAMQPPacket(bodyFrame, 50000, 15672)))
+	assert.True(t, p.CanParse(newAMQPPacket(bodyFrame, 50000, 5672)))
+	result, err = p.Parse(newAMQPPacket(truncated, 50000, 5672))
+	require.NoError(t, err)
+	assert.Equal(t, true, result.Metadata["amqp_truncated"])
+	assert.Equal(t, int64(len(message)), result.Metadata["amqp_body_size"])
+	assert.Equal(t, message[:len(message)-9], string(result.Body))
+}
+
+func TestAMQPParser_RejectsNonAMQP(t *testing.T) {
+	p := NewAMQPParser(newTestLogger(t))
+
+	httpRequest := []byte("GET /index.html HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	assert.False(t, p.CanParse(newAMQPPacket(httpRequest, 50000, 5672)))
+	assert.False(t, p.CanParse(newAMQPPacket([]byte("\x16\x03\x01\x02\x00\x01\x00\x01\xfc"), 50000, 5672)))
+	assert.False(t, p.CanParse(newAMQPPacket([]byte("AMQP"), 50000, 5672)))
+
+	// 帧结束标记错误
+	frame := amqpFrame(AMQPFrameMethod, 0, amqpMethodPayload(AMQPClassChannel, 10, amqpShortString("")))
+	frame[len(frame)-1] = 0x00
+	assert.False(t, p.CanParse(newAMQPPacket(frame, 50000, 5672)))
+
+	// 未知方法类
+	unknown := amqpFrame(AMQPFrameMethod, 0, amqpMethodPayload(99, 10))
+	assert.False(t, p.CanParse(newAMQPPacket(unknown, 50000, 5672)))
+
+	_, err := p.Parse(newAMQPPacket(httpRequest, 50000, 5672))
+	assert.Error(t, err)
+}
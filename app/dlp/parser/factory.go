@@ -78,90 +78,110 @@ func (f *ParserFactoryImpl) RegisterParserType(protocol string, creator ParserCr
 
 // registerBuiltinParsers 注册内置解析器
 func (f *ParserFactoryImpl) registerBuiltinParsers() {
+	for protocol, creator := range builtinParserCreators() {
+		f.creators[protocol] = creator
+	}
+
+	f.logger.Info("注册内置协议解析器完成", "count", len(f.creators))
+}
+
+// builtinParserCreators 返回内置协议解析器的创建函数
+func builtinParserCreators() map[string]ParserCreator {
+	creators := make(map[string]ParserCreator)
+
 	// HTTP/HTTPS 解析器
-	f.creators["http"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["http"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewHTTPParser(config.Logger), nil
 	}
-	f.creators["https"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["https"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewHTTPSParser(config.Logger, config.TLSConfig), nil
 	}
 
 	// FTP 解析器
-	f.creators["ftp"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["ftp"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewFTPParser(config.Logger), nil
 	}
-	f.creators["sftp"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["sftp"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewSFTPParser(config.Logger), nil
 	}
 
 	// 邮件协议解析器
-	f.creators["smtp"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["smtp"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewSMTPParser(config.Logger), nil
 	}
-	f.creators["pop3"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["pop3"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewPOP3Parser(config.Logger), nil
 	}
-	f.creators["imap"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["imap"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewIMAPParser(config.Logger), nil
 	}
 
 	// 文件共享协议解析器
-	f.creators["smb"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["smb"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewSMBParser(config.Logger), nil
 	}
-	f.creators["cifs"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["cifs"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewSMBParser(config.Logger), nil // CIFS使用SMB解析器
 	}
 
 	// WebSocket 解析器 - 暂时注释掉，等待修复
-	// f.creators["websocket"] = func(config ParserConfig) (ProtocolParser, error) {
+	// creators["websocket"] = func(config ParserConfig) (ProtocolParser, error) {
 	//	return NewWebSocketParser(config.Logger), nil
 	// }
 
 	// 数据库协议解析器
-	f.creators["mysql"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["mysql"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewMySQLParser(config.Logger), nil
 	}
-	f.creators["postgresql"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["postgresql"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewPostgreSQLParser(config.Logger), nil
 	}
-	f.creators["sqlserver"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["sqlserver"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewSQLServerParser(config.Logger), nil
 	}
+	creators["redis"] = func(config ParserConfig) (ProtocolParser, error) {
+		return NewRedisParser(config.Logger), nil
+	}
+
+	// 目录服务协议解析器
+	creators["ldap"] = func(config ParserConfig) (ProtocolParser, error) {
+		return NewLDAPParser(config.Logger), nil
+	}
 
 	// 消息队列协议解析器
-	f.creators["mqtt"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["mqtt"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewMQTTParser(config.Logger), nil
 	}
-	f.creators["amqp"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["amqp"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewAMQPParser(config.Logger), nil
 	}
-	f.creators["kafka"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["kafka"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewKafkaParser(config.Logger), nil
 	}
 
 	// API 协议解析器
-	f.creators["grpc"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["grpc"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewGRPCParser(config.Logger), nil
 	}
-	f.creators["graphql"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["graphql"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewGraphQLParser(config.Logger), nil
 	}
 
 	// 默认解析器
-	f.creators["unknown"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["unknown"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewDefaultParser(config.Logger), nil
 	}
-	f.creators["default"] = func(config ParserConfig) (ProtocolParser, error) {
+	creators["default"] = func(config ParserConfig) (ProtocolParser, error) {
 		return NewDefaultParser(config.Logger), nil
 	}
 
-	f.logger.Info("注册内置协议解析器完成", "count", len(f.creators))
+	return creators
 }
 
 // ProtocolDetector 协议检测器
 type ProtocolDetector struct {
-	logger logging.Logger
+	logger    logging.Logger
+	overrides map[uint16]string // 运维配置的端口到协议映射，优先于内容特征和标准端口
 }
 
 // NewProtocolDetector 创建协议检测器
@@ -171,13 +191,29 @@ func NewProtocolDetector(logger logging.Logger) *ProtocolDetector {
 	}
 }
 
+// SetPortOverrides 设置端口到协议的映射，用于识别运行在非标准端口上的服务
+// 映射在检测前验证，验证失败时保留原有映射
+func (d *ProtocolDetector) SetPortOverrides(overrides map[uint16]string) error {
+	if err := ValidatePortOverrides(overrides); err != nil {
+		return err
+	}
+	d.overrides = normalizePortOverrides(overrides)
+	return nil
+}
+
 // DetectProtocol 检测协议类型
 func (d *ProtocolDetector) DetectProtocol(data []byte, port uint16) string {
 	if len(data) == 0 {
 		return "unknown"
 	}
 
-	// 基于数据内容的深度检测（优先级最高）
+	// 运维配置的端口映射优先于特征检测
+	if protocol, exists := d.overrides[port]; exists {
+		d.logger.Debug("使用端口映射配置", "protocol", protocol, "port", port)
+		return protocol
+	}
+
+	// 基于数据内容的深度检测
 	protocolByContent := d.detectByContent(data)
 
 	// 基于端口的初步判断
@@ -222,8 +258,11 @@ func (d *ProtocolDetector) detectByPort(port uint16) string {
 		143:  "imap",
 		445:  "smb",
 		139:  "smb",
+		389:  "ldap",
+		636:  "ldap",
 		3306: "mysql",
 		5432: "postgresql",
+		6379: "redis",
 		1433: "sqlserver",
 		1883: "mqtt",
 		5672: "amqp",
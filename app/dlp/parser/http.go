@@ -267,18 +267,10 @@ func (h *HTTPParserImpl) parseHTTPRequest(packet *interceptor.PacketInfo) (*Pars
 	// 提取头部信息
 	headers := h.ExtractHeaders(req)
 
-	// 提取主体内容
-	body, err := h.ExtractBody(req)
-	if err != nil {
-		h.logger.Warn("提取HTTP主体失败", "error", err)
-		body = nil
-	}
-
 	// 构建解析结果
 	data := &ParsedData{
 		Protocol:    "HTTP",
 		Headers:     headers,
-		Body:        body,
 		ContentType: "application/http",
 		URL:         req.URL.String(),
 		Method:      req.Method,
@@ -301,6 +293,17 @@ func (h *HTTPParserImpl) parseHTTPRequest(packet *interceptor.PacketInfo) (*Pars
 		},
 	}
 
+	// 提取主体内容，解码分块传输和压缩编码后交给分析器
+	var body []byte
+	if req.Body != nil {
+		body, err = h.decodeHTTPBody(req.Body, req.Header, req.TransferEncoding, data.Metadata)
+		if err != nil {
+			h.logger.Warn("提取HTTP主体失败", "error", err)
+			body = nil
+		}
+	}
+	data.Body = body
+
 	// 提取URL详细信息
 	urlInfo := h.extractURLInfo(req.URL.String())
 	for k, v := range urlInfo {
@@ -354,23 +357,10 @@ func (h *HTTPParserImpl) parseHTTPResponse(packet *interceptor.PacketInfo) (*Par
 		headers[name] = strings.Join(values, ", ")
 	}
 
-	// 提取主体内容
-	var body []byte
-	if resp.Body != nil {
-		limitReader := io.LimitReader(resp.Body, h.config.MaxBodySize)
-		body, err = io.ReadAll(limitReader)
-		if err != nil {
-			h.logger.Warn("读取HTTP响应主体失败", "error", err)
-			body = nil
-		}
-		resp.Body.Close()
-	}
-
 	// 构建解析结果
 	data := &ParsedData{
 		Protocol:    "HTTP",
 		Headers:     headers,
-		Body:        body,
 		ContentType: "application/http",
 		StatusCode:  resp.StatusCode,
 		Metadata: map[string]interface{}{
@@ -385,6 +375,18 @@ func (h *HTTPParserImpl) parseHTTPResponse(packet *interceptor.PacketInfo) (*Par
 		},
 	}
 
+	// 提取主体内容，解码分块传输和压缩编码后交给分析器
+	var body []byte
+	if resp.Body != nil {
+		body, err = h.decodeHTTPBody(resp.Body, resp.Header, resp.TransferEncoding, data.Metadata)
+		if err != nil {
+			h.logger.Warn("读取HTTP响应主体失败", "error", err)
+			body = nil
+		}
+		resp.Body.Close()
+	}
+	data.Body = body
+
 	// 创建会话信息
 	sessionID := fmt.Sprintf("%s:%d-%s:%d",
 		packet.DestIP.String(), packet.DestPort,
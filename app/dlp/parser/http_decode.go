@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// DefaultMaxDecompressedSize 默认HTTP主体解压后保留的最大字节数
+const DefaultMaxDecompressedSize int64 = 20 * 1024 * 1024 // 20MB
+
+// maxContentEncodings 最多解码的内容编码层数，防止构造的多层编码消耗资源
+const maxContentEncodings = 4
+
+// errDecompressedTooLarge 解压后的数据超出限制
+var errDecompressedTooLarge = errors.New("解压后的数据超出大小限制")
+
+// httpBodyDecoding HTTP主体解码结果
+type httpBodyDecoding struct {
+	body      []byte
+	encodings []string // 按出现顺序记录的内容编码
+	truncated bool     // 解压后的数据超出限制被截断
+	err       error    // 解码失败时主体保持原样
+}
+
+// readHTTPBody 读取HTTP主体，Transfer-Encoding: chunked 由 net/http 解码
+// 数据包可能只包含部分分块，此时保留已读取的数据
+func readHTTPBody(body io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, limit))
+	if errors.Is(err, io.ErrUnexpectedEOF) && len(data) > 0 {
+		return data, nil
+	}
+	return data, err
+}
+
+// decodeContentEncoding 按 Content-Encoding 逆序解码主体
+// 不支持的编码或解码失败时返回原始主体并记录错误
+func decodeContentEncoding(body []byte, header http.Header, limit int64) *httpBodyDecoding {
+	result := &httpBodyDecoding{body: body}
+
+	for _, value := range header.Values("Content-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if encoding != "" && encoding != "identity" {
+				result.encodings = append(result.encodings, encoding)
+			}
+		}
+	}
+	if len(result.encodings) == 0 || len(body) == 0 {
+		return result
+	}
+	if len(result.encodings) > maxContentEncodings {
+		result.err = fmt.Errorf("内容编码层数过多: %d", len(result.encodings))
+		return result
+	}
+
+	decoded := body
+	for i := len(result.encodings) - 1; i >= 0; i-- {
+		data, err := decompressHTTPBody(decoded, result.encodings[i], limit)
+		if errors.Is(err, errDecompressedTooLarge) {
+			result.truncated = true
+		} else if err != nil {
+			result.err = err
+			return result
+		}
+		decoded = data
+	}
+
+	result.body = decoded
+	return result
+}
+
+// decompressHTTPBody 解码单层内容编码，最多保留 limit 字节
+func decompressHTTPBody(data []byte, encoding string, limit int64) ([]byte, error) {
+	var reader io.Reader
+	switch encoding {
+	case "gzip", "x-gzip":
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("gzip解码失败: %w", err)
+		}
+		defer gzipReader.Close()
+		reader = gzipReader
+
+	case "deflate":
+		// 规范要求zlib封装，部分服务端直接发送原始deflate数据
+		if zlibReader, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+			defer zlibReader.Close()
+			reader = zlibReader
+		} else {
+			flateReader := flate.NewReader(bytes.NewReader(data))
+			defer flateReader.Close()
+			reader = flateReader
+		}
+
+	case "br":
+		reader = brotli.NewReader(bytes.NewReader(data))
+
+	default:
+		return nil, fmt.Errorf("不支持的内容编码: %s", encoding)
+	}
+
+	decoded, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if int64(len(decoded)) > limit {
+		return decoded[:limit], errDecompressedTooLarge
+	}
+	// 数据包可能只包含压缩流的前一部分，保留已解码的内容
+	if err != nil && !(errors.Is(err, io.ErrUnexpectedEOF) && len(decoded) > 0) {
+		return nil, fmt.Errorf("%s解码失败: %w", encoding, err)
+	}
+	return decoded, nil
+}
+
+// decodeHTTPBody 读取并解码HTTP主体，将编码信息写入元数据
+func (h *HTTPParserImpl) decodeHTTPBody(body io.Reader, header http.Header, transferEncoding []string, metadata map[string]interface{}) ([]byte, error) {
+	raw, err := readHTTPBody(body, h.maxBodySize())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transferEncoding) > 0 {
+		metadata["transfer_encoding"] = strings.Join(transferEncoding, ", ")
+	}
+
+	decoding := decodeContentEncoding(raw, header, h.maxDecompressedSize())
+	if len(decoding.encodings) == 0 {
+		return raw, nil
+	}
+
+	metadata["content_encoding"] = strings.Join(decoding.encodings, ", ")
+	metadata["encoded_size"] = len(raw)
+	if decoding.err != nil {
+		metadata["body_decoded"] = false
+		metadata["body_decode_error"] = decoding.err.Error()
+		h.logger.Debug("解码HTTP主体失败", "content_encoding", metadata["content_encoding"], "error", decoding.err)
+		return raw, nil
+	}
+
+	metadata["body_decoded"] = true
+	metadata["decoded_size"] = len(decoding.body)
+	if decoding.truncated {
+		metadata["decompression_truncated"] = true
+		h.logger.Warn("HTTP主体解压后超出大小限制，已截断",
+			"content_encoding", metadata["content_encoding"],
+			"limit", h.maxDecompressedSize())
+	}
+	return decoding.body, nil
+}
+
+// maxBodySize 返回主体读取上限，未初始化时使用默认值
+func (h *HTTPParserImpl) maxBodySize() int64 {
+	if h.config.MaxBodySize > 0 {
+		return h.config.MaxBodySize
+	}
+	return DefaultParserConfig().MaxBodySize
+}
+
+// maxDecompressedSize 返回解压后的主体上限，未初始化时使用默认值
+func (h *HTTPParserImpl) maxDecompressedSize() int64 {
+	if h.config.MaxDecompressedSize > 0 {
+		return h.config.MaxDecompressedSize
+	}
+	return DefaultMaxDecompressedSize
+}
@@ -0,0 +1,201 @@
+package parser
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const httpDecodeSecret = "card=4111111111111111&id=110101199003077777"
+
+func newHTTPTestParser(t *testing.T, config ParserConfig) *HTTPParserImpl {
+	parser := NewHTTPParser(newTestLogger(t)).(*HTTPParserImpl)
+	require.NoError(t, parser.Initialize(config))
+	return parser
+}
+
+func newHTTPPacket(payload []byte) *interceptor.PacketInfo {
+	return &interceptor.PacketInfo{
+		SourceIP:   net.ParseIP("192.168.1.10"),
+		DestIP:     net.ParseIP("203.0.113.5"),
+		SourcePort: 50000,
+		DestPort:   80,
+		Payload:    payload,
+		Size:       len(payload),
+	}
+}
+
+// chunkBody 按固定大小将主体编码为 chunked 格式
+func chunkBody(body []byte, size int) []byte {
+	var buf bytes.Buffer
+	for len(body) > 0 {
+		n := size
+		if n > len(body) {
+			n = len(body)
+		}
+		fmt.Fprintf(&buf, "%x\r\n", n)
+		buf.Write(body[:n])
+		buf.WriteString("\r\n")
+		body = body[n:]
+	}
+	buf.WriteString("0\r\n\r\n")
+	return buf.Bytes()
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func buildHTTPRequest(headers string, body []byte) []byte {
+	return append([]byte("POST /upload HTTP/1.1\r\nHost: files.example.com\r\n"+headers+"\r\n"), body...)
+}
+
+func TestHTTPParser_ChunkedGzipRequest(t *testing.T) {
+	parser := newHTTPTestParser(t, DefaultParserConfig())
+
+	body := chunkBody(gzipBytes(t, []byte(httpDecodeSecret)), 7)
+	payload := buildHTTPRequest("Transfer-Encoding: chunked\r\nContent-Encoding: gzip\r\n", body)
+
+	data, err := parser.Parse(newHTTPPacket(payload))
+	require.NoError(t, err)
+
+	// 分析器收到的是解码后的明文
+	assert.Equal(t, httpDecodeSecret, string(data.Body))
+	assert.Equal(t, "chunked", data.Metadata["transfer_encoding"])
+	assert.Equal(t, "gzip", data.Metadata["content_encoding"])
+	assert.Equal(t, true, data.Metadata["body_decoded"])
+	assert.Equal(t, len(httpDecodeSecret), data.Metadata["decoded_size"])
+	assert.NotContains(t, data.Metadata, "decompression_truncated")
+}
+
+func TestHTTPParser_ChunkedPlainBody(t *testing.T) {
+	parser := newHTTPTestParser(t, DefaultParserConfig())
+
+	payload := buildHTTPRequest("Transfer-Encoding: chunked\r\n", chunkBody([]byte(httpDecodeSecret), 5))
+	data, err := parser.Parse(newHTTPPacket(payload))
+	require.NoError(t, err)
+	assert.Equal(t, httpDecodeSecret, string(data.Body))
+	assert.Equal(t, "chunked", data.Metadata["transfer_encoding"])
+	assert.NotContains(t, data.Metadata, "content_encoding")
+
+	// 数据包只包含前几个分块时保留已收到的内容
+	truncated := payload[:len(payload)-20]
+	data, err = parser.Parse(newHTTPPacket(truncated))
+	require.NoError(t, err)
+	assert.NotEmpty(t, data.Body)
+	assert.True(t, strings.HasPrefix(httpDecodeSecret, string(data.Body)))
+}
+
+func TestHTTPParser_GzipResponse(t *testing.T) {
+	parser := newHTTPTestParser(t, DefaultParserConfig())
+
+	compressed := gzipBytes(t, []byte(httpDecodeSecret))
+	payload := append([]byte(fmt.Sprintf("HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nContent-Encoding: gzip\r\nContent-Length: %d\r\n\r\n", len(compressed))), compressed...)
+
+	data, err := parser.Parse(newHTTPPacket(payload))
+	require.NoError(t, err)
+	assert.Equal(t, httpDecodeSecret, string(data.Body))
+	assert.Equal(t, "gzip", data.Metadata["content_encoding"])
+	assert.Equal(t, len(compressed), data.Metadata["encoded_size"])
+}
+
+func TestHTTPParser_DeflateAndBrotli(t *testing.T) {
+	parser := newHTTPTestParser(t, DefaultParserConfig())
+
+	var zlibBuf bytes.Buffer
+	zw := zlib.NewWriter(&zlibBuf)
+	zw.Write([]byte(httpDecodeSecret))
+	zw.Close()
+
+	var rawBuf bytes.Buffer
+	fw, err := flate.NewWriter(&rawBuf, flate.DefaultCompression)
+	require.NoError(t, err)
+	fw.Write([]byte(httpDecodeSecret))
+	fw.Close()
+
+	var brBuf bytes.Buffer
+	bw := brotli.NewWriter(&brBuf)
+	bw.Write([]byte(httpDecodeSecret))
+	bw.Close()
+
+	// 多层编码按逆序解码
+	var stacked bytes.Buffer
+	sw := brotli.NewWriter(&stacked)
+	sw.Write(gzipBytes(t, []byte(httpDecodeSecret)))
+	sw.Close()
+
+	tests := map[string]struct {
+		encoding string
+		body     []byte
+	}{
+		"zlib封装的deflate": {"deflate", zlibBuf.Bytes()},
+		"原始deflate":      {"deflate", rawBuf.Bytes()},
+		"brotli":         {"br", brBuf.Bytes()},
+		"gzip+br":        {"gzip, br", stacked.Bytes()},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			headers := fmt.Sprintf("Content-Encoding: %s\r\nContent-Length: %d\r\n", tt.encoding, len(tt.body))
+			data, err := parser.Parse(newHTTPPacket(buildHTTPRequest(headers, tt.body)))
+			require.NoError(t, err)
+			assert.Equal(t, httpDecodeSecret, string(data.Body))
+			assert.Equal(t, tt.encoding, data.Metadata["content_encoding"])
+			assert.Equal(t, true, data.Metadata["body_decoded"])
+		})
+	}
+}
+
+func TestHTTPParser_DecompressionCapped(t *testing.T) {
+	config := DefaultParserConfig()
+	config.MaxDecompressedSize = 64 * 1024
+	parser := newHTTPTestParser(t, config)
+
+	// 4MB的零字节压缩后只有几KB
+	bomb := gzipBytes(t, make([]byte, 4*1024*1024))
+	require.Less(t, len(bomb), 64*1024)
+
+	headers := fmt.Sprintf("Content-Encoding: gzip\r\nContent-Length: %d\r\n", len(bomb))
+	data, err := parser.Parse(newHTTPPacket(buildHTTPRequest(headers, bomb)))
+	require.NoError(t, err)
+
+	assert.Len(t, data.Body, 64*1024)
+	assert.Equal(t, true, data.Metadata["decompression_truncated"])
+	assert.Equal(t, 64*1024, data.Metadata["decoded_size"])
+}
+
+func TestHTTPParser_UndecodableBodyKept(t *testing.T) {
+	parser := newHTTPTestParser(t, DefaultParserConfig())
+
+	tests := map[string]string{
+		"不支持的编码":  "compress",
+		"损坏的gzip": "gzip",
+	}
+	for name, encoding := range tests {
+		t.Run(name, func(t *testing.T) {
+			body := []byte(httpDecodeSecret)
+			headers := fmt.Sprintf("Content-Encoding: %s\r\nContent-Length: %d\r\n", encoding, len(body))
+			data, err := parser.Parse(newHTTPPacket(buildHTTPRequest(headers, body)))
+			require.NoError(t, err)
+
+			// 解码失败时分析原始主体
+			assert.Equal(t, httpDecodeSecret, string(data.Body))
+			assert.Equal(t, false, data.Metadata["body_decoded"])
+			assert.NotEmpty(t, data.Metadata["body_decode_error"])
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/lomehong/kennel/app/dlp/interceptor"
@@ -15,6 +16,15 @@ type HTTPSParser struct {
 	logger    logging.Logger
 	tlsConfig *TLSConfig
 	sessions  map[string]*TLSSessionInfo
+
+	// 握手消息重组
+	handshakeMu      sync.Mutex
+	handshakeStreams map[string]*handshakeStream
+
+	// 证书链验证使用的根证书
+	rootsOnce sync.Once
+	roots     *x509.CertPool
+	rootsErr  error
 }
 
 // TLSSessionInfo TLS会话信息
@@ -37,6 +47,8 @@ func NewHTTPSParser(logger logging.Logger, tlsConfig *TLSConfig) *HTTPSParser {
 		logger:    logger,
 		tlsConfig: tlsConfig,
 		sessions:  make(map[string]*TLSSessionInfo),
+
+		handshakeStreams: make(map[string]*handshakeStream),
 	}
 }
 
@@ -73,12 +85,6 @@ func (h *HTTPSParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error)
 		return nil, fmt.Errorf("不是有效的HTTPS数据包")
 	}
 
-	// 解析TLS记录
-	tlsRecord, err := h.parseTLSRecord(packet.Payload)
-	if err != nil {
-		return nil, fmt.Errorf("解析TLS记录失败: %w", err)
-	}
-
 	// 创建解析结果
 	parsedData := &ParsedData{
 		Protocol:    "https",
@@ -88,10 +94,27 @@ func (h *HTTPSParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error)
 		ContentType: "application/octet-stream",
 	}
 
+	// 握手消息可能跨越多个TLS记录和数据包，按连接重组后解析
+	if messages, ok := h.reassembleHandshake(packet); ok {
+		if err := h.parseHandshake(messages, parsedData); err != nil {
+			return nil, fmt.Errorf("解析TLS握手失败: %w", err)
+		}
+		if packet.Payload[0] == 22 {
+			h.addRecordMetadata(packet.Payload, parsedData)
+		} else {
+			parsedData.Metadata["tls_continuation"] = true
+		}
+		return parsedData, nil
+	}
+
+	// 解析TLS记录
+	tlsRecord, err := h.parseTLSRecord(packet.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("解析TLS记录失败: %w", err)
+	}
+
 	// 处理不同类型的TLS记录
 	switch tlsRecord.ContentType {
-	case 22: // Handshake
-		err = h.parseHandshake(tlsRecord, parsedData, packet)
 	case 23: // Application Data
 		err = h.parseApplicationData(tlsRecord, parsedData, packet)
 	case 21: // Alert
@@ -124,6 +147,13 @@ func (h *HTTPSParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error)
 	return parsedData, nil
 }
 
+// addRecordMetadata 添加数据包中第一个TLS记录的元数据
+func (h *HTTPSParser) addRecordMetadata(payload []byte, parsedData *ParsedData) {
+	parsedData.Metadata["tls_version"] = uint16(payload[1])<<8 | uint16(payload[2])
+	parsedData.Metadata["tls_content_type"] = payload[0]
+	parsedData.Metadata["tls_length"] = uint16(payload[3])<<8 | uint16(payload[4])
+}
+
 // GetSupportedProtocols 获取支持的协议列表
 func (h *HTTPSParser) GetSupportedProtocols() []string {
 	return []string{"https", "tls"}
@@ -141,6 +171,8 @@ func (h *HTTPSParser) Initialize(config ParserConfig) error {
 			InsecureSkipVerify: true, // 默认跳过证书验证以便解析
 		}
 	}
+	h.rootsOnce = sync.Once{}
+	h.roots, h.rootsErr = nil, nil
 
 	return nil
 }
@@ -149,6 +181,10 @@ func (h *HTTPSParser) Initialize(config ParserConfig) error {
 func (h *HTTPSParser) Cleanup() error {
 	h.logger.Info("清理HTTPS解析器资源")
 	h.sessions = make(map[string]*TLSSessionInfo)
+
+	h.handshakeMu.Lock()
+	h.handshakeStreams = make(map[string]*handshakeStream)
+	h.handshakeMu.Unlock()
 	return nil
 }
 
@@ -229,30 +265,39 @@ func (h *HTTPSParser) parseTLSRecord(data []byte) (*TLSRecord, error) {
 	return record, nil
 }
 
-// parseHandshake 解析握手消息
-func (h *HTTPSParser) parseHandshake(record *TLSRecord, parsedData *ParsedData, packet *interceptor.PacketInfo) error {
-	if len(record.Data) < 4 {
-		return fmt.Errorf("握手消息长度不足")
+// parseHandshake 解析重组后的握手消息
+// 握手消息尚未完整时只标记 handshake_incomplete，等待后续数据包
+func (h *HTTPSParser) parseHandshake(messages []handshakeMessage, parsedData *ParsedData) error {
+	if len(messages) == 0 {
+		parsedData.Metadata["handshake_incomplete"] = true
+		return nil
 	}
 
-	handshakeType := record.Data[0]
-	length := uint32(record.Data[1])<<16 | uint32(record.Data[2])<<8 | uint32(record.Data[3])
-
-	parsedData.Metadata["handshake_type"] = handshakeType
-	parsedData.Metadata["handshake_length"] = length
-
-	switch handshakeType {
-	case 1: // Client Hello
-		return h.parseClientHello(record.Data[4:], parsedData)
-	case 2: // Server Hello
-		return h.parseServerHello(record.Data[4:], parsedData)
-	case 11: // Certificate
-		return h.parseCertificate(record.Data[4:], parsedData)
-	case 16: // Client Key Exchange
-		return h.parseClientKeyExchange(record.Data[4:], parsedData)
-	default:
-		h.logger.Debug("未处理的握手类型", "type", handshakeType)
+	parsedData.Metadata["handshake_type"] = messages[0].Type
+	parsedData.Metadata["handshake_length"] = uint32(len(messages[0].Body))
+
+	types := make([]uint8, 0, len(messages))
+	for _, message := range messages {
+		types = append(types, message.Type)
+
+		var err error
+		switch message.Type {
+		case 1: // Client Hello
+			err = h.parseClientHello(message.Body, parsedData)
+		case 2: // Server Hello
+			err = h.parseServerHello(message.Body, parsedData)
+		case 11: // Certificate
+			err = h.parseCertificate(message.Body, parsedData)
+		case 16: // Client Key Exchange
+			err = h.parseClientKeyExchange(message.Body, parsedData)
+		default:
+			h.logger.Debug("未处理的握手类型", "type", message.Type)
+		}
+		if err != nil {
+			return err
+		}
 	}
+	parsedData.Metadata["handshake_types"] = types
 
 	return nil
 }
@@ -352,47 +397,53 @@ func (h *HTTPSParser) parseServerHello(data []byte, parsedData *ParsedData) erro
 }
 
 // parseCertificate 解析证书消息
+// 第一个证书为服务器证书，其主体、颁发者、SAN、指纹和信任状态以 server_cert_ 前缀写入元数据
 func (h *HTTPSParser) parseCertificate(data []byte, parsedData *ParsedData) error {
-	if len(data) < 3 {
-		return fmt.Errorf("证书消息长度不足")
+	chain, err := parseCertificateChain(data)
+	if err != nil {
+		h.logger.Debug("证书链解析不完整", "error", err, "parsed", len(chain))
+	}
+
+	certificates := make([]map[string]any, 0, len(chain))
+	for _, cert := range chain {
+		info := NewTLSCertificateInfo(cert)
+		certificates = append(certificates, map[string]any{
+			"subject":            info.Subject,
+			"subject_cn":         info.SubjectCN,
+			"issuer":             info.Issuer,
+			"issuer_cn":          info.IssuerCN,
+			"not_before":         cert.NotBefore,
+			"not_after":          cert.NotAfter,
+			"dns_names":          cert.DNSNames,
+			"ip_addresses":       cert.IPAddresses,
+			"sans":               info.SANs,
+			"fingerprint_sha256": info.FingerprintSHA256,
+		})
 	}
+	parsedData.Metadata["certificates"] = certificates
 
-	certificatesLength := uint32(data[0])<<16 | uint32(data[1])<<8 | uint32(data[2])
-	offset := 3
-
-	certificates := make([]map[string]any, 0)
-
-	for offset < len(data) && offset < int(3+certificatesLength) {
-		if len(data) < offset+3 {
-			break
-		}
-
-		certLength := uint32(data[offset])<<16 | uint32(data[offset+1])<<8 | uint32(data[offset+2])
-		offset += 3
-
-		if len(data) < offset+int(certLength) {
-			break
-		}
-
-		certData := data[offset : offset+int(certLength)]
-		offset += int(certLength)
+	if len(chain) == 0 {
+		return nil
+	}
 
-		// 解析X.509证书
-		cert, err := x509.ParseCertificate(certData)
-		if err == nil {
-			certInfo := map[string]any{
-				"subject":      cert.Subject.String(),
-				"issuer":       cert.Issuer.String(),
-				"not_before":   cert.NotBefore,
-				"not_after":    cert.NotAfter,
-				"dns_names":    cert.DNSNames,
-				"ip_addresses": cert.IPAddresses,
-			}
-			certificates = append(certificates, certInfo)
-		}
+	leaf := NewTLSCertificateInfo(chain[0])
+	parsedData.Metadata["server_certificate"] = leaf
+	parsedData.Metadata["server_cert_subject_cn"] = leaf.SubjectCN
+	parsedData.Metadata["server_cert_subject"] = leaf.Subject
+	parsedData.Metadata["server_cert_issuer"] = leaf.Issuer
+	parsedData.Metadata["server_cert_issuer_cn"] = leaf.IssuerCN
+	parsedData.Metadata["server_cert_sans"] = leaf.SANs
+	parsedData.Metadata["server_cert_fingerprint"] = leaf.FingerprintSHA256
+	parsedData.Metadata["server_cert_self_signed"] = leaf.SelfSigned
+	parsedData.Metadata["server_cert_expired"] = time.Now().After(leaf.NotAfter)
+
+	if err := h.verifyCertificateChain(chain); err != nil {
+		parsedData.Metadata["server_cert_trusted"] = false
+		parsedData.Metadata["server_cert_verify_error"] = err.Error()
+	} else {
+		parsedData.Metadata["server_cert_trusted"] = true
 	}
 
-	parsedData.Metadata["certificates"] = certificates
 	return nil
 }
 
@@ -0,0 +1,215 @@
+package parser
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCertificateChain 测试CA签发的服务器证书链
+type testCertificateChain struct {
+	caPEM []byte
+	leaf  *x509.Certificate
+	tls   tls.Certificate
+}
+
+// newTestCertificateChain 创建CA和服务器证书，extraSANs 用于放大证书使Certificate消息跨越多个TLS记录
+func newTestCertificateChain(t *testing.T, extraSANs int) *testCertificateChain {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Kennel Test CA", Organization: []string{"Kennel"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	dnsNames := []string{"upload.example.com", "www.upload.example.com"}
+	for i := 0; i < extraSANs; i++ {
+		dnsNames = append(dnsNames, fmt.Sprintf("edge-node-%04d.cdn.upload.example.com", i))
+	}
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(4242),
+		Subject:      pkix.Name{CommonName: "upload.example.com", Organization: []string{"Example Uploads"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		DNSNames:     dnsNames,
+		IPAddresses:  []net.IP{net.ParseIP("203.0.113.7")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, caCert, &leafKey.PublicKey, caKey)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(leafDER)
+	require.NoError(t, err)
+
+	return &testCertificateChain{
+		caPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		leaf:  leaf,
+		tls:   tls.Certificate{Certificate: [][]byte{leafDER, caDER}, PrivateKey: leafKey},
+	}
+}
+
+// recordingConn 记录从连接读取的全部数据
+type recordingConn struct {
+	net.Conn
+	received []byte
+}
+
+func (c *recordingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.received = append(c.received, b[:n]...)
+	return n, err
+}
+
+// captureServerFlight 与本地TLS 1.2服务器握手，返回客户端收到的服务器首轮握手记录
+// （ServerHello、Certificate、ServerKeyExchange、ServerHelloDone）
+func captureServerFlight(t *testing.T, chain *testCertificateChain) []byte {
+	clientConn, serverConn := net.Pipe()
+	recorder := &recordingConn{Conn: clientConn}
+
+	server := tls.Server(serverConn, &tls.Config{
+		Certificates: []tls.Certificate{chain.tls},
+		MaxVersion:   tls.VersionTLS12,
+	})
+	done := make(chan error, 1)
+	go func() { done <- server.Handshake() }()
+
+	client := tls.Client(recorder, &tls.Config{
+		ServerName:         "upload.example.com",
+		InsecureSkipVerify: true,
+		MaxVersion:         tls.VersionTLS12,
+	})
+	require.NoError(t, client.Handshake())
+	require.NoError(t, <-done)
+	clientConn.Close()
+	serverConn.Close()
+
+	// 只保留首轮握手记录，之后是ChangeCipherSpec和加密的Finished
+	captured := recorder.received
+	offset := 0
+	for offset+5 <= len(captured) && captured[offset] == 22 {
+		offset += 5 + (int(captured[offset+3])<<8 | int(captured[offset+4]))
+	}
+	require.Greater(t, offset, 0)
+	return captured[:offset]
+}
+
+// serverPackets 将服务器发出的数据按MSS拆分为数据包
+func serverPackets(data []byte, mss int) []*interceptor.PacketInfo {
+	var packets []*interceptor.PacketInfo
+	for len(data) > 0 {
+		n := min(mss, len(data))
+		packets = append(packets, &interceptor.PacketInfo{
+			SourceIP:   net.ParseIP("203.0.113.7"),
+			DestIP:     net.ParseIP("192.168.1.10"),
+			SourcePort: 443,
+			DestPort:   51000,
+			Payload:    data[:n],
+			Size:       n,
+		})
+		data = data[n:]
+	}
+	return packets
+}
+
+// parseServerFlight 依次解析数据包，返回包含服务器证书信息的解析结果
+func parseServerFlight(t *testing.T, parser *HTTPSParser, packets []*interceptor.PacketInfo) *ParsedData {
+	var result *ParsedData
+	for _, packet := range packets {
+		parsed, err := parser.Parse(packet)
+		require.NoError(t, err)
+		if _, ok := parsed.Metadata["server_cert_subject_cn"]; ok {
+			require.Nil(t, result, "证书只应被解析一次")
+			result = parsed
+		}
+	}
+	require.NotNil(t, result, "未提取到服务器证书")
+	return result
+}
+
+func TestHTTPSParser_ExtractsServerCertificate(t *testing.T) {
+	chain := newTestCertificateChain(t, 0)
+	flight := captureServerFlight(t, chain)
+
+	parser := NewHTTPSParser(newTestLogger(t), nil)
+	parsed := parseServerFlight(t, parser, serverPackets(flight, len(flight)))
+
+	fingerprint := sha256.Sum256(chain.leaf.Raw)
+	assert.Equal(t, "upload.example.com", parsed.Metadata["server_cert_subject_cn"])
+	assert.Equal(t, "CN=upload.example.com,O=Example Uploads", parsed.Metadata["server_cert_subject"])
+	assert.Equal(t, "CN=Kennel Test CA,O=Kennel", parsed.Metadata["server_cert_issuer"])
+	assert.Equal(t, "Kennel Test CA", parsed.Metadata["server_cert_issuer_cn"])
+	assert.Equal(t, []string{"upload.example.com", "www.upload.example.com", "203.0.113.7"}, parsed.Metadata["server_cert_sans"])
+	assert.Equal(t, hex.EncodeToString(fingerprint[:]), parsed.Metadata["server_cert_fingerprint"])
+	assert.Equal(t, false, parsed.Metadata["server_cert_self_signed"])
+	assert.Equal(t, false, parsed.Metadata["server_cert_expired"])
+
+	// 测试CA不在系统根证书中
+	assert.Equal(t, false, parsed.Metadata["server_cert_trusted"])
+	assert.NotEmpty(t, parsed.Metadata["server_cert_verify_error"])
+
+	certificates := parsed.Metadata["certificates"].([]map[string]any)
+	require.Len(t, certificates, 2)
+	assert.Equal(t, "Kennel Test CA", certificates[1]["subject_cn"])
+
+	// 同一个数据包中的ServerHello也被解析
+	assert.Contains(t, parsed.Metadata["handshake_types"], uint8(2))
+	assert.Contains(t, parsed.Metadata, "selected_cipher_suite")
+}
+
+func TestHTTPSParser_CertificateSpanningRecordsAndPackets(t *testing.T) {
+	chain := newTestCertificateChain(t, 600)
+	flight := captureServerFlight(t, chain)
+
+	// 证书超过单个TLS记录的16KB上限
+	require.Greater(t, len(chain.leaf.Raw), 16*1024)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, chain.caPEM, 0600))
+	parser := NewHTTPSParser(newTestLogger(t), &TLSConfig{CAFile: caFile})
+
+	packets := serverPackets(flight, 1460)
+	parsed := parseServerFlight(t, parser, packets)
+
+	fingerprint := sha256.Sum256(chain.leaf.Raw)
+	assert.Equal(t, "upload.example.com", parsed.Metadata["server_cert_subject_cn"])
+	assert.Equal(t, hex.EncodeToString(fingerprint[:]), parsed.Metadata["server_cert_fingerprint"])
+	assert.Len(t, parsed.Metadata["server_cert_sans"], 603)
+	assert.Equal(t, true, parsed.Metadata["server_cert_trusted"])
+
+	// 握手完成后不保留重组状态
+	assert.Empty(t, parser.handshakeStreams)
+
+	// 中间的数据包只包含部分握手消息
+	middle, err := NewHTTPSParser(newTestLogger(t), nil).Parse(packets[0])
+	require.NoError(t, err)
+	assert.NotContains(t, middle.Metadata, "server_cert_subject_cn")
+}
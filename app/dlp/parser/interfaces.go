@@ -82,16 +82,21 @@ type ProtocolParser interface {
 
 // ParserConfig 解析器配置
 type ParserConfig struct {
-	MaxBodySize    int64             `yaml:"max_body_size" json:"max_body_size"`
-	Timeout        time.Duration     `yaml:"timeout" json:"timeout"`
-	EnableTLS      bool              `yaml:"enable_tls" json:"enable_tls"`
-	TLSConfig      *TLSConfig        `yaml:"tls_config" json:"tls_config"`
-	BufferSize     int               `yaml:"buffer_size" json:"buffer_size"`
-	SessionTimeout time.Duration     `yaml:"session_timeout" json:"session_timeout"`
-	MaxSessions    int               `yaml:"max_sessions" json:"max_sessions"`
-	EnableDeepScan bool              `yaml:"enable_deep_scan" json:"enable_deep_scan"`
-	CustomHeaders  map[string]string `yaml:"custom_headers" json:"custom_headers"`
-	Logger         logging.Logger    `yaml:"-" json:"-"`
+	MaxBodySize         int64                     `yaml:"max_body_size" json:"max_body_size"`
+	MaxAttachmentSize   int64                     `yaml:"max_attachment_size" json:"max_attachment_size"`
+	MaxDecompressedSize int64                     `yaml:"max_decompressed_size" json:"max_decompressed_size"` // HTTP主体解压后保留的最大字节数，防止压缩炸弹
+	MaxSMBWriteSize     int64                     `yaml:"max_smb_write_size" json:"max_smb_write_size"`       // 每个SMB2 WRITE请求保留的最大写入字节数
+	Timeout             time.Duration             `yaml:"timeout" json:"timeout"`
+	EnableTLS           bool                      `yaml:"enable_tls" json:"enable_tls"`
+	TLSConfig           *TLSConfig                `yaml:"tls_config" json:"tls_config"`
+	BufferSize          int                       `yaml:"buffer_size" json:"buffer_size"`
+	SessionTimeout      time.Duration             `yaml:"session_timeout" json:"session_timeout"`
+	MaxSessions         int                       `yaml:"max_sessions" json:"max_sessions"`
+	EnableDeepScan      bool                      `yaml:"enable_deep_scan" json:"enable_deep_scan"`
+	CustomHeaders       map[string]string         `yaml:"custom_headers" json:"custom_headers"`
+	PortOverrides       map[uint16]string         `yaml:"port_overrides" json:"port_overrides"` // 端口到协议的映射，优先于内容特征和标准端口
+	Parsers             map[string]ParserSettings `yaml:"parsers" json:"parsers"`               // 按协议名称配置解析器的启用状态和检测优先级
+	Logger              logging.Logger            `yaml:"-" json:"-"`
 }
 
 // TLSConfig TLS配置
@@ -109,14 +114,17 @@ type TLSConfig struct {
 // DefaultParserConfig 返回默认解析器配置
 func DefaultParserConfig() ParserConfig {
 	return ParserConfig{
-		MaxBodySize:    10 * 1024 * 1024, // 10MB
-		Timeout:        30 * time.Second,
-		EnableTLS:      true,
-		BufferSize:     65536,
-		SessionTimeout: 5 * time.Minute,
-		MaxSessions:    10000,
-		EnableDeepScan: true,
-		CustomHeaders:  make(map[string]string),
+		MaxBodySize:         10 * 1024 * 1024, // 10MB
+		MaxAttachmentSize:   DefaultMaxAttachmentSize,
+		MaxDecompressedSize: DefaultMaxDecompressedSize,
+		MaxSMBWriteSize:     DefaultMaxSMBWriteSize,
+		Timeout:             30 * time.Second,
+		EnableTLS:           true,
+		BufferSize:          65536,
+		SessionTimeout:      5 * time.Minute,
+		MaxSessions:         10000,
+		EnableDeepScan:      true,
+		CustomHeaders:       make(map[string]string),
 	}
 }
 
@@ -451,6 +459,7 @@ type EmailAttachment struct {
 	Size        int64  `json:"size"`         // 大小
 	Data        []byte `json:"data"`         // 数据
 	Hash        string `json:"hash"`         // 哈希值
+	Truncated   bool   `json:"truncated"`    // 数据是否因大小限制被截断
 }
 
 // EmailCommand 邮件命令
@@ -0,0 +1,721 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// LDAP默认端口
+const (
+	LDAPDefaultPort  = 389
+	LDAPSDefaultPort = 636
+)
+
+// BER标签
+const (
+	berTagBoolean     = 0x01
+	berTagInteger     = 0x02
+	berTagOctetString = 0x04
+	berTagEnumerated  = 0x0a
+	berTagSequence    = 0x30
+	berTagSet         = 0x31
+)
+
+// LDAP协议操作标签（RFC 4511，APPLICATION 类）
+const (
+	LDAPOpBindRequest           = 0x60
+	LDAPOpBindResponse          = 0x61
+	LDAPOpUnbindRequest         = 0x42
+	LDAPOpSearchRequest         = 0x63
+	LDAPOpSearchResultEntry     = 0x64
+	LDAPOpSearchResultDone      = 0x65
+	LDAPOpModifyRequest         = 0x66
+	LDAPOpModifyResponse        = 0x67
+	LDAPOpAddRequest            = 0x68
+	LDAPOpAddResponse           = 0x69
+	LDAPOpDelRequest            = 0x4a
+	LDAPOpDelResponse           = 0x6b
+	LDAPOpModifyDNRequest       = 0x6c
+	LDAPOpModifyDNResponse      = 0x6d
+	LDAPOpCompareRequest        = 0x6e
+	LDAPOpCompareResponse       = 0x6f
+	LDAPOpAbandonRequest        = 0x50
+	LDAPOpSearchResultReference = 0x73
+	LDAPOpExtendedRequest       = 0x77
+	LDAPOpExtendedResponse      = 0x78
+	LDAPOpIntermediateResponse  = 0x79
+)
+
+const (
+	// ldapMaxMessageSize 单个LDAP消息允许的最大声明长度
+	ldapMaxMessageSize = 16 * 1024 * 1024
+	// ldapMaxFilterDepth 搜索过滤器的最大嵌套深度
+	ldapMaxFilterDepth = 32
+)
+
+// ldapOperations LDAP操作标签与名称的对应关系
+var ldapOperations = map[byte]string{
+	LDAPOpBindRequest:           "bind",
+	LDAPOpBindResponse:          "bind_response",
+	LDAPOpUnbindRequest:         "unbind",
+	LDAPOpSearchRequest:         "search",
+	LDAPOpSearchResultEntry:     "search_result_entry",
+	LDAPOpSearchResultDone:      "search_result_done",
+	LDAPOpModifyRequest:         "modify",
+	LDAPOpModifyResponse:        "modify_response",
+	LDAPOpAddRequest:            "add",
+	LDAPOpAddResponse:           "add_response",
+	LDAPOpDelRequest:            "delete",
+	LDAPOpDelResponse:           "delete_response",
+	LDAPOpModifyDNRequest:       "modify_dn",
+	LDAPOpModifyDNResponse:      "modify_dn_response",
+	LDAPOpCompareRequest:        "compare",
+	LDAPOpCompareResponse:       "compare_response",
+	LDAPOpAbandonRequest:        "abandon",
+	LDAPOpSearchResultReference: "search_result_reference",
+	LDAPOpExtendedRequest:       "extended",
+	LDAPOpExtendedResponse:      "extended_response",
+	LDAPOpIntermediateResponse:  "intermediate_response",
+}
+
+// ldapSearchScopes 搜索范围名称
+var ldapSearchScopes = map[int64]string{
+	0: "base",
+	1: "one",
+	2: "sub",
+}
+
+// berElement BER编码的TLV元素
+type berElement struct {
+	Tag      byte
+	Value    []byte
+	Truncate bool // 数据包被截断，Value只包含部分内容
+}
+
+// LDAPMessage LDAP消息
+type LDAPMessage struct {
+	MessageID     int64
+	Operation     string
+	OpTag         byte
+	BindDN        string
+	BindVersion   int64
+	AuthType      string // simple 或 sasl
+	SASLMechanism string
+	Password      []byte // 简单绑定的明文密码，只用于标记，不会输出
+	SearchBase    string
+	SearchScope   string
+	SearchFilter  string
+	Attributes    []string
+	EntryDN       string
+	EntryValues   [][]byte
+	Truncated     bool
+}
+
+// LDAPParser LDAP协议解析器
+type LDAPParser struct {
+	logger       logging.Logger
+	maxValueSize int
+	timeout      time.Duration
+}
+
+// NewLDAPParser 创建LDAP解析器
+func NewLDAPParser(logger logging.Logger) *LDAPParser {
+	parser := &LDAPParser{
+		logger:       logger,
+		maxValueSize: 1024 * 1024, // 1MB
+		timeout:      30 * time.Second,
+	}
+
+	parser.logger.Info("初始化LDAP解析器",
+		"max_value_size", parser.maxValueSize,
+		"timeout", parser.timeout)
+
+	return parser
+}
+
+// GetParserInfo 获取解析器信息
+func (p *LDAPParser) GetParserInfo() ParserInfo {
+	return ParserInfo{
+		Name:               "LDAP Parser",
+		Version:            "1.0.0",
+		Description:        "LDAP协议解析器，解析BER编码的绑定和搜索请求",
+		SupportedProtocols: []string{"ldap"},
+		Author:             "DLP Team",
+		License:            "MIT",
+	}
+}
+
+// GetSupportedProtocols 获取支持的协议
+func (p *LDAPParser) GetSupportedProtocols() []string {
+	return []string{"ldap"}
+}
+
+// CanParse 检查是否可以解析数据
+// 只识别符合 LDAPMessage ::= SEQUENCE { messageID INTEGER, protocolOp ... } 帧结构的数据
+func (p *LDAPParser) CanParse(packet *interceptor.PacketInfo) bool {
+	data := packet.Payload
+	if len(data) < 7 || data[0] != berTagSequence {
+		return false
+	}
+
+	_, _, err := p.parseMessage(data, 0)
+	return err == nil
+}
+
+// Initialize 初始化解析器
+func (p *LDAPParser) Initialize(config ParserConfig) error {
+	if config.MaxBodySize > 0 {
+		p.maxValueSize = int(config.MaxBodySize)
+	}
+	if config.Timeout > 0 {
+		p.timeout = config.Timeout
+	}
+	p.logger.Info("初始化LDAP解析器", "config", config)
+	return nil
+}
+
+// Parse 解析LDAP数据包
+func (p *LDAPParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error) {
+	startTime := time.Now()
+	defer func() {
+		duration := time.Since(startTime)
+		if duration > p.timeout {
+			p.logger.Warn("LDAP解析超时", "duration", duration)
+		}
+	}()
+
+	data := packet.Payload
+	if len(data) == 0 {
+		return nil, fmt.Errorf("数据包为空")
+	}
+
+	messages, err := p.parseMessages(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析LDAP消息失败: %w", err)
+	}
+
+	result := &ParsedData{
+		Protocol:    "ldap",
+		ContentType: "application/ldap",
+		Headers:     make(map[string]string),
+		Metadata:    make(map[string]interface{}),
+	}
+
+	msg := messages[0]
+	operations := make([]string, 0, len(messages))
+	var body bytes.Buffer
+	for _, m := range messages {
+		operations = append(operations, m.Operation)
+		p.collectBody(m, &body)
+
+		// 简单绑定的密码以明文传输，只标记不输出
+		if m.OpTag == LDAPOpBindRequest && m.AuthType == "simple" && len(m.Password) > 0 {
+			result.Headers["Password"] = "***REDACTED***"
+			result.Metadata["ldap_plaintext_password"] = true
+			result.Metadata["password_provided"] = true
+		}
+	}
+
+	result.Body = body.Bytes()
+	result.Method = msg.Operation
+	result.Metadata["ldap_message_id"] = msg.MessageID
+	result.Metadata["ldap_operation"] = msg.Operation
+	result.Metadata["ldap_operations"] = operations
+	result.Metadata["ldap_message_count"] = len(messages)
+	result.Metadata["ldap_truncated"] = msg.Truncated
+	result.Metadata["ldap_tls_port"] = packet.DestPort == LDAPSDefaultPort || packet.SourcePort == LDAPSDefaultPort
+	result.Headers["Operation"] = msg.Operation
+
+	switch msg.OpTag {
+	case LDAPOpBindRequest:
+		result.Metadata["ldap_bind_dn"] = msg.BindDN
+		result.Metadata["ldap_version"] = msg.BindVersion
+		result.Metadata["ldap_auth_type"] = msg.AuthType
+		if msg.SASLMechanism != "" {
+			result.Metadata["ldap_sasl_mechanism"] = msg.SASLMechanism
+		}
+		result.Headers["Bind-DN"] = msg.BindDN
+	case LDAPOpSearchRequest:
+		result.Metadata["ldap_search_base"] = msg.SearchBase
+		result.Metadata["ldap_search_scope"] = msg.SearchScope
+		result.Metadata["ldap_search_filter"] = msg.SearchFilter
+		result.Metadata["ldap_search_attributes"] = msg.Attributes
+		result.URL = msg.SearchBase
+	case LDAPOpSearchResultEntry:
+		result.Metadata["ldap_entry_dn"] = msg.EntryDN
+		result.URL = msg.EntryDN
+	}
+
+	p.logger.Debug("LDAP消息解析",
+		"operation", msg.Operation,
+		"message_id", msg.MessageID,
+		"messages", len(messages))
+
+	return result, nil
+}
+
+// Cleanup 清理资源
+func (p *LDAPParser) Cleanup() error {
+	p.logger.Info("清理LDAP解析器资源")
+	return nil
+}
+
+// collectBody 汇总消息中需要参与内容检测的数据，绑定密码不参与
+func (p *LDAPParser) collectBody(msg *LDAPMessage, body *bytes.Buffer) {
+	var parts [][]byte
+	switch msg.OpTag {
+	case LDAPOpSearchRequest:
+		parts = append(parts, []byte(msg.SearchFilter))
+	case LDAPOpSearchResultEntry:
+		parts = append(parts, []byte(msg.EntryDN))
+		parts = append(parts, msg.EntryValues...)
+	}
+
+	for _, part := range parts {
+		if body.Len()+len(part) > p.maxValueSize {
+			return
+		}
+		body.Write(part)
+		body.WriteByte('\n')
+	}
+}
+
+// parseMessages 解析一个数据包中的所有LDAP消息
+func (p *LDAPParser) parseMessages(data []byte) ([]*LDAPMessage, error) {
+	var messages []*LDAPMessage
+
+	for pos := 0; pos < len(data); {
+		msg, next, err := p.parseMessage(data, pos)
+		if err != nil {
+			if len(messages) > 0 {
+				// 后续消息不完整时保留已解析的消息
+				break
+			}
+			return nil, err
+		}
+
+		messages = append(messages, msg)
+		pos = next
+	}
+
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("未找到LDAP消息")
+	}
+	return messages, nil
+}
+
+// parseMessage 从 pos 开始解析一个 LDAPMessage，返回消息和下一个消息的位置
+func (p *LDAPParser) parseMessage(data []byte, pos int) (*LDAPMessage, int, error) {
+	envelope, next, err := readBER(data, pos)
+	if err != nil && !envelope.Truncate {
+		return nil, 0, err
+	}
+	if envelope.Tag != berTagSequence {
+		return nil, 0, fmt.Errorf("LDAP消息必须是SEQUENCE，实际标签: 0x%02x", envelope.Tag)
+	}
+
+	content := envelope.Value
+	idElem, offset, err := readBER(content, 0)
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取消息ID失败: %w", err)
+	}
+	if idElem.Tag != berTagInteger || len(idElem.Value) == 0 || len(idElem.Value) > 4 {
+		return nil, 0, fmt.Errorf("无效的LDAP消息ID")
+	}
+	messageID := berInteger(idElem.Value)
+	if messageID < 0 {
+		return nil, 0, fmt.Errorf("LDAP消息ID不能为负数")
+	}
+
+	// 只有整个消息被截断时才允许协议操作不完整
+	opElem, _, err := readBER(content, offset)
+	if err != nil && !(opElem.Truncate && envelope.Truncate) {
+		return nil, 0, fmt.Errorf("读取协议操作失败: %w", err)
+	}
+	operation, ok := ldapOperations[opElem.Tag]
+	if !ok {
+		return nil, 0, fmt.Errorf("未知的LDAP协议操作: 0x%02x", opElem.Tag)
+	}
+
+	msg := &LDAPMessage{
+		MessageID: messageID,
+		Operation: operation,
+		OpTag:     opElem.Tag,
+		Truncated: envelope.Truncate || opElem.Truncate,
+	}
+
+	switch opElem.Tag {
+	case LDAPOpBindRequest:
+		err = p.parseBindRequest(opElem.Value, msg)
+	case LDAPOpSearchRequest:
+		err = p.parseSearchRequest(opElem.Value, msg)
+	case LDAPOpSearchResultEntry:
+		err = p.parseSearchResultEntry(opElem.Value, msg)
+	}
+	if err != nil && !msg.Truncated {
+		return nil, 0, fmt.Errorf("解析%s操作失败: %w", operation, err)
+	}
+
+	return msg, next, nil
+}
+
+// parseBindRequest 解析绑定请求
+// BindRequest ::= [APPLICATION 0] SEQUENCE { version INTEGER, name LDAPDN, authentication AuthenticationChoice }
+func (p *LDAPParser) parseBindRequest(data []byte, msg *LDAPMessage) error {
+	version, pos, err := readBER(data, 0)
+	if err != nil {
+		return err
+	}
+	if version.Tag != berTagInteger {
+		return fmt.Errorf("绑定请求缺少版本号")
+	}
+	msg.BindVersion = berInteger(version.Value)
+
+	name, pos, err := readBER(data, pos)
+	if err != nil {
+		return err
+	}
+	if name.Tag != berTagOctetString {
+		return fmt.Errorf("绑定请求缺少DN")
+	}
+	msg.BindDN = string(name.Value)
+
+	auth, _, err := readBER(data, pos)
+	if err != nil && auth.Tag == 0 {
+		return err
+	}
+	switch auth.Tag {
+	case 0x80: // simple [0] OCTET STRING
+		msg.AuthType = "simple"
+		msg.Password = auth.Value
+	case 0xa3: // sasl [3] SaslCredentials
+		msg.AuthType = "sasl"
+		if mechanism, _, err := readBER(auth.Value, 0); err == nil && mechanism.Tag == berTagOctetString {
+			msg.SASLMechanism = string(mechanism.Value)
+		}
+	default:
+		return fmt.Errorf("未知的认证方式: 0x%02x", auth.Tag)
+	}
+	return nil
+}
+
+// parseSearchRequest 解析搜索请求
+// SearchRequest ::= [APPLICATION 3] SEQUENCE { baseObject, scope, derefAliases, sizeLimit, timeLimit, typesOnly, filter, attributes }
+func (p *LDAPParser) parseSearchRequest(data []byte, msg *LDAPMessage) error {
+	expected := []byte{berTagOctetString, berTagEnumerated, berTagEnumerated, berTagInteger, berTagInteger, berTagBoolean}
+	fields := make([]berElement, len(expected))
+	pos := 0
+	for i, tag := range expected {
+		elem, next, err := readBER(data, pos)
+		if err != nil {
+			return err
+		}
+		if elem.Tag != tag {
+			return fmt.Errorf("搜索请求第%d个字段标签无效: 0x%02x", i+1, elem.Tag)
+		}
+		fields[i] = elem
+		pos = next
+	}
+
+	msg.SearchBase = string(fields[0].Value)
+	scope := berInteger(fields[1].Value)
+	if name, ok := ldapSearchScopes[scope]; ok {
+		msg.SearchScope = name
+	} else {
+		msg.SearchScope = strconv.FormatInt(scope, 10)
+	}
+
+	filter, next, err := readBER(data, pos)
+	if err != nil {
+		return err
+	}
+	msg.SearchFilter, err = formatLDAPFilter(filter, 0)
+	if err != nil {
+		return err
+	}
+
+	attributes, _, err := readBER(data, next)
+	if err != nil || attributes.Tag != berTagSequence {
+		// 属性列表可能被截断，已解析的过滤器仍然有效
+		return nil
+	}
+	for pos := 0; pos < len(attributes.Value); {
+		attr, next, err := readBER(attributes.Value, pos)
+		if err != nil || attr.Tag != berTagOctetString {
+			break
+		}
+		msg.Attributes = append(msg.Attributes, string(attr.Value))
+		pos = next
+	}
+	return nil
+}
+
+// parseSearchResultEntry 解析搜索结果条目
+// SearchResultEntry ::= [APPLICATION 4] SEQUENCE { objectName LDAPDN, attributes PartialAttributeList }
+func (p *LDAPParser) parseSearchResultEntry(data []byte, msg *LDAPMessage) error {
+	name, pos, err := readBER(data, 0)
+	if err != nil {
+		return err
+	}
+	if name.Tag != berTagOctetString {
+		return fmt.Errorf("搜索结果缺少DN")
+	}
+	msg.EntryDN = string(name.Value)
+
+	attributes, _, err := readBER(data, pos)
+	if err != nil && attributes.Tag == 0 {
+		return err
+	}
+	for pos := 0; pos < len(attributes.Value); {
+		attr, next, err := readBER(attributes.Value, pos)
+		if err != nil && attr.Tag == 0 {
+			break
+		}
+		// PartialAttribute ::= SEQUENCE { type AttributeDescription, vals SET OF AttributeValue }
+		attrType, valsPos, typeErr := readBER(attr.Value, 0)
+		if typeErr == nil && attrType.Tag == berTagOctetString {
+			msg.Attributes = append(msg.Attributes, string(attrType.Value))
+			if vals, _, _ := readBER(attr.Value, valsPos); vals.Tag == berTagSet {
+				for vpos := 0; vpos < len(vals.Value); {
+					val, vnext, verr := readBER(vals.Value, vpos)
+					if val.Tag == berTagOctetString {
+						msg.EntryValues = append(msg.EntryValues, val.Value)
+					}
+					if verr != nil {
+						break
+					}
+					vpos = vnext
+				}
+			}
+		}
+		if err != nil {
+			break
+		}
+		pos = next
+	}
+	return nil
+}
+
+// formatLDAPFilter 将BER编码的搜索过滤器转换为 RFC 4515 字符串形式
+func formatLDAPFilter(filter berElement, depth int) (string, error) {
+	if depth > ldapMaxFilterDepth {
+		return "", fmt.Errorf("过滤器嵌套过深")
+	}
+
+	switch filter.Tag {
+	case 0xa0, 0xa1: // and [0] / or [1] SET OF Filter
+		op := "&"
+		if filter.Tag == 0xa1 {
+			op = "|"
+		}
+		var b strings.Builder
+		b.WriteString("(" + op)
+		for pos := 0; pos < len(filter.Value); {
+			child, next, err := readBER(filter.Value, pos)
+			if err != nil {
+				return "", err
+			}
+			s, err := formatLDAPFilter(child, depth+1)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(s)
+			pos = next
+		}
+		b.WriteString(")")
+		return b.String(), nil
+	case 0xa2: // not [2] Filter
+		child, _, err := readBER(filter.Value, 0)
+		if err != nil {
+			return "", err
+		}
+		s, err := formatLDAPFilter(child, depth+1)
+		if err != nil {
+			return "", err
+		}
+		return "(!" + s + ")", nil
+	case 0xa3, 0xa5, 0xa6, 0xa8: // equalityMatch / greaterOrEqual / lessOrEqual / approxMatch
+		attr, value, err := readAttributeValueAssertion(filter.Value)
+		if err != nil {
+			return "", err
+		}
+		op := map[byte]string{0xa3: "=", 0xa5: ">=", 0xa6: "<=", 0xa8: "~="}[filter.Tag]
+		return "(" + attr + op + escapeLDAPFilterValue(value) + ")", nil
+	case 0xa4: // substrings [4] SubstringFilter
+		return formatSubstringFilter(filter.Value)
+	case 0x87: // present [7] AttributeDescription
+		return "(" + string(filter.Value) + "=*)", nil
+	case 0xa9: // extensibleMatch [9] MatchingRuleAssertion
+		return formatExtensibleFilter(filter.Value)
+	default:
+		return "", fmt.Errorf("未知的过滤器类型: 0x%02x", filter.Tag)
+	}
+}
+
+// readAttributeValueAssertion 读取 AttributeValueAssertion ::= SEQUENCE { attributeDesc, assertionValue }
+func readAttributeValueAssertion(data []byte) (string, []byte, error) {
+	attr, pos, err := readBER(data, 0)
+	if err != nil {
+		return "", nil, err
+	}
+	value, _, err := readBER(data, pos)
+	if err != nil {
+		return "", nil, err
+	}
+	if attr.Tag != berTagOctetString || value.Tag != berTagOctetString {
+		return "", nil, fmt.Errorf("无效的属性值断言")
+	}
+	return string(attr.Value), value.Value, nil
+}
+
+// formatSubstringFilter 转换子串过滤器，如 (cn=ab*cd*ef)
+func formatSubstringFilter(data []byte) (string, error) {
+	attr, pos, err := readBER(data, 0)
+	if err != nil {
+		return "", err
+	}
+	substrings, _, err := readBER(data, pos)
+	if err != nil {
+		return "", err
+	}
+	if attr.Tag != berTagOctetString || substrings.Tag != berTagSequence {
+		return "", fmt.Errorf("无效的子串过滤器")
+	}
+
+	var initial, final string
+	var middle []string
+	for pos := 0; pos < len(substrings.Value); {
+		elem, next, err := readBER(substrings.Value, pos)
+		if err != nil {
+			return "", err
+		}
+		switch elem.Tag {
+		case 0x80:
+			initial = escapeLDAPFilterValue(elem.Value)
+		case 0x81:
+			middle = append(middle, escapeLDAPFilterValue(elem.Value))
+		case 0x82:
+			final = escapeLDAPFilterValue(elem.Value)
+		default:
+			return "", fmt.Errorf("未知的子串类型: 0x%02x", elem.Tag)
+		}
+		pos = next
+	}
+
+	parts := append([]string{initial}, middle...)
+	parts = append(parts, final)
+	return "(" + string(attr.Value) + "=" + strings.Join(parts, "*") + ")", nil
+}
+
+// formatExtensibleFilter 转换可扩展匹配过滤器，如 (cn:dn:caseExactMatch:=Fred)
+func formatExtensibleFilter(data []byte) (string, error) {
+	var rule, attrType, value string
+	dnAttributes := false
+	for pos := 0; pos < len(data); {
+		elem, next, err := readBER(data, pos)
+		if err != nil {
+			return "", err
+		}
+		switch elem.Tag {
+		case 0x81:
+			rule = string(elem.Value)
+		case 0x82:
+			attrType = string(elem.Value)
+		case 0x83:
+			value = escapeLDAPFilterValue(elem.Value)
+		case 0x84:
+			dnAttributes = len(elem.Value) > 0 && elem.Value[0] != 0
+		}
+		pos = next
+	}
+
+	var b strings.Builder
+	b.WriteString("(" + attrType)
+	if dnAttributes {
+		b.WriteString(":dn")
+	}
+	if rule != "" {
+		b.WriteString(":" + rule)
+	}
+	b.WriteString(":=" + value + ")")
+	return b.String(), nil
+}
+
+// escapeLDAPFilterValue 按 RFC 4515 转义过滤器中的断言值
+func escapeLDAPFilterValue(value []byte) string {
+	var b strings.Builder
+	for _, c := range value {
+		switch {
+		case c == '*' || c == '(' || c == ')' || c == '\\' || c == 0 || c >= 0x80:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// readBER 从 pos 开始读取一个BER元素，返回元素和下一个元素的位置
+// 只支持单字节标签和定长编码（LDAP不允许不定长编码）；
+// 数据被截断时返回已读取的部分内容并设置 Truncate，同时返回错误
+func readBER(data []byte, pos int) (berElement, int, error) {
+	if pos+2 > len(data) {
+		return berElement{}, 0, fmt.Errorf("BER元素不完整")
+	}
+
+	tag := data[pos]
+	if tag&0x1f == 0x1f {
+		return berElement{}, 0, fmt.Errorf("不支持多字节BER标签")
+	}
+
+	length := int(data[pos+1])
+	offset := pos + 2
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 {
+			return berElement{}, 0, fmt.Errorf("LDAP不允许不定长BER编码")
+		}
+		if n > 4 {
+			return berElement{}, 0, fmt.Errorf("BER长度字段过长")
+		}
+		if offset+n > len(data) {
+			return berElement{}, 0, fmt.Errorf("BER长度字段不完整")
+		}
+		length = 0
+		for _, b := range data[offset : offset+n] {
+			length = length<<8 | int(b)
+		}
+		offset += n
+	}
+	if length > ldapMaxMessageSize {
+		return berElement{}, 0, fmt.Errorf("BER长度过大: %d", length)
+	}
+
+	end := offset + length
+	if end > len(data) {
+		elem := berElement{Tag: tag, Value: data[offset:], Truncate: true}
+		return elem, len(data), fmt.Errorf("BER元素被截断")
+	}
+	return berElement{Tag: tag, Value: data[offset:end]}, end, nil
+}
+
+// berInteger 解码BER整数（二进制补码，大端序）
+func berInteger(value []byte) int64 {
+	if len(value) == 0 {
+		return 0
+	}
+	var n int64
+	if value[0]&0x80 != 0 {
+		n = -1
+	}
+	for _, b := range value {
+		n = n<<8 | int64(b)
+	}
+	return n
+}
@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"net"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ber 编码一个BER元素，长度超过127字节时使用长格式
+func ber(tag byte, parts ...[]byte) []byte {
+	var value []byte
+	for _, part := range parts {
+		value = append(value, part...)
+	}
+
+	out := []byte{tag}
+	if n := len(value); n < 0x80 {
+		out = append(out, byte(n))
+	} else {
+		out = append(out, 0x82, byte(n>>8), byte(n))
+	}
+	return append(out, value...)
+}
+
+func berString(tag byte, s string) []byte {
+	return ber(tag, []byte(s))
+}
+
+func ldapMessage(id byte, op []byte) []byte {
+	return ber(berTagSequence, ber(berTagInteger, []byte{id}), op)
+}
+
+func newLDAPPacket(payload []byte, srcPort, dstPort uint16) *interceptor.PacketInfo {
+	return &interceptor.PacketInfo{
+		SourceIP:   net.ParseIP("192.168.1.10"),
+		DestIP:     net.ParseIP("10.0.0.5"),
+		SourcePort: srcPort,
+		DestPort:   dstPort,
+		Payload:    payload,
+		Size:       len(payload),
+	}
+}
+
+func TestLDAPParser_SimpleBind(t *testing.T) {
+	p := NewLDAPParser(newTestLogger(t))
+
+	password := "S3cret!Passw0rd"
+	bindDN := "cn=admin,dc=example,dc=com"
+	frame := ldapMessage(1, ber(LDAPOpBindRequest,
+		ber(berTagInteger, []byte{3}),
+		berString(berTagOctetString, bindDN),
+		berString(0x80, password),
+	))
+
+	packet := newLDAPPacket(frame, 50000, LDAPDefaultPort)
+	require.True(t, p.CanParse(packet))
+
+	result, err := p.Parse(packet)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ldap", result.Protocol)
+	assert.Equal(t, "bind", result.Metadata["ldap_operation"])
+	assert.Equal(t, int64(1), result.Metadata["ldap_message_id"])
+	assert.Equal(t, bindDN, result.Metadata["ldap_bind_dn"])
+	assert.Equal(t, int64(3), result.Metadata["ldap_version"])
+	assert.Equal(t, "simple", result.Metadata["ldap_auth_type"])
+	assert.Equal(t, true, result.Metadata["ldap_plaintext_password"])
+	assert.Equal(t, "***REDACTED***", result.Headers["Password"])
+
+	// 明文密码不能出现在解析结果中
+	assert.NotContains(t, string(result.Body), password)
+	for key, value := range result.Headers {
+		assert.NotContains(t, value, password, "header %s", key)
+	}
+}
+
+func TestLDAPParser_SASLBindNotFlagged(t *testing.T) {
+	p := NewLDAPParser(newTestLogger(t))
+
+	frame := ldapMessage(2, ber(LDAPOpBindRequest,
+		ber(berTagInteger, []byte{3}),
+		berString(berTagOctetString, ""),
+		ber(0xa3, berString(berTagOctetString, "GSSAPI")),
+	))
+
+	result, err := p.Parse(newLDAPPacket(frame, 50000, LDAPDefaultPort))
+	require.NoError(t, err)
+	assert.Equal(t, "sasl", result.Metadata["ldap_auth_type"])
+	assert.Equal(t, "GSSAPI", result.Metadata["ldap_sasl_mechanism"])
+	assert.NotContains(t, result.Metadata, "ldap_plaintext_password")
+	assert.NotContains(t, result.Headers, "Password")
+}
+
+func TestLDAPParser_SearchRequest(t *testing.T) {
+	p := NewLDAPParser(newTestLogger(t))
+
+	// (&(objectClass=person)(|(uid=jdoe)(mail=*@example.com))(!(cn=a\2ab)))
+	filter := ber(0xa0,
+		ber(0xa3, berString(berTagOctetString, "objectClass"), berString(berTagOctetString, "person")),
+		ber(0xa1,
+			ber(0xa3, berString(berTagOctetString, "uid"), berString(berTagOctetString, "jdoe")),
+			ber(0xa4, berString(berTagOctetString, "mail"), ber(berTagSequence, berString(0x82, "@example.com"))),
+		),
+		ber(0xa2, ber(0xa3, berString(berTagOctetString, "cn"), berString(berTagOctetString, "a*b"))),
+	)
+	frame := ldapMessage(7, ber(LDAPOpSearchRequest,
+		berString(berTagOctetString, "ou=people,dc=example,dc=com"),
+		ber(berTagEnumerated, []byte{2}),
+		ber(berTagEnumerated, []byte{0}),
+		ber(berTagInteger, []byte{0}),
+		ber(berTagInteger, []byte{0}),
+		ber(berTagBoolean, []byte{0}),
+		filter,
+		ber(berTagSequence, berString(berTagOctetString, "cn"), berString(berTagOctetString, "mail")),
+	))
+
+	// 非默认端口也应通过BER帧识别
+	packet := newLDAPPacket(frame, 50000, 10389)
+	require.True(t, p.CanParse(packet))
+
+	result, err := p.Parse(packet)
+	require.NoError(t, err)
+
+	assert.Equal(t, "search", result.Metadata["ldap_operation"])
+	assert.Equal(t, int64(7), result.Metadata["ldap_message_id"])
+	assert.Equal(t, "ou=people,dc=example,dc=com", result.Metadata["ldap_search_base"])
+	assert.Equal(t, "sub", result.Metadata["ldap_search_scope"])
+	assert.Equal(t, "(&(objectClass=person)(|(uid=jdoe)(mail=*@example.com))(!(cn=a\\2ab)))", result.Metadata["ldap_search_filter"])
+	assert.Equal(t, []string{"cn", "mail"}, result.Metadata["ldap_search_attributes"])
+	assert.NotContains(t, result.Metadata, "ldap_plaintext_password")
+}
+
+func TestLDAPParser_PipelinedMessages(t *testing.T) {
+	p := NewLDAPParser(newTestLogger(t))
+
+	bind := ldapMessage(1, ber(LDAPOpBindRequest,
+		ber(berTagInteger, []byte{3}),
+		berString(berTagOctetString, "cn=svc,dc=example,dc=com"),
+		berString(0x80, "pw"),
+	))
+	search := ldapMessage(2, ber(LDAPOpSearchRequest,
+		berString(berTagOctetString, "dc=example,dc=com"),
+		ber(berTagEnumerated, []byte{0}),
+		ber(berTagEnumerated, []byte{0}),
+		ber(berTagInteger, []byte{0}),
+		ber(berTagInteger, []byte{0}),
+		ber(berTagBoolean, []byte{0}),
+		berString(0x87, "objectClass"),
+		ber(berTagSequence),
+	))
+
+	result, err := p.Parse(newLDAPPacket(append(bind, search...), 50000, LDAPDefaultPort))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bind", "search"}, result.Metadata["ldap_operations"])
+	assert.Equal(t, true, result.Metadata["ldap_plaintext_password"])
+}
+
+func TestLDAPParser_RejectsNonLDAP(t *testing.T) {
+	p := NewLDAPParser(newTestLogger(t))
+
+	payloads := map[string][]byte{
+		"http":       []byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"),
+		"redis":      []byte("*1\r\n$4\r\nPING\r\n"),
+		"short":      {0x30, 0x03, 0x02, 0x01},
+		"snmp":       ber(berTagSequence, ber(berTagInteger, []byte{1}), berString(berTagOctetString, "public"), ber(0xa0)),
+		"indefinite": {0x30, 0x80, 0x02, 0x01, 0x01, 0x42, 0x00, 0x00, 0x00},
+		"bad_id":     ber(berTagSequence, berString(berTagOctetString, "x"), ber(LDAPOpUnbindRequest)),
+	}
+	for name, payload := range payloads {
+		packet := newLDAPPacket(payload, 50000, LDAPDefaultPort)
+		assert.False(t, p.CanParse(packet), name)
+		_, err := p.Parse(packet)
+		assert.Error(t, err, name)
+	}
+}
@@ -11,9 +11,17 @@ import (
 	"github.com/lomehong/kennel/pkg/logging"
 )
 
+// detectionEntry 参与协议检测的解析器
+type detectionEntry struct {
+	protocol string // 解析器的主协议名称
+	parser   ProtocolParser
+	priority int
+}
+
 // ProtocolManagerImpl 协议解析管理器实现
 type ProtocolManagerImpl struct {
 	parsers        map[string]ProtocolParser
+	detection      []detectionEntry // 按优先级从高到低排列，优先级相同时按注册顺序
 	sessionManager SessionManager
 	stats          ParserStats
 	logger         logging.Logger
@@ -36,28 +44,68 @@ func NewProtocolManager(logger logging.Logger, config ParserConfig) ProtocolMana
 	}
 }
 
-// RegisterParser 注册解析器
+// RegisterParser 注册解析器，配置中禁用的解析器被跳过，不参与协议检测和解析
 func (pm *ProtocolManagerImpl) RegisterParser(parser ProtocolParser) error {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
 	info := parser.GetParserInfo()
+	if !pm.config.ParserEnabled(info.SupportedProtocols...) {
+		pm.logger.Info("解析器已禁用，跳过注册", "name", info.Name, "protocols", info.SupportedProtocols)
+		return nil
+	}
+
 	for _, protocol := range info.SupportedProtocols {
 		if _, exists := pm.parsers[protocol]; exists {
 			return fmt.Errorf("协议解析器已存在: %s", protocol)
 		}
+	}
+	for _, protocol := range info.SupportedProtocols {
 		pm.parsers[protocol] = parser
 		pm.stats.ParserStats[protocol] = 0
 	}
 
+	// 默认解析器只用于兜底，不参与协议检测
+	priority := pm.config.ParserPriority(info.SupportedProtocols...)
+	if len(info.SupportedProtocols) > 0 && !contains(info.SupportedProtocols, "default") && !contains(info.SupportedProtocols, "unknown") {
+		pm.addDetectionEntry(detectionEntry{protocol: info.SupportedProtocols[0], parser: parser, priority: priority})
+	}
+
 	pm.logger.Info("注册协议解析器",
 		"name", info.Name,
 		"version", info.Version,
-		"protocols", info.SupportedProtocols)
+		"protocols", info.SupportedProtocols,
+		"priority", priority)
 
 	return nil
 }
 
+// addDetectionEntry 按优先级插入检测列表，优先级相同时排在已注册的解析器之后，调用方需持有写锁
+func (pm *ProtocolManagerImpl) addDetectionEntry(entry detectionEntry) {
+	index := len(pm.detection)
+	for i, existing := range pm.detection {
+		if entry.priority > existing.priority {
+			index = i
+			break
+		}
+	}
+	pm.detection = append(pm.detection, detectionEntry{})
+	copy(pm.detection[index+1:], pm.detection[index:])
+	pm.detection[index] = entry
+}
+
+// DetectionOrder 返回参与协议检测的解析器主协议名称，按检测顺序排列
+func (pm *ProtocolManagerImpl) DetectionOrder() []string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	order := make([]string, 0, len(pm.detection))
+	for _, entry := range pm.detection {
+		order = append(order, entry.protocol)
+	}
+	return order
+}
+
 // GetParser 获取解析器
 func (pm *ProtocolManagerImpl) GetParser(protocol string) (ProtocolParser, bool) {
 	pm.mu.RLock()
@@ -77,27 +125,16 @@ func (pm *ProtocolManagerImpl) ParsePacket(packet *interceptor.PacketInfo) (*Par
 
 	pm.mu.RLock()
 
-	// 定义协议解析器优先级顺序（从高到低）
-	// 注意：http应该在https之前检查，避免HTTP流量被误判为TLS
-	protocolPriority := []string{"http", "https", "ftp", "smtp", "mysql"}
-
-	// 按优先级顺序查找匹配的解析器
-	for _, proto := range protocolPriority {
-		if p, exists := pm.parsers[proto]; exists && p.CanParse(packet) {
-			parser = p
-			protocol = proto
-			pm.logger.Debug("找到匹配的协议解析器", "protocol", proto, "packet_size", packet.Size, "dest_port", packet.DestPort)
-			break
-		}
-	}
+	// 运维配置的端口映射优先于各解析器的特征检测
+	parser, protocol = pm.overrideParser(packet)
 
-	// 如果优先级列表中没有找到，再检查其他解析器
+	// 按优先级顺序查找匹配的解析器，优先级由配置和内置默认值决定
 	if parser == nil {
-		for proto, p := range pm.parsers {
-			if proto != "unknown" && proto != "default" && !contains(protocolPriority, proto) && p.CanParse(packet) {
-				parser = p
-				protocol = proto
-				pm.logger.Debug("找到其他协议解析器", "protocol", proto, "packet_size", packet.Size)
+		for _, entry := range pm.detection {
+			if entry.parser.CanParse(packet) {
+				parser = entry.parser
+				protocol = entry.protocol
+				pm.logger.Debug("找到匹配的协议解析器", "protocol", entry.protocol, "priority", entry.priority, "packet_size", packet.Size, "dest_port", packet.DestPort)
 				break
 			}
 		}
@@ -205,6 +242,21 @@ func (pm *ProtocolManagerImpl) ParsePacket(packet *interceptor.PacketInfo) (*Par
 	return data, nil
 }
 
+// overrideParser 根据端口映射查找解析器，先匹配目的端口再匹配源端口，调用方需持有读锁
+func (pm *ProtocolManagerImpl) overrideParser(packet *interceptor.PacketInfo) (ProtocolParser, string) {
+	for _, port := range []uint16{packet.DestPort, packet.SourcePort} {
+		protocol, exists := pm.config.PortOverrides[port]
+		if !exists {
+			continue
+		}
+		if p, exists := pm.parsers[protocol]; exists {
+			pm.logger.Debug("使用端口映射配置的协议解析器", "protocol", protocol, "port", port)
+			return p, protocol
+		}
+	}
+	return nil, ""
+}
+
 // GetSupportedProtocols 获取支持的协议列表
 func (pm *ProtocolManagerImpl) GetSupportedProtocols() []string {
 	pm.mu.RLock()
@@ -0,0 +1,248 @@
+package parser
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxAttachmentSize 默认单个附件保留的最大字节数
+const DefaultMaxAttachmentSize int64 = 5 * 1024 * 1024 // 5MB
+
+// maxMIMEDepth 嵌套multipart的最大解析深度，防止恶意构造的邮件导致深度递归
+const maxMIMEDepth = 10
+
+// mimeHeaderDecoder 解码RFC 2047编码的邮件头（如 =?UTF-8?B?...?=）
+var mimeHeaderDecoder = new(mime.WordDecoder)
+
+// parseMIMEMessage 解析MIME邮件，提取主题、收件人、正文和附件
+// maxAttachmentSize 限制每个附件保留的数据大小，超出部分只参与大小和哈希计算
+func parseMIMEMessage(data []byte, maxAttachmentSize int64) (*EmailMessage, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("读取邮件失败: %w", err)
+	}
+
+	if maxAttachmentSize <= 0 {
+		maxAttachmentSize = DefaultMaxAttachmentSize
+	}
+
+	email := &EmailMessage{
+		MessageID:   msg.Header.Get("Message-ID"),
+		Subject:     decodeMIMEHeader(msg.Header.Get("Subject")),
+		From:        parseEmailAddresses(msg.Header.Get("From")),
+		To:          parseEmailAddresses(msg.Header.Get("To")),
+		CC:          parseEmailAddresses(msg.Header.Get("Cc")),
+		BCC:         parseEmailAddresses(msg.Header.Get("Bcc")),
+		Attachments: make([]EmailAttachment, 0),
+		Headers:     make(map[string]string),
+		Size:        int64(len(data)),
+		Metadata:    make(map[string]interface{}),
+	}
+
+	for key, values := range msg.Header {
+		if len(values) > 0 {
+			email.Headers[key] = decodeMIMEHeader(values[0])
+		}
+	}
+
+	if date, err := msg.Header.Date(); err == nil {
+		email.Date = date
+	}
+
+	header := textproto.MIMEHeader(msg.Header)
+	if err := parseMIMEPart(header, msg.Body, email, maxAttachmentSize, 0); err != nil {
+		return email, err
+	}
+
+	return email, nil
+}
+
+// parseMIMEPart 解析单个MIME部分，multipart部分递归处理
+func parseMIMEPart(header textproto.MIMEHeader, body io.Reader, email *EmailMessage, maxAttachmentSize int64, depth int) error {
+	if depth > maxMIMEDepth {
+		return fmt.Errorf("MIME嵌套层级超过限制: %d", maxMIMEDepth)
+	}
+
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "application/octet-stream"
+		params = map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		boundary := params["boundary"]
+		if boundary == "" {
+			return fmt.Errorf("multipart缺少boundary参数")
+		}
+
+		reader := multipart.NewReader(body, boundary)
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("读取MIME部分失败: %w", err)
+			}
+
+			err = parseMIMEPart(part.Header, part, email, maxAttachmentSize, depth+1)
+			part.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	decoded := decodeTransferEncoding(body, header.Get("Content-Transfer-Encoding"))
+
+	fileName := mimePartFileName(header, params)
+	disposition, _, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	isAttachment := disposition == "attachment" || fileName != "" ||
+		(!strings.HasPrefix(mediaType, "text/") && mediaType != "message/rfc822")
+
+	if !isAttachment {
+		content, err := io.ReadAll(decoded)
+		if err != nil {
+			return fmt.Errorf("读取邮件正文失败: %w", err)
+		}
+
+		if mediaType == "text/html" {
+			email.HTMLBody += string(content)
+		} else {
+			email.Body += string(content)
+		}
+		return nil
+	}
+
+	// 附件数据只保留前maxAttachmentSize字节，哈希和大小基于完整内容计算
+	hasher := sha256.New()
+	var buf bytes.Buffer
+	size, err := io.Copy(io.MultiWriter(hasher, &limitedWriter{w: &buf, n: maxAttachmentSize}), decoded)
+	if err != nil {
+		return fmt.Errorf("读取邮件附件失败: %w", err)
+	}
+
+	email.Attachments = append(email.Attachments, EmailAttachment{
+		Name:        fileName,
+		ContentType: mediaType,
+		Size:        size,
+		Data:        buf.Bytes(),
+		Hash:        hex.EncodeToString(hasher.Sum(nil)),
+		Truncated:   size > maxAttachmentSize,
+	})
+
+	return nil
+}
+
+// decodeTransferEncoding 根据Content-Transfer-Encoding解码内容
+func decodeTransferEncoding(r io.Reader, encoding string) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
+
+// mimePartFileName 获取MIME部分的文件名，优先使用Content-Disposition中的filename
+func mimePartFileName(header textproto.MIMEHeader, contentTypeParams map[string]string) string {
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		if name := params["filename"]; name != "" {
+			return filepath.Base(decodeMIMEHeader(name))
+		}
+	}
+
+	if name := contentTypeParams["name"]; name != "" {
+		return filepath.Base(decodeMIMEHeader(name))
+	}
+
+	return ""
+}
+
+// decodeMIMEHeader 解码RFC 2047编码的头部值，失败时返回原值
+func decodeMIMEHeader(value string) string {
+	decoded, err := mimeHeaderDecoder.DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+// parseEmailAddresses 解析邮件地址列表，无法解析时保留原始值
+func parseEmailAddresses(value string) []EmailAddress {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	list, err := mail.ParseAddressList(value)
+	if err != nil {
+		return []EmailAddress{{Address: strings.TrimSpace(value)}}
+	}
+
+	addresses := make([]EmailAddress, 0, len(list))
+	for _, addr := range list {
+		addresses = append(addresses, EmailAddress{
+			Name:    addr.Name,
+			Address: addr.Address,
+		})
+	}
+	return addresses
+}
+
+// isTextContent 判断附件内容是否可作为文本进行内容检测
+func isTextContent(contentType string, data []byte) bool {
+	if strings.HasPrefix(contentType, "text/") ||
+		contentType == "application/json" ||
+		contentType == "application/xml" ||
+		contentType == "application/csv" {
+		return true
+	}
+
+	if len(data) == 0 || !utf8.Valid(data) {
+		return false
+	}
+
+	// application/octet-stream 等类型只有在内容为纯文本时才视为文本
+	return bytes.IndexByte(data, 0) < 0
+}
+
+// limitedWriter 只写入前n个字节，超出部分被丢弃但仍报告写入成功
+type limitedWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write 实现io.Writer接口
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if lw.n > 0 {
+		chunk := p
+		if int64(len(chunk)) > lw.n {
+			chunk = chunk[:lw.n]
+		}
+		written, err := lw.w.Write(chunk)
+		lw.n -= int64(written)
+		if err != nil {
+			return written, err
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,129 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultParserPriorities 内置的协议检测优先级，数值越大越先检测
+// http 应在 https 之前检查，避免HTTP流量被误判为TLS
+var defaultParserPriorities = map[string]int{
+	"http":  100,
+	"https": 90,
+	"ftp":   80,
+	"smtp":  70,
+	"mysql": 60,
+}
+
+// ParserSettings 单个解析器的启用状态和检测优先级
+type ParserSettings struct {
+	Enabled  bool `yaml:"enabled" json:"enabled"`
+	Priority *int `yaml:"priority,omitempty" json:"priority,omitempty"` // 数值越大越先参与协议检测，未设置时使用内置优先级
+}
+
+// ParseParserSettings 解析配置中各解析器的启用状态和优先级，键为协议名称，未设置 enabled 时视为启用
+//
+//	parsers:
+//	  smb:
+//	    enabled: false
+//	  redis:
+//	    priority: 95
+func ParseParserSettings(settings map[string]interface{}) (map[string]ParserSettings, error) {
+	parsers := make(map[string]ParserSettings, len(settings))
+	for name, value := range settings {
+		item, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("解析器 %s 的配置必须是映射: %v", name, value)
+		}
+
+		parserSettings := ParserSettings{Enabled: true}
+		if enabled, exists := item["enabled"]; exists {
+			flag, ok := enabled.(bool)
+			if !ok {
+				return nil, fmt.Errorf("解析器 %s 的 enabled 必须是布尔值: %v", name, enabled)
+			}
+			parserSettings.Enabled = flag
+		}
+		if priority, exists := item["priority"]; exists {
+			value, ok := settingInt(priority)
+			if !ok {
+				return nil, fmt.Errorf("解析器 %s 的 priority 必须是整数: %v", name, priority)
+			}
+			parserSettings.Priority = &value
+		}
+		parsers[normalizeProtocolName(name)] = parserSettings
+	}
+
+	if err := ValidateParserSettings(parsers); err != nil {
+		return nil, err
+	}
+	return parsers, nil
+}
+
+// ValidateParserSettings 验证解析器配置：协议必须有内置解析器，默认解析器不能禁用
+func ValidateParserSettings(parsers map[string]ParserSettings) error {
+	creators := builtinParserCreators()
+	for name, settings := range parsers {
+		protocol := normalizeProtocolName(name)
+		if protocol == "" {
+			return fmt.Errorf("解析器名称不能为空")
+		}
+		if protocol == "default" || protocol == "unknown" {
+			if !settings.Enabled {
+				return fmt.Errorf("默认解析器不能禁用")
+			}
+			continue
+		}
+		if _, exists := creators[protocol]; !exists {
+			return fmt.Errorf("不支持的解析器: %s，支持的解析器: %s", name, strings.Join(supportedOverrideProtocols(creators), ", "))
+		}
+	}
+	return nil
+}
+
+// ParserEnabled 检查支持指定协议的解析器是否启用，任一协议被禁用时视为禁用
+// 默认解析器始终启用，用于兜底未识别的流量
+func (c ParserConfig) ParserEnabled(protocols ...string) bool {
+	for _, protocol := range protocols {
+		name := normalizeProtocolName(protocol)
+		if name == "default" || name == "unknown" {
+			return true
+		}
+		if settings, exists := c.Parsers[name]; exists && !settings.Enabled {
+			return false
+		}
+	}
+	return true
+}
+
+// ParserPriority 返回支持指定协议的解析器的检测优先级，配置优先于内置优先级
+func (c ParserConfig) ParserPriority(protocols ...string) int {
+	for _, protocol := range protocols {
+		if settings, exists := c.Parsers[normalizeProtocolName(protocol)]; exists && settings.Priority != nil {
+			return *settings.Priority
+		}
+	}
+	for _, protocol := range protocols {
+		if priority, exists := defaultParserPriorities[normalizeProtocolName(protocol)]; exists {
+			return priority
+		}
+	}
+	return 0
+}
+
+// settingInt 将配置中的数值转换为整数
+func settingInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v != float64(int(v)) {
+			return 0, false
+		}
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// probeRecorder 记录解析器被询问的顺序
+type probeRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *probeRecorder) record(protocol string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, protocol)
+}
+
+// probeParser 记录 CanParse 调用的测试解析器
+type probeParser struct {
+	stubParser
+	protocols []string
+	recorder  *probeRecorder
+}
+
+func newProbeParser(recorder *probeRecorder, canParse bool, protocols ...string) *probeParser {
+	return &probeParser{
+		stubParser: stubParser{protocol: protocols[0], canParse: canParse},
+		protocols:  protocols,
+		recorder:   recorder,
+	}
+}
+
+func (p *probeParser) GetParserInfo() ParserInfo {
+	return ParserInfo{Name: p.protocol, SupportedProtocols: p.protocols}
+}
+func (p *probeParser) GetSupportedProtocols() []string { return p.protocols }
+func (p *probeParser) CanParse(packet *interceptor.PacketInfo) bool {
+	p.recorder.record(p.protocol)
+	return p.canParse
+}
+
+func newSettingsTestPacket() *interceptor.PacketInfo {
+	return &interceptor.PacketInfo{
+		SourceIP:   net.ParseIP("192.168.1.10"),
+		DestIP:     net.ParseIP("10.0.0.5"),
+		SourcePort: 50000,
+		DestPort:   445,
+		Protocol:   interceptor.ProtocolTCP,
+		Payload:    []byte("payload"),
+	}
+}
+
+func TestParseParserSettings(t *testing.T) {
+	parsers, err := ParseParserSettings(map[string]interface{}{
+		"SMB":   map[string]interface{}{"enabled": false},
+		"redis": map[string]interface{}{"priority": 95},
+		"http":  map[string]interface{}{"enabled": true, "priority": float64(10)},
+	})
+	require.NoError(t, err)
+
+	assert.False(t, parsers["smb"].Enabled)
+	assert.Nil(t, parsers["smb"].Priority)
+	assert.True(t, parsers["redis"].Enabled, "未设置 enabled 时视为启用")
+	require.NotNil(t, parsers["redis"].Priority)
+	assert.Equal(t, 95, *parsers["redis"].Priority)
+	assert.Equal(t, 10, *parsers["http"].Priority)
+
+	tests := map[string]map[string]interface{}{
+		"不支持的解析器":     {"oracle": map[string]interface{}{"enabled": false}},
+		"禁用默认解析器":     {"default": map[string]interface{}{"enabled": false}},
+		"配置不是映射":      {"smb": false},
+		"enabled非布尔":  {"smb": map[string]interface{}{"enabled": "no"}},
+		"priority非整数": {"smb": map[string]interface{}{"priority": 1.5}},
+	}
+	for name, settings := range tests {
+		_, err := ParseParserSettings(settings)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParserConfig_EnabledAndPriority(t *testing.T) {
+	config := DefaultParserConfig()
+	assert.True(t, config.ParserEnabled("smb", "smb2"))
+	assert.Equal(t, 100, config.ParserPriority("http"))
+	assert.Equal(t, 0, config.ParserPriority("redis", "resp"))
+
+	priority := 120
+	config.Parsers = map[string]ParserSettings{
+		"cifs":  {Enabled: false},
+		"redis": {Enabled: true, Priority: &priority},
+	}
+	assert.False(t, config.ParserEnabled("smb", "smb2", "smb3", "cifs"), "任一协议被禁用时解析器视为禁用")
+	assert.True(t, config.ParserEnabled("unknown", "default"))
+	assert.Equal(t, 120, config.ParserPriority("redis", "resp"))
+}
+
+func TestProtocolManager_DisabledParsersNeverConsulted(t *testing.T) {
+	config := DefaultParserConfig()
+	config.Parsers = map[string]ParserSettings{"smb": {Enabled: false}}
+	config.PortOverrides = map[uint16]string{445: "smb"}
+	manager := NewProtocolManager(newTestLogger(t), config)
+
+	recorder := &probeRecorder{}
+	require.NoError(t, manager.RegisterParser(newProbeParser(recorder, true, "smb", "smb2", "smb3", "cifs")))
+	require.NoError(t, manager.RegisterParser(newProbeParser(recorder, false, "ldap")))
+	require.NoError(t, manager.RegisterParser(newProbeParser(recorder, false, "default")))
+
+	_, exists := manager.GetParser("smb2")
+	assert.False(t, exists, "禁用的解析器不应注册")
+	assert.NotContains(t, manager.GetSupportedProtocols(), "smb")
+
+	// 禁用的解析器即使配置了端口映射也不被询问，流量交给默认解析器
+	data, err := manager.ParsePacket(newSettingsTestPacket())
+	require.NoError(t, err)
+	assert.Equal(t, "default", data.Protocol)
+	assert.Equal(t, []string{"ldap"}, recorder.calls)
+}
+
+func TestProtocolManager_PriorityOrder(t *testing.T) {
+	redisPriority, httpPriority := 95, 50
+	config := DefaultParserConfig()
+	config.Parsers = map[string]ParserSettings{
+		"redis": {Enabled: true, Priority: &redisPriority},
+		"http":  {Enabled: true, Priority: &httpPriority},
+	}
+	manager := NewProtocolManager(newTestLogger(t), config).(*ProtocolManagerImpl)
+
+	recorder := &probeRecorder{}
+	for _, p := range []*probeParser{
+		newProbeParser(recorder, false, "ldap"),
+		newProbeParser(recorder, false, "http"),
+		newProbeParser(recorder, false, "https", "tls"),
+		newProbeParser(recorder, false, "redis", "resp"),
+		newProbeParser(recorder, true, "smb"),
+		newProbeParser(recorder, false, "mysql"),
+		newProbeParser(recorder, false, "default"),
+	} {
+		require.NoError(t, manager.RegisterParser(p))
+	}
+
+	// 配置的优先级覆盖内置优先级，优先级相同时按注册顺序，默认解析器不参与检测
+	want := []string{"redis", "https", "mysql", "http", "ldap", "smb"}
+	assert.Equal(t, want, manager.DetectionOrder())
+
+	data, err := manager.ParsePacket(newSettingsTestPacket())
+	require.NoError(t, err)
+	assert.Equal(t, "smb", data.Protocol)
+	assert.Equal(t, want, recorder.calls, "解析器按优先级顺序被询问")
+
+	// 命中高优先级解析器后不再询问后续解析器
+	recorder.calls = nil
+	manager.detection[0].parser.(*probeParser).canParse = true
+	data, err = manager.ParsePacket(newSettingsTestPacket())
+	require.NoError(t, err)
+	assert.Equal(t, "redis", data.Protocol)
+	assert.Equal(t, []string{"redis"}, recorder.calls)
+}
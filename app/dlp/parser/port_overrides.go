@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParsePortOverrides 解析配置中的端口到协议映射，键为端口号，值为协议名称
+//
+//	port_overrides:
+//	  "3307": mysql
+//	  "8081": http
+func ParsePortOverrides(settings map[string]interface{}) (map[uint16]string, error) {
+	overrides := make(map[uint16]string, len(settings))
+	for key, value := range settings {
+		port, err := strconv.ParseUint(strings.TrimSpace(key), 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("无效的端口: %s", key)
+		}
+		protocol, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("端口 %d 的协议必须是字符串: %v", port, value)
+		}
+		overrides[uint16(port)] = protocol
+	}
+
+	if err := ValidatePortOverrides(overrides); err != nil {
+		return nil, err
+	}
+	return normalizePortOverrides(overrides), nil
+}
+
+// ValidatePortOverrides 验证端口到协议的映射：端口不能为0，协议必须有内置解析器
+func ValidatePortOverrides(overrides map[uint16]string) error {
+	creators := builtinParserCreators()
+	for port, protocol := range overrides {
+		if port == 0 {
+			return fmt.Errorf("端口映射中的端口不能为0")
+		}
+		name := normalizeProtocolName(protocol)
+		if name == "" {
+			return fmt.Errorf("端口 %d 的协议不能为空", port)
+		}
+		if _, exists := creators[name]; !exists || name == "unknown" {
+			return fmt.Errorf("端口 %d 映射到不支持的协议: %s，支持的协议: %s", port, protocol, strings.Join(supportedOverrideProtocols(creators), ", "))
+		}
+	}
+	return nil
+}
+
+// normalizePortOverrides 复制端口映射并规范协议名称
+func normalizePortOverrides(overrides map[uint16]string) map[uint16]string {
+	normalized := make(map[uint16]string, len(overrides))
+	for port, protocol := range overrides {
+		normalized[port] = normalizeProtocolName(protocol)
+	}
+	return normalized
+}
+
+func normalizeProtocolName(protocol string) string {
+	return strings.ToLower(strings.TrimSpace(protocol))
+}
+
+// supportedOverrideProtocols 返回可用于端口映射的协议，按名称排序
+func supportedOverrideProtocols(creators map[string]ParserCreator) []string {
+	protocols := make([]string, 0, len(creators))
+	for protocol := range creators {
+		if protocol != "unknown" {
+			protocols = append(protocols, protocol)
+		}
+	}
+	sort.Strings(protocols)
+	return protocols
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"net"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mysqlQuery 构造MySQL COM_QUERY数据包，本身没有可识别的握手特征
+func mysqlQuery(query string) []byte {
+	payload := append([]byte{0x03}, query...)
+	n := len(payload)
+	return append([]byte{byte(n), byte(n >> 8), byte(n >> 16), 0x00}, payload...)
+}
+
+var (
+	httpRequest     = []byte("GET /index.html HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	tlsClientHello  = []byte{0x16, 0x03, 0x01, 0x00, 0x2f, 0x01, 0x00, 0x00, 0x2b, 0x03, 0x03}
+	mysqlSelectStar = mysqlQuery("SELECT * FROM customers")
+)
+
+func TestProtocolDetector_PortOverrides(t *testing.T) {
+	detector := NewProtocolDetector(newTestLogger(t))
+	assert.NotEqual(t, "mysql", detector.DetectProtocol(mysqlSelectStar, 3307), "非标准端口上的MySQL查询无法通过特征识别")
+
+	require.NoError(t, detector.SetPortOverrides(map[uint16]string{
+		3307: "mysql",
+		3306: " PostgreSQL ",
+	}))
+
+	assert.Equal(t, "mysql", detector.DetectProtocol(mysqlSelectStar, 3307))
+	assert.Equal(t, "postgresql", detector.DetectProtocol(mysqlSelectStar, 3306), "端口映射优先于标准端口")
+	assert.Equal(t, "mysql", detector.DetectProtocol(httpRequest, 3307), "端口映射优先于内容特征")
+
+	// 未映射的端口仍然使用特征检测
+	assert.Equal(t, "http", detector.DetectProtocol(httpRequest, 8081))
+	assert.Equal(t, "https", detector.DetectProtocol(tlsClientHello, 443))
+	assert.Equal(t, "redis", detector.DetectProtocol([]byte{0x00, 0x01, 0x02, 0x03}, 6379))
+	assert.Equal(t, "unknown", detector.DetectProtocol(nil, 3307))
+
+	// 无效映射被拒绝且保留原有映射
+	assert.Error(t, detector.SetPortOverrides(map[uint16]string{3308: "oracle"}))
+	assert.Equal(t, "mysql", detector.DetectProtocol(mysqlSelectStar, 3307))
+}
+
+func TestParsePortOverrides(t *testing.T) {
+	overrides, err := ParsePortOverrides(map[string]interface{}{
+		"3307": "mysql",
+		"8081": "HTTP",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[uint16]string{3307: "mysql", 8081: "http"}, overrides)
+
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		errMsg   string
+	}{
+		{"端口不是数字", map[string]interface{}{"mysql": "mysql"}, "无效的端口"},
+		{"端口超出范围", map[string]interface{}{"70000": "mysql"}, "无效的端口"},
+		{"端口为0", map[string]interface{}{"0": "mysql"}, "不能为0"},
+		{"协议不是字符串", map[string]interface{}{"3307": 1}, "必须是字符串"},
+		{"协议为空", map[string]interface{}{"3307": " "}, "不能为空"},
+		{"不支持的协议", map[string]interface{}{"1521": "oracle"}, "不支持的协议"},
+		{"unknown不是有效协议", map[string]interface{}{"1521": "unknown"}, "不支持的协议"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePortOverrides(tt.settings)
+			assert.ErrorContains(t, err, tt.errMsg)
+		})
+	}
+}
+
+// stubParser 按固定结果响应的测试解析器
+type stubParser struct {
+	protocol string
+	canParse bool
+}
+
+func (s *stubParser) GetParserInfo() ParserInfo {
+	return ParserInfo{Name: s.protocol, SupportedProtocols: []string{s.protocol}}
+}
+func (s *stubParser) CanParse(packet *interceptor.PacketInfo) bool { return s.canParse }
+func (s *stubParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error) {
+	return &ParsedData{Protocol: s.protocol}, nil
+}
+func (s *stubParser) GetSupportedProtocols() []string     { return []string{s.protocol} }
+func (s *stubParser) Initialize(config ParserConfig) error { return nil }
+func (s *stubParser) Cleanup() error                       { return nil }
+
+func TestProtocolManager_PortOverrides(t *testing.T) {
+	config := DefaultParserConfig()
+	config.PortOverrides = map[uint16]string{3307: "mysql"}
+	manager := NewProtocolManager(newTestLogger(t), config)
+	require.NoError(t, manager.RegisterParser(&stubParser{protocol: "http", canParse: true}))
+	require.NoError(t, manager.RegisterParser(&stubParser{protocol: "mysql"}))
+	require.NoError(t, manager.RegisterParser(&stubParser{protocol: "default"}))
+
+	packet := func(srcPort, dstPort uint16) *interceptor.PacketInfo {
+		return &interceptor.PacketInfo{
+			SourceIP:   net.ParseIP("192.168.1.10"),
+			DestIP:     net.ParseIP("10.0.0.5"),
+			SourcePort: srcPort,
+			DestPort:   dstPort,
+			Protocol:   interceptor.ProtocolTCP,
+			Payload:    mysqlSelectStar,
+		}
+	}
+
+	// 映射端口上的流量交给映射的解析器，响应方向按源端口匹配
+	data, err := manager.ParsePacket(packet(50000, 3307))
+	require.NoError(t, err)
+	assert.Equal(t, "mysql", data.Protocol)
+	data, err = manager.ParsePacket(packet(3307, 50000))
+	require.NoError(t, err)
+	assert.Equal(t, "mysql", data.Protocol)
+
+	// 未映射的端口仍然按特征选择解析器
+	data, err = manager.ParsePacket(packet(50000, 3308))
+	require.NoError(t, err)
+	assert.Equal(t, "http", data.Protocol)
+}
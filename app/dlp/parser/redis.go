@@ -0,0 +1,567 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// Redis默认端口
+const RedisDefaultPort = 6379
+
+// RESP数据类型前缀
+const (
+	RESPSimpleString = '+'
+	RESPError        = '-'
+	RESPInteger      = ':'
+	RESPBulkString   = '$'
+	RESPArray        = '*'
+)
+
+const (
+	// redisMaxArrayLen 单个请求允许的最大参数个数
+	redisMaxArrayLen = 1024 * 1024
+	// redisMaxDepth 嵌套数组的最大深度
+	redisMaxDepth = 8
+)
+
+// redisCommands 常见Redis命令，用于识别RESP请求
+var redisCommands = map[string]bool{
+	"GET": true, "SET": true, "SETEX": true, "PSETEX": true, "SETNX": true, "GETSET": true,
+	"GETDEL": true, "GETEX": true, "MGET": true, "MSET": true, "MSETNX": true, "APPEND": true,
+	"STRLEN": true, "INCR": true, "INCRBY": true, "DECR": true, "DECRBY": true, "DEL": true,
+	"UNLINK": true, "EXISTS": true, "EXPIRE": true, "PEXPIRE": true, "TTL": true, "PTTL": true,
+	"TYPE": true, "KEYS": true, "SCAN": true, "RENAME": true, "DUMP": true, "RESTORE": true,
+	"HGET": true, "HSET": true, "HSETNX": true, "HMSET": true, "HMGET": true, "HGETALL": true,
+	"HDEL": true, "HKEYS": true, "HVALS": true, "HSCAN": true,
+	"LPUSH": true, "RPUSH": true, "LPOP": true, "RPOP": true, "LRANGE": true, "LSET": true, "LINDEX": true,
+	"SADD": true, "SREM": true, "SMEMBERS": true, "SISMEMBER": true, "SSCAN": true,
+	"ZADD": true, "ZREM": true, "ZRANGE": true, "ZSCORE": true, "ZSCAN": true,
+	"XADD": true, "XREAD": true, "XRANGE": true,
+	"PUBLISH": true, "SUBSCRIBE": true, "PSUBSCRIBE": true, "UNSUBSCRIBE": true,
+	"EVAL": true, "EVALSHA": true, "MULTI": true, "EXEC": true, "DISCARD": true, "WATCH": true,
+	"AUTH": true, "HELLO": true, "SELECT": true, "PING": true, "ECHO": true, "QUIT": true,
+	"INFO": true, "CONFIG": true, "CLIENT": true, "COMMAND": true, "DBSIZE": true,
+	"FLUSHDB": true, "FLUSHALL": true, "MIGRATE": true, "MONITOR": true, "SAVE": true, "BGSAVE": true,
+}
+
+// RESPValue RESP协议值
+type RESPValue struct {
+	Type     byte
+	Str      []byte
+	Int      int64
+	Array    []RESPValue
+	Null     bool
+	Size     int  // 声明的长度（批量字符串）
+	Truncate bool // 数据包被截断，Str只包含部分内容
+}
+
+// RedisCommand Redis请求命令
+type RedisCommand struct {
+	Command   string
+	Args      [][]byte
+	Key       string
+	Keys      []string
+	Values    [][]byte
+	ValueSize int
+	Inline    bool
+}
+
+// RedisParser Redis协议解析器
+type RedisParser struct {
+	logger       logging.Logger
+	maxValueSize int
+	timeout      time.Duration
+}
+
+// NewRedisParser 创建Redis解析器
+func NewRedisParser(logger logging.Logger) *RedisParser {
+	parser := &RedisParser{
+		logger:       logger,
+		maxValueSize: 1024 * 1024, // 1MB
+		timeout:      30 * time.Second,
+	}
+
+	parser.logger.Info("初始化Redis解析器",
+		"max_value_size", parser.maxValueSize,
+		"timeout", parser.timeout)
+
+	return parser
+}
+
+// GetParserInfo 获取解析器信息
+func (p *RedisParser) GetParserInfo() ParserInfo {
+	return ParserInfo{
+		Name:               "Redis Parser",
+		Version:            "1.0.0",
+		Description:        "Redis RESP协议解析器",
+		SupportedProtocols: []string{"redis", "resp"},
+		Author:             "DLP Team",
+		License:            "MIT",
+	}
+}
+
+// GetSupportedProtocols 获取支持的协议
+func (p *RedisParser) GetSupportedProtocols() []string {
+	return []string{"redis", "resp"}
+}
+
+// CanParse 检查是否可以解析数据
+func (p *RedisParser) CanParse(packet *interceptor.PacketInfo) bool {
+	data := packet.Payload
+	if len(data) < 3 {
+		return false
+	}
+
+	isRedisPort := packet.DestPort == RedisDefaultPort || packet.SourcePort == RedisDefaultPort
+
+	switch data[0] {
+	case RESPArray:
+		// 多批量请求：*<n>\r\n$<len>\r\n<command>\r\n
+		cmd, err := p.parseCommand(data)
+		return err == nil && (redisCommands[cmd.Command] || isRedisPort)
+	case RESPBulkString, RESPSimpleString, RESPError, RESPInteger:
+		// 服务端响应只在Redis端口上识别，避免误判
+		if !isRedisPort {
+			return false
+		}
+		_, _, err := p.parseValue(data, 0, 0)
+		return err == nil
+	default:
+		// 内联命令没有明显的帧特征，只在Redis端口上识别
+		if !isRedisPort {
+			return false
+		}
+		cmd, err := p.parseInlineCommand(data)
+		return err == nil && redisCommands[cmd.Command]
+	}
+}
+
+// Initialize 初始化解析器
+func (p *RedisParser) Initialize(config ParserConfig) error {
+	if config.MaxBodySize > 0 {
+		p.maxValueSize = int(config.MaxBodySize)
+	}
+	if config.Timeout > 0 {
+		p.timeout = config.Timeout
+	}
+	p.logger.Info("初始化Redis解析器", "config", config)
+	return nil
+}
+
+// Parse 解析Redis数据包
+func (p *RedisParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error) {
+	startTime := time.Now()
+	defer func() {
+		duration := time.Since(startTime)
+		if duration > p.timeout {
+			p.logger.Warn("Redis解析超时", "duration", duration)
+		}
+	}()
+
+	data := packet.Payload
+	if len(data) == 0 {
+		return nil, fmt.Errorf("数据包为空")
+	}
+
+	result := &ParsedData{
+		Protocol:    "redis",
+		ContentType: "application/redis",
+		Headers:     make(map[string]string),
+		Metadata:    make(map[string]interface{}),
+	}
+
+	isResponse := packet.SourcePort == RedisDefaultPort && packet.DestPort != RedisDefaultPort
+	if isResponse || (data[0] != RESPArray && p.isRESPType(data[0])) {
+		return p.parseResponse(data, result)
+	}
+
+	commands, err := p.parseCommands(data)
+	if err != nil {
+		return nil, fmt.Errorf("解析Redis命令失败: %w", err)
+	}
+
+	cmd := commands[0]
+	names := make([]string, 0, len(commands))
+	var body bytes.Buffer
+	totalValueSize := 0
+	for _, c := range commands {
+		names = append(names, c.Command)
+		totalValueSize += c.ValueSize
+		// AUTH/HELLO 的参数包含凭据，不参与内容检测
+		if c.Command == "AUTH" || c.Command == "HELLO" {
+			continue
+		}
+		for _, value := range c.Values {
+			if body.Len()+len(value) > p.maxValueSize {
+				break
+			}
+			body.Write(value)
+			body.WriteByte('\n')
+		}
+	}
+
+	result.Body = body.Bytes()
+	result.Method = cmd.Command
+	result.Metadata["redis_type"] = "request"
+	result.Metadata["redis_command"] = cmd.Command
+	result.Metadata["redis_key"] = cmd.Key
+	result.Metadata["redis_keys"] = cmd.Keys
+	result.Metadata["redis_value_size"] = cmd.ValueSize
+	result.Metadata["redis_arg_count"] = len(cmd.Args)
+	result.Metadata["redis_inline"] = cmd.Inline
+	result.Metadata["redis_commands"] = names
+	result.Metadata["redis_total_value_size"] = totalValueSize
+
+	result.Headers["Command"] = cmd.Command
+	if cmd.Key != "" {
+		result.Headers["Key"] = cmd.Key
+	}
+
+	p.logger.Debug("Redis命令解析",
+		"command", cmd.Command,
+		"key", cmd.Key,
+		"value_size", cmd.ValueSize,
+		"pipelined", len(commands))
+
+	return result, nil
+}
+
+// parseResponse 解析服务端响应
+func (p *RedisParser) parseResponse(data []byte, result *ParsedData) (*ParsedData, error) {
+	value, _, err := p.parseValue(data, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("解析Redis响应失败: %w", err)
+	}
+
+	var body bytes.Buffer
+	valueSize := p.collectValue(value, &body)
+
+	result.Body = body.Bytes()
+	result.Metadata["redis_type"] = "response"
+	result.Metadata["redis_resp_type"] = string(value.Type)
+	result.Metadata["redis_value_size"] = valueSize
+	result.Metadata["redis_null"] = value.Null
+	result.Metadata["redis_truncated"] = value.Truncate
+	if value.Type == RESPError {
+		result.Metadata["redis_error"] = string(value.Str)
+	}
+
+	return result, nil
+}
+
+// collectValue 汇总响应中的数据，返回数据总大小
+func (p *RedisParser) collectValue(value RESPValue, body *bytes.Buffer) int {
+	switch value.Type {
+	case RESPArray:
+		size := 0
+		for _, item := range value.Array {
+			size += p.collectValue(item, body)
+		}
+		return size
+	case RESPBulkString, RESPSimpleString:
+		if body.Len()+len(value.Str) <= p.maxValueSize {
+			body.Write(value.Str)
+			body.WriteByte('\n')
+		}
+		if value.Type == RESPBulkString && !value.Null {
+			return value.Size
+		}
+		return len(value.Str)
+	default:
+		return 0
+	}
+}
+
+// parseCommands 解析一个数据包中的所有命令（支持管道）
+func (p *RedisParser) parseCommands(data []byte) ([]*RedisCommand, error) {
+	var commands []*RedisCommand
+
+	for pos := 0; pos < len(data); {
+		var (
+			cmd  *RedisCommand
+			next int
+			err  error
+		)
+
+		if data[pos] == RESPArray {
+			var value RESPValue
+			value, next, err = p.parseValue(data, pos, 0)
+			if err == nil {
+				cmd, err = p.commandFromValue(value)
+			}
+		} else {
+			end := bytes.Index(data[pos:], []byte("\r\n"))
+			if end == -1 {
+				next = len(data)
+			} else {
+				next = pos + end + 2
+			}
+			cmd, err = p.parseInlineCommand(data[pos:next])
+		}
+
+		if err != nil {
+			if len(commands) > 0 {
+				// 管道中后续命令不完整时保留已解析的命令
+				break
+			}
+			return nil, err
+		}
+
+		commands = append(commands, cmd)
+		pos = next
+	}
+
+	if len(commands) == 0 {
+		return nil, fmt.Errorf("未找到Redis命令")
+	}
+	return commands, nil
+}
+
+// parseCommand 解析第一个多批量请求
+func (p *RedisParser) parseCommand(data []byte) (*RedisCommand, error) {
+	value, _, err := p.parseValue(data, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	return p.commandFromValue(value)
+}
+
+// commandFromValue 将RESP数组转换为命令
+func (p *RedisParser) commandFromValue(value RESPValue) (*RedisCommand, error) {
+	if value.Type != RESPArray || value.Null || len(value.Array) == 0 {
+		return nil, fmt.Errorf("Redis请求必须是非空数组")
+	}
+
+	args := make([][]byte, 0, len(value.Array))
+	sizes := make([]int, 0, len(value.Array))
+	for _, item := range value.Array {
+		if item.Type != RESPBulkString || item.Null {
+			return nil, fmt.Errorf("Redis请求参数必须是批量字符串")
+		}
+		args = append(args, item.Str)
+		sizes = append(sizes, item.Size)
+	}
+
+	return p.buildCommand(args, sizes, false), nil
+}
+
+// parseInlineCommand 解析内联命令，如 "SET key value\r\n"
+func (p *RedisParser) parseInlineCommand(data []byte) (*RedisCommand, error) {
+	line := data
+	if end := bytes.Index(line, []byte("\r\n")); end != -1 {
+		line = line[:end]
+	} else if end := bytes.IndexByte(line, '\n'); end != -1 {
+		line = line[:end]
+	}
+
+	for _, b := range line {
+		if b < 0x20 && b != '\t' {
+			return nil, fmt.Errorf("内联命令包含控制字符")
+		}
+	}
+
+	fields := bytes.Fields(line)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("内联命令为空")
+	}
+
+	sizes := make([]int, len(fields))
+	for i, field := range fields {
+		sizes[i] = len(field)
+	}
+
+	return p.buildCommand(fields, sizes, true), nil
+}
+
+// buildCommand 根据参数提取命令、键和值
+func (p *RedisParser) buildCommand(args [][]byte, sizes []int, inline bool) *RedisCommand {
+	cmd := &RedisCommand{
+		Command: strings.ToUpper(string(args[0])),
+		Args:    args[1:],
+		Inline:  inline,
+	}
+	argSizes := sizes[1:]
+
+	// addValue 将指定位置的参数记为值
+	addValue := func(i int) {
+		if i < len(cmd.Args) {
+			cmd.Values = append(cmd.Values, cmd.Args[i])
+			cmd.ValueSize += argSizes[i]
+		}
+	}
+
+	switch cmd.Command {
+	case "SET", "SETNX", "GETSET", "APPEND":
+		// 命令 键 值
+		addValue(1)
+	case "LSET":
+		// 命令 键 索引 值
+		addValue(2)
+	case "LPUSH", "RPUSH", "SADD", "PUBLISH":
+		// 命令 键 值 [值 ...]
+		for i := 1; i < len(cmd.Args); i++ {
+			addValue(i)
+		}
+	case "SETEX", "PSETEX":
+		// 命令 键 过期时间 值
+		addValue(2)
+	case "HSET", "HSETNX", "HMSET":
+		// 命令 键 字段 值 [字段 值 ...]
+		for i := 2; i < len(cmd.Args); i += 2 {
+			addValue(i)
+		}
+	case "MSET", "MSETNX":
+		// 命令 键 值 [键 值 ...]
+		for i := 0; i+1 < len(cmd.Args); i += 2 {
+			cmd.Keys = append(cmd.Keys, string(cmd.Args[i]))
+			addValue(i + 1)
+		}
+	case "XADD":
+		// 命令 键 ID 字段 值 [字段 值 ...]
+		for i := 3; i < len(cmd.Args); i += 2 {
+			addValue(i)
+		}
+	case "MGET", "DEL", "UNLINK", "EXISTS":
+		for _, arg := range cmd.Args {
+			cmd.Keys = append(cmd.Keys, string(arg))
+		}
+	}
+
+	switch cmd.Command {
+	case "AUTH", "HELLO", "PING", "ECHO", "QUIT", "INFO", "CONFIG", "CLIENT", "COMMAND", "DBSIZE",
+		"FLUSHDB", "FLUSHALL", "MULTI", "EXEC", "DISCARD", "SAVE", "BGSAVE", "MONITOR", "SELECT",
+		"KEYS", "SCAN", "EVAL", "EVALSHA", "SUBSCRIBE", "PSUBSCRIBE", "UNSUBSCRIBE":
+		// 这些命令的第一个参数不是键
+	default:
+		if len(cmd.Args) > 0 {
+			cmd.Key = string(cmd.Args[0])
+		}
+	}
+
+	if len(cmd.Keys) == 0 && cmd.Key != "" {
+		cmd.Keys = []string{cmd.Key}
+	}
+
+	return cmd
+}
+
+// parseValue 从pos开始解析一个RESP值，返回值和下一个值的位置
+// 批量字符串超出数据包范围时按截断处理，Size保留声明的长度
+func (p *RedisParser) parseValue(data []byte, pos, depth int) (RESPValue, int, error) {
+	if depth > redisMaxDepth {
+		return RESPValue{}, 0, fmt.Errorf("RESP嵌套过深")
+	}
+	if pos >= len(data) {
+		return RESPValue{}, 0, fmt.Errorf("数据不完整")
+	}
+
+	value := RESPValue{Type: data[pos]}
+	line, next, err := p.readLine(data, pos+1)
+	if err != nil {
+		return RESPValue{}, 0, err
+	}
+
+	switch value.Type {
+	case RESPSimpleString, RESPError:
+		value.Str = line
+		return value, next, nil
+
+	case RESPInteger:
+		n, err := strconv.ParseInt(string(line), 10, 64)
+		if err != nil {
+			return RESPValue{}, 0, fmt.Errorf("无效的RESP整数: %w", err)
+		}
+		value.Int = n
+		return value, next, nil
+
+	case RESPBulkString:
+		n, err := strconv.Atoi(string(line))
+		if err != nil || n < -1 {
+			return RESPValue{}, 0, fmt.Errorf("无效的批量字符串长度: %q", line)
+		}
+		if n == -1 {
+			value.Null = true
+			return value, next, nil
+		}
+		value.Size = n
+		end := next + n
+		if end+2 > len(data) {
+			// 数据包在批量字符串内部被截断
+			if end > len(data) {
+				end = len(data)
+				value.Truncate = true
+			}
+			value.Str = data[next:end]
+			return value, len(data), nil
+		}
+		if data[end] != '\r' || data[end+1] != '\n' {
+			return RESPValue{}, 0, fmt.Errorf("批量字符串缺少结束符")
+		}
+		value.Str = data[next:end]
+		return value, end + 2, nil
+
+	case RESPArray:
+		n, err := strconv.Atoi(string(line))
+		if err != nil || n < -1 || n > redisMaxArrayLen {
+			return RESPValue{}, 0, fmt.Errorf("无效的数组长度: %q", line)
+		}
+		if n == -1 {
+			value.Null = true
+			return value, next, nil
+		}
+		value.Array = make([]RESPValue, 0, n)
+		for i := 0; i < n; i++ {
+			if next >= len(data) {
+				value.Truncate = true
+				break
+			}
+			item, itemNext, err := p.parseValue(data, next, depth+1)
+			if err != nil {
+				return RESPValue{}, 0, err
+			}
+			value.Array = append(value.Array, item)
+			next = itemNext
+			if item.Truncate {
+				value.Truncate = true
+				break
+			}
+		}
+		if len(value.Array) == 0 && n > 0 {
+			return RESPValue{}, 0, fmt.Errorf("数据不完整")
+		}
+		return value, next, nil
+
+	default:
+		return RESPValue{}, 0, fmt.Errorf("未知的RESP类型: %q", value.Type)
+	}
+}
+
+// readLine 读取以CRLF结尾的行
+func (p *RedisParser) readLine(data []byte, pos int) ([]byte, int, error) {
+	end := bytes.Index(data[pos:], []byte("\r\n"))
+	if end == -1 {
+		return nil, 0, fmt.Errorf("缺少CRLF")
+	}
+	return data[pos : pos+end], pos + end + 2, nil
+}
+
+// isRESPType 检查是否为RESP类型前缀
+func (p *RedisParser) isRESPType(b byte) bool {
+	switch b {
+	case RESPSimpleString, RESPError, RESPInteger, RESPBulkString, RESPArray:
+		return true
+	}
+	return false
+}
+
+// Cleanup 清理资源
+func (p *RedisParser) Cleanup() error {
+	p.logger.Info("清理Redis解析器资源")
+	return nil
+}
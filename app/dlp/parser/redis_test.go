@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRedisPacket(payload string, srcPort, dstPort uint16) *interceptor.PacketInfo {
+	return &interceptor.PacketInfo{
+		SourceIP:   net.ParseIP("192.168.1.10"),
+		DestIP:     net.ParseIP("10.0.0.5"),
+		SourcePort: srcPort,
+		DestPort:   dstPort,
+		Payload:    []byte(payload),
+		Size:       len(payload),
+	}
+}
+
+func TestRedisParser_MultiBulkSet(t *testing.T) {
+	p := NewRedisParser(newTestLogger(t))
+
+	value := "4111111111111111"
+	frame := "*3\r\n$3\r\nSET\r\n$13\r\nuser:1001:card\r\n$16\r\n" + value + "\r\n"
+	// 故意写错键长度，验证格式错误会被拒绝
+	assert.False(t, p.CanParse(newRedisPacket(frame, 50000, 6380)))
+
+	frame = "*3\r\n$3\r\nSET\r\n$14\r\nuser:1001:card\r\n$16\r\n" + value + "\r\n"
+	packet := newRedisPacket(frame, 50000, 6380) // 非默认端口也应通过RESP帧识别
+	require.True(t, p.CanParse(packet))
+
+	result, err := p.Parse(packet)
+	require.NoError(t, err)
+
+	assert.Equal(t, "redis", result.Protocol)
+	assert.Equal(t, "request", result.Metadata["redis_type"])
+	assert.Equal(t, "SET", result.Metadata["redis_command"])
+	assert.Equal(t, "user:1001:card", result.Metadata["redis_key"])
+	assert.Equal(t, len(value), result.Metadata["redis_value_size"])
+	assert.Equal(t, false, result.Metadata["redis_inline"])
+	assert.Contains(t, string(result.Body), value)
+}
+
+func TestRedisParser_PipelinedGetAndResponse(t *testing.T) {
+	p := NewRedisParser(newTestLogger(t))
+
+	frame := "*2\r\n$3\r\nget\r\n$7\r\nsession\r\n*2\r\n$3\r\nDEL\r\n$3\r\nold\r\n"
+	packet := newRedisPacket(frame, 50000, 6379)
+	require.True(t, p.CanParse(packet))
+
+	result, err := p.Parse(packet)
+	require.NoError(t, err)
+	assert.Equal(t, "GET", result.Metadata["redis_command"])
+	assert.Equal(t, "session", result.Metadata["redis_key"])
+	assert.Equal(t, 0, result.Metadata["redis_value_size"])
+	assert.Equal(t, []string{"GET", "DEL"}, result.Metadata["redis_commands"])
+
+	// 服务端返回的批量字符串被截断时仍报告声明的大小
+	reply := "$1000\r\n" + strings.Repeat("x", 100)
+	response := newRedisPacket(reply, 6379, 50000)
+	require.True(t, p.CanParse(response))
+
+	result, err = p.Parse(response)
+	require.NoError(t, err)
+	assert.Equal(t, "response", result.Metadata["redis_type"])
+	assert.Equal(t, 1000, result.Metadata["redis_value_size"])
+	assert.Equal(t, true, result.Metadata["redis_truncated"])
+}
+
+func TestRedisParser_InlineCommand(t *testing.T) {
+	p := NewRedisParser(newTestLogger(t))
+
+	packet := newRedisPacket("SET token abc123\r\n", 50000, 6379)
+	require.True(t, p.CanParse(packet))
+
+	result, err := p.Parse(packet)
+	require.NoError(t, err)
+	assert.Equal(t, "SET", result.Metadata["redis_command"])
+	assert.Equal(t, "token", result.Metadata["redis_key"])
+	assert.Equal(t, 6, result.Metadata["redis_value_size"])
+	assert.Equal(t, true, result.Metadata["redis_inline"])
+
+	// 凭据不应进入检测内容
+	auth := newRedisPacket("*2\r\n$4\r\nAUTH\r\n$6\r\nsecret\r\n", 50000, 6379)
+	result, err = p.Parse(auth)
+	require.NoError(t, err)
+	assert.Equal(t, "AUTH", result.Metadata["redis_command"])
+	assert.Equal(t, "", result.Metadata["redis_key"])
+	assert.NotContains(t, string(result.Body), "secret")
+}
+
+func TestRedisParser_RejectsNonRESP(t *testing.T) {
+	p := NewRedisParser(newTestLogger(t))
+
+	httpRequest := "GET /index.html HTTP/1.1\r\nHost: example.com\r\n\r\n"
+	assert.False(t, p.CanParse(newRedisPacket(httpRequest, 50000, 80)))
+	assert.False(t, p.CanParse(newRedisPacket("*not a frame\r\n", 50000, 80)))
+	assert.False(t, p.CanParse(newRedisPacket("\x16\x03\x01\x02\x00\x01\x00\x01\xfc", 50000, 6379)))
+	assert.False(t, p.CanParse(newRedisPacket("+OK\r\n", 50000, 8080)))
+
+	_, err := p.Parse(newRedisPacket("*2\r\n:1\r\n:2\r\n", 50000, 6379))
+	assert.Error(t, err)
+}
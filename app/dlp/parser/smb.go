@@ -20,6 +20,10 @@ type SMBParser struct {
 	enableFileLog     bool
 	sensitivePatterns []*regexp.Regexp
 	fileExtensions    map[string]bool
+
+	// SMB2文件操作解码
+	maxWriteSize int64        // 每个WRITE请求保留的最大写入字节数
+	tracker      *smb2Tracker // 文件句柄到路径的映射
 }
 
 // SMB协议常量
@@ -58,6 +62,15 @@ type SMBPacket struct {
 	Operation   string
 	FileSize    uint64
 	Sensitive   bool
+
+	// SMB2文件操作
+	Path      string   // 共享内的文件路径
+	FileID    string   // 十六进制文件句柄
+	Offset    uint64   // 读写的文件偏移
+	Length    uint32   // 请求读写的字节数
+	Truncated bool     // 写入数据超过上限或数据包不完整而被截断
+	Response  bool     // 是否为服务端响应
+	Commands  []string // 复合请求中的全部操作
 }
 
 // NewSMBParser 创建SMB解析器
@@ -80,6 +93,8 @@ func NewSMBParser(logger logging.Logger) *SMBParser {
 			".pem": true, ".p12": true, ".pfx": true, ".crt": true,
 			".cer": true, ".zip": true, ".rar": true, ".7z": true,
 		},
+		maxWriteSize: DefaultMaxSMBWriteSize,
+		tracker:      newSMB2Tracker(maxSMB2TrackedEntries),
 	}
 
 	parser.logger.Info("初始化SMB解析器",
@@ -125,6 +140,13 @@ func (s *SMBParser) CanParse(packet *interceptor.PacketInfo) bool {
 // Initialize 初始化解析器
 func (s *SMBParser) Initialize(config ParserConfig) error {
 	s.logger.Info("初始化SMB解析器", "config", config)
+	if config.MaxSMBWriteSize > 0 {
+		s.maxWriteSize = config.MaxSMBWriteSize
+	}
+	// 数据包按 maxDataSize 截断，需能容纳完整的写入数据
+	if limit := s.maxWriteSize + smb2HeaderSize*2; int64(s.maxDataSize) < limit {
+		s.maxDataSize = int(limit)
+	}
 	return nil
 }
 
@@ -144,7 +166,7 @@ func (s *SMBParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error) {
 		data = data[:s.maxDataSize]
 	}
 
-	smbPacket, err := s.parsePacket(data)
+	smbPacket, err := s.parsePacket(data, smbConnectionKey(packet))
 	if err != nil {
 		return nil, fmt.Errorf("解析SMB数据包失败: %w", err)
 	}
@@ -167,6 +189,16 @@ func (s *SMBParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error) {
 	result.Metadata["sensitive"] = smbPacket.Sensitive
 	result.Metadata["tree_id"] = smbPacket.TreeID
 	result.Metadata["user_id"] = smbPacket.UserID
+	if smbPacket.Protocol == "smb2" {
+		result.Metadata["path"] = smbPacket.Path
+		result.Metadata["file_id"] = smbPacket.FileID
+		result.Metadata["offset"] = smbPacket.Offset
+		result.Metadata["length"] = smbPacket.Length
+		result.Metadata["write_size"] = len(smbPacket.Data)
+		result.Metadata["write_truncated"] = smbPacket.Truncated
+		result.Metadata["response"] = smbPacket.Response
+		result.Metadata["commands"] = smbPacket.Commands
+	}
 
 	// 填充头部信息
 	result.Headers["SMB-Command"] = fmt.Sprintf("0x%02X", smbPacket.Command)
@@ -174,6 +206,9 @@ func (s *SMBParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error) {
 	if smbPacket.Filename != "" {
 		result.Headers["SMB-Filename"] = smbPacket.Filename
 	}
+	if smbPacket.Path != "" {
+		result.Headers["SMB-Path"] = smbPacket.Path
+	}
 	if smbPacket.ShareName != "" {
 		result.Headers["SMB-Share"] = smbPacket.ShareName
 	}
@@ -195,16 +230,23 @@ func (s *SMBParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error) {
 			"operation", smbPacket.Operation,
 			"filename", smbPacket.Filename,
 			"share", smbPacket.ShareName,
-			"size", smbPacket.FileSize)
+			"path", smbPacket.Path,
+			"size", smbPacket.FileSize,
+			"write_size", len(smbPacket.Data))
 	}
 
 	return result, nil
 }
 
-// parsePacket 解析SMB数据包
-func (s *SMBParser) parsePacket(data []byte) (*SMBPacket, error) {
+// parsePacket 解析SMB数据包，conn 标识所属的连接，用于关联同一连接上的SMB2请求
+func (s *SMBParser) parsePacket(data []byte, conn string) (*SMBPacket, error) {
 	packet := &SMBPacket{}
 
+	// 跳过TCP 445端口上的NetBIOS会话头 (4字节)
+	if len(data) > 8 && data[0] == 0 && s.isSMBProtocol(data[4:]) {
+		data = data[4:]
+	}
+
 	if len(data) < 32 {
 		return nil, fmt.Errorf("SMB数据包太短")
 	}
@@ -215,7 +257,7 @@ func (s *SMBParser) parsePacket(data []byte) (*SMBPacket, error) {
 		return s.parseSMB1Packet(data, packet)
 	} else if bytes.HasPrefix(data, []byte(SMB2ProtocolID)) {
 		packet.Protocol = "smb2"
-		return s.parseSMB2Packet(data, packet)
+		return s.parseSMB2Packet(data, packet, conn)
 	}
 
 	return nil, fmt.Errorf("未知的SMB协议格式")
@@ -274,36 +316,17 @@ func (s *SMBParser) parseSMB1Packet(data []byte, packet *SMBPacket) (*SMBPacket,
 }
 
 // parseSMB2Packet 解析SMB2数据包
-func (s *SMBParser) parseSMB2Packet(data []byte, packet *SMBPacket) (*SMBPacket, error) {
-	if len(data) < 64 {
+// TREE_CONNECT、CREATE、READ、WRITE、CLOSE 按协议格式解码出共享、文件路径和写入数据
+func (s *SMBParser) parseSMB2Packet(data []byte, packet *SMBPacket, conn string) (*SMBPacket, error) {
+	if len(data) < smb2HeaderSize {
 		return nil, fmt.Errorf("SMB2数据包太短")
 	}
 
-	reader := bytes.NewReader(data)
+	s.parseSMB2Messages(data, packet, conn)
 
-	// 跳过协议标识 (4字节)
-	reader.Seek(4, 0)
-
-	// 跳过结构大小 (2字节)
-	reader.Seek(6, 0)
-
-	// 跳过信用费用 (2字节)
-	reader.Seek(8, 0)
-
-	// 跳过状态 (4字节)
-	reader.Seek(12, 0)
-
-	// 读取命令 (2字节)
-	var command uint16
-	binary.Read(reader, binary.LittleEndian, &command)
-	packet.Command = uint8(command)
-
-	// SMB2的其他字段解析...
-	packet.Operation = s.getSMB2Operation(command)
-
-	// 尝试提取文件名（简化处理）
-	if len(data) > 64 {
-		packet.Filename, packet.ShareName = s.extractSMB2FileInfo(data[64:], command)
+	// 其他命令尝试提取文件名（简化处理）
+	if !isSMB2FileCommand(uint16(packet.Command)) && len(data) > smb2HeaderSize {
+		packet.Filename, packet.ShareName = s.extractSMB2FileInfo(data[smb2HeaderSize:], uint16(packet.Command))
 	}
 
 	// 检测敏感数据
@@ -312,6 +335,16 @@ func (s *SMBParser) parseSMB2Packet(data []byte, packet *SMBPacket) (*SMBPacket,
 	return packet, nil
 }
 
+// smbConnectionKey 返回与方向无关的连接标识，请求和响应映射到同一连接
+func smbConnectionKey(packet *interceptor.PacketInfo) string {
+	local := fmt.Sprintf("%s:%d", packet.SourceIP, packet.SourcePort)
+	remote := fmt.Sprintf("%s:%d", packet.DestIP, packet.DestPort)
+	if local > remote {
+		local, remote = remote, local
+	}
+	return local + "-" + remote
+}
+
 // getSMBOperation 获取SMB1操作类型
 func (s *SMBParser) getSMBOperation(command uint8) string {
 	switch command {
@@ -455,5 +488,6 @@ func (s *SMBParser) isSMBProtocol(data []byte) bool {
 // Cleanup 清理资源
 func (s *SMBParser) Cleanup() error {
 	s.logger.Info("清理SMB解析器资源")
+	s.tracker.reset()
 	return nil
 }
@@ -0,0 +1,374 @@
+package parser
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+// DefaultMaxSMBWriteSize 默认每个SMB2 WRITE请求保留的最大写入字节数
+const DefaultMaxSMBWriteSize int64 = 1024 * 1024 // 1MB
+
+// SMB2协议字段
+const (
+	smb2HeaderSize = 64
+
+	smb2CommandTreeConnect uint16 = 0x0003
+	smb2CommandCreate      uint16 = 0x0005
+	smb2CommandClose       uint16 = 0x0006
+	smb2CommandRead        uint16 = 0x0008
+	smb2CommandWrite       uint16 = 0x0009
+
+	// smb2FlagServerToRedir 标识服务端响应
+	smb2FlagServerToRedir uint32 = 0x00000001
+
+	// maxSMB2TrackedEntries 每类跟踪表的最大条目数，超出后淘汰任意旧条目
+	maxSMB2TrackedEntries = 4096
+)
+
+// smb2RelatedFileID 复合请求中表示沿用前一个CREATE打开的文件
+var smb2RelatedFileID = [16]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+
+// smb2Header SMB2消息头
+type smb2Header struct {
+	Command     uint16
+	Status      uint32
+	Flags       uint32
+	NextCommand uint32
+	MessageID   uint64
+	TreeID      uint32
+	SessionID   uint64
+}
+
+// parseSMB2Header 解析SMB2消息头
+func parseSMB2Header(msg []byte) (smb2Header, bool) {
+	if len(msg) < smb2HeaderSize || string(msg[:4]) != SMB2ProtocolID {
+		return smb2Header{}, false
+	}
+	return smb2Header{
+		Status:      binary.LittleEndian.Uint32(msg[8:12]),
+		Command:     binary.LittleEndian.Uint16(msg[12:14]),
+		Flags:       binary.LittleEndian.Uint32(msg[16:20]),
+		NextCommand: binary.LittleEndian.Uint32(msg[20:24]),
+		MessageID:   binary.LittleEndian.Uint64(msg[24:32]),
+		TreeID:      binary.LittleEndian.Uint32(msg[36:40]),
+		SessionID:   binary.LittleEndian.Uint64(msg[40:48]),
+	}, true
+}
+
+// isResponse 是否为服务端响应
+func (h smb2Header) isResponse() bool {
+	return h.Flags&smb2FlagServerToRedir != 0
+}
+
+// smb2MessageKey 等待响应的请求
+type smb2MessageKey struct {
+	conn      string
+	sessionID uint64
+	messageID uint64
+}
+
+// smb2TreeKey 已连接的共享
+type smb2TreeKey struct {
+	conn      string
+	sessionID uint64
+	treeID    uint32
+}
+
+// smb2FileKey 已打开的文件
+type smb2FileKey struct {
+	conn   string
+	fileID [16]byte
+}
+
+// smb2Tracker 跟踪SMB2连接中的共享、打开的文件和等待响应的请求
+// READ、WRITE、CLOSE 请求只携带文件句柄，通过 CREATE 请求和响应记录的句柄映射回文件路径
+type smb2Tracker struct {
+	mu      sync.Mutex
+	limit   int
+	pending map[smb2MessageKey]string // TREE_CONNECT、CREATE、READ 请求的路径
+	trees   map[smb2TreeKey]string    // 树ID到共享路径
+	files   map[smb2FileKey]string    // 文件句柄到文件路径
+}
+
+// newSMB2Tracker 创建SMB2状态跟踪器
+func newSMB2Tracker(limit int) *smb2Tracker {
+	return &smb2Tracker{
+		limit:   limit,
+		pending: make(map[smb2MessageKey]string),
+		trees:   make(map[smb2TreeKey]string),
+		files:   make(map[smb2FileKey]string),
+	}
+}
+
+// remember 记录等待响应的请求路径
+func (t *smb2Tracker) remember(key smb2MessageKey, path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	boundedPut(t.pending, key, path, t.limit)
+}
+
+// take 取出请求路径
+func (t *smb2Tracker) take(key smb2MessageKey) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	path, ok := t.pending[key]
+	delete(t.pending, key)
+	return path, ok
+}
+
+// connectTree 记录共享路径
+func (t *smb2Tracker) connectTree(key smb2TreeKey, path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	boundedPut(t.trees, key, path, t.limit)
+}
+
+// tree 返回共享路径
+func (t *smb2Tracker) tree(key smb2TreeKey) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.trees[key]
+}
+
+// open 记录打开的文件
+func (t *smb2Tracker) open(key smb2FileKey, path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	boundedPut(t.files, key, path, t.limit)
+}
+
+// file 返回文件句柄对应的路径
+func (t *smb2Tracker) file(key smb2FileKey) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.files[key]
+}
+
+// close 移除关闭的文件
+func (t *smb2Tracker) close(key smb2FileKey) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.files, key)
+}
+
+// reset 清空跟踪状态
+func (t *smb2Tracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = make(map[smb2MessageKey]string)
+	t.trees = make(map[smb2TreeKey]string)
+	t.files = make(map[smb2FileKey]string)
+}
+
+// boundedPut 写入条目，表已满时淘汰任意一个旧条目
+func boundedPut[K comparable](m map[K]string, key K, value string, limit int) {
+	if _, exists := m[key]; !exists && len(m) >= limit {
+		for old := range m {
+			delete(m, old)
+			break
+		}
+	}
+	m[key] = value
+}
+
+// isSMB2FileCommand 是否为按协议格式解码的命令
+func isSMB2FileCommand(command uint16) bool {
+	switch command {
+	case smb2CommandTreeConnect, smb2CommandCreate, smb2CommandClose, smb2CommandRead, smb2CommandWrite:
+		return true
+	}
+	return false
+}
+
+// parseSMB2Messages 解析SMB2数据包中的全部消息，复合请求按 NextCommand 依次解析
+// 数据包的命令和操作取第一个消息，文件路径、写入数据等取自解码出的文件操作
+func (s *SMBParser) parseSMB2Messages(data []byte, packet *SMBPacket, conn string) {
+	related := ""
+	for offset := 0; offset < len(data); {
+		msg := data[offset:]
+		header, ok := parseSMB2Header(msg)
+		if !ok {
+			break
+		}
+		if header.NextCommand > 0 {
+			// 下一个消息须8字节对齐且位于当前消息头之后、数据包之内，否则视为畸形数据包
+			next := int(header.NextCommand)
+			if next < smb2HeaderSize || next%8 != 0 || next > len(msg) {
+				break
+			}
+			msg = msg[:next]
+		}
+
+		operation := s.getSMB2Operation(header.Command)
+		if len(packet.Commands) == 0 {
+			packet.Command = uint8(header.Command)
+			packet.Operation = operation
+			packet.Status = header.Status
+			packet.TreeID = uint16(header.TreeID)
+			packet.Response = header.isResponse()
+		}
+		packet.Commands = append(packet.Commands, operation)
+
+		s.decodeSMB2Command(msg, header, conn, packet, &related)
+
+		if header.NextCommand == 0 {
+			break
+		}
+		offset += int(header.NextCommand)
+	}
+
+	if packet.Path != "" {
+		packet.Filename = smb2BaseName(packet.Path)
+	}
+}
+
+// decodeSMB2Command 解码 TREE_CONNECT、CREATE、READ、WRITE、CLOSE 消息
+// related 记录复合请求中最近一个 CREATE 的路径，供沿用该文件的后续请求使用
+func (s *SMBParser) decodeSMB2Command(msg []byte, header smb2Header, conn string, packet *SMBPacket, related *string) {
+	body := msg[smb2HeaderSize:]
+	messageKey := smb2MessageKey{conn: conn, sessionID: header.SessionID, messageID: header.MessageID}
+	treeKey := smb2TreeKey{conn: conn, sessionID: header.SessionID, treeID: header.TreeID}
+
+	switch header.Command {
+	case smb2CommandTreeConnect:
+		if !header.isResponse() {
+			if path, ok := smb2String(msg, body, 4); ok {
+				packet.ShareName = smb2ShareName(path)
+				s.tracker.remember(messageKey, path)
+			}
+		} else if path, ok := s.tracker.take(messageKey); ok && header.Status == 0 {
+			packet.ShareName = smb2ShareName(path)
+			s.tracker.connectTree(treeKey, path)
+		}
+
+	case smb2CommandCreate:
+		if !header.isResponse() {
+			if path, ok := smb2String(msg, body, 44); ok {
+				packet.Path = path
+				*related = path
+				s.tracker.remember(messageKey, path)
+			}
+		} else if path, ok := s.tracker.take(messageKey); ok {
+			packet.Path = path
+			// 响应中 EndofFile 位于偏移48，FileId 位于偏移64
+			if header.Status == 0 && len(body) >= 80 {
+				var fileID [16]byte
+				copy(fileID[:], body[64:80])
+				packet.FileID = hex.EncodeToString(fileID[:])
+				packet.FileSize = binary.LittleEndian.Uint64(body[48:56])
+				s.tracker.open(smb2FileKey{conn: conn, fileID: fileID}, path)
+			}
+		}
+
+	case smb2CommandWrite:
+		// 请求：DataOffset(2) Length(4) Offset(8) FileId(16)
+		if header.isResponse() || len(body) < 32 {
+			break
+		}
+		dataOffset := binary.LittleEndian.Uint16(body[2:4])
+		packet.Length = binary.LittleEndian.Uint32(body[4:8])
+		packet.Offset = binary.LittleEndian.Uint64(body[8:16])
+		s.resolveFile(body[16:32], conn, packet, *related)
+		packet.Data, packet.Truncated = s.captureWrite(msg, dataOffset, packet.Length)
+
+	case smb2CommandRead:
+		// 请求：Padding(1) Flags(1) Length(4) Offset(8) FileId(16)
+		if header.isResponse() || len(body) < 32 {
+			break
+		}
+		packet.Length = binary.LittleEndian.Uint32(body[4:8])
+		packet.Offset = binary.LittleEndian.Uint64(body[8:16])
+		s.resolveFile(body[16:32], conn, packet, *related)
+
+	case smb2CommandClose:
+		// 请求：Flags(2) Reserved(4) FileId(16)
+		if header.isResponse() || len(body) < 24 {
+			break
+		}
+		key := s.resolveFile(body[8:24], conn, packet, *related)
+		s.tracker.close(key)
+	}
+
+	if packet.ShareName == "" {
+		packet.ShareName = smb2ShareName(s.tracker.tree(treeKey))
+	}
+}
+
+// resolveFile 根据文件句柄查找路径，返回句柄的跟踪键
+func (s *SMBParser) resolveFile(raw []byte, conn string, packet *SMBPacket, related string) smb2FileKey {
+	var fileID [16]byte
+	copy(fileID[:], raw)
+	key := smb2FileKey{conn: conn, fileID: fileID}
+
+	if fileID == smb2RelatedFileID {
+		packet.Path = related
+		return key
+	}
+	packet.FileID = hex.EncodeToString(fileID[:])
+	if path := s.tracker.file(key); path != "" {
+		packet.Path = path
+	}
+	return key
+}
+
+// captureWrite 提取写入的数据，最多保留 maxWriteSize 字节
+// 超出上限或数据包只包含部分数据时标记截断
+func (s *SMBParser) captureWrite(msg []byte, dataOffset uint16, length uint32) ([]byte, bool) {
+	if length == 0 {
+		return nil, false
+	}
+	if int(dataOffset) < smb2HeaderSize || int(dataOffset) > len(msg) {
+		return nil, true
+	}
+
+	size := int64(length)
+	truncated := false
+	if size > s.maxWriteSize {
+		size = s.maxWriteSize
+		truncated = true
+	}
+	if available := int64(len(msg) - int(dataOffset)); available < size {
+		size = available
+		truncated = true
+	}
+	return append([]byte(nil), msg[int(dataOffset):int(dataOffset)+int(size)]...), truncated
+}
+
+// smb2String 读取消息体中偏移字段指向的UTF-16LE字符串
+// field 为消息体中 Offset(2)、Length(2) 字段的位置，偏移相对于SMB2消息头
+func smb2String(msg, body []byte, field int) (string, bool) {
+	if len(body) < field+4 {
+		return "", false
+	}
+	offset := int(binary.LittleEndian.Uint16(body[field:]))
+	length := int(binary.LittleEndian.Uint16(body[field+2:]))
+	if length == 0 || length%2 != 0 || offset < smb2HeaderSize || offset+length > len(msg) {
+		return "", false
+	}
+
+	units := make([]uint16, length/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(msg[offset+i*2:])
+	}
+	return string(utf16.Decode(units)), true
+}
+
+// smb2ShareName 从 \\server\share 形式的路径中提取共享名
+func smb2ShareName(path string) string {
+	parts := strings.Split(strings.TrimLeft(path, `\`), `\`)
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[1]
+}
+
+// smb2BaseName 返回共享内路径的文件名
+func smb2BaseName(path string) string {
+	if index := strings.LastIndex(path, `\`); index >= 0 {
+		return path[index+1:]
+	}
+	return path
+}
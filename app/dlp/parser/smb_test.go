@@ -0,0 +1,234 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSMBFileID = []byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+
+func newSMBPacket(payload []byte, response bool) *interceptor.PacketInfo {
+	packet := &interceptor.PacketInfo{
+		SourceIP:   net.ParseIP("192.168.1.10"),
+		DestIP:     net.ParseIP("192.168.1.20"),
+		SourcePort: 50100,
+		DestPort:   445,
+		Payload:    payload,
+		Size:       len(payload),
+	}
+	if response {
+		packet.SourceIP, packet.DestIP = packet.DestIP, packet.SourceIP
+		packet.SourcePort, packet.DestPort = packet.DestPort, packet.SourcePort
+	}
+	return packet
+}
+
+// smb2Message 构造SMB2消息头和消息体
+func smb2Message(command uint16, response bool, messageID uint64, body []byte) []byte {
+	header := make([]byte, smb2HeaderSize)
+	copy(header, SMB2ProtocolID)
+	binary.LittleEndian.PutUint16(header[4:], smb2HeaderSize)
+	binary.LittleEndian.PutUint16(header[12:], command)
+	if response {
+		binary.LittleEndian.PutUint32(header[16:], smb2FlagServerToRedir)
+	}
+	binary.LittleEndian.PutUint64(header[24:], messageID)
+	binary.LittleEndian.PutUint32(header[36:], 7)      // TreeId
+	binary.LittleEndian.PutUint64(header[40:], 0x4001) // SessionId
+	return append(header, body...)
+}
+
+// withNetBIOS 添加NetBIOS会话头
+func withNetBIOS(msg []byte) []byte {
+	frame := make([]byte, 4, 4+len(msg))
+	binary.BigEndian.PutUint32(frame, uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+func utf16le(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	buf := make([]byte, len(units)*2)
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(buf[i*2:], unit)
+	}
+	return buf
+}
+
+func smb2TreeConnectRequest(messageID uint64, path string) []byte {
+	name := utf16le(path)
+	body := make([]byte, 8)
+	binary.LittleEndian.PutUint16(body[0:], 9)
+	binary.LittleEndian.PutUint16(body[4:], smb2HeaderSize+8)
+	binary.LittleEndian.PutUint16(body[6:], uint16(len(name)))
+	return smb2Message(smb2CommandTreeConnect, false, messageID, append(body, name...))
+}
+
+func smb2CreateRequest(messageID uint64, path string) []byte {
+	name := utf16le(path)
+	body := make([]byte, 56)
+	binary.LittleEndian.PutUint16(body[0:], 57)
+	binary.LittleEndian.PutUint16(body[44:], smb2HeaderSize+56)
+	binary.LittleEndian.PutUint16(body[46:], uint16(len(name)))
+	return smb2Message(smb2CommandCreate, false, messageID, append(body, name...))
+}
+
+func smb2CreateResponse(messageID uint64, fileID []byte, endOfFile uint64) []byte {
+	body := make([]byte, 88)
+	binary.LittleEndian.PutUint16(body[0:], 89)
+	binary.LittleEndian.PutUint64(body[48:], endOfFile)
+	copy(body[64:], fileID)
+	return smb2Message(smb2CommandCreate, true, messageID, body)
+}
+
+func smb2WriteRequest(messageID uint64, fileID []byte, offset uint64, data []byte) []byte {
+	body := make([]byte, 48)
+	binary.LittleEndian.PutUint16(body[0:], 49)
+	binary.LittleEndian.PutUint16(body[2:], smb2HeaderSize+48)
+	binary.LittleEndian.PutUint32(body[4:], uint32(len(data)))
+	binary.LittleEndian.PutUint64(body[8:], offset)
+	copy(body[16:], fileID)
+	return smb2Message(smb2CommandWrite, false, messageID, append(body, data...))
+}
+
+func smb2ReadRequest(messageID uint64, fileID []byte, offset uint64, length uint32) []byte {
+	body := make([]byte, 48)
+	binary.LittleEndian.PutUint16(body[0:], 49)
+	binary.LittleEndian.PutUint32(body[4:], length)
+	binary.LittleEndian.PutUint64(body[8:], offset)
+	copy(body[16:], fileID)
+	return smb2Message(smb2CommandRead, false, messageID, body)
+}
+
+func TestSMBParserCreateRequestPath(t *testing.T) {
+	p := NewSMBParser(newTestLogger(t))
+
+	for name, payload := range map[string][]byte{
+		"raw":     smb2CreateRequest(5, `finance\2024\工资表.xlsx`),
+		"netbios": withNetBIOS(smb2CreateRequest(5, `finance\2024\工资表.xlsx`)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := p.Parse(newSMBPacket(payload, false))
+			require.NoError(t, err)
+
+			assert.Equal(t, "smb2", result.Protocol)
+			assert.Equal(t, "CREATE", result.Metadata["operation"])
+			assert.Equal(t, `finance\2024\工资表.xlsx`, result.Metadata["path"])
+			assert.Equal(t, "工资表.xlsx", result.Metadata["filename"])
+			assert.Equal(t, `finance\2024\工资表.xlsx`, result.Headers["SMB-Path"])
+			assert.Equal(t, true, result.Metadata["sensitive"])
+		})
+	}
+}
+
+func TestSMBParserWriteResolvesPath(t *testing.T) {
+	p := NewSMBParser(newTestLogger(t))
+
+	// 连接共享并打开文件，服务端响应返回文件句柄
+	steps := []struct {
+		payload  []byte
+		response bool
+	}{
+		{smb2TreeConnectRequest(3, `\\fileserver\shared`), false},
+		{smb2Message(smb2CommandTreeConnect, true, 3, make([]byte, 16)), true},
+		{smb2CreateRequest(4, `hr\roster.csv`), false},
+		{smb2CreateResponse(4, testSMBFileID, 2048), true},
+	}
+	for _, step := range steps {
+		_, err := p.Parse(newSMBPacket(withNetBIOS(step.payload), step.response))
+		require.NoError(t, err)
+	}
+
+	data := []byte("name,id_card\n张三,110101199003074578\n")
+	result, err := p.Parse(newSMBPacket(withNetBIOS(smb2WriteRequest(5, testSMBFileID, 4096, data)), false))
+	require.NoError(t, err)
+
+	assert.Equal(t, "WRITE", result.Metadata["operation"])
+	assert.Equal(t, `hr\roster.csv`, result.Metadata["path"])
+	assert.Equal(t, "roster.csv", result.Metadata["filename"])
+	assert.Equal(t, "shared", result.Metadata["share_name"])
+	assert.Equal(t, uint64(4096), result.Metadata["offset"])
+	assert.Equal(t, uint32(len(data)), result.Metadata["length"])
+	assert.Equal(t, false, result.Metadata["write_truncated"])
+	assert.Equal(t, data, result.Body)
+
+	// READ 请求同样按句柄映射回路径，不携带数据
+	result, err = p.Parse(newSMBPacket(smb2ReadRequest(6, testSMBFileID, 0, 65536), false))
+	require.NoError(t, err)
+	assert.Equal(t, "READ", result.Metadata["operation"])
+	assert.Equal(t, `hr\roster.csv`, result.Metadata["path"])
+	assert.Equal(t, uint32(65536), result.Metadata["length"])
+	assert.Empty(t, result.Body)
+}
+
+func TestSMBParserCompoundCreateWrite(t *testing.T) {
+	p := NewSMBParser(newTestLogger(t))
+
+	// 复合请求中 WRITE 使用全 0xFF 的句柄沿用前一个 CREATE 打开的文件
+	create := smb2CreateRequest(10, `reports\q3.txt`)
+	for len(create)%8 != 0 {
+		create = append(create, 0)
+	}
+	binary.LittleEndian.PutUint32(create[20:], uint32(len(create)))
+	related := bytes.Repeat([]byte{0xFF}, 16)
+	write := smb2WriteRequest(11, related, 0, []byte("季度营收机密"))
+
+	result, err := p.Parse(newSMBPacket(append(create, write...), false))
+	require.NoError(t, err)
+
+	assert.Equal(t, "CREATE", result.Metadata["operation"])
+	assert.Equal(t, []string{"CREATE", "WRITE"}, result.Metadata["commands"])
+	assert.Equal(t, `reports\q3.txt`, result.Metadata["path"])
+	assert.Equal(t, []byte("季度营收机密"), result.Body)
+}
+
+func TestSMBParserWriteSizeLimit(t *testing.T) {
+	p := NewSMBParser(newTestLogger(t))
+	config := DefaultParserConfig()
+	config.MaxSMBWriteSize = 16
+	require.NoError(t, p.Initialize(config))
+
+	data := bytes.Repeat([]byte("0123456789"), 10)
+	result, err := p.Parse(newSMBPacket(smb2WriteRequest(1, testSMBFileID, 0, data), false))
+	require.NoError(t, err)
+
+	assert.Equal(t, data[:16], result.Body)
+	assert.Equal(t, uint32(len(data)), result.Metadata["length"])
+	assert.Equal(t, true, result.Metadata["write_truncated"])
+
+	// 数据包只包含部分写入数据时同样标记截断
+	p = NewSMBParser(newTestLogger(t))
+	partial := smb2WriteRequest(2, testSMBFileID, 0, data)[:smb2HeaderSize+48+30]
+	result, err = p.Parse(newSMBPacket(partial, false))
+	require.NoError(t, err)
+	assert.Equal(t, data[:30], result.Body)
+	assert.Equal(t, true, result.Metadata["write_truncated"])
+}
+
+func TestSMBParserMalformedNextCommand(t *testing.T) {
+	p := NewSMBParser(newTestLogger(t))
+
+	for name, next := range map[string]uint32{
+		"shorter_than_header": 48,
+		"unaligned":           smb2HeaderSize + 4,
+		"past_buffer":         4096,
+	} {
+		t.Run(name, func(t *testing.T) {
+			msg := smb2CreateRequest(1, `finance\q3.xlsx`)
+			binary.LittleEndian.PutUint32(msg[20:], next)
+			payload := append(msg, smb2CreateRequest(2, `finance\q4.xlsx`)...)
+
+			require.NotPanics(t, func() {
+				result, err := p.Parse(newSMBPacket(withNetBIOS(payload), false))
+				require.NoError(t, err)
+				assert.NotContains(t, result.Metadata["path"], "q4.xlsx")
+			})
+		})
+	}
+}
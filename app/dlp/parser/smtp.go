@@ -1,9 +1,7 @@
 package parser
 
 import (
-	"bufio"
 	"fmt"
-	"net/mail"
 	"strconv"
 	"strings"
 	"time"
@@ -14,8 +12,10 @@ import (
 
 // SMTPParser SMTP协议解析器
 type SMTPParser struct {
-	logger   logging.Logger
-	sessions map[string]*SMTPSession
+	logger            logging.Logger
+	sessions          map[string]*SMTPSession
+	maxBodySize       int64
+	maxAttachmentSize int64
 }
 
 // SMTPSession SMTP会话信息
@@ -59,8 +59,10 @@ type SMTPResponse struct {
 // NewSMTPParser 创建SMTP解析器
 func NewSMTPParser(logger logging.Logger) *SMTPParser {
 	return &SMTPParser{
-		logger:   logger,
-		sessions: make(map[string]*SMTPSession),
+		logger:            logger,
+		sessions:          make(map[string]*SMTPSession),
+		maxBodySize:       DefaultParserConfig().MaxBodySize,
+		maxAttachmentSize: DefaultMaxAttachmentSize,
 	}
 }
 
@@ -123,6 +125,12 @@ func (s *SMTPParser) GetSupportedProtocols() []string {
 // Initialize 初始化解析器
 func (s *SMTPParser) Initialize(config ParserConfig) error {
 	s.logger.Info("初始化SMTP解析器")
+	if config.MaxBodySize > 0 {
+		s.maxBodySize = config.MaxBodySize
+	}
+	if config.MaxAttachmentSize > 0 {
+		s.maxAttachmentSize = config.MaxAttachmentSize
+	}
 	return nil
 }
 
@@ -169,21 +177,18 @@ func (s *SMTPParser) isSMTPData(data []byte) bool {
 
 // parseSMTPData 解析SMTP数据
 func (s *SMTPParser) parseSMTPData(data []byte, parsedData *ParsedData, session *SMTPSession) (*ParsedData, error) {
-	dataStr := strings.TrimSpace(string(data))
-	lines := strings.Split(dataStr, "\r\n")
+	dataStr := strings.TrimRight(string(data), "\r\n")
+	lines := strings.Split(dataStr, "\n")
 
 	commands := make([]SMTPCommand, 0)
 	responses := make([]SMTPResponse, 0)
 
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	for _, rawLine := range lines {
+		rawLine = strings.TrimSuffix(rawLine, "\r")
 
-		// 如果会话处于DATA模式，所有数据都是邮件内容
+		// 如果会话处于DATA模式，所有数据都是邮件内容，保留原始行（包括空行）以便MIME解析
 		if session.DataMode {
-			if line == "." {
+			if rawLine == "." {
 				// 邮件结束标记
 				session.DataMode = false
 				session.State = SMTPStateReady
@@ -194,13 +199,22 @@ func (s *SMTPParser) parseSMTPData(data []byte, parsedData *ParsedData, session
 				}
 
 				session.MessageData = []byte{}
-			} else {
+			} else if int64(len(session.MessageData)) < s.maxBodySize {
+				// 去除点填充 (RFC 5321 4.5.2)
+				if strings.HasPrefix(rawLine, "..") {
+					rawLine = rawLine[1:]
+				}
 				// 累积邮件数据
-				session.MessageData = append(session.MessageData, []byte(line+"\r\n")...)
+				session.MessageData = append(session.MessageData, []byte(rawLine+"\r\n")...)
 			}
 			continue
 		}
 
+		line := strings.TrimSpace(rawLine)
+		if line == "" {
+			continue
+		}
+
 		// 尝试解析为响应
 		if response, isResponse := s.parseResponse(line); isResponse {
 			responses = append(responses, response)
@@ -384,72 +398,105 @@ func (s *SMTPParser) parseEmailContent(data []byte, parsedData *ParsedData) erro
 		return nil
 	}
 
-	// 使用Go标准库解析邮件
-	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	// 解析MIME结构，提取正文和附件
+	email, err := s.ParseEmail(data)
 	if err != nil {
 		// 如果无法解析为标准邮件格式，尝试简单解析
-		return s.parseEmailContentSimple(data, parsedData)
+		if email == nil {
+			return s.parseEmailContentSimple(data, parsedData)
+		}
+		s.logger.Warn("MIME邮件解析不完整", "error", err)
 	}
 
 	// 提取邮件头
-	for key, values := range msg.Header {
-		if len(values) > 0 {
-			parsedData.Headers[key] = values[0]
-		}
+	for key, value := range email.Headers {
+		parsedData.Headers[key] = value
 	}
 
 	// 提取常见头部到元数据
-	if from := msg.Header.Get("From"); from != "" {
+	if from := email.Headers["From"]; from != "" {
 		parsedData.Metadata["email_from"] = from
 	}
-	if to := msg.Header.Get("To"); to != "" {
+	if to := email.Headers["To"]; to != "" {
 		parsedData.Metadata["email_to"] = to
 	}
-	if cc := msg.Header.Get("Cc"); cc != "" {
+	if cc := email.Headers["Cc"]; cc != "" {
 		parsedData.Metadata["email_cc"] = cc
 	}
-	if subject := msg.Header.Get("Subject"); subject != "" {
-		parsedData.Metadata["email_subject"] = subject
+	if email.Subject != "" {
+		parsedData.Metadata["email_subject"] = email.Subject
 	}
-	if date := msg.Header.Get("Date"); date != "" {
+	if date := email.Headers["Date"]; date != "" {
 		parsedData.Metadata["email_date"] = date
 	}
-	if messageID := msg.Header.Get("Message-ID"); messageID != "" {
-		parsedData.Metadata["email_message_id"] = messageID
+	if email.MessageID != "" {
+		parsedData.Metadata["email_message_id"] = email.MessageID
 	}
 
-	// 读取邮件正文
-	scanner := bufio.NewScanner(msg.Body)
-	var bodyLines []string
-	for scanner.Scan() {
-		bodyLines = append(bodyLines, scanner.Text())
+	recipients := make([]string, 0, len(email.To)+len(email.CC)+len(email.BCC))
+	for _, addrs := range [][]EmailAddress{email.To, email.CC, email.BCC} {
+		for _, addr := range addrs {
+			recipients = append(recipients, addr.Address)
+		}
+	}
+	if len(recipients) > 0 {
+		parsedData.Metadata["email_recipients"] = recipients
 	}
 
-	body := strings.Join(bodyLines, "\n")
-	parsedData.Body = []byte(body)
+	body := email.Body
+	if body == "" {
+		body = email.HTMLBody
+	}
 	parsedData.Metadata["email_body"] = body
 	parsedData.Metadata["email_body_size"] = len(body)
+	if email.HTMLBody != "" {
+		parsedData.Metadata["is_html_email"] = true
+	}
 
 	// 检测内容类型
-	contentType := msg.Header.Get("Content-Type")
-	if contentType != "" {
+	if contentType := email.Headers["Content-Type"]; contentType != "" {
 		parsedData.ContentType = contentType
 		parsedData.Metadata["email_content_type"] = contentType
+	}
 
-		// 检查是否为HTML邮件
-		if strings.Contains(strings.ToLower(contentType), "text/html") {
-			parsedData.Metadata["is_html_email"] = true
-		}
+	// 组装供分析器检测的内容：主题、正文以及文本类附件内容
+	var content strings.Builder
+	if email.Subject != "" {
+		content.WriteString(email.Subject)
+		content.WriteString("\n")
 	}
+	content.WriteString(body)
+
+	if len(email.Attachments) > 0 {
+		names := make([]string, 0, len(email.Attachments))
+		for _, attachment := range email.Attachments {
+			names = append(names, attachment.Name)
+
+			content.WriteString("\n")
+			content.WriteString(attachment.Name)
+			if isTextContent(attachment.ContentType, attachment.Data) {
+				content.WriteString("\n")
+				content.Write(attachment.Data)
+			}
+		}
 
-	// 检查是否有附件
-	if strings.Contains(strings.ToLower(contentType), "multipart") {
 		parsedData.Metadata["has_attachments"] = true
+		parsedData.Metadata["email_attachments"] = email.Attachments
+		parsedData.Metadata["email_attachment_names"] = names
+		parsedData.Metadata["email_attachment_count"] = len(email.Attachments)
 	}
 
+	parsedData.Body = []byte(content.String())
+	parsedData.Metadata["email_message"] = email
+
 	return nil
 }
 
+// ParseEmail 解析完整的邮件内容（DATA阶段传输的数据）
+func (s *SMTPParser) ParseEmail(data []byte) (*EmailMessage, error) {
+	return parseMIMEMessage(data, s.maxAttachmentSize)
+}
+
 // parseEmailContentSimple 简单解析邮件内容
 func (s *SMTPParser) parseEmailContentSimple(data []byte, parsedData *ParsedData) error {
 	content := string(data)
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(t *testing.T) logging.Logger {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+	return logger
+}
+
+func newSMTPPacket(payload string) *interceptor.PacketInfo {
+	return &interceptor.PacketInfo{
+		SourceIP:   net.ParseIP("192.168.1.10"),
+		DestIP:     net.ParseIP("203.0.113.5"),
+		SourcePort: 50000,
+		DestPort:   25,
+		Payload:    []byte(payload),
+		Size:       len(payload),
+	}
+}
+
+func buildMultipartEmail(attachment []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(attachment)
+	var wrapped strings.Builder
+	for len(encoded) > 76 {
+		wrapped.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	wrapped.WriteString(encoded)
+
+	return "From: Alice <alice@example.com>\r\n" +
+		"To: Bob <bob@example.org>, carol@example.org\r\n" +
+		"Subject: =?UTF-8?B?5pyI5bqm5oql5ZGK?=\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/mixed; boundary=\"BOUNDARY\"\r\n" +
+		"\r\n" +
+		"--BOUNDARY\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"Card number: 4111 1111 1111 1111=\r\n" +
+		" please keep it safe.\r\n" +
+		"\r\n" +
+		"..leading dot line\r\n" +
+		"--BOUNDARY\r\n" +
+		"Content-Type: text/csv; name=\"customers.csv\"\r\n" +
+		"Content-Disposition: attachment; filename=\"customers.csv\"\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		wrapped.String() + "\r\n" +
+		"--BOUNDARY--\r\n"
+}
+
+func TestSMTPParser_ParseDataWithAttachment(t *testing.T) {
+	p := NewSMTPParser(newTestLogger(t))
+	require.NoError(t, p.Initialize(DefaultParserConfig()))
+
+	attachment := []byte("name,id_card\nzhangsan,110101199003077777\n")
+
+	_, err := p.Parse(newSMTPPacket("MAIL FROM:<alice@example.com>\r\nRCPT TO:<bob@example.org>\r\nDATA\r\n"))
+	require.NoError(t, err)
+
+	result, err := p.Parse(newSMTPPacket(buildMultipartEmail(attachment) + ".\r\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "月度报告", result.Metadata["email_subject"])
+	assert.Equal(t, []string{"bob@example.org", "carol@example.org"}, result.Metadata["email_recipients"])
+
+	body, ok := result.Metadata["email_body"].(string)
+	require.True(t, ok)
+	assert.Contains(t, body, "Card number: 4111 1111 1111 1111 please keep it safe.")
+	assert.Contains(t, body, ".leading dot line")
+	assert.NotContains(t, body, "..leading dot line")
+
+	assert.Equal(t, true, result.Metadata["has_attachments"])
+	assert.Equal(t, []string{"customers.csv"}, result.Metadata["email_attachment_names"])
+
+	attachments, ok := result.Metadata["email_attachments"].([]EmailAttachment)
+	require.True(t, ok)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "text/csv", attachments[0].ContentType)
+	assert.Equal(t, attachment, attachments[0].Data)
+	assert.Equal(t, int64(len(attachment)), attachments[0].Size)
+	assert.False(t, attachments[0].Truncated)
+
+	// 正文和附件内容都应交给分析器
+	assert.Contains(t, string(result.Body), "4111 1111 1111 1111")
+	assert.Contains(t, string(result.Body), "110101199003077777")
+}
+
+func TestSMTPParser_AttachmentSizeLimit(t *testing.T) {
+	p := NewSMTPParser(newTestLogger(t))
+	config := DefaultParserConfig()
+	config.MaxAttachmentSize = 16
+	require.NoError(t, p.Initialize(config))
+
+	attachment := []byte(strings.Repeat("0123456789", 10))
+	email, err := p.ParseEmail([]byte(buildMultipartEmail(attachment)))
+	require.NoError(t, err)
+
+	require.Len(t, email.Attachments, 1)
+	assert.Equal(t, attachment[:16], email.Attachments[0].Data)
+	assert.Equal(t, int64(len(attachment)), email.Attachments[0].Size)
+	assert.True(t, email.Attachments[0].Truncated)
+	assert.NotEmpty(t, email.Attachments[0].Hash)
+}
@@ -239,52 +239,6 @@ func (m *MQTTParser) Cleanup() error {
 	return nil
 }
 
-// AMQPParser AMQP协议解析器存根
-type AMQPParser struct {
-	logger logging.Logger
-}
-
-func NewAMQPParser(logger logging.Logger) *AMQPParser {
-	return &AMQPParser{logger: logger}
-}
-
-func (a *AMQPParser) GetParserInfo() ParserInfo {
-	return ParserInfo{
-		Name:               "AMQP Parser",
-		Version:            "1.0.0",
-		Description:        "AMQP协议解析器存根",
-		SupportedProtocols: []string{"amqp"},
-		Author:             "DLP Team",
-		License:            "MIT",
-	}
-}
-
-func (a *AMQPParser) CanParse(packet *interceptor.PacketInfo) bool {
-	return packet != nil && (packet.DestPort == 5672 || packet.SourcePort == 5672)
-}
-
-func (a *AMQPParser) Parse(packet *interceptor.PacketInfo) (*ParsedData, error) {
-	return &ParsedData{
-		Protocol:    "amqp",
-		Headers:     make(map[string]string),
-		Body:        packet.Payload,
-		Metadata:    map[string]any{"protocol": "amqp", "port": packet.DestPort},
-		ContentType: "application/amqp",
-	}, nil
-}
-
-func (a *AMQPParser) GetSupportedProtocols() []string {
-	return []string{"amqp"}
-}
-
-func (a *AMQPParser) Initialize(config ParserConfig) error {
-	return nil
-}
-
-func (a *AMQPParser) Cleanup() error {
-	return nil
-}
-
 // KafkaParser Kafka协议解析器存根
 type KafkaParser struct {
 	logger logging.Logger
@@ -0,0 +1,244 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+)
+
+const (
+	// maxHandshakeMessageSize 单个握手消息的最大长度，超过时放弃重组
+	maxHandshakeMessageSize = 256 * 1024
+
+	// maxHandshakeStreams 同时重组的连接数量上限
+	maxHandshakeStreams = 1024
+
+	// handshakeStreamTimeout 未完成的握手重组状态的保留时间
+	handshakeStreamTimeout = 30 * time.Second
+)
+
+// TLSCertificateInfo 从Certificate握手消息中提取的证书信息
+type TLSCertificateInfo struct {
+	SubjectCN         string    `json:"subject_cn"`
+	Subject           string    `json:"subject"`
+	IssuerCN          string    `json:"issuer_cn"`
+	Issuer            string    `json:"issuer"`
+	SANs              []string  `json:"sans"`
+	FingerprintSHA256 string    `json:"fingerprint_sha256"`
+	SerialNumber      string    `json:"serial_number"`
+	NotBefore         time.Time `json:"not_before"`
+	NotAfter          time.Time `json:"not_after"`
+	SelfSigned        bool      `json:"self_signed"`
+}
+
+// NewTLSCertificateInfo 提取证书的主体、颁发者、SAN和SHA-256指纹
+func NewTLSCertificateInfo(cert *x509.Certificate) *TLSCertificateInfo {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses)+len(cert.EmailAddresses)+len(cert.URIs))
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	return &TLSCertificateInfo{
+		SubjectCN:         cert.Subject.CommonName,
+		Subject:           cert.Subject.String(),
+		IssuerCN:          cert.Issuer.CommonName,
+		Issuer:            cert.Issuer.String(),
+		SANs:              sans,
+		FingerprintSHA256: hex.EncodeToString(fingerprint[:]),
+		SerialNumber:      cert.SerialNumber.String(),
+		NotBefore:         cert.NotBefore,
+		NotAfter:          cert.NotAfter,
+		SelfSigned:        cert.CheckSignatureFrom(cert) == nil,
+	}
+}
+
+// handshakeMessage 完整的握手消息
+type handshakeMessage struct {
+	Type uint8
+	Body []byte
+}
+
+// handshakeStream 单个连接方向上的握手重组状态
+type handshakeStream struct {
+	buffer          []byte // 尚未组成完整握手消息的数据
+	recordRemaining int    // 被数据包截断的握手记录还未到达的字节数
+	lastSeen        time.Time
+}
+
+// handshakeStreamKey 按连接方向生成重组键
+func handshakeStreamKey(packet *interceptor.PacketInfo) string {
+	return fmt.Sprintf("%s:%d-%s:%d",
+		packet.SourceIP.String(), packet.SourcePort,
+		packet.DestIP.String(), packet.DestPort)
+}
+
+// reassembleHandshake 按连接重组握手消息
+// 握手消息可以跨越多个TLS记录，记录也可能被拆分到多个数据包中。
+// 数据包以握手记录开头或延续上一个被截断的握手记录时返回 true，以及本次重组出的完整消息
+func (h *HTTPSParser) reassembleHandshake(packet *interceptor.PacketInfo) ([]handshakeMessage, bool) {
+	h.handshakeMu.Lock()
+	defer h.handshakeMu.Unlock()
+
+	key := handshakeStreamKey(packet)
+	stream := h.handshakeStreams[key]
+	payload := packet.Payload
+
+	if stream != nil && stream.recordRemaining > 0 {
+		n := min(stream.recordRemaining, len(payload))
+		stream.buffer = append(stream.buffer, payload[:n]...)
+		stream.recordRemaining -= n
+		payload = payload[n:]
+	} else if len(payload) < 5 || payload[0] != 22 {
+		return nil, false
+	} else if stream == nil {
+		stream = &handshakeStream{}
+	}
+	stream.lastSeen = time.Now()
+
+	// 依次取出数据包中连续的握手记录
+	for stream.recordRemaining == 0 && len(payload) >= 5 && payload[0] == 22 {
+		length := int(payload[3])<<8 | int(payload[4])
+		available := min(length, len(payload)-5)
+		stream.buffer = append(stream.buffer, payload[5:5+available]...)
+		stream.recordRemaining = length - available
+		payload = payload[5+available:]
+	}
+
+	var messages []handshakeMessage
+	for len(stream.buffer) >= 4 {
+		length := int(stream.buffer[1])<<16 | int(stream.buffer[2])<<8 | int(stream.buffer[3])
+		if length > maxHandshakeMessageSize {
+			// 通常是加密的Finished消息，无法重组
+			h.logger.Debug("握手消息过长，放弃重组", "length", length)
+			stream.buffer = nil
+			stream.recordRemaining = 0
+			break
+		}
+		if len(stream.buffer) < 4+length {
+			break
+		}
+		messages = append(messages, handshakeMessage{
+			Type: stream.buffer[0],
+			Body: stream.buffer[4 : 4+length],
+		})
+		stream.buffer = stream.buffer[4+length:]
+	}
+
+	if len(stream.buffer) == 0 && stream.recordRemaining == 0 {
+		delete(h.handshakeStreams, key)
+	} else {
+		stream.buffer = append([]byte(nil), stream.buffer...)
+		h.handshakeStreams[key] = stream
+		h.pruneHandshakeStreams()
+	}
+
+	return messages, true
+}
+
+// pruneHandshakeStreams 清理超时的重组状态，超过数量上限时清理最久未更新的状态
+func (h *HTTPSParser) pruneHandshakeStreams() {
+	now := time.Now()
+	for key, stream := range h.handshakeStreams {
+		if now.Sub(stream.lastSeen) > handshakeStreamTimeout {
+			delete(h.handshakeStreams, key)
+		}
+	}
+
+	for len(h.handshakeStreams) > maxHandshakeStreams {
+		var oldestKey string
+		var oldest time.Time
+		for key, stream := range h.handshakeStreams {
+			if oldestKey == "" || stream.lastSeen.Before(oldest) {
+				oldestKey, oldest = key, stream.lastSeen
+			}
+		}
+		delete(h.handshakeStreams, oldestKey)
+	}
+}
+
+// parseCertificateChain 解析Certificate消息中的证书链
+func parseCertificateChain(data []byte) ([]*x509.Certificate, error) {
+	if len(data) < 3 {
+		return nil, fmt.Errorf("证书消息长度不足")
+	}
+
+	listLength := int(data[0])<<16 | int(data[1])<<8 | int(data[2])
+	if len(data) < 3+listLength {
+		return nil, fmt.Errorf("证书列表长度不足")
+	}
+	data = data[3 : 3+listLength]
+
+	var chain []*x509.Certificate
+	for len(data) > 0 {
+		if len(data) < 3 {
+			return chain, fmt.Errorf("证书长度字段不完整")
+		}
+		certLength := int(data[0])<<16 | int(data[1])<<8 | int(data[2])
+		if len(data) < 3+certLength {
+			return chain, fmt.Errorf("证书数据不完整")
+		}
+
+		cert, err := x509.ParseCertificate(data[3 : 3+certLength])
+		if err != nil {
+			return chain, fmt.Errorf("解析X.509证书失败: %w", err)
+		}
+		chain = append(chain, cert)
+		data = data[3+certLength:]
+	}
+	return chain, nil
+}
+
+// verifyCertificateChain 验证服务器证书链
+// 配置了 CAFile 时只信任其中的CA，否则使用系统根证书
+func (h *HTTPSParser) verifyCertificateChain(chain []*x509.Certificate) error {
+	roots, err := h.trustedRoots()
+	if err != nil {
+		return err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err = chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// trustedRoots 加载配置的CA证书，未配置时返回nil表示使用系统根证书
+func (h *HTTPSParser) trustedRoots() (*x509.CertPool, error) {
+	h.rootsOnce.Do(func() {
+		if h.tlsConfig == nil || h.tlsConfig.CAFile == "" {
+			return
+		}
+
+		data, err := os.ReadFile(h.tlsConfig.CAFile)
+		if err != nil {
+			h.rootsErr = fmt.Errorf("读取CA证书失败: %w", err)
+			return
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			h.rootsErr = fmt.Errorf("CA证书文件中没有有效的证书: %s", h.tlsConfig.CAFile)
+			return
+		}
+		h.roots = pool
+	})
+	return h.roots, h.rootsErr
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+)
+
+// ErrModulePaused DLP处理已暂停
+var ErrModulePaused = errors.New("DLP模块已暂停")
+
+// pauseState 暂停状态和暂停期间跳过的数据统计
+type pauseState struct {
+	mu          sync.Mutex
+	paused      bool
+	pausedAt    time.Time
+	pauseCount  uint64
+	totalPaused time.Duration
+	skipped     map[string]uint64 // 按数据来源统计暂停期间跳过的数据
+}
+
+// newPauseState 创建暂停状态
+func newPauseState() *pauseState {
+	return &pauseState{skipped: make(map[string]uint64)}
+}
+
+// isPaused 判断是否已暂停
+func (ps *pauseState) isPaused() bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	return ps.paused
+}
+
+// pause 进入暂停状态，已暂停时返回 false
+func (ps *pauseState) pause(now time.Time) bool {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ps.paused {
+		return false
+	}
+	ps.paused = true
+	ps.pausedAt = now
+	ps.pauseCount++
+	return true
+}
+
+// resume 解除暂停，返回本次暂停的时长；未暂停时返回 false
+func (ps *pauseState) resume(now time.Time) (time.Duration, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if !ps.paused {
+		return 0, false
+	}
+	duration := now.Sub(ps.pausedAt)
+	ps.paused = false
+	ps.pausedAt = time.Time{}
+	ps.totalPaused += duration
+	return duration, true
+}
+
+// skip 记录一条因暂停而跳过的数据
+func (ps *pauseState) skip(source string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.skipped[source]++
+}
+
+// snapshot 返回暂停指标快照
+func (ps *pauseState) snapshot(now time.Time) map[string]interface{} {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	skipped := make(map[string]uint64, len(ps.skipped))
+	for source, count := range ps.skipped {
+		skipped[source] = count
+	}
+
+	totalPaused := ps.totalPaused
+	result := map[string]interface{}{
+		"paused":      ps.paused,
+		"pause_count": ps.pauseCount,
+		"skipped":     skipped,
+	}
+	if ps.paused {
+		result["paused_at"] = ps.pausedAt
+		result["paused_seconds"] = now.Sub(ps.pausedAt).Seconds()
+		totalPaused += now.Sub(ps.pausedAt)
+	}
+	result["total_paused_seconds"] = totalPaused.Seconds()
+	return result
+}
+
+// Pause 暂停DLP处理，组件保持运行
+// 暂停期间拦截器继续按监控模式放行数据包，但不再进行解析、分析和策略决策
+func (m *DLPModule) Pause() error {
+	m.mu.RLock()
+	running := m.running
+	m.mu.RUnlock()
+
+	if !running {
+		return fmt.Errorf("DLP模块未运行")
+	}
+	if !m.pauseState.pause(time.Now()) {
+		return nil
+	}
+
+	m.Logger.Warn("DLP处理已暂停，数据将不被检测")
+	return nil
+}
+
+// Resume 恢复DLP处理
+func (m *DLPModule) Resume() error {
+	duration, ok := m.pauseState.resume(time.Now())
+	if !ok {
+		return nil
+	}
+
+	m.Logger.Info("DLP处理已恢复", "paused_for", duration)
+	return nil
+}
+
+// IsPaused 判断DLP处理是否已暂停
+func (m *DLPModule) IsPaused() bool {
+	return m.pauseState.isPaused()
+}
+
+// CheckHealth 检查健康状态，暂停期间报告为降级
+func (m *DLPModule) CheckHealth() plugin.HealthStatus {
+	status := m.BaseModule.CheckHealth()
+	status.Details["pause"] = m.pauseState.snapshot(time.Now())
+
+	if err := m.HealthCheck(); err != nil {
+		status.Status = "unhealthy"
+		status.Details["error"] = err.Error()
+	} else if m.IsPaused() {
+		status.Status = "degraded"
+	}
+	return status
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sensitiveClipboard 包含敏感信息的剪贴板数据
+func sensitiveClipboard(id string) *DataContext {
+	return &DataContext{
+		ID:        id,
+		Type:      DataTypeClipboardContent,
+		Timestamp: time.Now(),
+		Data:      []byte("客户身份证号 110101199003077777，银行卡 6222021234567890128"),
+	}
+}
+
+func TestPauseResume_SkipsProcessing(t *testing.T) {
+	module := newRunningTestModule(t)
+
+	require.NoError(t, module.Pause())
+	require.NoError(t, module.Pause(), "重复暂停不应报错")
+	assert.True(t, module.IsPaused())
+
+	// 暂停期间不产生决策，也不计入处理指标
+	result, err := module.ProcessData(sensitiveClipboard("clip-paused"))
+	assert.ErrorIs(t, err, ErrModulePaused)
+	assert.False(t, result.Success)
+	assert.Empty(t, result.Data["decision_id"])
+
+	metrics := module.dlpMetrics()
+	assert.Equal(t, true, metrics["paused"])
+	assert.Empty(t, metrics["process_data"])
+	pause := metrics["pause"].(map[string]interface{})
+	assert.Equal(t, uint64(1), pause["pause_count"])
+	assert.Equal(t, map[string]uint64{DataTypeClipboardContent: 1}, pause["skipped"])
+
+	health := module.CheckHealth()
+	assert.Equal(t, "degraded", health.Status)
+
+	// 恢复后重新产生决策
+	require.NoError(t, module.Resume())
+	assert.False(t, module.IsPaused())
+
+	result, err = module.ProcessData(sensitiveClipboard("clip-resumed"))
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.NotEmpty(t, result.Data["decision_id"])
+	assert.NotEqual(t, "allow", result.Data["action"])
+
+	metrics = module.dlpMetrics()
+	assert.Equal(t, false, metrics["paused"])
+	stats := metrics["process_data"].(map[string]interface{})[DataTypeClipboardContent].(map[string]interface{})
+	assert.Equal(t, uint64(1), stats["total"])
+	assert.Equal(t, "healthy", module.CheckHealth().Status)
+}
+
+func TestPause_DropsQueuedTasks(t *testing.T) {
+	module := newRunningTestModule(t)
+	t.Cleanup(func() { close(module.stopCh) })
+
+	require.NoError(t, module.Pause())
+	for i := 0; i < 3; i++ {
+		require.True(t, module.enqueueTask(&ProcessingTask{ID: "task", Context: context.Background()}))
+	}
+	go module.processingWorker(0)
+
+	// 工作协程取出任务但不进入处理流水线
+	require.Eventually(t, func() bool {
+		skipped := module.pauseState.snapshot(time.Now())["skipped"].(map[string]uint64)
+		return skipped[DataTypeNetworkPacket] == 3
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, module.processingMetrics.snapshot())
+}
+
+func TestPause_RequiresRunningModule(t *testing.T) {
+	module := newRunningTestModule(t)
+	module.mu.Lock()
+	module.running = false
+	module.mu.Unlock()
+
+	assert.Error(t, module.Pause())
+	assert.False(t, module.IsPaused())
+	assert.NoError(t, module.Resume(), "未暂停时恢复不应报错")
+}
+
+func TestPauseResume_Requests(t *testing.T) {
+	module := newRunningTestModule(t)
+
+	resp, err := module.HandleRequest(context.Background(), &plugin.Request{ID: "1", Action: "pause"})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	assert.Equal(t, true, resp.Data["pause"].(map[string]interface{})["paused"])
+	assert.True(t, module.IsPaused())
+
+	resp, err = module.HandleRequest(context.Background(), &plugin.Request{ID: "2", Action: "resume"})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	assert.Equal(t, false, resp.Data["pause"].(map[string]interface{})["paused"])
+	assert.False(t, module.IsPaused())
+}
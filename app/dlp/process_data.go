@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/executor"
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/app/dlp/parser"
+	"github.com/lomehong/kennel/pkg/utils"
+)
+
+// 支持的数据类型
+const (
+	DataTypeNetworkPacket    = "network_packet"
+	DataTypeFileContent      = "file_content"
+	DataTypeClipboardContent = "clipboard_content"
+)
+
+// pipelineResult 处理流水线结果
+type pipelineResult struct {
+	ParsedData *parser.ParsedData
+	Decision   *engine.PolicyDecision
+	Execution  *executor.ExecutionResult
+}
+
+// processingStats 单个数据类型的处理统计
+type processingStats struct {
+	Total           uint64        `json:"total"`
+	Succeeded       uint64        `json:"succeeded"`
+	Failed          uint64        `json:"failed"`
+	NonAllow        uint64        `json:"non_allow"`
+	Bytes           uint64        `json:"bytes"`
+	TotalTime       time.Duration `json:"total_time"`
+	LastAction      string        `json:"last_action,omitempty"`
+	LastProcessedAt time.Time     `json:"last_processed_at"`
+}
+
+// processingMetrics 按数据类型统计的处理指标
+type processingMetrics struct {
+	stats map[string]*processingStats
+	mu    sync.Mutex
+}
+
+// newProcessingMetrics 创建处理指标
+func newProcessingMetrics() *processingMetrics {
+	return &processingMetrics{
+		stats: make(map[string]*processingStats),
+	}
+}
+
+// record 记录一次处理结果
+func (pm *processingMetrics) record(dataType string, size int, duration time.Duration, decision *engine.PolicyDecision, err error) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	stats, exists := pm.stats[dataType]
+	if !exists {
+		stats = &processingStats{}
+		pm.stats[dataType] = stats
+	}
+
+	stats.Total++
+	stats.Bytes += uint64(size)
+	stats.TotalTime += duration
+	stats.LastProcessedAt = time.Now()
+	if err != nil {
+		stats.Failed++
+		return
+	}
+
+	stats.Succeeded++
+	if decision != nil {
+		stats.LastAction = decision.Action.String()
+		if decision.Action != engine.PolicyActionAllow {
+			stats.NonAllow++
+		}
+	}
+}
+
+// snapshot 返回指标快照
+func (pm *processingMetrics) snapshot() map[string]interface{} {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	result := make(map[string]interface{}, len(pm.stats))
+	for dataType, stats := range pm.stats {
+		avgTime := time.Duration(0)
+		if stats.Total > 0 {
+			avgTime = stats.TotalTime / time.Duration(stats.Total)
+		}
+		result[dataType] = map[string]interface{}{
+			"total":             stats.Total,
+			"succeeded":         stats.Succeeded,
+			"failed":            stats.Failed,
+			"non_allow":         stats.NonAllow,
+			"bytes":             stats.Bytes,
+			"avg_time_ms":       float64(avgTime) / float64(time.Millisecond),
+			"last_action":       stats.LastAction,
+			"last_processed_at": stats.LastProcessedAt,
+		}
+	}
+	return result
+}
+
+// runPipeline 对已解析的数据执行 分析→策略→执行 流程
+// packet 为空表示非网络数据（文件、剪贴板）
+func (m *DLPModule) runPipeline(ctx context.Context, packet *interceptor.PacketInfo, parsedData *parser.ParsedData) (*pipelineResult, error) {
+	result := &pipelineResult{ParsedData: parsedData}
+
+	// 内容分析
+	analysisResult, err := m.analysisManager.AnalyzeContent(ctx, parsedData)
+	if err != nil {
+		return result, fmt.Errorf("内容分析失败: %w", err)
+	}
+
+	// 策略决策
+	decisionContext := &engine.DecisionContext{
+		PacketInfo:     packet,
+		ParsedData:     parsedData,
+		AnalysisResult: analysisResult,
+	}
+	// 统计发送进程的出站流量，超出配额时交由策略引擎处理
+	if packet != nil && m.trafficQuota != nil {
+		decisionContext.TrafficQuota = m.trafficQuota.Record(packet)
+		// 仅在本窗口首次超出时记录日志
+		if status := decisionContext.TrafficQuota; status != nil && status.Exceeded && status.Bytes-int64(packet.Size) <= status.Limit {
+			m.Logger.Warn("进程出站流量超出配额", "process", status.ProcessName, "pid", status.PID, "bytes", status.Bytes, "limit", status.Limit)
+		}
+	}
+
+	// 按发送数据的进程选择应用策略配置
+	if packet != nil && m.appProfiles != nil {
+		decisionContext.Profile = m.appProfiles.match(packet.ProcessInfo)
+	}
+
+	// 数据包所属的流，由流跟踪器在处理任务时写入
+	decisionContext.Flow = interceptor.PacketFlow(packet)
+
+	// 解析目的地址所属国家，未知时由地理围栏的 fail_closed 配置决定
+	if packet != nil && m.geoResolver != nil {
+		decisionContext.DestinationGeo = m.geoResolver.Enrich(packet)
+	}
+
+	decision, err := m.policyEngine.EvaluatePolicy(ctx, decisionContext)
+	if err != nil {
+		return result, fmt.Errorf("策略评估失败: %w", err)
+	}
+	result.Decision = decision
+	m.findingStats.recordDecision(time.Now(), decisionContext)
+
+	// 未被抽中的低风险决策只计入统计，不导出数据包、不执行动作
+	if decision.SampledOut {
+		return result, nil
+	}
+
+	// 非放行决策导出数据包用于离线分析
+	if packet != nil && m.pcapWriter != nil && decision.Action != engine.PolicyActionAllow {
+		if err := m.pcapWriter.Capture(packet); err != nil {
+			m.Logger.Warn("导出pcap失败", "packet_id", packet.ID, "error", err)
+		}
+	}
+
+	// 动作执行
+	execution, err := m.executionManager.ExecuteDecision(ctx, decision)
+	result.Execution = execution
+	if err != nil {
+		return result, fmt.Errorf("动作执行失败: %w", err)
+	}
+
+	return result, nil
+}
+
+// processNetworkData 处理网络数据：解析→分析→策略→执行
+func (m *DLPModule) processNetworkData(data *DataContext, result *ProcessResult) (*ProcessResult, error) {
+	m.Logger.Debug("处理网络数据", "data_id", data.ID)
+
+	packet := packetFromDataContext(data)
+
+	parsedData, err := m.protocolManager.ParsePacket(packet)
+	if err != nil {
+		return m.finishProcessing(data, result, nil, fmt.Errorf("协议解析失败: %w", err))
+	}
+
+	pipeline, err := m.runPipeline(context.Background(), packet, parsedData)
+	return m.finishProcessing(data, result, pipeline, err)
+}
+
+// processFileData 处理文件数据：分析→策略→执行
+func (m *DLPModule) processFileData(data *DataContext, result *ProcessResult) (*ProcessResult, error) {
+	m.Logger.Debug("处理文件数据", "data_id", data.ID)
+
+	parsedData := contentFromDataContext(data, "file")
+	if path := utils.GetString(data.Metadata, "file_path", ""); path != "" {
+		parsedData.URL = path
+	}
+
+	pipeline, err := m.runPipeline(context.Background(), nil, parsedData)
+	return m.finishProcessing(data, result, pipeline, err)
+}
+
+// processClipboardData 处理剪贴板数据：分析→策略→执行
+func (m *DLPModule) processClipboardData(data *DataContext, result *ProcessResult) (*ProcessResult, error) {
+	m.Logger.Debug("处理剪贴板数据", "data_id", data.ID)
+
+	parsedData := contentFromDataContext(data, "clipboard")
+
+	pipeline, err := m.runPipeline(context.Background(), nil, parsedData)
+	return m.finishProcessing(data, result, pipeline, err)
+}
+
+// finishProcessing 根据流水线结果填充处理结果并记录指标
+func (m *DLPModule) finishProcessing(data *DataContext, result *ProcessResult, pipeline *pipelineResult, err error) (*ProcessResult, error) {
+	result.Data["type"] = data.Type
+
+	var decision *engine.PolicyDecision
+	if pipeline != nil {
+		decision = pipeline.Decision
+		if pipeline.ParsedData != nil {
+			result.Data["protocol"] = pipeline.ParsedData.Protocol
+		}
+	}
+
+	if decision != nil {
+		matchedRules := make([]string, 0, len(decision.MatchedRules))
+		for _, rule := range decision.MatchedRules {
+			matchedRules = append(matchedRules, rule.RuleID)
+		}
+
+		result.Data["decision_id"] = decision.ID
+		result.Data["action"] = decision.Action.String()
+		result.Data["risk_level"] = decision.RiskLevel.String()
+		result.Data["risk_score"] = decision.RiskScore
+		result.Data["reason"] = decision.Reason
+		result.Data["matched_rules"] = matchedRules
+		if decision.SampledOut {
+			result.Data["sampled_out"] = true
+		}
+		if decision.Context != nil && decision.Context.AnalysisResult != nil {
+			result.Data["sensitive_count"] = len(decision.Context.AnalysisResult.SensitiveData)
+		}
+	}
+
+	if pipeline != nil && pipeline.Execution != nil {
+		result.Data["executed"] = pipeline.Execution.Success
+		if pipeline.Execution.Remediation != "" {
+			result.Data["remediation"] = pipeline.Execution.Remediation
+		}
+		if pipeline.Execution.Success {
+			result.Actions = append(result.Actions, pipeline.Execution.Action.String())
+		}
+	}
+
+	m.processingMetrics.record(data.Type, len(data.Data), time.Since(result.Timestamp), decision, err)
+
+	if err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	result.Success = true
+	return result, nil
+}
+
+// packetFromDataContext 根据数据上下文构造数据包信息
+func packetFromDataContext(data *DataContext) *interceptor.PacketInfo {
+	metadata := data.Metadata
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+
+	protocol := interceptor.ProtocolTCP
+	if strings.EqualFold(utils.GetString(metadata, "protocol", "tcp"), "udp") {
+		protocol = interceptor.ProtocolUDP
+	}
+
+	direction := interceptor.PacketDirectionOutbound
+	if utils.GetString(metadata, "direction", "outbound") == "inbound" {
+		direction = interceptor.PacketDirectionInbound
+	}
+
+	timestamp := data.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	var processInfo *interceptor.ProcessInfo
+	if name := utils.GetString(metadata, "process_name", ""); name != "" {
+		processInfo = &interceptor.ProcessInfo{
+			PID:         utils.GetInt(metadata, "pid", 0),
+			ProcessName: name,
+			ExecutePath: utils.GetString(metadata, "process_path", ""),
+		}
+	}
+
+	return &interceptor.PacketInfo{
+		ID:          data.ID,
+		Timestamp:   timestamp,
+		Direction:   direction,
+		Protocol:    protocol,
+		SourceIP:    net.ParseIP(utils.GetString(metadata, "source_ip", "127.0.0.1")),
+		DestIP:      net.ParseIP(utils.GetString(metadata, "dest_ip", "127.0.0.1")),
+		SourcePort:  uint16(utils.GetInt(metadata, "source_port", 0)),
+		DestPort:    uint16(utils.GetInt(metadata, "dest_port", 0)),
+		Payload:     data.Data,
+		Size:        len(data.Data),
+		Metadata:    metadata,
+		ProcessInfo: processInfo,
+	}
+}
+
+// contentFromDataContext 将文件或剪贴板内容包装为解析结果
+func contentFromDataContext(data *DataContext, protocol string) *parser.ParsedData {
+	metadata := make(map[string]interface{}, len(data.Metadata)+1)
+	for k, v := range data.Metadata {
+		metadata[k] = v
+	}
+	metadata["source"] = data.Source
+
+	return &parser.ParsedData{
+		Protocol:    protocol,
+		Headers:     make(map[string]string),
+		Body:        data.Data,
+		Metadata:    metadata,
+		ContentType: utils.GetString(data.Metadata, "content_type", "text/plain"),
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newRunningTestModule 创建只启动核心组件的DLP模块
+func newRunningTestModule(t *testing.T) *DLPModule {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	// 审计日志写入临时目录，避免在源码树中生成日志文件
+	logDir := t.TempDir()
+	module := NewDLPModule(logger)
+	err = module.Init(context.Background(), &plugin.ModuleConfig{
+		ID:   "dlp",
+		Name: "dlp",
+		Settings: map[string]interface{}{
+			"monitor_network":   false,
+			"monitor_files":     false,
+			"monitor_clipboard": false,
+			"audit": map[string]interface{}{
+				"log_path": filepath.Join(logDir, "engine_audit.log"),
+			},
+			"executor_config": map[string]interface{}{
+				"audit_writer": map[string]interface{}{
+					"path": filepath.Join(logDir, "dlp_audit.log"),
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, module.startCoreComponents())
+
+	module.mu.Lock()
+	module.running = true
+	module.mu.Unlock()
+
+	t.Cleanup(func() { module.stopCoreComponents() })
+	return module
+}
+
+func TestProcessData_RealDecisionPerType(t *testing.T) {
+	module := newRunningTestModule(t)
+
+	sensitive := "客户信息：身份证号 110101199003077777，银行卡 6222021234567890128，手机 13812345678"
+
+	request := fmt.Sprintf("POST /upload HTTP/1.1\r\nHost: example.com\r\nContent-Type: text/plain\r\n"+
+		"Content-Length: %d\r\n\r\n%s", len(sensitive), sensitive)
+
+	cases := []*DataContext{
+		{
+			ID:   "net-1",
+			Type: DataTypeNetworkPacket,
+			Data: []byte(request),
+			Metadata: map[string]interface{}{
+				"source_ip":   "192.168.1.10",
+				"dest_ip":     "203.0.113.5",
+				"source_port": 50000,
+				"dest_port":   80,
+			},
+		},
+		{
+			ID:       "file-1",
+			Type:     DataTypeFileContent,
+			Data:     []byte(sensitive),
+			Metadata: map[string]interface{}{"file_path": "C:\\Users\\test\\customers.txt"},
+		},
+		{
+			ID:   "clip-1",
+			Type: DataTypeClipboardContent,
+			Data: []byte(sensitive),
+		},
+	}
+
+	for _, data := range cases {
+		data.Timestamp = time.Now()
+		result, err := module.ProcessData(data)
+		require.NoError(t, err, data.Type)
+		require.True(t, result.Success, data.Type)
+
+		assert.Equal(t, data.Type, result.Data["type"])
+		assert.NotEmpty(t, result.Data["decision_id"], data.Type)
+		assert.NotEqual(t, "allow", result.Data["action"], "%s 敏感内容不应被直接放行", data.Type)
+		assert.Equal(t, "critical", result.Data["risk_level"], data.Type)
+		assert.Greater(t, result.Data["sensitive_count"], 0, data.Type)
+		assert.NotEmpty(t, result.Data["matched_rules"], data.Type)
+		assert.Contains(t, result.Actions, result.Data["action"], data.Type)
+	}
+
+	// 按数据类型统计处理指标
+	metrics := module.dlpMetrics()
+	processMetrics, ok := metrics["process_data"].(map[string]interface{})
+	require.True(t, ok)
+	for _, data := range cases {
+		stats, ok := processMetrics[data.Type].(map[string]interface{})
+		require.True(t, ok, data.Type)
+		assert.Equal(t, uint64(1), stats["total"], data.Type)
+		assert.Equal(t, uint64(1), stats["non_allow"], data.Type)
+		assert.Equal(t, uint64(0), stats["failed"], data.Type)
+	}
+}
+
+func TestProcessData_RejectsUnsupportedType(t *testing.T) {
+	module := newRunningTestModule(t)
+
+	result, err := module.ProcessData(&DataContext{ID: "x", Type: "unknown"})
+	assert.Error(t, err)
+	assert.False(t, result.Success)
+
+	module.mu.Lock()
+	module.running = false
+	module.mu.Unlock()
+
+	_, err = module.ProcessData(&DataContext{ID: "y", Type: DataTypeClipboardContent, Data: []byte("x")})
+	assert.Error(t, err)
+}
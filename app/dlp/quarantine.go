@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/lomehong/kennel/app/dlp/executor"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// parseQuarantineSettings 解析执行器配置中的隔离配置
+func parseQuarantineSettings(settings map[string]interface{}, config *executor.ExecutorConfig) {
+	quarantine := sdk.GetConfigMap(settings, "quarantine")
+	if len(quarantine) == 0 {
+		return
+	}
+
+	config.Quarantine.QuarantineDir = sdk.GetConfigString(quarantine, "quarantine_dir", config.Quarantine.QuarantineDir)
+	approverKeys := sdk.GetConfigMap(quarantine, "approver_keys")
+	if len(approverKeys) > 0 {
+		config.Quarantine.ApproverKeys = make(map[string]string, len(approverKeys))
+		for keyID, value := range approverKeys {
+			config.Quarantine.ApproverKeys[keyID] = fmt.Sprint(value)
+		}
+	}
+}
+
+// quarantineExecutor 获取隔离执行器
+func (m *DLPModule) quarantineExecutor() (*executor.QuarantineExecutorImpl, error) {
+	if m.executionManager == nil {
+		return nil, fmt.Errorf("执行管理器未初始化")
+	}
+	actionExecutor, exists := m.executionManager.GetExecutor(engine.PolicyActionQuarantine)
+	if !exists {
+		return nil, fmt.Errorf("隔离执行器未注册")
+	}
+	quarantineExecutor, ok := actionExecutor.(*executor.QuarantineExecutorImpl)
+	if !ok {
+		return nil, fmt.Errorf("隔离执行器不支持恢复文件")
+	}
+	return quarantineExecutor, nil
+}
+
+// listQuarantined 列出隔离的文件
+func (m *DLPModule) listQuarantined() ([]executor.QuarantinedFile, error) {
+	quarantineExecutor, err := m.quarantineExecutor()
+	if err != nil {
+		return nil, err
+	}
+	return quarantineExecutor.GetQuarantinedFiles(), nil
+}
+
+// releaseQuarantined 凭审批令牌恢复隔离的文件
+// expires_at 为 RFC3339 格式的时间
+func (m *DLPModule) releaseQuarantined(params map[string]interface{}) (*executor.QuarantinedFile, error) {
+	approval := executor.ReleaseApproval{
+		QuarantineID: sdk.GetConfigString(params, "quarantine_id", ""),
+		FileHash:     sdk.GetConfigString(params, "file_hash", ""),
+		Operator:     sdk.GetConfigString(params, "operator", ""),
+		KeyID:        sdk.GetConfigString(params, "key_id", ""),
+		Signature:    sdk.GetConfigString(params, "signature", ""),
+	}
+	if approval.QuarantineID == "" {
+		return nil, sdk.InvalidParamError("隔离ID不能为空")
+	}
+	expiresAt, err := time.Parse(time.RFC3339, sdk.GetConfigString(params, "expires_at", ""))
+	if err != nil {
+		return nil, sdk.InvalidParamError("无效的审批过期时间: %v", err)
+	}
+	approval.ExpiresAt = expiresAt
+
+	quarantineExecutor, err := m.quarantineExecutor()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := quarantineExecutor.ReleaseFile(approval)
+	switch {
+	case errors.Is(err, executor.ErrQuarantinedFileNotFound):
+		return nil, sdk.NotFoundError("%v", err)
+	case errors.Is(err, executor.ErrReleaseNotApproved), errors.Is(err, executor.ErrReleaseApprovalExpired):
+		return nil, sdk.UnauthorizedError("%v", err)
+	case err != nil:
+		return nil, err
+	}
+	return file, nil
+}
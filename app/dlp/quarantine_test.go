@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/executor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuarantineSettings(t *testing.T) {
+	config := executor.DefaultExecutorConfig()
+	parseQuarantineSettings(map[string]interface{}{
+		"quarantine": map[string]interface{}{
+			"quarantine_dir": "/var/lib/kennel/quarantine",
+			"approver_keys": map[string]interface{}{
+				"secops": "MCowBQYDK2VwAyEA",
+			},
+		},
+	}, &config)
+
+	assert.Equal(t, "/var/lib/kennel/quarantine", config.Quarantine.QuarantineDir)
+	assert.Equal(t, map[string]string{"secops": "MCowBQYDK2VwAyEA"}, config.Quarantine.ApproverKeys)
+
+	// 未配置隔离时保持默认值
+	config = executor.DefaultExecutorConfig()
+	parseQuarantineSettings(map[string]interface{}{}, &config)
+	assert.Empty(t, config.Quarantine.QuarantineDir)
+	assert.Empty(t, config.Quarantine.ApproverKeys)
+}
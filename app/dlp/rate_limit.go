@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// maxLimiterEvents 保留的最近流量限制事件数量
+const maxLimiterEvents = 100
+
+// limiterEventLog 最近的流量限制事件
+type limiterEventLog struct {
+	mu     sync.Mutex
+	events []interceptor.LimiterEvent
+}
+
+// newLimiterEventLog 创建流量限制事件记录
+func newLimiterEventLog() *limiterEventLog {
+	return &limiterEventLog{}
+}
+
+// add 记录事件，超出容量时丢弃最早的事件
+func (l *limiterEventLog) add(event interceptor.LimiterEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.events = append(l.events, event)
+	if len(l.events) > maxLimiterEvents {
+		l.events = l.events[len(l.events)-maxLimiterEvents:]
+	}
+}
+
+// list 返回最近的事件副本
+func (l *limiterEventLog) list() []interceptor.LimiterEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := make([]interceptor.LimiterEvent, len(l.events))
+	copy(events, l.events)
+	return events
+}
+
+// parseRateLimiterSettings 从配置项解析流量限制参数，未提供的参数保持原值
+// check_interval 以秒为单位
+func parseRateLimiterSettings(settings map[string]interface{}, config *interceptor.RateLimiterConfig) {
+	config.MaxPacketsPerSecond = int64(sdk.GetConfigInt(settings, "max_packets_per_second", int(config.MaxPacketsPerSecond)))
+	config.MaxBytesPerSecond = int64(sdk.GetConfigInt(settings, "max_bytes_per_second", int(config.MaxBytesPerSecond)))
+	config.BurstSize = int64(sdk.GetConfigInt(settings, "burst_size", int(config.BurstSize)))
+	config.CPUThreshold = getConfigFloat(settings, "cpu_threshold", config.CPUThreshold)
+	config.MemoryThreshold = getConfigFloat(settings, "memory_threshold", config.MemoryThreshold)
+	config.CheckInterval = time.Duration(sdk.GetConfigInt(settings, "check_interval", int(config.CheckInterval/time.Second))) * time.Second
+}
+
+// getConfigFloat 获取配置浮点数
+func getConfigFloat(config map[string]interface{}, key string, defaultValue float64) float64 {
+	if value, ok := config[key]; ok {
+		switch v := value.(type) {
+		case float64:
+			return v
+		case int:
+			return float64(v)
+		case int64:
+			return float64(v)
+		}
+	}
+	return defaultValue
+}
+
+// rateLimitController 获取支持运行时调整流量限制的拦截器
+func (m *DLPModule) rateLimitController() (interceptor.RateLimitController, error) {
+	if m.interceptorManager == nil {
+		return nil, fmt.Errorf("拦截器管理器未初始化")
+	}
+	trafficInterceptor, ok := m.interceptorManager.GetInterceptor("traffic")
+	if !ok {
+		return nil, fmt.Errorf("流量拦截器未启动")
+	}
+	controller, ok := trafficInterceptor.(interceptor.RateLimitController)
+	if !ok {
+		return nil, fmt.Errorf("当前平台的流量拦截器不支持流量限制")
+	}
+	return controller, nil
+}
+
+// handleLimiterEvent 处理流量限制开始和解除事件
+func (m *DLPModule) handleLimiterEvent(event interceptor.LimiterEvent) {
+	m.limiterEvents.add(event)
+
+	if event.Type == interceptor.LimiterEventEngaged {
+		m.Logger.Warn("网络流量限制生效，部分数据包将不被检测", "reason", event.Reason)
+	} else {
+		m.Logger.Info("网络流量限制解除", "reason", event.Reason)
+	}
+}
+
+// getLimiterState 获取流量限制器状态和最近的事件
+func (m *DLPModule) getLimiterState() (map[string]interface{}, error) {
+	controller, err := m.rateLimitController()
+	if err != nil {
+		return nil, err
+	}
+	state, err := controller.GetLimiterState()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"state":  state,
+		"events": m.limiterEvents.list(),
+	}, nil
+}
+
+// setLimiterParams 更新流量限制参数，未提供的参数保持当前值
+func (m *DLPModule) setLimiterParams(params map[string]interface{}) (interceptor.LimiterState, error) {
+	controller, err := m.rateLimitController()
+	if err != nil {
+		return interceptor.LimiterState{}, err
+	}
+	state, err := controller.GetLimiterState()
+	if err != nil {
+		return interceptor.LimiterState{}, err
+	}
+
+	limits := state.Params
+	parseRateLimiterSettings(params, &limits)
+	if err := controller.SetLimiterParams(limits); err != nil {
+		return interceptor.LimiterState{}, sdk.InvalidParamError("%v", err)
+	}
+
+	m.mu.Lock()
+	if m.dlpConfig != nil {
+		m.dlpConfig.InterceptorConfig.RateLimiter = limits
+	}
+	m.mu.Unlock()
+
+	m.Logger.Info("更新网络流量限制参数",
+		"max_packets_per_second", limits.MaxPacketsPerSecond,
+		"max_bytes_per_second", limits.MaxBytesPerSecond,
+		"burst_size", limits.BurstSize,
+		"cpu_threshold", limits.CPUThreshold,
+		"memory_threshold", limits.MemoryThreshold,
+		"check_interval", limits.CheckInterval)
+
+	return controller.GetLimiterState()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/app/dlp/interceptor"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// limitedInterceptor 带自适应流量限制器的测试拦截器
+type limitedInterceptor struct {
+	interceptor.TrafficInterceptor
+	limiter *interceptor.AdaptiveLimiter
+}
+
+func (l *limitedInterceptor) GetLimiterState() (interceptor.LimiterState, error) {
+	return l.limiter.GetLimiterState(), nil
+}
+
+func (l *limitedInterceptor) SetLimiterParams(params interceptor.RateLimiterConfig) error {
+	return l.limiter.SetLimiterParams(params)
+}
+
+func (l *limitedInterceptor) SetLimiterEventHandler(handler interceptor.LimiterEventHandler) {
+	l.limiter.SetEventHandler(handler)
+}
+
+// newRateLimitTestModule 创建注册了测试拦截器的模块
+func newRateLimitTestModule(t *testing.T) (*DLPModule, *limitedInterceptor) {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+
+	module := NewDLPModule(logger)
+	module.dlpConfig = &DLPConfig{InterceptorConfig: interceptor.DefaultInterceptorConfig()}
+	module.interceptorManager = interceptor.NewInterceptorManager(logger)
+
+	traffic := &limitedInterceptor{
+		limiter: interceptor.NewAdaptiveLimiterFromConfig(module.dlpConfig.InterceptorConfig.RateLimiter, logger),
+	}
+	traffic.SetLimiterEventHandler(module.handleLimiterEvent)
+	require.NoError(t, module.interceptorManager.RegisterInterceptor("traffic", traffic))
+	return module, traffic
+}
+
+func TestParseRateLimiterSettings(t *testing.T) {
+	config := interceptor.DefaultRateLimiterConfig()
+	parseRateLimiterSettings(map[string]interface{}{
+		"max_packets_per_second": 200,
+		"burst_size":             float64(20),
+		"cpu_threshold":          75.5,
+		"check_interval":         30,
+	}, &config)
+
+	assert.Equal(t, int64(200), config.MaxPacketsPerSecond)
+	assert.Equal(t, int64(20), config.BurstSize)
+	assert.Equal(t, 75.5, config.CPUThreshold)
+	assert.Equal(t, 30*time.Second, config.CheckInterval)
+	// 未提供的参数保持原值
+	assert.Equal(t, interceptor.DefaultRateLimiterConfig().MaxBytesPerSecond, config.MaxBytesPerSecond)
+	assert.Equal(t, interceptor.DefaultRateLimiterConfig().MemoryThreshold, config.MemoryThreshold)
+}
+
+func TestSetLimiterParams(t *testing.T) {
+	module, traffic := newRateLimitTestModule(t)
+
+	state, err := module.setLimiterParams(map[string]interface{}{"burst_size": float64(3), "max_packets_per_second": float64(1)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), state.Params.BurstSize)
+	assert.Equal(t, int64(3), state.EffectiveBurstSize)
+	assert.Equal(t, interceptor.DefaultRateLimiterConfig().MaxBytesPerSecond, state.Params.MaxBytesPerSecond)
+	assert.Equal(t, int64(3), module.dlpConfig.InterceptorConfig.RateLimiter.BurstSize)
+
+	// 新参数生效：超出突发大小的数据包被丢弃，并记录生效事件
+	for i := 0; i < 3; i++ {
+		assert.True(t, traffic.limiter.AllowPacket(100))
+	}
+	assert.False(t, traffic.limiter.AllowPacket(100))
+
+	data, err := module.getLimiterState()
+	require.NoError(t, err)
+	current := data["state"].(interceptor.LimiterState)
+	assert.True(t, current.Engaged)
+	assert.Equal(t, uint64(1), current.PacketsDropped)
+	events := data["events"].([]interceptor.LimiterEvent)
+	require.Len(t, events, 1)
+	assert.Equal(t, interceptor.LimiterEventEngaged, events[0].Type)
+
+	// 无效参数返回错误且不修改配置
+	_, err = module.setLimiterParams(map[string]interface{}{"burst_size": 0})
+	assert.Error(t, err)
+	assert.Equal(t, int64(3), module.dlpConfig.InterceptorConfig.RateLimiter.BurstSize)
+}
+
+func TestLimiterWithoutInterceptor(t *testing.T) {
+	logger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+	require.NoError(t, err)
+	module := NewDLPModule(logger)
+	module.interceptorManager = interceptor.NewInterceptorManager(logger)
+
+	_, err = module.getLimiterState()
+	assert.Error(t, err)
+	_, err = module.setLimiterParams(map[string]interface{}{"burst_size": 10})
+	assert.Error(t, err)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lomehong/kennel/app/dlp/executor"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// parseRemediationSettings 解析执行器配置中的修复建议模板，配置的模板覆盖同名默认模板
+func parseRemediationSettings(settings map[string]interface{}, config *executor.ExecutorConfig) {
+	templates := sdk.GetConfigMap(settings, "remediation_templates")
+	if len(templates) == 0 {
+		return
+	}
+
+	merged := executor.DefaultRemediationTemplates()
+	for key, value := range config.RemediationTemplates {
+		merged[key] = value
+	}
+	for key, value := range templates {
+		merged[key] = fmt.Sprint(value)
+	}
+	config.RemediationTemplates = merged
+}
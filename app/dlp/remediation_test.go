@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/executor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRemediationSettings(t *testing.T) {
+	config := executor.DefaultExecutorConfig()
+	parseRemediationSettings(map[string]interface{}{
+		"remediation_templates": map[string]interface{}{
+			"block": "联系安全团队解除对 ${dest_ip} 的阻断",
+			"allow": "",
+		},
+	}, &config)
+
+	assert.Equal(t, "联系安全团队解除对 ${dest_ip} 的阻断", config.RemediationTemplates["block"])
+	assert.Equal(t, "", config.RemediationTemplates["allow"])
+	// 未配置的模板保留默认值
+	assert.Equal(t, executor.DefaultRemediationTemplates()["quarantine"], config.RemediationTemplates["quarantine"])
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/lomehong/kennel/pkg/logging"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
 )
 
 // 辅助函数，用于从配置中获取字符串值
@@ -139,7 +140,7 @@ func (m *RuleManager) addRuleInternal(rule *DLPRule) error {
 	// 编译正则表达式
 	regex, err := regexp.Compile(rule.Pattern)
 	if err != nil {
-		return fmt.Errorf("编译正则表达式失败: %w", err)
+		return sdk.WrapError(sdk.ErrorCodeInvalidParam, fmt.Errorf("编译正则表达式失败: %w", err))
 	}
 	rule.regex = regex
 
@@ -150,6 +151,31 @@ func (m *RuleManager) addRuleInternal(rule *DLPRule) error {
 	return nil
 }
 
+// ReplaceRules 用给定规则替换全部规则，任一规则无效时保持现有规则不变
+func (m *RuleManager) ReplaceRules(rules []*DLPRule) error {
+	replaced := make(map[string]*DLPRule, len(rules))
+	for _, rule := range rules {
+		if rule.ID == "" || rule.Pattern == "" {
+			return sdk.InvalidParamError("规则缺少必要字段: ID=%s, Pattern=%s", rule.ID, rule.Pattern)
+		}
+		if _, exists := replaced[rule.ID]; exists {
+			return sdk.InvalidParamError("规则ID已存在: %s", rule.ID)
+		}
+		regex, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return sdk.WrapError(sdk.ErrorCodeInvalidParam, fmt.Errorf("编译正则表达式失败 [%s]: %w", rule.ID, err))
+		}
+		rule.regex = regex
+		replaced[rule.ID] = rule
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = replaced
+	m.logger.Info("替换规则", "count", len(replaced))
+	return nil
+}
+
 // getDefaultRules 获取默认规则
 func (m *RuleManager) getDefaultRules() []*DLPRule {
 	return []*DLPRule{
@@ -234,13 +260,13 @@ func (m *RuleManager) AddRule(rule *DLPRule) error {
 
 	// 检查规则ID是否已存在
 	if _, exists := m.rules[rule.ID]; exists {
-		return fmt.Errorf("规则ID已存在: %s", rule.ID)
+		return sdk.InvalidParamError("规则ID已存在: %s", rule.ID)
 	}
 
 	// 编译正则表达式
 	regex, err := regexp.Compile(rule.Pattern)
 	if err != nil {
-		return fmt.Errorf("编译正则表达式失败: %w", err)
+		return sdk.WrapError(sdk.ErrorCodeInvalidParam, fmt.Errorf("编译正则表达式失败: %w", err))
 	}
 	rule.regex = regex
 
@@ -258,13 +284,13 @@ func (m *RuleManager) UpdateRule(rule *DLPRule) error {
 
 	// 检查规则ID是否存在
 	if _, exists := m.rules[rule.ID]; !exists {
-		return fmt.Errorf("规则ID不存在: %s", rule.ID)
+		return sdk.NotFoundError("规则ID不存在: %s", rule.ID)
 	}
 
 	// 编译正则表达式
 	regex, err := regexp.Compile(rule.Pattern)
 	if err != nil {
-		return fmt.Errorf("编译正则表达式失败: %w", err)
+		return sdk.WrapError(sdk.ErrorCodeInvalidParam, fmt.Errorf("编译正则表达式失败: %w", err))
 	}
 	rule.regex = regex
 
@@ -282,7 +308,7 @@ func (m *RuleManager) DeleteRule(id string) error {
 
 	// 检查规则ID是否存在
 	if _, exists := m.rules[id]; !exists {
-		return fmt.Errorf("规则ID不存在: %s", id)
+		return sdk.NotFoundError("规则ID不存在: %s", id)
 	}
 
 	// 删除规则
@@ -300,7 +326,7 @@ func (m *RuleManager) EnableRule(id string) error {
 	// 检查规则ID是否存在
 	rule, exists := m.rules[id]
 	if !exists {
-		return fmt.Errorf("规则ID不存在: %s", id)
+		return sdk.NotFoundError("规则ID不存在: %s", id)
 	}
 
 	// 启用规则
@@ -318,7 +344,7 @@ func (m *RuleManager) DisableRule(id string) error {
 	// 检查规则ID是否存在
 	rule, exists := m.rules[id]
 	if !exists {
-		return fmt.Errorf("规则ID不存在: %s", id)
+		return sdk.NotFoundError("规则ID不存在: %s", id)
 	}
 
 	// 禁用规则
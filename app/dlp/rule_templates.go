@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
+)
+
+// parseRuleTemplateSettings 解析策略引擎配置中的规则模板和模板规则
+func parseRuleTemplateSettings(settings map[string]interface{}, config *engine.PolicyEngineConfig) error {
+	for _, item := range sdk.GetConfigSlice(settings, "rule_templates") {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		template := &engine.RuleTemplate{
+			ID:          sdk.GetConfigString(entry, "id", ""),
+			Description: sdk.GetConfigString(entry, "description", ""),
+			Defaults:    sdk.GetConfigMap(entry, "defaults"),
+		}
+		for _, name := range sdk.GetConfigSlice(entry, "variables") {
+			template.Variables = append(template.Variables, fmt.Sprint(name))
+		}
+		rule, err := parseTemplateRule(sdk.GetConfigMap(entry, "rule"))
+		if err != nil {
+			return fmt.Errorf("规则模板 %s: %w", template.ID, err)
+		}
+		template.Rule = rule
+		config.RuleTemplates = append(config.RuleTemplates, template)
+	}
+
+	for _, item := range sdk.GetConfigSlice(settings, "template_rules") {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		config.TemplateRules = append(config.TemplateRules, &engine.RuleTemplateInstance{
+			Template: sdk.GetConfigString(entry, "template", ""),
+			Params:   sdk.GetConfigMap(entry, "params"),
+		})
+	}
+	return nil
+}
+
+// parseTemplateRule 解析模板中的规则体
+func parseTemplateRule(settings map[string]interface{}) (engine.PolicyRule, error) {
+	rule := engine.PolicyRule{
+		ID:          sdk.GetConfigString(settings, "id", ""),
+		Name:        sdk.GetConfigString(settings, "name", ""),
+		Description: sdk.GetConfigString(settings, "description", ""),
+		Type:        sdk.GetConfigString(settings, "type", ""),
+		Priority:    sdk.GetConfigInt(settings, "priority", 50),
+		Enabled:     sdk.GetConfigBool(settings, "enabled", true),
+		Version:     sdk.GetConfigString(settings, "version", "1.0"),
+		Metadata:    sdk.GetConfigMap(settings, "metadata"),
+	}
+
+	for _, item := range sdk.GetConfigSlice(settings, "conditions") {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rule.Conditions = append(rule.Conditions, &engine.RuleCondition{
+			Field:    sdk.GetConfigString(entry, "field", ""),
+			Operator: sdk.GetConfigString(entry, "operator", ""),
+			Value:    entry["value"],
+			Type:     sdk.GetConfigString(entry, "type", ""),
+		})
+	}
+
+	for _, item := range sdk.GetConfigSlice(settings, "actions") {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name := sdk.GetConfigString(entry, "type", "")
+		action, ok := engine.ParsePolicyAction(name)
+		if !ok {
+			return rule, fmt.Errorf("未知的动作类型: %s", name)
+		}
+		rule.Actions = append(rule.Actions, &engine.RuleAction{
+			Type:       action,
+			Parameters: sdk.GetConfigMap(entry, "parameters"),
+		})
+	}
+	return rule, nil
+}
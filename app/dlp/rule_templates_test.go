@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lomehong/kennel/app/dlp/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRuleTemplateSettings(t *testing.T) {
+	config := engine.DefaultPolicyEngineConfig()
+	err := parseRuleTemplateSettings(map[string]interface{}{
+		"rule_templates": []interface{}{
+			map[string]interface{}{
+				"id":        "block_port",
+				"variables": []interface{}{"port"},
+				"rule": map[string]interface{}{
+					"id":   "block_port_${port}",
+					"name": "阻断端口${port}",
+					"conditions": []interface{}{
+						map[string]interface{}{"field": "packet_info.dest_port", "operator": "equals", "value": "${port}"},
+					},
+					"actions": []interface{}{
+						map[string]interface{}{"type": "block"},
+					},
+				},
+			},
+		},
+		"template_rules": []interface{}{
+			map[string]interface{}{"template": "block_port", "params": map[string]interface{}{"port": 21}},
+		},
+	}, &config)
+	require.NoError(t, err)
+
+	rules, err := engine.ExpandRuleTemplates(config.RuleTemplates, config.TemplateRules)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "block_port_21", rules[0].ID)
+	assert.Equal(t, 50, rules[0].Priority)
+	assert.True(t, rules[0].Enabled)
+	assert.Equal(t, 21, rules[0].Conditions[0].Value)
+	assert.Equal(t, engine.PolicyActionBlock, rules[0].Actions[0].Type)
+
+	err = parseRuleTemplateSettings(map[string]interface{}{
+		"rule_templates": []interface{}{
+			map[string]interface{}{
+				"id":   "bad",
+				"rule": map[string]interface{}{"actions": []interface{}{map[string]interface{}{"type": "explode"}}},
+			},
+		},
+	}, &engine.PolicyEngineConfig{})
+	assert.ErrorContains(t, err, "未知的动作类型: explode")
+}
@@ -7,9 +7,12 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/lomehong/kennel/app/dlp/analyzer"
 	"github.com/lomehong/kennel/pkg/logging"
+	sdk "github.com/lomehong/kennel/pkg/sdk/go"
 )
 
 // 辅助函数，用于从配置中获取布尔值
@@ -44,15 +47,26 @@ type Scanner struct {
 	ruleManager  *RuleManager
 	alertManager *AlertManager
 	config       map[string]interface{}
+
+	// 文件监控器
+	fileMonitor *FileMonitor
+	mu          sync.Mutex
+
+	// 警报内容的脱敏器，规则ID作为敏感数据类型
+	masker *analyzer.Masker
 }
 
 // NewScanner 创建一个新的扫描器
 func NewScanner(logger logging.Logger, ruleManager *RuleManager, alertManager *AlertManager, config map[string]interface{}) *Scanner {
+	masking := analyzer.DefaultMaskingConfig()
+	parseMaskingSettings(sdk.GetConfigMap(config, "masking"), &masking)
+
 	return &Scanner{
 		logger:       logger,
 		ruleManager:  ruleManager,
 		alertManager: alertManager,
 		config:       config,
+		masker:       analyzer.NewMasker(masking),
 	}
 }
 
@@ -62,7 +76,7 @@ func (s *Scanner) ScanFile(path string) ([]DLPAlert, error) {
 
 	// 检查文件是否存在
 	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil, fmt.Errorf("文件不存在: %s", path)
+		return nil, sdk.NotFoundError("文件不存在: %s", path)
 	}
 
 	// 读取文件内容
@@ -89,7 +103,7 @@ func (s *Scanner) ScanDirectory(dir string) ([]DLPAlert, error) {
 
 	// 检查目录是否存在
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("目录不存在: %s", dir)
+		return nil, sdk.NotFoundError("目录不存在: %s", dir)
 	}
 
 	// 获取监控的文件类型
@@ -113,14 +127,7 @@ func (s *Scanner) ScanDirectory(dir string) ([]DLPAlert, error) {
 		}
 
 		// 检查文件类型
-		matched := false
-		for _, pattern := range fileTypes {
-			if matched, _ = filepath.Match(pattern, filepath.Base(path)); matched {
-				break
-			}
-		}
-
-		if !matched {
+		if !matchesFileType(fileTypes, path) {
 			return nil
 		}
 
@@ -203,7 +210,7 @@ func (s *Scanner) ScanContent(content, source, sourceType string) []DLPAlert {
 			alert := DLPAlert{
 				RuleID:      rule.ID,
 				RuleName:    rule.Name,
-				Content:     match,
+				Content:     s.masker.Preview(rule.ID, match),
 				Source:      source,
 				Destination: sourceType,
 				Action:      rule.Action,
@@ -255,9 +262,40 @@ func (s *Scanner) MonitorFiles() error {
 		return nil
 	}
 
-	// 在实际应用中，这里应该启动一个后台协程监控文件变化
-	// 由于这是平台相关的，这里只是一个示例
-	s.logger.Info("文件监控已启动", "directories", strings.Join(dirs, ", "))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fileMonitor != nil {
+		return nil
+	}
+
+	// 只在文件创建、修改和重命名时扫描匹配监控类型的文件
+	fileTypes := getConfigStringSliceFromScanner(s.config, "monitored_file_types")
+	debounce := time.Duration(sdk.GetConfigInt(s.config, "file_monitor_debounce", int(DefaultFileMonitorDebounce/time.Millisecond))) * time.Millisecond
+	monitor, err := NewFileMonitor(s.logger, fileTypes, debounce, func(path string) {
+		if _, err := s.ScanFile(path); err != nil {
+			s.logger.Error("扫描文件失败", "path", path, "error", err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	watched := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		dir = expandMonitoredPath(dir)
+		if err := monitor.Watch(dir); err != nil {
+			s.logger.Warn("无法监控目录", "dir", dir, "error", err)
+			continue
+		}
+		watched = append(watched, dir)
+	}
+	if len(watched) == 0 {
+		monitor.Stop()
+		return fmt.Errorf("没有可监控的目录")
+	}
+
+	s.fileMonitor = monitor
+	s.logger.Info("文件监控已启动", "directories", strings.Join(watched, ", "), "debounce", debounce)
 
 	return nil
 }
@@ -266,8 +304,17 @@ func (s *Scanner) MonitorFiles() error {
 func (s *Scanner) StopMonitoring() error {
 	s.logger.Info("停止监控")
 
-	// 在实际应用中，这里应该停止所有监控
-	// 由于这是平台相关的，这里只是一个示例
+	s.mu.Lock()
+	monitor := s.fileMonitor
+	s.fileMonitor = nil
+	s.mu.Unlock()
+
+	if monitor != nil {
+		if err := monitor.Stop(); err != nil {
+			return fmt.Errorf("停止文件监控失败: %w", err)
+		}
+	}
+
 	s.logger.Info("监控已停止")
 
 	return nil
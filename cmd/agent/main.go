@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -15,8 +16,9 @@ import (
 
 var (
 	// 旧的应用程序变量
-	cfgFile string
-	app     *core.App
+	cfgFile   string
+	app       *core.App
+	checkOnly bool
 
 	// 新的配置变量
 	configPath string
@@ -38,6 +40,7 @@ var (
 func init() {
 	// 全局标志
 	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "配置文件路径")
+	startCmd.Flags().BoolVar(&checkOnly, "check", false, "仅执行启动前就绪检查，不启动代理")
 
 	// 添加子命令
 	rootCmd.AddCommand(versionCmd)
@@ -65,6 +68,7 @@ var pluginCmd = &cobra.Command{
 func init() {
 	pluginCmd.AddCommand(pluginListCmd)
 	pluginCmd.AddCommand(pluginLoadCmd)
+	pluginCmd.AddCommand(pluginUsageCmd)
 }
 
 // plugin list命令
@@ -77,6 +81,12 @@ var pluginListCmd = &cobra.Command{
 			app = core.NewApp(cfgFile)
 		}
 
+		// 仅执行就绪检查
+		if checkOnly {
+			runReadinessCheck()
+			return
+		}
+
 		if err := app.Init(); err != nil {
 			fmt.Printf("初始化应用程序失败: %v\n", err)
 			os.Exit(1)
@@ -168,6 +178,20 @@ var startCmd = &cobra.Command{
 	},
 }
 
+// runReadinessCheck 执行就绪检查并输出报告，未就绪时以非零状态退出
+func runReadinessCheck() {
+	report, err := app.CheckReadiness(context.Background())
+	if err != nil {
+		fmt.Printf("就绪检查失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(report.String())
+	if !report.Ready {
+		os.Exit(1)
+	}
+}
+
 // setupSignalHandlers 设置信号处理器
 func setupSignalHandlers() {
 	// 创建一个通道，用于接收信号
@@ -176,16 +200,28 @@ func setupSignalHandlers() {
 	// 注册要处理的信号
 	// SIGINT: Ctrl+C
 	// SIGTERM: 终止信号，通常由系统发送
-	// SIGHUP: 终端关闭时发送
+	// SIGHUP: 升级插件目录中版本发生变化的插件
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
 	// 在后台处理信号
 	go func() {
-		sig := <-sigCh
-		fmt.Printf("\n收到信号 %v，开始优雅终止...\n", sig)
-
-		// 停止应用程序
-		app.Stop()
+		for sig := range sigCh {
+			if sig == syscall.SIGHUP {
+				fmt.Println("\n收到 SIGHUP 信号，开始升级插件...")
+				if upgraded, err := app.UpgradePlugins(); err != nil {
+					fmt.Printf("升级插件失败: %v\n", err)
+				} else {
+					fmt.Printf("已升级插件: %v\n", upgraded)
+				}
+				continue
+			}
+
+			fmt.Printf("\n收到信号 %v，开始优雅终止...\n", sig)
+
+			// 停止应用程序
+			app.Stop()
+			return
+		}
 	}()
 }
 
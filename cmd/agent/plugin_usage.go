@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/core/pluginusage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageAPIAddr  string
+	usageUsername string
+	usagePassword string
+	usageRefresh  bool
+	usageJSON     bool
+)
+
+func init() {
+	pluginUsageCmd.Flags().StringVar(&usageAPIAddr, "addr", "http://127.0.0.1:8088/api", "运行中代理的Web控制台API地址")
+	pluginUsageCmd.Flags().StringVar(&usageUsername, "username", "", "Web控制台用户名（启用认证时）")
+	pluginUsageCmd.Flags().StringVar(&usagePassword, "password", "", "Web控制台密码（启用认证时）")
+	pluginUsageCmd.Flags().BoolVar(&usageRefresh, "refresh", false, "立即重新采样，而不是返回最近一次采样的结果")
+	pluginUsageCmd.Flags().BoolVar(&usageJSON, "json", false, "以JSON格式输出")
+}
+
+// plugin usage命令
+var pluginUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "查看运行中代理各插件的CPU和内存占用",
+	Run: func(cmd *cobra.Command, args []string) {
+		report, err := fetchPluginUsage()
+		if err != nil {
+			fmt.Printf("获取插件资源占用失败: %v\n", err)
+			os.Exit(1)
+		}
+
+		if usageJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				fmt.Printf("输出JSON失败: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		if len(report.Plugins) == 0 {
+			fmt.Println("没有运行中的插件")
+			return
+		}
+		fmt.Printf("采样时间: %s\n", report.Timestamp.Format(time.RFC3339))
+		fmt.Print(report.String())
+	},
+}
+
+// fetchPluginUsage 通过Web控制台API获取插件资源占用报告
+func fetchPluginUsage() (*pluginusage.Report, error) {
+	url := strings.TrimRight(usageAPIAddr, "/") + "/metrics/plugins"
+	if usageRefresh {
+		url += "?refresh=true"
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if usageUsername != "" {
+		req.SetBasicAuth(usageUsername, usagePassword)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("请求 %s 返回 %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var report pluginusage.Report
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &report, nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lomehong/kennel/pkg/core/config"
+)
+
+// configPaths 可重复指定的配置文件参数
+type configPaths []string
+
+func (p *configPaths) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *configPaths) Set(value string) error {
+	for _, path := range strings.Split(value, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			*p = append(*p, path)
+		}
+	}
+	return nil
+}
+
+func main() {
+	var paths configPaths
+	flag.Var(&paths, "config", "配置文件路径，可重复指定或用逗号分隔，后面的文件优先级更高 (默认: config.yaml)")
+	var (
+		envPrefix = flag.String("env-prefix", "APPFW", "环境变量前缀")
+		defaults  = flag.Bool("defaults", true, "合并组件登记的默认值")
+		format    = flag.String("format", "tree", "输出格式: tree 或 json")
+		help      = flag.Bool("help", false, "显示帮助信息")
+	)
+	flag.Parse()
+
+	if *help {
+		showHelp()
+		return
+	}
+
+	if len(paths) == 0 {
+		paths = configPaths{"config.yaml"}
+	}
+
+	var registry *config.DefaultsRegistry
+	if *defaults {
+		registry = config.RegisteredDefaults()
+	}
+
+	effective, sources, err := config.ComputeEffectiveWithDefaults(paths, *envPrefix, registry)
+	if err != nil {
+		fmt.Printf("错误: 计算有效配置失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch *format {
+	case "tree":
+		fmt.Println("Kennel有效配置")
+		fmt.Println("=====================================")
+		fmt.Printf("配置文件: %s\n", strings.Join(paths, " < "))
+		fmt.Printf("环境变量前缀: %s\n\n", *envPrefix)
+		bySource := make(map[string]config.Source, len(sources))
+		for _, source := range sources {
+			bySource[source.Key] = source
+		}
+		printTree(effective, "", "", bySource)
+	case "json":
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"config":  effective,
+			"sources": sources,
+		}, "", "  ")
+		if err != nil {
+			fmt.Printf("错误: 序列化有效配置失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	default:
+		fmt.Printf("错误: 不支持的输出格式 %s\n", *format)
+		os.Exit(1)
+	}
+}
+
+// printTree 以树形打印配置，叶子值后标注来源
+func printTree(node map[string]interface{}, prefix, indent string, sources map[string]config.Source) {
+	keys := make([]string, 0, len(node))
+	for k := range node {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for i, k := range keys {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		branch, childIndent := "├── ", indent+"│   "
+		if i == len(keys)-1 {
+			branch, childIndent = "└── ", indent+"    "
+		}
+
+		if nested, ok := node[k].(map[string]interface{}); ok {
+			fmt.Printf("%s%s%s\n", indent, branch, k)
+			printTree(nested, key, childIndent, sources)
+			continue
+		}
+
+		source := "未知"
+		if s, ok := sources[key]; ok {
+			source = s.String()
+		}
+		fmt.Printf("%s%s%s: %v  [%s]\n", indent, branch, k, node[k], source)
+	}
+}
+
+func showHelp() {
+	fmt.Println("Kennel有效配置查看工具")
+	fmt.Println()
+	fmt.Println("用法:")
+	fmt.Println("  config-effective [选项]")
+	fmt.Println()
+	fmt.Println("选项:")
+	fmt.Println("  -config string      配置文件路径，可重复指定，后面的文件覆盖前面的文件 (默认: config.yaml)")
+	fmt.Println("  -env-prefix string  环境变量前缀 (默认: APPFW)")
+	fmt.Println("  -defaults           合并组件登记的默认值，-defaults=false 只显示配置文件和环境变量 (默认: true)")
+	fmt.Println("  -format string      输出格式: tree 或 json (默认: tree)")
+	fmt.Println("  -help               显示帮助信息")
+	fmt.Println()
+	fmt.Println("功能:")
+	fmt.Println("  - 显示合并组件默认值、配置文件和环境变量覆盖后的最终配置")
+	fmt.Println("  - 标注每个配置值来自哪个组件默认值、文件或环境变量")
+}
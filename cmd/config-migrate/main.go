@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/lomehong/kennel/pkg/core/config"
 )
@@ -13,6 +14,7 @@ func main() {
 		sourceFile = flag.String("source", "config.yaml", "源配置文件路径")
 		targetFile = flag.String("target", "config.new.yaml", "目标配置文件路径")
 		backup     = flag.Bool("backup", true, "是否备份原配置文件")
+		backups    = flag.Int("backups", config.DefaultMaxBackups, "每个配置文件保留的备份数量")
 		force      = flag.Bool("force", false, "是否强制覆盖目标文件")
 		validate   = flag.Bool("validate", true, "是否验证迁移后的配置")
 		help       = flag.Bool("help", false, "显示帮助信息")
@@ -39,13 +41,15 @@ func main() {
 		os.Exit(1)
 	}
 
-	// 备份原配置文件
+	// 备份原配置文件，强制覆盖时同时备份已存在的目标文件
 	if *backup {
-		backupFile := *sourceFile + ".backup"
-		if err := copyFile(*sourceFile, backupFile); err != nil {
-			fmt.Printf("警告: 备份配置文件失败: %v\n", err)
-		} else {
-			fmt.Printf("✓ 已备份原配置文件到: %s\n", backupFile)
+		for _, file := range []string{*sourceFile, *targetFile} {
+			backupFile, err := config.BackupFile(file, "", *backups, time.Now())
+			if err != nil {
+				fmt.Printf("警告: 备份配置文件失败: %v\n", err)
+			} else if backupFile != "" {
+				fmt.Printf("✓ 已备份配置文件到: %s\n", backupFile)
+			}
 		}
 	}
 
@@ -84,6 +88,7 @@ func showHelp() {
 	fmt.Println("  -source string    源配置文件路径 (默认: config.yaml)")
 	fmt.Println("  -target string    目标配置文件路径 (默认: config.new.yaml)")
 	fmt.Println("  -backup          是否备份原配置文件 (默认: true)")
+	fmt.Println("  -backups int     每个配置文件保留的备份数量 (默认: 5)")
 	fmt.Println("  -force           是否强制覆盖目标文件 (默认: false)")
 	fmt.Println("  -validate        是否验证迁移后的配置 (默认: true)")
 	fmt.Println("  -help            显示帮助信息")
@@ -94,14 +99,6 @@ func showHelp() {
 	fmt.Println("  config-migrate -force -backup=false")
 }
 
-func copyFile(src, dst string) error {
-	data, err := os.ReadFile(src)
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(dst, data, 0644)
-}
-
 func validateConfig(configFile string) error {
 	// 这里可以添加配置验证逻辑
 	// 暂时只检查文件是否可以正常读取
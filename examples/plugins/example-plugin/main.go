@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
@@ -258,13 +260,23 @@ func main() {
 		os.Exit(1)
 	}
 
-	// 启动插件
-	if err := p.Start(context.Background()); err != nil {
+	// 启动插件，收到退出信号时取消上下文
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := p.Start(ctx); err != nil {
 		fmt.Printf("启动插件失败: %v\n", err)
 		os.Exit(1)
 	}
 
 	// 等待信号
 	fmt.Println("插件已启动，按Ctrl+C停止")
-	select {}
+	<-ctx.Done()
+
+	// 停止插件
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer stopCancel()
+	if err := p.Stop(stopCtx); err != nil {
+		fmt.Printf("停止插件失败: %v\n", err)
+		os.Exit(1)
+	}
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-hclog"
+	coreconfig "github.com/lomehong/kennel/pkg/core/config"
 	"github.com/lomehong/kennel/pkg/plugin/api"
 	"github.com/lomehong/kennel/pkg/plugin/sdk"
 )
@@ -102,32 +103,21 @@ func (p *HelloPlugin) Init(ctx context.Context, config api.PluginConfig) error {
 
 	p.configManager = configManager
 
-	// 解析配置
-	p.config = &HelloConfig{
-		Message:      "Hello, World!",
-		Interval:     5 * time.Second,
-		DebugPort:    8080,
-		DebugEnabled: false,
-	}
-
-	// 从配置中获取消息
-	if message := configManager.GetString("settings.message"); message != "" {
-		p.config.Message = message
-	}
-
-	// 从配置中获取间隔
-	if interval := configManager.GetInt("settings.interval"); interval > 0 {
-		p.config.Interval = time.Duration(interval) * time.Second
+	// 未配置的设置使用登记的默认值
+	for key, value := range coreconfig.RegisteredDefaults().Defaults("plugins.hello.settings") {
+		if configManager.Get("settings."+key) == nil {
+			configManager.Set("settings."+key, value)
+		}
 	}
 
-	// 从配置中获取调试端口
-	if debugPort := configManager.GetInt("settings.debug_port"); debugPort > 0 {
-		p.config.DebugPort = debugPort
+	// 解析配置
+	p.config = &HelloConfig{
+		Message:      configManager.GetString("settings.message"),
+		Interval:     time.Duration(configManager.GetInt("settings.interval")) * time.Second,
+		DebugPort:    configManager.GetInt("settings.debug_port"),
+		DebugEnabled: configManager.GetBool("settings.debug_enabled"),
 	}
 
-	// 从配置中获取是否启用调试
-	p.config.DebugEnabled = configManager.GetBool("settings.debug_enabled")
-
 	// 创建调试服务器
 	p.debugServer = sdk.NewDebugServer(
 		p.GetInfo().ID,
@@ -0,0 +1,152 @@
+package comm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// AuthToken 连接服务器使用的认证令牌
+type AuthToken struct {
+	Value     string    // Bearer 令牌
+	ExpiresAt time.Time // 过期时间，零值表示不过期
+}
+
+// TokenProvider 提供连接服务器使用的认证令牌
+// 客户端在每次连接时获取令牌，并在令牌过期前 TokenRefreshBefore 再次获取；
+// 返回与当前令牌不同的令牌时，客户端通过认证消息在现有连接上更新令牌
+type TokenProvider interface {
+	// Token 返回当前可用的令牌，令牌即将过期时应返回续期后的新令牌
+	Token(ctx context.Context) (AuthToken, error)
+}
+
+// TokenProviderFunc 函数形式的令牌提供者
+type TokenProviderFunc func(ctx context.Context) (AuthToken, error)
+
+// Token 实现 TokenProvider 接口
+func (f TokenProviderFunc) Token(ctx context.Context) (AuthToken, error) {
+	return f(ctx)
+}
+
+// SetTokenProvider 设置令牌提供者，在下次连接时生效
+// 设置后连接时使用提供者返回的令牌作为 Bearer 认证头，优先于 Security 中配置的静态认证
+func (c *Client) SetTokenProvider(provider TokenProvider) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+	c.tokenProvider = provider
+}
+
+// GetAuthToken 获取当前连接使用的认证令牌
+func (c *Client) GetAuthToken() AuthToken {
+	c.tokenMutex.RLock()
+	defer c.tokenMutex.RUnlock()
+	return c.token
+}
+
+// fetchToken 从令牌提供者获取令牌，未设置提供者时返回 false
+func (c *Client) fetchToken() (AuthToken, bool, error) {
+	c.tokenMutex.RLock()
+	provider := c.tokenProvider
+	c.tokenMutex.RUnlock()
+	if provider == nil {
+		return AuthToken{}, false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.HandshakeTimeout)
+	defer cancel()
+	token, err := provider.Token(ctx)
+	if err != nil {
+		return AuthToken{}, true, fmt.Errorf("获取认证令牌失败: %w", err)
+	}
+	if token.Value == "" {
+		return AuthToken{}, true, errors.New("获取认证令牌失败: 令牌为空")
+	}
+	return token, true, nil
+}
+
+// setToken 记录当前使用的令牌
+func (c *Client) setToken(token AuthToken) {
+	c.tokenMutex.Lock()
+	defer c.tokenMutex.Unlock()
+	c.token = token
+}
+
+// createAuthMessage 创建认证消息，携带续期后的令牌
+func createAuthMessage(token AuthToken) *Message {
+	payload := map[string]interface{}{
+		"auth_token": token.Value,
+	}
+	if !token.ExpiresAt.IsZero() {
+		payload["expires_at"] = token.ExpiresAt.Format(time.RFC3339)
+	}
+	return NewMessage(MessageTypeAuth, payload)
+}
+
+// tokenRefreshDelay 计算距离刷新令牌的时间，令牌有效期短于 TokenRefreshBefore 时在剩余有效期过半时刷新
+func (c *Client) tokenRefreshDelay(token AuthToken) time.Duration {
+	remaining := time.Until(token.ExpiresAt)
+	if wait := remaining - c.config.TokenRefreshBefore; wait > 0 {
+		return wait
+	}
+	if remaining <= 0 {
+		return 0
+	}
+	return remaining / 2
+}
+
+// startTokenRefresh 在令牌过期前刷新令牌，连接断开（done 关闭）或主动断开（stop 关闭）后退出
+// 刷新失败时按重连间隔重试；令牌过期仍未刷新成功时关闭连接，由重连流程使用新令牌重新认证
+func (c *Client) startTokenRefresh(stop <-chan struct{}, done <-chan struct{}, conn *websocket.Conn, token AuthToken) {
+	if token.ExpiresAt.IsZero() {
+		return
+	}
+
+	go func() {
+		wait := c.tokenRefreshDelay(token)
+		for {
+			timer := time.NewTimer(wait)
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-done:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			fresh, _, err := c.fetchToken()
+			if err == nil && fresh.Value == token.Value && !fresh.ExpiresAt.After(token.ExpiresAt) {
+				err = errors.New("令牌提供者未返回新令牌")
+			}
+			if err != nil {
+				remaining := time.Until(token.ExpiresAt)
+				if remaining <= 0 {
+					c.logger.Error("认证令牌已过期且刷新失败，重新连接", "error", err)
+					conn.Close()
+					return
+				}
+				c.logger.Warn("刷新认证令牌失败，稍后重试", "error", err, "expires_in", remaining)
+				wait = c.config.ReconnectInterval
+				if wait > remaining {
+					wait = remaining
+				}
+				continue
+			}
+
+			if fresh.Value != token.Value {
+				c.SendWithPriority(createAuthMessage(fresh), PriorityHigh)
+			}
+			c.setToken(fresh)
+			c.logger.Info("已刷新认证令牌", "expires_at", fresh.ExpiresAt)
+			token = fresh
+			if token.ExpiresAt.IsZero() {
+				return
+			}
+			wait = c.tokenRefreshDelay(token)
+		}
+	}()
+}
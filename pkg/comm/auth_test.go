@@ -0,0 +1,194 @@
+package comm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// rotatingTokenProvider 每次调用返回一个新令牌，令牌有效期为 ttl
+type rotatingTokenProvider struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	calls int
+	fail  map[int]bool // 第几次调用返回错误
+}
+
+func (p *rotatingTokenProvider) Token(ctx context.Context) (AuthToken, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.fail[p.calls] {
+		return AuthToken{}, errors.New("认证服务不可用")
+	}
+	return AuthToken{Value: fmt.Sprintf("token-%d", p.calls), ExpiresAt: time.Now().Add(p.ttl)}, nil
+}
+
+// authEvent 测试服务器收到的认证信息
+type authEvent struct {
+	connection int
+	kind       string // header、connect 或 auth
+	token      string
+	at         time.Time
+}
+
+// newAuthTestServer 创建记录认证头、连接消息和认证消息中令牌的测试服务器
+// dropFirst 为 true 时第一次连接收到连接消息后断开
+func newAuthTestServer(t *testing.T, dropFirst bool) (*httptest.Server, chan authEvent) {
+	events := make(chan authEvent, 32)
+	var mu sync.Mutex
+	connections := 0
+
+	server := newWSTestServer(t, func(conn *websocket.Conn, r *http.Request) {
+		mu.Lock()
+		connections++
+		connection := connections
+		mu.Unlock()
+
+		events <- authEvent{connection: connection, kind: "header", token: strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), at: time.Now()}
+
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			msg, err := decodeMessage(data)
+			if err != nil {
+				continue
+			}
+			switch msg.Type {
+			case MessageTypeConnect:
+				token, _ := msg.Payload["auth_token"].(string)
+				events <- authEvent{connection: connection, kind: "connect", token: token, at: time.Now()}
+				if dropFirst && connection == 1 {
+					return
+				}
+			case MessageTypeAuth:
+				token, _ := msg.Payload["auth_token"].(string)
+				events <- authEvent{connection: connection, kind: "auth", token: token, at: time.Now()}
+			}
+		}
+	})
+	return server, events
+}
+
+// waitAuthEvent 等待指定类型的认证信息
+func waitAuthEvent(t *testing.T, events chan authEvent, kind string) authEvent {
+	t.Helper()
+	timeout := time.After(3 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event.kind == kind {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("超时等待 %s 认证信息", kind)
+		}
+	}
+}
+
+// newAuthTestManager 创建使用令牌提供者的管理器，refreshBefore 为过期前刷新令牌的提前量
+func newAuthTestManager(server *httptest.Server, provider TokenProvider, refreshBefore time.Duration) *Manager {
+	config := DefaultConfig()
+	config.ServerURL = wsURL(server)
+	config.HandshakeTimeout = time.Second
+	config.HeartbeatInterval = 10 * time.Second
+	config.ReconnectInterval = 30 * time.Millisecond
+	config.CloseTimeout = 50 * time.Millisecond
+	config.TokenRefreshBefore = refreshBefore
+	manager := NewManager(config, nil)
+	manager.SetTokenProvider(provider)
+	return manager
+}
+
+// TestTokenUsedOnConnectAndReconnect 测试连接和重连时使用令牌提供者返回的最新令牌
+func TestTokenUsedOnConnectAndReconnect(t *testing.T) {
+	server, events := newAuthTestServer(t, true)
+	defer server.Close()
+
+	provider := &rotatingTokenProvider{ttl: time.Hour}
+	manager := newAuthTestManager(server, provider, time.Minute)
+	if err := manager.Connect(); err != nil {
+		t.Fatalf("连接服务器失败: %v", err)
+	}
+	defer manager.Disconnect()
+
+	for _, want := range []authEvent{
+		{connection: 1, kind: "header", token: "token-1"},
+		{connection: 1, kind: "connect", token: "token-1"},
+		{connection: 2, kind: "header", token: "token-2"},
+		{connection: 2, kind: "connect", token: "token-2"},
+	} {
+		got := waitAuthEvent(t, events, want.kind)
+		if got.connection != want.connection || got.token != want.token {
+			t.Errorf("第 %d 次连接的 %s 令牌应为 %s，实际为第 %d 次连接的 %s", want.connection, want.kind, want.token, got.connection, got.token)
+		}
+	}
+
+	if !waitFor(time.Second, manager.IsConnected) {
+		t.Fatal("重连后应处于已连接状态")
+	}
+	if token := manager.GetClient().GetAuthToken(); token.Value != "token-2" {
+		t.Errorf("当前令牌应为 token-2，实际为 %s", token.Value)
+	}
+}
+
+// TestTokenRefreshedBeforeExpiry 测试令牌在过期前刷新，并在现有连接上发送认证消息
+func TestTokenRefreshedBeforeExpiry(t *testing.T) {
+	server, events := newAuthTestServer(t, false)
+	defer server.Close()
+
+	// 第二次获取令牌失败，客户端按重连间隔重试
+	provider := &rotatingTokenProvider{ttl: 400 * time.Millisecond, fail: map[int]bool{2: true}}
+	manager := newAuthTestManager(server, provider, 300*time.Millisecond)
+	if err := manager.Connect(); err != nil {
+		t.Fatalf("连接服务器失败: %v", err)
+	}
+	defer manager.Disconnect()
+
+	connected := waitAuthEvent(t, events, "connect")
+	expiresAt := connected.at.Add(provider.ttl)
+
+	refreshed := waitAuthEvent(t, events, "auth")
+	if refreshed.connection != 1 {
+		t.Errorf("刷新令牌不应重新连接，实际在第 %d 次连接上收到认证消息", refreshed.connection)
+	}
+	if refreshed.token != "token-3" {
+		t.Errorf("刷新后的令牌应为 token-3，实际为 %s", refreshed.token)
+	}
+	if !refreshed.at.Before(expiresAt) {
+		t.Errorf("令牌应在过期前刷新，过期时间 %v，刷新时间 %v", expiresAt, refreshed.at)
+	}
+	if token := manager.GetClient().GetAuthToken(); token.Value != "token-3" {
+		t.Errorf("当前令牌应为 token-3，实际为 %s", token.Value)
+	}
+}
+
+// TestConnectFailsWithoutToken 测试获取令牌失败时不连接服务器
+func TestConnectFailsWithoutToken(t *testing.T) {
+	server, events := newAuthTestServer(t, false)
+	defer server.Close()
+
+	manager := newAuthTestManager(server, &rotatingTokenProvider{ttl: time.Hour, fail: map[int]bool{1: true}}, time.Minute)
+	if err := manager.Connect(); err == nil {
+		manager.Disconnect()
+		t.Fatal("获取令牌失败时连接应返回错误")
+	}
+	if manager.GetState() != StateDisconnected {
+		t.Errorf("连接失败后应处于断开状态，实际为 %s", manager.GetState())
+	}
+	select {
+	case event := <-events:
+		t.Errorf("获取令牌失败时不应连接服务器，实际收到 %s", event.kind)
+	default:
+	}
+}
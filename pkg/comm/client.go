@@ -18,8 +18,14 @@ type Client struct {
 	stateMutex     sync.RWMutex
 	reconnectCount int
 
+	// 服务器端点选择
+	endpoints *endpointSelector
+
+	// 当前连接协商的WebSocket子协议
+	subprotocol string
+
 	// 消息处理
-	sendChan    chan *Message
+	sendQueue   *priorityQueue
 	receiveChan chan *Message
 
 	// 处理器
@@ -29,16 +35,39 @@ type Client struct {
 
 	// 控制
 	stopChan       chan struct{}
+	readDone       chan struct{} // 读取协程退出时关闭
 	heartbeatTimer *time.Timer
 
+	// 优雅关闭时等待断开消息写出
+	closeMutex sync.Mutex
+	closeMsgID string
+	closeSent  chan struct{}
+
 	// 日志
 	logger logging.Logger
 
 	// 客户端信息
 	clientInfo map[string]interface{}
 
+	// 认证令牌，配置令牌提供者时每次连接获取并在过期前刷新
+	tokenProvider TokenProvider
+	token         AuthToken
+	tokenMutex    sync.RWMutex
+
 	// 指标收集器
 	metrics *MetricsCollector
+
+	// 请求往返延迟跟踪
+	tracer *requestTracer
+
+	// 协议版本协商
+	versions *versionNegotiator
+
+	// 消息序号和确认跟踪，重连后重发未确认的消息
+	delivery *deliveryTracker
+
+	// 按连接稳定性自适应调整的心跳间隔
+	heartbeat *heartbeatScheduler
 }
 
 // NewClient 创建一个新的WebSocket客户端
@@ -59,15 +88,25 @@ func NewClient(config ConnectionConfig, log logging.Logger) *Client {
 		log = enhancedLogger.Named("comm-client")
 	}
 
+	delivery, err := newDeliveryTracker(config.SequenceStateFile)
+	if err != nil {
+		log.Warn("恢复消息序号状态失败，从头分配序号", "file", config.SequenceStateFile, "error", err)
+	}
+
 	return &Client{
 		config:      config,
 		state:       StateDisconnected,
-		sendChan:    make(chan *Message, config.MessageBufferSize),
+		endpoints:   newEndpointSelector(config),
+		sendQueue:   newPriorityQueue(config.MessageBufferSize),
 		receiveChan: make(chan *Message, config.MessageBufferSize),
 		stopChan:    make(chan struct{}),
 		logger:      log,
 		clientInfo:  make(map[string]interface{}),
 		metrics:     NewMetricsCollector(),
+		tracer:      newRequestTracer(),
+		versions:    newVersionNegotiator(),
+		delivery:    delivery,
+		heartbeat:   newHeartbeatScheduler(config),
 	}
 }
 
@@ -85,6 +124,7 @@ func (c *Client) Connect() error {
 	// 设置连接超时和TLS配置
 	dialer := websocket.Dialer{
 		HandshakeTimeout: c.config.HandshakeTimeout,
+		Subprotocols:     c.config.Subprotocols,
 	}
 
 	// 如果启用了TLS，设置TLS配置
@@ -100,6 +140,9 @@ func (c *Client) Connect() error {
 
 	// 准备HTTP头
 	header := http.Header{}
+	for k, v := range c.config.Headers {
+		header.Set(k, v)
+	}
 
 	// 如果启用了认证，添加认证头
 	if c.config.Security.EnableAuth {
@@ -114,8 +157,20 @@ func (c *Client) Connect() error {
 		}
 	}
 
-	// 连接到服务器
-	conn, _, err := dialer.Dial(c.config.ServerURL, header)
+	// 配置了令牌提供者时，每次连接获取最新的令牌
+	token, hasProvider, err := c.fetchToken()
+	if err != nil {
+		c.setState(StateDisconnected)
+		c.logger.Error("获取认证令牌失败", "error", err)
+		return err
+	}
+	if hasProvider {
+		header.Set("Authorization", "Bearer "+token.Value)
+		c.setToken(token)
+	}
+
+	// 依次尝试服务器端点
+	conn, url, err := c.dialEndpoints(dialer, header)
 	if err != nil {
 		c.setState(StateDisconnected)
 		c.logger.Error("连接服务器失败", "error", err)
@@ -124,92 +179,222 @@ func (c *Client) Connect() error {
 	}
 
 	c.conn = conn
+	c.stateMutex.Lock()
+	c.subprotocol = conn.Subprotocol()
+	c.stateMutex.Unlock()
+	if len(c.config.Subprotocols) > 0 && conn.Subprotocol() == "" {
+		c.logger.Warn("服务器未选择WebSocket子协议", "requested", c.config.Subprotocols)
+	}
 	c.setState(StateConnected)
 	c.reconnectCount = 0
 	c.metrics.RecordConnect(true)
 
-	// 启动处理协程
-	go c.readPump()
-	go c.writePump()
-	go c.processPump()
+	// 新连接重新协商协议版本
+	c.versions.reset()
+
+	// 启动处理协程，协程持有本次连接的通道，断开后重建通道不影响已退出的协程
+	stop, receive := c.stopChan, c.receiveChan
+	c.readDone = make(chan struct{})
+	go c.readPump(stop, receive, c.readDone)
+	go c.writePump(stop)
+	go c.processPump(stop, receive)
+
+	// 发送连接消息，告知服务端已确认的最大序号
+	connectMsg := createConnectMessage(c.clientInfo)
+	connectMsg.Payload["last_acked_seq"] = c.delivery.lastAckedSeq()
+	if hasProvider {
+		connectMsg.Payload["auth_token"] = token.Value
+	}
+	c.Send(connectMsg)
 
-	// 发送连接消息
-	c.Send(createConnectMessage(c.clientInfo))
+	// 重发上次连接中已发送但未确认的消息，服务端可按序号去重
+	if unacked := c.delivery.unacked(); len(unacked) > 0 {
+		c.logger.Info("重发未确认的消息", "count", len(unacked))
+		for _, inflight := range unacked {
+			c.SendWithPriority(inflight.msg, inflight.priority)
+		}
+	}
 
 	// 启动心跳
-	c.startHeartbeat()
+	c.startHeartbeat(stop)
+	if hasProvider {
+		c.startTokenRefresh(stop, c.readDone, conn, token)
+	}
 
-	c.logger.Info("已连接到服务器", "url", c.config.ServerURL)
+	c.logger.Info("已连接到服务器", "url", url, "subprotocol", conn.Subprotocol())
 	return nil
 }
 
-// Disconnect 断开连接
+// dialEndpoints 按选择器给出的顺序连接服务器端点，返回第一个连接成功的端点
+func (c *Client) dialEndpoints(dialer websocket.Dialer, header http.Header) (*websocket.Conn, string, error) {
+	candidates := c.endpoints.candidates()
+	if len(candidates) == 0 {
+		return nil, "", errors.New("未配置服务器地址")
+	}
+
+	var lastErr error
+	for _, url := range candidates {
+		conn, _, err := dialer.Dial(url, header)
+		if err == nil {
+			c.endpoints.recordSuccess(url)
+			return conn, url, nil
+		}
+
+		lastErr = err
+		if c.endpoints.recordFailure(url, err) && len(candidates) > 1 {
+			c.logger.Warn("服务器端点连续失败，轮换到下一个端点", "url", url, "error", err)
+		} else {
+			c.logger.Warn("连接服务器端点失败", "url", url, "error", err)
+		}
+	}
+
+	return nil, "", lastErr
+}
+
+// Disconnect 优雅断开连接
+// 先发送断开消息，在 CloseTimeout 内等待发送队列中的消息写出并收到确认，
+// 再发送正常关闭帧并等待服务器回应。主动断开后不会自动重连
 func (c *Client) Disconnect() {
 	c.stateMutex.Lock()
-	defer c.stateMutex.Unlock()
-
-	if c.state == StateDisconnected {
+	if c.state == StateDisconnected || c.state == StateDisconnecting {
+		c.stateMutex.Unlock()
 		return
 	}
 
 	c.logger.Info("正在断开连接...")
-
-	// 停止所有协程
-	close(c.stopChan)
+	connected := c.state == StateConnected && c.conn != nil
+	c.setState(StateDisconnecting)
+	c.stateMutex.Unlock()
 
 	// 停止心跳
 	if c.heartbeatTimer != nil {
 		c.heartbeatTimer.Stop()
 	}
 
-	// 发送关闭消息
-	if c.conn != nil {
-		// 创建关闭消息
-		closeMsg := NewMessage(MessageTypeEvent, map[string]interface{}{
-			"event": "client_disconnect",
-			"details": map[string]interface{}{
-				"reason": "graceful_shutdown",
-				"time":   time.Now().Format(time.RFC3339),
-			},
-		})
-
-		// 编码消息
-		data, err := encodeMessage(closeMsg)
-		if err == nil {
-			// 设置写入超时
-			c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
-
-			// 写入消息
-			c.conn.WriteMessage(websocket.TextMessage, data)
+	if connected {
+		c.flushPendingMessages()
+		c.closeConnection()
+	}
 
-			// 等待一小段时间，确保消息发送出去
-			time.Sleep(100 * time.Millisecond)
-		}
+	// 停止所有协程
+	close(c.stopChan)
 
-		// 关闭连接
-		c.conn.WriteMessage(websocket.CloseMessage,
-			websocket.FormatCloseMessage(websocket.CloseNormalClosure, "客户端正常关闭"))
+	if c.conn != nil {
 		c.conn.Close()
 		c.conn = nil
 	}
 
+	c.stateMutex.Lock()
 	c.setState(StateDisconnected)
+	c.stateMutex.Unlock()
+
+	c.endpoints.markDisconnected()
+	if dropped := c.delivery.reset(); dropped > 0 {
+		c.logger.Warn("断开连接时仍有消息未确认", "count", dropped)
+	}
+	if err := c.delivery.flush(); err != nil {
+		c.logger.Warn("持久化消息序号失败", "error", err)
+	}
 	c.logger.Info("已断开连接")
 	c.metrics.RecordDisconnect()
 
 	// 重新初始化通道
 	c.stopChan = make(chan struct{})
-	c.sendChan = make(chan *Message, c.config.MessageBufferSize)
+	c.sendQueue.clear()
 	c.receiveChan = make(chan *Message, c.config.MessageBufferSize)
 }
 
-// Send 发送消息
-func (c *Client) Send(msg *Message) {
+// flushPendingMessages 发送断开消息，等待队列中的消息写出并收到确认，超过 CloseTimeout 后放弃等待
+func (c *Client) flushPendingMessages() {
+	deadline := time.Now().Add(c.config.CloseTimeout)
+
+	// 断开消息以低优先级入队，在已排队的消息之后写出
+	closeMsg := NewMessage(MessageTypeEvent, map[string]interface{}{
+		"event": "client_disconnect",
+		"details": map[string]interface{}{
+			"reason": "graceful_shutdown",
+			"time":   time.Now().Format(time.RFC3339),
+		},
+	})
+	sent := make(chan struct{})
+	c.closeMutex.Lock()
+	c.closeMsgID = closeMsg.ID
+	c.closeSent = sent
+	c.closeMutex.Unlock()
+	defer func() {
+		c.closeMutex.Lock()
+		c.closeMsgID = ""
+		c.closeSent = nil
+		c.closeMutex.Unlock()
+	}()
+
+	if !c.sendQueue.push(closeMsg, PriorityLow) {
+		c.logger.Warn("发送队列已满，断开消息被丢弃")
+		return
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-sent:
+	case <-c.readDone:
+		c.logger.Warn("连接已关闭，未能发送断开消息", "queued", c.sendQueue.len())
+		return
+	case <-timer.C:
+		c.logger.Warn("等待消息发送超时", "queued", c.sendQueue.len())
+		return
+	}
+
+	// 等待已发送消息的确认
+	for c.tracer.pendingCount() > 0 {
+		if time.Now().After(deadline) {
+			c.logger.Warn("等待消息确认超时", "pending", c.tracer.pendingCount())
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// closeConnection 发送正常关闭帧，等待服务器回应关闭帧后读取协程退出
+func (c *Client) closeConnection() {
+	err := c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, "客户端正常关闭"),
+		time.Now().Add(c.config.WriteTimeout))
+	if err != nil {
+		c.logger.Warn("发送关闭帧失败", "error", err)
+		return
+	}
+
+	timer := time.NewTimer(c.config.CloseTimeout)
+	defer timer.Stop()
 	select {
-	case c.sendChan <- msg:
-		// 消息已加入发送队列
-	default:
-		c.logger.Warn("发送队列已满，消息被丢弃")
+	case <-c.readDone:
+	case <-timer.C:
+		c.logger.Warn("等待服务器关闭帧超时")
+	}
+}
+
+// markSent 断开消息写出后通知 flushPendingMessages
+func (c *Client) markSent(msg *Message) {
+	c.closeMutex.Lock()
+	defer c.closeMutex.Unlock()
+
+	if c.closeSent != nil && msg.ID == c.closeMsgID {
+		close(c.closeSent)
+		c.closeSent = nil
+	}
+}
+
+// Send 按消息类型的默认优先级发送消息
+func (c *Client) Send(msg *Message) {
+	c.SendWithPriority(msg, defaultPriority(msg.Type))
+}
+
+// SendWithPriority 按指定优先级发送消息
+// 链路拥塞或离线缓存时，高优先级消息先于低优先级消息发送
+func (c *Client) SendWithPriority(msg *Message, priority MessagePriority) {
+	if !c.sendQueue.push(msg, priority) {
+		c.logger.Warn("发送队列已满，消息被丢弃", "type", msg.Type, "priority", priority)
 	}
 }
 
@@ -265,12 +450,78 @@ func (c *Client) handleError(err error) {
 	c.metrics.RecordError(err.Error())
 }
 
+// GetActiveEndpoint 获取当前连接的服务器端点，未连接时返回空字符串
+func (c *Client) GetActiveEndpoint() string {
+	return c.endpoints.activeEndpoint()
+}
+
+// GetSubprotocol 获取最近一次连接时与服务器协商的WebSocket子协议，未协商时返回空字符串
+func (c *Client) GetSubprotocol() string {
+	c.stateMutex.RLock()
+	defer c.stateMutex.RUnlock()
+	return c.subprotocol
+}
+
+// GetEndpointStatus 获取所有服务器端点的状态
+func (c *Client) GetEndpointStatus() []EndpointStatus {
+	return c.endpoints.status()
+}
+
 // GetMetrics 获取指标
 func (c *Client) GetMetrics() map[string]interface{} {
-	return c.metrics.GetMetrics()
+	metrics := c.metrics.GetMetrics()
+	metrics["active_endpoint"] = c.endpoints.activeEndpoint()
+	metrics["endpoints"] = c.endpoints.status()
+	metrics["send_queue_depth"] = c.sendQueue.depths()
+	metrics["send_queue_dropped"] = c.sendQueue.droppedCounts()
+	for key, value := range c.tracer.metrics() {
+		metrics[key] = value
+	}
+	for key, value := range c.delivery.metrics() {
+		metrics[key] = value
+	}
+	for key, value := range c.heartbeat.metrics() {
+		metrics[key] = value
+	}
+	return metrics
+}
+
+// GetLatencyHistograms 获取各消息类型从发送到收到确认的延迟直方图
+func (c *Client) GetLatencyHistograms() map[MessageType]LatencyHistogram {
+	return c.tracer.latencyHistograms()
+}
+
+// GetSlowRequests 获取最近最慢的请求，按延迟从高到低排列
+func (c *Client) GetSlowRequests() []SlowRequest {
+	return c.tracer.slowRequests()
 }
 
 // GetMetricsReport 获取指标报告
 func (c *Client) GetMetricsReport() string {
-	return c.metrics.GetMetricsReport()
+	report := c.metrics.GetMetricsReport()
+
+	report += "\n服务器端点:\n"
+	for _, endpoint := range c.endpoints.status() {
+		marker := " "
+		if endpoint.Active {
+			marker = "*"
+		}
+		health := "健康"
+		if !endpoint.Healthy {
+			health = "不健康"
+		}
+		report += "  " + marker + " " + endpoint.URL + " (" + health + ", 连续失败: " + formatUint64(uint64(endpoint.ConsecutiveFailures)) + ")\n"
+	}
+
+	report += "\n心跳间隔: " + c.heartbeat.Interval().String() + "\n"
+
+	report += "\n发送队列:\n"
+	depths := c.sendQueue.depths()
+	dropped := c.sendQueue.droppedCounts()
+	for _, priority := range []MessagePriority{PriorityHigh, PriorityNormal, PriorityLow} {
+		name := priority.String()
+		report += "  " + name + ": " + formatUint64(uint64(depths[name])) + " (丢弃: " + formatUint64(dropped[name]) + ")\n"
+	}
+
+	return report
 }
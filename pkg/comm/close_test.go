@@ -0,0 +1,156 @@
+package comm
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newAckingTestServer 创建延迟确认所有消息的WebSocket测试服务器，
+// closeCodes 接收客户端关闭帧的状态码，connections 统计连接次数
+func newAckingTestServer(t *testing.T, ackDelay time.Duration) (*httptest.Server, chan *Message, chan int, *atomic.Int32) {
+	received := make(chan *Message, 20)
+	closeCodes := make(chan int, 1)
+	connections := &atomic.Int32{}
+
+	server := newWSTestServer(t, func(conn *websocket.Conn, r *http.Request) {
+		connections.Add(1)
+
+		var writeMutex sync.Mutex
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				var closeErr *websocket.CloseError
+				if errors.As(err, &closeErr) {
+					closeCodes <- closeErr.Code
+				}
+				return
+			}
+
+			msg, err := decodeMessage(data)
+			if err != nil {
+				t.Logf("解析消息失败: %v", err)
+				continue
+			}
+			received <- msg
+
+			if msg.Type != MessageTypeAck {
+				time.AfterFunc(ackDelay, func() {
+					data, _ := encodeMessage(createAckMessage(msg.ID))
+					writeMutex.Lock()
+					defer writeMutex.Unlock()
+					conn.WriteMessage(websocket.TextMessage, data)
+				})
+			}
+		}
+	})
+	return server, received, closeCodes, connections
+}
+
+// TestClientGracefulClose 测试断开连接时先发出待发送消息并等待确认，再发送正常关闭帧
+func TestClientGracefulClose(t *testing.T) {
+	server, received, closeCodes, connections := newAckingTestServer(t, 50*time.Millisecond)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ServerURL = wsURL(server)
+	config.HeartbeatInterval = 10 * time.Second
+	config.ReconnectInterval = 50 * time.Millisecond
+	config.CloseTimeout = 2 * time.Second
+
+	client := NewClient(config, nil)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("连接服务器失败: %v", err)
+	}
+
+	// 断开前排队多条消息
+	var queued []string
+	for i := 0; i < 5; i++ {
+		msg := NewMessage(MessageTypeEvent, map[string]interface{}{"index": i})
+		queued = append(queued, msg.ID)
+		client.Send(msg)
+	}
+
+	client.Disconnect()
+
+	if pending := client.tracer.pendingCount(); pending != 0 {
+		t.Errorf("断开后仍有 %d 条消息未确认", pending)
+	}
+
+	// 服务器依次收到连接消息、排队的消息，最后是断开消息
+	var messages []*Message
+	for len(messages) < len(queued)+2 {
+		select {
+		case msg := <-received:
+			messages = append(messages, msg)
+		case <-time.After(time.Second):
+			t.Fatalf("超时等待消息，已收到 %d 条", len(messages))
+		}
+	}
+	if messages[0].Type != MessageTypeConnect {
+		t.Errorf("第一条消息应该是连接消息，但收到了 %s", messages[0].Type)
+	}
+	for i, id := range queued {
+		if messages[i+1].ID != id {
+			t.Errorf("第 %d 条排队消息未按顺序发出", i)
+		}
+	}
+	last := messages[len(messages)-1]
+	if event, _ := last.Payload["event"].(string); event != "client_disconnect" {
+		t.Errorf("最后一条消息应该是断开消息，但收到了 %v", last.Payload)
+	}
+
+	select {
+	case code := <-closeCodes:
+		if code != websocket.CloseNormalClosure {
+			t.Errorf("期望关闭状态码 %d，但收到了 %d", websocket.CloseNormalClosure, code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("超时等待关闭帧")
+	}
+
+	// 主动断开后不会重连
+	time.Sleep(4 * config.ReconnectInterval)
+	if state := client.GetState(); state != StateDisconnected {
+		t.Errorf("客户端状态应该是 %v，但是 %v", StateDisconnected, state)
+	}
+	if count := connections.Load(); count != 1 {
+		t.Errorf("主动断开后不应重连，连接次数: %d", count)
+	}
+}
+
+// TestClientCloseTimeout 测试服务器不确认消息时，断开连接在 CloseTimeout 后完成
+func TestClientCloseTimeout(t *testing.T) {
+	server, _, closeCodes, _ := newAckingTestServer(t, time.Hour)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ServerURL = wsURL(server)
+	config.CloseTimeout = 200 * time.Millisecond
+
+	client := NewClient(config, nil)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("连接服务器失败: %v", err)
+	}
+
+	start := time.Now()
+	client.Disconnect()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("断开连接耗时 %v，超过了关闭超时", elapsed)
+	}
+
+	select {
+	case code := <-closeCodes:
+		if code != websocket.CloseNormalClosure {
+			t.Errorf("期望关闭状态码 %d，但收到了 %d", websocket.CloseNormalClosure, code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("超时等待关闭帧")
+	}
+}
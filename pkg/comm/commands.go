@@ -0,0 +1,101 @@
+package comm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+const (
+	// CommandPing 内置的探活命令，回复 pong
+	CommandPing = "ping"
+)
+
+// errUnknownCommand 没有处理函数的命令
+var errUnknownCommand = errors.New("未知命令")
+
+// CommandHandler 服务端命令的处理函数
+// 返回值作为响应的 data 发回服务端，返回错误时响应标记为失败并携带错误信息
+type CommandHandler func(msg *Message, params map[string]interface{}) (interface{}, error)
+
+// RegisterCommandHandler 注册服务端命令的处理函数，同名命令的处理函数被替换
+// 处理函数执行完成后，管理器以命令消息ID作为 request_id 发送响应，服务端据此关联命令的执行结果
+func (m *Manager) RegisterCommandHandler(command string, handler CommandHandler) {
+	m.handlerMutex.Lock()
+	defer m.handlerMutex.Unlock()
+
+	if handler == nil {
+		delete(m.commands, command)
+		return
+	}
+	m.commands[command] = handler
+}
+
+// UnregisterCommandHandler 注销服务端命令的处理函数
+func (m *Manager) UnregisterCommandHandler(command string) {
+	m.RegisterCommandHandler(command, nil)
+}
+
+// registerBuiltinCommands 注册内置命令
+func (m *Manager) registerBuiltinCommands() {
+	m.RegisterCommandHandler(CommandPing, func(msg *Message, params map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{
+			"pong":      true,
+			"timestamp": time.Now().UnixNano() / int64(time.Millisecond),
+		}, nil
+	})
+}
+
+// commandTask 返回执行命令并发送关联响应的处理函数
+// 没有命令处理函数也没有命令消息处理函数时返回 false，由调用方回复未知命令
+func (m *Manager) commandTask(msg *Message, generic int) (registeredHandler, bool) {
+	command, _ := msg.Payload["command"].(string)
+
+	m.handlerMutex.RLock()
+	handler, ok := m.commands[command]
+	m.handlerMutex.RUnlock()
+
+	if !ok {
+		// 已注册的命令消息处理函数自行决定是否回复
+		if generic > 0 {
+			return registeredHandler{}, false
+		}
+		handler = func(msg *Message, params map[string]interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("%w: %q", errUnknownCommand, command)
+		}
+	}
+
+	return registeredHandler{
+		handle: func(msg *Message) error {
+			return m.runCommand(msg, command, handler)
+		},
+		ptr: reflect.ValueOf(handler).Pointer(),
+	}, true
+}
+
+// runCommand 执行命令处理函数并发送响应
+// 处理函数 panic 时先回复失败，再交给处理函数执行池恢复并计数
+func (m *Manager) runCommand(msg *Message, command string, handler CommandHandler) error {
+	responded := false
+	defer func() {
+		if r := recover(); r != nil {
+			if !responded {
+				m.SendResponse(msg.ID, false, nil, fmt.Sprintf("命令执行失败: %v", r))
+			}
+			panic(r)
+		}
+	}()
+
+	params, _ := msg.Payload["params"].(map[string]interface{})
+	result, err := handler(msg, params)
+	responded = true
+	if err != nil {
+		m.SendResponse(msg.ID, false, result, err.Error())
+		return err
+	}
+
+	m.logger.Debug("命令执行完成", "id", msg.ID, "command", command)
+	m.SendResponse(msg.ID, true, result, "")
+	return nil
+}
@@ -0,0 +1,141 @@
+package comm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitResponse 等待服务器收到关联到指定命令消息的响应
+func waitResponse(t *testing.T, received chan map[string]interface{}, requestID string) map[string]interface{} {
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case raw := <-received:
+			if raw["type"] != string(MessageTypeResponse) {
+				continue
+			}
+			payload, _ := raw["payload"].(map[string]interface{})
+			if payload["request_id"] == requestID {
+				return payload
+			}
+		case <-deadline:
+			t.Fatalf("服务器未收到命令 %s 的响应", requestID)
+			return nil
+		}
+	}
+}
+
+// TestManagerCommandResponses 测试服务端下发命令后收到关联的响应
+func TestManagerCommandResponses(t *testing.T) {
+	commands := []string{
+		`{"id": "cmd-ping", "type": "command", "timestamp": 1, "payload": {"command": "ping"}}`,
+		`{"id": "cmd-echo", "type": "command", "timestamp": 1, "payload": {"command": "echo", "params": {"text": "hello"}}}`,
+		`{"id": "cmd-fail", "type": "command", "timestamp": 1, "payload": {"command": "update", "params": {"version": "2.0.0"}}}`,
+		`{"id": "cmd-panic", "type": "command", "timestamp": 1, "payload": {"command": "restart"}}`,
+		`{"id": "cmd-unknown", "type": "command", "timestamp": 1, "payload": {"command": "reboot"}}`,
+	}
+	server, received := newVersionedTestServer(t, func(connectID string) []string {
+		return commands
+	})
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ServerURL = "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	config.MaxReconnectAttempts = 0
+	manager := NewManager(config, nil)
+
+	manager.RegisterCommandHandler("echo", func(msg *Message, params map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"text": params["text"], "id": msg.ID}, nil
+	})
+	manager.RegisterCommandHandler("update", func(msg *Message, params map[string]interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("下载版本 %v 失败", params["version"])
+	})
+	manager.RegisterCommandHandler("restart", func(msg *Message, params map[string]interface{}) (interface{}, error) {
+		panic("重启失败")
+	})
+
+	if err := manager.Connect(); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer manager.Disconnect()
+
+	pong := waitResponse(t, received, "cmd-ping")
+	if pong["success"] != true {
+		t.Errorf("ping 命令应执行成功: %v", pong)
+	}
+	if data, _ := pong["data"].(map[string]interface{}); data["pong"] != true {
+		t.Errorf("ping 命令应回复 pong: %v", pong)
+	}
+
+	echo := waitResponse(t, received, "cmd-echo")
+	data, _ := echo["data"].(map[string]interface{})
+	if echo["success"] != true || data["text"] != "hello" || data["id"] != "cmd-echo" {
+		t.Errorf("echo 命令应返回处理函数的结果: %v", echo)
+	}
+
+	failed := waitResponse(t, received, "cmd-fail")
+	if failed["success"] != false || failed["error"] != "下载版本 2.0.0 失败" {
+		t.Errorf("处理函数返回错误时响应应标记为失败: %v", failed)
+	}
+
+	panicked := waitResponse(t, received, "cmd-panic")
+	if panicked["success"] != false || !strings.Contains(fmt.Sprint(panicked["error"]), "重启失败") {
+		t.Errorf("处理函数 panic 时响应应标记为失败: %v", panicked)
+	}
+
+	unknown := waitResponse(t, received, "cmd-unknown")
+	if unknown["success"] != false || !strings.Contains(fmt.Sprint(unknown["error"]), "reboot") {
+		t.Errorf("未知命令应回复失败: %v", unknown)
+	}
+
+	// 处理器指标计入错误和 panic
+	deadline := time.Now().Add(time.Second)
+	for {
+		stats := manager.GetHandlerStats()
+		if stats.Executed >= uint64(len(commands)) {
+			if stats.Errors != 2 || stats.Panics != 1 {
+				t.Errorf("处理器指标应有2个错误和1个panic，实际为 %+v", stats)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("命令未全部执行: %+v", stats)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestManagerCommandHandlerRegistration 测试命令处理函数的注册、替换和注销
+func TestManagerCommandHandlerRegistration(t *testing.T) {
+	config := DefaultConfig()
+	manager := NewManager(config, nil)
+
+	calls := make(chan string, 1)
+	msg := &Message{ID: "1", Type: MessageTypeCommand, Payload: map[string]interface{}{"command": CommandPing}}
+
+	// 内置的 ping 命令在有命令消息处理函数时也会回复
+	if _, ok := manager.commandTask(msg, 1); !ok {
+		t.Fatal("内置的 ping 命令应该有处理函数")
+	}
+
+	// 同名命令的处理函数被替换
+	manager.RegisterCommandHandler(CommandPing, func(msg *Message, params map[string]interface{}) (interface{}, error) {
+		calls <- "custom"
+		return "pong", nil
+	})
+	manager.commands[CommandPing](nil, nil)
+	if got := <-calls; got != "custom" {
+		t.Errorf("ping 命令应使用替换后的处理函数，实际为 %s", got)
+	}
+
+	// 注销后，没有命令消息处理函数时回复未知命令，有则交给命令消息处理函数
+	manager.UnregisterCommandHandler(CommandPing)
+	if _, ok := manager.commandTask(msg, 0); !ok {
+		t.Error("没有任何处理函数时应回复未知命令")
+	}
+	if _, ok := manager.commandTask(msg, 1); ok {
+		t.Error("有命令消息处理函数时不应回复未知命令")
+	}
+}
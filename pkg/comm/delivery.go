@@ -0,0 +1,291 @@
+package comm
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultDedupWindow 去重窗口保留的已确认序号数量
+	defaultDedupWindow = 1024
+
+	// defaultMaxInflight 最多保留的已发送未确认消息数量，超出时丢弃最早的消息
+	defaultMaxInflight = 1024
+
+	// seqReserveBlock 每次持久化预留的序号数量，重启后从预留上限继续分配，保证序号不回退
+	seqReserveBlock = 1024
+
+	// sequenceSaveInterval 确认后持久化序号状态的最小间隔
+	sequenceSaveInterval = time.Second
+)
+
+// sequenceState 持久化的序号状态
+type sequenceState struct {
+	NextSeq      uint64 `json:"next_seq"`       // 重启后分配的第一个序号
+	LastAckedSeq uint64 `json:"last_acked_seq"` // 收到确认的最大序号
+}
+
+// inflightMessage 已发送等待确认的业务消息
+type inflightMessage struct {
+	msg      *Message
+	priority MessagePriority
+	queued   bool // 已重新加入发送队列，尚未写出
+}
+
+// deliveryTracker 为业务消息分配单调递增的序号并跟踪确认
+// 重连后重发已发送未确认的消息；去重窗口记录最近确认的序号，已确认的消息不会再次发送
+type deliveryTracker struct {
+	mu         sync.Mutex
+	nextSeq    uint64
+	reserved   uint64 // 已持久化的序号上限，分配到该值时先持久化新的上限
+	lastAcked  uint64
+	inflight   map[uint64]inflightMessage
+	ids        map[string]uint64 // 消息ID到序号的映射，确认消息只带消息ID时使用
+	acked      map[uint64]struct{}
+	ackedOrder []uint64 // 去重窗口中的序号，按确认顺序排列
+
+	window      int
+	maxInflight int
+	resent      uint64
+	skipped     uint64
+	dropped     uint64
+
+	stateFile string
+	dirty     bool
+	lastSaved time.Time
+	now       func() time.Time
+}
+
+// newDeliveryTracker 创建投递跟踪器，stateFile 不为空时从中恢复序号状态
+func newDeliveryTracker(stateFile string) (*deliveryTracker, error) {
+	t := &deliveryTracker{
+		nextSeq:     1,
+		inflight:    make(map[uint64]inflightMessage),
+		ids:         make(map[string]uint64),
+		acked:       make(map[uint64]struct{}),
+		window:      defaultDedupWindow,
+		maxInflight: defaultMaxInflight,
+		stateFile:   stateFile,
+		now:         time.Now,
+	}
+	if stateFile == "" {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(stateFile)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return t, fmt.Errorf("读取序号状态失败: %w", err)
+	}
+	var state sequenceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return t, fmt.Errorf("解析序号状态失败: %w", err)
+	}
+	t.lastAcked = state.LastAckedSeq
+	if state.NextSeq > t.nextSeq {
+		t.nextSeq = state.NextSeq
+	}
+	if t.lastAcked >= t.nextSeq {
+		t.nextSeq = t.lastAcked + 1
+	}
+	t.reserved = t.nextSeq
+	return t, nil
+}
+
+// needsSequence 检查消息是否需要序号，系统消息不需要确认，不分配序号
+func needsSequence(msg *Message) bool {
+	switch msg.Type {
+	case MessageTypeHeartbeat, MessageTypeConnect, MessageTypeAck, MessageTypeAuth:
+		return false
+	default:
+		return true
+	}
+}
+
+// prepare 在消息写出前调用：首次发送时分配序号，并将消息记为已发送未确认
+// 消息已被确认时返回 false，调用方应跳过发送
+func (t *deliveryTracker) prepare(msg *Message, priority MessagePriority) (bool, error) {
+	if !needsSequence(msg) {
+		return true, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if msg.Seq != 0 {
+		if _, ok := t.acked[msg.Seq]; ok {
+			t.skipped++
+			return false, nil
+		}
+	} else {
+		msg.Seq = t.nextSeq
+		t.nextSeq++
+	}
+
+	if len(t.inflight) >= t.maxInflight {
+		if _, ok := t.inflight[msg.Seq]; !ok {
+			t.dropOldestLocked()
+		}
+	}
+	t.inflight[msg.Seq] = inflightMessage{msg: msg, priority: priority}
+	t.ids[msg.ID] = msg.Seq
+
+	if t.stateFile != "" && t.nextSeq > t.reserved {
+		t.reserved = t.nextSeq + seqReserveBlock
+		return true, t.saveLocked()
+	}
+	return true, nil
+}
+
+// dropOldestLocked 丢弃序号最小的未确认消息，调用方需持有锁
+func (t *deliveryTracker) dropOldestLocked() {
+	var oldest uint64
+	for seq := range t.inflight {
+		if oldest == 0 || seq < oldest {
+			oldest = seq
+		}
+	}
+	if oldest != 0 {
+		delete(t.ids, t.inflight[oldest].msg.ID)
+		delete(t.inflight, oldest)
+		t.dropped++
+	}
+}
+
+// ack 记录收到确认，确认消息带序号时按序号匹配，否则按消息ID匹配
+// 返回确认是否对应一条已发送的消息
+func (t *deliveryTracker) ack(messageID string, seq uint64) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if seq == 0 {
+		var ok bool
+		if seq, ok = t.ids[messageID]; !ok {
+			return false, nil
+		}
+	}
+	if _, ok := t.acked[seq]; ok {
+		return false, nil
+	}
+
+	inflight, tracked := t.inflight[seq]
+	if tracked {
+		delete(t.ids, inflight.msg.ID)
+		delete(t.inflight, seq)
+	}
+
+	t.acked[seq] = struct{}{}
+	t.ackedOrder = append(t.ackedOrder, seq)
+	if len(t.ackedOrder) > t.window {
+		delete(t.acked, t.ackedOrder[0])
+		t.ackedOrder = t.ackedOrder[1:]
+	}
+
+	if seq > t.lastAcked {
+		t.lastAcked = seq
+		t.dirty = true
+	}
+	if t.dirty && t.stateFile != "" && t.now().Sub(t.lastSaved) >= sequenceSaveInterval {
+		return tracked, t.saveLocked()
+	}
+	return tracked, nil
+}
+
+// unacked 返回需要重发的已发送未确认消息，按序号从小到大排列，返回的消息记为已入队
+func (t *deliveryTracker) unacked() []inflightMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	messages := make([]inflightMessage, 0, len(t.inflight))
+	for seq, inflight := range t.inflight {
+		// 连续重连时，已在发送队列中等待重发的消息不重复入队
+		if inflight.queued {
+			continue
+		}
+		inflight.queued = true
+		t.inflight[seq] = inflight
+		messages = append(messages, inflight)
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].msg.Seq < messages[j].msg.Seq })
+	t.resent += uint64(len(messages))
+	return messages
+}
+
+// reset 丢弃所有已发送未确认的消息，返回丢弃的数量
+func (t *deliveryTracker) reset() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count := len(t.inflight)
+	t.inflight = make(map[uint64]inflightMessage)
+	t.ids = make(map[string]uint64)
+	return count
+}
+
+// lastAckedSeq 返回收到确认的最大序号
+func (t *deliveryTracker) lastAckedSeq() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastAcked
+}
+
+// flush 立即持久化尚未保存的序号状态
+func (t *deliveryTracker) flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.stateFile == "" || !t.dirty {
+		return nil
+	}
+	return t.saveLocked()
+}
+
+// saveLocked 原子地写入序号状态，调用方需持有锁
+func (t *deliveryTracker) saveLocked() error {
+	next := t.reserved
+	if next < t.nextSeq {
+		next = t.nextSeq
+	}
+	data, err := json.Marshal(sequenceState{NextSeq: next, LastAckedSeq: t.lastAcked})
+	if err != nil {
+		return fmt.Errorf("编码序号状态失败: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.stateFile), 0755); err != nil {
+		return fmt.Errorf("创建序号状态目录失败: %w", err)
+	}
+	tmp := t.stateFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入序号状态失败: %w", err)
+	}
+	if err := os.Rename(tmp, t.stateFile); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("保存序号状态失败: %w", err)
+	}
+
+	t.dirty = false
+	t.lastSaved = t.now()
+	return nil
+}
+
+// metrics 返回可导出的投递指标
+func (t *deliveryTracker) metrics() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return map[string]interface{}{
+		"next_seq":           t.nextSeq,
+		"last_acked_seq":     t.lastAcked,
+		"inflight_messages":  len(t.inflight),
+		"resent_messages":    t.resent,
+		"skipped_duplicates": t.skipped,
+		"dropped_inflight":   t.dropped,
+	}
+}
@@ -0,0 +1,259 @@
+package comm
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestDeliveryTrackerSequence 测试业务消息分配单调递增的序号，系统消息不分配序号
+func TestDeliveryTrackerSequence(t *testing.T) {
+	tracker, err := newDeliveryTracker("")
+	if err != nil {
+		t.Fatalf("创建投递跟踪器失败: %v", err)
+	}
+
+	var last uint64
+	for i := 0; i < 5; i++ {
+		msg := NewMessage(MessageTypeEvent, map[string]interface{}{"index": i})
+		if send, err := tracker.prepare(msg, PriorityNormal); !send || err != nil {
+			t.Fatalf("消息应该发送: send=%v, err=%v", send, err)
+		}
+		if msg.Seq <= last {
+			t.Errorf("序号应该单调递增: 上一个 %d, 当前 %d", last, msg.Seq)
+		}
+		last = msg.Seq
+	}
+
+	heartbeat := createHeartbeatMessage()
+	tracker.prepare(heartbeat, PriorityHigh)
+	if heartbeat.Seq != 0 {
+		t.Errorf("心跳消息不应分配序号，但分配了 %d", heartbeat.Seq)
+	}
+	if count := len(tracker.unacked()); count != 5 {
+		t.Errorf("期望 5 条未确认消息，实际 %d 条", count)
+	}
+}
+
+// TestDeliveryTrackerDedupWindow 测试已确认的消息不会重发，按消息ID或序号确认均可
+func TestDeliveryTrackerDedupWindow(t *testing.T) {
+	tracker, _ := newDeliveryTracker("")
+
+	var messages []*Message
+	for i := 0; i < 4; i++ {
+		msg := NewMessage(MessageTypeData, map[string]interface{}{"index": i})
+		tracker.prepare(msg, PriorityNormal)
+		messages = append(messages, msg)
+	}
+
+	if tracked, _ := tracker.ack(messages[0].ID, 0); !tracked {
+		t.Error("按消息ID确认应该匹配已发送的消息")
+	}
+	if tracked, _ := tracker.ack("", messages[1].Seq); !tracked {
+		t.Error("按序号确认应该匹配已发送的消息")
+	}
+	if tracked, _ := tracker.ack(messages[1].ID, 0); tracked {
+		t.Error("重复确认不应再次匹配")
+	}
+
+	unacked := tracker.unacked()
+	if len(unacked) != 2 || unacked[0].msg != messages[2] || unacked[1].msg != messages[3] {
+		t.Fatalf("未确认消息不正确: %d 条", len(unacked))
+	}
+
+	// 重发前收到确认，发送时跳过
+	tracker.ack(messages[2].ID, 0)
+	if send, _ := tracker.prepare(messages[2], PriorityNormal); send {
+		t.Error("已确认的消息不应重发")
+	}
+	if send, _ := tracker.prepare(messages[3], PriorityNormal); !send {
+		t.Error("未确认的消息应该重发")
+	}
+	if messages[3].Seq != 4 {
+		t.Errorf("重发的消息应保持原序号 4，实际 %d", messages[3].Seq)
+	}
+
+	// 连续重连时，已入队等待重发的消息不重复入队
+	tracker.unacked()
+	if count := len(tracker.unacked()); count != 0 {
+		t.Errorf("已入队的消息不应再次重发，实际 %d 条", count)
+	}
+}
+
+// TestDeliveryTrackerPersistence 测试序号状态跨重启保存，重启后序号不回退
+func TestDeliveryTrackerPersistence(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "comm", "sequence.json")
+
+	tracker, err := newDeliveryTracker(stateFile)
+	if err != nil {
+		t.Fatalf("创建投递跟踪器失败: %v", err)
+	}
+	var lastSeq uint64
+	for i := 0; i < 3; i++ {
+		msg := NewMessage(MessageTypeEvent, nil)
+		if _, err := tracker.prepare(msg, PriorityNormal); err != nil {
+			t.Fatalf("持久化序号失败: %v", err)
+		}
+		if _, err := tracker.ack(msg.ID, 0); err != nil {
+			t.Fatalf("持久化已确认序号失败: %v", err)
+		}
+		lastSeq = msg.Seq
+	}
+	if err := tracker.flush(); err != nil {
+		t.Fatalf("保存序号状态失败: %v", err)
+	}
+
+	restarted, err := newDeliveryTracker(stateFile)
+	if err != nil {
+		t.Fatalf("恢复序号状态失败: %v", err)
+	}
+	if acked := restarted.lastAckedSeq(); acked != lastSeq {
+		t.Errorf("期望已确认序号 %d，实际 %d", lastSeq, acked)
+	}
+	msg := NewMessage(MessageTypeEvent, nil)
+	restarted.prepare(msg, PriorityNormal)
+	if msg.Seq <= lastSeq {
+		t.Errorf("重启后序号应大于 %d，实际 %d", lastSeq, msg.Seq)
+	}
+}
+
+// TestDeliveryTrackerCorruptState 测试序号状态文件损坏时从头分配序号
+func TestDeliveryTrackerCorruptState(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "sequence.json")
+	if err := os.WriteFile(stateFile, []byte("{broken"), 0644); err != nil {
+		t.Fatalf("写入状态文件失败: %v", err)
+	}
+
+	tracker, err := newDeliveryTracker(stateFile)
+	if err == nil {
+		t.Error("状态文件损坏时应该返回错误")
+	}
+	if tracker == nil {
+		t.Fatal("状态文件损坏时仍应返回可用的跟踪器")
+	}
+	msg := NewMessage(MessageTypeEvent, nil)
+	tracker.prepare(msg, PriorityNormal)
+	if msg.Seq != 1 {
+		t.Errorf("期望从序号 1 开始，实际 %d", msg.Seq)
+	}
+}
+
+// TestClientResendOnReconnect 测试连接在消息确认途中断开后，重连只重发未确认的消息
+func TestClientResendOnReconnect(t *testing.T) {
+	const total, acked = 6, 3
+
+	var mu sync.Mutex
+	var connections int
+	firstSeqs := make(map[uint64]string)
+	resent := make(chan *Message, total)
+	reconnected := make(chan *Message, 1)
+
+	server := newWSTestServer(t, func(conn *websocket.Conn, r *http.Request) {
+		mu.Lock()
+		connections++
+		first := connections == 1
+		mu.Unlock()
+
+		received := 0
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			msg, err := decodeMessage(data)
+			if err != nil || msg.Type == MessageTypeAck || msg.Type == MessageTypeHeartbeat {
+				continue
+			}
+			if msg.Type == MessageTypeConnect {
+				if !first {
+					reconnected <- msg
+				}
+				continue
+			}
+
+			if !first {
+				resent <- msg
+				continue
+			}
+
+			// 第一次连接：收到全部消息，只确认前几条，然后断开连接
+			mu.Lock()
+			firstSeqs[msg.Seq] = msg.ID
+			mu.Unlock()
+			received++
+			if received <= acked {
+				reply, _ := encodeMessage(createAckMessage(msg.ID))
+				conn.WriteMessage(websocket.TextMessage, reply)
+			}
+			if received == total {
+				time.Sleep(100 * time.Millisecond)
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ServerURL = wsURL(server)
+	config.HeartbeatInterval = 10 * time.Second
+	config.ReconnectInterval = 50 * time.Millisecond
+	config.CloseTimeout = 200 * time.Millisecond
+	config.SequenceStateFile = filepath.Join(t.TempDir(), "sequence.json")
+
+	client := NewClient(config, nil)
+	if err := client.Connect(); err != nil {
+		t.Fatalf("连接服务器失败: %v", err)
+	}
+	defer client.Disconnect()
+
+	for i := 0; i < total; i++ {
+		client.Send(NewMessage(MessageTypeEvent, map[string]interface{}{"index": i}))
+	}
+
+	var connectMsg *Message
+	select {
+	case connectMsg = <-reconnected:
+	case <-time.After(3 * time.Second):
+		t.Fatal("超时等待客户端重连")
+	}
+	if seq, _ := connectMsg.Payload["last_acked_seq"].(float64); seq != acked {
+		t.Errorf("重连消息中的已确认序号应为 %d，实际 %v", acked, connectMsg.Payload["last_acked_seq"])
+	}
+
+	var got []*Message
+	timeout := time.After(2 * time.Second)
+	for len(got) < total-acked {
+		select {
+		case msg := <-resent:
+			got = append(got, msg)
+		case <-timeout:
+			t.Fatalf("超时等待重发消息，已收到 %d 条", len(got))
+		}
+	}
+
+	// 等待片刻，确认已确认的消息没有被重发
+	select {
+	case msg := <-resent:
+		t.Errorf("不应收到额外的重发消息: seq=%d", msg.Seq)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	sort.Slice(got, func(i, j int) bool { return got[i].Seq < got[j].Seq })
+	mu.Lock()
+	defer mu.Unlock()
+	for i, msg := range got {
+		wantSeq := uint64(acked + i + 1)
+		if msg.Seq != wantSeq {
+			t.Errorf("第 %d 条重发消息序号应为 %d，实际 %d", i, wantSeq, msg.Seq)
+		}
+		if firstSeqs[msg.Seq] != msg.ID {
+			t.Errorf("重发消息应保持原消息ID: seq=%d", msg.Seq)
+		}
+	}
+}
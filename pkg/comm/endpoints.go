@@ -0,0 +1,224 @@
+package comm
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// EndpointStrategy 定义服务器端点选择策略
+type EndpointStrategy string
+
+const (
+	EndpointStrategyOrdered EndpointStrategy = "ordered" // 按配置顺序故障转移
+	EndpointStrategyRandom  EndpointStrategy = "random"  // 随机顺序故障转移，分散客户端负载
+)
+
+// EndpointStatus 服务器端点状态
+type EndpointStatus struct {
+	URL                 string    `json:"url"`
+	Healthy             bool      `json:"healthy"`
+	Active              bool      `json:"active"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	TotalFailures       uint64    `json:"total_failures"`
+	LastSuccess         time.Time `json:"last_success,omitempty"`
+	LastFailure         time.Time `json:"last_failure,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
+// endpointState 端点运行状态
+type endpointState struct {
+	url                 string
+	consecutiveFailures int
+	totalFailures       uint64
+	unhealthyUntil      time.Time
+	lastSuccess         time.Time
+	lastFailure         time.Time
+	lastError           string
+}
+
+// endpointSelector 服务器端点选择器
+// 优先使用最近一次连接成功的端点，连续失败达到阈值的端点在冷却期内排到最后
+type endpointSelector struct {
+	endpoints []*endpointState
+	strategy  EndpointStrategy
+	threshold int
+	cooldown  time.Duration
+	preferred int // 最近一次连接成功的端点索引，-1表示无
+	active    int // 当前连接的端点索引，-1表示未连接
+	rand      *rand.Rand
+	mu        sync.Mutex
+}
+
+// newEndpointSelector 根据连接配置创建端点选择器
+func newEndpointSelector(config ConnectionConfig) *endpointSelector {
+	urls := config.ServerURLs
+	if len(urls) == 0 && config.ServerURL != "" {
+		urls = []string{config.ServerURL}
+	}
+
+	selector := &endpointSelector{
+		strategy:  config.EndpointStrategy,
+		threshold: config.EndpointFailureThreshold,
+		cooldown:  config.EndpointCooldown,
+		preferred: -1,
+		active:    -1,
+		rand:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	if selector.threshold <= 0 {
+		selector.threshold = 1
+	}
+
+	seen := make(map[string]bool)
+	for _, url := range urls {
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		selector.endpoints = append(selector.endpoints, &endpointState{url: url})
+	}
+	return selector
+}
+
+// candidates 返回本次连接尝试的端点顺序
+func (s *endpointSelector) candidates() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order := make([]int, 0, len(s.endpoints))
+	for i := range s.endpoints {
+		order = append(order, i)
+	}
+
+	if s.strategy == EndpointStrategyRandom {
+		s.rand.Shuffle(len(order), func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+	}
+
+	// 粘性偏好：最近一次成功的端点优先
+	if s.preferred >= 0 {
+		for i, idx := range order {
+			if idx == s.preferred {
+				copy(order[1:i+1], order[:i])
+				order[0] = idx
+				break
+			}
+		}
+	}
+
+	// 健康端点在前，冷却期内的端点在后
+	now := time.Now()
+	var healthy, unhealthy []string
+	for _, idx := range order {
+		endpoint := s.endpoints[idx]
+		if now.Before(endpoint.unhealthyUntil) {
+			unhealthy = append(unhealthy, endpoint.url)
+		} else {
+			healthy = append(healthy, endpoint.url)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// recordSuccess 记录端点连接成功
+func (s *endpointSelector) recordSuccess(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.indexOf(url)
+	if idx < 0 {
+		return
+	}
+
+	endpoint := s.endpoints[idx]
+	endpoint.consecutiveFailures = 0
+	endpoint.unhealthyUntil = time.Time{}
+	endpoint.lastSuccess = time.Now()
+	s.preferred = idx
+	s.active = idx
+}
+
+// recordFailure 记录端点连接失败，返回端点是否因此被标记为不健康
+func (s *endpointSelector) recordFailure(url string, err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	idx := s.indexOf(url)
+	if idx < 0 {
+		return false
+	}
+
+	endpoint := s.endpoints[idx]
+	endpoint.consecutiveFailures++
+	endpoint.totalFailures++
+	endpoint.lastFailure = time.Now()
+	if err != nil {
+		endpoint.lastError = err.Error()
+	}
+	if s.active == idx {
+		s.active = -1
+	}
+
+	if endpoint.consecutiveFailures < s.threshold {
+		return false
+	}
+
+	// 连续失败达到阈值，轮换到下一个端点
+	endpoint.unhealthyUntil = endpoint.lastFailure.Add(s.cooldown)
+	if s.preferred == idx {
+		s.preferred = -1
+	}
+	return true
+}
+
+// markDisconnected 标记当前没有活动端点
+func (s *endpointSelector) markDisconnected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active = -1
+}
+
+// activeEndpoint 返回当前连接的端点，未连接时返回空字符串
+func (s *endpointSelector) activeEndpoint() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.active < 0 {
+		return ""
+	}
+	return s.endpoints[s.active].url
+}
+
+// status 返回所有端点的状态
+func (s *endpointSelector) status() []EndpointStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	result := make([]EndpointStatus, 0, len(s.endpoints))
+	for i, endpoint := range s.endpoints {
+		result = append(result, EndpointStatus{
+			URL:                 endpoint.url,
+			Healthy:             !now.Before(endpoint.unhealthyUntil),
+			Active:              i == s.active,
+			ConsecutiveFailures: endpoint.consecutiveFailures,
+			TotalFailures:       endpoint.totalFailures,
+			LastSuccess:         endpoint.lastSuccess,
+			LastFailure:         endpoint.lastFailure,
+			LastError:           endpoint.lastError,
+		})
+	}
+	return result
+}
+
+// indexOf 查找端点索引，调用方需持有锁
+func (s *endpointSelector) indexOf(url string) int {
+	for i, endpoint := range s.endpoints {
+		if endpoint.url == url {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,168 @@
+package comm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+const (
+	// DefaultHandlerWorkers 默认的消息处理协程上限
+	DefaultHandlerWorkers = 8
+	// DefaultHandlerQueueSize 默认的待处理消息队列长度
+	DefaultHandlerQueueSize = 256
+)
+
+// errHandlerPanic 处理函数发生 panic
+var errHandlerPanic = errors.New("处理函数发生panic")
+
+// MessageHandlerFunc 返回错误的消息处理函数，错误计入处理器指标
+type MessageHandlerFunc func(msg *Message) error
+
+// HandlerStats 消息处理函数的执行统计
+type HandlerStats struct {
+	Executed uint64 `json:"executed"` // 已执行的处理函数调用次数
+	Errors   uint64 `json:"errors"`   // 返回错误的调用次数
+	Panics   uint64 `json:"panics"`   // 发生 panic 的调用次数
+	Dropped  uint64 `json:"dropped"`  // 队列已满被丢弃的调用次数
+	Active   int    `json:"active"`   // 正在运行的处理协程数
+	Queued   int    `json:"queued"`   // 等待执行的调用数
+}
+
+// registeredHandler 已注册的处理函数
+type registeredHandler struct {
+	handle MessageHandlerFunc
+	ptr    uintptr // 原始函数的地址，用于注销
+}
+
+// handlerTask 一次待执行的处理函数调用
+type handlerTask struct {
+	handler registeredHandler
+	msg     *Message
+}
+
+// handlerPool 有界的处理函数执行池
+// 处理函数在独立的协程中执行，协程数不超过 workers，待执行调用超过 queueSize 时丢弃；
+// 慢处理函数只占用一个协程，panic 被恢复并计数，不影响消息读取和其他处理函数
+type handlerPool struct {
+	workers   int
+	queueSize int
+	logger    logging.Logger
+
+	mu     sync.Mutex
+	queue  []handlerTask
+	active int
+	stats  HandlerStats
+}
+
+// newHandlerPool 创建处理函数执行池，参数不大于0时使用默认值
+func newHandlerPool(workers, queueSize int, logger logging.Logger) *handlerPool {
+	if workers <= 0 {
+		workers = DefaultHandlerWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultHandlerQueueSize
+	}
+	return &handlerPool{
+		workers:   workers,
+		queueSize: queueSize,
+		logger:    logger,
+	}
+}
+
+// submit 提交处理函数调用，队列已满时丢弃并返回 false
+func (p *handlerPool) submit(task handlerTask) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.queue) >= p.queueSize {
+		p.stats.Dropped++
+		return false
+	}
+	p.queue = append(p.queue, task)
+
+	// 按需启动协程，队列清空后协程退出
+	if p.active < p.workers {
+		p.active++
+		go p.work()
+	}
+	return true
+}
+
+// work 依次执行队列中的调用，队列为空时退出
+func (p *handlerPool) work() {
+	for {
+		p.mu.Lock()
+		if len(p.queue) == 0 {
+			p.active--
+			p.mu.Unlock()
+			return
+		}
+		task := p.queue[0]
+		p.queue[0] = handlerTask{}
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+
+		err := p.run(task)
+
+		p.mu.Lock()
+		p.stats.Executed++
+		if errors.Is(err, errHandlerPanic) {
+			p.stats.Panics++
+		} else if err != nil {
+			p.stats.Errors++
+		}
+		p.mu.Unlock()
+	}
+}
+
+// run 执行一次处理函数调用，恢复处理函数中的 panic
+func (p *handlerPool) run(task handlerTask) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", errHandlerPanic, r)
+			p.logger.Error("消息处理函数发生panic", "id", task.msg.ID, "type", task.msg.Type, "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+
+	if err := task.handler.handle(task.msg); err != nil {
+		p.logger.Warn("消息处理函数返回错误", "id", task.msg.ID, "type", task.msg.Type, "error", err)
+		return err
+	}
+	return nil
+}
+
+// snapshot 返回执行统计
+func (p *handlerPool) snapshot() HandlerStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.Active = p.active
+	stats.Queued = len(p.queue)
+	return stats
+}
+
+// RegisterTypedHandler 注册类型化的消息处理函数
+// 消息载荷按 json 标签解码为 T 后传给处理函数，解码失败或处理函数返回错误时计入处理器错误指标
+func RegisterTypedHandler[T any](m *Manager, msgType MessageType, handler func(msg *Message, payload T) error) {
+	m.registerHandler(msgType, registeredHandler{
+		handle: func(msg *Message) error {
+			var payload T
+			data, err := json.Marshal(msg.Payload)
+			if err != nil {
+				return fmt.Errorf("编码消息载荷失败: %w", err)
+			}
+			if err := json.Unmarshal(data, &payload); err != nil {
+				return fmt.Errorf("解码消息载荷失败: %w", err)
+			}
+			return handler(msg, payload)
+		},
+		ptr: reflect.ValueOf(handler).Pointer(),
+	})
+}
@@ -0,0 +1,237 @@
+package comm
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// waitFor 等待条件成立，超时返回 false
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return cond()
+}
+
+// newHandlerTestManager 创建不连接服务器的管理器
+func newHandlerTestManager(workers, queueSize int) *Manager {
+	config := DefaultConfig()
+	config.HandlerWorkers = workers
+	config.HandlerQueueSize = queueSize
+	return NewManager(config, nil)
+}
+
+// TestHandlerPoolPanicIsolation 测试处理函数 panic 被恢复并计数，不影响其他处理函数
+func TestHandlerPoolPanicIsolation(t *testing.T) {
+	manager := newHandlerTestManager(2, 16)
+
+	var handled atomic.Int32
+	manager.RegisterHandler(MessageTypeCommand, func(msg *Message) {
+		panic("处理失败")
+	})
+	manager.RegisterHandler(MessageTypeCommand, func(msg *Message) {
+		handled.Add(1)
+	})
+	manager.RegisterHandlerFunc(MessageTypeCommand, func(msg *Message) error {
+		return errors.New("命令无效")
+	})
+
+	for i := 0; i < 5; i++ {
+		manager.dispatchMessage(NewMessage(MessageTypeCommand, map[string]interface{}{"index": i}))
+	}
+
+	if !waitFor(time.Second, func() bool { return manager.GetHandlerStats().Executed == 15 }) {
+		t.Fatalf("处理函数未全部执行: %+v", manager.GetHandlerStats())
+	}
+	stats := manager.GetHandlerStats()
+	if stats.Panics != 5 {
+		t.Errorf("期望 5 次 panic，实际 %d", stats.Panics)
+	}
+	if stats.Errors != 5 {
+		t.Errorf("期望 5 次错误，实际 %d", stats.Errors)
+	}
+	if handled.Load() != 5 {
+		t.Errorf("正常的处理函数应处理 5 条消息，实际 %d", handled.Load())
+	}
+
+	metrics := manager.GetMetrics()
+	if metrics["handler_panics"] != uint64(5) || metrics["handler_errors"] != uint64(5) {
+		t.Errorf("指标中的处理函数统计不正确: panics=%v, errors=%v", metrics["handler_panics"], metrics["handler_errors"])
+	}
+}
+
+// TestHandlerPoolBounded 测试处理协程数有上限，队列已满时丢弃调用
+func TestHandlerPoolBounded(t *testing.T) {
+	manager := newHandlerTestManager(2, 3)
+
+	release := make(chan struct{})
+	var running, maxRunning atomic.Int32
+	manager.RegisterHandler(MessageTypeData, func(msg *Message) {
+		current := running.Add(1)
+		for {
+			max := maxRunning.Load()
+			if current <= max || maxRunning.CompareAndSwap(max, current) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+	})
+
+	// 两个协程各执行一条，队列中等待三条，其余丢弃
+	for i := 0; i < 8; i++ {
+		manager.dispatchMessage(NewMessage(MessageTypeData, nil))
+		if i == 1 {
+			waitFor(time.Second, func() bool { return running.Load() == 2 })
+		}
+	}
+
+	stats := manager.GetHandlerStats()
+	if stats.Active != 2 || stats.Queued != 3 || stats.Dropped != 3 {
+		t.Errorf("期望 2 个协程、3 条排队、3 条丢弃，实际 %+v", stats)
+	}
+
+	close(release)
+	if !waitFor(time.Second, func() bool { return manager.GetHandlerStats().Active == 0 }) {
+		t.Fatal("队列清空后处理协程应退出")
+	}
+	if executed := manager.GetHandlerStats().Executed; executed != 5 {
+		t.Errorf("期望执行 5 次，实际 %d", executed)
+	}
+	if maxRunning.Load() > 2 {
+		t.Errorf("同时运行的处理函数不应超过 2 个，实际 %d", maxRunning.Load())
+	}
+}
+
+// commandPayload 命令消息载荷
+type commandPayload struct {
+	Command string `json:"command"`
+	Params  struct {
+		Timeout int `json:"timeout"`
+	} `json:"params"`
+}
+
+// TestRegisterTypedHandler 测试类型化处理函数解码载荷，解码失败计为错误
+func TestRegisterTypedHandler(t *testing.T) {
+	manager := newHandlerTestManager(1, 16)
+
+	received := make(chan commandPayload, 1)
+	RegisterTypedHandler(manager, MessageTypeCommand, func(msg *Message, payload commandPayload) error {
+		received <- payload
+		return nil
+	})
+
+	manager.dispatchMessage(NewMessage(MessageTypeCommand, map[string]interface{}{
+		"command": "scan",
+		"params":  map[string]interface{}{"timeout": 30},
+	}))
+	select {
+	case payload := <-received:
+		if payload.Command != "scan" || payload.Params.Timeout != 30 {
+			t.Errorf("载荷解码不正确: %+v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("超时等待类型化处理函数")
+	}
+
+	manager.dispatchMessage(NewMessage(MessageTypeCommand, map[string]interface{}{"command": 42}))
+	if !waitFor(time.Second, func() bool { return manager.GetHandlerStats().Errors == 1 }) {
+		t.Errorf("载荷类型不匹配应计为处理错误: %+v", manager.GetHandlerStats())
+	}
+}
+
+// TestUnregisterHandler 测试注销处理函数
+func TestUnregisterHandler(t *testing.T) {
+	manager := newHandlerTestManager(1, 16)
+
+	var calls atomic.Int32
+	handler := func(msg *Message) { calls.Add(1) }
+	manager.RegisterHandler(MessageTypeEvent, handler)
+
+	if err := manager.UnregisterHandler(MessageTypeEvent, handler); err != nil {
+		t.Fatalf("注销处理函数失败: %v", err)
+	}
+	if err := manager.UnregisterHandler(MessageTypeEvent, handler); err == nil {
+		t.Error("重复注销应返回错误")
+	}
+
+	manager.dispatchMessage(NewMessage(MessageTypeEvent, nil))
+	time.Sleep(50 * time.Millisecond)
+	if calls.Load() != 0 {
+		t.Errorf("注销后不应再调用处理函数，实际调用 %d 次", calls.Load())
+	}
+}
+
+// TestManagerReadLoopSurvivesHandlers 测试处理函数 panic 或阻塞时，读取循环继续接收并分发消息
+func TestManagerReadLoopSurvivesHandlers(t *testing.T) {
+	const batches = 3
+	sendBatch := make(chan struct{}, batches)
+
+	server := newWSTestServer(t, func(conn *websocket.Conn, r *http.Request) {
+		go func() {
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+
+		// 每批依次发送会 panic、会阻塞和正常处理的消息
+		for range sendBatch {
+			for _, msgType := range []MessageType{MessageTypeCommand, MessageTypeData, MessageTypeEvent} {
+				data, _ := encodeMessage(NewMessage(msgType, nil))
+				if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+					return
+				}
+			}
+		}
+	})
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ServerURL = wsURL(server)
+	config.HeartbeatInterval = 10 * time.Second
+	config.CloseTimeout = 100 * time.Millisecond
+	config.HandlerWorkers = 4
+	manager := NewManager(config, nil)
+
+	release := make(chan struct{})
+	defer close(release)
+	var events atomic.Int32
+	manager.RegisterHandler(MessageTypeCommand, func(msg *Message) { panic("处理函数崩溃") })
+	manager.RegisterHandler(MessageTypeData, func(msg *Message) { <-release })
+	manager.RegisterHandler(MessageTypeEvent, func(msg *Message) { events.Add(1) })
+
+	if err := manager.Connect(); err != nil {
+		t.Fatalf("连接服务器失败: %v", err)
+	}
+	defer manager.Disconnect()
+
+	for i := 1; i <= batches; i++ {
+		sendBatch <- struct{}{}
+		want := int32(i)
+		if !waitFor(2*time.Second, func() bool { return events.Load() == want }) {
+			t.Fatalf("第 %d 批消息未处理，事件数 %d，统计 %+v", i, events.Load(), manager.GetHandlerStats())
+		}
+	}
+
+	stats := manager.GetHandlerStats()
+	if stats.Panics != batches {
+		t.Errorf("期望 %d 次 panic，实际 %d", batches, stats.Panics)
+	}
+	if stats.Active < batches {
+		t.Errorf("阻塞的处理函数应占用 %d 个协程，实际 %d", batches, stats.Active)
+	}
+	if !manager.IsConnected() {
+		t.Error("处理函数 panic 后连接应保持")
+	}
+}
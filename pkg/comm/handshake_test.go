@@ -0,0 +1,102 @@
+package comm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newGatewayTestServer 创建要求指定子协议和认证头的WebSocket测试服务器，模拟反向代理网关
+func newGatewayTestServer(t *testing.T, subprotocol, header, value string) *httptest.Server {
+	options := wsTestServerOptions{
+		Subprotocols: []string{subprotocol},
+		Accept: func(w http.ResponseWriter, r *http.Request) bool {
+			if r.Header.Get(header) != value {
+				http.Error(w, "缺少网关认证头", http.StatusUnauthorized)
+				return false
+			}
+			offered := false
+			for _, protocol := range websocket.Subprotocols(r) {
+				if protocol == subprotocol {
+					offered = true
+				}
+			}
+			if !offered {
+				http.Error(w, "不支持的子协议", http.StatusBadRequest)
+				return false
+			}
+			return true
+		},
+	}
+
+	return newWSTestServerWithOptions(t, options, func(conn *websocket.Conn, r *http.Request) {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func TestClientHandshakeSubprotocolAndHeaders(t *testing.T) {
+	server := newGatewayTestServer(t, "kennel.v1", "X-Gateway-Token", "secret")
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		subprotocols []string
+		headers      map[string]string
+		wantConnect  bool
+	}{
+		{name: "未提供子协议和请求头"},
+		{name: "只提供请求头", headers: map[string]string{"X-Gateway-Token": "secret"}},
+		{name: "只提供子协议", subprotocols: []string{"kennel.v1"}},
+		{name: "请求头的值错误", subprotocols: []string{"kennel.v1"}, headers: map[string]string{"X-Gateway-Token": "wrong"}},
+		{name: "子协议不匹配", subprotocols: []string{"kennel.v2"}, headers: map[string]string{"X-Gateway-Token": "secret"}},
+		{
+			name:         "同时提供子协议和请求头",
+			subprotocols: []string{"kennel.v2", "kennel.v1"},
+			headers:      map[string]string{"x-gateway-token": "secret"},
+			wantConnect:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.ServerURL = wsURL(server)
+			config.HandshakeTimeout = time.Second
+			config.CloseTimeout = 50 * time.Millisecond
+			config.Subprotocols = tt.subprotocols
+			config.Headers = tt.headers
+
+			client := NewClient(config, nil)
+			err := client.Connect()
+			if !tt.wantConnect {
+				if err == nil {
+					client.Disconnect()
+					t.Fatal("网关拒绝握手时连接应该失败")
+				}
+				if client.GetSubprotocol() != "" {
+					t.Errorf("连接失败时不应有协商的子协议，实际为 %q", client.GetSubprotocol())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("连接服务器失败: %v", err)
+			}
+			defer client.Disconnect()
+
+			if got := client.GetSubprotocol(); got != "kennel.v1" {
+				t.Errorf("协商的子协议应为 kennel.v1，实际为 %q", got)
+			}
+			if !client.IsConnected() {
+				t.Error("客户端应该处于已连接状态")
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package comm
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultHeartbeatStableThreshold 默认连续多少个心跳周期内连接无中断后延长心跳间隔
+const DefaultHeartbeatStableThreshold = 5
+
+// heartbeatScheduler 根据最近的连接稳定性自适应调整心跳间隔
+// 每个心跳周期比较中断计数：周期内出现重连、连接失败、断开或错误时间隔减半，尽快发现链路故障；
+// 连续 stableThreshold 个周期无中断时间隔增加一半，减少稳定链路上的心跳开销。间隔始终在上下限之间
+type heartbeatScheduler struct {
+	minInterval     time.Duration
+	maxInterval     time.Duration
+	stableThreshold int
+
+	mu          sync.Mutex
+	interval    time.Duration
+	stableTicks int    // 连续无中断的心跳周期数
+	lastCount   uint64 // 上个周期结束时的中断计数
+	disruptions uint64 // 检测到中断的周期数
+}
+
+// newHeartbeatScheduler 创建心跳间隔调度器
+// 初始间隔为 HeartbeatInterval；上下限未配置或不包含初始间隔时以初始间隔为界，上下限都未配置时间隔固定
+func newHeartbeatScheduler(config ConnectionConfig) *heartbeatScheduler {
+	interval := config.HeartbeatInterval
+	minInterval, maxInterval := config.HeartbeatMinInterval, config.HeartbeatMaxInterval
+	if minInterval <= 0 || minInterval > interval {
+		minInterval = interval
+	}
+	if maxInterval <= 0 || maxInterval < interval {
+		maxInterval = interval
+	}
+
+	stableThreshold := config.HeartbeatStableThreshold
+	if stableThreshold <= 0 {
+		stableThreshold = DefaultHeartbeatStableThreshold
+	}
+
+	return &heartbeatScheduler{
+		minInterval:     minInterval,
+		maxInterval:     maxInterval,
+		stableThreshold: stableThreshold,
+		interval:        interval,
+	}
+}
+
+// Interval 返回当前心跳间隔
+func (s *heartbeatScheduler) Interval() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.interval
+}
+
+// connected 连接建立时调用，上次连接以来出现中断时缩短间隔，返回第一个心跳周期的间隔
+func (s *heartbeatScheduler) connected(disruptionCount uint64) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.disrupted(disruptionCount) {
+		s.shorten()
+	}
+	return s.interval
+}
+
+// tick 心跳周期结束时调用，按本周期是否出现中断调整间隔，返回下一个周期的间隔
+func (s *heartbeatScheduler) tick(disruptionCount uint64) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.disrupted(disruptionCount) {
+		s.shorten()
+		return s.interval
+	}
+
+	s.stableTicks++
+	if s.stableTicks >= s.stableThreshold {
+		s.stableTicks = 0
+		s.interval += s.interval / 2
+		if s.interval > s.maxInterval {
+			s.interval = s.maxInterval
+		}
+	}
+	return s.interval
+}
+
+// disrupted 报告中断计数自上次调用以来是否增加，并记录当前计数
+// 计数减少表示指标已重置，只更新基准
+func (s *heartbeatScheduler) disrupted(count uint64) bool {
+	increased := count > s.lastCount
+	s.lastCount = count
+	return increased
+}
+
+// shorten 心跳间隔减半并重新累计稳定周期
+func (s *heartbeatScheduler) shorten() {
+	s.disruptions++
+	s.stableTicks = 0
+	s.interval /= 2
+	if s.interval < s.minInterval {
+		s.interval = s.minInterval
+	}
+}
+
+// metrics 返回心跳间隔指标，间隔单位为毫秒
+func (s *heartbeatScheduler) metrics() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return map[string]interface{}{
+		"heartbeat_interval":     s.interval.Milliseconds(),
+		"heartbeat_min_interval": s.minInterval.Milliseconds(),
+		"heartbeat_max_interval": s.maxInterval.Milliseconds(),
+		"heartbeat_disruptions":  s.disruptions,
+	}
+}
@@ -0,0 +1,143 @@
+package comm
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestHeartbeatScheduler 创建初始间隔30秒、上下限为5秒到2分钟的心跳调度器
+func newTestHeartbeatScheduler() *heartbeatScheduler {
+	config := DefaultConfig()
+	config.HeartbeatInterval = 30 * time.Second
+	config.HeartbeatMinInterval = 5 * time.Second
+	config.HeartbeatMaxInterval = 2 * time.Minute
+	config.HeartbeatStableThreshold = 3
+	return newHeartbeatScheduler(config)
+}
+
+// TestHeartbeatSchedulerStableLink 测试稳定链路上心跳间隔逐步延长且不超过上限
+func TestHeartbeatSchedulerStableLink(t *testing.T) {
+	s := newTestHeartbeatScheduler()
+	if interval := s.connected(0); interval != 30*time.Second {
+		t.Fatalf("初始心跳间隔应为30s，实际为 %v", interval)
+	}
+
+	// 稳定周期达到阈值前间隔不变
+	for i := 0; i < 2; i++ {
+		if interval := s.tick(0); interval != 30*time.Second {
+			t.Fatalf("第%d个稳定周期后间隔应保持30s，实际为 %v", i+1, interval)
+		}
+	}
+	if interval := s.tick(0); interval != 45*time.Second {
+		t.Fatalf("连续3个稳定周期后间隔应延长到45s，实际为 %v", interval)
+	}
+
+	previous := s.Interval()
+	for i := 0; i < 30; i++ {
+		interval := s.tick(0)
+		if interval < previous {
+			t.Fatalf("稳定链路上间隔不应缩短: %v -> %v", previous, interval)
+		}
+		if interval > 2*time.Minute {
+			t.Fatalf("间隔不应超过上限2m，实际为 %v", interval)
+		}
+		previous = interval
+	}
+	if previous != 2*time.Minute {
+		t.Errorf("长时间稳定后间隔应达到上限2m，实际为 %v", previous)
+	}
+}
+
+// TestHeartbeatSchedulerFlakyLink 测试链路中断后心跳间隔缩短且不低于下限，恢复稳定后再次延长
+func TestHeartbeatSchedulerFlakyLink(t *testing.T) {
+	s := newTestHeartbeatScheduler()
+	s.connected(0)
+
+	// 每个周期都出现中断
+	count := uint64(0)
+	expected := []time.Duration{15 * time.Second, 7500 * time.Millisecond, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		count++
+		if interval := s.tick(count); interval != want {
+			t.Fatalf("第%d次中断后间隔应为 %v，实际为 %v", i+1, want, interval)
+		}
+	}
+
+	// 重连后建立连接时立即按中断缩短，不会等到下一个周期
+	s = newTestHeartbeatScheduler()
+	s.connected(0)
+	if interval := s.connected(2); interval != 15*time.Second {
+		t.Fatalf("重连后的第一个周期间隔应为15s，实际为 %v", interval)
+	}
+
+	// 中断会清除已累计的稳定周期
+	s.tick(2)
+	s.tick(2)
+	if interval := s.tick(3); interval != 7500*time.Millisecond {
+		t.Fatalf("稳定周期未达到阈值时出现中断，间隔应缩短到7.5s，实际为 %v", interval)
+	}
+	for i := 0; i < 2; i++ {
+		if interval := s.tick(3); interval != 7500*time.Millisecond {
+			t.Fatalf("中断后需要重新累计稳定周期，实际间隔为 %v", interval)
+		}
+	}
+	if interval := s.tick(3); interval != 11250*time.Millisecond {
+		t.Errorf("恢复稳定后间隔应再次延长到11.25s，实际为 %v", interval)
+	}
+
+	metrics := s.metrics()
+	if metrics["heartbeat_interval"] != int64(11250) {
+		t.Errorf("指标中的心跳间隔应为11250毫秒，实际为 %v", metrics["heartbeat_interval"])
+	}
+	if metrics["heartbeat_disruptions"] != uint64(2) {
+		t.Errorf("应记录2个中断周期，实际为 %v", metrics["heartbeat_disruptions"])
+	}
+}
+
+// TestHeartbeatSchedulerBounds 测试上下限未配置或不包含初始间隔时以初始间隔为界
+func TestHeartbeatSchedulerBounds(t *testing.T) {
+	config := DefaultConfig()
+	config.HeartbeatInterval = 100 * time.Millisecond
+	config.HeartbeatMinInterval = 0
+	config.HeartbeatMaxInterval = 0
+	s := newHeartbeatScheduler(config)
+	for i := uint64(1); i <= 10; i++ {
+		if interval := s.tick(i); interval != 100*time.Millisecond {
+			t.Fatalf("未配置上下限时间隔应固定为100ms，实际为 %v", interval)
+		}
+	}
+
+	// 默认上下限不包含较短的初始间隔时，下限取初始间隔
+	config = DefaultConfig()
+	config.HeartbeatInterval = 100 * time.Millisecond
+	s = newHeartbeatScheduler(config)
+	if interval := s.tick(1); interval != 100*time.Millisecond {
+		t.Errorf("间隔不应低于初始间隔100ms，实际为 %v", interval)
+	}
+
+	// 指标重置后计数减少，只更新基准
+	s = newTestHeartbeatScheduler()
+	s.disrupted(5)
+	if interval := s.tick(0); interval != 30*time.Second {
+		t.Errorf("指标重置不应视为中断，实际间隔为 %v", interval)
+	}
+}
+
+// TestClientHeartbeatIntervalMetrics 测试客户端指标中包含当前心跳间隔
+func TestClientHeartbeatIntervalMetrics(t *testing.T) {
+	config := DefaultConfig()
+	config.HeartbeatInterval = 20 * time.Second
+	client := NewClient(config, nil)
+
+	metrics := client.GetMetrics()
+	if metrics["heartbeat_interval"] != int64(20000) {
+		t.Errorf("心跳间隔指标应为20000毫秒，实际为 %v", metrics["heartbeat_interval"])
+	}
+
+	// 断开连接计入中断，下次连接时缩短心跳间隔
+	client.metrics.RecordDisconnect()
+	client.heartbeat.connected(client.metrics.disruptionCount())
+	if metrics := client.GetMetrics(); metrics["heartbeat_interval"] != int64(10000) {
+		t.Errorf("断开后心跳间隔指标应为10000毫秒，实际为 %v", metrics["heartbeat_interval"])
+	}
+}
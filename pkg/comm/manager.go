@@ -2,8 +2,8 @@ package comm
 
 import (
 	"errors"
+	"reflect"
 	"sync"
-	"time"
 
 	"github.com/lomehong/kennel/pkg/logging"
 )
@@ -13,8 +13,13 @@ type Manager struct {
 	client       *Client
 	config       ConnectionConfig
 	logger       logging.Logger
-	handlers     map[MessageType][]MessageHandler
+	handlers     map[MessageType][]registeredHandler
+	commands     map[string]CommandHandler
 	handlerMutex sync.RWMutex
+	handlerPool  *handlerPool
+	schemas      map[MessageType]*MessageSchema
+	rejected     map[MessageType]uint64
+	schemaMutex  sync.RWMutex
 }
 
 // NewManager 创建一个新的通讯管理器
@@ -38,8 +43,13 @@ func NewManager(config ConnectionConfig, log logging.Logger) *Manager {
 	manager := &Manager{
 		config:   config,
 		logger:   log,
-		handlers: make(map[MessageType][]MessageHandler),
+		handlers: make(map[MessageType][]registeredHandler),
+		commands: make(map[string]CommandHandler),
+		schemas:  make(map[MessageType]*MessageSchema),
+		rejected: make(map[MessageType]uint64),
 	}
+	manager.handlerPool = newHandlerPool(config.HandlerWorkers, config.HandlerQueueSize, log)
+	manager.registerBuiltinCommands()
 
 	// 创建客户端
 	manager.client = NewClient(config, log)
@@ -58,28 +68,15 @@ func (m *Manager) Connect() error {
 	return m.client.Connect()
 }
 
-// Disconnect 断开连接
+// Disconnect 断开连接，客户端会先发出待发送的消息并等待确认
 func (m *Manager) Disconnect() {
 	m.logger.Info("正在断开与服务器的连接...")
 
-	// 等待所有消息处理完成
-	m.waitForPendingMessages()
-
-	// 断开连接
 	m.client.Disconnect()
 
 	m.logger.Info("已断开与服务器的连接")
 }
 
-// waitForPendingMessages 等待所有待处理的消息完成
-func (m *Manager) waitForPendingMessages() {
-	// 这里可以添加等待消息处理完成的逻辑
-	// 例如，使用WaitGroup或者通道来等待所有消息处理完成
-
-	// 简单起见，这里只等待一小段时间
-	time.Sleep(200 * time.Millisecond)
-}
-
 // IsConnected 检查是否已连接
 func (m *Manager) IsConnected() bool {
 	return m.client.IsConnected()
@@ -90,6 +87,16 @@ func (m *Manager) GetState() ConnectionState {
 	return m.client.GetState()
 }
 
+// GetActiveEndpoint 获取当前连接的服务器端点
+func (m *Manager) GetActiveEndpoint() string {
+	return m.client.GetActiveEndpoint()
+}
+
+// GetSubprotocol 获取与服务器协商的WebSocket子协议
+func (m *Manager) GetSubprotocol() string {
+	return m.client.GetSubprotocol()
+}
+
 // GetConfig 获取通讯配置
 func (m *Manager) GetConfig() ConnectionConfig {
 	return m.config
@@ -100,15 +107,36 @@ func (m *Manager) SetClientInfo(info map[string]interface{}) {
 	m.client.SetClientInfo(info)
 }
 
+// SetTokenProvider 设置令牌提供者，连接时使用提供者返回的令牌认证，并在令牌过期前刷新
+func (m *Manager) SetTokenProvider(provider TokenProvider) {
+	m.client.SetTokenProvider(provider)
+}
+
 // RegisterHandler 注册消息处理函数
+// 处理函数在有界的协程池中执行，panic 被恢复并计入处理器指标
 func (m *Manager) RegisterHandler(msgType MessageType, handler MessageHandler) {
+	m.registerHandler(msgType, registeredHandler{
+		handle: func(msg *Message) error {
+			handler(msg)
+			return nil
+		},
+		ptr: reflect.ValueOf(handler).Pointer(),
+	})
+}
+
+// RegisterHandlerFunc 注册返回错误的消息处理函数，返回的错误计入处理器指标
+func (m *Manager) RegisterHandlerFunc(msgType MessageType, handler MessageHandlerFunc) {
+	m.registerHandler(msgType, registeredHandler{
+		handle: handler,
+		ptr:    reflect.ValueOf(handler).Pointer(),
+	})
+}
+
+// registerHandler 添加处理函数
+func (m *Manager) registerHandler(msgType MessageType, handler registeredHandler) {
 	m.handlerMutex.Lock()
 	defer m.handlerMutex.Unlock()
 
-	if _, ok := m.handlers[msgType]; !ok {
-		m.handlers[msgType] = make([]MessageHandler, 0)
-	}
-
 	m.handlers[msgType] = append(m.handlers[msgType], handler)
 }
 
@@ -117,11 +145,12 @@ func (m *Manager) UnregisterHandler(msgType MessageType, handler MessageHandler)
 	m.handlerMutex.Lock()
 	defer m.handlerMutex.Unlock()
 
+	ptr := reflect.ValueOf(handler).Pointer()
 	if handlers, ok := m.handlers[msgType]; ok {
 		for i, h := range handlers {
-			if &h == &handler {
+			if h.ptr == ptr {
 				// 找到处理函数，从切片中删除
-				m.handlers[msgType] = append(handlers[:i], handlers[i+1:]...)
+				m.handlers[msgType] = append(handlers[:i:i], handlers[i+1:]...)
 				return nil
 			}
 		}
@@ -130,12 +159,73 @@ func (m *Manager) UnregisterHandler(msgType MessageType, handler MessageHandler)
 	return errors.New("处理函数未注册")
 }
 
-// SendMessage 发送消息
+// RegisterSchema 注册消息类型的载荷结构，不符合结构的消息在分发前被拒绝
+func (m *Manager) RegisterSchema(msgType MessageType, schema *MessageSchema) {
+	m.schemaMutex.Lock()
+	defer m.schemaMutex.Unlock()
+
+	if schema == nil {
+		delete(m.schemas, msgType)
+		return
+	}
+	m.schemas[msgType] = schema
+}
+
+// UnregisterSchema 注销消息类型的载荷结构
+func (m *Manager) UnregisterSchema(msgType MessageType) {
+	m.RegisterSchema(msgType, nil)
+}
+
+// GetRejectedCount 获取因结构校验失败被拒绝的消息数量
+func (m *Manager) GetRejectedCount(msgType MessageType) uint64 {
+	m.schemaMutex.RLock()
+	defer m.schemaMutex.RUnlock()
+
+	return m.rejected[msgType]
+}
+
+// RegisterUpcaster 注册从 from 版本升级到 from+1 版本的消息升级函数
+// 旧版本的消息在结构校验和分发前升级到当前协议版本
+func (m *Manager) RegisterUpcaster(from int, upcaster Upcaster) {
+	m.client.RegisterUpcaster(from, upcaster)
+}
+
+// GetProtocolVersion 返回与服务器协商出的协议版本
+func (m *Manager) GetProtocolVersion() int {
+	return m.client.GetProtocolVersion()
+}
+
+// validateMessage 按注册的结构校验消息，未注册结构的消息类型直接通过
+func (m *Manager) validateMessage(msg *Message) error {
+	m.schemaMutex.RLock()
+	schema, ok := m.schemas[msg.Type]
+	m.schemaMutex.RUnlock()
+
+	if !ok {
+		return nil
+	}
+	if err := schema.ValidatePayload(msg.Payload); err != nil {
+		m.schemaMutex.Lock()
+		m.rejected[msg.Type]++
+		m.schemaMutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// SendMessage 按消息类型的默认优先级发送消息
 func (m *Manager) SendMessage(msgType MessageType, payload map[string]interface{}) {
 	msg := NewMessage(msgType, payload)
 	m.client.Send(msg)
 }
 
+// SendMessageWithPriority 按指定优先级发送消息
+// 链路拥塞或离线缓存时，高优先级消息先发送，同一优先级内保持先进先出
+func (m *Manager) SendMessageWithPriority(msgType MessageType, payload map[string]interface{}, priority MessagePriority) {
+	msg := NewMessage(msgType, payload)
+	m.client.SendWithPriority(msg, priority)
+}
+
 // SendCommand 发送命令消息
 func (m *Manager) SendCommand(command string, params map[string]interface{}) {
 	payload := map[string]interface{}{
@@ -163,6 +253,15 @@ func (m *Manager) SendEvent(eventType string, details map[string]interface{}) {
 	m.SendMessage(MessageTypeEvent, payload)
 }
 
+// SendEventWithPriority 按指定优先级发送事件消息，安全告警等关键事件应使用 PriorityHigh
+func (m *Manager) SendEventWithPriority(eventType string, details map[string]interface{}, priority MessagePriority) {
+	payload := map[string]interface{}{
+		"event":   eventType,
+		"details": details,
+	}
+	m.SendMessageWithPriority(MessageTypeEvent, payload, priority)
+}
+
 // SendResponse 发送响应消息
 func (m *Manager) SendResponse(requestID string, success bool, data interface{}, errorMsg string) {
 	payload := map[string]interface{}{
@@ -180,17 +279,40 @@ func (m *Manager) SendResponse(requestID string, success bool, data interface{},
 
 // dispatchMessage 分发消息到对应的处理函数
 func (m *Manager) dispatchMessage(msg *Message) {
+	if msg == nil {
+		return
+	}
+
+	// 校验消息结构，处理函数可以假定载荷格式正确
+	if err := m.validateMessage(msg); err != nil {
+		m.logger.Warn("拒绝格式错误的消息", "id", msg.ID, "type", msg.Type, "error", err)
+		return
+	}
+
 	m.handlerMutex.RLock()
-	defer m.handlerMutex.RUnlock()
+	handlers := m.handlers[msg.Type]
+	m.handlerMutex.RUnlock()
+
+	// 命令消息先交给命令处理函数，执行结果作为关联响应发回服务端
+	if msg.Type == MessageTypeCommand {
+		if task, ok := m.commandTask(msg, len(handlers)); ok {
+			handlers = append([]registeredHandler{task}, handlers...)
+		}
+	}
 
-	// 查找对应类型的处理函数
-	if handlers, ok := m.handlers[msg.Type]; ok {
-		for _, handler := range handlers {
-			go handler(msg)
+	// 提交到处理函数协程池，慢处理函数和 panic 不阻塞消息读取
+	for _, handler := range handlers {
+		if !m.handlerPool.submit(handlerTask{handler: handler, msg: msg}) {
+			m.logger.Warn("消息处理队列已满，丢弃消息处理", "id", msg.ID, "type", msg.Type)
 		}
 	}
 }
 
+// GetHandlerStats 获取消息处理函数的执行统计
+func (m *Manager) GetHandlerStats() HandlerStats {
+	return m.handlerPool.snapshot()
+}
+
 // handleStateChange 处理连接状态变化
 func (m *Manager) handleStateChange(oldState, newState ConnectionState) {
 	m.logger.Info("连接状态变化", "old", oldState, "new", newState)
@@ -218,12 +340,41 @@ func (m *Manager) GetMetrics() map[string]interface{} {
 	for msgType, handlers := range m.handlers {
 		handlerCount[string(msgType)] = len(handlers)
 	}
+	commandCount := len(m.commands)
 	m.handlerMutex.RUnlock()
 	metrics["handler_count"] = handlerCount
+	metrics["command_handler_count"] = commandCount
+
+	handlerStats := m.handlerPool.snapshot()
+	metrics["handler_executed"] = handlerStats.Executed
+	metrics["handler_errors"] = handlerStats.Errors
+	metrics["handler_panics"] = handlerStats.Panics
+	metrics["handler_dropped"] = handlerStats.Dropped
+	metrics["handler_active"] = handlerStats.Active
+	metrics["handler_queued"] = handlerStats.Queued
+
+	m.schemaMutex.RLock()
+	rejected := make(map[string]uint64, len(m.rejected))
+	for msgType, count := range m.rejected {
+		rejected[string(msgType)] = count
+	}
+	m.schemaMutex.RUnlock()
+	metrics["rejected_messages"] = rejected
+	metrics["protocol_version"] = m.client.GetProtocolVersion()
 
 	return metrics
 }
 
+// GetLatencyHistograms 获取各消息类型从发送到收到确认的延迟直方图
+func (m *Manager) GetLatencyHistograms() map[MessageType]LatencyHistogram {
+	return m.client.GetLatencyHistograms()
+}
+
+// GetSlowRequests 获取最近最慢的请求，用于排查往返缓慢的问题
+func (m *Manager) GetSlowRequests() []SlowRequest {
+	return m.client.GetSlowRequests()
+}
+
 // GetMetricsReport 获取指标报告
 func (m *Manager) GetMetricsReport() string {
 	if m.client == nil {
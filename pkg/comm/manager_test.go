@@ -1,7 +1,14 @@
 package comm
 
 import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
 )
 
 // TestManagerConnect 测试管理器连接功能
@@ -18,3 +25,126 @@ func TestManagerSendReceive(t *testing.T) {
 func TestManagerHandlerRegistration(t *testing.T) {
 	t.Skip("跳过测试，需要重构")
 }
+
+// refusedURL 返回一个拒绝连接的WebSocket地址
+func refusedURL(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("创建监听器失败: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return "ws://" + addr + "/ws"
+}
+
+// newEndpointTestServer 创建记录接收消息类型的WebSocket测试服务器
+func newEndpointTestServer(t *testing.T) (*httptest.Server, chan MessageType) {
+	received := make(chan MessageType, 10)
+
+	server := newWSTestServer(t, func(conn *websocket.Conn, r *http.Request) {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if msg, err := decodeMessage(data); err == nil {
+				select {
+				case received <- msg.Type:
+				default:
+				}
+			}
+		}
+	})
+	return server, received
+}
+
+// TestManagerEndpointFailover 测试第一个服务器拒绝连接时故障转移到第二个服务器
+func TestManagerEndpointFailover(t *testing.T) {
+	server, receivedMessages := newEndpointTestServer(t)
+	defer server.Close()
+
+	downURL := refusedURL(t)
+	upURL := wsURL(server)
+
+	config := DefaultConfig()
+	config.ServerURLs = []string{downURL, upURL}
+	config.EndpointFailureThreshold = 1
+	config.EndpointCooldown = time.Minute
+	config.HeartbeatInterval = time.Second
+	config.HandshakeTimeout = time.Second
+
+	manager := NewManager(config, nil)
+
+	if err := manager.Connect(); err != nil {
+		t.Fatalf("故障转移连接失败: %v", err)
+	}
+	defer manager.Disconnect()
+
+	select {
+	case msgType := <-receivedMessages:
+		if msgType != MessageTypeConnect {
+			t.Errorf("期望收到连接消息，但收到了 %s", msgType)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("超时等待连接消息")
+	}
+
+	if active := manager.GetActiveEndpoint(); active != upURL {
+		t.Errorf("活动端点不匹配: 期望 %s, 实际 %s", upURL, active)
+	}
+
+	metrics := manager.GetMetrics()
+	if metrics["active_endpoint"] != upURL {
+		t.Errorf("指标中的活动端点不匹配: %v", metrics["active_endpoint"])
+	}
+
+	statuses, ok := metrics["endpoints"].([]EndpointStatus)
+	if !ok || len(statuses) != 2 {
+		t.Fatalf("端点状态不匹配: %v", metrics["endpoints"])
+	}
+	if statuses[0].Healthy || statuses[0].ConsecutiveFailures != 1 {
+		t.Errorf("拒绝连接的端点应被标记为不健康: %+v", statuses[0])
+	}
+	if !statuses[1].Healthy || !statuses[1].Active {
+		t.Errorf("可用端点应为健康的活动端点: %+v", statuses[1])
+	}
+}
+
+// TestEndpointSelectorStickyAndRotation 测试端点粘性偏好与连续失败轮换
+func TestEndpointSelectorStickyAndRotation(t *testing.T) {
+	config := DefaultConfig()
+	config.ServerURLs = []string{"ws://a/ws", "ws://b/ws", "ws://c/ws"}
+	config.EndpointFailureThreshold = 2
+	config.EndpointCooldown = time.Minute
+	selector := newEndpointSelector(config)
+
+	// 最近一次成功的端点优先
+	selector.recordSuccess("ws://b/ws")
+	if candidates := selector.candidates(); candidates[0] != "ws://b/ws" {
+		t.Errorf("应优先使用最近成功的端点: %v", candidates)
+	}
+
+	// 未达到失败阈值时保持偏好
+	if selector.recordFailure("ws://b/ws", nil) {
+		t.Errorf("单次失败不应标记为不健康")
+	}
+	if candidates := selector.candidates(); candidates[0] != "ws://b/ws" {
+		t.Errorf("未达到阈值时应保持偏好: %v", candidates)
+	}
+
+	// 连续失败达到阈值后轮换，不健康端点排在最后
+	if !selector.recordFailure("ws://b/ws", nil) {
+		t.Errorf("连续失败达到阈值应标记为不健康")
+	}
+	candidates := selector.candidates()
+	expected := []string{"ws://a/ws", "ws://c/ws", "ws://b/ws"}
+	if strings.Join(candidates, ",") != strings.Join(expected, ",") {
+		t.Errorf("端点顺序不匹配: 期望 %v, 实际 %v", expected, candidates)
+	}
+
+	// 随机策略返回所有端点
+	config.EndpointStrategy = EndpointStrategyRandom
+	if candidates := newEndpointSelector(config).candidates(); len(candidates) != 3 {
+		t.Errorf("随机策略应返回全部端点: %v", candidates)
+	}
+}
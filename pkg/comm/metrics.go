@@ -215,6 +215,15 @@ func (mc *MetricsCollector) RecordHeartbeatError() {
 	atomic.AddUint64(&mc.heartbeatErrorCount, 1)
 }
 
+// disruptionCount 返回与连接中断相关的事件总数，心跳调度器按其变化判断连接是否稳定
+func (mc *MetricsCollector) disruptionCount() uint64 {
+	return atomic.LoadUint64(&mc.reconnectCount) +
+		atomic.LoadUint64(&mc.connectFailCount) +
+		atomic.LoadUint64(&mc.disconnectCount) +
+		atomic.LoadUint64(&mc.heartbeatErrorCount) +
+		atomic.LoadUint64(&mc.errorCount)
+}
+
 // RecordError 记录错误事件
 func (mc *MetricsCollector) RecordError(message string) {
 	atomic.AddUint64(&mc.errorCount, 1)
@@ -0,0 +1,197 @@
+package comm
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsFileFormat 指标文件格式
+type MetricsFileFormat string
+
+const (
+	MetricsFormatCSV  MetricsFileFormat = "csv"  // 每行一个快照，嵌套指标展开为点号分隔的列
+	MetricsFormatJSON MetricsFileFormat = "json" // 每行一个JSON对象
+)
+
+// MetricsFormatFromPath 根据文件扩展名推断格式，.csv 之外的文件使用JSON行格式
+func MetricsFormatFromPath(path string) MetricsFileFormat {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return MetricsFormatCSV
+	}
+	return MetricsFormatJSON
+}
+
+// MetricsFileWriter 将指标快照追加写入文件
+type MetricsFileWriter struct {
+	file    *os.File
+	format  MetricsFileFormat
+	columns []string // CSV列，第一列为时间戳
+	csv     *csv.Writer
+	mu      sync.Mutex
+}
+
+// NewMetricsFileWriter 打开指标文件，文件已存在时追加写入
+// 追加到已有CSV文件时沿用其表头，保证各行的列一致
+func NewMetricsFileWriter(path string, format MetricsFileFormat) (*MetricsFileWriter, error) {
+	if format != MetricsFormatCSV && format != MetricsFormatJSON {
+		return nil, fmt.Errorf("不支持的指标文件格式: %s", format)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开指标文件失败: %w", err)
+	}
+
+	w := &MetricsFileWriter{file: file, format: format}
+	if format == MetricsFormatCSV {
+		header, err := readCSVHeader(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		w.columns = header
+		w.csv = csv.NewWriter(file)
+	}
+	return w, nil
+}
+
+// Write 写入一个指标快照
+func (w *MetricsFileWriter) Write(timestamp time.Time, metrics map[string]interface{}) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.format == MetricsFormatJSON {
+		record := make(map[string]interface{}, len(metrics)+1)
+		for key, value := range metrics {
+			record[key] = value
+		}
+		record["timestamp"] = timestamp.Format(time.RFC3339)
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("序列化指标失败: %w", err)
+		}
+		if _, err := w.file.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("写入指标文件失败: %w", err)
+		}
+		return nil
+	}
+
+	flat := make(map[string]string)
+	flattenMetrics("", metrics, flat)
+
+	if w.columns == nil {
+		w.columns = append([]string{"timestamp"}, sortedKeys(flat)...)
+		if err := w.csv.Write(w.columns); err != nil {
+			return fmt.Errorf("写入指标文件失败: %w", err)
+		}
+	}
+
+	row := make([]string, len(w.columns))
+	row[0] = timestamp.Format(time.RFC3339)
+	for i, column := range w.columns[1:] {
+		row[i+1] = flat[column]
+	}
+	if err := w.csv.Write(row); err != nil {
+		return fmt.Errorf("写入指标文件失败: %w", err)
+	}
+	w.csv.Flush()
+	if err := w.csv.Error(); err != nil {
+		return fmt.Errorf("写入指标文件失败: %w", err)
+	}
+	return nil
+}
+
+// Close 关闭指标文件
+func (w *MetricsFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.csv != nil {
+		w.csv.Flush()
+	}
+	return w.file.Close()
+}
+
+// readCSVHeader 读取已有CSV文件的表头，空文件返回nil
+func readCSVHeader(file *os.File) ([]string, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("读取指标文件失败: %w", err)
+	}
+	header, err := csv.NewReader(bufio.NewReader(file)).Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取指标文件表头失败: %w", err)
+	}
+	if len(header) == 0 || header[0] != "timestamp" {
+		return nil, fmt.Errorf("指标文件表头无效，第一列应为 timestamp")
+	}
+	return header, nil
+}
+
+// flattenMetrics 将嵌套指标展开为点号分隔的键
+func flattenMetrics(prefix string, value interface{}, out map[string]string) {
+	var nested map[string]interface{}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		nested = v
+	case map[string]uint64:
+		nested = make(map[string]interface{}, len(v))
+		for key, item := range v {
+			nested[key] = item
+		}
+	case map[string]int:
+		nested = make(map[string]interface{}, len(v))
+		for key, item := range v {
+			nested[key] = item
+		}
+	case nil:
+		out[prefix] = ""
+		return
+	default:
+		if data, err := json.Marshal(v); err == nil && !isJSONScalar(v) {
+			out[prefix] = string(data)
+		} else {
+			out[prefix] = fmt.Sprint(v)
+		}
+		return
+	}
+
+	for key, item := range nested {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		flattenMetrics(key, item, out)
+	}
+}
+
+// isJSONScalar 判断值是否为可直接格式化的标量
+func isJSONScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, float32, float64, int, int32, int64, uint, uint32, uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// sortedKeys 返回排序后的键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
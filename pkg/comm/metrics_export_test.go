@@ -0,0 +1,117 @@
+package comm
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMetricsFileWriterCSV 测试以CSV格式追加写入指标
+func TestMetricsFileWriterCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.csv")
+	if MetricsFormatFromPath(path) != MetricsFormatCSV {
+		t.Fatalf("应根据扩展名推断为CSV格式")
+	}
+
+	snapshot := map[string]interface{}{
+		"reconnect_count":   uint64(1),
+		"current_state":     "已连接",
+		"rejected_messages": map[string]uint64{"command": 2},
+		"endpoints":         []string{"ws://a", "ws://b"},
+	}
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	writer, err := NewMetricsFileWriter(path, MetricsFormatCSV)
+	if err != nil {
+		t.Fatalf("创建指标文件失败: %v", err)
+	}
+	if err := writer.Write(start, snapshot); err != nil {
+		t.Fatalf("写入指标失败: %v", err)
+	}
+	writer.Close()
+
+	// 重新打开后追加，沿用已有表头，新增的指标不改变列
+	writer, err = NewMetricsFileWriter(path, MetricsFormatCSV)
+	if err != nil {
+		t.Fatalf("重新打开指标文件失败: %v", err)
+	}
+	snapshot["reconnect_count"] = uint64(4)
+	snapshot["new_metric"] = 1
+	if err := writer.Write(start.Add(time.Minute), snapshot); err != nil {
+		t.Fatalf("追加指标失败: %v", err)
+	}
+	writer.Close()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("打开指标文件失败: %v", err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("读取CSV失败: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("应包含表头和两行数据，实际为 %d 行", len(records))
+	}
+	header := strings.Join(records[0], ",")
+	if header != "timestamp,current_state,endpoints,reconnect_count,rejected_messages.command" {
+		t.Errorf("表头不正确: %s", header)
+	}
+	if records[1][0] != "2026-01-02T03:04:05Z" || records[1][3] != "1" || records[1][4] != "2" {
+		t.Errorf("第一行数据不正确: %v", records[1])
+	}
+	if records[1][2] != `["ws://a","ws://b"]` {
+		t.Errorf("复合指标应以JSON写入: %s", records[1][2])
+	}
+	if records[2][3] != "4" || len(records[2]) != len(records[0]) {
+		t.Errorf("第二行数据不正确: %v", records[2])
+	}
+}
+
+// TestMetricsFileWriterJSON 测试以JSON行格式写入指标
+func TestMetricsFileWriterJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metrics.jsonl")
+	writer, err := NewMetricsFileWriter(path, MetricsFormatFromPath(path))
+	if err != nil {
+		t.Fatalf("创建指标文件失败: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := writer.Write(time.Now(), map[string]interface{}{"reconnect_count": uint64(i)}); err != nil {
+			t.Fatalf("写入指标失败: %v", err)
+		}
+	}
+	writer.Close()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("打开指标文件失败: %v", err)
+	}
+	defer file.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("第 %d 行不是有效的JSON: %v", lines+1, err)
+		}
+		if record["reconnect_count"] != float64(lines) || record["timestamp"] == nil {
+			t.Errorf("第 %d 行内容不正确: %v", lines+1, record)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Errorf("应写入 2 行，实际为 %d", lines)
+	}
+
+	if _, err := NewMetricsFileWriter(path, "xml"); err == nil {
+		t.Error("不支持的格式应返回错误")
+	}
+}
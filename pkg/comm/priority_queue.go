@@ -0,0 +1,166 @@
+package comm
+
+import (
+	"sync"
+)
+
+// MessagePriority 定义消息发送优先级
+type MessagePriority int
+
+const (
+	PriorityLow    MessagePriority = iota // 低优先级，如例行数据上报
+	PriorityNormal                        // 普通优先级
+	PriorityHigh                          // 高优先级，如安全告警和系统消息
+)
+
+// priorityLevels 优先级数量
+const priorityLevels = 3
+
+// String 返回优先级的字符串表示
+func (p MessagePriority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// valid 检查优先级是否有效
+func (p MessagePriority) valid() bool {
+	return p >= PriorityLow && p <= PriorityHigh
+}
+
+// defaultPriority 返回消息类型的默认优先级，系统消息优先发送以保持连接
+func defaultPriority(msgType MessageType) MessagePriority {
+	switch msgType {
+	case MessageTypeHeartbeat, MessageTypeConnect, MessageTypeAck, MessageTypeAuth:
+		return PriorityHigh
+	default:
+		return PriorityNormal
+	}
+}
+
+// priorityQueue 按优先级排序的发送队列
+// 高优先级消息先出队，同一优先级内保持先进先出；
+// 队列已满时，新消息挤掉优先级更低的最新消息，否则新消息被丢弃
+type priorityQueue struct {
+	mu       sync.Mutex
+	queues   [priorityLevels][]*Message
+	size     int
+	capacity int
+	dropped  [priorityLevels]uint64
+	ready    chan struct{}
+}
+
+// newPriorityQueue 创建发送队列，capacity 为全部优先级共享的容量
+func newPriorityQueue(capacity int) *priorityQueue {
+	if capacity <= 0 {
+		capacity = DefaultConfig().MessageBufferSize
+	}
+	return &priorityQueue{
+		capacity: capacity,
+		ready:    make(chan struct{}, 1),
+	}
+}
+
+// push 将消息加入队列，返回消息是否入队
+func (q *priorityQueue) push(msg *Message, priority MessagePriority) bool {
+	if !priority.valid() {
+		priority = PriorityNormal
+	}
+
+	q.mu.Lock()
+	if q.size >= q.capacity && !q.evictLowerLocked(priority) {
+		q.dropped[priority]++
+		q.mu.Unlock()
+		return false
+	}
+	q.queues[priority] = append(q.queues[priority], msg)
+	q.size++
+	q.mu.Unlock()
+
+	// 通知发送协程有新消息
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// evictLowerLocked 丢弃一条优先级低于 priority 的最新消息，调用方需持有锁
+func (q *priorityQueue) evictLowerLocked(priority MessagePriority) bool {
+	for p := PriorityLow; p < priority; p++ {
+		if n := len(q.queues[p]); n > 0 {
+			q.queues[p][n-1] = nil
+			q.queues[p] = q.queues[p][:n-1]
+			q.size--
+			q.dropped[p]++
+			return true
+		}
+	}
+	return false
+}
+
+// pop 取出优先级最高的最早消息及其优先级，队列为空时返回 false
+func (q *priorityQueue) pop() (*Message, MessagePriority, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for p := PriorityHigh; p >= PriorityLow; p-- {
+		if len(q.queues[p]) > 0 {
+			msg := q.queues[p][0]
+			q.queues[p][0] = nil
+			q.queues[p] = q.queues[p][1:]
+			q.size--
+			return msg, p, true
+		}
+	}
+	return nil, PriorityNormal, false
+}
+
+// clear 清空队列
+func (q *priorityQueue) clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for p := range q.queues {
+		q.queues[p] = nil
+	}
+	q.size = 0
+}
+
+// len 返回队列中的消息总数
+func (q *priorityQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.size
+}
+
+// depths 返回各优先级的队列长度
+func (q *priorityQueue) depths() map[string]int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	depths := make(map[string]int, priorityLevels)
+	for p := PriorityLow; p <= PriorityHigh; p++ {
+		depths[p.String()] = len(q.queues[p])
+	}
+	return depths
+}
+
+// droppedCounts 返回各优先级因队列已满被丢弃的消息数
+func (q *priorityQueue) droppedCounts() map[string]uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dropped := make(map[string]uint64, priorityLevels)
+	for p := PriorityLow; p <= PriorityHigh; p++ {
+		dropped[p.String()] = q.dropped[p]
+	}
+	return dropped
+}
@@ -0,0 +1,159 @@
+package comm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// seqMessage 创建带序号的数据消息
+func seqMessage(seq string) *Message {
+	return NewMessage(MessageTypeData, map[string]interface{}{"seq": seq})
+}
+
+// drainSeqs 按出队顺序返回队列中全部消息的序号
+func drainSeqs(q *priorityQueue) []string {
+	var seqs []string
+	for {
+		msg, _, ok := q.pop()
+		if !ok {
+			return seqs
+		}
+		seqs = append(seqs, msg.Payload["seq"].(string))
+	}
+}
+
+// TestPriorityQueueOrder 测试高优先级先出队且同一优先级内先进先出
+func TestPriorityQueueOrder(t *testing.T) {
+	q := newPriorityQueue(10)
+	q.push(seqMessage("low-1"), PriorityLow)
+	q.push(seqMessage("normal-1"), PriorityNormal)
+	q.push(seqMessage("high-1"), PriorityHigh)
+	q.push(seqMessage("low-2"), PriorityLow)
+	q.push(seqMessage("high-2"), PriorityHigh)
+	q.push(seqMessage("normal-2"), PriorityNormal)
+
+	depths := q.depths()
+	if depths["high"] != 2 || depths["normal"] != 2 || depths["low"] != 2 {
+		t.Errorf("各优先级队列长度不正确: %v", depths)
+	}
+
+	want := []string{"high-1", "high-2", "normal-1", "normal-2", "low-1", "low-2"}
+	if got := drainSeqs(q); !reflect.DeepEqual(got, want) {
+		t.Errorf("出队顺序不正确: 期望 %v, 实际 %v", want, got)
+	}
+}
+
+// TestPriorityQueueFull 测试队列已满时高优先级消息挤掉低优先级消息
+func TestPriorityQueueFull(t *testing.T) {
+	q := newPriorityQueue(3)
+	q.push(seqMessage("low-1"), PriorityLow)
+	q.push(seqMessage("low-2"), PriorityLow)
+	q.push(seqMessage("normal-1"), PriorityNormal)
+
+	if !q.push(seqMessage("high-1"), PriorityHigh) {
+		t.Error("高优先级消息应挤掉低优先级消息入队")
+	}
+	if q.push(seqMessage("low-3"), PriorityLow) {
+		t.Error("队列已满时低优先级消息应被丢弃")
+	}
+	if !q.push(seqMessage("normal-2"), PriorityNormal) {
+		t.Error("普通优先级消息应挤掉低优先级消息入队")
+	}
+	if q.push(seqMessage("normal-3"), PriorityNormal) {
+		t.Error("没有更低优先级的消息时，普通优先级消息应被丢弃")
+	}
+
+	dropped := q.droppedCounts()
+	if dropped["low"] != 3 || dropped["normal"] != 1 || dropped["high"] != 0 {
+		t.Errorf("丢弃计数不正确: %v", dropped)
+	}
+
+	want := []string{"high-1", "normal-1", "normal-2"}
+	if got := drainSeqs(q); !reflect.DeepEqual(got, want) {
+		t.Errorf("出队顺序不正确: 期望 %v, 实际 %v", want, got)
+	}
+}
+
+// newSeqTestServer 创建按接收顺序记录数据消息序号的WebSocket测试服务器
+func newSeqTestServer(t *testing.T) (*httptest.Server, chan string) {
+	received := make(chan string, 20)
+
+	server := newWSTestServer(t, func(conn *websocket.Conn, r *http.Request) {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			msg, err := decodeMessage(data)
+			if err != nil || msg.Type != MessageTypeData {
+				continue
+			}
+			if seq, ok := msg.Payload["seq"].(string); ok {
+				received <- seq
+			}
+		}
+	})
+	return server, received
+}
+
+// TestManagerPriorityFlush 测试离线缓存的消息在连接后按优先级发送
+func TestManagerPriorityFlush(t *testing.T) {
+	server, received := newSeqTestServer(t)
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.ServerURL = wsURL(server)
+	config.HeartbeatInterval = time.Minute
+	config.HandshakeTimeout = time.Second
+
+	manager := NewManager(config, nil)
+
+	// 未连接时发送方处于暂停状态，消息在队列中缓存
+	sends := []struct {
+		seq      string
+		priority MessagePriority
+	}{
+		{"low-1", PriorityLow},
+		{"normal-1", PriorityNormal},
+		{"low-2", PriorityLow},
+		{"high-1", PriorityHigh},
+		{"normal-2", PriorityNormal},
+		{"high-2", PriorityHigh},
+	}
+	for _, send := range sends {
+		manager.SendMessageWithPriority(MessageTypeData, map[string]interface{}{"seq": send.seq}, send.priority)
+	}
+
+	depths, ok := manager.GetMetrics()["send_queue_depth"].(map[string]int)
+	if !ok {
+		t.Fatalf("指标中缺少发送队列长度: %v", manager.GetMetrics()["send_queue_depth"])
+	}
+	if !reflect.DeepEqual(depths, map[string]int{"high": 2, "normal": 2, "low": 2}) {
+		t.Errorf("发送队列长度不正确: %v", depths)
+	}
+
+	if err := manager.Connect(); err != nil {
+		t.Fatalf("连接失败: %v", err)
+	}
+	defer manager.Disconnect()
+
+	var got []string
+	for len(got) < len(sends) {
+		select {
+		case seq := <-received:
+			got = append(got, seq)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("超时等待消息，已收到: %v", got)
+		}
+	}
+
+	want := []string{"high-1", "high-2", "normal-1", "normal-2", "low-1", "low-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("消息发送顺序不正确: 期望 %v, 实际 %v", want, got)
+	}
+}
@@ -8,9 +8,10 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-// readPump 从WebSocket连接读取消息
-func (c *Client) readPump() {
+// readPump 从WebSocket连接读取消息，退出时关闭 done
+func (c *Client) readPump(stop <-chan struct{}, receive chan<- *Message, done chan struct{}) {
 	defer func() {
+		close(done)
 		c.reconnect()
 	}()
 
@@ -24,7 +25,7 @@ func (c *Client) readPump() {
 	for {
 		// 检查是否需要停止
 		select {
-		case <-c.stopChan:
+		case <-stop:
 			return
 		default:
 			// 继续读取
@@ -33,7 +34,7 @@ func (c *Client) readPump() {
 		// 读取消息
 		_, data, err := c.conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				c.handleError(err)
 			}
 			return
@@ -70,6 +71,12 @@ func (c *Client) readPump() {
 			continue
 		}
 
+		// 协商协议版本，旧版本消息升级到当前版本
+		if err := c.receiveVersioned(msg); err != nil {
+			c.handleError(err)
+			continue
+		}
+
 		// 处理系统消息
 		if c.handleSystemMessage(msg) {
 			continue
@@ -77,7 +84,7 @@ func (c *Client) readPump() {
 
 		// 将消息放入接收队列
 		select {
-		case c.receiveChan <- msg:
+		case receive <- msg:
 			// 消息已加入接收队列
 		default:
 			c.logger.Warn("接收队列已满，消息被丢弃")
@@ -86,82 +93,117 @@ func (c *Client) readPump() {
 }
 
 // writePump 向WebSocket连接写入消息
-func (c *Client) writePump() {
+func (c *Client) writePump(stop <-chan struct{}) {
 	defer func() {
 		c.reconnect()
 	}()
 
 	for {
 		select {
-		case <-c.stopChan:
+		case <-stop:
 			return
-		case msg := <-c.sendChan:
-			// 编码消息
-			data, err := encodeMessage(msg)
-			if err != nil {
-				c.handleError(err)
-				continue
+		default:
+		}
+
+		// 按优先级取出消息，队列为空时等待新消息
+		msg, priority, ok := c.sendQueue.pop()
+		if !ok {
+			select {
+			case <-stop:
+				return
+			case <-c.sendQueue.ready:
 			}
+			continue
+		}
 
-			// 记录发送字节数
-			c.metrics.RecordSentMessage(len(data))
+		// 分配序号并记为未确认，重连后重发的消息已被确认时跳过
+		send, err := c.delivery.prepare(msg, priority)
+		if err != nil {
+			c.logger.Warn("持久化消息序号失败", "error", err)
+		}
+		if !send {
+			c.logger.Debug("消息已被确认，跳过重发", "id", msg.ID, "seq", msg.Seq)
+			continue
+		}
 
-			// 如果启用了压缩，压缩消息
-			if c.config.Security.EnableCompression {
-				beforeSize := len(data)
-				data, err = c.compressData(data)
-				if err != nil {
-					c.handleError(fmt.Errorf("压缩消息失败: %w", err))
-					continue
-				}
-				// 记录压缩指标
-				c.metrics.RecordCompression(beforeSize, len(data))
-			}
+		// 按协商出的协议版本编码消息
+		c.stampVersion(msg)
+		data, err := encodeMessage(msg)
+		if err != nil {
+			c.handleError(err)
+			continue
+		}
 
-			// 如果启用了加密，加密消息
-			if c.config.Security.EnableEncryption {
-				beforeSize := len(data)
-				data, err = c.encryptMessage(data)
-				if err != nil {
-					c.handleError(fmt.Errorf("加密消息失败: %w", err))
-					continue
-				}
-				// 记录加密指标
-				c.metrics.RecordEncryption(beforeSize, len(data))
-			}
+		// 记录发送字节数
+		c.metrics.RecordSentMessage(len(data))
 
-			// 设置写入超时
-			c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
+		// 如果启用了压缩，压缩消息
+		if c.config.Security.EnableCompression {
+			beforeSize := len(data)
+			data, err = c.compressData(data)
+			if err != nil {
+				c.handleError(fmt.Errorf("压缩消息失败: %w", err))
+				continue
+			}
+			// 记录压缩指标
+			c.metrics.RecordCompression(beforeSize, len(data))
+		}
 
-			// 写入消息
-			err = c.conn.WriteMessage(websocket.TextMessage, data)
+		// 如果启用了加密，加密消息
+		if c.config.Security.EnableEncryption {
+			beforeSize := len(data)
+			data, err = c.encryptMessage(data)
 			if err != nil {
-				c.handleError(err)
-				c.metrics.RecordMessageError()
-				return
+				c.handleError(fmt.Errorf("加密消息失败: %w", err))
+				continue
 			}
+			// 记录加密指标
+			c.metrics.RecordEncryption(beforeSize, len(data))
+		}
+
+		// 设置写入超时
+		c.conn.SetWriteDeadline(time.Now().Add(c.config.WriteTimeout))
 
-			c.logger.Debug("消息已发送", "type", msg.Type, "id", msg.ID)
+		// 写入消息
+		err = c.conn.WriteMessage(websocket.TextMessage, data)
+		if err != nil {
+			c.handleError(err)
+			c.metrics.RecordMessageError()
+			return
 		}
+
+		c.tracer.start(msg)
+		c.markSent(msg)
+		c.logger.Debug("消息已发送", "type", msg.Type, "id", msg.ID, "seq", msg.Seq)
 	}
 }
 
 // processPump 处理接收到的消息
-func (c *Client) processPump() {
+func (c *Client) processPump(stop <-chan struct{}, receive <-chan *Message) {
 	for {
 		select {
-		case <-c.stopChan:
+		case <-stop:
 			return
-		case msg := <-c.receiveChan:
-			// 调用消息处理函数
+		case msg := <-receive:
+			// 调用消息处理函数，管理器在其中把消息提交到有界的处理协程池
 			if c.messageHandler != nil {
-				go c.messageHandler(msg)
+				c.invokeMessageHandler(msg)
 			}
 			c.logger.Debug("消息已处理", "type", msg.Type, "id", msg.ID)
 		}
 	}
 }
 
+// invokeMessageHandler 调用消息处理函数，处理函数 panic 时记录错误而不退出处理协程
+func (c *Client) invokeMessageHandler(msg *Message) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.logger.Error("消息处理函数发生panic", "id", msg.ID, "type", msg.Type, "panic", r)
+		}
+	}()
+	c.messageHandler(msg)
+}
+
 // handleSystemMessage 处理系统消息
 func (c *Client) handleSystemMessage(msg *Message) bool {
 	switch msg.Type {
@@ -171,7 +213,16 @@ func (c *Client) handleSystemMessage(msg *Message) bool {
 		c.Send(createAckMessage(msg.ID))
 		return true
 	case MessageTypeAck:
-		// 收到确认消息，不需要特殊处理
+		// 收到确认消息，记录对应请求的往返延迟，并将消息移出未确认列表
+		messageID, _ := msg.Payload["message_id"].(string)
+		if messageID != "" {
+			if latency, tracked := c.tracer.complete(messageID); tracked {
+				c.metrics.RecordLatency(latency.Milliseconds())
+			}
+		}
+		if _, err := c.delivery.ack(messageID, ackedSeq(msg.Payload)); err != nil {
+			c.logger.Warn("持久化已确认序号失败", "error", err)
+		}
 		return true
 	default:
 		return false
@@ -179,27 +230,34 @@ func (c *Client) handleSystemMessage(msg *Message) bool {
 }
 
 // startHeartbeat 启动心跳
-func (c *Client) startHeartbeat() {
+func (c *Client) startHeartbeat(stop <-chan struct{}) {
 	// 停止现有的心跳定时器
 	if c.heartbeatTimer != nil {
 		c.heartbeatTimer.Stop()
 	}
 
-	// 创建新的心跳定时器
-	c.heartbeatTimer = time.NewTimer(c.config.HeartbeatInterval)
+	// 创建新的心跳定时器，上次连接以来出现中断时缩短心跳间隔
+	interval := c.heartbeat.connected(c.metrics.disruptionCount())
+	timer := time.NewTimer(interval)
+	c.heartbeatTimer = timer
 
 	// 启动心跳协程
 	go func() {
 		for {
 			select {
-			case <-c.stopChan:
+			case <-stop:
 				return
-			case <-c.heartbeatTimer.C:
+			case <-timer.C:
 				// 发送心跳消息
 				c.Send(createHeartbeatMessage())
 				c.metrics.RecordHeartbeatSent()
-				// 重置定时器
-				c.heartbeatTimer.Reset(c.config.HeartbeatInterval)
+				// 按本周期的连接稳定性调整下一次心跳间隔
+				next := c.heartbeat.tick(c.metrics.disruptionCount())
+				if next != interval {
+					c.logger.Debug("调整心跳间隔", "old", interval, "new", next)
+					interval = next
+				}
+				timer.Reset(interval)
 			}
 		}
 	}()
@@ -209,8 +267,8 @@ func (c *Client) startHeartbeat() {
 func (c *Client) reconnect() {
 	c.stateMutex.Lock()
 
-	// 如果已经是断开连接状态，不需要重连
-	if c.state == StateDisconnected {
+	// 已断开或正在主动断开，不需要重连
+	if c.state == StateDisconnected || c.state == StateDisconnecting {
 		c.stateMutex.Unlock()
 		return
 	}
@@ -238,6 +296,11 @@ func (c *Client) reconnect() {
 	// 等待重连间隔
 	time.Sleep(c.config.ReconnectInterval)
 
+	// 等待期间主动断开了连接，不再重连
+	if c.GetState() != StateReconnecting {
+		return
+	}
+
 	// 尝试重新连接
 	c.logger.Info("尝试重新连接", "attempt", c.reconnectCount)
 	err := c.Connect()
@@ -0,0 +1,203 @@
+package comm
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// FieldType 定义消息字段类型
+type FieldType string
+
+const (
+	FieldTypeAny     FieldType = "any"     // 任意类型
+	FieldTypeString  FieldType = "string"  // 字符串
+	FieldTypeNumber  FieldType = "number"  // 数字
+	FieldTypeInteger FieldType = "integer" // 整数
+	FieldTypeBool    FieldType = "bool"    // 布尔值
+	FieldTypeObject  FieldType = "object"  // 对象
+	FieldTypeArray   FieldType = "array"   // 数组
+)
+
+// FieldSchema 定义消息字段的结构
+type FieldSchema struct {
+	Type     FieldType               // 字段类型
+	Required bool                    // 是否必需
+	Enum     []string                // 字符串字段允许的取值，为空表示不限制
+	Fields   map[string]*FieldSchema // 对象字段的子字段
+	Items    *FieldSchema            // 数组字段的元素结构
+	Strict   bool                    // 对象字段是否拒绝未定义的子字段
+}
+
+// MessageSchema 定义消息载荷的结构
+type MessageSchema struct {
+	Fields   map[string]*FieldSchema                    // 载荷字段
+	Strict   bool                                       // 是否拒绝未定义的字段
+	Validate func(payload map[string]interface{}) error // 自定义校验，在字段校验通过后执行
+}
+
+// SchemaError 消息结构校验错误
+type SchemaError struct {
+	Path    string // 出错字段路径
+	Message string // 错误描述
+}
+
+// Error 实现error接口
+func (e *SchemaError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidatePayload 校验消息载荷
+func (s *MessageSchema) ValidatePayload(payload map[string]interface{}) error {
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+
+	if err := validateFields(payload, s.Fields, s.Strict, "payload"); err != nil {
+		return err
+	}
+
+	if s.Validate != nil {
+		if err := s.Validate(payload); err != nil {
+			return &SchemaError{Path: "payload", Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// validateFields 校验对象的字段
+func validateFields(object map[string]interface{}, fields map[string]*FieldSchema, strict bool, path string) error {
+	// 按名称排序，保证错误信息稳定
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := fields[name]
+		value, exists := object[name]
+		if !exists || value == nil {
+			if field.Required {
+				return &SchemaError{Path: path + "." + name, Message: "缺少必需字段"}
+			}
+			continue
+		}
+		if err := field.validate(value, path+"."+name); err != nil {
+			return err
+		}
+	}
+
+	if strict {
+		for name := range object {
+			if _, defined := fields[name]; !defined {
+				return &SchemaError{Path: path + "." + name, Message: "未定义的字段"}
+			}
+		}
+	}
+	return nil
+}
+
+// validate 校验字段值
+func (f *FieldSchema) validate(value interface{}, path string) error {
+	switch f.Type {
+	case FieldTypeAny, "":
+		return nil
+
+	case FieldTypeString:
+		str, ok := value.(string)
+		if !ok {
+			return typeMismatch(path, f.Type, value)
+		}
+		if len(f.Enum) > 0 {
+			for _, allowed := range f.Enum {
+				if str == allowed {
+					return nil
+				}
+			}
+			return &SchemaError{Path: path, Message: fmt.Sprintf("取值必须为 %s 之一", strings.Join(f.Enum, ", "))}
+		}
+		return nil
+
+	case FieldTypeNumber:
+		if _, ok := toFloat64(value); !ok {
+			return typeMismatch(path, f.Type, value)
+		}
+		return nil
+
+	case FieldTypeInteger:
+		number, ok := toFloat64(value)
+		if !ok || number != math.Trunc(number) {
+			return typeMismatch(path, f.Type, value)
+		}
+		return nil
+
+	case FieldTypeBool:
+		if _, ok := value.(bool); !ok {
+			return typeMismatch(path, f.Type, value)
+		}
+		return nil
+
+	case FieldTypeObject:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return typeMismatch(path, f.Type, value)
+		}
+		return validateFields(object, f.Fields, f.Strict, path)
+
+	case FieldTypeArray:
+		items, ok := value.([]interface{})
+		if !ok {
+			return typeMismatch(path, f.Type, value)
+		}
+		if f.Items == nil {
+			return nil
+		}
+		for i, item := range items {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if item == nil {
+				return &SchemaError{Path: itemPath, Message: "数组元素不能为空"}
+			}
+			if err := f.Items.validate(item, itemPath); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return &SchemaError{Path: path, Message: fmt.Sprintf("未知的字段类型 %s", f.Type)}
+	}
+}
+
+// typeMismatch 创建类型不匹配错误
+func typeMismatch(path string, expected FieldType, value interface{}) error {
+	return &SchemaError{Path: path, Message: fmt.Sprintf("类型应为 %s，实际为 %T", expected, value)}
+}
+
+// toFloat64 将数字类型转换为float64，JSON解码后的数字为float64，本地构造的消息可能为整数类型
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
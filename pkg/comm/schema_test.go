@@ -0,0 +1,138 @@
+package comm
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// commandSchema 测试用命令消息结构
+func commandSchema() *MessageSchema {
+	return &MessageSchema{
+		Fields: map[string]*FieldSchema{
+			"command": {Type: FieldTypeString, Required: true, Enum: []string{"scan", "restart"}},
+			"params": {
+				Type: FieldTypeObject,
+				Fields: map[string]*FieldSchema{
+					"timeout": {Type: FieldTypeInteger, Required: true},
+					"paths":   {Type: FieldTypeArray, Items: &FieldSchema{Type: FieldTypeString}},
+				},
+			},
+		},
+	}
+}
+
+// decodeTestMessage 按服务端发送的JSON解码消息
+func decodeTestMessage(t *testing.T, raw string) *Message {
+	var msg Message
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		t.Fatalf("解码消息失败: %v", err)
+	}
+	return &msg
+}
+
+// TestManagerRejectsMalformedMessages 测试格式错误的消息在分发前被拒绝
+func TestManagerRejectsMalformedMessages(t *testing.T) {
+	manager := NewManager(DefaultConfig(), nil)
+	manager.RegisterSchema(MessageTypeCommand, commandSchema())
+
+	received := make(chan string, 10)
+	manager.RegisterHandler(MessageTypeCommand, func(msg *Message) {
+		// 处理函数直接断言类型，格式错误的消息到达这里会panic
+		params := msg.Payload["params"].(map[string]interface{})
+		received <- msg.ID + ":" + msg.Payload["command"].(string) + ":" + params["paths"].([]interface{})[0].(string)
+	})
+
+	malformed := []string{
+		`{"id": "m1", "type": "command", "payload": {"params": {"timeout": 5}}}`,
+		`{"id": "m2", "type": "command", "payload": {"command": 42, "params": {"timeout": 5}}}`,
+		`{"id": "m3", "type": "command", "payload": {"command": "format", "params": {"timeout": 5}}}`,
+		`{"id": "m4", "type": "command", "payload": {"command": "scan", "params": "fast"}}`,
+		`{"id": "m5", "type": "command", "payload": {"command": "scan", "params": {"timeout": 1.5}}}`,
+		`{"id": "m6", "type": "command", "payload": {"command": "scan", "params": {"timeout": 5, "paths": ["/tmp", 7]}}}`,
+		`{"id": "m7", "type": "command"}`,
+	}
+	for _, raw := range malformed {
+		manager.dispatchMessage(decodeTestMessage(t, raw))
+	}
+
+	manager.dispatchMessage(decodeTestMessage(t,
+		`{"id": "ok", "type": "command", "payload": {"command": "scan", "params": {"timeout": 5, "paths": ["/tmp"]}, "extra": true}}`))
+
+	select {
+	case got := <-received:
+		if got != "ok:scan:/tmp" {
+			t.Errorf("处理函数收到了错误的消息: %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("合法消息未到达处理函数")
+	}
+
+	select {
+	case got := <-received:
+		t.Errorf("格式错误的消息不应到达处理函数: %s", got)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if count := manager.GetRejectedCount(MessageTypeCommand); count != uint64(len(malformed)) {
+		t.Errorf("拒绝数量应为 %d，实际为 %d", len(malformed), count)
+	}
+
+	rejected, ok := manager.GetMetrics()["rejected_messages"].(map[string]uint64)
+	if !ok || rejected[string(MessageTypeCommand)] != uint64(len(malformed)) {
+		t.Errorf("指标中的拒绝数量不正确: %v", manager.GetMetrics()["rejected_messages"])
+	}
+}
+
+// TestMessageSchemaStrictAndCustom 测试严格模式和自定义校验
+func TestMessageSchemaStrictAndCustom(t *testing.T) {
+	schema := &MessageSchema{
+		Fields: map[string]*FieldSchema{
+			"request_id": {Type: FieldTypeString, Required: true},
+			"success":    {Type: FieldTypeBool, Required: true},
+			"error":      {Type: FieldTypeString},
+			"data":       {Type: FieldTypeAny},
+		},
+		Strict: true,
+		Validate: func(payload map[string]interface{}) error {
+			if payload["success"] == false && payload["error"] == nil {
+				return errors.New("失败响应必须包含error")
+			}
+			return nil
+		},
+	}
+
+	valid := map[string]interface{}{"request_id": "r1", "success": true, "data": []interface{}{1, "a"}}
+	if err := schema.ValidatePayload(valid); err != nil {
+		t.Errorf("合法载荷校验失败: %v", err)
+	}
+
+	tests := map[string]map[string]interface{}{
+		"未定义字段": {"request_id": "r1", "success": true, "unknown": 1},
+		"自定义校验": {"request_id": "r1", "success": false},
+		"类型错误":  {"request_id": "r1", "success": "yes"},
+	}
+	for name, payload := range tests {
+		err := schema.ValidatePayload(payload)
+		var schemaErr *SchemaError
+		if !errors.As(err, &schemaErr) {
+			t.Errorf("%s: 应返回SchemaError，实际为 %v", name, err)
+		}
+	}
+
+	// 未注册结构的消息类型不做校验
+	manager := NewManager(DefaultConfig(), nil)
+	if err := manager.validateMessage(&Message{Type: MessageTypeEvent}); err != nil {
+		t.Errorf("未注册结构的消息不应被拒绝: %v", err)
+	}
+
+	manager.RegisterSchema(MessageTypeResponse, schema)
+	if err := manager.validateMessage(&Message{Type: MessageTypeResponse}); err == nil {
+		t.Error("缺少必需字段的消息应被拒绝")
+	}
+	manager.UnregisterSchema(MessageTypeResponse)
+	if err := manager.validateMessage(&Message{Type: MessageTypeResponse}); err != nil {
+		t.Errorf("注销结构后消息不应被拒绝: %v", err)
+	}
+}
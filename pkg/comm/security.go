@@ -140,7 +140,18 @@ func (c *Client) decryptMessage(data []byte) ([]byte, error) {
 	return decryptedData, nil
 }
 
-// SetServerURL 设置服务器URL
+// SetServerURL 设置服务器URL，替换已配置的端点列表
 func (c *Client) SetServerURL(url string) {
 	c.config.ServerURL = url
+	c.config.ServerURLs = nil
+	c.endpoints = newEndpointSelector(c.config)
+}
+
+// SetServerURLs 设置服务器URL列表，按优先级排列
+func (c *Client) SetServerURLs(urls []string) {
+	c.config.ServerURLs = append([]string(nil), urls...)
+	if len(urls) > 0 {
+		c.config.ServerURL = urls[0]
+	}
+	c.endpoints = newEndpointSelector(c.config)
 }
@@ -0,0 +1,49 @@
+package comm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsTestServerOptions WebSocket测试服务器选项
+type wsTestServerOptions struct {
+	// Subprotocols 服务器支持的子协议
+	Subprotocols []string
+	// Accept 在升级连接前检查请求，返回 false 时不升级连接，由 Accept 写入响应
+	Accept func(w http.ResponseWriter, r *http.Request) bool
+}
+
+// newWSTestServer 创建WebSocket测试服务器，每个连接升级后交给 handle 处理，handle 返回后关闭连接
+func newWSTestServer(t *testing.T, handle func(conn *websocket.Conn, r *http.Request)) *httptest.Server {
+	return newWSTestServerWithOptions(t, wsTestServerOptions{}, handle)
+}
+
+// newWSTestServerWithOptions 按选项创建WebSocket测试服务器
+func newWSTestServerWithOptions(t *testing.T, options wsTestServerOptions, handle func(conn *websocket.Conn, r *http.Request)) *httptest.Server {
+	upgrader := websocket.Upgrader{
+		Subprotocols: options.Subprotocols,
+		CheckOrigin:  func(r *http.Request) bool { return true },
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if options.Accept != nil && !options.Accept(w, r) {
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Logf("升级连接失败: %v", err)
+			return
+		}
+		defer conn.Close()
+		handle(conn, r)
+	}))
+}
+
+// wsURL 返回测试服务器的WebSocket地址
+func wsURL(server *httptest.Server) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
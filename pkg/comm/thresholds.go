@@ -0,0 +1,160 @@
+package comm
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ThresholdOperator 阈值比较运算符
+type ThresholdOperator string
+
+const (
+	ThresholdGreater      ThresholdOperator = ">"
+	ThresholdGreaterEqual ThresholdOperator = ">="
+	ThresholdLess         ThresholdOperator = "<"
+	ThresholdLessEqual    ThresholdOperator = "<="
+	ThresholdEqual        ThresholdOperator = "=="
+	ThresholdNotEqual     ThresholdOperator = "!="
+)
+
+// thresholdOperators 按解析优先级排列，双字符运算符在前
+var thresholdOperators = []ThresholdOperator{
+	ThresholdGreaterEqual, ThresholdLessEqual, ThresholdEqual, ThresholdNotEqual,
+	ThresholdGreater, ThresholdLess,
+}
+
+// MetricThreshold 指标阈值，指标值满足条件时触发告警
+// Metric 支持用点号访问嵌套指标，例如 rejected_messages.command
+type MetricThreshold struct {
+	Metric   string
+	Operator ThresholdOperator
+	Value    float64
+}
+
+// String 返回阈值表达式
+func (t MetricThreshold) String() string {
+	return t.Metric + string(t.Operator) + strconv.FormatFloat(t.Value, 'f', -1, 64)
+}
+
+// Exceeded 判断指标值是否触发阈值
+func (t MetricThreshold) Exceeded(value float64) bool {
+	switch t.Operator {
+	case ThresholdGreater:
+		return value > t.Value
+	case ThresholdGreaterEqual:
+		return value >= t.Value
+	case ThresholdLess:
+		return value < t.Value
+	case ThresholdLessEqual:
+		return value <= t.Value
+	case ThresholdEqual:
+		return value == t.Value
+	case ThresholdNotEqual:
+		return value != t.Value
+	default:
+		return false
+	}
+}
+
+// ParseMetricThreshold 解析阈值表达式，例如 reconnect_count>3
+func ParseMetricThreshold(expr string) (MetricThreshold, error) {
+	expr = strings.TrimSpace(expr)
+	for _, op := range thresholdOperators {
+		index := strings.Index(expr, string(op))
+		if index < 0 {
+			continue
+		}
+
+		metric := strings.TrimSpace(expr[:index])
+		raw := strings.TrimSpace(expr[index+len(op):])
+		if metric == "" {
+			return MetricThreshold{}, fmt.Errorf("阈值表达式 %q 缺少指标名称", expr)
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return MetricThreshold{}, fmt.Errorf("阈值表达式 %q 的阈值无效: %w", expr, err)
+		}
+		return MetricThreshold{Metric: metric, Operator: op, Value: value}, nil
+	}
+	return MetricThreshold{}, fmt.Errorf("阈值表达式 %q 缺少比较运算符", expr)
+}
+
+// ParseMetricThresholds 解析逗号分隔的多个阈值表达式
+func ParseMetricThresholds(exprs string) ([]MetricThreshold, error) {
+	var thresholds []MetricThreshold
+	for _, expr := range strings.Split(exprs, ",") {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		threshold, err := ParseMetricThreshold(expr)
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+// ThresholdViolation 触发的阈值
+type ThresholdViolation struct {
+	Threshold MetricThreshold
+	Actual    float64
+}
+
+// String 返回告警描述
+func (v ThresholdViolation) String() string {
+	return fmt.Sprintf("%s 当前值 %s 触发阈值 %s", v.Threshold.Metric,
+		strconv.FormatFloat(v.Actual, 'f', -1, 64), v.Threshold)
+}
+
+// EvaluateThresholds 使用指标快照评估阈值
+// 返回触发的阈值；指标不存在或不是数值时返回错误，避免拼写错误的指标永远不告警
+func EvaluateThresholds(metrics map[string]interface{}, thresholds []MetricThreshold) ([]ThresholdViolation, error) {
+	var violations []ThresholdViolation
+	var missing []string
+
+	for _, threshold := range thresholds {
+		value, ok := MetricValue(metrics, threshold.Metric)
+		if !ok {
+			missing = append(missing, threshold.Metric)
+			continue
+		}
+		if threshold.Exceeded(value) {
+			violations = append(violations, ThresholdViolation{Threshold: threshold, Actual: value})
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return violations, fmt.Errorf("指标不存在或不是数值: %s", strings.Join(missing, ", "))
+	}
+	return violations, nil
+}
+
+// MetricValue 按点号路径读取数值指标，布尔值按 1/0 处理
+func MetricValue(metrics map[string]interface{}, path string) (float64, bool) {
+	var current interface{} = metrics
+	for _, key := range strings.Split(path, ".") {
+		var exists bool
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current, exists = node[key]
+		case map[string]uint64:
+			current, exists = node[key]
+		case map[string]int:
+			current, exists = node[key]
+		}
+		if !exists {
+			return 0, false
+		}
+	}
+	if flag, ok := current.(bool); ok {
+		if flag {
+			return 1, true
+		}
+		return 0, true
+	}
+	return toFloat64(current)
+}
@@ -0,0 +1,97 @@
+package comm
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseMetricThreshold 测试解析阈值表达式
+func TestParseMetricThreshold(t *testing.T) {
+	tests := []struct {
+		expr     string
+		metric   string
+		operator ThresholdOperator
+		value    float64
+	}{
+		{"reconnect_count>3", "reconnect_count", ThresholdGreater, 3},
+		{" error_count >= 1 ", "error_count", ThresholdGreaterEqual, 1},
+		{"avg_latency<=250.5", "avg_latency", ThresholdLessEqual, 250.5},
+		{"rejected_messages.command!=0", "rejected_messages.command", ThresholdNotEqual, 0},
+		{"connected==0", "connected", ThresholdEqual, 0},
+	}
+	for _, tt := range tests {
+		threshold, err := ParseMetricThreshold(tt.expr)
+		if err != nil {
+			t.Errorf("解析 %q 失败: %v", tt.expr, err)
+			continue
+		}
+		if threshold.Metric != tt.metric || threshold.Operator != tt.operator || threshold.Value != tt.value {
+			t.Errorf("解析 %q 结果不正确: %+v", tt.expr, threshold)
+		}
+	}
+
+	for _, expr := range []string{"reconnect_count", ">3", "reconnect_count>many"} {
+		if _, err := ParseMetricThreshold(expr); err == nil {
+			t.Errorf("无效表达式 %q 应返回错误", expr)
+		}
+	}
+
+	thresholds, err := ParseMetricThresholds("reconnect_count>3, ,error_count>=1")
+	if err != nil || len(thresholds) != 2 {
+		t.Fatalf("解析多个阈值失败: %v, %v", thresholds, err)
+	}
+	if thresholds[0].String() != "reconnect_count>3" {
+		t.Errorf("阈值表达式不正确: %s", thresholds[0])
+	}
+}
+
+// TestEvaluateThresholds 测试阈值评估
+func TestEvaluateThresholds(t *testing.T) {
+	metrics := map[string]interface{}{
+		"reconnect_count": uint64(5),
+		"error_count":     uint64(0),
+		"avg_latency":     120.5,
+		"connected":       true,
+		"rejected_messages": map[string]uint64{
+			"command": 2,
+		},
+		"current_state": "已连接",
+	}
+
+	thresholds, err := ParseMetricThresholds("reconnect_count>3,error_count>=1,avg_latency<100,connected==1,rejected_messages.command>1")
+	if err != nil {
+		t.Fatalf("解析阈值失败: %v", err)
+	}
+
+	violations, err := EvaluateThresholds(metrics, thresholds)
+	if err != nil {
+		t.Fatalf("评估阈值失败: %v", err)
+	}
+
+	var triggered []string
+	for _, violation := range violations {
+		triggered = append(triggered, violation.Threshold.Metric)
+	}
+	expected := "reconnect_count,connected,rejected_messages.command"
+	if strings.Join(triggered, ",") != expected {
+		t.Errorf("触发的阈值应为 %s，实际为 %v", expected, triggered)
+	}
+	if violations[0].Actual != 5 || !strings.Contains(violations[0].String(), "reconnect_count>3") {
+		t.Errorf("告警描述不正确: %s", violations[0])
+	}
+
+	// 不存在或非数值的指标返回错误，同时保留其他阈值的结果
+	thresholds, _ = ParseMetricThresholds("reconect_count>3,current_state>0,rejected_messages.heartbeat>0,reconnect_count>3")
+	violations, err = EvaluateThresholds(metrics, thresholds)
+	if err == nil {
+		t.Fatal("指标不存在时应返回错误")
+	}
+	for _, name := range []string{"reconect_count", "current_state", "rejected_messages.heartbeat"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("错误应包含指标 %s: %v", name, err)
+		}
+	}
+	if len(violations) != 1 {
+		t.Errorf("有效阈值仍应被评估，实际触发 %d 个", len(violations))
+	}
+}
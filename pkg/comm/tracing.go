@@ -0,0 +1,266 @@
+package comm
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets 默认的往返延迟直方图桶上界
+var DefaultLatencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+const (
+	// defaultSlowRequestLimit 保留的最慢请求数量
+	defaultSlowRequestLimit = 20
+
+	// defaultSlowRequestWindow 慢请求记录的保留时间，超过后让位于新的请求
+	defaultSlowRequestWindow = 10 * time.Minute
+
+	// defaultPendingTimeout 等待确认的超时时间，超时的请求视为未确认
+	defaultPendingTimeout = 2 * time.Minute
+
+	// defaultMaxPending 最多跟踪的待确认请求数量
+	defaultMaxPending = 1024
+)
+
+// LatencyHistogram 某一消息类型的发送到确认延迟直方图
+type LatencyHistogram struct {
+	Bounds []time.Duration `json:"bounds"` // 桶上界，最后一个桶之外还有一个无上界的桶
+	Counts []uint64        `json:"counts"` // 各桶的请求数，不累计，长度为 len(Bounds)+1
+	Count  uint64          `json:"count"`  // 请求总数
+	Sum    time.Duration   `json:"sum"`    // 延迟总和
+}
+
+// observe 记录一次延迟
+func (h *LatencyHistogram) observe(latency time.Duration) {
+	index := sort.Search(len(h.Bounds), func(i int) bool { return latency <= h.Bounds[i] })
+	h.Counts[index]++
+	h.Count++
+	h.Sum += latency
+}
+
+// clone 返回直方图副本
+func (h *LatencyHistogram) clone() LatencyHistogram {
+	counts := make([]uint64, len(h.Counts))
+	copy(counts, h.Counts)
+	return LatencyHistogram{Bounds: h.Bounds, Counts: counts, Count: h.Count, Sum: h.Sum}
+}
+
+// Buckets 返回累计桶计数，键为 le_<上界毫秒>ms，最后一个键为 le_inf
+func (h LatencyHistogram) Buckets() map[string]uint64 {
+	buckets := make(map[string]uint64, len(h.Counts))
+	var cumulative uint64
+	for i, count := range h.Counts {
+		cumulative += count
+		if i < len(h.Bounds) {
+			buckets["le_"+strconv.FormatInt(h.Bounds[i].Milliseconds(), 10)+"ms"] = cumulative
+		} else {
+			buckets["le_inf"] = cumulative
+		}
+	}
+	return buckets
+}
+
+// SlowRequest 最慢请求的跟踪记录
+type SlowRequest struct {
+	ID      string        `json:"id"`
+	Type    MessageType   `json:"type"`
+	SentAt  time.Time     `json:"sent_at"`
+	AckedAt time.Time     `json:"acked_at"`
+	Latency time.Duration `json:"latency"`
+}
+
+// pendingRequest 已发送等待确认的请求
+type pendingRequest struct {
+	msgType MessageType
+	sentAt  time.Time
+}
+
+// requestTracer 跟踪消息从发送到收到确认的往返延迟
+type requestTracer struct {
+	mu         sync.Mutex
+	buckets    []time.Duration
+	histograms map[MessageType]*LatencyHistogram
+	pending    map[string]pendingRequest
+	slow       []SlowRequest // 按延迟从高到低排列
+	unacked    uint64
+
+	slowLimit      int
+	slowWindow     time.Duration
+	pendingTimeout time.Duration
+	maxPending     int
+
+	now func() time.Time
+}
+
+// newRequestTracer 创建请求跟踪器
+func newRequestTracer() *requestTracer {
+	return &requestTracer{
+		buckets:        DefaultLatencyBuckets,
+		histograms:     make(map[MessageType]*LatencyHistogram),
+		pending:        make(map[string]pendingRequest),
+		slowLimit:      defaultSlowRequestLimit,
+		slowWindow:     defaultSlowRequestWindow,
+		pendingTimeout: defaultPendingTimeout,
+		maxPending:     defaultMaxPending,
+		now:            time.Now,
+	}
+}
+
+// start 记录消息已发送，确认消息本身不需要确认，不做跟踪
+func (t *requestTracer) start(msg *Message) {
+	if msg == nil || msg.Type == MessageTypeAck {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if len(t.pending) >= t.maxPending {
+		t.expirePending(now)
+	}
+	if len(t.pending) >= t.maxPending {
+		// 服务端长时间不确认时放弃跟踪新请求，避免占用过多内存
+		t.unacked++
+		return
+	}
+	t.pending[msg.ID] = pendingRequest{msgType: msg.Type, sentAt: now}
+}
+
+// pendingCount 返回已发送但尚未收到确认的请求数
+func (t *requestTracer) pendingCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+// complete 记录收到确认，返回往返延迟；未跟踪的消息返回 false
+func (t *requestTracer) complete(messageID string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	request, ok := t.pending[messageID]
+	if !ok {
+		return 0, false
+	}
+	delete(t.pending, messageID)
+
+	now := t.now()
+	latency := now.Sub(request.sentAt)
+	if latency < 0 {
+		latency = 0
+	}
+
+	histogram, ok := t.histograms[request.msgType]
+	if !ok {
+		histogram = &LatencyHistogram{Bounds: t.buckets, Counts: make([]uint64, len(t.buckets)+1)}
+		t.histograms[request.msgType] = histogram
+	}
+	histogram.observe(latency)
+
+	t.recordSlow(SlowRequest{
+		ID:      messageID,
+		Type:    request.msgType,
+		SentAt:  request.sentAt,
+		AckedAt: now,
+		Latency: latency,
+	}, now)
+
+	return latency, true
+}
+
+// recordSlow 将请求加入最慢请求列表，过期记录先被移除
+func (t *requestTracer) recordSlow(request SlowRequest, now time.Time) {
+	t.expireSlow(now)
+
+	if len(t.slow) >= t.slowLimit && request.Latency <= t.slow[len(t.slow)-1].Latency {
+		return
+	}
+
+	index := sort.Search(len(t.slow), func(i int) bool { return t.slow[i].Latency < request.Latency })
+	t.slow = append(t.slow, SlowRequest{})
+	copy(t.slow[index+1:], t.slow[index:])
+	t.slow[index] = request
+
+	if len(t.slow) > t.slowLimit {
+		t.slow = t.slow[:t.slowLimit]
+	}
+}
+
+// expireSlow 移除超出保留时间的慢请求记录
+func (t *requestTracer) expireSlow(now time.Time) {
+	kept := t.slow[:0]
+	for _, request := range t.slow {
+		if now.Sub(request.AckedAt) <= t.slowWindow {
+			kept = append(kept, request)
+		}
+	}
+	t.slow = kept
+}
+
+// expirePending 移除等待确认超时的请求
+func (t *requestTracer) expirePending(now time.Time) {
+	for id, request := range t.pending {
+		if now.Sub(request.sentAt) > t.pendingTimeout {
+			delete(t.pending, id)
+			t.unacked++
+		}
+	}
+}
+
+// slowRequests 返回最近保留时间内最慢的请求，按延迟从高到低排列
+func (t *requestTracer) slowRequests() []SlowRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expireSlow(t.now())
+	requests := make([]SlowRequest, len(t.slow))
+	copy(requests, t.slow)
+	return requests
+}
+
+// latencyHistograms 返回各消息类型的延迟直方图副本
+func (t *requestTracer) latencyHistograms() map[MessageType]LatencyHistogram {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	histograms := make(map[MessageType]LatencyHistogram, len(t.histograms))
+	for msgType, histogram := range t.histograms {
+		histograms[msgType] = histogram.clone()
+	}
+	return histograms
+}
+
+// metrics 返回可导出的跟踪指标，嵌套结构可用点号路径访问，例如 latency_histograms.event.buckets.le_100ms
+func (t *requestTracer) metrics() map[string]interface{} {
+	histograms := make(map[string]interface{})
+	for msgType, histogram := range t.latencyHistograms() {
+		histograms[string(msgType)] = map[string]interface{}{
+			"count":   histogram.Count,
+			"sum_ms":  histogram.Sum.Milliseconds(),
+			"buckets": histogram.Buckets(),
+		}
+	}
+
+	t.mu.Lock()
+	pending := len(t.pending)
+	unacked := t.unacked
+	t.mu.Unlock()
+
+	return map[string]interface{}{
+		"latency_histograms": histograms,
+		"pending_requests":   pending,
+		"unacked_requests":   unacked,
+	}
+}
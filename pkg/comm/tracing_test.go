@@ -0,0 +1,149 @@
+package comm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// newTracingTestManager 创建使用可控时钟的通讯管理器
+func newTracingTestManager(t *testing.T) (*Manager, *time.Time) {
+	manager := NewManager(DefaultConfig(), nil)
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	manager.client.tracer.now = func() time.Time { return now }
+	return manager, &now
+}
+
+// traceRequest 模拟发送一条消息并在指定延迟后收到确认
+func traceRequest(m *Manager, now *time.Time, msgType MessageType, latency time.Duration) *Message {
+	msg := NewMessage(msgType, map[string]interface{}{})
+	m.client.tracer.start(msg)
+	*now = now.Add(latency)
+	m.client.handleSystemMessage(createAckMessage(msg.ID))
+	return msg
+}
+
+func TestLatencyHistograms(t *testing.T) {
+	manager, now := newTracingTestManager(t)
+
+	for _, latency := range []time.Duration{5, 8, 40, 120, 700, 3000, 9000} {
+		traceRequest(manager, now, MessageTypeEvent, latency*time.Millisecond)
+	}
+	traceRequest(manager, now, MessageTypeData, 20*time.Millisecond)
+
+	// 未跟踪消息的确认被忽略
+	manager.client.handleSystemMessage(createAckMessage("unknown"))
+
+	histograms := manager.GetLatencyHistograms()
+	event, ok := histograms[MessageTypeEvent]
+	if !ok {
+		t.Fatalf("缺少事件消息的延迟直方图")
+	}
+	if event.Count != 7 {
+		t.Errorf("事件请求数应为 7，实际为 %d", event.Count)
+	}
+	if event.Sum != 12873*time.Millisecond {
+		t.Errorf("延迟总和应为 12873ms，实际为 %v", event.Sum)
+	}
+	expected := []uint64{2, 1, 0, 1, 0, 1, 0, 1, 1}
+	for i, count := range expected {
+		if event.Counts[i] != count {
+			t.Errorf("第 %d 个桶的计数应为 %d，实际为 %d", i, count, event.Counts[i])
+		}
+	}
+	if data := histograms[MessageTypeData]; data.Count != 1 || data.Counts[1] != 1 {
+		t.Errorf("数据消息应落在 50ms 桶，实际为 %v", data.Counts)
+	}
+
+	// 指标中的桶计数为累计值，可用点号路径访问
+	metrics := manager.GetMetrics()
+	for path, want := range map[string]float64{
+		"latency_histograms.event.buckets.le_10ms":   2,
+		"latency_histograms.event.buckets.le_250ms":  4,
+		"latency_histograms.event.buckets.le_5000ms": 6,
+		"latency_histograms.event.buckets.le_inf":    7,
+		"latency_histograms.event.count":             7,
+		"latency_histograms.data.buckets.le_50ms":    1,
+		"pending_requests":                           0,
+		"latency_count":                              8,
+		"max_latency":                                9000,
+	} {
+		value, ok := MetricValue(metrics, path)
+		if !ok {
+			t.Errorf("指标 %s 不存在", path)
+			continue
+		}
+		if value != want {
+			t.Errorf("指标 %s 应为 %v，实际为 %v", path, want, value)
+		}
+	}
+}
+
+func TestSlowRequests(t *testing.T) {
+	manager, now := newTracingTestManager(t)
+	manager.client.tracer.slowLimit = 3
+
+	var ids []string
+	for _, latency := range []time.Duration{300, 50, 900, 10, 600, 200} {
+		msg := traceRequest(manager, now, MessageTypeCommand, latency*time.Millisecond)
+		ids = append(ids, msg.ID)
+	}
+
+	slow := manager.GetSlowRequests()
+	if len(slow) != 3 {
+		t.Fatalf("应保留 3 个最慢请求，实际为 %d", len(slow))
+	}
+	for i, want := range []struct {
+		id      string
+		latency time.Duration
+	}{{ids[2], 900 * time.Millisecond}, {ids[4], 600 * time.Millisecond}, {ids[0], 300 * time.Millisecond}} {
+		if slow[i].ID != want.id || slow[i].Latency != want.latency {
+			t.Errorf("第 %d 个慢请求应为 %s (%v)，实际为 %s (%v)", i, want.id, want.latency, slow[i].ID, slow[i].Latency)
+		}
+		if slow[i].Type != MessageTypeCommand {
+			t.Errorf("慢请求类型应为 command，实际为 %s", slow[i].Type)
+		}
+		if slow[i].AckedAt.Sub(slow[i].SentAt) != slow[i].Latency {
+			t.Errorf("慢请求的发送和确认时间与延迟不一致")
+		}
+	}
+
+	// 超出保留时间的记录让位于新的请求
+	*now = now.Add(defaultSlowRequestWindow)
+	fresh := traceRequest(manager, now, MessageTypeEvent, 100*time.Millisecond)
+	slow = manager.GetSlowRequests()
+	if len(slow) != 1 || slow[0].ID != fresh.ID {
+		t.Errorf("过期的慢请求应被移除，实际为 %v", slow)
+	}
+}
+
+func TestRequestTracerPendingLimit(t *testing.T) {
+	tracer := newRequestTracer()
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tracer.now = func() time.Time { return now }
+	tracer.maxPending = 2
+
+	// 确认消息本身不被跟踪
+	tracer.start(createAckMessage("x"))
+	for i := 0; i < 3; i++ {
+		tracer.start(&Message{ID: fmt.Sprintf("m%d", i), Type: MessageTypeData})
+	}
+	metrics := tracer.metrics()
+	if metrics["pending_requests"] != 2 || metrics["unacked_requests"] != uint64(1) {
+		t.Errorf("超出跟踪上限的请求应计为未确认，实际为 %v", metrics)
+	}
+
+	// 等待确认超时的请求被清理，为新请求让出空间
+	now = now.Add(defaultPendingTimeout + time.Second)
+	tracer.start(&Message{ID: "m3", Type: MessageTypeData})
+	metrics = tracer.metrics()
+	if metrics["pending_requests"] != 1 || metrics["unacked_requests"] != uint64(3) {
+		t.Errorf("超时的请求应被清理，实际为 %v", metrics)
+	}
+	if _, ok := tracer.complete("m0"); ok {
+		t.Errorf("超时清理后的请求不应再记录延迟")
+	}
+	if latency, ok := tracer.complete("m3"); !ok || latency != 0 {
+		t.Errorf("应记录请求 m3 的延迟，实际为 %v %v", latency, ok)
+	}
+}
@@ -12,6 +12,7 @@ const (
 	MessageTypeHeartbeat MessageType = "heartbeat" // 心跳消息
 	MessageTypeConnect   MessageType = "connect"   // 连接消息
 	MessageTypeAck       MessageType = "ack"       // 确认消息
+	MessageTypeAuth      MessageType = "auth"      // 认证消息，在已建立的连接上更新认证令牌
 
 	// 业务消息类型
 	MessageTypeCommand  MessageType = "command"  // 命令消息
@@ -22,10 +23,12 @@ const (
 
 // Message 定义通用消息结构
 type Message struct {
-	ID        string                 `json:"id"`        // 消息ID
-	Type      MessageType            `json:"type"`      // 消息类型
-	Timestamp int64                  `json:"timestamp"` // 时间戳
-	Payload   map[string]interface{} `json:"payload"`   // 消息内容
+	ID        string                 `json:"id"`                // 消息ID
+	Version   int                    `json:"version,omitempty"` // 协议版本，发送时按协商出的版本设置，为空表示版本1
+	Seq       uint64                 `json:"seq,omitempty"`     // 客户端分配的单调递增序号，仅业务消息携带，服务端可据此去重
+	Type      MessageType            `json:"type"`              // 消息类型
+	Timestamp int64                  `json:"timestamp"`         // 时间戳
+	Payload   map[string]interface{} `json:"payload"`           // 消息内容
 }
 
 // NewMessage 创建一个新消息
@@ -42,10 +45,11 @@ func NewMessage(msgType MessageType, payload map[string]interface{}) *Message {
 type ConnectionState int
 
 const (
-	StateDisconnected ConnectionState = iota // 断开连接
-	StateConnecting                          // 正在连接
-	StateConnected                           // 已连接
-	StateReconnecting                        // 正在重连
+	StateDisconnected  ConnectionState = iota // 断开连接
+	StateConnecting                           // 正在连接
+	StateConnected                            // 已连接
+	StateReconnecting                         // 正在重连
+	StateDisconnecting                        // 正在断开
 )
 
 // String 返回连接状态的字符串表示
@@ -59,6 +63,8 @@ func (s ConnectionState) String() string {
 		return "已连接"
 	case StateReconnecting:
 		return "正在重连"
+	case StateDisconnecting:
+		return "正在断开"
 	default:
 		return "未知状态"
 	}
@@ -67,14 +73,32 @@ func (s ConnectionState) String() string {
 // ConnectionConfig 定义连接配置
 type ConnectionConfig struct {
 	ServerURL            string         // 服务器URL
+	ServerURLs           []string       // 服务器URL列表，按优先级排列，配置后优先于ServerURL
 	ReconnectInterval    time.Duration  // 重连间隔
 	MaxReconnectAttempts int            // 最大重连次数
 	HeartbeatInterval    time.Duration  // 心跳间隔
 	HandshakeTimeout     time.Duration  // 握手超时
 	WriteTimeout         time.Duration  // 写超时
 	ReadTimeout          time.Duration  // 读超时
+	CloseTimeout         time.Duration  // 断开连接时等待待发送消息发出和确认的超时
 	MessageBufferSize    int            // 消息缓冲区大小
+	SequenceStateFile    string         // 消息序号状态文件，用于重启后延续序号和已确认位置，为空时不持久化
+	HandlerWorkers       int            // 并发执行消息处理函数的协程上限
+	HandlerQueueSize     int            // 等待执行的消息处理调用上限，超出时丢弃
 	Security             SecurityConfig // 安全配置
+
+	Subprotocols []string          // 升级握手时请求的WebSocket子协议，按优先级排列
+	Headers      map[string]string // 升级握手时附加的HTTP头，如反向代理要求的认证头，启用认证时认证头优先
+
+	EndpointStrategy         EndpointStrategy // 端点选择策略 (ordered, random)
+	EndpointFailureThreshold int              // 端点连续失败多少次后轮换到下一个端点
+	EndpointCooldown         time.Duration    // 不健康端点的冷却时间
+
+	TokenRefreshBefore time.Duration // 配置令牌提供者时，在令牌过期前多久刷新令牌
+
+	HeartbeatMinInterval     time.Duration // 自适应心跳间隔下限，连接出现中断后逐步缩短到不低于该值
+	HeartbeatMaxInterval     time.Duration // 自适应心跳间隔上限，连接稳定时逐步延长到不超过该值
+	HeartbeatStableThreshold int           // 连续多少个心跳周期内连接无中断后延长心跳间隔
 }
 
 // SecurityConfig 定义安全配置
@@ -109,7 +133,10 @@ func DefaultConfig() ConnectionConfig {
 		HandshakeTimeout:     time.Second * 10,
 		WriteTimeout:         time.Second * 10,
 		ReadTimeout:          time.Second * 60,
+		CloseTimeout:         time.Second * 3,
 		MessageBufferSize:    100,
+		HandlerWorkers:       DefaultHandlerWorkers,
+		HandlerQueueSize:     DefaultHandlerQueueSize,
 		Security: SecurityConfig{
 			EnableTLS:        false,
 			VerifyServerCert: true,
@@ -130,6 +157,16 @@ func DefaultConfig() ConnectionConfig {
 			CompressionLevel:     6,
 			CompressionThreshold: 1024,
 		},
+
+		EndpointStrategy:         EndpointStrategyOrdered,
+		EndpointFailureThreshold: 3,
+		EndpointCooldown:         time.Second * 30,
+
+		TokenRefreshBefore: time.Minute,
+
+		HeartbeatMinInterval:     time.Second * 5,
+		HeartbeatMaxInterval:     time.Minute * 2,
+		HeartbeatStableThreshold: DefaultHeartbeatStableThreshold,
 	}
 }
 
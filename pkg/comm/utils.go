@@ -59,9 +59,14 @@ func createHeartbeatMessage() *Message {
 	})
 }
 
-// createConnectMessage 创建连接消息
+// createConnectMessage 创建连接消息，载荷中声明本端支持的协议版本
 func createConnectMessage(clientInfo map[string]interface{}) *Message {
-	return NewMessage(MessageTypeConnect, clientInfo)
+	payload := make(map[string]interface{}, len(clientInfo)+1)
+	for k, v := range clientInfo {
+		payload[k] = v
+	}
+	payload["protocol_versions"] = SupportedProtocolVersions()
+	return NewMessage(MessageTypeConnect, payload)
 }
 
 // createAckMessage 创建确认消息
@@ -71,3 +76,20 @@ func createAckMessage(messageID string) *Message {
 		"time":       time.Now().UnixNano() / int64(time.Millisecond),
 	})
 }
+
+// ackedSeq 返回确认消息载荷中的序号，不带序号时返回0
+func ackedSeq(payload map[string]interface{}) uint64 {
+	switch seq := payload["seq"].(type) {
+	case float64:
+		if seq > 0 {
+			return uint64(seq)
+		}
+	case uint64:
+		return seq
+	case int:
+		if seq > 0 {
+			return uint64(seq)
+		}
+	}
+	return 0
+}
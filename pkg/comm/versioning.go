@@ -0,0 +1,216 @@
+package comm
+
+import (
+	"fmt"
+	"sync"
+)
+
+// 协议版本
+// 版本1为不带版本字段的旧消息格式，版本2在消息信封中加入了 version 字段
+const (
+	ProtocolVersion    = 2 // 当前协议版本
+	MinProtocolVersion = 1 // 仍然支持的最低协议版本
+)
+
+// Upcaster 将消息从某个协议版本升级到下一个版本，可以改写消息类型和载荷
+type Upcaster func(msg *Message) error
+
+// SupportedProtocolVersions 返回支持的协议版本，从低到高排列
+func SupportedProtocolVersions() []int {
+	versions := make([]int, 0, ProtocolVersion-MinProtocolVersion+1)
+	for v := MinProtocolVersion; v <= ProtocolVersion; v++ {
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// messageVersion 返回消息的协议版本，未带版本字段的消息视为版本1
+func messageVersion(msg *Message) int {
+	if msg.Version <= 0 {
+		return 1
+	}
+	return msg.Version
+}
+
+// versionNegotiator 管理协议版本协商和旧版本消息的升级
+type versionNegotiator struct {
+	mu         sync.RWMutex
+	upcasters  map[int]Upcaster // 键为升级前的版本
+	version    int              // 与对端协商出的版本
+	negotiated bool             // 本次连接是否已完成协商
+}
+
+// newVersionNegotiator 创建版本协商器
+func newVersionNegotiator() *versionNegotiator {
+	return &versionNegotiator{
+		upcasters: make(map[int]Upcaster),
+		version:   ProtocolVersion,
+	}
+}
+
+// register 注册从 from 版本升级到 from+1 版本的升级函数，upcaster 为nil时注销
+func (n *versionNegotiator) register(from int, upcaster Upcaster) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if upcaster == nil {
+		delete(n.upcasters, from)
+		return
+	}
+	n.upcasters[from] = upcaster
+}
+
+// reset 开始新连接的协商，协商完成前按当前版本发送
+func (n *versionNegotiator) reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.version = ProtocolVersion
+	n.negotiated = false
+}
+
+// current 返回协商出的协议版本
+func (n *versionNegotiator) current() int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.version
+}
+
+// negotiate 根据对端在本次连接中发来的第一条消息确定双方支持的最高版本
+// 对端在载荷中声明 protocol_versions 时取交集中的最高版本，否则以消息自身的版本为对端最高版本
+// 返回协商出的版本以及本次调用是否完成了协商
+func (n *versionNegotiator) negotiate(msg *Message) (int, bool, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.negotiated {
+		return n.version, false, nil
+	}
+	n.negotiated = true
+
+	peerVersions, ok := announcedVersions(msg.Payload)
+	if !ok {
+		peerVersions = []int{messageVersion(msg)}
+		// 对端只知道自己的版本时，认为它兼容所有更低的版本
+		for v := peerVersions[0] - 1; v >= MinProtocolVersion; v-- {
+			peerVersions = append(peerVersions, v)
+		}
+	}
+
+	version, ok := highestCommonVersion(peerVersions)
+	if !ok {
+		n.version = MinProtocolVersion
+		return n.version, true, fmt.Errorf("与对端没有共同的协议版本，对端支持 %v，本端支持 %v", peerVersions, SupportedProtocolVersions())
+	}
+	n.version = version
+	return n.version, true, nil
+}
+
+// upcast 将旧版本消息逐级升级到当前版本，返回升级前的版本
+func (n *versionNegotiator) upcast(msg *Message) (int, error) {
+	from := messageVersion(msg)
+	if from >= ProtocolVersion {
+		return from, nil
+	}
+	if from < MinProtocolVersion {
+		return from, fmt.Errorf("不支持的协议版本: %d", from)
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	for v := from; v < ProtocolVersion; v++ {
+		// 没有注册升级函数的版本间只有信封变化，载荷格式相同
+		if upcaster, ok := n.upcasters[v]; ok {
+			if err := upcaster(msg); err != nil {
+				return from, fmt.Errorf("升级消息 %s 从版本 %d 到 %d 失败: %w", msg.ID, v, v+1, err)
+			}
+		}
+		msg.Version = v + 1
+	}
+	return from, nil
+}
+
+// announcedVersions 解析对端在载荷中声明的支持版本列表
+func announcedVersions(payload map[string]interface{}) ([]int, bool) {
+	raw, ok := payload["protocol_versions"]
+	if !ok {
+		return nil, false
+	}
+
+	var versions []int
+	switch list := raw.(type) {
+	case []int:
+		versions = append(versions, list...)
+	case []interface{}:
+		for _, item := range list {
+			switch v := item.(type) {
+			case float64:
+				versions = append(versions, int(v))
+			case int:
+				versions = append(versions, v)
+			}
+		}
+	}
+	return versions, len(versions) > 0
+}
+
+// highestCommonVersion 返回对端版本中本端也支持的最高版本
+func highestCommonVersion(peerVersions []int) (int, bool) {
+	best := 0
+	for _, v := range peerVersions {
+		if v >= MinProtocolVersion && v <= ProtocolVersion && v > best {
+			best = v
+		}
+	}
+	return best, best > 0
+}
+
+// RegisterUpcaster 注册从 from 版本升级到 from+1 版本的消息升级函数
+// 收到旧版本的消息时，按版本逐级调用升级函数，处理函数只会看到当前版本的消息
+func (c *Client) RegisterUpcaster(from int, upcaster Upcaster) {
+	c.versions.register(from, upcaster)
+}
+
+// GetProtocolVersion 返回与服务器协商出的协议版本
+func (c *Client) GetProtocolVersion() int {
+	return c.versions.current()
+}
+
+// stampVersion 按协商出的版本设置发出消息的版本，版本1的消息不带版本字段
+func (c *Client) stampVersion(msg *Message) {
+	version := c.versions.current()
+	if version <= 1 {
+		msg.Version = 0
+		return
+	}
+	msg.Version = version
+}
+
+// receiveVersioned 处理收到消息的协议版本：协商版本并将旧版本消息升级到当前版本
+func (c *Client) receiveVersioned(msg *Message) error {
+	version, done, err := c.versions.negotiate(msg)
+	if err != nil {
+		return err
+	}
+	if done {
+		if version < ProtocolVersion {
+			c.logger.Info("服务器使用较低的协议版本，协议降级", "version", version, "local_version", ProtocolVersion)
+		} else {
+			c.logger.Debug("协议版本协商完成", "version", version)
+		}
+	}
+
+	if messageVersion(msg) > ProtocolVersion {
+		c.logger.Warn("收到高于本端协议版本的消息", "id", msg.ID, "type", msg.Type, "version", msg.Version, "local_version", ProtocolVersion)
+		return nil
+	}
+
+	from, err := c.versions.upcast(msg)
+	if err != nil {
+		return err
+	}
+	if from < ProtocolVersion {
+		c.logger.Debug("旧版本消息已升级", "id", msg.ID, "type", msg.Type, "from", from, "to", msg.Version)
+	}
+	return nil
+}
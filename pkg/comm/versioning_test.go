@@ -0,0 +1,237 @@
+package comm
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// renameCmdUpcaster 测试用升级函数：版本1的命令消息使用 cmd 字段，版本2改名为 command
+func renameCmdUpcaster(msg *Message) error {
+	if msg.Type != MessageTypeCommand {
+		return nil
+	}
+	cmd, ok := msg.Payload["cmd"]
+	if !ok {
+		return errors.New("缺少cmd字段")
+	}
+	delete(msg.Payload, "cmd")
+	msg.Payload["command"] = cmd
+	return nil
+}
+
+// newVersionedTestServer 创建收到连接消息后按脚本回复的WebSocket测试服务器，收到的原始消息写入通道
+func newVersionedTestServer(t *testing.T, greeting func(connectID string) []string) (*httptest.Server, chan map[string]interface{}) {
+	received := make(chan map[string]interface{}, 20)
+
+	server := newWSTestServer(t, func(conn *websocket.Conn, r *http.Request) {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var raw map[string]interface{}
+			if err := json.Unmarshal(data, &raw); err != nil {
+				continue
+			}
+			received <- raw
+
+			if raw["type"] == string(MessageTypeConnect) {
+				for _, reply := range greeting(raw["id"].(string)) {
+					if err := conn.WriteMessage(websocket.TextMessage, []byte(reply)); err != nil {
+						return
+					}
+				}
+			}
+		}
+	})
+	return server, received
+}
+
+// waitRawMessage 等待服务器收到指定类型的消息
+func waitRawMessage(t *testing.T, received chan map[string]interface{}, msgType MessageType) map[string]interface{} {
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case raw := <-received:
+			if raw["type"] == string(msgType) {
+				return raw
+			}
+		case <-deadline:
+			t.Fatalf("服务器未收到 %s 消息", msgType)
+			return nil
+		}
+	}
+}
+
+// TestManagerProtocolVersions 测试与不同协议版本的服务器通信
+func TestManagerProtocolVersions(t *testing.T) {
+	tests := []struct {
+		name       string
+		greeting   func(connectID string) []string
+		negotiated int
+	}{
+		{
+			name: "旧版本服务器",
+			greeting: func(connectID string) []string {
+				return []string{`{"id": "w1", "type": "command", "timestamp": 1, "payload": {"cmd": "welcome"}}`}
+			},
+			negotiated: 1,
+		},
+		{
+			name: "当前版本服务器",
+			greeting: func(connectID string) []string {
+				return []string{
+					`{"id": "a1", "version": 2, "type": "ack", "timestamp": 1, "payload": {"message_id": "` + connectID + `", "protocol_versions": [1, 2, 3]}}`,
+					`{"id": "w1", "version": 2, "type": "command", "timestamp": 1, "payload": {"command": "welcome"}}`,
+				}
+			},
+			negotiated: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, received := newVersionedTestServer(t, tt.greeting)
+			defer server.Close()
+
+			config := DefaultConfig()
+			config.ServerURL = wsURL(server)
+			config.MaxReconnectAttempts = 0
+			manager := NewManager(config, nil)
+			manager.RegisterUpcaster(1, renameCmdUpcaster)
+
+			commands := make(chan *Message, 1)
+			manager.RegisterHandler(MessageTypeCommand, func(msg *Message) {
+				commands <- msg
+			})
+
+			if err := manager.Connect(); err != nil {
+				t.Fatalf("连接失败: %v", err)
+			}
+			defer manager.Disconnect()
+
+			connect := waitRawMessage(t, received, MessageTypeConnect)
+			if connect["version"] != float64(ProtocolVersion) {
+				t.Errorf("连接消息应使用当前协议版本，实际为 %v", connect["version"])
+			}
+			payload, _ := connect["payload"].(map[string]interface{})
+			if versions, ok := announcedVersions(payload); !ok || len(versions) != ProtocolVersion-MinProtocolVersion+1 {
+				t.Errorf("连接消息应声明支持的协议版本: %v", payload)
+			}
+
+			select {
+			case msg := <-commands:
+				if msg.Payload["command"] != "welcome" {
+					t.Errorf("命令消息应被规范为当前格式: %v", msg.Payload)
+				}
+				if _, ok := msg.Payload["cmd"]; ok {
+					t.Errorf("升级后不应保留旧字段: %v", msg.Payload)
+				}
+				if msg.Version != ProtocolVersion {
+					t.Errorf("处理函数收到的消息版本应为 %d，实际为 %d", ProtocolVersion, msg.Version)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("命令消息未到达处理函数")
+			}
+
+			if version := manager.GetProtocolVersion(); version != tt.negotiated {
+				t.Errorf("协商出的协议版本应为 %d，实际为 %d", tt.negotiated, version)
+			}
+
+			// 协商完成后发出的消息使用协商出的版本，版本1不带版本字段
+			manager.SendEvent("scan_finished", nil)
+			event := waitRawMessage(t, received, MessageTypeEvent)
+			version, hasVersion := event["version"]
+			if tt.negotiated == 1 && hasVersion {
+				t.Errorf("版本1的消息不应带版本字段: %v", event)
+			}
+			if tt.negotiated > 1 && version != float64(tt.negotiated) {
+				t.Errorf("消息版本应为 %d，实际为 %v", tt.negotiated, version)
+			}
+		})
+	}
+}
+
+// TestVersionNegotiatorUpcast 测试旧版本消息逐级升级
+func TestVersionNegotiatorUpcast(t *testing.T) {
+	n := newVersionNegotiator()
+	n.register(1, renameCmdUpcaster)
+
+	legacy := decodeTestMessage(t, `{"id": "m1", "type": "command", "payload": {"cmd": "scan"}}`)
+	from, err := n.upcast(legacy)
+	if err != nil {
+		t.Fatalf("升级消息失败: %v", err)
+	}
+	if from != 1 || legacy.Version != ProtocolVersion || legacy.Payload["command"] != "scan" {
+		t.Errorf("旧版本消息升级结果不正确: from=%d, %+v", from, legacy)
+	}
+
+	current := decodeTestMessage(t, `{"id": "m2", "version": 2, "type": "command", "payload": {"cmd": "keep"}}`)
+	if _, err := n.upcast(current); err != nil {
+		t.Fatalf("当前版本消息不应出错: %v", err)
+	}
+	if current.Payload["cmd"] != "keep" {
+		t.Errorf("当前版本消息不应被升级函数修改: %v", current.Payload)
+	}
+
+	broken := decodeTestMessage(t, `{"id": "m3", "type": "command", "payload": {}}`)
+	if _, err := n.upcast(broken); err == nil {
+		t.Error("升级函数失败时应返回错误")
+	}
+
+	// 注销后版本间只升级信封
+	n.register(1, nil)
+	plain := decodeTestMessage(t, `{"id": "m4", "type": "command", "payload": {"cmd": "scan"}}`)
+	if _, err := n.upcast(plain); err != nil || plain.Version != ProtocolVersion || plain.Payload["cmd"] != "scan" {
+		t.Errorf("未注册升级函数时只应更新版本: %v, %+v", err, plain)
+	}
+}
+
+// TestVersionNegotiatorNegotiate 测试协商双方支持的最高版本
+func TestVersionNegotiatorNegotiate(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		version int
+		wantErr bool
+	}{
+		{"未带版本的旧消息", `{"id": "m", "type": "heartbeat"}`, 1, false},
+		{"对端当前版本", `{"id": "m", "version": 2, "type": "heartbeat"}`, 2, false},
+		{"对端更高版本", `{"id": "m", "version": 5, "type": "heartbeat"}`, ProtocolVersion, false},
+		{"声明版本列表", `{"id": "m", "type": "ack", "payload": {"protocol_versions": [1]}}`, 1, false},
+		{"声明更高版本列表", `{"id": "m", "type": "ack", "payload": {"protocol_versions": [2, 3, 4]}}`, 2, false},
+		{"没有共同版本", `{"id": "m", "type": "ack", "payload": {"protocol_versions": [7, 8]}}`, MinProtocolVersion, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newVersionNegotiator()
+			version, done, err := n.negotiate(decodeTestMessage(t, tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("错误不符合预期: %v", err)
+			}
+			if !done || version != tt.version {
+				t.Errorf("协商结果应为 %d，实际为 %d (done=%v)", tt.version, version, done)
+			}
+
+			// 同一连接只协商一次
+			if _, done, _ := n.negotiate(decodeTestMessage(t, `{"id": "n", "version": 1, "type": "heartbeat"}`)); done {
+				t.Error("同一连接不应重复协商")
+			}
+			if n.current() != tt.version {
+				t.Errorf("后续消息不应改变协商结果，实际为 %d", n.current())
+			}
+
+			n.reset()
+			if n.current() != ProtocolVersion {
+				t.Errorf("新连接应从当前版本开始协商，实际为 %d", n.current())
+			}
+		})
+	}
+}
@@ -13,6 +13,9 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/lomehong/kennel/pkg/concurrency"
 	"github.com/lomehong/kennel/pkg/config"
+	"github.com/lomehong/kennel/pkg/core/metrics"
+	"github.com/lomehong/kennel/pkg/core/pluginusage"
+	"github.com/lomehong/kennel/pkg/core/preflight"
 	"github.com/lomehong/kennel/pkg/errors"
 	"github.com/lomehong/kennel/pkg/events"
 	"github.com/lomehong/kennel/pkg/health"
@@ -108,6 +111,18 @@ type App struct {
 	// 事件管理器
 	eventManager *events.EventManager
 
+	// 事件总线
+	eventBus *events.EventBus
+
+	// 启动前就绪检查
+	preflightRunner *preflight.Runner
+
+	// 插件资源占用采集器
+	pluginUsage *pluginusage.Collector
+
+	// 指标注册表
+	metricsRegistry *metrics.Registry
+
 	// 上下文和取消函数
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -186,6 +201,15 @@ func NewApp(configFile string) *App {
 	// 初始化事件管理器
 	app.initEventManager()
 
+	// 初始化启动前就绪检查
+	app.initPreflight()
+
+	// 初始化插件资源占用采集器
+	app.initPluginUsage()
+
+	// 初始化指标注册表
+	app.initMetricsRegistry()
+
 	return app
 }
 
@@ -199,6 +223,12 @@ func (app *App) Init() error {
 		return fmt.Errorf("初始化配置失败: %w", err)
 	}
 
+	// 在加载和启动插件前执行就绪检查
+	if err := app.runPreflight(); err != nil {
+		app.logger.Error("就绪检查失败", "error", err)
+		return err
+	}
+
 	// 获取插件目录
 	pluginDir := app.configManager.GetString("plugin_dir")
 	if !filepath.IsAbs(pluginDir) {
@@ -221,6 +251,8 @@ func (app *App) Init() error {
 		plugin.WithPluginManagerErrorRegistry(app.errorRegistry),
 		plugin.WithPluginManagerRecoveryManager(app.recoveryManager),
 		plugin.WithPluginManagerContext(app.ctx),
+		plugin.WithHostServices(app.pluginHostServices()),
+		app.pluginSignatureOption(),
 	)
 
 	// 加载插件
@@ -244,6 +276,9 @@ func (app *App) Init() error {
 		}
 	}
 
+	// 为模块订阅事件总线上的事件
+	app.subscribeModuleEvents()
+
 	// 创建通讯管理器
 	app.commManager = NewCommManager(app.configManager)
 
@@ -327,17 +362,15 @@ func (app *App) Init() error {
 	}
 
 	// 记录系统初始化完成事件
-	if app.eventManager != nil {
-		app.eventManager.PublishEvent(events.Event{
-			Type:    "system.initialized",
-			Message: "系统初始化完成",
-			Source:  "app",
-			Data: map[string]interface{}{
-				"version": app.version,
-				"modules": app.pluginManager.ListPlugins(),
-			},
-		})
-	}
+	app.PublishEvent(events.Event{
+		Type:    "system.initialized",
+		Message: "系统初始化完成",
+		Source:  "app",
+		Data: map[string]interface{}{
+			"version": app.version,
+			"modules": app.pluginManager.ListPlugins(),
+		},
+	})
 
 	app.logger.Info("应用程序初始化完成")
 	return nil
@@ -350,17 +383,15 @@ func (app *App) Start() error {
 	// 设置运行状态
 	app.running = true
 
-	// 记录系统启动事件
-	if app.eventManager != nil {
-		app.eventManager.PublishEvent(events.Event{
-			Type:    "system.startup",
-			Message: "系统正在启动",
-			Source:  "app",
-			Data: map[string]interface{}{
-				"version": app.version,
-			},
-		})
-	}
+	// 发布系统启动事件
+	app.PublishEvent(events.Event{
+		Type:    events.EventTypeSystemStartup,
+		Message: "系统正在启动",
+		Source:  "app",
+		Data: map[string]interface{}{
+			"version": app.version,
+		},
+	})
 
 	// 记录系统启动日志
 	if app.logManager != nil {
@@ -404,6 +435,9 @@ func (app *App) Start() error {
 		}
 	}
 
+	// 启动插件资源占用采样
+	app.startPluginUsageSampling()
+
 	// 启动Web控制台（如果已初始化）
 	if app.webConsole != nil {
 		app.logger.Info("启动Web控制台")
@@ -486,27 +520,17 @@ func (app *App) Stop() {
 			}
 		}
 
-		// 创建一个通道，用于等待插件关闭完成
-		pluginsDone := make(chan struct{})
-
-		// 在后台关闭所有插件
-		go func() {
-			app.logger.Info("开始关闭所有插件")
-			// 停止所有插件
-			plugins := app.pluginManager.ListPlugins()
-			for _, p := range plugins {
-				app.logger.Info("停止插件", "id", p.ID)
-				app.pluginManager.StopPlugin(p.ID)
+		// 按依赖关系的逆序停止插件，超出停止时限的插件被强制终止
+		app.logger.Info("开始关闭所有插件")
+		report := app.stopPlugins(ctx)
+		if offenders := report.Offenders(); len(offenders) > 0 {
+			ids := make([]string, 0, len(offenders))
+			for _, offender := range offenders {
+				ids = append(ids, offender.Plugin)
 			}
-			close(pluginsDone)
-		}()
-
-		// 等待插件关闭完成或超时
-		select {
-		case <-ctx.Done():
-			app.logger.Warn("插件关闭超时，强制终止")
-		case <-pluginsDone:
-			app.logger.Info("所有插件已正常关闭")
+			app.logger.Warn("部分插件超出停止时限，已强制终止", "plugins", ids, "duration", report.Duration)
+		} else {
+			app.logger.Info("所有插件已正常关闭", "order", report.Order, "duration", report.Duration)
 		}
 
 		// 执行其他清理工作
@@ -564,17 +588,18 @@ func (app *App) Stop() {
 			app.metricsCollector.Stop()
 		}
 
-		// 记录关闭事件
-		if app.eventManager != nil {
-			app.logger.Info("记录系统关闭事件")
-			app.eventManager.PublishEvent(events.Event{
-				Type:    "system.shutdown",
-				Message: "系统正在关闭",
-				Source:  "app",
-				Data: map[string]interface{}{
-					"uptime": time.Since(app.startTime).String(),
-				},
-			})
+		// 发布关闭事件并关闭事件总线，总线会处理完已入队的事件
+		app.logger.Info("记录系统关闭事件")
+		app.PublishEvent(events.Event{
+			Type:    events.EventTypeSystemShutdown,
+			Message: "系统正在关闭",
+			Source:  "app",
+			Data: map[string]interface{}{
+				"uptime": time.Since(app.startTime).String(),
+			},
+		})
+		if app.eventBus != nil {
+			app.eventBus.Close()
 		}
 
 		// 记录系统关闭日志
@@ -650,6 +675,11 @@ func (app *App) GetEventManager() interfaces.EventManagerInterface {
 	return app.eventManager
 }
 
+// GetEventBus 获取事件总线
+func (app *App) GetEventBus() *events.EventBus {
+	return app.eventBus
+}
+
 // GetResourceTracker 获取资源追踪器
 func (app *App) GetResourceTracker() *resource.ResourceTracker {
 	return app.resourceTracker
@@ -756,6 +786,14 @@ func (app *App) initEventManager() {
 	app.eventManager = events.NewEventManager(eventManagerLogger,
 		events.WithMaxEvents(10000),
 	)
+
+	// 创建事件总线，事件管理器订阅所有事件以保留事件历史
+	app.eventBus = events.NewEventBus(app.GetNamedLogger("event-bus"),
+		events.WithSubscriberBufferSize(app.configManager.GetIntOrDefault("event_bus.buffer_size", 100)),
+	)
+	if _, err := app.eventBus.Subscribe("event-manager", app.eventManager.PublishEvent); err != nil {
+		app.logger.Error("事件管理器订阅事件总线失败", "error", err)
+	}
 }
 
 // GetSystemMonitor 获取系统监控器
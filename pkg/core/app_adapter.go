@@ -2,10 +2,13 @@ package core
 
 import (
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
 	"time"
 
 	"github.com/lomehong/kennel/pkg/comm"
+	"github.com/lomehong/kennel/pkg/core/pluginusage"
 	"github.com/lomehong/kennel/pkg/interfaces"
 	"github.com/lomehong/kennel/pkg/plugin"
 	pluginLib "github.com/lomehong/kennel/pkg/plugin"
@@ -56,6 +59,19 @@ func (a *AppInterfaceAdapter) GetSystemMonitor() interfaces.SystemMonitorInterfa
 	return a.app.GetSystemMonitor()
 }
 
+// GetPluginUsage 获取各插件的资源占用
+func (a *AppInterfaceAdapter) GetPluginUsage(refresh bool) pluginusage.Report {
+	if refresh {
+		return a.app.SamplePluginUsage()
+	}
+	return a.app.GetPluginUsage()
+}
+
+// WriteMetrics 以 Prometheus 文本格式输出全部子系统和插件的指标
+func (a *AppInterfaceAdapter) WriteMetrics(w io.Writer) error {
+	return a.app.WriteMetrics(w)
+}
+
 // GetStartTime 获取应用程序启动时间
 func (a *AppInterfaceAdapter) GetStartTime() time.Time {
 	return a.app.startTime
@@ -609,10 +625,25 @@ func (a *CommManagerAdapter) GetConfig() map[string]interface{} {
 	result["reconnect_interval"] = config.ReconnectInterval.String()
 	result["max_reconnect_attempts"] = config.MaxReconnectAttempts
 	result["heartbeat_interval"] = config.HeartbeatInterval.String()
+	result["heartbeat_min_interval"] = config.HeartbeatMinInterval.String()
+	result["heartbeat_max_interval"] = config.HeartbeatMaxInterval.String()
 	result["handshake_timeout"] = config.HandshakeTimeout.String()
 	result["write_timeout"] = config.WriteTimeout.String()
 	result["read_timeout"] = config.ReadTimeout.String()
+	result["close_timeout"] = config.CloseTimeout.String()
 	result["message_buffer_size"] = config.MessageBufferSize
+	result["sequence_state_file"] = config.SequenceStateFile
+	result["handler_workers"] = config.HandlerWorkers
+	result["handler_queue_size"] = config.HandlerQueueSize
+	result["subprotocols"] = config.Subprotocols
+
+	// 附加的HTTP头可能包含凭据，只返回头名称
+	headerNames := make([]string, 0, len(config.Headers))
+	for name := range config.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	result["headers"] = headerNames
 
 	// 安全配置
 	security := make(map[string]interface{})
@@ -179,53 +179,16 @@ func (app *App) handleServerConfigChange(oldConfig, newConfig map[string]interfa
 
 // handlePluginConfigChange 处理插件配置变更
 func (app *App) handlePluginConfigChange(oldConfig, newConfig map[string]interface{}) error {
-	// 获取旧的插件配置
-	var oldPlugins map[string]interface{}
-	if oldPluginsConfig, ok := oldConfig["plugins"].(map[string]interface{}); ok {
-		if list, ok := oldPluginsConfig["list"].(map[string]interface{}); ok {
-			oldPlugins = list
-		}
-	}
-
-	// 获取新的插件配置
-	var newPlugins map[string]interface{}
-	if newPluginsConfig, ok := newConfig["plugins"].(map[string]interface{}); ok {
-		if list, ok := newPluginsConfig["list"].(map[string]interface{}); ok {
-			newPlugins = list
-		}
-	}
-
-	// 检查插件配置是否变更
-	if oldPlugins == nil || newPlugins == nil {
-		return nil
-	}
+	// 按启用状态的变化启动或停止插件
+	app.applyPluginEnabledChanges(oldConfig, newConfig)
 
-	// 检查新增或修改的插件
-	for id, newPluginConfig := range newPlugins {
-		newPlugin, ok := newPluginConfig.(map[string]interface{})
-		if !ok {
-			continue
-		}
+	oldPlugins := pluginConfigs(oldConfig)
+	newPlugins := pluginConfigs(newConfig)
 
-		oldPlugin, exists := oldPlugins[id]
-		if !exists {
-			// 新增插件
+	// 检查新增的插件，启用的新插件已由 applyPluginEnabledChanges 加载
+	for id := range newPlugins {
+		if _, exists := oldPlugins[id]; !exists {
 			app.logger.Info("发现新插件", "id", id)
-			// 在实际应用中，这里可能需要加载新插件
-		} else {
-			// 检查插件配置是否变更
-			oldPluginMap, ok := oldPlugin.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			// 检查启用状态是否变更
-			oldEnabled, _ := oldPluginMap["enabled"].(bool)
-			newEnabled, _ := newPlugin["enabled"].(bool)
-			if oldEnabled != newEnabled {
-				app.logger.Info("插件启用状态已变更", "id", id, "enabled", newEnabled)
-				// 在实际应用中，这里可能需要启用或禁用插件
-			}
 		}
 	}
 
@@ -290,11 +253,6 @@ func (app *App) handleHealthConfigChange(oldConfig, newConfig map[string]interfa
 	return nil
 }
 
-// notifyConfigChange 通知配置变更
-func (app *App) notifyConfigChange(oldConfig, newConfig map[string]interface{}) {
-	// 在实际应用中，这里可能需要通知其他组件配置已变更
-}
-
 // SaveConfig 保存配置
 func (app *App) SaveConfig() error {
 	if app.dynamicConfig != nil {
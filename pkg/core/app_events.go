@@ -0,0 +1,108 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/lomehong/kennel/pkg/events"
+	"github.com/lomehong/kennel/pkg/plugin"
+)
+
+// moduleEventSubscriptions 各模块订阅的事件类型，事件由事件总线转发给对应的插件
+var moduleEventSubscriptions = map[string][]string{
+	"dlp": {
+		events.EventTypeSystemStartup,
+		events.EventTypeSystemShutdown,
+		events.EventTypeConfigChanged,
+		events.EventTypeSecurityAlert,
+	},
+	"control": {
+		events.EventTypeSystemStartup,
+		events.EventTypeSystemShutdown,
+		events.EventTypeConfigChanged,
+	},
+}
+
+// PublishEvent 通过事件总线发布事件
+func (app *App) PublishEvent(event events.Event) {
+	if app.eventBus == nil {
+		return
+	}
+	if err := app.eventBus.Publish(event); err != nil {
+		app.logger.Warn("发布事件失败", "type", event.Type, "error", err)
+	}
+}
+
+// subscribeModuleEvents 为已加载的模块订阅其关注的事件
+func (app *App) subscribeModuleEvents() {
+	if app.eventBus == nil || app.pluginManager == nil {
+		return
+	}
+
+	for _, p := range app.pluginManager.ListPlugins() {
+		eventTypes, ok := moduleEventSubscriptions[p.ID]
+		if !ok {
+			continue
+		}
+
+		if _, err := app.eventBus.Subscribe(p.ID, app.moduleEventHandler(p.ID), eventTypes...); err != nil {
+			app.logger.Error("模块订阅事件失败", "module", p.ID, "error", err)
+			continue
+		}
+		app.logger.Info("模块已订阅事件", "module", p.ID, "types", eventTypes)
+	}
+}
+
+// moduleEventHandler 创建把事件转发给插件的处理函数，插件未运行时忽略事件
+func (app *App) moduleEventHandler(id string) events.EventHandler {
+	return func(event events.Event) error {
+		managed, ok := app.pluginManager.GetPlugin(id)
+		if !ok || managed.State != plugin.PluginStateRunning {
+			return nil
+		}
+
+		module, ok := managed.Interface.(plugin.Module)
+		if !ok {
+			return fmt.Errorf("插件 %s 不支持接收事件", id)
+		}
+
+		data := make(map[string]interface{}, len(event.Data)+2)
+		for key, value := range event.Data {
+			data[key] = value
+		}
+		data["source"] = event.Source
+		data["message"] = event.Message
+
+		_, err := module.HandleMessage(event.Type, event.ID, event.Timestamp.UnixMilli(), data)
+		return err
+	}
+}
+
+// notifyConfigChange 发布配置变更事件
+func (app *App) notifyConfigChange(oldConfig, newConfig map[string]interface{}) {
+	changed := make([]string, 0)
+	for key, value := range newConfig {
+		if !reflect.DeepEqual(oldConfig[key], value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range oldConfig {
+		if _, ok := newConfig[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+	sort.Strings(changed)
+
+	app.PublishEvent(events.Event{
+		Type:    events.EventTypeConfigChanged,
+		Message: "配置已变更",
+		Source:  "config",
+		Data: map[string]interface{}{
+			"changed_keys": changed,
+		},
+	})
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"io"
+
+	"github.com/lomehong/kennel/pkg/core/metrics"
+	"github.com/lomehong/kennel/pkg/plugin"
+)
+
+// initMetricsRegistry 初始化指标注册表，登记核心子系统的指标来源并汇总运行中插件的指标
+func (app *App) initMetricsRegistry() {
+	app.metricsRegistry = metrics.NewRegistry(metrics.DefaultNamespace)
+	app.metricsRegistry.SetPluginLister(app.pluginMetricsSources)
+
+	// 通讯管理器在 Init 阶段创建，采集时再取
+	app.RegisterMetricsSource("comm", func() map[string]interface{} {
+		if app.commManager == nil {
+			return nil
+		}
+		return app.commManager.GetMetrics()
+	})
+	app.RegisterMetricsSource("system", app.metricsCollector.GetMetrics)
+	app.RegisterMetricsSource("resource", app.GetResourceStats)
+	app.RegisterMetricsSource("concurrency", func() map[string]interface{} {
+		pools := make(map[string]interface{})
+		for name, stats := range app.GetAllPoolStats() {
+			pools[name] = stats
+		}
+		return pools
+	})
+	app.RegisterMetricsSource("recovery", func() map[string]interface{} {
+		if app.recoveryManager == nil {
+			return nil
+		}
+		stats := app.recoveryManager.GetStats()
+		return map[string]interface{}{
+			"total_panics":     stats.TotalPanics,
+			"recovered_panics": stats.RecoveredPanics,
+		}
+	})
+	// 事件总线在 Init 阶段创建，采集时再取
+	app.RegisterMetricsSource("events", func() map[string]interface{} {
+		if app.eventBus == nil {
+			return nil
+		}
+		subscribers := make(map[string]interface{})
+		for _, stats := range app.eventBus.Stats() {
+			subscribers[stats.Name] = map[string]interface{}{
+				"queued":    stats.Queued,
+				"delivered": stats.Delivered,
+				"dropped":   stats.Dropped,
+				"failed":    stats.Failed,
+			}
+		}
+		return map[string]interface{}{"subscribers": subscribers}
+	})
+}
+
+// RegisterMetricsSource 登记子系统的指标来源，例如自我保护管理器的 GetMetrics
+// 子系统名称只能包含字母、数字和下划线，同名来源重复登记返回错误
+func (app *App) RegisterMetricsSource(subsystem string, source metrics.Source) error {
+	if err := app.metricsRegistry.Register(subsystem, source); err != nil {
+		app.logger.Warn("登记指标来源失败", "subsystem", subsystem, "error", err)
+		return err
+	}
+	return nil
+}
+
+// WriteMetrics 以 Prometheus 文本格式输出全部子系统和插件的指标
+func (app *App) WriteMetrics(w io.Writer) error {
+	return app.metricsRegistry.WritePrometheus(w)
+}
+
+// GetMetricsRegistry 获取指标注册表
+func (app *App) GetMetricsRegistry() *metrics.Registry {
+	return app.metricsRegistry
+}
+
+// pluginMetricsSources 列出运行中且实现了 Metricable 的插件
+// 进程外插件的 GRPCClient 通过 GetMetrics RPC 获取指标，插件不支持时返回 error 字段；
+// 注册表对每次采集设置超时，超时未返回的插件不输出指标
+func (app *App) pluginMetricsSources() map[string]metrics.Source {
+	if app.pluginManager == nil {
+		return nil
+	}
+
+	sources := make(map[string]metrics.Source)
+	for _, managed := range app.pluginManager.ListPlugins() {
+		if managed.State != plugin.PluginStateRunning {
+			continue
+		}
+		if metricable, ok := managed.Interface.(plugin.Metricable); ok {
+			sources[managed.ID] = metricable.GetMetrics
+		}
+	}
+	return sources
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	coreplugin "github.com/lomehong/kennel/pkg/core/plugin"
 	"github.com/lomehong/kennel/pkg/plugin"
 )
 
@@ -21,6 +22,8 @@ func (app *App) initPluginManager() {
 		plugin.WithPluginManagerContext(app.ctx),
 		plugin.WithHealthCheckInterval(app.configManager.GetDurationOrDefault("plugins.health_check_interval", 30*time.Second)),
 		plugin.WithIdleTimeout(app.configManager.GetDurationOrDefault("plugins.idle_timeout", 10*time.Minute)),
+		plugin.WithHostServices(app.pluginHostServices()),
+		app.pluginSignatureOption(),
 	)
 
 	// 启动健康检查
@@ -29,6 +32,78 @@ func (app *App) initPluginManager() {
 	app.logger.Info("插件管理器已初始化")
 }
 
+// pluginSignatureOption 根据 plugin_signature 配置返回插件签名校验选项
+// 无法识别的校验模式按 enforce 处理；信任库加载失败时没有受信任的密钥
+func (app *App) pluginSignatureOption() plugin.PluginManagerOption {
+	mode := coreplugin.SignatureMode(app.configManager.GetStringOrDefault("plugin_signature.mode", string(coreplugin.SignatureModeDisabled)))
+	switch mode {
+	case coreplugin.SignatureModeDisabled, coreplugin.SignatureModeWarn, coreplugin.SignatureModeEnforce:
+	default:
+		app.logger.Warn("未知的插件签名校验模式，按 enforce 处理", "mode", mode)
+		mode = coreplugin.SignatureModeEnforce
+	}
+	if mode == coreplugin.SignatureModeDisabled {
+		return plugin.WithSignatureVerification(nil, mode)
+	}
+
+	var store *coreplugin.TrustStore
+	if path := app.configManager.GetString("plugin_signature.trust_store"); path == "" {
+		app.logger.Warn("未配置插件签名信任库", "mode", mode)
+	} else if loaded, err := coreplugin.LoadTrustStore(path); err != nil {
+		app.logger.Error("加载插件签名信任库失败", "path", path, "error", err)
+	} else {
+		store = loaded
+	}
+	return plugin.WithSignatureVerification(store, mode)
+}
+
+// pluginHostServices 返回向插件提供的主机服务，插件管理器按各插件清单中声明的权限包装
+// 主机目前不提供存储服务
+func (app *App) pluginHostServices() coreplugin.HostServices {
+	return coreplugin.HostServices{
+		Comm: &appCommService{app: app},
+		Exec: coreplugin.DefaultExecService{},
+	}
+}
+
+// appCommService 通过应用程序的通讯管理器发送插件数据
+// 通讯管理器在插件加载后才创建，因此在发送时获取
+type appCommService struct {
+	app *App
+}
+
+// SendData 发送数据到服务端
+func (s *appCommService) SendData(dataType string, data interface{}) {
+	if s.app.commManager == nil {
+		s.app.logger.Warn("通讯管理器未创建，丢弃插件数据", "type", dataType)
+		return
+	}
+	if err := s.app.commManager.SendData(dataType, data); err != nil {
+		s.app.logger.Warn("发送插件数据失败", "type", dataType, "error", err)
+	}
+}
+
+// SendEvent 发送事件到服务端
+func (s *appCommService) SendEvent(eventType string, details map[string]interface{}) {
+	if s.app.commManager == nil {
+		s.app.logger.Warn("通讯管理器未创建，丢弃插件事件", "type", eventType)
+		return
+	}
+	if err := s.app.commManager.SendEvent(eventType, details); err != nil {
+		s.app.logger.Warn("发送插件事件失败", "type", eventType, "error", err)
+	}
+}
+
+// UpgradePlugins 将清单版本发生变化的运行中插件原地升级，返回升级成功的插件ID
+func (app *App) UpgradePlugins() ([]string, error) {
+	upgraded, err := app.pluginManager.UpgradePlugins()
+	if err != nil {
+		app.logger.Error("升级插件失败", "error", err)
+	}
+	app.logger.Info("插件升级完成", "upgraded", upgraded)
+	return upgraded, err
+}
+
 // LoadPlugin 加载插件
 func (app *App) LoadPlugin(config *plugin.PluginConfig) (*plugin.ManagedPlugin, error) {
 	return app.pluginManager.LoadPlugin(config)
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lomehong/kennel/pkg/core/pluginreload"
+	"github.com/lomehong/kennel/pkg/plugin"
+)
+
+// pluginConfigs 读取配置中的插件配置，支持 plugins.<id> 和旧版 plugins.list.<id> 两种格式
+func pluginConfigs(cfg map[string]interface{}) map[string]map[string]interface{} {
+	configs := make(map[string]map[string]interface{})
+	pluginsConfig, ok := cfg["plugins"].(map[string]interface{})
+	if !ok {
+		return configs
+	}
+
+	if list, ok := pluginsConfig["list"].(map[string]interface{}); ok {
+		for id, value := range list {
+			if m, ok := value.(map[string]interface{}); ok {
+				configs[id] = m
+			}
+		}
+	}
+	for id, value := range pluginsConfig {
+		if id == "list" {
+			continue
+		}
+		if m, ok := value.(map[string]interface{}); ok {
+			configs[id] = m
+		}
+	}
+	return configs
+}
+
+// pluginDependencies 读取插件配置中的 dependencies 列表
+func pluginDependencies(m map[string]interface{}) []string {
+	var deps []string
+	switch list := m["dependencies"].(type) {
+	case []string:
+		deps = append(deps, list...)
+	case []interface{}:
+		for _, item := range list {
+			if dep, ok := item.(string); ok {
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps
+}
+
+// pluginReloadController 通过插件管理器启动和停止插件，未加载的插件按新配置加载
+type pluginReloadController struct {
+	app     *App
+	configs map[string]map[string]interface{}
+}
+
+// StartPlugin 启动插件，插件未加载时先按新配置加载
+func (c *pluginReloadController) StartPlugin(id string) error {
+	if _, loaded := c.app.pluginManager.GetPlugin(id); !loaded {
+		m := c.configs[id]
+		if m == nil {
+			m = make(map[string]interface{})
+		}
+		config := &plugin.PluginConfig{
+			ID:             id,
+			Name:           getStringOrDefault(m, "name", id),
+			Version:        getStringOrDefault(m, "version", "1.0.0"),
+			Path:           getStringOrDefault(m, "path", id),
+			IsolationLevel: getIsolationLevel(m, "isolation_level"),
+			AutoRestart:    getBoolOrDefault(m, "auto_restart", false),
+			Enabled:        true,
+			Dependencies:   pluginDependencies(m),
+		}
+		if _, err := c.app.pluginManager.LoadPlugin(config); err != nil {
+			return fmt.Errorf("加载插件失败: %w", err)
+		}
+	}
+	return c.app.pluginManager.StartPlugin(id)
+}
+
+// StopPlugin 停止插件
+func (c *pluginReloadController) StopPlugin(ctx context.Context, id string) error {
+	return c.app.pluginManager.StopPlugin(id)
+}
+
+// KillPlugin 强制终止插件
+func (c *pluginReloadController) KillPlugin(id string) {
+	if err := c.app.pluginManager.KillPlugin(id); err != nil {
+		c.app.logger.Error("强制终止插件失败", "id", id, "error", err)
+	}
+}
+
+// applyPluginEnabledChanges 根据 plugins.<id>.enabled 的变化启动或停止插件，无需重启 Agent
+// 停用插件时先停止依赖它的插件，启用插件时先启动它依赖的插件；未配置 enabled 的插件视为启用
+func (app *App) applyPluginEnabledChanges(oldConfig, newConfig map[string]interface{}) *pluginreload.Report {
+	if app.pluginManager == nil {
+		return nil
+	}
+
+	oldConfigs := pluginConfigs(oldConfig)
+	newConfigs := pluginConfigs(newConfig)
+
+	loaded := make(map[string]*plugin.ManagedPlugin)
+	for _, managed := range app.pluginManager.ListPlugins() {
+		loaded[managed.ID] = managed
+	}
+
+	states := make(map[string]*pluginreload.Plugin)
+	state := func(id string) {
+		if _, ok := states[id]; ok {
+			return
+		}
+
+		// 不在旧配置中的插件：已加载的（如自动发现的插件）视为启用，否则视为新增
+		managed, isLoaded := loaded[id]
+		p := &pluginreload.Plugin{ID: id, WasEnabled: isLoaded}
+		if m, ok := oldConfigs[id]; ok {
+			p.WasEnabled = getBoolOrDefault(m, "enabled", true)
+		}
+		if m, ok := newConfigs[id]; ok {
+			p.Enabled = getBoolOrDefault(m, "enabled", true)
+			p.Dependencies = pluginDependencies(m)
+		} else {
+			// 从配置中删除的插件保持原状
+			p.Enabled = p.WasEnabled
+		}
+		if isLoaded {
+			p.Running = managed.State == plugin.PluginStateRunning
+			if managed.Config != nil && len(managed.Config.Dependencies) > 0 {
+				p.Dependencies = managed.Config.Dependencies
+			}
+		}
+		states[id] = p
+	}
+
+	for id := range loaded {
+		state(id)
+	}
+	for id := range oldConfigs {
+		state(id)
+	}
+	for id := range newConfigs {
+		state(id)
+	}
+
+	ids := make([]string, 0, len(states))
+	for id := range states {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	plugins := make([]pluginreload.Plugin, 0, len(ids))
+	for _, id := range ids {
+		plugins = append(plugins, *states[id])
+	}
+
+	plan := pluginreload.NewPlan(plugins)
+	if plan.Empty() {
+		return nil
+	}
+
+	controller := &pluginReloadController{app: app, configs: newConfigs}
+	report := pluginreload.Apply(app.ctx, plan, controller, app.pluginShutdownTimeout())
+
+	for _, result := range report.Stopped.Results {
+		if result.Error != "" {
+			app.logger.Error("按配置停止插件失败", "id", result.Plugin, "status", result.Status, "error", result.Error)
+		} else {
+			app.logger.Info("按配置停止插件", "id", result.Plugin, "duration", result.Duration)
+		}
+	}
+	for _, result := range report.Started {
+		if result.Status != pluginreload.StatusStarted {
+			app.logger.Error("按配置启动插件失败", "id", result.Plugin, "reason", result.Reason, "status", result.Status, "error", result.Error)
+		} else {
+			app.logger.Info("按配置启动插件", "id", result.Plugin, "reason", result.Reason)
+		}
+	}
+	for _, blocked := range report.Blocked {
+		app.logger.Warn("插件依赖未启用，暂不启动", "id", blocked.Plugin, "missing", blocked.Missing)
+	}
+	return report
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"time"
+
+	"github.com/lomehong/kennel/pkg/core/pluginusage"
+	"github.com/lomehong/kennel/pkg/plugin"
+)
+
+// initPluginUsage 初始化插件资源占用采集器
+func (app *App) initPluginUsage() {
+	app.pluginUsage = pluginusage.NewCollector(pluginusage.NewProcessSampler())
+}
+
+// startPluginUsageSampling 按 resource.plugin_usage_interval 周期采样插件资源占用，应用程序停止时退出
+func (app *App) startPluginUsageSampling() {
+	interval := 30 * time.Second
+	if intervalStr := app.configManager.GetString("resource.plugin_usage_interval"); intervalStr != "" {
+		if duration, err := time.ParseDuration(intervalStr); err == nil && duration > 0 {
+			interval = duration
+		} else {
+			app.logger.Warn("插件资源采样间隔无效，使用默认值", "value", intervalStr, "default", interval)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		app.SamplePluginUsage()
+		for {
+			select {
+			case <-app.ctx.Done():
+				return
+			case <-ticker.C:
+				report := app.SamplePluginUsage()
+				if top, ok := report.Top(); ok {
+					app.logger.Debug("插件资源占用",
+						"plugins", len(report.Plugins),
+						"total_cpu_percent", report.TotalCPUPercent,
+						"total_memory_rss", report.TotalMemoryRSS,
+						"top_plugin", top.PluginID,
+						"top_cpu_percent", top.CPUPercent,
+					)
+				}
+			}
+		}
+	}()
+
+	app.logger.Info("插件资源采样已启动", "interval", interval)
+}
+
+// SamplePluginUsage 立即采样各插件的资源占用
+func (app *App) SamplePluginUsage() pluginusage.Report {
+	return app.pluginUsage.Sample(app.pluginUsageTargets(), time.Now())
+}
+
+// GetPluginUsage 获取最近一次的插件资源占用报告，尚未采样时立即采样
+func (app *App) GetPluginUsage() pluginusage.Report {
+	if report, ok := app.pluginUsage.Latest(); ok {
+		return report
+	}
+	return app.SamplePluginUsage()
+}
+
+// pluginUsageTargets 收集运行中插件的采样目标
+// 独立进程插件按进程ID采样，进程内插件使用沙箱记录的累计执行耗时
+func (app *App) pluginUsageTargets() []pluginusage.Target {
+	if app.pluginManager == nil {
+		return nil
+	}
+
+	var targets []pluginusage.Target
+	for _, managed := range app.pluginManager.ListPlugins() {
+		if managed.State != plugin.PluginStateRunning {
+			continue
+		}
+
+		target := pluginusage.Target{PluginID: managed.ID}
+		if managed.Client != nil {
+			if reattach := managed.Client.ReattachConfig(); reattach != nil && reattach.Pid > 0 {
+				target.PID = int32(reattach.Pid)
+			}
+		}
+		if target.PID == 0 && managed.Sandbox != nil {
+			target.ExecTime = managed.Sandbox.TotalExecTime()
+		}
+		targets = append(targets, target)
+	}
+	return targets
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/lomehong/kennel/pkg/core/preflight"
+)
+
+// initPreflight 初始化启动前就绪检查
+func (app *App) initPreflight() {
+	app.preflightRunner = preflight.NewRunner()
+}
+
+// RegisterPreflightCheck 为插件注册启动前就绪检查
+// 插件也可以在配置的 plugins.<id>.preflight 中声明检查
+func (app *App) RegisterPreflightCheck(pluginID string, check preflight.Check) error {
+	return app.preflightRunner.Register(pluginID, check)
+}
+
+// RunPreflightChecks 执行已注册的检查和已启用插件在配置中声明的检查
+func (app *App) RunPreflightChecks(ctx context.Context) (*preflight.Report, error) {
+	runner := app.preflightRunner.Clone()
+
+	pluginsConfig := app.configManager.GetStringMap("plugins")
+	ids := make([]string, 0, len(pluginsConfig))
+	for id := range pluginsConfig {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if !app.configManager.GetBoolOrDefault(fmt.Sprintf("plugins.%s.enabled", id), true) {
+			continue
+		}
+
+		specs, ok := app.configManager.Get(fmt.Sprintf("plugins.%s.preflight", id)).([]interface{})
+		if !ok {
+			continue
+		}
+
+		checks, err := preflight.ChecksFromConfig(specs)
+		if err != nil {
+			return nil, fmt.Errorf("插件 %s 的就绪检查配置无效: %w", id, err)
+		}
+		for _, check := range checks {
+			if err := runner.Register(id, check); err != nil {
+				return nil, fmt.Errorf("插件 %s 的就绪检查配置无效: %w", id, err)
+			}
+		}
+	}
+
+	return runner.Run(ctx), nil
+}
+
+// CheckReadiness 加载配置并执行就绪检查，不启动任何插件
+func (app *App) CheckReadiness(ctx context.Context) (*preflight.Report, error) {
+	if err := app.configManager.InitConfig(); err != nil {
+		return nil, fmt.Errorf("初始化配置失败: %w", err)
+	}
+	return app.RunPreflightChecks(ctx)
+}
+
+// runPreflight 在加载插件前执行就绪检查，必需检查失败时返回汇总错误
+func (app *App) runPreflight() error {
+	if !app.configManager.GetBoolOrDefault("preflight.enabled", true) {
+		app.logger.Info("就绪检查已禁用")
+		return nil
+	}
+
+	report, err := app.RunPreflightChecks(app.ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case preflight.StatusFail:
+			app.logger.Error("就绪检查失败", "plugin", result.Plugin, "check", result.Name, "message", result.Message)
+		case preflight.StatusWarn:
+			app.logger.Warn("就绪检查未通过", "plugin", result.Plugin, "check", result.Name, "message", result.Message)
+		default:
+			app.logger.Debug("就绪检查通过", "plugin", result.Plugin, "check", result.Name)
+		}
+	}
+
+	if !report.Ready {
+		return fmt.Errorf("启动前就绪检查未通过:\n%s", report.String())
+	}
+	if len(report.Results) > 0 {
+		app.logger.Info("就绪检查通过", "checks", len(report.Results), "warnings", len(report.Warnings()))
+	}
+	return nil
+}
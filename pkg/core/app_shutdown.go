@@ -0,0 +1,75 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/core/shutdown"
+	"github.com/lomehong/kennel/pkg/plugin"
+)
+
+// pluginShutdownTimeout 返回单个插件的停止时限，配置项 plugin_shutdown_timeout 支持时长字符串或秒数
+func (app *App) pluginShutdownTimeout() time.Duration {
+	timeout := shutdown.DefaultTimeout
+	if timeoutStr := app.configManager.GetString("plugin_shutdown_timeout"); timeoutStr != "" {
+		if t, err := time.ParseDuration(timeoutStr); err == nil && t > 0 {
+			timeout = t
+		} else if seconds := app.configManager.GetInt("plugin_shutdown_timeout"); seconds > 0 {
+			timeout = time.Duration(seconds) * time.Second
+		} else {
+			app.logger.Warn("插件停止时限无效，使用默认值", "value", timeoutStr, "default", timeout)
+		}
+	}
+	return timeout
+}
+
+// stopPlugins 按依赖关系的逆序停止运行中的插件
+// 每个插件的停止时限由 plugin_shutdown_timeout 配置，超时的插件被强制终止后继续停止其余插件
+func (app *App) stopPlugins(ctx context.Context) *shutdown.Report {
+	if app.pluginManager == nil {
+		return &shutdown.Report{}
+	}
+
+	timeout := app.pluginShutdownTimeout()
+	var plugins []shutdown.Plugin
+	for _, managed := range app.pluginManager.ListPlugins() {
+		if managed.State != plugin.PluginStateRunning {
+			continue
+		}
+
+		id := managed.ID
+		var dependencies []string
+		if managed.Config != nil {
+			dependencies = managed.Config.Dependencies
+		}
+		plugins = append(plugins, shutdown.Plugin{
+			ID:           id,
+			Dependencies: dependencies,
+			Timeout:      timeout,
+			Stop: func(ctx context.Context) error {
+				return app.pluginManager.StopPlugin(id)
+			},
+			Kill: func() {
+				if err := app.pluginManager.KillPlugin(id); err != nil {
+					app.logger.Error("强制终止插件失败", "id", id, "error", err)
+				}
+			},
+		})
+	}
+
+	report := shutdown.Run(ctx, plugins)
+	if report.OrderError != "" {
+		app.logger.Warn("无法按依赖关系排序插件，按ID顺序停止", "error", report.OrderError)
+	}
+	for _, result := range report.Results {
+		switch result.Status {
+		case shutdown.StatusStopped:
+			app.logger.Info("插件已停止", "id", result.Plugin, "duration", result.Duration)
+		case shutdown.StatusFailed:
+			app.logger.Error("停止插件失败", "id", result.Plugin, "error", result.Error)
+		default:
+			app.logger.Warn("插件超出停止时限，已强制终止", "id", result.Plugin, "status", result.Status, "budget", result.Budget, "duration", result.Duration)
+		}
+	}
+	return report
+}
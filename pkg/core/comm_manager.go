@@ -9,6 +9,7 @@ import (
 	"github.com/hashicorp/go-hclog"
 	"github.com/lomehong/kennel/pkg/comm"
 	"github.com/lomehong/kennel/pkg/logging"
+	pluginLib "github.com/lomehong/kennel/pkg/plugin"
 )
 
 // CommManager 管理与服务端的通信
@@ -91,6 +92,22 @@ func (cm *CommManager) Init() error {
 		}
 	}
 
+	// 从配置中读取服务器URL列表，用于故障转移
+	if serverURLs, ok := cm.configManager.Get("server_urls").([]interface{}); ok {
+		for _, item := range serverURLs {
+			if url, ok := item.(string); ok && url != "" {
+				config.ServerURLs = append(config.ServerURLs, url)
+			}
+		}
+		if len(config.ServerURLs) > 0 {
+			config.ServerURL = config.ServerURLs[0]
+			cm.logger.Info("使用配置的服务器URL列表", "urls", config.ServerURLs)
+		}
+	}
+	if strategy := cm.configManager.GetString("endpoint_strategy"); strategy != "" {
+		config.EndpointStrategy = comm.EndpointStrategy(strategy)
+	}
+
 	// 从配置中读取心跳间隔
 	heartbeatInterval := cm.configManager.GetString("heartbeat_interval")
 	if heartbeatInterval != "" {
@@ -99,6 +116,18 @@ func (cm *CommManager) Init() error {
 		}
 	}
 
+	// 从配置中读取自适应心跳间隔的上下限
+	if minInterval := cm.configManager.GetString("heartbeat_min_interval"); minInterval != "" {
+		if interval, err := time.ParseDuration(minInterval); err == nil {
+			config.HeartbeatMinInterval = interval
+		}
+	}
+	if maxInterval := cm.configManager.GetString("heartbeat_max_interval"); maxInterval != "" {
+		if interval, err := time.ParseDuration(maxInterval); err == nil {
+			config.HeartbeatMaxInterval = interval
+		}
+	}
+
 	// 从配置中读取重连间隔
 	reconnectInterval := cm.configManager.GetString("reconnect_interval")
 	if reconnectInterval != "" {
@@ -107,6 +136,43 @@ func (cm *CommManager) Init() error {
 		}
 	}
 
+	// 从配置中读取断开连接时的关闭超时
+	closeTimeout := cm.configManager.GetString("close_timeout")
+	if closeTimeout != "" {
+		if timeout, err := time.ParseDuration(closeTimeout); err == nil {
+			config.CloseTimeout = timeout
+		}
+	}
+
+	// 从配置中读取消息序号状态文件，用于重启后延续序号和已确认位置
+	config.SequenceStateFile = cm.configManager.GetString("sequence_state_file")
+	if config.SequenceStateFile == "" {
+		config.SequenceStateFile = "data/comm/sequence.json"
+	}
+
+	// 从配置中读取消息处理协程上限和待处理队列长度
+	if workers := cm.configManager.GetInt("handler_workers"); workers > 0 {
+		config.HandlerWorkers = workers
+	}
+	if queueSize := cm.configManager.GetInt("handler_queue_size"); queueSize > 0 {
+		config.HandlerQueueSize = queueSize
+	}
+
+	// 从配置中读取升级握手时请求的WebSocket子协议和附加的HTTP头，用于通过要求特定子协议或认证头的反向代理
+	if subprotocols, ok := cm.configManager.Get("subprotocols").([]interface{}); ok {
+		for _, item := range subprotocols {
+			if subprotocol, ok := item.(string); ok && subprotocol != "" {
+				config.Subprotocols = append(config.Subprotocols, subprotocol)
+			}
+		}
+	}
+	if headers := cm.configManager.GetStringMap("headers"); len(headers) > 0 {
+		config.Headers = make(map[string]string, len(headers))
+		for name, value := range headers {
+			config.Headers[name] = fmt.Sprint(value)
+		}
+	}
+
 	// 从配置中读取最大重连次数
 	maxReconnectAttempts := cm.configManager.GetInt("max_reconnect_attempts")
 	if maxReconnectAttempts > 0 {
@@ -326,8 +392,10 @@ func (cm *CommManager) getClientInfo() map[string]interface{} {
 
 // registerDefaultHandlers 注册默认的消息处理函数
 func (cm *CommManager) registerDefaultHandlers() {
-	// 注册命令消息处理函数
-	cm.manager.RegisterHandler(comm.MessageTypeCommand, cm.handleCommand)
+	// 注册命令处理函数，执行结果作为关联响应发回服务端
+	cm.manager.RegisterCommandHandler("execute_plugin", cm.handlePluginCommand)
+	cm.manager.RegisterCommandHandler("restart", cm.handleRestartCommand)
+	cm.manager.RegisterCommandHandler("update", cm.handleUpdateCommand)
 
 	// 注册数据消息处理函数
 	cm.manager.RegisterHandler(comm.MessageTypeData, cm.handleData)
@@ -336,30 +404,6 @@ func (cm *CommManager) registerDefaultHandlers() {
 	cm.manager.RegisterHandler(comm.MessageTypeEvent, cm.handleEvent)
 }
 
-// handleCommand 处理命令消息
-func (cm *CommManager) handleCommand(msg *comm.Message) {
-	command, ok := msg.Payload["command"].(string)
-	if !ok {
-		cm.logger.Warn("收到无效的命令消息")
-		return
-	}
-
-	params, _ := msg.Payload["params"].(map[string]interface{})
-	cm.logger.Info("收到命令", "command", command)
-
-	// 根据命令类型路由到对应的插件
-	switch command {
-	case "execute_plugin":
-		cm.handlePluginCommand(params, msg.ID)
-	case "restart":
-		cm.handleRestartCommand(params)
-	case "update":
-		cm.handleUpdateCommand(params)
-	default:
-		cm.logger.Info("未知命令", "command", command)
-	}
-}
-
 // handleData 处理数据消息
 func (cm *CommManager) handleData(msg *comm.Message) {
 	dataType, ok := msg.Payload["type"].(string)
@@ -383,17 +427,15 @@ func (cm *CommManager) handleEvent(msg *comm.Message) {
 }
 
 // handlePluginCommand 处理插件命令
-func (cm *CommManager) handlePluginCommand(params map[string]interface{}, messageID string) {
+func (cm *CommManager) handlePluginCommand(msg *comm.Message, params map[string]interface{}) (interface{}, error) {
 	pluginName, ok := params["plugin"].(string)
 	if !ok {
-		cm.logger.Warn("缺少插件名称")
-		return
+		return nil, fmt.Errorf("缺少插件名称")
 	}
 
 	action, ok := params["action"].(string)
 	if !ok {
-		cm.logger.Warn("缺少操作名称")
-		return
+		return nil, fmt.Errorf("缺少操作名称")
 	}
 
 	actionParams, _ := params["params"].(map[string]interface{})
@@ -405,38 +447,40 @@ func (cm *CommManager) handlePluginCommand(params map[string]interface{}, messag
 	pluginManager := GetPluginManager()
 	if pluginManager == nil {
 		cm.logger.Error("无法获取插件管理器")
-		return
+		return nil, fmt.Errorf("无法获取插件管理器")
 	}
 
 	// 获取插件
 	plugin, ok := pluginManager.GetPlugin(pluginName)
 	if !ok {
-		cm.logger.Warn("插件未找到", "plugin", pluginName)
-		return
+		return nil, fmt.Errorf("插件未找到: %s", pluginName)
 	}
 
-	// 执行插件操作
-	cm.logger.Info("执行插件操作", "plugin", pluginName, "action", action)
-	result, err := plugin.Execute(action, actionParams)
+	// 执行插件操作，消息ID作为请求ID传递给插件，用于关联主机和插件日志
+	cm.logger.Info("执行插件操作", "plugin", pluginName, "action", action, "request_id", msg.ID)
+	result, err := pluginLib.ExecuteWithMetadata(plugin, action, actionParams, map[string]string{
+		"request_id": msg.ID,
+	})
 	if err != nil {
 		cm.logger.Error("执行插件操作失败", "plugin", pluginName, "action", action, "error", err)
-		return
+		return nil, fmt.Errorf("执行插件操作失败: %w", err)
 	}
 
-	// 发送响应
-	cm.SendResponse(messageID, true, result, "")
+	return result, nil
 }
 
 // handleRestartCommand 处理重启命令
-func (cm *CommManager) handleRestartCommand(params map[string]interface{}) {
+func (cm *CommManager) handleRestartCommand(msg *comm.Message, params map[string]interface{}) (interface{}, error) {
 	cm.logger.Info("收到重启命令")
 	// TODO: 实现重启逻辑
+	return nil, fmt.Errorf("暂不支持重启命令")
 }
 
 // handleUpdateCommand 处理更新命令
-func (cm *CommManager) handleUpdateCommand(params map[string]interface{}) {
+func (cm *CommManager) handleUpdateCommand(msg *comm.Message, params map[string]interface{}) (interface{}, error) {
 	cm.logger.Info("收到更新命令")
 	// TODO: 实现更新逻辑
+	return nil, fmt.Errorf("暂不支持更新命令")
 }
 
 // SendResponse 发送响应消息
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultMaxBackups 默认保留的配置备份数量
+const DefaultMaxBackups = 5
+
+// backupSuffix 备份文件后缀
+const backupSuffix = ".bak"
+
+// backupTimeFormat 备份文件名中的时间戳格式，按字典序排序即按时间排序
+const backupTimeFormat = "20060102T150405.000000000"
+
+// BackupInfo 配置备份信息
+type BackupInfo struct {
+	// 备份文件路径
+	Path string `json:"path"`
+
+	// 备份时间
+	Time time.Time `json:"time"`
+
+	// 文件大小
+	Size int64 `json:"size"`
+}
+
+// WriteFileAtomic 原子写入文件
+// 数据先写入同目录下的临时文件并同步到磁盘，再重命名为目标文件，
+// 写入过程中崩溃时目标文件要么保持旧内容，要么是完整的新内容
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	// 任何一步失败都清理临时文件
+	success := false
+	defer func() {
+		if !success {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("同步临时文件失败: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("关闭临时文件失败: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("设置文件权限失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("替换目标文件失败: %w", err)
+	}
+	success = true
+
+	// 同步目录，确保重命名本身持久化；部分平台不支持对目录执行同步，忽略错误
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// BackupFile 将文件复制为带时间戳的备份，并只保留最新的 maxBackups 个备份
+// 备份保存在 backupDir 中，为空时与原文件位于同一目录；maxBackups 小于等于0时不删除旧备份；
+// 原文件不存在时返回空路径
+func BackupFile(path, backupDir string, maxBackups int, now time.Time) (string, error) {
+	src, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("打开配置文件失败: %w", err)
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return "", fmt.Errorf("读取配置文件信息失败: %w", err)
+	}
+
+	if backupDir == "" {
+		backupDir = filepath.Dir(path)
+	}
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return "", fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return "", fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	backupPath := filepath.Join(backupDir, filepath.Base(path)+"."+now.UTC().Format(backupTimeFormat)+backupSuffix)
+	if err := WriteFileAtomic(backupPath, data, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("写入备份文件失败: %w", err)
+	}
+
+	if err := rotateBackups(path, backupDir, maxBackups); err != nil {
+		return backupPath, err
+	}
+	return backupPath, nil
+}
+
+// ListBackups 列出文件的全部备份，按时间从新到旧排序
+func ListBackups(path, backupDir string) ([]BackupInfo, error) {
+	if backupDir == "" {
+		backupDir = filepath.Dir(path)
+	}
+
+	entries, err := os.ReadDir(backupDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取备份目录失败: %w", err)
+	}
+
+	prefix := filepath.Base(path) + "."
+	backups := make([]BackupInfo, 0)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), backupSuffix))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupInfo{
+			Path: filepath.Join(backupDir, name),
+			Time: t,
+			Size: info.Size(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+	return backups, nil
+}
+
+// RestoreBackup 使用备份文件原子替换目标文件
+func RestoreBackup(path, backupPath string) error {
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return fmt.Errorf("读取备份文件失败: %w", err)
+	}
+
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return WriteFileAtomic(path, data, perm)
+}
+
+// rotateBackups 删除超出保留数量的旧备份
+func rotateBackups(path, backupDir string, maxBackups int) error {
+	if maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := ListBackups(path, backupDir)
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups[min(maxBackups, len(backups)):] {
+		if err := os.Remove(backup.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除旧备份失败: %w", err)
+		}
+	}
+	return nil
+}
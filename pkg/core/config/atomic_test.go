@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWriteFileAtomic 测试原子写入替换目标文件且不留下临时文件
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+
+	if err := os.WriteFile(path, []byte("version: 1\n"), 0600); err != nil {
+		t.Fatalf("写入初始配置失败: %v", err)
+	}
+	before, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("读取文件信息失败: %v", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("version: 2\n"), 0640); err != nil {
+		t.Fatalf("原子写入失败: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取配置失败: %v", err)
+	}
+	if string(data) != "version: 2\n" {
+		t.Errorf("配置内容应为新内容，实际为 %q", data)
+	}
+
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("读取文件信息失败: %v", err)
+	}
+	// 原子替换通过重命名完成，目标文件是新文件而不是原文件被就地改写
+	if os.SameFile(before, after) {
+		t.Error("目标文件应被重命名替换，而不是就地写入")
+	}
+	if after.Mode().Perm() != 0640 {
+		t.Errorf("文件权限应为 0640，实际为 %v", after.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("读取目录失败: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("目录中应只有目标文件，实际有 %d 个文件", len(entries))
+	}
+
+	// 目标目录不存在时写入失败，且不会产生任何文件
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "config.yaml"), []byte("x"), 0644); err == nil {
+		t.Error("目标目录不存在时应该返回错误")
+	}
+}
+
+// TestBackupFileRotation 测试备份按配置数量轮转
+func TestBackupFileRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	backupDir := filepath.Join(dir, "backups")
+
+	// 原文件不存在时不产生备份
+	backupPath, err := BackupFile(path, backupDir, 3, time.Now())
+	if err != nil || backupPath != "" {
+		t.Fatalf("原文件不存在时不应产生备份: %q, %v", backupPath, err)
+	}
+
+	base := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		content := []byte("version: " + string(rune('0'+i)) + "\n")
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("写入配置失败: %v", err)
+		}
+		if _, err := BackupFile(path, backupDir, 3, base.Add(time.Duration(i)*time.Second)); err != nil {
+			t.Fatalf("备份配置失败: %v", err)
+		}
+	}
+
+	backups, err := ListBackups(path, backupDir)
+	if err != nil {
+		t.Fatalf("列出备份失败: %v", err)
+	}
+	if len(backups) != 3 {
+		t.Fatalf("应保留3个备份，实际为 %d", len(backups))
+	}
+	for i, backup := range backups {
+		want := base.Add(time.Duration(4-i) * time.Second)
+		if !backup.Time.Equal(want) {
+			t.Errorf("第%d个备份时间应为 %v，实际为 %v", i, want, backup.Time)
+		}
+	}
+
+	data, err := os.ReadFile(backups[0].Path)
+	if err != nil {
+		t.Fatalf("读取备份失败: %v", err)
+	}
+	if string(data) != "version: 4\n" {
+		t.Errorf("最新备份内容应为 version: 4，实际为 %q", data)
+	}
+}
+
+// TestConfigManagerSaveBackupAndRestore 测试保存配置时备份并从备份恢复
+func TestConfigManagerSaveBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("global:\n  app:\n    name: original\n"), 0644); err != nil {
+		t.Fatalf("写入初始配置失败: %v", err)
+	}
+
+	cm, err := NewConfigManager(WithConfigPath(path), WithConfigBackups(2))
+	if err != nil {
+		t.Fatalf("创建配置管理器失败: %v", err)
+	}
+	defer cm.Close()
+
+	for _, name := range []string{"first", "second", "third"} {
+		cm.SetGlobalConfig(map[string]interface{}{"app": map[string]interface{}{"name": name}})
+		if err := cm.Save(); err != nil {
+			t.Fatalf("保存配置失败: %v", err)
+		}
+	}
+
+	backups, err := cm.ListBackups()
+	if err != nil {
+		t.Fatalf("列出备份失败: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("应保留2个备份，实际为 %d", len(backups))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("读取目录失败: %v", err)
+	}
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp-") {
+			t.Errorf("不应残留临时文件: %s", entry.Name())
+		}
+	}
+
+	// 最新的备份是保存 third 之前的 second
+	if err := cm.RestoreBackup(backups[0].Path); err != nil {
+		t.Fatalf("恢复备份失败: %v", err)
+	}
+	app, _ := cm.GetGlobalConfig()["app"].(map[string]interface{})
+	if app["name"] != "second" {
+		t.Errorf("恢复后应用名称应为 second，实际为 %v", app["name"])
+	}
+
+	// 恢复前的配置也被备份
+	backups, err = cm.ListBackups()
+	if err != nil {
+		t.Fatalf("列出备份失败: %v", err)
+	}
+	data, err := os.ReadFile(backups[0].Path)
+	if err != nil {
+		t.Fatalf("读取备份失败: %v", err)
+	}
+	if !strings.Contains(string(data), "third") {
+		t.Errorf("恢复前的配置应被备份，实际为 %q", data)
+	}
+
+	if err := cm.RestoreBackup(filepath.Join(dir, "other.yaml")); err == nil {
+		t.Error("恢复不存在的备份应该返回错误")
+	}
+}
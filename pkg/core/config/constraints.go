@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ConstraintType 跨字段约束类型
+type ConstraintType string
+
+const (
+	// ConstraintRequires 配置了字段时必须同时配置依赖字段
+	ConstraintRequires ConstraintType = "requires"
+	// ConstraintConflicts 互斥字段不能同时配置
+	ConstraintConflicts ConstraintType = "conflicts"
+	// ConstraintAtLeastOne 一组字段中至少配置一个
+	ConstraintAtLeastOne ConstraintType = "at_least_one"
+)
+
+// FieldConstraint 跨字段约束
+// 字段名支持用点号访问嵌套配置，如 interceptor_config.mode
+type FieldConstraint struct {
+	Type   ConstraintType
+	Field  string   // 触发约束的字段，at_least_one 约束不使用
+	Fields []string // 依赖、互斥或候选字段
+}
+
+// FieldError 字段级验证错误
+type FieldError struct {
+	Section    string         // 配置节，如插件ID
+	Field      string         // 出错的字段
+	Constraint ConstraintType // 违反的约束类型
+	Message    string         // 错误消息
+}
+
+// Error 实现error接口
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s 配置字段 %s: %s", e.Section, e.Field, e.Message)
+}
+
+// FieldErrors 字段级验证错误列表
+type FieldErrors []*FieldError
+
+// Error 实现error接口
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// validateConstraints 验证跨字段约束，返回所有违反约束的字段错误
+func (v *PluginConfigValidator) validateConstraints(section map[string]interface{}) FieldErrors {
+	var errs FieldErrors
+	for _, constraint := range v.Constraints {
+		switch constraint.Type {
+		case ConstraintRequires:
+			if !isFieldSet(section, constraint.Field) {
+				continue
+			}
+			for _, field := range constraint.Fields {
+				if !isFieldSet(section, field) {
+					errs = append(errs, &FieldError{
+						Section:    v.PluginID,
+						Field:      field,
+						Constraint: constraint.Type,
+						Message:    fmt.Sprintf("启用 %s 时必须配置 %s", constraint.Field, field),
+					})
+				}
+			}
+
+		case ConstraintConflicts:
+			if !isFieldSet(section, constraint.Field) {
+				continue
+			}
+			for _, field := range constraint.Fields {
+				if isFieldSet(section, field) {
+					errs = append(errs, &FieldError{
+						Section:    v.PluginID,
+						Field:      field,
+						Constraint: constraint.Type,
+						Message:    fmt.Sprintf("%s 与 %s 不能同时启用", field, constraint.Field),
+					})
+				}
+			}
+
+		case ConstraintAtLeastOne:
+			satisfied := false
+			for _, field := range constraint.Fields {
+				if isFieldSet(section, field) {
+					satisfied = true
+					break
+				}
+			}
+			if !satisfied {
+				errs = append(errs, &FieldError{
+					Section:    v.PluginID,
+					Field:      strings.Join(constraint.Fields, ","),
+					Constraint: constraint.Type,
+					Message:    fmt.Sprintf("至少需要启用以下字段之一: %s", strings.Join(constraint.Fields, ", ")),
+				})
+			}
+		}
+	}
+	return errs
+}
+
+// isFieldSet 检查字段是否已配置
+// 不存在的字段、nil、false、空字符串和空集合视为未配置，数值只要存在即视为已配置
+func isFieldSet(section map[string]interface{}, field string) bool {
+	var value interface{} = section
+	for _, key := range strings.Split(field, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if value, ok = m[key]; !ok {
+			return false
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return reflect.ValueOf(value).Len() > 0
+	}
+	return true
+}
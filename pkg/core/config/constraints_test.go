@@ -0,0 +1,122 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+// newConstraintTestValidator 创建声明了各类跨字段约束的验证器
+func newConstraintTestValidator() *PluginConfigValidator {
+	return NewPluginConfigValidator("capture").
+		AddRequires("monitor_network", "capture.backend", "capture.interface").
+		AddConflicts("monitor_only", "block").
+		AddAtLeastOne("monitor_network", "monitor_files", "monitor_clipboard")
+}
+
+// constraintTestConfig 包装插件配置
+func constraintTestConfig(section map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"plugins": map[string]interface{}{
+			"capture": section,
+		},
+	}
+}
+
+// validateFieldErrors 验证配置并返回字段错误
+func validateFieldErrors(t *testing.T, validator *PluginConfigValidator, section map[string]interface{}) FieldErrors {
+	t.Helper()
+	err := validator.Validate(constraintTestConfig(section))
+	if err == nil {
+		t.Fatal("应该检测到违反约束的配置")
+	}
+	var fieldErrs FieldErrors
+	if !errors.As(err, &fieldErrs) {
+		t.Fatalf("期望字段错误，实际: %v", err)
+	}
+	return fieldErrs
+}
+
+func TestConstraintsValidConfig(t *testing.T) {
+	validator := newConstraintTestValidator()
+
+	section := map[string]interface{}{
+		"monitor_network": true,
+		"monitor_only":    true,
+		"block":           false,
+		"capture": map[string]interface{}{
+			"backend":   "windivert",
+			"interface": "eth0",
+		},
+	}
+	if err := validator.Validate(constraintTestConfig(section)); err != nil {
+		t.Errorf("有效配置验证失败: %v", err)
+	}
+}
+
+func TestConstraintRequires(t *testing.T) {
+	validator := newConstraintTestValidator()
+
+	fieldErrs := validateFieldErrors(t, validator, map[string]interface{}{
+		"monitor_network": true,
+		"capture": map[string]interface{}{
+			"backend": "",
+		},
+	})
+	if len(fieldErrs) != 2 {
+		t.Fatalf("期望2个字段错误，实际 %d: %v", len(fieldErrs), fieldErrs)
+	}
+	for i, field := range []string{"capture.backend", "capture.interface"} {
+		if fieldErrs[i].Field != field || fieldErrs[i].Constraint != ConstraintRequires {
+			t.Errorf("第%d个错误期望 %s/%s，实际 %s/%s", i, field, ConstraintRequires, fieldErrs[i].Field, fieldErrs[i].Constraint)
+		}
+		if fieldErrs[i].Section != "capture" {
+			t.Errorf("错误配置节期望 capture，实际 %s", fieldErrs[i].Section)
+		}
+	}
+}
+
+func TestConstraintConflicts(t *testing.T) {
+	validator := newConstraintTestValidator()
+
+	fieldErrs := validateFieldErrors(t, validator, map[string]interface{}{
+		"monitor_files": true,
+		"monitor_only":  true,
+		"block":         true,
+	})
+	if len(fieldErrs) != 1 {
+		t.Fatalf("期望1个字段错误，实际 %d: %v", len(fieldErrs), fieldErrs)
+	}
+	if fieldErrs[0].Field != "block" || fieldErrs[0].Constraint != ConstraintConflicts {
+		t.Errorf("期望 block 字段的互斥错误，实际 %s/%s", fieldErrs[0].Field, fieldErrs[0].Constraint)
+	}
+}
+
+func TestConstraintAtLeastOne(t *testing.T) {
+	validator := newConstraintTestValidator()
+
+	fieldErrs := validateFieldErrors(t, validator, map[string]interface{}{
+		"monitor_network":   false,
+		"monitor_clipboard": false,
+	})
+	if len(fieldErrs) != 1 {
+		t.Fatalf("期望1个字段错误，实际 %d: %v", len(fieldErrs), fieldErrs)
+	}
+	if fieldErrs[0].Field != "monitor_network,monitor_files,monitor_clipboard" || fieldErrs[0].Constraint != ConstraintAtLeastOne {
+		t.Errorf("期望至少一个约束错误，实际 %s/%s", fieldErrs[0].Field, fieldErrs[0].Constraint)
+	}
+}
+
+func TestConstraintsReportAllViolations(t *testing.T) {
+	validator := newConstraintTestValidator()
+
+	fieldErrs := validateFieldErrors(t, validator, map[string]interface{}{
+		"monitor_only": true,
+		"block":        true,
+	})
+	if len(fieldErrs) != 2 {
+		t.Fatalf("期望同时报告互斥和至少一个约束错误，实际 %d: %v", len(fieldErrs), fieldErrs)
+	}
+	if fieldErrs[0].Constraint != ConstraintConflicts || fieldErrs[1].Constraint != ConstraintAtLeastOne {
+		t.Errorf("约束错误顺序应与声明顺序一致，实际 %s, %s", fieldErrs[0].Constraint, fieldErrs[1].Constraint)
+	}
+}
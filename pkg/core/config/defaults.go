@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultsRegistry 组件默认值登记表
+// 组件在点分隔的配置键下登记默认值，加载配置时默认值位于配置文件和环境变量之下，
+// 映射递归合并，配置文件中已有的值优先
+type DefaultsRegistry struct {
+	// 按登记顺序保存的默认值
+	entries []defaultsEntry
+
+	// 互斥锁
+	mu sync.RWMutex
+}
+
+// defaultsEntry 一个组件登记的默认值
+type defaultsEntry struct {
+	component string
+	key       string
+	values    map[string]interface{}
+}
+
+// NewDefaultsRegistry 创建默认值登记表
+func NewDefaultsRegistry() *DefaultsRegistry {
+	return &DefaultsRegistry{
+		entries: make([]defaultsEntry, 0),
+	}
+}
+
+// Register 登记组件默认值，key 为默认值所在的点分隔配置键，如 plugins.dlp，为空表示根
+// 同一个键只能登记一次；values 中的切片统一转换为 []interface{}，与解析配置文件的结果一致
+func (r *DefaultsRegistry) Register(component, key string, values map[string]interface{}) error {
+	if component == "" {
+		return fmt.Errorf("登记默认值缺少组件名称")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range r.entries {
+		if entry.key == key {
+			return fmt.Errorf("配置键 %q 的默认值已由组件 %s 登记", key, entry.component)
+		}
+	}
+
+	r.entries = append(r.entries, defaultsEntry{
+		component: component,
+		key:       key,
+		values:    normalizeDefaults(values),
+	})
+	return nil
+}
+
+// RegisterValidator 登记插件配置验证器中的默认值，配置键为 plugins.<插件ID>
+func (r *DefaultsRegistry) RegisterValidator(validator *PluginConfigValidator) error {
+	return r.Register(validator.PluginID, joinConfigKey("plugins", validator.PluginID), validator.Defaults)
+}
+
+// Defaults 返回指定配置键下合并后的默认值副本，没有默认值时返回空映射
+func (r *DefaultsRegistry) Defaults(key string) map[string]interface{} {
+	node := r.Layer()
+	if key == "" {
+		return node
+	}
+	for _, part := range strings.Split(key, ".") {
+		next, ok := node[part].(map[string]interface{})
+		if !ok {
+			return make(map[string]interface{})
+		}
+		node = next
+	}
+	return node
+}
+
+// Layer 返回全部默认值合并后的配置层副本
+func (r *DefaultsRegistry) Layer() map[string]interface{} {
+	layer := make(map[string]interface{})
+	r.merge(layer, make(map[string]Source))
+	return layer
+}
+
+// Apply 将默认值合并到配置之下，返回新的配置，不修改 config
+func (r *DefaultsRegistry) Apply(config map[string]interface{}) map[string]interface{} {
+	result := r.Layer()
+	mergeConfigLayer(result, config, "", Source{Type: SourceTypeFile}, make(map[string]Source))
+	return result
+}
+
+// merge 按登记顺序将默认值合并到 dst，来源记录为登记默认值的组件
+func (r *DefaultsRegistry) merge(dst map[string]interface{}, sources map[string]Source) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, entry := range r.entries {
+		layer := copyMap(entry.values)
+		if entry.key != "" {
+			parts := strings.Split(entry.key, ".")
+			for i := len(parts) - 1; i >= 0; i-- {
+				layer = map[string]interface{}{parts[i]: layer}
+			}
+		}
+		mergeConfigLayer(dst, layer, "", Source{Type: SourceTypeDefault, Name: entry.component}, sources)
+	}
+}
+
+// normalizeDefaults 复制默认值，并将各种类型的切片转换为 []interface{}
+func normalizeDefaults(values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		result[k] = normalizeDefaultValue(v)
+	}
+	return result
+}
+
+// normalizeDefaultValue 规范化单个默认值
+func normalizeDefaultValue(value interface{}) interface{} {
+	switch val := value.(type) {
+	case map[string]interface{}:
+		return normalizeDefaults(val)
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = normalizeDefaultValue(item)
+		}
+		return result
+	case []byte:
+		return string(val)
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return value
+	}
+	result := make([]interface{}, rv.Len())
+	for i := range result {
+		result[i] = normalizeDefaultValue(rv.Index(i).Interface())
+	}
+	return result
+}
+
+// registeredDefaults 全局默认值登记表，预先登记内置组件的默认值
+var registeredDefaults = newBuiltinDefaults()
+
+// RegisterDefaults 在全局登记表中登记组件默认值
+func RegisterDefaults(component, key string, values map[string]interface{}) error {
+	return registeredDefaults.Register(component, key, values)
+}
+
+// RegisteredDefaults 返回全局默认值登记表
+func RegisteredDefaults() *DefaultsRegistry {
+	return registeredDefaults
+}
+
+// newBuiltinDefaults 创建登记了内置插件默认值的登记表
+func newBuiltinDefaults() *DefaultsRegistry {
+	registry := NewDefaultsRegistry()
+
+	validators := GetAllPluginValidators()
+	ids := make([]string, 0, len(validators))
+	for id := range validators {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := registry.RegisterValidator(validators[id]); err != nil {
+			panic(err)
+		}
+	}
+
+	// 示例插件 hello 的设置
+	if err := registry.Register("hello", "plugins.hello.settings", map[string]interface{}{
+		"message":       "Hello, World!",
+		"interval":      5,
+		"debug_port":    8080,
+		"debug_enabled": false,
+	}); err != nil {
+		panic(err)
+	}
+
+	return registry
+}
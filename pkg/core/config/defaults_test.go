@@ -0,0 +1,187 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestDefaults 创建测试用的默认值登记表
+func newTestDefaults(t *testing.T) *DefaultsRegistry {
+	t.Helper()
+
+	registry := NewDefaultsRegistry()
+	if err := registry.Register("core", "global", map[string]interface{}{
+		"logging": map[string]interface{}{
+			"level": "info",
+			"file":  "logs/app.log",
+		},
+	}); err != nil {
+		t.Fatalf("登记默认值失败: %v", err)
+	}
+	if err := registry.Register("dlp", "plugins.dlp.settings", map[string]interface{}{
+		"monitor_network":   true,
+		"max_concurrency":   4,
+		"network_protocols": []string{"http", "https"},
+	}); err != nil {
+		t.Fatalf("登记默认值失败: %v", err)
+	}
+	return registry
+}
+
+// TestComputeEffectiveWithDefaults 测试默认值填充缺失的键，并被配置文件和环境变量覆盖
+func TestComputeEffectiveWithDefaults(t *testing.T) {
+	path := writeConfigLayer(t, t.TempDir(), "config.yaml", `
+global:
+  logging:
+    level: "warn"
+plugins:
+  dlp:
+    enabled: true
+    settings:
+      network_protocols: ["smtp"]
+`)
+	t.Setenv("APPFW_PLUGINS_DLP_SETTINGS_MAX_CONCURRENCY", "8")
+
+	effective, sources, err := ComputeEffectiveWithDefaults([]string{path}, "APPFW", newTestDefaults(t))
+	if err != nil {
+		t.Fatalf("计算有效配置失败: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"global": map[string]interface{}{
+			"logging": map[string]interface{}{
+				"level": "warn",
+				"file":  "logs/app.log",
+			},
+		},
+		"plugins": map[string]interface{}{
+			"dlp": map[string]interface{}{
+				"enabled": true,
+				"settings": map[string]interface{}{
+					"monitor_network":   true,
+					"max_concurrency":   8,
+					"network_protocols": []interface{}{"smtp"},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(effective, expected) {
+		t.Errorf("有效配置不匹配:\n期望 %v\n实际 %v", expected, effective)
+	}
+
+	expectedSources := []Source{
+		{Key: "global.logging.file", Type: SourceTypeDefault, Name: "core", Value: "logs/app.log"},
+		{Key: "global.logging.level", Type: SourceTypeFile, Name: path, Value: "warn"},
+		{Key: "plugins.dlp.enabled", Type: SourceTypeFile, Name: path, Value: true},
+		{Key: "plugins.dlp.settings.max_concurrency", Type: SourceTypeEnv, Name: "APPFW_PLUGINS_DLP_SETTINGS_MAX_CONCURRENCY", Value: 8},
+		{Key: "plugins.dlp.settings.monitor_network", Type: SourceTypeDefault, Name: "dlp", Value: true},
+		{Key: "plugins.dlp.settings.network_protocols", Type: SourceTypeFile, Name: path, Value: []interface{}{"smtp"}},
+	}
+	if !reflect.DeepEqual(sources, expectedSources) {
+		t.Errorf("配置来源不匹配:\n期望 %v\n实际 %v", expectedSources, sources)
+	}
+}
+
+// TestDefaultsRegistry 测试默认值登记、查询和合并
+func TestDefaultsRegistry(t *testing.T) {
+	registry := newTestDefaults(t)
+
+	if err := registry.Register("other", "plugins.dlp.settings", map[string]interface{}{}); err == nil {
+		t.Error("重复登记同一个配置键时应返回错误")
+	}
+	if err := registry.Register("", "plugins.assets", map[string]interface{}{}); err == nil {
+		t.Error("缺少组件名称时应返回错误")
+	}
+
+	settings := registry.Defaults("plugins.dlp.settings")
+	if protocols, ok := settings["network_protocols"].([]interface{}); !ok || len(protocols) != 2 {
+		t.Errorf("切片默认值应转换为 []interface{}，实际为 %#v", settings["network_protocols"])
+	}
+	settings["monitor_network"] = false
+	if registry.Defaults("plugins.dlp.settings")["monitor_network"] != true {
+		t.Error("修改返回的默认值不应影响登记表")
+	}
+	if missing := registry.Defaults("plugins.unknown"); len(missing) != 0 {
+		t.Errorf("未登记的配置键应返回空映射，实际为 %v", missing)
+	}
+
+	config := map[string]interface{}{
+		"plugins": map[string]interface{}{
+			"dlp": map[string]interface{}{
+				"settings": map[string]interface{}{"max_concurrency": 2},
+			},
+		},
+	}
+	applied := registry.Apply(config)
+	dlp := applied["plugins"].(map[string]interface{})["dlp"].(map[string]interface{})["settings"].(map[string]interface{})
+	if dlp["max_concurrency"] != 2 {
+		t.Errorf("配置中的值应覆盖默认值，实际为 %v", dlp["max_concurrency"])
+	}
+	if dlp["monitor_network"] != true {
+		t.Errorf("缺失的键应使用默认值，实际为 %v", dlp["monitor_network"])
+	}
+	if _, ok := config["global"]; ok {
+		t.Error("Apply 不应修改传入的配置")
+	}
+}
+
+// TestRegisteredDefaults 测试内置插件的默认值已登记
+func TestRegisteredDefaults(t *testing.T) {
+	dlp := RegisteredDefaults().Defaults("plugins.dlp")
+	if dlp["log_level"] != "info" || dlp["monitor_files"] != true {
+		t.Errorf("DLP默认值不正确: %v", dlp)
+	}
+	if _, ok := dlp["monitored_file_types"].([]interface{}); !ok {
+		t.Errorf("DLP默认值缺少监控文件类型: %v", dlp)
+	}
+
+	hello := RegisteredDefaults().Defaults("plugins.hello.settings")
+	if hello["message"] != "Hello, World!" || hello["interval"] != 5 {
+		t.Errorf("hello插件默认值不正确: %v", hello)
+	}
+}
+
+// TestConfigManagerDefaults 测试配置管理器加载时合并默认值
+func TestConfigManagerDefaults(t *testing.T) {
+	tempDir := t.TempDir()
+	path := writeConfigLayer(t, tempDir, "config.yaml", `
+global:
+  logging:
+    level: "debug"
+plugins:
+  dlp:
+    settings:
+      max_concurrency: 2
+`)
+
+	cm, err := NewConfigManager(WithConfigPath(path), WithConfigDefaults(newTestDefaults(t)))
+	if err != nil {
+		t.Fatalf("创建配置管理器失败: %v", err)
+	}
+	defer cm.Close()
+
+	logging := cm.GetGlobalConfig()["logging"].(map[string]interface{})
+	if logging["level"] != "debug" {
+		t.Errorf("配置文件中的日志级别应覆盖默认值，实际为 %v", logging["level"])
+	}
+	if logging["file"] != "logs/app.log" {
+		t.Errorf("缺失的日志文件应使用默认值，实际为 %v", logging["file"])
+	}
+
+	settings := cm.GetPluginConfig("dlp")["settings"].(map[string]interface{})
+	if settings["max_concurrency"] != 2 || settings["monitor_network"] != true {
+		t.Errorf("DLP设置合并不正确: %v", settings)
+	}
+
+	// 配置文件不存在时使用默认值
+	missing, err := NewConfigManager(WithConfigPath(filepath.Join(tempDir, "missing.yaml")), WithConfigDefaults(newTestDefaults(t)))
+	if err != nil {
+		t.Fatalf("创建配置管理器失败: %v", err)
+	}
+	defer missing.Close()
+
+	if settings := missing.GetPluginConfig("dlp")["settings"].(map[string]interface{}); settings["max_concurrency"] != 4 {
+		t.Errorf("配置文件不存在时应使用默认值，实际为 %v", settings)
+	}
+}
@@ -0,0 +1,203 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// SourceType 配置值来源类型
+type SourceType string
+
+// 预定义配置值来源类型
+const (
+	SourceTypeDefault SourceType = "default" // 组件登记的默认值
+	SourceTypeFile    SourceType = "file"    // 配置文件
+	SourceTypeEnv     SourceType = "env"     // 环境变量
+)
+
+// Source 有效配置中叶子值的来源
+type Source struct {
+	Key   string      `json:"key"`   // 点分隔的配置键，如 global.logging.level
+	Type  SourceType  `json:"type"`  // 来源类型
+	Name  string      `json:"name"`  // 配置文件路径或环境变量名
+	Value interface{} `json:"value"` // 来源提供的值
+}
+
+// String 返回来源的描述
+func (s Source) String() string {
+	return fmt.Sprintf("%s:%s", s.Type, s.Name)
+}
+
+// ComputeEffective 计算合并后的有效配置
+// 按顺序合并配置文件，后面的文件覆盖前面的文件，映射递归合并，其他值整体替换；
+// 配置文件通过 include 包含的子文件先于该文件合并，来源记录为子文件路径；
+// 然后用环境变量覆盖已有的叶子值，环境变量名为 前缀_键路径 的大写形式，
+// 如前缀 APPFW 时 global.logging.level 对应 APPFW_GLOBAL_LOGGING_LEVEL。
+// 返回有效配置和每个叶子值的来源，来源按键排序
+func ComputeEffective(paths []string, envPrefix string) (map[string]interface{}, []Source, error) {
+	return ComputeEffectiveWithDefaults(paths, envPrefix, nil)
+}
+
+// ComputeEffectiveWithDefaults 计算合并后的有效配置，defaults 中登记的默认值位于配置文件之下，
+// 来源记录为登记默认值的组件；环境变量同样可以覆盖只有默认值的键。defaults 为 nil 时不使用默认值
+func ComputeEffectiveWithDefaults(paths []string, envPrefix string, defaults *DefaultsRegistry) (map[string]interface{}, []Source, error) {
+	effective := make(map[string]interface{})
+	sources := make(map[string]Source)
+
+	if defaults != nil {
+		defaults.merge(effective, sources)
+	}
+
+	for _, path := range paths {
+		layers, err := loadConfigLayers(path, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, layer := range layers {
+			mergeConfigLayer(effective, layer.data, "", Source{Type: SourceTypeFile, Name: layer.path}, sources)
+		}
+	}
+
+	applyEnvOverrides(effective, "", envPrefix, sources)
+
+	result := make([]Source, 0, len(sources))
+	for _, source := range sources {
+		result = append(result, source)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Key < result[j].Key
+	})
+
+	return effective, result, nil
+}
+
+// envKeyReplacer 配置键转换为环境变量名时替换的字符
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// BindEnv 让 viper 按 EnvVarName 的规则从环境变量读取配置，保证运行时与有效配置计算一致
+func BindEnv(v *viper.Viper, envPrefix string) {
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	v.AutomaticEnv()
+}
+
+// EnvVarName 返回配置键对应的环境变量名
+func EnvVarName(envPrefix, key string) string {
+	name := strings.ToUpper(envKeyReplacer.Replace(key))
+	if envPrefix == "" {
+		return name
+	}
+	return strings.ToUpper(envPrefix) + "_" + name
+}
+
+// loadConfigLayer 读取一个配置文件，按扩展名选择 JSON 或 YAML 格式
+func loadConfigLayer(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败 %s: %w", path, err)
+	}
+
+	var layer map[string]interface{}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, &layer); err != nil {
+			return nil, fmt.Errorf("解析JSON配置失败 %s: %w", path, err)
+		}
+	} else {
+		if err := yaml.Unmarshal(data, &layer); err != nil {
+			return nil, fmt.Errorf("解析YAML配置失败 %s: %w", path, err)
+		}
+	}
+
+	if layer == nil {
+		layer = make(map[string]interface{})
+	}
+	return layer, nil
+}
+
+// mergeConfigLayer 将配置层合并到有效配置，并按 origin 的类型和名称记录叶子值来源
+func mergeConfigLayer(dst, src map[string]interface{}, prefix string, origin Source, sources map[string]Source) {
+	for k, v := range src {
+		key := joinConfigKey(prefix, k)
+
+		if srcMap, ok := v.(map[string]interface{}); ok {
+			dstMap, ok := dst[k].(map[string]interface{})
+			if !ok {
+				// 映射替换了非映射值
+				dstMap = make(map[string]interface{})
+				dst[k] = dstMap
+				delete(sources, key)
+			}
+			mergeConfigLayer(dstMap, srcMap, key, origin, sources)
+			continue
+		}
+
+		// 非映射值整体替换，原有子键的来源失效
+		removeSources(sources, key+".")
+		switch val := v.(type) {
+		case []interface{}:
+			dst[k] = copySlice(val)
+		default:
+			dst[k] = v
+		}
+		sources[key] = Source{Key: key, Type: origin.Type, Name: origin.Name, Value: dst[k]}
+	}
+}
+
+// applyEnvOverrides 使用环境变量覆盖已有的叶子值
+func applyEnvOverrides(config map[string]interface{}, prefix, envPrefix string, sources map[string]Source) {
+	for k, v := range config {
+		key := joinConfigKey(prefix, k)
+
+		if nested, ok := v.(map[string]interface{}); ok {
+			applyEnvOverrides(nested, key, envPrefix, sources)
+			continue
+		}
+
+		name := EnvVarName(envPrefix, key)
+		raw, exists := os.LookupEnv(name)
+		if !exists {
+			continue
+		}
+
+		value := parseEnvValue(raw)
+		config[k] = value
+		sources[key] = Source{Key: key, Type: SourceTypeEnv, Name: name, Value: value}
+	}
+}
+
+// parseEnvValue 将环境变量值解析为YAML标量，如 true、42、[a, b]，无法解析时保留字符串
+func parseEnvValue(raw string) interface{} {
+	var value interface{}
+	if err := yaml.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+		return raw
+	}
+	if _, ok := value.(map[string]interface{}); ok {
+		return raw
+	}
+	return value
+}
+
+// removeSources 删除指定前缀下的所有来源
+func removeSources(sources map[string]Source, prefix string) {
+	for key := range sources {
+		if strings.HasPrefix(key, prefix) {
+			delete(sources, key)
+		}
+	}
+}
+
+// joinConfigKey 拼接点分隔的配置键
+func joinConfigKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
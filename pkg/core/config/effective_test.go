@@ -0,0 +1,152 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// writeConfigLayer 写入测试配置文件
+func writeConfigLayer(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+	return path
+}
+
+// TestComputeEffective 测试分层配置文件与环境变量合并
+func TestComputeEffective(t *testing.T) {
+	tempDir := t.TempDir()
+
+	base := writeConfigLayer(t, tempDir, "config.yaml", `
+global:
+  app:
+    name: "base-app"
+    version: "1.0.0"
+  logging:
+    level: "info"
+    file: "logs/app.log"
+plugins:
+  dlp:
+    enabled: true
+    settings:
+      monitor_network: true
+      max_concurrency: 4
+      rules:
+        a: 1
+        b: 2
+`)
+	override := writeConfigLayer(t, tempDir, "config.local.yaml", `
+global:
+  app:
+    name: "override-app"
+plugins:
+  dlp:
+    settings:
+      max_concurrency: 8
+      rules: "disabled"
+`)
+	extra := writeConfigLayer(t, tempDir, "extra.json", `{"plugins": {"assets": {"enabled": false}}}`)
+
+	t.Setenv("APPFW_GLOBAL_LOGGING_LEVEL", "debug")
+	t.Setenv("APPFW_PLUGINS_DLP_ENABLED", "false")
+	t.Setenv("APPFW_PLUGINS_DLP_SETTINGS_MAX_CONCURRENCY", "16")
+	// 不存在于配置文件中的键不会被环境变量创建
+	t.Setenv("APPFW_GLOBAL_UNKNOWN", "ignored")
+
+	effective, sources, err := ComputeEffective([]string{base, override, extra}, "APPFW")
+	if err != nil {
+		t.Fatalf("计算有效配置失败: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"global": map[string]interface{}{
+			"app": map[string]interface{}{
+				"name":    "override-app",
+				"version": "1.0.0",
+			},
+			"logging": map[string]interface{}{
+				"level": "debug",
+				"file":  "logs/app.log",
+			},
+		},
+		"plugins": map[string]interface{}{
+			"dlp": map[string]interface{}{
+				"enabled": false,
+				"settings": map[string]interface{}{
+					"monitor_network": true,
+					"max_concurrency": 16,
+					"rules":           "disabled",
+				},
+			},
+			"assets": map[string]interface{}{
+				"enabled": false,
+			},
+		},
+	}
+	if !reflect.DeepEqual(effective, expected) {
+		t.Errorf("有效配置不匹配:\n期望 %v\n实际 %v", expected, effective)
+	}
+
+	expectedSources := []Source{
+		{Key: "global.app.name", Type: SourceTypeFile, Name: override, Value: "override-app"},
+		{Key: "global.app.version", Type: SourceTypeFile, Name: base, Value: "1.0.0"},
+		{Key: "global.logging.file", Type: SourceTypeFile, Name: base, Value: "logs/app.log"},
+		{Key: "global.logging.level", Type: SourceTypeEnv, Name: "APPFW_GLOBAL_LOGGING_LEVEL", Value: "debug"},
+		{Key: "plugins.assets.enabled", Type: SourceTypeFile, Name: extra, Value: false},
+		{Key: "plugins.dlp.enabled", Type: SourceTypeEnv, Name: "APPFW_PLUGINS_DLP_ENABLED", Value: false},
+		{Key: "plugins.dlp.settings.max_concurrency", Type: SourceTypeEnv, Name: "APPFW_PLUGINS_DLP_SETTINGS_MAX_CONCURRENCY", Value: 16},
+		{Key: "plugins.dlp.settings.monitor_network", Type: SourceTypeFile, Name: base, Value: true},
+		{Key: "plugins.dlp.settings.rules", Type: SourceTypeFile, Name: override, Value: "disabled"},
+	}
+	if !reflect.DeepEqual(sources, expectedSources) {
+		t.Errorf("配置来源不匹配:\n期望 %v\n实际 %v", expectedSources, sources)
+	}
+}
+
+// TestComputeEffectiveMissingFile 测试配置文件不存在时返回错误
+func TestComputeEffectiveMissingFile(t *testing.T) {
+	_, _, err := ComputeEffective([]string{filepath.Join(t.TempDir(), "missing.yaml")}, "APPFW")
+	if err == nil {
+		t.Error("配置文件不存在时应返回错误")
+	}
+}
+
+// TestEnvVarName 测试配置键到环境变量名的转换
+func TestEnvVarName(t *testing.T) {
+	tests := map[string]string{
+		"global.logging.level":          "APPFW_GLOBAL_LOGGING_LEVEL",
+		"plugins.test-plugin.enabled":   "APPFW_PLUGINS_TEST_PLUGIN_ENABLED",
+		"plugins.dlp.settings.max_size": "APPFW_PLUGINS_DLP_SETTINGS_MAX_SIZE",
+	}
+	for key, expected := range tests {
+		if name := EnvVarName("appfw", key); name != expected {
+			t.Errorf("键 %s 的环境变量名应为 %s，实际为 %s", key, expected, name)
+		}
+	}
+	if name := EnvVarName("", "global.debug"); name != "GLOBAL_DEBUG" {
+		t.Errorf("无前缀时环境变量名应为 GLOBAL_DEBUG，实际为 %s", name)
+	}
+}
+
+func TestBindEnvMatchesEnvVarName(t *testing.T) {
+	v := viper.New()
+	BindEnv(v, "APPFW")
+
+	for key, value := range map[string]string{
+		"global.logging.level":        "debug",
+		"plugins.test-plugin.enabled": "false",
+		"web_console.port":            "9090",
+	} {
+		t.Setenv(EnvVarName("APPFW", key), value)
+		if actual := v.GetString(key); actual != value {
+			t.Errorf("键 %s 应从环境变量 %s 读取到 %s，实际为 %q", key, EnvVarName("APPFW", key), value, actual)
+		}
+	}
+}
@@ -20,6 +20,7 @@ const (
 	ConfigErrorTypeFormatError     ConfigErrorType = "format_error"
 	ConfigErrorTypeConflictError   ConfigErrorType = "conflict_error"
 	ConfigErrorTypeHotReloadError  ConfigErrorType = "hot_reload_error"
+	ConfigErrorTypeIncludeCycle    ConfigErrorType = "include_cycle"
 )
 
 // ConfigError 配置错误
@@ -339,6 +340,11 @@ func (h *ConfigErrorHandler) addSuggestions(err *ConfigError) {
 			"重启应用程序",
 			"检查热更新支持范围",
 		}
+	case ConfigErrorTypeIncludeCycle:
+		err.Suggestions = []string{
+			"检查 include 声明的包含链",
+			"将公共配置提取到不再包含其他文件的子文件中",
+		}
 	}
 }
 
@@ -2,6 +2,7 @@ package config
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -88,6 +89,18 @@ type HotReloadHandler interface {
 	Rollback(ctx context.Context, config map[string]interface{}) error
 }
 
+// HotReloadChangeValidator 热更新前置校验
+// 处理器可选实现该接口，根据当前运行状态判断从旧配置切换到新配置是否安全，
+// 例如工作池不能缩减到正在使用的工作者数量以下。校验在任何副作用发生前执行，
+// 失败时直接拒绝本次热更新，不会重试和回滚
+type HotReloadChangeValidator interface {
+	// ValidateChange 校验配置变更
+	ValidateChange(oldConfig, newConfig map[string]interface{}) error
+}
+
+// ErrHotReloadRejected 前置校验拒绝了热更新
+var ErrHotReloadRejected = errors.New("热更新被拒绝")
+
 // HotReloadManager 热更新管理器
 type HotReloadManager struct {
 	config   *HotReloadConfig
@@ -193,6 +206,23 @@ func (hrm *HotReloadManager) Reload(reloadType HotReloadType, component string,
 		return err
 	}
 
+	// 前置校验，根据运行状态拒绝不安全的变更
+	if validator, ok := handler.(HotReloadChangeValidator); ok {
+		if err := validator.ValidateChange(oldConfig, newConfig); err != nil {
+			err = fmt.Errorf("%w: 组件 %s: %v", ErrHotReloadRejected, component, err)
+			event.Success = false
+			event.Error = err.Error()
+			event.Duration = time.Since(startTime)
+			hrm.addEvent(event)
+
+			hrm.logger.Warn("热更新前置校验失败", "component", component, "error", err)
+			if hrm.config.NotifyOnFailure {
+				hrm.notifyFailure(event)
+			}
+			return err
+		}
+	}
+
 	// 执行热更新（带重试）
 	var lastErr error
 	for retry := 0; retry <= hrm.config.MaxRetries; retry++ {
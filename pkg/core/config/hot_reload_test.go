@@ -0,0 +1,115 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// poolReloadHandler 工作池热更新处理器，用于测试前置校验
+type poolReloadHandler struct {
+	inUse      int
+	workers    int
+	validated  int
+	reloaded   int
+	rolledBack int
+}
+
+func (h *poolReloadHandler) GetSupportLevel() HotReloadSupport { return HotReloadSupportFull }
+
+func (h *poolReloadHandler) CanReload(oldConfig, newConfig map[string]interface{}) bool { return true }
+
+func (h *poolReloadHandler) Validate(config map[string]interface{}) error {
+	h.validated++
+	if _, ok := config["workers"].(int); !ok {
+		return fmt.Errorf("缺少 workers")
+	}
+	return nil
+}
+
+func (h *poolReloadHandler) ValidateChange(oldConfig, newConfig map[string]interface{}) error {
+	if workers := newConfig["workers"].(int); workers < h.inUse {
+		return fmt.Errorf("工作者数量 %d 小于正在使用的数量 %d", workers, h.inUse)
+	}
+	return nil
+}
+
+func (h *poolReloadHandler) Reload(ctx context.Context, oldConfig, newConfig map[string]interface{}) error {
+	h.reloaded++
+	h.workers = newConfig["workers"].(int)
+	return nil
+}
+
+func (h *poolReloadHandler) Rollback(ctx context.Context, config map[string]interface{}) error {
+	h.rolledBack++
+	return nil
+}
+
+func newTestHotReloadManager() *HotReloadManager {
+	config := DefaultHotReloadConfig()
+	config.RetryInterval = time.Millisecond
+	return NewHotReloadManager(config, hclog.NewNullLogger())
+}
+
+// TestHotReloadValidateChangeRejects 测试前置校验拒绝不安全的变更且没有副作用
+func TestHotReloadValidateChangeRejects(t *testing.T) {
+	manager := newTestHotReloadManager()
+	defer manager.Stop()
+
+	handler := &poolReloadHandler{inUse: 6, workers: 8}
+	manager.RegisterHandler("pool", handler)
+
+	err := manager.Reload(HotReloadTypeGlobal, "pool", "config.yaml",
+		map[string]interface{}{"workers": 8},
+		map[string]interface{}{"workers": 4})
+	if !errors.Is(err, ErrHotReloadRejected) {
+		t.Fatalf("缩减到正在使用的数量以下应被拒绝，实际为 %v", err)
+	}
+	if !strings.Contains(err.Error(), "小于正在使用的数量") {
+		t.Errorf("错误应包含拒绝原因: %v", err)
+	}
+
+	if handler.validated != 0 || handler.reloaded != 0 || handler.rolledBack != 0 {
+		t.Errorf("拒绝时不应执行校验、热更新或回滚: validate=%d reload=%d rollback=%d",
+			handler.validated, handler.reloaded, handler.rolledBack)
+	}
+	if handler.workers != 8 {
+		t.Errorf("拒绝时工作者数量应保持为 8，实际为 %d", handler.workers)
+	}
+
+	events := manager.GetEventsByComponent("pool")
+	if len(events) != 1 || events[0].Success || events[0].Retries != 0 {
+		t.Fatalf("应记录一次未重试的失败事件: %+v", events)
+	}
+}
+
+// TestHotReloadValidateChangeAccepts 测试前置校验通过时正常热更新
+func TestHotReloadValidateChangeAccepts(t *testing.T) {
+	manager := newTestHotReloadManager()
+	defer manager.Stop()
+
+	handler := &poolReloadHandler{inUse: 6, workers: 8}
+	manager.RegisterHandler("pool", handler)
+
+	err := manager.Reload(HotReloadTypeGlobal, "pool", "config.yaml",
+		map[string]interface{}{"workers": 8},
+		map[string]interface{}{"workers": 6})
+	if err != nil {
+		t.Fatalf("安全的变更应被接受: %v", err)
+	}
+	if handler.validated != 1 || handler.reloaded != 1 || handler.rolledBack != 0 {
+		t.Errorf("应执行一次校验和热更新: validate=%d reload=%d rollback=%d",
+			handler.validated, handler.reloaded, handler.rolledBack)
+	}
+	if handler.workers != 6 {
+		t.Errorf("工作者数量应更新为 6，实际为 %d", handler.workers)
+	}
+	if rate := manager.GetSuccessRate(); rate != 1.0 {
+		t.Errorf("成功率应为 1.0，实际为 %v", rate)
+	}
+}
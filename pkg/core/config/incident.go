@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultCorrelationWindow 默认事件关联窗口
+const DefaultCorrelationWindow = 5 * time.Minute
+
+// MonitorIncident 监控事件聚合后的事故
+// 同一组件、同一类型且在关联窗口内连续发生的事件归入同一个事故
+type MonitorIncident struct {
+	ID         string       `json:"id"`
+	Type       MonitorType  `json:"type"`
+	Component  string       `json:"component"`
+	Level      MonitorLevel `json:"level"`
+	Message    string       `json:"message"`
+	FirstSeen  time.Time    `json:"first_seen"`
+	LastSeen   time.Time    `json:"last_seen"`
+	Count      int          `json:"count"`
+	EventIDs   []string     `json:"event_ids"`
+	Alerted    bool         `json:"alerted"`
+	Resolved   bool         `json:"resolved"`
+	ResolvedAt *time.Time   `json:"resolved_at,omitempty"`
+}
+
+// maxIncidentEventIDs 每个事故最多保留的事件ID数量
+const maxIncidentEventIDs = 100
+
+// monitorLevelRank 监控级别的严重程度排序
+var monitorLevelRank = map[MonitorLevel]int{
+	MonitorLevelInfo:     0,
+	MonitorLevelWarning:  1,
+	MonitorLevelError:    2,
+	MonitorLevelCritical: 3,
+}
+
+// isAlertLevel 检查级别是否需要告警
+func isAlertLevel(level MonitorLevel) bool {
+	return level == MonitorLevelError || level == MonitorLevelCritical
+}
+
+// incidentKey 事件关联键
+func incidentKey(eventType MonitorType, component string) string {
+	return string(eventType) + "|" + component
+}
+
+// correlateEvent 将事件归入已有事故或创建新事故，返回事故以及是否需要告警
+// 调用方需持有锁
+func (cm *ConfigMonitor) correlateEvent(event *MonitorEvent) (*MonitorIncident, bool) {
+	key := incidentKey(event.Type, event.Component)
+
+	incident, exists := cm.openIncidents[key]
+	if exists && event.Timestamp.Sub(incident.LastSeen) > cm.correlationWindow {
+		// 超出关联窗口，开始新的事故
+		delete(cm.openIncidents, key)
+		exists = false
+	}
+
+	if !exists {
+		incident = &MonitorIncident{
+			ID:        cm.nextIncidentID(),
+			Type:      event.Type,
+			Component: event.Component,
+			Level:     event.Level,
+			FirstSeen: event.Timestamp,
+		}
+		cm.incidents = append(cm.incidents, incident)
+		cm.openIncidents[key] = incident
+
+		// 限制事故数量
+		if cm.maxEvents > 0 && len(cm.incidents) > cm.maxEvents {
+			cm.removeIncidents(cm.incidents[:len(cm.incidents)-cm.maxEvents])
+			cm.incidents = cm.incidents[len(cm.incidents)-cm.maxEvents:]
+		}
+	}
+
+	incident.Count++
+	incident.LastSeen = event.Timestamp
+	incident.Message = event.Message
+	if monitorLevelRank[event.Level] > monitorLevelRank[incident.Level] {
+		incident.Level = event.Level
+	}
+	if len(incident.EventIDs) < maxIncidentEventIDs {
+		incident.EventIDs = append(incident.EventIDs, event.ID)
+	}
+	event.IncidentID = incident.ID
+
+	// 每个事故只告警一次，级别升级到错误以上时补发
+	shouldAlert := !incident.Alerted && isAlertLevel(incident.Level)
+	if shouldAlert {
+		incident.Alerted = true
+	}
+
+	return incident, shouldAlert
+}
+
+// GetIncidents 获取事故列表，按最近发生时间倒序排列
+func (cm *ConfigMonitor) GetIncidents() []MonitorIncident {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	incidents := make([]MonitorIncident, 0, len(cm.incidents))
+	for _, incident := range cm.incidents {
+		copied := *incident
+		copied.EventIDs = append([]string(nil), incident.EventIDs...)
+		incidents = append(incidents, copied)
+	}
+
+	sort.SliceStable(incidents, func(i, j int) bool {
+		return incidents[i].LastSeen.After(incidents[j].LastSeen)
+	})
+	return incidents
+}
+
+// ResolveIncident 解决事故，之后的同类事件会创建新的事故
+func (cm *ConfigMonitor) ResolveIncident(incidentID string) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	for _, incident := range cm.incidents {
+		if incident.ID != incidentID {
+			continue
+		}
+		if incident.Resolved {
+			return nil
+		}
+
+		now := time.Now()
+		incident.Resolved = true
+		incident.ResolvedAt = &now
+
+		key := incidentKey(incident.Type, incident.Component)
+		if cm.openIncidents[key] == incident {
+			delete(cm.openIncidents, key)
+		}
+
+		cm.logger.Info("解决监控事故", "incident_id", incidentID, "count", incident.Count)
+		return nil
+	}
+
+	return fmt.Errorf("未找到事故: %s", incidentID)
+}
+
+// cleanupOldIncidents 清理最后发生时间早于cutoff的事故，调用方需持有锁
+func (cm *ConfigMonitor) cleanupOldIncidents(cutoff time.Time) {
+	var kept []*MonitorIncident
+	var removed []*MonitorIncident
+
+	for _, incident := range cm.incidents {
+		if incident.LastSeen.After(cutoff) {
+			kept = append(kept, incident)
+		} else {
+			removed = append(removed, incident)
+		}
+	}
+
+	cm.removeIncidents(removed)
+	cm.incidents = kept
+}
+
+// removeIncidents 从打开的事故中移除指定事故，调用方需持有锁
+func (cm *ConfigMonitor) removeIncidents(incidents []*MonitorIncident) {
+	for _, incident := range incidents {
+		key := incidentKey(incident.Type, incident.Component)
+		if cm.openIncidents[key] == incident {
+			delete(cm.openIncidents, key)
+		}
+	}
+}
+
+// nextIncidentID 生成事故ID，调用方需持有锁
+func (cm *ConfigMonitor) nextIncidentID() string {
+	cm.incidentSeq++
+	return fmt.Sprintf("incident_%d_%d", time.Now().UnixNano(), cm.incidentSeq)
+}
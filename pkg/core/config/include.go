@@ -0,0 +1,177 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IncludeKey 配置文件中声明包含文件的键
+// 取值为单个路径或路径列表，相对路径相对于声明包含的配置文件所在目录，例如：
+//
+//	include:
+//	  - plugins/dlp.yaml
+//	  - plugins/control.yaml
+const IncludeKey = "include"
+
+// configLayer 按合并顺序排列的配置层
+type configLayer struct {
+	path string
+	data map[string]interface{}
+}
+
+// LoadConfigWithIncludes 加载配置文件及其包含的所有子文件并合并
+// 包含的文件按声明顺序先合并，声明包含的文件最后合并并覆盖子文件中的同名配置，
+// 合并规则与 ComputeEffective 相同：映射递归合并，其他值整体替换。
+// 返回合并后的配置和参与合并的所有文件路径
+func LoadConfigWithIncludes(path string) (map[string]interface{}, []string, error) {
+	layers, err := loadConfigLayers(path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	config, files := mergeLayers(layers)
+	return config, files, nil
+}
+
+// ResolveIncludes 展开已解析配置中的包含声明
+// path 为该配置所在的文件路径，用于解析相对路径和检测循环包含。
+// 配置中没有包含声明时原样返回
+func ResolveIncludes(config map[string]interface{}, path string) (map[string]interface{}, []string, error) {
+	if _, ok := config[IncludeKey]; !ok {
+		return config, []string{path}, nil
+	}
+	layers, err := expandIncludes(config, path, []string{absConfigPath(path)})
+	if err != nil {
+		return nil, nil, err
+	}
+	merged, files := mergeLayers(layers)
+	return merged, files, nil
+}
+
+// loadConfigLayers 加载配置文件，递归展开包含的文件，返回按合并顺序排列的配置层
+// stack 为当前的包含链，用于检测循环包含
+func loadConfigLayers(path string, stack []string) ([]configLayer, error) {
+	absPath := absConfigPath(path)
+	for i, included := range stack {
+		if included == absPath {
+			chain := append(append([]string{}, stack[i:]...), absPath)
+			return nil, NewConfigError(
+				ConfigErrorTypeIncludeCycle,
+				"",
+				path,
+				IncludeKey,
+				fmt.Sprintf("检测到配置文件循环包含: %s", strings.Join(chain, " -> ")),
+				nil,
+			)
+		}
+	}
+
+	layer, err := loadConfigLayer(path)
+	if err != nil {
+		return nil, err
+	}
+	return expandIncludes(layer, path, append(stack, absPath))
+}
+
+// expandIncludes 展开配置层中的包含声明，包含声明本身不会出现在合并结果中
+func expandIncludes(layer map[string]interface{}, path string, stack []string) ([]configLayer, error) {
+	includes, err := parseIncludes(layer[IncludeKey], path)
+	if err != nil {
+		return nil, err
+	}
+	delete(layer, IncludeKey)
+
+	var layers []configLayer
+	for _, include := range includes {
+		includePath := include
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(filepath.Dir(path), includePath)
+		}
+
+		if _, err := os.Stat(includePath); err != nil {
+			errorType := ConfigErrorTypePermissionError
+			if os.IsNotExist(err) {
+				errorType = ConfigErrorTypeFileNotFound
+			}
+			return nil, NewConfigError(
+				errorType,
+				"",
+				path,
+				IncludeKey,
+				fmt.Sprintf("包含的配置文件不可用: %s", includePath),
+				err,
+			)
+		}
+
+		included, err := loadConfigLayers(includePath, stack)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, included...)
+	}
+
+	return append(layers, configLayer{path: path, data: layer}), nil
+}
+
+// parseIncludes 解析包含声明，支持单个路径或路径列表
+func parseIncludes(value interface{}, path string) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		includes := make([]string, 0, len(v))
+		for _, item := range v {
+			include, ok := item.(string)
+			if !ok || include == "" {
+				return nil, NewConfigError(
+					ConfigErrorTypeFormatError,
+					"",
+					path,
+					IncludeKey,
+					fmt.Sprintf("包含的配置文件路径必须是非空字符串: %v", item),
+					nil,
+				)
+			}
+			includes = append(includes, include)
+		}
+		return includes, nil
+	default:
+		return nil, NewConfigError(
+			ConfigErrorTypeFormatError,
+			"",
+			path,
+			IncludeKey,
+			fmt.Sprintf("包含声明必须是路径或路径列表，实际类型为 %T", value),
+			nil,
+		)
+	}
+}
+
+// mergeLayers 按顺序合并配置层，返回合并结果和去重后的文件路径
+func mergeLayers(layers []configLayer) (map[string]interface{}, []string) {
+	merged := make(map[string]interface{})
+	sources := make(map[string]Source)
+	seen := make(map[string]bool)
+	var files []string
+
+	for _, layer := range layers {
+		mergeConfigLayer(merged, layer.data, "", Source{Type: SourceTypeFile, Name: layer.path}, sources)
+		if !seen[layer.path] {
+			seen[layer.path] = true
+			files = append(files, layer.path)
+		}
+	}
+	return merged, files
+}
+
+// absConfigPath 返回用于比较的绝对路径，无法解析时返回清理后的原路径
+func absConfigPath(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return absPath
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestLoadConfigWithIncludes 测试嵌套包含的子配置文件按优先级合并
+func TestLoadConfigWithIncludes(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tempDir, "plugins"), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+
+	writeConfigLayer(t, tempDir, "plugins/common.yaml", `
+plugins:
+  dlp:
+    enabled: false
+    settings:
+      max_concurrency: 2
+      ocr: true
+`)
+	writeConfigLayer(t, tempDir, "plugins/dlp.yaml", `
+include: common.yaml
+plugins:
+  dlp:
+    enabled: true
+    settings:
+      max_concurrency: 4
+`)
+	writeConfigLayer(t, tempDir, "plugins/assets.json", `{"plugins": {"assets": {"enabled": true}}}`)
+	main := writeConfigLayer(t, tempDir, "config.yaml", `
+include:
+  - plugins/dlp.yaml
+  - plugins/assets.json
+global:
+  app:
+    name: "kennel"
+plugins:
+  dlp:
+    settings:
+      max_concurrency: 8
+`)
+
+	config, files, err := LoadConfigWithIncludes(main)
+	if err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"global": map[string]interface{}{
+			"app": map[string]interface{}{"name": "kennel"},
+		},
+		"plugins": map[string]interface{}{
+			"dlp": map[string]interface{}{
+				"enabled": true,
+				"settings": map[string]interface{}{
+					"max_concurrency": 8,
+					"ocr":             true,
+				},
+			},
+			"assets": map[string]interface{}{"enabled": true},
+		},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("合并结果错误:\n期望 %#v\n实际 %#v", expected, config)
+	}
+
+	expectedFiles := []string{
+		filepath.Join(tempDir, "plugins", "common.yaml"),
+		filepath.Join(tempDir, "plugins", "dlp.yaml"),
+		filepath.Join(tempDir, "plugins", "assets.json"),
+		main,
+	}
+	if !reflect.DeepEqual(files, expectedFiles) {
+		t.Errorf("参与合并的文件错误: 期望 %v, 实际 %v", expectedFiles, files)
+	}
+
+	// 有效配置的来源记录为实际提供值的子文件
+	_, sources, err := ComputeEffective([]string{main}, "")
+	if err != nil {
+		t.Fatalf("计算有效配置失败: %v", err)
+	}
+	for _, source := range sources {
+		if source.Key == "plugins.dlp.settings.ocr" && source.Name != expectedFiles[0] {
+			t.Errorf("%s 的来源错误: %s", source.Key, source.Name)
+		}
+		if source.Key == IncludeKey {
+			t.Errorf("包含声明不应出现在有效配置中")
+		}
+	}
+}
+
+// TestLoadConfigWithIncludesCycle 测试循环包含返回错误
+func TestLoadConfigWithIncludesCycle(t *testing.T) {
+	tempDir := t.TempDir()
+	main := writeConfigLayer(t, tempDir, "config.yaml", "include: a.yaml\n")
+	writeConfigLayer(t, tempDir, "a.yaml", "include: [b.yaml]\n")
+	writeConfigLayer(t, tempDir, "b.yaml", "include: a.yaml\n")
+
+	_, _, err := LoadConfigWithIncludes(main)
+	if err == nil {
+		t.Fatal("循环包含应返回错误")
+	}
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Type != ConfigErrorTypeIncludeCycle {
+		t.Fatalf("期望循环包含错误, 实际为: %v", err)
+	}
+	if !strings.Contains(err.Error(), "a.yaml -> ") || !strings.Contains(err.Error(), "b.yaml") {
+		t.Errorf("错误信息应包含循环链: %v", err)
+	}
+
+	// 文件包含自身同样是循环
+	self := writeConfigLayer(t, tempDir, "self.yaml", "include: self.yaml\n")
+	if _, _, err := LoadConfigWithIncludes(self); err == nil {
+		t.Error("包含自身应返回错误")
+	}
+}
+
+// TestLoadConfigWithIncludesMissing 测试包含不存在的文件返回错误
+func TestLoadConfigWithIncludesMissing(t *testing.T) {
+	tempDir := t.TempDir()
+	main := writeConfigLayer(t, tempDir, "config.yaml", "include: [plugins/missing.yaml]\n")
+
+	_, _, err := LoadConfigWithIncludes(main)
+	if err == nil {
+		t.Fatal("包含不存在的文件应返回错误")
+	}
+
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Type != ConfigErrorTypeFileNotFound {
+		t.Fatalf("期望文件不存在错误, 实际为: %v", err)
+	}
+	if configErr.ConfigPath != main || !strings.Contains(err.Error(), "missing.yaml") {
+		t.Errorf("错误信息应包含声明包含的文件和缺失的文件: %v", err)
+	}
+
+	// 包含声明格式错误
+	invalid := writeConfigLayer(t, tempDir, "invalid.yaml", "include: {path: a.yaml}\n")
+	if _, _, err := LoadConfigWithIncludes(invalid); err == nil {
+		t.Error("格式错误的包含声明应返回错误")
+	}
+}
+
+// TestConfigManagerLoadWithIncludes 测试配置管理器加载包含的插件配置
+func TestConfigManagerLoadWithIncludes(t *testing.T) {
+	tempDir := t.TempDir()
+	writeConfigLayer(t, tempDir, "dlp.yaml", `
+plugins:
+  dlp:
+    enabled: true
+`)
+	main := writeConfigLayer(t, tempDir, "config.yaml", `
+include: dlp.yaml
+global:
+  log_level: info
+`)
+
+	cm, err := NewConfigManager(WithConfigPath(main))
+	if err != nil {
+		t.Fatalf("创建配置管理器失败: %v", err)
+	}
+	defer cm.Close()
+
+	if enabled, _ := cm.GetPluginConfig("dlp")["enabled"].(bool); !enabled {
+		t.Errorf("包含文件中的插件配置未加载: %v", cm.GetAllPluginConfigs())
+	}
+	if cm.GetGlobalConfig()["log_level"] != "info" {
+		t.Errorf("主配置文件中的全局配置未加载: %v", cm.GetGlobalConfig())
+	}
+}
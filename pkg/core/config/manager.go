@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/hashicorp/go-hclog"
@@ -39,6 +40,12 @@ type ConfigManager struct {
 	// 配置格式
 	format ConfigFormat
 
+	// 保留的配置备份数量，0表示不备份
+	maxBackups int
+
+	// 备份目录，为空时与配置文件位于同一目录
+	backupDir string
+
 	// 配置监视器
 	watcher *fsnotify.Watcher
 
@@ -48,6 +55,9 @@ type ConfigManager struct {
 	// 配置验证器
 	validators []ConfigValidator
 
+	// 组件默认值，为nil时不合并默认值
+	defaults *DefaultsRegistry
+
 	// 日志记录器
 	logger hclog.Logger
 
@@ -87,6 +97,20 @@ func WithConfigFormat(format ConfigFormat) ConfigManagerOption {
 	}
 }
 
+// WithConfigBackups 设置保存配置时保留的备份数量，0表示不备份
+func WithConfigBackups(maxBackups int) ConfigManagerOption {
+	return func(cm *ConfigManager) {
+		cm.maxBackups = maxBackups
+	}
+}
+
+// WithConfigBackupDir 设置配置备份目录
+func WithConfigBackupDir(dir string) ConfigManagerOption {
+	return func(cm *ConfigManager) {
+		cm.backupDir = dir
+	}
+}
+
 // WithConfigLogger 设置日志记录器
 func WithConfigLogger(logger hclog.Logger) ConfigManagerOption {
 	return func(cm *ConfigManager) {
@@ -101,6 +125,13 @@ func WithConfigValidator(validator ConfigValidator) ConfigManagerOption {
 	}
 }
 
+// WithConfigDefaults 设置组件默认值登记表，加载时默认值合并到配置文件之下
+func WithConfigDefaults(defaults *DefaultsRegistry) ConfigManagerOption {
+	return func(cm *ConfigManager) {
+		cm.defaults = defaults
+	}
+}
+
 // WithConfigChangeListener 添加配置变更监听器
 func WithConfigChangeListener(listener ConfigChangeListener) ConfigManagerOption {
 	return func(cm *ConfigManager) {
@@ -122,6 +153,7 @@ func NewConfigManager(options ...ConfigManagerOption) (*ConfigManager, error) {
 		pluginConfigs:       make(map[string]map[string]interface{}),
 		configPath:          "config.yaml",
 		format:              ConfigFormatYAML,
+		maxBackups:          DefaultMaxBackups,
 		watcher:             watcher,
 		listeners:           make([]ConfigChangeListener, 0),
 		validators:          make([]ConfigValidator, 0),
@@ -162,6 +194,9 @@ func (cm *ConfigManager) Load() error {
 	// 检查文件是否存在
 	if _, err := os.Stat(cm.configPath); os.IsNotExist(err) {
 		cm.logger.Warn("配置文件不存在", "path", cm.configPath)
+		if cm.defaults != nil {
+			cm.setSections(cm.defaults.Layer())
+		}
 		return nil
 	}
 
@@ -186,6 +221,17 @@ func (cm *ConfigManager) Load() error {
 		return fmt.Errorf("不支持的配置格式: %s", cm.format)
 	}
 
+	// 展开包含的子配置文件
+	config, includedFiles, err := ResolveIncludes(config, cm.configPath)
+	if err != nil {
+		return fmt.Errorf("加载包含的配置文件失败: %w", err)
+	}
+
+	// 合并组件默认值，配置文件中的值优先
+	if cm.defaults != nil {
+		config = cm.defaults.Apply(config)
+	}
+
 	// 验证配置
 	for _, validator := range cm.validators {
 		if err := validator.Validate(config); err != nil {
@@ -193,6 +239,19 @@ func (cm *ConfigManager) Load() error {
 		}
 	}
 
+	cm.setSections(config)
+
+	// 监视配置文件及其包含的子文件
+	for _, file := range includedFiles {
+		cm.watcher.Add(file)
+	}
+
+	cm.logger.Info("加载配置成功", "path", cm.configPath)
+	return nil
+}
+
+// setSections 从完整配置中提取全局、插件管理和插件配置
+func (cm *ConfigManager) setSections(config map[string]interface{}) {
 	// 提取全局配置
 	if global, ok := config["global"].(map[string]interface{}); ok {
 		cm.globalConfig = global
@@ -215,12 +274,6 @@ func (cm *ConfigManager) Load() error {
 			}
 		}
 	}
-
-	// 监视配置文件
-	cm.watcher.Add(cm.configPath)
-
-	cm.logger.Info("加载配置成功", "path", cm.configPath)
-	return nil
 }
 
 // Save 保存配置
@@ -265,15 +318,71 @@ func (cm *ConfigManager) Save() error {
 		return fmt.Errorf("创建目录失败: %w", err)
 	}
 
-	// 写入文件
-	if err := ioutil.WriteFile(cm.configPath, data, 0644); err != nil {
+	// 备份当前配置文件
+	if cm.maxBackups > 0 {
+		backupPath, err := BackupFile(cm.configPath, cm.backupDir, cm.maxBackups, time.Now())
+		if err != nil {
+			return fmt.Errorf("备份配置文件失败: %w", err)
+		}
+		if backupPath != "" {
+			cm.logger.Debug("备份配置文件", "path", backupPath)
+		}
+	}
+
+	// 原子写入文件
+	if err := WriteFileAtomic(cm.configPath, data, 0644); err != nil {
 		return fmt.Errorf("写入配置文件失败: %w", err)
 	}
 
+	// 重命名替换了原文件，需要重新监视新文件
+	cm.watcher.Add(cm.configPath)
+
 	cm.logger.Info("保存配置成功", "path", cm.configPath)
 	return nil
 }
 
+// ListBackups 列出配置文件的备份，按时间从新到旧排序
+func (cm *ConfigManager) ListBackups() ([]BackupInfo, error) {
+	return ListBackups(cm.configPath, cm.backupDir)
+}
+
+// RestoreBackup 从备份恢复配置文件并重新加载
+// 恢复前会先备份当前配置文件，以便撤销恢复操作
+func (cm *ConfigManager) RestoreBackup(backupPath string) error {
+	backups, err := cm.ListBackups()
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, backup := range backups {
+		if filepath.Clean(backup.Path) == filepath.Clean(backupPath) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("备份不存在: %s", backupPath)
+	}
+
+	// 先读取备份内容，避免备份轮转删除要恢复的文件
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return fmt.Errorf("读取备份文件失败: %w", err)
+	}
+
+	if cm.maxBackups > 0 {
+		if _, err := BackupFile(cm.configPath, cm.backupDir, cm.maxBackups, time.Now()); err != nil {
+			return fmt.Errorf("备份配置文件失败: %w", err)
+		}
+	}
+	if err := WriteFileAtomic(cm.configPath, data, 0644); err != nil {
+		return fmt.Errorf("恢复配置文件失败: %w", err)
+	}
+
+	cm.logger.Info("恢复配置成功", "path", cm.configPath, "backup", backupPath)
+	return cm.Reload()
+}
+
 // watchConfig 监视配置文件变化
 func (cm *ConfigManager) watchConfig() {
 	for {
@@ -313,7 +313,7 @@ func (cm *ConfigMigration) writeNewConfig(config *NewConfig) error {
 		return err
 	}
 
-	return os.WriteFile(cm.targetFile, data, 0644)
+	return WriteFileAtomic(cm.targetFile, data, 0644)
 }
 
 // 辅助函数
@@ -42,6 +42,7 @@ type MonitorEvent struct {
 	Resolved   bool                   `json:"resolved"`
 	ResolvedAt *time.Time             `json:"resolved_at,omitempty"`
 	Tags       []string               `json:"tags"`
+	IncidentID string                 `json:"incident_id,omitempty"`
 }
 
 // MonitorRule 监控规则
@@ -91,6 +92,12 @@ type ConfigMonitor struct {
 	cancel        context.CancelFunc
 	wg            sync.WaitGroup
 
+	// 事件关联
+	incidents         []*MonitorIncident
+	openIncidents     map[string]*MonitorIncident
+	incidentSeq       uint64
+	correlationWindow time.Duration
+
 	// 监控配置
 	checkInterval  time.Duration
 	eventRetention time.Duration
@@ -107,6 +114,9 @@ type MonitorConfig struct {
 	EnabledTypes   map[MonitorType]bool `yaml:"enabled_types"`
 	Rules          []MonitorRule        `yaml:"rules"`
 	AlertChannels  []AlertChannelConfig `yaml:"alert_channels"`
+
+	// CorrelationWindow 事件关联窗口，同一组件同一类型的事件间隔不超过该值时归入同一事故
+	CorrelationWindow time.Duration `yaml:"correlation_window"`
 }
 
 // AlertChannelConfig 告警通道配置
@@ -119,10 +129,11 @@ type AlertChannelConfig struct {
 // DefaultMonitorConfig 默认监控配置
 func DefaultMonitorConfig() *MonitorConfig {
 	return &MonitorConfig{
-		Enabled:        true,
-		CheckInterval:  30 * time.Second,
-		EventRetention: 24 * time.Hour,
-		MaxEvents:      10000,
+		Enabled:           true,
+		CheckInterval:     30 * time.Second,
+		EventRetention:    24 * time.Hour,
+		MaxEvents:         10000,
+		CorrelationWindow: DefaultCorrelationWindow,
 		EnabledTypes: map[MonitorType]bool{
 			MonitorTypeConfigChange:   true,
 			MonitorTypeConfigHealth:   true,
@@ -164,6 +175,11 @@ func NewConfigMonitor(config *MonitorConfig, logger hclog.Logger) *ConfigMonitor
 		config = DefaultMonitorConfig()
 	}
 
+	correlationWindow := config.CorrelationWindow
+	if correlationWindow <= 0 {
+		correlationWindow = DefaultCorrelationWindow
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	monitor := &ConfigMonitor{
@@ -177,6 +193,10 @@ func NewConfigMonitor(config *MonitorConfig, logger hclog.Logger) *ConfigMonitor
 		eventRetention: config.EventRetention,
 		maxEvents:      config.MaxEvents,
 		enabledTypes:   config.EnabledTypes,
+
+		incidents:         make([]*MonitorIncident, 0),
+		openIncidents:     make(map[string]*MonitorIncident),
+		correlationWindow: correlationWindow,
 	}
 
 	// 初始化指标
@@ -260,7 +280,9 @@ func (cm *ConfigMonitor) RecordEvent(eventType MonitorType, level MonitorLevel,
 	}
 
 	cm.mu.Lock()
-	defer cm.mu.Unlock()
+
+	// 关联到事故
+	incident, shouldAlert := cm.correlateEvent(&event)
 
 	// 添加事件
 	cm.events = append(cm.events, event)
@@ -273,9 +295,14 @@ func (cm *ConfigMonitor) RecordEvent(eventType MonitorType, level MonitorLevel,
 	// 更新指标
 	cm.updateMetrics(event)
 
-	// 检查是否需要发送告警
-	if level == MonitorLevelError || level == MonitorLevelCritical {
-		cm.sendAlert(event)
+	incidentID, incidentCount := incident.ID, incident.Count
+	channels := cm.alertChannels
+	cm.mu.Unlock()
+
+	// 按事故发送告警，同一事故内的后续事件不重复告警
+	// 告警通道可能重试发送，不在持有锁时发送
+	if shouldAlert {
+		cm.sendAlert(channels, event)
 	}
 
 	cm.logger.Info("记录监控事件",
@@ -283,6 +310,8 @@ func (cm *ConfigMonitor) RecordEvent(eventType MonitorType, level MonitorLevel,
 		"level", level,
 		"component", component,
 		"message", message,
+		"incident_id", incidentID,
+		"incident_count", incidentCount,
 	)
 }
 
@@ -464,8 +493,8 @@ func (cm *ConfigMonitor) calculateHealthScore() {
 }
 
 // sendAlert 发送告警
-func (cm *ConfigMonitor) sendAlert(event MonitorEvent) {
-	for _, channel := range cm.alertChannels {
+func (cm *ConfigMonitor) sendAlert(channels []AlertChannel, event MonitorEvent) {
+	for _, channel := range channels {
 		if channel.IsEnabled() {
 			if err := channel.Send(event); err != nil {
 				cm.logger.Error("发送告警失败",
@@ -494,6 +523,7 @@ func (cm *ConfigMonitor) cleanupOldEvents() {
 
 	removed := len(cm.events) - len(newEvents)
 	cm.events = newEvents
+	cm.cleanupOldIncidents(cutoff)
 
 	if removed > 0 {
 		cm.logger.Info("清理旧监控事件", "removed", removed)
@@ -542,45 +572,6 @@ func (lac *LogAlertChannel) IsEnabled() bool {
 	return lac.enabled
 }
 
-// WebhookAlertChannel Webhook告警通道
-type WebhookAlertChannel struct {
-	url     string
-	timeout time.Duration
-	enabled bool
-	logger  hclog.Logger
-}
-
-// NewWebhookAlertChannel 创建Webhook告警通道
-func NewWebhookAlertChannel(url string, timeout time.Duration, enabled bool, logger hclog.Logger) *WebhookAlertChannel {
-	return &WebhookAlertChannel{
-		url:     url,
-		timeout: timeout,
-		enabled: enabled,
-		logger:  logger.Named("webhook-alert-channel"),
-	}
-}
-
-// Send 发送告警
-func (wac *WebhookAlertChannel) Send(event MonitorEvent) error {
-	// 这里应该实现实际的HTTP请求发送
-	wac.logger.Info("发送Webhook告警",
-		"url", wac.url,
-		"event_id", event.ID,
-		"level", event.Level,
-	)
-	return nil
-}
-
-// GetType 获取通道类型
-func (wac *WebhookAlertChannel) GetType() string {
-	return "webhook"
-}
-
-// IsEnabled 是否启用
-func (wac *WebhookAlertChannel) IsEnabled() bool {
-	return wac.enabled
-}
-
 // EmailAlertChannel 邮件告警通道
 type EmailAlertChannel struct {
 	smtpServer string
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// recordingAlertChannel 记录告警的测试通道
+type recordingAlertChannel struct {
+	mu     sync.Mutex
+	events []MonitorEvent
+}
+
+func (c *recordingAlertChannel) Send(event MonitorEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, event)
+	return nil
+}
+
+func (c *recordingAlertChannel) GetType() string { return "recording" }
+
+func (c *recordingAlertChannel) IsEnabled() bool { return true }
+
+func (c *recordingAlertChannel) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.events)
+}
+
+func newTestMonitor(window time.Duration) (*ConfigMonitor, *recordingAlertChannel) {
+	monitorConfig := DefaultMonitorConfig()
+	monitorConfig.CorrelationWindow = window
+
+	monitor := NewConfigMonitor(monitorConfig, hclog.NewNullLogger())
+	channel := &recordingAlertChannel{}
+	monitor.AddAlertChannel(channel)
+	return monitor, channel
+}
+
+// TestConfigMonitor_CorrelatesBurstIntoIncident 测试突发的相关事件聚合为单个事故
+func TestConfigMonitor_CorrelatesBurstIntoIncident(t *testing.T) {
+	monitor, channel := newTestMonitor(time.Minute)
+
+	for i := 0; i < 20; i++ {
+		monitor.RecordEvent(MonitorTypeConfigHealth, MonitorLevelError, "dlp", "dlp.yaml", "配置校验失败", nil)
+	}
+
+	if len(monitor.GetEvents()) != 20 {
+		t.Errorf("原始事件数量不匹配: 期望 %d, 实际 %d", 20, len(monitor.GetEvents()))
+	}
+
+	incidents := monitor.GetIncidents()
+	if len(incidents) != 1 {
+		t.Fatalf("事故数量不匹配: 期望 %d, 实际 %d", 1, len(incidents))
+	}
+
+	incident := incidents[0]
+	if incident.Count != 20 {
+		t.Errorf("事故事件计数不匹配: 期望 %d, 实际 %d", 20, incident.Count)
+	}
+	if incident.Component != "dlp" || incident.Type != MonitorTypeConfigHealth {
+		t.Errorf("事故归属不匹配: %s/%s", incident.Component, incident.Type)
+	}
+	if incident.LastSeen.Before(incident.FirstSeen) {
+		t.Errorf("最后发生时间早于首次发生时间")
+	}
+	for _, event := range monitor.GetEvents() {
+		if event.IncidentID != incident.ID {
+			t.Errorf("事件未关联到事故: %s", event.ID)
+		}
+	}
+
+	if channel.count() != 1 {
+		t.Errorf("告警次数不匹配: 期望 %d, 实际 %d", 1, channel.count())
+	}
+}
+
+// TestConfigMonitor_UnrelatedEventsSeparateIncidents 测试不相关事件形成独立的事故
+func TestConfigMonitor_UnrelatedEventsSeparateIncidents(t *testing.T) {
+	monitor, channel := newTestMonitor(time.Minute)
+
+	monitor.RecordEvent(MonitorTypeConfigHealth, MonitorLevelError, "dlp", "", "错误", nil)
+	monitor.RecordEvent(MonitorTypeConfigHealth, MonitorLevelError, "audit", "", "错误", nil)
+	monitor.RecordEvent(MonitorTypeConfigChange, MonitorLevelInfo, "dlp", "", "配置变更", nil)
+	monitor.RecordEvent(MonitorTypeConfigChange, MonitorLevelInfo, "dlp", "", "配置变更", nil)
+
+	incidents := monitor.GetIncidents()
+	if len(incidents) != 3 {
+		t.Fatalf("事故数量不匹配: 期望 %d, 实际 %d", 3, len(incidents))
+	}
+
+	counts := make(map[string]int)
+	for _, incident := range incidents {
+		counts[incidentKey(incident.Type, incident.Component)] = incident.Count
+	}
+	if counts[incidentKey(MonitorTypeConfigChange, "dlp")] != 2 {
+		t.Errorf("配置变更事故计数不匹配: %v", counts)
+	}
+
+	// 只有错误级别的两个事故触发告警
+	if channel.count() != 2 {
+		t.Errorf("告警次数不匹配: 期望 %d, 实际 %d", 2, channel.count())
+	}
+}
+
+// TestConfigMonitor_IncidentWindowAndEscalation 测试关联窗口过期和级别升级
+func TestConfigMonitor_IncidentWindowAndEscalation(t *testing.T) {
+	monitor, channel := newTestMonitor(20 * time.Millisecond)
+
+	monitor.RecordEvent(MonitorTypeConfigUsage, MonitorLevelWarning, "assets", "", "警告", nil)
+	if channel.count() != 0 {
+		t.Errorf("警告级别不应触发告警")
+	}
+
+	// 同一事故内升级为错误级别时告警一次
+	monitor.RecordEvent(MonitorTypeConfigUsage, MonitorLevelCritical, "assets", "", "严重", nil)
+	monitor.RecordEvent(MonitorTypeConfigUsage, MonitorLevelError, "assets", "", "错误", nil)
+	if channel.count() != 1 {
+		t.Errorf("告警次数不匹配: 期望 %d, 实际 %d", 1, channel.count())
+	}
+
+	incidents := monitor.GetIncidents()
+	if len(incidents) != 1 || incidents[0].Level != MonitorLevelCritical {
+		t.Fatalf("事故级别未升级: %+v", incidents)
+	}
+
+	// 超出关联窗口后形成新的事故
+	time.Sleep(50 * time.Millisecond)
+	monitor.RecordEvent(MonitorTypeConfigUsage, MonitorLevelError, "assets", "", "错误", nil)
+
+	incidents = monitor.GetIncidents()
+	if len(incidents) != 2 {
+		t.Fatalf("事故数量不匹配: 期望 %d, 实际 %d", 2, len(incidents))
+	}
+	if incidents[0].Count != 1 {
+		t.Errorf("新事故计数不匹配: 期望 %d, 实际 %d", 1, incidents[0].Count)
+	}
+	if channel.count() != 2 {
+		t.Errorf("告警次数不匹配: 期望 %d, 实际 %d", 2, channel.count())
+	}
+
+	// 解决事故后同类事件创建新的事故
+	if err := monitor.ResolveIncident(incidents[0].ID); err != nil {
+		t.Fatalf("解决事故失败: %v", err)
+	}
+	monitor.RecordEvent(MonitorTypeConfigUsage, MonitorLevelInfo, "assets", "", "恢复", nil)
+	if len(monitor.GetIncidents()) != 3 {
+		t.Errorf("解决后的事件应创建新事故")
+	}
+}
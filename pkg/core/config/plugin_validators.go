@@ -178,6 +178,11 @@ func CreateDLPValidator() *PluginConfigValidator {
 	validator.AddFieldType("version", reflect.String)
 	validator.AddDefault("version", "2.0.0")
 
+	// 日志级别
+	validator.AddFieldType("log_level", reflect.String)
+	validator.AddFieldValidator("log_level", StringEnumValidator("debug", "info", "warn", "error"))
+	validator.AddDefault("log_level", "info")
+
 	// 监控开关
 	validator.AddFieldType("monitor_network", reflect.Bool)
 	validator.AddDefault("monitor_network", true)
@@ -200,6 +205,7 @@ func CreateDLPValidator() *PluginConfigValidator {
 	// 网络协议
 	validator.AddFieldType("network_protocols", reflect.Slice)
 	validator.AddFieldValidator("network_protocols", ArrayValidator(1, 20))
+	validator.AddDefault("network_protocols", []interface{}{"http", "https", "ftp", "smtp"})
 
 	// 拦截器配置
 	validator.AddFieldType("interceptor_config", reflect.Map)
@@ -219,10 +225,12 @@ func CreateDLPValidator() *PluginConfigValidator {
 	// 监控目录
 	validator.AddFieldType("monitored_directories", reflect.Slice)
 	validator.AddFieldValidator("monitored_directories", ArrayValidator(0, 50))
+	validator.AddDefault("monitored_directories", []interface{}{"data/dlp/monitored"})
 
 	// 监控文件类型
 	validator.AddFieldType("monitored_file_types", reflect.Slice)
 	validator.AddFieldValidator("monitored_file_types", ArrayValidator(0, 100))
+	validator.AddDefault("monitored_file_types", []interface{}{"*.txt", "*.doc", "*.docx", "*.xls", "*.xlsx", "*.pdf"})
 
 	// 日志配置
 	validator.AddFieldType("logging", reflect.Map)
@@ -257,6 +265,9 @@ func CreateDLPValidator() *PluginConfigValidator {
 	// 审计配置
 	validator.AddFieldType("audit", reflect.Map)
 
+	// 网络监控依赖拦截器（抓包后端）配置
+	validator.AddRequires("monitor_network", "interceptor_config")
+
 	return validator
 }
 
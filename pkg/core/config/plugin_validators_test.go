@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -260,3 +261,31 @@ func TestValidatorCoverage(t *testing.T) {
 		}
 	}
 }
+
+func TestDLPValidatorNetworkMonitorRequiresInterceptor(t *testing.T) {
+	validator := CreateDLPValidator()
+
+	config := map[string]interface{}{
+		"plugins": map[string]interface{}{
+			"dlp": map[string]interface{}{
+				"enabled":         true,
+				"monitor_network": true,
+			},
+		},
+	}
+
+	err := validator.Validate(config)
+	var fieldErrs FieldErrors
+	if !errors.As(err, &fieldErrs) || len(fieldErrs) != 1 {
+		t.Fatalf("启用网络监控但缺少拦截器配置时应返回一个字段错误，实际: %v", err)
+	}
+	if fieldErrs[0].Field != "interceptor_config" {
+		t.Errorf("错误字段期望 interceptor_config，实际 %s", fieldErrs[0].Field)
+	}
+
+	// 关闭网络监控时不需要拦截器配置
+	config["plugins"].(map[string]interface{})["dlp"].(map[string]interface{})["monitor_network"] = false
+	if err := validator.Validate(config); err != nil {
+		t.Errorf("关闭网络监控的配置验证失败: %v", err)
+	}
+}
@@ -25,6 +25,9 @@ type PluginConfigValidator struct {
 
 	// 架构
 	Schema map[string]interface{}
+
+	// 跨字段约束
+	Constraints []FieldConstraint
 }
 
 // FieldValidator 字段验证器
@@ -198,6 +201,7 @@ func NewPluginConfigValidator(pluginID string) *PluginConfigValidator {
 		FieldValidators: make(map[string]FieldValidator),
 		Defaults:        make(map[string]interface{}),
 		Schema:          make(map[string]interface{}),
+		Constraints:     make([]FieldConstraint, 0),
 	}
 }
 
@@ -231,6 +235,24 @@ func (v *PluginConfigValidator) AddSchema(field string, schema interface{}) *Plu
 	return v
 }
 
+// AddRequires 添加依赖约束：配置了field时必须同时配置required中的所有字段
+func (v *PluginConfigValidator) AddRequires(field string, required ...string) *PluginConfigValidator {
+	v.Constraints = append(v.Constraints, FieldConstraint{Type: ConstraintRequires, Field: field, Fields: required})
+	return v
+}
+
+// AddConflicts 添加互斥约束：配置了field时不能配置conflicting中的任何字段
+func (v *PluginConfigValidator) AddConflicts(field string, conflicting ...string) *PluginConfigValidator {
+	v.Constraints = append(v.Constraints, FieldConstraint{Type: ConstraintConflicts, Field: field, Fields: conflicting})
+	return v
+}
+
+// AddAtLeastOne 添加至少一个约束：fields中至少配置一个字段
+func (v *PluginConfigValidator) AddAtLeastOne(fields ...string) *PluginConfigValidator {
+	v.Constraints = append(v.Constraints, FieldConstraint{Type: ConstraintAtLeastOne, Fields: fields})
+	return v
+}
+
 // Validate 验证配置
 func (v *PluginConfigValidator) Validate(config map[string]interface{}) error {
 	// 获取插件配置
@@ -280,6 +302,11 @@ func (v *PluginConfigValidator) Validate(config map[string]interface{}) error {
 		}
 	}
 
+	// 验证跨字段约束
+	if errs := v.validateConstraints(specificConfig); len(errs) > 0 {
+		return errs
+	}
+
 	return nil
 }
 
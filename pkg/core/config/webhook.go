@@ -0,0 +1,344 @@
+package config
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// DefaultWebhookSignatureHeader 默认签名请求头，值为 "sha256=" 加上请求体HMAC-SHA256签名的十六进制
+	DefaultWebhookSignatureHeader = "X-Kennel-Signature"
+
+	// WebhookEventIDHeader 事件ID请求头，重试时保持不变，接收方可据此去重
+	WebhookEventIDHeader = "X-Kennel-Event-ID"
+
+	// DefaultWebhookMaxDeadLetters 默认保留的死信数量
+	DefaultWebhookMaxDeadLetters = 100
+
+	webhookSignaturePrefix = "sha256="
+)
+
+// WebhookRetryPolicy Webhook告警重试策略
+type WebhookRetryPolicy struct {
+	// MaxRetries 首次发送失败后的最大重试次数，0表示不重试
+	MaxRetries int `yaml:"max_retries"`
+
+	// InitialBackoff 首次重试前的等待时间
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+
+	// MaxBackoff 重试等待时间上限
+	MaxBackoff time.Duration `yaml:"max_backoff"`
+
+	// Multiplier 每次重试后等待时间的增长倍数
+	Multiplier float64 `yaml:"multiplier"`
+}
+
+// DefaultWebhookRetryPolicy 返回默认Webhook重试策略
+func DefaultWebhookRetryPolicy() WebhookRetryPolicy {
+	return WebhookRetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// backoff 返回第 retry 次重试（从1开始）前的等待时间
+func (p WebhookRetryPolicy) backoff(retry int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < retry; i++ {
+		delay = time.Duration(float64(delay) * p.Multiplier)
+		if p.MaxBackoff > 0 && delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return delay
+}
+
+// WebhookAlertConfig Webhook告警通道配置
+type WebhookAlertConfig struct {
+	URL     string        `yaml:"url"`
+	Timeout time.Duration `yaml:"timeout"`
+	Enabled bool          `yaml:"enabled"`
+
+	// Secret 签名密钥，为空时不签名
+	Secret string `yaml:"secret"`
+
+	// SignatureHeader 签名请求头名称
+	SignatureHeader string `yaml:"signature_header"`
+
+	// Retry 发送失败时的重试策略，仅对网络错误、408、429和5xx响应重试
+	Retry WebhookRetryPolicy `yaml:"retry"`
+
+	// DeadLetterFile 死信文件，重试耗尽的告警以JSON行追加到该文件，为空时仅保留在内存中
+	DeadLetterFile string `yaml:"dead_letter_file"`
+
+	// MaxDeadLetters 内存中保留的死信数量
+	MaxDeadLetters int `yaml:"max_dead_letters"`
+}
+
+// DefaultWebhookAlertConfig 返回默认Webhook告警通道配置
+func DefaultWebhookAlertConfig(url string) WebhookAlertConfig {
+	return WebhookAlertConfig{
+		URL:             url,
+		Timeout:         10 * time.Second,
+		Enabled:         true,
+		SignatureHeader: DefaultWebhookSignatureHeader,
+		Retry:           DefaultWebhookRetryPolicy(),
+		MaxDeadLetters:  DefaultWebhookMaxDeadLetters,
+	}
+}
+
+// Validate 验证Webhook告警通道配置
+func (c WebhookAlertConfig) Validate() error {
+	parsed, err := url.Parse(c.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("无效的Webhook地址: %s", c.URL)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("Webhook超时时间不能为负数")
+	}
+	if c.Retry.MaxRetries < 0 {
+		return fmt.Errorf("Webhook最大重试次数不能为负数")
+	}
+	if c.Retry.InitialBackoff < 0 || c.Retry.MaxBackoff < 0 {
+		return fmt.Errorf("Webhook重试等待时间不能为负数")
+	}
+	if c.Retry.Multiplier != 0 && c.Retry.Multiplier < 1 {
+		return fmt.Errorf("Webhook重试等待时间增长倍数不能小于1")
+	}
+	if c.MaxDeadLetters < 0 {
+		return fmt.Errorf("Webhook死信数量不能为负数")
+	}
+	return nil
+}
+
+// withDefaults 为未设置的配置项填充默认值
+func (c WebhookAlertConfig) withDefaults() WebhookAlertConfig {
+	defaults := DefaultWebhookAlertConfig(c.URL)
+	if c.Timeout == 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.SignatureHeader == "" {
+		c.SignatureHeader = defaults.SignatureHeader
+	}
+	if c.Retry.Multiplier == 0 {
+		c.Retry.Multiplier = defaults.Retry.Multiplier
+	}
+	if c.MaxDeadLetters == 0 {
+		c.MaxDeadLetters = defaults.MaxDeadLetters
+	}
+	return c
+}
+
+// WebhookDeadLetter 重试耗尽后未能送达的告警
+type WebhookDeadLetter struct {
+	Event     MonitorEvent `json:"event"`
+	URL       string       `json:"url"`
+	Attempts  int          `json:"attempts"`
+	LastError string       `json:"last_error"`
+	FailedAt  time.Time    `json:"failed_at"`
+}
+
+// SignWebhookPayload 使用HMAC-SHA256签名请求体，返回签名请求头的值
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature 校验请求体签名，供告警接收方验证告警来源
+func VerifyWebhookSignature(secret string, payload []byte, signature string) bool {
+	if !strings.HasPrefix(signature, webhookSignaturePrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(SignWebhookPayload(secret, payload)), []byte(signature))
+}
+
+// WebhookAlertChannel Webhook告警通道
+// 以JSON格式POST告警事件，配置密钥时在请求头中携带签名，发送失败时按重试策略重试，重试耗尽后转入死信
+type WebhookAlertChannel struct {
+	config WebhookAlertConfig
+	client *http.Client
+	logger hclog.Logger
+
+	mu          sync.Mutex
+	deadLetters []WebhookDeadLetter
+}
+
+// NewWebhookAlertChannel 创建Webhook告警通道，使用默认重试策略且不签名
+func NewWebhookAlertChannel(url string, timeout time.Duration, enabled bool, logger hclog.Logger) *WebhookAlertChannel {
+	config := DefaultWebhookAlertConfig(url)
+	config.Timeout = timeout
+	config.Enabled = enabled
+	return newWebhookAlertChannel(config, logger)
+}
+
+// NewWebhookAlertChannelWithConfig 按配置创建Webhook告警通道
+func NewWebhookAlertChannelWithConfig(config WebhookAlertConfig, logger hclog.Logger) (*WebhookAlertChannel, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return newWebhookAlertChannel(config, logger), nil
+}
+
+func newWebhookAlertChannel(config WebhookAlertConfig, logger hclog.Logger) *WebhookAlertChannel {
+	config = config.withDefaults()
+	return &WebhookAlertChannel{
+		config:      config,
+		client:      &http.Client{Timeout: config.Timeout},
+		logger:      logger.Named("webhook-alert-channel"),
+		deadLetters: make([]WebhookDeadLetter, 0),
+	}
+}
+
+// Send 发送告警
+func (wac *WebhookAlertChannel) Send(event MonitorEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("序列化告警事件失败: %w", err)
+	}
+
+	policy := wac.config.Retry
+	attempts := 0
+	for {
+		attempts++
+		retryable, err := wac.post(event.ID, payload)
+		if err == nil {
+			wac.logger.Info("发送Webhook告警",
+				"url", wac.config.URL,
+				"event_id", event.ID,
+				"level", event.Level,
+				"attempts", attempts,
+			)
+			return nil
+		}
+
+		if !retryable || attempts > policy.MaxRetries {
+			wac.addDeadLetter(WebhookDeadLetter{
+				Event:     event,
+				URL:       wac.config.URL,
+				Attempts:  attempts,
+				LastError: err.Error(),
+				FailedAt:  time.Now(),
+			})
+			return fmt.Errorf("发送Webhook告警失败（已尝试%d次）: %w", attempts, err)
+		}
+
+		delay := policy.backoff(attempts)
+		wac.logger.Warn("发送Webhook告警失败，稍后重试",
+			"url", wac.config.URL,
+			"event_id", event.ID,
+			"attempt", attempts,
+			"retry_in", delay,
+			"error", err,
+		)
+		time.Sleep(delay)
+	}
+}
+
+// post 发送一次请求，返回失败是否可以重试
+func (wac *WebhookAlertChannel) post(eventID string, payload []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, wac.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return false, fmt.Errorf("创建Webhook请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventIDHeader, eventID)
+	if wac.config.Secret != "" {
+		req.Header.Set(wac.config.SignatureHeader, SignWebhookPayload(wac.config.Secret, payload))
+	}
+
+	resp, err := wac.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable := resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= 500
+	return retryable, fmt.Errorf("Webhook返回状态码 %d", resp.StatusCode)
+}
+
+// addDeadLetter 记录死信，配置死信文件时同时追加到文件
+func (wac *WebhookAlertChannel) addDeadLetter(letter WebhookDeadLetter) {
+	wac.mu.Lock()
+	wac.deadLetters = append(wac.deadLetters, letter)
+	if len(wac.deadLetters) > wac.config.MaxDeadLetters {
+		wac.deadLetters = wac.deadLetters[len(wac.deadLetters)-wac.config.MaxDeadLetters:]
+	}
+	wac.mu.Unlock()
+
+	wac.logger.Error("Webhook告警重试耗尽，转入死信",
+		"url", letter.URL,
+		"event_id", letter.Event.ID,
+		"attempts", letter.Attempts,
+		"error", letter.LastError,
+	)
+
+	if wac.config.DeadLetterFile != "" {
+		if err := appendDeadLetterFile(wac.config.DeadLetterFile, letter); err != nil {
+			wac.logger.Error("写入Webhook死信文件失败", "file", wac.config.DeadLetterFile, "error", err)
+		}
+	}
+}
+
+// appendDeadLetterFile 以JSON行追加死信
+func appendDeadLetterFile(path string, letter WebhookDeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// DeadLetters 获取内存中的死信
+func (wac *WebhookAlertChannel) DeadLetters() []WebhookDeadLetter {
+	wac.mu.Lock()
+	defer wac.mu.Unlock()
+
+	letters := make([]WebhookDeadLetter, len(wac.deadLetters))
+	copy(letters, wac.deadLetters)
+	return letters
+}
+
+// GetType 获取通道类型
+func (wac *WebhookAlertChannel) GetType() string {
+	return "webhook"
+}
+
+// IsEnabled 是否启用
+func (wac *WebhookAlertChannel) IsEnabled() bool {
+	return wac.config.Enabled
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// webhookStub 记录收到的请求，前 failures 次请求返回 status
+type webhookStub struct {
+	mu       sync.Mutex
+	failures int
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (s *webhookStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+	s.bodies = append(s.bodies, body)
+	if len(s.requests) <= s.failures {
+		w.WriteHeader(s.status)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *webhookStub) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func newWebhookTestChannel(t *testing.T, stub *webhookStub, modify func(*WebhookAlertConfig)) *WebhookAlertChannel {
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	config := DefaultWebhookAlertConfig(server.URL)
+	config.Secret = "test-secret"
+	config.Retry = WebhookRetryPolicy{
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Multiplier:     2,
+	}
+	if modify != nil {
+		modify(&config)
+	}
+
+	channel, err := NewWebhookAlertChannelWithConfig(config, hclog.NewNullLogger())
+	if err != nil {
+		t.Fatalf("创建Webhook告警通道失败: %v", err)
+	}
+	return channel
+}
+
+func testWebhookEvent() MonitorEvent {
+	return MonitorEvent{
+		ID:        "event_1",
+		Type:      MonitorTypeConfigSecurity,
+		Level:     MonitorLevelCritical,
+		Component: "dlp",
+		Message:   "配置被篡改",
+		Timestamp: time.Now(),
+	}
+}
+
+// TestWebhookAlertChannel_Signature 测试请求体签名可被接收方验证
+func TestWebhookAlertChannel_Signature(t *testing.T) {
+	stub := &webhookStub{}
+	channel := newWebhookTestChannel(t, stub, nil)
+
+	if err := channel.Send(testWebhookEvent()); err != nil {
+		t.Fatalf("发送告警失败: %v", err)
+	}
+	if stub.count() != 1 {
+		t.Fatalf("请求次数不匹配: 期望 %d, 实际 %d", 1, stub.count())
+	}
+
+	req, body := stub.requests[0], stub.bodies[0]
+	signature := req.Header.Get(DefaultWebhookSignatureHeader)
+	if !VerifyWebhookSignature("test-secret", body, signature) {
+		t.Errorf("签名校验失败: %s", signature)
+	}
+	if VerifyWebhookSignature("other-secret", body, signature) {
+		t.Error("使用错误的密钥不应通过签名校验")
+	}
+	if VerifyWebhookSignature("test-secret", append(body, ' '), signature) {
+		t.Error("请求体被修改后不应通过签名校验")
+	}
+	if req.Header.Get(WebhookEventIDHeader) != "event_1" {
+		t.Errorf("事件ID请求头不匹配: %s", req.Header.Get(WebhookEventIDHeader))
+	}
+
+	var event MonitorEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.ID != "event_1" {
+		t.Errorf("请求体应为告警事件JSON: %s", body)
+	}
+
+	// 未配置密钥时不签名
+	unsigned := &webhookStub{}
+	channel = newWebhookTestChannel(t, unsigned, func(c *WebhookAlertConfig) {
+		c.Secret = ""
+		c.SignatureHeader = "X-Signature"
+	})
+	if err := channel.Send(testWebhookEvent()); err != nil {
+		t.Fatalf("发送告警失败: %v", err)
+	}
+	if unsigned.requests[0].Header.Get("X-Signature") != "" {
+		t.Error("未配置密钥时不应携带签名")
+	}
+}
+
+// TestWebhookAlertChannel_RetryTransientFailure 测试暂时性失败后重试成功
+func TestWebhookAlertChannel_RetryTransientFailure(t *testing.T) {
+	stub := &webhookStub{failures: 2, status: http.StatusServiceUnavailable}
+	channel := newWebhookTestChannel(t, stub, nil)
+
+	if err := channel.Send(testWebhookEvent()); err != nil {
+		t.Fatalf("重试后应发送成功: %v", err)
+	}
+	if stub.count() != 3 {
+		t.Errorf("请求次数不匹配: 期望 %d, 实际 %d", 3, stub.count())
+	}
+	if len(channel.DeadLetters()) != 0 {
+		t.Error("发送成功时不应产生死信")
+	}
+
+	// 重试时签名和事件ID保持不变
+	for i, req := range stub.requests {
+		if !VerifyWebhookSignature("test-secret", stub.bodies[i], req.Header.Get(DefaultWebhookSignatureHeader)) {
+			t.Errorf("第%d次请求签名校验失败", i+1)
+		}
+		if req.Header.Get(WebhookEventIDHeader) != "event_1" {
+			t.Errorf("第%d次请求事件ID不匹配", i+1)
+		}
+	}
+}
+
+// TestWebhookAlertChannel_DeadLetter 测试重试耗尽和不可重试的失败转入死信
+func TestWebhookAlertChannel_DeadLetter(t *testing.T) {
+	deadLetterFile := filepath.Join(t.TempDir(), "alerts", "dead_letters.jsonl")
+	stub := &webhookStub{failures: 100, status: http.StatusInternalServerError}
+	channel := newWebhookTestChannel(t, stub, func(c *WebhookAlertConfig) {
+		c.DeadLetterFile = deadLetterFile
+	})
+
+	if err := channel.Send(testWebhookEvent()); err == nil {
+		t.Fatal("重试耗尽时应返回错误")
+	}
+	if stub.count() != 3 {
+		t.Errorf("请求次数不匹配: 期望 %d, 实际 %d", 3, stub.count())
+	}
+
+	letters := channel.DeadLetters()
+	if len(letters) != 1 {
+		t.Fatalf("死信数量不匹配: 期望 %d, 实际 %d", 1, len(letters))
+	}
+	if letters[0].Event.ID != "event_1" || letters[0].Attempts != 3 || letters[0].LastError == "" {
+		t.Errorf("死信内容不正确: %+v", letters[0])
+	}
+
+	file, err := os.Open(deadLetterFile)
+	if err != nil {
+		t.Fatalf("打开死信文件失败: %v", err)
+	}
+	defer file.Close()
+	var lines []WebhookDeadLetter
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var letter WebhookDeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+			t.Fatalf("解析死信文件失败: %v", err)
+		}
+		lines = append(lines, letter)
+	}
+	if len(lines) != 1 || lines[0].Event.ID != "event_1" {
+		t.Errorf("死信文件内容不正确: %+v", lines)
+	}
+
+	// 客户端错误不重试，直接转入死信
+	rejected := &webhookStub{failures: 100, status: http.StatusBadRequest}
+	channel = newWebhookTestChannel(t, rejected, nil)
+	if err := channel.Send(testWebhookEvent()); err == nil {
+		t.Fatal("请求被拒绝时应返回错误")
+	}
+	if rejected.count() != 1 {
+		t.Errorf("客户端错误不应重试: 请求次数 %d", rejected.count())
+	}
+	if letters := channel.DeadLetters(); len(letters) != 1 || letters[0].Attempts != 1 {
+		t.Errorf("客户端错误应转入死信: %+v", letters)
+	}
+}
+
+// TestWebhookAlertChannel_MaxDeadLetters 测试内存中死信数量受限
+func TestWebhookAlertChannel_MaxDeadLetters(t *testing.T) {
+	stub := &webhookStub{failures: 100, status: http.StatusBadRequest}
+	channel := newWebhookTestChannel(t, stub, func(c *WebhookAlertConfig) {
+		c.MaxDeadLetters = 2
+	})
+
+	for _, id := range []string{"event_1", "event_2", "event_3"} {
+		event := testWebhookEvent()
+		event.ID = id
+		_ = channel.Send(event)
+	}
+
+	letters := channel.DeadLetters()
+	if len(letters) != 2 || letters[0].Event.ID != "event_2" || letters[1].Event.ID != "event_3" {
+		t.Errorf("应只保留最近的死信: %+v", letters)
+	}
+}
+
+func TestWebhookRetryPolicy_Backoff(t *testing.T) {
+	policy := WebhookRetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 500 * time.Millisecond, Multiplier: 2}
+	expected := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond}
+	for i, want := range expected {
+		if got := policy.backoff(i + 1); got != want {
+			t.Errorf("第%d次重试等待时间不匹配: 期望 %v, 实际 %v", i+1, want, got)
+		}
+	}
+}
+
+func TestWebhookAlertConfig_Validate(t *testing.T) {
+	valid := DefaultWebhookAlertConfig("https://alerts.example.com/hook")
+	if err := valid.Validate(); err != nil {
+		t.Errorf("默认配置应通过验证: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*WebhookAlertConfig)
+	}{
+		{"地址为空", func(c *WebhookAlertConfig) { c.URL = "" }},
+		{"地址协议无效", func(c *WebhookAlertConfig) { c.URL = "ftp://alerts.example.com" }},
+		{"重试次数为负数", func(c *WebhookAlertConfig) { c.Retry.MaxRetries = -1 }},
+		{"等待时间为负数", func(c *WebhookAlertConfig) { c.Retry.InitialBackoff = -time.Second }},
+		{"增长倍数小于1", func(c *WebhookAlertConfig) { c.Retry.Multiplier = 0.5 }},
+		{"死信数量为负数", func(c *WebhookAlertConfig) { c.MaxDeadLetters = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+			if err := config.Validate(); err == nil {
+				t.Error("应返回验证错误")
+			}
+		})
+	}
+}
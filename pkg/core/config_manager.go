@@ -38,6 +38,10 @@ func NewConfigManager(configFile string) *ConfigManager {
 			"reconnect_interval":     "5s",
 			"max_reconnect_attempts": 10,
 			"comm_shutdown_timeout":  5,
+			"plugin_signature": map[string]interface{}{
+				"mode":        "disabled",
+				"trust_store": "",
+			},
 			"comm_security": map[string]interface{}{
 				"enable_tls":            false,
 				"verify_server_cert":    true,
@@ -115,9 +119,8 @@ func (cm *ConfigManager) InitConfig() error {
 		}
 	}
 
-	// 读取环境变量
-	viper.AutomaticEnv()
-	viper.SetEnvPrefix("APPFW")
+	// 读取环境变量，变量名规则与有效配置计算一致
+	configerror.BindEnv(viper.GetViper(), "APPFW")
 
 	// 读取配置文件
 	if err := viper.ReadInConfig(); err != nil {
@@ -148,6 +151,25 @@ func (cm *ConfigManager) InitConfig() error {
 		return configerror.HandleConfigError(configErr)
 	}
 
+	// 合并 include 声明的子配置文件，主配置文件中的值优先
+	if viper.IsSet(configerror.IncludeKey) {
+		merged, _, err := configerror.LoadConfigWithIncludes(viper.ConfigFileUsed())
+		if err != nil {
+			return configerror.HandleConfigError(err)
+		}
+		if err := viper.MergeConfigMap(merged); err != nil {
+			configErr := configerror.NewConfigError(
+				configerror.ConfigErrorTypeParseError,
+				"main",
+				viper.ConfigFileUsed(),
+				configerror.IncludeKey,
+				"合并包含的配置文件失败",
+				err,
+			)
+			return configerror.HandleConfigError(configErr)
+		}
+	}
+
 	return nil
 }
 
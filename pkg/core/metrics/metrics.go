@@ -0,0 +1,406 @@
+// Package metrics 汇总核心子系统和插件的运行指标，在单个端点以 Prometheus 文本格式输出
+// 核心子系统的指标命名为 <命名空间>_<子系统>_<指标>；插件指标命名为 <命名空间>_plugin_<指标>，
+// 用 plugin 标签区分插件，不同插件的同名指标归入同一个指标族。嵌套的指标按层级用下划线连接，非数值指标不输出
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultNamespace 默认指标命名空间
+	DefaultNamespace = "kennel"
+
+	// DefaultSourceTimeout 默认的单次采集超时，超时未返回的来源不输出指标
+	DefaultSourceTimeout = 2 * time.Second
+
+	// ContentType Prometheus 文本格式的内容类型
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	// pluginSubsystem 插件指标使用的子系统名，不能登记为核心子系统
+	pluginSubsystem = "plugin"
+	// metricsSubsystem 注册表自身指标使用的子系统名，不能登记为核心子系统
+	metricsSubsystem = "metrics"
+)
+
+// 来源类型，作为来源状态指标的 kind 标签
+const (
+	KindSubsystem = "subsystem"
+	KindPlugin    = "plugin"
+)
+
+// Source 指标来源，返回当前的指标快照
+// 结果中包含字符串类型的 error 字段时视为采集失败，其余数值指标仍然输出
+type Source func() map[string]interface{}
+
+// PluginLister 列出当前提供指标的插件及其指标来源，未实现指标的插件不包含在结果中
+type PluginLister func() map[string]Source
+
+// Sample 一个指标样本
+type Sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// Registry 指标注册表，采集时并发调用各来源，单个来源出错、panic或超时不影响其他来源
+type Registry struct {
+	namespace string
+	timeout   time.Duration
+
+	mu         sync.RWMutex
+	subsystems map[string]Source
+	plugins    PluginLister
+}
+
+// NewRegistry 创建指标注册表，namespace 为空时使用默认命名空间
+func NewRegistry(namespace string) *Registry {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	return &Registry{
+		namespace:  sanitizeName(namespace),
+		timeout:    DefaultSourceTimeout,
+		subsystems: make(map[string]Source),
+	}
+}
+
+// SetTimeout 设置单次采集的超时，超时未返回的来源报告为失败，不为正数时使用默认值
+func (r *Registry) SetTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultSourceTimeout
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timeout = timeout
+}
+
+// Register 登记核心子系统的指标来源，同名子系统重复登记返回错误
+func (r *Registry) Register(subsystem string, source Source) error {
+	if source == nil {
+		return fmt.Errorf("子系统 %s 的指标来源为空", subsystem)
+	}
+	name := sanitizeName(subsystem)
+	if subsystem == "" || name != subsystem {
+		return fmt.Errorf("子系统名称无效: %q，只能包含字母、数字和下划线", subsystem)
+	}
+	if name == pluginSubsystem || name == metricsSubsystem {
+		return fmt.Errorf("子系统名称 %s 为保留名称", subsystem)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.subsystems[name]; exists {
+		return fmt.Errorf("子系统 %s 的指标来源已登记", subsystem)
+	}
+	r.subsystems[name] = source
+	return nil
+}
+
+// Unregister 注销核心子系统的指标来源
+func (r *Registry) Unregister(subsystem string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subsystems, subsystem)
+}
+
+// SetPluginLister 设置插件指标来源的列举函数，每次采集时调用
+func (r *Registry) SetPluginLister(lister PluginLister) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.plugins = lister
+}
+
+// Subsystems 返回已登记的核心子系统名称
+func (r *Registry) Subsystems() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.subsystems))
+	for name := range r.subsystems {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Gather 采集全部来源的指标，按指标名和标签排序
+// 同一指标名和标签出现多次时（例如不同的键清理后同名）只保留第一个
+func (r *Registry) Gather() []Sample {
+	r.mu.RLock()
+	subsystems := make(map[string]Source, len(r.subsystems))
+	for name, source := range r.subsystems {
+		subsystems[name] = source
+	}
+	lister := r.plugins
+	timeout := r.timeout
+	r.mu.RUnlock()
+
+	// 在锁外并发调用来源，避免慢插件阻塞登记和其他来源；
+	// 来源无法取消，超时后其结果被丢弃
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	pending := make(map[string]<-chan collectResult, len(subsystems))
+	for name, source := range subsystems {
+		pending[name] = collectAsync(source)
+	}
+	var plugins map[string]Source
+	listed := true
+	if lister != nil {
+		plugins, listed = collectPlugins(lister)
+	}
+	pendingPlugins := make(map[string]<-chan collectResult, len(plugins))
+	for id, source := range plugins {
+		pendingPlugins[id] = collectAsync(source)
+	}
+
+	c := &collector{seen: make(map[string]bool)}
+	for name, result := range pending {
+		values, up := awaitCollect(ctx, result)
+		c.flatten(r.namespace+"_"+name, nil, values)
+		c.up(r.namespace, name, KindSubsystem, up)
+	}
+	if !listed {
+		c.up(r.namespace, pluginSubsystem, KindSubsystem, false)
+	}
+	for id, result := range pendingPlugins {
+		values, up := awaitCollect(ctx, result)
+		c.flatten(r.namespace+"_"+pluginSubsystem, map[string]string{"plugin": id}, values)
+		c.up(r.namespace, id, KindPlugin, up)
+	}
+
+	sort.Slice(c.samples, func(i, j int) bool {
+		if c.samples[i].Name != c.samples[j].Name {
+			return c.samples[i].Name < c.samples[j].Name
+		}
+		return formatLabels(c.samples[i].Labels) < formatLabels(c.samples[j].Labels)
+	})
+	return c.samples
+}
+
+// WritePrometheus 以 Prometheus 文本格式输出全部指标
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	var buf bytes.Buffer
+	family := ""
+	upFamily := r.namespace + "_" + metricsSubsystem + "_source_up"
+	for _, sample := range r.Gather() {
+		if sample.Name != family {
+			family = sample.Name
+			metricType := "untyped"
+			if family == upFamily {
+				metricType = "gauge"
+			}
+			fmt.Fprintf(&buf, "# TYPE %s %s\n", family, metricType)
+		}
+		buf.WriteString(sample.Name)
+		buf.WriteString(formatLabels(sample.Labels))
+		buf.WriteByte(' ')
+		buf.WriteString(formatValue(sample.Value))
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Handler 返回输出全部指标的HTTP处理器
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.WritePrometheus(w)
+	})
+}
+
+// collector 一次采集的样本
+type collector struct {
+	samples []Sample
+	seen    map[string]bool
+}
+
+// add 添加样本，同名同标签的样本已存在时忽略
+func (c *collector) add(name string, labels map[string]string, value float64) {
+	key := name + formatLabels(labels)
+	if c.seen[key] {
+		return
+	}
+	c.seen[key] = true
+	c.samples = append(c.samples, Sample{Name: name, Labels: labels, Value: value})
+}
+
+// up 添加来源状态指标，采集成功为1，失败为0
+func (c *collector) up(namespace, source, kind string, up bool) {
+	value := 0.0
+	if up {
+		value = 1
+	}
+	c.add(namespace+"_"+metricsSubsystem+"_source_up", map[string]string{"source": source, "kind": kind}, value)
+}
+
+// flatten 将嵌套的指标展开为样本，只输出数值、布尔和时长
+func (c *collector) flatten(prefix string, labels map[string]string, values map[string]interface{}) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		c.flattenValue(prefix+"_"+sanitizeName(key), labels, values[key])
+	}
+}
+
+// flattenValue 展开单个指标值
+func (c *collector) flattenValue(name string, labels map[string]string, value interface{}) {
+	if value == nil {
+		return
+	}
+	if duration, ok := value.(time.Duration); ok {
+		c.add(name+"_seconds", labels, duration.Seconds())
+		return
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		c.add(name, labels, float64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		c.add(name, labels, float64(v.Uint()))
+	case reflect.Float32, reflect.Float64:
+		c.add(name, labels, v.Float())
+	case reflect.Bool:
+		if v.Bool() {
+			c.add(name, labels, 1)
+		} else {
+			c.add(name, labels, 0)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		nested := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			nested[iter.Key().String()] = iter.Value().Interface()
+		}
+		c.flatten(name, labels, nested)
+	}
+}
+
+// collect 调用指标来源，来源panic或返回 error 字段时报告失败
+func collect(source Source) (values map[string]interface{}, up bool) {
+	defer func() {
+		if recover() != nil {
+			values, up = nil, false
+		}
+	}()
+
+	values = source()
+	if message, ok := values["error"].(string); ok && message != "" {
+		return values, false
+	}
+	return values, true
+}
+
+// collectResult 一个来源的采集结果
+type collectResult struct {
+	values map[string]interface{}
+	up     bool
+}
+
+// collectAsync 在独立的协程中调用指标来源
+func collectAsync(source Source) <-chan collectResult {
+	result := make(chan collectResult, 1)
+	go func() {
+		values, up := collect(source)
+		result <- collectResult{values: values, up: up}
+	}()
+	return result
+}
+
+// awaitCollect 等待采集结果，超时时报告失败且不输出该来源的指标
+func awaitCollect(ctx context.Context, result <-chan collectResult) (map[string]interface{}, bool) {
+	select {
+	case r := <-result:
+		return r.values, r.up
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// collectPlugins 调用插件列举函数，panic时报告失败
+func collectPlugins(lister PluginLister) (plugins map[string]Source, ok bool) {
+	defer func() {
+		if recover() != nil {
+			plugins, ok = nil, false
+		}
+	}()
+	return lister(), true
+}
+
+// sanitizeName 将名称中 Prometheus 指标名不允许的字符替换为下划线
+func sanitizeName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// formatLabels 按标签名排序输出标签
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+`="`+escapeLabelValue(labels[name])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// escapeLabelValue 转义标签值中的反斜杠、双引号和换行
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatValue 输出样本值
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
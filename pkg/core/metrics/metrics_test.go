@@ -0,0 +1,256 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRegistry 创建登记了模拟通讯、自我保护子系统和两个插件的注册表
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+
+	registry := NewRegistry("")
+	if err := registry.Register("comm", func() map[string]interface{} {
+		return map[string]interface{}{
+			"connected":      true,
+			"messages_sent":  uint64(42),
+			"status":         "已连接",
+			"heartbeat_rtt":  150 * time.Millisecond,
+			"send_queue":     map[string]interface{}{"depth": 3, "high_water": 10},
+			"reconnect.rate": 0.5,
+		}
+	}); err != nil {
+		t.Fatalf("登记通讯指标失败: %v", err)
+	}
+	if err := registry.Register("selfprotect", func() map[string]interface{} {
+		return map[string]interface{}{
+			"blocked_attempts": 7,
+			"events":           map[string]int{"file": 2, "process": 5},
+		}
+	}); err != nil {
+		t.Fatalf("登记自我保护指标失败: %v", err)
+	}
+
+	registry.SetPluginLister(func() map[string]Source {
+		return map[string]Source{
+			"dlp": func() map[string]interface{} {
+				return map[string]interface{}{"files_scanned": 120, "blocked": 4}
+			},
+			"assets": func() map[string]interface{} {
+				return map[string]interface{}{"files_scanned": 9}
+			},
+			"broken": func() map[string]interface{} {
+				panic("插件崩溃")
+			},
+			"remote": func() map[string]interface{} {
+				return map[string]interface{}{"error": "获取指标超时"}
+			},
+		}
+	})
+	return registry
+}
+
+// scrape 通过HTTP端点获取指标
+func scrape(t *testing.T, registry *Registry) string {
+	t.Helper()
+
+	server := httptest.NewServer(registry.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("请求指标端点失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != ContentType {
+		t.Errorf("内容类型应为 %s，实际为 %s", ContentType, contentType)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("读取指标失败: %v", err)
+	}
+	return string(body)
+}
+
+// TestRegistryAggregatesSources 测试汇总端点包含各子系统和插件的指标及正确的标签
+func TestRegistryAggregatesSources(t *testing.T) {
+	output := scrape(t, newTestRegistry(t))
+
+	expected := []string{
+		// 核心子系统按子系统名称命名，嵌套指标按层级展开
+		"kennel_comm_connected 1",
+		"kennel_comm_messages_sent 42",
+		"kennel_comm_heartbeat_rtt_seconds 0.15",
+		"kennel_comm_send_queue_depth 3",
+		"kennel_comm_send_queue_high_water 10",
+		"kennel_comm_reconnect_rate 0.5",
+		"kennel_selfprotect_blocked_attempts 7",
+		"kennel_selfprotect_events_process 5",
+		// 不同插件的同名指标归入同一个指标族，用 plugin 标签区分
+		"# TYPE kennel_plugin_files_scanned untyped",
+		`kennel_plugin_files_scanned{plugin="assets"} 9`,
+		`kennel_plugin_files_scanned{plugin="dlp"} 120`,
+		`kennel_plugin_blocked{plugin="dlp"} 4`,
+		// 来源状态
+		"# TYPE kennel_metrics_source_up gauge",
+		`kennel_metrics_source_up{kind="subsystem",source="comm"} 1`,
+		`kennel_metrics_source_up{kind="subsystem",source="selfprotect"} 1`,
+		`kennel_metrics_source_up{kind="plugin",source="dlp"} 1`,
+		`kennel_metrics_source_up{kind="plugin",source="broken"} 0`,
+		`kennel_metrics_source_up{kind="plugin",source="remote"} 0`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("指标输出缺少 %q:\n%s", line, output)
+		}
+	}
+
+	if strings.Contains(output, "status") || strings.Contains(output, "kennel_plugin_error") {
+		t.Errorf("非数值指标不应输出:\n%s", output)
+	}
+	if count := strings.Count(output, "# TYPE kennel_plugin_files_scanned "); count != 1 {
+		t.Errorf("同一指标族的类型只应输出一次，实际 %d 次", count)
+	}
+}
+
+// TestRegistryRegister 测试重复登记和无效名称
+func TestRegistryRegister(t *testing.T) {
+	registry := NewRegistry("agent")
+	source := func() map[string]interface{} { return map[string]interface{}{"value": 1} }
+
+	if err := registry.Register("comm", source); err != nil {
+		t.Fatalf("登记指标来源失败: %v", err)
+	}
+	if err := registry.Register("comm", source); err == nil {
+		t.Error("重复登记同名子系统应返回错误")
+	}
+	for _, name := range []string{"", "self-protect", "plugin", "metrics"} {
+		if err := registry.Register(name, source); err == nil {
+			t.Errorf("子系统名称 %q 应被拒绝", name)
+		}
+	}
+	if err := registry.Register("dlp", nil); err == nil {
+		t.Error("指标来源为空时应返回错误")
+	}
+
+	samples := registry.Gather()
+	if len(samples) != 2 || samples[0].Name != "agent_comm_value" {
+		t.Errorf("应使用指定的命名空间，实际为 %v", samples)
+	}
+
+	registry.Unregister("comm")
+	if err := registry.Register("comm", source); err != nil {
+		t.Errorf("注销后应允许重新登记: %v", err)
+	}
+	if names := registry.Subsystems(); len(names) != 1 || names[0] != "comm" {
+		t.Errorf("已登记的子系统不正确: %v", names)
+	}
+}
+
+// TestRegistryDuplicateSeries 测试清理后同名的指标只输出一次
+func TestRegistryDuplicateSeries(t *testing.T) {
+	registry := NewRegistry("")
+	registry.Register("comm", func() map[string]interface{} {
+		return map[string]interface{}{"queue.depth": 1, "queue_depth": 2}
+	})
+
+	var buf strings.Builder
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("输出指标失败: %v", err)
+	}
+	if count := strings.Count(buf.String(), "\nkennel_comm_queue_depth "); count != 1 {
+		t.Errorf("同名同标签的指标应只输出一次，实际 %d 次:\n%s", count, buf.String())
+	}
+}
+
+// TestRegistryPluginListerPanic 测试列举插件失败时核心子系统的指标仍然输出
+func TestRegistryPluginListerPanic(t *testing.T) {
+	registry := NewRegistry("")
+	registry.Register("comm", func() map[string]interface{} {
+		return map[string]interface{}{"connected": false}
+	})
+	registry.SetPluginLister(func() map[string]Source { panic("插件管理器不可用") })
+
+	var buf strings.Builder
+	registry.WritePrometheus(&buf)
+	output := buf.String()
+	if !strings.Contains(output, "kennel_comm_connected 0\n") {
+		t.Errorf("核心子系统指标应正常输出:\n%s", output)
+	}
+	if !strings.Contains(output, `kennel_metrics_source_up{kind="subsystem",source="plugin"} 0`) {
+		t.Errorf("列举插件失败时应报告插件来源不可用:\n%s", output)
+	}
+}
+
+// TestRegistrySkipsSlowSources 测试超时未返回的插件被跳过，不阻塞其他来源
+func TestRegistrySkipsSlowSources(t *testing.T) {
+	registry := NewRegistry("")
+	registry.SetTimeout(100 * time.Millisecond)
+	registry.Register("comm", func() map[string]interface{} {
+		return map[string]interface{}{"connected": true}
+	})
+
+	release := make(chan struct{})
+	defer close(release)
+	registry.SetPluginLister(func() map[string]Source {
+		return map[string]Source{
+			"dlp": func() map[string]interface{} {
+				return map[string]interface{}{"blocked": 4}
+			},
+			"hung": func() map[string]interface{} {
+				<-release
+				return map[string]interface{}{"blocked": 1}
+			},
+			"slow": func() map[string]interface{} {
+				<-release
+				return map[string]interface{}{"blocked": 2}
+			},
+		}
+	})
+
+	start := time.Now()
+	var buf strings.Builder
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("输出指标失败: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("多个插件超时应共用同一个截止时间，实际耗时 %v", elapsed)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		"kennel_comm_connected 1\n",
+		`kennel_plugin_blocked{plugin="dlp"} 4` + "\n",
+		`kennel_metrics_source_up{kind="plugin",source="hung"} 0`,
+		`kennel_metrics_source_up{kind="plugin",source="slow"} 0`,
+		`kennel_metrics_source_up{kind="plugin",source="dlp"} 1`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("输出中缺少 %q:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, `plugin="hung"} 1`) || strings.Contains(output, `plugin="slow"} 2`) {
+		t.Errorf("超时的插件不应输出指标:\n%s", output)
+	}
+}
+
+// TestSanitizeName 测试指标名中的非法字符替换
+func TestSanitizeName(t *testing.T) {
+	tests := map[string]string{
+		"messages_sent": "messages_sent",
+		"send.queue":    "send_queue",
+		"cpu-usage%":    "cpu_usage_",
+		"5xx":           "_5xx",
+		"连接数":           "___",
+	}
+	for input, expected := range tests {
+		if actual := sanitizeName(input); actual != expected {
+			t.Errorf("sanitizeName(%q) 应为 %q，实际为 %q", input, expected, actual)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -173,6 +174,7 @@ func (ci *ConfigIntegration) reloadPluginConfig(pluginID string, config map[stri
 		Version:      plugin.Metadata.Version,
 		Settings:     config,
 		Dependencies: plugin.Metadata.Dependencies,
+		Services:     plugin.Services,
 	}
 
 	// 重新初始化插件
@@ -184,6 +186,38 @@ func (ci *ConfigIntegration) reloadPluginConfig(pluginID string, config map[stri
 	return nil
 }
 
+// UpgradePlugins 重新扫描插件目录，将版本发生变化的运行中独立进程插件原地升级为目录中的版本
+// 返回升级成功的插件ID；升级失败的插件继续运行旧版本，错误合并返回
+func (ci *ConfigIntegration) UpgradePlugins(ctx context.Context) ([]string, error) {
+	metadataList, err := ci.pluginManager.ScanPluginsDir()
+	if err != nil {
+		return nil, fmt.Errorf("扫描插件目录失败: %w", err)
+	}
+
+	var upgraded []string
+	var errs []error
+	for _, metadata := range metadataList {
+		if !ci.pluginManager.needsUpgrade(metadata) {
+			continue
+		}
+
+		moduleConfig := &ModuleConfig{
+			ID:           metadata.ID,
+			Name:         metadata.Name,
+			Version:      metadata.Version,
+			Settings:     ci.configManager.GetPluginConfig(metadata.ID),
+			Dependencies: metadata.Dependencies,
+		}
+		if err := ci.pluginManager.UpgradePlugin(ctx, metadata, moduleConfig); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		upgraded = append(upgraded, metadata.ID)
+	}
+
+	return upgraded, errors.Join(errs...)
+}
+
 // LoadPluginFromConfig 从配置加载插件
 func (ci *ConfigIntegration) LoadPluginFromConfig(pluginID string) (*PluginInstance, error) {
 	// 获取插件配置
@@ -56,6 +56,9 @@ type ModuleConfig struct {
 
 	// Resources 资源限制
 	Resources ResourceLimits `json:"resources"`
+
+	// Services 主机服务，调用受插件权限清单约束；主机未提供时各服务为nil
+	Services HostServices `json:"-"`
 }
 
 // ModuleInfo 模块信息
@@ -253,6 +256,12 @@ type PluginMetadata struct {
 	// MinFrameworkVersion 最低框架版本
 	MinFrameworkVersion string `json:"min_framework_version"`
 
+	// Permissions 权限清单，主机只向插件开放已声明的操作
+	Permissions PluginPermissions `json:"permissions"`
+
+	// Signature 入口点二进制文件的签名
+	Signature *PluginSignature `json:"signature,omitempty"`
+
 	// Path 插件路径（运行时填充）
 	Path string `json:"-"`
 }
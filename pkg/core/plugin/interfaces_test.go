@@ -172,7 +172,7 @@ func TestHealthCheck(t *testing.T) {
 	}
 
 	// 测试健康检查
-	healthCheck, ok := module.(HealthCheck)
+	healthCheck, ok := interface{}(module).(HealthCheck)
 	if !ok {
 		t.Fatal("模块未实现健康检查接口")
 	}
@@ -217,7 +217,7 @@ func TestResourceManager(t *testing.T) {
 	}
 
 	// 测试资源管理
-	resourceManager, ok := module.(ResourceManager)
+	resourceManager, ok := interface{}(module).(ResourceManager)
 	if !ok {
 		t.Fatal("模块未实现资源管理接口")
 	}
@@ -23,6 +23,11 @@ type PluginManager struct {
 	mu                  sync.RWMutex
 	healthCheckInterval time.Duration
 	eventBus            EventBus
+	hostServices        HostServices
+	trustStore          *TrustStore
+	signatureMode       SignatureMode
+	processLoaders      map[string]ProcessLoader
+	upgradeConfig       UpgradeConfig
 }
 
 // PluginInstance 插件实例
@@ -47,6 +52,18 @@ type PluginInstance struct {
 
 	// Process 插件进程（如果是独立进程）
 	Process *PluginProcess
+
+	// Services 按权限清单包装后的主机服务
+	Services HostServices
+
+	// Guard 插件权限检查器
+	Guard *PermissionGuard
+
+	// inflight 当前插件实例正在处理的请求，升级时等待旧实例的请求处理完毕
+	inflight *sync.WaitGroup
+
+	// upgrading 插件是否正在升级
+	upgrading bool
 }
 
 // PluginProcess 插件进程
@@ -105,6 +122,36 @@ func WithEventBus(eventBus EventBus) PluginManagerOption {
 	}
 }
 
+// WithHostServices 设置向插件提供的主机服务
+func WithHostServices(services HostServices) PluginManagerOption {
+	return func(pm *PluginManager) {
+		pm.hostServices = services
+	}
+}
+
+// WithSignatureVerification 设置插件签名校验
+// enforce 模式拒绝加载未签名或签名无效的插件，warn 模式只记录警告
+func WithSignatureVerification(store *TrustStore, mode SignatureMode) PluginManagerOption {
+	return func(pm *PluginManager) {
+		pm.trustStore = store
+		pm.signatureMode = mode
+	}
+}
+
+// WithProcessLoader 设置指定入口点类型的独立进程插件加载器
+func WithProcessLoader(entryType string, loader ProcessLoader) PluginManagerOption {
+	return func(pm *PluginManager) {
+		pm.processLoaders[entryType] = loader
+	}
+}
+
+// WithUpgradeConfig 设置插件升级配置
+func WithUpgradeConfig(config UpgradeConfig) PluginManagerOption {
+	return func(pm *PluginManager) {
+		pm.upgradeConfig = config
+	}
+}
+
 // NewPluginManager 创建插件管理器
 func NewPluginManager(options ...PluginManagerOption) *PluginManager {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -117,6 +164,9 @@ func NewPluginManager(options ...PluginManagerOption) *PluginManager {
 		cancel:              cancel,
 		healthCheckInterval: 30 * time.Second,
 		eventBus:            NewDefaultEventBus(),
+		signatureMode:       SignatureModeDisabled,
+		processLoaders:      make(map[string]ProcessLoader),
+		upgradeConfig:       DefaultUpgradeConfig(),
 	}
 
 	// 应用选项
@@ -124,6 +174,16 @@ func NewPluginManager(options ...PluginManagerOption) *PluginManager {
 		option(pm)
 	}
 
+	// 注册内置的独立进程插件加载器，选项中指定的同类型加载器优先
+	if _, exists := pm.processLoaders["python"]; !exists {
+		pm.processLoaders["python"] = NewPythonPluginLoader(pm.logger)
+	}
+
+	// 未指定插件路由服务时由管理器直接分发插件间请求
+	if pm.hostServices.Router == nil {
+		pm.hostServices.Router = pm
+	}
+
 	return pm
 }
 
@@ -231,31 +291,41 @@ func (pm *PluginManager) LoadPlugin(metadata PluginMetadata) (*PluginInstance, e
 		return nil, fmt.Errorf("插件已加载: %s", metadata.ID)
 	}
 
+	// 校验插件签名
+	if err := pm.verifySignature(metadata); err != nil {
+		return nil, err
+	}
+
 	// 创建插件实例
+	guard := pm.NewPermissionGuard(metadata)
 	instance := &PluginInstance{
 		Metadata:  metadata,
 		State:     PluginStateInitializing,
 		StartTime: time.Now(),
+		Services:  pm.hostServices.Guard(guard),
+		Guard:     guard,
+		inflight:  &sync.WaitGroup{},
 	}
 
 	// 根据插件类型加载
-	switch metadata.EntryPoint.Type {
-	case "go":
-		// 加载Go插件
-		module, err := pm.loadGoPlugin(metadata)
+	loader, hasLoader := pm.processLoaders[metadata.EntryPoint.Type]
+	switch {
+	case hasLoader:
+		// 使用注册的独立进程加载器
+		module, process, err := loader.LoadPlugin(metadata)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("加载插件进程失败: %w", err)
 		}
 		instance.Instance = module
+		instance.Process = process
 
-	case "python":
-		// 加载Python插件
-		module, process, err := pm.loadPythonPlugin(metadata)
+	case metadata.EntryPoint.Type == "go":
+		// 加载Go插件
+		module, err := pm.loadGoPlugin(metadata)
 		if err != nil {
 			return nil, err
 		}
 		instance.Instance = module
-		instance.Process = process
 
 	default:
 		return nil, fmt.Errorf("不支持的插件类型: %s", metadata.EntryPoint.Type)
@@ -268,6 +338,34 @@ func (pm *PluginManager) LoadPlugin(metadata PluginMetadata) (*PluginInstance, e
 	return instance, nil
 }
 
+// verifySignature 按签名校验模式检查插件签名
+func (pm *PluginManager) verifySignature(metadata PluginMetadata) error {
+	if pm.signatureMode == SignatureModeDisabled || pm.signatureMode == "" {
+		return nil
+	}
+
+	store := pm.trustStore
+	if store == nil {
+		store = NewTrustStore()
+	}
+
+	err := VerifyPluginSignature(metadata, store)
+	if err == nil {
+		pm.logger.Info("插件签名校验通过", "id", metadata.ID, "key_id", metadata.Signature.KeyID)
+		return nil
+	}
+
+	if pm.signatureMode == SignatureModeWarn {
+		pm.logger.Warn("插件签名校验失败，开发模式下继续加载", "id", metadata.ID, "error", err)
+		pm.publishPluginEvent(metadata.ID, "plugin.signature_warning")
+		return nil
+	}
+
+	pm.logger.Error("插件签名校验失败，拒绝加载", "id", metadata.ID, "error", err)
+	pm.publishPluginEvent(metadata.ID, "plugin.signature_rejected")
+	return fmt.Errorf("插件 %s 签名校验失败: %w", metadata.ID, err)
+}
+
 // loadGoPlugin 加载Go插件
 func (pm *PluginManager) loadGoPlugin(metadata PluginMetadata) (Module, error) {
 	// 这里实现Go插件加载逻辑
@@ -275,13 +373,6 @@ func (pm *PluginManager) loadGoPlugin(metadata PluginMetadata) (Module, error) {
 	return nil, fmt.Errorf("Go插件加载尚未实现")
 }
 
-// loadPythonPlugin 加载Python插件
-func (pm *PluginManager) loadPythonPlugin(metadata PluginMetadata) (Module, *PluginProcess, error) {
-	// 这里实现Python插件加载逻辑
-	// 在实际实现中，这里应该启动Python解释器并建立gRPC连接
-	return nil, nil, fmt.Errorf("Python插件加载尚未实现")
-}
-
 // StartPlugin 启动插件
 func (pm *PluginManager) StartPlugin(id string) error {
 	pm.mu.Lock()
@@ -402,6 +493,20 @@ func (pm *PluginManager) ListPlugins() []*PluginInstance {
 	return plugins
 }
 
+// NewPermissionGuard 为插件创建权限检查器，拒绝的操作会发布插件事件
+func (pm *PluginManager) NewPermissionGuard(metadata PluginMetadata) *PermissionGuard {
+	guard := NewPermissionGuard(metadata, pm.logger)
+	guard.onDenied = func(pluginID string, permission Permission, operation string) {
+		pm.publishPluginEvent(pluginID, "plugin.permission_denied")
+	}
+	return guard
+}
+
+// HostServicesFor 返回按插件权限清单包装后的主机服务
+func (pm *PluginManager) HostServicesFor(metadata PluginMetadata) HostServices {
+	return pm.hostServices.Guard(pm.NewPermissionGuard(metadata))
+}
+
 // publishPluginEvent 发布插件事件
 func (pm *PluginManager) publishPluginEvent(id string, eventType string) {
 	if pm.eventBus == nil {
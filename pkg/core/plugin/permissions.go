@@ -0,0 +1,321 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// Permission 插件权限
+type Permission string
+
+// 插件权限常量
+const (
+	// PermissionNetwork 访问网络
+	PermissionNetwork Permission = "network"
+
+	// PermissionFilesystem 访问文件系统（限于声明的路径）
+	PermissionFilesystem Permission = "filesystem"
+
+	// PermissionExec 执行外部命令
+	PermissionExec Permission = "exec"
+
+	// PermissionRegistry 访问注册表（仅Windows）
+	PermissionRegistry Permission = "registry"
+
+	// PermissionComm 使用主机通讯服务
+	PermissionComm Permission = "comm"
+
+	// PermissionStorage 使用主机存储服务
+	PermissionStorage Permission = "storage"
+
+	// PermissionPluginCall 通过主机调用其他插件（限于声明的插件）
+	PermissionPluginCall Permission = "plugin_call"
+)
+
+// ErrPermissionDenied 插件未声明所需权限
+var ErrPermissionDenied = errors.New("插件权限不足")
+
+// PluginPermissions 插件权限清单
+// 未声明的权限一律拒绝
+type PluginPermissions struct {
+	// Network 是否允许访问网络
+	Network bool `json:"network"`
+
+	// Exec 是否允许执行外部命令
+	Exec bool `json:"exec"`
+
+	// Registry 是否允许访问注册表
+	Registry bool `json:"registry"`
+
+	// Comm 是否允许使用主机通讯服务
+	Comm bool `json:"comm"`
+
+	// Storage 是否允许使用主机存储服务
+	Storage bool `json:"storage"`
+
+	// FilesystemPaths 允许访问的文件系统路径（包含子路径）
+	FilesystemPaths []string `json:"filesystem_paths,omitempty"`
+
+	// Plugins 允许通过主机调用的插件ID，* 表示任意插件
+	Plugins []string `json:"plugins,omitempty"`
+}
+
+// Allows 检查是否声明了指定权限
+func (p PluginPermissions) Allows(permission Permission) bool {
+	switch permission {
+	case PermissionNetwork:
+		return p.Network
+	case PermissionExec:
+		return p.Exec
+	case PermissionRegistry:
+		return p.Registry
+	case PermissionComm:
+		return p.Comm
+	case PermissionStorage:
+		return p.Storage
+	case PermissionFilesystem:
+		return len(p.FilesystemPaths) > 0
+	case PermissionPluginCall:
+		return len(p.Plugins) > 0
+	default:
+		return false
+	}
+}
+
+// AllowsPath 检查路径是否位于声明的文件系统路径之内
+func (p PluginPermissions) AllowsPath(path string) bool {
+	target, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+
+	for _, allowed := range p.FilesystemPaths {
+		base, err := filepath.Abs(allowed)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(base, target)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsPluginCall 检查是否允许调用指定插件
+func (p PluginPermissions) AllowsPluginCall(targetID string) bool {
+	for _, allowed := range p.Plugins {
+		if allowed == "*" || allowed == targetID {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionGuard 在主机与插件的边界上检查插件权限
+type PermissionGuard struct {
+	pluginID    string
+	permissions PluginPermissions
+	logger      hclog.Logger
+	onDenied    func(pluginID string, permission Permission, operation string)
+	denied      uint64
+}
+
+// NewPermissionGuard 创建插件权限检查器
+func NewPermissionGuard(metadata PluginMetadata, logger hclog.Logger) *PermissionGuard {
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+
+	return &PermissionGuard{
+		pluginID:    metadata.ID,
+		permissions: metadata.Permissions,
+		logger:      logger.Named("permission-guard").With("plugin_id", metadata.ID),
+	}
+}
+
+// Check 检查插件是否声明了指定权限，拒绝时记录日志
+func (g *PermissionGuard) Check(permission Permission, operation string) error {
+	if g.permissions.Allows(permission) {
+		return nil
+	}
+	return g.deny(permission, operation)
+}
+
+// CheckPath 检查插件是否可以访问指定路径
+func (g *PermissionGuard) CheckPath(path string) error {
+	if g.permissions.AllowsPath(path) {
+		return nil
+	}
+	return g.deny(PermissionFilesystem, path)
+}
+
+// CheckPluginCall 检查插件是否可以调用目标插件
+func (g *PermissionGuard) CheckPluginCall(targetID, action string) error {
+	if g.permissions.AllowsPluginCall(targetID) {
+		return nil
+	}
+	return g.deny(PermissionPluginCall, "plugin.call:"+targetID+"/"+action)
+}
+
+// DeniedCount 返回被拒绝的操作次数
+func (g *PermissionGuard) DeniedCount() uint64 {
+	return atomic.LoadUint64(&g.denied)
+}
+
+// deny 记录并返回权限拒绝错误
+func (g *PermissionGuard) deny(permission Permission, operation string) error {
+	atomic.AddUint64(&g.denied, 1)
+	g.logger.Warn("拒绝插件未声明权限的操作", "permission", permission, "operation", operation)
+
+	if g.onDenied != nil {
+		g.onDenied(g.pluginID, permission, operation)
+	}
+
+	return fmt.Errorf("%w: 插件 %s 未声明 %s 权限 (%s)", ErrPermissionDenied, g.pluginID, permission, operation)
+}
+
+// CommService 主机通讯服务
+type CommService interface {
+	// SendData 发送数据到服务端
+	SendData(dataType string, data interface{})
+
+	// SendEvent 发送事件到服务端
+	SendEvent(eventType string, details map[string]interface{})
+}
+
+// StorageService 主机存储服务
+type StorageService interface {
+	// Get 读取数据
+	Get(key string) ([]byte, error)
+
+	// Set 写入数据
+	Set(key string, value []byte) error
+
+	// Delete 删除数据
+	Delete(key string) error
+}
+
+// ExecService 主机命令执行服务
+type ExecService interface {
+	// Execute 执行命令并返回合并的输出
+	Execute(ctx context.Context, command string, args ...string) ([]byte, error)
+}
+
+// HostServices 主机向插件提供的服务
+type HostServices struct {
+	// Comm 通讯服务
+	Comm CommService
+
+	// Storage 存储服务
+	Storage StorageService
+
+	// Exec 命令执行服务
+	Exec ExecService
+
+	// Router 插件间请求路由服务
+	Router PluginRouter
+}
+
+// Guard 返回按插件权限清单包装后的服务，未声明权限的调用会被拒绝
+func (s HostServices) Guard(guard *PermissionGuard) HostServices {
+	guarded := HostServices{}
+	if s.Comm != nil {
+		guarded.Comm = &guardedCommService{next: s.Comm, guard: guard}
+	}
+	if s.Storage != nil {
+		guarded.Storage = &guardedStorageService{next: s.Storage, guard: guard}
+	}
+	if s.Exec != nil {
+		guarded.Exec = &guardedExecService{next: s.Exec, guard: guard}
+	}
+	if s.Router != nil {
+		guarded.Router = &guardedPluginRouter{next: s.Router, guard: guard}
+	}
+	return guarded
+}
+
+// DefaultExecService 使用本机进程执行命令的服务
+type DefaultExecService struct{}
+
+// Execute 执行命令
+func (DefaultExecService) Execute(ctx context.Context, command string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, command, args...).CombinedOutput()
+}
+
+// guardedCommService 受权限控制的通讯服务
+type guardedCommService struct {
+	next  CommService
+	guard *PermissionGuard
+}
+
+// SendData 发送数据
+func (s *guardedCommService) SendData(dataType string, data interface{}) {
+	if s.guard.Check(PermissionComm, "comm.send_data:"+dataType) != nil {
+		return
+	}
+	s.next.SendData(dataType, data)
+}
+
+// SendEvent 发送事件
+func (s *guardedCommService) SendEvent(eventType string, details map[string]interface{}) {
+	if s.guard.Check(PermissionComm, "comm.send_event:"+eventType) != nil {
+		return
+	}
+	s.next.SendEvent(eventType, details)
+}
+
+// guardedStorageService 受权限控制的存储服务
+type guardedStorageService struct {
+	next  StorageService
+	guard *PermissionGuard
+}
+
+// Get 读取数据
+func (s *guardedStorageService) Get(key string) ([]byte, error) {
+	if err := s.guard.Check(PermissionStorage, "storage.get:"+key); err != nil {
+		return nil, err
+	}
+	return s.next.Get(key)
+}
+
+// Set 写入数据
+func (s *guardedStorageService) Set(key string, value []byte) error {
+	if err := s.guard.Check(PermissionStorage, "storage.set:"+key); err != nil {
+		return err
+	}
+	return s.next.Set(key, value)
+}
+
+// Delete 删除数据
+func (s *guardedStorageService) Delete(key string) error {
+	if err := s.guard.Check(PermissionStorage, "storage.delete:"+key); err != nil {
+		return err
+	}
+	return s.next.Delete(key)
+}
+
+// guardedExecService 受权限控制的命令执行服务
+type guardedExecService struct {
+	next  ExecService
+	guard *PermissionGuard
+}
+
+// Execute 执行命令
+func (s *guardedExecService) Execute(ctx context.Context, command string, args ...string) ([]byte, error) {
+	if err := s.guard.Check(PermissionExec, "exec:"+command); err != nil {
+		return nil, err
+	}
+	return s.next.Execute(ctx, command, args...)
+}
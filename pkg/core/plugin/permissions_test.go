@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeExecService 记录调用的命令执行服务
+type fakeExecService struct {
+	commands []string
+}
+
+func (s *fakeExecService) Execute(ctx context.Context, command string, args ...string) ([]byte, error) {
+	s.commands = append(s.commands, command)
+	return []byte("ok"), nil
+}
+
+// TestHostServices_ExecRequiresPermission 测试未声明exec权限的插件无法执行命令
+func TestHostServices_ExecRequiresPermission(t *testing.T) {
+	execService := &fakeExecService{}
+	eventBus := NewDefaultEventBus()
+	pm := NewPluginManager(
+		WithHostServices(HostServices{Exec: execService}),
+		WithEventBus(eventBus),
+	)
+
+	denied := make(chan *Event, 1)
+	eventBus.Subscribe("plugin.permission_denied", func(ctx context.Context, event *Event) error {
+		denied <- event
+		return nil
+	})
+
+	restricted := PluginMetadata{ID: "restricted", Permissions: PluginPermissions{Comm: true}}
+	services := pm.HostServicesFor(restricted)
+
+	_, err := services.Exec.Execute(context.Background(), "whoami")
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("期望权限拒绝错误, 实际 %v", err)
+	}
+	if len(execService.commands) != 0 {
+		t.Errorf("未授权的命令不应被执行: %v", execService.commands)
+	}
+
+	select {
+	case event := <-denied:
+		if event.Data["plugin_id"] != "restricted" {
+			t.Errorf("事件插件ID不匹配: 期望 %s, 实际 %v", "restricted", event.Data["plugin_id"])
+		}
+	case <-time.After(time.Second):
+		t.Error("未发布权限拒绝事件")
+	}
+
+	allowed := PluginMetadata{ID: "allowed", Permissions: PluginPermissions{Exec: true}}
+	output, err := pm.HostServicesFor(allowed).Exec.Execute(context.Background(), "whoami")
+	if err != nil {
+		t.Fatalf("已授权的命令执行失败: %v", err)
+	}
+	if string(output) != "ok" || len(execService.commands) != 1 {
+		t.Errorf("命令执行结果不匹配: %s, %v", output, execService.commands)
+	}
+}
+
+// TestPermissionGuard_FilesystemPaths 测试文件系统路径权限
+func TestPermissionGuard_FilesystemPaths(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "data")
+	guard := NewPermissionGuard(PluginMetadata{
+		ID:          "fs",
+		Permissions: PluginPermissions{FilesystemPaths: []string{dataDir}},
+	}, nil)
+
+	if err := guard.CheckPath(filepath.Join(dataDir, "logs", "a.log")); err != nil {
+		t.Errorf("声明路径内的访问被拒绝: %v", err)
+	}
+	if err := guard.CheckPath(filepath.Join(dataDir, "..", "secret")); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("声明路径外的访问未被拒绝: %v", err)
+	}
+	if err := guard.CheckPath(dataDir + "-other"); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("相同前缀的其他路径未被拒绝: %v", err)
+	}
+	if err := guard.Check(PermissionRegistry, "HKLM\\Software"); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("未声明的注册表访问未被拒绝: %v", err)
+	}
+	if guard.DeniedCount() != 3 {
+		t.Errorf("拒绝次数不匹配: 期望 %d, 实际 %d", 3, guard.DeniedCount())
+	}
+}
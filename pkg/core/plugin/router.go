@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MetadataSourcePlugin 路由请求的元数据键，值为发起调用的插件ID
+const MetadataSourcePlugin = "source_plugin"
+
+// ErrPluginUnavailable 目标插件不存在或未在运行
+var ErrPluginUnavailable = errors.New("目标插件不可用")
+
+// PluginRouter 插件间请求路由服务
+// 插件通过主机向其他插件发送请求，主机将请求分发给目标插件的 HandleRequest 并返回响应
+type PluginRouter interface {
+	// CallPlugin 向指定插件发送请求并返回响应
+	CallPlugin(ctx context.Context, targetID string, req *Request) (*Response, error)
+}
+
+// CallPlugin 将请求分发给目标插件
+// 请求的 Timeout 大于0时按毫秒限制处理时间
+func (pm *PluginManager) CallPlugin(ctx context.Context, targetID string, req *Request) (*Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("请求不能为空")
+	}
+
+	// 在读锁内取得插件实例并登记请求，升级切换实例后旧实例不会再收到新请求
+	pm.mu.RLock()
+	target, exists := pm.plugins[targetID]
+	var state PluginState
+	var module Module
+	if exists {
+		state = target.State
+		module = target.Instance
+	}
+	if module != nil && state == PluginStateRunning && target.inflight != nil {
+		target.inflight.Add(1)
+		defer target.inflight.Done()
+	}
+	pm.mu.RUnlock()
+
+	if module == nil {
+		return nil, fmt.Errorf("%w: 插件不存在: %s", ErrPluginUnavailable, targetID)
+	}
+	if state != PluginStateRunning {
+		return nil, fmt.Errorf("%w: 插件未在运行: %s (%s)", ErrPluginUnavailable, targetID, state)
+	}
+
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.Timeout)*time.Millisecond)
+		defer cancel()
+	}
+
+	pm.logger.Debug("路由插件请求",
+		"source", req.Metadata[MetadataSourcePlugin],
+		"target", targetID,
+		"action", req.Action)
+
+	return module.HandleRequest(ctx, req)
+}
+
+// guardedPluginRouter 受权限控制的插件路由服务
+// 转发的请求在元数据中携带调用方插件ID，目标插件可据此识别调用方
+type guardedPluginRouter struct {
+	next  PluginRouter
+	guard *PermissionGuard
+}
+
+// CallPlugin 检查调用权限后转发请求
+func (r *guardedPluginRouter) CallPlugin(ctx context.Context, targetID string, req *Request) (*Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("请求不能为空")
+	}
+	if err := r.guard.CheckPluginCall(targetID, req.Action); err != nil {
+		return nil, err
+	}
+
+	// 复制请求，调用方无法伪造来源
+	routed := *req
+	routed.Metadata = make(map[string]string, len(req.Metadata)+1)
+	for k, v := range req.Metadata {
+		routed.Metadata[k] = v
+	}
+	routed.Metadata[MetadataSourcePlugin] = r.guard.pluginID
+
+	return r.next.CallPlugin(ctx, targetID, &routed)
+}
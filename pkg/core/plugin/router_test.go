@@ -0,0 +1,126 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// routingModule 记录收到请求的测试插件
+type routingModule struct {
+	testModule
+	received []*Request
+}
+
+// HandleRequest 返回设备信息，并记录请求
+func (m *routingModule) HandleRequest(ctx context.Context, req *Request) (*Response, error) {
+	m.received = append(m.received, req)
+	if req.Action != "get_device_info" {
+		return m.testModule.HandleRequest(ctx, req)
+	}
+	return &Response{
+		ID:      req.ID,
+		Success: true,
+		Data: map[string]interface{}{
+			"hostname": "host-01",
+			"caller":   req.Metadata[MetadataSourcePlugin],
+		},
+	}, nil
+}
+
+// addRoutingPlugin 向管理器添加运行中的测试插件，返回插件和按权限包装后的主机服务
+func addRoutingPlugin(pm *PluginManager, metadata PluginMetadata) (*routingModule, HostServices) {
+	module := &routingModule{testModule: testModule{id: metadata.ID}}
+	guard := pm.NewPermissionGuard(metadata)
+	instance := &PluginInstance{
+		Metadata:  metadata,
+		Instance:  module,
+		State:     PluginStateRunning,
+		StartTime: time.Now(),
+		Services:  pm.hostServices.Guard(guard),
+		Guard:     guard,
+	}
+
+	pm.mu.Lock()
+	pm.plugins[metadata.ID] = instance
+	pm.mu.Unlock()
+	return module, instance.Services
+}
+
+// TestPluginRouter_RoutesRequest 测试插件通过主机调用其他插件
+func TestPluginRouter_RoutesRequest(t *testing.T) {
+	pm := NewPluginManager()
+	_, dlpServices := addRoutingPlugin(pm, PluginMetadata{
+		ID:          "dlp",
+		Permissions: PluginPermissions{Plugins: []string{"assets"}},
+	})
+	assets, _ := addRoutingPlugin(pm, PluginMetadata{ID: "assets"})
+
+	req := &Request{
+		ID:       "req-1",
+		Action:   "get_device_info",
+		Metadata: map[string]string{MetadataSourcePlugin: "forged"},
+	}
+	resp, err := dlpServices.Router.CallPlugin(context.Background(), "assets", req)
+	if err != nil {
+		t.Fatalf("路由请求失败: %v", err)
+	}
+	if !resp.Success || resp.ID != "req-1" || resp.Data["hostname"] != "host-01" {
+		t.Errorf("响应不匹配: %+v", resp)
+	}
+
+	// 目标插件看到的调用方由主机填写，不能被伪造
+	if resp.Data["caller"] != "dlp" {
+		t.Errorf("调用方不匹配: 期望 dlp, 实际 %v", resp.Data["caller"])
+	}
+	if req.Metadata[MetadataSourcePlugin] != "forged" {
+		t.Errorf("不应修改调用方的请求: %v", req.Metadata)
+	}
+	if len(assets.received) != 1 {
+		t.Errorf("目标插件收到请求数不匹配: 期望 1, 实际 %d", len(assets.received))
+	}
+
+	// 目标插件未在运行
+	if err := pm.StopPlugin("assets"); err != nil {
+		t.Fatalf("停止插件失败: %v", err)
+	}
+	if _, err := dlpServices.Router.CallPlugin(context.Background(), "assets", req); !errors.Is(err, ErrPluginUnavailable) {
+		t.Errorf("期望目标插件不可用错误, 实际 %v", err)
+	}
+}
+
+// TestPluginRouter_PermissionDenied 测试未声明调用权限的插件无法调用其他插件
+func TestPluginRouter_PermissionDenied(t *testing.T) {
+	eventBus := NewDefaultEventBus()
+	pm := NewPluginManager(WithEventBus(eventBus))
+
+	denied := make(chan *Event, 1)
+	eventBus.Subscribe("plugin.permission_denied", func(ctx context.Context, event *Event) error {
+		denied <- event
+		return nil
+	})
+
+	dlp, _ := addRoutingPlugin(pm, PluginMetadata{ID: "dlp"})
+	_, assetsServices := addRoutingPlugin(pm, PluginMetadata{
+		ID:          "assets",
+		Permissions: PluginPermissions{Plugins: []string{"control"}},
+	})
+
+	_, err := assetsServices.Router.CallPlugin(context.Background(), "dlp", &Request{ID: "req-2", Action: "get_device_info"})
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Fatalf("期望权限拒绝错误, 实际 %v", err)
+	}
+	if len(dlp.received) != 0 {
+		t.Errorf("被拒绝的请求不应分发给目标插件: %v", dlp.received)
+	}
+
+	select {
+	case event := <-denied:
+		if event.Data["plugin_id"] != "assets" {
+			t.Errorf("事件插件ID不匹配: 期望 %s, 实际 %v", "assets", event.Data["plugin_id"])
+		}
+	case <-time.After(time.Second):
+		t.Error("未发布权限拒绝事件")
+	}
+}
@@ -0,0 +1,195 @@
+package plugin
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SignatureAlgorithmEd25519 插件签名算法
+const SignatureAlgorithmEd25519 = "ed25519"
+
+// SignatureMode 插件签名校验模式
+type SignatureMode string
+
+// 签名校验模式常量
+const (
+	// SignatureModeDisabled 不校验签名
+	SignatureModeDisabled SignatureMode = "disabled"
+
+	// SignatureModeWarn 签名缺失或无效时记录警告并继续加载，用于开发环境
+	SignatureModeWarn SignatureMode = "warn"
+
+	// SignatureModeEnforce 签名缺失或无效时拒绝加载
+	SignatureModeEnforce SignatureMode = "enforce"
+)
+
+var (
+	// ErrPluginUnsigned 插件清单未包含签名
+	ErrPluginUnsigned = errors.New("插件未签名")
+
+	// ErrPluginSignatureInvalid 插件签名无效或二进制文件已被篡改
+	ErrPluginSignatureInvalid = errors.New("插件签名无效")
+
+	// ErrUntrustedSigningKey 签名密钥不在信任库中
+	ErrUntrustedSigningKey = errors.New("插件签名密钥不受信任")
+)
+
+// PluginSignature 插件清单中的签名信息
+// 签名针对入口点二进制文件的 SHA-256 摘要
+type PluginSignature struct {
+	// KeyID 签名密钥标识，对应信任库中的公钥
+	KeyID string `json:"key_id"`
+
+	// Algorithm 签名算法，目前仅支持 ed25519
+	Algorithm string `json:"algorithm"`
+
+	// BinarySHA256 入口点二进制文件的 SHA-256 摘要（十六进制）
+	BinarySHA256 string `json:"binary_sha256"`
+
+	// Signature 对摘要的签名（Base64）
+	Signature string `json:"signature"`
+}
+
+// TrustStore 插件签名信任库
+type TrustStore struct {
+	keys map[string]ed25519.PublicKey
+	mu   sync.RWMutex
+}
+
+// NewTrustStore 创建空的信任库
+func NewTrustStore() *TrustStore {
+	return &TrustStore{
+		keys: make(map[string]ed25519.PublicKey),
+	}
+}
+
+// LoadTrustStore 从JSON文件加载信任库
+// 文件内容为密钥标识到 Base64 编码公钥的映射
+func LoadTrustStore(path string) (*TrustStore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取信任库失败: %w", err)
+	}
+
+	var encoded map[string]string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, fmt.Errorf("解析信任库失败: %w", err)
+	}
+
+	store := NewTrustStore()
+	for keyID, value := range encoded {
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("解析公钥 %s 失败: %w", keyID, err)
+		}
+		if err := store.AddKey(keyID, key); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// AddKey 添加受信任的公钥
+func (s *TrustStore) AddKey(keyID string, key []byte) error {
+	if keyID == "" {
+		return fmt.Errorf("密钥标识不能为空")
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("公钥 %s 长度无效: %d", keyID, len(key))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[keyID] = ed25519.PublicKey(key)
+	return nil
+}
+
+// Key 获取受信任的公钥
+func (s *TrustStore) Key(keyID string) (ed25519.PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, ok := s.keys[keyID]
+	return key, ok
+}
+
+// SignPluginBinary 为插件二进制文件生成签名，供打包工具写入插件清单
+func SignPluginBinary(path string, keyID string, key ed25519.PrivateKey) (*PluginSignature, error) {
+	digest, err := fileSHA256(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PluginSignature{
+		KeyID:        keyID,
+		Algorithm:    SignatureAlgorithmEd25519,
+		BinarySHA256: hex.EncodeToString(digest),
+		Signature:    base64.StdEncoding.EncodeToString(ed25519.Sign(key, digest)),
+	}, nil
+}
+
+// VerifyPluginSignature 使用信任库校验插件签名
+// 重新计算入口点二进制文件的摘要，防止清单与文件不一致
+func VerifyPluginSignature(metadata PluginMetadata, store *TrustStore) error {
+	signature := metadata.Signature
+	if signature == nil || signature.Signature == "" {
+		return ErrPluginUnsigned
+	}
+	if !strings.EqualFold(signature.Algorithm, SignatureAlgorithmEd25519) {
+		return fmt.Errorf("%w: 不支持的签名算法 %q", ErrPluginSignatureInvalid, signature.Algorithm)
+	}
+
+	key, ok := store.Key(signature.KeyID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUntrustedSigningKey, signature.KeyID)
+	}
+
+	digest, err := fileSHA256(pluginBinaryPath(metadata))
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(hex.EncodeToString(digest), signature.BinarySHA256) {
+		return fmt.Errorf("%w: 二进制文件摘要与清单不一致", ErrPluginSignatureInvalid)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: 签名编码无效", ErrPluginSignatureInvalid)
+	}
+	if !ed25519.Verify(key, digest, sig) {
+		return fmt.Errorf("%w: 签名校验失败", ErrPluginSignatureInvalid)
+	}
+	return nil
+}
+
+// pluginBinaryPath 返回插件入口点文件路径，相对路径基于插件目录
+func pluginBinaryPath(metadata PluginMetadata) string {
+	if filepath.IsAbs(metadata.EntryPoint.Path) {
+		return metadata.EntryPoint.Path
+	}
+	return filepath.Join(metadata.Path, metadata.EntryPoint.Path)
+}
+
+// fileSHA256 计算文件的 SHA-256 摘要
+func fileSHA256(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开插件文件失败: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, fmt.Errorf("读取插件文件失败: %w", err)
+	}
+	return hash.Sum(nil), nil
+}
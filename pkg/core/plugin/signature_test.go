@@ -0,0 +1,159 @@
+package plugin
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newSignedPlugin 创建测试插件二进制文件并使用给定密钥签名
+func newSignedPlugin(t *testing.T, key ed25519.PrivateKey) PluginMetadata {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "plugin.bin"), []byte("plugin binary v1"), 0755); err != nil {
+		t.Fatalf("写入插件文件失败: %v", err)
+	}
+
+	metadata := PluginMetadata{
+		ID:         "signed",
+		Name:       "signed",
+		Version:    "1.0.0",
+		EntryPoint: PluginEntryPoint{Type: "go", Path: "plugin.bin"},
+		Path:       dir,
+	}
+	if key != nil {
+		signature, err := SignPluginBinary(pluginBinaryPath(metadata), "release", key)
+		if err != nil {
+			t.Fatalf("签名插件失败: %v", err)
+		}
+		metadata.Signature = signature
+	}
+	return metadata
+}
+
+// newTestTrustStore 创建包含发布密钥的信任库
+func newTestTrustStore(t *testing.T) (*TrustStore, ed25519.PrivateKey) {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "trust.json")
+	content := `{"release": "` + base64.StdEncoding.EncodeToString(public) + `"}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("写入信任库失败: %v", err)
+	}
+	store, err := LoadTrustStore(path)
+	if err != nil {
+		t.Fatalf("加载信任库失败: %v", err)
+	}
+	return store, private
+}
+
+// isSignatureError 判断是否为签名校验错误
+func isSignatureError(err error) bool {
+	return errors.Is(err, ErrPluginUnsigned) ||
+		errors.Is(err, ErrPluginSignatureInvalid) ||
+		errors.Is(err, ErrUntrustedSigningKey)
+}
+
+// TestLoadPlugin_SignedPluginPassesVerification 测试签名正确的插件通过校验
+func TestLoadPlugin_SignedPluginPassesVerification(t *testing.T) {
+	store, key := newTestTrustStore(t)
+	metadata := newSignedPlugin(t, key)
+
+	if err := VerifyPluginSignature(metadata, store); err != nil {
+		t.Fatalf("签名正确的插件应通过校验: %v", err)
+	}
+
+	pm := NewPluginManager(WithSignatureVerification(store, SignatureModeEnforce))
+	if _, err := pm.LoadPlugin(metadata); isSignatureError(err) {
+		t.Errorf("签名正确的插件不应因签名被拒绝: %v", err)
+	}
+}
+
+// TestLoadPlugin_TamperedBinaryRefused 测试二进制文件被篡改后拒绝加载
+func TestLoadPlugin_TamperedBinaryRefused(t *testing.T) {
+	store, key := newTestTrustStore(t)
+	metadata := newSignedPlugin(t, key)
+
+	if err := os.WriteFile(pluginBinaryPath(metadata), []byte("plugin binary v1 + payload"), 0755); err != nil {
+		t.Fatalf("篡改插件文件失败: %v", err)
+	}
+
+	pm := NewPluginManager(WithSignatureVerification(store, SignatureModeEnforce))
+	_, err := pm.LoadPlugin(metadata)
+	if !errors.Is(err, ErrPluginSignatureInvalid) {
+		t.Fatalf("篡改后的插件应被拒绝，实际错误: %v", err)
+	}
+	if _, exists := pm.GetPlugin(metadata.ID); exists {
+		t.Error("被拒绝的插件不应被注册")
+	}
+
+	// 同时篡改清单中的摘要也无法通过签名校验
+	metadata.Signature.BinarySHA256 = "00"
+	if err := VerifyPluginSignature(metadata, store); !errors.Is(err, ErrPluginSignatureInvalid) {
+		t.Errorf("摘要不一致时应返回签名无效，实际: %v", err)
+	}
+}
+
+// TestLoadPlugin_UntrustedKeyRefused 测试信任库之外的密钥签名被拒绝
+func TestLoadPlugin_UntrustedKeyRefused(t *testing.T) {
+	store, _ := newTestTrustStore(t)
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	metadata := newSignedPlugin(t, otherKey)
+	metadata.Signature.KeyID = "unknown"
+
+	if err := VerifyPluginSignature(metadata, store); !errors.Is(err, ErrUntrustedSigningKey) {
+		t.Errorf("未受信任的密钥应被拒绝，实际: %v", err)
+	}
+
+	// 冒用受信任密钥标识的签名同样无效
+	metadata.Signature.KeyID = "release"
+	if err := VerifyPluginSignature(metadata, store); !errors.Is(err, ErrPluginSignatureInvalid) {
+		t.Errorf("冒用密钥标识的签名应无效，实际: %v", err)
+	}
+}
+
+// TestLoadPlugin_UnsignedPlugin 测试未签名插件在强制模式下被拒绝、在开发模式下继续加载
+func TestLoadPlugin_UnsignedPlugin(t *testing.T) {
+	store, _ := newTestTrustStore(t)
+	metadata := newSignedPlugin(t, nil)
+
+	enforce := NewPluginManager(WithSignatureVerification(store, SignatureModeEnforce))
+	if _, err := enforce.LoadPlugin(metadata); !errors.Is(err, ErrPluginUnsigned) {
+		t.Errorf("强制模式下未签名插件应被拒绝，实际错误: %v", err)
+	}
+
+	warned := make(chan string, 1)
+	eventBus := NewDefaultEventBus()
+	eventBus.Subscribe("plugin.signature_warning", func(ctx context.Context, event *Event) error {
+		warned <- event.Data["plugin_id"].(string)
+		return nil
+	})
+	warn := NewPluginManager(WithSignatureVerification(store, SignatureModeWarn), WithEventBus(eventBus))
+	if _, err := warn.LoadPlugin(metadata); isSignatureError(err) {
+		t.Errorf("开发模式下未签名插件不应被拒绝: %v", err)
+	}
+	select {
+	case id := <-warned:
+		if id != metadata.ID {
+			t.Errorf("警告事件的插件ID不正确: %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Error("开发模式下应发布签名警告事件")
+	}
+
+	// 未启用签名校验时保持原有行为
+	if _, err := NewPluginManager().LoadPlugin(metadata); isSignatureError(err) {
+		t.Errorf("未启用签名校验时不应校验签名: %v", err)
+	}
+}
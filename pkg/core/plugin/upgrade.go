@@ -0,0 +1,220 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUpgradeInProgress 插件正在升级
+	ErrUpgradeInProgress = errors.New("插件正在升级")
+
+	// ErrUpgradeHealthCheckFailed 新版本插件启动后未通过健康检查，已回滚到旧版本
+	ErrUpgradeHealthCheckFailed = errors.New("新版本插件未通过健康检查")
+)
+
+// ProcessLoader 独立进程插件加载器，启动插件进程并返回与之通信的模块
+// PythonPluginLoader 实现了该接口
+type ProcessLoader interface {
+	// LoadPlugin 按元数据启动插件进程
+	LoadPlugin(metadata PluginMetadata) (Module, *PluginProcess, error)
+}
+
+// UpgradeConfig 插件升级配置
+type UpgradeConfig struct {
+	// HealthTimeout 等待新版本插件通过健康检查的最长时间
+	HealthTimeout time.Duration
+
+	// HealthInterval 健康检查间隔
+	HealthInterval time.Duration
+
+	// DrainTimeout 等待旧版本插件处理完正在进行的请求的最长时间，超时后强制停止
+	DrainTimeout time.Duration
+}
+
+// DefaultUpgradeConfig 返回默认插件升级配置
+func DefaultUpgradeConfig() UpgradeConfig {
+	return UpgradeConfig{
+		HealthTimeout:  30 * time.Second,
+		HealthInterval: time.Second,
+		DrainTimeout:   30 * time.Second,
+	}
+}
+
+// UpgradePlugin 将运行中的独立进程插件原地升级为新版本
+// 先启动新版本进程并等待其通过健康检查，再将后续请求切换到新版本，
+// 等待旧版本处理完正在进行的请求后停止旧版本。新版本启动失败或未通过健康检查时停止新版本，旧版本继续运行。
+// config 不为空时用于初始化新版本插件
+func (pm *PluginManager) UpgradePlugin(ctx context.Context, metadata PluginMetadata, config *ModuleConfig) error {
+	id := metadata.ID
+
+	pm.mu.Lock()
+	plugin, exists := pm.plugins[id]
+	if !exists {
+		pm.mu.Unlock()
+		return fmt.Errorf("插件不存在: %s", id)
+	}
+	if plugin.State != PluginStateRunning {
+		pm.mu.Unlock()
+		return fmt.Errorf("插件未在运行: %s", id)
+	}
+	if plugin.upgrading {
+		pm.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUpgradeInProgress, id)
+	}
+	plugin.upgrading = true
+	oldVersion := plugin.Metadata.Version
+	pm.mu.Unlock()
+
+	defer func() {
+		pm.mu.Lock()
+		plugin.upgrading = false
+		pm.mu.Unlock()
+	}()
+
+	pm.logger.Info("开始升级插件", "id", id, "from", oldVersion, "to", metadata.Version)
+
+	module, process, err := pm.startUpgradedPlugin(ctx, metadata, config)
+	if err != nil {
+		pm.logger.Error("插件升级失败，继续使用旧版本", "id", id, "version", oldVersion, "error", err)
+		pm.publishPluginEvent(id, "plugin.upgrade_rolled_back")
+		return fmt.Errorf("升级插件 %s 失败: %w", id, err)
+	}
+
+	// 切换到新版本，之后的请求由新版本处理
+	guard := pm.NewPermissionGuard(metadata)
+	pm.mu.Lock()
+	oldModule, oldProcess, oldInflight := plugin.Instance, plugin.Process, plugin.inflight
+	plugin.Metadata = metadata
+	plugin.Instance = module
+	plugin.Process = process
+	plugin.Guard = guard
+	plugin.Services = pm.hostServices.Guard(guard)
+	plugin.StartTime = time.Now()
+	plugin.LastError = nil
+	plugin.inflight = &sync.WaitGroup{}
+	pm.mu.Unlock()
+
+	// 等待旧版本处理完正在进行的请求后停止
+	if !waitInflight(oldInflight, pm.upgradeConfig.DrainTimeout) {
+		pm.logger.Warn("等待旧版本插件处理请求超时，强制停止", "id", id, "version", oldVersion, "timeout", pm.upgradeConfig.DrainTimeout)
+	}
+	if err := stopPluginModule(oldModule, oldProcess); err != nil {
+		pm.logger.Warn("停止旧版本插件失败", "id", id, "version", oldVersion, "error", err)
+	}
+
+	pm.publishPluginEvent(id, "plugin.upgraded")
+	pm.logger.Info("插件已升级", "id", id, "from", oldVersion, "to", metadata.Version)
+	return nil
+}
+
+// needsUpgrade 判断运行中的插件是否可以升级到元数据中的不同版本
+func (pm *PluginManager) needsUpgrade(metadata PluginMetadata) bool {
+	if _, exists := pm.processLoaders[metadata.EntryPoint.Type]; !exists {
+		return false
+	}
+
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	plugin, exists := pm.plugins[metadata.ID]
+	return exists && plugin.State == PluginStateRunning && !plugin.upgrading && plugin.Metadata.Version != metadata.Version
+}
+
+// startUpgradedPlugin 启动新版本插件并等待其通过健康检查，失败时停止新版本
+func (pm *PluginManager) startUpgradedPlugin(ctx context.Context, metadata PluginMetadata, config *ModuleConfig) (Module, *PluginProcess, error) {
+	if err := pm.verifySignature(metadata); err != nil {
+		return nil, nil, err
+	}
+
+	loader, exists := pm.processLoaders[metadata.EntryPoint.Type]
+	if !exists {
+		return nil, nil, fmt.Errorf("不支持升级的插件类型: %s", metadata.EntryPoint.Type)
+	}
+
+	module, process, err := loader.LoadPlugin(metadata)
+	if err != nil {
+		return nil, nil, fmt.Errorf("启动新版本插件进程失败: %w", err)
+	}
+
+	if config != nil {
+		if err := module.Init(ctx, config); err != nil {
+			_ = stopPluginModule(module, process)
+			return nil, nil, fmt.Errorf("初始化新版本插件失败: %w", err)
+		}
+	}
+	if err := module.Start(); err != nil {
+		_ = stopPluginModule(module, process)
+		return nil, nil, fmt.Errorf("启动新版本插件失败: %w", err)
+	}
+
+	if err := pm.waitHealthy(ctx, module); err != nil {
+		_ = stopPluginModule(module, process)
+		return nil, nil, err
+	}
+	return module, process, nil
+}
+
+// waitHealthy 等待插件通过健康检查，未实现健康检查接口的插件启动成功即视为健康
+func (pm *PluginManager) waitHealthy(ctx context.Context, module Module) error {
+	healthCheck, ok := module.(HealthCheck)
+	if !ok {
+		return nil
+	}
+
+	interval := pm.upgradeConfig.HealthInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, pm.upgradeConfig.HealthTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status := healthCheck.CheckHealth()
+		if status.Status == "healthy" {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: 状态 %s", ErrUpgradeHealthCheckFailed, status.Status)
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitInflight 等待请求处理完毕，超时返回false
+func waitInflight(inflight *sync.WaitGroup, timeout time.Duration) bool {
+	if inflight == nil {
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// stopPluginModule 停止插件模块，模块停止失败时结束插件进程
+func stopPluginModule(module Module, process *PluginProcess) error {
+	err := module.Stop()
+	if err != nil && process != nil {
+		if cmd, ok := process.Cmd.(*exec.Cmd); ok && cmd.Process != nil {
+			_ = cmd.Process.Kill()
+		}
+	}
+	return err
+}
@@ -0,0 +1,344 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/core/config"
+)
+
+// upgradeModule 模拟独立进程插件，可阻塞请求并返回指定的健康状态
+type upgradeModule struct {
+	testModule
+	health  string
+	block   chan struct{} // 不为空时 slow 请求阻塞到通道关闭
+	entered chan struct{}
+
+	mu      sync.Mutex
+	stopped bool
+}
+
+// HandleRequest 返回处理请求的插件版本
+func (m *upgradeModule) HandleRequest(ctx context.Context, req *Request) (*Response, error) {
+	if req.Action == "slow" && m.block != nil {
+		m.entered <- struct{}{}
+		<-m.block
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return nil, errors.New("插件进程已退出")
+	}
+	return &Response{ID: req.ID, Success: true, Data: map[string]interface{}{"version": m.version}}, nil
+}
+
+// CheckHealth 返回指定的健康状态
+func (m *upgradeModule) CheckHealth() HealthStatus {
+	return HealthStatus{Status: m.health}
+}
+
+// Stop 模拟结束插件进程
+func (m *upgradeModule) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+	return nil
+}
+
+func (m *upgradeModule) isStopped() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stopped
+}
+
+// fakeProcessLoader 按版本返回预先准备的插件模块，模拟启动插件子进程
+type fakeProcessLoader struct {
+	mu      sync.Mutex
+	modules map[string]*upgradeModule
+	loaded  []string
+}
+
+func (l *fakeProcessLoader) LoadPlugin(metadata PluginMetadata) (Module, *PluginProcess, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	module, ok := l.modules[metadata.Version]
+	if !ok {
+		return nil, nil, errors.New("插件二进制文件不存在")
+	}
+	l.loaded = append(l.loaded, metadata.Version)
+	return module, &PluginProcess{PID: len(l.loaded)}, nil
+}
+
+func upgradeMetadata(version string) PluginMetadata {
+	return PluginMetadata{
+		ID:         "assets",
+		Name:       "资产管理",
+		Version:    version,
+		EntryPoint: PluginEntryPoint{Type: "process", Path: "assets-" + version},
+	}
+}
+
+// newUpgradeTestManager 创建使用模拟加载器的管理器，并加载、启动1.0.0版本
+func newUpgradeTestManager(t *testing.T, modules map[string]*upgradeModule) (*PluginManager, *fakeProcessLoader) {
+	loader := &fakeProcessLoader{modules: modules}
+	pm := NewPluginManager(
+		WithProcessLoader("process", loader),
+		WithUpgradeConfig(UpgradeConfig{
+			HealthTimeout:  100 * time.Millisecond,
+			HealthInterval: 10 * time.Millisecond,
+			DrainTimeout:   2 * time.Second,
+		}),
+	)
+
+	if _, err := pm.LoadPlugin(upgradeMetadata("1.0.0")); err != nil {
+		t.Fatalf("加载插件失败: %v", err)
+	}
+	if err := pm.StartPlugin("assets"); err != nil {
+		t.Fatalf("启动插件失败: %v", err)
+	}
+	return pm, loader
+}
+
+// pluginVersion 在锁内读取插件当前版本
+func pluginVersion(pm *PluginManager, id string) string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.plugins[id].Metadata.Version
+}
+
+func callVersion(t *testing.T, pm *PluginManager, action string) string {
+	resp, err := pm.CallPlugin(context.Background(), "assets", &Request{ID: action, Action: action})
+	if err != nil {
+		t.Fatalf("调用插件失败: %v", err)
+	}
+	version, _ := resp.Data["version"].(string)
+	return version
+}
+
+// TestUpgradePlugin 测试升级成功：等待旧版本处理完正在进行的请求后再停止旧版本
+func TestUpgradePlugin(t *testing.T) {
+	v1 := &upgradeModule{
+		testModule: testModule{id: "assets", version: "1.0.0"},
+		health:     "healthy",
+		block:      make(chan struct{}),
+		entered:    make(chan struct{}, 1),
+	}
+	v2 := &upgradeModule{testModule: testModule{id: "assets", version: "2.0.0"}, health: "healthy"}
+	pm, loader := newUpgradeTestManager(t, map[string]*upgradeModule{"1.0.0": v1, "2.0.0": v2})
+	defer pm.Stop()
+
+	// 旧版本上有一个正在处理的请求
+	slowResult := make(chan string, 1)
+	go func() {
+		resp, err := pm.CallPlugin(context.Background(), "assets", &Request{ID: "slow", Action: "slow"})
+		if err != nil {
+			slowResult <- err.Error()
+			return
+		}
+		slowResult <- resp.Data["version"].(string)
+	}()
+	<-v1.entered
+
+	upgraded := make(chan error, 1)
+	go func() { upgraded <- pm.UpgradePlugin(context.Background(), upgradeMetadata("2.0.0"), nil) }()
+
+	// 切换后的新请求由新版本处理，旧版本在请求完成前不会被停止
+	deadline := time.After(2 * time.Second)
+	for {
+		if pluginVersion(pm, "assets") == "2.0.0" {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("未切换到新版本")
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	if version := callVersion(t, pm, "test"); version != "2.0.0" {
+		t.Errorf("切换后的请求应由新版本处理，实际为 %s", version)
+	}
+	if v1.isStopped() {
+		t.Error("旧版本在正在进行的请求完成前不应被停止")
+	}
+
+	close(v1.block)
+	if version := <-slowResult; version != "1.0.0" {
+		t.Errorf("正在进行的请求应由旧版本处理完毕，实际为 %s", version)
+	}
+	if err := <-upgraded; err != nil {
+		t.Fatalf("升级插件失败: %v", err)
+	}
+
+	if !v1.isStopped() {
+		t.Error("升级完成后旧版本应被停止")
+	}
+	if !v2.started || v2.isStopped() {
+		t.Error("升级完成后新版本应在运行")
+	}
+	instance, _ := pm.GetPlugin("assets")
+	if instance.State != PluginStateRunning || instance.Process.PID != 2 {
+		t.Errorf("插件实例应指向新版本进程: state=%s, pid=%d", instance.State, instance.Process.PID)
+	}
+	if len(loader.loaded) != 2 {
+		t.Errorf("应启动两个插件进程，实际为 %v", loader.loaded)
+	}
+}
+
+// TestUpgradePlugin_RollbackOnHealthCheck 测试新版本未通过健康检查时回滚
+func TestUpgradePlugin_RollbackOnHealthCheck(t *testing.T) {
+	v1 := &upgradeModule{testModule: testModule{id: "assets", version: "1.0.0"}, health: "healthy"}
+	v2 := &upgradeModule{testModule: testModule{id: "assets", version: "2.0.0"}, health: "unhealthy"}
+	pm, _ := newUpgradeTestManager(t, map[string]*upgradeModule{"1.0.0": v1, "2.0.0": v2})
+	defer pm.Stop()
+
+	events := make(chan string, 10)
+	pm.eventBus.Subscribe("plugin.upgrade_rolled_back", func(ctx context.Context, event *Event) error {
+		events <- event.Type
+		return nil
+	})
+
+	err := pm.UpgradePlugin(context.Background(), upgradeMetadata("2.0.0"), nil)
+	if !errors.Is(err, ErrUpgradeHealthCheckFailed) {
+		t.Fatalf("应返回健康检查失败错误，实际为 %v", err)
+	}
+
+	if !v2.isStopped() {
+		t.Error("未通过健康检查的新版本应被停止")
+	}
+	if v1.isStopped() {
+		t.Error("回滚后旧版本应继续运行")
+	}
+	instance, _ := pm.GetPlugin("assets")
+	if instance.Metadata.Version != "1.0.0" || instance.State != PluginStateRunning {
+		t.Errorf("回滚后插件应保持旧版本运行: version=%s, state=%s", instance.Metadata.Version, instance.State)
+	}
+	if version := callVersion(t, pm, "test"); version != "1.0.0" {
+		t.Errorf("回滚后请求应由旧版本处理，实际为 %s", version)
+	}
+
+	select {
+	case <-events:
+	case <-time.After(time.Second):
+		t.Error("回滚应发布插件事件")
+	}
+
+	// 新版本二进制文件不存在时同样保持旧版本
+	if err := pm.UpgradePlugin(context.Background(), upgradeMetadata("3.0.0"), nil); err == nil {
+		t.Error("新版本无法启动时应返回错误")
+	}
+	if version := callVersion(t, pm, "test"); version != "1.0.0" {
+		t.Errorf("升级失败后请求应由旧版本处理，实际为 %s", version)
+	}
+}
+
+// writeScriptPlugin 在插件目录中写入指定版本的插件，插件脚本按Python插件协议通信，由 sh 解释执行
+func writeScriptPlugin(t *testing.T, pluginsDir, version string) {
+	t.Helper()
+	dir := filepath.Join(pluginsDir, "echo")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("创建插件目录失败: %v", err)
+	}
+
+	// 每个版本使用单独的脚本文件，避免覆盖正在运行的旧版本脚本
+	script := fmt.Sprintf(`echo 'KENNEL_PLUGIN_READY:{"id":"echo","name":"echo","version":"%[1]s"}'
+while read -r line; do
+  case "$line" in
+    KENNEL_STOP) exit 0 ;;
+    KENNEL_COMMAND:*) echo 'KENNEL_RESPONSE:{"id":"version","success":true,"data":{"version":"%[1]s"}}' ;;
+  esac
+done
+`, version)
+	scriptName := "echo-" + version + ".sh"
+	if err := os.WriteFile(filepath.Join(dir, scriptName), []byte(script), 0644); err != nil {
+		t.Fatalf("写入插件脚本失败: %v", err)
+	}
+
+	metadata := fmt.Sprintf(`{"id":"echo","name":"echo","version":"%s","entry_point":{"type":"python","path":"%s","interpreter":"sh"}}`, version, scriptName)
+	if err := os.WriteFile(filepath.Join(dir, "plugin.json"), []byte(metadata), 0644); err != nil {
+		t.Fatalf("写入插件元数据失败: %v", err)
+	}
+}
+
+// TestConfigIntegration_UpgradePlugins 测试通过默认注册的Python插件加载器升级真实的插件进程
+func TestConfigIntegration_UpgradePlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("测试插件脚本需要 sh")
+	}
+
+	pluginsDir := t.TempDir()
+	writeScriptPlugin(t, pluginsDir, "1.0.0")
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("plugins:\n  echo:\n    enabled: true\n"), 0644); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+	configManager, err := config.NewConfigManager(config.WithConfigPath(configPath))
+	if err != nil {
+		t.Fatalf("创建配置管理器失败: %v", err)
+	}
+	defer configManager.Close()
+
+	// 未指定进程加载器，使用管理器内置的加载器
+	pm := NewPluginManager(
+		WithPluginsDir(pluginsDir),
+		WithUpgradeConfig(UpgradeConfig{
+			HealthTimeout:  time.Second,
+			HealthInterval: 10 * time.Millisecond,
+			DrainTimeout:   time.Second,
+		}),
+	)
+	defer pm.Stop()
+	ci := NewConfigIntegration(configManager, pm)
+
+	if _, err := ci.LoadPluginFromConfig("echo"); err != nil {
+		t.Fatalf("加载插件失败: %v", err)
+	}
+	call := func() string {
+		resp, err := pm.CallPlugin(context.Background(), "echo", &Request{ID: "version", Action: "version", Timeout: 5000})
+		if err != nil {
+			t.Fatalf("调用插件失败: %v", err)
+		}
+		version, _ := resp.Data["version"].(string)
+		return version
+	}
+	if version := call(); version != "1.0.0" {
+		t.Fatalf("升级前应由1.0.0版本处理请求，实际为 %s", version)
+	}
+	oldPlugin, _ := pm.GetPlugin("echo")
+	oldCmd := oldPlugin.Process.Cmd.(*exec.Cmd)
+
+	// 插件目录中的版本未变化时不升级
+	upgraded, err := ci.UpgradePlugins(context.Background())
+	if err != nil || len(upgraded) != 0 {
+		t.Fatalf("版本未变化时不应升级: %v, %v", upgraded, err)
+	}
+
+	writeScriptPlugin(t, pluginsDir, "2.0.0")
+	upgraded, err = ci.UpgradePlugins(context.Background())
+	if err != nil {
+		t.Fatalf("升级插件失败: %v", err)
+	}
+	if len(upgraded) != 1 || upgraded[0] != "echo" {
+		t.Fatalf("应升级echo插件，实际为 %v", upgraded)
+	}
+
+	if version := pluginVersion(pm, "echo"); version != "2.0.0" {
+		t.Errorf("升级后插件版本应为2.0.0，实际为 %s", version)
+	}
+	if version := call(); version != "2.0.0" {
+		t.Errorf("升级后应由2.0.0版本处理请求，实际为 %s", version)
+	}
+	if oldCmd.ProcessState == nil || !oldCmd.ProcessState.Exited() {
+		t.Error("升级后旧版本插件进程应已退出")
+	}
+}
@@ -291,6 +291,29 @@ func (pm *PluginManager) ListPlugins() []pluginLib.ModuleInfo {
 	return result
 }
 
+// GetPluginMetrics 汇总所有已加载插件的指标
+// 进程外插件的指标通过 GetMetrics RPC 获取，不支持指标的插件不包含在结果中
+func (pm *PluginManager) GetPluginMetrics() map[string]map[string]interface{} {
+	pm.mu.RLock()
+	instances := make(map[string]pluginLib.Module, len(pm.plugins))
+	for name, p := range pm.plugins {
+		instances[name] = p.instance
+	}
+	pm.mu.RUnlock()
+
+	// 在锁外调用插件，避免慢插件阻塞插件管理
+	result := make(map[string]map[string]interface{}, len(instances))
+	for name, instance := range instances {
+		metricable, ok := instance.(pluginLib.Metricable)
+		if !ok {
+			continue
+		}
+		result[name] = metricable.GetMetrics()
+	}
+
+	return result
+}
+
 // ClosePlugin 关闭指定名称的插件
 func (pm *PluginManager) ClosePlugin(name string) error {
 	pm.mu.Lock()
@@ -0,0 +1,285 @@
+// Package pluginreload 根据重新加载的配置启动或停止插件
+// 插件的 enabled 配置变化时计算需要启动和停止的插件：停用插件时一并停止依赖它的插件，
+// 启用插件时先启动它依赖的插件，依赖未启用的插件不会启动。停止按依赖关系的逆序进行，
+// 每个插件有独立的停止时限，启动按依赖关系的顺序进行
+package pluginreload
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/core/shutdown"
+)
+
+// Reason 启动或停止插件的原因
+type Reason string
+
+const (
+	ReasonEnabled    Reason = "enabled"    // 插件在配置中被启用
+	ReasonDisabled   Reason = "disabled"   // 插件在配置中被停用
+	ReasonDependency Reason = "dependency" // 依赖关系：依赖的插件被停用或启用，或被启用的插件依赖本插件
+)
+
+// Status 启动结果状态
+type Status string
+
+const (
+	StatusStarted Status = "started" // 启动成功
+	StatusFailed  Status = "failed"  // 启动失败
+	StatusSkipped Status = "skipped" // 依赖的插件未能启动，未尝试启动
+)
+
+// Plugin 插件在配置重新加载前后的状态
+type Plugin struct {
+	ID           string
+	Dependencies []string // 依赖的插件ID，未配置的依赖被忽略
+	WasEnabled   bool     // 重新加载前是否启用
+	Enabled      bool     // 重新加载后是否启用
+	Running      bool     // 当前是否运行
+}
+
+// Step 计划中的一个操作
+type Step struct {
+	Plugin string `json:"plugin"`
+	Reason Reason `json:"reason"`
+}
+
+// Blocked 已启用但依赖未启用而无法启动的插件
+type Blocked struct {
+	Plugin  string   `json:"plugin"`
+	Missing []string `json:"missing"` // 未启用的依赖
+}
+
+// Plan 配置重新加载后的启动和停止计划
+type Plan struct {
+	Stop    []Step    `json:"stop"`    // 按停止顺序排列：依赖其他插件的插件在前
+	Start   []Step    `json:"start"`   // 按启动顺序排列：被依赖的插件在前
+	Blocked []Blocked `json:"blocked"` // 无法启动的插件
+	deps    map[string][]string
+}
+
+// Empty 检查计划是否没有任何操作
+func (p *Plan) Empty() bool {
+	return len(p.Stop) == 0 && len(p.Start) == 0 && len(p.Blocked) == 0
+}
+
+// NewPlan 根据插件的启用状态变化计算启动和停止计划
+// 只处理启用状态发生变化的插件、依赖它们的插件以及被启用插件的依赖，其余插件保持原状
+func NewPlan(plugins []Plugin) *Plan {
+	byID := make(map[string]Plugin, len(plugins))
+	for _, p := range plugins {
+		byID[p.ID] = p
+	}
+
+	// dependents[x] 为直接依赖 x 的插件
+	dependents := make(map[string][]string)
+	deps := make(map[string][]string, len(plugins))
+	for _, p := range plugins {
+		for _, dep := range p.Dependencies {
+			if _, ok := byID[dep]; !ok || dep == p.ID {
+				continue
+			}
+			deps[p.ID] = append(deps[p.ID], dep)
+			dependents[dep] = append(dependents[dep], p.ID)
+		}
+	}
+
+	// desired 插件在新配置下是否应该运行：自身启用且所有依赖都应该运行
+	desired := make(map[string]bool, len(plugins))
+	visiting := make(map[string]bool)
+	var resolve func(id string) bool
+	resolve = func(id string) bool {
+		if v, ok := desired[id]; ok {
+			return v
+		}
+		if visiting[id] {
+			// 循环依赖不阻止启动，由启动顺序按ID排序处理
+			return byID[id].Enabled
+		}
+		visiting[id] = true
+		v := byID[id].Enabled
+		for _, dep := range deps[id] {
+			if !resolve(dep) {
+				v = false
+			}
+		}
+		visiting[id] = false
+		desired[id] = v
+		return v
+	}
+
+	// 受影响的插件：启用状态变化的插件、依赖它们的插件，以及被启用插件的依赖
+	affected := make(map[string]bool)
+	var addDependents, addDependencies func(id string)
+	addDependents = func(id string) {
+		for _, dependent := range dependents[id] {
+			if !affected[dependent] {
+				affected[dependent] = true
+				addDependents(dependent)
+			}
+		}
+	}
+	addDependencies = func(id string) {
+		for _, dep := range deps[id] {
+			if !affected[dep] {
+				affected[dep] = true
+				addDependencies(dep)
+			}
+		}
+	}
+	for _, p := range plugins {
+		if p.WasEnabled == p.Enabled {
+			continue
+		}
+		affected[p.ID] = true
+		addDependents(p.ID)
+		if p.Enabled {
+			addDependencies(p.ID)
+		}
+	}
+
+	ids := make([]string, 0, len(affected))
+	for id := range affected {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	plan := &Plan{deps: deps}
+	stops := make(map[string]Reason)
+	starts := make(map[string]Reason)
+	for _, id := range ids {
+		p := byID[id]
+		changed := p.WasEnabled != p.Enabled
+		switch want := resolve(id); {
+		case p.Running && !want:
+			reason := ReasonDependency
+			if !p.Enabled {
+				reason = ReasonDisabled
+			}
+			stops[id] = reason
+		case !p.Running && want:
+			reason := ReasonDependency
+			if changed {
+				reason = ReasonEnabled
+			}
+			starts[id] = reason
+		case !p.Running && p.Enabled && changed:
+			var missing []string
+			for _, dep := range deps[id] {
+				if !resolve(dep) {
+					missing = append(missing, dep)
+				}
+			}
+			plan.Blocked = append(plan.Blocked, Blocked{Plugin: id, Missing: missing})
+		}
+	}
+
+	order := stopOrder(byID, deps)
+	for _, id := range order {
+		if reason, ok := stops[id]; ok {
+			plan.Stop = append(plan.Stop, Step{Plugin: id, Reason: reason})
+		}
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		if reason, ok := starts[order[i]]; ok {
+			plan.Start = append(plan.Start, Step{Plugin: order[i], Reason: reason})
+		}
+	}
+	return plan
+}
+
+// stopOrder 计算全部插件的停止顺序，存在循环依赖时按ID排序
+func stopOrder(byID map[string]Plugin, deps map[string][]string) []string {
+	plugins := make([]shutdown.Plugin, 0, len(byID))
+	for id := range byID {
+		plugins = append(plugins, shutdown.Plugin{ID: id, Dependencies: deps[id]})
+	}
+
+	order, err := shutdown.Order(plugins)
+	if err != nil {
+		order = make([]string, 0, len(byID))
+		for id := range byID {
+			order = append(order, id)
+		}
+		sort.Strings(order)
+	}
+	return order
+}
+
+// Controller 启动和停止插件
+type Controller interface {
+	// StartPlugin 启动插件，插件未加载时先加载
+	StartPlugin(id string) error
+
+	// StopPlugin 停止插件，ctx 在停止时限到达时取消
+	StopPlugin(ctx context.Context, id string) error
+
+	// KillPlugin 强制终止超出停止时限的插件
+	KillPlugin(id string)
+}
+
+// Result 单个插件的启动结果
+type Result struct {
+	Plugin string `json:"plugin"`
+	Reason Reason `json:"reason"`
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report 计划执行结果
+type Report struct {
+	Stopped *shutdown.Report `json:"stopped"`
+	Started []Result         `json:"started"`
+	Blocked []Blocked        `json:"blocked,omitempty"`
+}
+
+// Apply 执行计划：先按依赖关系的逆序停止插件，再按依赖关系的顺序启动插件
+// 每个插件的停止时限为 timeout，0表示使用 shutdown.DefaultTimeout；依赖的插件未能启动时跳过依赖它的插件
+func Apply(ctx context.Context, plan *Plan, controller Controller, timeout time.Duration) *Report {
+	report := &Report{Blocked: plan.Blocked}
+
+	stops := make([]shutdown.Plugin, 0, len(plan.Stop))
+	for _, step := range plan.Stop {
+		id := step.Plugin
+		stops = append(stops, shutdown.Plugin{
+			ID:           id,
+			Dependencies: plan.deps[id],
+			Timeout:      timeout,
+			Stop: func(ctx context.Context) error {
+				return controller.StopPlugin(ctx, id)
+			},
+			Kill: func() {
+				controller.KillPlugin(id)
+			},
+		})
+	}
+	report.Stopped = shutdown.Run(ctx, stops)
+
+	failed := make(map[string]bool)
+	report.Started = make([]Result, 0, len(plan.Start))
+	for _, step := range plan.Start {
+		result := Result{Plugin: step.Plugin, Reason: step.Reason}
+		for _, dep := range plan.deps[step.Plugin] {
+			if failed[dep] {
+				result.Status = StatusSkipped
+				result.Error = fmt.Sprintf("依赖的插件 %s 未能启动", dep)
+				break
+			}
+		}
+		if result.Status == "" {
+			if err := controller.StartPlugin(step.Plugin); err != nil {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+			} else {
+				result.Status = StatusStarted
+			}
+		}
+		if result.Status != StatusStarted {
+			failed[step.Plugin] = true
+		}
+		report.Started = append(report.Started, result)
+	}
+	return report
+}
@@ -0,0 +1,239 @@
+package pluginreload
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeController 模拟插件管理器，记录启动和停止顺序
+type fakeController struct {
+	mu       sync.Mutex
+	running  map[string]bool
+	started  []string
+	stopped  []string
+	killed   []string
+	startErr map[string]error
+	hang     map[string]bool // 停止时不响应，直到 ctx 取消
+}
+
+func newFakeController(running ...string) *fakeController {
+	c := &fakeController{running: make(map[string]bool), startErr: make(map[string]error), hang: make(map[string]bool)}
+	for _, id := range running {
+		c.running[id] = true
+	}
+	return c
+}
+
+func (c *fakeController) StartPlugin(id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.startErr[id]; err != nil {
+		return err
+	}
+	c.running[id] = true
+	c.started = append(c.started, id)
+	return nil
+}
+
+func (c *fakeController) StopPlugin(ctx context.Context, id string) error {
+	c.mu.Lock()
+	hang := c.hang[id]
+	c.mu.Unlock()
+	if hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running[id] = false
+	c.stopped = append(c.stopped, id)
+	return nil
+}
+
+func (c *fakeController) KillPlugin(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running[id] = false
+	c.killed = append(c.killed, id)
+}
+
+// plugins 根据当前运行状态构造插件列表，enabled 为重新加载前后的启用状态
+func (c *fakeController) plugins(deps map[string][]string, enabled map[string][2]bool) []Plugin {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	plugins := make([]Plugin, 0, len(enabled))
+	for id, states := range enabled {
+		plugins = append(plugins, Plugin{
+			ID:           id,
+			Dependencies: deps[id],
+			WasEnabled:   states[0],
+			Enabled:      states[1],
+			Running:      c.running[id],
+		})
+	}
+	return plugins
+}
+
+func steps(plan []Step) []Step {
+	if plan == nil {
+		return []Step{}
+	}
+	return plan
+}
+
+// dependencies comm 被 audit 和 dlp 依赖，dlp 同时依赖 audit
+var dependencies = map[string][]string{
+	"audit": {"comm"},
+	"dlp":   {"comm", "audit"},
+}
+
+// TestDisableStopsDependents 测试停用插件时先停止依赖它的插件
+func TestDisableStopsDependents(t *testing.T) {
+	c := newFakeController("comm", "audit", "dlp", "assets")
+	plan := NewPlan(c.plugins(dependencies, map[string][2]bool{
+		"comm":   {true, true},
+		"audit":  {true, false},
+		"dlp":    {true, true},
+		"assets": {true, true},
+	}))
+
+	wantStop := []Step{{Plugin: "dlp", Reason: ReasonDependency}, {Plugin: "audit", Reason: ReasonDisabled}}
+	if !reflect.DeepEqual(plan.Stop, wantStop) {
+		t.Errorf("停止计划不正确，期望 %v，实际 %v", wantStop, plan.Stop)
+	}
+	if len(plan.Start) != 0 || len(plan.Blocked) != 0 {
+		t.Errorf("停用插件不应启动插件，实际 %v %v", plan.Start, plan.Blocked)
+	}
+
+	report := Apply(context.Background(), plan, c, time.Second)
+	if want := []string{"dlp", "audit"}; !reflect.DeepEqual(c.stopped, want) {
+		t.Errorf("停止顺序不正确，期望 %v，实际 %v", want, c.stopped)
+	}
+	if !c.running["comm"] || !c.running["assets"] {
+		t.Error("不相关的插件不应停止")
+	}
+	if len(report.Stopped.Results) != 2 {
+		t.Errorf("报告应包含2个停止结果，实际 %d", len(report.Stopped.Results))
+	}
+}
+
+// TestEnableStartsDependenciesFirst 测试重新启用插件时按依赖顺序启动，并恢复依赖它的插件
+func TestEnableStartsDependenciesFirst(t *testing.T) {
+	c := newFakeController("assets")
+	plan := NewPlan(c.plugins(dependencies, map[string][2]bool{
+		"comm":   {true, true},
+		"audit":  {false, true},
+		"dlp":    {true, true},
+		"assets": {true, true},
+	}))
+
+	// comm 应运行但未运行（例如此前启动失败），作为 audit 的依赖一并启动
+	wantStart := []Step{
+		{Plugin: "comm", Reason: ReasonDependency},
+		{Plugin: "audit", Reason: ReasonEnabled},
+		{Plugin: "dlp", Reason: ReasonDependency},
+	}
+	if !reflect.DeepEqual(steps(plan.Start), wantStart) {
+		t.Errorf("启动计划不正确，期望 %v，实际 %v", wantStart, plan.Start)
+	}
+
+	report := Apply(context.Background(), plan, c, time.Second)
+	if want := []string{"comm", "audit", "dlp"}; !reflect.DeepEqual(c.started, want) {
+		t.Errorf("启动顺序不正确，期望 %v，实际 %v", want, c.started)
+	}
+	for _, result := range report.Started {
+		if result.Status != StatusStarted {
+			t.Errorf("插件 %s 应启动成功，实际 %s", result.Plugin, result.Status)
+		}
+	}
+}
+
+// TestToggleRoundTrip 测试停用后重新启用恢复原有的运行状态
+func TestToggleRoundTrip(t *testing.T) {
+	c := newFakeController("comm", "audit", "dlp")
+	enabled := func(comm bool) map[string][2]bool {
+		return map[string][2]bool{"comm": {!comm, comm}, "audit": {true, true}, "dlp": {true, true}}
+	}
+
+	Apply(context.Background(), NewPlan(c.plugins(dependencies, enabled(false))), c, time.Second)
+	for _, id := range []string{"comm", "audit", "dlp"} {
+		if c.running[id] {
+			t.Errorf("停用 comm 后插件 %s 应停止", id)
+		}
+	}
+
+	Apply(context.Background(), NewPlan(c.plugins(dependencies, enabled(true))), c, time.Second)
+	for _, id := range []string{"comm", "audit", "dlp"} {
+		if !c.running[id] {
+			t.Errorf("重新启用 comm 后插件 %s 应运行", id)
+		}
+	}
+	if want := []string{"comm", "audit", "dlp"}; !reflect.DeepEqual(c.started, want) {
+		t.Errorf("启动顺序不正确，期望 %v，实际 %v", want, c.started)
+	}
+}
+
+// TestEnableBlockedByDisabledDependency 测试依赖未启用时不启动插件
+func TestEnableBlockedByDisabledDependency(t *testing.T) {
+	c := newFakeController()
+	plan := NewPlan(c.plugins(dependencies, map[string][2]bool{
+		"comm":  {false, false},
+		"audit": {false, true},
+	}))
+
+	if len(plan.Start) != 0 {
+		t.Errorf("依赖未启用时不应启动插件，实际 %v", plan.Start)
+	}
+	want := []Blocked{{Plugin: "audit", Missing: []string{"comm"}}}
+	if !reflect.DeepEqual(plan.Blocked, want) {
+		t.Errorf("无法启动的插件不正确，期望 %v，实际 %v", want, plan.Blocked)
+	}
+}
+
+// TestApplySkipsDependentsOfFailedStart 测试依赖启动失败时跳过依赖它的插件
+func TestApplySkipsDependentsOfFailedStart(t *testing.T) {
+	c := newFakeController()
+	c.startErr["comm"] = errors.New("连接失败")
+	plan := NewPlan(c.plugins(dependencies, map[string][2]bool{
+		"comm":  {false, true},
+		"audit": {true, true},
+	}))
+
+	report := Apply(context.Background(), plan, c, time.Second)
+	if len(report.Started) != 2 {
+		t.Fatalf("报告应包含2个启动结果，实际 %v", report.Started)
+	}
+	if report.Started[0].Status != StatusFailed || report.Started[1].Status != StatusSkipped {
+		t.Errorf("启动结果不正确: %v", report.Started)
+	}
+	if len(c.started) != 0 {
+		t.Errorf("不应启动任何插件，实际 %v", c.started)
+	}
+}
+
+// TestApplyKillsHungPlugin 测试停止超时的插件被强制终止
+func TestApplyKillsHungPlugin(t *testing.T) {
+	c := newFakeController("comm", "audit")
+	c.hang["audit"] = true
+	plan := NewPlan(c.plugins(dependencies, map[string][2]bool{
+		"comm":  {true, false},
+		"audit": {true, true},
+	}))
+
+	report := Apply(context.Background(), plan, c, 20*time.Millisecond)
+	if want := []string{"audit"}; !reflect.DeepEqual(c.killed, want) {
+		t.Errorf("应强制终止 audit，实际 %v", c.killed)
+	}
+	if want := []string{"comm"}; !reflect.DeepEqual(c.stopped, want) {
+		t.Errorf("audit 超时后应继续停止 comm，实际 %v", c.stopped)
+	}
+	if offenders := report.Stopped.Offenders(); len(offenders) != 1 || offenders[0].Plugin != "audit" {
+		t.Errorf("超时插件不正确: %v", offenders)
+	}
+}
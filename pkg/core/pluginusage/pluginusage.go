@@ -0,0 +1,216 @@
+// Package pluginusage 统计各插件的CPU和内存占用
+// 独立进程插件按进程ID采样；进程内插件与宿主共享进程，无法单独统计内存，
+// 按采样间隔内的累计执行耗时估算CPU占用
+package pluginusage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Target 待采样的插件
+// 同一插件可以有多个采样目标（例如插件派生的子进程），报告中按插件汇总
+type Target struct {
+	PluginID string
+	PID      int32         // 插件进程ID，0表示进程内插件
+	ExecTime time.Duration // 进程内插件的累计执行耗时
+}
+
+// ProcessSample 单个进程的资源占用
+type ProcessSample struct {
+	CPUPercent float64 // 自上次采样以来的CPU使用率（百分比）
+	MemoryRSS  uint64  // 常驻内存（字节）
+	Threads    int32   // 线程数
+}
+
+// Sampler 进程资源采样器
+type Sampler interface {
+	// SampleProcess 采样指定进程的资源占用
+	SampleProcess(pid int32) (ProcessSample, error)
+}
+
+// Usage 单个插件的资源占用
+type Usage struct {
+	PluginID   string        `json:"plugin_id"`
+	PIDs       []int32       `json:"pids,omitempty"`
+	InProcess  bool          `json:"in_process"`
+	CPUPercent float64       `json:"cpu_percent"`
+	MemoryRSS  uint64        `json:"memory_rss"`
+	Threads    int32         `json:"threads,omitempty"`
+	ExecTime   time.Duration `json:"exec_time,omitempty"`
+	Errors     []string      `json:"errors,omitempty"`
+}
+
+// Report 插件资源占用报告
+type Report struct {
+	Timestamp       time.Time `json:"timestamp"`
+	Plugins         []Usage   `json:"plugins"` // 按CPU使用率从高到低排序
+	TotalCPUPercent float64   `json:"total_cpu_percent"`
+	TotalMemoryRSS  uint64    `json:"total_memory_rss"`
+}
+
+// Top 返回CPU占用最高的插件
+func (r Report) Top() (Usage, bool) {
+	if len(r.Plugins) == 0 {
+		return Usage{}, false
+	}
+	return r.Plugins[0], true
+}
+
+// Plugin 返回指定插件的资源占用
+func (r Report) Plugin(id string) (Usage, bool) {
+	for _, usage := range r.Plugins {
+		if usage.PluginID == id {
+			return usage, true
+		}
+	}
+	return Usage{}, false
+}
+
+// String 以表格形式输出报告
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-20s %-12s %8s %12s  %s\n", "插件", "进程", "CPU%", "内存", "备注")
+	for _, usage := range r.Plugins {
+		process := "进程内"
+		if !usage.InProcess {
+			pids := make([]string, len(usage.PIDs))
+			for i, pid := range usage.PIDs {
+				pids[i] = fmt.Sprint(pid)
+			}
+			process = strings.Join(pids, ",")
+		}
+		memory := FormatBytes(usage.MemoryRSS)
+		if usage.InProcess {
+			memory = "-"
+		}
+		fmt.Fprintf(&b, "%-20s %-12s %8.1f %12s  %s\n", usage.PluginID, process, usage.CPUPercent, memory, strings.Join(usage.Errors, "; "))
+	}
+	fmt.Fprintf(&b, "%-20s %-12s %8.1f %12s\n", "合计", "", r.TotalCPUPercent, FormatBytes(r.TotalMemoryRSS))
+	return b.String()
+}
+
+// FormatBytes 将字节数格式化为易读的形式
+func FormatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// execSample 进程内插件上一次采样的累计执行耗时
+type execSample struct {
+	at       time.Time
+	execTime time.Duration
+}
+
+// Collector 插件资源占用采集器
+type Collector struct {
+	sampler Sampler
+
+	mu       sync.Mutex
+	lastExec map[string]execSample
+	latest   *Report
+}
+
+// NewCollector 创建插件资源占用采集器
+func NewCollector(sampler Sampler) *Collector {
+	return &Collector{
+		sampler:  sampler,
+		lastExec: make(map[string]execSample),
+	}
+}
+
+// Sample 采样各插件的资源占用并按插件汇总
+func (c *Collector) Sample(targets []Target, now time.Time) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	usages := make(map[string]*Usage)
+	seenExec := make(map[string]bool)
+	for _, target := range targets {
+		usage, exists := usages[target.PluginID]
+		if !exists {
+			usage = &Usage{PluginID: target.PluginID, InProcess: true}
+			usages[target.PluginID] = usage
+		}
+
+		if target.PID > 0 {
+			usage.InProcess = false
+			usage.PIDs = append(usage.PIDs, target.PID)
+			sample, err := c.sampler.SampleProcess(target.PID)
+			if err != nil {
+				usage.Errors = append(usage.Errors, fmt.Sprintf("进程 %d: %v", target.PID, err))
+				continue
+			}
+			usage.CPUPercent += sample.CPUPercent
+			usage.MemoryRSS += sample.MemoryRSS
+			usage.Threads += sample.Threads
+			continue
+		}
+
+		// 进程内插件按执行耗时的增量估算CPU占用，首次采样没有基准，记为0
+		usage.ExecTime += target.ExecTime
+		seenExec[target.PluginID] = true
+	}
+
+	for id := range seenExec {
+		usage := usages[id]
+		if last, ok := c.lastExec[id]; ok {
+			elapsed := now.Sub(last.at)
+			delta := usage.ExecTime - last.execTime
+			if elapsed > 0 && delta > 0 {
+				usage.CPUPercent += float64(delta) / float64(elapsed) * 100
+			}
+		}
+		c.lastExec[id] = execSample{at: now, execTime: usage.ExecTime}
+	}
+	for id := range c.lastExec {
+		if !seenExec[id] {
+			delete(c.lastExec, id)
+		}
+	}
+
+	report := Report{
+		Timestamp: now,
+		Plugins:   make([]Usage, 0, len(usages)),
+	}
+	for _, usage := range usages {
+		report.Plugins = append(report.Plugins, *usage)
+		report.TotalCPUPercent += usage.CPUPercent
+		report.TotalMemoryRSS += usage.MemoryRSS
+	}
+	sort.Slice(report.Plugins, func(i, j int) bool {
+		a, b := report.Plugins[i], report.Plugins[j]
+		if a.CPUPercent != b.CPUPercent {
+			return a.CPUPercent > b.CPUPercent
+		}
+		if a.MemoryRSS != b.MemoryRSS {
+			return a.MemoryRSS > b.MemoryRSS
+		}
+		return a.PluginID < b.PluginID
+	})
+
+	c.latest = &report
+	return report
+}
+
+// Latest 返回最近一次采样的报告
+func (c *Collector) Latest() (Report, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.latest == nil {
+		return Report{}, false
+	}
+	return *c.latest, true
+}
@@ -0,0 +1,161 @@
+package pluginusage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSampler 按进程ID返回预设结果的测试采样器
+type fakeSampler struct {
+	samples map[int32]ProcessSample
+	errs    map[int32]error
+	calls   []int32
+}
+
+func (s *fakeSampler) SampleProcess(pid int32) (ProcessSample, error) {
+	s.calls = append(s.calls, pid)
+	if err, ok := s.errs[pid]; ok {
+		return ProcessSample{}, err
+	}
+	return s.samples[pid], nil
+}
+
+// TestCollectorPerPluginUsage 测试按插件报告独立进程插件的资源占用
+func TestCollectorPerPluginUsage(t *testing.T) {
+	sampler := &fakeSampler{samples: map[int32]ProcessSample{
+		101: {CPUPercent: 12.5, MemoryRSS: 64 << 20, Threads: 8},
+		202: {CPUPercent: 40, MemoryRSS: 32 << 20, Threads: 4},
+	}}
+	collector := NewCollector(sampler)
+
+	report := collector.Sample([]Target{
+		{PluginID: "assets", PID: 101},
+		{PluginID: "dlp", PID: 202},
+	}, time.Now())
+
+	if len(report.Plugins) != 2 {
+		t.Fatalf("插件数量应为 2，实际为 %d", len(report.Plugins))
+	}
+	top, ok := report.Top()
+	if !ok || top.PluginID != "dlp" {
+		t.Errorf("CPU占用最高的插件应为 dlp，实际为 %+v", top)
+	}
+
+	assets, ok := report.Plugin("assets")
+	if !ok {
+		t.Fatal("报告中应包含 assets 插件")
+	}
+	if assets.InProcess {
+		t.Error("assets 插件不应为进程内插件")
+	}
+	if assets.CPUPercent != 12.5 || assets.MemoryRSS != 64<<20 || assets.Threads != 8 {
+		t.Errorf("assets 插件资源占用不正确: %+v", assets)
+	}
+	if len(assets.PIDs) != 1 || assets.PIDs[0] != 101 {
+		t.Errorf("assets 插件进程ID应为 [101]，实际为 %v", assets.PIDs)
+	}
+
+	if report.TotalCPUPercent != 52.5 {
+		t.Errorf("CPU合计应为 52.5，实际为 %v", report.TotalCPUPercent)
+	}
+	if report.TotalMemoryRSS != 96<<20 {
+		t.Errorf("内存合计应为 %d，实际为 %d", uint64(96<<20), report.TotalMemoryRSS)
+	}
+
+	latest, ok := collector.Latest()
+	if !ok || len(latest.Plugins) != 2 {
+		t.Errorf("应保存最近一次的报告，实际为 %+v", latest)
+	}
+}
+
+// TestCollectorAggregatesProcesses 测试同一插件多个进程的资源占用汇总
+func TestCollectorAggregatesProcesses(t *testing.T) {
+	sampler := &fakeSampler{
+		samples: map[int32]ProcessSample{
+			101: {CPUPercent: 10, MemoryRSS: 100, Threads: 2},
+			102: {CPUPercent: 5, MemoryRSS: 50, Threads: 1},
+		},
+		errs: map[int32]error{103: errors.New("进程不存在")},
+	}
+	collector := NewCollector(sampler)
+
+	report := collector.Sample([]Target{
+		{PluginID: "dlp", PID: 101},
+		{PluginID: "dlp", PID: 102},
+		{PluginID: "dlp", PID: 103},
+	}, time.Now())
+
+	if len(report.Plugins) != 1 {
+		t.Fatalf("同一插件应汇总为一条记录，实际为 %d 条", len(report.Plugins))
+	}
+	dlp := report.Plugins[0]
+	if dlp.CPUPercent != 15 || dlp.MemoryRSS != 150 || dlp.Threads != 3 {
+		t.Errorf("汇总结果不正确: %+v", dlp)
+	}
+	if len(dlp.PIDs) != 3 {
+		t.Errorf("应记录全部 3 个进程ID，实际为 %v", dlp.PIDs)
+	}
+	if len(dlp.Errors) != 1 || !strings.Contains(dlp.Errors[0], "103") {
+		t.Errorf("应记录进程 103 的采样错误，实际为 %v", dlp.Errors)
+	}
+}
+
+// TestCollectorInProcessCPU 测试按执行耗时增量估算进程内插件的CPU占用
+func TestCollectorInProcessCPU(t *testing.T) {
+	sampler := &fakeSampler{}
+	collector := NewCollector(sampler)
+	start := time.Now()
+
+	first := collector.Sample([]Target{{PluginID: "control", ExecTime: time.Second}}, start)
+	control, ok := first.Plugin("control")
+	if !ok {
+		t.Fatal("报告中应包含 control 插件")
+	}
+	if !control.InProcess {
+		t.Error("control 插件应为进程内插件")
+	}
+	if control.CPUPercent != 0 {
+		t.Errorf("首次采样没有基准，CPU占用应为 0，实际为 %v", control.CPUPercent)
+	}
+	if len(sampler.calls) != 0 {
+		t.Errorf("进程内插件不应按进程采样，实际采样了 %v", sampler.calls)
+	}
+
+	// 10 秒内执行了 2.5 秒
+	second := collector.Sample([]Target{{PluginID: "control", ExecTime: 3500 * time.Millisecond}}, start.Add(10*time.Second))
+	control, _ = second.Plugin("control")
+	if control.CPUPercent != 25 {
+		t.Errorf("CPU占用应为 25，实际为 %v", control.CPUPercent)
+	}
+	if control.MemoryRSS != 0 {
+		t.Errorf("进程内插件不应报告内存，实际为 %d", control.MemoryRSS)
+	}
+
+	// 插件卸载后重新加载，基准应重新建立
+	collector.Sample(nil, start.Add(20*time.Second))
+	third := collector.Sample([]Target{{PluginID: "control", ExecTime: time.Second}}, start.Add(30*time.Second))
+	control, _ = third.Plugin("control")
+	if control.CPUPercent != 0 {
+		t.Errorf("重新加载后首次采样CPU占用应为 0，实际为 %v", control.CPUPercent)
+	}
+}
+
+// TestReportString 测试报告的文本输出
+func TestReportString(t *testing.T) {
+	collector := NewCollector(&fakeSampler{samples: map[int32]ProcessSample{
+		101: {CPUPercent: 3, MemoryRSS: 2 << 20},
+	}})
+	report := collector.Sample([]Target{
+		{PluginID: "assets", PID: 101},
+		{PluginID: "control"},
+	}, time.Now())
+
+	output := report.String()
+	for _, want := range []string{"assets", "101", "2.0 MiB", "control", "进程内", "合计"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("输出应包含 %q:\n%s", want, output)
+		}
+	}
+}
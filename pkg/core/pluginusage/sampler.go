@@ -0,0 +1,57 @@
+package pluginusage
+
+import (
+	"sync"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessSampler 基于操作系统进程信息的采样器
+// 缓存进程句柄，使CPU使用率反映两次采样之间的占用
+type ProcessSampler struct {
+	mu        sync.Mutex
+	processes map[int32]*process.Process
+}
+
+// NewProcessSampler 创建进程采样器
+func NewProcessSampler() *ProcessSampler {
+	return &ProcessSampler{
+		processes: make(map[int32]*process.Process),
+	}
+}
+
+// SampleProcess 采样指定进程的资源占用，进程已退出时返回错误并丢弃缓存
+func (s *ProcessSampler) SampleProcess(pid int32) (ProcessSample, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proc, ok := s.processes[pid]
+	if !ok {
+		var err error
+		proc, err = process.NewProcess(pid)
+		if err != nil {
+			return ProcessSample{}, err
+		}
+		s.processes[pid] = proc
+	}
+
+	cpuPercent, err := proc.Percent(0)
+	if err != nil {
+		delete(s.processes, pid)
+		return ProcessSample{}, err
+	}
+	memory, err := proc.MemoryInfo()
+	if err != nil {
+		delete(s.processes, pid)
+		return ProcessSample{}, err
+	}
+
+	sample := ProcessSample{
+		CPUPercent: cpuPercent,
+		MemoryRSS:  memory.RSS,
+	}
+	if threads, err := proc.NumThreads(); err == nil {
+		sample.Threads = threads
+	}
+	return sample, nil
+}
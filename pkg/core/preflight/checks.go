@@ -0,0 +1,182 @@
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// 配置中支持的检查类型
+const (
+	CheckTypeBinary = "binary" // 可执行文件存在（PATH中或指定路径）
+	CheckTypeDriver = "driver" // 驱动文件已安装
+	CheckTypeFile   = "file"   // 文件存在
+	CheckTypePort   = "port"   // 端口未被占用
+)
+
+// BinaryCheck 检查可执行文件是否存在，name 可以是 PATH 中的命令名或完整路径
+func BinaryCheck(name string) Check {
+	return Check{
+		Name:     "binary:" + name,
+		Required: true,
+		Run: func(ctx context.Context) error {
+			if _, err := exec.LookPath(name); err != nil {
+				return fmt.Errorf("未找到可执行文件 %s", name)
+			}
+			return nil
+		},
+	}
+}
+
+// DriverCheck 检查驱动文件是否已安装，如 WinDivert64.sys
+func DriverCheck(path string) Check {
+	return Check{
+		Name:     "driver:" + path,
+		Required: true,
+		Run: func(ctx context.Context) error {
+			if err := statRegularFile(path); err != nil {
+				return fmt.Errorf("驱动未安装: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// FileCheck 检查文件是否存在
+func FileCheck(path string) Check {
+	return Check{
+		Name:     "file:" + path,
+		Required: true,
+		Run: func(ctx context.Context) error {
+			return statRegularFile(path)
+		},
+	}
+}
+
+// PortFreeCheck 检查本地端口是否未被占用，host 为空时检查所有地址
+func PortFreeCheck(host string, port int) Check {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	return Check{
+		Name:     "port:" + address,
+		Required: true,
+		Run: func(ctx context.Context) error {
+			var lc net.ListenConfig
+			listener, err := lc.Listen(ctx, "tcp", address)
+			if err != nil {
+				return fmt.Errorf("端口 %s 已被占用: %w", address, err)
+			}
+			return listener.Close()
+		},
+	}
+}
+
+// statRegularFile 检查路径存在且不是目录
+func statRegularFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("文件不存在: %s", path)
+		}
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s 是目录", path)
+	}
+	return nil
+}
+
+// ChecksFromConfig 根据插件配置中的 preflight 列表创建检查
+// 每一项形如 {type: binary, path: tesseract}、{type: driver, path: WinDivert64.sys}、
+// {type: port, port: 8080, host: 127.0.0.1}，可选 name（显示名称）、required（默认true）、
+// timeout（如"3s"）和 description
+func ChecksFromConfig(specs []interface{}) ([]Check, error) {
+	checks := make([]Check, 0, len(specs))
+	for i, item := range specs {
+		spec, ok := toStringMap(item)
+		if !ok {
+			return nil, fmt.Errorf("第 %d 项检查配置格式错误", i+1)
+		}
+
+		check, err := checkFromSpec(spec)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 项检查配置无效: %w", i+1, err)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// checkFromSpec 根据单项配置创建检查
+func checkFromSpec(spec map[string]interface{}) (Check, error) {
+	var check Check
+	checkType := stringValue(spec["type"])
+	switch checkType {
+	case CheckTypeBinary, CheckTypeDriver, CheckTypeFile:
+		path := stringValue(spec["path"])
+		if path == "" {
+			return check, fmt.Errorf("%s 检查缺少 path", checkType)
+		}
+		switch checkType {
+		case CheckTypeBinary:
+			check = BinaryCheck(path)
+		case CheckTypeDriver:
+			check = DriverCheck(path)
+		default:
+			check = FileCheck(path)
+		}
+
+	case CheckTypePort:
+		port, err := strconv.Atoi(stringValue(spec["port"]))
+		if err != nil || port <= 0 || port > 65535 {
+			return check, fmt.Errorf("port 检查的端口无效: %v", spec["port"])
+		}
+		check = PortFreeCheck(stringValue(spec["host"]), port)
+
+	default:
+		return check, fmt.Errorf("不支持的检查类型: %q", checkType)
+	}
+
+	if name := stringValue(spec["name"]); name != "" {
+		check.Name = name
+	}
+	check.Description = stringValue(spec["description"])
+	if required, ok := spec["required"].(bool); ok {
+		check.Required = required
+	}
+	if timeout := stringValue(spec["timeout"]); timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			return check, fmt.Errorf("超时时间无效: %w", err)
+		}
+		check.Timeout = duration
+	}
+	return check, nil
+}
+
+// toStringMap 转换YAML解码得到的映射
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = item
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+// stringValue 将配置值转换为字符串
+func stringValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
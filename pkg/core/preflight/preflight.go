@@ -0,0 +1,243 @@
+// Package preflight 提供启动前的依赖与就绪检查
+// 插件声明自身依赖的外部工具、驱动和端口，应用在启动插件前统一检查，
+// 依赖缺失时给出汇总报告并终止启动，而不是在运行时日志中才暴露问题
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status 检查结果状态
+type Status string
+
+const (
+	StatusPass Status = "pass" // 检查通过
+	StatusWarn Status = "warn" // 非必需检查未通过
+	StatusFail Status = "fail" // 必需检查未通过
+)
+
+// DefaultTimeout 单项检查的默认超时时间
+const DefaultTimeout = 5 * time.Second
+
+// Check 就绪检查
+type Check struct {
+	Name        string                          // 检查名称
+	Description string                          // 检查说明
+	Required    bool                            // 是否必需，必需检查失败时应用不启动
+	Timeout     time.Duration                   // 超时时间，0表示使用默认值
+	Run         func(ctx context.Context) error // 检查函数，返回nil表示通过
+}
+
+// Result 单项检查结果
+type Result struct {
+	Plugin   string        `json:"plugin"`
+	Name     string        `json:"name"`
+	Status   Status        `json:"status"`
+	Required bool          `json:"required"`
+	Message  string        `json:"message,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Report 就绪检查汇总报告
+type Report struct {
+	Ready    bool          `json:"ready"`
+	Results  []Result      `json:"results"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Failures 返回必需检查中未通过的结果
+func (r *Report) Failures() []Result {
+	var failures []Result
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+// Warnings 返回非必需检查中未通过的结果
+func (r *Report) Warnings() []Result {
+	var warnings []Result
+	for _, result := range r.Results {
+		if result.Status == StatusWarn {
+			warnings = append(warnings, result)
+		}
+	}
+	return warnings
+}
+
+// Err 存在必需检查失败时返回汇总错误
+func (r *Report) Err() error {
+	failures := r.Failures()
+	if len(failures) == 0 {
+		return nil
+	}
+
+	items := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		items = append(items, fmt.Sprintf("%s/%s: %s", failure.Plugin, failure.Name, failure.Message))
+	}
+	return fmt.Errorf("就绪检查失败 (%d 项): %s", len(failures), strings.Join(items, "; "))
+}
+
+// String 返回可读的检查报告
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		fmt.Fprintf(&b, "[%s] %s/%s", strings.ToUpper(string(result.Status)), result.Plugin, result.Name)
+		if result.Message != "" {
+			fmt.Fprintf(&b, ": %s", result.Message)
+		}
+		b.WriteString("\n")
+	}
+
+	summary := "就绪"
+	if !r.Ready {
+		summary = "未就绪"
+	}
+	fmt.Fprintf(&b, "%s: %d 项检查，%d 项失败，%d 项警告，耗时 %s\n",
+		summary, len(r.Results), len(r.Failures()), len(r.Warnings()), r.Duration.Round(time.Millisecond))
+	return b.String()
+}
+
+// registeredCheck 已注册的检查
+type registeredCheck struct {
+	plugin string
+	check  Check
+}
+
+// Runner 就绪检查执行器
+type Runner struct {
+	mu     sync.Mutex
+	checks []registeredCheck
+}
+
+// NewRunner 创建就绪检查执行器
+func NewRunner() *Runner {
+	return &Runner{}
+}
+
+// Register 为插件注册就绪检查
+func (r *Runner) Register(plugin string, check Check) error {
+	if check.Name == "" {
+		return fmt.Errorf("检查名称不能为空")
+	}
+	if check.Run == nil {
+		return fmt.Errorf("检查 %s 缺少检查函数", check.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, registeredCheck{plugin: plugin, check: check})
+	return nil
+}
+
+// Clone 复制执行器及已注册的检查
+func (r *Runner) Clone() *Runner {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	checks := make([]registeredCheck, len(r.checks))
+	copy(checks, r.checks)
+	return &Runner{checks: checks}
+}
+
+// Len 返回已注册的检查数量
+func (r *Runner) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.checks)
+}
+
+// Run 并发执行所有检查并汇总结果
+// 结果按插件和检查名称排序，便于阅读和比较
+func (r *Runner) Run(ctx context.Context) *Report {
+	r.mu.Lock()
+	checks := make([]registeredCheck, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.Unlock()
+
+	start := time.Now()
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, item := range checks {
+		wg.Add(1)
+		go func(i int, item registeredCheck) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, item.plugin, item.check)
+		}(i, item)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Plugin != results[j].Plugin {
+			return results[i].Plugin < results[j].Plugin
+		}
+		return results[i].Name < results[j].Name
+	})
+
+	report := &Report{
+		Ready:    true,
+		Results:  results,
+		Duration: time.Since(start),
+	}
+	for _, result := range results {
+		if result.Status == StatusFail {
+			report.Ready = false
+			break
+		}
+	}
+	return report
+}
+
+// runCheck 执行单项检查
+func runCheck(ctx context.Context, plugin string, check Check) Result {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- fmt.Errorf("检查发生panic: %v", r)
+			}
+		}()
+		errCh <- check.Run(checkCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-checkCtx.Done():
+		err = fmt.Errorf("检查超时: %w", checkCtx.Err())
+	}
+
+	result := Result{
+		Plugin:   plugin,
+		Name:     check.Name,
+		Status:   StatusPass,
+		Required: check.Required,
+		Message:  check.Description,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		result.Message = err.Error()
+		result.Status = StatusWarn
+		if check.Required {
+			result.Status = StatusFail
+		}
+	}
+	return result
+}
@@ -0,0 +1,186 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRunnerAllPassing 测试全部检查通过
+func TestRunnerAllPassing(t *testing.T) {
+	runner := NewRunner()
+	driver := filepath.Join(t.TempDir(), "WinDivert64.sys")
+	if err := os.WriteFile(driver, []byte("driver"), 0644); err != nil {
+		t.Fatalf("写入驱动文件失败: %v", err)
+	}
+
+	mustRegister(t, runner, "dlp", DriverCheck(driver))
+	mustRegister(t, runner, "dlp", Check{
+		Name:     "tesseract",
+		Required: true,
+		Run:      func(ctx context.Context) error { return nil },
+	})
+
+	report := runner.Run(context.Background())
+	if !report.Ready {
+		t.Fatalf("全部检查通过时应就绪:\n%s", report)
+	}
+	if len(report.Results) != 2 {
+		t.Fatalf("结果数量应为 2，实际为 %d", len(report.Results))
+	}
+	for _, result := range report.Results {
+		if result.Status != StatusPass {
+			t.Errorf("检查 %s 应通过，实际为 %s: %s", result.Name, result.Status, result.Message)
+		}
+	}
+	if err := report.Err(); err != nil {
+		t.Errorf("就绪时不应返回错误: %v", err)
+	}
+}
+
+// TestRunnerAggregatesFailures 测试汇总必需检查失败和非必需检查警告
+func TestRunnerAggregatesFailures(t *testing.T) {
+	runner := NewRunner()
+	missing := filepath.Join(t.TempDir(), "missing.sys")
+
+	mustRegister(t, runner, "dlp", DriverCheck(missing))
+	mustRegister(t, runner, "dlp", Check{
+		Name: "ocr",
+		Run:  func(ctx context.Context) error { return errors.New("tesseract 未安装") },
+	})
+	mustRegister(t, runner, "assets", Check{
+		Name:     "ok",
+		Required: true,
+		Run:      func(ctx context.Context) error { return nil },
+	})
+	mustRegister(t, runner, "control", Check{
+		Name:     "panic",
+		Required: true,
+		Run:      func(ctx context.Context) error { panic("boom") },
+	})
+	mustRegister(t, runner, "control", Check{
+		Name:     "slow",
+		Required: true,
+		Timeout:  20 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		},
+	})
+
+	report := runner.Run(context.Background())
+	if report.Ready {
+		t.Fatal("存在必需检查失败时不应就绪")
+	}
+
+	// 结果按插件和名称排序
+	var order []string
+	for _, result := range report.Results {
+		order = append(order, result.Plugin+"/"+result.Name)
+	}
+	expected := "assets/ok,control/panic,control/slow,dlp/driver:" + missing + ",dlp/ocr"
+	if strings.Join(order, ",") != expected {
+		t.Errorf("结果顺序不正确: %v", order)
+	}
+
+	failures := report.Failures()
+	if len(failures) != 3 {
+		t.Fatalf("失败数量应为 3，实际为 %d:\n%s", len(failures), report)
+	}
+	warnings := report.Warnings()
+	if len(warnings) != 1 || warnings[0].Name != "ocr" {
+		t.Errorf("非必需检查失败应为警告: %v", warnings)
+	}
+
+	text := report.String()
+	for _, want := range []string{"[FAIL] dlp/driver:", "驱动未安装", "[WARN] dlp/ocr: tesseract 未安装", "[PASS] assets/ok", "检查超时", "检查发生panic", "未就绪: 5 项检查，3 项失败，1 项警告"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("报告应包含 %q:\n%s", want, text)
+		}
+	}
+
+	err := report.Err()
+	if err == nil || !strings.Contains(err.Error(), "3 项") {
+		t.Errorf("汇总错误不正确: %v", err)
+	}
+}
+
+// TestPortFreeCheck 测试端口占用检查
+func TestPortFreeCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听端口失败: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	if err := PortFreeCheck("127.0.0.1", port).Run(context.Background()); err == nil {
+		t.Error("端口被占用时检查应失败")
+	}
+
+	listener.Close()
+	if err := PortFreeCheck("127.0.0.1", port).Run(context.Background()); err != nil {
+		t.Errorf("端口空闲时检查应通过: %v", err)
+	}
+}
+
+// TestChecksFromConfig 测试从插件配置创建检查
+func TestChecksFromConfig(t *testing.T) {
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatalf("获取可执行文件路径失败: %v", err)
+	}
+
+	checks, err := ChecksFromConfig([]interface{}{
+		map[string]interface{}{"type": "binary", "path": self},
+		map[interface{}]interface{}{"type": "binary", "path": "kennel-no-such-binary", "required": false, "name": "ocr"},
+		map[string]interface{}{"type": "port", "port": 70000},
+	})
+	if err == nil || !strings.Contains(err.Error(), "第 3 项") {
+		t.Fatalf("无效端口应返回错误，实际为 %v, %d", err, len(checks))
+	}
+
+	checks, err = ChecksFromConfig([]interface{}{
+		map[string]interface{}{"type": "binary", "path": self},
+		map[interface{}]interface{}{"type": "binary", "path": "kennel-no-such-binary", "required": false, "name": "ocr", "timeout": "1s"},
+	})
+	if err != nil {
+		t.Fatalf("解析检查配置失败: %v", err)
+	}
+
+	runner := NewRunner()
+	for _, check := range checks {
+		mustRegister(t, runner, "dlp", check)
+	}
+	report := runner.Run(context.Background())
+	if !report.Ready {
+		t.Errorf("非必需检查失败时仍应就绪:\n%s", report)
+	}
+	if warnings := report.Warnings(); len(warnings) != 1 || warnings[0].Name != "ocr" {
+		t.Errorf("缺少非必需的可执行文件应为警告:\n%s", report)
+	}
+
+	for _, spec := range []interface{}{
+		"binary",
+		map[string]interface{}{"type": "unknown"},
+		map[string]interface{}{"type": "driver"},
+		map[string]interface{}{"type": "file", "path": "a", "timeout": "soon"},
+	} {
+		if _, err := ChecksFromConfig([]interface{}{spec}); err == nil {
+			t.Errorf("无效配置应返回错误: %v", spec)
+		}
+	}
+}
+
+func mustRegister(t *testing.T, runner *Runner, plugin string, check Check) {
+	t.Helper()
+	if err := runner.Register(plugin, check); err != nil {
+		t.Fatalf("注册检查失败: %v", err)
+	}
+}
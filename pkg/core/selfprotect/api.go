@@ -31,6 +31,7 @@ func (api *ProtectionAPI) RegisterRoutes(router *mux.Router) {
 	router.HandleFunc("/api/protection/status", api.GetStatus).Methods("GET")
 	router.HandleFunc("/api/protection/config", api.GetConfig).Methods("GET")
 	router.HandleFunc("/api/protection/health", api.GetHealth).Methods("GET")
+	router.HandleFunc("/api/protection/selftest", api.RunSelfTest).Methods("POST")
 	
 	// 防护事件相关
 	router.HandleFunc("/api/protection/events", api.GetEvents).Methods("GET")
@@ -103,6 +104,19 @@ func (api *ProtectionAPI) GetHealth(w http.ResponseWriter, r *http.Request) {
 	api.writeJSONResponse(w, http.StatusOK, response)
 }
 
+// RunSelfTest 按需执行防护自检
+func (api *ProtectionAPI) RunSelfTest(w http.ResponseWriter, r *http.Request) {
+	healthChecker := NewProtectionHealthChecker(api.service, api.logger)
+	health := healthChecker.RunSelfTest(r.Context())
+
+	response := map[string]interface{}{
+		"success": true,
+		"data":    health,
+	}
+
+	api.writeJSONResponse(w, http.StatusOK, response)
+}
+
 // GetEvents 获取防护事件
 func (api *ProtectionAPI) GetEvents(w http.ResponseWriter, r *http.Request) {
 	// 解析查询参数
@@ -305,9 +319,10 @@ func (api *ProtectionAPI) GetProtectedFiles(w http.ResponseWriter, r *http.Reque
 	config := api.service.GetConfig()
 	
 	files := map[string]interface{}{
-		"enabled": config.FileProtection.Enabled,
-		"files":   config.FileProtection.ProtectedFiles,
-		"dirs":    config.FileProtection.ProtectedDirs,
+		"enabled":    config.FileProtection.Enabled,
+		"files":      config.FileProtection.ProtectedFiles,
+		"dirs":       config.FileProtection.ProtectedDirs,
+		"exclusions": config.FileProtection.Exclusions,
 		"settings": map[string]interface{}{
 			"check_integrity": config.FileProtection.CheckIntegrity,
 			"backup_enabled":  config.FileProtection.BackupEnabled,
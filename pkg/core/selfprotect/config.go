@@ -36,6 +36,7 @@ type ProtectionConfigYAML struct {
 	FileProtection     FileProtectionConfigYAML     `yaml:"file_protection"`
 	RegistryProtection RegistryProtectionConfigYAML `yaml:"registry_protection"`
 	ServiceProtection  ServiceProtectionConfigYAML  `yaml:"service_protection"`
+	SelfTest           SelfTestConfigYAML           `yaml:"self_test"`
 }
 
 // WhitelistConfigYAML 白名单配置YAML结构
@@ -53,16 +54,21 @@ type ProcessProtectionConfigYAML struct {
 	MonitorChildren    bool     `yaml:"monitor_children"`
 	PreventDebug       bool     `yaml:"prevent_debug"`
 	PreventDump        bool     `yaml:"prevent_dump"`
+	CheckInterval      string   `yaml:"check_interval"`
 }
 
 // FileProtectionConfigYAML 文件防护配置YAML结构
 type FileProtectionConfigYAML struct {
-	Enabled        bool     `yaml:"enabled"`
-	ProtectedFiles []string `yaml:"protected_files"`
-	ProtectedDirs  []string `yaml:"protected_dirs"`
-	CheckIntegrity bool     `yaml:"check_integrity"`
-	BackupEnabled  bool     `yaml:"backup_enabled"`
-	BackupDir      string   `yaml:"backup_dir"`
+	Enabled        bool                `yaml:"enabled"`
+	ProtectedFiles []string            `yaml:"protected_files"`
+	ProtectedDirs  []string            `yaml:"protected_dirs"`
+	Exclusions     map[string][]string `yaml:"exclusions"`
+	CheckIntegrity bool                `yaml:"check_integrity"`
+	BackupEnabled  bool                `yaml:"backup_enabled"`
+	BackupDir      string              `yaml:"backup_dir"`
+	CheckInterval  string              `yaml:"check_interval"`
+
+	AllowedModifiers []string `yaml:"allowed_modifiers"`
 }
 
 // RegistryProtectionConfigYAML 注册表防护配置YAML结构
@@ -70,14 +76,38 @@ type RegistryProtectionConfigYAML struct {
 	Enabled        bool     `yaml:"enabled"`
 	ProtectedKeys  []string `yaml:"protected_keys"`
 	MonitorChanges bool     `yaml:"monitor_changes"`
+	CheckInterval  string   `yaml:"check_interval"`
+
+	AllowedModifiers []string `yaml:"allowed_modifiers"`
 }
 
 // ServiceProtectionConfigYAML 服务防护配置YAML结构
 type ServiceProtectionConfigYAML struct {
-	Enabled        bool   `yaml:"enabled"`
-	ServiceName    string `yaml:"service_name"`
-	AutoRestart    bool   `yaml:"auto_restart"`
-	PreventDisable bool   `yaml:"prevent_disable"`
+	Enabled        bool                      `yaml:"enabled"`
+	ServiceName    string                    `yaml:"service_name"`
+	AutoRestart    bool                      `yaml:"auto_restart"`
+	PreventDisable bool                      `yaml:"prevent_disable"`
+	CheckInterval  string                    `yaml:"check_interval"`
+	Recovery       ServiceRecoveryConfigYAML `yaml:"recovery"`
+}
+
+// ServiceRecoveryConfigYAML 服务故障恢复配置YAML结构
+type ServiceRecoveryConfigYAML struct {
+	Enabled            bool    `yaml:"enabled"`
+	RestartDelay       string  `yaml:"restart_delay"`
+	MaxRestartDelay    string  `yaml:"max_restart_delay"`
+	BackoffMultiplier  float64 `yaml:"backoff_multiplier"`
+	MaxRestarts        int     `yaml:"max_restarts"`
+	ResetPeriod        string  `yaml:"reset_period"`
+	OnNonCrashFailures bool    `yaml:"on_non_crash_failures"`
+}
+
+// SelfTestConfigYAML 防护自检配置YAML结构
+type SelfTestConfigYAML struct {
+	Enabled   bool   `yaml:"enabled"`
+	Interval  string `yaml:"interval"`
+	Timeout   string `yaml:"timeout"`
+	CanaryDir string `yaml:"canary_dir"`
 }
 
 // convertYAMLToProtectionConfig 将YAML配置转换为防护配置
@@ -93,6 +123,37 @@ func convertYAMLToProtectionConfig(yamlConfig ProtectionConfigYAML) (*Protection
 		restartDelay = 3 * time.Second
 	}
 
+	selfTestInterval, err := time.ParseDuration(yamlConfig.SelfTest.Interval)
+	if err != nil {
+		selfTestInterval = time.Hour
+	}
+
+	selfTestTimeout, err := time.ParseDuration(yamlConfig.SelfTest.Timeout)
+	if err != nil {
+		selfTestTimeout = 10 * time.Second
+	}
+
+	processCheckInterval, err := parseProtectorCheckInterval("process_protection", yamlConfig.ProcessProtection.CheckInterval)
+	if err != nil {
+		return nil, err
+	}
+	fileCheckInterval, err := parseProtectorCheckInterval("file_protection", yamlConfig.FileProtection.CheckInterval)
+	if err != nil {
+		return nil, err
+	}
+	registryCheckInterval, err := parseProtectorCheckInterval("registry_protection", yamlConfig.RegistryProtection.CheckInterval)
+	if err != nil {
+		return nil, err
+	}
+	serviceCheckInterval, err := parseProtectorCheckInterval("service_protection", yamlConfig.ServiceProtection.CheckInterval)
+	if err != nil {
+		return nil, err
+	}
+	serviceRecovery, err := convertYAMLToServiceRecoveryConfig(yamlConfig.ServiceProtection.Recovery)
+	if err != nil {
+		return nil, err
+	}
+
 	// 解析防护级别
 	var level ProtectionLevel
 	switch yamlConfig.Level {
@@ -127,25 +188,41 @@ func convertYAMLToProtectionConfig(yamlConfig ProtectionConfigYAML) (*Protection
 			MonitorChildren:    yamlConfig.ProcessProtection.MonitorChildren,
 			PreventDebug:       yamlConfig.ProcessProtection.PreventDebug,
 			PreventDump:        yamlConfig.ProcessProtection.PreventDump,
+			CheckInterval:      processCheckInterval,
 		},
 		FileProtection: FileProtectionConfig{
 			Enabled:        yamlConfig.FileProtection.Enabled,
 			ProtectedFiles: yamlConfig.FileProtection.ProtectedFiles,
 			ProtectedDirs:  yamlConfig.FileProtection.ProtectedDirs,
+			Exclusions:     yamlConfig.FileProtection.Exclusions,
 			CheckIntegrity: yamlConfig.FileProtection.CheckIntegrity,
 			BackupEnabled:  yamlConfig.FileProtection.BackupEnabled,
 			BackupDir:      yamlConfig.FileProtection.BackupDir,
+			CheckInterval:  fileCheckInterval,
+
+			AllowedModifiers: yamlConfig.FileProtection.AllowedModifiers,
 		},
 		RegistryProtection: RegistryProtectionConfig{
 			Enabled:        yamlConfig.RegistryProtection.Enabled,
 			ProtectedKeys:  yamlConfig.RegistryProtection.ProtectedKeys,
 			MonitorChanges: yamlConfig.RegistryProtection.MonitorChanges,
+			CheckInterval:  registryCheckInterval,
+
+			AllowedModifiers: yamlConfig.RegistryProtection.AllowedModifiers,
 		},
 		ServiceProtection: ServiceProtectionConfig{
 			Enabled:        yamlConfig.ServiceProtection.Enabled,
 			ServiceName:    yamlConfig.ServiceProtection.ServiceName,
 			AutoRestart:    yamlConfig.ServiceProtection.AutoRestart,
 			PreventDisable: yamlConfig.ServiceProtection.PreventDisable,
+			CheckInterval:  serviceCheckInterval,
+			Recovery:       serviceRecovery,
+		},
+		SelfTest: SelfTestConfig{
+			Enabled:   yamlConfig.SelfTest.Enabled,
+			Interval:  selfTestInterval,
+			Timeout:   selfTestTimeout,
+			CanaryDir: yamlConfig.SelfTest.CanaryDir,
 		},
 	}
 
@@ -165,6 +242,68 @@ func convertYAMLToProtectionConfig(yamlConfig ProtectionConfigYAML) (*Protection
 	return config, nil
 }
 
+// parseProtectorCheckInterval 解析防护器的检查间隔，未设置时返回0表示使用全局检查间隔
+func parseProtectorCheckInterval(section, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("解析%s.check_interval失败: %w", section, err)
+	}
+	return interval, nil
+}
+
+// convertYAMLToServiceRecoveryConfig 转换服务故障恢复配置，未设置的时间为0，启动服务防护时使用全局重启策略补全
+func convertYAMLToServiceRecoveryConfig(yamlConfig ServiceRecoveryConfigYAML) (ServiceRecoveryConfig, error) {
+	config := ServiceRecoveryConfig{
+		Enabled:            yamlConfig.Enabled,
+		BackoffMultiplier:  yamlConfig.BackoffMultiplier,
+		MaxRestarts:        yamlConfig.MaxRestarts,
+		OnNonCrashFailures: yamlConfig.OnNonCrashFailures,
+	}
+
+	durations := []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"restart_delay", yamlConfig.RestartDelay, &config.RestartDelay},
+		{"max_restart_delay", yamlConfig.MaxRestartDelay, &config.MaxRestartDelay},
+		{"reset_period", yamlConfig.ResetPeriod, &config.ResetPeriod},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		value, err := time.ParseDuration(d.value)
+		if err != nil {
+			return config, fmt.Errorf("解析service_protection.recovery.%s失败: %w", d.name, err)
+		}
+		*d.target = value
+	}
+	return config, nil
+}
+
+// ProtectorCheckInterval 获取防护器的定期检查间隔，未单独配置时使用全局检查间隔
+func (c *ProtectionConfig) ProtectorCheckInterval(protectionType ProtectionType) time.Duration {
+	var interval time.Duration
+	switch protectionType {
+	case ProtectionTypeProcess:
+		interval = c.ProcessProtection.CheckInterval
+	case ProtectionTypeFile:
+		interval = c.FileProtection.CheckInterval
+	case ProtectionTypeRegistry:
+		interval = c.RegistryProtection.CheckInterval
+	case ProtectionTypeService:
+		interval = c.ServiceProtection.CheckInterval
+	}
+	if interval > 0 {
+		return interval
+	}
+	return c.CheckInterval
+}
+
 // ValidateProtectionConfig 验证防护配置
 func ValidateProtectionConfig(config *ProtectionConfig) error {
 	if config == nil {
@@ -184,10 +323,30 @@ func ValidateProtectionConfig(config *ProtectionConfig) error {
 		return fmt.Errorf("检查间隔不能小于1秒")
 	}
 
+	protectorIntervals := []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"进程防护", config.ProcessProtection.CheckInterval},
+		{"文件防护", config.FileProtection.CheckInterval},
+		{"注册表防护", config.RegistryProtection.CheckInterval},
+		{"服务防护", config.ServiceProtection.CheckInterval},
+	}
+	for _, item := range protectorIntervals {
+		if item.interval != 0 && item.interval < time.Second {
+			return fmt.Errorf("%s检查间隔不能小于1秒", item.name)
+		}
+	}
+
 	if config.RestartDelay < 0 {
 		return fmt.Errorf("重启延迟不能为负数")
 	}
 
+	// 验证自检配置
+	if config.SelfTest.Enabled && config.SelfTest.Interval < time.Second {
+		return fmt.Errorf("自检间隔不能小于1秒")
+	}
+
 	// 验证重启尝试次数
 	if config.MaxRestartAttempts < 0 {
 		return fmt.Errorf("最大重启尝试次数不能为负数")
@@ -198,6 +357,11 @@ func ValidateProtectionConfig(config *ProtectionConfig) error {
 		if len(config.ProcessProtection.ProtectedProcesses) == 0 {
 			return fmt.Errorf("启用进程防护时必须指定受保护的进程")
 		}
+		for _, entry := range config.ProcessProtection.ProtectedProcesses {
+			if _, err := ParseProcessMatcher(entry); err != nil {
+				return err
+			}
+		}
 	}
 
 	// 验证文件防护配置
@@ -205,6 +369,12 @@ func ValidateProtectionConfig(config *ProtectionConfig) error {
 		if len(config.FileProtection.ProtectedFiles) == 0 && len(config.FileProtection.ProtectedDirs) == 0 {
 			return fmt.Errorf("启用文件防护时必须指定受保护的文件或目录")
 		}
+		if err := validateDirExclusions(config.FileProtection); err != nil {
+			return err
+		}
+		if _, err := NewModifierAllowlist(config.FileProtection.AllowedModifiers); err != nil {
+			return fmt.Errorf("文件防护的允许修改进程无效: %w", err)
+		}
 	}
 
 	// 验证注册表防护配置
@@ -212,6 +382,9 @@ func ValidateProtectionConfig(config *ProtectionConfig) error {
 		if len(config.RegistryProtection.ProtectedKeys) == 0 {
 			return fmt.Errorf("启用注册表防护时必须指定受保护的注册表键")
 		}
+		if _, err := NewModifierAllowlist(config.RegistryProtection.AllowedModifiers); err != nil {
+			return fmt.Errorf("注册表防护的允许修改进程无效: %w", err)
+		}
 	}
 
 	// 验证服务防护配置
@@ -219,6 +392,9 @@ func ValidateProtectionConfig(config *ProtectionConfig) error {
 		if config.ServiceProtection.ServiceName == "" {
 			return fmt.Errorf("启用服务防护时必须指定服务名称")
 		}
+		if err := validateServiceRecovery(config.ServiceProtection.Recovery); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -279,6 +455,9 @@ func MergeProtectionConfigs(base, override *ProtectionConfig) *ProtectionConfig
 	merged.ProcessProtection.MonitorChildren = override.ProcessProtection.MonitorChildren
 	merged.ProcessProtection.PreventDebug = override.ProcessProtection.PreventDebug
 	merged.ProcessProtection.PreventDump = override.ProcessProtection.PreventDump
+	if override.ProcessProtection.CheckInterval > 0 {
+		merged.ProcessProtection.CheckInterval = override.ProcessProtection.CheckInterval
+	}
 
 	// 合并文件防护配置
 	if override.FileProtection.Enabled {
@@ -290,11 +469,27 @@ func MergeProtectionConfigs(base, override *ProtectionConfig) *ProtectionConfig
 	if len(override.FileProtection.ProtectedDirs) > 0 {
 		merged.FileProtection.ProtectedDirs = append(merged.FileProtection.ProtectedDirs, override.FileProtection.ProtectedDirs...)
 	}
+	if len(override.FileProtection.Exclusions) > 0 {
+		exclusions := make(map[string][]string, len(merged.FileProtection.Exclusions)+len(override.FileProtection.Exclusions))
+		for dir, patterns := range merged.FileProtection.Exclusions {
+			exclusions[dir] = append([]string(nil), patterns...)
+		}
+		for dir, patterns := range override.FileProtection.Exclusions {
+			exclusions[dir] = append(exclusions[dir], patterns...)
+		}
+		merged.FileProtection.Exclusions = exclusions
+	}
 	merged.FileProtection.CheckIntegrity = override.FileProtection.CheckIntegrity
 	merged.FileProtection.BackupEnabled = override.FileProtection.BackupEnabled
 	if override.FileProtection.BackupDir != "" {
 		merged.FileProtection.BackupDir = override.FileProtection.BackupDir
 	}
+	if override.FileProtection.CheckInterval > 0 {
+		merged.FileProtection.CheckInterval = override.FileProtection.CheckInterval
+	}
+	if len(override.FileProtection.AllowedModifiers) > 0 {
+		merged.FileProtection.AllowedModifiers = append(merged.FileProtection.AllowedModifiers, override.FileProtection.AllowedModifiers...)
+	}
 
 	// 合并注册表防护配置
 	if override.RegistryProtection.Enabled {
@@ -304,6 +499,12 @@ func MergeProtectionConfigs(base, override *ProtectionConfig) *ProtectionConfig
 		merged.RegistryProtection.ProtectedKeys = append(merged.RegistryProtection.ProtectedKeys, override.RegistryProtection.ProtectedKeys...)
 	}
 	merged.RegistryProtection.MonitorChanges = override.RegistryProtection.MonitorChanges
+	if override.RegistryProtection.CheckInterval > 0 {
+		merged.RegistryProtection.CheckInterval = override.RegistryProtection.CheckInterval
+	}
+	if len(override.RegistryProtection.AllowedModifiers) > 0 {
+		merged.RegistryProtection.AllowedModifiers = append(merged.RegistryProtection.AllowedModifiers, override.RegistryProtection.AllowedModifiers...)
+	}
 
 	// 合并服务防护配置
 	if override.ServiceProtection.Enabled {
@@ -314,6 +515,26 @@ func MergeProtectionConfigs(base, override *ProtectionConfig) *ProtectionConfig
 	}
 	merged.ServiceProtection.AutoRestart = override.ServiceProtection.AutoRestart
 	merged.ServiceProtection.PreventDisable = override.ServiceProtection.PreventDisable
+	if override.ServiceProtection.CheckInterval > 0 {
+		merged.ServiceProtection.CheckInterval = override.ServiceProtection.CheckInterval
+	}
+	if override.ServiceProtection.Recovery.Enabled {
+		merged.ServiceProtection.Recovery = override.ServiceProtection.Recovery
+	}
+
+	// 合并自检配置
+	if override.SelfTest.Enabled {
+		merged.SelfTest.Enabled = override.SelfTest.Enabled
+	}
+	if override.SelfTest.Interval > 0 {
+		merged.SelfTest.Interval = override.SelfTest.Interval
+	}
+	if override.SelfTest.Timeout > 0 {
+		merged.SelfTest.Timeout = override.SelfTest.Timeout
+	}
+	if override.SelfTest.CanaryDir != "" {
+		merged.SelfTest.CanaryDir = override.SelfTest.CanaryDir
+	}
 
 	return &merged
 }
@@ -338,6 +559,7 @@ func GetProtectionConfigSummary(config *ProtectionConfig) map[string]interface{}
 		"file_protection":         config.FileProtection.Enabled,
 		"registry_protection":     config.RegistryProtection.Enabled,
 		"service_protection":      config.ServiceProtection.Enabled,
+		"service_recovery":        config.ServiceProtection.Recovery.Enabled,
 		"protected_processes":     len(config.ProcessProtection.ProtectedProcesses),
 		"protected_files":         len(config.FileProtection.ProtectedFiles),
 		"protected_dirs":          len(config.FileProtection.ProtectedDirs),
@@ -345,5 +567,13 @@ func GetProtectionConfigSummary(config *ProtectionConfig) map[string]interface{}
 		"whitelist_enabled":       config.Whitelist.Enabled,
 		"whitelist_processes":     len(config.Whitelist.Processes),
 		"whitelist_users":         len(config.Whitelist.Users),
+		"allowed_modifiers":       len(config.FileProtection.AllowedModifiers) + len(config.RegistryProtection.AllowedModifiers),
+		"self_test":               config.SelfTest.Enabled,
+		"protector_check_intervals": map[string]string{
+			string(ProtectionTypeProcess):  config.ProtectorCheckInterval(ProtectionTypeProcess).String(),
+			string(ProtectionTypeFile):     config.ProtectorCheckInterval(ProtectionTypeFile).String(),
+			string(ProtectionTypeRegistry): config.ProtectorCheckInterval(ProtectionTypeRegistry).String(),
+			string(ProtectionTypeService):  config.ProtectorCheckInterval(ProtectionTypeService).String(),
+		},
 	}
 }
@@ -152,6 +152,9 @@ func NewServiceProtector(config ServiceProtectionConfig, logger hclog.Logger) Se
 	}
 }
 
+// ConfigureServiceRecovery 自我防护功能禁用时不修改服务配置
+func ConfigureServiceRecovery(config ServiceProtectionConfig) error { return nil }
+
 func (dsp *DisabledServiceProtector) Start(ctx context.Context) error {
 	dsp.logger.Info("服务防护功能已禁用")
 	return nil
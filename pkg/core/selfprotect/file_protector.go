@@ -30,6 +30,7 @@ type FileProtectorImpl struct {
 	enabled        bool
 	protectedFiles map[string]*ProtectedFile
 	protectedDirs  map[string]*ProtectedDir
+	exclusions     map[string]*DirExclusions // 受保护目录的绝对路径到排除规则的映射
 	eventCallback  EventCallback
 
 	// 文件监控
@@ -38,6 +39,9 @@ type FileProtectorImpl struct {
 
 	// 文件完整性
 	checksums map[string]FileChecksum
+
+	// 允许修改受保护文件的进程
+	modifiers *modifierGuard
 }
 
 // ProtectedFile 受保护的文件信息
@@ -53,11 +57,12 @@ type ProtectedFile struct {
 
 // ProtectedDir 受保护的目录信息
 type ProtectedDir struct {
-	Path      string
-	Recursive bool
-	Protected bool
-	LastCheck time.Time
-	FileCount int
+	Path       string
+	Recursive  bool
+	Protected  bool
+	LastCheck  time.Time
+	FileCount  int
+	Exclusions *DirExclusions
 }
 
 // FileChecksum 文件校验和
@@ -83,7 +88,7 @@ type FileAttributes struct {
 func NewFileProtector(config FileProtectionConfig, logger hclog.Logger) FileProtector {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &FileProtectorImpl{
+	fp := &FileProtectorImpl{
 		config:         config,
 		logger:         logger.Named("file-protector"),
 		ctx:            ctx,
@@ -91,8 +96,30 @@ func NewFileProtector(config FileProtectionConfig, logger hclog.Logger) FileProt
 		enabled:        config.Enabled,
 		protectedFiles: make(map[string]*ProtectedFile),
 		protectedDirs:  make(map[string]*ProtectedDir),
+		exclusions:     make(map[string]*DirExclusions),
 		checksums:      make(map[string]FileChecksum),
 	}
+
+	for dir, patterns := range config.Exclusions {
+		exclusions, err := NewDirExclusions(dir, patterns)
+		if err != nil {
+			fp.logger.Warn("忽略无效的目录排除规则", "dir", dir, "error", err)
+			continue
+		}
+		if existing, ok := fp.exclusions[exclusions.Dir]; ok {
+			existing.Patterns = append(existing.Patterns, exclusions.Patterns...)
+			continue
+		}
+		fp.exclusions[exclusions.Dir] = exclusions
+	}
+
+	modifiers, err := newModifierGuard(config.AllowedModifiers)
+	if err != nil {
+		fp.logger.Warn("忽略无效的允许修改进程配置", "error", err)
+	}
+	fp.modifiers = modifiers
+
+	return fp
 }
 
 // Start 启动文件防护
@@ -174,11 +201,19 @@ func (fp *FileProtectorImpl) PeriodicCheck() error {
 	}
 	fp.mu.RUnlock()
 
+	// 检查受保护目录中新增的文件
+	if fp.config.CheckIntegrity {
+		fp.checkDirectories()
+	}
+
 	for _, file := range files {
 		if fp.config.CheckIntegrity {
 			if valid, err := fp.CheckFileIntegrity(file.Path); err != nil {
 				fp.logger.Error("检查文件完整性失败", "file", file.Path, "error", err)
 			} else if !valid {
+				if fp.acceptAllowedChange(file.Path) {
+					continue
+				}
 				fp.logger.Warn("文件完整性验证失败", "file", file.Path)
 
 				// 记录事件
@@ -212,6 +247,11 @@ func (fp *FileProtectorImpl) SetEventCallback(callback EventCallback) {
 	fp.eventCallback = callback
 }
 
+// SetModifierResolver 设置查询文件修改者的函数，未设置时所有变更都按未授权处理
+func (fp *FileProtectorImpl) SetModifierResolver(resolver ModifierResolver) {
+	fp.modifiers.setResolver(resolver)
+}
+
 // ProtectFile 保护文件
 func (fp *FileProtectorImpl) ProtectFile(filePath string) error {
 	fp.mu.Lock()
@@ -493,12 +533,17 @@ func (fp *FileProtectorImpl) protectDirectory(dirPath string) error {
 	}
 
 	// 添加到保护列表
-	fp.protectedDirs[absPath] = &ProtectedDir{
-		Path:      absPath,
-		Recursive: true,
-		Protected: true,
-		LastCheck: time.Now(),
+	exclusions := fp.exclusions[absPath]
+	dir := &ProtectedDir{
+		Path:       absPath,
+		Recursive:  true,
+		Protected:  true,
+		LastCheck:  time.Now(),
+		Exclusions: exclusions,
 	}
+	fp.mu.Lock()
+	fp.protectedDirs[absPath] = dir
+	fp.mu.Unlock()
 
 	// 添加到文件监控
 	if fp.watcher != nil {
@@ -507,27 +552,109 @@ func (fp *FileProtectorImpl) protectDirectory(dirPath string) error {
 		}
 	}
 
-	// 保护目录中的所有文件
-	err = filepath.Walk(absPath, func(path string, info os.FileInfo, err error) error {
+	// 保护目录中除排除路径外的所有文件，作为完整性基线
+	fileCount := 0
+	err = fp.walkProtectedDir(dir, func(path string) {
+		if err := fp.ProtectFile(path); err != nil {
+			fp.logger.Warn("保护目录文件失败", "file", path, "error", err)
+			return
+		}
+		fileCount++
+	})
+
+	if err != nil {
+		return fmt.Errorf("遍历目录失败: %w", err)
+	}
+
+	fp.mu.Lock()
+	dir.FileCount = fileCount
+	fp.mu.Unlock()
+
+	fp.logger.Info("目录已保护", "dir", absPath, "files", fileCount)
+	return nil
+}
+
+// walkProtectedDir 遍历受保护目录中未被排除的文件，被排除的子目录整体跳过
+func (fp *FileProtectorImpl) walkProtectedDir(dir *ProtectedDir, visit func(path string)) error {
+	return filepath.Walk(dir.Path, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if !info.IsDir() {
-			if err := fp.ProtectFile(path); err != nil {
-				fp.logger.Warn("保护目录文件失败", "file", path, "error", err)
+		if dir.Exclusions.Excludes(path) {
+			if info.IsDir() {
+				return filepath.SkipDir
 			}
+			return nil
 		}
 
+		if !info.IsDir() {
+			visit(path)
+		}
 		return nil
 	})
+}
 
+// IsPathExcluded 检查路径是否被所在受保护目录的排除规则排除
+func (fp *FileProtectorImpl) IsPathExcluded(filePath string) bool {
+	absPath, err := filepath.Abs(filePath)
 	if err != nil {
-		return fmt.Errorf("遍历目录失败: %w", err)
+		return false
 	}
 
-	fp.logger.Info("目录已保护", "dir", absPath)
-	return nil
+	for _, exclusions := range fp.exclusions {
+		if exclusions.Excludes(absPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkDirectories 检查受保护目录中新增的未排除文件，发现后记录事件并加入保护
+func (fp *FileProtectorImpl) checkDirectories() {
+	fp.mu.RLock()
+	dirs := make([]*ProtectedDir, 0, len(fp.protectedDirs))
+	for _, dir := range fp.protectedDirs {
+		dirs = append(dirs, dir)
+	}
+	fp.mu.RUnlock()
+
+	for _, dir := range dirs {
+		var added []string
+		err := fp.walkProtectedDir(dir, func(path string) {
+			if !fp.IsFileProtected(path) {
+				added = append(added, path)
+			}
+		})
+		if err != nil {
+			fp.logger.Error("检查受保护目录失败", "dir", dir.Path, "error", err)
+			continue
+		}
+
+		for _, path := range added {
+			fp.logger.Warn("受保护目录中出现新文件", "dir", dir.Path, "file", path)
+			if fp.eventCallback != nil {
+				fp.eventCallback(ProtectionEvent{
+					Type:        ProtectionTypeFile,
+					Action:      "file_added",
+					Target:      path,
+					Description: fmt.Sprintf("受保护目录 %s 中出现新文件 %s", dir.Path, path),
+					Details: map[string]interface{}{
+						"file_path": path,
+						"dir_path":  dir.Path,
+					},
+				})
+			}
+			if err := fp.ProtectFile(path); err != nil {
+				fp.logger.Warn("保护新增文件失败", "file", path, "error", err)
+			}
+		}
+
+		fp.mu.Lock()
+		dir.FileCount += len(added)
+		dir.LastCheck = time.Now()
+		fp.mu.Unlock()
+	}
 }
 
 // calculateFileChecksum 计算文件校验和
@@ -627,6 +754,11 @@ func (fp *FileProtectorImpl) handleFileEvent(event fsnotify.Event) {
 		return
 	}
 
+	// 允许的进程修改的文件不做恢复
+	if fp.acceptAllowedChange(event.Name) {
+		return
+	}
+
 	var action string
 	var blocked bool
 
@@ -663,6 +795,67 @@ func (fp *FileProtectorImpl) handleFileEvent(event fsnotify.Event) {
 	}
 }
 
+// acceptAllowedChange 变更来自允许的进程时，以当前内容作为新的基线并记录审计事件
+func (fp *FileProtectorImpl) acceptAllowedChange(filePath string) bool {
+	process, entry, allowed := fp.modifiers.allowed(filePath)
+	if !allowed {
+		return false
+	}
+
+	fp.logger.Info("受保护文件由允许的进程修改", "file", filePath, "process", process.Path, "pid", process.PID)
+	if err := fp.rebaseline(filePath); err != nil {
+		fp.logger.Warn("更新文件基线失败", "file", filePath, "error", err)
+	}
+
+	if fp.eventCallback != nil {
+		fp.eventCallback(allowedChangeEvent(ProtectionTypeFile, filePath, process, entry))
+	}
+	return true
+}
+
+// rebaseline 重新计算文件的校验和与属性，启用备份时重新备份
+// 文件已被删除时保留原基线，重新创建后再次更新
+func (fp *FileProtectorImpl) rebaseline(filePath string) error {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	checksum, err := fp.calculateFileChecksum(filePath)
+	if err != nil {
+		return fmt.Errorf("计算文件校验和失败: %w", err)
+	}
+	attributes, err := fp.getFileAttributes(fileInfo)
+	if err != nil {
+		return fmt.Errorf("获取文件属性失败: %w", err)
+	}
+
+	var backupPath string
+	if fp.config.BackupEnabled {
+		if backupPath, err = fp.BackupFile(filePath); err != nil {
+			return err
+		}
+	}
+
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	file, exists := fp.protectedFiles[filePath]
+	if !exists {
+		return nil
+	}
+	file.Checksum = checksum
+	file.Attributes = attributes
+	file.LastCheck = time.Now()
+	if backupPath != "" {
+		file.BackupPath = backupPath
+	}
+	return nil
+}
+
 // handleFileModification 处理文件修改
 func (fp *FileProtectorImpl) handleFileModification(filePath string) bool {
 	fp.logger.Warn("检测到受保护文件被修改", "file", filePath)
@@ -1,6 +1,7 @@
 package selfprotect
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"time"
@@ -87,6 +88,12 @@ func (ps *ProtectionService) IsEnabled() bool {
 	return ps.started && ps.manager.IsEnabled()
 }
 
+// OnStatusChange 注册防护状态变化回调
+// 与通讯模块集成时可将 RemoteReporter.NotifyStatusChange 注册为回调，状态变化立即推送到服务端
+func (ps *ProtectionService) OnStatusChange(callback StatusChangeCallback) {
+	ps.manager.OnStatusChange(callback)
+}
+
 // GetStatus 获取防护状态
 func (ps *ProtectionService) GetStatus() ProtectionStatus {
 	if !ps.started {
@@ -116,6 +123,27 @@ func (ps *ProtectionService) GetEvents() []ProtectionEvent {
 	return ps.manager.GetEvents()
 }
 
+// RunSelfTest 立即执行一次防护自检
+func (ps *ProtectionService) RunSelfTest(ctx context.Context) SelfTestResult {
+	if !ps.started {
+		return SelfTestResult{
+			StartTime: time.Now(),
+			Message:   "自我防护服务未启动，无法自检",
+		}
+	}
+
+	return ps.manager.RunSelfTest(ctx)
+}
+
+// LastSelfTest 获取最近一次自检结果
+func (ps *ProtectionService) LastSelfTest() *SelfTestResult {
+	if !ps.started {
+		return nil
+	}
+
+	return ps.manager.LastSelfTest()
+}
+
 // GetConfig 获取防护配置
 func (ps *ProtectionService) GetConfig() *ProtectionConfig {
 	return ps.config
@@ -133,6 +161,11 @@ type ProtectionStatus struct {
 type ProtectionIntegrator struct {
 	service *ProtectionService
 	logger  hclog.Logger
+
+	// 通讯模块可用时创建远程上报器，将防护状态和事件推送到服务端
+	sender         StatusSender
+	reporterConfig RemoteReporterConfig
+	reporter       *RemoteReporter
 }
 
 // NewProtectionIntegrator 创建防护集成器
@@ -152,11 +185,19 @@ func NewProtectionIntegrator(configFile string, logger hclog.Logger) (*Protectio
 func (pi *ProtectionIntegrator) Initialize() error {
 	pi.logger.Info("初始化自我防护")
 
+	// 在启动防护前注册状态变化回调，不遗漏启动时的状态变化
+	pi.createRemoteReporter()
+
 	// 启动防护服务
 	if err := pi.service.Start(); err != nil {
 		return fmt.Errorf("启动防护服务失败: %w", err)
 	}
 
+	// 启动远程上报
+	if pi.reporter != nil {
+		pi.reporter.Start()
+	}
+
 	// 注册优雅关闭处理
 	pi.registerShutdownHandler()
 
@@ -167,6 +208,31 @@ func (pi *ProtectionIntegrator) Initialize() error {
 func (pi *ProtectionIntegrator) Shutdown() {
 	pi.logger.Info("关闭自我防护")
 	pi.service.Stop()
+	if pi.reporter != nil {
+		pi.reporter.Stop()
+	}
+}
+
+// SetStatusSender 设置通讯模块，Initialize 时创建远程上报器，需要在 Initialize 之前调用
+// sender 通常为 comm.Manager，为空时不上报
+func (pi *ProtectionIntegrator) SetStatusSender(sender StatusSender, config RemoteReporterConfig) {
+	pi.sender = sender
+	pi.reporterConfig = config
+}
+
+// GetRemoteReporter 获取远程上报器，未设置通讯模块时返回nil
+func (pi *ProtectionIntegrator) GetRemoteReporter() *RemoteReporter {
+	return pi.reporter
+}
+
+// createRemoteReporter 设置了通讯模块时创建远程上报器，状态变化立即推送，防护事件按周期上报
+func (pi *ProtectionIntegrator) createRemoteReporter() {
+	if pi.sender == nil || pi.reporter != nil {
+		return
+	}
+
+	pi.reporter = NewRemoteReporter(pi.service, pi.sender, pi.reporterConfig, pi.logger)
+	pi.service.OnStatusChange(pi.reporter.NotifyStatusChange)
 }
 
 // GetService 获取防护服务
@@ -263,6 +329,19 @@ func (phc *ProtectionHealthChecker) CheckHealth() HealthCheckResult {
 	status := phc.service.GetStatus()
 	stats := status.Stats
 
+	// 最近一次自检失败说明防护实际未生效
+	selfTest := phc.service.LastSelfTest()
+	if selfTest != nil && !selfTest.Passed {
+		return HealthCheckResult{
+			Status:  "unhealthy",
+			Message: fmt.Sprintf("防护自检失败: %s", selfTest.Message),
+			Details: map[string]interface{}{
+				"self_test": selfTest,
+				"stats":     stats,
+			},
+		}
+	}
+
 	// 检查健康指标
 	healthScore := stats.ConfigHealthScore
 	if healthScore < 50 {
@@ -285,10 +364,28 @@ func (phc *ProtectionHealthChecker) CheckHealth() HealthCheckResult {
 			"start_time":   status.StartTime,
 			"health_score": healthScore,
 			"stats":        stats,
+			"self_test":    selfTest,
 		},
 	}
 }
 
+// RunSelfTest 按需执行防护自检并返回自检后的健康状态
+func (phc *ProtectionHealthChecker) RunSelfTest(ctx context.Context) HealthCheckResult {
+	result := phc.service.RunSelfTest(ctx)
+	if !phc.service.started {
+		return HealthCheckResult{
+			Status:  "unhealthy",
+			Message: result.Message,
+			Details: map[string]interface{}{
+				"started":   false,
+				"self_test": result,
+			},
+		}
+	}
+
+	return phc.CheckHealth()
+}
+
 // HealthCheckResult 健康检查结果
 type HealthCheckResult struct {
 	Status  string                 `json:"status"`
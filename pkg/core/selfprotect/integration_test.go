@@ -0,0 +1,62 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtectionIntegrator_RemoteReporting(t *testing.T) {
+	dir := t.TempDir()
+	emergencyFile := filepath.Join(dir, "disable_protection")
+	require.NoError(t, os.WriteFile(emergencyFile, nil, 0644))
+
+	configFile := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(fmt.Sprintf(`self_protection:
+  enabled: true
+  level: basic
+  emergency_disable: %q
+  check_interval: 10s
+`, emergencyFile)), 0644))
+
+	integrator, err := NewProtectionIntegrator(configFile, hclog.NewNullLogger())
+	require.NoError(t, err)
+	sender := &fakeSender{connected: true}
+	integrator.SetStatusSender(sender, RemoteReporterConfig{Interval: time.Hour})
+
+	// 启动时检测到紧急禁用文件，状态变化经上报器推送到通讯模块
+	require.NoError(t, integrator.Initialize())
+	defer integrator.Shutdown()
+	require.NotNil(t, integrator.GetRemoteReporter())
+
+	dataCount, eventCount := sender.counts()
+	require.Equal(t, 1, eventCount)
+	assert.Equal(t, 1, dataCount)
+	assert.Equal(t, string(StatusChangeReasonEmergencyFile), sender.events[0]["reason"])
+	assert.Equal(t, false, sender.events[0]["enabled"])
+
+	// 周期上报发送当前防护状态
+	integrator.GetRemoteReporter().Report()
+	dataCount, _ = sender.counts()
+	assert.Equal(t, 2, dataCount)
+}
+
+func TestProtectionIntegrator_NoSenderNoReporter(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("self_protection:\n  enabled: false\n"), 0644))
+
+	integrator, err := NewProtectionIntegrator(configFile, hclog.NewNullLogger())
+	require.NoError(t, err)
+	require.NoError(t, integrator.Initialize())
+	defer integrator.Shutdown()
+	assert.Nil(t, integrator.GetRemoteReporter())
+}
@@ -0,0 +1,115 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ModifierResolver 返回最近修改目标（文件路径或注册表键）的进程
+// 由平台的审计机制提供，无法确定修改者时返回 false，此时变更按未授权处理
+type ModifierResolver func(target string) (ProcessCandidate, bool)
+
+// ModifierAllowlist 允许修改受保护资源的进程
+// 配置项格式与受保护进程相同，但只接受完整路径和 "sha256:<哈希>"：进程名可以被任意程序冒用
+type ModifierAllowlist struct {
+	matchers []*ProcessMatcher
+	hasher   *ExecutableHasher
+}
+
+// NewModifierAllowlist 解析允许修改受保护资源的进程配置
+func NewModifierAllowlist(entries []string) (*ModifierAllowlist, error) {
+	allowlist := &ModifierAllowlist{hasher: NewExecutableHasher()}
+	for _, entry := range entries {
+		matcher, err := ParseProcessMatcher(entry)
+		if err != nil {
+			return nil, err
+		}
+		if matcher.Mode == ProcessMatchName {
+			return nil, fmt.Errorf("允许修改的进程必须按完整路径或SHA-256哈希指定: %s", entry)
+		}
+		allowlist.matchers = append(allowlist.matchers, matcher)
+	}
+	return allowlist, nil
+}
+
+// Empty 是否没有配置任何进程
+func (a *ModifierAllowlist) Empty() bool {
+	return a == nil || len(a.matchers) == 0
+}
+
+// Match 返回进程匹配的配置项
+func (a *ModifierAllowlist) Match(process ProcessCandidate) (string, bool) {
+	if a.Empty() {
+		return "", false
+	}
+	for _, matcher := range a.matchers {
+		if matcher.Match(process, a.hasher) {
+			return matcher.Entry, true
+		}
+	}
+	return "", false
+}
+
+// modifierGuard 判断受保护资源的变更是否来自允许的进程，供各防护器共用
+type modifierGuard struct {
+	allowlist *ModifierAllowlist
+
+	mu       sync.RWMutex
+	resolver ModifierResolver
+}
+
+// newModifierGuard 创建变更来源检查，配置无效时返回错误和空的允许列表
+func newModifierGuard(entries []string) (*modifierGuard, error) {
+	allowlist, err := NewModifierAllowlist(entries)
+	if err != nil {
+		return &modifierGuard{}, err
+	}
+	return &modifierGuard{allowlist: allowlist}, nil
+}
+
+// setResolver 设置修改者查询函数
+func (g *modifierGuard) setResolver(resolver ModifierResolver) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.resolver = resolver
+}
+
+// allowed 检查目标的最近一次变更是否来自允许的进程，返回修改者和匹配的配置项
+func (g *modifierGuard) allowed(target string) (ProcessCandidate, string, bool) {
+	if g.allowlist.Empty() {
+		return ProcessCandidate{}, "", false
+	}
+
+	g.mu.RLock()
+	resolver := g.resolver
+	g.mu.RUnlock()
+	if resolver == nil {
+		return ProcessCandidate{}, "", false
+	}
+
+	process, ok := resolver(target)
+	if !ok {
+		return ProcessCandidate{}, "", false
+	}
+	entry, matched := g.allowlist.Match(process)
+	return process, entry, matched
+}
+
+// allowedChangeEvent 允许的变更产生的审计事件，不触发防护响应
+func allowedChangeEvent(protectionType ProtectionType, target string, process ProcessCandidate, entry string) ProtectionEvent {
+	return ProtectionEvent{
+		Type:        protectionType,
+		Action:      "allowed_change",
+		Target:      target,
+		Source:      process.Path,
+		Description: fmt.Sprintf("%s 由允许的进程 %s 修改", target, process.Path),
+		Details: map[string]interface{}{
+			"pid":           process.PID,
+			"process_path":  process.Path,
+			"allowed_entry": entry,
+		},
+	}
+}
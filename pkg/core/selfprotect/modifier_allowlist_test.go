@@ -0,0 +1,136 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewModifierAllowlist(t *testing.T) {
+	dir := t.TempDir()
+	installer, _ := writeTestBinary(t, dir, "installer.exe", "installer v1")
+	updater, updaterHash := writeTestBinary(t, dir, "updater.exe", "updater v1")
+
+	allowlist, err := NewModifierAllowlist([]string{installer, "sha256:" + updaterHash})
+	require.NoError(t, err)
+
+	entry, ok := allowlist.Match(ProcessCandidate{PID: 10, Path: installer})
+	assert.True(t, ok)
+	assert.Equal(t, installer, entry)
+
+	// 哈希匹配不受路径影响
+	renamed := filepath.Join(dir, "renamed.exe")
+	require.NoError(t, os.Rename(updater, renamed))
+	_, ok = allowlist.Match(ProcessCandidate{PID: 11, Path: renamed})
+	assert.True(t, ok)
+
+	// 同名但路径不同的进程不被允许
+	_, ok = allowlist.Match(ProcessCandidate{PID: 12, Name: "installer.exe", Path: filepath.Join(dir, "tmp", "installer.exe")})
+	assert.False(t, ok)
+
+	// 进程名可被冒用，不能作为允许修改的依据
+	_, err = NewModifierAllowlist([]string{"installer.exe"})
+	assert.Error(t, err)
+	_, err = NewModifierAllowlist([]string{"sha256:1234"})
+	assert.Error(t, err)
+
+	var empty *ModifierAllowlist
+	assert.True(t, empty.Empty())
+	_, ok = empty.Match(ProcessCandidate{Path: installer})
+	assert.False(t, ok)
+}
+
+func TestValidateConfig_AllowedModifiers(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultProtectionConfig()
+	config.FileProtection = FileProtectionConfig{
+		Enabled:          true,
+		ProtectedDirs:    []string{dir},
+		AllowedModifiers: []string{filepath.Join(dir, "installer.exe")},
+	}
+	require.NoError(t, ValidateProtectionConfig(config))
+
+	config.FileProtection.AllowedModifiers = []string{"installer.exe"}
+	assert.Error(t, ValidateProtectionConfig(config))
+}
+
+func TestFileProtector_AllowedModifier(t *testing.T) {
+	dir := t.TempDir()
+	binDir := filepath.Join(dir, "bin")
+	require.NoError(t, os.MkdirAll(binDir, 0755))
+	installer, _ := writeTestBinary(t, binDir, "installer.exe", "installer v1")
+	updater, updaterHash := writeTestBinary(t, binDir, "updater.exe", "updater v1")
+	impostor, _ := writeTestBinary(t, dir, "installer.exe", "malware")
+
+	target := filepath.Join(dir, "agent.yaml")
+	require.NoError(t, os.WriteFile(target, []byte("version: 1"), 0644))
+
+	protector := NewFileProtector(FileProtectionConfig{
+		Enabled:          true,
+		ProtectedFiles:   []string{target},
+		CheckIntegrity:   true,
+		BackupEnabled:    true,
+		BackupDir:        t.TempDir(),
+		AllowedModifiers: []string{installer, "sha256:" + updaterHash},
+	}, hclog.NewNullLogger()).(*FileProtectorImpl)
+	recorder := &eventRecorder{}
+	protector.SetEventCallback(recorder.record)
+	require.NoError(t, protector.ProtectFile(target))
+
+	// 模拟平台审计报告的修改者
+	var modifier ProcessCandidate
+	protector.SetModifierResolver(func(path string) (ProcessCandidate, bool) {
+		return modifier, path == target && modifier.Path != ""
+	})
+	modify := func(process ProcessCandidate, content string) {
+		modifier = process
+		require.NoError(t, os.WriteFile(target, []byte(content), 0644))
+		protector.handleFileEvent(fsnotify.Event{Name: target, Op: fsnotify.Write})
+	}
+	read := func() string {
+		content, err := os.ReadFile(target)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	// 安装程序按路径允许：保留修改并作为新的基线
+	modify(ProcessCandidate{PID: 100, Path: installer}, "version: 2")
+	assert.Equal(t, "version: 2", read())
+	assert.Equal(t, []string{target}, recorder.targets("allowed_change"))
+	assert.Empty(t, recorder.targets("modify"))
+	assert.Empty(t, recorder.targets("restore"))
+	valid, err := protector.CheckFileIntegrity(target)
+	require.NoError(t, err)
+	assert.True(t, valid, "允许的修改应更新基线")
+
+	// 升级服务按哈希允许
+	modify(ProcessCandidate{PID: 101, Path: updater}, "version: 3")
+	assert.Equal(t, "version: 3", read())
+	assert.Len(t, recorder.targets("allowed_change"), 2)
+	assert.Empty(t, recorder.targets("restore"))
+
+	// 同名但不在允许列表中的进程：触发防护并从最新的基线恢复
+	modify(ProcessCandidate{PID: 200, Name: "installer.exe", Path: impostor}, "version: evil")
+	assert.Equal(t, "version: 3", read())
+	assert.Equal(t, []string{target}, recorder.targets("modify"))
+	assert.Equal(t, []string{target}, recorder.targets("restore"))
+	for _, event := range recorder.snapshot() {
+		if event.Action == "modify" {
+			assert.True(t, event.Blocked)
+		}
+	}
+
+	// 无法确定修改者时按未授权处理
+	modify(ProcessCandidate{}, "version: unknown")
+	assert.Equal(t, "version: 3", read())
+	assert.Len(t, recorder.targets("restore"), 2)
+	assert.Len(t, recorder.targets("allowed_change"), 2)
+}
@@ -0,0 +1,99 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultModifierAuditWindow 查询修改者时回溯的审计事件时间范围
+const DefaultModifierAuditWindow = 10 * time.Minute
+
+// 记录修改者的Windows安全审计事件
+const (
+	// auditEventObjectAccess 对象访问（文件写入、删除等），需要对受保护路径配置SACL
+	auditEventObjectAccess = 4663
+	// auditEventRegistryValueModified 注册表值被修改，需要对受保护键配置SACL
+	auditEventRegistryValueModified = 4657
+)
+
+// registryAuditPrefixes 注册表根键到审计事件中对象名称前缀的映射
+// HKEY_CURRENT_USER 在审计事件中记录为具体用户的SID，无法从配置的键名确定，不支持
+var registryAuditPrefixes = []struct {
+	root   string
+	object string
+}{
+	{"HKEY_LOCAL_MACHINE", `\REGISTRY\MACHINE`},
+	{"HKLM", `\REGISTRY\MACHINE`},
+	{"HKEY_USERS", `\REGISTRY\USER`},
+	{"HKU", `\REGISTRY\USER`},
+}
+
+// auditObjectName 返回目标在审计事件中的对象名称
+// 文件目标为完整路径，注册表目标转换为 \REGISTRY\... 形式
+func auditObjectName(target string) (string, bool) {
+	upper := strings.ToUpper(target)
+	for _, prefix := range registryAuditPrefixes {
+		if upper == prefix.root || strings.HasPrefix(upper, prefix.root+`\`) {
+			return prefix.object + target[len(prefix.root):], true
+		}
+	}
+	if strings.HasPrefix(upper, "HKEY_") || strings.HasPrefix(upper, "HKCU") {
+		return "", false
+	}
+	return target, target != ""
+}
+
+// auditQuery 构造查询目标最近修改记录的事件日志XPath查询
+// 对象名称包含单引号时无法在XPath字符串中表示，返回 false
+func auditQuery(objectName string, window time.Duration) (string, bool) {
+	if strings.Contains(objectName, "'") {
+		return "", false
+	}
+	return fmt.Sprintf(
+		"*[System[(EventID=%d or EventID=%d) and TimeCreated[timediff(@SystemTime) <= %d]]] and *[EventData[Data[@Name='ObjectName']='%s']]",
+		auditEventObjectAccess, auditEventRegistryValueModified, window.Milliseconds(), objectName,
+	), true
+}
+
+// auditEvent 审计事件XML中需要的字段
+type auditEvent struct {
+	Data []struct {
+		Name  string `xml:"Name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"EventData>Data"`
+}
+
+// parseAuditEvent 从审计事件XML中解析修改者进程
+func parseAuditEvent(data []byte) (ProcessCandidate, bool) {
+	var event auditEvent
+	if err := xml.Unmarshal(data, &event); err != nil {
+		return ProcessCandidate{}, false
+	}
+
+	var process ProcessCandidate
+	for _, field := range event.Data {
+		value := strings.TrimSpace(field.Value)
+		switch field.Name {
+		case "ProcessId":
+			// 审计事件中的进程ID为十六进制
+			pid, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(value), "0x"), 16, 32)
+			if err == nil {
+				process.PID = uint32(pid)
+			}
+		case "ProcessName":
+			process.Path = value
+			if i := strings.LastIndexAny(value, `\/`); i >= 0 {
+				process.Name = value[i+1:]
+			} else {
+				process.Name = value
+			}
+		}
+	}
+	return process, process.Path != ""
+}
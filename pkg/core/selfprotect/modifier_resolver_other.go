@@ -0,0 +1,9 @@
+//go:build selfprotect && !windows
+// +build selfprotect,!windows
+
+package selfprotect
+
+// platformModifierResolver 非Windows平台没有可用的修改者审计来源，allowed_modifiers 不生效
+func platformModifierResolver() ModifierResolver {
+	return nil
+}
@@ -0,0 +1,61 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditObjectName(t *testing.T) {
+	name, ok := auditObjectName(`C:\Program Files\Kennel\config.yaml`)
+	assert.True(t, ok)
+	assert.Equal(t, `C:\Program Files\Kennel\config.yaml`, name)
+
+	name, ok = auditObjectName(`HKEY_LOCAL_MACHINE\SOFTWARE\Kennel`)
+	assert.True(t, ok)
+	assert.Equal(t, `\REGISTRY\MACHINE\SOFTWARE\Kennel`, name)
+
+	name, ok = auditObjectName(`HKU\S-1-5-18\Software\Kennel`)
+	assert.True(t, ok)
+	assert.Equal(t, `\REGISTRY\USER\S-1-5-18\Software\Kennel`, name)
+
+	// 当前用户的键在审计事件中记录为SID，无法确定
+	_, ok = auditObjectName(`HKEY_CURRENT_USER\SOFTWARE\Kennel`)
+	assert.False(t, ok)
+}
+
+func TestAuditQuery(t *testing.T) {
+	query, ok := auditQuery(`C:\Kennel\agent.exe`, 10*time.Minute)
+	require.True(t, ok)
+	assert.Contains(t, query, "EventID=4663")
+	assert.Contains(t, query, "timediff(@SystemTime) <= 600000")
+	assert.Contains(t, query, `Data[@Name='ObjectName']='C:\Kennel\agent.exe'`)
+
+	_, ok = auditQuery(`C:\Kennel's\agent.exe`, time.Minute)
+	assert.False(t, ok)
+}
+
+func TestParseAuditEvent(t *testing.T) {
+	data := []byte(`<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event">
+  <System><EventID>4663</EventID></System>
+  <EventData>
+    <Data Name="ObjectName">C:\Kennel\config.yaml</Data>
+    <Data Name="ProcessId">0x1a4</Data>
+    <Data Name="ProcessName">C:\Program Files\Kennel\installer.exe</Data>
+  </EventData>
+</Event>`)
+
+	process, ok := parseAuditEvent(data)
+	require.True(t, ok)
+	assert.Equal(t, uint32(0x1a4), process.PID)
+	assert.Equal(t, `C:\Program Files\Kennel\installer.exe`, process.Path)
+	assert.Equal(t, "installer.exe", process.Name)
+
+	_, ok = parseAuditEvent([]byte(`<Event><EventData></EventData></Event>`))
+	assert.False(t, ok)
+}
@@ -0,0 +1,118 @@
+//go:build selfprotect && windows
+// +build selfprotect,windows
+
+package selfprotect
+
+import (
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	wevtapi = windows.NewLazySystemDLL("wevtapi.dll")
+
+	procEvtQuery  = wevtapi.NewProc("EvtQuery")
+	procEvtNext   = wevtapi.NewProc("EvtNext")
+	procEvtRender = wevtapi.NewProc("EvtRender")
+	procEvtClose  = wevtapi.NewProc("EvtClose")
+)
+
+const (
+	EVT_QUERY_CHANNEL_PATH      = 0x1
+	EVT_QUERY_REVERSE_DIRECTION = 0x200
+	EVT_RENDER_EVENT_XML        = 1
+
+	// 等待查询结果的超时时间（毫秒）
+	evtNextTimeout = 1000
+)
+
+// NewAuditModifierResolver 创建从Windows安全审计日志查询修改者的函数
+// 依赖对受保护文件和注册表键配置的对象访问审计（事件4663、4657），未启用审计时查不到修改者，变更按未授权处理
+func NewAuditModifierResolver(window time.Duration) ModifierResolver {
+	if window <= 0 {
+		window = DefaultModifierAuditWindow
+	}
+
+	return func(target string) (ProcessCandidate, bool) {
+		objectName, ok := auditObjectName(target)
+		if !ok {
+			return ProcessCandidate{}, false
+		}
+		query, ok := auditQuery(objectName, window)
+		if !ok {
+			return ProcessCandidate{}, false
+		}
+
+		data, ok := queryLatestSecurityEvent(query)
+		if !ok {
+			return ProcessCandidate{}, false
+		}
+		return parseAuditEvent(data)
+	}
+}
+
+// platformModifierResolver 返回平台的修改者查询函数
+func platformModifierResolver() ModifierResolver {
+	if err := wevtapi.Load(); err != nil {
+		return nil
+	}
+	return NewAuditModifierResolver(DefaultModifierAuditWindow)
+}
+
+// queryLatestSecurityEvent 查询安全日志中符合条件的最近一条事件，返回事件XML
+func queryLatestSecurityEvent(query string) ([]byte, bool) {
+	channel, err := windows.UTF16PtrFromString("Security")
+	if err != nil {
+		return nil, false
+	}
+	queryPtr, err := windows.UTF16PtrFromString(query)
+	if err != nil {
+		return nil, false
+	}
+
+	results, _, _ := procEvtQuery.Call(0,
+		uintptr(unsafe.Pointer(channel)),
+		uintptr(unsafe.Pointer(queryPtr)),
+		EVT_QUERY_CHANNEL_PATH|EVT_QUERY_REVERSE_DIRECTION)
+	if results == 0 {
+		return nil, false
+	}
+	defer procEvtClose.Call(results)
+
+	var event uintptr
+	var returned uint32
+	ret, _, _ := procEvtNext.Call(results, 1,
+		uintptr(unsafe.Pointer(&event)),
+		evtNextTimeout, 0,
+		uintptr(unsafe.Pointer(&returned)))
+	if ret == 0 || returned == 0 {
+		return nil, false
+	}
+	defer procEvtClose.Call(event)
+
+	return renderEventXML(event)
+}
+
+// renderEventXML 将事件渲染为XML
+func renderEventXML(event uintptr) ([]byte, bool) {
+	var used, properties uint32
+	procEvtRender.Call(0, event, EVT_RENDER_EVENT_XML, 0, 0,
+		uintptr(unsafe.Pointer(&used)),
+		uintptr(unsafe.Pointer(&properties)))
+	if used == 0 {
+		return nil, false
+	}
+
+	buffer := make([]uint16, (used+1)/2)
+	ret, _, _ := procEvtRender.Call(0, event, EVT_RENDER_EVENT_XML,
+		uintptr(len(buffer)*2),
+		uintptr(unsafe.Pointer(&buffer[0])),
+		uintptr(unsafe.Pointer(&used)),
+		uintptr(unsafe.Pointer(&properties)))
+	if ret == 0 {
+		return nil, false
+	}
+	return []byte(windows.UTF16ToString(buffer)), true
+}
@@ -0,0 +1,115 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DirExclusions 受保护目录的排除规则
+// 模式相对于受保护目录，使用 / 分隔，支持 * ? [] 通配符：
+//   - 不含 / 的模式匹配任意层级的文件名或目录名，如 "*.log"、"cache"
+//   - 含 / 的模式从受保护目录开始匹配，如 "logs/*"、"data/tmp"
+//
+// 模式匹配到目录时，排除该目录下的整个子树
+type DirExclusions struct {
+	Dir      string   // 受保护目录的绝对路径
+	Patterns []string // 排除模式
+}
+
+// ValidateExclusionPattern 检查排除模式是否有效
+func ValidateExclusionPattern(pattern string) error {
+	cleaned := cleanExclusionPattern(pattern)
+	if cleaned == "" || cleaned == "." {
+		return fmt.Errorf("排除模式不能为空")
+	}
+	if strings.HasPrefix(cleaned, "/") || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return fmt.Errorf("排除模式不能指向受保护目录之外: %s", pattern)
+	}
+	if _, err := path.Match(cleaned, ""); err != nil {
+		return fmt.Errorf("排除模式无效 %s: %w", pattern, err)
+	}
+	return nil
+}
+
+// NewDirExclusions 创建受保护目录的排除规则，dir 转换为绝对路径
+func NewDirExclusions(dir string, patterns []string) (*DirExclusions, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("获取绝对路径失败: %w", err)
+	}
+
+	exclusions := &DirExclusions{Dir: absDir}
+	for _, pattern := range patterns {
+		if err := ValidateExclusionPattern(pattern); err != nil {
+			return nil, err
+		}
+		exclusions.Patterns = append(exclusions.Patterns, cleanExclusionPattern(pattern))
+	}
+	return exclusions, nil
+}
+
+// Excludes 检查路径是否被排除，不在受保护目录下的路径不被排除
+func (e *DirExclusions) Excludes(filePath string) bool {
+	if e == nil || len(e.Patterns) == 0 {
+		return false
+	}
+
+	rel, err := filepath.Rel(e.Dir, filePath)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return false
+	}
+
+	// 依次检查路径本身及其各级父目录
+	for current := rel; current != "." && current != ""; current = path.Dir(current) {
+		name := path.Base(current)
+		for _, pattern := range e.Patterns {
+			target := current
+			if !strings.Contains(pattern, "/") {
+				target = name
+			}
+			if matched, _ := path.Match(pattern, target); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateDirExclusions 检查排除规则对应的目录均为受保护目录，且模式有效
+func validateDirExclusions(config FileProtectionConfig) error {
+	protected := make(map[string]bool, len(config.ProtectedDirs))
+	for _, dir := range config.ProtectedDirs {
+		if absDir, err := filepath.Abs(dir); err == nil {
+			protected[absDir] = true
+		}
+	}
+
+	for dir, patterns := range config.Exclusions {
+		exclusions, err := NewDirExclusions(dir, patterns)
+		if err != nil {
+			return fmt.Errorf("目录 %s 的排除规则无效: %w", dir, err)
+		}
+		if !protected[exclusions.Dir] {
+			return fmt.Errorf("排除规则对应的目录未受保护: %s", dir)
+		}
+	}
+	return nil
+}
+
+// cleanExclusionPattern 规范化排除模式
+func cleanExclusionPattern(pattern string) string {
+	pattern = strings.TrimSpace(strings.ReplaceAll(pattern, "\\", "/"))
+	if pattern == "" {
+		return ""
+	}
+	return strings.TrimPrefix(path.Clean(pattern), "./")
+}
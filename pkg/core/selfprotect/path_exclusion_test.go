@@ -0,0 +1,170 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirExclusions_Excludes(t *testing.T) {
+	dir := t.TempDir()
+	exclusions, err := NewDirExclusions(dir, []string{"logs", "*.tmp", "data/cache/*", "./build/"})
+	require.NoError(t, err)
+
+	cases := map[string]bool{
+		"logs":                  true,
+		"logs/agent.log":        true,
+		"logs/2024/01/app.log":  true,
+		"plugins/logs/x.log":    true, // 不含 / 的模式匹配任意层级
+		"session.tmp":           true,
+		"plugins/state.tmp":     true,
+		"data/cache/index":      true,
+		"data/cache/a/b":        true,
+		"build/output.bin":      true,
+		"data/cache":            false,
+		"data/config.yaml":      false,
+		"agent.exe":             false,
+		"plugins/dlp/plugin.so": false,
+		"logsbackup/file":       false,
+	}
+	for rel, want := range cases {
+		assert.Equal(t, want, exclusions.Excludes(filepath.Join(dir, filepath.FromSlash(rel))), rel)
+	}
+
+	// 受保护目录本身及目录之外的路径不被排除
+	assert.False(t, exclusions.Excludes(dir))
+	assert.False(t, exclusions.Excludes(filepath.Join(filepath.Dir(dir), "logs")))
+
+	var nilExclusions *DirExclusions
+	assert.False(t, nilExclusions.Excludes(filepath.Join(dir, "logs")))
+}
+
+func TestValidateExclusionPattern(t *testing.T) {
+	for _, pattern := range []string{"logs", "*.log", "data/cache/*", `cache\tmp`} {
+		assert.NoError(t, ValidateExclusionPattern(pattern), pattern)
+	}
+	for _, pattern := range []string{"", "  ", ".", "../secrets", "/var/log", "[unclosed"} {
+		assert.Error(t, ValidateExclusionPattern(pattern), pattern)
+	}
+}
+
+func TestValidateConfig_DirExclusions(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultProtectionConfig()
+	config.FileProtection = FileProtectionConfig{
+		Enabled:       true,
+		ProtectedDirs: []string{dir},
+		Exclusions:    map[string][]string{dir: {"logs", "*.tmp"}},
+	}
+	require.NoError(t, ValidateProtectionConfig(config))
+
+	config.FileProtection.Exclusions = map[string][]string{dir: {"[bad"}}
+	assert.Error(t, ValidateProtectionConfig(config))
+
+	config.FileProtection.Exclusions = map[string][]string{filepath.Join(dir, "other"): {"logs"}}
+	assert.Error(t, ValidateProtectionConfig(config), "排除规则必须对应受保护目录")
+}
+
+// eventRecorder 记录防护事件
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []ProtectionEvent
+}
+
+func (r *eventRecorder) record(event ProtectionEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// snapshot 返回已记录的事件
+func (r *eventRecorder) snapshot() []ProtectionEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ProtectionEvent(nil), r.events...)
+}
+
+// targets 返回指定动作的事件目标
+func (r *eventRecorder) targets(action string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var targets []string
+	for _, event := range r.events {
+		if event.Action == action {
+			targets = append(targets, event.Target)
+		}
+	}
+	return targets
+}
+
+func TestFileProtector_ExcludedSubtree(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"agent.yaml":         "level: info",
+		"plugins/dlp.yaml":   "enabled: true",
+		"logs/agent.log":     "started",
+		"logs/old/agent.log": "rotated",
+		"cache.tmp":          "tmp",
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	protector := NewFileProtector(FileProtectionConfig{
+		Enabled:        true,
+		ProtectedDirs:  []string{dir},
+		Exclusions:     map[string][]string{dir: {"logs", "*.tmp"}},
+		CheckIntegrity: true,
+	}, hclog.NewNullLogger()).(*FileProtectorImpl)
+	recorder := &eventRecorder{}
+	protector.SetEventCallback(recorder.record)
+
+	require.NoError(t, protector.Start(context.Background()))
+	defer protector.Stop()
+
+	// 基线只包含未排除的文件
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "agent.yaml"),
+		filepath.Join(dir, "plugins", "dlp.yaml"),
+	}, protector.GetProtectedFiles())
+	assert.True(t, protector.IsPathExcluded(filepath.Join(dir, "logs", "agent.log")))
+	assert.False(t, protector.IsPathExcluded(filepath.Join(dir, "agent.yaml")))
+
+	// 修改、新增排除路径内外的文件
+	write := func(rel, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	write("logs/agent.log", "started\nrequest handled")
+	write("logs/new.log", "new log")
+	write("cache.tmp", "changed")
+	write("plugins/state.tmp", "state")
+	write("plugins/dlp.yaml", "enabled: false")
+	write("plugins/backdoor.so", "payload")
+
+	require.NoError(t, protector.PeriodicCheck())
+
+	// 只有排除路径之外的变更产生事件
+	assert.Equal(t, []string{filepath.Join(dir, "plugins", "dlp.yaml")}, recorder.targets("integrity_violation"))
+	assert.Equal(t, []string{filepath.Join(dir, "plugins", "backdoor.so")}, recorder.targets("file_added"))
+	for _, event := range recorder.snapshot() {
+		assert.False(t, protector.IsPathExcluded(event.Target), "排除路径不应产生事件: %s %s", event.Action, event.Target)
+	}
+
+	// 新增文件加入基线后不再重复报告
+	require.NoError(t, protector.PeriodicCheck())
+	assert.Len(t, recorder.targets("file_added"), 1)
+}
@@ -0,0 +1,193 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProcessMatchMode 受保护进程的匹配方式
+type ProcessMatchMode string
+
+const (
+	ProcessMatchName ProcessMatchMode = "name" // 按进程名匹配，支持通配符
+	ProcessMatchPath ProcessMatchMode = "path" // 按可执行文件完整路径匹配，支持通配符
+	ProcessMatchHash ProcessMatchMode = "hash" // 按可执行文件SHA-256匹配，重命名后仍然有效
+)
+
+// processHashPrefix 按哈希匹配的配置前缀，如 "sha256:9f86d0..."
+const processHashPrefix = "sha256:"
+
+// ProcessCandidate 正在运行的进程
+type ProcessCandidate struct {
+	PID  uint32
+	Name string
+	Path string // 可执行文件完整路径，未解析时为空
+}
+
+// ProcessMatcher 受保护进程的匹配规则
+// 配置项格式：
+//   - "sha256:<64位十六进制>" 按可执行文件哈希匹配
+//   - 包含路径分隔符的配置按完整路径匹配，如 "C:\Program Files\Kennel\agent.exe"
+//   - 其他配置按进程名匹配，如 "agent.exe"、"kennel-*.exe"
+//
+// 路径和进程名均不区分大小写，支持 * ? [] 通配符
+type ProcessMatcher struct {
+	Entry   string           // 原始配置项
+	Mode    ProcessMatchMode // 匹配方式
+	pattern string           // 小写的名称/路径模式或哈希值
+}
+
+// ParseProcessMatcher 解析受保护进程配置项
+func ParseProcessMatcher(entry string) (*ProcessMatcher, error) {
+	value := strings.TrimSpace(entry)
+	if value == "" {
+		return nil, fmt.Errorf("受保护进程配置不能为空")
+	}
+
+	matcher := &ProcessMatcher{Entry: entry}
+	switch {
+	case strings.HasPrefix(strings.ToLower(value), processHashPrefix):
+		hash := strings.ToLower(value[len(processHashPrefix):])
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return nil, fmt.Errorf("无效的SHA-256哈希: %s", entry)
+		}
+		matcher.Mode = ProcessMatchHash
+		matcher.pattern = hash
+
+	case strings.ContainsAny(value, `/\`):
+		matcher.Mode = ProcessMatchPath
+		matcher.pattern = normalizeProcessPath(value)
+
+	default:
+		matcher.Mode = ProcessMatchName
+		matcher.pattern = strings.ToLower(value)
+	}
+
+	if matcher.Mode != ProcessMatchHash {
+		if _, err := filepath.Match(matcher.pattern, ""); err != nil {
+			return nil, fmt.Errorf("无效的匹配模式 %s: %w", entry, err)
+		}
+	}
+	return matcher, nil
+}
+
+// NeedsPath 匹配时是否需要解析进程的可执行文件路径
+func (m *ProcessMatcher) NeedsPath() bool {
+	return m.Mode != ProcessMatchName
+}
+
+// Match 检查进程是否匹配，按哈希匹配时使用 hasher 计算可执行文件哈希
+func (m *ProcessMatcher) Match(process ProcessCandidate, hasher *ExecutableHasher) bool {
+	switch m.Mode {
+	case ProcessMatchName:
+		name := process.Name
+		if name == "" && process.Path != "" {
+			name = filepath.Base(normalizeProcessPath(process.Path))
+		}
+		return matchProcessPattern(m.pattern, strings.ToLower(name))
+
+	case ProcessMatchPath:
+		if process.Path == "" {
+			return false
+		}
+		return matchProcessPattern(m.pattern, normalizeProcessPath(process.Path))
+
+	case ProcessMatchHash:
+		if process.Path == "" || hasher == nil {
+			return false
+		}
+		hash, err := hasher.Hash(process.Path)
+		return err == nil && hash == m.pattern
+
+	default:
+		return false
+	}
+}
+
+// FindProcess 在进程列表中查找第一个匹配的进程
+func (m *ProcessMatcher) FindProcess(processes []ProcessCandidate, hasher *ExecutableHasher) (ProcessCandidate, bool) {
+	for _, process := range processes {
+		if m.Match(process, hasher) {
+			return process, true
+		}
+	}
+	return ProcessCandidate{}, false
+}
+
+// matchProcessPattern 通配符匹配，无通配符时直接比较
+func matchProcessPattern(pattern, value string) bool {
+	if !strings.ContainsAny(pattern, "*?[") {
+		return pattern == value
+	}
+	matched, _ := filepath.Match(pattern, value)
+	return matched
+}
+
+// normalizeProcessPath 统一路径分隔符和大小写
+// 通配符匹配以 / 为分隔符，Windows路径中的 \ 会被当作转义字符
+func normalizeProcessPath(path string) string {
+	return strings.ToLower(strings.ReplaceAll(path, `\`, "/"))
+}
+
+// ExecutableHasher 计算可执行文件的SHA-256，按文件大小和修改时间缓存结果
+type ExecutableHasher struct {
+	mu    sync.Mutex
+	cache map[string]executableHash
+}
+
+// executableHash 缓存的文件哈希
+type executableHash struct {
+	size    int64
+	modTime time.Time
+	hash    string
+}
+
+// NewExecutableHasher 创建可执行文件哈希计算器
+func NewExecutableHasher() *ExecutableHasher {
+	return &ExecutableHasher{
+		cache: make(map[string]executableHash),
+	}
+}
+
+// Hash 返回文件的SHA-256（小写十六进制）
+func (h *ExecutableHasher) Hash(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+
+	h.mu.Lock()
+	cached, exists := h.cache[path]
+	h.mu.Unlock()
+	if exists && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.hash, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	digest := sha256.New()
+	if _, err := io.Copy(digest, file); err != nil {
+		return "", fmt.Errorf("计算文件哈希失败: %w", err)
+	}
+	hash := hex.EncodeToString(digest.Sum(nil))
+
+	h.mu.Lock()
+	h.cache[path] = executableHash{size: info.Size(), modTime: info.ModTime(), hash: hash}
+	h.mu.Unlock()
+
+	return hash, nil
+}
@@ -0,0 +1,118 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestBinary 写入模拟可执行文件并返回路径和SHA-256
+func writeTestBinary(t *testing.T, dir, name, content string) (string, string) {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0755))
+	sum := sha256.Sum256([]byte(content))
+	return path, hex.EncodeToString(sum[:])
+}
+
+func mustParseMatcher(t *testing.T, entry string) *ProcessMatcher {
+	matcher, err := ParseProcessMatcher(entry)
+	require.NoError(t, err)
+	return matcher
+}
+
+func TestProcessMatcher_NameMatch(t *testing.T) {
+	matcher := mustParseMatcher(t, "agent.exe")
+	assert.Equal(t, ProcessMatchName, matcher.Mode)
+	assert.False(t, matcher.NeedsPath())
+
+	assert.True(t, matcher.Match(ProcessCandidate{PID: 1, Name: "Agent.EXE"}, nil))
+	assert.False(t, matcher.Match(ProcessCandidate{PID: 2, Name: "agent2.exe"}, nil))
+
+	// 通配符
+	glob := mustParseMatcher(t, "kennel-*.exe")
+	assert.True(t, glob.Match(ProcessCandidate{Name: "kennel-dlp.exe"}, nil))
+	assert.False(t, glob.Match(ProcessCandidate{Name: "kennel.exe"}, nil))
+
+	process, found := glob.FindProcess([]ProcessCandidate{
+		{PID: 10, Name: "explorer.exe"},
+		{PID: 11, Name: "KENNEL-audit.exe"},
+	}, nil)
+	assert.True(t, found)
+	assert.Equal(t, uint32(11), process.PID)
+}
+
+func TestProcessMatcher_PathMatch(t *testing.T) {
+	matcher := mustParseMatcher(t, `C:\Program Files\Kennel\agent.exe`)
+	assert.Equal(t, ProcessMatchPath, matcher.Mode)
+	assert.True(t, matcher.NeedsPath())
+
+	assert.True(t, matcher.Match(ProcessCandidate{Name: "agent.exe", Path: `c:\program files\kennel\AGENT.exe`}, nil))
+	// 同名但位于其他目录的进程不匹配
+	assert.False(t, matcher.Match(ProcessCandidate{Name: "agent.exe", Path: `C:\Users\Public\agent.exe`}, nil))
+	// 未解析路径时不匹配
+	assert.False(t, matcher.Match(ProcessCandidate{Name: "agent.exe"}, nil))
+
+	glob := mustParseMatcher(t, `C:\Program Files\Kennel\*.exe`)
+	assert.True(t, glob.Match(ProcessCandidate{Path: `C:\Program Files\Kennel\dlp.exe`}, nil))
+	assert.False(t, glob.Match(ProcessCandidate{Path: `C:\Program Files\Kennel\plugins\dlp.exe`}, nil))
+
+	unix := mustParseMatcher(t, "/opt/kennel/bin/agent")
+	assert.True(t, unix.Match(ProcessCandidate{Path: "/opt/kennel/bin/agent"}, nil))
+}
+
+func TestProcessMatcher_HashMatch(t *testing.T) {
+	dir := t.TempDir()
+	agentPath, agentHash := writeTestBinary(t, dir, "agent.exe", "kennel agent binary")
+	otherPath, _ := writeTestBinary(t, dir, "other.exe", "some other binary")
+
+	matcher := mustParseMatcher(t, "SHA256:"+agentHash)
+	assert.Equal(t, ProcessMatchHash, matcher.Mode)
+	assert.True(t, matcher.NeedsPath())
+
+	hasher := NewExecutableHasher()
+	assert.True(t, matcher.Match(ProcessCandidate{PID: 1, Name: "agent.exe", Path: agentPath}, hasher))
+	assert.False(t, matcher.Match(ProcessCandidate{PID: 2, Name: "agent.exe", Path: otherPath}, hasher))
+	assert.False(t, matcher.Match(ProcessCandidate{PID: 3, Name: "agent.exe", Path: filepath.Join(dir, "missing.exe")}, hasher))
+	assert.False(t, matcher.Match(ProcessCandidate{PID: 1, Name: "agent.exe", Path: agentPath}, nil))
+
+	// 文件内容变化后重新计算哈希
+	require.NoError(t, os.WriteFile(agentPath, []byte("tampered agent binary"), 0755))
+	assert.False(t, matcher.Match(ProcessCandidate{PID: 1, Name: "agent.exe", Path: agentPath}, hasher))
+}
+
+func TestProcessMatcher_RenamedBinaryStillProtected(t *testing.T) {
+	dir := t.TempDir()
+	originalPath, hash := writeTestBinary(t, dir, "agent.exe", "kennel agent binary")
+	renamedPath := filepath.Join(dir, "svchost32.exe")
+	require.NoError(t, os.Rename(originalPath, renamedPath))
+
+	processes := []ProcessCandidate{
+		{PID: 100, Name: "explorer.exe", Path: filepath.Join(dir, "explorer.exe")},
+		{PID: 200, Name: "svchost32.exe", Path: renamedPath},
+	}
+
+	// 按名称匹配无法识别重命名的程序
+	_, found := mustParseMatcher(t, "agent.exe").FindProcess(processes, nil)
+	assert.False(t, found)
+
+	// 按哈希匹配仍能找到
+	process, found := mustParseMatcher(t, "sha256:"+hash).FindProcess(processes, NewExecutableHasher())
+	assert.True(t, found)
+	assert.Equal(t, uint32(200), process.PID)
+	assert.Equal(t, renamedPath, process.Path)
+}
+
+func TestParseProcessMatcher_Invalid(t *testing.T) {
+	for _, entry := range []string{"", "  ", "sha256:xyz", "sha256:abcd", "agent[.exe"} {
+		_, err := ParseProcessMatcher(entry)
+		assert.Error(t, err, entry)
+	}
+}
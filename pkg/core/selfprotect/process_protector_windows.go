@@ -6,7 +6,6 @@ package selfprotect
 import (
 	"context"
 	"fmt"
-	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -102,6 +101,7 @@ type WindowsProcessProtector struct {
 	enabled            bool
 	protectedProcesses map[string]*ProtectedProcess
 	eventCallback      EventCallback
+	hasher             *ExecutableHasher
 
 	// 监控状态
 	monitoring         bool
@@ -133,6 +133,7 @@ func NewProcessProtector(config ProcessProtectionConfig, logger hclog.Logger) Pr
 		cancel:             cancel,
 		enabled:            config.Enabled,
 		protectedProcesses: make(map[string]*ProtectedProcess),
+		hasher:             NewExecutableHasher(),
 		checkInterval:      5 * time.Second,
 		restartAttempts:    make(map[string]int),
 		maxRestartAttempts: 3,
@@ -227,12 +228,13 @@ func (pp *WindowsProcessProtector) SetEventCallback(callback EventCallback) {
 }
 
 // ProtectProcess 保护进程
+// processName 为受保护进程配置项，支持进程名、完整路径、通配符和 sha256:<哈希>
 func (pp *WindowsProcessProtector) ProtectProcess(processName string) error {
 	pp.mu.Lock()
 	defer pp.mu.Unlock()
 
 	// 查找进程
-	processID, processPath, err := pp.findProcessByName(processName)
+	processID, processPath, err := pp.findProcess(processName)
 	if err != nil {
 		return fmt.Errorf("查找进程失败: %w", err)
 	}
@@ -570,11 +572,42 @@ func (pp *WindowsProcessProtector) setProcessProtection(handle windows.Handle) e
 	return nil
 }
 
-// findProcessByName 根据名称查找进程
-func (pp *WindowsProcessProtector) findProcessByName(processName string) (uint32, string, error) {
+// findProcess 根据受保护进程配置项查找进程
+// 按路径或哈希匹配时解析每个进程的可执行文件路径，哈希按文件缓存
+func (pp *WindowsProcessProtector) findProcess(entry string) (uint32, string, error) {
+	matcher, err := ParseProcessMatcher(entry)
+	if err != nil {
+		return 0, "", err
+	}
+
+	processes, err := pp.listProcesses()
+	if err != nil {
+		return 0, "", err
+	}
+
+	if matcher.NeedsPath() {
+		for i := range processes {
+			processes[i].Path, _ = pp.getProcessPath(processes[i].PID)
+		}
+	}
+
+	process, found := matcher.FindProcess(processes, pp.hasher)
+	if !found {
+		return 0, "", nil
+	}
+
+	// 获取进程完整路径
+	if process.Path == "" {
+		process.Path, _ = pp.getProcessPath(process.PID)
+	}
+	return process.PID, process.Path, nil
+}
+
+// listProcesses 列出正在运行的进程
+func (pp *WindowsProcessProtector) listProcesses() ([]ProcessCandidate, error) {
 	snapshot, _, err := procCreateToolhelp32Snapshot.Call(TH32CS_SNAPPROCESS, 0)
 	if snapshot == uintptr(syscall.InvalidHandle) {
-		return 0, "", fmt.Errorf("创建进程快照失败: %v", err)
+		return nil, fmt.Errorf("创建进程快照失败: %v", err)
 	}
 	defer procCloseHandle.Call(snapshot)
 
@@ -583,16 +616,15 @@ func (pp *WindowsProcessProtector) findProcessByName(processName string) (uint32
 
 	ret, _, _ := procProcess32First.Call(snapshot, uintptr(unsafe.Pointer(&pe32)))
 	if ret == 0 {
-		return 0, "", fmt.Errorf("获取第一个进程失败")
+		return nil, fmt.Errorf("获取第一个进程失败")
 	}
 
+	var processes []ProcessCandidate
 	for {
-		exeFile := syscall.UTF16ToString(pe32.ExeFile[:])
-		if strings.EqualFold(exeFile, processName) {
-			// 获取进程完整路径
-			processPath, _ := pp.getProcessPath(pe32.ProcessID)
-			return pe32.ProcessID, processPath, nil
-		}
+		processes = append(processes, ProcessCandidate{
+			PID:  pe32.ProcessID,
+			Name: syscall.UTF16ToString(pe32.ExeFile[:]),
+		})
 
 		ret, _, _ := procProcess32Next.Call(snapshot, uintptr(unsafe.Pointer(&pe32)))
 		if ret == 0 {
@@ -600,7 +632,7 @@ func (pp *WindowsProcessProtector) findProcessByName(processName string) (uint32
 		}
 	}
 
-	return 0, "", nil
+	return processes, nil
 }
 
 // getProcessPath 获取进程完整路径
@@ -626,7 +658,7 @@ func (pp *WindowsProcessProtector) getProcessPath(processID uint32) (string, err
 func (pp *WindowsProcessProtector) checkProcessStatus(process *ProtectedProcess) error {
 	if process.ProcessID == 0 {
 		// 进程未运行，尝试查找
-		processID, _, err := pp.findProcessByName(process.Name)
+		processID, _, err := pp.findProcess(process.Name)
 		if err != nil {
 			return err
 		}
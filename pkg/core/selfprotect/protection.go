@@ -34,8 +34,21 @@ type ProtectionManager struct {
 	events        []ProtectionEvent
 	maxEvents     int
 
+	// 状态变化回调
+	statusCallbacks []StatusChangeCallback
+
 	// 统计
 	stats ProtectionStats
+
+	// 自检
+	selfTestMu   sync.Mutex
+	lastSelfTest *SelfTestResult
+
+	// 定期检查调度时钟
+	clock protectionClock
+
+	// 是否已设置修改者查询函数，未设置时启动时使用平台的查询函数
+	modifierResolverSet bool
 }
 
 // DefaultProtectionConfig 默认防护配置
@@ -97,6 +110,11 @@ func DefaultProtectionConfig() *ProtectionConfig {
 			AutoRestart:    true,
 			PreventDisable: true,
 		},
+		SelfTest: SelfTestConfig{
+			Enabled:  false,
+			Interval: time.Hour,
+			Timeout:  10 * time.Second,
+		},
 	}
 }
 
@@ -115,6 +133,7 @@ func NewProtectionManager(config *ProtectionConfig, logger hclog.Logger) *Protec
 		cancel:    cancel,
 		enabled:   config.Enabled,
 		maxEvents: 10000,
+		clock:     systemClock{},
 		stats: ProtectionStats{
 			StartTime:         time.Now(),
 			ConfigHealthScore: 100.0, // 初始健康分数
@@ -146,7 +165,8 @@ func (pm *ProtectionManager) initProtectors() {
 
 	// 初始化服务防护器
 	if pm.config.ServiceProtection.Enabled {
-		pm.serviceProtector = NewServiceProtector(pm.config.ServiceProtection, pm.logger)
+		serviceConfig := pm.config.ServiceProtection.withRecoveryDefaults(pm.config.RestartDelay, pm.config.MaxRestartAttempts)
+		pm.serviceProtector = NewServiceProtector(serviceConfig, pm.logger)
 	}
 }
 
@@ -160,12 +180,17 @@ func (pm *ProtectionManager) Start() error {
 	// 检查紧急禁用文件
 	if pm.checkEmergencyDisable() {
 		pm.logger.Warn("检测到紧急禁用文件，自我防护已禁用")
-		pm.emergencyMode = true
+		pm.updateStatus(StatusChangeReasonEmergencyFile, pm.config.EmergencyDisable, func() {
+			pm.emergencyMode = true
+		})
 		return nil
 	}
 
 	pm.logger.Info("启动自我防护", "level", pm.config.Level)
 
+	// 注册平台的修改者查询函数，使 allowed_modifiers 生效
+	pm.registerPlatformModifierResolver()
+
 	// 启动各个防护组件
 	if pm.processProtector != nil {
 		pm.wg.Add(1)
@@ -188,8 +213,18 @@ func (pm *ProtectionManager) Start() error {
 	}
 
 	// 启动主监控循环
+	mainTicker := pm.clock.NewTicker(pm.config.CheckInterval)
 	pm.wg.Add(1)
-	go pm.runMainLoop()
+	go pm.runMainLoop(mainTicker)
+
+	// 按各防护器的检查间隔启动定期检查
+	pm.startPeriodicChecks()
+
+	// 启动定期自检
+	if pm.config.SelfTest.Enabled {
+		pm.wg.Add(1)
+		go pm.runSelfTestLoop()
+	}
 
 	return nil
 }
@@ -208,6 +243,54 @@ func (pm *ProtectionManager) IsEnabled() bool {
 	return pm.enabled && !pm.emergencyMode
 }
 
+// OnStatusChange 注册防护状态变化回调，防护在启用和禁用之间切换时调用
+// 回调在触发变化的协程中同步执行，不应长时间阻塞
+func (pm *ProtectionManager) OnStatusChange(callback StatusChangeCallback) {
+	if callback == nil {
+		return
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.statusCallbacks = append(pm.statusCallbacks, callback)
+}
+
+// SetEnabled 切换防护是否生效，例如重新加载配置或防护出错时调用
+// 只影响已启动的防护，启动时已禁用的防护需要重新启动才能生效
+func (pm *ProtectionManager) SetEnabled(enabled bool, reason StatusChangeReason, message string) {
+	pm.updateStatus(reason, message, func() {
+		pm.enabled = enabled
+	})
+}
+
+// updateStatus 在持有锁的情况下修改状态，防护是否生效发生变化时通知回调
+func (pm *ProtectionManager) updateStatus(reason StatusChangeReason, message string, update func()) {
+	pm.mu.Lock()
+	previous := pm.enabled && !pm.emergencyMode
+	update()
+	current := pm.enabled && !pm.emergencyMode
+	callbacks := make([]StatusChangeCallback, len(pm.statusCallbacks))
+	copy(callbacks, pm.statusCallbacks)
+	pm.mu.Unlock()
+
+	if previous == current {
+		return
+	}
+
+	change := StatusChange{
+		Enabled:   current,
+		Previous:  previous,
+		Reason:    reason,
+		Message:   message,
+		Timestamp: time.Now(),
+	}
+	pm.logger.Info("防护状态变化", "enabled", current, "reason", reason, "message", message)
+
+	for _, callback := range callbacks {
+		callback(change)
+	}
+}
+
 // GetStats 获取防护统计
 func (pm *ProtectionManager) GetStats() ProtectionStats {
 	pm.mu.RLock()
@@ -225,6 +308,42 @@ func (pm *ProtectionManager) GetEvents() []ProtectionEvent {
 	return events
 }
 
+// SetModifierResolver 设置查询受保护资源修改者的函数，转发给文件和注册表防护器
+// 修改者在对应防护器的 AllowedModifiers 中时，变更作为新的基线，不触发恢复
+func (pm *ProtectionManager) SetModifierResolver(resolver ModifierResolver) {
+	pm.mu.Lock()
+	pm.modifierResolverSet = true
+	pm.mu.Unlock()
+
+	for _, protector := range []interface{}{pm.fileProtector, pm.registryProtector} {
+		if aware, ok := protector.(interface{ SetModifierResolver(ModifierResolver) }); ok {
+			aware.SetModifierResolver(resolver)
+		}
+	}
+}
+
+// registerPlatformModifierResolver 未设置修改者查询函数且配置了 allowed_modifiers 时注册平台的查询函数
+func (pm *ProtectionManager) registerPlatformModifierResolver() {
+	if len(pm.config.FileProtection.AllowedModifiers) == 0 && len(pm.config.RegistryProtection.AllowedModifiers) == 0 {
+		return
+	}
+
+	pm.mu.RLock()
+	set := pm.modifierResolverSet
+	pm.mu.RUnlock()
+	if set {
+		return
+	}
+
+	resolver := platformModifierResolver()
+	if resolver == nil {
+		pm.logger.Warn("当前平台无法确定受保护资源的修改者，allowed_modifiers 不生效")
+		return
+	}
+	pm.SetModifierResolver(resolver)
+	pm.logger.Info("已注册修改者审计查询")
+}
+
 // checkEmergencyDisable 检查紧急禁用
 func (pm *ProtectionManager) checkEmergencyDisable() bool {
 	if pm.config.EmergencyDisable == "" {
@@ -279,57 +398,27 @@ func (pm *ProtectionManager) recordEvent(event ProtectionEvent) {
 	)
 }
 
-// runMainLoop 运行主监控循环
-func (pm *ProtectionManager) runMainLoop() {
+// runMainLoop 运行主监控循环，按全局检查间隔检测紧急禁用文件
+func (pm *ProtectionManager) runMainLoop(ticker protectionTicker) {
 	defer pm.wg.Done()
-
-	ticker := time.NewTicker(pm.config.CheckInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-pm.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			// 检查紧急禁用
-			if pm.checkEmergencyDisable() && !pm.emergencyMode {
+			if pm.checkEmergencyDisable() && !pm.isEmergencyMode() {
 				pm.logger.Warn("检测到紧急禁用文件，进入紧急模式")
-				pm.mu.Lock()
-				pm.emergencyMode = true
-				pm.mu.Unlock()
+				pm.updateStatus(StatusChangeReasonEmergencyFile, pm.config.EmergencyDisable, func() {
+					pm.emergencyMode = true
+				})
 			}
-
-			// 如果在紧急模式，跳过防护检查
-			if pm.emergencyMode {
-				continue
-			}
-
-			// 执行定期检查
-			pm.performPeriodicChecks()
 		}
 	}
 }
 
-// performPeriodicChecks 执行定期检查
-func (pm *ProtectionManager) performPeriodicChecks() {
-	// 检查各个防护组件的状态
-	if pm.processProtector != nil {
-		pm.processProtector.PeriodicCheck()
-	}
-
-	if pm.fileProtector != nil {
-		pm.fileProtector.PeriodicCheck()
-	}
-
-	if pm.registryProtector != nil {
-		pm.registryProtector.PeriodicCheck()
-	}
-
-	if pm.serviceProtector != nil {
-		pm.serviceProtector.PeriodicCheck()
-	}
-}
-
 // runProcessProtection 运行进程防护
 func (pm *ProtectionManager) runProcessProtection() {
 	defer pm.wg.Done()
@@ -30,6 +30,9 @@ type WindowsRegistryProtector struct {
 	// 监控状态
 	monitoring    bool
 	checkInterval time.Duration
+
+	// 允许修改受保护注册表键的进程
+	modifiers *modifierGuard
 }
 
 // ProtectedRegistryKey 受保护的注册表键信息
@@ -63,7 +66,7 @@ type RegistryValue struct {
 func NewRegistryProtector(config RegistryProtectionConfig, logger hclog.Logger) RegistryProtector {
 	ctx, cancel := context.WithCancel(context.Background())
 	
-	return &WindowsRegistryProtector{
+	rp := &WindowsRegistryProtector{
 		config:        config,
 		logger:        logger.Named("registry-protector"),
 		ctx:           ctx,
@@ -72,6 +75,14 @@ func NewRegistryProtector(config RegistryProtectionConfig, logger hclog.Logger)
 		protectedKeys: make(map[string]*ProtectedRegistryKey),
 		checkInterval: 10 * time.Second,
 	}
+
+	modifiers, err := newModifierGuard(config.AllowedModifiers)
+	if err != nil {
+		rp.logger.Warn("忽略无效的允许修改进程配置", "error", err)
+	}
+	rp.modifiers = modifiers
+
+	return rp
 }
 
 // Start 启动注册表防护
@@ -143,6 +154,11 @@ func (rp *WindowsRegistryProtector) SetEventCallback(callback EventCallback) {
 	rp.eventCallback = callback
 }
 
+// SetModifierResolver 设置查询注册表键修改者的函数，未设置时所有变更都按未授权处理
+func (rp *WindowsRegistryProtector) SetModifierResolver(resolver ModifierResolver) {
+	rp.modifiers.setResolver(resolver)
+}
+
 // ProtectRegistryKey 保护注册表键
 func (rp *WindowsRegistryProtector) ProtectRegistryKey(keyPath string) error {
 	rp.mu.Lock()
@@ -451,6 +467,10 @@ func (rp *WindowsRegistryProtector) checkRegistryKeyStatus(protectedKey *Protect
 	key, err := registry.OpenKey(protectedKey.Root, protectedKey.SubKey, registry.READ)
 	if err != nil {
 		if err == registry.ErrNotExist {
+			// 允许的进程删除的键保留原基线，重新创建后再次更新
+			if rp.acceptAllowedChange(protectedKey, nil) {
+				return nil
+			}
 			rp.logger.Warn("受保护的注册表键不存在", "key", protectedKey.Path)
 			
 			// 记录事件
@@ -479,6 +499,9 @@ func (rp *WindowsRegistryProtector) checkRegistryKeyStatus(protectedKey *Protect
 	// 比较值是否发生变化
 	changed := rp.compareRegistryValues(protectedKey.Values, currentValues)
 	if len(changed) > 0 {
+		if rp.acceptAllowedChange(protectedKey, currentValues) {
+			return nil
+		}
 		rp.logger.Warn("检测到注册表键值变更", "key", protectedKey.Path, "changed", len(changed))
 		
 		// 记录事件
@@ -504,6 +527,32 @@ func (rp *WindowsRegistryProtector) checkRegistryKeyStatus(protectedKey *Protect
 	return nil
 }
 
+// acceptAllowedChange 变更来自允许的进程时，以当前值作为新的基线和备份并记录审计事件
+func (rp *WindowsRegistryProtector) acceptAllowedChange(protectedKey *ProtectedRegistryKey, currentValues map[string]RegistryValue) bool {
+	process, entry, allowed := rp.modifiers.allowed(protectedKey.Path)
+	if !allowed {
+		return false
+	}
+
+	rp.logger.Info("受保护注册表键由允许的进程修改", "key", protectedKey.Path, "process", process.Path, "pid", process.PID)
+	if currentValues != nil {
+		rp.mu.Lock()
+		protectedKey.Values = currentValues
+		protectedKey.Backup = RegistryBackup{
+			Path:      protectedKey.Path,
+			Values:    currentValues,
+			Timestamp: time.Now(),
+		}
+		protectedKey.LastCheck = time.Now()
+		rp.mu.Unlock()
+	}
+
+	if rp.eventCallback != nil {
+		rp.eventCallback(allowedChangeEvent(ProtectionTypeRegistry, protectedKey.Path, process, entry))
+	}
+	return true
+}
+
 // compareRegistryValues 比较注册表值
 func (rp *WindowsRegistryProtector) compareRegistryValues(original, current map[string]RegistryValue) []string {
 	var changed []string
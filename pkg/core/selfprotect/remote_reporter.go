@@ -0,0 +1,285 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// 远程上报使用的数据和事件类型
+const (
+	RemoteStatusDataType        = "selfprotect_status"
+	RemoteEventType             = "selfprotect_event"
+	RemoteStatusChangeEventType = "selfprotect_status_change"
+)
+
+// StatusSender 防护状态发送接口，comm.Manager 实现了该接口
+type StatusSender interface {
+	IsConnected() bool
+	SendData(dataType string, data interface{})
+	SendEvent(eventType string, details map[string]interface{})
+}
+
+// StatusSource 防护状态来源接口，ProtectionService 实现了该接口
+type StatusSource interface {
+	GetStatus() ProtectionStatus
+	GetEvents() []ProtectionEvent
+}
+
+// RemoteReporterConfig 远程上报配置
+type RemoteReporterConfig struct {
+	// Interval 状态上报间隔，同时也是事件批量上报的节流间隔
+	Interval time.Duration `yaml:"interval" json:"interval"`
+	// MaxBufferedEvents 离线时最多缓存的事件数，超出后丢弃最旧的事件
+	MaxBufferedEvents int `yaml:"max_buffered_events" json:"max_buffered_events"`
+	// MaxEventsPerFlush 每次上报最多发送的事件数，剩余事件留到下一个周期
+	MaxEventsPerFlush int `yaml:"max_events_per_flush" json:"max_events_per_flush"`
+}
+
+// DefaultRemoteReporterConfig 默认远程上报配置
+func DefaultRemoteReporterConfig() RemoteReporterConfig {
+	return RemoteReporterConfig{
+		Interval:          30 * time.Second,
+		MaxBufferedEvents: 1000,
+		MaxEventsPerFlush: 100,
+	}
+}
+
+// RemoteReporterStats 远程上报统计
+type RemoteReporterStats struct {
+	StatusSent     int64     `json:"status_sent"`
+	EventsSent     int64     `json:"events_sent"`
+	EventsDropped  int64     `json:"events_dropped"`
+	BufferedEvents int       `json:"buffered_events"`
+	LastReport     time.Time `json:"last_report"`
+}
+
+// RemoteReporter 将防护状态和事件推送到服务端
+// 按固定间隔节流上报，通讯断开时缓存事件，恢复连接后补发
+type RemoteReporter struct {
+	source StatusSource
+	sender StatusSender
+	config RemoteReporterConfig
+	logger hclog.Logger
+
+	mu         sync.Mutex
+	pending    []ProtectionEvent
+	changes    []StatusChange
+	watermark  time.Time
+	seenAtMark map[string]struct{}
+	stats      RemoteReporterStats
+
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	running bool
+}
+
+// NewRemoteReporter 创建远程上报器
+func NewRemoteReporter(source StatusSource, sender StatusSender, config RemoteReporterConfig, logger hclog.Logger) *RemoteReporter {
+	defaults := DefaultRemoteReporterConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.MaxBufferedEvents <= 0 {
+		config.MaxBufferedEvents = defaults.MaxBufferedEvents
+	}
+	if config.MaxEventsPerFlush <= 0 {
+		config.MaxEventsPerFlush = defaults.MaxEventsPerFlush
+	}
+
+	return &RemoteReporter{
+		source:     source,
+		sender:     sender,
+		config:     config,
+		logger:     logger.Named("protection-remote-reporter"),
+		pending:    make([]ProtectionEvent, 0),
+		seenAtMark: make(map[string]struct{}),
+	}
+}
+
+// Start 启动周期性上报
+func (rr *RemoteReporter) Start() {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if rr.running {
+		return
+	}
+
+	rr.running = true
+	rr.stopCh = make(chan struct{})
+	rr.wg.Add(1)
+	go rr.reportLoop(rr.stopCh)
+
+	rr.logger.Info("启动防护状态远程上报", "interval", rr.config.Interval)
+}
+
+// Stop 停止周期性上报
+func (rr *RemoteReporter) Stop() {
+	rr.mu.Lock()
+	if !rr.running {
+		rr.mu.Unlock()
+		return
+	}
+	rr.running = false
+	close(rr.stopCh)
+	rr.mu.Unlock()
+
+	rr.wg.Wait()
+	rr.logger.Info("防护状态远程上报已停止")
+}
+
+// Report 立即执行一次上报：收集新事件，在线时发送缓存的事件和当前状态
+func (rr *RemoteReporter) Report() {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.collectEvents()
+
+	if !rr.sender.IsConnected() {
+		rr.logger.Debug("通讯未连接，缓存防护事件", "buffered", len(rr.pending))
+		return
+	}
+
+	// 状态变化优先于普通防护事件发送
+	rr.flushStatusChanges()
+
+	count := len(rr.pending)
+	if count > rr.config.MaxEventsPerFlush {
+		count = rr.config.MaxEventsPerFlush
+	}
+	for _, event := range rr.pending[:count] {
+		rr.sender.SendEvent(RemoteEventType, eventDetails(event))
+	}
+	rr.pending = rr.pending[count:]
+	rr.stats.EventsSent += int64(count)
+
+	rr.sender.SendData(RemoteStatusDataType, rr.source.GetStatus())
+	rr.stats.StatusSent++
+	rr.stats.LastReport = time.Now()
+}
+
+// NotifyStatusChange 推送防护状态变化，可注册为 ProtectionService 的状态变化回调
+// 在线时立即发送状态变化事件和当前状态，离线时缓存，恢复连接后在下一次上报时补发
+func (rr *RemoteReporter) NotifyStatusChange(change StatusChange) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	rr.changes = append(rr.changes, change)
+	if overflow := len(rr.changes) - rr.config.MaxBufferedEvents; overflow > 0 {
+		rr.changes = rr.changes[overflow:]
+		rr.stats.EventsDropped += int64(overflow)
+	}
+
+	if !rr.sender.IsConnected() {
+		rr.logger.Debug("通讯未连接，缓存防护状态变化", "enabled", change.Enabled, "reason", change.Reason)
+		return
+	}
+
+	rr.flushStatusChanges()
+	rr.sender.SendData(RemoteStatusDataType, rr.source.GetStatus())
+	rr.stats.StatusSent++
+}
+
+// flushStatusChanges 发送缓存的状态变化，调用方需持有锁
+func (rr *RemoteReporter) flushStatusChanges() {
+	for _, change := range rr.changes {
+		rr.sender.SendEvent(RemoteStatusChangeEventType, statusChangeDetails(change))
+	}
+	rr.stats.EventsSent += int64(len(rr.changes))
+	rr.changes = rr.changes[:0]
+}
+
+// GetStats 获取上报统计
+func (rr *RemoteReporter) GetStats() RemoteReporterStats {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	stats := rr.stats
+	stats.BufferedEvents = len(rr.pending) + len(rr.changes)
+	return stats
+}
+
+// reportLoop 上报循环
+func (rr *RemoteReporter) reportLoop(stopCh chan struct{}) {
+	defer rr.wg.Done()
+
+	ticker := time.NewTicker(rr.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			rr.Report()
+		}
+	}
+}
+
+// collectEvents 将上次上报之后产生的事件加入缓存，调用方需持有锁
+func (rr *RemoteReporter) collectEvents() {
+	newest := rr.watermark
+	seen := rr.seenAtMark
+
+	for _, event := range rr.source.GetEvents() {
+		if event.Timestamp.Before(rr.watermark) {
+			continue
+		}
+		if event.Timestamp.Equal(rr.watermark) {
+			if _, exists := rr.seenAtMark[event.ID]; exists {
+				continue
+			}
+		}
+
+		rr.pending = append(rr.pending, event)
+
+		if event.Timestamp.After(newest) {
+			newest = event.Timestamp
+			seen = make(map[string]struct{})
+		}
+		if event.Timestamp.Equal(newest) {
+			seen[event.ID] = struct{}{}
+		}
+	}
+
+	rr.watermark = newest
+	rr.seenAtMark = seen
+
+	// 超出缓存上限时丢弃最旧的事件
+	if overflow := len(rr.pending) - rr.config.MaxBufferedEvents; overflow > 0 {
+		rr.pending = rr.pending[overflow:]
+		rr.stats.EventsDropped += int64(overflow)
+		rr.logger.Warn("防护事件缓存已满，丢弃最旧的事件", "dropped", overflow)
+	}
+}
+
+// eventDetails 将防护事件转换为事件消息内容
+func eventDetails(event ProtectionEvent) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          event.ID,
+		"type":        string(event.Type),
+		"action":      event.Action,
+		"target":      event.Target,
+		"source":      event.Source,
+		"timestamp":   event.Timestamp,
+		"blocked":     event.Blocked,
+		"description": event.Description,
+		"details":     event.Details,
+	}
+}
+
+// statusChangeDetails 将防护状态变化转换为事件消息内容
+func statusChangeDetails(change StatusChange) map[string]interface{} {
+	return map[string]interface{}{
+		"enabled":   change.Enabled,
+		"previous":  change.Previous,
+		"reason":    string(change.Reason),
+		"message":   change.Message,
+		"timestamp": change.Timestamp,
+	}
+}
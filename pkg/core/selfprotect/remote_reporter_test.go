@@ -0,0 +1,175 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/lomehong/kennel/pkg/comm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// comm.Manager 必须满足 StatusSender 接口
+var _ StatusSender = (*comm.Manager)(nil)
+
+// fakeSender 模拟通讯管理器
+type fakeSender struct {
+	mu        sync.Mutex
+	connected bool
+	data      []interface{}
+	events    []map[string]interface{}
+}
+
+func (f *fakeSender) IsConnected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connected
+}
+
+func (f *fakeSender) SetConnected(connected bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = connected
+}
+
+func (f *fakeSender) SendData(dataType string, data interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data = append(f.data, data)
+}
+
+func (f *fakeSender) SendEvent(eventType string, details map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, details)
+}
+
+func (f *fakeSender) counts() (int, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.data), len(f.events)
+}
+
+// fakeSource 模拟防护服务
+type fakeSource struct {
+	mu     sync.Mutex
+	events []ProtectionEvent
+}
+
+func (f *fakeSource) GetStatus() ProtectionStatus {
+	return ProtectionStatus{Enabled: true, Level: string(ProtectionLevelBasic)}
+}
+
+func (f *fakeSource) GetEvents() []ProtectionEvent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	events := make([]ProtectionEvent, len(f.events))
+	copy(events, f.events)
+	return events
+}
+
+func (f *fakeSource) addEvent(id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, ProtectionEvent{
+		ID:        id,
+		Type:      ProtectionTypeFile,
+		Action:    "modify",
+		Target:    "agent.exe",
+		Timestamp: time.Now(),
+		Blocked:   true,
+	})
+}
+
+func TestRemoteReporter_SendsAtInterval(t *testing.T) {
+	sender := &fakeSender{connected: true}
+	source := &fakeSource{}
+	source.addEvent("e1")
+
+	config := RemoteReporterConfig{Interval: 50 * time.Millisecond}
+	reporter := NewRemoteReporter(source, sender, config, hclog.NewNullLogger())
+	reporter.Start()
+	defer reporter.Stop()
+
+	// 在第一个间隔之前不应发送任何内容
+	time.Sleep(20 * time.Millisecond)
+	statusCount, eventCount := sender.counts()
+	assert.Equal(t, 0, statusCount)
+	assert.Equal(t, 0, eventCount)
+
+	time.Sleep(200 * time.Millisecond)
+	statusCount, eventCount = sender.counts()
+	assert.GreaterOrEqual(t, statusCount, 2)
+	assert.LessOrEqual(t, statusCount, 5)
+	assert.Equal(t, 1, eventCount, "同一事件只应上报一次")
+}
+
+func TestRemoteReporter_BuffersWhileDisconnected(t *testing.T) {
+	sender := &fakeSender{connected: false}
+	source := &fakeSource{}
+	reporter := NewRemoteReporter(source, sender, RemoteReporterConfig{
+		Interval:          time.Hour,
+		MaxBufferedEvents: 3,
+	}, hclog.NewNullLogger())
+
+	for i := 0; i < 2; i++ {
+		source.addEvent(fmt.Sprintf("e%d", i))
+	}
+	reporter.Report()
+
+	for i := 2; i < 5; i++ {
+		source.addEvent(fmt.Sprintf("e%d", i))
+	}
+	reporter.Report()
+
+	statusCount, eventCount := sender.counts()
+	assert.Equal(t, 0, statusCount)
+	assert.Equal(t, 0, eventCount)
+
+	stats := reporter.GetStats()
+	assert.Equal(t, 3, stats.BufferedEvents)
+	assert.Equal(t, int64(2), stats.EventsDropped)
+
+	// 恢复连接后补发缓存的事件和当前状态
+	sender.SetConnected(true)
+	reporter.Report()
+
+	statusCount, eventCount = sender.counts()
+	assert.Equal(t, 1, statusCount)
+	require.Equal(t, 3, eventCount)
+	assert.Equal(t, "e2", sender.events[0]["id"])
+	assert.Equal(t, "e4", sender.events[2]["id"])
+
+	stats = reporter.GetStats()
+	assert.Equal(t, 0, stats.BufferedEvents)
+	assert.Equal(t, int64(3), stats.EventsSent)
+}
+
+func TestRemoteReporter_ThrottlesEventsPerFlush(t *testing.T) {
+	sender := &fakeSender{connected: true}
+	source := &fakeSource{}
+	reporter := NewRemoteReporter(source, sender, RemoteReporterConfig{
+		Interval:          time.Hour,
+		MaxEventsPerFlush: 2,
+	}, hclog.NewNullLogger())
+
+	for i := 0; i < 5; i++ {
+		source.addEvent(fmt.Sprintf("e%d", i))
+	}
+
+	reporter.Report()
+	_, eventCount := sender.counts()
+	assert.Equal(t, 2, eventCount)
+	assert.Equal(t, 3, reporter.GetStats().BufferedEvents)
+
+	reporter.Report()
+	reporter.Report()
+	_, eventCount = sender.counts()
+	assert.Equal(t, 5, eventCount)
+}
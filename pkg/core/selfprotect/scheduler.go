@@ -0,0 +1,109 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import "time"
+
+// protectionTicker 定时器
+type protectionTicker interface {
+	// C 返回定时触发的通道
+	C() <-chan time.Time
+
+	// Stop 停止定时器
+	Stop()
+}
+
+// protectionClock 防护管理器调度定期检查使用的时钟，测试中可以替换为可控时钟
+type protectionClock interface {
+	// NewTicker 创建按指定间隔触发的定时器
+	NewTicker(interval time.Duration) protectionTicker
+}
+
+// systemClock 基于系统时间的时钟
+type systemClock struct{}
+
+// NewTicker 创建系统定时器
+func (systemClock) NewTicker(interval time.Duration) protectionTicker {
+	return systemTicker{time.NewTicker(interval)}
+}
+
+// systemTicker 包装 time.Ticker
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time { return t.ticker.C }
+func (t systemTicker) Stop()               { t.ticker.Stop() }
+
+// protectorSchedule 防护器的定期检查计划
+type protectorSchedule struct {
+	protectionType ProtectionType
+	protector      Protector
+	interval       time.Duration
+}
+
+// protectorSchedules 返回已初始化防护器的定期检查计划
+func (pm *ProtectionManager) protectorSchedules() []protectorSchedule {
+	var schedules []protectorSchedule
+	add := func(protectionType ProtectionType, protector Protector) {
+		schedules = append(schedules, protectorSchedule{
+			protectionType: protectionType,
+			protector:      protector,
+			interval:       pm.config.ProtectorCheckInterval(protectionType),
+		})
+	}
+
+	if pm.processProtector != nil {
+		add(ProtectionTypeProcess, pm.processProtector)
+	}
+	if pm.fileProtector != nil {
+		add(ProtectionTypeFile, pm.fileProtector)
+	}
+	if pm.registryProtector != nil {
+		add(ProtectionTypeRegistry, pm.registryProtector)
+	}
+	if pm.serviceProtector != nil {
+		add(ProtectionTypeService, pm.serviceProtector)
+	}
+	return schedules
+}
+
+// startPeriodicChecks 为每个防护器按各自的间隔启动定期检查
+// 定时器在调用方的协程中创建，保证返回后所有检查都已开始计时
+func (pm *ProtectionManager) startPeriodicChecks() {
+	for _, schedule := range pm.protectorSchedules() {
+		ticker := pm.clock.NewTicker(schedule.interval)
+		pm.logger.Debug("调度防护器定期检查", "type", schedule.protectionType, "interval", schedule.interval)
+
+		pm.wg.Add(1)
+		go pm.runPeriodicCheck(schedule, ticker)
+	}
+}
+
+// runPeriodicCheck 运行单个防护器的定期检查，紧急模式或防护被禁用时跳过
+func (pm *ProtectionManager) runPeriodicCheck(schedule protectorSchedule, ticker protectionTicker) {
+	defer pm.wg.Done()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pm.ctx.Done():
+			return
+		case <-ticker.C():
+			if !pm.IsEnabled() {
+				continue
+			}
+			if err := schedule.protector.PeriodicCheck(); err != nil {
+				pm.logger.Warn("防护器定期检查失败", "type", schedule.protectionType, "error", err)
+			}
+		}
+	}
+}
+
+// isEmergencyMode 检查是否处于紧急模式
+func (pm *ProtectionManager) isEmergencyMode() bool {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	return pm.emergencyMode
+}
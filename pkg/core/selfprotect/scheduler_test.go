@@ -0,0 +1,195 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock 手动推进的时钟
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// fakeTicker 由 fakeClock 触发的定时器，通道无缓冲，触发时等待接收方取走
+type fakeTicker struct {
+	interval time.Duration
+	next     time.Time
+	stopped  atomic.Bool
+	ch       chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.ch }
+func (t *fakeTicker) Stop()               { t.stopped.Store(true) }
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) NewTicker(interval time.Duration) protectionTicker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ticker := &fakeTicker{interval: interval, next: c.now.Add(interval), ch: make(chan time.Time)}
+	c.tickers = append(c.tickers, ticker)
+	return ticker
+}
+
+// Advance 推进时钟，按时间顺序触发到期的定时器
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		var due *fakeTicker
+		for _, ticker := range c.tickers {
+			if ticker.stopped.Load() || ticker.next.After(target) {
+				continue
+			}
+			if due == nil || ticker.next.Before(due.next) {
+				due = ticker
+			}
+		}
+		if due == nil {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+		c.now = due.next
+		due.next = due.next.Add(due.interval)
+		now := c.now
+		c.mu.Unlock()
+
+		due.ch <- now
+	}
+}
+
+// countingProtector 统计定期检查次数的防护器，满足全部防护器接口
+type countingProtector struct {
+	ProcessProtector
+	FileProtector
+	RegistryProtector
+	ServiceProtector
+
+	checks atomic.Int64
+}
+
+func (p *countingProtector) Start(ctx context.Context) error         { return nil }
+func (p *countingProtector) Stop() error                             { return nil }
+func (p *countingProtector) IsEnabled() bool                         { return true }
+func (p *countingProtector) SetEventCallback(callback EventCallback) {}
+
+func (p *countingProtector) PeriodicCheck() error {
+	p.checks.Add(1)
+	return nil
+}
+
+func TestProtectionManager_PerProtectorCheckInterval(t *testing.T) {
+	config := &ProtectionConfig{
+		Enabled:            true,
+		Level:              ProtectionLevelBasic,
+		CheckInterval:      10 * time.Second,
+		FileProtection:     FileProtectionConfig{CheckInterval: time.Minute},
+		RegistryProtection: RegistryProtectionConfig{CheckInterval: 30 * time.Second},
+		ServiceProtection:  ServiceProtectionConfig{CheckInterval: 20 * time.Second},
+	}
+	pm := NewProtectionManager(config, hclog.NewNullLogger())
+	clock := newFakeClock()
+	pm.clock = clock
+
+	process, file, registry, service := &countingProtector{}, &countingProtector{}, &countingProtector{}, &countingProtector{}
+	pm.processProtector = process
+	pm.fileProtector = file
+	pm.registryProtector = registry
+	pm.serviceProtector = service
+
+	require.NoError(t, pm.Start())
+	defer pm.Stop()
+
+	clock.Advance(2 * time.Minute)
+
+	expected := map[*countingProtector]int64{process: 12, file: 2, registry: 4, service: 6}
+	require.Eventually(t, func() bool {
+		for protector, count := range expected {
+			if protector.checks.Load() != count {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, int64(12), process.checks.Load())
+	assert.Equal(t, int64(2), file.checks.Load())
+	assert.Equal(t, int64(4), registry.checks.Load())
+	assert.Equal(t, int64(6), service.checks.Load())
+}
+
+func TestProtectionManager_EmergencyModeSkipsPeriodicChecks(t *testing.T) {
+	pm := NewProtectionManager(&ProtectionConfig{
+		Enabled:       true,
+		Level:         ProtectionLevelBasic,
+		CheckInterval: 10 * time.Second,
+	}, hclog.NewNullLogger())
+	clock := newFakeClock()
+	pm.clock = clock
+
+	file := &countingProtector{}
+	pm.fileProtector = file
+
+	require.NoError(t, pm.Start())
+	defer pm.Stop()
+
+	clock.Advance(10 * time.Second)
+	require.Eventually(t, func() bool { return file.checks.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	pm.mu.Lock()
+	pm.emergencyMode = true
+	pm.mu.Unlock()
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, int64(1), file.checks.Load())
+}
+
+func TestProtectorCheckIntervalConfig(t *testing.T) {
+	config, err := LoadProtectionConfigFromYAML([]byte(`
+self_protection:
+  enabled: true
+  level: basic
+  check_interval: 5s
+  file_protection:
+    check_interval: 5m
+  service_protection:
+    check_interval: 30s
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, 5*time.Second, config.ProtectorCheckInterval(ProtectionTypeProcess))
+	assert.Equal(t, 5*time.Minute, config.ProtectorCheckInterval(ProtectionTypeFile))
+	assert.Equal(t, 5*time.Second, config.ProtectorCheckInterval(ProtectionTypeRegistry))
+	assert.Equal(t, 30*time.Second, config.ProtectorCheckInterval(ProtectionTypeService))
+
+	merged := MergeProtectionConfigs(config, &ProtectionConfig{
+		RegistryProtection: RegistryProtectionConfig{CheckInterval: time.Minute},
+	})
+	assert.Equal(t, time.Minute, merged.ProtectorCheckInterval(ProtectionTypeRegistry))
+	assert.Equal(t, 5*time.Minute, merged.ProtectorCheckInterval(ProtectionTypeFile))
+
+	config.FileProtection.CheckInterval = 100 * time.Millisecond
+	assert.ErrorContains(t, ValidateProtectionConfig(config), "文件防护检查间隔不能小于1秒")
+
+	_, err = LoadProtectionConfigFromYAML([]byte("self_protection:\n  process_protection:\n    check_interval: soon\n"))
+	assert.ErrorContains(t, err, "process_protection.check_interval")
+}
@@ -0,0 +1,254 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// selfTestCanaryPrefix 金丝雀文件名前缀，只有带此前缀的文件会被自检创建、篡改和删除
+const selfTestCanaryPrefix = ".kennel-selftest-canary-"
+
+// SelfTestCheck 单个防护组件的自检结果
+type SelfTestCheck struct {
+	Name      string `json:"name"`
+	Target    string `json:"target"`
+	Detected  bool   `json:"detected"`  // 防护器是否检测到模拟篡改
+	Responded bool   `json:"responded"` // 防护器是否作出响应（阻止或恢复）
+	Passed    bool   `json:"passed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SelfTestResult 防护自检结果
+type SelfTestResult struct {
+	Passed    bool            `json:"passed"`
+	Message   string          `json:"message"`
+	StartTime time.Time       `json:"start_time"`
+	Duration  time.Duration   `json:"duration"`
+	Checks    []SelfTestCheck `json:"checks"`
+}
+
+// RunSelfTest 执行一次防护自检
+// 对无害的金丝雀资源进行模拟篡改，验证防护器能够检测并响应，结束后清理全部金丝雀资源
+func (pm *ProtectionManager) RunSelfTest(ctx context.Context) SelfTestResult {
+	pm.selfTestMu.Lock()
+	defer pm.selfTestMu.Unlock()
+
+	result := SelfTestResult{StartTime: time.Now()}
+
+	if !pm.IsEnabled() {
+		result.Message = "自我防护未启用，无法自检"
+	} else {
+		if pm.fileProtector != nil && pm.fileProtector.IsEnabled() {
+			result.Checks = append(result.Checks, pm.selfTestFileProtection(ctx))
+		}
+		result.Passed, result.Message = summarizeSelfTest(result.Checks)
+	}
+	result.Duration = time.Since(result.StartTime)
+
+	pm.mu.Lock()
+	pm.lastSelfTest = &result
+	pm.mu.Unlock()
+
+	if result.Passed {
+		pm.logger.Info("防护自检通过", "checks", len(result.Checks), "duration", result.Duration)
+	} else {
+		pm.logger.Warn("防护自检失败", "message", result.Message)
+	}
+
+	return result
+}
+
+// LastSelfTest 获取最近一次自检结果，尚未自检时返回 nil
+func (pm *ProtectionManager) LastSelfTest() *SelfTestResult {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	if pm.lastSelfTest == nil {
+		return nil
+	}
+	result := *pm.lastSelfTest
+	return &result
+}
+
+// runSelfTestLoop 运行定期自检
+func (pm *ProtectionManager) runSelfTestLoop() {
+	defer pm.wg.Done()
+
+	interval := pm.config.SelfTest.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-pm.ctx.Done():
+			return
+		case <-ticker.C:
+			if pm.IsEnabled() {
+				pm.RunSelfTest(pm.ctx)
+			}
+		}
+	}
+}
+
+// selfTestFileProtection 通过金丝雀文件验证文件防护
+func (pm *ProtectionManager) selfTestFileProtection(ctx context.Context) SelfTestCheck {
+	check := SelfTestCheck{Name: string(ProtectionTypeFile)}
+
+	canaryDir := pm.config.SelfTest.CanaryDir
+	if canaryDir == "" {
+		canaryDir = filepath.Join(os.TempDir(), "kennel-selfprotect")
+	}
+	if err := os.MkdirAll(canaryDir, 0755); err != nil {
+		check.Error = fmt.Sprintf("创建金丝雀目录失败: %v", err)
+		return check
+	}
+
+	canaryPath, err := filepath.Abs(filepath.Join(canaryDir, fmt.Sprintf("%s%d", selfTestCanaryPrefix, time.Now().UnixNano())))
+	if err != nil {
+		check.Error = fmt.Sprintf("获取金丝雀文件路径失败: %v", err)
+		return check
+	}
+	check.Target = canaryPath
+
+	if err := os.WriteFile(canaryPath, []byte("kennel self-protection canary\n"), 0644); err != nil {
+		check.Error = fmt.Sprintf("创建金丝雀文件失败: %v", err)
+		return check
+	}
+	defer pm.cleanupFileCanary(canaryPath)
+
+	if err := pm.fileProtector.ProtectFile(canaryPath); err != nil {
+		check.Error = fmt.Sprintf("保护金丝雀文件失败: %v", err)
+		return check
+	}
+
+	// 模拟篡改：向金丝雀文件追加内容
+	tamperedAt := time.Now()
+	if err := appendToFile(canaryPath, []byte("tampered\n")); err != nil {
+		check.Error = fmt.Sprintf("模拟篡改失败: %v", err)
+		return check
+	}
+
+	// 启用备份时防护器应恢复文件，否则只要求检测到篡改
+	expectResponse := pm.config.FileProtection.BackupEnabled
+
+	timeout := pm.config.SelfTest.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(50 * time.Millisecond)
+	defer poll.Stop()
+
+	for {
+		check.Detected, check.Responded = pm.tamperResponse(canaryPath, tamperedAt)
+		if check.Detected && (check.Responded || !expectResponse) {
+			check.Passed = true
+			return check
+		}
+
+		select {
+		case <-ctx.Done():
+			check.Error = fmt.Sprintf("自检被取消: %v", ctx.Err())
+			return check
+		case <-deadline.C:
+			if !check.Detected {
+				check.Error = fmt.Sprintf("%v 内未检测到模拟篡改", timeout)
+			} else {
+				check.Error = fmt.Sprintf("%v 内未响应模拟篡改", timeout)
+			}
+			return check
+		case <-poll.C:
+		}
+	}
+}
+
+// tamperResponse 根据防护事件判断防护器是否检测到并响应了对 target 的篡改
+func (pm *ProtectionManager) tamperResponse(target string, since time.Time) (detected, responded bool) {
+	for _, event := range pm.GetEvents() {
+		if event.Type != ProtectionTypeFile || event.Target != target || event.Timestamp.Before(since) {
+			continue
+		}
+		switch event.Action {
+		case "protect", "unprotect":
+			continue
+		case "restore":
+			responded = true
+		default:
+			detected = true
+			if event.Blocked {
+				responded = true
+			}
+		}
+	}
+	return detected, responded
+}
+
+// cleanupFileCanary 取消保护并删除金丝雀文件及其备份
+func (pm *ProtectionManager) cleanupFileCanary(canaryPath string) {
+	if pm.fileProtector.IsFileProtected(canaryPath) {
+		if err := pm.fileProtector.UnprotectFile(canaryPath); err != nil {
+			pm.logger.Warn("取消保护金丝雀文件失败", "file", canaryPath, "error", err)
+		}
+	}
+
+	if err := os.Remove(canaryPath); err != nil && !os.IsNotExist(err) {
+		pm.logger.Warn("删除金丝雀文件失败", "file", canaryPath, "error", err)
+	}
+
+	backupDir := pm.config.FileProtection.BackupDir
+	if backupDir == "" {
+		return
+	}
+	backups, _ := filepath.Glob(filepath.Join(backupDir, filepath.Base(canaryPath)+".*.backup"))
+	for _, backup := range backups {
+		if !strings.HasPrefix(filepath.Base(backup), selfTestCanaryPrefix) {
+			continue
+		}
+		if err := os.Remove(backup); err != nil {
+			pm.logger.Warn("删除金丝雀备份失败", "file", backup, "error", err)
+		}
+	}
+}
+
+// appendToFile 向文件末尾追加内容
+func appendToFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// summarizeSelfTest 汇总各组件的自检结果
+func summarizeSelfTest(checks []SelfTestCheck) (bool, string) {
+	if len(checks) == 0 {
+		return false, "没有可自检的防护组件"
+	}
+
+	var failed []string
+	for _, check := range checks {
+		if !check.Passed {
+			failed = append(failed, fmt.Sprintf("%s: %s", check.Name, check.Error))
+		}
+	}
+	if len(failed) > 0 {
+		return false, "自检未通过: " + strings.Join(failed, "; ")
+	}
+	return true, fmt.Sprintf("%d 项自检全部通过", len(checks))
+}
@@ -0,0 +1,137 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selfTestConfigYAML 只启用文件防护的配置
+const selfTestConfigYAML = `
+self_protection:
+  enabled: true
+  level: basic
+  check_interval: 1h
+  file_protection:
+    enabled: true
+    protected_files:
+      - %q
+    check_integrity: true
+    backup_enabled: true
+    backup_dir: %q
+  self_test:
+    timeout: 5s
+    canary_dir: %q
+`
+
+// blindFileProtector 无法检测任何篡改的文件防护器
+type blindFileProtector struct {
+	FileProtector
+}
+
+func (b *blindFileProtector) IsEnabled() bool                      { return true }
+func (b *blindFileProtector) ProtectFile(filePath string) error    { return nil }
+func (b *blindFileProtector) IsFileProtected(filePath string) bool { return false }
+
+func dirEntries(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func TestSelfTest_DetectsSimulatedTamper(t *testing.T) {
+	dir := t.TempDir()
+	protectedFile := filepath.Join(dir, "config.yaml")
+	backupDir := filepath.Join(dir, "backup")
+	canaryDir := filepath.Join(dir, "canary")
+	require.NoError(t, os.WriteFile(protectedFile, []byte("real: asset\n"), 0644))
+
+	configFile := filepath.Join(dir, "protection.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(fmt.Sprintf(selfTestConfigYAML, protectedFile, backupDir, canaryDir)), 0644))
+
+	service, err := NewProtectionService(configFile, hclog.NewNullLogger())
+	require.NoError(t, err)
+	require.NoError(t, service.Start())
+	defer service.Stop()
+
+	// 等待文件防护器完成启动
+	require.Eventually(t, func() bool {
+		return service.manager.fileProtector.IsFileProtected(protectedFile)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	checker := NewProtectionHealthChecker(service, hclog.NewNullLogger())
+	health := checker.RunSelfTest(context.Background())
+	assert.Equal(t, "healthy", health.Status, health.Message)
+
+	selfTest, ok := health.Details["self_test"].(*SelfTestResult)
+	require.True(t, ok)
+	assert.True(t, selfTest.Passed, selfTest.Message)
+	require.Len(t, selfTest.Checks, 1)
+	check := selfTest.Checks[0]
+	assert.Equal(t, "file", check.Name)
+	assert.True(t, check.Detected, "应检测到对金丝雀文件的篡改")
+	assert.True(t, check.Responded, "启用备份时应从备份恢复金丝雀文件")
+	assert.True(t, strings.HasPrefix(filepath.Base(check.Target), selfTestCanaryPrefix))
+
+	// 自检结束后清理金丝雀资源，且不影响真实的受保护文件
+	content, err := os.ReadFile(protectedFile)
+	require.NoError(t, err)
+	assert.Equal(t, "real: asset\n", string(content))
+	assert.True(t, service.manager.fileProtector.IsFileProtected(protectedFile))
+	assert.Empty(t, dirEntries(t, canaryDir))
+	for _, name := range dirEntries(t, backupDir) {
+		assert.True(t, strings.HasPrefix(name, "config.yaml."), "金丝雀备份应被删除: %s", name)
+	}
+
+	// 健康检查保留最近一次自检结果
+	assert.Equal(t, "healthy", checker.CheckHealth().Status)
+	assert.True(t, service.LastSelfTest().Passed)
+}
+
+func TestSelfTest_ReportsUndetectedTamper(t *testing.T) {
+	config := DefaultProtectionConfig()
+	config.Enabled = true
+	config.SelfTest.Timeout = 200 * time.Millisecond
+	config.SelfTest.CanaryDir = t.TempDir()
+
+	manager := &ProtectionManager{
+		config:        config,
+		logger:        hclog.NewNullLogger(),
+		enabled:       true,
+		fileProtector: &blindFileProtector{},
+	}
+	service := &ProtectionService{manager: manager, config: config, logger: hclog.NewNullLogger(), started: true}
+
+	result := service.RunSelfTest(context.Background())
+	assert.False(t, result.Passed)
+	require.Len(t, result.Checks, 1)
+	assert.False(t, result.Checks[0].Detected)
+	assert.Contains(t, result.Message, "未检测到模拟篡改")
+	assert.Empty(t, dirEntries(t, config.SelfTest.CanaryDir), "自检失败时同样要清理金丝雀文件")
+
+	health := NewProtectionHealthChecker(service, hclog.NewNullLogger()).CheckHealth()
+	assert.Equal(t, "unhealthy", health.Status)
+	assert.Contains(t, health.Message, "防护自检失败")
+
+	// 没有可自检的防护组件时同样视为失败
+	manager.fileProtector = nil
+	result = service.RunSelfTest(context.Background())
+	assert.False(t, result.Passed)
+	assert.Equal(t, "没有可自检的防护组件", result.Message)
+}
@@ -40,3 +40,6 @@ func (esp *EmptyServiceProtector) PreventServiceDisable(serviceName string) erro
 func (esp *EmptyServiceProtector) GetServiceStatus(serviceName string) (ServiceStatus, error) {
 	return ServiceStatus{}, nil
 }
+
+// ConfigureServiceRecovery 服务故障恢复仅在Windows平台可用
+func ConfigureServiceRecovery(config ServiceProtectionConfig) error { return nil }
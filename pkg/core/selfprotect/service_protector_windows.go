@@ -156,6 +156,16 @@ func (sp *WindowsServiceProtector) ProtectService(serviceName string) error {
 		return fmt.Errorf("获取服务配置失败: %w", err)
 	}
 
+	// 使服务控制管理器的故障恢复与应用的重启策略一致
+	if serviceName == sp.config.ServiceName && sp.config.Recovery.Enabled {
+		if err := applyServiceRecovery(service, sp.config); err != nil {
+			sp.logger.Warn("配置服务恢复操作失败", "service", serviceName, "error", err)
+		} else {
+			sp.logger.Info("服务恢复操作已配置", "service", serviceName, "auto_restart", sp.config.AutoRestart,
+				"restart_delays", sp.config.Recovery.RestartDelays())
+		}
+	}
+
 	// 添加到保护列表
 	sp.protectedServices[serviceName] = &ProtectedService{
 		Name:          serviceName,
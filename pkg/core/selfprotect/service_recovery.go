@@ -0,0 +1,78 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"fmt"
+	"time"
+)
+
+// 服务故障恢复的默认值
+const (
+	DefaultRecoveryBackoffMultiplier = 2.0
+	DefaultRecoveryMaxRestartDelay   = 5 * time.Minute
+	DefaultRecoveryResetPeriod       = 24 * time.Hour
+)
+
+// withRecoveryDefaults 用全局重启策略补全恢复配置中未设置的项，使服务控制管理器与应用的重启策略一致
+func (c ServiceProtectionConfig) withRecoveryDefaults(restartDelay time.Duration, maxRestartAttempts int) ServiceProtectionConfig {
+	recovery := &c.Recovery
+	if recovery.RestartDelay <= 0 {
+		recovery.RestartDelay = restartDelay
+	}
+	if recovery.MaxRestarts <= 0 {
+		recovery.MaxRestarts = maxRestartAttempts
+	}
+	if recovery.BackoffMultiplier <= 0 {
+		recovery.BackoffMultiplier = DefaultRecoveryBackoffMultiplier
+	}
+	if recovery.MaxRestartDelay <= 0 {
+		recovery.MaxRestartDelay = DefaultRecoveryMaxRestartDelay
+	}
+	if recovery.ResetPeriod <= 0 {
+		recovery.ResetPeriod = DefaultRecoveryResetPeriod
+	}
+	return c
+}
+
+// RestartDelays 返回每次故障后重启服务前的等待时间
+// 第一次等待 RestartDelay，之后按 BackoffMultiplier 递增，不超过 MaxRestartDelay
+func (c ServiceRecoveryConfig) RestartDelays() []time.Duration {
+	if c.MaxRestarts <= 0 {
+		return nil
+	}
+
+	delays := make([]time.Duration, 0, c.MaxRestarts)
+	delay := c.RestartDelay
+	for i := 0; i < c.MaxRestarts; i++ {
+		if c.MaxRestartDelay > 0 && delay > c.MaxRestartDelay {
+			delay = c.MaxRestartDelay
+		}
+		delays = append(delays, delay)
+		if c.BackoffMultiplier > 1 {
+			delay = time.Duration(float64(delay) * c.BackoffMultiplier)
+		}
+	}
+	return delays
+}
+
+// validateServiceRecovery 验证服务故障恢复配置
+func validateServiceRecovery(config ServiceRecoveryConfig) error {
+	if !config.Enabled {
+		return nil
+	}
+	if config.RestartDelay < 0 || config.MaxRestartDelay < 0 {
+		return fmt.Errorf("服务恢复的重启等待时间不能为负数")
+	}
+	if config.MaxRestarts < 0 {
+		return fmt.Errorf("服务恢复的重启次数不能为负数")
+	}
+	if config.BackoffMultiplier != 0 && config.BackoffMultiplier < 1 {
+		return fmt.Errorf("服务恢复的退避倍数不能小于1")
+	}
+	if config.ResetPeriod < 0 {
+		return fmt.Errorf("服务恢复的故障计数重置周期不能为负数")
+	}
+	return nil
+}
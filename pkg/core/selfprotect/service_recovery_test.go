@@ -0,0 +1,96 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceRecoveryRestartDelays(t *testing.T) {
+	recovery := ServiceRecoveryConfig{
+		RestartDelay:      5 * time.Second,
+		MaxRestartDelay:   time.Minute,
+		BackoffMultiplier: 3,
+		MaxRestarts:       5,
+	}
+	assert.Equal(t, []time.Duration{
+		5 * time.Second,
+		15 * time.Second,
+		45 * time.Second,
+		time.Minute,
+		time.Minute,
+	}, recovery.RestartDelays())
+
+	recovery.MaxRestarts = 0
+	assert.Empty(t, recovery.RestartDelays())
+}
+
+func TestServiceRecoveryDefaults(t *testing.T) {
+	config := ServiceProtectionConfig{
+		ServiceName: "KennelAgent",
+		AutoRestart: true,
+		Recovery:    ServiceRecoveryConfig{Enabled: true},
+	}.withRecoveryDefaults(3*time.Second, 3)
+
+	recovery := config.Recovery
+	assert.Equal(t, 3*time.Second, recovery.RestartDelay, "未设置时使用全局重启延迟")
+	assert.Equal(t, 3, recovery.MaxRestarts, "未设置时使用全局最大重启尝试次数")
+	assert.Equal(t, DefaultRecoveryBackoffMultiplier, recovery.BackoffMultiplier)
+	assert.Equal(t, DefaultRecoveryMaxRestartDelay, recovery.MaxRestartDelay)
+	assert.Equal(t, DefaultRecoveryResetPeriod, recovery.ResetPeriod)
+	assert.Equal(t, []time.Duration{3 * time.Second, 6 * time.Second, 12 * time.Second}, recovery.RestartDelays())
+
+	// 显式配置优先于全局重启策略
+	config = ServiceProtectionConfig{
+		Recovery: ServiceRecoveryConfig{Enabled: true, RestartDelay: time.Second, MaxRestarts: 2, BackoffMultiplier: 1},
+	}.withRecoveryDefaults(3*time.Second, 3)
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, config.Recovery.RestartDelays())
+}
+
+func TestLoadServiceRecoveryConfig(t *testing.T) {
+	config, err := LoadProtectionConfigFromYAML([]byte(`
+self_protection:
+  enabled: true
+  level: basic
+  service_protection:
+    enabled: true
+    service_name: KennelAgent
+    auto_restart: true
+    recovery:
+      enabled: true
+      restart_delay: 10s
+      max_restart_delay: 2m
+      backoff_multiplier: 1.5
+      max_restarts: 4
+      reset_period: 12h
+      on_non_crash_failures: true
+`))
+	require.NoError(t, err)
+	require.NoError(t, ValidateProtectionConfig(config))
+
+	assert.Equal(t, ServiceRecoveryConfig{
+		Enabled:            true,
+		RestartDelay:       10 * time.Second,
+		MaxRestartDelay:    2 * time.Minute,
+		BackoffMultiplier:  1.5,
+		MaxRestarts:        4,
+		ResetPeriod:        12 * time.Hour,
+		OnNonCrashFailures: true,
+	}, config.ServiceProtection.Recovery)
+
+	_, err = LoadProtectionConfigFromYAML([]byte(`
+self_protection:
+  service_protection:
+    recovery:
+      restart_delay: soon
+`))
+	assert.ErrorContains(t, err, "service_protection.recovery.restart_delay")
+
+	config.ServiceProtection.Recovery.BackoffMultiplier = 0.5
+	assert.Error(t, ValidateProtectionConfig(config), "退避倍数不能小于1")
+}
@@ -0,0 +1,64 @@
+//go:build selfprotect && windows
+// +build selfprotect,windows
+
+package selfprotect
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// ConfigureServiceRecovery 将服务故障恢复操作写入服务控制管理器，供安装服务时调用
+// 未启用 Recovery 时不修改服务配置
+func ConfigureServiceRecovery(config ServiceProtectionConfig) error {
+	if !config.Recovery.Enabled {
+		return nil
+	}
+
+	manager, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("连接服务管理器失败: %w", err)
+	}
+	defer manager.Disconnect()
+
+	service, err := manager.OpenService(config.ServiceName)
+	if err != nil {
+		return fmt.Errorf("打开服务失败: %w", err)
+	}
+	defer service.Close()
+
+	return applyServiceRecovery(service, config)
+}
+
+// applyServiceRecovery 按防护配置设置服务的恢复操作
+// AutoRestart 时按退避策略重启服务，否则清除恢复操作，避免服务控制管理器重启应用不再自动重启的服务
+func applyServiceRecovery(service *mgr.Service, config ServiceProtectionConfig) error {
+	if !config.AutoRestart {
+		if err := service.ResetRecoveryActions(); err != nil {
+			return fmt.Errorf("清除服务恢复操作失败: %w", err)
+		}
+		return nil
+	}
+
+	recovery := config.Recovery
+	resetPeriod := uint32(recovery.ResetPeriod.Seconds())
+	if err := service.SetRecoveryActions(serviceRecoveryActions(recovery), resetPeriod); err != nil {
+		return fmt.Errorf("设置服务恢复操作失败: %w", err)
+	}
+	if err := service.SetRecoveryActionsOnNonCrashFailures(recovery.OnNonCrashFailures); err != nil {
+		return fmt.Errorf("设置非崩溃故障的恢复操作失败: %w", err)
+	}
+	return nil
+}
+
+// serviceRecoveryActions 将重启等待时间转换为服务控制管理器的恢复操作
+// 服务控制管理器对后续故障重复最后一个操作，因此重启次数用完后追加一个不执行操作的动作
+func serviceRecoveryActions(config ServiceRecoveryConfig) []mgr.RecoveryAction {
+	delays := config.RestartDelays()
+	actions := make([]mgr.RecoveryAction, 0, len(delays)+1)
+	for _, delay := range delays {
+		actions = append(actions, mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: delay})
+	}
+	return append(actions, mgr.RecoveryAction{Type: mgr.NoAction})
+}
@@ -0,0 +1,127 @@
+//go:build selfprotect && windows
+// +build selfprotect,windows
+
+package selfprotect
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// createTestService 注册一个不会启动的测试服务，需要管理员权限，否则跳过测试
+func createTestService(t *testing.T) string {
+	manager, err := mgr.Connect()
+	if err != nil {
+		t.Skipf("连接服务管理器失败（需要管理员权限）: %v", err)
+	}
+	defer manager.Disconnect()
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	name := fmt.Sprintf("KennelRecoveryTest%d", time.Now().UnixNano())
+	service, err := manager.CreateService(name, exe, mgr.Config{
+		DisplayName: "Kennel recovery test",
+		StartType:   mgr.StartManual,
+	})
+	if err != nil {
+		t.Skipf("创建测试服务失败（需要管理员权限）: %v", err)
+	}
+	service.Close()
+
+	t.Cleanup(func() {
+		manager, err := mgr.Connect()
+		if err != nil {
+			return
+		}
+		defer manager.Disconnect()
+		if service, err := manager.OpenService(name); err == nil {
+			service.Delete()
+			service.Close()
+		}
+	})
+	return name
+}
+
+func openTestService(t *testing.T, name string) *mgr.Service {
+	manager, err := mgr.Connect()
+	require.NoError(t, err)
+	t.Cleanup(func() { manager.Disconnect() })
+
+	service, err := manager.OpenService(name)
+	require.NoError(t, err)
+	t.Cleanup(func() { service.Close() })
+	return service
+}
+
+func TestConfigureServiceRecovery(t *testing.T) {
+	name := createTestService(t)
+	config := ServiceProtectionConfig{
+		Enabled:     true,
+		ServiceName: name,
+		AutoRestart: true,
+		Recovery: ServiceRecoveryConfig{
+			Enabled:            true,
+			RestartDelay:       2 * time.Second,
+			MaxRestartDelay:    5 * time.Second,
+			BackoffMultiplier:  2,
+			MaxRestarts:        3,
+			ResetPeriod:        time.Hour,
+			OnNonCrashFailures: true,
+		},
+	}
+	require.NoError(t, ConfigureServiceRecovery(config))
+
+	service := openTestService(t, name)
+	actions, err := service.RecoveryActions()
+	require.NoError(t, err)
+	assert.Equal(t, []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 2 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 4 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+		{Type: mgr.NoAction},
+	}, actions)
+
+	resetPeriod, err := service.ResetPeriod()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3600), resetPeriod)
+
+	onNonCrash, err := service.RecoveryActionsOnNonCrashFailures()
+	require.NoError(t, err)
+	assert.True(t, onNonCrash)
+
+	// 关闭自动重启后清除恢复操作
+	config.AutoRestart = false
+	require.NoError(t, ConfigureServiceRecovery(config))
+	actions, err = service.RecoveryActions()
+	require.NoError(t, err)
+	assert.Empty(t, actions)
+}
+
+func TestServiceProtectorConfiguresRecovery(t *testing.T) {
+	name := createTestService(t)
+	config := ServiceProtectionConfig{
+		Enabled:     true,
+		ServiceName: name,
+		AutoRestart: true,
+		Recovery:    ServiceRecoveryConfig{Enabled: true},
+	}.withRecoveryDefaults(time.Second, 2)
+
+	protector := NewServiceProtector(config, hclog.NewNullLogger())
+	require.NoError(t, protector.ProtectService(name))
+
+	actions, err := openTestService(t, name).RecoveryActions()
+	require.NoError(t, err)
+	assert.Equal(t, []mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: time.Second},
+		{Type: mgr.ServiceRestart, Delay: 2 * time.Second},
+		{Type: mgr.NoAction},
+	}, actions)
+}
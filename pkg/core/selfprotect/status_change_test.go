@@ -0,0 +1,145 @@
+//go:build selfprotect
+// +build selfprotect
+
+package selfprotect
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusRecorder 记录防护状态变化回调
+type statusRecorder struct {
+	mu      sync.Mutex
+	changes []StatusChange
+}
+
+func (r *statusRecorder) record(change StatusChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, change)
+}
+
+func (r *statusRecorder) snapshot() []StatusChange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changes := make([]StatusChange, len(r.changes))
+	copy(changes, r.changes)
+	return changes
+}
+
+func TestProtectionManager_StatusChangeOnStartWithEmergencyFile(t *testing.T) {
+	emergencyFile := filepath.Join(t.TempDir(), "disable_protection")
+	require.NoError(t, os.WriteFile(emergencyFile, nil, 0644))
+
+	pm := NewProtectionManager(&ProtectionConfig{
+		Enabled:          true,
+		Level:            ProtectionLevelBasic,
+		EmergencyDisable: emergencyFile,
+		CheckInterval:    10 * time.Second,
+	}, hclog.NewNullLogger())
+	recorder := &statusRecorder{}
+	pm.OnStatusChange(recorder.record)
+
+	require.NoError(t, pm.Start())
+	defer pm.Stop()
+
+	changes := recorder.snapshot()
+	require.Len(t, changes, 1)
+	assert.False(t, changes[0].Enabled)
+	assert.True(t, changes[0].Previous)
+	assert.Equal(t, StatusChangeReasonEmergencyFile, changes[0].Reason)
+	assert.Equal(t, emergencyFile, changes[0].Message)
+	assert.False(t, pm.IsEnabled())
+}
+
+func TestProtectionManager_StatusChangeWhenEmergencyFileAppears(t *testing.T) {
+	emergencyFile := filepath.Join(t.TempDir(), "disable_protection")
+	pm := NewProtectionManager(&ProtectionConfig{
+		Enabled:          true,
+		Level:            ProtectionLevelBasic,
+		EmergencyDisable: emergencyFile,
+		CheckInterval:    10 * time.Second,
+	}, hclog.NewNullLogger())
+	clock := newFakeClock()
+	pm.clock = clock
+	recorder := &statusRecorder{}
+	pm.OnStatusChange(recorder.record)
+
+	require.NoError(t, pm.Start())
+	defer pm.Stop()
+
+	clock.Advance(10 * time.Second)
+	assert.Empty(t, recorder.snapshot())
+
+	require.NoError(t, os.WriteFile(emergencyFile, nil, 0644))
+	clock.Advance(10 * time.Second)
+	require.Eventually(t, func() bool { return len(recorder.snapshot()) == 1 }, time.Second, 5*time.Millisecond)
+
+	change := recorder.snapshot()[0]
+	assert.False(t, change.Enabled)
+	assert.Equal(t, StatusChangeReasonEmergencyFile, change.Reason)
+	assert.Equal(t, emergencyFile, change.Message)
+
+	// 已处于紧急模式时不重复通知
+	clock.Advance(time.Minute)
+	assert.Len(t, recorder.snapshot(), 1)
+}
+
+func TestProtectionManager_SetEnabled(t *testing.T) {
+	pm := NewProtectionManager(&ProtectionConfig{Enabled: true, Level: ProtectionLevelBasic}, hclog.NewNullLogger())
+	recorder := &statusRecorder{}
+	pm.OnStatusChange(recorder.record)
+
+	pm.SetEnabled(false, StatusChangeReasonConfigReload, "self_protection.enabled=false")
+	pm.SetEnabled(false, StatusChangeReasonConfigReload, "self_protection.enabled=false")
+	pm.SetEnabled(true, StatusChangeReasonError, "")
+
+	changes := recorder.snapshot()
+	require.Len(t, changes, 2)
+	assert.False(t, changes[0].Enabled)
+	assert.Equal(t, StatusChangeReasonConfigReload, changes[0].Reason)
+	assert.Equal(t, "self_protection.enabled=false", changes[0].Message)
+	assert.True(t, changes[1].Enabled)
+	assert.False(t, changes[1].Previous)
+	assert.Equal(t, StatusChangeReasonError, changes[1].Reason)
+}
+
+func TestRemoteReporter_NotifyStatusChange(t *testing.T) {
+	sender := &fakeSender{}
+	reporter := NewRemoteReporter(&fakeSource{}, sender, RemoteReporterConfig{Interval: time.Hour, MaxBufferedEvents: 2}, hclog.NewNullLogger())
+
+	// 未连接时缓存，超出上限丢弃最早的变化
+	for _, reason := range []StatusChangeReason{StatusChangeReasonConfigReload, StatusChangeReasonError, StatusChangeReasonEmergencyFile} {
+		reporter.NotifyStatusChange(StatusChange{Reason: reason, Timestamp: time.Now()})
+	}
+	dataCount, eventCount := sender.counts()
+	assert.Zero(t, dataCount)
+	assert.Zero(t, eventCount)
+	assert.Equal(t, int64(1), reporter.GetStats().EventsDropped)
+	assert.Equal(t, 2, reporter.GetStats().BufferedEvents)
+
+	// 恢复连接后随下一次上报补发
+	sender.SetConnected(true)
+	reporter.Report()
+	_, eventCount = sender.counts()
+	require.Equal(t, 2, eventCount)
+	assert.Equal(t, string(StatusChangeReasonError), sender.events[0]["reason"])
+	assert.Equal(t, string(StatusChangeReasonEmergencyFile), sender.events[1]["reason"])
+
+	// 已连接时立即发送变化和当前状态
+	dataCount, _ = sender.counts()
+	reporter.NotifyStatusChange(StatusChange{Enabled: false, Previous: true, Reason: StatusChangeReasonEmergencyFile, Message: "/tmp/disable"})
+	newDataCount, eventCount := sender.counts()
+	assert.Equal(t, 3, eventCount)
+	assert.Equal(t, dataCount+1, newDataCount)
+	assert.Equal(t, "/tmp/disable", sender.events[2]["message"])
+	assert.Equal(t, 0, reporter.GetStats().BufferedEvents)
+}
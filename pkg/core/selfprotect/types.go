@@ -38,6 +38,7 @@ type ProtectionConfig struct {
 	FileProtection     FileProtectionConfig     `yaml:"file_protection"`
 	RegistryProtection RegistryProtectionConfig `yaml:"registry_protection"`
 	ServiceProtection  ServiceProtectionConfig  `yaml:"service_protection"`
+	SelfTest           SelfTestConfig           `yaml:"self_test"`
 }
 
 // WhitelistConfig 白名单配置
@@ -50,36 +51,71 @@ type WhitelistConfig struct {
 
 // ProcessProtectionConfig 进程防护配置
 type ProcessProtectionConfig struct {
-	Enabled            bool     `yaml:"enabled"`
-	ProtectedProcesses []string `yaml:"protected_processes"`
-	MonitorChildren    bool     `yaml:"monitor_children"`
-	PreventDebug       bool     `yaml:"prevent_debug"`
-	PreventDump        bool     `yaml:"prevent_dump"`
+	Enabled            bool          `yaml:"enabled"`
+	ProtectedProcesses []string      `yaml:"protected_processes"` // 进程名、完整路径、通配符或 sha256:<哈希>
+	MonitorChildren    bool          `yaml:"monitor_children"`
+	PreventDebug       bool          `yaml:"prevent_debug"`
+	PreventDump        bool          `yaml:"prevent_dump"`
+	CheckInterval      time.Duration `yaml:"check_interval"` // 定期检查间隔，为0时使用全局检查间隔
 }
 
 // FileProtectionConfig 文件防护配置
 type FileProtectionConfig struct {
-	Enabled        bool     `yaml:"enabled"`
-	ProtectedFiles []string `yaml:"protected_files"`
-	ProtectedDirs  []string `yaml:"protected_dirs"`
-	CheckIntegrity bool     `yaml:"check_integrity"`
-	BackupEnabled  bool     `yaml:"backup_enabled"`
-	BackupDir      string   `yaml:"backup_dir"`
+	Enabled        bool                `yaml:"enabled"`
+	ProtectedFiles []string            `yaml:"protected_files"`
+	ProtectedDirs  []string            `yaml:"protected_dirs"`
+	Exclusions     map[string][]string `yaml:"exclusions"` // 受保护目录下不做完整性监控的子路径，键为 ProtectedDirs 中的目录，值为排除模式
+	CheckIntegrity bool                `yaml:"check_integrity"`
+	BackupEnabled  bool                `yaml:"backup_enabled"`
+	BackupDir      string              `yaml:"backup_dir"`
+	CheckInterval  time.Duration       `yaml:"check_interval"` // 定期检查间隔，为0时使用全局检查间隔
+
+	// AllowedModifiers 允许修改受保护文件的进程（如安装程序、升级服务），按完整路径或 sha256:<哈希> 指定
+	// 这些进程的修改作为新的基线，不触发恢复
+	AllowedModifiers []string `yaml:"allowed_modifiers"`
 }
 
 // RegistryProtectionConfig 注册表防护配置
 type RegistryProtectionConfig struct {
-	Enabled        bool     `yaml:"enabled"`
-	ProtectedKeys  []string `yaml:"protected_keys"`
-	MonitorChanges bool     `yaml:"monitor_changes"`
+	Enabled        bool          `yaml:"enabled"`
+	ProtectedKeys  []string      `yaml:"protected_keys"`
+	MonitorChanges bool          `yaml:"monitor_changes"`
+	CheckInterval  time.Duration `yaml:"check_interval"` // 定期检查间隔，为0时使用全局检查间隔
+
+	// AllowedModifiers 允许修改受保护注册表键的进程，格式同文件防护
+	AllowedModifiers []string `yaml:"allowed_modifiers"`
 }
 
 // ServiceProtectionConfig 服务防护配置
 type ServiceProtectionConfig struct {
-	Enabled        bool   `yaml:"enabled"`
-	ServiceName    string `yaml:"service_name"`
-	AutoRestart    bool   `yaml:"auto_restart"`
-	PreventDisable bool   `yaml:"prevent_disable"`
+	Enabled        bool          `yaml:"enabled"`
+	ServiceName    string        `yaml:"service_name"`
+	AutoRestart    bool          `yaml:"auto_restart"`
+	PreventDisable bool          `yaml:"prevent_disable"`
+	CheckInterval  time.Duration `yaml:"check_interval"` // 定期检查间隔，为0时使用全局检查间隔
+
+	// Recovery 服务控制管理器的故障恢复操作（仅Windows）
+	Recovery ServiceRecoveryConfig `yaml:"recovery"`
+}
+
+// ServiceRecoveryConfig 服务故障恢复配置
+// 启用后在启动服务防护时将恢复操作写入服务控制管理器：AutoRestart 时按退避策略重启服务，否则清除恢复操作
+type ServiceRecoveryConfig struct {
+	Enabled            bool          `yaml:"enabled"`
+	RestartDelay       time.Duration `yaml:"restart_delay"`         // 第一次重启前的等待时间，为0时使用全局重启延迟
+	MaxRestartDelay    time.Duration `yaml:"max_restart_delay"`     // 重启等待时间的上限
+	BackoffMultiplier  float64       `yaml:"backoff_multiplier"`    // 每次故障后重启等待时间的倍数
+	MaxRestarts        int           `yaml:"max_restarts"`          // 重启次数，为0时使用全局最大重启尝试次数
+	ResetPeriod        time.Duration `yaml:"reset_period"`          // 无故障运行多久后重置故障计数
+	OnNonCrashFailures bool          `yaml:"on_non_crash_failures"` // 服务以非零退出码停止时也执行恢复操作
+}
+
+// SelfTestConfig 防护自检配置
+type SelfTestConfig struct {
+	Enabled   bool          `yaml:"enabled"`    // 是否定期自检，按需自检不受此开关影响
+	Interval  time.Duration `yaml:"interval"`   // 定期自检间隔
+	Timeout   time.Duration `yaml:"timeout"`    // 等待防护器检测到模拟篡改的最长时间
+	CanaryDir string        `yaml:"canary_dir"` // 金丝雀文件所在目录，默认为系统临时目录下的 kennel-selfprotect
 }
 
 // ProtectionEvent 防护事件
@@ -123,6 +159,27 @@ type ServiceStatus struct {
 // EventCallback 事件回调函数类型
 type EventCallback func(event ProtectionEvent)
 
+// StatusChangeReason 防护状态变化的原因
+type StatusChangeReason string
+
+const (
+	StatusChangeReasonEmergencyFile StatusChangeReason = "emergency_file" // 出现或移除紧急禁用文件
+	StatusChangeReasonConfigReload  StatusChangeReason = "config_reload"  // 重新加载配置
+	StatusChangeReasonError         StatusChangeReason = "error"          // 防护运行出错
+)
+
+// StatusChange 防护状态变化
+type StatusChange struct {
+	Enabled   bool               `json:"enabled"`           // 变化后是否启用
+	Previous  bool               `json:"previous"`          // 变化前是否启用
+	Reason    StatusChangeReason `json:"reason"`            // 变化原因
+	Message   string             `json:"message,omitempty"` // 说明，如紧急禁用文件路径或错误信息
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+// StatusChangeCallback 防护状态变化回调函数类型
+type StatusChangeCallback func(change StatusChange)
+
 // Protector 防护器接口
 type Protector interface {
 	// Start 启动防护
@@ -0,0 +1,221 @@
+// Package shutdown 按依赖关系有序停止插件
+// 依赖其他插件的插件先于被依赖的插件停止；每个插件有独立的停止时限，
+// 超时的插件被强制终止后继续停止其余插件，单个插件卡住不会拖住整个关闭流程
+package shutdown
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout 单个插件的默认停止时限
+const DefaultTimeout = 10 * time.Second
+
+// Status 插件停止结果状态
+type Status string
+
+const (
+	StatusStopped  Status = "stopped"   // 在时限内停止
+	StatusFailed   Status = "failed"    // 停止函数返回错误
+	StatusTimedOut Status = "timed_out" // 超出时限，已强制终止
+	StatusSkipped  Status = "skipped"   // 整体关闭时限已耗尽，未尝试停止，已强制终止
+)
+
+// Plugin 待停止的插件
+type Plugin struct {
+	ID           string
+	Dependencies []string                        // 依赖的插件ID，这些插件在本插件之后停止
+	Timeout      time.Duration                   // 停止时限，0表示使用默认值
+	Stop         func(ctx context.Context) error // 停止函数，ctx 在时限到达时取消
+	Kill         func()                          // 强制终止函数，超时后调用，可以为nil
+}
+
+// Result 单个插件的停止结果
+type Result struct {
+	Plugin   string        `json:"plugin"`
+	Status   Status        `json:"status"`
+	Budget   time.Duration `json:"budget"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Report 关闭汇总报告
+type Report struct {
+	Order      []string      `json:"order"`                 // 实际的停止顺序
+	OrderError string        `json:"order_error,omitempty"` // 无法按依赖关系排序的原因，此时按ID顺序停止
+	Results    []Result      `json:"results"`               // 按停止顺序排列
+	Duration   time.Duration `json:"duration"`
+}
+
+// Offenders 返回超出停止时限或未来得及停止的插件
+func (r *Report) Offenders() []Result {
+	var offenders []Result
+	for _, result := range r.Results {
+		if result.Status == StatusTimedOut || result.Status == StatusSkipped {
+			offenders = append(offenders, result)
+		}
+	}
+	return offenders
+}
+
+// String 返回可读的关闭报告
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, result := range r.Results {
+		fmt.Fprintf(&b, "[%s] %s: %s/%s", strings.ToUpper(string(result.Status)), result.Plugin,
+			result.Duration.Round(time.Millisecond), result.Budget)
+		if result.Error != "" {
+			fmt.Fprintf(&b, ": %s", result.Error)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%d 个插件，%d 个超时，耗时 %s\n", len(r.Results), len(r.Offenders()), r.Duration.Round(time.Millisecond))
+	return b.String()
+}
+
+// Order 计算停止顺序：依赖其他插件的插件排在被依赖的插件之前
+// 未加载的依赖被忽略；存在循环依赖时返回错误，同一层级内按ID排序
+func Order(plugins []Plugin) ([]string, error) {
+	known := make(map[string]bool, len(plugins))
+	for _, p := range plugins {
+		if known[p.ID] {
+			return nil, fmt.Errorf("插件ID重复: %s", p.ID)
+		}
+		known[p.ID] = true
+	}
+
+	// dependents[x] 为依赖 x 的插件，它们停止后 x 才能停止
+	dependents := make(map[string]int, len(plugins))
+	deps := make(map[string][]string, len(plugins))
+	for _, p := range plugins {
+		for _, dep := range p.Dependencies {
+			if !known[dep] || dep == p.ID {
+				continue
+			}
+			dependents[dep]++
+			deps[p.ID] = append(deps[p.ID], dep)
+		}
+	}
+
+	var ready []string
+	for _, p := range plugins {
+		if dependents[p.ID] == 0 {
+			ready = append(ready, p.ID)
+		}
+	}
+
+	order := make([]string, 0, len(plugins))
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+
+		for _, dep := range deps[id] {
+			dependents[dep]--
+			if dependents[dep] == 0 {
+				ready = append(ready, dep)
+			}
+		}
+	}
+
+	if len(order) != len(plugins) {
+		var cycle []string
+		for _, p := range plugins {
+			if dependents[p.ID] > 0 {
+				cycle = append(cycle, p.ID)
+			}
+		}
+		sort.Strings(cycle)
+		return nil, fmt.Errorf("插件存在循环依赖: %s", strings.Join(cycle, ", "))
+	}
+	return order, nil
+}
+
+// Run 按依赖顺序依次停止插件
+// 每个插件的时限不超过 ctx 剩余的整体时限；超时的插件被强制终止后继续停止下一个插件，
+// 整体时限耗尽后其余插件不再等待，直接强制终止。存在循环依赖时按ID顺序停止
+func Run(ctx context.Context, plugins []Plugin) *Report {
+	start := time.Now()
+
+	byID := make(map[string]Plugin, len(plugins))
+	for _, p := range plugins {
+		byID[p.ID] = p
+	}
+
+	report := &Report{}
+	order, err := Order(plugins)
+	if err != nil {
+		report.OrderError = err.Error()
+		order = make([]string, 0, len(byID))
+		for id := range byID {
+			order = append(order, id)
+		}
+		sort.Strings(order)
+	}
+
+	report.Order = order
+	report.Results = make([]Result, 0, len(order))
+	for _, id := range order {
+		report.Results = append(report.Results, stopPlugin(ctx, byID[id]))
+	}
+	report.Duration = time.Since(start)
+	return report
+}
+
+// stopPlugin 在时限内停止单个插件，超时或整体时限已耗尽时强制终止
+func stopPlugin(ctx context.Context, p Plugin) Result {
+	budget := p.Timeout
+	if budget <= 0 {
+		budget = DefaultTimeout
+	}
+	result := Result{Plugin: p.ID, Budget: budget}
+
+	if ctx.Err() != nil {
+		result.Status = StatusSkipped
+		result.Error = "整体关闭时限已耗尽"
+		kill(p)
+		return result
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errCh <- fmt.Errorf("停止插件发生panic: %v", r)
+			}
+		}()
+		errCh <- p.Stop(stopCtx)
+	}()
+
+	select {
+	case err := <-errCh:
+		result.Duration = time.Since(start)
+		if err != nil {
+			result.Status = StatusFailed
+			result.Error = err.Error()
+		} else {
+			result.Status = StatusStopped
+		}
+	case <-stopCtx.Done():
+		result.Duration = time.Since(start)
+		result.Status = StatusTimedOut
+		result.Error = fmt.Sprintf("超出停止时限 %s", budget)
+		kill(p)
+	}
+	return result
+}
+
+// kill 强制终止插件
+func kill(p Plugin) {
+	if p.Kill != nil {
+		p.Kill()
+	}
+}
@@ -0,0 +1,178 @@
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder 记录插件的停止和强制终止顺序
+type recorder struct {
+	mu      sync.Mutex
+	stopped []string
+	killed  []string
+}
+
+func (r *recorder) plugin(id string, delay time.Duration, deps ...string) Plugin {
+	return Plugin{
+		ID:           id,
+		Dependencies: deps,
+		Timeout:      50 * time.Millisecond,
+		Stop: func(ctx context.Context) error {
+			if delay > 0 {
+				// 模拟不响应取消的插件，例如阻塞在驱动调用中
+				time.Sleep(delay)
+			}
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.stopped = append(r.stopped, id)
+			return nil
+		},
+		Kill: func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.killed = append(r.killed, id)
+		},
+	}
+}
+
+func (r *recorder) snapshot() ([]string, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.stopped...), append([]string(nil), r.killed...)
+}
+
+// TestOrderReverseDependencies 测试依赖其他插件的插件先停止
+func TestOrderReverseDependencies(t *testing.T) {
+	r := &recorder{}
+	order, err := Order([]Plugin{
+		r.plugin("comm", 0),
+		r.plugin("dlp", 0, "comm", "audit"),
+		r.plugin("audit", 0, "comm"),
+		r.plugin("assets", 0, "missing"),
+	})
+	if err != nil {
+		t.Fatalf("计算停止顺序失败: %v", err)
+	}
+
+	want := []string{"assets", "dlp", "audit", "comm"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("停止顺序不正确，期望 %v，实际 %v", want, order)
+	}
+}
+
+// TestOrderCycle 测试循环依赖返回错误，Run 按ID顺序停止
+func TestOrderCycle(t *testing.T) {
+	r := &recorder{}
+	plugins := []Plugin{r.plugin("b", 0, "a"), r.plugin("a", 0, "b"), r.plugin("c", 0)}
+
+	if _, err := Order(plugins); err == nil || !strings.Contains(err.Error(), "a, b") {
+		t.Errorf("期望循环依赖错误，实际 %v", err)
+	}
+
+	report := Run(context.Background(), plugins)
+	if report.OrderError == "" {
+		t.Error("报告应记录排序错误")
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(report.Order, want) {
+		t.Errorf("循环依赖时应按ID顺序停止，期望 %v，实际 %v", want, report.Order)
+	}
+}
+
+// TestRunSlowPlugin 测试停止缓慢的插件被强制终止，其余插件继续按顺序停止
+func TestRunSlowPlugin(t *testing.T) {
+	r := &recorder{}
+	plugins := []Plugin{
+		r.plugin("comm", 0),
+		r.plugin("dlp", time.Second, "comm"),
+		r.plugin("assets", 0, "comm"),
+	}
+
+	start := time.Now()
+	report := Run(context.Background(), plugins)
+	elapsed := time.Since(start)
+
+	// 整体耗时不超过各插件时限之和
+	if budget := 3 * 50 * time.Millisecond; elapsed > budget+100*time.Millisecond {
+		t.Errorf("关闭耗时 %s 超出整体时限 %s", elapsed, budget)
+	}
+
+	if want := []string{"assets", "dlp", "comm"}; !reflect.DeepEqual(report.Order, want) {
+		t.Errorf("停止顺序不正确，期望 %v，实际 %v", want, report.Order)
+	}
+
+	offenders := report.Offenders()
+	if len(offenders) != 1 || offenders[0].Plugin != "dlp" || offenders[0].Status != StatusTimedOut {
+		t.Fatalf("期望 dlp 超出停止时限，实际 %+v", offenders)
+	}
+	if offenders[0].Budget != 50*time.Millisecond {
+		t.Errorf("报告中的停止时限不正确: %s", offenders[0].Budget)
+	}
+	if !strings.Contains(report.String(), "[TIMED_OUT] dlp") {
+		t.Errorf("可读报告应列出超时插件:\n%s", report.String())
+	}
+
+	stopped, killed := r.snapshot()
+	if want := []string{"assets", "comm"}; !reflect.DeepEqual(stopped, want) {
+		t.Errorf("期望 %v 正常停止，实际 %v", want, stopped)
+	}
+	if want := []string{"dlp"}; !reflect.DeepEqual(killed, want) {
+		t.Errorf("期望强制终止 %v，实际 %v", want, killed)
+	}
+}
+
+// TestRunAggregateDeadline 测试整体时限耗尽后其余插件直接强制终止
+func TestRunAggregateDeadline(t *testing.T) {
+	r := &recorder{}
+	slow := r.plugin("dlp", time.Second, "comm")
+	slow.Timeout = time.Second
+	plugins := []Plugin{slow, r.plugin("comm", 0)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 80*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	report := Run(ctx, plugins)
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("整体时限耗尽后应立即返回，实际耗时 %s", elapsed)
+	}
+
+	if len(report.Results) != 2 {
+		t.Fatalf("期望 2 个结果，实际 %+v", report.Results)
+	}
+	if report.Results[0].Status != StatusTimedOut || report.Results[1].Status != StatusSkipped {
+		t.Errorf("期望 dlp 超时、comm 跳过，实际 %+v", report.Results)
+	}
+
+	_, killed := r.snapshot()
+	if want := []string{"dlp", "comm"}; !reflect.DeepEqual(killed, want) {
+		t.Errorf("期望强制终止 %v，实际 %v", want, killed)
+	}
+}
+
+// TestRunStopError 测试停止函数返回错误或 panic 时记录失败并继续
+func TestRunStopError(t *testing.T) {
+	report := Run(context.Background(), []Plugin{
+		{ID: "a", Stop: func(ctx context.Context) error { return errors.New("设备忙") }},
+		{ID: "b", Stop: func(ctx context.Context) error { panic("驱动异常") }},
+		{ID: "c", Stop: func(ctx context.Context) error { return nil }},
+	})
+
+	statuses := make([]Status, 0, len(report.Results))
+	for _, result := range report.Results {
+		statuses = append(statuses, result.Status)
+	}
+	if want := []Status{StatusFailed, StatusFailed, StatusStopped}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("期望状态 %v，实际 %v", want, statuses)
+	}
+	if report.Results[0].Budget != DefaultTimeout {
+		t.Errorf("未设置时限时应使用默认值，实际 %s", report.Results[0].Budget)
+	}
+	if len(report.Offenders()) != 0 {
+		t.Errorf("停止失败不应计为超时: %+v", report.Offenders())
+	}
+}
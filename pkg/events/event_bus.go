@@ -0,0 +1,257 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// 系统内置的事件类型
+const (
+	// EventTypeSystemStartup 系统启动事件
+	EventTypeSystemStartup = "system.startup"
+
+	// EventTypeSystemShutdown 系统关闭事件
+	EventTypeSystemShutdown = "system.shutdown"
+
+	// EventTypeConfigChanged 配置变更事件，Data 中的 changed_keys 为变更的顶层配置项
+	EventTypeConfigChanged = "config.changed"
+
+	// EventTypeSecurityAlert 安全告警事件
+	EventTypeSecurityAlert = "security.alert"
+)
+
+// defaultSubscriberBufferSize 每个订阅者默认的事件队列长度
+const defaultSubscriberBufferSize = 100
+
+// SubscriberStats 订阅者统计信息
+type SubscriberStats struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	EventTypes []string `json:"event_types"`
+	Queued     int      `json:"queued"`
+	Delivered  uint64   `json:"delivered"`
+	Dropped    uint64   `json:"dropped"`
+	Failed     uint64   `json:"failed"`
+}
+
+// subscriber 事件总线订阅者，每个订阅者拥有独立的事件队列和处理协程
+type subscriber struct {
+	id         string
+	name       string
+	eventTypes map[string]bool
+	handler    EventHandler
+	queue      chan Event
+	delivered  atomic.Uint64
+	dropped    atomic.Uint64
+	failed     atomic.Uint64
+}
+
+// accepts 检查订阅者是否订阅了指定类型的事件，未指定类型时订阅所有事件
+func (s *subscriber) accepts(eventType string) bool {
+	return len(s.eventTypes) == 0 || s.eventTypes[eventType]
+}
+
+// EventBus 类型化事件总线
+// 订阅者按事件类型订阅，各自在独立的协程中处理事件；
+// 订阅者队列已满时丢弃该订阅者的新事件，慢订阅者不会阻塞发布者和其他订阅者
+type EventBus struct {
+	logger      logging.Logger
+	bufferSize  int
+	subscribers map[string]*subscriber
+	mutex       sync.RWMutex
+	closed      bool
+}
+
+// EventBusOption 事件总线选项
+type EventBusOption func(*EventBus)
+
+// WithSubscriberBufferSize 设置每个订阅者的事件队列长度
+func WithSubscriberBufferSize(size int) EventBusOption {
+	return func(b *EventBus) {
+		if size > 0 {
+			b.bufferSize = size
+		}
+	}
+}
+
+// NewEventBus 创建一个新的事件总线
+func NewEventBus(log logging.Logger, options ...EventBusOption) *EventBus {
+	if log == nil {
+		// 创建默认日志记录器
+		enhancedLogger, err := logging.NewEnhancedLogger(logging.DefaultLogConfig())
+		if err != nil {
+			enhancedLogger, _ = logging.NewEnhancedLogger(nil)
+		}
+		log = enhancedLogger.Named("event-bus")
+	}
+
+	bus := &EventBus{
+		logger:      log,
+		bufferSize:  defaultSubscriberBufferSize,
+		subscribers: make(map[string]*subscriber),
+	}
+
+	for _, option := range options {
+		option(bus)
+	}
+
+	return bus
+}
+
+// Subscribe 订阅事件，eventTypes 为空时订阅所有类型的事件，返回订阅ID
+func (b *EventBus) Subscribe(name string, handler EventHandler, eventTypes ...string) (string, error) {
+	if handler == nil {
+		return "", fmt.Errorf("事件处理函数不能为空")
+	}
+
+	sub := &subscriber{
+		id:         uuid.New().String(),
+		name:       name,
+		eventTypes: make(map[string]bool, len(eventTypes)),
+		handler:    handler,
+		queue:      make(chan Event, b.bufferSize),
+	}
+	for _, eventType := range eventTypes {
+		sub.eventTypes[eventType] = true
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return "", fmt.Errorf("事件总线已关闭")
+	}
+
+	b.subscribers[sub.id] = sub
+	go b.run(sub)
+
+	b.logger.Info("订阅事件", "subscriber", name, "id", sub.id, "types", eventTypes)
+	return sub.id, nil
+}
+
+// Unsubscribe 取消订阅，队列中尚未处理的事件仍会被处理
+func (b *EventBus) Unsubscribe(id string) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	sub, ok := b.subscribers[id]
+	if !ok {
+		return fmt.Errorf("未找到订阅: %s", id)
+	}
+
+	delete(b.subscribers, id)
+	close(sub.queue)
+
+	b.logger.Info("取消订阅事件", "subscriber", sub.name, "id", id)
+	return nil
+}
+
+// Publish 发布事件，事件被放入所有匹配订阅者的队列后立即返回
+func (b *EventBus) Publish(event Event) error {
+	if event.Type == "" {
+		return fmt.Errorf("事件类型不能为空")
+	}
+
+	// 设置事件ID和时间戳
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.closed {
+		return fmt.Errorf("事件总线已关闭")
+	}
+
+	for _, sub := range b.subscribers {
+		if !sub.accepts(event.Type) {
+			continue
+		}
+
+		select {
+		case sub.queue <- event:
+		default:
+			// 订阅者处理过慢，丢弃事件以免阻塞发布者
+			sub.dropped.Add(1)
+			b.logger.Warn("订阅者事件队列已满，丢弃事件", "subscriber", sub.name, "type", event.Type, "id", event.ID)
+		}
+	}
+
+	b.logger.Debug("发布事件", "type", event.Type, "id", event.ID, "source", event.Source)
+	return nil
+}
+
+// Stats 获取所有订阅者的统计信息
+func (b *EventBus) Stats() []SubscriberStats {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	stats := make([]SubscriberStats, 0, len(b.subscribers))
+	for _, sub := range b.subscribers {
+		eventTypes := make([]string, 0, len(sub.eventTypes))
+		for eventType := range sub.eventTypes {
+			eventTypes = append(eventTypes, eventType)
+		}
+
+		stats = append(stats, SubscriberStats{
+			ID:         sub.id,
+			Name:       sub.name,
+			EventTypes: eventTypes,
+			Queued:     len(sub.queue),
+			Delivered:  sub.delivered.Load(),
+			Dropped:    sub.dropped.Load(),
+			Failed:     sub.failed.Load(),
+		})
+	}
+
+	return stats
+}
+
+// Close 关闭事件总线，取消所有订阅
+func (b *EventBus) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+
+	for id, sub := range b.subscribers {
+		delete(b.subscribers, id)
+		close(sub.queue)
+	}
+
+	b.logger.Info("事件总线已关闭")
+}
+
+// run 处理订阅者队列中的事件，直到队列关闭
+func (b *EventBus) run(sub *subscriber) {
+	for event := range sub.queue {
+		if err := b.dispatch(sub, event); err != nil {
+			sub.failed.Add(1)
+			b.logger.Error("事件处理失败", "subscriber", sub.name, "type", event.Type, "id", event.ID, "error", err)
+			continue
+		}
+		sub.delivered.Add(1)
+	}
+}
+
+// dispatch 调用订阅者的处理函数，处理函数的 panic 不会影响其他订阅者
+func (b *EventBus) dispatch(sub *subscriber, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("事件处理函数发生panic: %v", r)
+		}
+	}()
+	return sub.handler(event)
+}
@@ -0,0 +1,188 @@
+package events
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// collect 创建把事件写入通道的处理函数
+func collect(ch chan Event) EventHandler {
+	return func(event Event) error {
+		ch <- event
+		return nil
+	}
+}
+
+// waitEvent 等待通道中的下一个事件
+func waitEvent(t *testing.T, ch chan Event, name string) Event {
+	t.Helper()
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(time.Second):
+		t.Fatalf("超时等待订阅者 %s 收到事件", name)
+		return Event{}
+	}
+}
+
+// TestEventBusFanOut 测试事件发布到所有订阅者
+func TestEventBusFanOut(t *testing.T) {
+	bus := NewEventBus(nil)
+	defer bus.Close()
+
+	channels := make([]chan Event, 3)
+	for i := range channels {
+		channels[i] = make(chan Event, 1)
+		if _, err := bus.Subscribe("subscriber", collect(channels[i]), EventTypeSystemStartup); err != nil {
+			t.Fatalf("订阅事件失败: %v", err)
+		}
+	}
+
+	if err := bus.Publish(Event{Type: EventTypeSystemStartup, Source: "app"}); err != nil {
+		t.Fatalf("发布事件失败: %v", err)
+	}
+
+	var id string
+	for i, ch := range channels {
+		event := waitEvent(t, ch, "subscriber")
+		if event.Type != EventTypeSystemStartup || event.Source != "app" {
+			t.Errorf("订阅者 %d 收到了错误的事件: %+v", i, event)
+		}
+		if event.ID == "" || event.Timestamp.IsZero() {
+			t.Errorf("事件ID和时间戳应该自动填充: %+v", event)
+		}
+		if id != "" && event.ID != id {
+			t.Errorf("所有订阅者应该收到同一事件")
+		}
+		id = event.ID
+	}
+}
+
+// TestEventBusTypedFiltering 测试订阅者只收到订阅类型的事件
+func TestEventBusTypedFiltering(t *testing.T) {
+	bus := NewEventBus(nil)
+	defer bus.Close()
+
+	configEvents := make(chan Event, 10)
+	alertEvents := make(chan Event, 10)
+	allEvents := make(chan Event, 10)
+	bus.Subscribe("config", collect(configEvents), EventTypeConfigChanged)
+	bus.Subscribe("alert", collect(alertEvents), EventTypeSecurityAlert, EventTypeSystemShutdown)
+	bus.Subscribe("all", collect(allEvents))
+
+	for _, eventType := range []string{EventTypeSystemStartup, EventTypeConfigChanged, EventTypeSecurityAlert} {
+		if err := bus.Publish(Event{Type: eventType}); err != nil {
+			t.Fatalf("发布事件失败: %v", err)
+		}
+	}
+
+	if event := waitEvent(t, configEvents, "config"); event.Type != EventTypeConfigChanged {
+		t.Errorf("期望收到 %s 事件，但收到了 %s", EventTypeConfigChanged, event.Type)
+	}
+	if event := waitEvent(t, alertEvents, "alert"); event.Type != EventTypeSecurityAlert {
+		t.Errorf("期望收到 %s 事件，但收到了 %s", EventTypeSecurityAlert, event.Type)
+	}
+	for _, eventType := range []string{EventTypeSystemStartup, EventTypeConfigChanged, EventTypeSecurityAlert} {
+		if event := waitEvent(t, allEvents, "all"); event.Type != eventType {
+			t.Errorf("期望按发布顺序收到 %s 事件，但收到了 %s", eventType, event.Type)
+		}
+	}
+
+	// 不应收到其他类型的事件
+	time.Sleep(50 * time.Millisecond)
+	if len(configEvents) != 0 || len(alertEvents) != 0 {
+		t.Errorf("订阅者收到了未订阅类型的事件")
+	}
+
+	if err := bus.Publish(Event{}); err == nil {
+		t.Error("发布没有类型的事件应该返回错误")
+	}
+}
+
+// TestEventBusSlowSubscriberIsolation 测试慢订阅者不会阻塞发布者和其他订阅者
+func TestEventBusSlowSubscriberIsolation(t *testing.T) {
+	bus := NewEventBus(nil, WithSubscriberBufferSize(2))
+	defer bus.Close()
+
+	block := make(chan struct{})
+	defer close(block)
+	bus.Subscribe("slow", func(event Event) error {
+		<-block
+		return nil
+	}, EventTypeSecurityAlert)
+
+	var panics atomic.Int32
+	bus.Subscribe("panicking", func(event Event) error {
+		panics.Add(1)
+		panic("处理失败")
+	}, EventTypeSecurityAlert)
+
+	fast := make(chan Event, 10)
+	bus.Subscribe("fast", collect(fast), EventTypeSecurityAlert)
+
+	// 发布的事件数超过慢订阅者的队列长度，发布不应阻塞，快订阅者依次收到所有事件
+	for i := 0; i < 10; i++ {
+		start := time.Now()
+		if err := bus.Publish(Event{Type: EventTypeSecurityAlert, Data: map[string]interface{}{"index": i}}); err != nil {
+			t.Fatalf("发布事件失败: %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Fatalf("慢订阅者阻塞了事件发布，耗时 %v", elapsed)
+		}
+		if event := waitEvent(t, fast, "fast"); event.Data["index"] != i {
+			t.Errorf("快订阅者应该按顺序收到第 %d 个事件，但收到了 %v", i, event.Data["index"])
+		}
+	}
+
+	stats := make(map[string]SubscriberStats)
+	for _, s := range bus.Stats() {
+		stats[s.Name] = s
+	}
+	if stats["slow"].Dropped == 0 {
+		t.Error("慢订阅者队列满后应该丢弃事件")
+	}
+	if stats["fast"].Dropped != 0 {
+		t.Errorf("快订阅者不应丢弃事件: %+v", stats["fast"])
+	}
+
+	// 处理函数panic不影响后续事件的处理
+	deadline := time.Now().Add(time.Second)
+	for panics.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if count := panics.Load(); count < 2 {
+		t.Errorf("发生panic的订阅者应该继续处理事件，已处理 %d 个", count)
+	}
+}
+
+// TestEventBusUnsubscribe 测试取消订阅和关闭
+func TestEventBusUnsubscribe(t *testing.T) {
+	bus := NewEventBus(nil)
+
+	ch := make(chan Event, 10)
+	id, err := bus.Subscribe("subscriber", collect(ch))
+	if err != nil {
+		t.Fatalf("订阅事件失败: %v", err)
+	}
+	if err := bus.Unsubscribe(id); err != nil {
+		t.Fatalf("取消订阅失败: %v", err)
+	}
+	if err := bus.Unsubscribe(id); err == nil {
+		t.Error("重复取消订阅应该返回错误")
+	}
+
+	bus.Publish(Event{Type: EventTypeSystemStartup})
+	time.Sleep(50 * time.Millisecond)
+	if len(ch) != 0 {
+		t.Error("取消订阅后不应收到事件")
+	}
+
+	bus.Close()
+	if err := bus.Publish(Event{Type: EventTypeSystemStartup}); err == nil {
+		t.Error("事件总线关闭后发布事件应该返回错误")
+	}
+	if _, err := bus.Subscribe("subscriber", collect(ch)); err == nil {
+		t.Error("事件总线关闭后订阅事件应该返回错误")
+	}
+}
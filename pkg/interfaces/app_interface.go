@@ -1,9 +1,11 @@
 package interfaces
 
 import (
+	"io"
 	"time"
 
 	"github.com/lomehong/kennel/pkg/comm"
+	"github.com/lomehong/kennel/pkg/core/pluginusage"
 )
 
 // AppInterface 定义应用程序接口，用于解耦core和webconsole包
@@ -26,6 +28,12 @@ type AppInterface interface {
 	// GetSystemMonitor 获取系统监控器接口
 	GetSystemMonitor() SystemMonitorInterface
 
+	// GetPluginUsage 获取各插件的资源占用，refresh 为 true 时立即重新采样
+	GetPluginUsage(refresh bool) pluginusage.Report
+
+	// WriteMetrics 以 Prometheus 文本格式输出全部子系统和插件的指标
+	WriteMetrics(w io.Writer) error
+
 	// GetStartTime 获取应用程序启动时间
 	GetStartTime() time.Time
 
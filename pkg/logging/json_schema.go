@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// JSONLogSchemaVersion JSON日志格式版本
+// 字段名称、类型或含义发生不兼容变化时递增，日志采集方据此适配
+const JSONLogSchemaVersion = 1
+
+// JSONLogEntry JSON格式的日志条目
+// 字段按声明顺序输出，每条日志都包含全部字段；
+// Fields 中的键按字母顺序输出，嵌套的映射同样按键排序，保证相同内容序列化结果一致
+type JSONLogEntry struct {
+	SchemaVersion int                    `json:"schema_version"` // 格式版本
+	Timestamp     string                 `json:"timestamp"`      // 时间戳，按 LogConfig.TimeFormat 格式化，未启用时为空
+	Level         string                 `json:"level"`          // 日志级别，小写
+	Logger        string                 `json:"logger"`         // 日志记录器名称，子记录器以点号分隔
+	Msg           string                 `json:"msg"`            // 日志消息
+	Caller        string                 `json:"caller"`         // 调用位置（文件:行号），未启用时为空
+	Fields        map[string]interface{} `json:"fields"`         // 结构化字段，没有字段时为空对象
+}
+
+// encodeJSONLogEntry 将日志条目编码为一行JSON
+func encodeJSONLogEntry(entry *JSONLogEntry) ([]byte, error) {
+	fields := make(map[string]interface{}, len(entry.Fields))
+	for key, value := range entry.Fields {
+		fields[key] = normalizeLogValue(value)
+	}
+	entry.Fields = fields
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(entry); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// normalizeLogValue 将字段值转换为可稳定序列化的形式
+// 错误记录为错误消息，时长记录为字符串，无法序列化为JSON的值使用 %v 格式化
+func normalizeLogValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	case json.Marshaler:
+		return v
+	case fmt.Stringer:
+		if _, err := json.Marshal(v); err != nil {
+			return v.String()
+		}
+		return v
+	}
+
+	if _, err := json.Marshal(value); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return value
+}
+
+// logArgsToFields 将键值对参数转换为字段
+// 非字符串键使用 %v 格式化，末尾缺少值的键记录在 extra_value 中
+func logArgsToFields(fields map[string]interface{}, args []interface{}) {
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			fields["extra_value"] = args[i]
+			break
+		}
+		key, ok := args[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", args[i])
+		}
+		fields[key] = args[i+1]
+	}
+}
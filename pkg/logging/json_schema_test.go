@@ -0,0 +1,120 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newJSONTestLogger 创建输出到缓冲区的JSON日志记录器
+func newJSONTestLogger(t *testing.T) (*EnhancedLogger, *bytes.Buffer) {
+	config := DefaultLogConfig()
+	config.Level = LogLevelDebug
+
+	logger, err := NewEnhancedLogger(config)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	logger.writer = &buf
+	return logger, &buf
+}
+
+// jsonKeys 按出现顺序返回JSON对象的顶层键
+func jsonKeys(t *testing.T, line []byte) []string {
+	decoder := json.NewDecoder(bytes.NewReader(line))
+	token, err := decoder.Token()
+	require.NoError(t, err)
+	require.Equal(t, json.Delim('{'), token)
+
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		require.NoError(t, err)
+		keys = append(keys, token.(string))
+
+		var value json.RawMessage
+		require.NoError(t, decoder.Decode(&value))
+	}
+	return keys
+}
+
+func TestJSONLog_Schema(t *testing.T) {
+	logger, buf := newJSONTestLogger(t)
+
+	logger.Named("dlp").WithField("request_id", "req-1").
+		Info("检测到敏感数据", "count", 3, "error", errors.New("超时"), "elapsed", 1500*time.Millisecond)
+
+	line := buf.Bytes()
+	assert.Equal(t, []string{"schema_version", "timestamp", "level", "logger", "msg", "caller", "fields"}, jsonKeys(t, line))
+
+	var entry JSONLogEntry
+	require.NoError(t, json.Unmarshal(line, &entry))
+	assert.Equal(t, JSONLogSchemaVersion, entry.SchemaVersion)
+	assert.Equal(t, "info", entry.Level)
+	assert.Equal(t, "app.dlp", entry.Logger)
+	assert.Equal(t, "检测到敏感数据", entry.Msg)
+	assert.Contains(t, entry.Caller, "json_schema_test.go:")
+	_, err := time.Parse(time.RFC3339, entry.Timestamp)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"request_id": "req-1",
+		"count":      float64(3),
+		"error":      "超时",
+		"elapsed":    "1.5s",
+	}, entry.Fields)
+}
+
+func TestJSONLog_StableAcrossCalls(t *testing.T) {
+	logger, buf := newJSONTestLogger(t)
+	logger.config.IncludeTimestamp = false
+
+	nested := map[string]interface{}{
+		"zeta":  map[string]interface{}{"b": 2, "a": 1},
+		"alpha": []string{"x", "y"},
+	}
+
+	var lines []string
+	for i := 0; i < 20; i++ {
+		buf.Reset()
+		logger.WithFields(map[string]interface{}{"user": "alice", "host": "pc-01"}).
+			Warn("重复日志", "nested", nested, "b", true, "a", 1.5)
+		lines = append(lines, buf.String())
+	}
+	for _, line := range lines[1:] {
+		assert.Equal(t, lines[0], line, "相同内容的日志序列化结果应完全一致")
+	}
+
+	// 字段与嵌套映射按键排序
+	fields := lines[0][strings.Index(lines[0], `"fields":`):]
+	assert.Equal(t, `"fields":{"a":1.5,"b":true,"host":"pc-01","nested":{"alpha":["x","y"],"zeta":{"a":1,"b":2}},"user":"alice"}}`+"\n", fields)
+
+	// 没有字段时也输出空对象，禁用的时间戳和调用位置保留为空字符串
+	buf.Reset()
+	logger.config.IncludeLocation = false
+	logger.Error("无字段")
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]interface{}{}, entry["fields"])
+	assert.Equal(t, "", entry["timestamp"])
+	assert.Equal(t, "", entry["caller"])
+}
+
+func TestJSONLog_UnencodableFieldsAndOddArgs(t *testing.T) {
+	logger, buf := newJSONTestLogger(t)
+
+	logger.Info("异常字段", "callback", func() {}, 42, "数字键", "dangling")
+
+	var entry JSONLogEntry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry), "无法序列化的字段不应导致整条日志丢失")
+	assert.IsType(t, "", entry.Fields["callback"])
+	assert.Equal(t, "数字键", entry.Fields["42"])
+	assert.Equal(t, "dangling", entry.Fields["extra_value"])
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"), "每条日志只输出一行")
+}
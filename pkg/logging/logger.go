@@ -131,6 +131,7 @@ type EnhancedLogger struct {
 	config     *LogConfig
 	writer     io.Writer
 	rotator    *LogRotator
+	name       string
 	fields     map[string]interface{}
 	mu         sync.RWMutex
 }
@@ -183,6 +184,7 @@ func NewEnhancedLogger(config *LogConfig) (*EnhancedLogger, error) {
 		config:     config,
 		writer:     writer,
 		rotator:    rotator,
+		name:       "app",
 		fields:     make(map[string]interface{}),
 	}
 
@@ -303,6 +305,12 @@ func (l *EnhancedLogger) log(level LogLevel, msg string, args ...interface{}) {
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
+	// JSON格式按固定格式输出，便于日志采集
+	if l.config.Format == LogFormatJSON {
+		l.writeJSON(level, msg, args)
+		return
+	}
+
 	// 使用hclog记录日志
 	switch level {
 	case LogLevelTrace:
@@ -351,6 +359,66 @@ func (l *EnhancedLogger) log(level LogLevel, msg string, args ...interface{}) {
 	event.Msg(msg)
 }
 
+// writeJSON 按 JSONLogEntry 格式输出一条日志
+func (l *EnhancedLogger) writeJSON(level LogLevel, msg string, args []interface{}) {
+	if logLevelRank(level) < logLevelRank(l.config.Level) {
+		return
+	}
+
+	entry := &JSONLogEntry{
+		SchemaVersion: JSONLogSchemaVersion,
+		Level:         string(level),
+		Logger:        l.name,
+		Msg:           msg,
+		Fields:        make(map[string]interface{}, len(l.fields)+len(args)/2),
+	}
+	if l.config.IncludeTimestamp {
+		timeFormat := l.config.TimeFormat
+		if timeFormat == "" {
+			timeFormat = time.RFC3339
+		}
+		entry.Timestamp = time.Now().Format(timeFormat)
+	}
+	if l.config.IncludeLocation {
+		// 跳过 writeJSON、log 和级别方法
+		if _, file, line, ok := runtime.Caller(3); ok {
+			entry.Caller = fmt.Sprintf("%s:%d", file, line)
+		}
+	}
+
+	for k, v := range l.fields {
+		entry.Fields[k] = v
+	}
+	logArgsToFields(entry.Fields, args)
+
+	data, err := encodeJSONLogEntry(entry)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "序列化日志失败: %v\n", err)
+		return
+	}
+	l.writer.Write(data)
+}
+
+// logLevelRank 返回日志级别的严重程度，用于级别过滤
+func logLevelRank(level LogLevel) int {
+	switch level {
+	case LogLevelTrace:
+		return 0
+	case LogLevelDebug:
+		return 1
+	case LogLevelInfo:
+		return 2
+	case LogLevelWarn:
+		return 3
+	case LogLevelError:
+		return 4
+	case LogLevelFatal:
+		return 5
+	default:
+		return 2
+	}
+}
+
 // getZeroLogEvent 获取zerolog事件
 func (l *EnhancedLogger) getZeroLogEvent(level LogLevel) *zerolog.Event {
 	switch level {
@@ -426,6 +494,7 @@ func (l *EnhancedLogger) Named(name string) Logger {
 	newLogger := l.clone()
 
 	// 设置名称
+	newLogger.name = l.name + "." + name
 	newLogger.hcLogger = l.hcLogger.Named(name)
 	newZeroLogger := l.zeroLogger.With().Str("name", name).Logger()
 	newLogger.zeroLogger = &newZeroLogger
@@ -501,6 +570,7 @@ func (l *EnhancedLogger) clone() *EnhancedLogger {
 		config:     l.config,
 		writer:     l.writer,
 		rotator:    l.rotator,
+		name:       l.name,
 		fields:     fields,
 	}
 }
@@ -60,10 +60,11 @@ func TestEnhancedLogger(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 验证日志字段
-	assert.Equal(t, "这是一条调试日志", logEntry["message"])
+	logFields, _ := logEntry["fields"].(map[string]interface{})
+	assert.Equal(t, "这是一条调试日志", logEntry["msg"])
 	assert.Equal(t, "debug", logEntry["level"])
-	assert.Equal(t, "value", logEntry["key"])
-	assert.Contains(t, logEntry, "time")
+	assert.Equal(t, "value", logFields["key"])
+	assert.Contains(t, logEntry, "timestamp")
 }
 
 func TestEnhancedLoggerWithContext(t *testing.T) {
@@ -108,14 +109,15 @@ func TestEnhancedLoggerWithContext(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 验证日志字段
-	assert.Equal(t, "带上下文的日志", logEntry["message"])
+	logFields, _ := logEntry["fields"].(map[string]interface{})
+	assert.Equal(t, "带上下文的日志", logEntry["msg"])
 	assert.Equal(t, "info", logEntry["level"])
-	assert.Equal(t, "value", logEntry["key"])
-	assert.Equal(t, "req-123", logEntry["request_id"])
-	assert.Equal(t, "user-456", logEntry["user_id"])
-	assert.Equal(t, "session-789", logEntry["session_id"])
-	assert.Equal(t, "trace-abc", logEntry["trace_id"])
-	assert.Equal(t, "span-def", logEntry["span_id"])
+	assert.Equal(t, "value", logFields["key"])
+	assert.Equal(t, "req-123", logFields["request_id"])
+	assert.Equal(t, "user-456", logFields["user_id"])
+	assert.Equal(t, "session-789", logFields["session_id"])
+	assert.Equal(t, "trace-abc", logFields["trace_id"])
+	assert.Equal(t, "span-def", logFields["span_id"])
 }
 
 func TestEnhancedLoggerWithFields(t *testing.T) {
@@ -153,10 +155,11 @@ func TestEnhancedLoggerWithFields(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 验证日志字段
-	assert.Equal(t, "带字段的日志", logEntry["message"])
+	logFields, _ := logEntry["fields"].(map[string]interface{})
+	assert.Equal(t, "带字段的日志", logEntry["msg"])
 	assert.Equal(t, "info", logEntry["level"])
-	assert.Equal(t, "test", logEntry["component"])
-	assert.Equal(t, "1.0.0", logEntry["version"])
+	assert.Equal(t, "test", logFields["component"])
+	assert.Equal(t, "1.0.0", logFields["version"])
 }
 
 func TestEnhancedLoggerWithMultipleFields(t *testing.T) {
@@ -199,12 +202,13 @@ func TestEnhancedLoggerWithMultipleFields(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 验证日志字段
-	assert.Equal(t, "带多个字段的日志", logEntry["message"])
+	logFields, _ := logEntry["fields"].(map[string]interface{})
+	assert.Equal(t, "带多个字段的日志", logEntry["msg"])
 	assert.Equal(t, "info", logEntry["level"])
-	assert.Equal(t, "test", logEntry["component"])
-	assert.Equal(t, "1.0.0", logEntry["version"])
-	assert.Equal(t, true, logEntry["enabled"])
-	assert.Equal(t, float64(42), logEntry["count"])
+	assert.Equal(t, "test", logFields["component"])
+	assert.Equal(t, "1.0.0", logFields["version"])
+	assert.Equal(t, true, logFields["enabled"])
+	assert.Equal(t, float64(42), logFields["count"])
 }
 
 func TestEnhancedLoggerNamed(t *testing.T) {
@@ -241,9 +245,9 @@ func TestEnhancedLoggerNamed(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 验证日志字段
-	assert.Equal(t, "命名的日志", logEntry["message"])
+	assert.Equal(t, "命名的日志", logEntry["msg"])
 	assert.Equal(t, "info", logEntry["level"])
-	assert.Contains(t, logEntry["name"], "test")
+	assert.Equal(t, "app.test", logEntry["logger"])
 }
 
 func TestEnhancedLoggerSetLevel(t *testing.T) {
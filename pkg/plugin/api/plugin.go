@@ -18,7 +18,8 @@ type Plugin interface {
 	Init(ctx context.Context, config PluginConfig) error
 
 	// Start 启动插件
-	// ctx: 上下文，可用于超时控制和取消操作
+	// ctx: 插件运行期间的上下文，插件停止时会被取消；
+	// 插件在 Start 中启动的后台任务必须在 ctx 取消后退出
 	// 返回: 启动过程中的错误
 	Start(ctx context.Context) error
 
@@ -117,6 +118,23 @@ type PluginStatus struct {
 	Statistics map[string]interface{} // 统计信息
 }
 
+// PluginRuntimeInfo 定义了插件的运行时信息
+// 与静态的 PluginInfo 不同，运行时信息随插件生命周期变化，供宿主查询插件运行状况
+type PluginRuntimeInfo struct {
+	State         PluginState   // 当前状态
+	StartTime     time.Time     // 最近一次启动时间
+	Uptime        time.Duration // 自最近一次启动以来的运行时长，未运行时为0
+	RestartCount  int           // 首次启动之后的重启次数
+	LastError     string        // 最后一个错误信息
+	LastErrorTime time.Time     // 最后一个错误的发生时间
+}
+
+// RuntimeInfoProvider 由能够报告运行时信息的插件实现
+type RuntimeInfoProvider interface {
+	// GetRuntimeInfo 返回插件的运行时信息
+	GetRuntimeInfo() PluginRuntimeInfo
+}
+
 // PluginEvent 定义了插件事件
 type PluginEvent struct {
 	Type      string                 // 事件类型: loaded, unloaded, started, stopped, error
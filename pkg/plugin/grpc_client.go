@@ -3,14 +3,21 @@ package plugin
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
+	goplugin "github.com/hashicorp/go-plugin"
 	pb "github.com/lomehong/kennel/pkg/plugin/proto/gen"
 )
 
 // GRPCClient 是一个gRPC客户端适配器，用于将gRPC客户端转换为Module接口
 type GRPCClient struct {
 	client pb.ModuleClient
+
+	// 插件连接的代理，用于向插件提供主机服务
+	broker *goplugin.GRPCBroker
+	// 主机服务代理ID，未提供主机服务时为0
+	hostBrokerID atomic.Uint32
 }
 
 // Init 实现了Module接口的Init方法
@@ -22,7 +29,7 @@ func (c *GRPCClient) Init(config map[string]interface{}) error {
 	}
 
 	// 调用gRPC服务
-	resp, err := c.client.Init(context.Background(), &pb.InitRequest{
+	resp, err := c.client.Init(c.hostContext(context.Background()), &pb.InitRequest{
 		Config: configJSON,
 	})
 	if err != nil {
@@ -39,6 +46,11 @@ func (c *GRPCClient) Init(config map[string]interface{}) error {
 
 // Execute 实现了Module接口的Execute方法
 func (c *GRPCClient) Execute(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return c.ExecuteWithMetadata(action, params, nil)
+}
+
+// ExecuteWithMetadata 实现了MetadataExecutor接口，请求元数据通过gRPC元数据传递给插件
+func (c *GRPCClient) ExecuteWithMetadata(action string, params map[string]interface{}, metadata map[string]string) (map[string]interface{}, error) {
 	// 将参数转换为JSON
 	paramsJSON, err := ConfigToJSON(params)
 	if err != nil {
@@ -50,7 +62,7 @@ func (c *GRPCClient) Execute(action string, params map[string]interface{}) (map[
 	defer cancel()
 
 	// 调用gRPC服务
-	resp, err := c.client.Execute(ctx, &pb.ActionRequest{
+	resp, err := c.client.Execute(outgoingMetadataContext(c.hostContext(ctx), metadata), &pb.ActionRequest{
 		Action: action,
 		Params: paramsJSON,
 	})
@@ -89,7 +101,7 @@ func (c *GRPCClient) Shutdown() error {
 // GetInfo 实现了Module接口的GetInfo方法
 func (c *GRPCClient) GetInfo() ModuleInfo {
 	// 调用gRPC服务
-	resp, err := c.client.GetInfo(context.Background(), &pb.EmptyRequest{})
+	resp, err := c.client.GetInfo(c.hostContext(context.Background()), &pb.EmptyRequest{})
 	if err != nil {
 		// 如果出错，返回一个默认的ModuleInfo
 		return ModuleInfo{
@@ -121,7 +133,7 @@ func (c *GRPCClient) HandleMessage(messageType string, messageID string, timesta
 	defer cancel()
 
 	// 调用gRPC服务
-	resp, err := c.client.HandleMessage(ctx, &pb.MessageRequest{
+	resp, err := c.client.HandleMessage(c.hostContext(ctx), &pb.MessageRequest{
 		MessageType: messageType,
 		MessageId:   messageID,
 		Timestamp:   timestamp,
@@ -151,3 +163,44 @@ func (c *GRPCClient) HandleMessage(messageType string, messageID string, timesta
 
 	return result, nil
 }
+
+// GetMetrics 实现了Metricable接口，通过gRPC获取插件的运行指标
+// 获取失败时返回只包含 error 字段的指标，便于主机统一汇总
+func (c *GRPCClient) GetMetrics() map[string]interface{} {
+	metrics, err := c.FetchMetrics(context.Background())
+	if err != nil {
+		return map[string]interface{}{
+			"error": err.Error(),
+		}
+	}
+	return metrics
+}
+
+// FetchMetrics 通过gRPC获取插件的运行指标
+func (c *GRPCClient) FetchMetrics(ctx context.Context) (map[string]interface{}, error) {
+	// 添加超时控制，避免插件无响应时阻塞指标汇总
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// 调用gRPC服务
+	resp, err := c.client.GetMetrics(ctx, &pb.EmptyRequest{})
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("获取指标超时")
+		}
+		return nil, fmt.Errorf("gRPC调用失败: %w", err)
+	}
+
+	// 检查响应
+	if !resp.Success {
+		return nil, fmt.Errorf("获取指标失败: %s", resp.ErrorMessage)
+	}
+
+	// 将JSON指标转换为map
+	metrics, err := JSONToConfig(resp.Metrics)
+	if err != nil {
+		return nil, fmt.Errorf("解析指标失败: %w", err)
+	}
+
+	return metrics, nil
+}
@@ -12,10 +12,15 @@ import (
 type GRPCServer struct {
 	pb.UnimplementedModuleServer
 	Impl Module
+
+	// 主机服务客户端，为nil时不接收主机服务代理ID
+	host *Host
 }
 
 // Init 实现了gRPC服务的Init方法
 func (s *GRPCServer) Init(ctx context.Context, req *pb.InitRequest) (*pb.InitResponse, error) {
+	s.host.observe(ctx)
+
 	// 将JSON配置转换为map
 	config, err := JSONToConfig(req.Config)
 	if err != nil {
@@ -41,6 +46,8 @@ func (s *GRPCServer) Init(ctx context.Context, req *pb.InitRequest) (*pb.InitRes
 
 // Execute 实现了gRPC服务的Execute方法
 func (s *GRPCServer) Execute(ctx context.Context, req *pb.ActionRequest) (*pb.ActionResponse, error) {
+	s.host.observe(ctx)
+
 	// 添加超时控制，避免执行时间过长
 	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
@@ -60,8 +67,11 @@ func (s *GRPCServer) Execute(ctx context.Context, req *pb.ActionRequest) (*pb.Ac
 		err    error
 	}, 1)
 
+	// 主机传递的请求元数据
+	metadata := incomingRequestMetadata(ctx)
+
 	go func() {
-		result, err := s.Impl.Execute(req.Action, params)
+		result, err := ExecuteWithMetadata(s.Impl, req.Action, params, metadata)
 		resultCh <- struct {
 			result map[string]interface{}
 			err    error
@@ -130,6 +140,8 @@ func (s *GRPCServer) Shutdown(ctx context.Context, req *pb.EmptyRequest) (*pb.Em
 
 // GetInfo 实现了gRPC服务的GetInfo方法
 func (s *GRPCServer) GetInfo(ctx context.Context, req *pb.EmptyRequest) (*pb.ModuleInfo, error) {
+	s.host.observe(ctx)
+
 	// 调用实现
 	info := s.Impl.GetInfo()
 
@@ -143,6 +155,8 @@ func (s *GRPCServer) GetInfo(ctx context.Context, req *pb.EmptyRequest) (*pb.Mod
 
 // HandleMessage 实现了gRPC服务的HandleMessage方法
 func (s *GRPCServer) HandleMessage(ctx context.Context, req *pb.MessageRequest) (*pb.MessageResponse, error) {
+	s.host.observe(ctx)
+
 	// 添加超时控制，避免执行时间过长
 	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
@@ -200,3 +214,37 @@ func (s *GRPCServer) HandleMessage(ctx context.Context, req *pb.MessageRequest)
 		}, nil
 	}
 }
+
+// GetMetrics 实现了gRPC服务的GetMetrics方法
+// 模块未实现 Metricable 或未返回指标时，响应中 Success 为 false
+func (s *GRPCServer) GetMetrics(ctx context.Context, req *pb.EmptyRequest) (*pb.MetricsResponse, error) {
+	metricable, ok := s.Impl.(Metricable)
+	if !ok {
+		return &pb.MetricsResponse{
+			Success:      false,
+			ErrorMessage: "模块不支持指标",
+		}, nil
+	}
+
+	metrics := metricable.GetMetrics()
+	if metrics == nil {
+		return &pb.MetricsResponse{
+			Success:      false,
+			ErrorMessage: "模块不支持指标",
+		}, nil
+	}
+
+	// 将指标转换为JSON
+	metricsJSON, err := ConfigToJSON(metrics)
+	if err != nil {
+		return &pb.MetricsResponse{
+			Success:      false,
+			ErrorMessage: fmt.Sprintf("序列化指标失败: %v", err),
+		}, nil
+	}
+
+	return &pb.MetricsResponse{
+		Success: true,
+		Metrics: metricsJSON,
+	}, nil
+}
@@ -0,0 +1,213 @@
+package plugin
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	coreplugin "github.com/lomehong/kennel/pkg/core/plugin"
+	pb "github.com/lomehong/kennel/pkg/plugin/proto/gen"
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrHostUnavailable 主机尚未向插件提供主机服务
+var ErrHostUnavailable = errors.New("主机服务不可用")
+
+// HostConsumer 需要调用主机服务的模块实现此接口，插件服务启动时传入主机服务客户端
+type HostConsumer interface {
+	SetHost(host *Host)
+}
+
+// Host 插件侧的主机服务客户端
+// 主机在调用插件时通过gRPC元数据告知主机服务代理ID，客户端在首次调用时按需连接
+type Host struct {
+	broker *goplugin.GRPCBroker
+
+	mu       sync.Mutex
+	brokerID uint32
+	client   *GRPCClient
+}
+
+// newHost 创建主机服务客户端
+func newHost(broker *goplugin.GRPCBroker) *Host {
+	return &Host{broker: broker}
+}
+
+// observe 从主机调用的元数据中读取主机服务代理ID
+func (h *Host) observe(ctx context.Context) {
+	if h == nil {
+		return
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return
+	}
+	values := md.Get(hostBrokerMetadataKey)
+	if len(values) == 0 {
+		return
+	}
+	id, err := strconv.ParseUint(values[0], 10, 32)
+	if err != nil || id == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.brokerID != uint32(id) {
+		h.brokerID = uint32(id)
+		h.client = nil
+	}
+}
+
+// connect 返回到主机服务的客户端，必要时建立连接
+func (h *Host) connect() (*GRPCClient, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.client != nil {
+		return h.client, nil
+	}
+	if h.broker == nil || h.brokerID == 0 {
+		return nil, ErrHostUnavailable
+	}
+	conn, err := h.broker.Dial(h.brokerID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrHostUnavailable, err)
+	}
+	h.client = &GRPCClient{client: pb.NewModuleClient(conn)}
+	return h.client, nil
+}
+
+// hostErrors 经gRPC传输后需要还原的主机错误
+var hostErrors = []error{
+	coreplugin.ErrPermissionDenied,
+	coreplugin.ErrPluginUnavailable,
+}
+
+// Call 调用主机操作，action 为 HostAction* 常量
+// 主机以权限不足拒绝或目标插件不可用时，返回的错误分别包装 coreplugin.ErrPermissionDenied 和 coreplugin.ErrPluginUnavailable
+func (h *Host) Call(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	client, err := h.connect()
+	if err != nil {
+		return nil, err
+	}
+	result, err := client.Execute(action, params)
+	if err != nil {
+		for _, target := range hostErrors {
+			if strings.Contains(err.Error(), target.Error()) {
+				return nil, fmt.Errorf("%w: %v", target, err)
+			}
+		}
+	}
+	return result, err
+}
+
+// Services 返回通过主机提供的服务，调用受插件权限清单约束
+func (h *Host) Services() coreplugin.HostServices {
+	return coreplugin.HostServices{
+		Comm:    &hostCommClient{host: h},
+		Storage: &hostStorageClient{host: h},
+		Exec:    &hostExecClient{host: h},
+		Router:  &hostRouterClient{host: h},
+	}
+}
+
+// hostCommClient 通过主机发送数据，通讯服务没有返回值，失败时丢弃
+type hostCommClient struct {
+	host *Host
+}
+
+func (c *hostCommClient) SendData(dataType string, data interface{}) {
+	c.host.Call(HostActionSendData, map[string]interface{}{"type": dataType, "data": data})
+}
+
+func (c *hostCommClient) SendEvent(eventType string, details map[string]interface{}) {
+	c.host.Call(HostActionSendEvent, map[string]interface{}{"type": eventType, "details": details})
+}
+
+// hostStorageClient 通过主机读写存储
+type hostStorageClient struct {
+	host *Host
+}
+
+func (c *hostStorageClient) Get(key string) ([]byte, error) {
+	result, err := c.host.Call(HostActionStorageGet, map[string]interface{}{"key": key})
+	if err != nil {
+		return nil, err
+	}
+	return decodeBytesResult(result, "value")
+}
+
+func (c *hostStorageClient) Set(key string, value []byte) error {
+	_, err := c.host.Call(HostActionStorageSet, map[string]interface{}{
+		"key":   key,
+		"value": base64.StdEncoding.EncodeToString(value),
+	})
+	return err
+}
+
+func (c *hostStorageClient) Delete(key string) error {
+	_, err := c.host.Call(HostActionStorageDelete, map[string]interface{}{"key": key})
+	return err
+}
+
+// hostExecClient 通过主机执行命令
+type hostExecClient struct {
+	host *Host
+}
+
+func (c *hostExecClient) Execute(ctx context.Context, command string, args ...string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	list := make([]interface{}, len(args))
+	for i, arg := range args {
+		list[i] = arg
+	}
+	result, err := c.host.Call(HostActionExec, map[string]interface{}{"command": command, "args": list})
+	if err != nil {
+		return nil, err
+	}
+	return decodeBytesResult(result, "output")
+}
+
+// hostRouterClient 通过主机调用其他插件
+type hostRouterClient struct {
+	host *Host
+}
+
+func (c *hostRouterClient) CallPlugin(ctx context.Context, targetID string, req *coreplugin.Request) (*coreplugin.Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("请求不能为空")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result, err := c.host.Call(HostActionPluginCall, map[string]interface{}{"target": targetID, "request": req})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp coreplugin.Response
+	if err := convertParam(result["response"], &resp); err != nil {
+		return nil, fmt.Errorf("解析插件响应失败: %w", err)
+	}
+	return &resp, nil
+}
+
+// decodeBytesResult 解码主机返回的字节数据，[]byte 经JSON序列化后为base64字符串
+func decodeBytesResult(result map[string]interface{}, key string) ([]byte, error) {
+	encoded, _ := result[key].(string)
+	if encoded == "" {
+		return nil, nil
+	}
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("解析主机返回数据失败: %w", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,230 @@
+package plugin
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	coreplugin "github.com/lomehong/kennel/pkg/core/plugin"
+	pb "github.com/lomehong/kennel/pkg/plugin/proto/gen"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// 插件通过主机服务代理调用的操作
+const (
+	HostActionSendData      = "comm.send_data"
+	HostActionSendEvent     = "comm.send_event"
+	HostActionStorageGet    = "storage.get"
+	HostActionStorageSet    = "storage.set"
+	HostActionStorageDelete = "storage.delete"
+	HostActionExec          = "exec"
+	HostActionPluginCall    = "plugin.call"
+)
+
+// hostBrokerMetadataKey 主机在gRPC元数据中告知插件主机服务代理ID的键
+// 不使用请求元数据前缀，避免被当作请求元数据传给插件
+const hostBrokerMetadataKey = "kennel-host-broker"
+
+// hostExecTimeout 插件通过主机执行命令的超时时间
+const hostExecTimeout = 30 * time.Second
+
+// HostServicesServer 可向插件进程提供主机服务的插件客户端
+type HostServicesServer interface {
+	// ServeHostServices 在插件连接上提供主机服务，插件通过 Host 调用
+	// services 应已按插件的权限清单包装，permissions 为插件声明的权限
+	ServeHostServices(pluginID string, services coreplugin.HostServices, permissions coreplugin.PluginPermissions) error
+}
+
+// ServeHostServices 通过go-plugin代理在插件连接上提供主机服务
+// 服务复用 Module gRPC 接口，操作名为 HostAction* 常量；代理ID随之后的每次调用传给插件，
+// 并通过一次 GetInfo 调用立即告知插件，使插件无需等待主机请求即可使用主机服务
+func (c *GRPCClient) ServeHostServices(pluginID string, services coreplugin.HostServices, permissions coreplugin.PluginPermissions) error {
+	if c.broker == nil {
+		return fmt.Errorf("插件连接不支持主机服务")
+	}
+
+	host := newHostModule(pluginID, services, permissions)
+	id := c.broker.NextId()
+	go c.broker.AcceptAndServe(id, func(opts []grpc.ServerOption) *grpc.Server {
+		server := grpc.NewServer(opts...)
+		pb.RegisterModuleServer(server, &GRPCServer{Impl: host})
+		return server
+	})
+	c.hostBrokerID.Store(id)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := c.client.GetInfo(c.hostContext(ctx), &pb.EmptyRequest{}); err != nil {
+		return fmt.Errorf("告知插件主机服务失败: %w", err)
+	}
+	return nil
+}
+
+// hostContext 在调用上下文中附加主机服务代理ID
+func (c *GRPCClient) hostContext(ctx context.Context) context.Context {
+	id := c.hostBrokerID.Load()
+	if id == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, hostBrokerMetadataKey, strconv.FormatUint(uint64(id), 10))
+}
+
+// hostModule 主机服务，将插件的调用分发给按权限清单包装后的主机服务
+type hostModule struct {
+	pluginID    string
+	services    coreplugin.HostServices
+	permissions coreplugin.PluginPermissions
+}
+
+// newHostModule 创建提供给指定插件的主机服务，services 应已按插件的权限清单包装
+func newHostModule(pluginID string, services coreplugin.HostServices, permissions coreplugin.PluginPermissions) *hostModule {
+	return &hostModule{
+		pluginID:    pluginID,
+		services:    services,
+		permissions: permissions,
+	}
+}
+
+// Init 实现了Module接口
+func (h *hostModule) Init(config map[string]interface{}) error {
+	return nil
+}
+
+// Execute 执行插件请求的主机操作
+func (h *hostModule) Execute(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	switch action {
+	case HostActionSendData:
+		if h.services.Comm == nil {
+			return nil, h.unavailable("comm")
+		}
+		h.services.Comm.SendData(stringParam(params, "type"), params["data"])
+		return nil, h.checkComm(action)
+
+	case HostActionSendEvent:
+		if h.services.Comm == nil {
+			return nil, h.unavailable("comm")
+		}
+		details, _ := params["details"].(map[string]interface{})
+		h.services.Comm.SendEvent(stringParam(params, "type"), details)
+		return nil, h.checkComm(action)
+
+	case HostActionStorageGet:
+		if h.services.Storage == nil {
+			return nil, h.unavailable("storage")
+		}
+		value, err := h.services.Storage.Get(stringParam(params, "key"))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"value": value}, nil
+
+	case HostActionStorageSet:
+		if h.services.Storage == nil {
+			return nil, h.unavailable("storage")
+		}
+		value, err := base64.StdEncoding.DecodeString(stringParam(params, "value"))
+		if err != nil {
+			return nil, fmt.Errorf("存储数据编码无效: %w", err)
+		}
+		return nil, h.services.Storage.Set(stringParam(params, "key"), value)
+
+	case HostActionStorageDelete:
+		if h.services.Storage == nil {
+			return nil, h.unavailable("storage")
+		}
+		return nil, h.services.Storage.Delete(stringParam(params, "key"))
+
+	case HostActionExec:
+		if h.services.Exec == nil {
+			return nil, h.unavailable("exec")
+		}
+		var args []string
+		if list, ok := params["args"].([]interface{}); ok {
+			for _, arg := range list {
+				args = append(args, fmt.Sprint(arg))
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), hostExecTimeout)
+		defer cancel()
+		output, err := h.services.Exec.Execute(ctx, stringParam(params, "command"), args...)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"output": output}, nil
+
+	case HostActionPluginCall:
+		if h.services.Router == nil {
+			return nil, h.unavailable("router")
+		}
+		var req coreplugin.Request
+		if err := convertParam(params["request"], &req); err != nil {
+			return nil, fmt.Errorf("解析插件请求失败: %w", err)
+		}
+		resp, err := h.services.Router.CallPlugin(context.Background(), stringParam(params, "target"), &req)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"response": resp}, nil
+
+	default:
+		return nil, fmt.Errorf("不支持的主机操作: %s", action)
+	}
+}
+
+// checkComm 通讯服务没有返回值，被拒绝的调用由包装后的服务记录，这里向插件返回拒绝错误
+func (h *hostModule) checkComm(action string) error {
+	if h.permissions.Allows(coreplugin.PermissionComm) {
+		return nil
+	}
+	return fmt.Errorf("%w: 插件 %s 未声明 %s 权限 (%s)", coreplugin.ErrPermissionDenied, h.pluginID, coreplugin.PermissionComm, action)
+}
+
+// unavailable 主机未提供服务时返回的错误
+func (h *hostModule) unavailable(service string) error {
+	return fmt.Errorf("主机未提供 %s 服务", service)
+}
+
+// Shutdown 实现了Module接口
+func (h *hostModule) Shutdown() error {
+	return nil
+}
+
+// GetInfo 实现了Module接口
+func (h *hostModule) GetInfo() ModuleInfo {
+	return ModuleInfo{
+		Name:        "host",
+		Description: "主机服务",
+		SupportedActions: []string{
+			HostActionSendData, HostActionSendEvent,
+			HostActionStorageGet, HostActionStorageSet, HostActionStorageDelete,
+			HostActionExec, HostActionPluginCall,
+		},
+	}
+}
+
+// HandleMessage 实现了Module接口，主机服务不接收消息
+func (h *hostModule) HandleMessage(messageType string, messageID string, timestamp int64, payload map[string]interface{}) (map[string]interface{}, error) {
+	return nil, fmt.Errorf("主机服务不支持消息: %s", messageType)
+}
+
+// convertParam 将经JSON传输的参数转换为结构体
+func convertParam(value interface{}, target interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// stringParam 读取字符串参数
+func stringParam(params map[string]interface{}, key string) string {
+	value, _ := params[key].(string)
+	return value
+}
+
+// 确保 GRPCClient 可以提供主机服务
+var _ HostServicesServer = (*GRPCClient)(nil)
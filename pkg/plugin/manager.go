@@ -13,6 +13,7 @@ import (
 	"github.com/hashicorp/go-plugin"
 	goplugin "github.com/hashicorp/go-plugin"
 	"github.com/lomehong/kennel/pkg/concurrency"
+	coreplugin "github.com/lomehong/kennel/pkg/core/plugin"
 	"github.com/lomehong/kennel/pkg/errors"
 	"github.com/lomehong/kennel/pkg/resource"
 )
@@ -33,6 +34,9 @@ type PluginManager struct {
 	mu                  sync.RWMutex
 	healthCheckInterval time.Duration
 	idleTimeout         time.Duration
+	hostServices        coreplugin.HostServices
+	trustStore          *coreplugin.TrustStore
+	signatureMode       coreplugin.SignatureMode
 }
 
 // ManagedPlugin 受管理的插件
@@ -49,6 +53,11 @@ type ManagedPlugin struct {
 	LastError error
 	StartTime time.Time
 	StopTime  time.Time
+
+	// 插件清单，插件未提供清单时为nil
+	Manifest *coreplugin.PluginMetadata
+	// 按插件清单约束主机服务调用的权限守卫
+	Guard *coreplugin.PermissionGuard
 }
 
 // PluginConfig 插件配置
@@ -137,6 +146,14 @@ func WithIdleTimeout(timeout time.Duration) PluginManagerOption {
 	}
 }
 
+// WithHostServices 设置向插件提供的主机服务
+// 每个插件得到按其清单中声明的权限包装后的服务
+func WithHostServices(services coreplugin.HostServices) PluginManagerOption {
+	return func(pm *PluginManager) {
+		pm.hostServices = services
+	}
+}
+
 // NewPluginManager 创建一个新的插件管理器
 func NewPluginManager(options ...PluginManagerOption) *PluginManager {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -157,6 +174,11 @@ func NewPluginManager(options ...PluginManagerOption) *PluginManager {
 		option(pm)
 	}
 
+	// 插件间调用默认由本管理器路由
+	if pm.hostServices.Router == nil {
+		pm.hostServices.Router = pm
+	}
+
 	// 创建插件隔离器
 	isolationConfig := DefaultPluginIsolationConfig()
 	pm.isolator = NewPluginIsolator(isolationConfig,
@@ -221,6 +243,21 @@ func (pm *PluginManager) LoadPlugin(config *PluginConfig) (*ManagedPlugin, error
 
 	pm.logger.Info("找到插件可执行文件", "id", config.ID, "path", pluginPath)
 
+	// 读取插件清单
+	manifest, err := pm.loadManifest(config, pluginPath)
+	if err != nil {
+		pm.logger.Error("读取插件清单失败", "id", config.ID, "error", err)
+		return nil, err
+	}
+	if manifest == nil {
+		pm.logger.Warn("插件没有清单，主机服务将拒绝所有受控操作", "id", config.ID)
+	}
+
+	// 校验插件签名
+	if err := pm.verifySignature(config.ID, manifest, pluginPath); err != nil {
+		return nil, err
+	}
+
 	// 创建插件沙箱
 	pm.logger.Debug("创建插件沙箱", "id", config.ID)
 	sandbox := NewPluginSandbox(config.ID, pm.isolator,
@@ -240,6 +277,8 @@ func (pm *PluginManager) LoadPlugin(config *PluginConfig) (*ManagedPlugin, error
 		State:     PluginStateInitializing,
 		StartTime: time.Now(),
 		Client:    nil, // 将在启动时设置
+		Manifest:  manifest,
+		Guard:     pm.permissionGuard(config, manifest),
 	}
 
 	// 存储插件
@@ -301,6 +340,46 @@ func (pm *PluginManager) StartPlugin(id string) error {
 		return fmt.Errorf("插件可执行文件不存在: %s", pluginPath)
 	}
 
+	// 加载后插件可能已被替换为新版本，执行前重新读取清单并校验签名
+	manifest, err := pm.loadManifest(plugin.Config, pluginPath)
+	if err == nil {
+		err = pm.verifySignature(id, manifest, pluginPath)
+	}
+	if err != nil {
+		pm.mu.Lock()
+		plugin.State = PluginStateError
+		plugin.LastError = err
+		pm.mu.Unlock()
+		return err
+	}
+	guard := pm.permissionGuard(plugin.Config, manifest)
+
+	client, instance, err := pm.launchPlugin(id, pluginPath, manifest, guard)
+	if err != nil {
+		return err
+	}
+
+	pm.logger.Debug("更新插件状态", "id", id)
+
+	// 更新插件状态
+	pm.mu.Lock()
+	plugin.Client = client
+	plugin.Interface = instance
+	plugin.Manifest = manifest
+	plugin.Guard = guard
+	if manifest != nil && manifest.Version != "" {
+		plugin.Version = manifest.Version
+	}
+	plugin.State = PluginStateRunning
+	plugin.Sandbox.SetState(PluginStateRunning)
+	pm.mu.Unlock()
+
+	pm.logger.Info("插件已启动", "id", id, "path", pluginPath)
+	return nil
+}
+
+// launchPlugin 启动插件进程并获取插件实例，向插件提供按权限清单包装的主机服务
+func (pm *PluginManager) launchPlugin(id, pluginPath string, manifest *coreplugin.PluginMetadata, guard *coreplugin.PermissionGuard) (*goplugin.Client, interface{}, error) {
 	pm.logger.Debug("创建插件客户端", "id", id)
 
 	// 创建插件客户端
@@ -336,7 +415,7 @@ func (pm *PluginManager) StartPlugin(id string) error {
 		if client.Exited() {
 			pm.logger.Error("插件进程已退出", "id", id)
 		}
-		return fmt.Errorf("连接到插件失败: %w", err)
+		return nil, nil, fmt.Errorf("连接到插件失败: %w", err)
 	}
 	pm.logger.Debug("成功连接到插件", "id", id)
 
@@ -353,22 +432,22 @@ func (pm *PluginManager) StartPlugin(id string) error {
 		if err != nil {
 			pm.logger.Error("获取插件实例失败", "id", id, "error", err)
 			client.Kill()
-			return fmt.Errorf("获取插件实例失败: %w", err)
+			return nil, nil, fmt.Errorf("获取插件实例失败: %w", err)
 		}
 	}
 
-	pm.logger.Debug("更新插件状态", "id", id)
-
-	// 更新插件状态
-	pm.mu.Lock()
-	plugin.Client = client
-	plugin.Interface = instance
-	plugin.State = PluginStateRunning
-	plugin.Sandbox.SetState(PluginStateRunning)
-	pm.mu.Unlock()
+	// 向插件提供按权限清单包装的主机服务
+	if server, ok := instance.(HostServicesServer); ok {
+		var permissions coreplugin.PluginPermissions
+		if manifest != nil {
+			permissions = manifest.Permissions
+		}
+		if err := server.ServeHostServices(id, pm.hostServices.Guard(guard), permissions); err != nil {
+			pm.logger.Warn("向插件提供主机服务失败", "id", id, "error", err)
+		}
+	}
 
-	pm.logger.Info("插件已启动", "id", id, "path", pluginPath)
-	return nil
+	return client, instance, nil
 }
 
 // StopPlugin 停止插件
@@ -398,6 +477,33 @@ func (pm *PluginManager) StopPlugin(id string) error {
 	return nil
 }
 
+// KillPlugin 强制终止插件进程，用于停止超时的插件
+func (pm *PluginManager) KillPlugin(id string) error {
+	pm.mu.Lock()
+	plugin, exists := pm.plugins[id]
+	if !exists {
+		pm.mu.Unlock()
+		return fmt.Errorf("插件 %s 不存在", id)
+	}
+
+	client := plugin.Client
+	plugin.Client = nil
+	plugin.Interface = nil
+	plugin.State = PluginStateStopped
+	plugin.StopTime = time.Now()
+	pm.mu.Unlock()
+
+	if plugin.Sandbox != nil {
+		plugin.Sandbox.Stop()
+	}
+	if client != nil {
+		client.Kill()
+	}
+
+	pm.logger.Warn("插件已强制终止", "id", id)
+	return nil
+}
+
 // RestartPlugin 重启插件
 func (pm *PluginManager) RestartPlugin(id string) error {
 	// 停止插件
@@ -567,6 +567,24 @@ func (m *PluginManagerV3) GetPluginStatus(id string) (api.PluginStatus, error) {
 	}, nil
 }
 
+// GetPluginRuntimeInfo 获取插件运行时信息
+// 插件未实现 api.RuntimeInfoProvider 时只返回生命周期管理器记录的状态
+func (m *PluginManagerV3) GetPluginRuntimeInfo(id string) (api.PluginRuntimeInfo, error) {
+	m.mu.RLock()
+	lcm, exists := m.lifecycleManagers[id]
+	plugin, pluginExists := m.plugins[id]
+	m.mu.RUnlock()
+
+	if !exists || !pluginExists {
+		return api.PluginRuntimeInfo{}, fmt.Errorf("插件 %s 未加载", id)
+	}
+
+	if provider, ok := plugin.(api.RuntimeInfoProvider); ok {
+		return provider.GetRuntimeInfo(), nil
+	}
+	return api.PluginRuntimeInfo{State: lcm.GetCurrentState()}, nil
+}
+
 // GetPluginDependencies 获取插件依赖
 func (m *PluginManagerV3) GetPluginDependencies(id string) ([]api.PluginDependency, error) {
 	return m.dependencyManager.GetPluginDependencies(id)
@@ -0,0 +1,55 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	coreplugin "github.com/lomehong/kennel/pkg/core/plugin"
+)
+
+// ManifestFileName 插件清单文件名
+const ManifestFileName = "plugin.json"
+
+// loadManifest 读取插件清单
+// 依次查找插件目录和可执行文件所在目录，都不存在时返回nil，插件不具有任何权限
+func (pm *PluginManager) loadManifest(config *PluginConfig, pluginPath string) (*coreplugin.PluginMetadata, error) {
+	dirs := []string{
+		filepath.Join(pm.pluginsDir, config.Path),
+		filepath.Dir(pluginPath),
+	}
+
+	for _, dir := range dirs {
+		path := filepath.Join(dir, ManifestFileName)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取插件清单失败: %w", err)
+		}
+
+		var metadata coreplugin.PluginMetadata
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			return nil, fmt.Errorf("解析插件清单失败: %s: %w", path, err)
+		}
+		if metadata.ID != "" && metadata.ID != config.ID {
+			return nil, fmt.Errorf("插件清单ID不匹配: %s (期望 %s)", metadata.ID, config.ID)
+		}
+		metadata.ID = config.ID
+		metadata.Path = dir
+		return &metadata, nil
+	}
+
+	return nil, nil
+}
+
+// permissionGuard 根据插件清单创建权限守卫，没有清单的插件拒绝所有受控操作
+func (pm *PluginManager) permissionGuard(config *PluginConfig, manifest *coreplugin.PluginMetadata) *coreplugin.PermissionGuard {
+	metadata := coreplugin.PluginMetadata{ID: config.ID}
+	if manifest != nil {
+		metadata = *manifest
+	}
+	return coreplugin.NewPermissionGuard(metadata, pm.logger.Named(fmt.Sprintf("permissions-%s", config.ID)))
+}
@@ -30,6 +30,13 @@ type Module interface {
 	HandleMessage(messageType string, messageID string, timestamp int64, payload map[string]interface{}) (map[string]interface{}, error)
 }
 
+// Metricable 定义了可提供运行指标的模块
+// 进程外插件通过 GetMetrics RPC 向主机报告指标，主机侧的 GRPCClient 同样实现此接口
+type Metricable interface {
+	// GetMetrics 获取指标
+	GetMetrics() map[string]interface{}
+}
+
 // ModuleInfo 包含模块的基本信息
 type ModuleInfo struct {
 	Name             string   `json:"name"`
@@ -46,8 +53,14 @@ type ModulePlugin struct {
 
 // GRPCServer 实现了go-plugin的GRPCServer接口
 func (p *ModulePlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	// 主机服务客户端，模块实现 HostConsumer 时传给模块
+	host := newHost(broker)
+	if consumer, ok := p.Impl.(HostConsumer); ok {
+		consumer.SetHost(host)
+	}
+
 	// 注册gRPC服务
-	pb.RegisterModuleServer(s, &GRPCServer{Impl: p.Impl})
+	pb.RegisterModuleServer(s, &GRPCServer{Impl: p.Impl, host: host})
 	return nil
 }
 
@@ -55,7 +68,7 @@ func (p *ModulePlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) err
 func (p *ModulePlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
 	// 创建gRPC客户端
 	client := pb.NewModuleClient(c)
-	return &GRPCClient{client: client}, nil
+	return &GRPCClient{client: client, broker: broker}, nil
 }
 
 // PluginMap 是插件类型到插件实现的映射
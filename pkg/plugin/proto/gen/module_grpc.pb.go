@@ -24,6 +24,7 @@ const (
 	Module_Shutdown_FullMethodName      = "/plugin.Module/Shutdown"
 	Module_GetInfo_FullMethodName       = "/plugin.Module/GetInfo"
 	Module_HandleMessage_FullMethodName = "/plugin.Module/HandleMessage"
+	Module_GetMetrics_FullMethodName    = "/plugin.Module/GetMetrics"
 )
 
 // ModuleClient is the client API for Module service.
@@ -42,6 +43,8 @@ type ModuleClient interface {
 	GetInfo(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*ModuleInfo, error)
 	// HandleMessage 处理消息
 	HandleMessage(ctx context.Context, in *MessageRequest, opts ...grpc.CallOption) (*MessageResponse, error)
+	// 获取模块运行指标
+	GetMetrics(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*MetricsResponse, error)
 }
 
 type moduleClient struct {
@@ -102,6 +105,16 @@ func (c *moduleClient) HandleMessage(ctx context.Context, in *MessageRequest, op
 	return out, nil
 }
 
+func (c *moduleClient) GetMetrics(ctx context.Context, in *EmptyRequest, opts ...grpc.CallOption) (*MetricsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MetricsResponse)
+	err := c.cc.Invoke(ctx, Module_GetMetrics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ModuleServer is the server API for Module service.
 // All implementations must embed UnimplementedModuleServer
 // for forward compatibility.
@@ -118,6 +131,8 @@ type ModuleServer interface {
 	GetInfo(context.Context, *EmptyRequest) (*ModuleInfo, error)
 	// HandleMessage 处理消息
 	HandleMessage(context.Context, *MessageRequest) (*MessageResponse, error)
+	// 获取模块运行指标
+	GetMetrics(context.Context, *EmptyRequest) (*MetricsResponse, error)
 	mustEmbedUnimplementedModuleServer()
 }
 
@@ -143,6 +158,9 @@ func (UnimplementedModuleServer) GetInfo(context.Context, *EmptyRequest) (*Modul
 func (UnimplementedModuleServer) HandleMessage(context.Context, *MessageRequest) (*MessageResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HandleMessage not implemented")
 }
+func (UnimplementedModuleServer) GetMetrics(context.Context, *EmptyRequest) (*MetricsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMetrics not implemented")
+}
 func (UnimplementedModuleServer) mustEmbedUnimplementedModuleServer() {}
 func (UnimplementedModuleServer) testEmbeddedByValue()                {}
 
@@ -254,6 +272,24 @@ func _Module_HandleMessage_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Module_GetMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmptyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ModuleServer).GetMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Module_GetMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ModuleServer).GetMetrics(ctx, req.(*EmptyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Module_ServiceDesc is the grpc.ServiceDesc for Module service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -281,6 +317,10 @@ var Module_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HandleMessage",
 			Handler:    _Module_HandleMessage_Handler,
 		},
+		{
+			MethodName: "GetMetrics",
+			Handler:    _Module_GetMetrics_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/plugin/proto/module.proto",
@@ -0,0 +1,69 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/lomehong/kennel/pkg/logging"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestMetadataPrefix 请求元数据在gRPC元数据中的键前缀，用于与gRPC自身的头部区分
+const requestMetadataPrefix = "x-kennel-"
+
+// MetadataExecutor 支持携带请求元数据执行操作的模块
+// 元数据由主机提供，包含请求ID、用户等关联字段，插件据此在日志中输出相同的关联字段
+type MetadataExecutor interface {
+	// ExecuteWithMetadata 携带请求元数据执行模块操作
+	ExecuteWithMetadata(action string, params map[string]interface{}, metadata map[string]string) (map[string]interface{}, error)
+}
+
+// ExecuteWithMetadata 携带请求元数据执行模块操作，模块不支持元数据时忽略元数据
+func ExecuteWithMetadata(module Module, action string, params map[string]interface{}, metadata map[string]string) (map[string]interface{}, error) {
+	if executor, ok := module.(MetadataExecutor); ok && len(metadata) > 0 {
+		return executor.ExecuteWithMetadata(action, params, metadata)
+	}
+	return module.Execute(action, params)
+}
+
+// MetadataFromContext 将上下文中的日志关联字段（request_id、user_id、trace_id等）转换为请求元数据
+func MetadataFromContext(ctx context.Context) map[string]string {
+	fields := logging.GetLogFieldsFromContext(ctx)
+	result := make(map[string]string, len(fields))
+	for key, value := range fields {
+		if s := fmt.Sprint(value); s != "" {
+			result[key] = s
+		}
+	}
+	return result
+}
+
+// outgoingMetadataContext 将请求元数据附加到gRPC调用的上下文
+func outgoingMetadataContext(ctx context.Context, md map[string]string) context.Context {
+	if len(md) == 0 {
+		return ctx
+	}
+
+	pairs := make([]string, 0, len(md)*2)
+	for key, value := range md {
+		pairs = append(pairs, requestMetadataPrefix+strings.ToLower(key), value)
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// incomingRequestMetadata 从gRPC调用的上下文中提取请求元数据
+func incomingRequestMetadata(ctx context.Context) map[string]string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	result := make(map[string]string)
+	for key, values := range md {
+		if name, ok := strings.CutPrefix(key, requestMetadataPrefix); ok && len(values) > 0 {
+			result[name] = values[0]
+		}
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	coreplugin "github.com/lomehong/kennel/pkg/core/plugin"
+)
+
+// CallPlugin 实现了 coreplugin.PluginRouter 接口，将请求分发给运行中的目标插件
+// 请求ID和元数据随请求元数据传给插件；请求的 Timeout 大于0时按毫秒限制处理时间
+func (pm *PluginManager) CallPlugin(ctx context.Context, targetID string, req *coreplugin.Request) (*coreplugin.Response, error) {
+	if req == nil {
+		return nil, fmt.Errorf("请求不能为空")
+	}
+
+	pm.mu.RLock()
+	target, exists := pm.plugins[targetID]
+	var state PluginState
+	var module Module
+	if exists {
+		state = target.State
+		module, _ = target.Interface.(Module)
+	}
+	pm.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: 插件不存在: %s", coreplugin.ErrPluginUnavailable, targetID)
+	}
+	if state != PluginStateRunning || module == nil {
+		return nil, fmt.Errorf("%w: 插件未在运行: %s (%s)", coreplugin.ErrPluginUnavailable, targetID, state)
+	}
+
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.Timeout)*time.Millisecond)
+		defer cancel()
+	}
+
+	metadata := make(map[string]string, len(req.Metadata)+1)
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	if req.ID != "" {
+		metadata["request_id"] = req.ID
+	}
+
+	pm.logger.Debug("路由插件请求",
+		"source", metadata[coreplugin.MetadataSourcePlugin],
+		"target", targetID,
+		"action", req.Action)
+
+	resultCh := make(chan struct {
+		result map[string]interface{}
+		err    error
+	}, 1)
+	go func() {
+		result, err := ExecuteWithMetadata(module, req.Action, req.Params, metadata)
+		resultCh <- struct {
+			result map[string]interface{}
+			err    error
+		}{result, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("调用插件 %s 超时: %s", targetID, req.Action)
+	case res := <-resultCh:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return &coreplugin.Response{
+			ID:      req.ID,
+			Success: true,
+			Data:    res.result,
+		}, nil
+	}
+}
+
+// 确保 PluginManager 实现了插件路由接口
+var _ coreplugin.PluginRouter = (*PluginManager)(nil)
@@ -157,6 +157,13 @@ func (ps *PluginSandbox) SetState(state PluginState) {
 	ps.lastActivityTime = time.Now()
 }
 
+// TotalExecTime 获取在沙箱中累计执行的耗时
+func (ps *PluginSandbox) TotalExecTime() time.Duration {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return ps.totalExecTime
+}
+
 // GetStats 获取统计信息
 func (ps *PluginSandbox) GetStats() map[string]interface{} {
 	ps.mu.RLock()
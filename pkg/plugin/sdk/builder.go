@@ -39,6 +39,16 @@ type PluginBuilder struct {
 
 	// 健康检查函数
 	healthCheckFunc HealthCheckFunc
+
+	// 健康检查子项
+	healthChecks []builderHealthCheck
+}
+
+// builderHealthCheck 构建时注册的健康检查子项
+type builderHealthCheck struct {
+	name string
+	fn   HealthCheckerFunc
+	opts []HealthCheckOption
 }
 
 // NewPluginBuilder 创建一个新的插件构建器
@@ -140,11 +150,24 @@ func (b *PluginBuilder) WithHealthCheckFunc(healthCheckFunc HealthCheckFunc) *Pl
 	return b
 }
 
+// WithHealthCheck 注册健康检查子项，未设置 WithHealthCheckFunc 时由基础实现汇总
+func (b *PluginBuilder) WithHealthCheck(name string, fn HealthCheckerFunc, opts ...HealthCheckOption) *PluginBuilder {
+	b.healthChecks = append(b.healthChecks, builderHealthCheck{name: name, fn: fn, opts: opts})
+	return b
+}
+
 // Build 构建插件
 func (b *PluginBuilder) Build() api.Plugin {
 	// 创建基础插件
 	basePlugin := NewBasePlugin(b.info, b.logger)
 
+	// 注册健康检查子项
+	for _, check := range b.healthChecks {
+		if err := basePlugin.HealthChecks().Register(check.name, check.fn, check.opts...); err != nil {
+			basePlugin.GetLogger().Warn("注册健康检查失败", "name", check.name, "error", err)
+		}
+	}
+
 	// 创建自定义插件
 	plugin := &customPlugin{
 		BasePlugin:     basePlugin,
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/lomehong/kennel/pkg/plugin/api"
@@ -33,6 +35,20 @@ type ConfigManager struct {
 
 	// 环境变量前缀
 	envPrefix string
+
+	// 上次保存或加载的配置，用于计算变更
+	persisted map[string]interface{}
+
+	// 配置变更审计
+	auditMu         sync.Mutex
+	auditDisabled   bool
+	auditMaxRecords int
+	auditMaxAge     time.Duration
+	secretKeys      []string
+	now             func() time.Time
+
+	// 已弃用的配置项
+	deprecations configDeprecations
 }
 
 // ConfigOption 配置选项
@@ -78,6 +94,9 @@ func NewConfigManager(pluginID string, logger hclog.Logger, options ...ConfigOpt
 		logger:     logger.Named("config-manager"),
 		data:       make(map[string]interface{}),
 		envPrefix:  strings.ToUpper(pluginID),
+
+		auditMaxRecords: DefaultConfigAuditMaxRecords,
+		now:             time.Now,
 	}
 
 	// 应用选项
@@ -106,16 +125,36 @@ func (cm *ConfigManager) Load() error {
 	}
 
 	// 解析配置文件
-	if err := yaml.Unmarshal(data, &cm.data); err != nil {
+	var loaded map[string]interface{}
+	if err := yaml.Unmarshal(data, &loaded); err != nil {
 		return fmt.Errorf("解析配置文件失败: %w", err)
 	}
 
+	// 重新加载时记录配置文件在外部发生的变更，首次加载只作为比较基准
+	if cm.persisted != nil {
+		cm.auditChanges(ConfigSourceReload, cm.persisted, loaded)
+	}
+	cm.persisted = cloneConfigMap(loaded)
+
+	if cm.data == nil {
+		cm.data = make(map[string]interface{})
+	}
+	for key, value := range cloneConfigMap(loaded) {
+		cm.data[key] = value
+	}
+	cm.applyDeprecations()
+
 	cm.logger.Debug("加载配置", "path", configPath)
 	return nil
 }
 
 // Save 保存配置
 func (cm *ConfigManager) Save() error {
+	return cm.SaveWithSource(ConfigSourceSave)
+}
+
+// SaveWithSource 保存配置，并以指定来源（如 debug_server、admin）记录配置变更审计
+func (cm *ConfigManager) SaveWithSource(source string) error {
 	// 构建配置文件路径
 	configPath := filepath.Join(cm.configDir, cm.configFile)
 
@@ -130,6 +169,9 @@ func (cm *ConfigManager) Save() error {
 		return fmt.Errorf("写入配置文件失败: %w", err)
 	}
 
+	cm.auditChanges(source, cm.persisted, cm.data)
+	cm.persisted = cloneConfigMap(cm.data)
+
 	cm.logger.Debug("保存配置", "path", configPath)
 	return nil
 }
@@ -411,6 +453,7 @@ func (cm *ConfigManager) GetData() map[string]interface{} {
 // SetData 设置配置数据
 func (cm *ConfigManager) SetData(data map[string]interface{}) {
 	cm.data = data
+	cm.applyDeprecations()
 }
 
 // GetConfigDir 获取配置目录
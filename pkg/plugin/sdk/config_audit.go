@@ -0,0 +1,341 @@
+package sdk
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConfigAuditFile 配置变更审计日志文件名，位于插件配置目录
+const ConfigAuditFile = "config_audit.jsonl"
+
+// 配置变更来源
+const (
+	// ConfigSourceSave 通过 Save 保存
+	ConfigSourceSave = "save"
+
+	// ConfigSourceReload 通过 Load 重新加载时发现的文件变更
+	ConfigSourceReload = "reload"
+)
+
+const (
+	// DefaultConfigAuditMaxRecords 默认保留的审计记录数量
+	DefaultConfigAuditMaxRecords = 1000
+
+	// redactedValue 敏感配置值的替代文本
+	redactedValue = "******"
+)
+
+// defaultSecretKeyPatterns 默认视为敏感配置的键名片段，按不区分大小写匹配键的最后一段
+var defaultSecretKeyPatterns = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "private_key", "credential"}
+
+// ConfigChange 单个配置项的变更
+// 新增的配置项 OldValue 为空，删除的配置项 NewValue 为空
+type ConfigChange struct {
+	Key      string      `json:"key"`
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
+	Redacted bool        `json:"redacted,omitempty"`
+}
+
+// ConfigAuditRecord 一次配置变更的审计记录
+type ConfigAuditRecord struct {
+	Timestamp time.Time      `json:"timestamp"`
+	PluginID  string         `json:"plugin_id"`
+	Source    string         `json:"source"`
+	Changes   []ConfigChange `json:"changes"`
+}
+
+// Keys 返回变更的配置键
+func (r ConfigAuditRecord) Keys() []string {
+	keys := make([]string, len(r.Changes))
+	for i, change := range r.Changes {
+		keys[i] = change.Key
+	}
+	return keys
+}
+
+// ConfigAuditQuery 审计记录查询条件，零值表示不限制
+type ConfigAuditQuery struct {
+	Since  time.Time // 只返回该时间之后的记录
+	Key    string    // 只返回变更了该配置项或其子项的记录
+	Source string    // 只返回该来源的记录
+	Limit  int       // 最多返回最近的 Limit 条记录
+}
+
+// matches 判断记录是否满足查询条件
+func (q ConfigAuditQuery) matches(record ConfigAuditRecord) bool {
+	if !q.Since.IsZero() && record.Timestamp.Before(q.Since) {
+		return false
+	}
+	if q.Source != "" && record.Source != q.Source {
+		return false
+	}
+	if q.Key == "" {
+		return true
+	}
+	for _, change := range record.Changes {
+		if change.Key == q.Key || strings.HasPrefix(change.Key, q.Key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithConfigAuditRetention 设置审计记录的保留策略
+// maxRecords 为保留的最大记录数，maxAge 为记录的最长保留时间，0 表示不按时间清理
+func WithConfigAuditRetention(maxRecords int, maxAge time.Duration) ConfigOption {
+	return func(cm *ConfigManager) {
+		cm.auditMaxRecords = maxRecords
+		cm.auditMaxAge = maxAge
+	}
+}
+
+// WithSecretKeys 声明敏感配置项，审计日志中不记录其值
+// 键可以是完整的点分隔路径（如 settings.db.password），也可以是键名（如 password）
+func WithSecretKeys(keys ...string) ConfigOption {
+	return func(cm *ConfigManager) {
+		cm.secretKeys = append(cm.secretKeys, keys...)
+	}
+}
+
+// WithConfigAudit 启用或禁用配置变更审计，默认启用
+func WithConfigAudit(enabled bool) ConfigOption {
+	return func(cm *ConfigManager) {
+		cm.auditDisabled = !enabled
+	}
+}
+
+// GetAuditPath 获取配置变更审计日志路径
+func (cm *ConfigManager) GetAuditPath() string {
+	return filepath.Join(cm.configDir, ConfigAuditFile)
+}
+
+// QueryConfigAudit 按条件查询配置变更审计记录，按时间从早到晚排列
+func (cm *ConfigManager) QueryConfigAudit(query ConfigAuditQuery) ([]ConfigAuditRecord, error) {
+	cm.auditMu.Lock()
+	defer cm.auditMu.Unlock()
+
+	records, err := cm.readAuditRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ConfigAuditRecord
+	for _, record := range records {
+		if query.matches(record) {
+			result = append(result, record)
+		}
+	}
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[len(result)-query.Limit:]
+	}
+	return result, nil
+}
+
+// auditChanges 比较上次保存或加载的配置与当前配置，有变更时追加审计记录
+// 审计失败不影响配置的保存和加载，只记录日志
+func (cm *ConfigManager) auditChanges(source string, previous, current map[string]interface{}) {
+	if cm.auditDisabled {
+		return
+	}
+
+	changes := cm.diffConfig(previous, current)
+	if len(changes) == 0 {
+		return
+	}
+
+	record := ConfigAuditRecord{
+		Timestamp: cm.now(),
+		PluginID:  cm.pluginID,
+		Source:    source,
+		Changes:   changes,
+	}
+	if err := cm.appendAuditRecord(record); err != nil {
+		cm.logger.Warn("记录配置变更审计失败", "error", err)
+		return
+	}
+	cm.logger.Info("配置已变更", "source", source, "keys", record.Keys())
+}
+
+// diffConfig 比较两份配置，返回按键排序的变更，敏感配置的值被隐藏
+func (cm *ConfigManager) diffConfig(previous, current map[string]interface{}) []ConfigChange {
+	before := make(map[string]interface{})
+	after := make(map[string]interface{})
+	flattenConfig("", previous, before)
+	flattenConfig("", current, after)
+
+	var changes []ConfigChange
+	for key, oldValue := range before {
+		newValue, exists := after[key]
+		if exists && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, cm.newConfigChange(key, oldValue, newValue))
+	}
+	for key, newValue := range after {
+		if _, exists := before[key]; !exists {
+			changes = append(changes, cm.newConfigChange(key, nil, newValue))
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+// newConfigChange 创建配置变更，敏感配置的值被替换
+func (cm *ConfigManager) newConfigChange(key string, oldValue, newValue interface{}) ConfigChange {
+	change := ConfigChange{Key: key, OldValue: oldValue, NewValue: newValue}
+	if cm.isSecretKey(key) {
+		change.Redacted = true
+		if oldValue != nil {
+			change.OldValue = redactedValue
+		}
+		if newValue != nil {
+			change.NewValue = redactedValue
+		}
+	}
+	return change
+}
+
+// isSecretKey 判断配置项是否为敏感配置
+func (cm *ConfigManager) isSecretKey(key string) bool {
+	name := strings.ToLower(key[strings.LastIndex(key, ".")+1:])
+	for _, secret := range cm.secretKeys {
+		if key == secret || strings.HasPrefix(key, secret+".") || name == strings.ToLower(secret) {
+			return true
+		}
+	}
+	for _, pattern := range defaultSecretKeyPatterns {
+		if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenConfig 将嵌套配置展开为点分隔的键，列表作为整体比较
+func flattenConfig(prefix string, config map[string]interface{}, out map[string]interface{}) {
+	for key, value := range config {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenConfig(key, nested, out)
+			continue
+		}
+		out[key] = value
+	}
+}
+
+// appendAuditRecord 追加审计记录，超出保留策略时重写日志
+func (cm *ConfigManager) appendAuditRecord(record ConfigAuditRecord) error {
+	cm.auditMu.Lock()
+	defer cm.auditMu.Unlock()
+
+	records, err := cm.readAuditRecords()
+	if err != nil {
+		return err
+	}
+	records = append(records, record)
+
+	retained := cm.retainAuditRecords(records)
+	if len(retained) < len(records) {
+		return cm.writeAuditRecords(retained)
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("序列化审计记录失败: %w", err)
+	}
+	file, err := os.OpenFile(cm.GetAuditPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("打开审计日志失败: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入审计日志失败: %w", err)
+	}
+	return nil
+}
+
+// retainAuditRecords 按保留策略过滤审计记录
+func (cm *ConfigManager) retainAuditRecords(records []ConfigAuditRecord) []ConfigAuditRecord {
+	if cm.auditMaxAge > 0 {
+		cutoff := cm.now().Add(-cm.auditMaxAge)
+		kept := records[:0:0]
+		for _, record := range records {
+			if !record.Timestamp.Before(cutoff) {
+				kept = append(kept, record)
+			}
+		}
+		records = kept
+	}
+	if cm.auditMaxRecords > 0 && len(records) > cm.auditMaxRecords {
+		records = records[len(records)-cm.auditMaxRecords:]
+	}
+	return records
+}
+
+// readAuditRecords 读取全部审计记录，日志不存在时返回空
+func (cm *ConfigManager) readAuditRecords() ([]ConfigAuditRecord, error) {
+	file, err := os.Open(cm.GetAuditPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开审计日志失败: %w", err)
+	}
+	defer file.Close()
+
+	var records []ConfigAuditRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record ConfigAuditRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			cm.logger.Warn("跳过无法解析的审计记录", "error", err)
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取审计日志失败: %w", err)
+	}
+	return records, nil
+}
+
+// writeAuditRecords 通过临时文件重写审计日志
+func (cm *ConfigManager) writeAuditRecords(records []ConfigAuditRecord) error {
+	var builder strings.Builder
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("序列化审计记录失败: %w", err)
+		}
+		builder.Write(data)
+		builder.WriteByte('\n')
+	}
+
+	path := cm.GetAuditPath()
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(builder.String()), 0600); err != nil {
+		return fmt.Errorf("写入审计日志失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("替换审计日志失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package sdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuditTestConfigManager 创建使用临时目录和可控时钟的配置管理器
+func newAuditTestConfigManager(t *testing.T, options ...ConfigOption) (*ConfigManager, *time.Time) {
+	options = append([]ConfigOption{WithConfigDir(t.TempDir())}, options...)
+	cm, err := NewConfigManager("audit-test", nil, options...)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	cm.now = func() time.Time { return now }
+	return cm, &now
+}
+
+func TestConfigAudit_RecordsDiffs(t *testing.T) {
+	cm, now := newAuditTestConfigManager(t, WithSecretKeys("settings.license"))
+
+	cm.Set("log_level", "info")
+	cm.Set("settings.interval", 10)
+	cm.Set("settings.db.password", "hunter2")
+	cm.Set("settings.license", "ABC-123")
+	require.NoError(t, cm.Save())
+
+	*now = now.Add(time.Minute)
+	cm.Set("settings.interval", 30)
+	cm.Set("settings.db.password", "correct-horse")
+	cm.Set("settings.api_token", "t0ken")
+	delete(cm.GetData(), "log_level")
+	require.NoError(t, cm.SaveWithSource("debug_server"))
+
+	// 没有变更时不记录
+	require.NoError(t, cm.Save())
+
+	records, err := cm.QueryConfigAudit(ConfigAuditQuery{})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	first := records[0]
+	assert.Equal(t, "audit-test", first.PluginID)
+	assert.Equal(t, ConfigSourceSave, first.Source)
+	assert.Equal(t, []string{"log_level", "settings.db.password", "settings.interval", "settings.license"}, first.Keys())
+	assert.Equal(t, ConfigChange{Key: "settings.db.password", NewValue: "******", Redacted: true}, first.Changes[1])
+	assert.Equal(t, ConfigChange{Key: "settings.license", NewValue: "******", Redacted: true}, first.Changes[3])
+
+	second := records[1]
+	assert.Equal(t, "debug_server", second.Source)
+	assert.Equal(t, now.UTC(), second.Timestamp.UTC())
+	assert.Equal(t, []ConfigChange{
+		{Key: "log_level", OldValue: "info"},
+		{Key: "settings.api_token", NewValue: "******", Redacted: true},
+		{Key: "settings.db.password", OldValue: "******", NewValue: "******", Redacted: true},
+		{Key: "settings.interval", OldValue: float64(10), NewValue: float64(30)},
+	}, second.Changes)
+
+	// 审计日志中不出现敏感值
+	data, err := os.ReadFile(cm.GetAuditPath())
+	require.NoError(t, err)
+	for _, secret := range []string{"hunter2", "correct-horse", "t0ken", "ABC-123"} {
+		assert.NotContains(t, string(data), secret)
+	}
+}
+
+func TestConfigAudit_Reload(t *testing.T) {
+	cm, _ := newAuditTestConfigManager(t)
+	path := cm.GetConfigPath()
+
+	require.NoError(t, os.WriteFile(path, []byte("enabled: true\nsettings:\n  interval: 5\n"), 0644))
+	require.NoError(t, cm.Load())
+
+	// 首次加载不产生记录
+	records, err := cm.QueryConfigAudit(ConfigAuditQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	require.NoError(t, os.WriteFile(path, []byte("enabled: false\nsettings:\n  interval: 5\n"), 0644))
+	require.NoError(t, cm.Load())
+	assert.False(t, cm.GetBool("enabled"))
+
+	records, err = cm.QueryConfigAudit(ConfigAuditQuery{Source: ConfigSourceReload})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, []ConfigChange{{Key: "enabled", OldValue: true, NewValue: false}}, records[0].Changes)
+}
+
+func TestConfigAudit_QueryAndRetention(t *testing.T) {
+	cm, now := newAuditTestConfigManager(t, WithConfigAuditRetention(3, time.Hour))
+	start := *now
+
+	for i := 1; i <= 5; i++ {
+		*now = start.Add(time.Duration(i) * time.Minute)
+		cm.Set("settings.interval", i)
+		if i%2 == 0 {
+			cm.Set("settings.mode", i)
+		}
+		require.NoError(t, cm.Save())
+	}
+
+	// 只保留最近3条记录
+	records, err := cm.QueryConfigAudit(ConfigAuditQuery{})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, start.Add(3*time.Minute).UTC(), records[0].Timestamp.UTC())
+
+	records, err = cm.QueryConfigAudit(ConfigAuditQuery{Key: "settings.mode"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, ConfigChange{Key: "settings.mode", OldValue: float64(2), NewValue: float64(4)}, records[0].Changes[1])
+
+	records, err = cm.QueryConfigAudit(ConfigAuditQuery{Key: "settings", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, start.Add(5*time.Minute).UTC(), records[1].Timestamp.UTC())
+
+	records, err = cm.QueryConfigAudit(ConfigAuditQuery{Since: start.Add(5 * time.Minute)})
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	// 超过最长保留时间的记录在下次写入时清理
+	*now = start.Add(2 * time.Hour)
+	cm.Set("settings.interval", 100)
+	require.NoError(t, cm.Save())
+	records, err = cm.QueryConfigAudit(ConfigAuditQuery{})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, float64(100), records[0].Changes[0].NewValue)
+
+	_, err = os.Stat(filepath.Join(cm.GetConfigDir(), ConfigAuditFile+".tmp"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestConfigAudit_Disabled(t *testing.T) {
+	cm, _ := newAuditTestConfigManager(t, WithConfigAudit(false))
+	cm.Set("settings.interval", 1)
+	require.NoError(t, cm.Save())
+
+	_, err := os.Stat(cm.GetAuditPath())
+	assert.True(t, os.IsNotExist(err))
+}
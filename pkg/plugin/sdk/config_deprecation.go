@@ -0,0 +1,117 @@
+package sdk
+
+import (
+	"strings"
+	"sync"
+)
+
+// DeprecatedKey 已弃用的配置项声明
+type DeprecatedKey struct {
+	Key         string // 旧配置项，点分隔路径（如 settings.timeout）
+	Replacement string // 替代的新配置项，为空表示没有替代项
+	RemovedIn   string // 计划移除旧配置项的版本
+	AutoMap     bool   // 新配置项未设置时，将旧配置项的值映射到新配置项
+}
+
+// DeprecationWarning 配置中出现已弃用配置项时产生的警告
+type DeprecationWarning struct {
+	Key         string `json:"key"`
+	Replacement string `json:"replacement,omitempty"`
+	RemovedIn   string `json:"removed_in,omitempty"`
+	Mapped      bool   `json:"mapped"` // 旧配置项的值是否已映射到新配置项
+}
+
+// configDeprecations 已弃用配置项及已发出的警告，每个旧配置项只警告一次
+type configDeprecations struct {
+	mu       sync.Mutex
+	keys     []DeprecatedKey
+	warned   map[string]bool
+	warnings []DeprecationWarning
+}
+
+// WithDeprecatedKeys 声明已弃用的配置项
+// 加载配置时若出现旧配置项，记录一次警告；AutoMap 为 true 且新配置项未设置时，旧值会映射到新配置项
+func WithDeprecatedKeys(keys ...DeprecatedKey) ConfigOption {
+	return func(cm *ConfigManager) {
+		cm.deprecations.keys = append(cm.deprecations.keys, keys...)
+	}
+}
+
+// DeprecateKeys 在创建配置管理器后声明已弃用的配置项，并立即检查当前配置
+func (cm *ConfigManager) DeprecateKeys(keys ...DeprecatedKey) {
+	cm.deprecations.mu.Lock()
+	cm.deprecations.keys = append(cm.deprecations.keys, keys...)
+	cm.deprecations.mu.Unlock()
+
+	cm.applyDeprecations()
+}
+
+// DeprecationWarnings 返回已发出的弃用警告，按发出顺序排列
+func (cm *ConfigManager) DeprecationWarnings() []DeprecationWarning {
+	cm.deprecations.mu.Lock()
+	defer cm.deprecations.mu.Unlock()
+
+	warnings := make([]DeprecationWarning, len(cm.deprecations.warnings))
+	copy(warnings, cm.deprecations.warnings)
+	return warnings
+}
+
+// applyDeprecations 检查配置中的已弃用配置项，按声明映射到新配置项并发出一次性警告
+func (cm *ConfigManager) applyDeprecations() {
+	cm.deprecations.mu.Lock()
+	defer cm.deprecations.mu.Unlock()
+
+	for _, deprecated := range cm.deprecations.keys {
+		value, exists := lookupConfigValue(cm.data, deprecated.Key)
+		if !exists {
+			continue
+		}
+
+		// 每次加载都映射，保证重新加载后新配置项仍然生效
+		mapped := false
+		if deprecated.AutoMap && deprecated.Replacement != "" {
+			if _, set := lookupConfigValue(cm.data, deprecated.Replacement); !set {
+				cm.Set(deprecated.Replacement, value)
+				mapped = true
+			}
+		}
+
+		if cm.deprecations.warned[deprecated.Key] {
+			continue
+		}
+		if cm.deprecations.warned == nil {
+			cm.deprecations.warned = make(map[string]bool)
+		}
+		cm.deprecations.warned[deprecated.Key] = true
+
+		warning := DeprecationWarning{
+			Key:         deprecated.Key,
+			Replacement: deprecated.Replacement,
+			RemovedIn:   deprecated.RemovedIn,
+			Mapped:      mapped,
+		}
+		cm.deprecations.warnings = append(cm.deprecations.warnings, warning)
+		cm.logger.Warn("配置项已弃用",
+			"plugin", cm.pluginID,
+			"key", warning.Key,
+			"replacement", warning.Replacement,
+			"removed_in", warning.RemovedIn,
+			"mapped", warning.Mapped,
+		)
+	}
+}
+
+// lookupConfigValue 按点分隔路径查找配置数据中的值，不检查环境变量
+func lookupConfigValue(data map[string]interface{}, key string) (interface{}, bool) {
+	value := interface{}(data)
+	for _, k := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
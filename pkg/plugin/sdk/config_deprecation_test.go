@@ -0,0 +1,93 @@
+package sdk
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDeprecationTestConfigManager 创建写入指定配置文件、日志输出到缓冲区的配置管理器
+func newDeprecationTestConfigManager(t *testing.T, content string, options ...ConfigOption) (*ConfigManager, *bytes.Buffer) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0644))
+
+	var buf bytes.Buffer
+	logger := hclog.New(&hclog.LoggerOptions{Output: &buf, Level: hclog.Warn})
+	options = append([]ConfigOption{WithConfigDir(dir)}, options...)
+	cm, err := NewConfigManager("deprecation-test", logger, options...)
+	require.NoError(t, err)
+	return cm, &buf
+}
+
+func TestConfigDeprecation_WarnsOnceAndMaps(t *testing.T) {
+	cm, logs := newDeprecationTestConfigManager(t, "settings:\n  scan_interval: 30\n",
+		WithDeprecatedKeys(DeprecatedKey{
+			Key:         "settings.scan_interval",
+			Replacement: "settings.scan.interval",
+			RemovedIn:   "2.0.0",
+			AutoMap:     true,
+		}))
+
+	require.NoError(t, cm.Load())
+	assert.Equal(t, 30, cm.GetInt("settings.scan.interval"))
+
+	// 重新加载仍然映射，但不再重复警告
+	require.NoError(t, cm.Load())
+	cm.SetData(cm.GetData())
+	assert.Equal(t, 30, cm.GetInt("settings.scan.interval"))
+
+	assert.Equal(t, 1, strings.Count(logs.String(), "配置项已弃用"))
+	assert.Equal(t, []DeprecationWarning{{
+		Key:         "settings.scan_interval",
+		Replacement: "settings.scan.interval",
+		RemovedIn:   "2.0.0",
+		Mapped:      true,
+	}}, cm.DeprecationWarnings())
+}
+
+func TestConfigDeprecation_NewKeyTakesPrecedence(t *testing.T) {
+	cm, _ := newDeprecationTestConfigManager(t, "settings:\n  scan_interval: 30\n  scan:\n    interval: 60\n",
+		WithDeprecatedKeys(DeprecatedKey{Key: "settings.scan_interval", Replacement: "settings.scan.interval", AutoMap: true}))
+
+	require.NoError(t, cm.Load())
+	assert.Equal(t, 60, cm.GetInt("settings.scan.interval"))
+
+	warnings := cm.DeprecationWarnings()
+	require.Len(t, warnings, 1)
+	assert.False(t, warnings[0].Mapped)
+}
+
+func TestConfigDeprecation_NoAutoMap(t *testing.T) {
+	cm, _ := newDeprecationTestConfigManager(t, "log_file: agent.log\n",
+		WithDeprecatedKeys(DeprecatedKey{Key: "log_file", Replacement: "logging.file", RemovedIn: "2.0.0"}))
+
+	require.NoError(t, cm.Load())
+	assert.Nil(t, cm.Get("logging.file"))
+	require.Len(t, cm.DeprecationWarnings(), 1)
+}
+
+func TestConfigDeprecation_NoWarningForNewKey(t *testing.T) {
+	cm, logs := newDeprecationTestConfigManager(t, "settings:\n  scan:\n    interval: 60\n",
+		WithDeprecatedKeys(DeprecatedKey{Key: "settings.scan_interval", Replacement: "settings.scan.interval", AutoMap: true}))
+
+	require.NoError(t, cm.Load())
+	assert.Equal(t, 60, cm.GetInt("settings.scan.interval"))
+	assert.Empty(t, cm.DeprecationWarnings())
+	assert.NotContains(t, logs.String(), "配置项已弃用")
+}
+
+func TestConfigDeprecation_DeprecateKeysAfterLoad(t *testing.T) {
+	cm, logs := newDeprecationTestConfigManager(t, "timeout: 5\n")
+	require.NoError(t, cm.Load())
+	assert.Empty(t, cm.DeprecationWarnings())
+
+	cm.DeprecateKeys(DeprecatedKey{Key: "timeout", Replacement: "settings.timeout", AutoMap: true})
+	assert.Equal(t, 5, cm.GetInt("settings.timeout"))
+	assert.Equal(t, 1, strings.Count(logs.String(), "配置项已弃用"))
+}
@@ -8,9 +8,11 @@ import (
 	"os"
 	"runtime"
 	"runtime/pprof"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/lomehong/kennel/pkg/plugin/api"
 )
 
 // DebugServer 调试服务器
@@ -33,6 +35,12 @@ type DebugServer struct {
 
 	// 调试处理器
 	handlers map[string]http.HandlerFunc
+
+	// 配置查看和修改
+	configTarget   api.Configurable
+	configMutation bool
+	configToken    string
+	configMu       sync.Mutex
 }
 
 // DebugOption 调试选项
@@ -105,6 +113,11 @@ func (ds *DebugServer) registerDefaultHandlers() {
 
 	// 注册GC统计处理器
 	ds.RegisterHandler("/debug/gcstats", ds.handleGCStats)
+
+	// 注册配置处理器
+	if ds.configTarget != nil {
+		ds.RegisterHandler("/config", ds.handleConfig)
+	}
 }
 
 // RegisterHandler 注册处理器
@@ -112,6 +125,15 @@ func (ds *DebugServer) RegisterHandler(path string, handler http.HandlerFunc) {
 	ds.handlers[path] = handler
 }
 
+// Handler 返回包含所有已注册处理器的路由器
+func (ds *DebugServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for path, handler := range ds.handlers {
+		mux.HandleFunc(path, handler)
+	}
+	return mux
+}
+
 // Start 启动调试服务器
 func (ds *DebugServer) Start() error {
 	if !ds.enabled {
@@ -119,18 +141,10 @@ func (ds *DebugServer) Start() error {
 		return nil
 	}
 
-	// 创建路由器
-	mux := http.NewServeMux()
-
-	// 注册处理器
-	for path, handler := range ds.handlers {
-		mux.HandleFunc(path, handler)
-	}
-
 	// 创建服务器
 	ds.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", ds.port),
-		Handler: mux,
+		Handler: ds.Handler(),
 	}
 
 	// 启动服务器
@@ -0,0 +1,182 @@
+package sdk
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lomehong/kennel/pkg/plugin/api"
+)
+
+// WithDebugConfig 注册 /config 端点，通过 GET 查看插件当前配置
+func WithDebugConfig(target api.Configurable) DebugOption {
+	return func(ds *DebugServer) {
+		ds.configTarget = target
+	}
+}
+
+// WithDebugConfigMutation 允许通过 PATCH /config 在运行时修改配置
+// 请求必须携带令牌（Authorization: Bearer <token>），令牌为空时拒绝所有修改
+func WithDebugConfigMutation(enabled bool, token string) DebugOption {
+	return func(ds *DebugServer) {
+		ds.configMutation = enabled
+		ds.configToken = token
+	}
+}
+
+// handleConfig 处理配置查看和修改
+func (ds *DebugServer) handleConfig(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		ds.writeConfig(w, http.StatusOK)
+	case http.MethodPatch:
+		ds.handleConfigPatch(w, r)
+	default:
+		w.Header().Set("Allow", "GET, PATCH")
+		writeDebugError(w, http.StatusMethodNotAllowed, fmt.Errorf("不支持的方法: %s", r.Method))
+	}
+}
+
+// handleConfigPatch 修改配置
+// 请求体为 JSON 对象，键为点分隔的配置路径，如 {"settings.interval": 10}；
+// 修改后的完整配置先经过 ValidateConfig 验证，再通过 UpdateConfig 生效
+func (ds *DebugServer) handleConfigPatch(w http.ResponseWriter, r *http.Request) {
+	if !ds.configMutation {
+		writeDebugError(w, http.StatusForbidden, fmt.Errorf("配置修改未启用"))
+		return
+	}
+	if !ds.authorizeConfigMutation(r) {
+		writeDebugError(w, http.StatusUnauthorized, fmt.Errorf("无效的调试令牌"))
+		return
+	}
+
+	var changes map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&changes); err != nil {
+		writeDebugError(w, http.StatusBadRequest, fmt.Errorf("解析请求失败: %w", err))
+		return
+	}
+	if len(changes) == 0 {
+		writeDebugError(w, http.StatusBadRequest, fmt.Errorf("没有要修改的配置"))
+		return
+	}
+
+	// 串行化修改，避免并发请求互相覆盖
+	ds.configMu.Lock()
+	defer ds.configMu.Unlock()
+
+	config := cloneConfigMap(ds.configTarget.GetConfig())
+	for key, value := range changes {
+		if err := setConfigPath(config, key, value); err != nil {
+			writeDebugError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if valid, err := ds.configTarget.ValidateConfig(config); !valid || err != nil {
+		if err == nil {
+			err = fmt.Errorf("配置验证失败")
+		}
+		writeDebugError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	if err := ds.configTarget.UpdateConfig(r.Context(), config); err != nil {
+		writeDebugError(w, http.StatusInternalServerError, fmt.Errorf("应用配置失败: %w", err))
+		return
+	}
+
+	keys := make([]string, 0, len(changes))
+	for key := range changes {
+		keys = append(keys, key)
+	}
+	ds.logger.Info("通过调试服务器修改配置", "keys", keys, "remote", r.RemoteAddr)
+
+	ds.writeConfig(w, http.StatusOK)
+}
+
+// authorizeConfigMutation 校验修改配置的令牌
+func (ds *DebugServer) authorizeConfigMutation(r *http.Request) bool {
+	if ds.configToken == "" {
+		return false
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(ds.configToken)) == 1
+}
+
+// writeConfig 输出当前配置
+func (ds *DebugServer) writeConfig(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"plugin_id": ds.pluginID,
+		"config":    ds.configTarget.GetConfig(),
+		"mutable":   ds.configMutation,
+	})
+}
+
+// writeDebugError 输出错误响应
+func writeDebugError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": err.Error(),
+	})
+}
+
+// setConfigPath 按点分隔的路径设置配置值，中间路径不存在时自动创建
+func setConfigPath(config map[string]interface{}, path string, value interface{}) error {
+	keys := strings.Split(path, ".")
+	current := config
+	for i, key := range keys {
+		if key == "" {
+			return fmt.Errorf("无效的配置路径: %s", path)
+		}
+		if i == len(keys)-1 {
+			current[key] = value
+			return nil
+		}
+
+		next, exists := current[key]
+		if !exists {
+			child := make(map[string]interface{})
+			current[key] = child
+			current = child
+			continue
+		}
+
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("配置路径 %s 中的 %s 不是对象", path, key)
+		}
+		current = child
+	}
+	return nil
+}
+
+// cloneConfigMap 深拷贝配置，修改不影响插件当前配置
+func cloneConfigMap(config map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		result[k] = cloneConfigValue(v)
+	}
+	return result
+}
+
+// cloneConfigValue 深拷贝配置值
+func cloneConfigValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return cloneConfigMap(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = cloneConfigValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,150 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// debugConfigPlugin 测试用可配置插件，interval 必须为正整数
+type debugConfigPlugin struct {
+	mu      sync.Mutex
+	config  map[string]interface{}
+	updates int
+}
+
+func (p *debugConfigPlugin) UpdateConfig(ctx context.Context, config map[string]interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.config = config
+	p.updates++
+	return nil
+}
+
+func (p *debugConfigPlugin) GetConfig() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.config
+}
+
+func (p *debugConfigPlugin) ValidateConfig(config map[string]interface{}) (bool, error) {
+	settings, _ := config["settings"].(map[string]interface{})
+	interval, ok := settings["interval"].(float64)
+	if !ok || interval <= 0 || interval != float64(int(interval)) {
+		return false, fmt.Errorf("settings.interval 必须为正整数")
+	}
+	return true, nil
+}
+
+func newDebugConfigPlugin() *debugConfigPlugin {
+	return &debugConfigPlugin{
+		config: map[string]interface{}{
+			"log_level": "info",
+			"settings": map[string]interface{}{
+				"interval": float64(30),
+				"message":  "hello",
+			},
+		},
+	}
+}
+
+func doDebugRequest(t *testing.T, handler http.Handler, method, body, token string) (int, map[string]interface{}) {
+	req := httptest.NewRequest(method, "/config", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return rec.Code, response
+}
+
+func TestDebugServer_ConfigGet(t *testing.T) {
+	plugin := newDebugConfigPlugin()
+	ds := NewDebugServer("debug-test", nil, WithDebugConfig(plugin))
+
+	status, response := doDebugRequest(t, ds.Handler(), http.MethodGet, "", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "debug-test", response["plugin_id"])
+	assert.Equal(t, false, response["mutable"])
+
+	config := response["config"].(map[string]interface{})
+	settings := config["settings"].(map[string]interface{})
+	assert.Equal(t, float64(30), settings["interval"])
+
+	// 未启用修改时拒绝 PATCH
+	status, _ = doDebugRequest(t, ds.Handler(), http.MethodPatch, `{"settings.interval": 10}`, "secret")
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, 0, plugin.updates)
+}
+
+func TestDebugServer_ConfigPatch(t *testing.T) {
+	plugin := newDebugConfigPlugin()
+	ds := NewDebugServer("debug-test", nil,
+		WithDebugConfig(plugin),
+		WithDebugConfigMutation(true, "secret"),
+	)
+	handler := ds.Handler()
+
+	// 缺少或错误的令牌
+	status, _ := doDebugRequest(t, handler, http.MethodPatch, `{"settings.interval": 10}`, "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+	status, _ = doDebugRequest(t, handler, http.MethodPatch, `{"settings.interval": 10}`, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	// 验证失败的值被拒绝，配置保持不变
+	status, response := doDebugRequest(t, handler, http.MethodPatch, `{"settings.interval": -5}`, "secret")
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Contains(t, response["error"], "interval")
+	status, _ = doDebugRequest(t, handler, http.MethodPatch, `{"settings.interval": "fast"}`, "secret")
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, 0, plugin.updates)
+	assert.Equal(t, float64(30), plugin.GetConfig()["settings"].(map[string]interface{})["interval"])
+
+	// 路径穿过非对象值
+	status, _ = doDebugRequest(t, handler, http.MethodPatch, `{"log_level.value": 1}`, "secret")
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	// 合法的修改通过 UpdateConfig 生效，其他设置保持不变
+	status, response = doDebugRequest(t, handler, http.MethodPatch, `{"settings.interval": 10, "log_level": "debug"}`, "secret")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 1, plugin.updates)
+
+	config := response["config"].(map[string]interface{})
+	assert.Equal(t, "debug", config["log_level"])
+	settings := config["settings"].(map[string]interface{})
+	assert.Equal(t, float64(10), settings["interval"])
+	assert.Equal(t, "hello", settings["message"])
+
+	status, response = doDebugRequest(t, handler, http.MethodGet, "", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, float64(10), response["config"].(map[string]interface{})["settings"].(map[string]interface{})["interval"])
+}
+
+func TestDebugServer_ConfigMutationRequiresToken(t *testing.T) {
+	plugin := newDebugConfigPlugin()
+	ds := NewDebugServer("debug-test", nil,
+		WithDebugConfig(plugin),
+		WithDebugConfigMutation(true, ""),
+	)
+
+	// 未配置令牌时任何请求都不能修改配置
+	status, _ := doDebugRequest(t, ds.Handler(), http.MethodPatch, `{"settings.interval": 10}`, "")
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	status, _ = doDebugRequest(t, ds.Handler(), http.MethodDelete, "", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+	assert.Equal(t, 0, plugin.updates)
+}
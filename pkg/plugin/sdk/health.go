@@ -0,0 +1,278 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/plugin/api"
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// 健康状态
+const (
+	HealthStatusHealthy   = "healthy"   // 所有检查通过
+	HealthStatusDegraded  = "degraded"  // 可选检查失败，插件仍可提供服务
+	HealthStatusUnhealthy = "unhealthy" // 关键检查失败
+	HealthStatusUnknown   = "unknown"   // 插件未运行
+)
+
+// DefaultHealthCheckTimeout 子检查的默认超时时间
+const DefaultHealthCheckTimeout = 5 * time.Second
+
+// ErrDuplicateHealthCheck 同名健康检查已注册
+var ErrDuplicateHealthCheck = errors.New("健康检查已注册")
+
+// ErrHealthCheckTimeout 健康检查超时
+var ErrHealthCheckTimeout = errors.New("健康检查超时")
+
+// HealthCheckerFunc 健康检查子项，返回nil表示检查通过
+type HealthCheckerFunc func(ctx context.Context) error
+
+// HealthCheckOption 健康检查选项
+type HealthCheckOption func(*healthCheckOptions)
+
+// healthCheckOptions 健康检查配置
+type healthCheckOptions struct {
+	timeout  time.Duration
+	optional bool
+}
+
+// WithCheckTimeout 设置检查超时时间，默认为 DefaultHealthCheckTimeout
+func WithCheckTimeout(timeout time.Duration) HealthCheckOption {
+	return func(o *healthCheckOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithOptionalCheck 将检查标记为可选，失败时整体状态为 degraded 而不是 unhealthy
+func WithOptionalCheck() HealthCheckOption {
+	return func(o *healthCheckOptions) {
+		o.optional = true
+	}
+}
+
+// HealthCheckResult 单个子检查的结果
+type HealthCheckResult struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Optional bool          `json:"optional"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// healthCheck 已注册的子检查
+type healthCheck struct {
+	name string
+	fn   HealthCheckerFunc
+	opts healthCheckOptions
+}
+
+// HealthChecker 组合多个命名子检查
+// 子检查并发执行，各自有独立的超时时间，结果汇总为插件的整体健康状态
+type HealthChecker struct {
+	mu     sync.RWMutex
+	checks map[string]*healthCheck
+}
+
+// NewHealthChecker 创建健康检查器
+func NewHealthChecker() *HealthChecker {
+	return &HealthChecker{
+		checks: make(map[string]*healthCheck),
+	}
+}
+
+// Register 注册子检查，同名检查已存在时返回 ErrDuplicateHealthCheck
+func (h *HealthChecker) Register(name string, fn HealthCheckerFunc, opts ...HealthCheckOption) error {
+	if fn == nil {
+		return fmt.Errorf("健康检查 %s 缺少检查函数", name)
+	}
+
+	options := healthCheckOptions{timeout: DefaultHealthCheckTimeout}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.timeout <= 0 {
+		options.timeout = DefaultHealthCheckTimeout
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.checks[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateHealthCheck, name)
+	}
+	h.checks[name] = &healthCheck{name: name, fn: fn, opts: options}
+	return nil
+}
+
+// Unregister 移除子检查
+func (h *HealthChecker) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.checks, name)
+}
+
+// Len 返回已注册的子检查数量
+func (h *HealthChecker) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.checks)
+}
+
+// Run 并发执行所有子检查并汇总结果
+// 关键检查失败时为 unhealthy，只有可选检查失败时为 degraded，
+// Details 中 checks 字段为按名称排序的各子检查结果
+func (h *HealthChecker) Run(ctx context.Context) api.HealthStatus {
+	h.mu.RLock()
+	checks := make([]*healthCheck, 0, len(h.checks))
+	for _, check := range h.checks {
+		checks = append(checks, check)
+	}
+	h.mu.RUnlock()
+
+	sort.Slice(checks, func(i, j int) bool { return checks[i].name < checks[j].name })
+
+	results := make([]HealthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check *healthCheck) {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	status := HealthStatusHealthy
+	failed := 0
+	for _, result := range results {
+		if result.Status == HealthStatusHealthy {
+			continue
+		}
+		failed++
+		if !result.Optional {
+			status = HealthStatusUnhealthy
+		} else if status == HealthStatusHealthy {
+			status = HealthStatusDegraded
+		}
+	}
+
+	return api.HealthStatus{
+		Status: status,
+		Details: map[string]interface{}{
+			"checks": results,
+			"failed": failed,
+		},
+		LastChecked: time.Now(),
+	}
+}
+
+// runHealthCheck 在超时时间内执行单个子检查
+// 不响应 ctx 的检查函数在超时后继续在后台运行，但结果会被丢弃
+func runHealthCheck(ctx context.Context, check *healthCheck) HealthCheckResult {
+	start := time.Now()
+	result := HealthCheckResult{
+		Name:     check.name,
+		Status:   HealthStatusHealthy,
+		Optional: check.opts.optional,
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, check.opts.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("健康检查panic: %v", r)
+			}
+		}()
+		done <- check.fn(checkCtx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+		if err != nil && errors.Is(checkCtx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("%w (%s): %v", ErrHealthCheckTimeout, check.opts.timeout, err)
+		}
+	case <-checkCtx.Done():
+		err = checkCtx.Err()
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("%w (%s)", ErrHealthCheckTimeout, check.opts.timeout)
+		}
+	}
+
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Status = HealthStatusUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// DiskSpaceCheck 检查路径所在磁盘的可用空间不少于 minFreeBytes
+func DiskSpaceCheck(path string, minFreeBytes uint64) HealthCheckerFunc {
+	return func(ctx context.Context) error {
+		usage, err := disk.UsageWithContext(ctx, path)
+		if err != nil {
+			return fmt.Errorf("获取磁盘使用情况失败: %w", err)
+		}
+		if usage.Free < minFreeBytes {
+			return fmt.Errorf("磁盘可用空间不足: %s 剩余 %d 字节，要求至少 %d 字节", path, usage.Free, minFreeBytes)
+		}
+		return nil
+	}
+}
+
+// Pinger 可以探测连通性的依赖，*sql.DB 满足该接口
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// PingCheck 检查数据库等依赖可以连通
+func PingCheck(pinger Pinger) HealthCheckerFunc {
+	return func(ctx context.Context) error {
+		if err := pinger.PingContext(ctx); err != nil {
+			return fmt.Errorf("连接失败: %w", err)
+		}
+		return nil
+	}
+}
+
+// TCPCheck 检查外部服务的TCP地址可以连接
+func TCPCheck(address string) HealthCheckerFunc {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return fmt.Errorf("无法连接 %s: %w", address, err)
+		}
+		return conn.Close()
+	}
+}
+
+// HTTPCheck 检查外部服务的HTTP地址可访问，响应状态码不低于500视为失败
+func HTTPCheck(url string) HealthCheckerFunc {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("创建请求失败: %w", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("请求 %s 失败: %w", url, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s 返回状态码 %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
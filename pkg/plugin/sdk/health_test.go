@@ -0,0 +1,216 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/plugin/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passingCheck(ctx context.Context) error {
+	return nil
+}
+
+func failingCheck(ctx context.Context) error {
+	return errors.New("连接被拒绝")
+}
+
+func hangingCheck(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// checkResults 从健康状态中取出子检查结果
+func checkResults(t *testing.T, status api.HealthStatus) map[string]HealthCheckResult {
+	results, ok := status.Details["checks"].([]HealthCheckResult)
+	require.True(t, ok)
+
+	byName := make(map[string]HealthCheckResult, len(results))
+	for _, result := range results {
+		byName[result.Name] = result
+	}
+	return byName
+}
+
+func TestHealthChecker_Aggregation(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(h *HealthChecker)
+		status   string
+		failed   int
+	}{
+		{
+			name: "全部通过",
+			register: func(h *HealthChecker) {
+				require.NoError(t, h.Register("disk", passingCheck))
+				require.NoError(t, h.Register("cache", passingCheck, WithOptionalCheck()))
+			},
+			status: HealthStatusHealthy,
+		},
+		{
+			name: "可选检查失败",
+			register: func(h *HealthChecker) {
+				require.NoError(t, h.Register("disk", passingCheck))
+				require.NoError(t, h.Register("cache", failingCheck, WithOptionalCheck()))
+			},
+			status: HealthStatusDegraded,
+			failed: 1,
+		},
+		{
+			name: "关键检查失败",
+			register: func(h *HealthChecker) {
+				require.NoError(t, h.Register("database", failingCheck))
+				require.NoError(t, h.Register("cache", failingCheck, WithOptionalCheck()))
+			},
+			status: HealthStatusUnhealthy,
+			failed: 2,
+		},
+		{
+			name: "关键检查超时",
+			register: func(h *HealthChecker) {
+				require.NoError(t, h.Register("disk", passingCheck))
+				require.NoError(t, h.Register("upstream", hangingCheck, WithCheckTimeout(50*time.Millisecond)))
+			},
+			status: HealthStatusUnhealthy,
+			failed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealthChecker()
+			tt.register(h)
+
+			status := h.Run(context.Background())
+			assert.Equal(t, tt.status, status.Status)
+			assert.Equal(t, tt.failed, status.Details["failed"])
+			assert.False(t, status.LastChecked.IsZero())
+			assert.Len(t, checkResults(t, status), h.Len())
+		})
+	}
+}
+
+func TestHealthChecker_ResultDetails(t *testing.T) {
+	h := NewHealthChecker()
+	require.NoError(t, h.Register("disk", passingCheck))
+	require.NoError(t, h.Register("database", failingCheck))
+	require.NoError(t, h.Register("upstream", hangingCheck, WithCheckTimeout(50*time.Millisecond), WithOptionalCheck()))
+	// 不响应 ctx 的检查同样在超时后返回
+	require.NoError(t, h.Register("stuck", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, WithCheckTimeout(50*time.Millisecond), WithOptionalCheck()))
+	require.NoError(t, h.Register("panicky", func(ctx context.Context) error {
+		panic("检查崩溃")
+	}, WithOptionalCheck()))
+
+	start := time.Now()
+	status := h.Run(context.Background())
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "子检查应该并发执行并遵守超时")
+	assert.Equal(t, HealthStatusUnhealthy, status.Status)
+
+	results := checkResults(t, status)
+	assert.Equal(t, HealthStatusHealthy, results["disk"].Status)
+	assert.Empty(t, results["disk"].Error)
+
+	assert.Equal(t, HealthStatusUnhealthy, results["database"].Status)
+	assert.Contains(t, results["database"].Error, "连接被拒绝")
+	assert.False(t, results["database"].Optional)
+
+	for _, name := range []string{"upstream", "stuck"} {
+		assert.Equal(t, HealthStatusUnhealthy, results[name].Status)
+		assert.Contains(t, results[name].Error, ErrHealthCheckTimeout.Error())
+		assert.True(t, results[name].Optional)
+		assert.GreaterOrEqual(t, results[name].Duration, 50*time.Millisecond)
+	}
+	assert.Contains(t, results["panicky"].Error, "检查崩溃")
+}
+
+func TestHealthChecker_Register(t *testing.T) {
+	h := NewHealthChecker()
+	require.NoError(t, h.Register("disk", passingCheck))
+	assert.ErrorIs(t, h.Register("disk", passingCheck), ErrDuplicateHealthCheck)
+	assert.Error(t, h.Register("empty", nil))
+
+	h.Unregister("disk")
+	assert.Equal(t, 0, h.Len())
+	assert.Equal(t, HealthStatusHealthy, h.Run(context.Background()).Status)
+}
+
+func TestBasePlugin_HealthCheckAggregatesSubChecks(t *testing.T) {
+	ctx := context.Background()
+	p := NewBasePlugin(api.PluginInfo{ID: "health"}, nil)
+	require.NoError(t, p.HealthChecks().Register("cache", failingCheck, WithOptionalCheck()))
+
+	// 未运行时不执行子检查
+	status, err := p.HealthCheck(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, HealthStatusUnknown, status.Status)
+	assert.NotContains(t, status.Details, "checks")
+
+	require.NoError(t, p.Init(ctx, api.PluginConfig{}))
+	require.NoError(t, p.Start(ctx))
+	status, err = p.HealthCheck(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, HealthStatusDegraded, status.Status)
+	assert.Equal(t, HealthStatusDegraded, p.GetStats()["health"])
+
+	p.SetState(api.PluginStateFailed)
+	status, err = p.HealthCheck(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, HealthStatusUnhealthy, status.Status)
+}
+
+func TestPluginBuilder_WithHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	plugin := NewPluginBuilder("builder-health").
+		WithHealthCheck("database", failingCheck, WithCheckTimeout(time.Second)).
+		Build()
+	require.NoError(t, plugin.Init(ctx, api.PluginConfig{}))
+	require.NoError(t, plugin.Start(ctx))
+
+	status, err := plugin.HealthCheck(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, HealthStatusUnhealthy, status.Status)
+	assert.Contains(t, checkResults(t, status), "database")
+}
+
+func TestDependencyChecks(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, DiskSpaceCheck(t.TempDir(), 1)(ctx))
+	assert.Error(t, DiskSpaceCheck(t.TempDir(), ^uint64(0))(ctx))
+
+	assert.NoError(t, PingCheck(pingerFunc(func(ctx context.Context) error { return nil }))(ctx))
+	assert.Error(t, PingCheck(pingerFunc(failingCheck))(ctx))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	assert.NoError(t, TCPCheck(address)(ctx))
+	require.NoError(t, listener.Close())
+	assert.Error(t, TCPCheck(address)(ctx))
+
+	code := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+	assert.NoError(t, HTTPCheck(server.URL)(ctx))
+	code = http.StatusServiceUnavailable
+	assert.Error(t, HTTPCheck(server.URL)(ctx))
+}
+
+// pingerFunc 函数形式的 Pinger
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) PingContext(ctx context.Context) error {
+	return f(ctx)
+}
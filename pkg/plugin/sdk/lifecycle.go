@@ -0,0 +1,34 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lomehong/kennel/pkg/plugin/api"
+)
+
+// ErrInvalidStateTransition 无效的插件状态转换
+var ErrInvalidStateTransition = errors.New("无效的插件状态转换")
+
+// allowedTransitions 各生命周期操作允许的源状态
+// 生命周期：Created(unknown) → Initialized → Running → Stopped
+var allowedTransitions = map[string][]api.PluginState{
+	"init":  {api.PluginStateUnknown, api.PluginStateStopped, api.PluginStateFailed},
+	"start": {api.PluginStateInitialized, api.PluginStateStopped},
+	"stop":  {api.PluginStateRunning},
+}
+
+// checkTransition 检查当前状态是否允许执行操作，调用方需持有锁
+func (p *BasePlugin) checkTransition(action string) error {
+	for _, state := range allowedTransitions[action] {
+		if p.state == state {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: 插件 %s 当前状态为 %s，不能执行 %s", ErrInvalidStateTransition, p.info.ID, p.state, action)
+}
+
+// State 获取插件当前的生命周期状态
+func (p *BasePlugin) State() api.PluginState {
+	return p.GetState()
+}
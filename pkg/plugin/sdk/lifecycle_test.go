@@ -0,0 +1,58 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lomehong/kennel/pkg/plugin/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasePlugin_LifecycleTransitions(t *testing.T) {
+	ctx := context.Background()
+	p := NewBasePlugin(api.PluginInfo{ID: "lifecycle-test"}, nil)
+
+	assert.True(t, errors.Is(p.Start(ctx), ErrInvalidStateTransition))
+	assert.True(t, errors.Is(p.Stop(ctx), ErrInvalidStateTransition))
+	assert.Equal(t, api.PluginStateUnknown, p.State())
+
+	require.NoError(t, p.Init(ctx, api.PluginConfig{ID: "lifecycle-test"}))
+	assert.Equal(t, api.PluginStateInitialized, p.State())
+	assert.True(t, errors.Is(p.Init(ctx, api.PluginConfig{}), ErrInvalidStateTransition))
+
+	require.NoError(t, p.Start(ctx))
+	assert.Equal(t, api.PluginStateRunning, p.State())
+	assert.True(t, errors.Is(p.Start(ctx), ErrInvalidStateTransition))
+
+	require.NoError(t, p.Stop(ctx))
+	assert.True(t, errors.Is(p.Stop(ctx), ErrInvalidStateTransition))
+	assert.Equal(t, api.PluginStateStopped, p.State())
+
+	// 停止后可以重新启动
+	require.NoError(t, p.Start(ctx))
+	assert.Equal(t, api.PluginStateRunning, p.State())
+}
+
+func TestBasePlugin_ConcurrentStart(t *testing.T) {
+	ctx := context.Background()
+	p := NewBasePlugin(api.PluginInfo{ID: "lifecycle-concurrent"}, nil)
+	require.NoError(t, p.Init(ctx, api.PluginConfig{}))
+
+	var started int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if p.Start(ctx) == nil {
+				atomic.AddInt32(&started, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), started)
+}
@@ -36,9 +36,25 @@ type BasePlugin struct {
 	// 错误信息
 	lastError error
 
+	// 最后一个错误的发生时间
+	lastErrorTime time.Time
+
+	// 启动次数，首次启动之后的每次启动计为一次重启
+	startCount int
+
 	// 统计信息
 	stats map[string]interface{}
 
+	// 运行上下文，由 Start 的 ctx 派生，插件停止时取消
+	runCtx    context.Context
+	runCancel context.CancelFunc
+
+	// 后台任务
+	tasks *TaskGroup
+
+	// 健康检查子项
+	healthChecks *HealthChecker
+
 	// 互斥锁
 	mu sync.RWMutex
 }
@@ -49,6 +65,10 @@ func NewBasePlugin(info api.PluginInfo, logger hclog.Logger) *BasePlugin {
 		logger = hclog.NewNullLogger()
 	}
 
+	// 启动前的运行上下文处于已取消状态
+	runCtx, runCancel := context.WithCancel(context.Background())
+	runCancel()
+
 	return &BasePlugin{
 		info:   info,
 		state:  api.PluginStateUnknown,
@@ -58,7 +78,11 @@ func NewBasePlugin(info api.PluginInfo, logger hclog.Logger) *BasePlugin {
 			Details:     make(map[string]interface{}),
 			LastChecked: time.Now(),
 		},
-		stats: make(map[string]interface{}),
+		stats:        make(map[string]interface{}),
+		tasks:        NewTaskGroup(logger.Named(info.ID).Named("tasks")),
+		healthChecks: NewHealthChecker(),
+		runCtx:       runCtx,
+		runCancel:    runCancel,
 	}
 }
 
@@ -67,57 +91,122 @@ func (p *BasePlugin) GetInfo() api.PluginInfo {
 	return p.info
 }
 
-// Init 初始化插件
+// Init 初始化插件，只能在创建后、停止后或失败后调用
 func (p *BasePlugin) Init(ctx context.Context, config api.PluginConfig) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if err := p.checkTransition("init"); err != nil {
+		p.logger.Warn("初始化插件失败", "id", p.info.ID, "error", err)
+		return err
+	}
+
 	p.logger.Info("初始化插件", "id", p.info.ID)
 	p.config = config
 	p.state = api.PluginStateInitialized
 	return nil
 }
 
-// Start 启动插件
+// Start 启动插件，必须先初始化，重复启动返回错误
+// 运行上下文由 ctx 派生，ctx 被取消或插件停止时取消，见 Context
 func (p *BasePlugin) Start(ctx context.Context) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if err := p.checkTransition("start"); err != nil {
+		p.logger.Warn("启动插件失败", "id", p.info.ID, "error", err)
+		return err
+	}
+
 	p.logger.Info("启动插件", "id", p.info.ID)
+	p.runCancel()
+	p.runCtx, p.runCancel = context.WithCancel(ctx)
 	p.state = api.PluginStateRunning
 	p.startTime = time.Now()
+	p.startCount++
 	return nil
 }
 
-// Stop 停止插件
+// Stop 停止插件，只能停止运行中的插件
+// 先取消运行上下文，再取消通过 Tasks 启动的后台任务，并在 ctx 到期前等待其退出
 func (p *BasePlugin) Stop(ctx context.Context) error {
 	p.mu.Lock()
-	defer p.mu.Unlock()
+	if err := p.checkTransition("stop"); err != nil {
+		p.mu.Unlock()
+		p.logger.Warn("停止插件失败", "id", p.info.ID, "error", err)
+		return err
+	}
 
 	p.logger.Info("停止插件", "id", p.info.ID)
+	p.state = api.PluginStateStopping
+	p.runCancel()
+	p.mu.Unlock()
+
+	// 等待任务退出时不持有锁，任务中仍可访问插件状态
+	err := p.tasks.Stop(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.state = api.PluginStateStopped
 	p.stopTime = time.Now()
+	if err != nil {
+		p.setLastError(err)
+		p.logger.Warn("后台任务未能全部退出", "id", p.info.ID, "error", err)
+		return err
+	}
 	return nil
 }
 
-// HealthCheck 执行健康检查
-func (p *BasePlugin) HealthCheck(ctx context.Context) (api.HealthStatus, error) {
+// Context 返回插件的运行上下文，插件停止后被取消
+// 即使调用方传给 Start 的 ctx 不会被取消，运行上下文也保证在 Stop 时取消；
+// 不通过 Tasks 启动的后台goroutine应监听该上下文并在取消后退出
+func (p *BasePlugin) Context() context.Context {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
+	return p.runCtx
+}
 
-	// 更新最后检查时间
-	p.health.LastChecked = time.Now()
+// Tasks 返回插件的后台任务组
+// 插件应通过任务组启动长期运行的goroutine，插件停止时统一取消并等待退出
+func (p *BasePlugin) Tasks() *TaskGroup {
+	return p.tasks
+}
 
-	// 根据状态设置健康状态
-	if p.state == api.PluginStateRunning {
-		p.health.Status = "healthy"
-	} else if p.state == api.PluginStateFailed {
-		p.health.Status = "unhealthy"
-	} else {
-		p.health.Status = "unknown"
+// HealthChecks 返回插件的健康检查器
+// 插件可以注册磁盘空间、数据库连接、外部服务等子检查，运行时由 HealthCheck 汇总
+func (p *BasePlugin) HealthChecks() *HealthChecker {
+	return p.healthChecks
+}
+
+// HealthCheck 执行健康检查
+// 插件运行中且注册了子检查时，整体状态由子检查结果汇总得出
+func (p *BasePlugin) HealthCheck(ctx context.Context) (api.HealthStatus, error) {
+	p.mu.RLock()
+	state := p.state
+	p.mu.RUnlock()
+
+	var health api.HealthStatus
+	switch {
+	case state == api.PluginStateRunning && p.healthChecks.Len() > 0:
+		// 执行子检查时不持有锁，检查中仍可访问插件状态
+		health = p.healthChecks.Run(ctx)
+	case state == api.PluginStateRunning:
+		health = api.HealthStatus{Status: HealthStatusHealthy}
+	case state == api.PluginStateFailed:
+		health = api.HealthStatus{Status: HealthStatusUnhealthy}
+	default:
+		health = api.HealthStatus{Status: HealthStatusUnknown}
+	}
+	if health.Details == nil {
+		health.Details = make(map[string]interface{})
 	}
+	health.LastChecked = time.Now()
 
-	return p.health, nil
+	p.mu.Lock()
+	p.health = health
+	p.mu.Unlock()
+
+	return health, nil
 }
 
 // GetState 获取插件状态
@@ -186,7 +275,50 @@ func (p *BasePlugin) GetLastError() error {
 func (p *BasePlugin) SetLastError(err error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	p.setLastError(err)
+}
+
+// setLastError 记录最后一个错误及其发生时间，调用方需持有锁
+func (p *BasePlugin) setLastError(err error) {
 	p.lastError = err
+	if err != nil {
+		p.lastErrorTime = time.Now()
+	}
+}
+
+// GetRestartCount 获取插件的重启次数
+func (p *BasePlugin) GetRestartCount() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.restartCount()
+}
+
+// restartCount 返回首次启动之后的启动次数，调用方需持有锁
+func (p *BasePlugin) restartCount() int {
+	if p.startCount == 0 {
+		return 0
+	}
+	return p.startCount - 1
+}
+
+// GetRuntimeInfo 返回插件的运行时信息，实现 api.RuntimeInfoProvider
+func (p *BasePlugin) GetRuntimeInfo() api.PluginRuntimeInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	info := api.PluginRuntimeInfo{
+		State:         p.state,
+		StartTime:     p.startTime,
+		RestartCount:  p.restartCount(),
+		LastErrorTime: p.lastErrorTime,
+	}
+	if p.state == api.PluginStateRunning {
+		info.Uptime = time.Since(p.startTime)
+	}
+	if p.lastError != nil {
+		info.LastError = p.lastError.Error()
+	}
+	return info
 }
 
 // GetStats 获取统计信息
@@ -34,6 +34,12 @@ type PluginRunner struct {
 	// 取消函数
 	cancel context.CancelFunc
 
+	// 传给插件 Start 的上下文的取消函数，停止插件前调用
+	cancelPlugin context.CancelFunc
+
+	// 插件关闭完成后关闭
+	done chan struct{}
+
 	// 配置
 	config RunnerConfig
 }
@@ -104,12 +110,16 @@ func NewPluginRunner(plugin api.Plugin, config RunnerConfig) *PluginRunner {
 		logger: logger,
 		ctx:    ctx,
 		cancel: cancel,
+		done:   make(chan struct{}),
 		config: config,
 	}
 }
 
-// Run 运行插件
+// Run 运行插件，直到收到 SIGINT/SIGTERM 或调用 Stop
+// 传给插件 Start 的上下文由运行器的上下文派生，停止插件前一定会被取消，
+// 插件在 Start 中启动的后台任务必须在该上下文取消后退出
 func (r *PluginRunner) Run() error {
+	defer close(r.done)
 	r.logger.Info("启动插件", "id", r.plugin.GetInfo().ID, "version", r.plugin.GetInfo().Version)
 
 	// 设置信号处理
@@ -140,7 +150,10 @@ func (r *PluginRunner) Run() error {
 
 	// 启动插件
 	r.logger.Info("启动插件")
-	if err := r.plugin.Start(r.ctx); err != nil {
+	pluginCtx, cancelPlugin := context.WithCancel(r.ctx)
+	r.cancelPlugin = cancelPlugin
+	if err := r.plugin.Start(pluginCtx); err != nil {
+		cancelPlugin()
 		r.logger.Error("启动插件失败", "error", err)
 		return fmt.Errorf("启动插件失败: %w", err)
 	}
@@ -164,15 +177,31 @@ func (r *PluginRunner) Run() error {
 	return nil
 }
 
-// setupSignalHandling 设置信号处理
+// Stop 停止运行器：取消插件的上下文并停止插件，阻塞到 Run 返回
+// 只能在 Run 已经开始后调用
+func (r *PluginRunner) Stop() {
+	r.cancel()
+	<-r.done
+}
+
+// Done 返回插件关闭完成后关闭的通道
+func (r *PluginRunner) Done() <-chan struct{} {
+	return r.done
+}
+
+// setupSignalHandling 设置信号处理，运行器停止后不再监听信号
 func (r *PluginRunner) setupSignalHandling() {
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		sig := <-signalCh
-		r.logger.Info("收到信号", "signal", sig)
-		r.cancel()
+		defer signal.Stop(signalCh)
+		select {
+		case sig := <-signalCh:
+			r.logger.Info("收到信号", "signal", sig)
+			r.cancel()
+		case <-r.ctx.Done():
+		}
 	}()
 }
 
@@ -215,6 +244,11 @@ func (r *PluginRunner) startGRPCServer() {
 func (r *PluginRunner) shutdown() {
 	r.logger.Info("关闭插件")
 
+	// 先取消插件的上下文，即使插件未监听 Stop 也能让后台任务退出
+	if r.cancelPlugin != nil {
+		r.cancelPlugin()
+	}
+
 	// 创建带超时的上下文
 	ctx, cancel := context.WithTimeout(context.Background(), r.config.ShutdownTimeout)
 	defer cancel()
@@ -0,0 +1,90 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/plugin/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopPlugin 后台循环只在 Start 的上下文取消后退出
+type loopPlugin struct {
+	*BasePlugin
+	started chan struct{}
+	exited  chan struct{}
+}
+
+func newLoopPlugin() *loopPlugin {
+	return &loopPlugin{
+		BasePlugin: NewBasePlugin(api.PluginInfo{ID: "loop-test"}, nil),
+		started:    make(chan struct{}),
+		exited:     make(chan struct{}),
+	}
+}
+
+func (p *loopPlugin) Start(ctx context.Context) error {
+	if err := p.BasePlugin.Start(ctx); err != nil {
+		return err
+	}
+	go func() {
+		defer close(p.exited)
+		close(p.started)
+		<-ctx.Done()
+	}()
+	return nil
+}
+
+func TestPluginRunner_StopCancelsStartContext(t *testing.T) {
+	p := newLoopPlugin()
+	config := DefaultRunnerConfig()
+	config.Protocol = ProtocolInProcess
+	config.HealthCheckInterval = 0
+	config.ShutdownTimeout = time.Second
+	runner := NewPluginRunner(p, config)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- runner.Run() }()
+
+	select {
+	case <-p.started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("插件后台循环未启动")
+	}
+
+	runner.Stop()
+	require.NoError(t, <-errCh)
+
+	select {
+	case <-p.exited:
+	case <-time.After(time.Second):
+		t.Fatal("停止运行器后插件后台循环应退出")
+	}
+	assert.Equal(t, api.PluginStateStopped, p.State())
+}
+
+func TestBasePlugin_StopCancelsContext(t *testing.T) {
+	ctx := context.Background()
+	p := NewBasePlugin(api.PluginInfo{ID: "context-test"}, nil)
+
+	assert.Error(t, p.Context().Err(), "启动前上下文应已取消")
+
+	require.NoError(t, p.Init(ctx, api.PluginConfig{ID: "context-test"}))
+	require.NoError(t, p.Start(ctx))
+	runCtx := p.Context()
+	assert.NoError(t, runCtx.Err(), "运行期间上下文不应取消")
+
+	require.NoError(t, p.Stop(ctx))
+	select {
+	case <-runCtx.Done():
+	default:
+		t.Fatal("即使 Start 传入不可取消的上下文，Stop 后插件上下文也应取消")
+	}
+
+	// 重新启动后获得新的上下文
+	require.NoError(t, p.Start(ctx))
+	assert.NoError(t, p.Context().Err())
+	require.NoError(t, p.Stop(ctx))
+}
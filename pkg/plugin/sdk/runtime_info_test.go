@@ -0,0 +1,82 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/plugin/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasePlugin_RuntimeInfo(t *testing.T) {
+	ctx := context.Background()
+	p := NewBasePlugin(api.PluginInfo{ID: "runtime-test"}, nil)
+	var _ api.RuntimeInfoProvider = p
+
+	info := p.GetRuntimeInfo()
+	assert.Equal(t, api.PluginStateUnknown, info.State)
+	assert.Zero(t, info.Uptime)
+	assert.Zero(t, info.RestartCount)
+	assert.Empty(t, info.LastError)
+
+	require.NoError(t, p.Init(ctx, api.PluginConfig{ID: "runtime-test"}))
+	require.NoError(t, p.Start(ctx))
+
+	// 运行时长随时间增加
+	first := p.GetRuntimeInfo()
+	assert.Equal(t, api.PluginStateRunning, first.State)
+	time.Sleep(5 * time.Millisecond)
+	second := p.GetRuntimeInfo()
+	assert.Greater(t, second.Uptime, first.Uptime)
+	assert.Equal(t, first.StartTime, second.StartTime)
+	assert.Zero(t, second.RestartCount)
+
+	// 每次停止后重新启动计为一次重启，运行时长从新的启动时间开始计算
+	for i := 1; i <= 2; i++ {
+		require.NoError(t, p.Stop(ctx))
+		stopped := p.GetRuntimeInfo()
+		assert.Equal(t, api.PluginStateStopped, stopped.State)
+		assert.Zero(t, stopped.Uptime)
+
+		require.NoError(t, p.Start(ctx))
+		restarted := p.GetRuntimeInfo()
+		assert.Equal(t, i, restarted.RestartCount)
+		assert.True(t, restarted.StartTime.After(first.StartTime))
+		assert.Less(t, restarted.Uptime, second.Uptime)
+	}
+	assert.Equal(t, 2, p.GetRestartCount())
+
+	// 记录最后一个错误
+	before := time.Now()
+	p.SetLastError(errors.New("连接上游服务失败"))
+	info = p.GetRuntimeInfo()
+	assert.Equal(t, "连接上游服务失败", info.LastError)
+	assert.False(t, info.LastErrorTime.Before(before))
+}
+
+func TestBasePlugin_RuntimeInfoCapturesStopError(t *testing.T) {
+	ctx := context.Background()
+	p := NewBasePlugin(api.PluginInfo{ID: "runtime-stop-error"}, nil)
+	require.NoError(t, p.Init(ctx, api.PluginConfig{}))
+	require.NoError(t, p.Start(ctx))
+
+	// 不响应取消的任务导致停止超时
+	release := make(chan struct{})
+	defer close(release)
+	require.NoError(t, p.Tasks().Go("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	}))
+
+	stopCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	err := p.Stop(stopCtx)
+	require.Error(t, err)
+
+	info := p.GetRuntimeInfo()
+	assert.Equal(t, err.Error(), info.LastError)
+	assert.False(t, info.LastErrorTime.IsZero())
+}
@@ -0,0 +1,299 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// ErrDuplicateTask 同名任务正在运行
+var ErrDuplicateTask = errors.New("任务已在运行")
+
+// TaskFunc 后台任务函数，必须在 ctx 取消后返回
+type TaskFunc func(ctx context.Context) error
+
+// RestartPolicy 任务退出后的重启策略
+type RestartPolicy int
+
+const (
+	// RestartNever 任务退出后不重启
+	RestartNever RestartPolicy = iota
+	// RestartOnFailure 任务返回错误或panic时重启
+	RestartOnFailure
+	// RestartAlways 任务退出后总是重启，直到任务组停止
+	RestartAlways
+)
+
+// String 返回重启策略名称
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartNever:
+		return "never"
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(p))
+	}
+}
+
+// TaskOption 任务选项
+type TaskOption func(*taskOptions)
+
+// taskOptions 任务配置
+type taskOptions struct {
+	restart     RestartPolicy
+	maxRestarts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// WithRestartPolicy 设置任务的重启策略，默认不重启
+func WithRestartPolicy(policy RestartPolicy) TaskOption {
+	return func(o *taskOptions) {
+		o.restart = policy
+	}
+}
+
+// WithMaxRestarts 设置最大重启次数，0表示不限制
+func WithMaxRestarts(n int) TaskOption {
+	return func(o *taskOptions) {
+		o.maxRestarts = n
+	}
+}
+
+// WithRestartBackoff 设置重启间隔，每次重启翻倍，最长不超过 max
+func WithRestartBackoff(initial, max time.Duration) TaskOption {
+	return func(o *taskOptions) {
+		o.backoff = initial
+		o.maxBackoff = max
+	}
+}
+
+// TaskStatus 任务运行状态
+type TaskStatus struct {
+	Name      string    `json:"name"`
+	Running   bool      `json:"running"`
+	Restarts  int       `json:"restarts"`
+	Panics    int       `json:"panics"`
+	LastError string    `json:"last_error,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// task 受监督的任务
+type task struct {
+	name   string
+	fn     TaskFunc
+	opts   taskOptions
+	status TaskStatus
+}
+
+// taskGeneration 一次运行周期内启动的任务，任务组停止后重新使用时创建新的周期
+type taskGeneration struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// TaskGroup 管理插件的后台goroutine
+// 通过 Go 启动的任务在 Stop 时统一取消并等待退出，任务panic会被恢复并按重启策略重新运行
+type TaskGroup struct {
+	logger hclog.Logger
+	mu     sync.Mutex
+	gen    *taskGeneration
+	tasks  map[string]*task
+}
+
+// NewTaskGroup 创建任务组
+func NewTaskGroup(logger hclog.Logger) *TaskGroup {
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+	return &TaskGroup{
+		logger: logger,
+		tasks:  make(map[string]*task),
+	}
+}
+
+// Go 启动受监督的后台任务，同名任务仍在运行时返回 ErrDuplicateTask
+func (g *TaskGroup) Go(name string, fn TaskFunc, opts ...TaskOption) error {
+	if fn == nil {
+		return fmt.Errorf("任务 %s 缺少任务函数", name)
+	}
+
+	options := taskOptions{
+		backoff:    100 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if existing, ok := g.tasks[name]; ok && existing.status.Running {
+		return fmt.Errorf("%w: %s", ErrDuplicateTask, name)
+	}
+
+	if g.gen == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		g.gen = &taskGeneration{ctx: ctx, cancel: cancel}
+	}
+
+	t := &task{
+		name: name,
+		fn:   fn,
+		opts: options,
+		status: TaskStatus{
+			Name:      name,
+			Running:   true,
+			StartedAt: time.Now(),
+		},
+	}
+	g.tasks[name] = t
+
+	gen := g.gen
+	gen.wg.Add(1)
+	go g.supervise(gen, t)
+	return nil
+}
+
+// Stop 取消所有任务并等待退出
+// ctx 到期时仍未退出的任务会在错误中列出
+func (g *TaskGroup) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	gen := g.gen
+	g.gen = nil
+	g.mu.Unlock()
+
+	if gen == nil {
+		return nil
+	}
+	gen.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		gen.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("等待后台任务退出超时，仍在运行: %v", g.runningNames())
+	}
+}
+
+// Running 返回正在运行的任务数量
+func (g *TaskGroup) Running() int {
+	return len(g.runningNames())
+}
+
+// Status 返回所有任务的状态，按名称排序
+func (g *TaskGroup) Status() []TaskStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	statuses := make([]TaskStatus, 0, len(g.tasks))
+	for _, t := range g.tasks {
+		statuses = append(statuses, t.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// runningNames 返回正在运行的任务名称
+func (g *TaskGroup) runningNames() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var names []string
+	for name, t := range g.tasks {
+		if t.status.Running {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// supervise 运行任务并按重启策略重启
+func (g *TaskGroup) supervise(gen *taskGeneration, t *task) {
+	defer gen.wg.Done()
+	defer g.update(t, func(s *TaskStatus) { s.Running = false })
+
+	backoff := t.opts.backoff
+	for {
+		err, panicked := g.runOnce(gen.ctx, t)
+		if gen.ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			g.logger.Warn("后台任务异常退出", "task", t.name, "error", err)
+		}
+		if !t.shouldRestart(err, panicked) {
+			return
+		}
+
+		g.logger.Info("重启后台任务", "task", t.name, "policy", t.opts.restart, "restarts", t.status.Restarts+1, "backoff", backoff)
+		select {
+		case <-gen.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		g.update(t, func(s *TaskStatus) { s.Restarts++ })
+		backoff *= 2
+		if backoff > t.opts.maxBackoff {
+			backoff = t.opts.maxBackoff
+		}
+	}
+}
+
+// runOnce 运行一次任务，恢复panic
+func (g *TaskGroup) runOnce(ctx context.Context, t *task) (err error, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			err = fmt.Errorf("任务panic: %v", r)
+			g.logger.Error("后台任务panic", "task", t.name, "panic", r, "stack", string(debug.Stack()))
+			g.update(t, func(s *TaskStatus) { s.Panics++ })
+		}
+		if err != nil {
+			g.update(t, func(s *TaskStatus) { s.LastError = err.Error() })
+		}
+	}()
+
+	return t.fn(ctx), false
+}
+
+// shouldRestart 根据重启策略和已重启次数判断是否重启，调用时任务状态只由本goroutine修改
+func (t *task) shouldRestart(err error, panicked bool) bool {
+	switch t.opts.restart {
+	case RestartAlways:
+	case RestartOnFailure:
+		if err == nil && !panicked {
+			return false
+		}
+	default:
+		return false
+	}
+	return t.opts.maxRestarts <= 0 || t.status.Restarts < t.opts.maxRestarts
+}
+
+// update 在锁内修改任务状态
+func (g *TaskGroup) update(t *task, fn func(*TaskStatus)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fn(&t.status)
+}
@@ -0,0 +1,149 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/plugin/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasePlugin_StopCancelsTasks(t *testing.T) {
+	ctx := context.Background()
+	before := runtime.NumGoroutine()
+
+	p := NewBasePlugin(api.PluginInfo{ID: "tasks-stop"}, nil)
+	require.NoError(t, p.Init(ctx, api.PluginConfig{}))
+	require.NoError(t, p.Start(ctx))
+
+	var exited int32
+	for _, name := range []string{"collector", "uploader", "watcher"} {
+		require.NoError(t, p.Tasks().Go(name, func(ctx context.Context) error {
+			<-ctx.Done()
+			atomic.AddInt32(&exited, 1)
+			return nil
+		}))
+	}
+	assert.Equal(t, 3, p.Tasks().Running())
+	assert.ErrorIs(t, p.Tasks().Go("collector", func(ctx context.Context) error { return nil }), ErrDuplicateTask)
+
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	require.NoError(t, p.Stop(stopCtx))
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&exited))
+	assert.Equal(t, 0, p.Tasks().Running())
+	assert.Equal(t, api.PluginStateStopped, p.State())
+	assertNoGoroutineLeak(t, before)
+
+	// 重新启动后可以继续使用任务组
+	require.NoError(t, p.Start(ctx))
+	require.NoError(t, p.Tasks().Go("collector", func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	}))
+	require.NoError(t, p.Stop(ctx))
+	assertNoGoroutineLeak(t, before)
+}
+
+func TestBasePlugin_StopTimeout(t *testing.T) {
+	ctx := context.Background()
+	p := NewBasePlugin(api.PluginInfo{ID: "tasks-timeout"}, nil)
+	require.NoError(t, p.Init(ctx, api.PluginConfig{}))
+	require.NoError(t, p.Start(ctx))
+
+	release := make(chan struct{})
+	require.NoError(t, p.Tasks().Go("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	}))
+
+	stopCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	err := p.Stop(stopCtx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stuck")
+	assert.Equal(t, api.PluginStateStopped, p.State())
+	assert.Equal(t, err, p.GetLastError())
+
+	close(release)
+	assert.Eventually(t, func() bool { return p.Tasks().Running() == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestTaskGroup_PanicRestartsPerPolicy(t *testing.T) {
+	group := NewTaskGroup(nil)
+
+	var runs int32
+	require.NoError(t, group.Go("flaky", func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		panic("boom")
+	}, WithRestartPolicy(RestartOnFailure), WithMaxRestarts(2), WithRestartBackoff(time.Millisecond, time.Millisecond)))
+
+	assert.Eventually(t, func() bool { return group.Running() == 0 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&runs))
+
+	status := group.Status()
+	require.Len(t, status, 1)
+	assert.Equal(t, "flaky", status[0].Name)
+	assert.Equal(t, 2, status[0].Restarts)
+	assert.Equal(t, 3, status[0].Panics)
+	assert.Contains(t, status[0].LastError, "boom")
+	require.NoError(t, group.Stop(context.Background()))
+}
+
+func TestTaskGroup_RestartPolicies(t *testing.T) {
+	tests := map[string]struct {
+		policy RestartPolicy
+		err    error
+		runs   int32
+	}{
+		"never不重启":         {RestartNever, errors.New("failed"), 1},
+		"on-failure成功后不重启": {RestartOnFailure, nil, 1},
+		"on-failure失败后重启":  {RestartOnFailure, errors.New("failed"), 3},
+		"always成功后也重启":     {RestartAlways, nil, 3},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			group := NewTaskGroup(nil)
+			var runs int32
+			require.NoError(t, group.Go("task", func(ctx context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return tt.err
+			}, WithRestartPolicy(tt.policy), WithMaxRestarts(2), WithRestartBackoff(time.Millisecond, time.Millisecond)))
+
+			assert.Eventually(t, func() bool { return group.Running() == 0 }, time.Second, 5*time.Millisecond)
+			assert.Equal(t, tt.runs, atomic.LoadInt32(&runs))
+			require.NoError(t, group.Stop(context.Background()))
+		})
+	}
+}
+
+func TestTaskGroup_StopInterruptsBackoff(t *testing.T) {
+	before := runtime.NumGoroutine()
+	group := NewTaskGroup(nil)
+
+	require.NoError(t, group.Go("retry", func(ctx context.Context) error {
+		return errors.New("unavailable")
+	}, WithRestartPolicy(RestartAlways), WithRestartBackoff(time.Hour, time.Hour)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, group.Stop(ctx))
+	assertNoGoroutineLeak(t, before)
+}
+
+// assertNoGoroutineLeak 等待goroutine数量回落到基准值
+// 不使用 assert.Eventually，它自身会启动goroutine
+func assertNoGoroutineLeak(t *testing.T, before int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before, "goroutine泄漏")
+}
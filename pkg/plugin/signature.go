@@ -0,0 +1,50 @@
+package plugin
+
+import (
+	"fmt"
+
+	coreplugin "github.com/lomehong/kennel/pkg/core/plugin"
+)
+
+// WithSignatureVerification 设置插件签名校验
+// store 为nil时没有受信任的密钥，enforce 模式下所有插件都将被拒绝
+func WithSignatureVerification(store *coreplugin.TrustStore, mode coreplugin.SignatureMode) PluginManagerOption {
+	return func(pm *PluginManager) {
+		pm.trustStore = store
+		pm.signatureMode = mode
+	}
+}
+
+// verifySignature 按签名校验模式校验将要执行的插件二进制文件
+// 清单中的入口点可能是源码路径，这里始终校验实际执行的文件
+func (pm *PluginManager) verifySignature(id string, manifest *coreplugin.PluginMetadata, pluginPath string) error {
+	if pm.signatureMode == coreplugin.SignatureModeDisabled || pm.signatureMode == "" {
+		return nil
+	}
+
+	metadata := coreplugin.PluginMetadata{ID: id}
+	if manifest != nil {
+		metadata = *manifest
+	}
+	metadata.Path = ""
+	metadata.EntryPoint.Path = pluginPath
+
+	store := pm.trustStore
+	if store == nil {
+		store = coreplugin.NewTrustStore()
+	}
+
+	err := coreplugin.VerifyPluginSignature(metadata, store)
+	if err == nil {
+		pm.logger.Info("插件签名校验通过", "id", id, "key_id", metadata.Signature.KeyID)
+		return nil
+	}
+
+	if pm.signatureMode == coreplugin.SignatureModeWarn {
+		pm.logger.Warn("插件签名校验失败，开发模式下继续加载", "id", id, "path", pluginPath, "error", err)
+		return nil
+	}
+
+	pm.logger.Error("插件签名校验失败，拒绝加载", "id", id, "path", pluginPath, "error", err)
+	return fmt.Errorf("插件 %s 签名校验失败: %w", id, err)
+}
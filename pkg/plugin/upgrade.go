@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// UpgradePlugins 重新读取运行中插件的清单，将版本发生变化的插件原地升级
+// 返回升级成功的插件ID；升级失败的插件继续运行旧版本，错误合并返回
+func (pm *PluginManager) UpgradePlugins() ([]string, error) {
+	pm.mu.RLock()
+	var candidates []string
+	for id, plugin := range pm.plugins {
+		if plugin.State == PluginStateRunning {
+			candidates = append(candidates, id)
+		}
+	}
+	pm.mu.RUnlock()
+
+	var upgraded []string
+	var errs []error
+	for _, id := range candidates {
+		changed, err := pm.needsUpgrade(id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if err := pm.UpgradePlugin(id); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		upgraded = append(upgraded, id)
+	}
+
+	return upgraded, errors.Join(errs...)
+}
+
+// needsUpgrade 检查插件清单中的版本是否与运行中的版本不同，没有清单的插件无法判断版本，不升级
+func (pm *PluginManager) needsUpgrade(id string) (bool, error) {
+	pm.mu.RLock()
+	plugin, exists := pm.plugins[id]
+	if !exists {
+		pm.mu.RUnlock()
+		return false, fmt.Errorf("插件 %s 不存在", id)
+	}
+	config, path, current := plugin.Config, plugin.Path, plugin.Manifest
+	pm.mu.RUnlock()
+
+	manifest, err := pm.loadManifest(config, path)
+	if err != nil {
+		return false, fmt.Errorf("插件 %s: %w", id, err)
+	}
+	if manifest == nil {
+		return false, nil
+	}
+	return current == nil || current.Version != manifest.Version, nil
+}
+
+// UpgradePlugin 使用插件目录中的当前版本原地升级运行中的插件
+// 先启动新版本进程并确认可用，再将插件切换到新进程并停止旧进程；新版本启动失败时旧版本继续运行
+func (pm *PluginManager) UpgradePlugin(id string) error {
+	pm.mu.RLock()
+	plugin, exists := pm.plugins[id]
+	if !exists {
+		pm.mu.RUnlock()
+		return fmt.Errorf("插件 %s 不存在", id)
+	}
+	if plugin.State != PluginStateRunning {
+		pm.mu.RUnlock()
+		return fmt.Errorf("插件 %s 未在运行", id)
+	}
+	config, pluginPath := plugin.Config, plugin.Path
+	pm.mu.RUnlock()
+
+	pm.logger.Info("开始升级插件", "id", id, "path", pluginPath)
+
+	// 重新读取清单，新版本的权限和签名以新清单为准
+	manifest, err := pm.loadManifest(config, pluginPath)
+	if err != nil {
+		return fmt.Errorf("升级插件 %s 失败: %w", id, err)
+	}
+	if err := pm.verifySignature(id, manifest, pluginPath); err != nil {
+		return fmt.Errorf("升级插件 %s 失败: %w", id, err)
+	}
+	guard := pm.permissionGuard(config, manifest)
+
+	client, instance, err := pm.launchPlugin(id, pluginPath, manifest, guard)
+	if err != nil {
+		pm.logger.Error("启动新版本插件失败，继续运行旧版本", "id", id, "error", err)
+		return fmt.Errorf("升级插件 %s 失败: %w", id, err)
+	}
+
+	// 确认新版本进程可用
+	if rpcClient, err := client.Client(); err != nil || rpcClient.Ping() != nil {
+		client.Kill()
+		pm.logger.Error("新版本插件不可用，继续运行旧版本", "id", id)
+		return fmt.Errorf("升级插件 %s 失败: 新版本插件不可用", id)
+	}
+
+	pm.mu.Lock()
+	if plugin.State != PluginStateRunning {
+		pm.mu.Unlock()
+		client.Kill()
+		return fmt.Errorf("升级插件 %s 失败: 插件在升级过程中已停止", id)
+	}
+	old := plugin.Client
+	plugin.Client = client
+	plugin.Interface = instance
+	plugin.Manifest = manifest
+	plugin.Guard = guard
+	if manifest != nil && manifest.Version != "" {
+		plugin.Version = manifest.Version
+	}
+	plugin.StartTime = time.Now()
+	version := plugin.Version
+	pm.mu.Unlock()
+
+	// 停止旧版本进程
+	if old != nil {
+		old.Kill()
+	}
+
+	pm.logger.Info("插件已升级", "id", id, "version", version)
+	return nil
+}
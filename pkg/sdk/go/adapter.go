@@ -12,6 +12,14 @@ import (
 // ModuleAdapter 是一个适配器，将 core/plugin.Module 接口转换为 plugin.Module 接口
 type ModuleAdapter struct {
 	Module plugin.Module
+
+	// 主机服务客户端，插件服务启动时设置
+	host *pluginLib.Host
+}
+
+// SetHost 实现了 pluginLib.HostConsumer 接口
+func (a *ModuleAdapter) SetHost(host *pluginLib.Host) {
+	a.host = host
 }
 
 // Init 实现了 plugin.Module 接口的 Init 方法
@@ -20,6 +28,9 @@ func (a *ModuleAdapter) Init(config map[string]interface{}) error {
 	moduleConfig := &plugin.ModuleConfig{
 		Settings: config,
 	}
+	if a.host != nil {
+		moduleConfig.Services = a.host.Services()
+	}
 
 	// 调用原始模块的 Init 方法
 	return a.Module.Init(context.Background(), moduleConfig)
@@ -27,15 +38,27 @@ func (a *ModuleAdapter) Init(config map[string]interface{}) error {
 
 // Execute 实现了 plugin.Module 接口的 Execute 方法
 func (a *ModuleAdapter) Execute(action string, params map[string]interface{}) (map[string]interface{}, error) {
+	return a.ExecuteWithMetadata(action, params, nil)
+}
+
+// ExecuteWithMetadata 实现了 plugin.MetadataExecutor 接口
+// 主机提供的元数据写入请求和上下文，请求ID优先使用元数据中的 request_id
+func (a *ModuleAdapter) ExecuteWithMetadata(action string, params map[string]interface{}, metadata map[string]string) (map[string]interface{}, error) {
 	// 创建请求
 	req := &plugin.Request{
-		ID:     fmt.Sprintf("req-%d", time.Now().UnixNano()),
-		Action: action,
-		Params: params,
+		ID:       metadata["request_id"],
+		Action:   action,
+		Params:   params,
+		Metadata: metadata,
+	}
+	if req.ID == "" {
+		req.ID = fmt.Sprintf("req-%d", time.Now().UnixNano())
 	}
 
 	// 调用原始模块的 HandleRequest 方法
-	resp, err := a.Module.HandleRequest(context.Background(), req)
+	ctx := ContextWithRequestMetadata(context.Background(), metadata)
+	resp, err := a.Module.HandleRequest(ctx, req)
+	a.recordRequest(resp, err)
 	if err != nil {
 		return nil, err
 	}
@@ -43,7 +66,7 @@ func (a *ModuleAdapter) Execute(action string, params map[string]interface{}) (m
 	// 检查响应
 	if !resp.Success {
 		if resp.Error != nil {
-			return nil, fmt.Errorf("%s: %s", resp.Error.Code, resp.Error.Message)
+			return nil, FromErrorInfo(resp.Error)
 		}
 		return nil, fmt.Errorf("执行失败: %s", action)
 	}
@@ -51,6 +74,24 @@ func (a *ModuleAdapter) Execute(action string, params map[string]interface{}) (m
 	return resp.Data, nil
 }
 
+// recordRequest 如果模块支持请求计数（如嵌入了BaseModule），记录本次请求结果
+func (a *ModuleAdapter) recordRequest(resp *plugin.Response, err error) {
+	recorder, ok := a.Module.(requestRecorder)
+	if !ok {
+		return
+	}
+
+	if err == nil && resp != nil && !resp.Success && resp.Error != nil {
+		err = FromErrorInfo(resp.Error)
+	}
+	recorder.RecordRequest(err)
+}
+
+// requestRecorder 可记录请求处理结果的模块
+type requestRecorder interface {
+	RecordRequest(err error)
+}
+
 // Shutdown 实现了 plugin.Module 接口的 Shutdown 方法
 func (a *ModuleAdapter) Shutdown() error {
 	// 调用原始模块的 Stop 方法
@@ -71,6 +112,16 @@ func (a *ModuleAdapter) GetInfo() pluginLib.ModuleInfo {
 	}
 }
 
+// GetMetrics 实现了 plugin.Metricable 接口，返回原始模块的指标
+// 原始模块未实现 Metricable 时返回 nil
+func (a *ModuleAdapter) GetMetrics() map[string]interface{} {
+	metricable, ok := a.Module.(Metricable)
+	if !ok {
+		return nil
+	}
+	return metricable.GetMetrics()
+}
+
 // HandleMessage 实现了 plugin.Module 接口的 HandleMessage 方法
 func (a *ModuleAdapter) HandleMessage(messageType string, messageID string, timestamp int64, payload map[string]interface{}) (map[string]interface{}, error) {
 	// 创建事件
@@ -0,0 +1,229 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+)
+
+// ErrorCode 插件错误代码
+type ErrorCode string
+
+// 插件与主机共享的错误代码
+const (
+	// ErrorCodeInvalidParam 请求参数无效
+	ErrorCodeInvalidParam ErrorCode = "invalid_param"
+
+	// ErrorCodeNotFound 请求的资源不存在
+	ErrorCodeNotFound ErrorCode = "not_found"
+
+	// ErrorCodeUnauthorized 未授权的操作
+	ErrorCodeUnauthorized ErrorCode = "unauthorized"
+
+	// ErrorCodeTimeout 操作超时，可以重试
+	ErrorCodeTimeout ErrorCode = "timeout"
+
+	// ErrorCodeInternal 插件内部错误
+	ErrorCodeInternal ErrorCode = "internal"
+
+	// ErrorCodeUnknownAction 不支持的操作
+	ErrorCodeUnknownAction ErrorCode = "unknown_action"
+
+	// ErrorCodeNotImplemented 操作尚未实现
+	ErrorCodeNotImplemented ErrorCode = "not_implemented"
+)
+
+// knownErrorCodes 已知的错误代码
+var knownErrorCodes = map[ErrorCode]bool{
+	ErrorCodeInvalidParam:   true,
+	ErrorCodeNotFound:       true,
+	ErrorCodeUnauthorized:   true,
+	ErrorCodeTimeout:        true,
+	ErrorCodeInternal:       true,
+	ErrorCodeUnknownAction:  true,
+	ErrorCodeNotImplemented: true,
+}
+
+// 用于 errors.Is 匹配的哨兵错误，只比较错误代码
+var (
+	ErrInvalidParam   = &Error{Code: ErrorCodeInvalidParam}
+	ErrNotFound       = &Error{Code: ErrorCodeNotFound}
+	ErrUnauthorized   = &Error{Code: ErrorCodeUnauthorized}
+	ErrTimeout        = &Error{Code: ErrorCodeTimeout}
+	ErrInternal       = &Error{Code: ErrorCodeInternal}
+	ErrUnknownAction  = &Error{Code: ErrorCodeUnknownAction}
+	ErrNotImplemented = &Error{Code: ErrorCodeNotImplemented}
+)
+
+// Error 带错误代码的插件错误
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Details map[string]interface{}
+	Cause   error
+}
+
+// NewError 创建插件错误
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// WrapError 使用指定错误代码包装错误
+func WrapError(code ErrorCode, err error) *Error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Message: err.Error(), Cause: err}
+}
+
+// InvalidParamError 创建参数无效错误
+func InvalidParamError(format string, args ...interface{}) *Error {
+	return NewError(ErrorCodeInvalidParam, fmt.Sprintf(format, args...))
+}
+
+// NotFoundError 创建资源不存在错误
+func NotFoundError(format string, args ...interface{}) *Error {
+	return NewError(ErrorCodeNotFound, fmt.Sprintf(format, args...))
+}
+
+// UnauthorizedError 创建未授权错误
+func UnauthorizedError(format string, args ...interface{}) *Error {
+	return NewError(ErrorCodeUnauthorized, fmt.Sprintf(format, args...))
+}
+
+// TimeoutError 创建超时错误
+func TimeoutError(format string, args ...interface{}) *Error {
+	return NewError(ErrorCodeTimeout, fmt.Sprintf(format, args...))
+}
+
+// InternalError 创建内部错误
+func InternalError(format string, args ...interface{}) *Error {
+	return NewError(ErrorCodeInternal, fmt.Sprintf(format, args...))
+}
+
+// UnknownActionError 创建不支持的操作错误
+func UnknownActionError(action string) *Error {
+	return NewError(ErrorCodeUnknownAction, fmt.Sprintf("不支持的操作: %s", action))
+}
+
+// Error 实现error接口，格式与 ErrorInfo 跨插件边界传递时一致
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return string(e.Code)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is 错误代码相同即视为匹配，便于使用 errors.Is(err, sdk.ErrTimeout)
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+// WithDetails 添加错误详情
+func (e *Error) WithDetails(details map[string]interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+// Retryable 是否可以重试
+func (e *Error) Retryable() bool {
+	return e.Code == ErrorCodeTimeout
+}
+
+// ErrorInfo 转换为响应中的错误信息
+func (e *Error) ErrorInfo() *plugin.ErrorInfo {
+	return &plugin.ErrorInfo{
+		Code:    string(e.Code),
+		Message: e.Message,
+		Details: e.Details,
+	}
+}
+
+// AsError 将任意错误转换为插件错误，未分类的错误视为内部错误
+func AsError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var sdkErr *Error
+	if errors.As(err, &sdkErr) {
+		return sdkErr
+	}
+	return WrapError(ErrorCodeInternal, err)
+}
+
+// CodeOf 获取错误代码，未分类的错误返回 ErrorCodeInternal
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	return AsError(err).Code
+}
+
+// IsRetryable 检查错误是否可以重试
+func IsRetryable(err error) bool {
+	return err != nil && AsError(err).Retryable()
+}
+
+// ErrorResponse 根据错误创建失败响应
+func ErrorResponse(requestID string, err error) *plugin.Response {
+	if err == nil {
+		err = InternalError("未知错误")
+	}
+	return &plugin.Response{
+		ID:      requestID,
+		Success: false,
+		Error:   AsError(err).ErrorInfo(),
+	}
+}
+
+// FromErrorInfo 将响应中的错误信息还原为插件错误
+func FromErrorInfo(info *plugin.ErrorInfo) *Error {
+	if info == nil {
+		return nil
+	}
+	return &Error{
+		Code:    ErrorCode(info.Code),
+		Message: info.Message,
+		Details: info.Details,
+	}
+}
+
+// ParseError 从跨进程传递的错误文本中还原插件错误
+// gRPC边界只传递错误文本（如 "执行失败: timeout: 扫描超时"），查找其中第一个已知的错误代码
+func ParseError(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var sdkErr *Error
+	if errors.As(err, &sdkErr) {
+		return sdkErr
+	}
+
+	text := err.Error()
+	segments := strings.Split(text, ": ")
+	for i, segment := range segments {
+		code := ErrorCode(strings.TrimSpace(segment))
+		if knownErrorCodes[code] {
+			return &Error{
+				Code:    code,
+				Message: strings.Join(segments[i+1:], ": "),
+				Cause:   err,
+			}
+		}
+	}
+
+	return WrapError(ErrorCodeInternal, err)
+}
@@ -0,0 +1,108 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	pluginLib "github.com/lomehong/kennel/pkg/plugin"
+	pb "github.com/lomehong/kennel/pkg/plugin/proto/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errorsTestModule 按操作返回不同类型错误的测试模块
+type errorsTestModule struct {
+	*BaseModule
+}
+
+func (m *errorsTestModule) HandleRequest(ctx context.Context, req *plugin.Request) (*plugin.Response, error) {
+	switch req.Action {
+	case "slow":
+		return ErrorResponse(req.ID, TimeoutError("扫描超时: %s", "/data")), nil
+	case "missing":
+		return ErrorResponse(req.ID, NotFoundError("规则ID不存在: %s", "r1")), nil
+	case "crash":
+		return ErrorResponse(req.ID, errors.New("磁盘已满")), nil
+	default:
+		return ErrorResponse(req.ID, UnknownActionError(req.Action)), nil
+	}
+}
+
+func TestErrorHelpers_Codes(t *testing.T) {
+	cases := []struct {
+		err  *Error
+		code ErrorCode
+		is   error
+	}{
+		{InvalidParamError("参数 %s 不能为空", "path"), ErrorCodeInvalidParam, ErrInvalidParam},
+		{NotFoundError("规则不存在"), ErrorCodeNotFound, ErrNotFound},
+		{UnauthorizedError("令牌无效"), ErrorCodeUnauthorized, ErrUnauthorized},
+		{TimeoutError("执行超时"), ErrorCodeTimeout, ErrTimeout},
+		{InternalError("内部错误"), ErrorCodeInternal, ErrInternal},
+		{UnknownActionError("foo"), ErrorCodeUnknownAction, ErrUnknownAction},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.code, c.err.Code)
+		assert.Equal(t, c.code, CodeOf(c.err))
+		assert.True(t, errors.Is(c.err, c.is), "%s 应匹配哨兵错误", c.code)
+		assert.False(t, errors.Is(c.err, ErrUnauthorized) && c.code != ErrorCodeUnauthorized)
+
+		info := c.err.ErrorInfo()
+		assert.Equal(t, string(c.code), info.Code)
+		assert.Equal(t, c.err.Message, info.Message)
+	}
+
+	assert.Equal(t, "参数 path 不能为空", cases[0].err.Message)
+	assert.True(t, cases[3].err.Retryable())
+	assert.False(t, cases[0].err.Retryable())
+
+	// 包装后的错误仍可匹配，未分类错误视为内部错误
+	wrapped := fmt.Errorf("处理请求失败: %w", TimeoutError("执行超时"))
+	assert.True(t, errors.Is(wrapped, ErrTimeout))
+	assert.True(t, IsRetryable(wrapped))
+	assert.Equal(t, ErrorCodeInternal, CodeOf(errors.New("未知")))
+
+	cause := errors.New("连接被拒绝")
+	assert.True(t, errors.Is(WrapError(ErrorCodeTimeout, cause), cause))
+}
+
+func TestErrors_MatchAcrossPluginBoundary(t *testing.T) {
+	module := &errorsTestModule{
+		BaseModule: NewBaseModule("errors-test", "错误测试模块", "1.0.0", ""),
+	}
+	adapter := &ModuleAdapter{Module: module}
+
+	// 进程内适配器边界
+	_, err := adapter.Execute("slow", nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, "timeout: 扫描超时: /data", err.Error())
+
+	_, err = adapter.Execute("crash", nil)
+	assert.True(t, errors.Is(err, ErrInternal))
+
+	// gRPC边界只传递错误文本，主机侧通过 ParseError 还原错误代码
+	server := &pluginLib.GRPCServer{Impl: adapter}
+	for action, target := range map[string]error{
+		"slow":    ErrTimeout,
+		"missing": ErrNotFound,
+		"bogus":   ErrUnknownAction,
+	} {
+		resp, err := server.Execute(context.Background(), &pb.ActionRequest{Action: action, Params: "{}"})
+		require.NoError(t, err)
+		require.False(t, resp.Success)
+
+		// 与 GRPCClient.Execute 生成的错误格式一致
+		remoteErr := fmt.Errorf("执行失败: %s", resp.ErrorMessage)
+		parsed := ParseError(remoteErr)
+		assert.True(t, errors.Is(parsed, target), "%s: %v", action, remoteErr)
+	}
+
+	parsed := ParseError(errors.New("gRPC调用失败: connection refused"))
+	assert.Equal(t, ErrorCodeInternal, parsed.Code)
+}
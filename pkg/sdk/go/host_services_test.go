@@ -0,0 +1,242 @@
+package sdk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	pluginLib "github.com/lomehong/kennel/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hostServicesTestModule 记录初始化时收到的主机服务
+type hostServicesTestModule struct {
+	*BaseModule
+	services plugin.HostServices
+}
+
+func (m *hostServicesTestModule) Init(ctx context.Context, config *plugin.ModuleConfig) error {
+	m.services = config.Services
+	return m.BaseModule.Init(ctx, config)
+}
+
+// memoryStorage 内存存储服务
+type memoryStorage struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func (s *memoryStorage) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data[key], nil
+}
+
+func (s *memoryStorage) Set(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+func (s *memoryStorage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+// recordingComm 记录发送的数据
+type recordingComm struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (c *recordingComm) SendData(dataType string, data interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, dataType)
+}
+
+func (c *recordingComm) SendEvent(eventType string, details map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent = append(c.sent, eventType)
+}
+
+// countingExec 记录执行次数的命令服务
+type countingExec struct {
+	calls int
+}
+
+func (e *countingExec) Execute(ctx context.Context, command string, args ...string) ([]byte, error) {
+	e.calls++
+	return []byte("ok"), nil
+}
+
+func TestGRPCPlugin_HostServicesEnforcePermissions(t *testing.T) {
+	module := &hostServicesTestModule{
+		BaseModule: NewBaseModule("host-test", "主机服务测试模块", "1.0.0", "用于测试主机服务的模块"),
+	}
+	grpcClient := dispenseGRPCModule(t, &ModuleAdapter{Module: module})
+
+	storage := &memoryStorage{data: make(map[string][]byte)}
+	comm := &recordingComm{}
+	exec := &countingExec{}
+	permissions := plugin.PluginPermissions{Storage: true}
+	guard := plugin.NewPermissionGuard(plugin.PluginMetadata{ID: "host-test", Permissions: permissions}, nil)
+	services := plugin.HostServices{Comm: comm, Storage: storage, Exec: exec}.Guard(guard)
+
+	require.NoError(t, grpcClient.ServeHostServices("host-test", services, permissions))
+	require.NoError(t, grpcClient.Init(map[string]interface{}{}))
+	require.NotNil(t, module.services.Storage)
+
+	// 声明了存储权限，读写经主机完成
+	require.NoError(t, module.services.Storage.Set("key", []byte("value")))
+	assert.Equal(t, []byte("value"), storage.data["key"])
+	value, err := module.services.Storage.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("value"), value)
+
+	// 未声明执行权限，主机拒绝且不执行命令
+	_, err = module.services.Exec.Execute(context.Background(), "echo", "hello")
+	assert.ErrorIs(t, err, plugin.ErrPermissionDenied)
+	assert.Equal(t, 0, exec.calls)
+
+	// 未声明通讯权限，数据被丢弃
+	module.services.Comm.SendData("report", map[string]interface{}{"a": 1})
+	assert.Empty(t, comm.sent)
+	assert.Equal(t, uint64(2), guard.DeniedCount())
+}
+
+func TestGRPCPlugin_HostServicesUnavailable(t *testing.T) {
+	module := &hostServicesTestModule{
+		BaseModule: NewBaseModule("host-test", "主机服务测试模块", "1.0.0", "用于测试主机服务的模块"),
+	}
+	grpcClient := dispenseGRPCModule(t, &ModuleAdapter{Module: module})
+
+	// 主机未提供主机服务时，插件调用返回不可用
+	require.NoError(t, grpcClient.Init(map[string]interface{}{}))
+	require.NotNil(t, module.services.Storage)
+	_, err := module.services.Storage.Get("key")
+	assert.ErrorIs(t, err, pluginLib.ErrHostUnavailable)
+}
+
+func TestPluginManager_LoadPluginReadsManifest(t *testing.T) {
+	dir := t.TempDir()
+	pluginDir := filepath.Join(dir, "sample")
+	require.NoError(t, os.MkdirAll(pluginDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "sample.exe"), []byte("binary"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, pluginLib.ManifestFileName), []byte(`{
+		"id": "sample",
+		"name": "示例插件",
+		"version": "1.0.0",
+		"permissions": {"storage": true}
+	}`), 0644))
+
+	pm := pluginLib.NewPluginManager(pluginLib.WithPluginsDir(dir))
+	defer pm.Stop()
+
+	managed, err := pm.LoadPlugin(&pluginLib.PluginConfig{ID: "sample", Name: "示例插件", Path: "sample"})
+	require.NoError(t, err)
+	require.NotNil(t, managed.Manifest)
+	assert.True(t, managed.Manifest.Permissions.Storage)
+	assert.NoError(t, managed.Guard.Check(plugin.PermissionStorage, "storage.get"))
+	assert.ErrorIs(t, managed.Guard.Check(plugin.PermissionExec, "exec"), plugin.ErrPermissionDenied)
+}
+
+func TestPluginManager_LoadPluginRejectsMismatchedManifest(t *testing.T) {
+	dir := t.TempDir()
+	pluginDir := filepath.Join(dir, "sample")
+	require.NoError(t, os.MkdirAll(pluginDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "sample.exe"), []byte("binary"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, pluginLib.ManifestFileName), []byte(`{"id": "other"}`), 0644))
+
+	pm := pluginLib.NewPluginManager(pluginLib.WithPluginsDir(dir))
+	defer pm.Stop()
+
+	_, err := pm.LoadPlugin(&pluginLib.PluginConfig{ID: "sample", Path: "sample"})
+	assert.Error(t, err)
+}
+
+// recordingRouter 记录路由请求的插件路由服务
+type recordingRouter struct {
+	mu       sync.Mutex
+	targetID string
+	request  *plugin.Request
+}
+
+func (r *recordingRouter) CallPlugin(ctx context.Context, targetID string, req *plugin.Request) (*plugin.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.targetID = targetID
+	r.request = req
+	return &plugin.Response{ID: req.ID, Success: true, Data: map[string]interface{}{"echo": req.Params["value"]}}, nil
+}
+
+func TestGRPCPlugin_HostServicesRoutePluginCalls(t *testing.T) {
+	module := &hostServicesTestModule{
+		BaseModule: NewBaseModule("caller", "调用方插件", "1.0.0", "用于测试插件间调用的模块"),
+	}
+	grpcClient := dispenseGRPCModule(t, &ModuleAdapter{Module: module})
+
+	router := &recordingRouter{}
+	permissions := plugin.PluginPermissions{Plugins: []string{"target"}}
+	guard := plugin.NewPermissionGuard(plugin.PluginMetadata{ID: "caller", Permissions: permissions}, nil)
+	services := plugin.HostServices{Router: router}.Guard(guard)
+	require.NoError(t, grpcClient.ServeHostServices("caller", services, permissions))
+	require.NoError(t, grpcClient.Init(map[string]interface{}{}))
+
+	resp, err := module.services.Router.CallPlugin(context.Background(), "target", &plugin.Request{
+		ID:       "req-1",
+		Action:   "echo",
+		Params:   map[string]interface{}{"value": "hello"},
+		Metadata: map[string]string{plugin.MetadataSourcePlugin: "forged"},
+	})
+	require.NoError(t, err)
+	assert.True(t, resp.Success)
+	assert.Equal(t, "req-1", resp.ID)
+	assert.Equal(t, "hello", resp.Data["echo"])
+
+	// 目标插件看到的来源由主机设置，无法伪造
+	assert.Equal(t, "target", router.targetID)
+	assert.Equal(t, "caller", router.request.Metadata[plugin.MetadataSourcePlugin])
+
+	// 未声明的目标插件被拒绝
+	_, err = module.services.Router.CallPlugin(context.Background(), "other", &plugin.Request{Action: "echo"})
+	assert.ErrorIs(t, err, plugin.ErrPermissionDenied)
+}
+
+func TestPluginManager_CallPluginUnavailable(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sample"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sample", "sample.exe"), []byte("binary"), 0755))
+
+	pm := pluginLib.NewPluginManager(pluginLib.WithPluginsDir(dir))
+	defer pm.Stop()
+	_, err := pm.LoadPlugin(&pluginLib.PluginConfig{ID: "sample", Path: "sample"})
+	require.NoError(t, err)
+
+	module := &hostServicesTestModule{
+		BaseModule: NewBaseModule("caller", "调用方插件", "1.0.0", "用于测试插件间调用的模块"),
+	}
+	grpcClient := dispenseGRPCModule(t, &ModuleAdapter{Module: module})
+
+	permissions := plugin.PluginPermissions{Plugins: []string{"*"}}
+	guard := plugin.NewPermissionGuard(plugin.PluginMetadata{ID: "caller", Permissions: permissions}, nil)
+	services := plugin.HostServices{Router: pm}.Guard(guard)
+	require.NoError(t, grpcClient.ServeHostServices("caller", services, permissions))
+	require.NoError(t, grpcClient.Init(map[string]interface{}{}))
+
+	// 目标插件未加载
+	_, err = module.services.Router.CallPlugin(context.Background(), "missing", &plugin.Request{Action: "echo"})
+	assert.ErrorIs(t, err, plugin.ErrPluginUnavailable)
+
+	// 目标插件已加载但未启动
+	_, err = module.services.Router.CallPlugin(context.Background(), "sample", &plugin.Request{Action: "echo"})
+	assert.ErrorIs(t, err, plugin.ErrPluginUnavailable)
+}
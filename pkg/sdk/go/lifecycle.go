@@ -0,0 +1,105 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// LifecycleState 模块生命周期状态
+type LifecycleState int
+
+// 模块生命周期状态：Created → Initialized → Started → Stopped
+const (
+	// StateCreated 已创建，尚未初始化
+	StateCreated LifecycleState = iota
+
+	// StateInitialized 已初始化
+	StateInitialized
+
+	// StateStarted 已启动
+	StateStarted
+
+	// StateStopped 已停止，可以重新初始化或重新启动
+	StateStopped
+)
+
+// stateUnchanged 转换后保持当前状态
+const stateUnchanged LifecycleState = -1
+
+// String 返回生命周期状态的字符串表示
+func (s LifecycleState) String() string {
+	switch s {
+	case StateCreated:
+		return "created"
+	case StateInitialized:
+		return "initialized"
+	case StateStarted:
+		return "started"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// ErrInvalidTransition 无效的生命周期状态转换
+var ErrInvalidTransition = errors.New("无效的生命周期状态转换")
+
+// lifecycleTransitions 各操作允许的源状态和目标状态
+// reconfigure 在已初始化或运行中重新应用配置（如配置热更新时再次调用 Init），不改变状态
+var lifecycleTransitions = map[string]struct {
+	from []LifecycleState
+	to   LifecycleState
+}{
+	"init":        {from: []LifecycleState{StateCreated, StateStopped}, to: StateInitialized},
+	"reconfigure": {from: []LifecycleState{StateInitialized, StateStarted}, to: stateUnchanged},
+	"start":       {from: []LifecycleState{StateInitialized, StateStopped}, to: StateStarted},
+	"stop":        {from: []LifecycleState{StateStarted}, to: StateStopped},
+}
+
+// lifecycle 并发安全的生命周期状态机
+type lifecycle struct {
+	mu    sync.Mutex
+	state LifecycleState
+}
+
+// transition 依次尝试各操作，执行第一个当前状态允许的状态转换，返回转换前的状态和执行的操作
+// 所有操作都不允许时返回 ErrInvalidTransition
+func (l *lifecycle) transition(actions ...string) (LifecycleState, string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, action := range actions {
+		rule, ok := lifecycleTransitions[action]
+		if !ok {
+			return l.state, "", fmt.Errorf("%w: 未知操作 %s", ErrInvalidTransition, action)
+		}
+
+		for _, from := range rule.from {
+			if l.state == from {
+				previous := l.state
+				if rule.to != stateUnchanged {
+					l.state = rule.to
+				}
+				return previous, action, nil
+			}
+		}
+	}
+
+	return l.state, "", fmt.Errorf("%w: 当前状态为 %s，不能执行 %s", ErrInvalidTransition, l.state, strings.Join(actions, "/"))
+}
+
+// current 获取当前状态
+func (l *lifecycle) current() LifecycleState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.state
+}
+
+// State 获取模块当前的生命周期状态
+func (m *BaseModule) State() LifecycleState {
+	return m.lifecycle.current()
+}
@@ -0,0 +1,105 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseModule_LifecycleTransitions(t *testing.T) {
+	m := NewBaseModule("lifecycle-test", "生命周期测试模块", "1.0.0", "")
+	assert.Equal(t, StateCreated, m.State())
+
+	// 初始化前不能启动或停止
+	assert.True(t, errors.Is(m.Start(), ErrInvalidTransition))
+	assert.True(t, errors.Is(m.Stop(), ErrInvalidTransition))
+	assert.Equal(t, StateCreated, m.State())
+
+	require.NoError(t, m.Init(context.Background(), &plugin.ModuleConfig{Settings: map[string]interface{}{"k": "v"}}))
+	assert.Equal(t, StateInitialized, m.State())
+	assert.Equal(t, "v", m.Config["k"])
+
+	// 未启动时停止被拒绝，重复初始化只重新应用配置
+	assert.True(t, errors.Is(m.Stop(), ErrInvalidTransition))
+	require.NoError(t, m.Init(context.Background(), &plugin.ModuleConfig{Settings: map[string]interface{}{"k": "v2"}}))
+	assert.Equal(t, StateInitialized, m.State())
+	assert.Equal(t, "v2", m.Config["k"])
+
+	require.NoError(t, m.Start())
+	assert.Equal(t, StateStarted, m.State())
+	assert.Equal(t, "started", m.CheckHealth().Details["state"])
+
+	err := m.Start()
+	assert.True(t, errors.Is(err, ErrInvalidTransition))
+	assert.Contains(t, err.Error(), "started")
+
+	require.NoError(t, m.Stop())
+	assert.Equal(t, StateStopped, m.State())
+	assert.True(t, errors.Is(m.Stop(), ErrInvalidTransition))
+
+	// 停止后可以重新启动或重新初始化
+	require.NoError(t, m.Start())
+	require.NoError(t, m.Stop())
+	require.NoError(t, m.Init(context.Background(), &plugin.ModuleConfig{}))
+	assert.Equal(t, StateInitialized, m.State())
+}
+
+func TestBaseModule_ReloadRunningModuleConfig(t *testing.T) {
+	m := NewBaseModule("lifecycle-reload", "配置热更新测试模块", "1.0.0", "")
+	require.NoError(t, m.Init(context.Background(), &plugin.ModuleConfig{Settings: map[string]interface{}{"log_level": "info"}}))
+	require.NoError(t, m.Start())
+
+	// 配置热更新时对运行中的模块再次调用 Init，模块保持运行
+	require.NoError(t, m.Init(context.Background(), &plugin.ModuleConfig{Settings: map[string]interface{}{"log_level": "debug"}}))
+	assert.Equal(t, StateStarted, m.State())
+	assert.Equal(t, "debug", m.Config["log_level"])
+
+	// 重新加载配置后仍能正常停止，且不能重复启动
+	assert.True(t, errors.Is(m.Start(), ErrInvalidTransition))
+	require.NoError(t, m.Stop())
+	assert.Equal(t, StateStopped, m.State())
+}
+
+func TestBaseModule_ConcurrentStartStop(t *testing.T) {
+	m := NewBaseModule("lifecycle-concurrent", "并发测试模块", "1.0.0", "")
+	require.NoError(t, m.Init(context.Background(), &plugin.ModuleConfig{}))
+
+	const workers = 32
+	var started, stopped int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.Start(); err == nil {
+				atomic.AddInt32(&started, 1)
+			} else {
+				assert.True(t, errors.Is(err, ErrInvalidTransition))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), started, "并发启动只能成功一次")
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.Stop(); err == nil {
+				atomic.AddInt32(&stopped, 1)
+			} else {
+				assert.True(t, errors.Is(err, ErrInvalidTransition))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), stopped, "并发停止只能成功一次")
+	assert.Equal(t, StateStopped, m.State())
+}
@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"sync"
+	"time"
+
+	pluginLib "github.com/lomehong/kennel/pkg/plugin"
+)
+
+// Metricable 定义了可提供运行指标的模块
+// 主机通过此接口统一采集所有插件的指标，进程外插件的指标通过 GetMetrics RPC 传递
+type Metricable = pluginLib.Metricable
+
+// MetricsProvider 模块特定指标的提供函数
+// 返回的指标会合并到BaseModule的通用指标中
+type MetricsProvider func() map[string]interface{}
+
+// moduleMetrics 模块通用指标
+type moduleMetrics struct {
+	mu            sync.RWMutex
+	requestCount  uint64
+	errorCount    uint64
+	lastError     string
+	lastErrorTime time.Time
+	provider      MetricsProvider
+}
+
+// RecordRequest 记录一次请求处理，err不为nil时同时记录错误
+func (m *BaseModule) RecordRequest(err error) {
+	m.metrics.mu.Lock()
+	defer m.metrics.mu.Unlock()
+
+	m.metrics.requestCount++
+	if err != nil {
+		m.recordErrorLocked(err)
+	}
+}
+
+// RecordError 记录一次错误
+func (m *BaseModule) RecordError(err error) {
+	if err == nil {
+		return
+	}
+
+	m.metrics.mu.Lock()
+	defer m.metrics.mu.Unlock()
+
+	m.recordErrorLocked(err)
+}
+
+// recordErrorLocked 记录错误，调用方需持有锁
+func (m *BaseModule) recordErrorLocked(err error) {
+	m.metrics.errorCount++
+	m.metrics.lastError = err.Error()
+	m.metrics.lastErrorTime = time.Now()
+}
+
+// SetMetricsProvider 设置模块特定指标的提供函数
+func (m *BaseModule) SetMetricsProvider(provider MetricsProvider) {
+	m.metrics.mu.Lock()
+	defer m.metrics.mu.Unlock()
+
+	m.metrics.provider = provider
+}
+
+// GetMetrics 获取模块指标，包括运行时间、请求计数、最近错误以及模块特定指标
+func (m *BaseModule) GetMetrics() map[string]interface{} {
+	m.metrics.mu.RLock()
+	provider := m.metrics.provider
+	metrics := map[string]interface{}{
+		"id":             m.ID,
+		"start_time":     m.StartTime,
+		"uptime":         time.Since(m.StartTime).String(),
+		"uptime_seconds": time.Since(m.StartTime).Seconds(),
+		"request_count":  m.metrics.requestCount,
+		"error_count":    m.metrics.errorCount,
+	}
+	if m.metrics.lastError != "" {
+		metrics["last_error"] = m.metrics.lastError
+		metrics["last_error_time"] = m.metrics.lastErrorTime
+	}
+	m.metrics.mu.RUnlock()
+
+	// 在锁外调用模块提供的函数，避免模块回调中再次访问BaseModule导致死锁
+	if provider != nil {
+		for key, value := range provider() {
+			if _, exists := metrics[key]; !exists {
+				metrics[key] = value
+			}
+		}
+	}
+
+	return metrics
+}
@@ -0,0 +1,62 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	pluginLib "github.com/lomehong/kennel/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plainModule 未实现Metricable接口的测试模块
+type plainModule struct {
+	plugin.Module
+}
+
+// dispenseGRPCModule 通过内存gRPC连接启动插件，返回主机侧的插件客户端
+func dispenseGRPCModule(t *testing.T, impl pluginLib.Module) *pluginLib.GRPCClient {
+	client, _ := goplugin.TestPluginGRPCConn(t, false, map[string]goplugin.Plugin{
+		"module": &pluginLib.ModulePlugin{Impl: impl},
+	})
+	t.Cleanup(func() { client.Close() })
+
+	raw, err := client.Dispense("module")
+	require.NoError(t, err)
+	grpcClient, ok := raw.(*pluginLib.GRPCClient)
+	require.True(t, ok)
+	return grpcClient
+}
+
+func TestGRPCPlugin_GetMetrics(t *testing.T) {
+	module := newMetricsTestModule()
+	grpcClient := dispenseGRPCModule(t, &ModuleAdapter{Module: module})
+
+	_, err := grpcClient.Execute("process", nil)
+	require.NoError(t, err)
+	_, err = grpcClient.Execute("process", nil)
+	require.NoError(t, err)
+
+	// 主机通过统一的Metricable接口获取子进程插件的指标
+	var metricable pluginLib.Metricable = grpcClient
+	metrics := metricable.GetMetrics()
+
+	// 指标经JSON传输，数值统一为float64
+	assert.Equal(t, "metrics-test", metrics["id"])
+	assert.Equal(t, float64(2), metrics["request_count"])
+	assert.Equal(t, float64(0), metrics["error_count"])
+	assert.Equal(t, float64(2), metrics["processed_items"])
+	assert.Contains(t, metrics, "uptime_seconds")
+}
+
+func TestGRPCPlugin_GetMetricsUnsupported(t *testing.T) {
+	grpcClient := dispenseGRPCModule(t, &ModuleAdapter{Module: &plainModule{}})
+
+	_, err := grpcClient.FetchMetrics(context.Background())
+	assert.Error(t, err)
+
+	metrics := grpcClient.GetMetrics()
+	assert.Contains(t, metrics, "error")
+}
@@ -0,0 +1,97 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// metricsTestModule 嵌入BaseModule并提供自定义指标的测试模块
+type metricsTestModule struct {
+	*BaseModule
+	processed int
+}
+
+func newMetricsTestModule() *metricsTestModule {
+	m := &metricsTestModule{
+		BaseModule: NewBaseModule("metrics-test", "指标测试模块", "1.0.0", "用于测试指标的模块"),
+	}
+	m.SetMetricsProvider(func() map[string]interface{} {
+		return map[string]interface{}{
+			"processed_items": m.processed,
+			"request_count":   -1, // 不能覆盖通用指标
+		}
+	})
+	return m
+}
+
+func (m *metricsTestModule) HandleRequest(ctx context.Context, req *plugin.Request) (*plugin.Response, error) {
+	switch req.Action {
+	case "process":
+		m.processed++
+		return &plugin.Response{ID: req.ID, Success: true, Data: map[string]interface{}{}}, nil
+	case "fail":
+		return &plugin.Response{
+			ID:      req.ID,
+			Success: false,
+			Error:   &plugin.ErrorInfo{Code: "failed", Message: "处理失败"},
+		}, nil
+	default:
+		return nil, errors.New("未知操作")
+	}
+}
+
+func TestBaseModule_TracksRequestsAndUptime(t *testing.T) {
+	module := newMetricsTestModule()
+	module.StartTime = time.Now().Add(-2 * time.Second)
+	adapter := &ModuleAdapter{Module: module}
+
+	_, err := adapter.Execute("process", nil)
+	require.NoError(t, err)
+	_, err = adapter.Execute("process", nil)
+	require.NoError(t, err)
+	_, err = adapter.Execute("fail", nil)
+	require.Error(t, err)
+	_, err = adapter.Execute("unknown", nil)
+	require.Error(t, err)
+
+	var metricable Metricable = module
+	metrics := metricable.GetMetrics()
+
+	assert.Equal(t, uint64(4), metrics["request_count"])
+	assert.Equal(t, uint64(2), metrics["error_count"])
+	assert.Equal(t, "未知操作", metrics["last_error"])
+	assert.Contains(t, metrics, "last_error_time")
+	assert.GreaterOrEqual(t, metrics["uptime_seconds"].(float64), 2.0)
+	assert.Equal(t, "metrics-test", metrics["id"])
+}
+
+func TestBaseModule_CustomMetrics(t *testing.T) {
+	module := newMetricsTestModule()
+	module.RecordRequest(nil)
+	module.processed = 7
+
+	metrics := module.GetMetrics()
+
+	assert.Equal(t, 7, metrics["processed_items"])
+	assert.Equal(t, uint64(1), metrics["request_count"])
+	assert.Equal(t, uint64(0), metrics["error_count"])
+	assert.NotContains(t, metrics, "last_error")
+}
+
+func TestBaseModule_RecordError(t *testing.T) {
+	module := NewBaseModule("errors-test", "错误测试模块", "1.0.0", "")
+
+	module.RecordError(nil)
+	module.RecordError(errors.New("磁盘已满"))
+
+	metrics := module.GetMetrics()
+	assert.Equal(t, uint64(0), metrics["request_count"])
+	assert.Equal(t, uint64(1), metrics["error_count"])
+	assert.Equal(t, "磁盘已满", metrics["last_error"])
+}
@@ -23,6 +23,12 @@ type BaseModule struct {
 	Logger      logging.Logger
 	Config      map[string]interface{}
 	StartTime   time.Time
+
+	// 通用运行指标
+	metrics moduleMetrics
+
+	// 生命周期状态
+	lifecycle lifecycle
 }
 
 // NewBaseModule 创建基础模块
@@ -51,21 +57,44 @@ func NewBaseModule(id, name, version, description string) *BaseModule {
 }
 
 // Init 初始化模块
+// 创建后或停止后调用时进入已初始化状态；已初始化或运行中再次调用时重新应用配置，状态不变
 func (m *BaseModule) Init(ctx context.Context, config *plugin.ModuleConfig) error {
-	m.Logger.Info("初始化模块", "id", m.ID)
-	m.Config = config.Settings
+	_, action, err := m.lifecycle.transition("init", "reconfigure")
+	if err != nil {
+		m.Logger.Warn("初始化模块失败", "id", m.ID, "error", err)
+		return err
+	}
+
+	if action == "reconfigure" {
+		m.Logger.Info("重新加载模块配置", "id", m.ID, "state", m.State().String())
+	} else {
+		m.Logger.Info("初始化模块", "id", m.ID)
+	}
+	if config != nil {
+		m.Config = config.Settings
+	}
 	return nil
 }
 
-// Start 启动模块
+// Start 启动模块，必须先初始化，重复启动返回错误
 func (m *BaseModule) Start() error {
+	if _, _, err := m.lifecycle.transition("start"); err != nil {
+		m.Logger.Warn("启动模块失败", "id", m.ID, "error", err)
+		return err
+	}
+
 	m.Logger.Info("启动模块", "id", m.ID)
 	m.StartTime = time.Now()
 	return nil
 }
 
-// Stop 停止模块
+// Stop 停止模块，只能停止已启动的模块
 func (m *BaseModule) Stop() error {
+	if _, _, err := m.lifecycle.transition("stop"); err != nil {
+		m.Logger.Warn("停止模块失败", "id", m.ID, "error", err)
+		return err
+	}
+
 	m.Logger.Info("停止模块", "id", m.ID)
 	uptime := time.Since(m.StartTime)
 	m.Logger.Info("运行时间", "uptime", uptime.String())
@@ -89,20 +118,13 @@ func (m *BaseModule) GetInfo() plugin.ModuleInfo {
 
 // HandleRequest 处理请求
 func (m *BaseModule) HandleRequest(ctx context.Context, req *plugin.Request) (*plugin.Response, error) {
-	m.Logger.Info("处理请求", "action", req.Action)
-	return &plugin.Response{
-		ID:      req.ID,
-		Success: false,
-		Error: &plugin.ErrorInfo{
-			Code:    "not_implemented",
-			Message: fmt.Sprintf("未实现的操作: %s", req.Action),
-		},
-	}, nil
+	m.RequestLogger(ctx).Info("处理请求", "action", req.Action)
+	return ErrorResponse(req.ID, NewError(ErrorCodeNotImplemented, fmt.Sprintf("未实现的操作: %s", req.Action))), nil
 }
 
 // HandleEvent 处理事件
 func (m *BaseModule) HandleEvent(ctx context.Context, event *plugin.Event) error {
-	m.Logger.Info("处理事件", "type", event.Type, "source", event.Source)
+	m.RequestLogger(ctx).Info("处理事件", "type", event.Type, "source", event.Source)
 	return nil
 }
 
@@ -112,6 +134,7 @@ func (m *BaseModule) CheckHealth() plugin.HealthStatus {
 		Status: "healthy",
 		Details: map[string]interface{}{
 			"uptime": time.Since(m.StartTime).String(),
+			"state":  m.State().String(),
 		},
 		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
 	}
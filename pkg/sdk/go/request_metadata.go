@@ -0,0 +1,55 @@
+package sdk
+
+import (
+	"context"
+
+	"github.com/lomehong/kennel/pkg/logging"
+)
+
+// requestMetadataKey 请求元数据在上下文中的键
+type requestMetadataKey struct{}
+
+// ContextWithRequestMetadata 创建带主机请求元数据的上下文
+// 元数据中的 request_id、user_id、session_id、trace_id、span_id 同时写入日志上下文
+func ContextWithRequestMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	if len(metadata) == 0 {
+		return ctx
+	}
+
+	ctx = context.WithValue(ctx, requestMetadataKey{}, metadata)
+	for _, key := range []logging.LogContextKey{
+		logging.LogContextKeyRequestID,
+		logging.LogContextKeyUserID,
+		logging.LogContextKeySessionID,
+		logging.LogContextKeyTraceID,
+		logging.LogContextKeySpanID,
+	} {
+		if value := metadata[string(key)]; value != "" {
+			ctx = context.WithValue(ctx, key, value)
+		}
+	}
+	return ctx
+}
+
+// RequestMetadata 从上下文中获取主机请求元数据
+func RequestMetadata(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	metadata, _ := ctx.Value(requestMetadataKey{}).(map[string]string)
+	return metadata
+}
+
+// RequestLogger 返回带主机请求元数据字段的日志记录器，处理请求和事件时应使用它记录日志
+func (m *BaseModule) RequestLogger(ctx context.Context) logging.Logger {
+	metadata := RequestMetadata(ctx)
+	if len(metadata) == 0 {
+		return logging.LoggerFromContext(ctx, m.Logger)
+	}
+
+	fields := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		fields[key] = value
+	}
+	return m.Logger.WithFields(fields)
+}
@@ -0,0 +1,108 @@
+package sdk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	"github.com/lomehong/kennel/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// correlationTestModule 在请求处理中使用请求日志记录器的测试模块
+type correlationTestModule struct {
+	*BaseModule
+	lastRequest *plugin.Request
+}
+
+func (m *correlationTestModule) HandleRequest(ctx context.Context, req *plugin.Request) (*plugin.Response, error) {
+	m.lastRequest = req
+	m.RequestLogger(ctx).Info("处理关联请求", "action", req.Action)
+	return &plugin.Response{ID: req.ID, Success: true, Data: map[string]interface{}{"ok": true}}, nil
+}
+
+// newCorrelationTestModule 创建日志写入临时文件的测试模块，返回模块和日志文件路径
+func newCorrelationTestModule(t *testing.T) (*correlationTestModule, string) {
+	logFile := filepath.Join(t.TempDir(), "plugin.log")
+	logConfig := logging.DefaultLogConfig()
+	logConfig.Level = logging.LogLevelInfo
+	logConfig.Format = logging.LogFormatJSON
+	logConfig.Output = logging.LogOutputFile
+	logConfig.FilePath = logFile
+	logger, err := logging.NewEnhancedLogger(logConfig)
+	require.NoError(t, err)
+
+	base := NewBaseModule("correlation-test", "关联测试模块", "1.0.0", "")
+	base.Logger = logger
+	return &correlationTestModule{BaseModule: base}, logFile
+}
+
+// readLogLines 读取日志文件中包含指定消息的行
+func readLogLines(t *testing.T, path, message string) []string {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.Contains(line, message) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestGRPCPlugin_RequestMetadataInLogs(t *testing.T) {
+	module, logFile := newCorrelationTestModule(t)
+	grpcClient := dispenseGRPCModule(t, &ModuleAdapter{Module: module})
+
+	result, err := grpcClient.ExecuteWithMetadata("process", nil, map[string]string{
+		"request_id": "req-host-123",
+		"user_id":    "alice",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, true, result["ok"])
+
+	// 插件收到主机的请求ID和元数据
+	require.NotNil(t, module.lastRequest)
+	assert.Equal(t, "req-host-123", module.lastRequest.ID)
+	assert.Equal(t, "alice", module.lastRequest.Metadata["user_id"])
+
+	// 插件日志自动带上主机提供的关联字段
+	lines := readLogLines(t, logFile, "处理关联请求")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "req-host-123")
+	assert.Contains(t, lines[0], "alice")
+}
+
+func TestGRPCPlugin_ExecuteWithoutMetadata(t *testing.T) {
+	module, logFile := newCorrelationTestModule(t)
+	grpcClient := dispenseGRPCModule(t, &ModuleAdapter{Module: module})
+
+	_, err := grpcClient.Execute("process", nil)
+	require.NoError(t, err)
+
+	// 未提供元数据时生成请求ID
+	require.NotNil(t, module.lastRequest)
+	assert.True(t, strings.HasPrefix(module.lastRequest.ID, "req-"))
+	assert.Empty(t, module.lastRequest.Metadata)
+	assert.Len(t, readLogLines(t, logFile, "处理关联请求"), 1)
+}
+
+func TestContextWithRequestMetadata(t *testing.T) {
+	ctx := ContextWithRequestMetadata(context.Background(), map[string]string{
+		"request_id": "req-1",
+		"trace_id":   "trace-1",
+		"tenant":     "acme",
+	})
+
+	assert.Equal(t, "acme", RequestMetadata(ctx)["tenant"])
+	assert.Equal(t, "req-1", logging.GetRequestIDFromContext(ctx))
+	assert.Equal(t, "trace-1", logging.GetTraceIDFromContext(ctx))
+	assert.Empty(t, logging.GetUserIDFromContext(ctx))
+
+	assert.Nil(t, RequestMetadata(context.Background()))
+}
@@ -0,0 +1,94 @@
+package sdk
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	pluginLib "github.com/lomehong/kennel/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSignedPlugin 在插件目录中写入插件可执行文件和带签名的清单，返回信任库
+func writeSignedPlugin(t *testing.T, dir string) *plugin.TrustStore {
+	pluginDir := filepath.Join(dir, "sample")
+	require.NoError(t, os.MkdirAll(pluginDir, 0755))
+	binary := filepath.Join(pluginDir, "sample.exe")
+	require.NoError(t, os.WriteFile(binary, []byte("binary"), 0755))
+
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	signature, err := plugin.SignPluginBinary(binary, "release", private)
+	require.NoError(t, err)
+
+	manifest, err := json.Marshal(plugin.PluginMetadata{
+		ID:        "sample",
+		Name:      "示例插件",
+		Version:   "1.0.0",
+		Signature: signature,
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, pluginLib.ManifestFileName), manifest, 0644))
+
+	store := plugin.NewTrustStore()
+	require.NoError(t, store.AddKey("release", public))
+	return store
+}
+
+func TestPluginManager_LoadPluginVerifiesSignature(t *testing.T) {
+	dir := t.TempDir()
+	store := writeSignedPlugin(t, dir)
+
+	pm := pluginLib.NewPluginManager(
+		pluginLib.WithPluginsDir(dir),
+		pluginLib.WithSignatureVerification(store, plugin.SignatureModeEnforce),
+	)
+	defer pm.Stop()
+
+	_, err := pm.LoadPlugin(&pluginLib.PluginConfig{ID: "sample", Path: "sample"})
+	require.NoError(t, err)
+}
+
+func TestPluginManager_LoadPluginRejectsTamperedBinary(t *testing.T) {
+	dir := t.TempDir()
+	store := writeSignedPlugin(t, dir)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sample", "sample.exe"), []byte("tampered"), 0755))
+
+	pm := pluginLib.NewPluginManager(
+		pluginLib.WithPluginsDir(dir),
+		pluginLib.WithSignatureVerification(store, plugin.SignatureModeEnforce),
+	)
+	defer pm.Stop()
+
+	_, err := pm.LoadPlugin(&pluginLib.PluginConfig{ID: "sample", Path: "sample"})
+	assert.ErrorIs(t, err, plugin.ErrPluginSignatureInvalid)
+	_, exists := pm.GetPlugin("sample")
+	assert.False(t, exists)
+}
+
+func TestPluginManager_LoadPluginSignatureModes(t *testing.T) {
+	dir := t.TempDir()
+	writeSignedPlugin(t, dir)
+
+	// 签名密钥不在信任库中
+	pm := pluginLib.NewPluginManager(
+		pluginLib.WithPluginsDir(dir),
+		pluginLib.WithSignatureVerification(plugin.NewTrustStore(), plugin.SignatureModeEnforce),
+	)
+	defer pm.Stop()
+	_, err := pm.LoadPlugin(&pluginLib.PluginConfig{ID: "sample", Path: "sample"})
+	assert.ErrorIs(t, err, plugin.ErrUntrustedSigningKey)
+
+	// warn 模式下校验失败仍然加载
+	pm = pluginLib.NewPluginManager(
+		pluginLib.WithPluginsDir(dir),
+		pluginLib.WithSignatureVerification(nil, plugin.SignatureModeWarn),
+	)
+	defer pm.Stop()
+	_, err = pm.LoadPlugin(&pluginLib.PluginConfig{ID: "sample", Path: "sample"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,107 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+)
+
+// requiredTag 标记必填参数的结构体标签，例如 `json:"path" sdk:"required"`
+const requiredTag = "required"
+
+// DecodeParams 将请求参数解码为类型化结构体
+// 参数按结构体字段的 json 标签匹配，未提供的字段保持零值，多余的参数被忽略；
+// 参数类型不匹配或缺少带 `sdk:"required"` 标签的参数时返回 ErrorCodeInvalidParam 错误，错误详情中的 field 为参数名
+func DecodeParams[T any](req *plugin.Request) (T, error) {
+	var params T
+	if req == nil {
+		return params, InvalidParamError("请求不能为空")
+	}
+
+	data, err := json.Marshal(req.Params)
+	if err != nil {
+		return params, InvalidParamError("参数无法编码: %v", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(&params); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return params, InvalidParamError("参数 %s 类型错误: 期望 %s, 实际 %s", typeErr.Field, typeErr.Type, typeErr.Value).
+				WithDetails(map[string]interface{}{"field": typeErr.Field, "expected": typeErr.Type.String(), "actual": typeErr.Value})
+		}
+		return params, InvalidParamError("参数解码失败: %v", err)
+	}
+
+	if field, ok := missingRequired(reflect.TypeOf(params), req.Params); ok {
+		return params, InvalidParamError("缺少参数: %s", field).
+			WithDetails(map[string]interface{}{"field": field})
+	}
+	return params, nil
+}
+
+// missingRequired 返回第一个缺失的必填参数名
+func missingRequired(t reflect.Type, params map[string]interface{}) (string, bool) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Tag.Get("sdk") != requiredTag {
+			continue
+		}
+		name := jsonFieldName(field)
+		if name == "" {
+			continue
+		}
+		if value, ok := params[name]; !ok || value == nil {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// jsonFieldName 返回字段在JSON中的名称，忽略的字段返回空字符串
+func jsonFieldName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// NewTypedResponse 根据类型化数据创建成功响应
+// 结构体和映射按 json 标签转换为响应数据，其他类型（如切片）放在响应数据的 result 字段中；
+// 数据无法编码时返回内部错误响应
+func NewTypedResponse[T any](requestID string, data T) *plugin.Response {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return ErrorResponse(requestID, InternalError("响应数据无法编码: %v", err))
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil || fields == nil {
+		var value interface{}
+		if err := json.Unmarshal(encoded, &value); err != nil {
+			return ErrorResponse(requestID, InternalError("响应数据无法解码: %v", err))
+		}
+		fields = map[string]interface{}{"result": value}
+	}
+
+	return &plugin.Response{
+		ID:      requestID,
+		Success: true,
+		Data:    fields,
+	}
+}
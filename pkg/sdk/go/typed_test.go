@@ -0,0 +1,132 @@
+package sdk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lomehong/kennel/pkg/core/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scanParams 扫描请求参数
+type scanParams struct {
+	Path      string   `json:"path" sdk:"required"`
+	Depth     int      `json:"depth"`
+	Recursive bool     `json:"recursive"`
+	Patterns  []string `json:"patterns"`
+	Options   struct {
+		MaxSize int64 `json:"max_size"`
+	} `json:"options"`
+	Ignored string `json:"-" sdk:"required"`
+}
+
+// scanResult 扫描结果
+type scanResult struct {
+	Path    string   `json:"path"`
+	Matches int      `json:"matches"`
+	Files   []string `json:"files,omitempty"`
+}
+
+func TestDecodeParams(t *testing.T) {
+	req := &plugin.Request{
+		ID:     "req-1",
+		Action: "scan",
+		Params: map[string]interface{}{
+			"path":      "/data",
+			"depth":     float64(3), // 经JSON传输的数字为 float64
+			"recursive": true,
+			"patterns":  []interface{}{"*.doc", "*.pdf"},
+			"options":   map[string]interface{}{"max_size": 1024},
+			"unknown":   "ignored",
+		},
+	}
+
+	params, err := DecodeParams[scanParams](req)
+	require.NoError(t, err)
+	assert.Equal(t, "/data", params.Path)
+	assert.Equal(t, 3, params.Depth)
+	assert.True(t, params.Recursive)
+	assert.Equal(t, []string{"*.doc", "*.pdf"}, params.Patterns)
+	assert.Equal(t, int64(1024), params.Options.MaxSize)
+}
+
+func TestDecodeParams_MissingFields(t *testing.T) {
+	// 可选参数缺失时保持零值
+	params, err := DecodeParams[scanParams](&plugin.Request{Params: map[string]interface{}{"path": "/data"}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, params.Depth)
+	assert.False(t, params.Recursive)
+	assert.Nil(t, params.Patterns)
+
+	// 必填参数缺失或为 null
+	for _, p := range []map[string]interface{}{nil, {"depth": 1}, {"path": nil}} {
+		_, err := DecodeParams[scanParams](&plugin.Request{Params: p})
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidParam))
+
+		sdkErr := AsError(err)
+		assert.Equal(t, "缺少参数: path", sdkErr.Message)
+		assert.Equal(t, "path", sdkErr.Details["field"])
+	}
+
+	_, err = DecodeParams[scanParams](nil)
+	assert.True(t, errors.Is(err, ErrInvalidParam))
+}
+
+func TestDecodeParams_WrongType(t *testing.T) {
+	cases := []struct {
+		params map[string]interface{}
+		field  string
+	}{
+		{map[string]interface{}{"path": 123}, "path"},
+		{map[string]interface{}{"path": "/data", "depth": "deep"}, "depth"},
+		{map[string]interface{}{"path": "/data", "depth": 1.5}, "depth"},
+		{map[string]interface{}{"path": "/data", "patterns": "*.doc"}, "patterns"},
+		{map[string]interface{}{"path": "/data", "options": map[string]interface{}{"max_size": "big"}}, "options.max_size"},
+	}
+
+	for _, c := range cases {
+		_, err := DecodeParams[scanParams](&plugin.Request{Params: c.params})
+		require.Error(t, err, "参数 %v 应解码失败", c.params)
+		assert.True(t, errors.Is(err, ErrInvalidParam))
+		assert.Equal(t, c.field, AsError(err).Details["field"])
+		assert.Contains(t, err.Error(), "类型错误")
+	}
+}
+
+func TestNewTypedResponse(t *testing.T) {
+	resp := NewTypedResponse("req-1", scanResult{Path: "/data", Matches: 2, Files: []string{"a.doc", "b.pdf"}})
+	require.NotNil(t, resp)
+	assert.Equal(t, "req-1", resp.ID)
+	assert.True(t, resp.Success)
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, map[string]interface{}{
+		"path":    "/data",
+		"matches": float64(2),
+		"files":   []interface{}{"a.doc", "b.pdf"},
+	}, resp.Data)
+
+	// omitempty 字段不出现在响应中
+	resp = NewTypedResponse("req-2", &scanResult{Path: "/tmp"})
+	assert.NotContains(t, resp.Data, "files")
+
+	// 非对象数据放在 result 字段中
+	resp = NewTypedResponse("req-3", []string{"x", "y"})
+	assert.True(t, resp.Success)
+	assert.Equal(t, []interface{}{"x", "y"}, resp.Data["result"])
+
+	// 无法编码的数据返回内部错误响应
+	resp = NewTypedResponse("req-4", map[string]interface{}{"ch": make(chan int)})
+	assert.False(t, resp.Success)
+	require.NotNil(t, resp.Error)
+	assert.Equal(t, string(ErrorCodeInternal), resp.Error.Code)
+}
+
+func TestTypedRoundTrip(t *testing.T) {
+	// 类型化响应的数据可以作为另一个插件请求的参数解码
+	resp := NewTypedResponse("req-1", scanResult{Path: "/data", Matches: 5})
+	decoded, err := DecodeParams[scanResult](&plugin.Request{Params: resp.Data})
+	require.NoError(t, err)
+	assert.Equal(t, scanResult{Path: "/data", Matches: 5}, decoded)
+}
@@ -0,0 +1,34 @@
+package sdk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pluginLib "github.com/lomehong/kennel/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginManager_UpgradeSkipsStoppedPlugins(t *testing.T) {
+	dir := t.TempDir()
+	pluginDir := filepath.Join(dir, "sample")
+	require.NoError(t, os.MkdirAll(pluginDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginDir, "sample.exe"), []byte("binary"), 0755))
+	manifest := filepath.Join(pluginDir, pluginLib.ManifestFileName)
+	require.NoError(t, os.WriteFile(manifest, []byte(`{"id": "sample", "version": "1.0.0"}`), 0644))
+
+	pm := pluginLib.NewPluginManager(pluginLib.WithPluginsDir(dir))
+	defer pm.Stop()
+	_, err := pm.LoadPlugin(&pluginLib.PluginConfig{ID: "sample", Path: "sample"})
+	require.NoError(t, err)
+
+	// 新版本清单只影响运行中的插件，未启动的插件在启动时直接使用新版本
+	require.NoError(t, os.WriteFile(manifest, []byte(`{"id": "sample", "version": "2.0.0"}`), 0644))
+	upgraded, err := pm.UpgradePlugins()
+	require.NoError(t, err)
+	assert.Empty(t, upgraded)
+
+	assert.Error(t, pm.UpgradePlugin("sample"))
+	assert.Error(t, pm.UpgradePlugin("missing"))
+}
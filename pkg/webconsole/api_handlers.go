@@ -9,6 +9,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/lomehong/kennel/pkg/comm"
+	"github.com/lomehong/kennel/pkg/core/metrics"
 )
 
 // LogEntry 表示日志条目
@@ -338,6 +339,22 @@ func (c *Console) getSystemMetricsData() map[string]interface{} {
 	return metrics
 }
 
+// getPluginUsage 获取各插件的CPU和内存占用
+// 默认返回最近一次采样的结果，refresh=true 时立即重新采样
+func (c *Console) getPluginUsage(ctx *gin.Context) {
+	refresh := ctx.Query("refresh") == "true"
+	ctx.JSON(http.StatusOK, c.app.GetPluginUsage(refresh))
+}
+
+// getPrometheusMetrics 以 Prometheus 文本格式输出全部子系统和插件的指标
+func (c *Console) getPrometheusMetrics(ctx *gin.Context) {
+	ctx.Header("Content-Type", metrics.ContentType)
+	ctx.Status(http.StatusOK)
+	if err := c.app.WriteMetrics(ctx.Writer); err != nil {
+		c.logger.Error("输出指标失败", "error", err)
+	}
+}
+
 // getSystemStatus 获取系统状态
 func (c *Console) getSystemStatus(ctx *gin.Context) {
 	// 获取系统监控器
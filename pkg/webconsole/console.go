@@ -243,6 +243,8 @@ func (c *Console) setupRoutes() {
 			metrics.GET("", c.getMetrics)
 			metrics.GET("/comm", c.getCommMetrics)
 			metrics.GET("/system", c.getSystemMetrics)
+			metrics.GET("/plugins", c.getPluginUsage)
+			metrics.GET("/prometheus", c.getPrometheusMetrics)
 		}
 
 		// 系统监控API
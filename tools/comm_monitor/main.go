@@ -22,11 +22,31 @@ var (
 	jsonOutput  = flag.Bool("json", false, "输出JSON格式")
 	watchMetric = flag.String("watch", "", "监控特定指标")
 	duration    = flag.Int("duration", 0, "监控持续时间（秒），0表示一直运行")
+	outputFile  = flag.String("output", "", "将每次采集的指标追加写入文件")
+	outputFmt   = flag.String("output-format", "", "指标文件格式（csv/json），默认根据扩展名判断")
+	alertRules  = flag.String("alert", "", "告警阈值，多个用逗号分隔，例如 reconnect_count>3,error_count>=1")
+	alertExit   = flag.Bool("alert-exit", false, "触发告警时以退出码 2 退出，便于CI和冒烟测试判断")
+	slowCount   = flag.Int("slow", 0, "每次采集后输出最近最慢的 N 个请求，0表示不输出")
 )
 
+// exitCodeAlert 触发告警时的退出码
+const exitCodeAlert = 2
+
 func main() {
+	os.Exit(run())
+}
+
+// run 运行监控，返回进程退出码
+func run() int {
 	flag.Parse()
 
+	// 解析告警阈值
+	thresholds, err := comm.ParseMetricThresholds(*alertRules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "告警阈值无效: %v\n", err)
+		return 1
+	}
+
 	// 创建日志器
 	logConfig := logging.DefaultLogConfig()
 
@@ -72,12 +92,27 @@ func main() {
 		"monitor_mode": true,
 	})
 
+	// 打开指标文件
+	var writer *comm.MetricsFileWriter
+	if *outputFile != "" {
+		format := comm.MetricsFileFormat(*outputFmt)
+		if format == "" {
+			format = comm.MetricsFormatFromPath(*outputFile)
+		}
+		writer, err = comm.NewMetricsFileWriter(*outputFile, format)
+		if err != nil {
+			log.Error("打开指标文件失败", "error", err)
+			return 1
+		}
+		defer writer.Close()
+	}
+
 	// 连接到服务器
 	fmt.Printf("连接到服务器 %s...\n", config.ServerURL)
-	err := manager.Connect()
+	err = manager.Connect()
 	if err != nil {
 		log.Error("连接服务器失败", "error", err)
-		os.Exit(1)
+		return 1
 	}
 	defer manager.Disconnect()
 
@@ -104,12 +139,20 @@ func main() {
 			// 检查是否到达结束时间
 			if *duration > 0 && time.Now().After(endTime) {
 				fmt.Println("监控时间到，退出...")
-				return
+				return 0
 			}
 
 			// 获取指标
+			now := time.Now()
 			metrics := manager.GetMetrics()
 
+			// 写入指标文件
+			if writer != nil {
+				if err := writer.Write(now, metrics); err != nil {
+					log.Error("写入指标文件失败", "error", err)
+				}
+			}
+
 			// 输出指标
 			if *jsonOutput {
 				// JSON格式输出
@@ -141,14 +184,51 @@ func main() {
 				}
 			}
 
+			// 输出最慢的请求
+			if *slowCount > 0 {
+				printSlowRequests(manager.GetSlowRequests(), *slowCount)
+			}
+
 			// 输出分隔线
 			if !*jsonOutput {
 				fmt.Println("----------------------------------------")
 			}
 
+			// 评估告警阈值
+			if len(thresholds) > 0 {
+				violations, err := comm.EvaluateThresholds(metrics, thresholds)
+				if err != nil {
+					log.Warn("评估告警阈值失败", "error", err)
+				}
+				for _, violation := range violations {
+					log.Error("指标告警", "metric", violation.Threshold.Metric,
+						"value", violation.Actual, "threshold", violation.Threshold.String())
+					fmt.Printf("告警: %s\n", violation)
+				}
+				if len(violations) > 0 && *alertExit {
+					return exitCodeAlert
+				}
+			}
+
 		case <-sigCh:
 			fmt.Println("接收到中断信号，退出...")
-			return
+			return 0
 		}
 	}
 }
+
+// printSlowRequests 输出最慢的请求
+func printSlowRequests(requests []comm.SlowRequest, limit int) {
+	if len(requests) > limit {
+		requests = requests[:limit]
+	}
+	fmt.Println("最慢的请求:")
+	if len(requests) == 0 {
+		fmt.Println("  尚无请求数据")
+		return
+	}
+	for _, request := range requests {
+		fmt.Printf("  %s %-10s %8dms  发送于 %s\n", request.ID, request.Type,
+			request.Latency.Milliseconds(), request.SentAt.Format("15:04:05.000"))
+	}
+}